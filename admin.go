@@ -44,6 +44,10 @@ func adminCreate(ctx context.Context, command *cli.Command) error {
 		return err
 	}
 
+	if err := password.RecordHistory(ctx, catalyst.Queries, user.ID, passwordHash); err != nil {
+		return err
+	}
+
 	if err := catalyst.Queries.AssignGroupToUser(ctx, sqlc.AssignGroupToUserParams{
 		UserID:  user.ID,
 		GroupID: "admin",
@@ -88,6 +92,10 @@ func adminSetPassword(ctx context.Context, command *cli.Command) error {
 		return err
 	}
 
+	if err := password.RecordHistory(ctx, catalyst.Queries, user.ID, passwordHash); err != nil {
+		return err
+	}
+
 	slog.InfoContext(ctx, "Setting password for admin", "id", user.ID, "email", user.Email)
 
 	return nil
@@ -0,0 +1,306 @@
+// Package anomaly learns the hourly ticket volume per ticket type and source
+// and raises an alert ticket when the volume spikes or a source goes silent,
+// which catches broken ingestion as well as real attack waves.
+package anomaly
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/SecurityBrewery/catalyst/app/auth/usercontext"
+	"github.com/SecurityBrewery/catalyst/app/database"
+	"github.com/SecurityBrewery/catalyst/app/database/sqlc"
+	"github.com/SecurityBrewery/catalyst/app/hook"
+	"github.com/SecurityBrewery/catalyst/app/openapi"
+	"github.com/SecurityBrewery/catalyst/app/settings"
+)
+
+const (
+	Spike   = "spike"
+	Silence = "silence"
+
+	DefaultSensitivity  = 3.0
+	DefaultMinCount     = 5
+	DefaultSilenceHours = 6
+
+	checkInterval = time.Hour
+	// learningPeriod is the history the baseline is learned from.
+	learningPeriod = 14 * 24 * time.Hour
+	// minHistory hours are needed before a series is checked.
+	minHistory = 24
+	// cooldown between two alerts of the same kind for the same series.
+	cooldown = 24 * time.Hour
+)
+
+// Anomaly is an unexpected ticket volume of a ticket type and source. The
+// source is the "source" field of the ticket state.
+type Anomaly struct {
+	Kind     string
+	Type     string
+	Source   string
+	Count    int
+	Expected float64
+}
+
+func (a *Anomaly) key() string {
+	return a.Kind + "/" + a.Type + "/" + a.Source
+}
+
+func (a *Anomaly) series() string {
+	if a.Source == "" {
+		return a.Type
+	}
+
+	return a.Type + " from " + a.Source
+}
+
+type Detector struct {
+	queries *sqlc.Queries
+	hooks   *hook.Hooks
+	now     func() time.Time
+
+	mu      sync.Mutex
+	alerted map[string]time.Time
+}
+
+func New(queries *sqlc.Queries, hooks *hook.Hooks) *Detector {
+	return &Detector{
+		queries: queries,
+		hooks:   hooks,
+		now:     time.Now,
+		alerted: map[string]time.Time{},
+	}
+}
+
+// Start checks the ticket volume every hour until the context is canceled.
+func (d *Detector) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(checkInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := d.Check(ctx); err != nil {
+					slog.ErrorContext(ctx, "Failed to check ticket volume", "error", err)
+				}
+			}
+		}
+	}()
+}
+
+// Check creates an alert ticket for each new anomaly.
+func (d *Detector) Check(ctx context.Context) error {
+	se, err := settings.Load(ctx, d.queries)
+	if err != nil {
+		return err
+	}
+
+	config := withDefaults(se.AnomalyDetection)
+	if !config.Enabled {
+		return nil
+	}
+
+	anomalies, err := d.Detect(ctx, &config)
+	if err != nil {
+		return err
+	}
+
+	for _, anomaly := range anomalies {
+		if !d.allow(&anomaly) {
+			continue
+		}
+
+		if err := d.createTicket(ctx, &config, &anomaly); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func withDefaults(config settings.AnomalyDetection) settings.AnomalyDetection {
+	if config.Sensitivity <= 0 {
+		config.Sensitivity = DefaultSensitivity
+	}
+
+	if config.MinCount <= 0 {
+		config.MinCount = DefaultMinCount
+	}
+
+	if config.SilenceHours <= 0 {
+		config.SilenceHours = DefaultSilenceHours
+	}
+
+	return config
+}
+
+type series struct {
+	ticketType string
+	source     string
+	first      time.Time
+	counts     map[time.Time]int
+}
+
+// Detect compares the last complete hours of each series with its baseline.
+// A spike is a last hour with more than Sensitivity standard deviations
+// above the mean. A silent source had no tickets in the last SilenceHours,
+// although at least MinCount were expected.
+func (d *Detector) Detect(ctx context.Context, config *settings.AnomalyDetection) ([]Anomaly, error) {
+	now := d.now().UTC().Truncate(time.Hour)
+
+	rows, err := d.queries.CountTicketsPerHour(ctx, now.Add(-learningPeriod).Format(time.DateTime))
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		order []string
+		all   = map[string]*series{}
+	)
+
+	for _, row := range rows {
+		hour, err := time.Parse(time.DateTime, row.Hour)
+		if err != nil || !hour.Before(now) {
+			continue // skip the current, incomplete hour
+		}
+
+		key := row.Type + "/" + row.Source
+
+		s, ok := all[key]
+		if !ok {
+			s = &series{ticketType: row.Type, source: row.Source, first: hour, counts: map[time.Time]int{}}
+			all[key] = s
+			order = append(order, key)
+		}
+
+		s.counts[hour] += int(row.Count)
+	}
+
+	var anomalies []Anomaly
+
+	for _, key := range order {
+		s := all[key]
+
+		lastHour := now.Add(-time.Hour)
+		if mean, stddev, n := s.stats(lastHour); n >= minHistory {
+			if current := s.counts[lastHour]; current >= config.MinCount && float64(current) > mean+config.Sensitivity*stddev {
+				anomalies = append(anomalies, Anomaly{Kind: Spike, Type: s.ticketType, Source: s.source, Count: current, Expected: mean})
+			}
+		}
+
+		silenceStart := now.Add(-time.Duration(config.SilenceHours) * time.Hour)
+		if mean, _, n := s.stats(silenceStart); n >= minHistory {
+			expected := mean * float64(config.SilenceHours)
+			if s.sum(silenceStart, now) == 0 && expected >= float64(config.MinCount) {
+				anomalies = append(anomalies, Anomaly{Kind: Silence, Type: s.ticketType, Source: s.source, Expected: expected})
+			}
+		}
+	}
+
+	return anomalies, nil
+}
+
+// stats returns the mean and standard deviation of the hourly counts from
+// the first hour of the series until the end, and the number of hours.
+func (s *series) stats(end time.Time) (mean, stddev float64, n int) {
+	for hour := s.first; hour.Before(end); hour = hour.Add(time.Hour) {
+		mean += float64(s.counts[hour])
+		n++
+	}
+
+	if n == 0 {
+		return 0, 0, 0
+	}
+
+	mean /= float64(n)
+
+	for hour := s.first; hour.Before(end); hour = hour.Add(time.Hour) {
+		diff := float64(s.counts[hour]) - mean
+		stddev += diff * diff
+	}
+
+	return mean, math.Sqrt(stddev / float64(n)), n
+}
+
+func (s *series) sum(start, end time.Time) int {
+	sum := 0
+	for hour := start; hour.Before(end); hour = hour.Add(time.Hour) {
+		sum += s.counts[hour]
+	}
+
+	return sum
+}
+
+// allow reports whether the anomaly was not alerted within the cooldown.
+func (d *Detector) allow(anomaly *Anomaly) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := d.now()
+	if last, ok := d.alerted[anomaly.key()]; ok && now.Sub(last) < cooldown {
+		return false
+	}
+
+	d.alerted[anomaly.key()] = now
+
+	return true
+}
+
+func (d *Detector) createTicket(ctx context.Context, config *settings.AnomalyDetection, anomaly *Anomaly) error {
+	ticketType, err := d.queries.GetType(ctx, config.TicketType)
+	if err != nil {
+		return fmt.Errorf("failed to get ticket type %q: %w", config.TicketType, err)
+	}
+
+	systemUser, err := d.queries.SystemUser(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to find system user: %w", err)
+	}
+
+	ctx = usercontext.UserContext(ctx, &systemUser)
+
+	name := "Ticket volume spike: " + anomaly.series()
+	description := fmt.Sprintf("%d tickets were created in the last hour, %.1f were expected.", anomaly.Count, anomaly.Expected)
+
+	if anomaly.Kind == Silence {
+		name = "Silent ticket source: " + anomaly.series()
+		description = fmt.Sprintf("No tickets were created in the last %d hours, %.1f were expected. The ingestion may be broken.", config.SilenceHours, anomaly.Expected)
+	}
+
+	d.hooks.OnRecordBeforeCreateRequest.Publish(ctx, database.TicketsTable.ID, map[string]string{
+		"name":        name,
+		"description": description,
+	})
+
+	ticket, err := d.queries.CreateTicket(ctx, sqlc.CreateTicketParams{
+		Name:        name,
+		Description: description,
+		Open:        true,
+		Type:        ticketType.ID,
+		Schema:      ticketType.Schema,
+		State:       []byte("{}"),
+	})
+	if err != nil {
+		return err
+	}
+
+	d.hooks.OnRecordAfterCreateRequest.Publish(ctx, database.TicketsTable.ID, openapi.Ticket{
+		Created:     ticket.Created,
+		Description: ticket.Description,
+		Id:          ticket.ID,
+		Name:        ticket.Name,
+		Open:        ticket.Open,
+		Type:        ticket.Type,
+		State:       map[string]any{},
+		Updated:     ticket.Updated,
+	})
+
+	return nil
+}
@@ -0,0 +1,136 @@
+package anomaly
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/SecurityBrewery/catalyst/app/data"
+	"github.com/SecurityBrewery/catalyst/app/database"
+	"github.com/SecurityBrewery/catalyst/app/database/sqlc"
+	"github.com/SecurityBrewery/catalyst/app/hook"
+	"github.com/SecurityBrewery/catalyst/app/settings"
+)
+
+func insertTickets(t *testing.T, queries *sqlc.Queries, ticketType, state string, created time.Time, count int) {
+	t.Helper()
+
+	for range count {
+		_, err := queries.InsertTicket(t.Context(), sqlc.InsertTicketParams{
+			ID:      database.GenerateID("t"),
+			Name:    "generated",
+			Open:    true,
+			Type:    ticketType,
+			Schema:  []byte("{}"),
+			State:   []byte(state),
+			Created: created,
+			Updated: created,
+		})
+		require.NoError(t, err)
+	}
+}
+
+func newTestDetector(t *testing.T) (*Detector, time.Time) {
+	t.Helper()
+
+	queries := data.NewTestDB(t, t.TempDir())
+	now := time.Date(2025, 3, 15, 12, 30, 0, 0, time.UTC)
+	hour := now.Truncate(time.Hour)
+
+	// an EDR sends one alert per hour and ten in the last hour
+	for h := 49; h > 1; h-- {
+		insertTickets(t, queries, "alert", `{"source":"edr"}`, hour.Add(-time.Duration(h)*time.Hour+time.Minute), 1)
+	}
+
+	insertTickets(t, queries, "alert", `{"source":"edr"}`, hour.Add(-30*time.Minute), 10)
+
+	// a SIEM sends two incidents per hour, but nothing in the last 7 hours
+	for h := 72; h > 7; h-- {
+		insertTickets(t, queries, "incident", `{"source":"siem"}`, hour.Add(-time.Duration(h)*time.Hour+time.Minute), 2)
+	}
+
+	d := New(queries, hook.NewHooks())
+	d.now = func() time.Time { return now }
+
+	return d, now
+}
+
+func TestDetector_Detect(t *testing.T) {
+	t.Parallel()
+
+	d, _ := newTestDetector(t)
+
+	config := withDefaults(settings.AnomalyDetection{Enabled: true})
+
+	anomalies, err := d.Detect(t.Context(), &config)
+	require.NoError(t, err)
+	require.Len(t, anomalies, 2)
+
+	// series are ordered by their first ticket
+	assert.Equal(t, Silence, anomalies[0].Kind)
+	assert.Equal(t, "incident from siem", anomalies[0].series())
+	assert.InDelta(t, 11.8, anomalies[0].Expected, 0.1)
+
+	assert.Equal(t, Spike, anomalies[1].Kind)
+	assert.Equal(t, "alert", anomalies[1].Type)
+	assert.Equal(t, "edr", anomalies[1].Source)
+	assert.Equal(t, 10, anomalies[1].Count)
+	assert.InDelta(t, 1.0, anomalies[1].Expected, 0.01)
+
+	// a less sensitive configuration ignores the spike
+	config.MinCount = 20
+
+	anomalies, err = d.Detect(t.Context(), &config)
+	require.NoError(t, err)
+	assert.Empty(t, anomalies)
+}
+
+func TestDetector_Check(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	d, _ := newTestDetector(t)
+
+	// disabled by default
+	require.NoError(t, d.Check(ctx))
+	assert.Empty(t, d.alerted)
+
+	_, err := settings.Update(ctx, d.queries, func(se *settings.Settings) {
+		se.AnomalyDetection = settings.AnomalyDetection{Enabled: true, TicketType: "incident"}
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, d.Check(ctx))
+	require.NoError(t, d.Check(ctx)) // within the cooldown
+
+	tickets, err := database.PaginateItems(ctx, func(ctx context.Context, offset, limit int64) ([]sqlc.ListTicketsRow, error) {
+		return d.queries.ListTickets(ctx, sqlc.ListTicketsParams{Offset: offset, Limit: limit})
+	})
+	require.NoError(t, err)
+
+	var names []string
+
+	for _, ticket := range tickets {
+		if ticket.Name != "generated" && ticket.ID != "test-ticket" {
+			names = append(names, ticket.Name)
+		}
+	}
+
+	assert.ElementsMatch(t, []string{"Ticket volume spike: alert from edr", "Silent ticket source: incident from siem"}, names)
+}
+
+func TestSeries_stats(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	s := series{first: start, counts: map[time.Time]int{start: 2, start.Add(2 * time.Hour): 4}}
+
+	mean, stddev, n := s.stats(start.Add(4 * time.Hour))
+	assert.Equal(t, 4, n)
+	assert.InDelta(t, 1.5, mean, 0.001)
+	assert.InDelta(t, 1.658, stddev, 0.001)
+	assert.Equal(t, 4, s.sum(start.Add(time.Hour), start.Add(3*time.Hour)))
+}
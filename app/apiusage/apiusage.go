@@ -0,0 +1,232 @@
+// Package apiusage counts the API requests of each client per day and
+// enforces optional daily quotas, to find runaway scripts and keep shared
+// instances usable for everybody. A client is a user with their own tokens,
+// or an automation like a reaction acting for the user with its tokens.
+// Requests are counted in memory and stored every few seconds, so reads do
+// not wait for the single writer of the database.
+package apiusage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/SecurityBrewery/catalyst/app/database/sqlc"
+)
+
+const (
+	dayFormat = time.DateOnly
+	// flushInterval is how often the counts are stored and the quotas are
+	// read again.
+	flushInterval = 10 * time.Second
+)
+
+// ErrQuotaExceeded is returned for requests over the daily quota of a client.
+var ErrQuotaExceeded = errors.New("daily API quota exceeded")
+
+// Counter counts the requests in memory and stores the counts in the
+// background, so that counting does not write to the database on every
+// request. The quotas are read again whenever the counts are stored.
+type Counter struct {
+	queries *sqlc.Queries
+
+	mux    sync.Mutex
+	usage  map[usageKey]*usage
+	quotas map[clientKey]int64
+}
+
+type clientKey struct {
+	user, client string
+}
+
+type usageKey struct {
+	clientKey
+	day string
+}
+
+type usage struct {
+	// stored is the count in the database when the client was first seen on
+	// the day, pending the requests counted since.
+	stored, pending int64
+}
+
+func NewCounter(queries *sqlc.Queries) *Counter {
+	return &Counter{queries: queries, usage: map[usageKey]*usage{}}
+}
+
+// Start stores the counts every flushInterval and once more when the context
+// is canceled. Without Start, e.g. on a read-only instance, the requests are
+// only counted in memory.
+func (c *Counter) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(flushInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				c.Flush(context.WithoutCancel(ctx))
+
+				return
+			case <-ticker.C:
+				c.Flush(ctx)
+			}
+		}
+	}()
+}
+
+// Record counts a request of the client of a user. Requests over the daily
+// quota of the client are counted as well, so that a runaway script stays
+// visible, and return ErrQuotaExceeded.
+func (c *Counter) Record(ctx context.Context, user, client string, now time.Time) error {
+	key := usageKey{clientKey: clientKey{user: user, client: client}, day: Day(now)}
+
+	if err := c.load(ctx, key); err != nil {
+		return err
+	}
+
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	u := c.usage[key]
+	u.pending++
+
+	if quota, ok := c.quotas[key.clientKey]; ok && u.stored+u.pending > quota {
+		return ErrQuotaExceeded
+	}
+
+	return nil
+}
+
+// load reads the stored count of a client and the quotas unless they are
+// known.
+func (c *Counter) load(ctx context.Context, key usageKey) error {
+	c.mux.Lock()
+	_, known := c.usage[key]
+	loaded := c.quotas != nil
+	c.mux.Unlock()
+
+	if known && loaded {
+		return nil
+	}
+
+	var quotas map[clientKey]int64
+
+	if !loaded {
+		var err error
+		if quotas, err = c.loadQuotas(ctx); err != nil {
+			return err
+		}
+	}
+
+	stored, err := c.queries.GetAPIUsage(ctx, sqlc.GetAPIUsageParams{User: key.user, Client: key.client, Day: key.day})
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("failed to get API usage: %w", err)
+	}
+
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	if c.quotas == nil {
+		c.quotas = quotas
+	}
+
+	if _, ok := c.usage[key]; !ok {
+		c.usage[key] = &usage{stored: stored}
+	}
+
+	return nil
+}
+
+func (c *Counter) loadQuotas(ctx context.Context) (map[clientKey]int64, error) {
+	list, err := c.queries.ListAPIQuotas(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list API quotas: %w", err)
+	}
+
+	quotas := make(map[clientKey]int64, len(list))
+	for _, quota := range list {
+		quotas[clientKey{user: quota.User, client: quota.Client}] = quota.DailyLimit
+	}
+
+	return quotas, nil
+}
+
+// Flush stores the pending counts and reads the quotas again. Counts that
+// fail to be stored stay pending, the counts of past days are forgotten
+// once they are stored.
+func (c *Counter) Flush(ctx context.Context) {
+	c.mux.Lock()
+	pending := make(map[usageKey]int64, len(c.usage))
+
+	for key, u := range c.usage {
+		if u.pending > 0 {
+			pending[key] = u.pending
+			u.stored += u.pending
+			u.pending = 0
+		}
+	}
+	c.mux.Unlock()
+
+	for key, requests := range pending {
+		if err := c.queries.AddAPIUsage(ctx, sqlc.AddAPIUsageParams{
+			User:     key.user,
+			Client:   key.client,
+			Day:      key.day,
+			Requests: requests,
+		}); err != nil {
+			slog.ErrorContext(ctx, "Failed to store API usage", "user", key.user, "client", key.client, "error", err)
+
+			c.mux.Lock()
+			c.usage[key].stored -= requests
+			c.usage[key].pending += requests
+			c.mux.Unlock()
+		}
+	}
+
+	quotas, err := c.loadQuotas(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to load API quotas", "error", err)
+	}
+
+	today := Day(time.Now())
+
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	if quotas != nil {
+		c.quotas = quotas
+	}
+
+	for key, u := range c.usage {
+		if key.day != today && u.pending == 0 {
+			delete(c.usage, key)
+		}
+	}
+}
+
+// Report returns the requests of each client between since and until, the
+// busiest first, with the requests of today and the daily quota.
+func Report(ctx context.Context, queries *sqlc.Queries, since, until, now time.Time) ([]sqlc.ListAPIUsageRow, error) {
+	return queries.ListAPIUsage(ctx, sqlc.ListAPIUsageParams{
+		Today: Day(now),
+		Since: Day(since),
+		Until: Day(until),
+	})
+}
+
+// Day returns the UTC day of a time, as the usage is counted.
+func Day(t time.Time) string {
+	return t.UTC().Format(dayFormat)
+}
+
+// Reset returns the time until the quotas reset at midnight UTC.
+func Reset(now time.Time) time.Duration {
+	now = now.UTC()
+
+	return time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, time.UTC).Sub(now)
+}
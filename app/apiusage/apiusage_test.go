@@ -0,0 +1,98 @@
+package apiusage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/SecurityBrewery/catalyst/app/data"
+	"github.com/SecurityBrewery/catalyst/app/database/sqlc"
+)
+
+func TestRecord(t *testing.T) {
+	t.Parallel()
+
+	queries := data.NewTestDB(t, t.TempDir())
+	ctx := t.Context()
+
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	_, err := queries.UpsertAPIQuota(ctx, sqlc.UpsertAPIQuotaParams{User: "u_bob_analyst", Client: "r_script", DailyLimit: 2})
+	require.NoError(t, err)
+
+	counter := NewCounter(queries)
+
+	// the quota only applies to the automation, not to the user
+	for range 3 {
+		require.NoError(t, counter.Record(ctx, "u_bob_analyst", "", now))
+	}
+
+	require.NoError(t, counter.Record(ctx, "u_bob_analyst", "r_script", now))
+	require.NoError(t, counter.Record(ctx, "u_bob_analyst", "r_script", now))
+	require.ErrorIs(t, counter.Record(ctx, "u_bob_analyst", "r_script", now), ErrQuotaExceeded)
+
+	// the quota resets the next day
+	require.NoError(t, counter.Record(ctx, "u_bob_analyst", "r_script", now.Add(24*time.Hour)))
+
+	// the requests are only stored by Flush
+	usage, err := Report(ctx, queries, now.AddDate(0, 0, -30), now.Add(24*time.Hour), now.Add(24*time.Hour))
+	require.NoError(t, err)
+	assert.Empty(t, usage)
+
+	counter.Flush(ctx)
+
+	usage, err = Report(ctx, queries, now.AddDate(0, 0, -30), now.Add(24*time.Hour), now.Add(24*time.Hour))
+	require.NoError(t, err)
+	require.Len(t, usage, 2)
+
+	assert.Equal(t, "r_script", usage[0].Client)
+	assert.Equal(t, int64(4), usage[0].Requests, "rejected requests are counted")
+	assert.Equal(t, int64(1), usage[0].Today)
+	assert.Equal(t, int64(2), *usage[0].DailyLimit)
+
+	assert.Empty(t, usage[1].Client)
+	assert.Equal(t, int64(3), usage[1].Requests)
+	assert.Equal(t, int64(0), usage[1].Today)
+	assert.Nil(t, usage[1].DailyLimit)
+}
+
+func TestCounter_stored(t *testing.T) {
+	t.Parallel()
+
+	queries := data.NewTestDB(t, t.TempDir())
+	ctx := t.Context()
+	now := time.Now()
+
+	_, err := queries.UpsertAPIQuota(ctx, sqlc.UpsertAPIQuotaParams{User: "u_bob_analyst", DailyLimit: 2})
+	require.NoError(t, err)
+
+	counter := NewCounter(queries)
+	require.NoError(t, counter.Record(ctx, "u_bob_analyst", "", now))
+	counter.Flush(ctx)
+
+	// a restarted instance continues with the stored count
+	restarted := NewCounter(queries)
+	require.NoError(t, restarted.Record(ctx, "u_bob_analyst", "", now))
+	require.ErrorIs(t, restarted.Record(ctx, "u_bob_analyst", "", now), ErrQuotaExceeded)
+
+	// the quotas are read again on flush
+	_, err = queries.UpsertAPIQuota(ctx, sqlc.UpsertAPIQuotaParams{User: "u_bob_analyst", DailyLimit: 10})
+	require.NoError(t, err)
+
+	restarted.Flush(ctx)
+	require.NoError(t, restarted.Record(ctx, "u_bob_analyst", "", now))
+
+	usage, err := Report(ctx, queries, now, now, now)
+	require.NoError(t, err)
+	require.Len(t, usage, 1)
+	assert.Equal(t, int64(3), usage[0].Requests)
+}
+
+func TestReset(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, 12*time.Hour, Reset(time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)))
+	assert.Equal(t, time.Second, Reset(time.Date(2025, 12, 31, 23, 59, 59, 0, time.UTC)))
+}
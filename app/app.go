@@ -2,18 +2,49 @@ package app
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
+	"log/slog"
 	"net/http"
+	"net/netip"
+	"path/filepath"
 
+	"github.com/SecurityBrewery/catalyst/app/anomaly"
+	"github.com/SecurityBrewery/catalyst/app/apiusage"
+	"github.com/SecurityBrewery/catalyst/app/applog"
+	"github.com/SecurityBrewery/catalyst/app/attack"
+	"github.com/SecurityBrewery/catalyst/app/autoclose"
+	"github.com/SecurityBrewery/catalyst/app/backup"
+	"github.com/SecurityBrewery/catalyst/app/campaign"
+	"github.com/SecurityBrewery/catalyst/app/casekey"
+	"github.com/SecurityBrewery/catalyst/app/custody"
+	"github.com/SecurityBrewery/catalyst/app/cve"
 	"github.com/SecurityBrewery/catalyst/app/database"
 	"github.com/SecurityBrewery/catalyst/app/database/sqlc"
+	"github.com/SecurityBrewery/catalyst/app/detection"
+	"github.com/SecurityBrewery/catalyst/app/digest"
+	"github.com/SecurityBrewery/catalyst/app/escalation"
+	"github.com/SecurityBrewery/catalyst/app/export"
+	"github.com/SecurityBrewery/catalyst/app/federation"
+	"github.com/SecurityBrewery/catalyst/app/feed"
 	"github.com/SecurityBrewery/catalyst/app/hook"
+	"github.com/SecurityBrewery/catalyst/app/logsink"
 	"github.com/SecurityBrewery/catalyst/app/mail"
+	"github.com/SecurityBrewery/catalyst/app/maintenance"
 	"github.com/SecurityBrewery/catalyst/app/migration"
+	"github.com/SecurityBrewery/catalyst/app/notification"
+	"github.com/SecurityBrewery/catalyst/app/platform"
+	"github.com/SecurityBrewery/catalyst/app/plugin"
+	"github.com/SecurityBrewery/catalyst/app/push"
 	"github.com/SecurityBrewery/catalyst/app/reaction"
 	"github.com/SecurityBrewery/catalyst/app/reaction/schedule"
+	"github.com/SecurityBrewery/catalyst/app/reopen"
 	"github.com/SecurityBrewery/catalyst/app/router"
 	"github.com/SecurityBrewery/catalyst/app/service"
+	"github.com/SecurityBrewery/catalyst/app/slack"
+	"github.com/SecurityBrewery/catalyst/app/storage"
+	"github.com/SecurityBrewery/catalyst/app/tasktimer"
 	"github.com/SecurityBrewery/catalyst/app/upload"
 	"github.com/SecurityBrewery/catalyst/app/webhook"
 )
@@ -21,36 +52,96 @@ import (
 type App struct {
 	Queries *sqlc.Queries
 	Hooks   *hook.Hooks
-	router  http.Handler
+	// Logs keeps the application log, it receives the records of the
+	// handlers it returns.
+	Logs   *applog.Log
+	router http.Handler
 }
 
-func New(ctx context.Context, dir string) (*App, func(), error) {
-	uploader, err := upload.New(dir)
+// Config holds the settings of the server that are given on startup
+// instead of being stored in the database.
+type Config struct {
+	Backup backup.Config
+	// Storage keeps the uploaded files, the data directory by default.
+	Storage storage.Config
+	// ReadOnly rejects all requests that modify data, e.g. for an instance
+	// serving a replicated database. The database is opened read-only, it is
+	// not migrated, the log is not stored and no background job runs.
+	ReadOnly bool
+	// TrustedProxies are the reverse proxies whose X-Forwarded-For header
+	// identifies the client for rate limiting.
+	TrustedProxies []netip.Prefix
+}
+
+func New(ctx context.Context, dir string, config Config) (*App, func(), error) {
+	uploader, err := upload.NewWithStorage(dir, config.Storage)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to create uploader: %w", err)
 	}
 
-	queries, cleanup, err := database.DB(ctx, dir)
+	queries, cleanup, err := connect(ctx, dir, uploader, config.ReadOnly)
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to connect to database: %w", err)
+		return nil, nil, err
 	}
 
-	if err := migration.Apply(ctx, queries, dir, uploader); err != nil {
-		return nil, nil, fmt.Errorf("failed to migrate database: %w", err)
+	// a missing master key must not be replaced while tickets are encrypted
+	// with it
+	wrapped, err := queries.AnyTicketKey(ctx)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, nil, fmt.Errorf("failed to read case keys: %w", err)
 	}
 
-	mailer := mail.New(queries)
+	keyring, err := casekey.Load(dir, wrapped)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load case key: %w", err)
+	}
+
+	signer, err := custody.Load(dir)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load custody signing key: %w", err)
+	}
+
+	backups, err := backup.New(queries, uploader, dir, config.Backup)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create backup manager: %w", err)
+	}
 
-	scheduler, err := schedule.New(ctx, queries)
+	feeds, err := feed.New(queries, dir)
 	if err != nil {
-		return nil, cleanup, fmt.Errorf("failed to create scheduler: %w", err)
+		return nil, nil, fmt.Errorf("failed to create feed loader: %w", err)
+	}
+
+	mailer := mail.New(queries)
+
+	// the scheduler is only used by the requests that change reactions,
+	// which a read-only instance rejects
+	var scheduler *schedule.Scheduler
+	if !config.ReadOnly {
+		scheduler, err = schedule.New(ctx, queries)
+		if err != nil {
+			return nil, cleanup, fmt.Errorf("failed to create scheduler: %w", err)
+		}
 	}
 
 	hooks := hook.NewHooks()
 
-	service := service.New(queries, hooks, uploader, scheduler)
+	plugins, err := plugin.New(ctx, filepath.Join(dir, "plugins"), hooks)
+	if err != nil {
+		return nil, cleanup, fmt.Errorf("failed to load plugins: %w", err)
+	}
+
+	service := service.New(queries, hooks, uploader, scheduler, plugins, keyring, signer, backups, feeds)
+
+	slackApp := slack.New(queries, hooks, service)
+	fed := federation.New(queries, hooks)
+
+	logs := applog.New(queries)
+	sinks := logsink.New(queries, logs, hooks, dir)
+	monitor := platform.New(queries, hooks, logs)
 
-	router, err := router.New(service, queries, uploader, mailer)
+	usage := apiusage.NewCounter(queries)
+
+	router, err := router.New(service, queries, uploader, mailer, plugins, slackApp, fed, logs, backups, usage, config.ReadOnly, config.TrustedProxies)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to create router: %w", err)
 	}
@@ -60,14 +151,105 @@ func New(ctx context.Context, dir string) (*App, func(), error) {
 	}
 
 	webhook.BindHooks(hooks, queries)
+	pusher := push.BindHooks(hooks, queries)
+	notification.BindHooks(hooks, queries, mailer)
+	escalations := escalation.BindHooks(hooks, queries, mailer, pusher)
+	reopen.BindHooks(hooks, queries, mailer, pusher)
+	campaign.BindHooks(hooks, queries)
+	attack.BindHooks(hooks, queries)
+	cve.BindHooks(hooks, queries)
+	detection.BindHooks(hooks, queries)
+	slackApp.BindHooks()
+	fed.BindHooks()
+
+	// the background jobs write to the database or the data directory
+	if !config.ReadOnly {
+		logs.Start(ctx)
+		usage.Start(ctx)
+		sinks.Start(ctx)
+		monitor.Start(ctx)
+		digest.New(queries, mailer).Start(ctx)
+		export.New(queries).Start(ctx)
+		anomaly.New(queries, hooks).Start(ctx)
+		escalations.Start(ctx)
+		autoclose.New(queries, hooks, mailer, pusher).Start(ctx)
+		tasktimer.New(queries).Start(ctx)
+		maintenance.New(queries).Start(ctx)
+		backups.Start(ctx)
+		cve.New(queries).Start(ctx)
+	}
 
 	app := &App{
 		Queries: queries,
 		Hooks:   hooks,
+		Logs:    logs,
 		router:  router,
 	}
 
-	return app, cleanup, nil
+	return app, func() {
+		if err := plugins.Close(ctx); err != nil {
+			slog.ErrorContext(ctx, "Failed to close plugins", "error", err)
+		}
+
+		cleanup()
+	}, nil
+}
+
+// connect opens the database and brings it up to date, or checks that it is
+// up to date if it is read-only.
+func connect(ctx context.Context, dir string, uploader *upload.Uploader, readOnly bool) (*sqlc.Queries, func(), error) {
+	if readOnly {
+		queries, cleanup, err := database.ReadOnlyDB(ctx, dir)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to connect to database: %w", err)
+		}
+
+		if err := migration.Check(ctx, queries); err != nil {
+			cleanup()
+
+			return nil, nil, err
+		}
+
+		return queries, cleanup, nil
+	}
+
+	queries, cleanup, err := database.DB(ctx, dir)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+
+	if err := migration.Apply(ctx, queries, dir, uploader); err != nil {
+		cleanup()
+
+		return nil, nil, fmt.Errorf("failed to migrate database: %w", err)
+	}
+
+	if err := storeUploads(ctx, queries, uploader); err != nil {
+		cleanup()
+
+		return nil, nil, fmt.Errorf("failed to store uploads: %w", err)
+	}
+
+	return queries, cleanup, nil
+}
+
+// storeUploads moves the uploads of the files that are still in the data
+// directory, after a restore or a change of the storage, to the storage.
+func storeUploads(ctx context.Context, queries *sqlc.Queries, uploader *upload.Uploader) error {
+	files, err := database.PaginateItems(ctx, func(ctx context.Context, offset, limit int64) ([]sqlc.ListFileBlobsRow, error) {
+		return queries.ListFileBlobs(ctx, sqlc.ListFileBlobsParams{Limit: limit, Offset: offset})
+	})
+	if err != nil {
+		return fmt.Errorf("list files: %w", err)
+	}
+
+	for _, file := range files {
+		if err := uploader.Store(ctx, file.ID, file.Blob); err != nil {
+			return fmt.Errorf("store file %s: %w", file.ID, err)
+		}
+	}
+
+	return nil
 }
 
 func (a *App) ServeHTTP(w http.ResponseWriter, r *http.Request) {
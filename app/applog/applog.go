@@ -0,0 +1,386 @@
+// Package applog keeps the application log in the database, so that admins
+// can search it and follow it live without access to the server. Records
+// are written in the background and records that arrive faster than they
+// can be written are dropped, logging never blocks the caller.
+package applog
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/SecurityBrewery/catalyst/app/auth/usercontext"
+	"github.com/SecurityBrewery/catalyst/app/database/sqlc"
+	"github.com/SecurityBrewery/catalyst/app/settings"
+)
+
+const (
+	// ComponentKey sets the component of a record, which defaults to the
+	// package that logged it.
+	ComponentKey = "component"
+	// CriticalKey marks a record as a critical error of Catalyst itself,
+	// see Critical.
+	CriticalKey = "critical"
+
+	DefaultLevel         = slog.LevelWarn
+	DefaultRetentionDays = 14
+
+	modulePath      = "github.com/SecurityBrewery/catalyst/"
+	bufferSize      = 4096
+	subscriberSize  = 256
+	reloadInterval  = time.Minute
+	cleanupInterval = time.Hour
+)
+
+// Critical is the attribute of critical errors of Catalyst itself, like a
+// failed backup, that the SOC must notice. They open platform tickets if
+// enabled.
+var Critical = slog.Bool(CriticalKey, true)
+
+// Entry is a log record.
+type Entry struct {
+	Level     slog.Level
+	Message   string
+	Component string
+	User      string
+	Attrs     map[string]any
+	Created   time.Time
+}
+
+// Critical reports whether the record was logged with the Critical
+// attribute.
+func (e *Entry) Critical() bool {
+	critical, _ := e.Attrs[CriticalKey].(bool)
+
+	return critical
+}
+
+// Log stores the records of its handlers and streams them to subscribers.
+type Log struct {
+	queries *sqlc.Queries
+	level   slog.LevelVar
+	entries chan Entry
+
+	mux         sync.Mutex
+	subscribers map[chan Entry]struct{}
+}
+
+func New(queries *sqlc.Queries) *Log {
+	l := &Log{
+		queries:     queries,
+		entries:     make(chan Entry, bufferSize),
+		subscribers: map[chan Entry]struct{}{},
+	}
+
+	l.level.Set(DefaultLevel)
+
+	return l
+}
+
+// Start writes the stored records, applies changes of the log settings
+// within a minute and deletes the records older than the retention until
+// the context is canceled.
+func (l *Log) Start(ctx context.Context) {
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case entry := <-l.entries:
+				l.write(ctx, entry)
+			}
+		}
+	}()
+
+	go func() {
+		l.cleanup(ctx)
+
+		reload := time.NewTicker(reloadInterval)
+		defer reload.Stop()
+
+		cleanup := time.NewTicker(cleanupInterval)
+		defer cleanup.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-reload.C:
+				if _, err := l.reload(ctx); err != nil && ctx.Err() == nil {
+					fmt.Fprintln(os.Stderr, "failed to load log settings:", err)
+				}
+			case <-cleanup.C:
+				l.cleanup(ctx)
+			}
+		}
+	}()
+}
+
+// reload applies the stored level and returns the log settings.
+func (l *Log) reload(ctx context.Context) (*settings.Logs, error) {
+	s, err := settings.Load(ctx, l.queries)
+	if err != nil {
+		return nil, err
+	}
+
+	level, err := ParseLevel(s.Logs.Level)
+	if err != nil {
+		return nil, err
+	}
+
+	l.level.Set(level)
+
+	return &s.Logs, nil
+}
+
+// Subscribe returns a channel that receives all records, regardless of the
+// stored level, until cancel is called. Records are dropped for slow
+// subscribers.
+func (l *Log) Subscribe() (<-chan Entry, func()) {
+	ch := make(chan Entry, subscriberSize)
+
+	l.mux.Lock()
+	l.subscribers[ch] = struct{}{}
+	l.mux.Unlock()
+
+	return ch, func() {
+		l.mux.Lock()
+		delete(l.subscribers, ch)
+		l.mux.Unlock()
+	}
+}
+
+func (l *Log) subscribed() bool {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+
+	return len(l.subscribers) > 0
+}
+
+func (l *Log) publish(entry Entry) {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+
+	for ch := range l.subscribers {
+		select {
+		case ch <- entry:
+		default:
+		}
+	}
+}
+
+func (l *Log) add(entry Entry) {
+	l.publish(entry)
+
+	if entry.Level < l.level.Level() {
+		return
+	}
+
+	select {
+	case l.entries <- entry:
+	default:
+	}
+}
+
+// write stores a record. Its errors go to stderr, logging them would feed
+// them back into the log.
+func (l *Log) write(ctx context.Context, entry Entry) {
+	attrs, err := json.Marshal(entry.Attrs)
+	if err != nil {
+		attrs = []byte("{}")
+	}
+
+	var user *string
+	if entry.User != "" {
+		user = &entry.User
+	}
+
+	if err := l.queries.CreateLog(ctx, sqlc.CreateLogParams{
+		Level:     int64(entry.Level),
+		Message:   entry.Message,
+		Component: entry.Component,
+		User:      user,
+		Attrs:     attrs,
+		Created:   entry.Created.UTC(),
+	}); err != nil && ctx.Err() == nil {
+		fmt.Fprintln(os.Stderr, "failed to store log record:", err)
+	}
+}
+
+func (l *Log) cleanup(ctx context.Context) {
+	config, err := l.reload(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			fmt.Fprintln(os.Stderr, "failed to load log settings:", err)
+		}
+
+		return
+	}
+
+	days := config.RetentionDays
+	if days <= 0 {
+		days = DefaultRetentionDays
+	}
+
+	if _, err := l.queries.DeleteLogsBefore(ctx, time.Now().UTC().AddDate(0, 0, -days)); err != nil && ctx.Err() == nil {
+		fmt.Fprintln(os.Stderr, "failed to delete old log records:", err)
+	}
+}
+
+// ParseLevel parses a level like "info", an empty level is the default.
+func ParseLevel(level string) (slog.Level, error) {
+	if level == "" {
+		return DefaultLevel, nil
+	}
+
+	var l slog.Level
+	if err := l.UnmarshalText([]byte(level)); err != nil {
+		return 0, fmt.Errorf("invalid log level %q, expected debug, info, warn or error", level)
+	}
+
+	return l, nil
+}
+
+// LevelName returns the name of a level like "info".
+func LevelName(level slog.Level) string {
+	return strings.ToLower(level.String())
+}
+
+// Handler returns a handler that passes the records to next and adds them
+// to the log.
+func (l *Log) Handler(next slog.Handler) slog.Handler {
+	return &handler{log: l, next: next}
+}
+
+type handler struct {
+	log    *Log
+	next   slog.Handler
+	attrs  []slog.Attr
+	prefix string
+}
+
+func (h *handler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level) || level >= h.log.level.Level() || h.log.subscribed()
+}
+
+func (h *handler) Handle(ctx context.Context, record slog.Record) error {
+	entry := Entry{
+		Level:   record.Level,
+		Message: record.Message,
+		Attrs:   map[string]any{},
+		Created: record.Time,
+	}
+
+	if user, ok := usercontext.UserFromContext(ctx); ok {
+		entry.User = user.ID
+	}
+
+	for _, attr := range h.attrs {
+		entry.addAttr("", attr)
+	}
+
+	record.Attrs(func(attr slog.Attr) bool {
+		entry.addAttr(h.prefix, attr)
+
+		return true
+	})
+
+	if name, ok := entry.Attrs[ComponentKey].(string); ok {
+		entry.Component = name
+		delete(entry.Attrs, ComponentKey)
+	} else {
+		entry.Component = component(record.PC)
+	}
+
+	h.log.add(entry)
+
+	if !h.next.Enabled(ctx, record.Level) {
+		return nil
+	}
+
+	return h.next.Handle(ctx, record)
+}
+
+func (h *handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	prefixed := make([]slog.Attr, 0, len(h.attrs)+len(attrs))
+	prefixed = append(prefixed, h.attrs...)
+
+	for _, attr := range attrs {
+		prefixed = append(prefixed, slog.Attr{Key: h.prefix + attr.Key, Value: attr.Value})
+	}
+
+	return &handler{log: h.log, next: h.next.WithAttrs(attrs), attrs: prefixed, prefix: h.prefix}
+}
+
+func (h *handler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+
+	return &handler{log: h.log, next: h.next.WithGroup(name), attrs: h.attrs, prefix: h.prefix + name + "."}
+}
+
+func (e *Entry) addAttr(prefix string, attr slog.Attr) {
+	value := attr.Value.Resolve()
+
+	if value.Kind() == slog.KindGroup {
+		for _, a := range value.Group() {
+			e.addAttr(prefix+attr.Key+".", a)
+		}
+
+		return
+	}
+
+	if attr.Key == "" {
+		return
+	}
+
+	switch value.Kind() {
+	case slog.KindAny:
+		if err, ok := value.Any().(error); ok {
+			e.Attrs[prefix+attr.Key] = err.Error()
+		} else {
+			e.Attrs[prefix+attr.Key] = value.String()
+		}
+	case slog.KindBool:
+		e.Attrs[prefix+attr.Key] = value.Bool()
+	case slog.KindInt64:
+		e.Attrs[prefix+attr.Key] = value.Int64()
+	case slog.KindUint64:
+		e.Attrs[prefix+attr.Key] = value.Uint64()
+	case slog.KindFloat64:
+		e.Attrs[prefix+attr.Key] = value.Float64()
+	default:
+		e.Attrs[prefix+attr.Key] = value.String()
+	}
+}
+
+// component returns the package of the function that logged a record,
+// relative to the app folder, like "reaction/schedule".
+func component(pc uintptr) string {
+	if pc == 0 {
+		return ""
+	}
+
+	frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+
+	// the function is like "github.com/SecurityBrewery/catalyst/app/reaction/schedule.(*Scheduler).run"
+	name := frame.Function
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		if j := strings.Index(name[i:], "."); j >= 0 {
+			name = name[:i+j]
+		}
+	} else if j := strings.Index(name, "."); j >= 0 {
+		name = name[:j]
+	}
+
+	name = strings.TrimPrefix(name, modulePath)
+
+	return strings.TrimPrefix(name, "app/")
+}
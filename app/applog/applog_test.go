@@ -0,0 +1,146 @@
+package applog
+
+import (
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/SecurityBrewery/catalyst/app/auth/usercontext"
+	"github.com/SecurityBrewery/catalyst/app/data"
+	"github.com/SecurityBrewery/catalyst/app/database/sqlc"
+	"github.com/SecurityBrewery/catalyst/app/settings"
+)
+
+func listLogs(t *testing.T, queries *sqlc.Queries, expected int) []sqlc.ListLogsRow {
+	t.Helper()
+
+	var logs []sqlc.ListLogsRow
+
+	require.Eventually(t, func() bool {
+		var err error
+
+		logs, err = queries.ListLogs(t.Context(), sqlc.ListLogsParams{Limit: 100})
+		require.NoError(t, err)
+
+		return len(logs) == expected
+	}, 5*time.Second, 10*time.Millisecond)
+
+	return logs
+}
+
+func TestLog(t *testing.T) {
+	t.Parallel()
+
+	queries := data.NewTestDB(t, t.TempDir())
+
+	l := New(queries)
+	l.Start(t.Context())
+
+	logger := slog.New(l.Handler(slog.DiscardHandler))
+
+	user, err := queries.GetUser(t.Context(), "u_bob_analyst")
+	require.NoError(t, err)
+
+	ctx := usercontext.UserContext(t.Context(), &user)
+
+	logger.InfoContext(ctx, "not stored below the default level")
+	logger.WithGroup("request").ErrorContext(ctx, "Failed to run reaction", "reaction", "r_test", "error", errors.New("timeout"), "status", 502)
+	logger.Warn("Slow query", ComponentKey, "database")
+
+	logs := listLogs(t, queries, 2)
+
+	assert.Equal(t, "Slow query", logs[0].Message)
+	assert.Equal(t, "database", logs[0].Component)
+	assert.Nil(t, logs[0].User)
+	assert.JSONEq(t, `{}`, string(logs[0].Attrs))
+
+	assert.Equal(t, "Failed to run reaction", logs[1].Message)
+	assert.Equal(t, int64(slog.LevelError), logs[1].Level)
+	assert.Equal(t, "applog", logs[1].Component)
+	assert.Equal(t, "u_bob_analyst", *logs[1].User)
+	assert.JSONEq(t, `{"request.reaction": "r_test", "request.error": "timeout", "request.status": 502}`, string(logs[1].Attrs))
+}
+
+func TestLog_subscribe(t *testing.T) {
+	t.Parallel()
+
+	queries := data.NewTestDB(t, t.TempDir())
+
+	l := New(queries)
+	logger := slog.New(l.Handler(slog.DiscardHandler))
+
+	entries, cancel := l.Subscribe()
+
+	// subscribers receive the records below the stored level
+	logger.Debug("Checking feature", "key", "readonly")
+
+	entry := <-entries
+	assert.Equal(t, slog.LevelDebug, entry.Level)
+	assert.Equal(t, "Checking feature", entry.Message)
+	assert.Equal(t, map[string]any{"key": "readonly"}, entry.Attrs)
+	assert.False(t, entry.Critical())
+
+	logger.Error("Failed to stream backup", Critical)
+
+	entry = <-entries
+	assert.True(t, entry.Critical())
+
+	cancel()
+
+	assert.False(t, logger.Enabled(t.Context(), slog.LevelDebug))
+}
+
+func TestLog_cleanup(t *testing.T) {
+	t.Parallel()
+
+	queries := data.NewTestDB(t, t.TempDir())
+
+	_, err := settings.Update(t.Context(), queries, func(s *settings.Settings) {
+		s.Logs = settings.Logs{Level: "debug", RetentionDays: 7}
+	})
+	require.NoError(t, err)
+
+	for _, created := range []time.Time{time.Now().AddDate(0, 0, -8), time.Now().AddDate(0, 0, -6)} {
+		require.NoError(t, queries.CreateLog(t.Context(), sqlc.CreateLogParams{
+			Level:   int64(slog.LevelInfo),
+			Message: "old",
+			Attrs:   []byte("{}"),
+			Created: created.UTC(),
+		}))
+	}
+
+	l := New(queries)
+	l.cleanup(t.Context())
+
+	assert.Equal(t, slog.LevelDebug, l.level.Level())
+
+	logs, err := queries.ListLogs(t.Context(), sqlc.ListLogsParams{Limit: 100})
+	require.NoError(t, err)
+	assert.Len(t, logs, 1)
+}
+
+func TestParseLevel(t *testing.T) {
+	t.Parallel()
+
+	level, err := ParseLevel("")
+	require.NoError(t, err)
+	assert.Equal(t, DefaultLevel, level)
+
+	level, err = ParseLevel("error")
+	require.NoError(t, err)
+	assert.Equal(t, slog.LevelError, level)
+	assert.Equal(t, "error", LevelName(level))
+
+	_, err = ParseLevel("verbose")
+	require.Error(t, err)
+}
+
+func Test_component(t *testing.T) {
+	t.Parallel()
+
+	assert.Empty(t, component(0))
+}
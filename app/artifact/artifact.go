@@ -0,0 +1,280 @@
+// Package artifact adds indicators like domains, IP addresses and hashes to
+// tickets in bulk. Values are canonicalized, so that different spellings of
+// the same indicator are stored once, and each row of the input gets a
+// result, so that invalid rows can be fixed and submitted again.
+//
+// Every time an indicator is seen, in the data of an ingested alert, when the
+// alert is correlated into a ticket or when it is added to a ticket by hand,
+// a sighting is recorded, so that it can be told when it was first and last
+// seen.
+package artifact
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/SecurityBrewery/catalyst/app/canonical"
+	"github.com/SecurityBrewery/catalyst/app/database/sqlc"
+)
+
+// MaxRows is the maximum number of rows of a single bulk add.
+const MaxRows = 1000
+
+var (
+	ErrInvalidInput = errors.New("invalid artifacts")
+	ErrTooManyRows  = fmt.Errorf("%w: more than %d rows", ErrInvalidInput, MaxRows)
+)
+
+type Status string
+
+const (
+	// Added artifacts were new to the ticket.
+	Added Status = "added"
+	// Duplicate artifacts were already on the ticket or earlier in the input.
+	Duplicate Status = "duplicate"
+	// Invalid artifacts have an unknown kind or a value that cannot be
+	// canonicalized.
+	Invalid Status = "invalid"
+)
+
+// The sources of sightings.
+const (
+	// SourceAlert sightings are found in the data of an ingested alert.
+	SourceAlert = "alert"
+	// SourceCorrelation sightings are found in the data of an alert that was
+	// promoted to a ticket.
+	SourceCorrelation = "correlation"
+	// SourceManual sightings were added to a ticket by hand.
+	SourceManual = "manual"
+)
+
+// maxValueLength is the length up to which a string of alert data is checked
+// for an indicator.
+const maxValueLength = 2048
+
+// Row is an artifact of the input. The kind is detected if it is empty.
+type Row struct {
+	Line  int
+	Kind  string
+	Value string
+}
+
+// Result is the outcome of a row.
+type Result struct {
+	// Line is the line of the row in a CSV input, or its position in a list,
+	// starting at 1.
+	Line   int
+	Input  string
+	Kind   string
+	Value  string
+	Status Status
+	Error  string
+}
+
+// ParseCSV reads rows of a value or of a kind and a value, like pasted from
+// a spreadsheet. A header row "kind,value" or "value" is skipped.
+func ParseCSV(data string) ([]Row, error) {
+	reader := csv.NewReader(strings.NewReader(data))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	var rows []Row
+
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidInput, err)
+		}
+
+		line, _ := reader.FieldPos(0)
+
+		if len(rows) == 0 && isHeader(record) {
+			continue
+		}
+
+		row := Row{Line: line}
+
+		switch len(record) {
+		case 1:
+			row.Value = record[0]
+		case 2:
+			row.Kind, row.Value = record[0], record[1]
+		default:
+			return nil, fmt.Errorf("%w: line %d has %d columns, expected the value or the kind and the value", ErrInvalidInput, line, len(record))
+		}
+
+		if strings.TrimSpace(row.Value) == "" {
+			continue
+		}
+
+		rows = append(rows, row)
+
+		if len(rows) > MaxRows {
+			return nil, ErrTooManyRows
+		}
+	}
+
+	return rows, nil
+}
+
+func isHeader(record []string) bool {
+	header := strings.ToLower(strings.Join(record, ","))
+
+	return header == "kind,value" || header == "value"
+}
+
+// Add canonicalizes the rows and adds the new artifacts to the ticket. Every
+// valid row is recorded as a manual sighting, also if the artifact was
+// already on the ticket.
+func Add(ctx context.Context, queries *sqlc.Queries, ticket string, rows []Row) ([]Result, error) {
+	if len(rows) > MaxRows {
+		return nil, ErrTooManyRows
+	}
+
+	if _, err := queries.Ticket(ctx, ticket); err != nil {
+		return nil, err
+	}
+
+	seen := map[string]bool{}
+	results := make([]Result, 0, len(rows))
+
+	for _, row := range rows {
+		result := Result{Line: row.Line, Input: row.Value, Kind: strings.ToLower(strings.TrimSpace(row.Kind))}
+
+		if result.Kind == "" {
+			result.Kind = canonical.Detect(row.Value)
+		}
+
+		value, err := canonical.Canonicalize(result.Kind, row.Value)
+		if err != nil {
+			if result.Kind == "" {
+				err = errors.New("the kind could not be detected")
+			}
+
+			result.Status, result.Error = Invalid, err.Error()
+			results = append(results, result)
+
+			continue
+		}
+
+		result.Value = value
+
+		key := result.Kind + "\x00" + value
+		if seen[key] {
+			result.Status = Duplicate
+			results = append(results, result)
+
+			continue
+		}
+
+		seen[key] = true
+
+		if err := queries.AddArtifactSighting(ctx, sqlc.AddArtifactSightingParams{
+			Kind:   result.Kind,
+			Value:  value,
+			Ticket: &ticket,
+			Source: SourceManual,
+		}); err != nil {
+			return nil, err
+		}
+
+		added, err := queries.AddTicketArtifact(ctx, sqlc.AddTicketArtifactParams{Ticket: ticket, Kind: result.Kind, Value: value})
+		if err != nil {
+			return nil, err
+		}
+
+		result.Status = Added
+		if added == 0 {
+			result.Status = Duplicate
+		}
+
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// Indicator is an artifact found in data, in its canonical form.
+type Indicator struct {
+	Kind  string
+	Value string
+}
+
+// Extract finds the indicators in the string values of JSON data, like the
+// data of an alert. Each indicator is returned once, in the order it was
+// found. Data that is not valid JSON has no indicators.
+func Extract(data []byte) []Indicator {
+	var value any
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil
+	}
+
+	var indicators []Indicator
+
+	seen := map[Indicator]bool{}
+
+	var walk func(value any)
+	walk = func(value any) {
+		switch value := value.(type) {
+		case map[string]any:
+			for _, key := range slices.Sorted(maps.Keys(value)) {
+				walk(value[key])
+			}
+		case []any:
+			for _, item := range value {
+				walk(item)
+			}
+		case string:
+			if len(value) > maxValueLength || len(indicators) >= MaxRows {
+				return
+			}
+
+			kind := canonical.Detect(value)
+			if kind == "" {
+				return
+			}
+
+			canonicalValue, err := canonical.Canonicalize(kind, value)
+			if err != nil {
+				return
+			}
+
+			indicator := Indicator{Kind: kind, Value: canonicalValue}
+			if !seen[indicator] {
+				seen[indicator] = true
+				indicators = append(indicators, indicator)
+			}
+		}
+	}
+
+	walk(value)
+
+	return indicators
+}
+
+// Sight records a sighting of each indicator in the data. The reference
+// names what carried the indicators, like the ID of an alert.
+func Sight(ctx context.Context, queries *sqlc.Queries, ticket *string, source, reference string, data []byte) error {
+	for _, indicator := range Extract(data) {
+		if err := queries.AddArtifactSighting(ctx, sqlc.AddArtifactSightingParams{
+			Kind:      indicator.Kind,
+			Value:     indicator.Value,
+			Ticket:    ticket,
+			Source:    source,
+			Reference: reference,
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,135 @@
+package artifact
+
+import (
+	"database/sql"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/SecurityBrewery/catalyst/app/data"
+	"github.com/SecurityBrewery/catalyst/app/database/sqlc"
+)
+
+func TestParseCSV(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		data    string
+		want    []Row
+		wantErr bool
+	}{
+		{name: "values", data: "10.0.0.1\nexample.com\n", want: []Row{{Line: 1, Value: "10.0.0.1"}, {Line: 2, Value: "example.com"}}},
+		{name: "header", data: "kind,value\nip, 10.0.0.1\n\n,example.com", want: []Row{{Line: 2, Kind: "ip", Value: "10.0.0.1"}, {Line: 4, Value: "example.com"}}},
+		{name: "empty values", data: "ip,\n", want: nil},
+		{name: "too many columns", data: "ip,10.0.0.1,extra", wantErr: true},
+		{name: "too many rows", data: strings.Repeat("10.0.0.1\n", MaxRows+1), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			rows, err := ParseCSV(tt.data)
+			if tt.wantErr {
+				require.ErrorIs(t, err, ErrInvalidInput)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, rows)
+		})
+	}
+}
+
+func TestAdd(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	queries := data.NewTestDB(t, t.TempDir())
+
+	_, err := Add(ctx, queries, "unknown", []Row{{Line: 1, Value: "10.0.0.1"}})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	results, err := Add(ctx, queries, "test-ticket", []Row{
+		{Line: 1, Value: "hxxps://Example.com/a/../b"},
+		{Line: 2, Kind: "IP", Value: "10.0.0.1"},
+		{Line: 3, Value: "https://example.com/b"},
+		{Line: 4, Kind: "ip", Value: "example.com"},
+		{Line: 5, Value: "not an indicator"},
+	})
+	require.NoError(t, err)
+	require.Len(t, results, 5)
+
+	assert.Equal(t, Result{Line: 1, Input: "hxxps://Example.com/a/../b", Kind: "url", Value: "https://example.com/b", Status: Added}, results[0])
+	assert.Equal(t, Added, results[1].Status)
+	assert.Equal(t, Duplicate, results[2].Status)
+	assert.Equal(t, Invalid, results[3].Status)
+	assert.Equal(t, Result{Line: 5, Input: "not an indicator", Status: Invalid, Error: "the kind could not be detected"}, results[4])
+
+	// a second add only finds duplicates
+	results, err = Add(ctx, queries, "test-ticket", []Row{{Line: 1, Value: "10.0.0.1"}})
+	require.NoError(t, err)
+	assert.Equal(t, Duplicate, results[0].Status)
+
+	artifacts, err := queries.ListTicketArtifacts(ctx, "test-ticket")
+	require.NoError(t, err)
+	assert.Len(t, artifacts, 2)
+
+	// every add of a valid row is a sighting, also of a duplicate
+	count, err := queries.CountArtifactSightings(ctx, sqlc.CountArtifactSightingsParams{Kind: "ip", Value: "10.0.0.1"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+}
+
+func TestExtract(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		data string
+		want []Indicator
+	}{
+		{name: "invalid", data: `{`, want: nil},
+		{name: "no indicators", data: `{"user": "bob", "count": 3}`, want: nil},
+		{
+			name: "nested",
+			data: `{"host": {"ip": "10.0.0.1", "names": ["Example.com", "example[.]com"]}, "cve": "cve-2021-44228", "message": "login failed"}`,
+			want: []Indicator{{Kind: "cve", Value: "CVE-2021-44228"}, {Kind: "ip", Value: "10.0.0.1"}, {Kind: "domain", Value: "example.com"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, Extract([]byte(tt.data)))
+		})
+	}
+}
+
+func TestSight(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	queries := data.NewTestDB(t, t.TempDir())
+
+	require.NoError(t, Sight(ctx, queries, nil, SourceAlert, "a-1", []byte(`{"src": "10.0.0.1"}`)))
+
+	ticket := "test-ticket"
+	require.NoError(t, Sight(ctx, queries, &ticket, SourceCorrelation, "a-1", []byte(`{"src": "10.0.0.1"}`)))
+
+	sightings, err := queries.ListArtifactSightings(ctx, sqlc.ListArtifactSightingsParams{Kind: "ip", Value: "10.0.0.1", Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, sightings, 2)
+
+	// the latest first
+	assert.Equal(t, SourceCorrelation, sightings[0].Source)
+	assert.Equal(t, &ticket, sightings[0].Ticket)
+	assert.Equal(t, SourceAlert, sightings[1].Source)
+	assert.Nil(t, sightings[1].Ticket)
+	assert.Equal(t, "a-1", sightings[1].Reference)
+}
@@ -0,0 +1,320 @@
+// Package attack tags tickets and ticket types with the tactics and
+// techniques of the MITRE ATT&CK framework. A catalog of the enterprise
+// tactics and techniques is bundled with Catalyst, it can be replaced with a
+// newer catalog or the STIX bundle published by MITRE.
+package attack
+
+import (
+	"context"
+	"database/sql"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"github.com/SecurityBrewery/catalyst/app/database"
+	"github.com/SecurityBrewery/catalyst/app/database/sqlc"
+	"github.com/SecurityBrewery/catalyst/app/hook"
+	"github.com/SecurityBrewery/catalyst/app/openapi"
+)
+
+// catalogParam is the key of the uploaded catalog in the params table.
+const catalogParam = "attack_catalog"
+
+//go:embed catalog.json
+var bundled []byte
+
+var (
+	ErrInvalidCatalog    = errors.New("invalid ATT&CK catalog")
+	ErrInvalidTechniques = errors.New("invalid ATT&CK techniques")
+)
+
+// Catalog lists the tactics in the order of the ATT&CK matrix and the
+// techniques with the IDs of their tactics.
+type Catalog struct {
+	Version    string      `json:"version"`
+	Tactics    []Tactic    `json:"tactics"`
+	Techniques []Technique `json:"techniques"`
+}
+
+type Tactic struct {
+	ID        string `json:"id"`
+	Shortname string `json:"shortname"`
+	Name      string `json:"name"`
+}
+
+type Technique struct {
+	ID      string   `json:"id"`
+	Name    string   `json:"name"`
+	Tactics []string `json:"tactics"`
+}
+
+// Parent returns the ID of the parent of a sub-technique, e.g. T1566 for
+// T1566.001, or an empty string for techniques.
+func (t Technique) Parent() string {
+	parent, _, found := strings.Cut(t.ID, ".")
+	if !found {
+		return ""
+	}
+
+	return parent
+}
+
+// Tag is a technique used in a ticket for one of its tactics.
+type Tag struct {
+	Technique string `json:"technique"`
+	Tactic    string `json:"tactic"`
+}
+
+// Bundled returns the catalog that is shipped with Catalyst.
+func Bundled() *Catalog {
+	catalog, err := Parse(bundled)
+	if err != nil {
+		panic(fmt.Sprintf("invalid bundled ATT&CK catalog: %v", err))
+	}
+
+	return catalog
+}
+
+// Load returns the uploaded catalog or the bundled one.
+func Load(ctx context.Context, queries *sqlc.Queries) (*Catalog, error) {
+	param, err := queries.Param(ctx, catalogParam)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Bundled(), nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return Parse(param.Value)
+}
+
+// Save replaces the catalog. Tags of techniques that are missing in the new
+// catalog are kept, but not shown in the matrix.
+func Save(ctx context.Context, queries *sqlc.Queries, catalog *Catalog) error {
+	b, err := json.Marshal(catalog)
+	if err != nil {
+		return err
+	}
+
+	_, err = queries.Param(ctx, catalogParam)
+	if errors.Is(err, sql.ErrNoRows) {
+		return queries.CreateParam(ctx, sqlc.CreateParamParams{Key: catalogParam, Value: b})
+	} else if err != nil {
+		return err
+	}
+
+	return queries.UpdateParam(ctx, sqlc.UpdateParamParams{Key: catalogParam, Value: b})
+}
+
+// Parse reads a catalog in the format of the bundled catalog or a STIX
+// bundle like enterprise-attack.json of the MITRE ATT&CK repository.
+func Parse(data []byte) (*Catalog, error) {
+	var header struct {
+		Type string `json:"type"`
+	}
+
+	if err := json.Unmarshal(data, &header); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidCatalog, err)
+	}
+
+	var catalog *Catalog
+
+	if header.Type == "bundle" {
+		c, err := parseSTIX(data)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidCatalog, err)
+		}
+
+		catalog = c
+	} else if err := json.Unmarshal(data, &catalog); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidCatalog, err)
+	}
+
+	if err := catalog.validate(); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidCatalog, err)
+	}
+
+	return catalog, nil
+}
+
+func (c *Catalog) validate() error {
+	if len(c.Tactics) == 0 {
+		return errors.New("no tactics")
+	}
+
+	for i, tactic := range c.Tactics {
+		if tactic.ID == "" || tactic.Name == "" {
+			return fmt.Errorf("tactic %d has no ID or name", i+1)
+		}
+
+		if slices.IndexFunc(c.Tactics[:i], func(t Tactic) bool { return t.ID == tactic.ID }) != -1 {
+			return fmt.Errorf("duplicate tactic %s", tactic.ID)
+		}
+	}
+
+	ids := map[string]bool{}
+
+	for i, technique := range c.Techniques {
+		if technique.ID == "" || technique.Name == "" {
+			return fmt.Errorf("technique %d has no ID or name", i+1)
+		}
+
+		if ids[technique.ID] {
+			return fmt.Errorf("duplicate technique %s", technique.ID)
+		}
+
+		ids[technique.ID] = true
+
+		if len(technique.Tactics) == 0 {
+			return fmt.Errorf("technique %s has no tactics", technique.ID)
+		}
+
+		for _, tactic := range technique.Tactics {
+			if _, ok := c.Tactic(tactic); !ok {
+				return fmt.Errorf("technique %s has the unknown tactic %s", technique.ID, tactic)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (c *Catalog) Tactic(id string) (Tactic, bool) {
+	i := slices.IndexFunc(c.Tactics, func(t Tactic) bool { return t.ID == id })
+	if i == -1 {
+		return Tactic{}, false
+	}
+
+	return c.Tactics[i], true
+}
+
+func (c *Catalog) Technique(id string) (Technique, bool) {
+	i := slices.IndexFunc(c.Techniques, func(t Technique) bool { return t.ID == id })
+	if i == -1 {
+		return Technique{}, false
+	}
+
+	return c.Techniques[i], true
+}
+
+// Resolve checks the tags against the catalog. A tag without a tactic is
+// expanded to all tactics of its technique. Duplicates are removed.
+func (c *Catalog) Resolve(tags []Tag) ([]Tag, error) {
+	resolved := []Tag{}
+
+	add := func(tag Tag) {
+		if !slices.Contains(resolved, tag) {
+			resolved = append(resolved, tag)
+		}
+	}
+
+	for _, tag := range tags {
+		technique, ok := c.Technique(strings.ToUpper(strings.TrimSpace(tag.Technique)))
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown technique %q", ErrInvalidTechniques, tag.Technique)
+		}
+
+		if tag.Tactic == "" {
+			for _, tactic := range technique.Tactics {
+				add(Tag{Technique: technique.ID, Tactic: tactic})
+			}
+
+			continue
+		}
+
+		if !slices.Contains(technique.Tactics, tag.Tactic) {
+			return nil, fmt.Errorf("%w: technique %s is not used for tactic %q", ErrInvalidTechniques, technique.ID, tag.Tactic)
+		}
+
+		add(Tag{Technique: technique.ID, Tactic: tag.Tactic})
+	}
+
+	return resolved, nil
+}
+
+// SetTicketTechniques replaces the techniques of a ticket.
+func SetTicketTechniques(ctx context.Context, queries *sqlc.Queries, ticket string, tags []Tag) error {
+	catalog, err := Load(ctx, queries)
+	if err != nil {
+		return err
+	}
+
+	tags, err = catalog.Resolve(tags)
+	if err != nil {
+		return err
+	}
+
+	if err := queries.DeleteTicketTechniques(ctx, ticket); err != nil {
+		return err
+	}
+
+	for _, tag := range tags {
+		if err := queries.AddTicketTechnique(ctx, sqlc.AddTicketTechniqueParams{Ticket: ticket, Technique: tag.Technique, Tactic: tag.Tactic}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// SetTypeTechniques replaces the techniques of a ticket type, they are
+// added to each new ticket of the type.
+func SetTypeTechniques(ctx context.Context, queries *sqlc.Queries, typ string, tags []Tag) error {
+	catalog, err := Load(ctx, queries)
+	if err != nil {
+		return err
+	}
+
+	tags, err = catalog.Resolve(tags)
+	if err != nil {
+		return err
+	}
+
+	if err := queries.DeleteTypeTechniques(ctx, typ); err != nil {
+		return err
+	}
+
+	for _, tag := range tags {
+		if err := queries.AddTypeTechnique(ctx, sqlc.AddTypeTechniqueParams{Type: typ, Technique: tag.Technique, Tactic: tag.Tactic}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// BindHooks adds the techniques of the ticket type to every new ticket.
+func BindHooks(hooks *hook.Hooks, queries *sqlc.Queries) {
+	hooks.OnRecordAfterCreateRequest.Subscribe(func(ctx context.Context, table string, record any) {
+		if table != database.TicketsTable.ID {
+			return
+		}
+
+		ticket, ok := record.(openapi.Ticket)
+		if !ok {
+			return
+		}
+
+		if err := copyTypeTechniques(ctx, queries, ticket.Id, ticket.Type); err != nil {
+			slog.ErrorContext(ctx, "Failed to add the techniques of the ticket type", "ticket", ticket.Id, "error", err)
+		}
+	})
+}
+
+func copyTypeTechniques(ctx context.Context, queries *sqlc.Queries, ticket, typ string) error {
+	techniques, err := queries.ListTypeTechniques(ctx, typ)
+	if err != nil {
+		return err
+	}
+
+	for _, technique := range techniques {
+		if err := queries.AddTicketTechnique(ctx, sqlc.AddTicketTechniqueParams{Ticket: ticket, Technique: technique.Technique, Tactic: technique.Tactic}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
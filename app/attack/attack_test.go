@@ -0,0 +1,174 @@
+package attack
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/SecurityBrewery/catalyst/app/data"
+	"github.com/SecurityBrewery/catalyst/app/database"
+	"github.com/SecurityBrewery/catalyst/app/database/sqlc"
+	"github.com/SecurityBrewery/catalyst/app/hook"
+	"github.com/SecurityBrewery/catalyst/app/openapi"
+)
+
+const enterpriseBundle = `{
+	"type": "bundle",
+	"objects": [
+		{"type": "x-mitre-collection", "id": "x-mitre-collection--1", "x_mitre_version": "16.1"},
+		{"type": "x-mitre-matrix", "id": "x-mitre-matrix--1", "tactic_refs": ["x-mitre-tactic--2", "x-mitre-tactic--1"]},
+		{"type": "x-mitre-tactic", "id": "x-mitre-tactic--1", "name": "Execution", "x_mitre_shortname": "execution", "external_references": [{"source_name": "mitre-attack", "external_id": "TA0002"}]},
+		{"type": "x-mitre-tactic", "id": "x-mitre-tactic--2", "name": "Initial Access", "x_mitre_shortname": "initial-access", "external_references": [{"source_name": "mitre-attack", "external_id": "TA0001"}]},
+		{"type": "attack-pattern", "name": "Phishing", "kill_chain_phases": [{"kill_chain_name": "mitre-attack", "phase_name": "initial-access"}], "external_references": [{"source_name": "mitre-attack", "external_id": "T1566"}]},
+		{"type": "attack-pattern", "name": "User Execution", "kill_chain_phases": [{"kill_chain_name": "mitre-attack", "phase_name": "execution"}], "external_references": [{"source_name": "mitre-attack", "external_id": "T1204"}]},
+		{"type": "attack-pattern", "name": "Old Technique", "revoked": true, "kill_chain_phases": [{"kill_chain_name": "mitre-attack", "phase_name": "execution"}], "external_references": [{"source_name": "mitre-attack", "external_id": "T1000"}]}
+	]
+}`
+
+func TestBundled(t *testing.T) {
+	t.Parallel()
+
+	catalog := Bundled()
+
+	assert.Len(t, catalog.Tactics, 14)
+	assert.Equal(t, "TA0043", catalog.Tactics[0].ID)
+
+	technique, ok := catalog.Technique("T1566.001")
+	require.True(t, ok)
+	assert.Equal(t, "T1566", technique.Parent())
+}
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	catalog, err := Parse([]byte(enterpriseBundle))
+	require.NoError(t, err)
+
+	assert.Equal(t, &Catalog{
+		Version: "16.1",
+		Tactics: []Tactic{
+			{ID: "TA0001", Shortname: "initial-access", Name: "Initial Access"},
+			{ID: "TA0002", Shortname: "execution", Name: "Execution"},
+		},
+		Techniques: []Technique{
+			{ID: "T1204", Name: "User Execution", Tactics: []string{"TA0002"}},
+			{ID: "T1566", Name: "Phishing", Tactics: []string{"TA0001"}},
+		},
+	}, catalog)
+
+	_, err = Parse([]byte(`{"version": "1", "tactics": [{"id": "TA0001", "name": "Initial Access"}], "techniques": [{"id": "T1204", "name": "User Execution", "tactics": ["TA0002"]}]}`))
+	require.ErrorIs(t, err, ErrInvalidCatalog)
+	assert.ErrorContains(t, err, "unknown tactic TA0002")
+
+	_, err = Parse([]byte(`{"type": "bundle", "objects": []}`))
+	require.ErrorIs(t, err, ErrInvalidCatalog)
+}
+
+func TestCatalog_Resolve(t *testing.T) {
+	t.Parallel()
+
+	catalog := Bundled()
+
+	tags, err := catalog.Resolve([]Tag{{Technique: "t1078"}, {Technique: "T1566.001", Tactic: "TA0001"}, {Technique: "T1078", Tactic: "TA0001"}})
+	require.NoError(t, err)
+	assert.Equal(t, []Tag{
+		{Technique: "T1078", Tactic: "TA0001"},
+		{Technique: "T1078", Tactic: "TA0003"},
+		{Technique: "T1078", Tactic: "TA0004"},
+		{Technique: "T1078", Tactic: "TA0005"},
+		{Technique: "T1566.001", Tactic: "TA0001"},
+	}, tags)
+
+	_, err = catalog.Resolve([]Tag{{Technique: "T9999"}})
+	require.ErrorIs(t, err, ErrInvalidTechniques)
+
+	_, err = catalog.Resolve([]Tag{{Technique: "T1566", Tactic: "TA0040"}})
+	require.ErrorIs(t, err, ErrInvalidTechniques)
+}
+
+func TestCatalog_Matrix(t *testing.T) {
+	t.Parallel()
+
+	matrix := Bundled().Matrix([]sqlc.TicketTechnique{
+		{Ticket: "a", Technique: "T1566.001", Tactic: "TA0001"},
+		{Ticket: "a", Technique: "T1566", Tactic: "TA0001"},
+		{Ticket: "b", Technique: "T1566.002", Tactic: "TA0001"},
+		{Ticket: "b", Technique: "T1486", Tactic: "TA0040"},
+		{Ticket: "c", Technique: "T9999", Tactic: "TA0040"},
+	})
+
+	assert.Equal(t, 2, matrix.Tickets)
+
+	var initialAccess MatrixTactic
+
+	for _, tactic := range matrix.Tactics {
+		if tactic.ID == "TA0001" {
+			initialAccess = tactic
+		}
+	}
+
+	assert.Equal(t, 2, initialAccess.Tickets)
+
+	for _, technique := range initialAccess.Techniques {
+		if technique.ID == "T1566" {
+			assert.Equal(t, 2, technique.Tickets)
+			assert.Equal(t, []MatrixTechnique{
+				{ID: "T1566.001", Name: "Spearphishing Attachment", Tickets: 1},
+				{ID: "T1566.002", Name: "Spearphishing Link", Tickets: 1},
+			}, technique.Subtechniques)
+		}
+	}
+}
+
+func TestSave(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	queries := data.NewTestDB(t, t.TempDir())
+
+	catalog, err := Load(ctx, queries)
+	require.NoError(t, err)
+	assert.Equal(t, "bundled", catalog.Version)
+
+	err = SetTicketTechniques(ctx, queries, "test-ticket", []Tag{{Technique: "T1204"}})
+	require.NoError(t, err)
+
+	updated, err := Parse([]byte(enterpriseBundle))
+	require.NoError(t, err)
+	require.NoError(t, Save(ctx, queries, updated))
+
+	catalog, err = Load(ctx, queries)
+	require.NoError(t, err)
+	assert.Equal(t, updated, catalog)
+
+	// techniques are checked against the uploaded catalog
+	err = SetTicketTechniques(ctx, queries, "test-ticket", []Tag{{Technique: "T1486"}})
+	require.ErrorIs(t, err, ErrInvalidTechniques)
+
+	techniques, err := queries.ListTicketTechniques(ctx, "test-ticket")
+	require.NoError(t, err)
+	require.Len(t, techniques, 1)
+	assert.Equal(t, "T1204", techniques[0].Technique)
+}
+
+func TestBindHooks(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	queries := data.NewTestDB(t, t.TempDir())
+	hooks := hook.NewHooks()
+
+	BindHooks(hooks, queries)
+
+	require.NoError(t, SetTypeTechniques(ctx, queries, "incident", []Tag{{Technique: "T1566"}}))
+
+	ticket, err := queries.CreateTicket(ctx, sqlc.CreateTicketParams{Name: "Phishing", Open: true, Type: "incident", Schema: []byte(`{}`), State: []byte(`{}`)})
+	require.NoError(t, err)
+
+	hooks.OnRecordAfterCreateRequest.Publish(ctx, database.TicketsTable.ID, openapi.Ticket{Id: ticket.ID, Type: ticket.Type})
+
+	techniques, err := queries.ListTicketTechniques(ctx, ticket.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []sqlc.TicketTechnique{{Ticket: ticket.ID, Technique: "T1566", Tactic: "TA0001"}}, techniques)
+}
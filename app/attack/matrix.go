@@ -0,0 +1,115 @@
+package attack
+
+import (
+	"slices"
+
+	"github.com/SecurityBrewery/catalyst/app/database/sqlc"
+)
+
+// Matrix counts the tickets per tactic and technique. It contains all
+// tactics and techniques of the catalog, so it can be shown as a heatmap.
+type Matrix struct {
+	Version string
+	Tickets int
+	Tactics []MatrixTactic
+}
+
+type MatrixTactic struct {
+	Tactic
+	Tickets    int
+	Techniques []MatrixTechnique
+}
+
+// MatrixTechnique is a cell of the matrix. The tickets of a technique
+// include the tickets of its sub-techniques.
+type MatrixTechnique struct {
+	ID            string
+	Name          string
+	Tickets       int
+	Subtechniques []MatrixTechnique
+}
+
+// Matrix aggregates the techniques of tickets. Each ticket is counted once
+// per cell, tags of techniques that are not in the catalog are ignored.
+func (c *Catalog) Matrix(tags []sqlc.TicketTechnique) Matrix {
+	type cell struct{ tactic, technique string }
+
+	// the tickets per cell, the cells without technique hold the tickets
+	// of the whole tactic
+	cells := map[cell]map[string]bool{}
+	tickets := map[string]bool{}
+
+	add := func(key cell, ticket string) {
+		if cells[key] == nil {
+			cells[key] = map[string]bool{}
+		}
+
+		cells[key][ticket] = true
+	}
+
+	for _, tag := range tags {
+		technique, ok := c.Technique(tag.Technique)
+		if !ok || !slices.Contains(technique.Tactics, tag.Tactic) {
+			continue
+		}
+
+		add(cell{tag.Tactic, ""}, tag.Ticket)
+		add(cell{tag.Tactic, technique.ID}, tag.Ticket)
+
+		if parent := technique.Parent(); parent != "" {
+			add(cell{tag.Tactic, parent}, tag.Ticket)
+		}
+
+		tickets[tag.Ticket] = true
+	}
+
+	subtechniques := map[string][]Technique{}
+
+	for _, technique := range c.Techniques {
+		if parent := technique.Parent(); parent != "" {
+			subtechniques[parent] = append(subtechniques[parent], technique)
+		}
+	}
+
+	matrix := Matrix{
+		Version: c.Version,
+		Tickets: len(tickets),
+		Tactics: make([]MatrixTactic, 0, len(c.Tactics)),
+	}
+
+	for _, tactic := range c.Tactics {
+		column := MatrixTactic{
+			Tactic:     tactic,
+			Tickets:    len(cells[cell{tactic.ID, ""}]),
+			Techniques: []MatrixTechnique{},
+		}
+
+		for _, technique := range c.Techniques {
+			if technique.Parent() != "" || !slices.Contains(technique.Tactics, tactic.ID) {
+				continue
+			}
+
+			entry := MatrixTechnique{
+				ID:      technique.ID,
+				Name:    technique.Name,
+				Tickets: len(cells[cell{tactic.ID, technique.ID}]),
+			}
+
+			for _, sub := range subtechniques[technique.ID] {
+				if slices.Contains(sub.Tactics, tactic.ID) {
+					entry.Subtechniques = append(entry.Subtechniques, MatrixTechnique{
+						ID:      sub.ID,
+						Name:    sub.Name,
+						Tickets: len(cells[cell{tactic.ID, sub.ID}]),
+					})
+				}
+			}
+
+			column.Techniques = append(column.Techniques, entry)
+		}
+
+		matrix.Tactics = append(matrix.Tactics, column)
+	}
+
+	return matrix
+}
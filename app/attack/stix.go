@@ -0,0 +1,132 @@
+package attack
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"slices"
+)
+
+type stixBundle struct {
+	Objects []stixObject `json:"objects"`
+}
+
+type stixObject struct {
+	ID                 string          `json:"id"`
+	Type               string          `json:"type"`
+	Name               string          `json:"name"`
+	Revoked            bool            `json:"revoked"`
+	Deprecated         bool            `json:"x_mitre_deprecated"`
+	Version            string          `json:"x_mitre_version"`
+	Shortname          string          `json:"x_mitre_shortname"`
+	TacticRefs         []string        `json:"tactic_refs"`
+	KillChainPhases    []stixPhase     `json:"kill_chain_phases"`
+	ExternalReferences []stixReference `json:"external_references"`
+}
+
+type stixPhase struct {
+	KillChainName string `json:"kill_chain_name"`
+	PhaseName     string `json:"phase_name"`
+}
+
+type stixReference struct {
+	SourceName string `json:"source_name"`
+	ExternalID string `json:"external_id"`
+}
+
+func (o stixObject) externalID() string {
+	for _, reference := range o.ExternalReferences {
+		if reference.SourceName == "mitre-attack" {
+			return reference.ExternalID
+		}
+	}
+
+	return ""
+}
+
+// parseSTIX converts a STIX bundle of the ATT&CK framework. The tactics are
+// ordered like the matrix of the bundle, revoked and deprecated objects are
+// skipped.
+func parseSTIX(data []byte) (*Catalog, error) {
+	var bundle stixBundle
+	if err := json.Unmarshal(data, &bundle); err != nil {
+		return nil, err
+	}
+
+	catalog := &Catalog{Version: "stix"}
+
+	var order []string
+
+	tactics := map[string]stixObject{}
+
+	for _, object := range bundle.Objects {
+		if object.Revoked || object.Deprecated {
+			continue
+		}
+
+		switch object.Type {
+		case "x-mitre-collection":
+			if object.Version != "" {
+				catalog.Version = object.Version
+			}
+		case "x-mitre-matrix":
+			if order == nil {
+				order = object.TacticRefs
+			}
+		case "x-mitre-tactic":
+			tactics[object.ID] = object
+		}
+	}
+
+	if order == nil {
+		for _, object := range bundle.Objects {
+			if _, ok := tactics[object.ID]; ok {
+				order = append(order, object.ID)
+			}
+		}
+	}
+
+	shortnames := map[string]string{}
+
+	for _, ref := range order {
+		tactic, ok := tactics[ref]
+		if !ok {
+			continue
+		}
+
+		id := tactic.externalID()
+		shortnames[tactic.Shortname] = id
+		catalog.Tactics = append(catalog.Tactics, Tactic{ID: id, Shortname: tactic.Shortname, Name: tactic.Name})
+	}
+
+	for _, object := range bundle.Objects {
+		if object.Type != "attack-pattern" || object.Revoked || object.Deprecated {
+			continue
+		}
+
+		technique := Technique{ID: object.externalID(), Name: object.Name}
+
+		for _, phase := range object.KillChainPhases {
+			tactic, ok := shortnames[phase.PhaseName]
+			if phase.KillChainName == "mitre-attack" && ok && !slices.Contains(technique.Tactics, tactic) {
+				technique.Tactics = append(technique.Tactics, tactic)
+			}
+		}
+
+		if technique.ID == "" || len(technique.Tactics) == 0 {
+			continue
+		}
+
+		catalog.Techniques = append(catalog.Techniques, technique)
+	}
+
+	if len(catalog.Techniques) == 0 {
+		return nil, errors.New("the bundle contains no ATT&CK techniques")
+	}
+
+	slices.SortStableFunc(catalog.Techniques, func(a, b Technique) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+
+	return catalog, nil
+}
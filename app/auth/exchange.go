@@ -0,0 +1,135 @@
+package auth
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/SecurityBrewery/catalyst/app/database/sqlc"
+)
+
+const (
+	grantTypeTokenExchange = "urn:ietf:params:oauth:grant-type:token-exchange"
+	tokenTypeAccessToken   = "urn:ietf:params:oauth:token-type:access_token"
+
+	// exchangeTokenDuration is the lifetime of exchanged tokens, long enough
+	// for a job and short enough to be of little use when leaked.
+	exchangeTokenDuration = 15 * time.Minute
+)
+
+// handleTokenExchange exchanges an access token for a short-lived token of
+// an automation, following RFC 8693. Workers request tokens with only the
+// scopes they need, optionally restricted to a single ticket, instead of
+// sharing a long-lived token. The form parameters "automation", the ID of a
+// reaction, and "ticket" select the actor and the ticket of the token.
+func handleTokenExchange(queries *sqlc.Queries) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			errorJSON(w, http.StatusBadRequest, "Invalid request")
+
+			return
+		}
+
+		if r.PostForm.Get("grant_type") != grantTypeTokenExchange {
+			errorJSON(w, http.StatusBadRequest, "Unsupported grant type")
+
+			return
+		}
+
+		if r.PostForm.Get("subject_token_type") != tokenTypeAccessToken {
+			errorJSON(w, http.StatusBadRequest, "Unsupported subject token type")
+
+			return
+		}
+
+		user, claims, err := verifyAccessToken(r.Context(), r.PostForm.Get("subject_token"), queries)
+		if err != nil {
+			unauthorizedJSON(w, "invalid subject token")
+
+			return
+		}
+
+		subjectScopes, err := scopes(claims)
+		if err != nil {
+			unauthorizedJSON(w, "failed to get scopes")
+
+			return
+		}
+
+		requestedScopes := strings.Fields(r.PostForm.Get("scope"))
+		if len(requestedScopes) == 0 {
+			errorJSON(w, http.StatusBadRequest, "The scope is required")
+
+			return
+		}
+
+		if !HasScopes(subjectScopes, requestedScopes) {
+			errorJSON(w, http.StatusBadRequest, "The scope exceeds the scopes of the subject token")
+
+			return
+		}
+
+		automation := r.PostForm.Get("automation")
+		if automation == "" {
+			errorJSON(w, http.StatusBadRequest, "The automation is required")
+
+			return
+		}
+
+		if _, err := queries.GetReaction(r.Context(), automation); errors.Is(err, sql.ErrNoRows) {
+			errorJSON(w, http.StatusBadRequest, "Unknown automation")
+
+			return
+		} else if err != nil {
+			errorJSON(w, http.StatusInternalServerError, "Failed to get automation")
+
+			return
+		}
+
+		// a token restricted to a ticket cannot be exchanged for another one
+		ticket := r.PostForm.Get("ticket")
+		if bound := boundTicket(claims); bound != "" {
+			if ticket != "" && ticket != bound {
+				errorJSON(w, http.StatusBadRequest, "The subject token is restricted to another ticket")
+
+				return
+			}
+
+			ticket = bound
+		}
+
+		if ticket != "" {
+			if _, err := queries.Ticket(r.Context(), ticket); errors.Is(err, sql.ErrNoRows) {
+				errorJSON(w, http.StatusBadRequest, "Unknown ticket")
+
+				return
+			} else if err != nil {
+				errorJSON(w, http.StatusInternalServerError, "Failed to get ticket")
+
+				return
+			}
+		}
+
+		token, err := CreateAutomationToken(r.Context(), user, requestedScopes, exchangeTokenDuration, automation, ticket, queries)
+		if err != nil {
+			errorJSON(w, http.StatusInternalServerError, "Failed to create token")
+
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		w.WriteHeader(http.StatusOK)
+
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"access_token":      token,
+			"issued_token_type": tokenTypeAccessToken,
+			"token_type":        "Bearer",
+			"expires_in":        int(exchangeTokenDuration.Seconds()),
+			"scope":             strings.Join(requestedScopes, " "),
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/SecurityBrewery/catalyst/app/auth/password"
+	"github.com/SecurityBrewery/catalyst/app/database/sqlc"
+	"github.com/SecurityBrewery/catalyst/app/invitation"
+)
+
+func handleAcceptInvitation(queries *sqlc.Queries) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		type acceptInvitationData struct {
+			Token           string `json:"token"`
+			Username        string `json:"username"`
+			Name            string `json:"name"`
+			Password        string `json:"password"`
+			PasswordConfirm string `json:"password_confirm"`
+		}
+
+		var data acceptInvitationData
+		if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
+			errorJSON(w, http.StatusBadRequest, "Invalid request, missing token, username or password fields")
+
+			return
+		}
+
+		if data.Password != data.PasswordConfirm {
+			errorJSON(w, http.StatusBadRequest, "Passwords do not match")
+
+			return
+		}
+
+		if data.Username == "" {
+			errorJSON(w, http.StatusBadRequest, "Invalid request, missing username")
+
+			return
+		}
+
+		user, err := invitation.Accept(r.Context(), queries, data.Token, data.Username, data.Name, data.Password)
+		if err != nil {
+			if errors.Is(err, invitation.ErrInvalid) || errors.Is(err, invitation.ErrUserExists) || errors.Is(err, password.ErrPolicy) {
+				errorJSON(w, http.StatusBadRequest, err.Error())
+
+				return
+			}
+
+			errorJSON(w, http.StatusInternalServerError, "Failed to accept invitation: "+err.Error())
+
+			return
+		}
+
+		b, err := json.Marshal(map[string]any{
+			"message":  "Invitation accepted, you can now log in",
+			"username": user.Username,
+		})
+		if err != nil {
+			errorJSON(w, http.StatusInternalServerError, "Failed to create response: "+err.Error())
+
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(b)
+	}
+}
@@ -1,9 +1,12 @@
 package auth
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"log/slog"
 	"net/http"
 	"slices"
@@ -21,7 +24,7 @@ const bearerPrefix = "Bearer "
 func Middleware(queries *sqlc.Queries) func(next http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			if r.URL.Path == "/api/config" {
+			if isPublicPath(r) {
 				next.ServeHTTP(w, r)
 
 				return
@@ -30,7 +33,16 @@ func Middleware(queries *sqlc.Queries) func(next http.Handler) http.Handler {
 			authorizationHeader := r.Header.Get("Authorization")
 			bearerToken := strings.TrimPrefix(authorizationHeader, bearerPrefix)
 
-			user, claims, err := verifyAccessToken(r.Context(), bearerToken, queries)
+			verify := verifyAccessToken
+
+			// browsers cannot set the header of a WebSocket
+			if authorizationHeader == "" && isWebSocket(r) && r.URL.Query().Has(SocketTokenParam) {
+				bearerToken = r.URL.Query().Get(SocketTokenParam)
+				verify = verifySocketToken
+				r = r.WithContext(context.WithValue(r.Context(), queryCredentialsKey{}, true))
+			}
+
+			user, claims, err := verify(r.Context(), bearerToken, queries)
 			if err != nil {
 				slog.ErrorContext(r.Context(), "invalid bearer token", "error", err)
 
@@ -48,15 +60,78 @@ func Middleware(queries *sqlc.Queries) func(next http.Handler) http.Handler {
 				return
 			}
 
+			if ticket := boundTicket(claims); ticket != "" && !ticketRequest(r, ticket) {
+				slog.WarnContext(r.Context(), "ticket bound token used for another request", "ticket", ticket, "automation", actor(claims), "path", r.URL.Path)
+
+				errorJSON(w, http.StatusForbidden, "token is restricted to ticket "+ticket)
+
+				return
+			}
+
 			// Set the user in the context
 			r = usercontext.UserRequest(r, user)
 			r = usercontext.PermissionRequest(r, scopes)
 
+			if act := actor(claims); act != "" {
+				r = usercontext.ActorRequest(r, act)
+			}
+
 			next.ServeHTTP(w, r)
 		})
 	}
 }
 
+func isPublicPath(r *http.Request) bool {
+	switch r.URL.Path {
+	case "/api/config", "/api/intake":
+		return true
+	case "/api/branding":
+		return r.Method == http.MethodGet
+	default:
+		return false
+	}
+}
+
+// maxTicketBodySize limits the request bodies that are read to check the
+// ticket of new records.
+const maxTicketBodySize = 1 << 20
+
+// ticketRequest reports whether a request only concerns the ticket: the
+// ticket itself and its sub-resources, lists of records filtered by the
+// ticket, and new records of the ticket.
+func ticketRequest(r *http.Request, ticket string) bool {
+	prefix := "/api/tickets/" + ticket
+	if r.URL.Path == prefix || strings.HasPrefix(r.URL.Path, prefix+"/") {
+		return true
+	}
+
+	switch r.URL.Path {
+	case "/api/comments", "/api/tasks", "/api/timeline", "/api/links", "/api/files":
+	default:
+		return false
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		return r.URL.Query().Get("ticket") == ticket
+	case http.MethodPost:
+		body, err := io.ReadAll(io.LimitReader(r.Body, maxTicketBodySize))
+		if err != nil {
+			return false
+		}
+
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		var record struct {
+			Ticket string `json:"ticket"`
+		}
+
+		return json.Unmarshal(body, &record) == nil && record.Ticket == ticket
+	default:
+		return false
+	}
+}
+
 func ValidateFileScopes(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		requiredScopes := []string{"file:read"}
@@ -75,6 +150,22 @@ func ValidateFileScopes(next http.Handler) http.Handler {
 	})
 }
 
+// RequireScopes rejects requests whose token lacks one of the scopes.
+func RequireScopes(requiredScopes []string) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if err := validateScopes(r.Context(), requiredScopes); err != nil {
+				slog.ErrorContext(r.Context(), "failed to validate scopes", "error", err)
+				unauthorizedJSON(w, "missing required scopes")
+
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
 func ValidateScopesStrict(next strictnethttp.StrictHTTPHandlerFunc, _ string) strictnethttp.StrictHTTPHandlerFunc {
 	return func(ctx context.Context, w http.ResponseWriter, r *http.Request, request any) (response any, err error) {
 		requiredScopes, err := requiredScopes(ctx)
@@ -120,7 +211,7 @@ func validateScopes(ctx context.Context, requiredScopes []string) error {
 			return errors.New("missing permissions")
 		}
 
-		if !hasScope(permissions, requiredScopes) {
+		if !HasScopes(permissions, requiredScopes) {
 			return fmt.Errorf("missing required scopes: %v", requiredScopes)
 		}
 	}
@@ -142,7 +233,9 @@ func requiredScopes(ctx context.Context) ([]string, error) {
 	return requiredScopes, nil
 }
 
-func hasScope(scopes []string, requiredScopes []string) bool {
+// HasScopes reports whether the scopes include all required scopes. The
+// "admin" scope grants every scope.
+func HasScopes(scopes []string, requiredScopes []string) bool {
 	if slices.Contains(scopes, "admin") {
 		// If the user has admin scope, they can access everything
 		return true
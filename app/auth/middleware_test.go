@@ -96,7 +96,7 @@ func TestService_ValidateScopes(t *testing.T) {
 	}
 }
 
-func Test_hasScope(t *testing.T) {
+func Test_HasScopes(t *testing.T) {
 	t.Parallel()
 
 	type args struct {
@@ -137,7 +137,7 @@ func Test_hasScope(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
 
-			assert.Equalf(t, tt.want, hasScope(tt.args.scopes, tt.args.requiredScopes), "hasScope(%v, %v)", tt.args.scopes, tt.args.requiredScopes)
+			assert.Equalf(t, tt.want, HasScopes(tt.args.scopes, tt.args.requiredScopes), "HasScopes(%v, %v)", tt.args.scopes, tt.args.requiredScopes)
 		})
 	}
 }
@@ -186,3 +186,61 @@ func Test_requiredScopes(t *testing.T) {
 		})
 	}
 }
+
+func Test_ticketRequest(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		r    *http.Request
+		want bool
+	}{
+		{
+			name: "ticket",
+			r:    httptest.NewRequest(http.MethodGet, "/api/tickets/t1", nil),
+			want: true,
+		},
+		{
+			name: "ticket sub-resource",
+			r:    httptest.NewRequest(http.MethodPost, "/api/tickets/t1/work/start", nil),
+			want: true,
+		},
+		{
+			name: "other ticket",
+			r:    httptest.NewRequest(http.MethodGet, "/api/tickets/t10", nil),
+			want: false,
+		},
+		{
+			name: "filtered list",
+			r:    httptest.NewRequest(http.MethodGet, "/api/comments?ticket=t1", nil),
+			want: true,
+		},
+		{
+			name: "unfiltered list",
+			r:    httptest.NewRequest(http.MethodGet, "/api/comments", nil),
+			want: false,
+		},
+		{
+			name: "new record of the ticket",
+			r:    httptest.NewRequest(http.MethodPost, "/api/tasks", bytes.NewBufferString(`{"ticket":"t1","name":"x"}`)),
+			want: true,
+		},
+		{
+			name: "new record of another ticket",
+			r:    httptest.NewRequest(http.MethodPost, "/api/tasks", bytes.NewBufferString(`{"ticket":"t2","name":"x"}`)),
+			want: false,
+		},
+		{
+			name: "other resource",
+			r:    httptest.NewRequest(http.MethodGet, "/api/users?ticket=t1", nil),
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, ticketRequest(tt.r, "t1"))
+		})
+	}
+}
@@ -1,15 +1,45 @@
 package password
 
 import (
+	"crypto/pbkdf2"
 	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
 	"encoding/base64"
+	"errors"
 	"fmt"
+	"strconv"
+	"strings"
 
 	"golang.org/x/crypto/bcrypt"
+
+	"github.com/SecurityBrewery/catalyst/app/fips"
+)
+
+const (
+	pbkdf2Prefix     = "$pbkdf2-sha256$"
+	pbkdf2Iterations = 600_000
+	pbkdf2SaltLength = 16
+	pbkdf2KeyLength  = 32
 )
 
+var (
+	ErrMismatch    = errors.New("password does not match")
+	ErrNotApproved = errors.New("the password hash is not FIPS approved, the password must be reset")
+)
+
+// Hash hashes a password with bcrypt, or with PBKDF2-HMAC-SHA256 in FIPS
+// mode, and returns it with a new token key.
 func Hash(password string) (hashedPassword, tokenKey string, err error) {
-	hashedPasswordB, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if fips.Enabled() {
+		hashedPassword, err = hashPBKDF2(password, pbkdf2Iterations)
+	} else {
+		var hashedPasswordB []byte
+
+		hashedPasswordB, err = bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+		hashedPassword = string(hashedPasswordB)
+	}
+
 	if err != nil {
 		return "", "", fmt.Errorf("failed to hash password: %w", err)
 	}
@@ -19,7 +49,84 @@ func Hash(password string) (hashedPassword, tokenKey string, err error) {
 		return "", "", err
 	}
 
-	return string(hashedPasswordB), tokenKey, nil
+	return hashedPassword, tokenKey, nil
+}
+
+// Compare checks a password against a hash of either algorithm. In FIPS
+// mode bcrypt hashes are rejected with ErrNotApproved.
+func Compare(hashedPassword, password string) error {
+	if strings.HasPrefix(hashedPassword, pbkdf2Prefix) {
+		return comparePBKDF2(hashedPassword, password)
+	}
+
+	if fips.Enabled() {
+		return ErrNotApproved
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(password)); err != nil {
+		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+			return ErrMismatch
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// IsBcrypt reports whether a hash was created with bcrypt.
+func IsBcrypt(hashedPassword string) bool {
+	return strings.HasPrefix(hashedPassword, "$2")
+}
+
+// hashPBKDF2 returns a hash like $pbkdf2-sha256$<iterations>$<salt>$<key>.
+func hashPBKDF2(password string, iterations int) (string, error) {
+	salt := make([]byte, pbkdf2SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+
+	key, err := pbkdf2.Key(sha256.New, password, salt, iterations, pbkdf2KeyLength)
+	if err != nil {
+		return "", err
+	}
+
+	return pbkdf2Prefix + strconv.Itoa(iterations) + "$" +
+		base64.RawStdEncoding.EncodeToString(salt) + "$" +
+		base64.RawStdEncoding.EncodeToString(key), nil
+}
+
+func comparePBKDF2(hashedPassword, password string) error {
+	parts := strings.Split(strings.TrimPrefix(hashedPassword, pbkdf2Prefix), "$")
+	if len(parts) != 3 {
+		return errors.New("invalid PBKDF2 hash")
+	}
+
+	iterations, err := strconv.Atoi(parts[0])
+	if err != nil || iterations < 1 {
+		return errors.New("invalid PBKDF2 iterations")
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[1])
+	if err != nil {
+		return fmt.Errorf("invalid PBKDF2 salt: %w", err)
+	}
+
+	expected, err := base64.RawStdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return fmt.Errorf("invalid PBKDF2 key: %w", err)
+	}
+
+	key, err := pbkdf2.Key(sha256.New, password, salt, iterations, len(expected))
+	if err != nil {
+		return err
+	}
+
+	if subtle.ConstantTimeCompare(key, expected) != 1 {
+		return ErrMismatch
+	}
+
+	return nil
 }
 
 func GenerateTokenKey() (string, error) {
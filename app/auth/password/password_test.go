@@ -65,3 +65,23 @@ func TestGenerateTokenKey(t *testing.T) {
 	assert.NotEmpty(t, tokenKey, "GenerateTokenKey() tokenKey should not be empty")
 	assert.GreaterOrEqual(t, len(tokenKey), 43, "GenerateTokenKey() tokenKey should be at least 43 characters long")
 }
+
+func TestCompare(t *testing.T) {
+	t.Parallel()
+
+	bcryptHash, _, err := Hash("securePassword123!")
+	require.NoError(t, err)
+
+	pbkdf2Hash, err := hashPBKDF2("securePassword123!", 1000)
+	require.NoError(t, err)
+
+	assert.True(t, IsBcrypt(bcryptHash))
+	assert.False(t, IsBcrypt(pbkdf2Hash))
+	assert.True(t, strings.HasPrefix(pbkdf2Hash, "$pbkdf2-sha256$1000$"))
+
+	require.NoError(t, Compare(bcryptHash, "securePassword123!"))
+	require.NoError(t, Compare(pbkdf2Hash, "securePassword123!"))
+	require.ErrorIs(t, Compare(bcryptHash, "wrong"), ErrMismatch)
+	require.ErrorIs(t, Compare(pbkdf2Hash, "wrong"), ErrMismatch)
+	require.Error(t, Compare("$pbkdf2-sha256$x$y", "securePassword123!"))
+}
@@ -0,0 +1,201 @@
+package password
+
+import (
+	"bufio"
+	"cmp"
+	"context"
+	"crypto/sha1" //nolint:gosec // the range API of Have I Been Pwned uses SHA-1
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/SecurityBrewery/catalyst/app/database/sqlc"
+	"github.com/SecurityBrewery/catalyst/app/settings"
+)
+
+const (
+	// DefaultMinLength is the minimum length of a password if the policy
+	// does not set one.
+	DefaultMinLength = 8
+	// DefaultBreachCheckURL is the range API of Have I Been Pwned.
+	DefaultBreachCheckURL = "https://api.pwnedpasswords.com"
+)
+
+var (
+	ErrPolicy  = errors.New("the password does not meet the password policy")
+	ErrExpired = errors.New("the password has expired and must be reset")
+)
+
+var breachCheckClient = &http.Client{Timeout: 5 * time.Second}
+
+// Check checks a new password of a user against the password policy. The
+// error wraps ErrPolicy and lists all violated rules. The breach check fails
+// open, so an unavailable breach check service does not lock out users.
+func Check(ctx context.Context, queries *sqlc.Queries, userID, plaintext string) error {
+	s, err := settings.Load(ctx, queries)
+	if err != nil {
+		return err
+	}
+
+	policy := s.PasswordPolicy
+
+	problems := checkRules(&policy, plaintext)
+
+	if policy.History > 0 {
+		reused, err := isReused(ctx, queries, userID, plaintext, policy.History)
+		if err != nil {
+			return err
+		}
+
+		if reused {
+			problems = append(problems, fmt.Sprintf("must not be one of the last %d passwords", policy.History))
+		}
+	}
+
+	if policy.BreachCheck && len(problems) == 0 {
+		breached, err := Breached(ctx, cmp.Or(policy.BreachCheckURL, DefaultBreachCheckURL), plaintext)
+
+		switch {
+		case err != nil:
+			slog.WarnContext(ctx, "Failed to check the password for data breaches", "error", err)
+		case breached:
+			problems = append(problems, "is known from a data breach")
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("%w, it %s", ErrPolicy, strings.Join(problems, ", "))
+	}
+
+	return nil
+}
+
+func checkRules(policy *settings.PasswordPolicy, plaintext string) []string {
+	var problems []string
+
+	if minLength := cmp.Or(policy.MinLength, DefaultMinLength); utf8.RuneCountInString(plaintext) < minLength {
+		problems = append(problems, fmt.Sprintf("must be at least %d characters long", minLength))
+	}
+
+	rules := []struct {
+		required bool
+		match    func(r rune) bool
+		problem  string
+	}{
+		{policy.RequireUppercase, unicode.IsUpper, "must contain an uppercase letter"},
+		{policy.RequireLowercase, unicode.IsLower, "must contain a lowercase letter"},
+		{policy.RequireDigit, unicode.IsDigit, "must contain a digit"},
+		{policy.RequireSymbol, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) }, "must contain a symbol"},
+	}
+
+	for _, rule := range rules {
+		if rule.required && !strings.ContainsFunc(plaintext, rule.match) {
+			problems = append(problems, rule.problem)
+		}
+	}
+
+	return problems
+}
+
+func isReused(ctx context.Context, queries *sqlc.Queries, userID, plaintext string, history int) (bool, error) {
+	previous, err := queries.ListPasswordHistory(ctx, sqlc.ListPasswordHistoryParams{User: userID, Limit: int64(history)})
+	if err != nil {
+		return false, fmt.Errorf("failed to list previous passwords: %w", err)
+	}
+
+	for _, p := range previous {
+		if Compare(p.Passwordhash, plaintext) == nil {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// Breached reports whether a password is known from a data breach. Only the
+// first five characters of the SHA-1 hash are sent to the range API, the
+// response is padded so that its size does not reveal the prefix either.
+func Breached(ctx context.Context, baseURL, plaintext string) (bool, error) {
+	sum := sha1.Sum([]byte(plaintext)) //nolint:gosec
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	prefix, suffix := hash[:5], hash[5:]
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(baseURL, "/")+"/range/"+prefix, nil)
+	if err != nil {
+		return false, err
+	}
+
+	req.Header.Set("Add-Padding", "true")
+
+	resp, err := breachCheckClient.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("unexpected status code %d from the breach check", resp.StatusCode)
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		candidate, count, ok := strings.Cut(strings.TrimSpace(scanner.Text()), ":")
+
+		// padding entries have a count of 0
+		if ok && candidate == suffix && count != "0" {
+			return true, nil
+		}
+	}
+
+	return false, scanner.Err()
+}
+
+// RecordHistory records a new password hash of a user. The history keeps at
+// least the current password, which dates the expiry.
+func RecordHistory(ctx context.Context, queries *sqlc.Queries, userID, hashedPassword string) error {
+	s, err := settings.Load(ctx, queries)
+	if err != nil {
+		return err
+	}
+
+	if err := queries.CreatePasswordHistory(ctx, sqlc.CreatePasswordHistoryParams{User: userID, PasswordHash: hashedPassword}); err != nil {
+		return fmt.Errorf("failed to record password history: %w", err)
+	}
+
+	return queries.PrunePasswordHistory(ctx, sqlc.PrunePasswordHistoryParams{
+		User: userID,
+		Keep: int64(max(s.PasswordPolicy.History, 1)),
+	})
+}
+
+// Expired reports whether the password of a user is older than the maximum
+// age of the password policy. Passwords without history do not expire.
+func Expired(ctx context.Context, queries *sqlc.Queries, userID string) (bool, error) {
+	s, err := settings.Load(ctx, queries)
+	if err != nil {
+		return false, err
+	}
+
+	if s.PasswordPolicy.MaxAge <= 0 {
+		return false, nil
+	}
+
+	current, err := queries.ListPasswordHistory(ctx, sqlc.ListPasswordHistoryParams{User: userID, Limit: 1})
+	if err != nil {
+		return false, fmt.Errorf("failed to get the password age: %w", err)
+	}
+
+	if len(current) == 0 {
+		return false, nil
+	}
+
+	maxAge := time.Duration(s.PasswordPolicy.MaxAge) * 24 * time.Hour
+
+	return time.Since(current[0].Created) > maxAge, nil
+}
@@ -0,0 +1,139 @@
+package password_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/SecurityBrewery/catalyst/app/auth/password"
+	"github.com/SecurityBrewery/catalyst/app/data"
+	"github.com/SecurityBrewery/catalyst/app/database/sqlc"
+	"github.com/SecurityBrewery/catalyst/app/settings"
+)
+
+// SHA-1 of "password123" is CBFDAC6008F9CAB4083784CBD1874F76618D2A97.
+func pwnedServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Regexp(t, `^/range/[0-9A-F]{5}$`, r.URL.Path)
+		assert.Equal(t, "true", r.Header.Get("Add-Padding"))
+
+		_, _ = fmt.Fprint(w, "0018A45C4D1DEF81644B54AB7F969B88D65:0\r\nC6008F9CAB4083784CBD1874F76618D2A97:2254650\r\n")
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestBreached(t *testing.T) {
+	t.Parallel()
+
+	server := pwnedServer(t)
+
+	breached, err := password.Breached(t.Context(), server.URL, "password123")
+	require.NoError(t, err)
+	assert.True(t, breached)
+
+	breached, err = password.Breached(t.Context(), server.URL+"/", "Pas5word!123")
+	require.NoError(t, err)
+	assert.False(t, breached)
+}
+
+func TestCheck(t *testing.T) {
+	t.Parallel()
+
+	queries := data.NewTestDB(t, t.TempDir())
+
+	require.NoError(t, password.Check(t.Context(), queries, "u_bob_analyst", "password123"))
+
+	err := password.Check(t.Context(), queries, "u_bob_analyst", "short")
+	require.ErrorIs(t, err, password.ErrPolicy)
+	assert.Contains(t, err.Error(), "must be at least 8 characters long")
+
+	server := pwnedServer(t)
+
+	_, err = settings.Update(t.Context(), queries, func(s *settings.Settings) {
+		s.PasswordPolicy = settings.PasswordPolicy{
+			MinLength:        12,
+			RequireUppercase: true,
+			RequireDigit:     true,
+			RequireSymbol:    true,
+			History:          2,
+			BreachCheck:      true,
+			BreachCheckURL:   server.URL,
+		}
+	})
+	require.NoError(t, err)
+
+	err = password.Check(t.Context(), queries, "u_bob_analyst", "passwordpassword")
+	require.ErrorIs(t, err, password.ErrPolicy)
+	assert.Equal(t, "the password does not meet the password policy, it must contain an uppercase letter, must contain a digit, must contain a symbol", err.Error())
+
+	require.NoError(t, password.Check(t.Context(), queries, "u_bob_analyst", "Correct-Horse-1"))
+
+	// previous passwords must not be reused
+	for _, pw := range []string{"Correct-Horse-1", "Correct-Horse-2"} {
+		hash, _, err := password.Hash(pw)
+		require.NoError(t, err)
+		require.NoError(t, password.RecordHistory(t.Context(), queries, "u_bob_analyst", hash))
+	}
+
+	err = password.Check(t.Context(), queries, "u_bob_analyst", "Correct-Horse-1")
+	require.ErrorIs(t, err, password.ErrPolicy)
+	assert.Contains(t, err.Error(), "must not be one of the last 2 passwords")
+
+	// the history keeps the configured number of passwords
+	hash, _, err := password.Hash("Correct-Horse-3")
+	require.NoError(t, err)
+	require.NoError(t, password.RecordHistory(t.Context(), queries, "u_bob_analyst", hash))
+	require.NoError(t, password.Check(t.Context(), queries, "u_bob_analyst", "Correct-Horse-1"))
+
+	history, err := queries.ListPasswordHistory(t.Context(), sqlc.ListPasswordHistoryParams{User: "u_bob_analyst", Limit: 10})
+	require.NoError(t, err)
+	assert.Len(t, history, 2)
+
+	// breached passwords are rejected
+	_, err = settings.Update(t.Context(), queries, func(s *settings.Settings) {
+		s.PasswordPolicy = settings.PasswordPolicy{BreachCheck: true, BreachCheckURL: server.URL}
+	})
+	require.NoError(t, err)
+
+	err = password.Check(t.Context(), queries, "u_bob_analyst", "password123")
+	require.ErrorIs(t, err, password.ErrPolicy)
+	assert.Contains(t, err.Error(), "is known from a data breach")
+}
+
+func TestExpired(t *testing.T) {
+	t.Parallel()
+
+	queries := data.NewTestDB(t, t.TempDir())
+
+	hash, _, err := password.Hash("Correct-Horse-1")
+	require.NoError(t, err)
+	require.NoError(t, password.RecordHistory(t.Context(), queries, "u_bob_analyst", hash))
+
+	expired, err := password.Expired(t.Context(), queries, "u_bob_analyst")
+	require.NoError(t, err)
+	assert.False(t, expired, "passwords do not expire without a maximum age")
+
+	_, err = settings.Update(t.Context(), queries, func(s *settings.Settings) {
+		s.PasswordPolicy.MaxAge = 90
+	})
+	require.NoError(t, err)
+
+	expired, err = password.Expired(t.Context(), queries, "u_bob_analyst")
+	require.NoError(t, err)
+	assert.False(t, expired)
+
+	_, err = queries.WriteDB.ExecContext(t.Context(), "UPDATE password_history SET created = datetime('now', '-91 days')")
+	require.NoError(t, err)
+
+	expired, err = password.Expired(t.Context(), queries, "u_bob_analyst")
+	require.NoError(t, err)
+	assert.True(t, expired)
+}
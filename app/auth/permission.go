@@ -7,22 +7,26 @@ import (
 )
 
 var (
-	TicketReadPermission    = "ticket:read"
-	TicketWritePermission   = "ticket:write"
-	FileReadPermission      = "file:read"
-	FileWritePermission     = "file:write"
-	TypeReadPermission      = "type:read"
-	TypeWritePermission     = "type:write"
-	UserReadPermission      = "user:read"
-	UserWritePermission     = "user:write"
-	GroupReadPermission     = "group:read"
-	GroupWritePermission    = "group:write"
-	ReactionReadPermission  = "reaction:read"
-	ReactionWritePermission = "reaction:write"
-	WebhookReadPermission   = "webhook:read"
-	WebhookWritePermission  = "webhook:write"
-	SettingsReadPermission  = "settings:read"
-	SettingsWritePermission = "settings:write"
+	TicketReadPermission     = "ticket:read"
+	TicketWritePermission    = "ticket:write"
+	FileReadPermission       = "file:read"
+	FileWritePermission      = "file:write"
+	TypeReadPermission       = "type:read"
+	TypeWritePermission      = "type:write"
+	UserReadPermission       = "user:read"
+	UserWritePermission      = "user:write"
+	GroupReadPermission      = "group:read"
+	GroupWritePermission     = "group:write"
+	ReactionReadPermission   = "reaction:read"
+	ReactionWritePermission  = "reaction:write"
+	WebhookReadPermission    = "webhook:read"
+	WebhookWritePermission   = "webhook:write"
+	SettingsReadPermission   = "settings:read"
+	SettingsWritePermission  = "settings:write"
+	LegalHoldWritePermission = "legalhold:write"
+	ExportCSVPermission      = "export:csv"
+	ExportBackupPermission   = "export:backup"
+	ExportReportPermission   = "export:report"
 )
 
 func All() []string {
@@ -43,6 +47,10 @@ func All() []string {
 		WebhookWritePermission,
 		SettingsReadPermission,
 		SettingsWritePermission,
+		LegalHoldWritePermission,
+		ExportCSVPermission,
+		ExportBackupPermission,
+		ExportReportPermission,
 	}
 }
 
@@ -143,6 +143,18 @@ func handlePassword(queries *sqlc.Queries) func(w http.ResponseWriter, r *http.R
 			return
 		}
 
+		if err := password.Check(r.Context(), queries, user.ID, data.Password); err != nil {
+			if errors.Is(err, password.ErrPolicy) {
+				errorJSON(w, http.StatusBadRequest, err.Error())
+
+				return
+			}
+
+			errorJSON(w, http.StatusInternalServerError, "Failed to check password: "+err.Error())
+
+			return
+		}
+
 		passwordHash, tokenKey, err := password.Hash(data.Password)
 		if err != nil {
 			errorJSON(w, http.StatusInternalServerError, "Failed to hash password: "+err.Error())
@@ -163,6 +175,12 @@ func handlePassword(queries *sqlc.Queries) func(w http.ResponseWriter, r *http.R
 			return
 		}
 
+		if err := password.RecordHistory(r.Context(), queries, user.ID, passwordHash); err != nil {
+			errorJSON(w, http.StatusInternalServerError, "Failed to update password: "+err.Error())
+
+			return
+		}
+
 		b, err := json.Marshal(map[string]any{
 			"message": "Password reset successfully",
 		})
@@ -18,6 +18,9 @@ func Server(queries *sqlc.Queries, mailer *mail.Mailer) http.Handler {
 	router.Post("/local/login", handleLogin(queries))
 	router.Post("/local/reset-password-mail", handleResetPasswordMail(queries, mailer))
 	router.Post("/local/reset-password", handlePassword(queries))
+	router.Post("/local/accept-invitation", handleAcceptInvitation(queries))
+	router.Post("/token", handleTokenExchange(queries))
+	router.Post("/socket-token", handleSocketToken(queries))
 
 	return router
 }
@@ -5,11 +5,11 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"log/slog"
 	"net/http"
 	"time"
 
-	"golang.org/x/crypto/bcrypt"
-
+	"github.com/SecurityBrewery/catalyst/app/auth/password"
 	"github.com/SecurityBrewery/catalyst/app/database/sqlc"
 	"github.com/SecurityBrewery/catalyst/app/settings"
 )
@@ -38,6 +38,12 @@ func handleLogin(queries *sqlc.Queries) func(w http.ResponseWriter, r *http.Requ
 				return
 			}
 
+			if errors.Is(err, password.ErrExpired) {
+				unauthorizedJSON(w, "Password expired, reset your password")
+
+				return
+			}
+
 			unauthorizedJSON(w, "Login failed")
 
 			return
@@ -81,7 +87,7 @@ func handleLogin(queries *sqlc.Queries) func(w http.ResponseWriter, r *http.Requ
 	}
 }
 
-func loginWithMail(ctx context.Context, mail, password string, queries *sqlc.Queries) (*sqlc.User, error) {
+func loginWithMail(ctx context.Context, mail, plaintext string, queries *sqlc.Queries) (*sqlc.User, error) {
 	user, err := queries.UserByEmail(ctx, &mail)
 	if err != nil {
 		return nil, fmt.Errorf("failed to find user by email %q: %w", mail, err)
@@ -91,9 +97,22 @@ func loginWithMail(ctx context.Context, mail, password string, queries *sqlc.Que
 		return nil, ErrUserInactive
 	}
 
-	if err := bcrypt.CompareHashAndPassword([]byte(user.Passwordhash), []byte(password)); err != nil {
+	if err := password.Compare(user.Passwordhash, plaintext); err != nil {
+		if errors.Is(err, password.ErrNotApproved) {
+			slog.WarnContext(ctx, "Login with a password hash that is not FIPS approved", "user", user.ID)
+		}
+
 		return nil, fmt.Errorf("invalid credentials: %w", err)
 	}
 
+	expired, err := password.Expired(ctx, queries, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if expired {
+		return nil, password.ErrExpired
+	}
+
 	return &user, nil
 }
@@ -0,0 +1,153 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/SecurityBrewery/catalyst/app/database/sqlc"
+	"github.com/SecurityBrewery/catalyst/app/settings"
+)
+
+const (
+	// SocketTokenParam is the query parameter of socket tokens. Browsers
+	// cannot set the Authorization header of a WebSocket, so they exchange
+	// their access token for a socket token and pass it in the URL.
+	SocketTokenParam = "token"
+
+	// socketTokenDuration is the lifetime of socket tokens, they are used
+	// right after they are issued.
+	socketTokenDuration = 30 * time.Second
+
+	claimTokenID = "jti"
+)
+
+var errSocketTokenUsed = errors.New("socket token already used")
+
+// usedSocketTokens holds the IDs of the used socket tokens until they
+// expire, so that a token that leaked with a URL cannot be used again.
+var usedSocketTokens = &tokenSet{used: map[string]time.Time{}}
+
+type tokenSet struct {
+	mux  sync.Mutex
+	used map[string]time.Time
+}
+
+// use marks a token as used and reports whether it was unused.
+func (s *tokenSet) use(id string, expires, now time.Time) bool {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	for used, expiry := range s.used {
+		if expiry.Before(now) {
+			delete(s.used, used)
+		}
+	}
+
+	if _, ok := s.used[id]; ok {
+		return false
+	}
+
+	s.used[id] = expires
+
+	return true
+}
+
+type queryCredentialsKey struct{}
+
+// QueryCredentials reports whether the request was authenticated with a
+// socket token in the URL instead of the Authorization header. A browser
+// sends the URL of any site, so the origin of such requests must be checked.
+func QueryCredentials(ctx context.Context) bool {
+	fromQuery, _ := ctx.Value(queryCredentialsKey{}).(bool)
+
+	return fromQuery
+}
+
+// handleSocketToken issues a single-use socket token with the scopes of the
+// access token of the request.
+func handleSocketToken(queries *sqlc.Queries) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		bearerToken := strings.TrimPrefix(r.Header.Get("Authorization"), bearerPrefix)
+
+		user, claims, err := verifyAccessToken(r.Context(), bearerToken, queries)
+		if err != nil {
+			unauthorizedJSON(w, "invalid bearer token")
+
+			return
+		}
+
+		token, err := createSocketToken(r.Context(), user, claims, queries)
+		if err != nil {
+			errorJSON(w, http.StatusInternalServerError, "Failed to create socket token")
+
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"token":      token,
+			"expires_in": int(socketTokenDuration.Seconds()),
+		})
+	}
+}
+
+// createSocketToken creates a socket token with the scopes, the automation
+// and the ticket of the claims of an access token.
+func createSocketToken(ctx context.Context, user *sqlc.User, claims jwt.MapClaims, queries *sqlc.Queries) (string, error) {
+	settings, err := settings.Load(ctx, queries)
+	if err != nil {
+		return "", fmt.Errorf("failed to load settings: %w", err)
+	}
+
+	scopes, err := scopes(claims)
+	if err != nil {
+		return "", err
+	}
+
+	extra := jwt.MapClaims{claimTokenID: rand.Text()}
+
+	for _, claim := range []string{claimActor, claimTicket} {
+		if value, ok := claims[claim]; ok {
+			extra[claim] = value
+		}
+	}
+
+	return createTokenWithClaims(user, socketTokenDuration, purposeSocket, scopes, extra, settings.Meta.AppURL, settings.RecordAuthToken.Secret)
+}
+
+// verifySocketToken verifies a socket token and marks it as used.
+func verifySocketToken(ctx context.Context, token string, queries *sqlc.Queries) (*sqlc.User, jwt.MapClaims, error) {
+	user, claims, err := verifyUserToken(ctx, token, purposeSocket, queries)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	id, _ := claims[claimTokenID].(string)
+
+	expires, err := claims.GetExpirationTime()
+	if err != nil || id == "" {
+		return nil, nil, errors.New("invalid socket token")
+	}
+
+	if !usedSocketTokens.use(id, expires.Time, time.Now()) {
+		return nil, nil, errSocketTokenUsed
+	}
+
+	return user, claims, nil
+}
+
+// isWebSocket reports whether the request opens a WebSocket.
+func isWebSocket(r *http.Request) bool {
+	return strings.EqualFold(r.Header.Get("Upgrade"), "websocket")
+}
@@ -3,6 +3,7 @@ package auth
 import (
 	"context"
 	"fmt"
+	"maps"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -14,7 +15,14 @@ import (
 const (
 	purposeAccess = "access"
 	purposeReset  = "reset"
+	purposeSocket = "socket"
 	scopeReset    = "reset"
+
+	// claimActor names the automation a token acts for, like the actor
+	// claim of RFC 8693.
+	claimActor = "act"
+	// claimTicket restricts a token to the requests on a single ticket.
+	claimTicket = "ticket"
 )
 
 func CreateAccessToken(ctx context.Context, user *sqlc.User, permissions []string, duration time.Duration, queries *sqlc.Queries) (string, error) {
@@ -26,6 +34,23 @@ func CreateAccessToken(ctx context.Context, user *sqlc.User, permissions []strin
 	return createToken(user, duration, purposeAccess, permissions, settings.Meta.AppURL, settings.RecordAuthToken.Secret)
 }
 
+// CreateAutomationToken creates an access token of the user for the
+// automation, e.g. a reaction, acting on its behalf. If a ticket is given,
+// the token is only valid for requests on the ticket.
+func CreateAutomationToken(ctx context.Context, user *sqlc.User, permissions []string, duration time.Duration, automation, ticket string, queries *sqlc.Queries) (string, error) {
+	settings, err := settings.Load(ctx, queries)
+	if err != nil {
+		return "", fmt.Errorf("failed to load settings: %w", err)
+	}
+
+	claims := jwt.MapClaims{claimActor: map[string]any{"sub": automation}}
+	if ticket != "" {
+		claims[claimTicket] = ticket
+	}
+
+	return createTokenWithClaims(user, duration, purposeAccess, permissions, claims, settings.Meta.AppURL, settings.RecordAuthToken.Secret)
+}
+
 func createResetToken(user *sqlc.User, settings *settings.Settings) (string, error) {
 	duration := time.Duration(settings.RecordPasswordResetToken.Duration) * time.Second
 
@@ -37,6 +62,10 @@ func createResetTokenWithDuration(user *sqlc.User, url, appToken string, duratio
 }
 
 func createToken(user *sqlc.User, duration time.Duration, purpose string, scopes []string, url, appToken string) (string, error) {
+	return createTokenWithClaims(user, duration, purpose, scopes, nil, url, appToken)
+}
+
+func createTokenWithClaims(user *sqlc.User, duration time.Duration, purpose string, scopes []string, extra jwt.MapClaims, url, appToken string) (string, error) {
 	if scopes == nil {
 		scopes = []string{}
 	}
@@ -50,6 +79,8 @@ func createToken(user *sqlc.User, duration time.Duration, purpose string, scopes
 		"scopes":  scopes,
 	}
 
+	maps.Copy(claims, extra)
+
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 
 	signingKey := user.Tokenkey + appToken
@@ -111,6 +142,11 @@ func verifyToken(tokenStr string, user *sqlc.User, url, appToken string) (jwt.Ma
 }
 
 func verifyAccessToken(ctx context.Context, bearerToken string, queries *sqlc.Queries) (*sqlc.User, jwt.MapClaims, error) {
+	return verifyUserToken(ctx, bearerToken, purposeAccess, queries)
+}
+
+// verifyUserToken verifies a token of an active user with the purpose.
+func verifyUserToken(ctx context.Context, bearerToken, expectedPurpose string, queries *sqlc.Queries) (*sqlc.User, jwt.MapClaims, error) {
 	token, _, err := jwt.NewParser().ParseUnverified(bearerToken, jwt.MapClaims{})
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to parse token: %w", err)
@@ -131,6 +167,10 @@ func verifyAccessToken(ctx context.Context, bearerToken string, queries *sqlc.Qu
 		return nil, nil, fmt.Errorf("failed to retrieve user for subject %s: %w", sub, err)
 	}
 
+	if !user.Active {
+		return nil, nil, ErrUserInactive
+	}
+
 	settings, err := settings.Load(ctx, queries)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to load settings: %w", err)
@@ -141,7 +181,7 @@ func verifyAccessToken(ctx context.Context, bearerToken string, queries *sqlc.Qu
 		return nil, nil, fmt.Errorf("failed to verify token: %w", err)
 	}
 
-	if err := hasPurpose(claims, purposeAccess); err != nil {
+	if err := hasPurpose(claims, expectedPurpose); err != nil {
 		return nil, nil, fmt.Errorf("failed to check scopes: %w", err)
 	}
 
@@ -199,6 +239,21 @@ func purpose(claim jwt.MapClaims) (string, error) {
 	return purpose, nil
 }
 
+// actor returns the automation of a token, if any.
+func actor(claim jwt.MapClaims) string {
+	act, _ := claim[claimActor].(map[string]any)
+	sub, _ := act["sub"].(string)
+
+	return sub
+}
+
+// boundTicket returns the ticket a token is restricted to, if any.
+func boundTicket(claim jwt.MapClaims) string {
+	ticket, _ := claim[claimTicket].(string)
+
+	return ticket
+}
+
 func scopes(claim jwt.MapClaims) ([]string, error) {
 	scopesClaim, ok := claim["scopes"]
 	if !ok {
@@ -44,3 +44,17 @@ func PermissionFromContext(ctx context.Context) ([]string, bool) {
 
 	return permissions, true
 }
+
+type actorKey struct{}
+
+// ActorRequest sets the automation that acts for the user of the request.
+func ActorRequest(r *http.Request, actor string) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), actorKey{}, actor))
+}
+
+// ActorFromContext returns the automation that acts for the user, if any.
+func ActorFromContext(ctx context.Context) string {
+	actor, _ := ctx.Value(actorKey{}).(string)
+
+	return actor
+}
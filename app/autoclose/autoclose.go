@@ -0,0 +1,404 @@
+// Package autoclose closes stale tickets by the automatic closing rules,
+// e.g. informational alerts that nobody touched for 30 days. A ticket is
+// stale when neither the ticket nor its comments, tasks, files and timeline
+// changed for the inactive days of a rule. The owner is warned the warning
+// days before the ticket is closed, activity after the warning keeps the
+// ticket open.
+package autoclose
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/SecurityBrewery/catalyst/app/auth/usercontext"
+	"github.com/SecurityBrewery/catalyst/app/database"
+	"github.com/SecurityBrewery/catalyst/app/database/sqlc"
+	"github.com/SecurityBrewery/catalyst/app/hook"
+	"github.com/SecurityBrewery/catalyst/app/openapi"
+	"github.com/SecurityBrewery/catalyst/app/push"
+	"github.com/SecurityBrewery/catalyst/app/settings"
+)
+
+const (
+	checkInterval = time.Hour
+
+	day = 24 * time.Hour
+)
+
+var ErrInvalidRule = errors.New("invalid automatic closing rule")
+
+// Filter selects the tickets a rule applies to. Empty lists match all
+// tickets, the severity is read from the ticket state field "severity".
+type Filter struct {
+	Types      []string   `json:"types,omitempty"`
+	Severities []string   `json:"severities,omitempty"`
+	Exclude    Exclusions `json:"exclude,omitzero"`
+}
+
+// Exclusions keep tickets open that match the filter otherwise. Tags are
+// read from the list in the ticket state field "tags".
+type Exclusions struct {
+	Tags       []string `json:"tags,omitempty"`
+	Severities []string `json:"severities,omitempty"`
+	Owners     []string `json:"owners,omitempty"`
+	// Assigned excludes all tickets with an owner.
+	Assigned bool `json:"assigned,omitempty"`
+}
+
+func ParseFilter(data []byte) (Filter, error) {
+	var filter Filter
+	if len(data) == 0 {
+		return filter, nil
+	}
+
+	err := json.Unmarshal(data, &filter)
+
+	return filter, err
+}
+
+// Matches reports whether the rule applies to a ticket with the owner and
+// state.
+func (f *Filter) Matches(ticketType string, owner *string, state map[string]any) bool {
+	severity, _ := state["severity"].(string)
+
+	if !matchAny(f.Types, ticketType) || !matchAny(f.Severities, severity) {
+		return false
+	}
+
+	if owner != nil && (f.Exclude.Assigned || slices.Contains(f.Exclude.Owners, *owner)) {
+		return false
+	}
+
+	if len(f.Exclude.Severities) > 0 && matchAny(f.Exclude.Severities, severity) {
+		return false
+	}
+
+	tags, _ := state["tags"].([]any)
+
+	for _, tag := range tags {
+		if tag, ok := tag.(string); ok && len(f.Exclude.Tags) > 0 && matchAny(f.Exclude.Tags, tag) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func matchAny(values []string, value string) bool {
+	return len(values) == 0 || slices.ContainsFunc(values, func(v string) bool { return strings.EqualFold(v, value) })
+}
+
+// Validate checks the days of a rule. The warning must be sent before the
+// ticket is closed.
+func Validate(name, resolution string, inactiveDays, warningDays int) error {
+	switch {
+	case strings.TrimSpace(name) == "":
+		return fmt.Errorf("%w: missing name", ErrInvalidRule)
+	case strings.TrimSpace(resolution) == "":
+		return fmt.Errorf("%w: missing resolution", ErrInvalidRule)
+	case inactiveDays < 1:
+		return fmt.Errorf("%w: the inactive days must be at least 1", ErrInvalidRule)
+	case warningDays < 0 || warningDays >= inactiveDays:
+		return fmt.Errorf("%w: the warning days must be between 0 and %d", ErrInvalidRule, inactiveDays-1)
+	}
+
+	return nil
+}
+
+// Sender delivers emails, usually the mail.Mailer.
+type Sender interface {
+	Send(ctx context.Context, to, subject, plainTextBody, htmlBody string) error
+}
+
+// Pusher delivers push notifications, usually the push.Notifier.
+type Pusher interface {
+	Send(ctx context.Context, user string, message push.Message) error
+}
+
+type Closer struct {
+	queries *sqlc.Queries
+	hooks   *hook.Hooks
+	mailer  Sender
+	pusher  Pusher
+	now     func() time.Time
+}
+
+func New(queries *sqlc.Queries, hooks *hook.Hooks, mailer Sender, pusher Pusher) *Closer {
+	return &Closer{
+		queries: queries,
+		hooks:   hooks,
+		mailer:  mailer,
+		pusher:  pusher,
+		now:     time.Now,
+	}
+}
+
+// Start checks for stale tickets every hour until the context is canceled.
+func (c *Closer) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(checkInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := c.Run(ctx); err != nil {
+					slog.ErrorContext(ctx, "Failed to close stale tickets", "error", err)
+				}
+			}
+		}
+	}()
+}
+
+// Run warns the owners of tickets that become stale soon and closes stale
+// tickets. Each ticket is handled by the first enabled rule, by name, that
+// matches it. Encrypted tickets are skipped, as their state is unknown.
+func (c *Closer) Run(ctx context.Context) error {
+	rules, err := c.queries.ListEnabledAutoCloseRules(ctx)
+	if err != nil {
+		return err
+	}
+
+	if len(rules) == 0 {
+		return nil
+	}
+
+	ctx, err = c.systemContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	// tickets that became inactive after the earliest warning of any rule
+	earliest := slices.MinFunc(rules, func(a, b sqlc.AutoCloseRule) int {
+		return int((a.InactiveDays - a.WarningDays) - (b.InactiveDays - b.WarningDays))
+	})
+
+	now := c.now()
+
+	tickets, err := c.queries.ListInactiveTickets(ctx, now.Add(-time.Duration(earliest.InactiveDays-earliest.WarningDays)*day).UTC().Format(time.DateTime))
+	if err != nil {
+		return err
+	}
+
+	for _, ticket := range tickets {
+		if ticket.Encrypted {
+			continue
+		}
+
+		var state map[string]any
+		if err := json.Unmarshal(ticket.State, &state); err != nil {
+			slog.ErrorContext(ctx, "Invalid ticket state", "ticket", ticket.ID, "error", err)
+
+			continue
+		}
+
+		rule, ok := c.rule(ctx, rules, &ticket, state)
+		if !ok {
+			continue
+		}
+
+		if err := c.handle(ctx, rule, &ticket, state, now); err != nil {
+			return fmt.Errorf("failed to close ticket %s: %w", ticket.ID, err)
+		}
+	}
+
+	return nil
+}
+
+func (c *Closer) rule(ctx context.Context, rules []sqlc.AutoCloseRule, ticket *sqlc.ListInactiveTicketsRow, state map[string]any) (*sqlc.AutoCloseRule, bool) {
+	for _, rule := range rules {
+		filter, err := ParseFilter(rule.Filter)
+		if err != nil {
+			slog.ErrorContext(ctx, "Invalid automatic closing rule filter", "rule", rule.ID, "error", err)
+
+			continue
+		}
+
+		if filter.Matches(ticket.Type, ticket.Owner, state) {
+			return &rule, true
+		}
+	}
+
+	return nil, false
+}
+
+// handle warns the owner of the ticket or closes the ticket, depending on
+// how long it was inactive and when the owner was warned.
+func (c *Closer) handle(ctx context.Context, rule *sqlc.AutoCloseRule, ticket *sqlc.ListInactiveTicketsRow, state map[string]any, now time.Time) error {
+	lastActivity, err := time.ParseInLocation(time.DateTime, ticket.LastActivity, time.UTC)
+	if err != nil {
+		return fmt.Errorf("invalid last activity %q: %w", ticket.LastActivity, err)
+	}
+
+	inactive := now.Sub(lastActivity)
+
+	if inactive < time.Duration(rule.InactiveDays-rule.WarningDays)*day {
+		return nil
+	}
+
+	if rule.WarningDays > 0 {
+		// a warning before the last activity is outdated
+		warning, err := c.queries.GetAutoCloseWarning(ctx, ticket.ID)
+		if errors.Is(err, sql.ErrNoRows) || (err == nil && warning.Warned.Before(lastActivity)) {
+			return c.warn(ctx, rule, ticket, now)
+		} else if err != nil {
+			return err
+		}
+
+		if now.Sub(warning.Warned) < time.Duration(rule.WarningDays)*day {
+			return nil
+		}
+	}
+
+	if inactive < time.Duration(rule.InactiveDays)*day {
+		return nil
+	}
+
+	return c.close(ctx, rule, ticket, state)
+}
+
+func (c *Closer) warn(ctx context.Context, rule *sqlc.AutoCloseRule, ticket *sqlc.ListInactiveTicketsRow, now time.Time) error {
+	if err := c.queries.SetAutoCloseWarning(ctx, sqlc.SetAutoCloseWarningParams{
+		Ticket: ticket.ID,
+		Rule:   rule.ID,
+		Warned: now.UTC(),
+	}); err != nil {
+		return err
+	}
+
+	if ticket.Owner == nil {
+		return nil
+	}
+
+	owner, err := c.queries.GetUser(ctx, *ticket.Owner)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	if !owner.Active {
+		return nil
+	}
+
+	se, err := settings.Load(ctx, c.queries)
+	if err != nil {
+		return err
+	}
+
+	url := strings.TrimSuffix(se.Meta.AppURL, "/") + "/ui/tickets/" + ticket.Type + "/" + ticket.ID
+	subject := "Stale ticket: " + ticket.Name + " will be closed"
+	body := fmt.Sprintf("The ticket %q had no activity for %d days and will be closed as %q in %d days by the rule %q. Update the ticket to keep it open.\n\n%s",
+		ticket.Name, rule.InactiveDays-rule.WarningDays, rule.Resolution, rule.WarningDays, rule.Name, url)
+
+	if owner.Email != nil && *owner.Email != "" && c.mailer != nil {
+		if err := c.mailer.Send(ctx, *owner.Email, subject, body, ""); err != nil {
+			slog.ErrorContext(ctx, "Failed to send stale ticket warning", "ticket", ticket.ID, "user", owner.ID, "error", err)
+		}
+	}
+
+	if c.pusher != nil {
+		if err := c.pusher.Send(ctx, owner.ID, push.Message{Title: subject, Body: ticket.Name, URL: url}); err != nil {
+			slog.ErrorContext(ctx, "Failed to push stale ticket warning", "ticket", ticket.ID, "user", owner.ID, "error", err)
+		}
+	}
+
+	return nil
+}
+
+func (c *Closer) close(ctx context.Context, rule *sqlc.AutoCloseRule, ticket *sqlc.ListInactiveTicketsRow, state map[string]any) error {
+	previous := openapi.Ticket{
+		Acknowledged:   ticket.Acknowledged,
+		AcknowledgedBy: ticket.AcknowledgedBy,
+		Created:        ticket.Created,
+		Description:    ticket.Description,
+		Id:             ticket.ID,
+		Key:            ticket.Key,
+		Name:           ticket.Name,
+		Open:           ticket.Open,
+		Owner:          ticket.Owner,
+		Resolution:     ticket.Resolution,
+		Resolved:       ticket.Resolved,
+		ResolvedBy:     ticket.ResolvedBy,
+		ReopenCount:    int(ticket.ReopenCount),
+		Schema:         unmarshal(ticket.Schema),
+		State:          state,
+		Type:           ticket.Type,
+		Updated:        ticket.Updated,
+	}
+
+	var resolvedBy *string
+	if user, ok := usercontext.UserFromContext(ctx); ok {
+		resolvedBy = &user.ID
+	}
+
+	closed, err := c.queries.UpdateTicket(ctx, sqlc.UpdateTicketParams{
+		ID:         ticket.ID,
+		Open:       new(bool),
+		Resolution: &rule.Resolution,
+		ResolvedBy: resolvedBy,
+	})
+	if err != nil {
+		return err
+	}
+
+	if _, err := c.queries.CreateTimeline(ctx, sqlc.CreateTimelineParams{
+		Ticket:  ticket.ID,
+		Message: fmt.Sprintf("Closed automatically as %s by the rule %s after %d days without activity", rule.Resolution, rule.Name, rule.InactiveDays),
+		Time:    c.now().UTC(),
+	}); err != nil {
+		return err
+	}
+
+	if err := c.queries.DeleteAutoCloseWarning(ctx, ticket.ID); err != nil {
+		return err
+	}
+
+	c.hooks.OnRecordAfterUpdateRequest.Publish(hook.WithPrevious(ctx, previous), database.TicketsTable.ID, openapi.Ticket{
+		Acknowledged:   closed.Acknowledged,
+		AcknowledgedBy: closed.AcknowledgedBy,
+		Created:        closed.Created,
+		Description:    closed.Description,
+		Id:             closed.ID,
+		Key:            closed.Key,
+		Name:           closed.Name,
+		Open:           closed.Open,
+		Owner:          closed.Owner,
+		Resolution:     closed.Resolution,
+		Resolved:       closed.Resolved,
+		ResolvedBy:     closed.ResolvedBy,
+		ReopenCount:    int(closed.ReopenCount),
+		Schema:         unmarshal(closed.Schema),
+		State:          state,
+		Type:           closed.Type,
+		Updated:        closed.Updated,
+	})
+
+	return nil
+}
+
+// systemContext authenticates the changes as the system user.
+func (c *Closer) systemContext(ctx context.Context) (context.Context, error) {
+	systemUser, err := c.queries.SystemUser(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find system user: %w", err)
+	}
+
+	return usercontext.UserContext(ctx, &systemUser), nil
+}
+
+func unmarshal(data []byte) map[string]any {
+	var m map[string]any
+	_ = json.Unmarshal(data, &m)
+
+	return m
+}
@@ -0,0 +1,149 @@
+package autoclose
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/SecurityBrewery/catalyst/app/data"
+	"github.com/SecurityBrewery/catalyst/app/database/sqlc"
+	"github.com/SecurityBrewery/catalyst/app/hook"
+	"github.com/SecurityBrewery/catalyst/app/pointer"
+	"github.com/SecurityBrewery/catalyst/app/push"
+)
+
+type fakeMailer struct {
+	to []string
+}
+
+func (f *fakeMailer) Send(_ context.Context, to, _, _, _ string) error {
+	f.to = append(f.to, to)
+
+	return nil
+}
+
+type fakePusher struct {
+	users []string
+}
+
+func (f *fakePusher) Send(_ context.Context, user string, _ push.Message) error {
+	f.users = append(f.users, user)
+
+	return nil
+}
+
+func TestFilter_Matches(t *testing.T) {
+	t.Parallel()
+
+	state := map[string]any{"severity": "Low", "tags": []any{"phishing", "vip"}}
+
+	assert.True(t, (&Filter{}).Matches("alert", nil, state))
+	assert.True(t, (&Filter{Types: []string{"alert"}, Severities: []string{"low"}}).Matches("alert", nil, state))
+	assert.False(t, (&Filter{Types: []string{"incident"}}).Matches("alert", nil, state))
+	assert.False(t, (&Filter{Severities: []string{"High"}}).Matches("alert", nil, state))
+	assert.False(t, (&Filter{Exclude: Exclusions{Tags: []string{"VIP"}}}).Matches("alert", nil, state))
+	assert.False(t, (&Filter{Exclude: Exclusions{Severities: []string{"Low"}}}).Matches("alert", nil, state))
+	assert.False(t, (&Filter{Exclude: Exclusions{Assigned: true}}).Matches("alert", pointer.Pointer("u_bob_analyst"), state))
+	assert.False(t, (&Filter{Exclude: Exclusions{Owners: []string{"u_bob_analyst"}}}).Matches("alert", pointer.Pointer("u_bob_analyst"), state))
+	assert.True(t, (&Filter{Exclude: Exclusions{Owners: []string{"u_bob_analyst"}}}).Matches("alert", pointer.Pointer("u_admin"), state))
+}
+
+func TestValidate(t *testing.T) {
+	t.Parallel()
+
+	require.NoError(t, Validate("Alerts", "false positive", 30, 5))
+	require.NoError(t, Validate("Alerts", "false positive", 1, 0))
+	require.ErrorIs(t, Validate("", "false positive", 30, 5), ErrInvalidRule)
+	require.ErrorIs(t, Validate("Alerts", "", 30, 5), ErrInvalidRule)
+	require.ErrorIs(t, Validate("Alerts", "false positive", 0, 0), ErrInvalidRule)
+	require.ErrorIs(t, Validate("Alerts", "false positive", 30, 30), ErrInvalidRule)
+	require.ErrorIs(t, Validate("Alerts", "false positive", 30, -1), ErrInvalidRule)
+}
+
+func newTestCloser(t *testing.T, filter string) (*Closer, *sqlc.Queries, *fakeMailer, *fakePusher, *time.Time) {
+	t.Helper()
+
+	queries := data.NewTestDB(t, t.TempDir())
+
+	_, err := queries.CreateAutoCloseRule(t.Context(), sqlc.CreateAutoCloseRuleParams{
+		Name:         "Stale incidents",
+		Enabled:      true,
+		Filter:       []byte(filter),
+		InactiveDays: 30,
+		WarningDays:  5,
+		Resolution:   "stale",
+	})
+	require.NoError(t, err)
+
+	mailer := &fakeMailer{}
+	pusher := &fakePusher{}
+
+	// the test ticket has no activity since 2025-06-21
+	now := time.Date(2025, 7, 10, 12, 0, 0, 0, time.UTC)
+
+	c := New(queries, hook.NewHooks(), mailer, pusher)
+	c.now = func() time.Time { return now }
+
+	return c, queries, mailer, pusher, &now
+}
+
+func TestCloser_Run(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+
+	c, queries, mailer, pusher, now := newTestCloser(t, `{"types":["incident"]}`)
+
+	// active for 19 days
+	require.NoError(t, c.Run(ctx))
+	assert.Empty(t, mailer.to)
+
+	// inactive for 26 days, the owner is warned
+	*now = now.Add(7 * day)
+	require.NoError(t, c.Run(ctx))
+	assert.Equal(t, []string{"analyst@catalyst-soar.com"}, mailer.to)
+	assert.Equal(t, []string{"u_bob_analyst"}, pusher.users)
+
+	// the warning is sent only once
+	*now = now.Add(2 * day)
+	require.NoError(t, c.Run(ctx))
+	assert.Len(t, mailer.to, 1)
+
+	// inactive for 30 days, but the warning was only 4 days ago
+	*now = now.Add(2 * day)
+	require.NoError(t, c.Run(ctx))
+
+	ticket, err := queries.Ticket(ctx, "test-ticket")
+	require.NoError(t, err)
+	assert.True(t, ticket.Open)
+
+	*now = now.Add(day)
+	require.NoError(t, c.Run(ctx))
+
+	ticket, err = queries.Ticket(ctx, "test-ticket")
+	require.NoError(t, err)
+	assert.False(t, ticket.Open)
+	assert.Equal(t, "stale", pointer.Dereference(ticket.Resolution))
+
+	_, err = queries.GetAutoCloseWarning(ctx, "test-ticket")
+	require.Error(t, err)
+}
+
+func TestCloser_RunExcluded(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+
+	c, queries, mailer, _, now := newTestCloser(t, `{"types":["incident"],"exclude":{"owners":["u_bob_analyst"]}}`)
+
+	*now = now.Add(60 * day)
+	require.NoError(t, c.Run(ctx))
+	assert.Empty(t, mailer.to)
+
+	ticket, err := queries.Ticket(ctx, "test-ticket")
+	require.NoError(t, err)
+	assert.True(t, ticket.Open)
+}
@@ -0,0 +1,392 @@
+// Package azblob reads and writes block blobs of Azure Blob Storage
+// containers with requests signed with a Shared Key, which Azure and the
+// Azurite emulator accept.
+package azblob
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	apiVersion = "2021-08-06"
+
+	// BlockSize is the size of the blocks of an upload. A blob has at most
+	// 50000 blocks, so blobs can be up to about 780 GiB.
+	BlockSize = 16 << 20
+	maxBlocks = 50000
+)
+
+var (
+	ErrTooLarge = errors.New("the blob exceeds the maximum number of blocks")
+	ErrNotFound = errors.New("the blob does not exist")
+)
+
+// Config is the storage account and its key.
+type Config struct {
+	Account string
+	// Key is the base64 encoded account key.
+	Key string
+	// Endpoint of the blob service, https://<account>.blob.core.windows.net
+	// if empty.
+	Endpoint string
+}
+
+func (c *Config) endpoint() string {
+	if c.Endpoint != "" {
+		return strings.TrimSuffix(c.Endpoint, "/")
+	}
+
+	return "https://" + c.Account + ".blob.core.windows.net"
+}
+
+// GetBlob returns the content and the size of a blob, the caller must close
+// the content.
+func GetBlob(ctx context.Context, client *http.Client, config *Config, container, name string, now time.Time) (io.ReadCloser, int64, error) {
+	resp, err := do(ctx, client, config, http.MethodGet, container, name, nil, nil, nil, now)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to download %s: %w", name, err)
+	}
+
+	return resp.Body, resp.ContentLength, nil
+}
+
+// DeleteBlob deletes a blob. It returns an error wrapping ErrNotFound if the
+// blob does not exist.
+func DeleteBlob(ctx context.Context, client *http.Client, config *Config, container, name string, now time.Time) error {
+	resp, err := do(ctx, client, config, http.MethodDelete, container, name, nil, nil, nil, now)
+	if err != nil {
+		return fmt.Errorf("failed to delete %s: %w", name, err)
+	}
+
+	return resp.Body.Close()
+}
+
+// Blob is a blob of a listing.
+type Blob struct {
+	Name string `xml:"Name"`
+	Size int64  `xml:"Properties>Content-Length"`
+}
+
+// ListBlobs lists the blobs whose names start with the prefix. The blobs
+// are listed in pages of up to 5000 blobs.
+func ListBlobs(ctx context.Context, client *http.Client, config *Config, container, prefix string, now func() time.Time) ([]Blob, error) {
+	var blobs []Blob
+
+	marker := ""
+
+	for {
+		query := url.Values{"restype": {"container"}, "comp": {"list"}, "prefix": {prefix}}
+		if marker != "" {
+			query.Set("marker", marker)
+		}
+
+		resp, err := do(ctx, client, config, http.MethodGet, container, "", query, nil, nil, now())
+		if err != nil {
+			return nil, fmt.Errorf("failed to list %s: %w", container, err)
+		}
+
+		var result struct {
+			Blobs      []Blob `xml:"Blobs>Blob"`
+			NextMarker string `xml:"NextMarker"`
+		}
+
+		err = xml.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to list %s: invalid response", container)
+		}
+
+		blobs = append(blobs, result.Blobs...)
+
+		if result.NextMarker == "" {
+			return blobs, nil
+		}
+
+		marker = result.NextMarker
+	}
+}
+
+// Upload streams a block blob to a container in blocks of BlockSize, so
+// that only one block is held in memory. Close commits the blocks, Abort
+// discards them.
+type Upload struct {
+	ctx             context.Context //nolint:containedctx
+	client          *http.Client
+	config          *Config
+	container, name string
+	contentType     string
+	now             func() time.Time
+
+	buf    []byte
+	blocks []string
+	size   int64
+}
+
+// NewUpload starts the upload of a block blob. Blocks are only stored once
+// they are written, so nothing is sent yet.
+func NewUpload(ctx context.Context, client *http.Client, config *Config, container, name, contentType string, now func() time.Time) (*Upload, error) {
+	if _, err := base64.StdEncoding.DecodeString(config.Key); err != nil {
+		return nil, fmt.Errorf("the account key is not base64 encoded: %w", err)
+	}
+
+	return &Upload{
+		ctx:         ctx,
+		client:      client,
+		config:      config,
+		container:   container,
+		name:        name,
+		contentType: contentType,
+		now:         now,
+		buf:         make([]byte, 0, BlockSize),
+	}, nil
+}
+
+// Size returns the number of bytes written.
+func (u *Upload) Size() int64 {
+	return u.size
+}
+
+func (u *Upload) Write(p []byte) (int, error) {
+	written := 0
+
+	for len(p) > 0 {
+		n := min(len(p), BlockSize-len(u.buf))
+		u.buf = append(u.buf, p[:n]...)
+		p = p[n:]
+		written += n
+
+		if len(u.buf) == BlockSize {
+			if err := u.flush(); err != nil {
+				return written, err
+			}
+		}
+	}
+
+	u.size += int64(written)
+
+	return written, nil
+}
+
+func (u *Upload) flush() error {
+	if len(u.blocks) == maxBlocks {
+		return ErrTooLarge
+	}
+
+	// the ids of the blocks of a blob must have the same length
+	id := base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("%08d", len(u.blocks))))
+	query := url.Values{"comp": {"block"}, "blockid": {id}}
+
+	resp, err := do(u.ctx, u.client, u.config, http.MethodPut, u.container, u.name, query, u.buf, nil, u.now())
+	if err != nil {
+		return fmt.Errorf("failed to upload block %d of %s: %w", len(u.blocks), u.name, err)
+	}
+
+	if err := resp.Body.Close(); err != nil {
+		return err
+	}
+
+	u.blocks = append(u.blocks, id)
+	u.buf = u.buf[:0]
+
+	return nil
+}
+
+// Close uploads the last block and commits the blocks as the blob.
+func (u *Upload) Close() error {
+	if len(u.buf) > 0 || len(u.blocks) == 0 {
+		if err := u.flush(); err != nil {
+			return err
+		}
+	}
+
+	body, err := xml.Marshal(struct {
+		XMLName xml.Name `xml:"BlockList"`
+		Latest  []string `xml:"Latest"`
+	}{Latest: u.blocks})
+	if err != nil {
+		return err
+	}
+
+	headers := http.Header{"Content-Type": {"application/xml"}}
+	if u.contentType != "" {
+		headers.Set("X-Ms-Blob-Content-Type", u.contentType)
+	}
+
+	resp, err := do(u.ctx, u.client, u.config, http.MethodPut, u.container, u.name, url.Values{"comp": {"blocklist"}}, append([]byte(xml.Header), body...), headers, u.now())
+	if err != nil {
+		return fmt.Errorf("failed to commit %s: %w", u.name, err)
+	}
+
+	return resp.Body.Close()
+}
+
+// Abort discards the uploaded blocks. Blocks that are not committed are
+// deleted by Azure after a week, a blob that does not exist yet cannot be
+// deleted earlier, so there is nothing to send.
+func (u *Upload) Abort(context.Context) error {
+	u.blocks = nil
+	u.buf = u.buf[:0]
+
+	return nil
+}
+
+// do sends a signed request and returns the response of a successful
+// request, whose body the caller must close.
+func do(ctx context.Context, client *http.Client, config *Config, method, container, name string, query url.Values, body []byte, headers http.Header, now time.Time) (*http.Response, error) {
+	// a request without a name is a request for the container
+	target := config.endpoint() + "/" + escapePath(container)
+	if name != "" {
+		target += "/" + escapePath(name)
+	}
+
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, target, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	for key, values := range headers {
+		req.Header[key] = values
+	}
+
+	if err := sign(req, config, len(body), now); err != nil {
+		return nil, err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		defer resp.Body.Close()
+
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+
+		if resp.StatusCode == http.StatusNotFound {
+			return nil, fmt.Errorf("%w: %s: %s", ErrNotFound, resp.Status, message)
+		}
+
+		return nil, fmt.Errorf("%s: %s", resp.Status, message)
+	}
+
+	return resp, nil
+}
+
+// sign adds the date, the version and the Shared Key authorization of the
+// blob service to a request.
+func sign(req *http.Request, config *Config, contentLength int, now time.Time) error {
+	key, err := base64.StdEncoding.DecodeString(config.Key)
+	if err != nil {
+		return fmt.Errorf("the account key is not base64 encoded: %w", err)
+	}
+
+	req.Header.Set("X-Ms-Date", now.UTC().Format(http.TimeFormat))
+	req.Header.Set("X-Ms-Version", apiVersion)
+
+	length := ""
+	if contentLength > 0 {
+		length = strconv.Itoa(contentLength)
+	}
+
+	stringToSign := strings.Join([]string{
+		req.Method,
+		req.Header.Get("Content-Encoding"),
+		req.Header.Get("Content-Language"),
+		length,
+		req.Header.Get("Content-Md5"),
+		req.Header.Get("Content-Type"),
+		"", // the date is in x-ms-date
+		req.Header.Get("If-Modified-Since"),
+		req.Header.Get("If-Match"),
+		req.Header.Get("If-None-Match"),
+		req.Header.Get("If-Unmodified-Since"),
+		req.Header.Get("Range"),
+		canonicalHeaders(req.Header) + canonicalResource(config.Account, req.URL),
+	}, "\n")
+
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(stringToSign))
+
+	req.Header.Set("Authorization", "SharedKey "+config.Account+":"+base64.StdEncoding.EncodeToString(mac.Sum(nil)))
+
+	return nil
+}
+
+// canonicalHeaders lists the x-ms- headers sorted by their lowercase names,
+// each followed by a newline.
+func canonicalHeaders(header http.Header) string {
+	var lines []string
+
+	for name, values := range header {
+		name = strings.ToLower(name)
+		if strings.HasPrefix(name, "x-ms-") {
+			lines = append(lines, name+":"+strings.TrimSpace(strings.Join(values, ","))+"\n")
+		}
+	}
+
+	slices.Sort(lines)
+
+	return strings.Join(lines, "")
+}
+
+// canonicalResource is the account and the path of the request, followed by
+// the query parameters sorted by their lowercase names. The path of an
+// emulator endpoint contains the account a second time.
+func canonicalResource(account string, u *url.URL) string {
+	resource := "/" + account + u.EscapedPath()
+
+	query := map[string][]string{}
+	for name, values := range u.Query() {
+		name = strings.ToLower(name)
+		query[name] = append(query[name], values...)
+	}
+
+	names := slices.Sorted(maps.Keys(query))
+
+	for _, name := range names {
+		values := query[name]
+		slices.Sort(values)
+
+		resource += "\n" + name + ":" + strings.Join(values, ",")
+	}
+
+	return resource
+}
+
+// escapePath percent-encodes everything but unreserved characters and
+// slashes.
+func escapePath(path string) string {
+	var b strings.Builder
+
+	for _, c := range []byte(path) {
+		switch {
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9',
+			c == '-', c == '_', c == '.', c == '~', c == '/':
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+
+	return b.String()
+}
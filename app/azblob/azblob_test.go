@@ -0,0 +1,300 @@
+package azblob
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// the well-known key of the Azurite emulator
+const testKey = "Eby8vdM02xNOcqFlqUwJPLlmEtlCDXJ1OUzFT50uSRZ6IFsuFq2UVErCz4I6tq/K1SZFPTOtr/KBHBeksoGMGw=="
+
+// fakeBlob implements the blob, listing and block requests of the blob
+// service. Listings have pages of pageSize blobs.
+type fakeBlob struct {
+	mu           sync.Mutex
+	blocks       map[string][]byte
+	blobs        map[string][]byte
+	contentTypes map[string]string
+	pageSize     int
+}
+
+func newFakeBlob(t *testing.T) (*fakeBlob, *Config) {
+	t.Helper()
+
+	f := &fakeBlob{blocks: map[string][]byte{}, blobs: map[string][]byte{}, contentTypes: map[string]string{}, pageSize: 5000}
+
+	server := httptest.NewServer(f)
+	t.Cleanup(server.Close)
+
+	return f, &Config{Account: "devstoreaccount1", Key: testKey, Endpoint: server.URL + "/devstoreaccount1"}
+}
+
+func (f *fakeBlob) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+
+	if r.Header.Get("Authorization") != expectedAuthorization(r, len(body)) || r.Header.Get("X-Ms-Version") != apiVersion {
+		w.WriteHeader(http.StatusForbidden)
+
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	name := strings.TrimPrefix(r.URL.Path, "/devstoreaccount1/")
+	query := r.URL.Query()
+
+	switch {
+	case r.Method == http.MethodGet && query.Get("comp") == "list":
+		f.list(w, name, query)
+
+		return
+	case r.Method == http.MethodGet, r.Method == http.MethodDelete:
+		blob, ok := f.blobs[name]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+
+			return
+		}
+
+		if r.Method == http.MethodDelete {
+			delete(f.blobs, name)
+			w.WriteHeader(http.StatusAccepted)
+
+			return
+		}
+
+		_, _ = w.Write(blob)
+
+		return
+	}
+
+	switch query.Get("comp") {
+	case "block":
+		f.blocks[name+"#"+query.Get("blockid")] = body
+	case "blocklist":
+		var list struct {
+			Latest []string `xml:"Latest"`
+		}
+
+		if err := xml.Unmarshal(body, &list); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+
+			return
+		}
+
+		var blob []byte
+		for _, id := range list.Latest {
+			blob = append(blob, f.blocks[name+"#"+id]...)
+		}
+
+		f.blobs[name] = blob
+		f.contentTypes[name] = r.Header.Get("X-Ms-Blob-Content-Type")
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+}
+
+// expectedAuthorization signs the request like the documentation of the
+// Shared Key authorization of the blob service.
+func expectedAuthorization(r *http.Request, length int) string {
+	contentLength := ""
+	if length > 0 {
+		contentLength = r.Header.Get("Content-Length")
+	}
+
+	headers := "x-ms-date:" + r.Header.Get("X-Ms-Date") + "\n"
+	if contentType := r.Header.Get("X-Ms-Blob-Content-Type"); contentType != "" {
+		headers = "x-ms-blob-content-type:" + contentType + "\n" + headers
+	}
+
+	headers += "x-ms-version:" + r.Header.Get("X-Ms-Version") + "\n"
+
+	resource := "/devstoreaccount1" + r.URL.EscapedPath()
+
+	query := r.URL.Query()
+	for _, name := range slices.Sorted(maps.Keys(query)) {
+		resource += "\n" + name + ":" + query.Get(name)
+	}
+
+	stringToSign := r.Method + "\n\n\n" + contentLength + "\n\n" + r.Header.Get("Content-Type") + "\n\n\n\n\n\n\n" + headers + resource
+
+	key, _ := base64.StdEncoding.DecodeString(testKey)
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(stringToSign))
+
+	return "SharedKey devstoreaccount1:" + base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func (f *fakeBlob) list(w http.ResponseWriter, container string, query url.Values) {
+	var names []string
+
+	for name := range f.blobs {
+		if blob, ok := strings.CutPrefix(name, container+"/"); ok && strings.HasPrefix(blob, query.Get("prefix")) {
+			names = append(names, blob)
+		}
+	}
+
+	slices.Sort(names)
+
+	// the marker is the first name of the next page
+	start := 0
+	if marker := query.Get("marker"); marker != "" {
+		start, _ = slices.BinarySearch(names, marker)
+	}
+
+	end := min(start+f.pageSize, len(names))
+
+	_, _ = io.WriteString(w, "<EnumerationResults><Blobs>")
+
+	for _, name := range names[start:end] {
+		_, _ = fmt.Fprintf(w, "<Blob><Name>%s</Name><Properties><Content-Length>%d</Content-Length></Properties></Blob>", name, len(f.blobs[container+"/"+name]))
+	}
+
+	_, _ = io.WriteString(w, "</Blobs><NextMarker>")
+
+	if end < len(names) {
+		_, _ = io.WriteString(w, names[end])
+	}
+
+	_, _ = io.WriteString(w, "</NextMarker></EnumerationResults>")
+}
+
+func TestGetBlob(t *testing.T) {
+	t.Parallel()
+
+	f, config := newFakeBlob(t)
+	f.blobs["uploads/a/file.txt"] = []byte("content")
+
+	content, size, err := GetBlob(t.Context(), http.DefaultClient, config, "uploads", "a/file.txt", time.Now())
+	require.NoError(t, err)
+
+	defer content.Close()
+
+	data, err := io.ReadAll(content)
+	require.NoError(t, err)
+
+	assert.Equal(t, "content", string(data))
+	assert.Equal(t, int64(7), size)
+
+	_, _, err = GetBlob(t.Context(), http.DefaultClient, config, "uploads", "missing.txt", time.Now())
+	require.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestDeleteBlob(t *testing.T) {
+	t.Parallel()
+
+	f, config := newFakeBlob(t)
+	f.blobs["uploads/a.txt"] = []byte("a")
+
+	require.NoError(t, DeleteBlob(t.Context(), http.DefaultClient, config, "uploads", "a.txt", time.Now()))
+	assert.Empty(t, f.blobs)
+
+	require.ErrorIs(t, DeleteBlob(t.Context(), http.DefaultClient, config, "uploads", "a.txt", time.Now()), ErrNotFound)
+}
+
+func TestListBlobs(t *testing.T) {
+	t.Parallel()
+
+	f, config := newFakeBlob(t)
+	f.pageSize = 2
+
+	for _, name := range []string{"catalyst/a.info", "catalyst/a/file name.txt", "catalyst/b.info", "catalyst/b/c.txt", "other/d.txt"} {
+		f.blobs["uploads/"+name] = []byte(name)
+	}
+
+	f.blobs["backups/catalyst/e.txt"] = []byte("e")
+
+	blobs, err := ListBlobs(t.Context(), http.DefaultClient, config, "uploads", "catalyst/", time.Now)
+	require.NoError(t, err)
+
+	assert.Equal(t, []Blob{
+		{Name: "catalyst/a.info", Size: 15},
+		{Name: "catalyst/a/file name.txt", Size: 24},
+		{Name: "catalyst/b.info", Size: 15},
+		{Name: "catalyst/b/c.txt", Size: 16},
+	}, blobs)
+}
+
+func TestUpload(t *testing.T) {
+	t.Parallel()
+
+	f, config := newFakeBlob(t)
+
+	data := bytes.Repeat([]byte("0123456789abcdef"), (BlockSize*2+100)/16)
+
+	u, err := NewUpload(t.Context(), http.DefaultClient, config, "backups", "catalyst/backup 1.zip", "application/zip", time.Now)
+	require.NoError(t, err)
+
+	_, err = io.Copy(u, bytes.NewReader(data))
+	require.NoError(t, err)
+	require.NoError(t, u.Close())
+
+	assert.Equal(t, int64(len(data)), u.Size())
+	assert.Len(t, f.blocks, 3)
+	assert.Equal(t, data, f.blobs["backups/catalyst/backup 1.zip"])
+	assert.Equal(t, "application/zip", f.contentTypes["backups/catalyst/backup 1.zip"])
+}
+
+func TestUpload_empty(t *testing.T) {
+	t.Parallel()
+
+	f, config := newFakeBlob(t)
+
+	u, err := NewUpload(t.Context(), http.DefaultClient, config, "backups", "empty", "", time.Now)
+	require.NoError(t, err)
+	require.NoError(t, u.Close())
+
+	assert.Contains(t, f.blobs, "backups/empty")
+	assert.Empty(t, f.blobs["backups/empty"])
+}
+
+func TestUpload_errors(t *testing.T) {
+	t.Parallel()
+
+	_, config := newFakeBlob(t)
+
+	_, err := NewUpload(t.Context(), http.DefaultClient, &Config{Account: "a", Key: "not base64!"}, "backups", "x", "", time.Now)
+	require.Error(t, err)
+
+	config.Key = base64.StdEncoding.EncodeToString([]byte("wrong key"))
+
+	u, err := NewUpload(t.Context(), http.DefaultClient, config, "backups", "x", "", time.Now)
+	require.NoError(t, err)
+
+	_, err = u.Write([]byte("data"))
+	require.NoError(t, err)
+	require.ErrorContains(t, u.Close(), "403 Forbidden")
+	require.NoError(t, u.Abort(t.Context()))
+}
+
+func Test_canonicalResource(t *testing.T) {
+	t.Parallel()
+
+	u, err := url.Parse("https://account.blob.core.windows.net/backups/a%20b.zip?comp=block&blockid=MDA%3D&Timeout=30")
+	require.NoError(t, err)
+
+	assert.Equal(t, "/account/backups/a%20b.zip\nblockid:MDA=\ncomp:block\ntimeout:30", canonicalResource("account", u))
+	assert.Equal(t, "https://account.blob.core.windows.net", (&Config{Account: "account"}).endpoint())
+}
@@ -0,0 +1,662 @@
+// Package backup creates zip archives of the database and the uploaded
+// files. Each archive has a manifest with the Catalyst version, the schema
+// version and the checksum of every file, which is used to verify the
+// archive later. Backups of older versions are migrated when they are
+// restored.
+//
+// Incremental backups refer to a previous backup, their base. They contain
+// the database and the uploads that changed since the base, unchanged
+// uploads are only listed in the manifest with the backup that holds them.
+// The database is always contained as a whole, as it is a single file that
+// is small compared to the uploads.
+//
+// With a passphrase or key file in the Config, archives are encrypted and
+// stored with the extension ".zip.enc".
+//
+// Instead of the backups folder, full backups can be streamed to a Target
+// like an S3 bucket, an Azure Blob Storage container, a Google Cloud Storage
+// bucket or a directory in the background.
+//
+// The backups folder is pruned to the daily and weekly backups of the
+// retention settings, the bases of retained backups are kept. Finished and
+// failed backups are posted to the webhook of the notification settings.
+//
+// The logs and the reaction runs, which dominate the size of busy instances,
+// can be excluded from a backup. Their tables are empty in the backup and
+// listed in the manifest, a restore keeps their current rows.
+package backup
+
+import (
+	"archive/zip"
+	"cmp"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/SecurityBrewery/catalyst/app/database/sqlc"
+	"github.com/SecurityBrewery/catalyst/app/storage"
+	"github.com/SecurityBrewery/catalyst/app/upload"
+)
+
+const (
+	ManifestName = "manifest.json"
+	DatabaseName = "data.db"
+	uploadsDir   = "uploads"
+)
+
+var (
+	ErrNotFound         = errors.New("backup not found")
+	ErrInvalidExclusion = errors.New("invalid exclusion")
+
+	// backups before the random suffix are named by the second
+	validName = regexp.MustCompile(`^catalyst-\d{8}-\d{6}(-[0-9a-f]{8})?\.zip(\.enc)?$`)
+
+	// exclusions are the tables that can be excluded from a backup by name.
+	exclusions = map[string][]string{
+		"logs": {"logs"},
+		"jobs": {"reaction_runs"},
+	}
+)
+
+type Manifest struct {
+	Created time.Time `json:"created"`
+	// Version is the Catalyst release that wrote the backup, Schema is the
+	// migration level of its database.
+	Version string `json:"version,omitempty"`
+	Schema  int    `json:"schema"`
+	// Base is the backup an incremental backup refers to.
+	Base string `json:"base,omitempty"`
+	// Excluded are the tables whose rows are not in the backup.
+	Excluded []string       `json:"excluded,omitempty"`
+	Files    []ManifestFile `json:"files"`
+}
+
+type ManifestFile struct {
+	Path   string `json:"path"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+	// Base is the backup that holds a file that did not change since the
+	// base of an incremental backup.
+	Base string `json:"base,omitempty"`
+}
+
+// Base is the previous backup of an incremental backup.
+type Base struct {
+	Name     string
+	Manifest *Manifest
+}
+
+// Info describes a stored backup.
+type Info struct {
+	Name    string
+	Size    int64
+	Created time.Time
+	// Base is the backup an incremental backup refers to.
+	Base      string
+	Encrypted bool
+	// Excluded are the tables whose rows are not in the backup.
+	Excluded []string
+	// Version is the Catalyst release that wrote the backup.
+	Version string
+}
+
+// Manager stores backups in the backups folder of the data directory.
+type Manager struct {
+	queries  *sqlc.Queries
+	uploader *upload.Uploader
+	dir      string
+	key      *Key
+	version  string
+	now      func() time.Time
+	client   *http.Client
+
+	jobsMu      sync.Mutex
+	jobs        map[string]*Job
+	subscribers map[chan Job]struct{}
+}
+
+func New(queries *sqlc.Queries, uploader *upload.Uploader, dir string, config Config) (*Manager, error) {
+	key, err := NewKey(config)
+	if err != nil {
+		return nil, err
+	}
+
+	backupsDir := filepath.Join(dir, "backups")
+
+	if err := os.MkdirAll(backupsDir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create backups directory: %w", err)
+	}
+
+	return &Manager{
+		queries:  queries,
+		uploader: uploader,
+		dir:      backupsDir,
+		key:      key,
+		version:  config.Version,
+		now:      time.Now,
+		client:   &http.Client{Timeout: notifyTimeout},
+		jobs:     map[string]*Job{},
+
+		subscribers: map[chan Job]struct{}{},
+	}, nil
+}
+
+// Create writes a new backup to the backups folder. With the name of a
+// stored backup as base, the backup is incremental. The rows of the
+// exclusions, "logs" and "jobs", are left out.
+func (m *Manager) Create(ctx context.Context, baseName string, exclude ...string) (*Info, error) {
+	excluded, err := ExcludedTables(exclude)
+	if err != nil {
+		return nil, err
+	}
+
+	base, err := m.base(baseName)
+	if err != nil {
+		return nil, err
+	}
+
+	created := m.now().UTC()
+	name := m.name(created)
+
+	f, err := os.OpenFile(filepath.Join(m.dir, name), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create backup file: %w", err)
+	}
+	defer f.Close()
+
+	if err := m.write(ctx, m.newManifest(created, excluded), base, f, nil); err != nil {
+		_ = os.Remove(f.Name())
+
+		m.notify(ctx, m.finished(Job{Name: name, Location: name, Status: JobFailed, Error: err.Error(), Created: created}), true)
+
+		return nil, err
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	m.storeSummary(ctx, name, summary{Created: created, Version: m.version, Base: baseName, Excluded: excluded})
+
+	m.notify(ctx, m.finished(Job{Name: name, Location: name, Status: JobSucceeded, Size: info.Size(), Created: created}), true)
+
+	return &Info{Name: name, Size: info.Size(), Created: created, Base: baseName, Encrypted: m.key != nil, Excluded: excluded, Version: m.version}, nil
+}
+
+// base returns the stored backup that a new backup is incremental to, or nil
+// without a name.
+func (m *Manager) base(name string) (*Base, error) {
+	if name == "" {
+		return nil, nil //nolint:nilnil // a full backup
+	}
+
+	manifest, err := m.manifest(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Base{Name: name, Manifest: manifest}, nil
+}
+
+// newManifest returns the manifest of a new backup, which is completed while
+// the backup is written.
+func (m *Manager) newManifest(created time.Time, excluded []string) Manifest {
+	return Manifest{Created: created, Version: m.version, Excluded: excluded}
+}
+
+// ExcludedTables returns the tables of the exclusions, "logs" for the
+// application log and "jobs" for the runs of reactions.
+func ExcludedTables(exclude []string) ([]string, error) {
+	var tables []string
+
+	for _, name := range exclude {
+		excluded, ok := exclusions[name]
+		if !ok {
+			return nil, fmt.Errorf("%w: %q, only logs and jobs can be excluded", ErrInvalidExclusion, name)
+		}
+
+		for _, table := range excluded {
+			if !slices.Contains(tables, table) {
+				tables = append(tables, table)
+			}
+		}
+	}
+
+	return tables, nil
+}
+
+// name returns the name of a backup created at the time. The random suffix
+// tells apart the backups created in the same second.
+func (m *Manager) name(created time.Time) string {
+	suffix := make([]byte, 4)
+	_, _ = rand.Read(suffix) // never returns an error
+
+	name := "catalyst-" + created.Format("20060102-150405") + "-" + hex.EncodeToString(suffix) + ".zip"
+	if m.key != nil {
+		name += ".enc"
+	}
+
+	return name
+}
+
+func (m *Manager) write(ctx context.Context, manifest Manifest, base *Base, dst io.Writer, progress progressFunc) error {
+	if m.key == nil {
+		return write(ctx, m.queries, m.uploader, manifest, base, dst, progress)
+	}
+
+	w, err := Encrypt(dst, m.key)
+	if err != nil {
+		return err
+	}
+
+	if err := write(ctx, m.queries, m.uploader, manifest, base, w, progress); err != nil {
+		return err
+	}
+
+	return w.Close()
+}
+
+// List returns the stored backups, the newest first. The backups are listed
+// from their summaries, so that they are not read and decrypted.
+func (m *Manager) List() ([]Info, error) {
+	entries, err := os.ReadDir(m.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var backups []Info
+
+	for _, entry := range entries {
+		if !validName.MatchString(entry.Name()) {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return nil, err
+		}
+
+		backup := Info{
+			Name:      entry.Name(),
+			Size:      info.Size(),
+			Created:   nameTime(entry.Name()),
+			Encrypted: strings.HasSuffix(entry.Name(), ".enc"),
+		}
+
+		if summary, err := m.summary(entry.Name()); err == nil {
+			backup.Created = summary.Created
+			backup.Base = summary.Base
+			backup.Excluded = summary.Excluded
+			backup.Version = summary.Version
+		}
+
+		backups = append(backups, backup)
+	}
+
+	slices.Reverse(backups)
+
+	return backups, nil
+}
+
+// summary holds the fields of the manifest of a stored backup that are
+// listed. It is stored unencrypted next to the backup, in a file with the
+// name of the backup and .json appended.
+type summary struct {
+	Created  time.Time `json:"created"`
+	Version  string    `json:"version,omitempty"`
+	Base     string    `json:"base,omitempty"`
+	Excluded []string  `json:"excluded,omitempty"`
+}
+
+func summaryPath(dir, name string) string {
+	return filepath.Join(dir, name+".json")
+}
+
+// summary returns the summary of a stored backup. A backup without a
+// summary, e.g. one that was copied to the backups folder, is read once to
+// store its summary.
+func (m *Manager) summary(name string) (*summary, error) {
+	data, err := os.ReadFile(summaryPath(m.dir, name))
+	if err == nil {
+		var s summary
+		if err := json.Unmarshal(data, &s); err == nil {
+			return &s, nil
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	manifest, err := m.manifest(name)
+	if err != nil {
+		return nil, err
+	}
+
+	s := summary{Created: manifest.Created, Version: manifest.Version, Base: manifest.Base, Excluded: manifest.Excluded}
+
+	if err := writeSummary(m.dir, name, s); err != nil {
+		return nil, err
+	}
+
+	return &s, nil
+}
+
+// storeSummary stores the summary of a new backup. The backup is complete
+// without it, a missing summary is stored when the backups are listed.
+func (m *Manager) storeSummary(ctx context.Context, name string, s summary) {
+	if err := writeSummary(m.dir, name, s); err != nil {
+		slog.WarnContext(ctx, "Failed to store backup summary", "name", name, "error", err)
+	}
+}
+
+func writeSummary(dir, name string, s summary) error {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.CreateTemp(dir, ".summary-*")
+	if err != nil {
+		return err
+	}
+
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+
+		return err
+	}
+
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+
+		return err
+	}
+
+	return os.Rename(f.Name(), summaryPath(dir, name))
+}
+
+// remove deletes a stored backup and its summary.
+func (m *Manager) remove(name string) error {
+	if err := os.Remove(filepath.Join(m.dir, name)); err != nil {
+		return err
+	}
+
+	if err := os.Remove(summaryPath(m.dir, name)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
+
+// Open opens a stored backup.
+func (m *Manager) Open(name string) (*os.File, error) {
+	if !validName.MatchString(name) {
+		return nil, ErrNotFound
+	}
+
+	f, err := os.Open(filepath.Join(m.dir, name))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+
+	return f, err
+}
+
+// Archive returns the zip archive of a backup, which is decrypted with the
+// key of the manager if the backup is encrypted.
+func (m *Manager) Archive(r io.ReaderAt, size int64) (io.ReaderAt, int64, error) {
+	return Archive(r, size, m.key)
+}
+
+// Key returns the key of encrypted backups, or nil if encryption is
+// disabled.
+func (m *Manager) Key() *Key {
+	return m.key
+}
+
+// manifest reads the manifest of a stored backup.
+func (m *Manager) manifest(name string) (*Manifest, error) {
+	f, err := m.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	r, size, err := m.Archive(f, info.Size())
+	if err != nil {
+		return nil, err
+	}
+
+	archive, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalid, err)
+	}
+
+	manifest, err := readManifest(findEntry(archive, ManifestName))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalid, err)
+	}
+
+	return manifest, nil
+}
+
+// Write writes a backup archive of the database and the uploaded files.
+// With a base, uploads with the same checksum as in the base are not
+// written again.
+func Write(ctx context.Context, queries *sqlc.Queries, uploader *upload.Uploader, created time.Time, base *Base, w io.Writer) error {
+	return write(ctx, queries, uploader, Manifest{Created: created}, base, w, nil)
+}
+
+// progressFunc is called with the stage of a backup and the number of files
+// that are done, of the total number of files.
+type progressFunc func(stage Stage, done, total int)
+
+// write writes a backup archive with the manifest, which is completed with
+// the schema version, the base and the files.
+func write(ctx context.Context, queries *sqlc.Queries, uploader *upload.Uploader, manifest Manifest, base *Base, w io.Writer, progress progressFunc) error {
+	if progress == nil {
+		progress = func(Stage, int, int) {}
+	}
+
+	uploads := uploader.Storage
+
+	// the database and the uploads
+	total := 1
+
+	if err := uploads.Walk(ctx, func(string, int64) error {
+		total++
+
+		return nil
+	}); err != nil {
+		return fmt.Errorf("failed to count uploads: %w", err)
+	}
+
+	progress(StageDatabase, 0, total)
+
+	tmp, err := os.MkdirTemp("", "catalyst-backup")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmp)
+
+	// VACUUM INTO writes a consistent copy of the database, even in WAL mode
+	snapshot := filepath.Join(tmp, DatabaseName)
+	if _, err := queries.WriteDB.ExecContext(ctx, "VACUUM INTO ?", snapshot); err != nil {
+		return fmt.Errorf("failed to snapshot database: %w", err)
+	}
+
+	if err := clearTables(ctx, snapshot, manifest.Excluded); err != nil {
+		return err
+	}
+
+	manifest.Schema, err = schemaVersion(ctx, snapshot)
+	if err != nil {
+		return err
+	}
+
+	unchanged := map[string]ManifestFile{}
+
+	if base != nil {
+		manifest.Base = base.Name
+
+		for _, file := range base.Manifest.Files {
+			// a file of the base may itself be held by an earlier backup
+			file.Base = cmp.Or(file.Base, base.Name)
+			unchanged[file.Path] = file
+		}
+	}
+
+	archive := zip.NewWriter(w)
+
+	db, err := os.Open(snapshot)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	if err := addFile(archive, &manifest, DatabaseName, db); err != nil {
+		return err
+	}
+
+	done := 1
+	progress(StageUploads, done, total)
+
+	if err := uploads.Walk(ctx, func(name string, size int64) error {
+		archiveName := path.Join(uploadsDir, name)
+
+		// uploads that were added during the backup are not counted
+		done = min(done+1, total)
+		defer progress(StageUploads, done, total)
+
+		// only uploads of the same size are read for the checksum
+		if previous, ok := unchanged[archiveName]; ok && size == previous.Size {
+			size, sum, err := checksum(ctx, uploads, name)
+			if err != nil {
+				return err
+			}
+
+			if size == previous.Size && sum == previous.SHA256 {
+				manifest.Files = append(manifest.Files, previous)
+
+				return nil
+			}
+		}
+
+		f, _, err := uploads.Open(ctx, name)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		return addFile(archive, &manifest, archiveName, f)
+	}); err != nil {
+		return fmt.Errorf("failed to add uploads: %w", err)
+	}
+
+	manifestWriter, err := archive.Create(ManifestName)
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(manifestWriter)
+	encoder.SetIndent("", "  ")
+
+	if err := encoder.Encode(manifest); err != nil {
+		return err
+	}
+
+	return archive.Close()
+}
+
+// clearTables deletes the rows of the excluded tables from the snapshot of
+// the database and shrinks it.
+func clearTables(ctx context.Context, snapshot string, tables []string) error {
+	if len(tables) == 0 {
+		return nil
+	}
+
+	db, err := sql.Open("sqlite3", "file:"+snapshot)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	for _, table := range tables {
+		if _, err := db.ExecContext(ctx, "DELETE FROM "+quote(table)); err != nil {
+			return fmt.Errorf("failed to exclude table %s: %w", table, err)
+		}
+	}
+
+	if _, err := db.ExecContext(ctx, "VACUUM"); err != nil {
+		return fmt.Errorf("failed to shrink database: %w", err)
+	}
+
+	return db.Close()
+}
+
+func checksum(ctx context.Context, uploads storage.Storage, name string) (int64, string, error) {
+	f, _, err := uploads.Open(ctx, name)
+	if err != nil {
+		return 0, "", err
+	}
+	defer f.Close()
+
+	hash := sha256.New()
+
+	size, err := io.Copy(hash, f)
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to read %s: %w", name, err)
+	}
+
+	return size, hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+func findEntry(archive *zip.Reader, name string) *zip.File {
+	for _, entry := range archive.File {
+		if entry.Name == name {
+			return entry
+		}
+	}
+
+	return nil
+}
+
+func addFile(archive *zip.Writer, manifest *Manifest, name string, r io.Reader) error {
+	w, err := archive.Create(name)
+	if err != nil {
+		return err
+	}
+
+	hash := sha256.New()
+
+	size, err := io.Copy(io.MultiWriter(w, hash), r)
+	if err != nil {
+		return fmt.Errorf("failed to add %s: %w", name, err)
+	}
+
+	manifest.Files = append(manifest.Files, ManifestFile{Path: name, Size: size, SHA256: hex.EncodeToString(hash.Sum(nil))})
+
+	return nil
+}
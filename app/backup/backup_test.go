@@ -0,0 +1,372 @@
+package backup
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/SecurityBrewery/catalyst/app/data"
+	"github.com/SecurityBrewery/catalyst/app/migration"
+	"github.com/SecurityBrewery/catalyst/app/upload"
+)
+
+func newTestBackup(t *testing.T) []byte {
+	t.Helper()
+
+	dir := t.TempDir()
+	queries := data.NewTestDB(t, dir)
+
+	uploader, err := upload.New(dir)
+	require.NoError(t, err)
+
+	_, err = uploader.CreateFile(t.Context(), "b_evidence", "evidence.txt", []byte("evidence"))
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, Write(t.Context(), queries, uploader, time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC), nil, &buf))
+
+	return buf.Bytes()
+}
+
+// rewrite copies a zip archive and changes the content of its files.
+func rewrite(t *testing.T, archive []byte, change func(name string, content []byte) []byte) []byte {
+	t.Helper()
+
+	r, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+
+	w := zip.NewWriter(&buf)
+
+	for _, entry := range r.File {
+		f, err := entry.Open()
+		require.NoError(t, err)
+
+		content, err := io.ReadAll(f)
+		require.NoError(t, err)
+
+		if content = change(entry.Name, content); content == nil {
+			continue
+		}
+
+		out, err := w.Create(entry.Name)
+		require.NoError(t, err)
+
+		_, err = out.Write(content)
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, w.Close())
+
+	return buf.Bytes()
+}
+
+func TestVerify(t *testing.T) {
+	t.Parallel()
+
+	archive := newTestBackup(t)
+
+	report := Verify(t.Context(), bytes.NewReader(archive), int64(len(archive)), migration.Latest())
+	assert.True(t, report.Valid, report.Errors)
+	assert.True(t, report.Compatible)
+	assert.Equal(t, migration.Latest(), *report.Schema)
+	assert.Empty(t, report.Errors)
+
+	var paths []string
+	for _, file := range report.Files {
+		assert.True(t, file.Valid, file.Error)
+
+		paths = append(paths, file.Path)
+	}
+
+	assert.Contains(t, paths, DatabaseName)
+	assert.Contains(t, paths, "uploads/b_evidence.info")
+}
+
+func TestVerify_tampered(t *testing.T) {
+	t.Parallel()
+
+	archive := rewrite(t, newTestBackup(t), func(name string, content []byte) []byte {
+		switch {
+		case name == "uploads/b_evidence.info":
+			return nil
+		case bytes.Equal(content, []byte("evidence")):
+			return []byte("tampered")
+		default:
+			return content
+		}
+	})
+
+	report := Verify(t.Context(), bytes.NewReader(archive), int64(len(archive)), migration.Latest())
+	assert.False(t, report.Valid)
+
+	invalid := map[string]string{}
+
+	for _, file := range report.Files {
+		if !file.Valid {
+			invalid[file.Path] = file.Error
+		}
+	}
+
+	assert.Len(t, invalid, 2)
+	assert.Equal(t, "missing in the archive", invalid["uploads/b_evidence.info"])
+}
+
+func TestVerify_schema(t *testing.T) {
+	t.Parallel()
+
+	archive := newTestBackup(t)
+
+	// a backup of a newer version cannot be restored
+	report := Verify(t.Context(), bytes.NewReader(archive), int64(len(archive)), migration.Latest()-1)
+	assert.False(t, report.Valid)
+	assert.False(t, report.Compatible)
+
+	versioned := rewrite(t, archive, func(name string, content []byte) []byte {
+		if name != ManifestName {
+			return content
+		}
+
+		var manifest Manifest
+		require.NoError(t, json.Unmarshal(content, &manifest))
+
+		manifest.Version = "v0.15.0"
+
+		content, err := json.Marshal(manifest)
+		require.NoError(t, err)
+
+		return content
+	})
+
+	report = Verify(t.Context(), bytes.NewReader(versioned), int64(len(versioned)), migration.Latest()-1)
+	assert.Equal(t, "v0.15.0", report.Version)
+	require.NotEmpty(t, report.Errors)
+	assert.Contains(t, report.Errors[0], "restore it with Catalyst v0.15.0 or newer")
+
+	// the manifest must match the database
+	archive = rewrite(t, archive, func(name string, content []byte) []byte {
+		if name != ManifestName {
+			return content
+		}
+
+		var manifest Manifest
+		require.NoError(t, json.Unmarshal(content, &manifest))
+
+		manifest.Schema--
+
+		content, err := json.Marshal(manifest)
+		require.NoError(t, err)
+
+		return content
+	})
+
+	report = Verify(t.Context(), bytes.NewReader(archive), int64(len(archive)), migration.Latest())
+	assert.False(t, report.Valid)
+	assert.True(t, report.Compatible)
+	require.Len(t, report.Errors, 1)
+	assert.Contains(t, report.Errors[0], "but the manifest states")
+}
+
+func TestVerify_invalidArchive(t *testing.T) {
+	t.Parallel()
+
+	report := Verify(t.Context(), bytes.NewReader([]byte("not a zip")), 9, migration.Latest())
+	assert.False(t, report.Valid)
+	assert.Nil(t, report.Schema)
+	require.Len(t, report.Errors, 1)
+	assert.Contains(t, report.Errors[0], "invalid zip archive")
+}
+
+func TestManager(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	queries := data.NewTestDB(t, dir)
+
+	uploader, err := upload.New(dir)
+	require.NoError(t, err)
+
+	m, err := New(queries, uploader, dir, Config{Version: "v0.16.0"})
+	require.NoError(t, err)
+
+	m.now = func() time.Time { return time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC) }
+
+	info, err := m.Create(t.Context(), "")
+	require.NoError(t, err)
+	assert.Regexp(t, `^catalyst-20250601-120000-[0-9a-f]{8}\.zip$`, info.Name)
+
+	// the listing uses the creation time of the manifest, which unlike the
+	// modification time survives copying the backup
+	require.NoError(t, os.Chtimes(filepath.Join(m.dir, info.Name), time.Time{}, time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)))
+
+	backups, err := m.List()
+	require.NoError(t, err)
+	require.Len(t, backups, 1)
+	assert.Equal(t, info.Size, backups[0].Size)
+	assert.Equal(t, "v0.16.0", backups[0].Version)
+	assert.Equal(t, info.Created, backups[0].Created)
+	assert.FileExists(t, summaryPath(m.dir, info.Name))
+
+	// a backup without a summary is read once to store it
+	require.NoError(t, os.Remove(summaryPath(m.dir, info.Name)))
+
+	backups, err = m.List()
+	require.NoError(t, err)
+	require.Len(t, backups, 1)
+	assert.Equal(t, "v0.16.0", backups[0].Version)
+	assert.Equal(t, info.Created, backups[0].Created)
+	assert.FileExists(t, summaryPath(m.dir, info.Name))
+
+	// backups created in the same second get different names
+	other, err := m.Create(t.Context(), "")
+	require.NoError(t, err)
+	assert.NotEqual(t, info.Name, other.Name)
+
+	f, err := m.Open(info.Name)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	_, err = m.Open("../data.db")
+	require.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestManager_incremental(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	queries := data.NewTestDB(t, dir)
+
+	uploader, err := upload.New(dir)
+	require.NoError(t, err)
+
+	m, err := New(queries, uploader, dir, Config{})
+	require.NoError(t, err)
+
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	m.now = func() time.Time {
+		now = now.Add(time.Hour)
+
+		return now
+	}
+
+	_, err = uploader.CreateFile(t.Context(), "b_evidence", "evidence.txt", []byte("evidence"))
+	require.NoError(t, err)
+
+	full, err := m.Create(t.Context(), "")
+	require.NoError(t, err)
+
+	_, err = uploader.CreateFile(t.Context(), "b_report", "report.txt", []byte("report"))
+	require.NoError(t, err)
+
+	first, err := m.Create(t.Context(), full.Name)
+	require.NoError(t, err)
+	assert.Equal(t, full.Name, first.Base)
+
+	second, err := m.Create(t.Context(), first.Name)
+	require.NoError(t, err)
+
+	_, err = m.Create(t.Context(), "catalyst-20990101-000000.zip")
+	require.ErrorIs(t, err, ErrNotFound)
+
+	backups, err := m.List()
+	require.NoError(t, err)
+	require.Len(t, backups, 3)
+	assert.Equal(t, first.Name, backups[0].Base)
+	assert.Empty(t, backups[2].Base)
+
+	// unchanged uploads refer to the backup that holds them
+	manifest, err := m.manifest(second.Name)
+	require.NoError(t, err)
+	assert.Equal(t, first.Name, manifest.Base)
+
+	bases := map[string]string{}
+	for _, file := range manifest.Files {
+		bases[file.Path] = file.Base
+	}
+
+	assert.Empty(t, bases[DatabaseName])
+	assert.Equal(t, full.Name, bases["uploads/b_evidence.info"])
+	assert.Equal(t, first.Name, bases["uploads/b_report.info"])
+
+	f, err := m.Open(second.Name)
+	require.NoError(t, err)
+
+	t.Cleanup(func() { f.Close() })
+
+	info, err := f.Stat()
+	require.NoError(t, err)
+
+	archive, err := zip.NewReader(f, info.Size())
+	require.NoError(t, err)
+	assert.NotNil(t, findEntry(archive, DatabaseName))
+	assert.Nil(t, findEntry(archive, "uploads/b_evidence.info"), "unchanged uploads are not written again")
+
+	report := Verify(t.Context(), f, info.Size(), migration.Latest())
+	assert.True(t, report.Valid, report.Errors)
+	assert.ElementsMatch(t, []string{full.Name, first.Name}, report.Bases)
+
+	m.VerifyBases(f, info.Size(), report)
+	assert.True(t, report.Valid, report.Files)
+
+	// a restore reads the unchanged uploads from the bases
+	target := t.TempDir()
+
+	_, err = Restore(t.Context(), f, info.Size(), target, m.dir, nil, Selection{})
+	require.NoError(t, err)
+
+	evidence, err := filepath.Glob(filepath.Join(target, uploadsDir, "b_evidence", "evidence_*.txt"))
+	require.NoError(t, err)
+	require.Len(t, evidence, 1)
+
+	content, err := os.ReadFile(evidence[0])
+	require.NoError(t, err)
+	assert.Equal(t, "evidence", string(content))
+	assert.FileExists(t, filepath.Join(target, uploadsDir, "b_report.info"))
+
+	// without the bases the restore fails
+	_, err = Restore(t.Context(), f, info.Size(), t.TempDir(), t.TempDir(), nil, Selection{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to open the base")
+
+	// a tampered base is found before a restore
+	fullPath := filepath.Join(m.dir, full.Name)
+
+	content, err = os.ReadFile(fullPath)
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(fullPath, rewrite(t, content, func(_ string, content []byte) []byte {
+		if bytes.Equal(content, []byte("evidence")) {
+			return []byte("tampered")
+		}
+
+		return content
+	}), 0o600))
+
+	report = Verify(t.Context(), f, info.Size(), migration.Latest())
+	m.VerifyBases(f, info.Size(), report)
+	assert.False(t, report.Valid)
+
+	invalid := map[string]string{}
+
+	for _, file := range report.Files {
+		if !file.Valid {
+			invalid[file.Path] = file.Error
+		}
+	}
+
+	require.Len(t, invalid, 1)
+
+	for path, err := range invalid {
+		assert.Contains(t, path, "b_evidence/")
+		assert.Contains(t, err, "in the base "+full.Name)
+	}
+}
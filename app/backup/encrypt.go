@@ -0,0 +1,319 @@
+package backup
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hkdf"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Encrypted archives start with a header of the magic, the key derivation,
+// its iterations and a random salt. The zip archive follows in chunks, each
+// sealed with AES-256-GCM under a key derived for the archive. The nonce of
+// a chunk is its counter and a flag for the last chunk, so reordered or
+// truncated archives fail to decrypt.
+const (
+	encryptionMagic = "CATALYST-BACKUP\x01"
+	chunkSize       = 64 * 1024
+	saltSize        = 32
+	headerSize      = len(encryptionMagic) + 1 + 4 + saltSize
+
+	kdfKeyFile    byte = 0
+	kdfPassphrase byte = 1
+
+	passphraseIterations    = 600_000
+	maxPassphraseIterations = 10_000_000
+	keyFileSize             = 32
+)
+
+var (
+	ErrEncrypted = errors.New("backup is encrypted, a passphrase or key file is required")
+	ErrDecrypt   = errors.New("failed to decrypt backup, the passphrase or key file is wrong or the backup is damaged")
+)
+
+// Config enables the encryption of new backups with a passphrase or a key
+// file, and is used to decrypt encrypted backups. The key file holds 32
+// random bytes in base64, e.g. from "openssl rand -base64 32". The key must
+// be kept apart from the backups, which are useless without it.
+type Config struct {
+	Passphrase string
+	KeyFile    string
+	// Version is the Catalyst release that writes the backups.
+	Version string
+}
+
+// Key encrypts and decrypts backup archives.
+type Key struct {
+	passphrase string
+	fileKey    []byte
+}
+
+// NewKey returns the key of the config, or nil if encryption is disabled.
+func NewKey(config Config) (*Key, error) {
+	switch {
+	case config.Passphrase != "" && config.KeyFile != "":
+		return nil, errors.New("backup encryption needs either a passphrase or a key file, not both")
+	case config.Passphrase != "":
+		return &Key{passphrase: config.Passphrase}, nil
+	case config.KeyFile != "":
+		encoded, err := os.ReadFile(config.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read backup key file: %w", err)
+		}
+
+		fileKey, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(encoded)))
+		if err != nil || len(fileKey) != keyFileSize {
+			return nil, fmt.Errorf("invalid backup key file %s, it must hold %d bytes in base64", config.KeyFile, keyFileSize)
+		}
+
+		return &Key{fileKey: fileKey}, nil
+	default:
+		return nil, nil //nolint:nilnil // encryption is disabled
+	}
+}
+
+// Encrypt returns a writer that encrypts an archive to w. The writer must be
+// closed to write the last chunk.
+func Encrypt(w io.Writer, key *Key) (io.WriteCloser, error) {
+	header := make([]byte, headerSize)
+	copy(header, encryptionMagic)
+
+	salt := header[headerSize-saltSize:]
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+
+	kdf, iterations := kdfKeyFile, 0
+	if key.fileKey == nil {
+		kdf, iterations = kdfPassphrase, passphraseIterations
+	}
+
+	header[len(encryptionMagic)] = kdf
+	binary.BigEndian.PutUint32(header[len(encryptionMagic)+1:], uint32(iterations)) //nolint:gosec
+
+	aead, err := key.archiveCipher(kdf, iterations, salt)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := w.Write(header); err != nil {
+		return nil, err
+	}
+
+	return &encryptWriter{w: w, aead: aead}, nil
+}
+
+// Archive returns the zip archive of a backup and its size. Encrypted
+// backups are decrypted chunk by chunk on access, as the zip reader needs
+// random access to the archive.
+func Archive(r io.ReaderAt, size int64, key *Key) (io.ReaderAt, int64, error) {
+	if !IsEncrypted(r) {
+		return r, size, nil
+	}
+
+	if key == nil {
+		return nil, 0, ErrEncrypted
+	}
+
+	header := make([]byte, headerSize)
+	if _, err := r.ReadAt(header, 0); err != nil {
+		return nil, 0, fmt.Errorf("%w: invalid encryption header", ErrInvalid)
+	}
+
+	kdf := header[len(encryptionMagic)]
+	iterations := int(binary.BigEndian.Uint32(header[len(encryptionMagic)+1:]))
+
+	aead, err := key.archiveCipher(kdf, iterations, header[headerSize-saltSize:])
+	if err != nil {
+		return nil, 0, err
+	}
+
+	sealedSize := int64(chunkSize + aead.Overhead())
+	body := size - int64(headerSize)
+	chunks := (body + sealedSize - 1) / sealedSize
+
+	if body < int64(aead.Overhead()) || body-(chunks-1)*sealedSize < int64(aead.Overhead()) {
+		return nil, 0, fmt.Errorf("%w: truncated encrypted archive", ErrInvalid)
+	}
+
+	d := &decryptReaderAt{
+		r:      r,
+		aead:   aead,
+		size:   size,
+		chunks: chunks,
+		cached: -1,
+	}
+
+	return d, body - chunks*int64(aead.Overhead()), nil
+}
+
+// IsEncrypted reports whether a backup file is encrypted.
+func IsEncrypted(r io.ReaderAt) bool {
+	magic := make([]byte, len(encryptionMagic))
+	if _, err := r.ReadAt(magic, 0); err != nil {
+		return false
+	}
+
+	return string(magic) == encryptionMagic
+}
+
+func (k *Key) archiveCipher(kdf byte, iterations int, salt []byte) (cipher.AEAD, error) {
+	var (
+		archiveKey []byte
+		err        error
+	)
+
+	switch {
+	case kdf == kdfKeyFile && k.fileKey != nil:
+		archiveKey, err = hkdf.Key(sha256.New, k.fileKey, salt, "catalyst backup", 32)
+	case kdf == kdfPassphrase && k.passphrase != "":
+		if iterations <= 0 || iterations > maxPassphraseIterations {
+			return nil, fmt.Errorf("%w: invalid key derivation", ErrInvalid)
+		}
+
+		archiveKey, err = pbkdf2.Key(sha256.New, k.passphrase, salt, iterations, 32)
+	case kdf == kdfPassphrase:
+		return nil, fmt.Errorf("%w, the backup is encrypted with a passphrase", ErrDecrypt)
+	case kdf == kdfKeyFile:
+		return nil, fmt.Errorf("%w, the backup is encrypted with a key file", ErrDecrypt)
+	default:
+		return nil, fmt.Errorf("%w: unknown key derivation", ErrInvalid)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(archiveKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+func chunkNonce(aead cipher.AEAD, counter uint64, last bool) []byte {
+	nonce := make([]byte, aead.NonceSize())
+	binary.BigEndian.PutUint64(nonce, counter)
+
+	if last {
+		nonce[len(nonce)-1] = 1
+	}
+
+	return nonce
+}
+
+type encryptWriter struct {
+	w       io.Writer
+	aead    cipher.AEAD
+	buf     []byte
+	counter uint64
+}
+
+func (e *encryptWriter) Write(p []byte) (int, error) {
+	e.buf = append(e.buf, p...)
+
+	// a full chunk is only written once more data follows, so that the last
+	// chunk is never empty unless the archive is
+	for len(e.buf) > chunkSize {
+		if err := e.seal(e.buf[:chunkSize], false); err != nil {
+			return 0, err
+		}
+
+		e.buf = append(e.buf[:0], e.buf[chunkSize:]...)
+	}
+
+	return len(p), nil
+}
+
+func (e *encryptWriter) Close() error {
+	return e.seal(e.buf, true)
+}
+
+func (e *encryptWriter) seal(chunk []byte, last bool) error {
+	sealed := e.aead.Seal(nil, chunkNonce(e.aead, e.counter, last), chunk, nil)
+	e.counter++
+
+	_, err := e.w.Write(sealed)
+
+	return err
+}
+
+type decryptReaderAt struct {
+	r      io.ReaderAt
+	aead   cipher.AEAD
+	size   int64
+	chunks int64
+
+	mu     sync.Mutex
+	cached int64
+	plain  []byte
+}
+
+func (d *decryptReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, errors.New("negative offset")
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	n := 0
+
+	for n < len(p) {
+		index := off / chunkSize
+		if index >= d.chunks {
+			return n, io.EOF
+		}
+
+		if err := d.load(index); err != nil {
+			return n, err
+		}
+
+		pos := off - index*chunkSize
+		if pos >= int64(len(d.plain)) {
+			return n, io.EOF
+		}
+
+		copied := copy(p[n:], d.plain[pos:])
+		n += copied
+		off += int64(copied)
+	}
+
+	return n, nil
+}
+
+// load decrypts a chunk. The last chunk by position must be sealed as the
+// last one, which detects archives truncated at a chunk boundary.
+func (d *decryptReaderAt) load(index int64) error {
+	if d.cached == index {
+		return nil
+	}
+
+	sealedSize := int64(chunkSize + d.aead.Overhead())
+	start := int64(headerSize) + index*sealedSize
+	sealed := make([]byte, min(sealedSize, d.size-start))
+
+	if _, err := d.r.ReadAt(sealed, start); err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+
+	plain, err := d.aead.Open(nil, chunkNonce(d.aead, uint64(index), index == d.chunks-1), sealed, nil) //nolint:gosec
+	if err != nil {
+		return ErrDecrypt
+	}
+
+	d.cached, d.plain = index, plain
+
+	return nil
+}
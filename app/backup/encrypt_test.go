@@ -0,0 +1,239 @@
+package backup
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/SecurityBrewery/catalyst/app/data"
+	"github.com/SecurityBrewery/catalyst/app/migration"
+	"github.com/SecurityBrewery/catalyst/app/upload"
+)
+
+func newTestKeyFile(t *testing.T) string {
+	t.Helper()
+
+	b := make([]byte, keyFileSize)
+	_, err := rand.Read(b)
+	require.NoError(t, err)
+
+	keyFile := filepath.Join(t.TempDir(), "backup.key")
+	require.NoError(t, os.WriteFile(keyFile, []byte(base64.StdEncoding.EncodeToString(b)+"\n"), 0o600))
+
+	return keyFile
+}
+
+func encrypt(t *testing.T, plain []byte, key *Key) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+
+	w, err := Encrypt(&buf, key)
+	require.NoError(t, err)
+
+	_, err = w.Write(plain)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	return buf.Bytes()
+}
+
+func decrypt(encrypted []byte, key *Key) ([]byte, error) {
+	r, size, err := Archive(bytes.NewReader(encrypted), int64(len(encrypted)), key)
+	if err != nil {
+		return nil, err
+	}
+
+	return io.ReadAll(io.NewSectionReader(r, 0, size))
+}
+
+func TestEncrypt(t *testing.T) {
+	t.Parallel()
+
+	key, err := NewKey(Config{KeyFile: newTestKeyFile(t)})
+	require.NoError(t, err)
+
+	for _, size := range []int{0, 1, chunkSize - 1, chunkSize, chunkSize + 1, 3*chunkSize + 17} {
+		plain := make([]byte, size)
+		_, err := rand.Read(plain)
+		require.NoError(t, err)
+
+		encrypted := encrypt(t, plain, key)
+		assert.True(t, IsEncrypted(bytes.NewReader(encrypted)))
+
+		decrypted, err := decrypt(encrypted, key)
+		require.NoError(t, err, size)
+		assert.Equal(t, plain, decrypted, size)
+	}
+}
+
+func TestEncrypt_wrongKey(t *testing.T) {
+	t.Parallel()
+
+	key, err := NewKey(Config{KeyFile: newTestKeyFile(t)})
+	require.NoError(t, err)
+
+	other, err := NewKey(Config{KeyFile: newTestKeyFile(t)})
+	require.NoError(t, err)
+
+	passphrase, err := NewKey(Config{Passphrase: "correct horse battery staple"})
+	require.NoError(t, err)
+
+	encrypted := encrypt(t, []byte("archive"), key)
+
+	_, err = decrypt(encrypted, other)
+	require.ErrorIs(t, err, ErrDecrypt)
+
+	_, err = decrypt(encrypted, passphrase)
+	require.ErrorIs(t, err, ErrDecrypt)
+	assert.Contains(t, err.Error(), "encrypted with a key file")
+
+	_, err = decrypt(encrypted, nil)
+	require.ErrorIs(t, err, ErrEncrypted)
+}
+
+func TestEncrypt_passphrase(t *testing.T) {
+	t.Parallel()
+
+	key, err := NewKey(Config{Passphrase: "correct horse battery staple"})
+	require.NoError(t, err)
+
+	encrypted := encrypt(t, []byte("archive"), key)
+
+	decrypted, err := decrypt(encrypted, key)
+	require.NoError(t, err)
+	assert.Equal(t, "archive", string(decrypted))
+
+	wrong, err := NewKey(Config{Passphrase: "wrong"})
+	require.NoError(t, err)
+
+	_, err = decrypt(encrypted, wrong)
+	require.ErrorIs(t, err, ErrDecrypt)
+}
+
+func TestEncrypt_tampered(t *testing.T) {
+	t.Parallel()
+
+	key, err := NewKey(Config{KeyFile: newTestKeyFile(t)})
+	require.NoError(t, err)
+
+	plain := make([]byte, 2*chunkSize+100)
+	encrypted := encrypt(t, plain, key)
+	sealedSize := chunkSize + 16
+
+	// truncated at a chunk boundary, the last chunk is missing
+	_, err = decrypt(encrypted[:headerSize+2*sealedSize], key)
+	require.ErrorIs(t, err, ErrDecrypt)
+
+	_, err = decrypt(encrypted[:headerSize+10], key)
+	require.ErrorIs(t, err, ErrInvalid)
+
+	flipped := bytes.Clone(encrypted)
+	flipped[headerSize+sealedSize+5] ^= 1
+
+	_, err = decrypt(flipped, key)
+	require.ErrorIs(t, err, ErrDecrypt)
+
+	// the first two chunks swapped
+	swapped := bytes.Clone(encrypted)
+	copy(swapped[headerSize:], encrypted[headerSize+sealedSize:headerSize+2*sealedSize])
+	copy(swapped[headerSize+sealedSize:], encrypted[headerSize:headerSize+sealedSize])
+
+	_, err = decrypt(swapped, key)
+	require.ErrorIs(t, err, ErrDecrypt)
+}
+
+func TestNewKey(t *testing.T) {
+	t.Parallel()
+
+	key, err := NewKey(Config{})
+	require.NoError(t, err)
+	assert.Nil(t, key)
+
+	_, err = NewKey(Config{Passphrase: "secret", KeyFile: newTestKeyFile(t)})
+	require.Error(t, err)
+
+	short := filepath.Join(t.TempDir(), "short.key")
+	require.NoError(t, os.WriteFile(short, []byte(base64.StdEncoding.EncodeToString([]byte("short"))), 0o600))
+
+	_, err = NewKey(Config{KeyFile: short})
+	require.Error(t, err)
+
+	_, err = NewKey(Config{KeyFile: filepath.Join(t.TempDir(), "missing.key")})
+	require.Error(t, err)
+}
+
+func TestManager_encrypted(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	queries := data.NewTestDB(t, dir)
+
+	uploader, err := upload.New(dir)
+	require.NoError(t, err)
+
+	m, err := New(queries, uploader, dir, Config{KeyFile: newTestKeyFile(t)})
+	require.NoError(t, err)
+
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	m.now = func() time.Time {
+		now = now.Add(time.Hour)
+
+		return now
+	}
+
+	_, err = uploader.CreateFile(t.Context(), "b_evidence", "evidence.txt", []byte("evidence"))
+	require.NoError(t, err)
+
+	full, err := m.Create(t.Context(), "")
+	require.NoError(t, err)
+	assert.Regexp(t, `^catalyst-20250601-130000-[0-9a-f]{8}\.zip\.enc$`, full.Name)
+	assert.True(t, full.Encrypted)
+
+	incremental, err := m.Create(t.Context(), full.Name)
+	require.NoError(t, err)
+
+	backups, err := m.List()
+	require.NoError(t, err)
+	require.Len(t, backups, 2)
+	assert.True(t, backups[0].Encrypted)
+	assert.Equal(t, full.Name, backups[0].Base)
+
+	f, err := m.Open(incremental.Name)
+	require.NoError(t, err)
+
+	t.Cleanup(func() { f.Close() })
+
+	info, err := f.Stat()
+	require.NoError(t, err)
+
+	report := Verify(t.Context(), f, info.Size(), migration.Latest())
+	assert.False(t, report.Valid, "the encrypted file is not a zip archive")
+
+	archive, size, err := m.Archive(f, info.Size())
+	require.NoError(t, err)
+
+	report = Verify(t.Context(), archive, size, migration.Latest())
+	assert.True(t, report.Valid, report.Errors)
+
+	// the restore decrypts the backup and its base
+	target := t.TempDir()
+
+	_, err = Restore(t.Context(), f, info.Size(), target, m.dir, m.Key(), Selection{})
+	require.NoError(t, err)
+
+	evidence, err := filepath.Glob(filepath.Join(target, uploadsDir, "b_evidence", "evidence_*.txt"))
+	require.NoError(t, err)
+	require.Len(t, evidence, 1)
+
+	_, err = Restore(t.Context(), f, info.Size(), t.TempDir(), m.dir, nil, Selection{})
+	require.ErrorIs(t, err, ErrEncrypted)
+}
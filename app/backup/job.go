@@ -0,0 +1,301 @@
+package backup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/SecurityBrewery/catalyst/app/applog"
+	"github.com/SecurityBrewery/catalyst/app/database"
+)
+
+const (
+	// jobRetention is how long finished jobs can be looked up.
+	jobRetention = 24 * time.Hour
+
+	// sizeInterval is how often subscribers receive the written bytes of a
+	// job, the other changes are published immediately.
+	sizeInterval = time.Second
+
+	subscriberSize = 100
+)
+
+var (
+	ErrJobNotFound = errors.New("backup job not found")
+	ErrNoDownload  = errors.New("backup job has no download")
+)
+
+type JobStatus string
+
+const (
+	JobRunning   JobStatus = "running"
+	JobSucceeded JobStatus = "succeeded"
+	JobFailed    JobStatus = "failed"
+)
+
+// Stage is the part of a backup that is written.
+type Stage string
+
+const (
+	StageDatabase Stage = "database"
+	StageUploads  Stage = "uploads"
+)
+
+// Job is a backup that is written in the background, to a target or to the
+// backups folder. Jobs are kept in memory, they are lost on a restart.
+type Job struct {
+	ID       string
+	Name     string
+	Location string
+	// Base is the backup an incremental backup refers to.
+	Base   string
+	Status JobStatus
+	Stage  Stage
+	// Files is the number of files that were added so far, of TotalFiles.
+	Files      int
+	TotalFiles int
+	// Size is the number of bytes written so far.
+	Size  int64
+	Error string
+	// Excluded are the tables whose rows are not in the backup.
+	Excluded []string
+	Created  time.Time
+	Finished *time.Time
+	// Expires is when the download of a backup that was written to the
+	// backups folder expires, it is set once the job succeeded.
+	Expires *time.Time
+}
+
+// Stream writes a new full backup to the target in the background and
+// returns the job that tracks it. The rows of the exclusions are left out
+// like in Create.
+func (m *Manager) Stream(ctx context.Context, target Target, exclude ...string) (*Job, error) {
+	excluded, err := ExcludedTables(exclude)
+	if err != nil {
+		return nil, err
+	}
+
+	return m.start(ctx, target, nil, excluded), nil
+}
+
+// CreateJob writes a new backup to the backups folder in the background like
+// Create and returns the job that tracks it, so that big backups do not
+// depend on the timeout of a request. The backup can be downloaded with
+// Download until the job expires.
+func (m *Manager) CreateJob(ctx context.Context, baseName string, exclude ...string) (*Job, error) {
+	excluded, err := ExcludedTables(exclude)
+	if err != nil {
+		return nil, err
+	}
+
+	base, err := m.base(baseName)
+	if err != nil {
+		return nil, err
+	}
+
+	return m.start(ctx, folderTarget{dir: m.dir}, base, excluded), nil
+}
+
+func (m *Manager) start(ctx context.Context, target Target, base *Base, excluded []string) *Job {
+	created := m.now().UTC()
+	name := m.name(created)
+
+	job := &Job{
+		ID:       database.GenerateID("bj"),
+		Name:     name,
+		Location: target.Location(name),
+		Status:   JobRunning,
+		Excluded: excluded,
+		Created:  created,
+	}
+
+	if base != nil {
+		job.Base = base.Name
+	}
+
+	m.jobsMu.Lock()
+	m.pruneJobs(created)
+	m.jobs[job.ID] = job
+	started := *job
+	m.publish(started)
+	m.jobsMu.Unlock()
+
+	go m.stream(context.WithoutCancel(ctx), target, base, job)
+
+	return &started
+}
+
+func (m *Manager) stream(ctx context.Context, target Target, base *Base, job *Job) {
+	err := m.streamTo(ctx, target, base, job)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to stream backup", "location", job.Location, "error", err, applog.Critical)
+	}
+
+	finished := m.now().UTC()
+	_, stored := target.(folderTarget)
+
+	// the summary is stored before the backup is announced as finished
+	if stored && err == nil {
+		m.storeSummary(ctx, job.Name, summary{Created: job.Created, Version: m.version, Base: job.Base, Excluded: job.Excluded})
+	}
+
+	m.jobsMu.Lock()
+
+	job.Finished = &finished
+	job.Status = JobSucceeded
+
+	if err != nil {
+		job.Status = JobFailed
+		job.Error = err.Error()
+	} else if stored {
+		expires := finished.Add(jobRetention)
+		job.Expires = &expires
+	}
+
+	m.publish(*job)
+	done := *job
+
+	m.jobsMu.Unlock()
+
+	m.notify(ctx, done, stored)
+}
+
+// finished marks a job that was not tracked as finished now.
+func (m *Manager) finished(job Job) Job {
+	finished := m.now().UTC()
+	job.Finished = &finished
+
+	return job
+}
+
+func (m *Manager) streamTo(ctx context.Context, target Target, base *Base, job *Job) error {
+	w, err := target.Create(ctx, job.Name)
+	if err != nil {
+		return err
+	}
+
+	progress := func(stage Stage, done, total int) {
+		m.jobsMu.Lock()
+		defer m.jobsMu.Unlock()
+
+		job.Stage = stage
+		job.Files = done
+		job.TotalFiles = total
+
+		m.publish(*job)
+	}
+
+	if err := m.write(ctx, m.newManifest(job.Created, job.Excluded), base, &progressWriter{w: w, m: m, job: job}, progress); err != nil {
+		return errors.Join(err, w.Abort(ctx))
+	}
+
+	if err := w.Close(); err != nil {
+		return errors.Join(err, w.Abort(ctx))
+	}
+
+	return nil
+}
+
+// Job returns a copy of a job.
+func (m *Manager) Job(id string) (*Job, error) {
+	m.jobsMu.Lock()
+	defer m.jobsMu.Unlock()
+
+	job, ok := m.jobs[id]
+	if !ok {
+		return nil, ErrJobNotFound
+	}
+
+	c := *job
+
+	return &c, nil
+}
+
+// Download opens the backup of a job that wrote it to the backups folder,
+// until the download expires.
+func (m *Manager) Download(id string) (*os.File, *Job, error) {
+	job, err := m.Job(id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	switch {
+	case job.Status != JobSucceeded:
+		return nil, nil, fmt.Errorf("%w: the job is %s", ErrNoDownload, job.Status)
+	case job.Expires == nil:
+		return nil, nil, fmt.Errorf("%w: the backup was streamed to %s", ErrNoDownload, job.Location)
+	case m.now().After(*job.Expires):
+		return nil, nil, ErrJobNotFound
+	}
+
+	f, err := m.Open(job.Name)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return f, job, nil
+}
+
+// SubscribeJobs returns a channel that receives a copy of a job whenever it
+// progresses, until cancel is called. Updates are dropped for slow
+// subscribers.
+func (m *Manager) SubscribeJobs() (<-chan Job, func()) {
+	ch := make(chan Job, subscriberSize)
+
+	m.jobsMu.Lock()
+	m.subscribers[ch] = struct{}{}
+	m.jobsMu.Unlock()
+
+	return ch, func() {
+		m.jobsMu.Lock()
+		delete(m.subscribers, ch)
+		m.jobsMu.Unlock()
+	}
+}
+
+// publish sends a job to the subscribers. The caller must hold jobsMu.
+func (m *Manager) publish(job Job) {
+	for ch := range m.subscribers {
+		select {
+		case ch <- job:
+		default:
+		}
+	}
+}
+
+// pruneJobs removes the jobs that finished more than a day ago. The caller
+// must hold jobsMu.
+func (m *Manager) pruneJobs(now time.Time) {
+	for id, job := range m.jobs {
+		if job.Finished != nil && now.Sub(*job.Finished) > jobRetention {
+			delete(m.jobs, id)
+		}
+	}
+}
+
+// progressWriter updates the size of a job.
+type progressWriter struct {
+	w         TargetWriter
+	m         *Manager
+	job       *Job
+	published time.Time
+}
+
+func (p *progressWriter) Write(b []byte) (int, error) {
+	n, err := p.w.Write(b)
+
+	p.m.jobsMu.Lock()
+	defer p.m.jobsMu.Unlock()
+
+	p.job.Size += int64(n)
+
+	if time.Since(p.published) >= sizeInterval {
+		p.published = time.Now()
+		p.m.publish(*p.job)
+	}
+
+	return n, err
+}
@@ -0,0 +1,357 @@
+package backup
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/SecurityBrewery/catalyst/app/data"
+	"github.com/SecurityBrewery/catalyst/app/migration"
+	"github.com/SecurityBrewery/catalyst/app/settings"
+	"github.com/SecurityBrewery/catalyst/app/upload"
+)
+
+// memoryTarget stores backups in memory, or fails after the first write.
+type memoryTarget struct {
+	fail    bool
+	stored  map[string][]byte
+	aborted bool
+}
+
+type memoryWriter struct {
+	bytes.Buffer
+
+	target *memoryTarget
+	name   string
+}
+
+func (t *memoryTarget) Create(_ context.Context, name string) (TargetWriter, error) {
+	return &memoryWriter{target: t, name: name}, nil
+}
+
+func (t *memoryTarget) Location(name string) string {
+	return "memory://" + name
+}
+
+func (w *memoryWriter) Write(p []byte) (int, error) {
+	if w.target.fail && w.Len() > 0 {
+		return 0, errors.New("connection reset")
+	}
+
+	return w.Buffer.Write(p)
+}
+
+func (w *memoryWriter) Close() error {
+	w.target.stored[w.name] = w.Bytes()
+
+	return nil
+}
+
+func (w *memoryWriter) Abort(context.Context) error {
+	w.target.aborted = true
+
+	return nil
+}
+
+func waitForJob(t *testing.T, m *Manager, id string) *Job {
+	t.Helper()
+
+	var job *Job
+
+	require.Eventually(t, func() bool {
+		var err error
+
+		job, err = m.Job(id)
+		require.NoError(t, err)
+
+		return job.Status != JobRunning
+	}, 10*time.Second, 10*time.Millisecond)
+
+	return job
+}
+
+func TestManager_Stream(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	queries := data.NewTestDB(t, dir)
+
+	uploader, err := upload.New(dir)
+	require.NoError(t, err)
+
+	m, err := New(queries, uploader, dir, Config{})
+	require.NoError(t, err)
+
+	m.now = func() time.Time { return time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC) }
+
+	target := &memoryTarget{stored: map[string][]byte{}}
+
+	updates, cancel := m.SubscribeJobs()
+	defer cancel()
+
+	started, err := m.Stream(t.Context(), target)
+	require.NoError(t, err)
+	assert.Equal(t, JobRunning, started.Status)
+	assert.Equal(t, "memory://"+started.Name, started.Location)
+	assert.Regexp(t, `^catalyst-20250601-120000-[0-9a-f]{8}\.zip$`, started.Name)
+
+	job := waitForJob(t, m, started.ID)
+	assert.Equal(t, JobSucceeded, job.Status, job.Error)
+	require.NotNil(t, job.Finished)
+	assert.Equal(t, StageUploads, job.Stage)
+	assert.Equal(t, job.TotalFiles, job.Files)
+
+	// the subscribers follow the job from the start to the end
+	var stages []Stage
+
+	for update := range updates {
+		require.Equal(t, started.ID, update.ID)
+
+		if len(stages) == 0 || stages[len(stages)-1] != update.Stage {
+			stages = append(stages, update.Stage)
+		}
+
+		if update.Finished != nil {
+			assert.Equal(t, *job, update)
+
+			break
+		}
+	}
+
+	assert.Equal(t, []Stage{"", StageDatabase, StageUploads}, stages)
+
+	archive := target.stored[started.Name]
+	assert.Equal(t, int64(len(archive)), job.Size)
+
+	report := Verify(t.Context(), bytes.NewReader(archive), int64(len(archive)), migration.Latest())
+	assert.True(t, report.Valid, report.Errors)
+
+	// streamed backups are not stored in the backups folder
+	backups, err := m.List()
+	require.NoError(t, err)
+	assert.Empty(t, backups)
+
+	_, err = m.Job("bj_unknown")
+	require.ErrorIs(t, err, ErrJobNotFound)
+}
+
+func TestManager_Stream_failed(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	queries := data.NewTestDB(t, dir)
+
+	uploader, err := upload.New(dir)
+	require.NoError(t, err)
+
+	m, err := New(queries, uploader, dir, Config{})
+	require.NoError(t, err)
+
+	target := &memoryTarget{fail: true, stored: map[string][]byte{}}
+
+	started, err := m.Stream(t.Context(), target)
+	require.NoError(t, err)
+
+	job := waitForJob(t, m, started.ID)
+	assert.Equal(t, JobFailed, job.Status)
+	assert.Contains(t, job.Error, "connection reset")
+	assert.True(t, target.aborted)
+	assert.Empty(t, target.stored)
+}
+
+func TestManager_CreateJob(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	queries := data.NewTestDB(t, dir)
+
+	uploader, err := upload.New(dir)
+	require.NoError(t, err)
+
+	m, err := New(queries, uploader, dir, Config{})
+	require.NoError(t, err)
+
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	m.now = func() time.Time { return now }
+
+	_, err = m.CreateJob(t.Context(), "catalyst-20990101-000000.zip")
+	require.ErrorIs(t, err, ErrNotFound)
+
+	_, err = m.CreateJob(t.Context(), "", "users")
+	require.ErrorIs(t, err, ErrInvalidExclusion)
+
+	started, err := m.CreateJob(t.Context(), "", "logs")
+	require.NoError(t, err)
+	assert.Equal(t, started.Name, started.Location)
+
+	_, _, err = m.Download(started.ID)
+	require.ErrorIs(t, err, ErrNoDownload)
+
+	job := waitForJob(t, m, started.ID)
+	assert.Equal(t, JobSucceeded, job.Status, job.Error)
+	require.NotNil(t, job.Expires)
+	assert.Equal(t, now.Add(jobRetention), *job.Expires)
+
+	// the backup is stored like a created one, without the temporary file
+	backups, err := m.List()
+	require.NoError(t, err)
+	require.Len(t, backups, 1)
+	assert.Equal(t, started.Name, backups[0].Name)
+	assert.Equal(t, []string{"logs"}, backups[0].Excluded)
+
+	partial, err := filepath.Glob(filepath.Join(m.dir, ".partial-*"))
+	require.NoError(t, err)
+	assert.Empty(t, partial)
+
+	f, downloaded, err := m.Download(started.ID)
+	require.NoError(t, err)
+
+	t.Cleanup(func() { f.Close() })
+
+	info, err := f.Stat()
+	require.NoError(t, err)
+	assert.Equal(t, job.Size, info.Size())
+	assert.Equal(t, started.Name, downloaded.Name)
+
+	report := Verify(t.Context(), f, info.Size(), migration.Latest())
+	assert.True(t, report.Valid, report.Errors)
+
+	// an incremental job refers to its base
+	now = now.Add(time.Hour)
+
+	incremental, err := m.CreateJob(t.Context(), started.Name)
+	require.NoError(t, err)
+	assert.Equal(t, started.Name, incremental.Base)
+	assert.Equal(t, JobSucceeded, waitForJob(t, m, incremental.ID).Status)
+
+	// the download expires with the job
+	now = now.Add(jobRetention + time.Hour)
+
+	_, _, err = m.Download(started.ID)
+	require.ErrorIs(t, err, ErrJobNotFound)
+
+	// streamed backups cannot be downloaded
+	streamed, err := m.Stream(t.Context(), &memoryTarget{stored: map[string][]byte{}})
+	require.NoError(t, err)
+	waitForJob(t, m, streamed.ID)
+
+	_, _, err = m.Download(streamed.ID)
+	require.ErrorIs(t, err, ErrNoDownload)
+}
+
+func TestNewStorageTarget(t *testing.T) {
+	t.Parallel()
+
+	config := &settings.BackupStorage{
+		Endpoint:        "https://s3.eu-central-1.amazonaws.com",
+		Region:          "eu-central-1",
+		Bucket:          "backups",
+		AzureAccount:    "catalyst",
+		AzureAccountKey: "a2V5",
+		AzureContainer:  "backups",
+		GCSBucket:       "backups",
+		GCSAccessKeyID:  "GOOG1E",
+		GCSSecret:       "secret",
+	}
+
+	for target, location := range map[string]string{
+		"s3://backups/catalyst/prod/":    "s3://backups/catalyst/prod/catalyst-20250601-120000.zip",
+		"s3://backups":                   "s3://backups/catalyst-20250601-120000.zip",
+		"azure://backups/catalyst/prod/": "azure://backups/catalyst/prod/catalyst-20250601-120000.zip",
+		"gs://backups/catalyst/prod/":    "gs://backups/catalyst/prod/catalyst-20250601-120000.zip",
+	} {
+		got, err := NewTarget(target, config)
+		require.NoError(t, err, target)
+		assert.IsType(t, &StorageTarget{}, got)
+		assert.Equal(t, location, got.Location("catalyst-20250601-120000.zip"))
+	}
+
+	for _, invalid := range []string{
+		"https://backups/catalyst", "s3:///catalyst", "s3://other/catalyst", "%",
+		"azure:///catalyst", "azure://other/catalyst",
+		"gs:///catalyst", "gs://other/catalyst",
+	} {
+		_, err := NewTarget(invalid, config)
+		require.ErrorIs(t, err, ErrInvalidTarget, invalid)
+	}
+
+	// the backup storage is not configured
+	for _, target := range []string{"s3://backups/catalyst", "azure://backups/catalyst", "gs://backups/catalyst"} {
+		_, err := NewTarget(target, &settings.BackupStorage{})
+		require.ErrorIs(t, err, ErrInvalidTarget, target)
+	}
+
+	_, err := NewTarget("azure://backups", &settings.BackupStorage{AzureAccount: "catalyst", AzureAccountKey: "not base64!", AzureContainer: "backups"})
+	require.ErrorIs(t, err, ErrInvalidTarget)
+}
+
+func TestNewDirTarget(t *testing.T) {
+	t.Parallel()
+
+	storage := &settings.BackupStorage{Directory: "/mnt/backups"}
+
+	target, err := NewDirTarget("file:///mnt/backups/catalyst/", storage)
+	require.NoError(t, err)
+	assert.Equal(t, "file:///mnt/backups/catalyst/catalyst-20250601-120000.zip", target.Location("catalyst-20250601-120000.zip"))
+
+	for _, invalid := range []string{"file://host/mnt/backups", "file:///mnt/other", "file:///mnt/backups/../other", "file:relative", "s3://mnt/backups"} {
+		_, err = NewDirTarget(invalid, storage)
+		require.ErrorIs(t, err, ErrInvalidTarget, invalid)
+	}
+
+	_, err = NewDirTarget("file:///mnt/backups", &settings.BackupStorage{})
+	require.ErrorIs(t, err, ErrInvalidTarget)
+
+	_, err = NewTarget("https://backups/catalyst", storage)
+	require.ErrorIs(t, err, ErrInvalidTarget)
+}
+
+func TestManager_Stream_directory(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	queries := data.NewTestDB(t, dir)
+
+	uploader, err := upload.New(dir)
+	require.NoError(t, err)
+
+	m, err := New(queries, uploader, dir, Config{})
+	require.NoError(t, err)
+
+	m.now = func() time.Time { return time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC) }
+
+	storage := &settings.BackupStorage{Directory: t.TempDir()}
+	backupDir := filepath.Join(storage.Directory, "catalyst")
+
+	target, err := NewTarget("file://"+filepath.ToSlash(backupDir), storage)
+	require.NoError(t, err)
+
+	started, err := m.Stream(t.Context(), target)
+	require.NoError(t, err)
+	assert.Equal(t, "file://"+filepath.ToSlash(filepath.Join(backupDir, started.Name)), started.Location)
+
+	job := waitForJob(t, m, started.ID)
+	assert.Equal(t, JobSucceeded, job.Status, job.Error)
+	assert.Nil(t, job.Expires)
+
+	// the backup can be restored like a stored one
+	f, err := os.Open(filepath.Join(backupDir, started.Name))
+	require.NoError(t, err)
+
+	defer f.Close()
+
+	info, err := f.Stat()
+	require.NoError(t, err)
+	assert.Equal(t, job.Size, info.Size())
+
+	restored, err := Restore(t.Context(), f, info.Size(), t.TempDir(), backupDir, nil, Selection{})
+	require.NoError(t, err)
+	assert.Equal(t, migration.Latest(), restored.Schema)
+}
@@ -0,0 +1,140 @@
+package backup
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/SecurityBrewery/catalyst/app/settings"
+)
+
+// notifyTimeout limits the request to the backup webhook, which a created
+// backup waits for.
+const notifyTimeout = 10 * time.Second
+
+// Notification is posted to the backup webhook when a backup finished or
+// failed.
+type Notification struct {
+	Status JobStatus `json:"status"`
+	// Job is set for backups that were written in the background.
+	Job  string `json:"job,omitempty"`
+	Name string `json:"name"`
+	// Location is the name in the backups folder or the location in a
+	// target, like s3://bucket/key.
+	Location string `json:"location"`
+	// Download is the API URL of a backup in the backups folder.
+	Download string `json:"download,omitempty"`
+	Size     int64  `json:"size"`
+	// Duration in seconds.
+	Duration float64   `json:"duration"`
+	Error    string    `json:"error,omitempty"`
+	Created  time.Time `json:"created"`
+	Finished time.Time `json:"finished"`
+}
+
+// notify posts a finished job to the webhook of the backup notification
+// settings. Errors are logged, they do not fail the backup.
+func (m *Manager) notify(ctx context.Context, job Job, stored bool) {
+	se, err := settings.Load(ctx, m.queries)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to load settings for the backup notification", "error", err)
+
+		return
+	}
+
+	config := se.BackupNotification
+	if config.URL == "" {
+		return
+	}
+
+	notification := newNotification(job, stored, se.Meta.AppURL)
+
+	var payload any = notification
+	if config.Slack {
+		payload = map[string]string{"text": slackText(notification)}
+	}
+
+	if err := m.postNotification(ctx, config, payload); err != nil {
+		slog.ErrorContext(ctx, "Failed to send backup notification", "backup", job.Name, "error", err)
+	}
+}
+
+func newNotification(job Job, stored bool, appURL string) Notification {
+	notification := Notification{
+		Status:   job.Status,
+		Name:     job.Name,
+		Location: job.Location,
+		Size:     job.Size,
+		Error:    job.Error,
+		Created:  job.Created,
+	}
+
+	if job.ID != "" {
+		notification.Job = job.ID
+	}
+
+	if job.Finished != nil {
+		notification.Finished = *job.Finished
+		notification.Duration = job.Finished.Sub(job.Created).Seconds()
+	}
+
+	if stored && job.Status == JobSucceeded {
+		notification.Download = strings.TrimSuffix(appURL, "/") + "/api/backups/" + url.PathEscape(job.Name)
+	}
+
+	return notification
+}
+
+func slackText(n Notification) string {
+	duration := time.Duration(n.Duration * float64(time.Second)).Round(time.Second)
+
+	if n.Status == JobFailed {
+		return fmt.Sprintf("Backup %s failed after %s: %s", n.Name, duration, n.Error)
+	}
+
+	text := fmt.Sprintf("Backup %s succeeded in %s, %.1f MB stored at %s", n.Name, duration, float64(n.Size)/1e6, n.Location)
+	if n.Download != "" {
+		text += fmt.Sprintf(" <%s|download>", n.Download)
+	}
+
+	return text
+}
+
+func (m *Manager) postNotification(ctx context.Context, config settings.BackupNotification, payload any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, config.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	for key, value := range config.Headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+
+		return fmt.Errorf("%s: %s", resp.Status, message)
+	}
+
+	return nil
+}
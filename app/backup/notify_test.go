@@ -0,0 +1,73 @@
+package backup
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/SecurityBrewery/catalyst/app/data"
+	"github.com/SecurityBrewery/catalyst/app/settings"
+	"github.com/SecurityBrewery/catalyst/app/upload"
+)
+
+func TestManager_notify(t *testing.T) {
+	t.Parallel()
+
+	received := make(chan map[string]any, 10)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+
+		var payload map[string]any
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+
+		received <- payload
+	}))
+	t.Cleanup(server.Close)
+
+	dir := t.TempDir()
+	queries := data.NewTestDB(t, dir)
+
+	uploader, err := upload.New(dir)
+	require.NoError(t, err)
+
+	m, err := New(queries, uploader, dir, Config{})
+	require.NoError(t, err)
+
+	m.now = func() time.Time { return time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC) }
+
+	_, err = settings.Update(t.Context(), queries, func(se *settings.Settings) {
+		se.Meta.AppURL = "https://catalyst.example.com/"
+		se.BackupNotification = settings.BackupNotification{URL: server.URL, Headers: map[string]string{"Authorization": "Bearer token"}}
+	})
+	require.NoError(t, err)
+
+	info, err := m.Create(t.Context(), "")
+	require.NoError(t, err)
+
+	payload := <-received
+	assert.Equal(t, "succeeded", payload["status"])
+	assert.Equal(t, info.Name, payload["name"])
+	assert.InDelta(t, float64(info.Size), payload["size"], 0)
+	assert.Equal(t, "https://catalyst.example.com/api/backups/"+info.Name, payload["download"])
+	assert.NotContains(t, payload, "job")
+
+	_, err = settings.Update(t.Context(), queries, func(se *settings.Settings) {
+		se.BackupNotification.Slack = true
+	})
+	require.NoError(t, err)
+
+	started, err := m.Stream(t.Context(), &memoryTarget{fail: true, stored: map[string][]byte{}})
+	require.NoError(t, err)
+
+	waitForJob(t, m, started.ID)
+
+	payload = <-received
+	assert.Contains(t, payload["text"], "failed after 0s: ")
+	assert.Contains(t, payload["text"], "connection reset")
+}
@@ -0,0 +1,240 @@
+package backup
+
+import (
+	"archive/zip"
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/SecurityBrewery/catalyst/app/database/sqlc"
+	"github.com/SecurityBrewery/catalyst/app/migration"
+	"github.com/SecurityBrewery/catalyst/app/upload"
+)
+
+// Diff summarizes what restoring a backup would change. Added rows and
+// uploads are only in the backup and would be restored, removed ones are
+// only in the current data and would be lost.
+type Diff struct {
+	Created time.Time
+	Schema  int
+	Tables  []TableDiff
+	Uploads UploadDiff
+}
+
+// TableDiff counts the differing rows of a table by their primary key.
+type TableDiff struct {
+	Table   string
+	Added   int
+	Removed int
+	Changed int
+}
+
+type UploadDiff struct {
+	Added   []string
+	Removed []string
+	// Missing are the uploads of files in the backup database that are not
+	// in the backup archive.
+	Missing []string
+}
+
+// Preview compares a backup with the current database and uploads. Backups
+// of older versions are migrated to the current schema first, so that the
+// rows can be compared. Tables without a single column primary key and
+// tables that are excluded from the backup are skipped.
+func Preview(ctx context.Context, queries *sqlc.Queries, uploader *upload.Uploader, r io.ReaderAt, size int64) (*Diff, error) {
+	report := Verify(ctx, r, size, migration.Latest())
+	if !report.Valid {
+		return nil, fmt.Errorf("%w: %s", ErrInvalid, strings.Join(reportErrors(report), "; "))
+	}
+
+	archive, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, err
+	}
+
+	tmp, err := os.MkdirTemp("", "catalyst-preview")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmp)
+
+	restored, err := restoredUploads(archive)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := extract(findEntry(archive, DatabaseName), filepath.Join(tmp, DatabaseName)); err != nil {
+		return nil, fmt.Errorf("failed to extract database: %w", err)
+	}
+
+	if err := migrate(ctx, tmp); err != nil {
+		return nil, fmt.Errorf("failed to migrate backup from schema version %d: %w", *report.Schema, err)
+	}
+
+	current := filepath.Join(tmp, "current.db")
+	if _, err := queries.WriteDB.ExecContext(ctx, "VACUUM INTO ?", current); err != nil {
+		return nil, fmt.Errorf("failed to snapshot database: %w", err)
+	}
+
+	diff := &Diff{Created: *report.Created, Schema: *report.Schema}
+
+	if err := diffDatabases(ctx, filepath.Join(tmp, DatabaseName), current, restored, diff); err != nil {
+		return nil, err
+	}
+
+	diff.Tables = slices.DeleteFunc(diff.Tables, func(table TableDiff) bool {
+		return slices.Contains(report.Excluded, table.Table)
+	})
+
+	if err := diffUploads(ctx, uploader, restored, diff); err != nil {
+		return nil, err
+	}
+
+	return diff, nil
+}
+
+// restoredUploads returns the uploads of a backup. The uploads of an
+// incremental backup include the unchanged ones of its bases.
+func restoredUploads(archive *zip.Reader) (map[string]bool, error) {
+	manifest, err := readManifest(findEntry(archive, ManifestName))
+	if err != nil {
+		return nil, err
+	}
+
+	restored := map[string]bool{}
+
+	for _, file := range manifest.Files {
+		if name, ok := strings.CutPrefix(file.Path, uploadsDir+"/"); ok {
+			restored[name] = true
+		}
+	}
+
+	return restored, nil
+}
+
+func diffDatabases(ctx context.Context, backupDB, currentDB string, restored map[string]bool, diff *Diff) error {
+	db, err := sql.Open("sqlite3", "file:"+backupDB)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	// attached databases belong to a single connection
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, "ATTACH DATABASE ? AS current", "file:"+currentDB+"?mode=ro"); err != nil {
+		return fmt.Errorf("failed to attach current database: %w", err)
+	}
+
+	tables, err := primaryKeys(ctx, conn)
+	if err != nil {
+		return err
+	}
+
+	for _, table := range tables {
+		t, pk := quote(table[0]), quote(table[1])
+
+		var tableDiff TableDiff
+
+		if err := conn.QueryRowContext(ctx, fmt.Sprintf(`SELECT
+			(SELECT count(*) FROM main.%[1]s WHERE %[2]s NOT IN (SELECT %[2]s FROM current.%[1]s)),
+			(SELECT count(*) FROM current.%[1]s WHERE %[2]s NOT IN (SELECT %[2]s FROM main.%[1]s)),
+			(SELECT count(*) FROM (SELECT * FROM main.%[1]s EXCEPT SELECT * FROM current.%[1]s) WHERE %[2]s IN (SELECT %[2]s FROM current.%[1]s))`,
+			t, pk)).Scan(&tableDiff.Added, &tableDiff.Removed, &tableDiff.Changed); err != nil {
+			return fmt.Errorf("failed to compare table %s: %w", table[0], err)
+		}
+
+		if tableDiff.Added+tableDiff.Removed+tableDiff.Changed > 0 {
+			tableDiff.Table = table[0]
+			diff.Tables = append(diff.Tables, tableDiff)
+		}
+	}
+
+	rows, err := conn.QueryContext(ctx, "SELECT id, blob FROM main.files ORDER BY id")
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id, blob string
+		if err := rows.Scan(&id, &blob); err != nil {
+			return err
+		}
+
+		if name := path.Join(id, blob); !restored[name] {
+			diff.Uploads.Missing = append(diff.Uploads.Missing, name)
+		}
+	}
+
+	return rows.Err()
+}
+
+// primaryKeys returns the tables with a single column primary key and the
+// name of that column.
+func primaryKeys(ctx context.Context, conn *sql.Conn) ([][2]string, error) {
+	rows, err := conn.QueryContext(ctx, `SELECT m.name, min(p.name)
+		FROM main.sqlite_master m, pragma_table_info(m.name) p
+		WHERE m.type = 'table' AND m.name NOT LIKE 'sqlite_%' AND p.pk > 0
+		GROUP BY m.name
+		HAVING count(*) = 1
+		ORDER BY m.name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tables [][2]string
+
+	for rows.Next() {
+		var table [2]string
+		if err := rows.Scan(&table[0], &table[1]); err != nil {
+			return nil, err
+		}
+
+		tables = append(tables, table)
+	}
+
+	return tables, rows.Err()
+}
+
+func quote(identifier string) string {
+	return `"` + strings.ReplaceAll(identifier, `"`, `""`) + `"`
+}
+
+func diffUploads(ctx context.Context, uploader *upload.Uploader, restored map[string]bool, diff *Diff) error {
+	current := map[string]bool{}
+
+	if err := uploader.Storage.Walk(ctx, func(name string, _ int64) error {
+		current[name] = true
+
+		if !restored[name] {
+			diff.Uploads.Removed = append(diff.Uploads.Removed, name)
+		}
+
+		return nil
+	}); err != nil {
+		return fmt.Errorf("failed to list uploads: %w", err)
+	}
+
+	for name := range restored {
+		if !current[name] {
+			diff.Uploads.Added = append(diff.Uploads.Added, name)
+		}
+	}
+
+	slices.Sort(diff.Uploads.Added)
+
+	return nil
+}
@@ -0,0 +1,63 @@
+package backup
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/SecurityBrewery/catalyst/app/data"
+	"github.com/SecurityBrewery/catalyst/app/database/sqlc"
+	"github.com/SecurityBrewery/catalyst/app/pointer"
+	"github.com/SecurityBrewery/catalyst/app/upload"
+)
+
+func TestPreview(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	dir := t.TempDir()
+	queries := data.NewTestDB(t, dir)
+
+	uploader, err := upload.New(dir)
+	require.NoError(t, err)
+
+	// a file without upload
+	_, err = queries.InsertFile(ctx, sqlc.InsertFileParams{
+		ID: "b_lost", Name: "lost.txt", Blob: "lost.txt", Size: 4, Ticket: "test-ticket",
+		Created: time.Now(), Updated: time.Now(),
+	})
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, Write(ctx, queries, uploader, time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC), nil, &buf))
+
+	archive := downgrade(t, buf.Bytes())
+
+	// change the current data after the backup
+	_, err = queries.UpdateTicket(ctx, sqlc.UpdateTicketParams{ID: "test-ticket", Name: pointer.Pointer("renamed")})
+	require.NoError(t, err)
+	require.NoError(t, queries.DeleteComment(ctx, "c_test_comment"))
+
+	_, err = uploader.CreateFile(t.Context(), "b_new", "new.txt", []byte("new"))
+	require.NoError(t, err)
+
+	diff, err := Preview(ctx, queries, uploader, bytes.NewReader(archive), int64(len(archive)))
+	require.NoError(t, err)
+
+	assert.Equal(t, time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC), diff.Created)
+
+	tables := map[string]TableDiff{}
+	for _, table := range diff.Tables {
+		tables[table.Table] = table
+	}
+
+	assert.Equal(t, TableDiff{Table: "tickets", Changed: 1}, tables["tickets"])
+	assert.Equal(t, TableDiff{Table: "comments", Added: 1}, tables["comments"])
+
+	assert.Empty(t, diff.Uploads.Added)
+	assert.Len(t, diff.Uploads.Removed, 2)
+	assert.Equal(t, []string{"b_lost/lost.txt"}, diff.Uploads.Missing)
+}
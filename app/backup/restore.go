@@ -0,0 +1,644 @@
+package backup
+
+import (
+	"archive/zip"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/SecurityBrewery/catalyst/app/casekey"
+	"github.com/SecurityBrewery/catalyst/app/database"
+	"github.com/SecurityBrewery/catalyst/app/migration"
+	"github.com/SecurityBrewery/catalyst/app/upload"
+)
+
+var (
+	ErrInvalid          = errors.New("backup is invalid")
+	ErrInvalidSelection = errors.New("invalid selection")
+)
+
+// restored are the files and folders of the data directory that are
+// replaced by a restore.
+var restored = []string{DatabaseName, DatabaseName + "-wal", DatabaseName + "-shm", uploadsDir}
+
+// Selection restricts a restore to some tables of the database and the
+// uploads of some files, the rest of the current data is kept. An empty
+// selection restores everything.
+type Selection struct {
+	Tables []string
+	// Uploads are the ids of the files whose uploads are restored.
+	Uploads []string
+}
+
+func (s Selection) all() bool {
+	return len(s.Tables) == 0 && len(s.Uploads) == 0
+}
+
+// check fails if a selected table is excluded from the backup.
+func (s Selection) check(excluded []string) error {
+	for _, table := range s.Tables {
+		if slices.Contains(excluded, table) {
+			return fmt.Errorf("%w: the rows of table %s are excluded from the backup", ErrInvalidSelection, table)
+		}
+	}
+
+	return nil
+}
+
+// names returns the files and folders of the data directory that are
+// replaced by a restore of the selection.
+func (s Selection) names() []string {
+	if s.all() {
+		return restored
+	}
+
+	var names []string
+
+	if len(s.Tables) > 0 {
+		names = append(names, DatabaseName, DatabaseName+"-wal", DatabaseName+"-shm")
+	}
+
+	for _, id := range s.Uploads {
+		names = append(names, filepath.Join(uploadsDir, id), filepath.Join(uploadsDir, id+".info"))
+	}
+
+	return names
+}
+
+// Restored describes a restored backup.
+type Restored struct {
+	// Version is the Catalyst release that wrote the backup.
+	Version string
+	// Schema is the schema version of the backup before it was migrated.
+	Schema int
+	// Previous is the folder that holds the replaced data.
+	Previous string
+	// Kept are the tables that are excluded from the backup, whose current
+	// rows were kept.
+	Kept []string
+}
+
+// Restore replaces the database and the uploaded files in the data
+// directory with the content of a backup archive. The backup is verified
+// and its schema version is read from the manifest. Backups of older
+// versions are migrated to the current schema before they replace the
+// current data, so an invalid backup or a failed migration leaves the data
+// directory untouched. The replaced data is moved to a folder in the data
+// directory. The unchanged files of an incremental backup are read from its
+// bases in baseDir. Encrypted backups and bases are decrypted with the
+// key. A restore of a selection replaces the rows of the selected tables in
+// the current database and the uploads of the selected files, so a single
+// type of data can be recovered without losing the other changes since the
+// backup. The current rows of the tables that are excluded from the backup
+// are kept. A backup with encrypted tickets is only restored if the master
+// key of the data directory decrypts them, the master key is not in the
+// backup. Catalyst must not run during a restore. The uploads are
+// restored to the data directory, with another storage they are moved there
+// on the next start.
+func Restore(ctx context.Context, r io.ReaderAt, size int64, dir, baseDir string, key *Key, selection Selection) (*Restored, error) {
+	r, size, err := Archive(r, size, key)
+	if err != nil {
+		return nil, err
+	}
+
+	report := Verify(ctx, r, size, migration.Latest())
+	if !report.Valid {
+		return nil, fmt.Errorf("%w: %s", ErrInvalid, strings.Join(reportErrors(report), "; "))
+	}
+
+	if err := selection.check(report.Excluded); err != nil {
+		return nil, err
+	}
+
+	archive, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, err
+	}
+
+	// the staging folder is in the data directory, so that it can be
+	// renamed into place
+	staging, err := os.MkdirTemp(dir, ".restore-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(staging)
+
+	if err := extractAll(archive, staging); err != nil {
+		return nil, fmt.Errorf("failed to extract backup: %w", err)
+	}
+
+	if err := extractBases(archive, staging, baseDir, key); err != nil {
+		return nil, fmt.Errorf("failed to extract the unchanged files of the incremental backup: %w", err)
+	}
+
+	if err := migrate(ctx, staging); err != nil {
+		return nil, fmt.Errorf("failed to migrate backup from schema version %d: %w", *report.Schema, err)
+	}
+
+	if err := checkUploads(staging, selection.Uploads); err != nil {
+		return nil, err
+	}
+
+	var kept []string
+
+	if selection.all() {
+		if err := keepExcluded(ctx, filepath.Join(dir, DatabaseName), staging, report.Excluded); err != nil {
+			return nil, err
+		}
+
+		kept = report.Excluded
+	}
+
+	if len(selection.Tables) > 0 {
+		merged := filepath.Join(staging, "merged.db")
+
+		if err := snapshot(ctx, filepath.Join(dir, DatabaseName), merged); err != nil {
+			return nil, err
+		}
+
+		if err := mergeTables(ctx, staging, merged, selection.Tables); err != nil {
+			return nil, err
+		}
+	}
+
+	if selection.all() || len(selection.Tables) > 0 {
+		if err := checkCaseKey(ctx, dir, filepath.Join(staging, DatabaseName)); err != nil {
+			return nil, err
+		}
+	}
+
+	previous := filepath.Join(dir, "restore-previous-"+time.Now().UTC().Format("20060102-150405"))
+	if err := os.Mkdir(previous, 0o700); err != nil {
+		return nil, err
+	}
+
+	names := selection.names()
+
+	if err := move(dir, previous, names); err != nil {
+		return nil, fmt.Errorf("failed to move the current data: %w", err)
+	}
+
+	if err := move(staging, dir, names); err != nil {
+		return nil, fmt.Errorf("failed to move the restored data, the previous data is in %s: %w", previous, err)
+	}
+
+	return &Restored{Version: report.Version, Schema: *report.Schema, Previous: previous, Kept: kept}, nil
+}
+
+// checkCaseKey checks that the master key of the data directory decrypts
+// the encrypted tickets of the restored database. Backups do not contain the
+// master key, it must be imported before a backup with encrypted tickets is
+// restored on another instance.
+func checkCaseKey(ctx context.Context, dir, restored string) error {
+	db, err := sql.Open("sqlite3", "file:"+restored)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	var wrapped string
+
+	err = db.QueryRowContext(ctx, "SELECT key FROM ticket_keys LIMIT 1").Scan(&wrapped)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("failed to read case keys: %w", err)
+	}
+
+	if err := casekey.Check(dir, wrapped); err != nil {
+		return fmt.Errorf("the encrypted tickets of the backup cannot be decrypted, import the master key of the backed up instance first: %w", err)
+	}
+
+	return nil
+}
+
+func reportErrors(report *Report) []string {
+	errs := report.Errors
+
+	for _, file := range report.Files {
+		if !file.Valid {
+			errs = append(errs, fmt.Sprintf("%s: %s", file.Path, file.Error))
+		}
+	}
+
+	return errs
+}
+
+func extractAll(archive *zip.Reader, dir string) error {
+	for _, entry := range archive.File {
+		if entry.Name == ManifestName {
+			continue
+		}
+
+		if entry.Name != DatabaseName && (!strings.HasPrefix(entry.Name, uploadsDir+"/") || !filepath.IsLocal(entry.Name)) {
+			return fmt.Errorf("unexpected file %s", entry.Name)
+		}
+
+		filename := filepath.Join(dir, filepath.FromSlash(entry.Name))
+
+		if err := os.MkdirAll(filepath.Dir(filename), 0o755); err != nil {
+			return err
+		}
+
+		if err := extract(entry, filename); err != nil {
+			return err
+		}
+	}
+
+	return os.MkdirAll(filepath.Join(dir, uploadsDir), 0o755)
+}
+
+// extractBases extracts the files of an incremental backup that are held by
+// its bases. The files are verified against the manifest of the backup.
+func extractBases(archive *zip.Reader, dir, baseDir string, key *Key) error {
+	manifest, err := readManifest(findEntry(archive, ManifestName))
+	if err != nil {
+		return err
+	}
+
+	bases := newBaseArchives(baseDir, key)
+	defer bases.Close()
+
+	for _, file := range manifest.Files {
+		if file.Base == "" {
+			continue
+		}
+
+		if !strings.HasPrefix(file.Path, uploadsDir+"/") || !filepath.IsLocal(file.Path) {
+			return fmt.Errorf("unexpected file %s", file.Path)
+		}
+
+		base, err := bases.open(file.Base)
+		if err != nil {
+			return err
+		}
+
+		entry := findEntry(base, file.Path)
+		if err := verifyFile(entry, &file); err != nil {
+			return fmt.Errorf("%s in the base %s: %w", file.Path, file.Base, err)
+		}
+
+		filename := filepath.Join(dir, filepath.FromSlash(file.Path))
+
+		if err := os.MkdirAll(filepath.Dir(filename), 0o755); err != nil {
+			return err
+		}
+
+		if err := extract(entry, filename); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// baseArchives opens the bases of an incremental backup once each.
+type baseArchives struct {
+	dir      string
+	key      *Key
+	archives map[string]*zip.Reader
+	files    []*os.File
+}
+
+func newBaseArchives(dir string, key *Key) *baseArchives {
+	return &baseArchives{dir: dir, key: key, archives: map[string]*zip.Reader{}}
+}
+
+func (b *baseArchives) open(name string) (*zip.Reader, error) {
+	if archive, ok := b.archives[name]; ok {
+		return archive, nil
+	}
+
+	if !validName.MatchString(name) {
+		return nil, fmt.Errorf("invalid base %s", name)
+	}
+
+	f, err := os.Open(filepath.Join(b.dir, name))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open the base %s: %w", name, err)
+	}
+
+	b.files = append(b.files, f)
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	r, size, err := Archive(f, info.Size(), b.key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open the base %s: %w", name, err)
+	}
+
+	archive, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base %s: %w", name, err)
+	}
+
+	b.archives[name] = archive
+
+	return archive, nil
+}
+
+func (b *baseArchives) Close() {
+	for _, f := range b.files {
+		f.Close()
+	}
+}
+
+// migrate applies the migrations to the database in the directory.
+func migrate(ctx context.Context, dir string) error {
+	uploader, err := upload.New(dir)
+	if err != nil {
+		return err
+	}
+	defer uploader.Root.Close()
+
+	queries, cleanup, err := database.DB(ctx, dir)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	return migration.Apply(ctx, queries, dir, uploader)
+}
+
+// checkUploads checks that the uploads of the files are in the backup.
+func checkUploads(staging string, ids []string) error {
+	for _, id := range ids {
+		if !filepath.IsLocal(id) || strings.ContainsAny(id, `/\`) {
+			return fmt.Errorf("%w: invalid upload %q", ErrInvalidSelection, id)
+		}
+
+		if _, err := os.Stat(filepath.Join(staging, uploadsDir, id+".info")); err != nil {
+			return fmt.Errorf("%w: the upload %s is not in the backup", ErrInvalidSelection, id)
+		}
+	}
+
+	return nil
+}
+
+// mergeTables replaces the rows of the tables in the copy of the current
+// database with the rows of the backup and moves the copy in place of the
+// database in the staging folder. The tables must not break the references
+// of the other tables.
+func mergeTables(ctx context.Context, staging, merged string, tables []string) error {
+	backupDB := filepath.Join(staging, DatabaseName)
+
+	db, err := sql.Open("sqlite3", "file:"+merged)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	// attached databases belong to a single connection
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, "PRAGMA foreign_keys = OFF"); err != nil {
+		return err
+	}
+
+	if _, err := conn.ExecContext(ctx, "ATTACH DATABASE ? AS backup", "file:"+backupDB); err != nil {
+		return fmt.Errorf("failed to attach backup database: %w", err)
+	}
+
+	// the merged database is a copy, a failure leaves the current data
+	// untouched
+	for _, table := range tables {
+		if err := replaceTable(ctx, conn, table); err != nil {
+			return err
+		}
+	}
+
+	if err := checkReferences(ctx, conn); err != nil {
+		return err
+	}
+
+	if _, err := conn.ExecContext(ctx, "DETACH DATABASE backup"); err != nil {
+		return err
+	}
+
+	if err := conn.Close(); err != nil {
+		return err
+	}
+
+	if err := db.Close(); err != nil {
+		return err
+	}
+
+	for _, suffix := range []string{"-wal", "-shm"} {
+		if err := os.Remove(backupDB + suffix); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+
+	return os.Rename(merged, backupDB)
+}
+
+// keepExcluded copies the rows of the tables that are excluded from the
+// backup from the current database into the database in the staging folder.
+// There is nothing to keep without a current database.
+func keepExcluded(ctx context.Context, current, staging string, tables []string) error {
+	if len(tables) == 0 {
+		return nil
+	}
+
+	if _, err := os.Stat(current); errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+
+	kept := filepath.Join(staging, "kept.db")
+	defer os.Remove(kept)
+
+	if err := snapshot(ctx, current, kept); err != nil {
+		return err
+	}
+
+	return copyTables(ctx, filepath.Join(staging, DatabaseName), kept, tables)
+}
+
+// copyTables replaces the rows of the tables in the target database with the
+// rows of the source database. Copied rows that reference rows that are not
+// in the target, like runs of reactions that are not in the backup, are
+// dropped.
+func copyTables(ctx context.Context, target, source string, tables []string) error {
+	db, err := sql.Open("sqlite3", "file:"+target)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	// attached databases belong to a single connection
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, "PRAGMA foreign_keys = OFF"); err != nil {
+		return err
+	}
+
+	// replaceTable reads the rows from the database attached as backup
+	if _, err := conn.ExecContext(ctx, "ATTACH DATABASE ? AS backup", "file:"+source); err != nil {
+		return fmt.Errorf("failed to attach current database: %w", err)
+	}
+
+	for _, table := range tables {
+		if err := replaceTable(ctx, conn, table); err != nil {
+			return err
+		}
+
+		if _, err := conn.ExecContext(ctx, fmt.Sprintf("DELETE FROM main.%s WHERE rowid IN (SELECT rowid FROM main.pragma_foreign_key_check(?))", quote(table)), table); err != nil {
+			return fmt.Errorf("failed to drop the broken references of table %s: %w", table, err)
+		}
+	}
+
+	if _, err := conn.ExecContext(ctx, "DETACH DATABASE backup"); err != nil {
+		return err
+	}
+
+	if err := conn.Close(); err != nil {
+		return err
+	}
+
+	return db.Close()
+}
+
+// snapshot copies the current database, which must have the current schema.
+func snapshot(ctx context.Context, current, target string) error {
+	if _, err := os.Stat(current); err != nil {
+		return fmt.Errorf("%w: there is no current database to restore the tables into", ErrInvalidSelection)
+	}
+
+	db, err := sql.Open("sqlite3", "file:"+current)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	var version int
+	if err := db.QueryRowContext(ctx, "PRAGMA user_version").Scan(&version); err != nil {
+		return fmt.Errorf("failed to read the schema version of the current database: %w", err)
+	}
+
+	if version != migration.Latest() {
+		return fmt.Errorf("%w: the current database has schema version %d, migrate it to %d first", ErrInvalidSelection, version, migration.Latest())
+	}
+
+	if _, err := db.ExecContext(ctx, "VACUUM INTO ?", target); err != nil {
+		return fmt.Errorf("failed to snapshot database: %w", err)
+	}
+
+	return nil
+}
+
+func replaceTable(ctx context.Context, conn *sql.Conn, table string) error {
+	var exists bool
+	if err := conn.QueryRowContext(ctx, `SELECT count(*) > 0 FROM backup.sqlite_master
+		WHERE type = 'table' AND name = ? AND name NOT LIKE 'sqlite_%'`, table).Scan(&exists); err != nil {
+		return err
+	}
+
+	if !exists {
+		return fmt.Errorf("%w: unknown table %s", ErrInvalidSelection, table)
+	}
+
+	rows, err := conn.QueryContext(ctx, "SELECT name FROM pragma_table_info(?, 'main')", table)
+	if err != nil {
+		return err
+	}
+
+	var columns []string
+
+	for rows.Next() {
+		var column string
+		if err := rows.Scan(&column); err != nil {
+			rows.Close()
+
+			return err
+		}
+
+		columns = append(columns, quote(column))
+	}
+
+	rows.Close()
+
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	t, c := quote(table), strings.Join(columns, ", ")
+
+	if _, err := conn.ExecContext(ctx, fmt.Sprintf("DELETE FROM main.%s", t)); err != nil {
+		return fmt.Errorf("failed to clear table %s: %w", table, err)
+	}
+
+	if _, err := conn.ExecContext(ctx, fmt.Sprintf("INSERT INTO main.%[1]s (%[2]s) SELECT %[2]s FROM backup.%[1]s", t, c)); err != nil {
+		return fmt.Errorf("failed to restore table %s: %w", table, err)
+	}
+
+	return nil
+}
+
+// checkReferences fails if rows reference rows that do not exist, like
+// tickets of a restored type that is not in the backup.
+func checkReferences(ctx context.Context, conn *sql.Conn) error {
+	rows, err := conn.QueryContext(ctx, "SELECT DISTINCT \"table\", parent FROM main.pragma_foreign_key_check ORDER BY 1, 2")
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var broken []string
+
+	for rows.Next() {
+		var table, parent string
+		if err := rows.Scan(&table, &parent); err != nil {
+			return err
+		}
+
+		broken = append(broken, table+" -> "+parent)
+	}
+
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	if len(broken) > 0 {
+		return fmt.Errorf("%w: the restored tables break references, select the referenced tables too: %s", ErrInvalidSelection, strings.Join(broken, ", "))
+	}
+
+	return nil
+}
+
+func move(from, to string, names []string) error {
+	for _, name := range names {
+		source, target := filepath.Join(from, name), filepath.Join(to, name)
+
+		if _, err := os.Lstat(source); errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+
+		if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+			return err
+		}
+
+		if err := os.Rename(source, target); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
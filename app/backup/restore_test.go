@@ -0,0 +1,358 @@
+package backup
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/SecurityBrewery/catalyst/app/casekey"
+	"github.com/SecurityBrewery/catalyst/app/data"
+	"github.com/SecurityBrewery/catalyst/app/database"
+	"github.com/SecurityBrewery/catalyst/app/database/sqlc"
+	"github.com/SecurityBrewery/catalyst/app/migration"
+	"github.com/SecurityBrewery/catalyst/app/upload"
+)
+
+// oldSchema is the schema version before 013_create_file_custody.
+const oldSchema = 14
+
+// downgrade turns a backup into a backup of an older schema version by
+// reverting the migrations since 013_create_file_custody.
+func downgrade(t *testing.T, archive []byte) []byte {
+	t.Helper()
+
+	var snapshot []byte
+
+	archive = rewrite(t, archive, func(name string, content []byte) []byte {
+		if name != DatabaseName {
+			return content
+		}
+
+		filename := filepath.Join(t.TempDir(), DatabaseName)
+		require.NoError(t, os.WriteFile(filename, content, 0o600))
+
+		db, err := sql.Open("sqlite3", filename)
+		require.NoError(t, err)
+
+		for _, table := range []string{"alert_mappings", "request_journal", "redaction_profiles", "artifact_sightings", "ticket_artifacts", "maintenance_windows", "platform_errors", "logs", "api_quotas", "api_usage", "invitations", "password_history", "work_entries", "queues", "alerts", "file_custody", "ticket_reopens", "auto_close_warnings", "auto_close_rules", "document_templates", "federation_links", "federation_peers", "ticket_key_sequences", "dlp_events", "ticket_activity", "announcement_receipts", "announcements", "detection_rule_tickets", "detection_rules", "known_exploited", "ticket_cves", "cves", "type_techniques", "ticket_techniques", "reaction_runs", "enrichment_cache", "feed_indicators", "feeds", "task_timers", "task_outputs", "campaign_timeline", "ticket_features", "campaign_tickets", "campaigns", "playbook_run_tasks", "playbook_runs", "playbooks"} {
+			_, err = db.ExecContext(t.Context(), "DROP TABLE "+table)
+			require.NoError(t, err)
+		}
+
+		for _, statement := range []string{"DROP INDEX idx_tickets_unassigned", "DROP TRIGGER ticket_key", "DROP INDEX idx_files_md5", "DROP INDEX idx_files_sha1", "DROP INDEX idx_files_sha256", "ALTER TABLE files DROP COLUMN md5", "ALTER TABLE files DROP COLUMN sha1", "ALTER TABLE files DROP COLUMN sha256", "ALTER TABLE tickets DROP COLUMN reopen_count", "ALTER TABLE tickets DROP COLUMN resolved_by", "DROP INDEX idx_tickets_key", "ALTER TABLE tickets DROP COLUMN key", "ALTER TABLE types DROP COLUMN metrics", "ALTER TABLE types DROP COLUMN key_prefix", "ALTER TABLE ticket_escalations DROP COLUMN paused", "ALTER TABLE ticket_escalations DROP COLUMN paused_since", "ALTER TABLE escalation_policies DROP COLUMN pause_states"} {
+			_, err = db.ExecContext(t.Context(), statement)
+			require.NoError(t, err)
+		}
+
+		_, err = db.ExecContext(t.Context(), "PRAGMA user_version = "+strconv.Itoa(oldSchema))
+		require.NoError(t, err)
+		require.NoError(t, db.Close())
+
+		snapshot, err = os.ReadFile(filename)
+		require.NoError(t, err)
+
+		return snapshot
+	})
+
+	return rewrite(t, archive, func(name string, content []byte) []byte {
+		if name != ManifestName {
+			return content
+		}
+
+		var manifest Manifest
+		require.NoError(t, json.Unmarshal(content, &manifest))
+
+		manifest.Version = "v0.14.0"
+		manifest.Schema = oldSchema
+
+		for i, file := range manifest.Files {
+			if file.Path == DatabaseName {
+				sum := sha256.Sum256(snapshot)
+				manifest.Files[i].Size = int64(len(snapshot))
+				manifest.Files[i].SHA256 = hex.EncodeToString(sum[:])
+			}
+		}
+
+		content, err := json.Marshal(manifest)
+		require.NoError(t, err)
+
+		return content
+	})
+}
+
+func TestRestore(t *testing.T) {
+	t.Parallel()
+
+	archive := downgrade(t, newTestBackup(t))
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, DatabaseName), []byte("current"), 0o600))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, uploadsDir), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, uploadsDir, "current.txt"), []byte("current"), 0o600))
+
+	restored, err := Restore(t.Context(), bytes.NewReader(archive), int64(len(archive)), dir, t.TempDir(), nil, Selection{})
+	require.NoError(t, err)
+	assert.Equal(t, "v0.14.0", restored.Version)
+	assert.Equal(t, oldSchema, restored.Schema)
+
+	// the replaced data is kept
+	previous, err := os.ReadFile(filepath.Join(restored.Previous, DatabaseName))
+	require.NoError(t, err)
+	assert.Equal(t, "current", string(previous))
+	assert.FileExists(t, filepath.Join(restored.Previous, uploadsDir, "current.txt"))
+
+	assert.FileExists(t, filepath.Join(dir, uploadsDir, "b_evidence.info"))
+	assert.NoFileExists(t, filepath.Join(dir, uploadsDir, "current.txt"))
+
+	// the restored database was migrated
+	queries, cleanup, err := database.DB(t.Context(), dir)
+	require.NoError(t, err)
+	t.Cleanup(cleanup)
+
+	var version int
+	require.NoError(t, queries.ReadDB.QueryRowContext(t.Context(), "PRAGMA user_version").Scan(&version))
+	assert.Equal(t, migration.Latest(), version)
+
+	_, err = queries.Ticket(t.Context(), "test-ticket")
+	require.NoError(t, err)
+
+	_, err = queries.ListCustodyEvents(t.Context(), "test-ticket")
+	require.NoError(t, err)
+}
+
+func TestRestore_invalid(t *testing.T) {
+	t.Parallel()
+
+	archive := rewrite(t, newTestBackup(t), func(_ string, content []byte) []byte {
+		if bytes.Equal(content, []byte("evidence")) {
+			return []byte("tampered")
+		}
+
+		return content
+	})
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, DatabaseName), []byte("current"), 0o600))
+
+	_, err := Restore(t.Context(), bytes.NewReader(archive), int64(len(archive)), dir, t.TempDir(), nil, Selection{})
+	require.ErrorIs(t, err, ErrInvalid)
+	assert.Contains(t, err.Error(), "uploads/b_evidence")
+
+	// the data directory is untouched
+	current, err := os.ReadFile(filepath.Join(dir, DatabaseName))
+	require.NoError(t, err)
+	assert.Equal(t, "current", string(current))
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
+
+func TestRestore_caseKey(t *testing.T) {
+	t.Parallel()
+
+	source := t.TempDir()
+	queries := data.NewTestDB(t, source)
+
+	keyring, err := casekey.Load(source, "")
+	require.NoError(t, err)
+
+	_, wrapped, err := keyring.NewCaseKey()
+	require.NoError(t, err)
+
+	require.NoError(t, queries.CreateTicketKey(t.Context(), sqlc.CreateTicketKeyParams{Ticket: "test-ticket", Key: wrapped}))
+
+	uploader, err := upload.New(source)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, Write(t.Context(), queries, uploader, time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC), nil, &buf))
+
+	archive := buf.Bytes()
+
+	// the master key is not in the backup
+	dir := t.TempDir()
+
+	_, err = Restore(t.Context(), bytes.NewReader(archive), int64(len(archive)), dir, t.TempDir(), nil, Selection{})
+	require.ErrorIs(t, err, casekey.ErrMissingKey)
+	assert.NoFileExists(t, filepath.Join(dir, DatabaseName))
+
+	exported, err := casekey.Export(source)
+	require.NoError(t, err)
+	require.NoError(t, casekey.Import(dir, exported, wrapped))
+
+	_, err = Restore(t.Context(), bytes.NewReader(archive), int64(len(archive)), dir, t.TempDir(), nil, Selection{})
+	require.NoError(t, err)
+	assert.FileExists(t, filepath.Join(dir, DatabaseName))
+}
+
+// newDataDir returns a data directory with the test data changed by the
+// statements.
+func newDataDir(t *testing.T, statements ...string) string {
+	t.Helper()
+
+	queries := data.NewTestDB(t, t.TempDir())
+
+	for _, statement := range statements {
+		_, err := queries.WriteDB.ExecContext(t.Context(), statement)
+		require.NoError(t, err)
+	}
+
+	dir := t.TempDir()
+
+	_, err := queries.WriteDB.ExecContext(t.Context(), "VACUUM INTO ?", filepath.Join(dir, DatabaseName))
+	require.NoError(t, err)
+
+	return dir
+}
+
+func TestRestore_selection(t *testing.T) {
+	t.Parallel()
+
+	archive := downgrade(t, newTestBackup(t))
+
+	// the current data differs from the backup
+	dir := newDataDir(t,
+		"DELETE FROM reactions",
+		"UPDATE tickets SET name = 'changed' WHERE id = 'test-ticket'",
+	)
+
+	uploader, err := upload.New(dir)
+	require.NoError(t, err)
+
+	_, err = uploader.CreateFile(t.Context(), "b_current", "current.txt", []byte("current"))
+	require.NoError(t, err)
+
+	uploader.Root.Close()
+
+	restored, err := Restore(t.Context(), bytes.NewReader(archive), int64(len(archive)), dir, t.TempDir(), nil, Selection{
+		Tables:  []string{"reactions"},
+		Uploads: []string{"b_evidence"},
+	})
+	require.NoError(t, err)
+
+	// only the selected data is replaced
+	assert.FileExists(t, filepath.Join(restored.Previous, DatabaseName))
+	assert.NoDirExists(t, filepath.Join(restored.Previous, uploadsDir, "b_current"))
+	assert.FileExists(t, filepath.Join(dir, uploadsDir, "b_evidence.info"))
+	assert.FileExists(t, filepath.Join(dir, uploadsDir, "b_current.info"))
+
+	queries, cleanup, err := database.DB(t.Context(), dir)
+	require.NoError(t, err)
+	t.Cleanup(cleanup)
+
+	reactions, err := queries.ListReactions(t.Context(), sqlc.ListReactionsParams{Limit: 100})
+	require.NoError(t, err)
+	assert.Len(t, reactions, 3)
+
+	ticket, err := queries.Ticket(t.Context(), "test-ticket")
+	require.NoError(t, err)
+	assert.Equal(t, "changed", ticket.Name)
+}
+
+func TestRestore_excluded(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	m := newTestManager(t)
+
+	for _, statement := range []string{
+		"INSERT INTO logs (id, level, message) VALUES ('l_backup', 0, 'backup')",
+		"INSERT INTO reaction_runs (id, reaction, success, duration_ms, cpu_ms, memory_peak) VALUES ('x_backup', 'r-test-hook', TRUE, 1, 1, 1)",
+	} {
+		_, err := m.queries.WriteDB.ExecContext(ctx, statement)
+		require.NoError(t, err)
+	}
+
+	_, err := m.Create(ctx, "", "tickets")
+	require.ErrorIs(t, err, ErrInvalidExclusion)
+
+	info, err := m.Create(ctx, "", "logs", "jobs")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"logs", "reaction_runs"}, info.Excluded)
+
+	archive, err := os.ReadFile(filepath.Join(m.dir, info.Name))
+	require.NoError(t, err)
+
+	dir := newDataDir(t,
+		"INSERT INTO logs (id, level, message) VALUES ('l_current', 0, 'current')",
+		"INSERT INTO reaction_runs (id, reaction, success, duration_ms, cpu_ms, memory_peak) VALUES ('x_current', 'r-test-hook', TRUE, 1, 1, 1)",
+		"INSERT INTO reactions (id, name, action, actiondata, trigger, triggerdata) VALUES ('r-current', 'Current', 'webhook', '{}', 'hook', '{}')",
+		"INSERT INTO reaction_runs (id, reaction, success, duration_ms, cpu_ms, memory_peak) VALUES ('x_removed', 'r-current', TRUE, 1, 1, 1)",
+	)
+
+	_, err = Restore(ctx, bytes.NewReader(archive), int64(len(archive)), dir, t.TempDir(), nil, Selection{Tables: []string{"logs"}})
+	require.ErrorIs(t, err, ErrInvalidSelection)
+
+	restored, err := Restore(ctx, bytes.NewReader(archive), int64(len(archive)), dir, t.TempDir(), nil, Selection{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"logs", "reaction_runs"}, restored.Kept)
+
+	queries, cleanup, err := database.DB(ctx, dir)
+	require.NoError(t, err)
+	t.Cleanup(cleanup)
+
+	// the current rows of the excluded tables are kept, without the runs of
+	// reactions that are not restored
+	for table, want := range map[string][]string{"logs": {"l_current"}, "reaction_runs": {"x_current"}} {
+		rows, err := queries.ReadDB.QueryContext(ctx, "SELECT id FROM "+table+" ORDER BY id")
+		require.NoError(t, err)
+
+		var ids []string
+
+		for rows.Next() {
+			var id string
+			require.NoError(t, rows.Scan(&id))
+
+			ids = append(ids, id)
+		}
+
+		require.NoError(t, rows.Err())
+		require.NoError(t, rows.Close())
+		assert.Equal(t, want, ids, table)
+	}
+}
+
+func TestRestore_selection_invalid(t *testing.T) {
+	t.Parallel()
+
+	archive := newTestBackup(t)
+
+	// a ticket of a type that is not in the backup
+	dir := newDataDir(t,
+		"INSERT INTO types (id, singular, plural) VALUES ('new-type', 'New', 'News')",
+		"UPDATE tickets SET type = 'new-type' WHERE id = 'test-ticket'",
+	)
+
+	for name, tt := range map[string]struct {
+		selection Selection
+		err       string
+	}{
+		"unknown table":     {selection: Selection{Tables: []string{"unknown"}}, err: "unknown table unknown"},
+		"unknown upload":    {selection: Selection{Uploads: []string{"b_unknown"}}, err: "b_unknown is not in the backup"},
+		"invalid upload":    {selection: Selection{Uploads: []string{"../data.db"}}, err: "invalid upload"},
+		"broken references": {selection: Selection{Tables: []string{"types"}}, err: "tickets -> types"},
+	} {
+		_, err := Restore(t.Context(), bytes.NewReader(archive), int64(len(archive)), dir, t.TempDir(), nil, tt.selection)
+		require.ErrorIs(t, err, ErrInvalidSelection, name)
+		assert.Contains(t, err.Error(), tt.err, name)
+	}
+
+	// the data directory is untouched
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+
+	for _, entry := range entries {
+		assert.NotContains(t, entry.Name(), "restore", entry.Name())
+	}
+}
@@ -0,0 +1,155 @@
+package backup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/SecurityBrewery/catalyst/app/settings"
+)
+
+const pruneInterval = time.Hour
+
+var ErrInUse = errors.New("backup is the base of an incremental backup")
+
+// Start deletes the backups outside of the retention of the settings every
+// hour until the context is canceled.
+func (m *Manager) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(pruneInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				se, err := settings.Load(ctx, m.queries)
+				if err != nil {
+					slog.ErrorContext(ctx, "Failed to load settings", "error", err)
+
+					continue
+				}
+
+				deleted, err := m.Prune(se.BackupRetention)
+				if err != nil {
+					slog.ErrorContext(ctx, "Failed to prune backups", "error", err)
+				}
+
+				if len(deleted) > 0 {
+					slog.InfoContext(ctx, "Pruned backups", "backups", deleted)
+				}
+			}
+		}
+	}()
+}
+
+// Prune deletes the stored backups that are not retained and returns their
+// names. The newest backup of each of the last retention.Daily days and of
+// each of the last retention.Weekly weeks is retained, with the bases it
+// depends on. Days and weeks without backups do not count.
+func (m *Manager) Prune(retention settings.BackupRetention) ([]string, error) {
+	if retention.Daily <= 0 && retention.Weekly <= 0 {
+		return nil, nil
+	}
+
+	backups, err := m.List()
+	if err != nil {
+		return nil, err
+	}
+
+	keep := retained(backups, retention)
+
+	var deleted []string
+
+	for _, backup := range backups {
+		if keep[backup.Name] {
+			continue
+		}
+
+		if err := m.remove(backup.Name); err != nil {
+			return deleted, fmt.Errorf("failed to delete backup %s: %w", backup.Name, err)
+		}
+
+		deleted = append(deleted, backup.Name)
+	}
+
+	return deleted, nil
+}
+
+// retained returns the names of the backups to keep, the backups must be
+// sorted newest first.
+func retained(backups []Info, retention settings.BackupRetention) map[string]bool {
+	keep := map[string]bool{}
+
+	var days, weeks []string
+
+	for _, backup := range backups {
+		created := nameTime(backup.Name)
+
+		day := created.Format(time.DateOnly)
+		if !slices.Contains(days, day) && len(days) < retention.Daily {
+			days = append(days, day)
+			keep[backup.Name] = true
+		}
+
+		year, isoWeek := created.ISOWeek()
+
+		week := fmt.Sprintf("%d-%02d", year, isoWeek)
+		if !slices.Contains(weeks, week) && len(weeks) < retention.Weekly {
+			weeks = append(weeks, week)
+			keep[backup.Name] = true
+		}
+	}
+
+	// bases are older than their incremental backups, so they come later
+	for _, backup := range backups {
+		if keep[backup.Name] && backup.Base != "" {
+			keep[backup.Base] = true
+		}
+	}
+
+	return keep
+}
+
+// nameTime returns the time a backup was created at from its name, which
+// unlike the modification time survives copying the backup.
+func nameTime(name string) time.Time {
+	stamp := strings.TrimPrefix(name, "catalyst-")
+	if len(stamp) < len("20060102-150405") {
+		return time.Time{}
+	}
+
+	created, _ := time.Parse("20060102-150405", stamp[:len("20060102-150405")])
+
+	return created
+}
+
+// Delete deletes a stored backup. The base of an incremental backup cannot
+// be deleted.
+func (m *Manager) Delete(name string) error {
+	if !validName.MatchString(name) {
+		return ErrNotFound
+	}
+
+	backups, err := m.List()
+	if err != nil {
+		return err
+	}
+
+	if !slices.ContainsFunc(backups, func(b Info) bool { return b.Name == name }) {
+		return ErrNotFound
+	}
+
+	for _, backup := range backups {
+		if backup.Base == name {
+			return fmt.Errorf("%w %s", ErrInUse, backup.Name)
+		}
+	}
+
+	return m.remove(name)
+}
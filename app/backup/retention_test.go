@@ -0,0 +1,145 @@
+package backup
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/SecurityBrewery/catalyst/app/data"
+	"github.com/SecurityBrewery/catalyst/app/settings"
+	"github.com/SecurityBrewery/catalyst/app/upload"
+)
+
+func newTestManager(t *testing.T) *Manager {
+	t.Helper()
+
+	dir := t.TempDir()
+
+	uploader, err := upload.New(dir)
+	require.NoError(t, err)
+
+	m, err := New(data.NewTestDB(t, dir), uploader, dir, Config{})
+	require.NoError(t, err)
+
+	return m
+}
+
+func Test_retained(t *testing.T) {
+	t.Parallel()
+
+	// newest first, 2025-06-02 is a Monday
+	backups := []Info{
+		{Name: "catalyst-20250610-180000.zip"},
+		{Name: "catalyst-20250610-060000.zip"},
+		{Name: "catalyst-20250609-060000.zip", Base: "catalyst-20250601-060000.zip"},
+		{Name: "catalyst-20250605-060000.zip"},
+		{Name: "catalyst-20250603-060000.zip"},
+		{Name: "catalyst-20250601-060000.zip"},
+		{Name: "catalyst-20250520-060000.zip"},
+	}
+
+	tests := []struct {
+		name      string
+		retention settings.BackupRetention
+		want      []string
+	}{
+		{
+			name:      "daily",
+			retention: settings.BackupRetention{Daily: 3},
+			want:      []string{"catalyst-20250610-180000.zip", "catalyst-20250609-060000.zip", "catalyst-20250605-060000.zip", "catalyst-20250601-060000.zip"},
+		},
+		{
+			name:      "weekly",
+			retention: settings.BackupRetention{Weekly: 3},
+			want:      []string{"catalyst-20250610-180000.zip", "catalyst-20250605-060000.zip", "catalyst-20250601-060000.zip"},
+		},
+		{
+			name:      "daily and weekly",
+			retention: settings.BackupRetention{Daily: 1, Weekly: 4},
+			want:      []string{"catalyst-20250610-180000.zip", "catalyst-20250605-060000.zip", "catalyst-20250601-060000.zip", "catalyst-20250520-060000.zip"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			keep := retained(backups, tt.retention)
+
+			var got []string
+
+			for _, backup := range backups {
+				if keep[backup.Name] {
+					got = append(got, backup.Name)
+				}
+			}
+
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestManager_Prune(t *testing.T) {
+	t.Parallel()
+
+	m := newTestManager(t)
+
+	for _, name := range []string{"catalyst-20250601-060000.zip", "catalyst-20250602-060000-0a1b2c3d.zip", "catalyst-20250603-060000.zip.enc", "notes.txt"} {
+		require.NoError(t, os.WriteFile(filepath.Join(m.dir, name), []byte("backup"), 0o600))
+	}
+
+	// all backups are kept by default
+	deleted, err := m.Prune(settings.BackupRetention{})
+	require.NoError(t, err)
+	assert.Empty(t, deleted)
+
+	deleted, err = m.Prune(settings.BackupRetention{Daily: 2})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"catalyst-20250601-060000.zip"}, deleted)
+
+	backups, err := m.List()
+	require.NoError(t, err)
+	require.Len(t, backups, 2)
+
+	// other files of the backups folder are not touched
+	assert.FileExists(t, filepath.Join(m.dir, "notes.txt"))
+}
+
+func TestManager_Delete(t *testing.T) {
+	t.Parallel()
+
+	m := newTestManager(t)
+
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	m.now = func() time.Time {
+		now = now.Add(time.Hour)
+
+		return now
+	}
+
+	full, err := m.Create(t.Context(), "")
+	require.NoError(t, err)
+
+	incremental, err := m.Create(t.Context(), full.Name)
+	require.NoError(t, err)
+
+	require.ErrorIs(t, m.Delete(full.Name), ErrInUse)
+	require.ErrorIs(t, m.Delete("catalyst-20990101-000000.zip"), ErrNotFound)
+	require.ErrorIs(t, m.Delete("../data.db"), ErrNotFound)
+
+	require.NoError(t, m.Delete(incremental.Name))
+	require.NoError(t, m.Delete(full.Name))
+
+	backups, err := m.List()
+	require.NoError(t, err)
+	assert.Empty(t, backups)
+
+	// the summaries are deleted with the backups
+	entries, err := os.ReadDir(m.dir)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
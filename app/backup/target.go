@@ -0,0 +1,234 @@
+package backup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/SecurityBrewery/catalyst/app/settings"
+	"github.com/SecurityBrewery/catalyst/app/storage"
+)
+
+var ErrInvalidTarget = errors.New("invalid backup target")
+
+// Target stores backups outside of the backups folder.
+type Target interface {
+	// Create returns a writer for a new backup, which is stored once the
+	// writer is closed.
+	Create(ctx context.Context, name string) (TargetWriter, error)
+	// Location returns where a backup is stored, like s3://bucket/key.
+	Location(name string) string
+}
+
+type TargetWriter interface {
+	io.WriteCloser
+	// Abort discards an incomplete backup.
+	Abort(ctx context.Context) error
+}
+
+// NewTarget parses a target like s3://bucket/prefix, azure://container/prefix,
+// gs://bucket/prefix or file:///directory. The bucket, the container or the
+// directory must be the one of the backup storage settings.
+func NewTarget(target string, config *settings.BackupStorage) (Target, error) {
+	u, err := url.Parse(target)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %q is not like s3://bucket/prefix, azure://container/prefix, gs://bucket/prefix or file:///directory", ErrInvalidTarget, target)
+	}
+
+	switch u.Scheme {
+	case "s3", "azure", "gs":
+		return NewStorageTarget(target, config)
+	case "file":
+		return NewDirTarget(target, config)
+	default:
+		return nil, fmt.Errorf("%w: %q is not like s3://bucket/prefix, azure://container/prefix, gs://bucket/prefix or file:///directory", ErrInvalidTarget, target)
+	}
+}
+
+// StorageTarget streams backups to a prefix of an S3 compatible bucket, an
+// Azure Blob Storage container or a Google Cloud Storage bucket, without
+// writing them to disk first. The backups are written like uploaded files.
+type StorageTarget struct {
+	store    storage.Storage
+	location string
+}
+
+// NewStorageTarget parses a target like s3://bucket/prefix,
+// azure://container/prefix or gs://bucket/prefix and connects to it with the
+// credentials of the backup storage settings.
+func NewStorageTarget(target string, config *settings.BackupStorage) (*StorageTarget, error) {
+	u, err := url.Parse(target)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("%w: %q is not like s3://bucket/prefix, azure://container/prefix or gs://bucket/prefix", ErrInvalidTarget, target)
+	}
+
+	storageConfig, err := storageConfig(u, config)
+	if err != nil {
+		return nil, err
+	}
+
+	store, err := storage.New(nil, storageConfig)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidTarget, err)
+	}
+
+	return &StorageTarget{
+		store:    store,
+		location: u.Scheme + "://" + path.Join(u.Host, strings.Trim(u.Path, "/")),
+	}, nil
+}
+
+// storageConfig returns the storage config of a target with the credentials
+// of the backup storage settings.
+func storageConfig(u *url.URL, config *settings.BackupStorage) (storage.Config, error) {
+	switch u.Scheme {
+	case "s3":
+		if config.Endpoint == "" {
+			return storage.Config{}, fmt.Errorf("%w: the backup storage is not configured", ErrInvalidTarget)
+		}
+
+		if u.Host != config.Bucket {
+			return storage.Config{}, fmt.Errorf("%w: %q is not the bucket of the backup storage", ErrInvalidTarget, u.Host)
+		}
+
+		return storage.Config{
+			URL:             u.String(),
+			Endpoint:        config.Endpoint,
+			Region:          config.Region,
+			AccessKeyID:     config.AccessKeyID,
+			SecretAccessKey: config.SecretAccessKey,
+		}, nil
+	case "azure":
+		if config.AzureAccount == "" {
+			return storage.Config{}, fmt.Errorf("%w: the Azure storage account is not configured", ErrInvalidTarget)
+		}
+
+		if u.Host != config.AzureContainer {
+			return storage.Config{}, fmt.Errorf("%w: %q is not the container of the backup storage", ErrInvalidTarget, u.Host)
+		}
+
+		return storage.Config{
+			URL:             u.String(),
+			Endpoint:        config.AzureEndpoint,
+			AzureAccount:    config.AzureAccount,
+			AzureAccountKey: config.AzureAccountKey,
+		}, nil
+	case "gs":
+		if config.GCSBucket == "" {
+			return storage.Config{}, fmt.Errorf("%w: the GCS bucket is not configured", ErrInvalidTarget)
+		}
+
+		if u.Host != config.GCSBucket {
+			return storage.Config{}, fmt.Errorf("%w: %q is not the GCS bucket of the backup storage", ErrInvalidTarget, u.Host)
+		}
+
+		return storage.Config{
+			URL:             u.String(),
+			Endpoint:        config.GCSEndpoint,
+			AccessKeyID:     config.GCSAccessKeyID,
+			SecretAccessKey: config.GCSSecret,
+		}, nil
+	default:
+		return storage.Config{}, fmt.Errorf("%w: %q is not like s3://bucket/prefix, azure://container/prefix or gs://bucket/prefix", ErrInvalidTarget, u.String())
+	}
+}
+
+func (t *StorageTarget) Create(ctx context.Context, name string) (TargetWriter, error) {
+	return t.store.Create(ctx, name, "application/octet-stream")
+}
+
+func (t *StorageTarget) Location(name string) string {
+	return t.location + "/" + name
+}
+
+// DirTarget stores backups in a directory outside of the data directory,
+// like a mounted network share, for deployments without an S3 compatible
+// store.
+type DirTarget struct {
+	dir string
+}
+
+// NewDirTarget parses a target like file:///directory. The directory must be
+// the directory of the backup storage settings or below it. Backups are
+// written like to the backups folder, so that incomplete ones never show up.
+func NewDirTarget(target string, config *settings.BackupStorage) (*DirTarget, error) {
+	u, err := url.Parse(target)
+	if err != nil || u.Scheme != "file" || u.Host != "" || !filepath.IsAbs(u.Path) {
+		return nil, fmt.Errorf("%w: %q is not like file:///directory", ErrInvalidTarget, target)
+	}
+
+	if config.Directory == "" {
+		return nil, fmt.Errorf("%w: the backup directory is not configured", ErrInvalidTarget)
+	}
+
+	dir := filepath.Clean(u.Path)
+
+	rel, err := filepath.Rel(config.Directory, dir)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return nil, fmt.Errorf("%w: %q is not in the directory of the backup storage", ErrInvalidTarget, dir)
+	}
+
+	return &DirTarget{dir: dir}, nil
+}
+
+func (t *DirTarget) Create(ctx context.Context, name string) (TargetWriter, error) {
+	if err := os.MkdirAll(t.dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create backup directory: %w", err)
+	}
+
+	return folderTarget{dir: t.dir}.Create(ctx, name)
+}
+
+func (t *DirTarget) Location(name string) string {
+	return "file://" + filepath.ToSlash(filepath.Join(t.dir, name))
+}
+
+// folderTarget stores backups in the backups folder. A backup is written to a
+// temporary file that is moved in place once it is complete, so that
+// incomplete backups are not listed.
+type folderTarget struct {
+	dir string
+}
+
+func (t folderTarget) Create(_ context.Context, name string) (TargetWriter, error) {
+	f, err := os.CreateTemp(t.dir, ".partial-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create backup file: %w", err)
+	}
+
+	return &folderWriter{File: f, name: filepath.Join(t.dir, name)}, nil
+}
+
+func (t folderTarget) Location(name string) string {
+	return name
+}
+
+type folderWriter struct {
+	*os.File
+	name string
+}
+
+func (w *folderWriter) Close() error {
+	if err := w.File.Close(); err != nil {
+		return err
+	}
+
+	// a link, unlike a rename, does not replace a backup of the same second
+	if err := os.Link(w.File.Name(), w.name); err != nil {
+		return fmt.Errorf("failed to store backup: %w", err)
+	}
+
+	return os.Remove(w.File.Name())
+}
+
+func (w *folderWriter) Abort(_ context.Context) error {
+	w.File.Close()
+
+	return os.Remove(w.File.Name())
+}
@@ -0,0 +1,155 @@
+package backup
+
+import (
+	"archive/zip"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/SecurityBrewery/catalyst/app/migration"
+)
+
+// Validation is the result of a dry run of a restore.
+type Validation struct {
+	// Valid is set if the restore would succeed.
+	Valid  bool
+	Report *Report
+	// Diff is what the restore would change, it is only set if the restore
+	// would succeed.
+	Diff   *Diff
+	Errors []string
+}
+
+// Validate runs a restore of the selection without touching the current
+// data. The backup is verified, extracted with the files of its bases from
+// the backups folder and migrated to the current schema in a temporary
+// folder. The selected tables are merged into a copy of the current
+// database, so that broken references are found. The changes of the restore
+// are compared like in Preview.
+func (m *Manager) Validate(ctx context.Context, r io.ReaderAt, size int64, selection Selection) (*Validation, error) {
+	report := Verify(ctx, r, size, migration.Latest())
+
+	validation := &Validation{Report: report}
+
+	if !report.Valid {
+		validation.Errors = reportErrors(report)
+
+		return validation, nil
+	}
+
+	staging, err := os.MkdirTemp("", "catalyst-validate")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(staging)
+
+	if err := selection.check(report.Excluded); err != nil {
+		validation.Errors = append(validation.Errors, err.Error())
+
+		return validation, nil
+	}
+
+	diff, err := m.rehearse(ctx, r, size, staging, selection, report.Excluded)
+	if err != nil {
+		validation.Errors = append(validation.Errors, err.Error())
+
+		return validation, nil
+	}
+
+	diff.Created = *report.Created
+	diff.Schema = *report.Schema
+
+	validation.Valid = true
+	validation.Diff = diff
+
+	return validation, nil
+}
+
+func (m *Manager) rehearse(ctx context.Context, r io.ReaderAt, size int64, staging string, selection Selection, excluded []string) (*Diff, error) {
+	archive, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, err
+	}
+
+	restored, err := restoredUploads(archive)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := extractAll(archive, staging); err != nil {
+		return nil, fmt.Errorf("failed to extract backup: %w", err)
+	}
+
+	if err := extractBases(archive, staging, m.dir, m.key); err != nil {
+		return nil, fmt.Errorf("failed to extract the unchanged files of the incremental backup: %w", err)
+	}
+
+	if err := migrate(ctx, staging); err != nil {
+		return nil, fmt.Errorf("failed to migrate backup: %w", err)
+	}
+
+	if err := checkUploads(staging, selection.Uploads); err != nil {
+		return nil, err
+	}
+
+	current := filepath.Join(staging, "current.db")
+	if _, err := m.queries.WriteDB.ExecContext(ctx, "VACUUM INTO ?", current); err != nil {
+		return nil, fmt.Errorf("failed to snapshot database: %w", err)
+	}
+
+	if selection.all() && len(excluded) > 0 {
+		if err := copyTables(ctx, filepath.Join(staging, DatabaseName), current, excluded); err != nil {
+			return nil, err
+		}
+	}
+
+	diff := &Diff{}
+
+	if err := diffDatabases(ctx, filepath.Join(staging, DatabaseName), current, restored, diff); err != nil {
+		return nil, err
+	}
+
+	if err := diffUploads(ctx, m.uploader, restored, diff); err != nil {
+		return nil, err
+	}
+
+	if len(selection.Tables) > 0 {
+		merged := filepath.Join(staging, "merged.db")
+		if _, err := m.queries.WriteDB.ExecContext(ctx, "VACUUM INTO ?", merged); err != nil {
+			return nil, fmt.Errorf("failed to snapshot database: %w", err)
+		}
+
+		if err := mergeTables(ctx, staging, merged, selection.Tables); err != nil {
+			return nil, err
+		}
+	}
+
+	selection.filter(diff)
+
+	return diff, nil
+}
+
+// filter removes the tables and uploads that are not selected from the diff.
+func (s Selection) filter(diff *Diff) {
+	if s.all() {
+		return
+	}
+
+	diff.Tables = slices.DeleteFunc(diff.Tables, func(table TableDiff) bool {
+		return !slices.Contains(s.Tables, table.Table)
+	})
+
+	unselected := func(name string) bool {
+		id, _, _ := strings.Cut(name, "/")
+
+		return !slices.Contains(s.Uploads, strings.TrimSuffix(id, ".info"))
+	}
+
+	diff.Uploads.Added = slices.DeleteFunc(diff.Uploads.Added, unselected)
+	diff.Uploads.Removed = slices.DeleteFunc(diff.Uploads.Removed, unselected)
+	diff.Uploads.Missing = slices.DeleteFunc(diff.Uploads.Missing, unselected)
+}
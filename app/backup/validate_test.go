@@ -0,0 +1,86 @@
+package backup
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/SecurityBrewery/catalyst/app/data"
+	"github.com/SecurityBrewery/catalyst/app/upload"
+)
+
+func TestManager_Validate(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	dir := t.TempDir()
+	queries := data.NewTestDB(t, dir)
+
+	uploader, err := upload.New(dir)
+	require.NoError(t, err)
+
+	_, err = uploader.CreateFile(t.Context(), "b_evidence", "evidence.txt", []byte("evidence"))
+	require.NoError(t, err)
+
+	m, err := New(queries, uploader, dir, Config{})
+	require.NoError(t, err)
+
+	m.now = func() time.Time { return time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC) }
+
+	base, err := m.Create(ctx, "")
+	require.NoError(t, err)
+
+	// the incremental backup needs its base from the backups folder
+	m.now = func() time.Time { return time.Date(2025, 6, 2, 0, 0, 0, 0, time.UTC) }
+
+	info, err := m.Create(ctx, base.Name)
+	require.NoError(t, err)
+
+	archive, err := os.ReadFile(filepath.Join(m.dir, info.Name))
+	require.NoError(t, err)
+
+	// change the current data after the backup
+	_, err = queries.WriteDB.ExecContext(ctx, "DELETE FROM reactions")
+	require.NoError(t, err)
+
+	_, err = uploader.CreateFile(t.Context(), "b_new", "new.txt", []byte("new"))
+	require.NoError(t, err)
+
+	validation, err := m.Validate(ctx, bytes.NewReader(archive), int64(len(archive)), Selection{})
+	require.NoError(t, err)
+	require.True(t, validation.Valid, validation.Errors)
+	assert.Equal(t, []TableDiff{{Table: "reactions", Added: 3}}, validation.Diff.Tables)
+	assert.Len(t, validation.Diff.Uploads.Removed, 2)
+
+	// only the changes of the selection are reported
+	validation, err = m.Validate(ctx, bytes.NewReader(archive), int64(len(archive)), Selection{Tables: []string{"tickets"}, Uploads: []string{"b_evidence"}})
+	require.NoError(t, err)
+	require.True(t, validation.Valid, validation.Errors)
+	assert.Empty(t, validation.Diff.Tables)
+	assert.Empty(t, validation.Diff.Uploads.Removed)
+
+	validation, err = m.Validate(ctx, bytes.NewReader(archive), int64(len(archive)), Selection{Tables: []string{"unknown"}})
+	require.NoError(t, err)
+	assert.False(t, validation.Valid)
+	assert.Nil(t, validation.Diff)
+	assert.Equal(t, []string{"invalid selection: unknown table unknown"}, validation.Errors)
+
+	// the base is required
+	require.NoError(t, os.Remove(filepath.Join(m.dir, base.Name)))
+
+	validation, err = m.Validate(ctx, bytes.NewReader(archive), int64(len(archive)), Selection{})
+	require.NoError(t, err)
+	assert.False(t, validation.Valid)
+	assert.Contains(t, validation.Errors[0], "failed to extract the unchanged files of the incremental backup")
+
+	// the current data is untouched
+	reactions, err := queries.WriteDB.QueryContext(ctx, "SELECT id FROM reactions")
+	require.NoError(t, err)
+	assert.False(t, reactions.Next())
+	require.NoError(t, reactions.Close())
+}
@@ -0,0 +1,323 @@
+package backup
+
+import (
+	"archive/zip"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3" // import sqlite driver
+)
+
+// Report is the result of a backup verification.
+type Report struct {
+	Valid   bool
+	Created *time.Time
+	// Version is the Catalyst release that wrote the backup, it is unset for
+	// backups of releases before it was recorded.
+	Version       string
+	Schema        *int
+	CurrentSchema int
+	// Compatible backups have the current or an older schema version, which
+	// can be migrated.
+	Compatible bool
+	Files      []FileCheck
+	// Bases are the backups that hold the unchanged files of an
+	// incremental backup, they are required to restore it.
+	Bases []string
+	// Excluded are the tables whose rows are not in the backup.
+	Excluded []string
+	Errors   []string
+}
+
+type FileCheck struct {
+	Path  string
+	Valid bool
+	Error string
+}
+
+func (r *Report) errorf(format string, args ...any) {
+	r.Errors = append(r.Errors, fmt.Sprintf(format, args...))
+}
+
+// Verify checks the zip integrity of a backup archive, the checksums of the
+// files in its manifest, the integrity of the database and whether its
+// schema version is compatible with the current one. Files held by the bases
+// of an incremental backup are checked by VerifyBases and when it is
+// restored.
+func Verify(ctx context.Context, r io.ReaderAt, size int64, currentSchema int) *Report {
+	report := &Report{CurrentSchema: currentSchema}
+
+	archive, err := zip.NewReader(r, size)
+	if err != nil {
+		report.errorf("invalid zip archive: %v", err)
+
+		return report
+	}
+
+	entries := map[string]*zip.File{}
+	for _, entry := range archive.File {
+		entries[entry.Name] = entry
+	}
+
+	manifest, err := readManifest(entries[ManifestName])
+	if err != nil {
+		report.errorf("invalid manifest: %v", err)
+
+		return report
+	}
+
+	report.Created = &manifest.Created
+	report.Version = manifest.Version
+	report.Schema = &manifest.Schema
+	report.Excluded = manifest.Excluded
+	report.Compatible = manifest.Schema <= currentSchema
+
+	if !report.Compatible {
+		if manifest.Version != "" {
+			report.errorf("the schema version %d of the backup is newer than the current schema version %d, restore it with Catalyst %s or newer",
+				manifest.Schema, currentSchema, manifest.Version)
+		} else {
+			report.errorf("the schema version %d of the backup is newer than the current schema version %d", manifest.Schema, currentSchema)
+		}
+	}
+
+	listed := map[string]bool{ManifestName: true}
+
+	for _, file := range manifest.Files {
+		listed[file.Path] = true
+
+		check := FileCheck{Path: file.Path, Valid: true}
+
+		if file.Base != "" {
+			if !slices.Contains(report.Bases, file.Base) {
+				report.Bases = append(report.Bases, file.Base)
+			}
+
+			if !validName.MatchString(file.Base) || file.Path == DatabaseName {
+				check.Valid, check.Error = false, "invalid base "+file.Base
+			}
+
+			report.Files = append(report.Files, check)
+
+			continue
+		}
+
+		if err := verifyFile(entries[file.Path], &file); err != nil {
+			check.Valid, check.Error = false, err.Error()
+		}
+
+		report.Files = append(report.Files, check)
+	}
+
+	for _, entry := range archive.File {
+		if !listed[entry.Name] {
+			report.Files = append(report.Files, FileCheck{Path: entry.Name, Error: "not listed in the manifest"})
+		}
+	}
+
+	if !listed[DatabaseName] {
+		report.errorf("the backup has no database")
+	} else if err := verifyDatabase(ctx, entries[DatabaseName], manifest.Schema); err != nil {
+		report.errorf("invalid database: %v", err)
+	}
+
+	report.validate()
+
+	return report
+}
+
+func (r *Report) validate() {
+	r.Valid = len(r.Errors) == 0
+
+	for _, file := range r.Files {
+		if !file.Valid {
+			r.Valid = false
+		}
+	}
+}
+
+// VerifyBases checks the files of an incremental backup that are held by its
+// bases in the backups folder against the manifest of the backup and adds
+// the results to the report of Verify, so that a missing, truncated or
+// tampered base is found before the backup is restored.
+func (m *Manager) VerifyBases(r io.ReaderAt, size int64, report *Report) {
+	VerifyBasesIn(r, size, m.dir, m.key, report)
+}
+
+// VerifyBasesIn checks the bases of an incremental backup like VerifyBases,
+// with the bases in another folder, like next to a downloaded backup.
+func VerifyBasesIn(r io.ReaderAt, size int64, baseDir string, key *Key, report *Report) {
+	if len(report.Bases) == 0 {
+		return
+	}
+
+	archive, err := zip.NewReader(r, size)
+	if err != nil {
+		return
+	}
+
+	manifest, err := readManifest(findEntry(archive, ManifestName))
+	if err != nil {
+		return
+	}
+
+	bases := newBaseArchives(baseDir, key)
+	defer bases.Close()
+
+	// Verify adds a check for each file of the manifest first
+	for i, file := range manifest.Files {
+		if file.Base == "" || !report.Files[i].Valid {
+			continue
+		}
+
+		base, err := bases.open(file.Base)
+		if err != nil {
+			report.Files[i].Valid, report.Files[i].Error = false, err.Error()
+
+			continue
+		}
+
+		if err := verifyFile(findEntry(base, file.Path), &file); err != nil {
+			report.Files[i].Valid, report.Files[i].Error = false, fmt.Sprintf("in the base %s: %v", file.Base, err)
+		}
+	}
+
+	report.validate()
+}
+
+func readManifest(entry *zip.File) (*Manifest, error) {
+	if entry == nil {
+		return nil, fmt.Errorf("%s is missing", ManifestName)
+	}
+
+	f, err := entry.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var manifest Manifest
+	if err := json.NewDecoder(f).Decode(&manifest); err != nil {
+		return nil, err
+	}
+
+	return &manifest, nil
+}
+
+// verifyFile compares a file with its manifest entry. Reading the file also
+// checks its zip CRC-32 checksum.
+func verifyFile(entry *zip.File, file *ManifestFile) error {
+	if entry == nil {
+		return fmt.Errorf("missing in the archive")
+	}
+
+	f, err := entry.Open()
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	hash := sha256.New()
+
+	size, err := io.Copy(hash, f)
+	if err != nil {
+		return err
+	}
+
+	if size != file.Size {
+		return fmt.Errorf("size is %d, expected %d", size, file.Size)
+	}
+
+	if sum := hex.EncodeToString(hash.Sum(nil)); sum != file.SHA256 {
+		return fmt.Errorf("SHA-256 is %s, expected %s", sum, file.SHA256)
+	}
+
+	return nil
+}
+
+// verifyDatabase extracts the database and checks its integrity and its
+// schema version.
+func verifyDatabase(ctx context.Context, entry *zip.File, schema int) error {
+	tmp, err := os.MkdirTemp("", "catalyst-verify")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmp)
+
+	filename := filepath.Join(tmp, DatabaseName)
+
+	if err := extract(entry, filename); err != nil {
+		return err
+	}
+
+	db, err := sql.Open("sqlite3", "file:"+filename+"?mode=ro")
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	var integrity string
+	if err := db.QueryRowContext(ctx, "PRAGMA integrity_check").Scan(&integrity); err != nil {
+		return err
+	}
+
+	if integrity != "ok" {
+		return fmt.Errorf("integrity check failed: %s", integrity)
+	}
+
+	var version int
+	if err := db.QueryRowContext(ctx, "PRAGMA user_version").Scan(&version); err != nil {
+		return err
+	}
+
+	if version != schema {
+		return fmt.Errorf("schema version is %d, but the manifest states %d", version, schema)
+	}
+
+	return nil
+}
+
+func extract(entry *zip.File, filename string) error {
+	r, err := entry.Open()
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	w, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer w.Close()
+
+	if _, err := io.Copy(w, r); err != nil {
+		return err
+	}
+
+	return w.Close()
+}
+
+// schemaVersion reads the schema version of a database file.
+func schemaVersion(ctx context.Context, filename string) (int, error) {
+	db, err := sql.Open("sqlite3", "file:"+filename+"?mode=ro")
+	if err != nil {
+		return 0, err
+	}
+	defer db.Close()
+
+	var version int
+	if err := db.QueryRowContext(ctx, "PRAGMA user_version").Scan(&version); err != nil {
+		return 0, fmt.Errorf("failed to read schema version: %w", err)
+	}
+
+	return version, nil
+}
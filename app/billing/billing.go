@@ -0,0 +1,171 @@
+// Package billing reports the usage of each customer per month for MSSP
+// deployments: the created tickets, the recorded effort, the playbook runs
+// and the size of the uploaded files. The customer of a ticket is read from
+// the ticket state field "customer", encrypted tickets and tickets without
+// a customer are reported with an empty customer.
+package billing
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/SecurityBrewery/catalyst/app/database/sqlc"
+	"github.com/SecurityBrewery/catalyst/app/pdf"
+)
+
+// Usage is the usage of a customer in a month like "2025-06".
+type Usage struct {
+	Customer       string
+	Month          string
+	Tickets        int64
+	Effort         time.Duration
+	AutomationRuns int64
+	StorageBytes   int64
+}
+
+type key struct {
+	customer, month string
+}
+
+// Report returns the usage between since and until, ordered by month and
+// customer. Months without any usage of a customer are left out.
+func Report(ctx context.Context, queries *sqlc.Queries, since, until time.Time) ([]Usage, error) {
+	from, to := since.UTC().Format(time.DateTime), until.UTC().Format(time.DateTime)
+
+	usage := map[key]*Usage{}
+
+	get := func(customer, month string) *Usage {
+		k := key{customer: customer, month: month}
+		if _, ok := usage[k]; !ok {
+			usage[k] = &Usage{Customer: customer, Month: month}
+		}
+
+		return usage[k]
+	}
+
+	tickets, err := queries.ListTicketUsage(ctx, sqlc.ListTicketUsageParams{Since: from, Until: to})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range tickets {
+		get(row.Customer, row.Month).Tickets = row.Tickets
+	}
+
+	effort, err := queries.ListEffortUsage(ctx, sqlc.ListEffortUsageParams{Since: from, Until: to})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range effort {
+		get(row.Customer, row.Month).Effort = time.Duration(row.Duration) * time.Second
+	}
+
+	runs, err := queries.ListAutomationUsage(ctx, sqlc.ListAutomationUsageParams{Since: from, Until: to})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range runs {
+		get(row.Customer, row.Month).AutomationRuns = row.Runs
+	}
+
+	storage, err := queries.ListStorageUsage(ctx, sqlc.ListStorageUsageParams{Since: from, Until: to})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range storage {
+		get(row.Customer, row.Month).StorageBytes = row.Size
+	}
+
+	result := make([]Usage, 0, len(usage))
+	for _, u := range usage {
+		result = append(result, *u)
+	}
+
+	slices.SortFunc(result, func(a, b Usage) int {
+		return cmp.Or(cmp.Compare(a.Month, b.Month), cmp.Compare(a.Customer, b.Customer))
+	})
+
+	return result, nil
+}
+
+var header = []string{"month", "customer", "tickets", "effort_hours", "automation_runs", "storage_bytes"}
+
+// CSV renders the usage as CSV with a header row.
+func CSV(usage []Usage) ([]byte, error) {
+	var buf bytes.Buffer
+
+	w := csv.NewWriter(&buf)
+
+	if err := w.Write(header); err != nil {
+		return nil, err
+	}
+
+	for _, u := range usage {
+		if err := w.Write([]string{
+			u.Month,
+			u.Customer,
+			strconv.FormatInt(u.Tickets, 10),
+			hours(u.Effort),
+			strconv.FormatInt(u.AutomationRuns, 10),
+			strconv.FormatInt(u.StorageBytes, 10),
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	w.Flush()
+
+	return buf.Bytes(), w.Error()
+}
+
+// PDF renders the usage as a PDF table, grouped by month.
+func PDF(usage []Usage, since, until time.Time) []byte {
+	lines := []string{
+		fmt.Sprintf("Period: %s to %s", since.UTC().Format(time.DateOnly), until.UTC().Format(time.DateOnly)),
+		"",
+	}
+
+	row := func(customer, tickets, effort, runs, storage string) string {
+		return fmt.Sprintf("%-40.40s %10s %14s %16s %16s", customer, tickets, effort, runs, storage)
+	}
+
+	month := ""
+
+	for _, u := range usage {
+		if u.Month != month {
+			if month != "" {
+				lines = append(lines, "")
+			}
+
+			month = u.Month
+			lines = append(lines, month, row("Customer", "Tickets", "Effort (h)", "Automation runs", "Storage (bytes)"))
+		}
+
+		lines = append(lines, row(
+			cmp.Or(u.Customer, "(none)"),
+			strconv.FormatInt(u.Tickets, 10),
+			hours(u.Effort),
+			strconv.FormatInt(u.AutomationRuns, 10),
+			strconv.FormatInt(u.StorageBytes, 10),
+		))
+	}
+
+	if len(usage) == 0 {
+		lines = append(lines, "No usage in the period.")
+	}
+
+	return pdf.Render("Usage Report", lines)
+}
+
+func hours(d time.Duration) string {
+	return strconv.FormatFloat(d.Hours(), 'f', 2, 64)
+}
@@ -0,0 +1,61 @@
+package billing
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/SecurityBrewery/catalyst/app/data"
+	"github.com/SecurityBrewery/catalyst/app/database/sqlc"
+	"github.com/SecurityBrewery/catalyst/app/pointer"
+)
+
+func TestReport(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	queries := data.NewTestDB(t, t.TempDir())
+
+	// the test ticket and its file are created in June 2025
+	_, err := queries.UpdateTicket(ctx, sqlc.UpdateTicketParams{ID: "test-ticket", State: []byte(`{"customer":"ACME"}`)})
+	require.NoError(t, err)
+
+	for _, started := range []time.Time{
+		time.Date(2025, 6, 22, 9, 0, 0, 0, time.UTC),
+		time.Date(2025, 7, 1, 9, 0, 0, 0, time.UTC),
+	} {
+		_, err = queries.CreateWorkEntry(ctx, sqlc.CreateWorkEntryParams{
+			Ticket:   "test-ticket",
+			User:     "u_bob_analyst",
+			Started:  started,
+			Duration: pointer.Pointer(int64(5400)),
+		})
+		require.NoError(t, err)
+	}
+
+	since := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	until := time.Date(2025, 8, 1, 0, 0, 0, 0, time.UTC)
+
+	usage, err := Report(ctx, queries, since, until)
+	require.NoError(t, err)
+
+	assert.Equal(t, []Usage{
+		{Customer: "ACME", Month: "2025-06", Tickets: 1, Effort: 90 * time.Minute, StorageBytes: 5},
+		{Customer: "ACME", Month: "2025-07", Effort: 90 * time.Minute},
+	}, usage)
+
+	document, err := CSV(usage)
+	require.NoError(t, err)
+
+	assert.Equal(t, strings.Join([]string{
+		"month,customer,tickets,effort_hours,automation_runs,storage_bytes",
+		"2025-06,ACME,1,1.50,0,5",
+		"2025-07,ACME,0,1.50,0,0",
+		"",
+	}, "\n"), string(document))
+
+	assert.Contains(t, string(PDF(usage, since, until)), "(2025-07) Tj")
+}
@@ -0,0 +1,254 @@
+// Package bundle checks playbook and automation bundles before they are
+// imported. Bundles are shared between teams and downloaded from community
+// repositories, so the scripts and settings of their reactions are scanned
+// for plaintext credentials, runtimes that Catalyst does not allow and
+// dangerous commands. Errors block the import, warnings must be confirmed by
+// an admin with the digest of the reviewed bundle.
+package bundle
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+)
+
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// Runtimes are the reaction actions a bundle may use.
+var Runtimes = []string{"python", "webhook"}
+
+// Triggers are the reaction triggers a bundle may use.
+var Triggers = []string{"hook", "schedule", "webhook"}
+
+// Reaction is an automation of a bundle.
+type Reaction struct {
+	Name        string         `json:"name"`
+	Action      string         `json:"action"`
+	Actiondata  map[string]any `json:"actiondata"`
+	Trigger     string         `json:"trigger"`
+	Triggerdata map[string]any `json:"triggerdata"`
+}
+
+// Playbook is a playbook of a bundle. Its inputs and tasks are only scanned
+// as text, they are validated by the playbook package.
+type Playbook struct {
+	Name   string `json:"name"`
+	Inputs any    `json:"inputs"`
+	Tasks  any    `json:"tasks"`
+}
+
+// Finding is a policy violation in an item of a bundle, e.g. the reaction
+// "Notify" or the playbook "Block indicator".
+type Finding struct {
+	Severity string `json:"severity"`
+	Rule     string `json:"rule"`
+	Item     string `json:"item"`
+	Message  string `json:"message"`
+}
+
+type pattern struct {
+	rule    string
+	regexp  *regexp.Regexp
+	message string
+}
+
+var credentialPatterns = []pattern{
+	{"plaintext-credential", regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----`), "contains a private key"},
+	{"plaintext-credential", regexp.MustCompile(`\b(AKIA|ASIA)[0-9A-Z]{16}\b`), "contains an AWS access key"},
+	{"plaintext-credential", regexp.MustCompile(`\bxox[abprs]-[0-9A-Za-z-]{10,}`), "contains a Slack token"},
+	{"plaintext-credential", regexp.MustCompile(`\bgh[pousr]_[0-9A-Za-z]{36}\b`), "contains a GitHub token"},
+	{"plaintext-credential", regexp.MustCompile(`(?i)\b(password|passwd|secret|api_?key|token)\b["']?\s*[:=]\s*["'][^"'\s]{8,}["']`), "assigns a credential literal"},
+}
+
+var dangerousPatterns = []pattern{
+	{"dangerous-command", regexp.MustCompile(`\bos\.(system|popen|exec[lv]p?e?)\s*\(`), "runs shell commands"},
+	{"dangerous-command", regexp.MustCompile(`\bsubprocess\b`), "runs processes"},
+	{"dangerous-command", regexp.MustCompile(`(^|[^.\w])(eval|exec|__import__)\s*\(`), "evaluates dynamic code"},
+	{"dangerous-command", regexp.MustCompile(`\b(pickle|marshal)\.loads?\s*\(`), "deserializes untrusted data"},
+	{"dangerous-command", regexp.MustCompile(`\brm\s+-[a-zA-Z]*r[a-zA-Z]*f|\brm\s+-[a-zA-Z]*f[a-zA-Z]*r`), "deletes files recursively"},
+	{"dangerous-command", regexp.MustCompile(`\b(curl|wget)\b[^|\n]*\|\s*(ba|z)?sh\b`), "pipes a download into a shell"},
+	{"dangerous-command", regexp.MustCompile(`\bbase64\.b64decode\b`), "decodes hidden payloads"},
+}
+
+var remoteRequirement = regexp.MustCompile(`(?i)(git\+|https?://|--index-url|--extra-index-url)`)
+
+// Scan checks the playbooks and reactions of a bundle. The findings are
+// ordered by item, errors first.
+func Scan(playbooks []Playbook, reactions []Reaction) []Finding {
+	var findings []Finding
+
+	for _, p := range playbooks {
+		item := fmt.Sprintf("playbook %q", p.Name)
+
+		findings = append(findings, match(item, text(p.Inputs)+"\n"+text(p.Tasks), credentialPatterns, SeverityWarning)...)
+	}
+
+	for _, r := range reactions {
+		findings = append(findings, scanReaction(r)...)
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		if findings[i].Item != findings[j].Item {
+			return findings[i].Item < findings[j].Item
+		}
+
+		return findings[i].Severity == SeverityError && findings[j].Severity != SeverityError
+	})
+
+	return findings
+}
+
+func scanReaction(r Reaction) []Finding {
+	item := fmt.Sprintf("reaction %q", r.Name)
+
+	var findings []Finding
+
+	if !slices.Contains(Runtimes, r.Action) {
+		findings = append(findings, Finding{
+			Severity: SeverityError,
+			Rule:     "runtime",
+			Item:     item,
+			Message:  fmt.Sprintf("the runtime %q is not allowed, allowed are %s", r.Action, strings.Join(Runtimes, ", ")),
+		})
+	}
+
+	if !slices.Contains(Triggers, r.Trigger) {
+		findings = append(findings, Finding{
+			Severity: SeverityError,
+			Rule:     "trigger",
+			Item:     item,
+			Message:  fmt.Sprintf("the trigger %q is not allowed, allowed are %s", r.Trigger, strings.Join(Triggers, ", ")),
+		})
+	}
+
+	if token, _ := r.Triggerdata["token"].(string); token != "" {
+		findings = append(findings, Finding{
+			Severity: SeverityWarning,
+			Rule:     "plaintext-credential",
+			Item:     item,
+			Message:  "the webhook trigger contains a token, set a new one after the import",
+		})
+	}
+
+	if headers, ok := r.Actiondata["headers"].(map[string]any); ok {
+		for _, name := range slices.Sorted(maps.Keys(headers)) {
+			if strings.EqualFold(name, "Authorization") || strings.Contains(strings.ToLower(name), "key") || strings.Contains(strings.ToLower(name), "token") {
+				findings = append(findings, Finding{
+					Severity: SeverityWarning,
+					Rule:     "plaintext-credential",
+					Item:     item,
+					Message:  fmt.Sprintf("the header %q contains a credential", name),
+				})
+			}
+		}
+	}
+
+	findings = append(findings, match(item, text(r.Actiondata)+"\n"+text(r.Triggerdata), credentialPatterns, SeverityWarning)...)
+
+	if script, _ := r.Actiondata["script"].(string); script != "" {
+		findings = append(findings, match(item, script, dangerousPatterns, SeverityWarning)...)
+	}
+
+	if requirements, _ := r.Actiondata["requirements"].(string); remoteRequirement.MatchString(requirements) {
+		findings = append(findings, Finding{
+			Severity: SeverityWarning,
+			Rule:     "remote-requirement",
+			Item:     item,
+			Message:  "the requirements install packages from a URL or another index",
+		})
+	}
+
+	return findings
+}
+
+// match returns a finding per pattern that matches the text, patterns with
+// the same message are reported once.
+func match(item, text string, patterns []pattern, severity string) []Finding {
+	var findings []Finding
+
+	for _, p := range patterns {
+		if !p.regexp.MatchString(text) {
+			continue
+		}
+
+		if slices.ContainsFunc(findings, func(f Finding) bool { return f.Message == p.message }) {
+			continue
+		}
+
+		findings = append(findings, Finding{Severity: severity, Rule: p.rule, Item: item, Message: p.message})
+	}
+
+	return findings
+}
+
+// text returns the strings in a value, one per line, and the string fields
+// of objects like "key = \"value\"", so that the patterns match scripts like
+// they are run and credentials assigned to fields.
+func text(value any) string {
+	b, err := json.Marshal(value)
+	if err != nil {
+		return ""
+	}
+
+	var generic any
+	if err := json.Unmarshal(b, &generic); err != nil {
+		return ""
+	}
+
+	var parts []string
+
+	var walk func(v any)
+	walk = func(v any) {
+		switch v := v.(type) {
+		case string:
+			parts = append(parts, v)
+		case map[string]any:
+			for key, value := range v {
+				if s, ok := value.(string); ok {
+					parts = append(parts, fmt.Sprintf("%s = %q", key, s))
+				}
+
+				walk(value)
+			}
+		case []any:
+			for _, value := range v {
+				walk(value)
+			}
+		}
+	}
+
+	walk(generic)
+
+	return strings.Join(parts, "\n")
+}
+
+// Blocked reports whether a finding blocks the import.
+func Blocked(findings []Finding) bool {
+	return slices.ContainsFunc(findings, func(f Finding) bool { return f.Severity == SeverityError })
+}
+
+// Digest returns the digest an admin confirms the import with. It changes
+// with every change of the bundle, so a confirmation only covers the
+// reviewed content.
+func Digest(playbooks []Playbook, reactions []Reaction) (string, error) {
+	b, err := json.Marshal(struct {
+		Playbooks []Playbook `json:"playbooks"`
+		Reactions []Reaction `json:"reactions"`
+	}{playbooks, reactions})
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(b)
+
+	return hex.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,120 @@
+// Package campaign runs a single playbook across a set of tickets, e.g. all
+// tickets of a phishing campaign. Each ticket added to a campaign gets its
+// own tasks from the playbook of the campaign, and the campaign reports the
+// completion of these tasks across all of its tickets.
+package campaign
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/SecurityBrewery/catalyst/app/database/sqlc"
+	"github.com/SecurityBrewery/catalyst/app/playbook"
+)
+
+// Create creates a campaign and adds the given tickets to it. The inputs
+// are checked against the playbook before the campaign is created.
+func Create(ctx context.Context, queries *sqlc.Queries, name, description string, playbookID *string, inputs map[string]any, tickets []string) (*sqlc.Campaign, []playbook.Run, error) {
+	if inputs == nil {
+		inputs = map[string]any{}
+	}
+
+	if playbookID != nil {
+		p, err := queries.GetPlaybook(ctx, *playbookID)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		declared, err := playbook.ParseInputs(p.Inputs)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid inputs of playbook %s: %w", p.ID, err)
+		}
+
+		if _, err := playbook.Resolve(declared, inputs); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	b, err := json.Marshal(inputs)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	campaign, err := queries.CreateCampaign(ctx, sqlc.CreateCampaignParams{
+		Name:        name,
+		Description: description,
+		Playbook:    playbookID,
+		Inputs:      b,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	runs, err := AddTickets(ctx, queries, campaign.ID, tickets)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &campaign, runs, nil
+}
+
+// AddTickets adds tickets to a campaign and attaches the playbook of the
+// campaign to each of them. Tickets that are already part of the campaign
+// are skipped. The runs of the attached playbooks are returned.
+func AddTickets(ctx context.Context, queries *sqlc.Queries, campaignID string, tickets []string) ([]playbook.Run, error) {
+	campaign, err := queries.GetCampaign(ctx, campaignID)
+	if err != nil {
+		return nil, err
+	}
+
+	inputs := map[string]any{}
+	if err := json.Unmarshal(campaign.Inputs, &inputs); err != nil {
+		return nil, fmt.Errorf("invalid inputs of campaign %s: %w", campaign.ID, err)
+	}
+
+	var runs []playbook.Run
+
+	for _, ticket := range tickets {
+		_, err := queries.GetCampaignTicket(ctx, sqlc.GetCampaignTicketParams{Campaign: campaign.ID, Ticket: ticket})
+		if err == nil {
+			continue
+		} else if !errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+
+		var runID *string
+
+		if campaign.Playbook != nil {
+			run, err := playbook.Attach(ctx, queries, *campaign.Playbook, ticket, inputs)
+			if err != nil {
+				return nil, fmt.Errorf("failed to attach the playbook to ticket %s: %w", ticket, err)
+			}
+
+			runID = &run.Run.ID
+			runs = append(runs, *run)
+		}
+
+		if _, err := queries.AddCampaignTicket(ctx, sqlc.AddCampaignTicketParams{
+			Campaign: campaign.ID,
+			Ticket:   ticket,
+			Run:      runID,
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	return runs, nil
+}
+
+// Completed reports whether all tasks of a campaign or one of its tickets
+// are closed. Without tasks, all tickets must be closed instead.
+func Completed(tasks, openTasks, tickets, openTickets int64) bool {
+	if tasks > 0 {
+		return openTasks == 0
+	}
+
+	return tickets > 0 && openTickets == 0
+}
@@ -0,0 +1,79 @@
+package campaign
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/SecurityBrewery/catalyst/app/data"
+	"github.com/SecurityBrewery/catalyst/app/database/sqlc"
+	"github.com/SecurityBrewery/catalyst/app/playbook"
+	"github.com/SecurityBrewery/catalyst/app/pointer"
+)
+
+func TestCreate(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	queries := data.NewTestDB(t, t.TempDir())
+
+	p, err := queries.CreatePlaybook(ctx, sqlc.CreatePlaybookParams{
+		Name:   "Purge mail",
+		Inputs: []byte(`[{"name":"sender","type":"string"}]`),
+		Tasks:  []byte(`[{"name":"Purge mails from {{ inputs.sender }}"},{"name":"Reset password of {{ ticket.name }}"}]`),
+	})
+	require.NoError(t, err)
+
+	second, err := queries.CreateTicket(ctx, sqlc.CreateTicketParams{Name: "Second Ticket", Open: true, Type: "incident", Schema: []byte(`{}`), State: []byte(`{}`)})
+	require.NoError(t, err)
+
+	_, _, err = Create(ctx, queries, "Phishing wave", "", &p.ID, nil, []string{"test-ticket"})
+	require.ErrorIs(t, err, playbook.ErrInvalidInputs)
+
+	campaign, runs, err := Create(ctx, queries, "Phishing wave", "", &p.ID, map[string]any{"sender": "ceo@evil.example"}, []string{"test-ticket", second.ID})
+	require.NoError(t, err)
+	require.Len(t, runs, 2)
+	assert.Equal(t, "Purge mails from ceo@evil.example", runs[0].Tasks[0].Name)
+	assert.Equal(t, "Reset password of Second Ticket", runs[1].Tasks[1].Name)
+
+	// tickets are added only once
+	runs, err = AddTickets(ctx, queries, campaign.ID, []string{"test-ticket"})
+	require.NoError(t, err)
+	assert.Empty(t, runs)
+
+	status, err := queries.GetCampaign(ctx, campaign.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), status.TicketCount)
+	assert.Equal(t, int64(4), status.TaskCount)
+	assert.Equal(t, int64(4), status.OpenTaskCount)
+	assert.False(t, Completed(status.TaskCount, status.OpenTaskCount, status.TicketCount, status.OpenTicketCount))
+
+	tickets, err := queries.ListCampaignTickets(ctx, campaign.ID)
+	require.NoError(t, err)
+
+	for _, ticket := range tickets {
+		tasks, err := queries.ListPlaybookRunTasks(ctx, *ticket.Run)
+		require.NoError(t, err)
+
+		for _, task := range tasks {
+			_, err := queries.UpdateTask(ctx, sqlc.UpdateTaskParams{ID: task.ID, Open: pointer.Pointer(false)})
+			require.NoError(t, err)
+		}
+	}
+
+	status, err = queries.GetCampaign(ctx, campaign.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), status.OpenTaskCount)
+	assert.True(t, Completed(status.TaskCount, status.OpenTaskCount, status.TicketCount, status.OpenTicketCount))
+}
+
+func TestCompleted(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, Completed(3, 0, 2, 2))
+	assert.False(t, Completed(3, 1, 2, 0))
+	assert.True(t, Completed(0, 0, 2, 0))
+	assert.False(t, Completed(0, 0, 2, 1))
+	assert.False(t, Completed(0, 0, 0, 0))
+}
@@ -0,0 +1,278 @@
+package campaign
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/SecurityBrewery/catalyst/app/canonical"
+	"github.com/SecurityBrewery/catalyst/app/database"
+	"github.com/SecurityBrewery/catalyst/app/database/sqlc"
+	"github.com/SecurityBrewery/catalyst/app/hook"
+	"github.com/SecurityBrewery/catalyst/app/openapi"
+	"github.com/SecurityBrewery/catalyst/app/settings"
+)
+
+// The kinds of values that group tickets into campaigns, in the order they
+// are checked.
+const (
+	FeatureSender  = "sender"
+	FeatureSubject = "subject"
+	FeatureURL     = "url"
+	FeatureHash    = "hash"
+
+	DefaultWindow     = 24
+	DefaultMinTickets = 3
+)
+
+var (
+	// replyPrefix matches prefixes like "Re:" and "FW:" of mail subjects.
+	replyPrefix = regexp.MustCompile(`(?i)^((re|aw|fw|fwd|wg)\s*:\s*)+`)
+
+	// tokenSeparators split text into candidate URLs and hashes, brackets are
+	// kept because they are part of defanged values like example[.]com.
+	tokenSeparators = func(r rune) bool {
+		return strings.ContainsRune(" \t\r\n\"'<>,;|", r)
+	}
+)
+
+// Feature is a value of a ticket that is shared by the tickets of a
+// campaign.
+type Feature struct {
+	Kind  string
+	Value string
+}
+
+// Features returns the sender and subject fields of the ticket state and the
+// URLs and hashes in the description and the state.
+func Features(description string, state map[string]any) []Feature {
+	var features []Feature
+
+	add := func(kind, value string) {
+		feature := Feature{Kind: kind, Value: value}
+		if value != "" && !slices.Contains(features, feature) {
+			features = append(features, feature)
+		}
+	}
+
+	for _, field := range []string{"sender", "from"} {
+		if sender, ok := state[field].(string); ok {
+			if email, err := canonical.Canonicalize(canonical.Email, sender); err == nil {
+				add(FeatureSender, email)
+			} else {
+				add(FeatureSender, strings.ToLower(strings.TrimSpace(sender)))
+			}
+		}
+	}
+
+	if subject, ok := state["subject"].(string); ok {
+		add(FeatureSubject, normalizeSubject(subject))
+	}
+
+	texts := append([]string{description}, stringValues(state)...)
+	for _, text := range texts {
+		for _, token := range strings.FieldsFunc(text, tokenSeparators) {
+			token = strings.TrimRight(token, ".:!?)")
+
+			switch canonical.Detect(token) {
+			case canonical.URL:
+				if url, err := canonical.Canonicalize(canonical.URL, token); err == nil {
+					add(FeatureURL, url)
+				}
+			case canonical.Hash:
+				if hash, err := canonical.Canonicalize(canonical.Hash, token); err == nil {
+					add(FeatureHash, hash)
+				}
+			}
+		}
+	}
+
+	slices.SortStableFunc(features, func(a, b Feature) int {
+		return featureOrder(a.Kind) - featureOrder(b.Kind)
+	})
+
+	return features
+}
+
+func featureOrder(kind string) int {
+	return slices.Index([]string{FeatureSender, FeatureSubject, FeatureURL, FeatureHash}, kind)
+}
+
+func normalizeSubject(subject string) string {
+	subject = replyPrefix.ReplaceAllString(strings.TrimSpace(subject), "")
+
+	return strings.ToLower(strings.Join(strings.Fields(subject), " "))
+}
+
+func stringValues(value any) []string {
+	switch v := value.(type) {
+	case string:
+		return []string{v}
+	case map[string]any:
+		var values []string
+		for _, key := range slices.Sorted(maps.Keys(v)) {
+			values = append(values, stringValues(v[key])...)
+		}
+
+		return values
+	case []any:
+		var values []string
+		for _, item := range v {
+			values = append(values, stringValues(item)...)
+		}
+
+		return values
+	default:
+		return nil
+	}
+}
+
+// Detector groups new tickets into campaigns.
+type Detector struct {
+	queries *sqlc.Queries
+	now     func() time.Time
+}
+
+func NewDetector(queries *sqlc.Queries) *Detector {
+	return &Detector{
+		queries: queries,
+		now:     time.Now,
+	}
+}
+
+// BindHooks checks every new ticket for a campaign. Encrypted tickets are
+// skipped, because their content must not be stored in plain text.
+func BindHooks(hooks *hook.Hooks, queries *sqlc.Queries) *Detector {
+	d := NewDetector(queries)
+
+	hooks.OnRecordAfterCreateRequest.Subscribe(func(ctx context.Context, table string, record any) {
+		if table != database.TicketsTable.ID {
+			return
+		}
+
+		ticket, ok := record.(openapi.Ticket)
+		if !ok || ticket.Encrypted {
+			return
+		}
+
+		if _, err := d.Detect(ctx, ticket.Id, ticket.Description, ticket.State); err != nil {
+			slog.ErrorContext(ctx, "Failed to detect campaign", "ticket", ticket.Id, "error", err)
+		}
+	})
+
+	return d
+}
+
+func withDefaults(config settings.CampaignDetection) settings.CampaignDetection {
+	if config.Window <= 0 {
+		config.Window = DefaultWindow
+	}
+
+	if config.MinTickets <= 0 {
+		config.MinTickets = DefaultMinTickets
+	}
+
+	return config
+}
+
+// Detect stores the features of a ticket and adds it to a campaign. A
+// ticket joins the latest detected campaign of one of its features that
+// grew within the window. Otherwise a campaign is created once MinTickets
+// created within the window share a feature. The returned campaign is nil
+// if the ticket did not join a campaign.
+func (d *Detector) Detect(ctx context.Context, ticket, description string, state map[string]any) (*sqlc.Campaign, error) {
+	se, err := settings.Load(ctx, d.queries)
+	if err != nil {
+		return nil, err
+	}
+
+	config := withDefaults(se.CampaignDetection)
+	if !config.Enabled {
+		return nil, nil
+	}
+
+	features := Features(description, state)
+
+	for _, feature := range features {
+		if err := d.queries.SetTicketFeature(ctx, sqlc.SetTicketFeatureParams{Ticket: ticket, Kind: feature.Kind, Value: feature.Value}); err != nil {
+			return nil, err
+		}
+	}
+
+	since := d.now().UTC().Add(-time.Duration(config.Window) * time.Hour).Format(time.DateTime)
+
+	for _, feature := range features {
+		campaign, err := d.queries.GetDetectedCampaign(ctx, sqlc.GetDetectedCampaignParams{Kind: &feature.Kind, Value: &feature.Value, Since: since})
+		if err == nil {
+			if err := d.join(ctx, &campaign, ticket, feature); err != nil {
+				return nil, err
+			}
+
+			return &campaign, nil
+		} else if !errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+
+		tickets, err := d.queries.ListTicketsWithFeature(ctx, sqlc.ListTicketsWithFeatureParams{Kind: feature.Kind, Value: feature.Value, Since: since})
+		if err != nil {
+			return nil, err
+		}
+
+		if len(tickets) >= config.MinTickets {
+			return d.create(ctx, feature, tickets)
+		}
+	}
+
+	return nil, nil
+}
+
+func (d *Detector) join(ctx context.Context, campaign *sqlc.Campaign, ticket string, feature Feature) error {
+	if _, err := AddTickets(ctx, d.queries, campaign.ID, []string{ticket}); err != nil {
+		return err
+	}
+
+	if err := d.queries.TouchCampaign(ctx, campaign.ID); err != nil {
+		return err
+	}
+
+	_, err := d.queries.CreateCampaignTimeline(ctx, sqlc.CreateCampaignTimelineParams{
+		Campaign: campaign.ID,
+		Ticket:   &ticket,
+		Message:  fmt.Sprintf("Ticket %s joined the campaign, it shares the %s %s", ticket, feature.Kind, feature.Value),
+	})
+
+	return err
+}
+
+func (d *Detector) create(ctx context.Context, feature Feature, tickets []string) (*sqlc.Campaign, error) {
+	campaign, err := d.queries.CreateCampaign(ctx, sqlc.CreateCampaignParams{
+		Name:         fmt.Sprintf("Campaign: %s %s", feature.Kind, feature.Value),
+		Description:  fmt.Sprintf("Detected %d tickets that share the %s %s.", len(tickets), feature.Kind, feature.Value),
+		Inputs:       []byte("{}"),
+		FeatureKind:  &feature.Kind,
+		FeatureValue: &feature.Value,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := AddTickets(ctx, d.queries, campaign.ID, tickets); err != nil {
+		return nil, err
+	}
+
+	if _, err := d.queries.CreateCampaignTimeline(ctx, sqlc.CreateCampaignTimelineParams{
+		Campaign: campaign.ID,
+		Message:  fmt.Sprintf("Campaign detected, %d tickets share the %s %s", len(tickets), feature.Kind, feature.Value),
+	}); err != nil {
+		return nil, err
+	}
+
+	return &campaign, nil
+}
@@ -0,0 +1,101 @@
+package campaign
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/SecurityBrewery/catalyst/app/data"
+	"github.com/SecurityBrewery/catalyst/app/database/sqlc"
+	"github.com/SecurityBrewery/catalyst/app/settings"
+)
+
+func TestFeatures(t *testing.T) {
+	t.Parallel()
+
+	features := Features(
+		"Reported mail links to hxxps://evil[.]example/login. Attachment 44D88612FEA8A8F36DE82E1278ABB02F",
+		map[string]any{
+			"sender":  "CEO@Evil.Example",
+			"subject": "RE: Fwd:  Urgent   Invoice",
+			"headers": map[string]any{"links": []any{"https://evil.example/login", "not a url"}},
+		},
+	)
+
+	assert.Equal(t, []Feature{
+		{Kind: FeatureSender, Value: "CEO@evil.example"},
+		{Kind: FeatureSubject, Value: "urgent invoice"},
+		{Kind: FeatureURL, Value: "https://evil.example/login"},
+		{Kind: FeatureHash, Value: "44d88612fea8a8f36de82e1278abb02f"},
+	}, features)
+
+	assert.Empty(t, Features("nothing to see", nil))
+}
+
+func TestDetector_Detect(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	queries := data.NewTestDB(t, t.TempDir())
+	d := NewDetector(queries)
+
+	newTicket := func(sender string) string {
+		t.Helper()
+
+		ticket, err := queries.CreateTicket(ctx, sqlc.CreateTicketParams{Name: "Phishing", Open: true, Type: "incident", Schema: []byte(`{}`), State: []byte(`{}`)})
+		require.NoError(t, err)
+
+		_, err = d.Detect(ctx, ticket.ID, "", map[string]any{"sender": sender})
+		require.NoError(t, err)
+
+		return ticket.ID
+	}
+
+	// disabled by default
+	newTicket("a@evil.example")
+	newTicket("a@evil.example")
+
+	campaigns, err := queries.ListCampaigns(ctx, sqlc.ListCampaignsParams{Limit: 10})
+	require.NoError(t, err)
+	assert.Empty(t, campaigns)
+
+	_, err = settings.Update(ctx, queries, func(se *settings.Settings) {
+		se.CampaignDetection = settings.CampaignDetection{Enabled: true, MinTickets: 2}
+	})
+	require.NoError(t, err)
+
+	first := newTicket("b@evil.example")
+	newTicket("other@example.com")
+	second := newTicket("b@evil.example")
+
+	campaigns, err = queries.ListCampaigns(ctx, sqlc.ListCampaignsParams{Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, campaigns, 1)
+	assert.Equal(t, "Campaign: sender b@evil.example", campaigns[0].Name)
+	assert.Equal(t, int64(2), campaigns[0].TicketCount)
+
+	// later tickets join the detected campaign
+	third := newTicket("b@evil.example")
+
+	tickets, err := queries.ListCampaignTickets(ctx, campaigns[0].ID)
+	require.NoError(t, err)
+
+	var ids []string
+	for _, ticket := range tickets {
+		ids = append(ids, ticket.Ticket)
+	}
+
+	assert.ElementsMatch(t, []string{first, second, third}, ids)
+
+	timeline, err := queries.ListCampaignTimeline(ctx, campaigns[0].ID)
+	require.NoError(t, err)
+	require.Len(t, timeline, 2)
+	assert.Equal(t, "Campaign detected, 2 tickets share the sender b@evil.example", timeline[0].Message)
+	assert.Equal(t, third, *timeline[1].Ticket)
+
+	linked, err := queries.ListTicketCampaigns(ctx, third)
+	require.NoError(t, err)
+	require.Len(t, linked, 1)
+	assert.Equal(t, campaigns[0].ID, linked[0].ID)
+}
@@ -0,0 +1,261 @@
+// Package canonical normalizes artifact values like domains, IP addresses,
+// hashes, URLs, email addresses and CVE IDs, so that different spellings of the same
+// value can be recognized and collapsed.
+package canonical
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/idna"
+)
+
+const (
+	Domain = "domain"
+	IP     = "ip"
+	Hash   = "hash"
+	URL    = "url"
+	Email  = "email"
+	CVE    = "cve"
+)
+
+var ErrUnknownKind = errors.New("unknown artifact kind")
+
+// Kinds returns the supported artifact kinds.
+func Kinds() []string {
+	return []string{Domain, IP, Hash, URL, Email, CVE}
+}
+
+// cvePattern matches CVE IDs like CVE-2021-44228.
+var cvePattern = regexp.MustCompile(`(?i)^cve-\d{4}-\d{4,}$`)
+
+// refanger reverts common defanging like hxxp:// and example[.]com.
+var refanger = strings.NewReplacer(
+	"hxxps://", "https://", "hXXps://", "https://",
+	"hxxp://", "http://", "hXXp://", "http://",
+	"[.]", ".", "(.)", ".", "[dot]", ".",
+	"[:]", ":", "[@]", "@", "[at]", "@",
+)
+
+// Canonicalize returns the canonical form of the value:
+//   - domains are lowercased IDNA A-labels without a trailing dot
+//   - IP addresses use the RFC 5952 form, IPv4-mapped IPv6 becomes IPv4
+//   - hashes are lowercase hex of the length of MD5, SHA-1, SHA-256 or SHA-512
+//   - URLs have a lowercase scheme, a canonical host without default port and
+//     a path without dot segments
+//   - email addresses have a canonical domain
+//   - CVE IDs are uppercase
+//
+// Defanged values are refanged first.
+func Canonicalize(kind, value string) (string, error) {
+	value = refanger.Replace(strings.TrimSpace(value))
+
+	switch kind {
+	case Domain:
+		return canonicalDomain(value)
+	case IP:
+		return canonicalIP(value)
+	case Hash:
+		return canonicalHash(value)
+	case URL:
+		return canonicalURL(value)
+	case Email:
+		return canonicalEmail(value)
+	case CVE:
+		return canonicalCVE(value)
+	default:
+		return "", fmt.Errorf("%w: %q", ErrUnknownKind, kind)
+	}
+}
+
+// Detect guesses the kind of the value, or returns an empty string.
+func Detect(value string) string {
+	value = refanger.Replace(strings.TrimSpace(value))
+
+	switch {
+	case strings.Contains(value, "://"):
+		return URL
+	case strings.Contains(value, "@"):
+		return Email
+	case cvePattern.MatchString(value):
+		return CVE
+	}
+
+	if _, err := canonicalIP(value); err == nil {
+		return IP
+	}
+
+	if _, err := canonicalHash(value); err == nil {
+		return Hash
+	}
+
+	if strings.Contains(value, ".") {
+		if _, err := canonicalDomain(value); err == nil {
+			return Domain
+		}
+	}
+
+	return ""
+}
+
+func canonicalDomain(value string) (string, error) {
+	domain, err := idna.Lookup.ToASCII(strings.TrimSuffix(value, "."))
+	if err != nil {
+		return "", fmt.Errorf("invalid domain %q: %w", value, err)
+	}
+
+	if domain == "" {
+		return "", fmt.Errorf("invalid domain %q", value)
+	}
+
+	return domain, nil
+}
+
+func canonicalIP(value string) (string, error) {
+	addr, err := netip.ParseAddr(strings.Trim(value, "[]"))
+	if err != nil {
+		return "", fmt.Errorf("invalid IP address %q: %w", value, err)
+	}
+
+	return addr.Unmap().String(), nil
+}
+
+func canonicalHash(value string) (string, error) {
+	hash := strings.ToLower(value)
+
+	switch len(hash) {
+	case 32, 40, 64, 128:
+	default:
+		return "", fmt.Errorf("invalid hash %q: unexpected length %d", value, len(hash))
+	}
+
+	for _, c := range hash {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return "", fmt.Errorf("invalid hash %q: not hexadecimal", value)
+		}
+	}
+
+	return hash, nil
+}
+
+var defaultPorts = map[string]string{"http": "80", "https": "443", "ftp": "21"}
+
+func canonicalURL(value string) (string, error) {
+	u, err := url.Parse(value)
+	if err != nil {
+		return "", fmt.Errorf("invalid URL %q: %w", value, err)
+	}
+
+	if u.Scheme == "" || u.Host == "" {
+		return "", fmt.Errorf("invalid URL %q: missing scheme or host", value)
+	}
+
+	u.Scheme = strings.ToLower(u.Scheme)
+
+	host, err := canonicalHost(u.Hostname())
+	if err != nil {
+		return "", fmt.Errorf("invalid URL %q: %w", value, err)
+	}
+
+	if port := u.Port(); port != "" && port != defaultPorts[u.Scheme] {
+		host = net.JoinHostPort(strings.Trim(host, "[]"), port)
+	}
+
+	u.Host = host
+
+	if u.Path == "" {
+		u.Path = "/"
+	} else {
+		trailingSlash := strings.HasSuffix(u.Path, "/")
+
+		u.Path = path.Clean(u.Path)
+		if trailingSlash && u.Path != "/" {
+			u.Path += "/"
+		}
+	}
+
+	u.RawPath = ""
+
+	return u.String(), nil
+}
+
+func canonicalHost(host string) (string, error) {
+	if ip, err := canonicalIP(host); err == nil {
+		if strings.Contains(ip, ":") {
+			return "[" + ip + "]", nil
+		}
+
+		return ip, nil
+	}
+
+	return canonicalDomain(host)
+}
+
+func canonicalEmail(value string) (string, error) {
+	local, domain, ok := strings.Cut(strings.TrimPrefix(value, "mailto:"), "@")
+	if !ok || local == "" {
+		return "", fmt.Errorf("invalid email address %q", value)
+	}
+
+	domain, err := canonicalDomain(domain)
+	if err != nil {
+		return "", fmt.Errorf("invalid email address %q: %w", value, err)
+	}
+
+	return local + "@" + domain, nil
+}
+
+func canonicalCVE(value string) (string, error) {
+	if !cvePattern.MatchString(value) {
+		return "", fmt.Errorf("invalid CVE ID %q", value)
+	}
+
+	return strings.ToUpper(value), nil
+}
+
+// Artifact is a canonical value and the original values it was collapsed
+// from.
+type Artifact struct {
+	Kind      string
+	Value     string
+	Originals []string
+}
+
+// Collapse canonicalizes the values and merges duplicates, keeping the
+// order of the first occurrence. If kind is empty, it is detected per value.
+// Values that cannot be canonicalized are returned as invalid.
+func Collapse(kind string, values []string) (artifacts []Artifact, invalid []string) {
+	index := map[string]int{}
+
+	for _, value := range values {
+		valueKind := kind
+		if valueKind == "" {
+			valueKind = Detect(value)
+		}
+
+		canonical, err := Canonicalize(valueKind, value)
+		if err != nil {
+			invalid = append(invalid, value)
+
+			continue
+		}
+
+		key := valueKind + "\x00" + canonical
+		if i, ok := index[key]; ok {
+			artifacts[i].Originals = append(artifacts[i].Originals, value)
+
+			continue
+		}
+
+		index[key] = len(artifacts)
+		artifacts = append(artifacts, Artifact{Kind: valueKind, Value: canonical, Originals: []string{value}})
+	}
+
+	return artifacts, invalid
+}
@@ -0,0 +1,94 @@
+package canonical
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCanonicalize(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		kind    string
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{kind: Domain, value: "Example.COM.", want: "example.com"},
+		{kind: Domain, value: "bücher.example", want: "xn--bcher-kva.example"},
+		{kind: Domain, value: "evil[.]example[.]com", want: "evil.example.com"},
+		{kind: Domain, value: "", wantErr: true},
+		{kind: IP, value: "2001:0DB8:0000:0000:0000:0000:0000:0001", want: "2001:db8::1"},
+		{kind: IP, value: "::ffff:192.0.2.1", want: "192.0.2.1"},
+		{kind: IP, value: "192.0.2[.]1", want: "192.0.2.1"},
+		{kind: IP, value: "192.0.2", wantErr: true},
+		{kind: Hash, value: " D41D8CD98F00B204E9800998ECF8427E ", want: "d41d8cd98f00b204e9800998ecf8427e"},
+		{kind: Hash, value: "d41d8cd98f00b204e9800998ecf8427", wantErr: true},
+		{kind: Hash, value: "z41d8cd98f00b204e9800998ecf8427e", wantErr: true},
+		{kind: URL, value: "HTTPS://Example.COM:443", want: "https://example.com/"},
+		{kind: URL, value: "hxxp://evil[.]example/a/./b/../c/?q=1#x", want: "http://evil.example/a/c/?q=1#x"},
+		{kind: URL, value: "http://[2001:DB8::1]:8080/path", want: "http://[2001:db8::1]:8080/path"},
+		{kind: URL, value: "http://bücher.example/", want: "http://xn--bcher-kva.example/"},
+		{kind: URL, value: "/relative", wantErr: true},
+		{kind: Email, value: "Alice@Example.COM", want: "Alice@example.com"},
+		{kind: Email, value: "bob[@]example[.]org", want: "bob@example.org"},
+		{kind: Email, value: "nobody", wantErr: true},
+		{kind: CVE, value: " cve-2021-44228", want: "CVE-2021-44228"},
+		{kind: CVE, value: "CVE-2021-442", wantErr: true},
+		{kind: "registry", value: "HKLM", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.kind+" "+tt.value, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := Canonicalize(tt.kind, tt.value)
+			if tt.wantErr {
+				require.Error(t, err)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestDetect(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, URL, Detect("hxxps://example.com/login"))
+	assert.Equal(t, Email, Detect("alice@example.com"))
+	assert.Equal(t, IP, Detect("2001:db8::1"))
+	assert.Equal(t, IP, Detect("192.0.2.1"))
+	assert.Equal(t, Hash, Detect("da39a3ee5e6b4b0d3255bfef95601890afd80709"))
+	assert.Equal(t, Domain, Detect("example[.]com"))
+	assert.Equal(t, CVE, Detect("CVE-2024-3094"))
+	assert.Empty(t, Detect("hello"))
+}
+
+func TestCollapse(t *testing.T) {
+	t.Parallel()
+
+	artifacts, invalid := Collapse("", []string{
+		"Example.com",
+		"192.0.2.1",
+		"example[.]com.",
+		"::ffff:192.0.2.1",
+		"not an artifact",
+		"EXAMPLE.COM",
+	})
+
+	assert.Equal(t, []Artifact{
+		{Kind: Domain, Value: "example.com", Originals: []string{"Example.com", "example[.]com.", "EXAMPLE.COM"}},
+		{Kind: IP, Value: "192.0.2.1", Originals: []string{"192.0.2.1", "::ffff:192.0.2.1"}},
+	}, artifacts)
+	assert.Equal(t, []string{"not an artifact"}, invalid)
+
+	artifacts, invalid = Collapse(Hash, []string{"D41D8CD98F00B204E9800998ECF8427E", "d41d8cd98f00b204e9800998ecf8427e", "example.com"})
+	assert.Len(t, artifacts, 1)
+	assert.Equal(t, []string{"example.com"}, invalid)
+}
@@ -0,0 +1,240 @@
+// Package casekey encrypts the sensitive fields of tickets with a random key
+// per ticket, the case key. Case keys are stored wrapped with a master key
+// that is kept in the data directory, outside the database. Backups do not
+// contain the master key, it must be exported and kept separately to restore
+// the encrypted tickets on another instance.
+package casekey
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	keyFileName = "case.key"
+	keySize     = 32
+
+	// Prefix marks encrypted values.
+	Prefix = "enc:v1:"
+)
+
+var (
+	ErrNotEncrypted = errors.New("value is not encrypted")
+	ErrMissingKey   = errors.New("the master key of the encrypted tickets is missing")
+	ErrWrongKey     = errors.New("the master key cannot decrypt the case keys of the encrypted tickets")
+)
+
+type Keyring struct {
+	master cipher.AEAD
+}
+
+// Load reads the master key from the data directory. wrapped is a stored
+// case key, or empty if no ticket is encrypted. The master key is only
+// created if no ticket is encrypted, and it must unwrap the stored case key,
+// so that a data directory that lost its master key fails to load instead
+// of getting a new one.
+func Load(dir, wrapped string) (*Keyring, error) {
+	path := filepath.Join(dir, keyFileName)
+
+	encoded, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) && wrapped == "" {
+		key := make([]byte, keySize)
+		if _, err := rand.Read(key); err != nil {
+			return nil, err
+		}
+
+		encoded = []byte(base64.StdEncoding.EncodeToString(key))
+
+		if err := os.WriteFile(path, encoded, 0o600); err != nil {
+			return nil, fmt.Errorf("failed to write master key: %w", err)
+		}
+	} else if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: import it to %s with catalyst case-key import", ErrMissingKey, path)
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read master key: %w", err)
+	}
+
+	return parse(path, string(encoded), wrapped)
+}
+
+// Check checks that the master key of the data directory unwraps the stored
+// case key wrapped, without creating a master key.
+func Check(dir, wrapped string) error {
+	if wrapped == "" {
+		return nil
+	}
+
+	path := filepath.Join(dir, keyFileName)
+
+	encoded, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("%w: import it to %s with catalyst case-key import", ErrMissingKey, path)
+	} else if err != nil {
+		return fmt.Errorf("failed to read master key: %w", err)
+	}
+
+	_, err = parse(path, string(encoded), wrapped)
+
+	return err
+}
+
+// Export returns the master key of the data directory, base64 encoded like
+// in its file.
+func Export(dir string) (string, error) {
+	path := filepath.Join(dir, keyFileName)
+
+	encoded, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read master key: %w", err)
+	}
+
+	if _, err := parse(path, string(encoded), ""); err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(string(encoded)), nil
+}
+
+// Import stores an exported master key in the data directory, e.g. before a
+// backup is restored on another instance. The key must unwrap the stored
+// case key wrapped, which is empty if no ticket is encrypted. A different
+// master key is not replaced.
+func Import(dir, encoded, wrapped string) error {
+	if _, err := parse("the import", encoded, wrapped); err != nil {
+		return err
+	}
+
+	path := filepath.Join(dir, keyFileName)
+
+	encoded = strings.TrimSpace(encoded)
+
+	existing, err := os.ReadFile(path)
+	if err == nil {
+		if strings.TrimSpace(string(existing)) == encoded {
+			return nil
+		}
+
+		return fmt.Errorf("a different master key exists in %s", path)
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to read master key: %w", err)
+	}
+
+	if err := os.WriteFile(path, []byte(encoded), 0o600); err != nil {
+		return fmt.Errorf("failed to write master key: %w", err)
+	}
+
+	return nil
+}
+
+// parse decodes a master key read from source and checks that it unwraps
+// the stored case key wrapped, unless it is empty.
+func parse(source, encoded, wrapped string) (*Keyring, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil || len(key) != keySize {
+		return nil, fmt.Errorf("invalid master key in %s", source)
+	}
+
+	master, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	keyring := &Keyring{master: master}
+
+	if wrapped != "" {
+		if _, err := keyring.Unwrap(wrapped); err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrWrongKey, source)
+		}
+	}
+
+	return keyring, nil
+}
+
+// NewCaseKey returns a random case key and its wrapped form for storage.
+func (k *Keyring) NewCaseKey() (key []byte, wrapped string, err error) {
+	key = make([]byte, keySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, "", err
+	}
+
+	wrapped, err = seal(k.master, key)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return key, wrapped, nil
+}
+
+// Unwrap returns the case key of a wrapped key.
+func (k *Keyring) Unwrap(wrapped string) ([]byte, error) {
+	return open(k.master, wrapped)
+}
+
+// Encrypt encrypts the plaintext with the case key.
+func Encrypt(key, plaintext []byte) (string, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return "", err
+	}
+
+	return seal(aead, plaintext)
+}
+
+// Decrypt decrypts a value encrypted with the case key.
+func Decrypt(key []byte, value string) ([]byte, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return open(aead, value)
+}
+
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, Prefix)
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+func seal(aead cipher.AEAD, plaintext []byte) (string, error) {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	return Prefix + base64.StdEncoding.EncodeToString(aead.Seal(nonce, nonce, plaintext, nil)), nil
+}
+
+func open(aead cipher.AEAD, value string) ([]byte, error) {
+	encoded, ok := strings.CutPrefix(value, Prefix)
+	if !ok {
+		return nil, ErrNotEncrypted
+	}
+
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("invalid encrypted value: %w", err)
+	}
+
+	if len(data) < aead.NonceSize() {
+		return nil, errors.New("invalid encrypted value: too short")
+	}
+
+	nonce, ciphertext := data[:aead.NonceSize()], data[aead.NonceSize():]
+
+	return aead.Open(nil, nonce, ciphertext, nil)
+}
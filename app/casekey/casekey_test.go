@@ -0,0 +1,163 @@
+package casekey
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyring(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	keyring, err := Load(dir, "")
+	require.NoError(t, err)
+
+	info, err := os.Stat(filepath.Join(dir, keyFileName))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	key, wrapped, err := keyring.NewCaseKey()
+	require.NoError(t, err)
+	assert.True(t, IsEncrypted(wrapped))
+
+	// the master key is reused
+	reloaded, err := Load(dir, "")
+	require.NoError(t, err)
+
+	unwrapped, err := reloaded.Unwrap(wrapped)
+	require.NoError(t, err)
+	assert.Equal(t, key, unwrapped)
+
+	// a different master key cannot unwrap the case key
+	other, err := Load(t.TempDir(), "")
+	require.NoError(t, err)
+
+	_, err = other.Unwrap(wrapped)
+	require.Error(t, err)
+}
+
+func TestEncryptDecrypt(t *testing.T) {
+	t.Parallel()
+
+	keyring, err := Load(t.TempDir(), "")
+	require.NoError(t, err)
+
+	key, _, err := keyring.NewCaseKey()
+	require.NoError(t, err)
+
+	encrypted, err := Encrypt(key, []byte("patient records"))
+	require.NoError(t, err)
+	assert.True(t, IsEncrypted(encrypted))
+	assert.NotContains(t, encrypted, "patient")
+
+	plaintext, err := Decrypt(key, encrypted)
+	require.NoError(t, err)
+	assert.Equal(t, "patient records", string(plaintext))
+
+	otherKey, _, err := keyring.NewCaseKey()
+	require.NoError(t, err)
+
+	_, err = Decrypt(otherKey, encrypted)
+	require.Error(t, err)
+
+	_, err = Decrypt(key, "patient records")
+	require.ErrorIs(t, err, ErrNotEncrypted)
+}
+
+func TestLoad_invalid(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, keyFileName), []byte("short"), 0o600))
+
+	_, err := Load(dir, "")
+	require.Error(t, err)
+}
+
+func TestLoad_missing(t *testing.T) {
+	t.Parallel()
+
+	keyring, err := Load(t.TempDir(), "")
+	require.NoError(t, err)
+
+	_, wrapped, err := keyring.NewCaseKey()
+	require.NoError(t, err)
+
+	// a master key is not created for encrypted tickets
+	dir := t.TempDir()
+
+	_, err = Load(dir, wrapped)
+	require.ErrorIs(t, err, ErrMissingKey)
+	assert.NoFileExists(t, filepath.Join(dir, keyFileName))
+
+	require.ErrorIs(t, Check(dir, wrapped), ErrMissingKey)
+	require.NoError(t, Check(dir, ""))
+}
+
+func TestLoad_wrong(t *testing.T) {
+	t.Parallel()
+
+	keyring, err := Load(t.TempDir(), "")
+	require.NoError(t, err)
+
+	_, wrapped, err := keyring.NewCaseKey()
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+
+	_, err = Load(dir, "")
+	require.NoError(t, err)
+
+	_, err = Load(dir, wrapped)
+	require.ErrorIs(t, err, ErrWrongKey)
+
+	require.ErrorIs(t, Check(dir, wrapped), ErrWrongKey)
+}
+
+func TestExportImport(t *testing.T) {
+	t.Parallel()
+
+	source := t.TempDir()
+
+	keyring, err := Load(source, "")
+	require.NoError(t, err)
+
+	key, wrapped, err := keyring.NewCaseKey()
+	require.NoError(t, err)
+
+	exported, err := Export(source)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	require.NoError(t, Import(dir, exported+"\n", wrapped))
+	require.NoError(t, Import(dir, exported, wrapped))
+
+	imported, err := Load(dir, wrapped)
+	require.NoError(t, err)
+
+	unwrapped, err := imported.Unwrap(wrapped)
+	require.NoError(t, err)
+	assert.Equal(t, key, unwrapped)
+
+	// a key that cannot unwrap the case key is not imported
+	other := t.TempDir()
+
+	_, err = Load(other, "")
+	require.NoError(t, err)
+
+	otherKey, err := Export(other)
+	require.NoError(t, err)
+
+	require.ErrorIs(t, Import(t.TempDir(), otherKey, wrapped), ErrWrongKey)
+
+	// an existing master key is not replaced
+	require.Error(t, Import(other, exported, wrapped))
+
+	_, err = Export(t.TempDir())
+	require.Error(t, err)
+}
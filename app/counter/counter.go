@@ -1,6 +1,9 @@
 package counter
 
-import "sync"
+import (
+	"maps"
+	"sync"
+)
 
 type Counter struct {
 	mux    sync.Mutex
@@ -34,3 +37,10 @@ func (c *Counter) Count(name string) int {
 
 	return c.counts[name]
 }
+
+func (c *Counter) Counts() map[string]int {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	return maps.Clone(c.counts)
+}
@@ -39,3 +39,19 @@ func TestCounter(t *testing.T) {
 		})
 	}
 }
+
+func TestCounter_Counts(t *testing.T) {
+	t.Parallel()
+
+	c := NewCounter()
+	c.Increment("a")
+	c.Increment("a")
+	c.Increment("b")
+
+	counts := c.Counts()
+	assert.Equal(t, map[string]int{"a": 2, "b": 1}, counts)
+
+	// the snapshot is not affected by later increments
+	c.Increment("a")
+	assert.Equal(t, 2, counts["a"])
+}
@@ -0,0 +1,165 @@
+// Package custody keeps the chain of custody of ticket files: who uploaded,
+// downloaded and deleted each file, with the SHA-256 hash of its content.
+// The log is exported as a signed PDF document.
+package custody
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/SecurityBrewery/catalyst/app/auth/usercontext"
+	"github.com/SecurityBrewery/catalyst/app/database/sqlc"
+	"github.com/SecurityBrewery/catalyst/app/pdf"
+	"github.com/SecurityBrewery/catalyst/app/pointer"
+)
+
+const (
+	Upload   = "upload"
+	Download = "download"
+	Delete   = "delete"
+
+	keyFileName = "custody.key"
+)
+
+// Record hashes the content of the file and adds the action of the current
+// user to the chain of custody.
+func Record(ctx context.Context, queries *sqlc.Queries, file *sqlc.File, action string, content io.Reader) error {
+	hash := sha256.New()
+	if _, err := io.Copy(hash, content); err != nil {
+		return fmt.Errorf("failed to hash file: %w", err)
+	}
+
+	var userID *string
+	if user, ok := usercontext.UserFromContext(ctx); ok {
+		userID = &user.ID
+	}
+
+	return queries.CreateCustodyEvent(ctx, sqlc.CreateCustodyEventParams{
+		Ticket:   file.Ticket,
+		File:     file.ID,
+		FileName: file.Name,
+		Action:   action,
+		User:     userID,
+		Sha256:   hex.EncodeToString(hash.Sum(nil)),
+	})
+}
+
+// Signer signs custody documents with an Ed25519 key that is kept in the
+// data directory.
+type Signer struct {
+	key ed25519.PrivateKey
+}
+
+// Load reads the signing key from the data directory and creates it on
+// first use.
+func Load(dir string) (*Signer, error) {
+	path := filepath.Join(dir, keyFileName)
+
+	encoded, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		seed := make([]byte, ed25519.SeedSize)
+		if _, err := rand.Read(seed); err != nil {
+			return nil, err
+		}
+
+		encoded = []byte(base64.StdEncoding.EncodeToString(seed))
+
+		if err := os.WriteFile(path, encoded, 0o600); err != nil {
+			return nil, fmt.Errorf("failed to write signing key: %w", err)
+		}
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read signing key: %w", err)
+	}
+
+	seed, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(encoded)))
+	if err != nil || len(seed) != ed25519.SeedSize {
+		return nil, fmt.Errorf("invalid signing key in %s", path)
+	}
+
+	return &Signer{key: ed25519.NewKeyFromSeed(seed)}, nil
+}
+
+// PublicKey returns the base64 encoded public key to verify signatures.
+func (s *Signer) PublicKey() string {
+	return base64.StdEncoding.EncodeToString(s.key.Public().(ed25519.PublicKey))
+}
+
+// Sign returns the base64 encoded Ed25519 signature of the document.
+func (s *Signer) Sign(document []byte) string {
+	return base64.StdEncoding.EncodeToString(ed25519.Sign(s.key, document))
+}
+
+// Document renders the chain of custody of the files of a ticket as PDF.
+func Document(ticket *sqlc.TicketRow, events []sqlc.ListCustodyEventsRow, generatedBy string, generated time.Time) []byte {
+	lines := []string{
+		"Ticket: " + ticket.ID + " - " + ticket.Name,
+		"Type: " + ticket.Type,
+		"Owner: " + pointer.Dereference(ticket.OwnerName),
+		"Generated: " + generated.UTC().Format(time.RFC3339) + " by " + generatedBy,
+		"",
+	}
+
+	var (
+		order  []string
+		byFile = map[string][]sqlc.ListCustodyEventsRow{}
+	)
+
+	for _, event := range events {
+		if _, ok := byFile[event.File]; !ok {
+			order = append(order, event.File)
+		}
+
+		byFile[event.File] = append(byFile[event.File], event)
+	}
+
+	if len(order) == 0 {
+		lines = append(lines, "No file custody events were recorded for this ticket.")
+	}
+
+	for _, file := range order {
+		fileEvents := byFile[file]
+		uploaded := ""
+
+		lines = append(lines, fmt.Sprintf("File: %s (%s)", fileEvents[0].FileName, file))
+
+		for _, event := range fileEvents {
+			note := ""
+
+			switch {
+			case event.Action == Upload:
+				uploaded = event.Sha256
+			case uploaded != "" && event.Sha256 != uploaded:
+				note = "  HASH MISMATCH"
+			}
+
+			lines = append(lines, fmt.Sprintf("  %s  %-8s  %s", event.Created.UTC().Format(time.RFC3339), event.Action, userName(&event)))
+			lines = append(lines, "    SHA-256 "+event.Sha256+note)
+		}
+
+		lines = append(lines, "")
+	}
+
+	return pdf.Render("Chain of Custody", lines)
+}
+
+func userName(event *sqlc.ListCustodyEventsRow) string {
+	switch {
+	case event.User == nil:
+		return "unknown user"
+	case event.UserName != nil && *event.UserName != "":
+		return *event.UserName + " (" + pointer.Dereference(event.Username) + ")"
+	default:
+		return *event.User
+	}
+}
@@ -0,0 +1,74 @@
+package custody
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/SecurityBrewery/catalyst/app/auth/usercontext"
+	"github.com/SecurityBrewery/catalyst/app/data"
+	"github.com/SecurityBrewery/catalyst/app/database/sqlc"
+)
+
+func TestRecord(t *testing.T) {
+	t.Parallel()
+
+	queries := data.NewTestDB(t, t.TempDir())
+	ctx := usercontext.UserContext(t.Context(), &sqlc.User{ID: "u_bob_analyst"})
+
+	file, err := queries.GetFile(ctx, "b_test_file")
+	require.NoError(t, err)
+
+	require.NoError(t, Record(ctx, queries, &file, Upload, strings.NewReader("hello")))
+	require.NoError(t, Record(t.Context(), queries, &file, Download, strings.NewReader("hellO")))
+
+	events, err := queries.ListCustodyEvents(ctx, "test-ticket")
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+
+	assert.Equal(t, Upload, events[0].Action)
+	assert.Equal(t, "u_bob_analyst", *events[0].User)
+	assert.Equal(t, "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824", events[0].Sha256)
+	assert.Nil(t, events[1].User)
+
+	ticket, err := queries.Ticket(ctx, "test-ticket")
+	require.NoError(t, err)
+
+	document := Document(&ticket, events, "Bob Analyst (bob)", time.Date(2025, 6, 22, 8, 0, 0, 0, time.UTC))
+
+	assert.True(t, bytes.HasPrefix(document, []byte("%PDF-1.4")))
+	assert.True(t, bytes.HasSuffix(document, []byte("%%EOF\n")))
+	assert.Contains(t, string(document), "(Ticket: test-ticket - Test Ticket) Tj")
+	assert.Contains(t, string(document), "(File: hello.txt \\(b_test_file\\)) Tj")
+	assert.Contains(t, string(document), "HASH MISMATCH")
+	assert.Contains(t, string(document), "by Bob Analyst \\(bob\\)")
+}
+
+func TestSigner(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	signer, err := Load(dir)
+	require.NoError(t, err)
+
+	signature, err := base64.StdEncoding.DecodeString(signer.Sign([]byte("document")))
+	require.NoError(t, err)
+
+	publicKey, err := base64.StdEncoding.DecodeString(signer.PublicKey())
+	require.NoError(t, err)
+
+	assert.True(t, ed25519.Verify(publicKey, []byte("document"), signature))
+	assert.False(t, ed25519.Verify(publicKey, []byte("changed"), signature))
+
+	// the key is reused
+	reloaded, err := Load(dir)
+	require.NoError(t, err)
+	assert.Equal(t, signer.PublicKey(), reloaded.PublicKey())
+}
@@ -0,0 +1,296 @@
+// Package cve tracks the CVEs referenced in tickets. CVE IDs are extracted
+// from new and updated tickets and enriched in the background with the CVSS
+// score and the affected products from NVD and OSV. The known exploited
+// vulnerabilities catalog of CISA flags CVEs that are exploited in the wild.
+package cve
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/SecurityBrewery/catalyst/app/database"
+	"github.com/SecurityBrewery/catalyst/app/database/sqlc"
+	"github.com/SecurityBrewery/catalyst/app/enrichment"
+	"github.com/SecurityBrewery/catalyst/app/hook"
+	"github.com/SecurityBrewery/catalyst/app/openapi"
+	"github.com/SecurityBrewery/catalyst/app/settings"
+)
+
+const (
+	DefaultNVDURL = "https://services.nvd.nist.gov/rest/json/cves/2.0"
+	DefaultOSVURL = "https://api.osv.dev/v1/vulns"
+	DefaultKEVURL = "https://www.cisa.gov/sites/default/files/feeds/known_exploited_vulnerabilities.json"
+
+	// EnricherName is the name of the CVE lookups in the TTLs of the
+	// enrichment cache settings.
+	EnricherName = "cve"
+
+	checkInterval = time.Minute
+	kevInterval   = 24 * time.Hour
+
+	// batchSize limits the lookups per minute to stay within the rate limit
+	// of NVD for clients without an API key.
+	batchSize = 5
+
+	// maxSize limits the size of a response.
+	maxSize = 64 << 20
+)
+
+var (
+	ErrDisabled = errors.New("CVE enrichment is disabled")
+
+	pattern = regexp.MustCompile(`(?i)\bcve-\d{4}-\d{4,}\b`)
+)
+
+// IDs returns the uppercase CVE IDs in the texts in the order of their first
+// occurrence.
+func IDs(texts ...string) []string {
+	var ids []string
+
+	for _, text := range texts {
+		for _, match := range pattern.FindAllString(text, -1) {
+			if id := strings.ToUpper(match); !slices.Contains(ids, id) {
+				ids = append(ids, id)
+			}
+		}
+	}
+
+	return ids
+}
+
+// Record links the CVEs in the name, description and state of a ticket to
+// the ticket. CVEs that are removed from the ticket stay linked.
+func Record(ctx context.Context, queries *sqlc.Queries, ticket, name, description string, state map[string]any) ([]string, error) {
+	b, err := json.Marshal(state)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := IDs(name, description, string(b))
+
+	for _, id := range ids {
+		if err := queries.CreateCVE(ctx, id); err != nil {
+			return nil, err
+		}
+
+		if err := queries.AddTicketCVE(ctx, sqlc.AddTicketCVEParams{Ticket: ticket, Cve: id}); err != nil {
+			return nil, err
+		}
+	}
+
+	return ids, nil
+}
+
+// BindHooks records the CVEs of new and updated tickets. Encrypted tickets
+// are skipped, their content is redacted in the hooks.
+func BindHooks(hooks *hook.Hooks, queries *sqlc.Queries) {
+	record := func(ctx context.Context, table string, r any) {
+		if table != database.TicketsTable.ID {
+			return
+		}
+
+		ticket, ok := r.(openapi.Ticket)
+		if !ok || ticket.Encrypted {
+			return
+		}
+
+		if _, err := Record(ctx, queries, ticket.Id, ticket.Name, ticket.Description, ticket.State); err != nil {
+			slog.ErrorContext(ctx, "Failed to record the CVEs of a ticket", "ticket", ticket.Id, "error", err)
+		}
+	}
+
+	hooks.OnRecordAfterCreateRequest.Subscribe(record)
+	hooks.OnRecordAfterUpdateRequest.Subscribe(record)
+}
+
+func withDefaults(config settings.CVEEnrichment) settings.CVEEnrichment {
+	config.NVDURL = cmp.Or(config.NVDURL, DefaultNVDURL)
+	config.OSVURL = cmp.Or(config.OSVURL, DefaultOSVURL)
+	config.KEVURL = cmp.Or(config.KEVURL, DefaultKEVURL)
+
+	return config
+}
+
+// Enricher looks up the CVEs of tickets.
+type Enricher struct {
+	queries *sqlc.Queries
+	client  *http.Client
+	now     func() time.Time
+
+	mu           sync.Mutex
+	kevRefreshed time.Time
+}
+
+func New(queries *sqlc.Queries) *Enricher {
+	return &Enricher{
+		queries: queries,
+		client:  &http.Client{Timeout: 30 * time.Second},
+		now:     time.Now,
+	}
+}
+
+// Start refreshes the known exploited vulnerabilities once a day and
+// enriches new and expired CVEs every minute until the context is canceled.
+func (e *Enricher) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(checkInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := e.enrichDue(ctx); err != nil {
+					slog.ErrorContext(ctx, "Failed to enrich CVEs", "error", err)
+				}
+			}
+		}
+	}()
+}
+
+func (e *Enricher) enrichDue(ctx context.Context) error {
+	se, err := settings.Load(ctx, e.queries)
+	if err != nil {
+		return err
+	}
+
+	if !se.CVEEnrichment.Enabled {
+		return nil
+	}
+
+	now := e.now().UTC()
+
+	e.mu.Lock()
+	due := e.kevRefreshed.IsZero() || now.Sub(e.kevRefreshed) >= kevInterval
+	e.mu.Unlock()
+
+	var errs []error
+
+	if due {
+		if _, err := e.RefreshKEV(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("failed to refresh the known exploited vulnerabilities: %w", err))
+		}
+	}
+
+	before := now.Add(-enrichment.TTL(&se.EnrichmentCache, EnricherName))
+
+	ids, err := e.queries.ListCVEsToEnrich(ctx, sqlc.ListCVEsToEnrichParams{Before: &before, Limit: batchSize})
+	if err != nil {
+		return errors.Join(append(errs, err)...)
+	}
+
+	for _, id := range ids {
+		if err := e.Enrich(ctx, id); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", id, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Enrich looks up a CVE in NVD and OSV. NVD is preferred for the
+// description and the CVSS score, the affected products of both are merged.
+func (e *Enricher) Enrich(ctx context.Context, id string) error {
+	se, err := settings.Load(ctx, e.queries)
+	if err != nil {
+		return err
+	}
+
+	if !se.CVEEnrichment.Enabled {
+		return ErrDisabled
+	}
+
+	config := withDefaults(se.CVEEnrichment)
+
+	nvd, err := e.fetchNVD(ctx, &config, id)
+	if err != nil {
+		return fmt.Errorf("failed to look up %s in NVD: %w", id, err)
+	}
+
+	osv, err := e.fetchOSV(ctx, &config, id)
+	if err != nil {
+		return fmt.Errorf("failed to look up %s in OSV: %w", id, err)
+	}
+
+	details := merge(nvd, osv)
+
+	products, err := json.Marshal(details.Products)
+	if err != nil {
+		return err
+	}
+
+	enriched := e.now().UTC().Truncate(time.Second)
+
+	return e.queries.UpdateCVE(ctx, sqlc.UpdateCVEParams{
+		ID:           id,
+		Description:  details.Description,
+		CvssScore:    details.Score,
+		CvssSeverity: details.Severity,
+		CvssVector:   details.Vector,
+		Products:     products,
+		Published:    details.Published,
+		Enriched:     &enriched,
+	})
+}
+
+// RefreshKEV replaces the known exploited vulnerabilities with the current
+// catalog and returns the number of its entries.
+func (e *Enricher) RefreshKEV(ctx context.Context) (int, error) {
+	se, err := settings.Load(ctx, e.queries)
+	if err != nil {
+		return 0, err
+	}
+
+	if !se.CVEEnrichment.Enabled {
+		return 0, ErrDisabled
+	}
+
+	config := withDefaults(se.CVEEnrichment)
+
+	entries, err := e.fetchKEV(ctx, &config)
+	if err != nil {
+		return 0, err
+	}
+
+	// an empty catalog is most likely a broken mirror, the previous one is
+	// kept in that case
+	if len(entries) == 0 {
+		return 0, errors.New("the known exploited vulnerabilities catalog is empty")
+	}
+
+	updated := e.now().UTC().Truncate(time.Second)
+
+	for _, entry := range entries {
+		if err := e.queries.SetKnownExploited(ctx, sqlc.SetKnownExploitedParams{
+			Cve:        strings.ToUpper(entry.CVE),
+			Name:       entry.Name,
+			Added:      entry.Added,
+			Due:        entry.Due,
+			Ransomware: strings.EqualFold(entry.Ransomware, "known"),
+			Updated:    updated,
+		}); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := e.queries.DeleteKnownExploitedBefore(ctx, updated); err != nil {
+		return 0, err
+	}
+
+	e.mu.Lock()
+	e.kevRefreshed = updated
+	e.mu.Unlock()
+
+	return len(entries), nil
+}
@@ -0,0 +1,140 @@
+package cve
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/SecurityBrewery/catalyst/app/data"
+	"github.com/SecurityBrewery/catalyst/app/database/sqlc"
+	"github.com/SecurityBrewery/catalyst/app/settings"
+)
+
+const (
+	nvdLog4Shell = `{"vulnerabilities": [{"cve": {
+		"id": "CVE-2021-44228",
+		"published": "2021-12-10T10:15:09.143",
+		"descriptions": [{"lang": "es", "value": "Apache Log4j2 ..."}, {"lang": "en", "value": "Apache Log4j2 JNDI features do not protect against attacker controlled LDAP endpoints."}],
+		"metrics": {
+			"cvssMetricV31": [{"cvssData": {"baseScore": 10.0, "baseSeverity": "CRITICAL", "vectorString": "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:C/C:H/I:H/A:H"}}],
+			"cvssMetricV2": [{"cvssData": {"baseScore": 9.3, "vectorString": "AV:N/AC:M/Au:N/C:C/I:C/A:C"}, "baseSeverity": "HIGH"}]
+		},
+		"configurations": [{"nodes": [{"cpeMatch": [
+			{"vulnerable": true, "criteria": "cpe:2.3:a:apache:log4j:*:*:*:*:*:*:*:*"},
+			{"vulnerable": true, "criteria": "cpe:2.3:a:apache:log4j:2.0:beta9:*:*:*:*:*:*"},
+			{"vulnerable": false, "criteria": "cpe:2.3:o:linux:linux_kernel:-:*:*:*:*:*:*:*"}
+		]}]}]
+	}}]}`
+	osvLog4Shell = `{
+		"id": "CVE-2021-44228",
+		"summary": "Remote code injection in Log4j",
+		"published": "2021-12-10T00:00:00Z",
+		"affected": [{"package": {"ecosystem": "Maven", "name": "org.apache.logging.log4j:log4j-core"}}]
+	}`
+	kevCatalog = `{"vulnerabilities": [
+		{"cveID": "CVE-2021-44228", "vulnerabilityName": "Apache Log4j2 Remote Code Execution Vulnerability", "dateAdded": "2021-12-10", "dueDate": "2021-12-24", "knownRansomwareCampaignUse": "Known"}
+	]}`
+)
+
+func TestIDs(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, []string{"CVE-2021-44228", "CVE-2024-3094"}, IDs(
+		"Exploitation of cve-2021-44228 (Log4Shell)",
+		`{"related": ["CVE-2024-3094", "CVE-2021-44228"], "other": "CVE-21-1"}`,
+	))
+	assert.Empty(t, IDs("no CVEs here"))
+}
+
+func newSources(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/nvd", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "secret", r.Header.Get("apiKey"))
+
+		if r.URL.Query().Get("cveId") != "CVE-2021-44228" {
+			_, _ = w.Write([]byte(`{"vulnerabilities": []}`))
+
+			return
+		}
+
+		_, _ = w.Write([]byte(nvdLog4Shell))
+	})
+	mux.HandleFunc("/osv/CVE-2021-44228", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(osvLog4Shell))
+	})
+	mux.HandleFunc("/kev.json", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(kevCatalog))
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestEnricher(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	queries := data.NewTestDB(t, t.TempDir())
+	server := newSources(t)
+	e := New(queries)
+
+	ticket, err := queries.CreateTicket(ctx, sqlc.CreateTicketParams{Name: "Log4Shell on the VPN", Open: true, Type: "incident", Schema: []byte(`{}`), State: []byte(`{}`)})
+	require.NoError(t, err)
+
+	ids, err := Record(ctx, queries, ticket.ID, ticket.Name, "Exploit attempts for CVE-2021-44228", map[string]any{"cves": []any{"CVE-2023-0001"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"CVE-2021-44228", "CVE-2023-0001"}, ids)
+
+	require.ErrorIs(t, e.Enrich(ctx, "CVE-2021-44228"), ErrDisabled)
+
+	_, err = settings.Update(ctx, queries, func(se *settings.Settings) {
+		se.CVEEnrichment = settings.CVEEnrichment{
+			Enabled:   true,
+			NVDURL:    server.URL + "/nvd",
+			NVDAPIKey: "secret",
+			OSVURL:    server.URL + "/osv",
+			KEVURL:    server.URL + "/kev.json",
+		}
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, e.enrichDue(ctx))
+
+	log4shell, err := queries.GetCVE(ctx, "CVE-2021-44228")
+	require.NoError(t, err)
+	assert.Equal(t, "Apache Log4j2 JNDI features do not protect against attacker controlled LDAP endpoints.", log4shell.Description)
+	assert.InDelta(t, 10.0, *log4shell.CvssScore, 0.001)
+	assert.Equal(t, "critical", *log4shell.CvssSeverity)
+	assert.JSONEq(t, `["apache log4j", "org.apache.logging.log4j:log4j-core (Maven)"]`, string(log4shell.Products))
+	assert.Equal(t, "2021-12-10 10:15:09", log4shell.Published.Format("2006-01-02 15:04:05"))
+	assert.NotNil(t, log4shell.Enriched)
+	assert.True(t, log4shell.Kev)
+	assert.True(t, log4shell.KevRansomware)
+	assert.Equal(t, "2021-12-24", log4shell.KevDue)
+	assert.Equal(t, int64(1), log4shell.OpenTicketCount)
+
+	// unknown CVEs are enriched without details
+	unknown, err := queries.GetCVE(ctx, "CVE-2023-0001")
+	require.NoError(t, err)
+	assert.NotNil(t, unknown.Enriched)
+	assert.Nil(t, unknown.CvssScore)
+	assert.False(t, unknown.Kev)
+
+	tickets, err := queries.ListCVETickets(ctx, sqlc.ListCVETicketsParams{Kev: true, Open: true, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, tickets, 1)
+	assert.Equal(t, ticket.ID, tickets[0].Ticket)
+	assert.Equal(t, "CVE-2021-44228", tickets[0].Cve)
+
+	tickets, err = queries.ListCVETickets(ctx, sqlc.ListCVETicketsParams{Kev: false, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, tickets, 1)
+	assert.Equal(t, "CVE-2023-0001", tickets[0].Cve)
+}
@@ -0,0 +1,269 @@
+package cve
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/SecurityBrewery/catalyst/app/settings"
+)
+
+// Details are the enrichment results of a CVE. Fields are nil if the source
+// does not know them.
+type Details struct {
+	Description string
+	Score       *float64
+	Severity    *string
+	Vector      *string
+	Products    []string
+	Published   *time.Time
+}
+
+// merge prefers the description and the CVSS metrics of NVD and merges the
+// affected products.
+func merge(nvd, osv *Details) *Details {
+	details := &Details{
+		Description: cmp.Or(nvd.Description, osv.Description),
+		Score:       nvd.Score,
+		Severity:    nvd.Severity,
+		Vector:      cmp.Or(nvd.Vector, osv.Vector),
+		Published:   cmp.Or(nvd.Published, osv.Published),
+		Products:    []string{},
+	}
+
+	for _, product := range append(nvd.Products, osv.Products...) {
+		if !slices.Contains(details.Products, product) {
+			details.Products = append(details.Products, product)
+		}
+	}
+
+	return details
+}
+
+type nvdResponse struct {
+	Vulnerabilities []struct {
+		CVE struct {
+			Published    string `json:"published"`
+			Descriptions []struct {
+				Lang  string `json:"lang"`
+				Value string `json:"value"`
+			} `json:"descriptions"`
+			Metrics struct {
+				V40 []nvdMetric `json:"cvssMetricV40"`
+				V31 []nvdMetric `json:"cvssMetricV31"`
+				V30 []nvdMetric `json:"cvssMetricV30"`
+				V2  []nvdMetric `json:"cvssMetricV2"`
+			} `json:"metrics"`
+			Configurations []struct {
+				Nodes []struct {
+					CPEMatch []struct {
+						Vulnerable bool   `json:"vulnerable"`
+						Criteria   string `json:"criteria"`
+					} `json:"cpeMatch"`
+				} `json:"nodes"`
+			} `json:"configurations"`
+		} `json:"cve"`
+	} `json:"vulnerabilities"`
+}
+
+type nvdMetric struct {
+	CVSSData struct {
+		BaseScore    float64 `json:"baseScore"`
+		BaseSeverity string  `json:"baseSeverity"`
+		VectorString string  `json:"vectorString"`
+	} `json:"cvssData"`
+	// BaseSeverity is part of the metric instead of the data for CVSS v2.
+	BaseSeverity string `json:"baseSeverity"`
+}
+
+// nvdTimeLayout is the format of timestamps of the NVD API, which are UTC
+// without a time zone.
+const nvdTimeLayout = "2006-01-02T15:04:05.999"
+
+func (e *Enricher) fetchNVD(ctx context.Context, config *settings.CVEEnrichment, id string) (*Details, error) {
+	header := http.Header{}
+	if config.NVDAPIKey != "" {
+		header.Set("apiKey", config.NVDAPIKey)
+	}
+
+	var response nvdResponse
+	if found, err := e.get(ctx, config.NVDURL+"?cveId="+url.QueryEscape(id), header, &response); err != nil || !found {
+		return &Details{}, err
+	}
+
+	details := &Details{}
+
+	for _, vulnerability := range response.Vulnerabilities {
+		cve := vulnerability.CVE
+
+		for _, description := range cve.Descriptions {
+			if description.Lang == "en" {
+				details.Description = description.Value
+
+				break
+			}
+		}
+
+		if published, err := time.Parse(nvdTimeLayout, cve.Published); err == nil {
+			details.Published = &published
+		}
+
+		for _, metrics := range [][]nvdMetric{cve.Metrics.V31, cve.Metrics.V30, cve.Metrics.V40, cve.Metrics.V2} {
+			if len(metrics) == 0 {
+				continue
+			}
+
+			metric := metrics[0]
+			score := metric.CVSSData.BaseScore
+			severity := strings.ToLower(cmp.Or(metric.CVSSData.BaseSeverity, metric.BaseSeverity))
+			vector := metric.CVSSData.VectorString
+
+			details.Score, details.Severity, details.Vector = &score, &severity, &vector
+
+			break
+		}
+
+		for _, configuration := range cve.Configurations {
+			for _, node := range configuration.Nodes {
+				for _, match := range node.CPEMatch {
+					if product := cpeProduct(match.Criteria); match.Vulnerable && product != "" && !slices.Contains(details.Products, product) {
+						details.Products = append(details.Products, product)
+					}
+				}
+			}
+		}
+	}
+
+	return details, nil
+}
+
+// cpeProduct returns the vendor and product of a CPE 2.3 name like
+// cpe:2.3:a:apache:log4j:2.0:*:*:*:*:*:*:*, e.g. "apache log4j".
+func cpeProduct(cpe string) string {
+	parts := strings.Split(cpe, ":")
+	if len(parts) < 5 || parts[0] != "cpe" {
+		return ""
+	}
+
+	return strings.ReplaceAll(parts[3]+" "+parts[4], "_", " ")
+}
+
+type osvResponse struct {
+	Summary   string `json:"summary"`
+	Details   string `json:"details"`
+	Published string `json:"published"`
+	Affected  []struct {
+		Package struct {
+			Ecosystem string `json:"ecosystem"`
+			Name      string `json:"name"`
+		} `json:"package"`
+	} `json:"affected"`
+	Severity []struct {
+		Type  string `json:"type"`
+		Score string `json:"score"`
+	} `json:"severity"`
+}
+
+func (e *Enricher) fetchOSV(ctx context.Context, config *settings.CVEEnrichment, id string) (*Details, error) {
+	var response osvResponse
+	if found, err := e.get(ctx, strings.TrimSuffix(config.OSVURL, "/")+"/"+url.PathEscape(id), nil, &response); err != nil || !found {
+		return &Details{}, err
+	}
+
+	details := &Details{
+		Description: cmp.Or(response.Summary, response.Details),
+	}
+
+	if published, err := time.Parse(time.RFC3339, response.Published); err == nil {
+		details.Published = &published
+	}
+
+	for _, severity := range response.Severity {
+		if strings.HasPrefix(severity.Type, "CVSS_") {
+			vector := severity.Score
+			details.Vector = &vector
+
+			break
+		}
+	}
+
+	for _, affected := range response.Affected {
+		if affected.Package.Name == "" {
+			continue
+		}
+
+		product := fmt.Sprintf("%s (%s)", affected.Package.Name, affected.Package.Ecosystem)
+		if !slices.Contains(details.Products, product) {
+			details.Products = append(details.Products, product)
+		}
+	}
+
+	return details, nil
+}
+
+type kevEntry struct {
+	CVE        string `json:"cveID"`
+	Name       string `json:"vulnerabilityName"`
+	Added      string `json:"dateAdded"`
+	Due        string `json:"dueDate"`
+	Ransomware string `json:"knownRansomwareCampaignUse"`
+}
+
+func (e *Enricher) fetchKEV(ctx context.Context, config *settings.CVEEnrichment) ([]kevEntry, error) {
+	var response struct {
+		Vulnerabilities []kevEntry `json:"vulnerabilities"`
+	}
+
+	found, err := e.get(ctx, config.KEVURL, nil, &response)
+	if err != nil {
+		return nil, err
+	}
+
+	if !found {
+		return nil, fmt.Errorf("known exploited vulnerabilities catalog %s not found", config.KEVURL)
+	}
+
+	return response.Vulnerabilities, nil
+}
+
+// get decodes the JSON response of the URL. A missing resource is not an
+// error, but reported as not found.
+func (e *Enricher) get(ctx context.Context, u string, header http.Header, v any) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return false, err
+	}
+
+	for key, values := range header {
+		req.Header[key] = values
+	}
+
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return false, nil
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxSize)).Decode(v); err != nil {
+		return false, fmt.Errorf("invalid response: %w", err)
+	}
+
+	return true, nil
+}
@@ -84,6 +84,32 @@ func DB(ctx context.Context, dir string) (*sqlc.Queries, func(), error) {
 	}, nil
 }
 
+// ReadOnlyDB connects to the database without writing to it, e.g. to serve
+// a replicated database. Both connections are read-only, so writes fail.
+func ReadOnlyDB(ctx context.Context, dir string) (*sqlc.Queries, func(), error) {
+	filename := filepath.Join(dir, "data.db")
+
+	slog.InfoContext(ctx, "Connecting to database read-only", "path", filename)
+
+	if _, err := os.Stat(filename); err != nil {
+		return nil, nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	read, err := sql.Open(sqliteDriver, fmt.Sprintf("file:%s?mode=ro&_busy_timeout=5000", filename))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	read.SetMaxOpenConns(100)
+	read.SetConnMaxIdleTime(time.Minute)
+
+	return sqlc.New(read, read), func() {
+		if err := read.Close(); err != nil {
+			slog.Error("failed to close read connection", "error", err)
+		}
+	}, nil
+}
+
 func TestDB(t *testing.T, dir string) *sqlc.Queries {
 	queries, cleanup, err := DB(t.Context(), filepath.Join(dir, "data.db"))
 	require.NoError(t, err)
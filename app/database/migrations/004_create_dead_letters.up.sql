@@ -0,0 +1,13 @@
+CREATE TABLE dead_letters
+(
+    id          TEXT PRIMARY KEY DEFAULT ('d' || lower(hex(randomblob(7)))) NOT NULL,
+    webhook     TEXT                                                        NOT NULL,
+    destination TEXT                                                        NOT NULL,
+    payload     JSON                                                        NOT NULL,
+    error       TEXT                                                        NOT NULL,
+    attempts    INTEGER          DEFAULT 1                                  NOT NULL,
+    created     DATETIME         DEFAULT CURRENT_TIMESTAMP                  NOT NULL,
+    updated     DATETIME         DEFAULT CURRENT_TIMESTAMP                  NOT NULL,
+
+    FOREIGN KEY (webhook) REFERENCES webhooks (id) ON DELETE CASCADE
+);
@@ -0,0 +1,10 @@
+CREATE TABLE slack_threads
+(
+    ticket    TEXT PRIMARY KEY                          NOT NULL,
+    channel   TEXT                                      NOT NULL,
+    thread_ts TEXT                                      NOT NULL,
+    created   DATETIME DEFAULT CURRENT_TIMESTAMP        NOT NULL,
+
+    UNIQUE (channel, thread_ts),
+    FOREIGN KEY (ticket) REFERENCES tickets (id) ON DELETE CASCADE
+);
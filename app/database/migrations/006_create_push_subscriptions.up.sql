@@ -0,0 +1,12 @@
+CREATE TABLE push_subscriptions
+(
+    id       TEXT PRIMARY KEY DEFAULT ('p' || lower(hex(randomblob(7)))) NOT NULL,
+    user     TEXT                                                        NOT NULL,
+    endpoint TEXT                                                        NOT NULL UNIQUE,
+    p256dh   TEXT                                                        NOT NULL,
+    auth     TEXT                                                        NOT NULL,
+    created  DATETIME         DEFAULT CURRENT_TIMESTAMP                  NOT NULL,
+    updated  DATETIME         DEFAULT CURRENT_TIMESTAMP                  NOT NULL,
+
+    FOREIGN KEY (user) REFERENCES users (id) ON DELETE CASCADE
+);
@@ -0,0 +1,10 @@
+CREATE TABLE digests
+(
+    user      TEXT PRIMARY KEY                          NOT NULL,
+    frequency TEXT     DEFAULT 'off'                    NOT NULL,
+    last_sent DATETIME,
+    created   DATETIME DEFAULT CURRENT_TIMESTAMP        NOT NULL,
+    updated   DATETIME DEFAULT CURRENT_TIMESTAMP        NOT NULL,
+
+    FOREIGN KEY (user) REFERENCES users (id) ON DELETE CASCADE
+);
@@ -0,0 +1,13 @@
+CREATE TABLE notification_rules
+(
+    id       TEXT PRIMARY KEY DEFAULT ('n' || lower(hex(randomblob(7)))) NOT NULL,
+    name     TEXT                                                        NOT NULL,
+    enabled  BOOLEAN          DEFAULT TRUE                               NOT NULL,
+    filter   JSON             DEFAULT '{}'                               NOT NULL,
+    channel  TEXT                                                        NOT NULL,
+    target   TEXT                                                        NOT NULL,
+    throttle INTEGER          DEFAULT 0                                  NOT NULL,
+    dedup    INTEGER          DEFAULT 0                                  NOT NULL,
+    created  DATETIME         DEFAULT CURRENT_TIMESTAMP                  NOT NULL,
+    updated  DATETIME         DEFAULT CURRENT_TIMESTAMP                  NOT NULL
+);
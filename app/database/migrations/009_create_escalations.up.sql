@@ -0,0 +1,26 @@
+CREATE TABLE escalation_policies
+(
+    id      TEXT PRIMARY KEY DEFAULT ('e' || lower(hex(randomblob(7)))) NOT NULL,
+    name    TEXT                                                        NOT NULL,
+    filter  JSON             DEFAULT '{}'                               NOT NULL,
+    steps   JSON             DEFAULT '[]'                               NOT NULL,
+    repeat  BOOLEAN          DEFAULT FALSE                              NOT NULL,
+    created DATETIME         DEFAULT CURRENT_TIMESTAMP                  NOT NULL,
+    updated DATETIME         DEFAULT CURRENT_TIMESTAMP                  NOT NULL
+);
+
+CREATE TABLE ticket_escalations
+(
+    ticket          TEXT PRIMARY KEY                   NOT NULL,
+    policy          TEXT                               NOT NULL,
+    step            INTEGER  DEFAULT 0                 NOT NULL,
+    next_at         DATETIME,
+    acknowledged_by TEXT,
+    acknowledged_at DATETIME,
+    created         DATETIME DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    updated         DATETIME DEFAULT CURRENT_TIMESTAMP NOT NULL,
+
+    FOREIGN KEY (ticket) REFERENCES tickets (id) ON DELETE CASCADE,
+    FOREIGN KEY (policy) REFERENCES escalation_policies (id) ON DELETE CASCADE,
+    FOREIGN KEY (acknowledged_by) REFERENCES users (id) ON DELETE SET NULL
+);
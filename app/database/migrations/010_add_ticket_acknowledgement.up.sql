@@ -0,0 +1,11 @@
+ALTER TABLE tickets
+    ADD COLUMN acknowledged DATETIME;
+ALTER TABLE tickets
+    ADD COLUMN acknowledged_by TEXT REFERENCES users (id) ON DELETE SET NULL;
+ALTER TABLE tickets
+    ADD COLUMN resolved DATETIME;
+
+-- closed tickets have no resolution time yet, their last update is the best guess
+UPDATE tickets
+SET resolved = updated
+WHERE open = FALSE;
@@ -0,0 +1,22 @@
+ALTER TABLE tickets
+    ADD COLUMN encrypted BOOLEAN DEFAULT FALSE NOT NULL;
+
+CREATE TABLE ticket_keys
+(
+    ticket  TEXT PRIMARY KEY                   NOT NULL,
+    key     TEXT                               NOT NULL,
+    created DATETIME DEFAULT CURRENT_TIMESTAMP NOT NULL,
+
+    FOREIGN KEY (ticket) REFERENCES tickets (id) ON DELETE CASCADE
+);
+
+CREATE TABLE ticket_grants
+(
+    ticket  TEXT                               NOT NULL,
+    user    TEXT                               NOT NULL,
+    created DATETIME DEFAULT CURRENT_TIMESTAMP NOT NULL,
+
+    PRIMARY KEY (ticket, user),
+    FOREIGN KEY (ticket) REFERENCES tickets (id) ON DELETE CASCADE,
+    FOREIGN KEY (user) REFERENCES users (id) ON DELETE CASCADE
+);
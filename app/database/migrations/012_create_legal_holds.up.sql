@@ -0,0 +1,30 @@
+CREATE TABLE legal_holds
+(
+    id         TEXT PRIMARY KEY                   NOT NULL,
+    collection TEXT                               NOT NULL,
+    reason     TEXT                               NOT NULL,
+    created_by TEXT,
+    created    DATETIME DEFAULT CURRENT_TIMESTAMP NOT NULL,
+
+    FOREIGN KEY (created_by) REFERENCES users (id) ON DELETE SET NULL
+);
+
+-- held records cannot be deleted, neither by the API nor by a purge
+CREATE TRIGGER legal_hold_tickets
+    BEFORE DELETE
+    ON tickets
+    WHEN EXISTS (SELECT 1
+                 FROM legal_holds
+                 WHERE legal_holds.id = old.id
+                    OR legal_holds.id IN (SELECT files.id FROM files WHERE files.ticket = old.id))
+BEGIN
+    SELECT RAISE(ABORT, 'ticket is under legal hold');
+END;
+
+CREATE TRIGGER legal_hold_files
+    BEFORE DELETE
+    ON files
+    WHEN EXISTS (SELECT 1 FROM legal_holds WHERE legal_holds.id = old.id)
+BEGIN
+    SELECT RAISE(ABORT, 'file is under legal hold');
+END;
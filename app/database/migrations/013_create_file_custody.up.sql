@@ -0,0 +1,16 @@
+CREATE TABLE file_custody
+(
+    id        TEXT PRIMARY KEY DEFAULT ('v' || lower(hex(randomblob(7)))) NOT NULL,
+    ticket    TEXT                               NOT NULL,
+    file      TEXT                               NOT NULL,
+    file_name TEXT                               NOT NULL,
+    action    TEXT                               NOT NULL,
+    user      TEXT,
+    sha256    TEXT                               NOT NULL,
+    created   DATETIME DEFAULT CURRENT_TIMESTAMP NOT NULL,
+
+    FOREIGN KEY (ticket) REFERENCES tickets (id) ON DELETE CASCADE,
+    FOREIGN KEY (user) REFERENCES users (id) ON DELETE SET NULL
+);
+
+CREATE INDEX idx_file_custody_ticket ON file_custody (ticket, created);
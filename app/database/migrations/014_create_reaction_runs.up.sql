@@ -0,0 +1,15 @@
+CREATE TABLE reaction_runs
+(
+    id          TEXT PRIMARY KEY DEFAULT ('x' || lower(hex(randomblob(7)))) NOT NULL,
+    reaction    TEXT                                                        NOT NULL,
+    success     BOOLEAN                                                     NOT NULL,
+    duration_ms INTEGER                                                     NOT NULL,
+    cpu_ms      INTEGER                                                     NOT NULL,
+    memory_peak INTEGER                                                     NOT NULL,
+    created     DATETIME         DEFAULT CURRENT_TIMESTAMP                  NOT NULL,
+
+    FOREIGN KEY (reaction) REFERENCES reactions (id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_reaction_runs_reaction ON reaction_runs (reaction, created);
+CREATE INDEX idx_reaction_runs_created ON reaction_runs (created);
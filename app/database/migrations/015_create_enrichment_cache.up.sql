@@ -0,0 +1,12 @@
+CREATE TABLE enrichment_cache
+(
+    enricher TEXT                               NOT NULL,
+    value    TEXT                               NOT NULL,
+    result   JSON                               NOT NULL,
+    expires  DATETIME                           NOT NULL,
+    created  DATETIME DEFAULT CURRENT_TIMESTAMP NOT NULL,
+
+    PRIMARY KEY (enricher, value)
+);
+
+CREATE INDEX idx_enrichment_cache_expires ON enrichment_cache (expires);
@@ -0,0 +1,19 @@
+CREATE TABLE feeds
+(
+    name       TEXT PRIMARY KEY                   NOT NULL,
+    source     TEXT                               NOT NULL,
+    indicators INTEGER                            NOT NULL,
+    imported   DATETIME DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+CREATE TABLE feed_indicators
+(
+    feed  TEXT NOT NULL,
+    kind  TEXT NOT NULL,
+    value TEXT NOT NULL,
+
+    PRIMARY KEY (feed, value),
+    FOREIGN KEY (feed) REFERENCES feeds (name) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_feed_indicators_value ON feed_indicators (value);
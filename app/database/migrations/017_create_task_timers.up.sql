@@ -0,0 +1,19 @@
+CREATE TABLE task_timers
+(
+    task             TEXT PRIMARY KEY                   NOT NULL,
+    due              DATETIME                           NOT NULL,
+    action           TEXT                               NOT NULL,
+    escalation_name  TEXT,
+    escalation_owner TEXT,
+    result           TEXT,
+    escalation_task  TEXT,
+    fired            DATETIME,
+    created          DATETIME DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    updated          DATETIME DEFAULT CURRENT_TIMESTAMP NOT NULL,
+
+    FOREIGN KEY (task) REFERENCES tasks (id) ON DELETE CASCADE,
+    FOREIGN KEY (escalation_owner) REFERENCES users (id) ON DELETE SET NULL,
+    FOREIGN KEY (escalation_task) REFERENCES tasks (id) ON DELETE SET NULL
+);
+
+CREATE INDEX idx_task_timers_due ON task_timers (due) WHERE fired IS NULL;
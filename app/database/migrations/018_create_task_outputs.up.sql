@@ -0,0 +1,13 @@
+CREATE TABLE task_outputs
+(
+    task    TEXT PRIMARY KEY                   NOT NULL,
+    ticket  TEXT                               NOT NULL,
+    name    TEXT                               NOT NULL,
+    output  JSON                               NOT NULL,
+    created DATETIME DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    updated DATETIME DEFAULT CURRENT_TIMESTAMP NOT NULL,
+
+    UNIQUE (ticket, name),
+    FOREIGN KEY (task) REFERENCES tasks (id) ON DELETE CASCADE,
+    FOREIGN KEY (ticket) REFERENCES tickets (id) ON DELETE CASCADE
+);
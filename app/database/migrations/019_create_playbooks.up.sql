@@ -0,0 +1,34 @@
+CREATE TABLE playbooks
+(
+    id          TEXT PRIMARY KEY DEFAULT ('o' || lower(hex(randomblob(7)))) NOT NULL,
+    name        TEXT                                                        NOT NULL,
+    description TEXT             DEFAULT ''                                 NOT NULL,
+    inputs      JSON             DEFAULT '[]'                               NOT NULL,
+    tasks       JSON             DEFAULT '[]'                               NOT NULL,
+    created     DATETIME         DEFAULT CURRENT_TIMESTAMP                  NOT NULL,
+    updated     DATETIME         DEFAULT CURRENT_TIMESTAMP                  NOT NULL
+);
+
+CREATE TABLE playbook_runs
+(
+    id       TEXT PRIMARY KEY DEFAULT ('q' || lower(hex(randomblob(7)))) NOT NULL,
+    playbook TEXT                                                        NOT NULL,
+    ticket   TEXT                                                        NOT NULL,
+    inputs   JSON             DEFAULT '{}'                               NOT NULL,
+    created  DATETIME         DEFAULT CURRENT_TIMESTAMP                  NOT NULL,
+
+    FOREIGN KEY (playbook) REFERENCES playbooks (id) ON DELETE CASCADE,
+    FOREIGN KEY (ticket) REFERENCES tickets (id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_playbook_runs_ticket ON playbook_runs (ticket);
+
+CREATE TABLE playbook_run_tasks
+(
+    run  TEXT NOT NULL,
+    task TEXT NOT NULL,
+
+    PRIMARY KEY (run, task),
+    FOREIGN KEY (run) REFERENCES playbook_runs (id) ON DELETE CASCADE,
+    FOREIGN KEY (task) REFERENCES tasks (id) ON DELETE CASCADE
+);
@@ -0,0 +1,27 @@
+CREATE TABLE campaigns
+(
+    id          TEXT PRIMARY KEY DEFAULT ('m' || lower(hex(randomblob(7)))) NOT NULL,
+    name        TEXT                                                        NOT NULL,
+    description TEXT             DEFAULT ''                                 NOT NULL,
+    playbook    TEXT,
+    inputs      JSON             DEFAULT '{}'                               NOT NULL,
+    created     DATETIME         DEFAULT CURRENT_TIMESTAMP                  NOT NULL,
+    updated     DATETIME         DEFAULT CURRENT_TIMESTAMP                  NOT NULL,
+
+    FOREIGN KEY (playbook) REFERENCES playbooks (id) ON DELETE SET NULL
+);
+
+CREATE TABLE campaign_tickets
+(
+    campaign TEXT                               NOT NULL,
+    ticket   TEXT                               NOT NULL,
+    run      TEXT,
+    created  DATETIME DEFAULT CURRENT_TIMESTAMP NOT NULL,
+
+    PRIMARY KEY (campaign, ticket),
+    FOREIGN KEY (campaign) REFERENCES campaigns (id) ON DELETE CASCADE,
+    FOREIGN KEY (ticket) REFERENCES tickets (id) ON DELETE CASCADE,
+    FOREIGN KEY (run) REFERENCES playbook_runs (id) ON DELETE SET NULL
+);
+
+CREATE INDEX idx_campaign_tickets_ticket ON campaign_tickets (ticket);
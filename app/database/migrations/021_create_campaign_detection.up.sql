@@ -0,0 +1,32 @@
+ALTER TABLE campaigns
+    ADD COLUMN feature_kind TEXT;
+ALTER TABLE campaigns
+    ADD COLUMN feature_value TEXT;
+
+CREATE INDEX idx_campaigns_feature ON campaigns (feature_kind, feature_value);
+
+CREATE TABLE ticket_features
+(
+    ticket TEXT NOT NULL,
+    kind   TEXT NOT NULL,
+    value  TEXT NOT NULL,
+
+    PRIMARY KEY (ticket, kind, value),
+    FOREIGN KEY (ticket) REFERENCES tickets (id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_ticket_features_value ON ticket_features (kind, value);
+
+CREATE TABLE campaign_timeline
+(
+    id       TEXT PRIMARY KEY DEFAULT ('f' || lower(hex(randomblob(7)))) NOT NULL,
+    campaign TEXT                                                        NOT NULL,
+    ticket   TEXT,
+    message  TEXT                                                        NOT NULL,
+    time     DATETIME         DEFAULT CURRENT_TIMESTAMP                  NOT NULL,
+
+    FOREIGN KEY (campaign) REFERENCES campaigns (id) ON DELETE CASCADE,
+    FOREIGN KEY (ticket) REFERENCES tickets (id) ON DELETE SET NULL
+);
+
+CREATE INDEX idx_campaign_timeline_campaign ON campaign_timeline (campaign);
@@ -0,0 +1,21 @@
+CREATE TABLE ticket_techniques
+(
+    ticket    TEXT NOT NULL,
+    technique TEXT NOT NULL,
+    tactic    TEXT NOT NULL,
+
+    PRIMARY KEY (ticket, technique, tactic),
+    FOREIGN KEY (ticket) REFERENCES tickets (id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_ticket_techniques_technique ON ticket_techniques (technique);
+
+CREATE TABLE type_techniques
+(
+    type      TEXT NOT NULL,
+    technique TEXT NOT NULL,
+    tactic    TEXT NOT NULL,
+
+    PRIMARY KEY (type, technique, tactic),
+    FOREIGN KEY (type) REFERENCES types (id) ON DELETE CASCADE
+);
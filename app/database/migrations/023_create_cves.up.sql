@@ -0,0 +1,37 @@
+CREATE TABLE cves
+(
+    id            TEXT PRIMARY KEY                   NOT NULL,
+    description   TEXT     DEFAULT ''                NOT NULL,
+    cvss_score    REAL,
+    cvss_severity TEXT,
+    cvss_vector   TEXT,
+    products      JSON     DEFAULT '[]'              NOT NULL,
+    published     DATETIME,
+    enriched      DATETIME,
+    created       DATETIME DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+CREATE INDEX idx_cves_enriched ON cves (enriched);
+
+CREATE TABLE ticket_cves
+(
+    ticket  TEXT                               NOT NULL,
+    cve     TEXT                               NOT NULL,
+    created DATETIME DEFAULT CURRENT_TIMESTAMP NOT NULL,
+
+    PRIMARY KEY (ticket, cve),
+    FOREIGN KEY (ticket) REFERENCES tickets (id) ON DELETE CASCADE,
+    FOREIGN KEY (cve) REFERENCES cves (id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_ticket_cves_cve ON ticket_cves (cve);
+
+CREATE TABLE known_exploited
+(
+    cve        TEXT PRIMARY KEY                   NOT NULL,
+    name       TEXT     DEFAULT ''                NOT NULL,
+    added      TEXT     DEFAULT ''                NOT NULL,
+    due        TEXT     DEFAULT ''                NOT NULL,
+    ransomware BOOLEAN  DEFAULT FALSE             NOT NULL,
+    updated    DATETIME DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
@@ -0,0 +1,32 @@
+CREATE TABLE detection_rules
+(
+    id          TEXT PRIMARY KEY DEFAULT ('s' || lower(hex(randomblob(7)))) NOT NULL,
+    ticket      TEXT                                                        NOT NULL UNIQUE,
+    name        TEXT                                                        NOT NULL UNIQUE,
+    description TEXT             DEFAULT ''                                 NOT NULL,
+    format      TEXT             DEFAULT 'sigma'                            NOT NULL,
+    content     TEXT                                                        NOT NULL,
+    status      TEXT             DEFAULT 'proposed'                         NOT NULL,
+    author      TEXT,
+    reviewer    TEXT,
+    deployed    DATETIME,
+    created     DATETIME         DEFAULT CURRENT_TIMESTAMP                  NOT NULL,
+    updated     DATETIME         DEFAULT CURRENT_TIMESTAMP                  NOT NULL,
+
+    FOREIGN KEY (ticket) REFERENCES tickets (id) ON DELETE CASCADE,
+    FOREIGN KEY (author) REFERENCES users (id) ON DELETE SET NULL,
+    FOREIGN KEY (reviewer) REFERENCES users (id) ON DELETE SET NULL
+);
+
+CREATE TABLE detection_rule_tickets
+(
+    rule    TEXT                               NOT NULL,
+    ticket  TEXT                               NOT NULL,
+    created DATETIME DEFAULT CURRENT_TIMESTAMP NOT NULL,
+
+    PRIMARY KEY (rule, ticket),
+    FOREIGN KEY (rule) REFERENCES detection_rules (id) ON DELETE CASCADE,
+    FOREIGN KEY (ticket) REFERENCES tickets (id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_detection_rule_tickets_ticket ON detection_rule_tickets (ticket);
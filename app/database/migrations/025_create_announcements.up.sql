@@ -0,0 +1,26 @@
+CREATE TABLE announcements
+(
+    id          TEXT PRIMARY KEY DEFAULT ('a' || lower(hex(randomblob(7)))) NOT NULL,
+    title       TEXT                                                        NOT NULL,
+    message     TEXT             DEFAULT ''                                 NOT NULL,
+    severity    TEXT             DEFAULT 'info'                             NOT NULL,
+    require_ack BOOLEAN          DEFAULT FALSE                              NOT NULL,
+    author      TEXT,
+    expires     DATETIME,
+    created     DATETIME         DEFAULT CURRENT_TIMESTAMP                  NOT NULL,
+    updated     DATETIME         DEFAULT CURRENT_TIMESTAMP                  NOT NULL,
+
+    FOREIGN KEY (author) REFERENCES users (id) ON DELETE SET NULL
+);
+
+CREATE TABLE announcement_receipts
+(
+    announcement TEXT     NOT NULL,
+    user         TEXT     NOT NULL,
+    read         DATETIME NOT NULL,
+    acknowledged DATETIME,
+
+    PRIMARY KEY (announcement, user),
+    FOREIGN KEY (announcement) REFERENCES announcements (id) ON DELETE CASCADE,
+    FOREIGN KEY (user) REFERENCES users (id) ON DELETE CASCADE
+);
@@ -0,0 +1,17 @@
+CREATE TABLE ticket_activity
+(
+    id       TEXT PRIMARY KEY DEFAULT ('i' || lower(hex(randomblob(7)))) NOT NULL,
+    ticket   TEXT                                                        NOT NULL,
+    user     TEXT,
+    method   TEXT                                                        NOT NULL,
+    path     TEXT                                                        NOT NULL,
+    body     TEXT,
+    status   INTEGER                                                     NOT NULL,
+    duration INTEGER                                                     NOT NULL,
+    created  DATETIME         DEFAULT CURRENT_TIMESTAMP                  NOT NULL,
+
+    FOREIGN KEY (ticket) REFERENCES tickets (id) ON DELETE CASCADE,
+    FOREIGN KEY (user) REFERENCES users (id) ON DELETE SET NULL
+);
+
+CREATE INDEX idx_ticket_activity_ticket ON ticket_activity (ticket, created);
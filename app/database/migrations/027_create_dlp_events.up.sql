@@ -0,0 +1,11 @@
+CREATE TABLE dlp_events
+(
+    id      TEXT PRIMARY KEY DEFAULT ('z' || lower(hex(randomblob(7)))) NOT NULL,
+    rule    TEXT                                                        NOT NULL,
+    export  TEXT                                                        NOT NULL,
+    ticket  TEXT                                                        NOT NULL,
+    user    TEXT,
+    created DATETIME         DEFAULT CURRENT_TIMESTAMP                  NOT NULL,
+
+    FOREIGN KEY (user) REFERENCES users (id) ON DELETE SET NULL
+);
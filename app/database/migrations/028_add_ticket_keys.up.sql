@@ -0,0 +1,38 @@
+ALTER TABLE types
+    ADD COLUMN key_prefix TEXT;
+ALTER TABLE tickets
+    ADD COLUMN key TEXT;
+
+CREATE UNIQUE INDEX idx_tickets_key ON tickets (key);
+
+-- the counters restart every year, types with the same prefix share one
+CREATE TABLE ticket_key_sequences
+(
+    prefix TEXT    NOT NULL,
+    year   INTEGER NOT NULL,
+    value  INTEGER NOT NULL,
+
+    PRIMARY KEY (prefix, year)
+);
+
+-- keys are allocated in the transaction of the insert, so concurrent
+-- inserts never draw the same number
+CREATE TRIGGER ticket_key
+    AFTER INSERT
+    ON tickets
+    WHEN new.key IS NULL
+        AND (SELECT key_prefix FROM types WHERE types.id = new.type) IS NOT NULL
+BEGIN
+    INSERT INTO ticket_key_sequences (prefix, year, value)
+    VALUES ((SELECT key_prefix FROM types WHERE types.id = new.type), CAST(strftime('%Y', new.created) AS INTEGER), 1)
+    ON CONFLICT (prefix, year) DO UPDATE SET value = value + 1;
+
+    UPDATE tickets
+    SET key = (SELECT ticket_key_sequences.prefix || '-' || ticket_key_sequences.year || '-' ||
+                      printf('%04d', ticket_key_sequences.value)
+               FROM ticket_key_sequences
+                        JOIN types ON types.key_prefix = ticket_key_sequences.prefix
+               WHERE types.id = new.type
+                 AND ticket_key_sequences.year = CAST(strftime('%Y', new.created) AS INTEGER))
+    WHERE id = new.id;
+END;
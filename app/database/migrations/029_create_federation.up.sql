@@ -0,0 +1,31 @@
+CREATE TABLE federation_peers
+(
+    id      TEXT PRIMARY KEY DEFAULT ('j' || lower(hex(randomblob(7)))) NOT NULL,
+    name    TEXT UNIQUE                                                 NOT NULL,
+    url     TEXT UNIQUE                                                 NOT NULL,
+    secret  TEXT                                                        NOT NULL,
+    max_tlp TEXT             DEFAULT 'GREEN'                            NOT NULL,
+    created DATETIME         DEFAULT CURRENT_TIMESTAMP                  NOT NULL,
+    updated DATETIME         DEFAULT CURRENT_TIMESTAMP                  NOT NULL
+);
+
+CREATE TABLE federation_links
+(
+    id            TEXT PRIMARY KEY DEFAULT ('k' || lower(hex(randomblob(7)))) NOT NULL,
+    ticket        TEXT                                                        NOT NULL,
+    peer          TEXT                                                        NOT NULL,
+    remote_ticket TEXT                                                        NOT NULL,
+    remote_key    TEXT,
+    remote_type   TEXT                                                        NOT NULL,
+    remote_name   TEXT                                                        NOT NULL,
+    remote_open   BOOLEAN                                                     NOT NULL,
+    share         BOOLEAN          DEFAULT TRUE                               NOT NULL,
+    created       DATETIME         DEFAULT CURRENT_TIMESTAMP                  NOT NULL,
+    updated       DATETIME         DEFAULT CURRENT_TIMESTAMP                  NOT NULL,
+
+    UNIQUE (ticket, peer, remote_ticket),
+    FOREIGN KEY (ticket) REFERENCES tickets (id) ON DELETE CASCADE,
+    FOREIGN KEY (peer) REFERENCES federation_peers (id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_federation_links_remote ON federation_links (peer, remote_ticket);
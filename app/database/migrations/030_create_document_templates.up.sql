@@ -0,0 +1,8 @@
+CREATE TABLE document_templates
+(
+    format  TEXT PRIMARY KEY                          NOT NULL,
+    name    TEXT                                      NOT NULL,
+    content BLOB                                      NOT NULL,
+    created DATETIME DEFAULT CURRENT_TIMESTAMP        NOT NULL,
+    updated DATETIME DEFAULT CURRENT_TIMESTAMP        NOT NULL
+);
@@ -0,0 +1,22 @@
+CREATE TABLE auto_close_rules
+(
+    id            TEXT PRIMARY KEY DEFAULT ('r' || lower(hex(randomblob(7)))) NOT NULL,
+    name          TEXT                                                        NOT NULL,
+    enabled       BOOLEAN          DEFAULT TRUE                               NOT NULL,
+    filter        JSON             DEFAULT '{}'                               NOT NULL,
+    inactive_days INTEGER                                                     NOT NULL,
+    warning_days  INTEGER          DEFAULT 0                                  NOT NULL,
+    resolution    TEXT                                                        NOT NULL,
+    created       DATETIME         DEFAULT CURRENT_TIMESTAMP                  NOT NULL,
+    updated       DATETIME         DEFAULT CURRENT_TIMESTAMP                  NOT NULL
+);
+
+CREATE TABLE auto_close_warnings
+(
+    ticket TEXT PRIMARY KEY                   NOT NULL,
+    rule   TEXT                               NOT NULL,
+    warned DATETIME DEFAULT CURRENT_TIMESTAMP NOT NULL,
+
+    FOREIGN KEY (ticket) REFERENCES tickets (id) ON DELETE CASCADE,
+    FOREIGN KEY (rule) REFERENCES auto_close_rules (id) ON DELETE CASCADE
+);
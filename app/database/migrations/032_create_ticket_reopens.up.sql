@@ -0,0 +1,21 @@
+ALTER TABLE tickets
+    ADD COLUMN resolved_by TEXT;
+ALTER TABLE tickets
+    ADD COLUMN reopen_count INTEGER DEFAULT 0 NOT NULL;
+
+CREATE TABLE ticket_reopens
+(
+    id          TEXT PRIMARY KEY DEFAULT ('r' || lower(hex(randomblob(7)))) NOT NULL,
+    ticket      TEXT                                                        NOT NULL,
+    reason      TEXT                                                        NOT NULL,
+    resolution  TEXT,
+    closed_by   TEXT,
+    reopened_by TEXT,
+    created     DATETIME         DEFAULT CURRENT_TIMESTAMP                  NOT NULL,
+
+    FOREIGN KEY (ticket) REFERENCES tickets (id) ON DELETE CASCADE,
+    FOREIGN KEY (closed_by) REFERENCES users (id) ON DELETE SET NULL,
+    FOREIGN KEY (reopened_by) REFERENCES users (id) ON DELETE SET NULL
+);
+
+CREATE INDEX idx_ticket_reopens_ticket ON ticket_reopens (ticket, created);
@@ -0,0 +1,20 @@
+-- alerts are raw events of noisy sources, they only become tickets, with a
+-- ticket key and in the ticket metrics, when they are promoted
+CREATE TABLE alerts
+(
+    id          TEXT PRIMARY KEY DEFAULT ('r' || lower(hex(randomblob(7)))) NOT NULL,
+    source      TEXT                                                        NOT NULL,
+    name        TEXT                                                        NOT NULL,
+    description TEXT             DEFAULT ''                                 NOT NULL,
+    severity    TEXT             DEFAULT ''                                 NOT NULL,
+    data        JSON             DEFAULT '{}'                               NOT NULL,
+    status      TEXT             DEFAULT 'new'                              NOT NULL,
+    ticket      TEXT,
+    created     DATETIME         DEFAULT CURRENT_TIMESTAMP                  NOT NULL,
+    updated     DATETIME         DEFAULT CURRENT_TIMESTAMP                  NOT NULL,
+
+    FOREIGN KEY (ticket) REFERENCES tickets (id) ON DELETE SET NULL
+);
+
+CREATE INDEX idx_alerts_status ON alerts (status, created);
+CREATE INDEX idx_alerts_ticket ON alerts (ticket);
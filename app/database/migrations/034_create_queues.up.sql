@@ -0,0 +1,13 @@
+-- queues are named views of the open, unassigned tickets that analysts
+-- claim one after another
+CREATE TABLE queues
+(
+    id          TEXT PRIMARY KEY DEFAULT ('q' || lower(hex(randomblob(7)))) NOT NULL,
+    name        TEXT UNIQUE                                                 NOT NULL,
+    description TEXT             DEFAULT ''                                 NOT NULL,
+    filter      JSON             DEFAULT '{}'                               NOT NULL,
+    created     DATETIME         DEFAULT CURRENT_TIMESTAMP                  NOT NULL,
+    updated     DATETIME         DEFAULT CURRENT_TIMESTAMP                  NOT NULL
+);
+
+CREATE INDEX idx_tickets_unassigned ON tickets (created) WHERE open AND owner IS NULL;
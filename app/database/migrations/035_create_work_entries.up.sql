@@ -0,0 +1,21 @@
+-- work entries record the time analysts spend on tickets, either by a timer
+-- or entered manually. The duration of a running timer is NULL, a user runs
+-- at most one timer per ticket.
+CREATE TABLE work_entries
+(
+    id          TEXT PRIMARY KEY DEFAULT ('w' || lower(hex(randomblob(7)))) NOT NULL,
+    ticket      TEXT                                                        NOT NULL,
+    user        TEXT                                                        NOT NULL,
+    description TEXT             DEFAULT ''                                 NOT NULL,
+    started     DATETIME                                                    NOT NULL,
+    duration    INTEGER,
+    manual      BOOLEAN          DEFAULT FALSE                              NOT NULL,
+    created     DATETIME         DEFAULT CURRENT_TIMESTAMP                  NOT NULL,
+    updated     DATETIME         DEFAULT CURRENT_TIMESTAMP                  NOT NULL,
+
+    FOREIGN KEY (ticket) REFERENCES tickets (id) ON DELETE CASCADE,
+    FOREIGN KEY (user) REFERENCES users (id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX idx_work_entries_running ON work_entries (ticket, user) WHERE duration IS NULL;
+CREATE INDEX idx_work_entries_started ON work_entries (started);
@@ -0,0 +1,20 @@
+-- password history keeps the hashes of the previous passwords of local users,
+-- so that the password policy can prevent their reuse and expire passwords.
+-- Existing passwords count as set at the last update of their user.
+CREATE TABLE password_history
+(
+    id           TEXT PRIMARY KEY DEFAULT ('h' || lower(hex(randomblob(7)))) NOT NULL,
+    user         TEXT                                                        NOT NULL,
+    passwordHash TEXT                                                        NOT NULL,
+    created      DATETIME         DEFAULT CURRENT_TIMESTAMP                  NOT NULL,
+
+    FOREIGN KEY (user) REFERENCES users (id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_password_history_user ON password_history (user, created);
+
+INSERT INTO password_history (user, passwordHash, created)
+SELECT id, passwordHash, updated
+FROM users
+WHERE passwordHash != ''
+  AND id != 'system';
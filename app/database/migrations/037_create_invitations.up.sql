@@ -0,0 +1,22 @@
+-- invitations let admins invite users by email. Only the hash of the token
+-- of the invitation link is stored, an invitation is pending until a user
+-- accepted it, and an email has at most one pending invitation.
+CREATE TABLE invitations
+(
+    id          TEXT PRIMARY KEY DEFAULT ('i' || lower(hex(randomblob(7)))) NOT NULL,
+    email       TEXT                                                        NOT NULL,
+    name        TEXT             DEFAULT ''                                 NOT NULL,
+    groups      JSON             DEFAULT '[]'                               NOT NULL,
+    token_hash  TEXT                                                        NOT NULL,
+    invited_by  TEXT,
+    expires     DATETIME                                                    NOT NULL,
+    accepted_by TEXT,
+    created     DATETIME         DEFAULT CURRENT_TIMESTAMP                  NOT NULL,
+    updated     DATETIME         DEFAULT CURRENT_TIMESTAMP                  NOT NULL,
+
+    FOREIGN KEY (invited_by) REFERENCES users (id) ON DELETE SET NULL,
+    FOREIGN KEY (accepted_by) REFERENCES users (id) ON DELETE SET NULL
+);
+
+CREATE UNIQUE INDEX idx_invitations_token ON invitations (token_hash);
+CREATE UNIQUE INDEX idx_invitations_pending ON invitations (email) WHERE accepted_by IS NULL;
@@ -0,0 +1,25 @@
+-- api usage counts the API requests per day of each client, a user with
+-- their own tokens or an automation acting for the user, to find runaway
+-- scripts. Clients with a quota are limited to a number of requests per day.
+CREATE TABLE api_usage
+(
+    user     TEXT                NOT NULL,
+    client   TEXT    DEFAULT ''  NOT NULL,
+    day      TEXT                NOT NULL,
+    requests INTEGER DEFAULT 0   NOT NULL,
+
+    PRIMARY KEY (user, client, day),
+    FOREIGN KEY (user) REFERENCES users (id) ON DELETE CASCADE
+);
+
+CREATE TABLE api_quotas
+(
+    user        TEXT                               NOT NULL,
+    client      TEXT     DEFAULT ''                NOT NULL,
+    daily_limit INTEGER                            NOT NULL,
+    created     DATETIME DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    updated     DATETIME DEFAULT CURRENT_TIMESTAMP NOT NULL,
+
+    PRIMARY KEY (user, client),
+    FOREIGN KEY (user) REFERENCES users (id) ON DELETE CASCADE
+);
@@ -0,0 +1,16 @@
+-- logs keeps the application log for troubleshooting without access to the
+-- server. The level is the slog level, so that it can be filtered by minimum
+-- level, and the component is the package that logged the record.
+CREATE TABLE logs
+(
+    id        TEXT PRIMARY KEY DEFAULT ('l' || lower(hex(randomblob(7)))) NOT NULL,
+    level     INTEGER                                                     NOT NULL,
+    message   TEXT                                                        NOT NULL,
+    component TEXT             DEFAULT ''                                 NOT NULL,
+    user      TEXT,
+    attrs     JSON             DEFAULT '{}'                               NOT NULL,
+    created   DATETIME         DEFAULT CURRENT_TIMESTAMP                  NOT NULL
+);
+
+CREATE INDEX idx_logs_created ON logs (created);
+CREATE INDEX idx_logs_component ON logs (component, created);
@@ -0,0 +1,18 @@
+INSERT OR IGNORE INTO types (id, singular, plural, icon, schema)
+VALUES ('platform', 'Platform Error', 'Platform Errors', 'ServerCrash', '{"type": "object", "properties": { "component": { "title": "Component", "type": "string" }, "occurrences": { "title": "Occurrences", "type": "integer" }, "last_seen": { "title": "Last seen", "type": "string" }}}');
+
+-- platform_errors maps the fingerprints of critical errors of Catalyst itself
+-- to the ticket that was opened for them, so that an error that repeats
+-- updates its open ticket instead of opening a new one
+CREATE TABLE platform_errors
+(
+    fingerprint TEXT PRIMARY KEY                   NOT NULL,
+    ticket      TEXT                               NOT NULL,
+    occurrences INTEGER  DEFAULT 1                 NOT NULL,
+    first_seen  DATETIME DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    last_seen   DATETIME DEFAULT CURRENT_TIMESTAMP NOT NULL,
+
+    FOREIGN KEY (ticket) REFERENCES tickets (id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_platform_errors_ticket ON platform_errors (ticket);
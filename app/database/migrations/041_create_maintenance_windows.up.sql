@@ -0,0 +1,18 @@
+-- maintenance windows silence planned changes: alerts of matching sources
+-- are tagged with the window, and notifications, escalations and task
+-- timers of matching tickets are paused while the window is active
+CREATE TABLE maintenance_windows
+(
+    id      TEXT PRIMARY KEY DEFAULT ('r' || lower(hex(randomblob(7)))) NOT NULL,
+    name    TEXT                                                        NOT NULL,
+    filter  JSON             DEFAULT '{}'                               NOT NULL,
+    starts  DATETIME                                                    NOT NULL,
+    ends    DATETIME                                                    NOT NULL,
+    created DATETIME         DEFAULT CURRENT_TIMESTAMP                  NOT NULL,
+    updated DATETIME         DEFAULT CURRENT_TIMESTAMP                  NOT NULL
+);
+
+CREATE INDEX idx_maintenance_windows_ends ON maintenance_windows (ends);
+
+ALTER TABLE alerts
+    ADD COLUMN maintenance TEXT REFERENCES maintenance_windows (id) ON DELETE SET NULL;
@@ -0,0 +1,11 @@
+-- escalation policies pause the escalation of a ticket while its status is
+-- one of the pause states, e.g. while waiting for the customer; the time a
+-- ticket was paused is excluded from the time to resolve
+ALTER TABLE escalation_policies
+    ADD COLUMN pause_states JSON DEFAULT '[]' NOT NULL;
+
+ALTER TABLE ticket_escalations
+    ADD COLUMN paused_since DATETIME;
+
+ALTER TABLE ticket_escalations
+    ADD COLUMN paused INTEGER DEFAULT 0 NOT NULL;
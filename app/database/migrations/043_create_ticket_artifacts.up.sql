@@ -0,0 +1,15 @@
+-- ticket_artifacts are the indicators of a ticket, like domains, IP addresses
+-- and hashes, in their canonical form, so that different spellings of the
+-- same indicator are stored once
+CREATE TABLE ticket_artifacts
+(
+    ticket  TEXT                               NOT NULL,
+    kind    TEXT                               NOT NULL,
+    value   TEXT                               NOT NULL,
+    created DATETIME DEFAULT CURRENT_TIMESTAMP NOT NULL,
+
+    PRIMARY KEY (ticket, kind, value),
+    FOREIGN KEY (ticket) REFERENCES tickets (id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_ticket_artifacts_value ON ticket_artifacts (kind, value);
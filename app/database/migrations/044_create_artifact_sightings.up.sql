@@ -0,0 +1,18 @@
+-- artifact_sightings record every time an indicator was seen, when an alert
+-- carrying it was ingested, when the alert was correlated into a ticket or
+-- when it was added to a ticket by hand, to tell when it was first and last
+-- seen
+CREATE TABLE artifact_sightings
+(
+    id        TEXT PRIMARY KEY DEFAULT ('s' || lower(hex(randomblob(7)))) NOT NULL,
+    kind      TEXT                                                        NOT NULL,
+    value     TEXT                                                        NOT NULL,
+    ticket    TEXT,
+    source    TEXT                                                        NOT NULL,
+    reference TEXT     DEFAULT ''                                         NOT NULL,
+    seen      DATETIME DEFAULT CURRENT_TIMESTAMP                          NOT NULL,
+
+    FOREIGN KEY (ticket) REFERENCES tickets (id) ON DELETE SET NULL
+);
+
+CREATE INDEX idx_artifact_sightings_value ON artifact_sightings (kind, value, seen);
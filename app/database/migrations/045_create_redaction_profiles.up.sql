@@ -0,0 +1,14 @@
+-- redaction_profiles hide parts of a ticket when it is exported for an
+-- audience, like customers, management or legal: custom fields, file names
+-- and the identities of the people that worked on the ticket
+CREATE TABLE redaction_profiles
+(
+    id              TEXT PRIMARY KEY DEFAULT ('p' || lower(hex(randomblob(7)))) NOT NULL,
+    name            TEXT UNIQUE                                                NOT NULL,
+    audience        TEXT                                                       NOT NULL,
+    fields          JSON     DEFAULT '[]'                                      NOT NULL,
+    hide_files      BOOLEAN  DEFAULT FALSE                                     NOT NULL,
+    hide_identities BOOLEAN  DEFAULT FALSE                                     NOT NULL,
+    created         DATETIME DEFAULT CURRENT_TIMESTAMP                         NOT NULL,
+    updated         DATETIME DEFAULT CURRENT_TIMESTAMP                         NOT NULL
+);
@@ -0,0 +1,18 @@
+-- request_journal keeps the requests of webhook triggers that opted in, so
+-- that they can be replayed once a broken reaction is fixed
+CREATE TABLE request_journal
+(
+    id        TEXT PRIMARY KEY DEFAULT ('j' || lower(hex(randomblob(7)))) NOT NULL,
+    reaction  TEXT                                                        NOT NULL,
+    request   JSON                                                        NOT NULL,
+    size      INTEGER                                                     NOT NULL,
+    truncated BOOLEAN  DEFAULT FALSE                                      NOT NULL,
+    success   BOOLEAN                                                     NOT NULL,
+    error     TEXT     DEFAULT ''                                         NOT NULL,
+    replayed  DATETIME,
+    created   DATETIME DEFAULT CURRENT_TIMESTAMP                          NOT NULL,
+
+    FOREIGN KEY (reaction) REFERENCES reactions (id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_request_journal_reaction ON request_journal (reaction, created);
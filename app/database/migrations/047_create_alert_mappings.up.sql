@@ -0,0 +1,14 @@
+-- alert_mappings turn the events of a source into alerts, they are created
+-- from scratch or cloned from a template that Catalyst ships, whose version
+-- is kept to offer upgrades
+CREATE TABLE alert_mappings
+(
+    id               TEXT PRIMARY KEY DEFAULT ('m' || lower(hex(randomblob(7)))) NOT NULL,
+    name             TEXT UNIQUE                                                NOT NULL,
+    source           TEXT                                                       NOT NULL,
+    template         TEXT     DEFAULT ''                                        NOT NULL,
+    template_version INTEGER  DEFAULT 0                                         NOT NULL,
+    fields           JSON     DEFAULT '{}'                                      NOT NULL,
+    created          DATETIME DEFAULT CURRENT_TIMESTAMP                         NOT NULL,
+    updated          DATETIME DEFAULT CURRENT_TIMESTAMP                         NOT NULL
+);
@@ -0,0 +1,3 @@
+-- the custom fields of the schema that the stats aggregate over time
+ALTER TABLE types
+    ADD COLUMN metrics JSON NOT NULL DEFAULT '[]';
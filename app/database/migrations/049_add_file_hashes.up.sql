@@ -0,0 +1,12 @@
+-- the hashes of the content, for lookups of indicators, files uploaded
+-- before are hashed by 050_hash_files
+ALTER TABLE files
+    ADD COLUMN md5 TEXT NOT NULL DEFAULT '';
+ALTER TABLE files
+    ADD COLUMN sha1 TEXT NOT NULL DEFAULT '';
+ALTER TABLE files
+    ADD COLUMN sha256 TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX idx_files_md5 ON files (md5);
+CREATE INDEX idx_files_sha1 ON files (sha1);
+CREATE INDEX idx_files_sha256 ON files (sha256);
@@ -12,6 +12,11 @@ FROM tickets
          LEFT JOIN types ON types.id = tickets.type
 WHERE tickets.id = @id;
 
+-- name: TicketIDByKey :one
+SELECT id
+FROM tickets
+WHERE key = @key;
+
 -- name: ListTickets :many
 SELECT tickets.*,
        users.name       as owner_name,
@@ -21,9 +26,828 @@ SELECT tickets.*,
 FROM tickets
          LEFT JOIN users ON users.id = tickets.owner
          LEFT JOIN types ON types.id = tickets.type
+WHERE sqlc.narg('acknowledged') IS NULL
+   OR (tickets.acknowledged IS NOT NULL) = sqlc.narg('acknowledged')
 ORDER BY tickets.created DESC
 LIMIT @limit OFFSET @offset;
 
+-- name: CountTicketsPerHour :many
+SELECT type,
+       CAST(coalesce(json_extract(state, '$.source'), '') AS TEXT) AS source,
+       CAST(strftime('%Y-%m-%d %H:00:00', created) AS TEXT)      AS hour,
+       COUNT(*)                                                  AS count
+FROM tickets
+WHERE datetime(created) >= datetime(CAST(@since AS TEXT))
+GROUP BY 1, 2, 3
+ORDER BY 3;
+
+-- name: GetResponseTimes :one
+SELECT COUNT(*)                                                                                                 AS tickets,
+       COUNT(tickets.acknowledged)                                                                              AS acknowledged,
+       COUNT(tickets.resolved)                                                                                  AS resolved,
+       CAST(coalesce(AVG(unixepoch(tickets.acknowledged) - unixepoch(tickets.created)), 0) AS REAL)             AS mtta,
+       CAST(coalesce(AVG(unixepoch(tickets.resolved) - unixepoch(tickets.created)
+           - coalesce(ticket_escalations.paused, 0)), 0) AS REAL)                                               AS mttr,
+       COUNT(CASE WHEN tickets.reopen_count > 0 THEN 1 END)                                                     AS reopened,
+       CAST(coalesce(SUM(tickets.reopen_count), 0) AS INTEGER)                                                  AS reopens
+FROM tickets
+         LEFT JOIN ticket_escalations ON ticket_escalations.ticket = tickets.id
+WHERE (sqlc.narg('type') IS NULL OR tickets.type = sqlc.narg('type'))
+  AND datetime(tickets.created) >= datetime(CAST(@since AS TEXT));
+
+-- name: ListTicketMetricValues :many
+SELECT CAST(CASE CAST(@interval AS TEXT)
+                WHEN 'day' THEN date(tickets.created)
+                WHEN 'month' THEN date(tickets.created, 'start of month')
+                ELSE date(tickets.created, 'weekday 0', '-6 days') END AS TEXT)       AS bucket,
+       CAST(coalesce(json_extract(tickets.state, CAST(@path AS TEXT)), '') AS TEXT) AS value,
+       COUNT(*)                                                                     AS tickets
+FROM tickets
+WHERE tickets.type = @type
+  AND datetime(tickets.created) >= datetime(CAST(@since AS TEXT))
+  AND datetime(tickets.created) < datetime(CAST(@until AS TEXT))
+GROUP BY bucket, value
+ORDER BY bucket, value;
+
+-- name: ListTicketMetricNumbers :many
+SELECT CAST(CASE CAST(@interval AS TEXT)
+                WHEN 'day' THEN date(tickets.created)
+                WHEN 'month' THEN date(tickets.created, 'start of month')
+                ELSE date(tickets.created, 'weekday 0', '-6 days') END AS TEXT)                    AS bucket,
+       COUNT(*)                                                                                  AS tickets,
+       COUNT(CASE WHEN json_type(tickets.state, CAST(@path AS TEXT)) IN ('integer', 'real') THEN 1 END) AS count,
+       CAST(coalesce(SUM(CASE WHEN json_type(tickets.state, CAST(@path AS TEXT)) IN ('integer', 'real')
+                                  THEN json_extract(tickets.state, CAST(@path AS TEXT)) END), 0) AS REAL) AS sum,
+       CAST(coalesce(MIN(CASE WHEN json_type(tickets.state, CAST(@path AS TEXT)) IN ('integer', 'real')
+                                  THEN json_extract(tickets.state, CAST(@path AS TEXT)) END), 0) AS REAL) AS min,
+       CAST(coalesce(MAX(CASE WHEN json_type(tickets.state, CAST(@path AS TEXT)) IN ('integer', 'real')
+                                  THEN json_extract(tickets.state, CAST(@path AS TEXT)) END), 0) AS REAL) AS max
+FROM tickets
+WHERE tickets.type = @type
+  AND datetime(tickets.created) >= datetime(CAST(@since AS TEXT))
+  AND datetime(tickets.created) < datetime(CAST(@until AS TEXT))
+GROUP BY bucket
+ORDER BY bucket;
+
+------------------------------------------------------------------
+
+-- name: ListTicketReopens :many
+SELECT ticket_reopens.*, closer.name AS closed_by_name, reopener.name AS reopened_by_name
+FROM ticket_reopens
+         LEFT JOIN users AS closer ON closer.id = ticket_reopens.closed_by
+         LEFT JOIN users AS reopener ON reopener.id = ticket_reopens.reopened_by
+WHERE ticket_reopens.ticket = @ticket
+ORDER BY ticket_reopens.created;
+
+-- name: GetTicketKey :one
+SELECT key
+FROM ticket_keys
+WHERE ticket = @ticket;
+
+-- AnyTicketKey returns one of the wrapped case keys, to check that the
+-- master key matches them.
+-- name: AnyTicketKey :one
+SELECT key
+FROM ticket_keys
+LIMIT 1;
+
+-- name: ListTicketGrants :many
+SELECT ticket_grants.*, users.username, users.name AS user_name
+FROM ticket_grants
+         JOIN users ON users.id = ticket_grants.user
+WHERE ticket_grants.ticket = @ticket
+ORDER BY ticket_grants.created;
+
+-- name: HasTicketGrant :one
+SELECT EXISTS (SELECT 1
+               FROM ticket_grants
+               WHERE ticket = @ticket
+                 AND user = @user);
+
+------------------------------------------------------------------
+
+-- name: GetLegalHold :one
+SELECT *
+FROM legal_holds
+WHERE id = @id;
+
+-- name: GetTicketLegalHold :one
+SELECT *
+FROM legal_holds
+WHERE legal_holds.id = @ticket
+   OR legal_holds.id IN (SELECT files.id FROM files WHERE files.ticket = @ticket)
+ORDER BY legal_holds.created
+LIMIT 1;
+
+-- name: ListLegalHolds :many
+SELECT legal_holds.*,
+       coalesce(tickets.name, files.name, '') AS name,
+       coalesce(tickets.id, files.ticket, '') AS ticket,
+       users.name                             AS created_by_name,
+       COUNT(*) OVER ()                       as total_count
+FROM legal_holds
+         LEFT JOIN tickets ON legal_holds.collection = 'tickets' AND tickets.id = legal_holds.id
+         LEFT JOIN files ON legal_holds.collection = 'files' AND files.id = legal_holds.id
+         LEFT JOIN users ON users.id = legal_holds.created_by
+ORDER BY legal_holds.created DESC
+LIMIT @limit OFFSET @offset;
+
+------------------------------------------------------------------
+
+-- name: ListCustodyEvents :many
+SELECT file_custody.*, users.name AS user_name, users.username
+FROM file_custody
+         LEFT JOIN users ON users.id = file_custody.user
+WHERE file_custody.ticket = @ticket
+ORDER BY file_custody.created, file_custody.rowid;
+
+------------------------------------------------------------------
+
+-- name: GetEnrichment :one
+SELECT *
+FROM enrichment_cache
+WHERE enricher = @enricher
+  AND value = @value
+  AND expires > @now;
+
+-- name: ListEnrichments :many
+SELECT *
+FROM enrichment_cache
+WHERE value = @value
+  AND expires > @now
+ORDER BY enricher;
+
+------------------------------------------------------------------
+
+-- name: GetPlaybook :one
+SELECT *
+FROM playbooks
+WHERE id = @id;
+
+-- name: ListPlaybooks :many
+SELECT playbooks.*, COUNT(*) OVER () as total_count
+FROM playbooks
+ORDER BY name
+LIMIT @limit OFFSET @offset;
+
+-- name: ListPlaybookRuns :many
+SELECT playbook_runs.*, playbooks.name AS playbook_name
+FROM playbook_runs
+         JOIN playbooks ON playbooks.id = playbook_runs.playbook
+WHERE playbook_runs.ticket = @ticket
+ORDER BY playbook_runs.created;
+
+-- name: ListPlaybookRunTasks :many
+SELECT tasks.*
+FROM playbook_run_tasks
+         JOIN tasks ON tasks.id = playbook_run_tasks.task
+WHERE playbook_run_tasks.run = @run
+ORDER BY tasks.created;
+
+-- name: IsPlaybookTask :one
+SELECT EXISTS (SELECT 1
+               FROM playbook_run_tasks
+                        JOIN playbook_runs ON playbook_runs.id = playbook_run_tasks.run
+               WHERE playbook_runs.ticket = @ticket
+                 AND playbook_run_tasks.task = @task) AS is_playbook_task;
+
+------------------------------------------------------------------
+
+-- name: GetCampaign :one
+SELECT campaigns.*,
+       (SELECT COUNT(*)
+        FROM campaign_tickets
+        WHERE campaign_tickets.campaign = campaigns.id)            AS ticket_count,
+       (SELECT COUNT(*)
+        FROM campaign_tickets
+                 JOIN tickets ON tickets.id = campaign_tickets.ticket
+        WHERE campaign_tickets.campaign = campaigns.id
+          AND tickets.open)                                        AS open_ticket_count,
+       (SELECT COUNT(*)
+        FROM campaign_tickets
+                 JOIN playbook_run_tasks ON playbook_run_tasks.run = campaign_tickets.run
+        WHERE campaign_tickets.campaign = campaigns.id)            AS task_count,
+       (SELECT COUNT(*)
+        FROM campaign_tickets
+                 JOIN playbook_run_tasks ON playbook_run_tasks.run = campaign_tickets.run
+                 JOIN tasks ON tasks.id = playbook_run_tasks.task
+        WHERE campaign_tickets.campaign = campaigns.id
+          AND tasks.open)                                          AS open_task_count
+FROM campaigns
+WHERE campaigns.id = @id;
+
+-- name: ListCampaigns :many
+SELECT campaigns.*,
+       (SELECT COUNT(*)
+        FROM campaign_tickets
+        WHERE campaign_tickets.campaign = campaigns.id)            AS ticket_count,
+       (SELECT COUNT(*)
+        FROM campaign_tickets
+                 JOIN tickets ON tickets.id = campaign_tickets.ticket
+        WHERE campaign_tickets.campaign = campaigns.id
+          AND tickets.open)                                        AS open_ticket_count,
+       (SELECT COUNT(*)
+        FROM campaign_tickets
+                 JOIN playbook_run_tasks ON playbook_run_tasks.run = campaign_tickets.run
+        WHERE campaign_tickets.campaign = campaigns.id)            AS task_count,
+       (SELECT COUNT(*)
+        FROM campaign_tickets
+                 JOIN playbook_run_tasks ON playbook_run_tasks.run = campaign_tickets.run
+                 JOIN tasks ON tasks.id = playbook_run_tasks.task
+        WHERE campaign_tickets.campaign = campaigns.id
+          AND tasks.open)                                          AS open_task_count,
+       COUNT(*) OVER () as total_count
+FROM campaigns
+ORDER BY created DESC
+LIMIT @limit OFFSET @offset;
+
+-- name: GetDetectedCampaign :one
+SELECT *
+FROM campaigns
+WHERE feature_kind = @kind
+  AND feature_value = @value
+  AND datetime(updated) >= datetime(CAST(@since AS TEXT))
+ORDER BY updated DESC
+LIMIT 1;
+
+-- name: ListTicketCampaigns :many
+SELECT campaigns.*,
+       (SELECT COUNT(*)
+        FROM campaign_tickets
+        WHERE campaign_tickets.campaign = campaigns.id)            AS ticket_count,
+       (SELECT COUNT(*)
+        FROM campaign_tickets
+                 JOIN tickets ON tickets.id = campaign_tickets.ticket
+        WHERE campaign_tickets.campaign = campaigns.id
+          AND tickets.open)                                        AS open_ticket_count,
+       (SELECT COUNT(*)
+        FROM campaign_tickets
+                 JOIN playbook_run_tasks ON playbook_run_tasks.run = campaign_tickets.run
+        WHERE campaign_tickets.campaign = campaigns.id)            AS task_count,
+       (SELECT COUNT(*)
+        FROM campaign_tickets
+                 JOIN playbook_run_tasks ON playbook_run_tasks.run = campaign_tickets.run
+                 JOIN tasks ON tasks.id = playbook_run_tasks.task
+        WHERE campaign_tickets.campaign = campaigns.id
+          AND tasks.open)                                          AS open_task_count
+FROM campaigns
+         JOIN campaign_tickets AS membership ON membership.campaign = campaigns.id
+WHERE membership.ticket = @ticket
+ORDER BY campaigns.created DESC;
+
+-- name: ListCampaignTimeline :many
+SELECT *
+FROM campaign_timeline
+WHERE campaign = @campaign
+ORDER BY time, rowid;
+
+-- name: ListTicketsWithFeature :many
+SELECT ticket_features.ticket
+FROM ticket_features
+         JOIN tickets ON tickets.id = ticket_features.ticket
+WHERE ticket_features.kind = @kind
+  AND ticket_features.value = @value
+  AND datetime(tickets.created) >= datetime(CAST(@since AS TEXT))
+ORDER BY tickets.created;
+
+-- name: GetCampaignTicket :one
+SELECT *
+FROM campaign_tickets
+WHERE campaign = @campaign
+  AND ticket = @ticket;
+
+-- name: ListCampaignTickets :many
+SELECT campaign_tickets.*,
+       tickets.name AS ticket_name,
+       tickets.open AS ticket_open,
+       (SELECT COUNT(*)
+        FROM playbook_run_tasks
+        WHERE playbook_run_tasks.run = campaign_tickets.run) AS task_count,
+       (SELECT COUNT(*)
+        FROM playbook_run_tasks
+                 JOIN tasks ON tasks.id = playbook_run_tasks.task
+        WHERE playbook_run_tasks.run = campaign_tickets.run
+          AND tasks.open)                                   AS open_task_count
+FROM campaign_tickets
+         JOIN tickets ON tickets.id = campaign_tickets.ticket
+WHERE campaign_tickets.campaign = @campaign
+ORDER BY campaign_tickets.created;
+
+------------------------------------------------------------------
+
+-- name: ListTicketTechniques :many
+SELECT *
+FROM ticket_techniques
+WHERE ticket = @ticket
+ORDER BY tactic, technique;
+
+-- name: ListTypeTechniques :many
+SELECT *
+FROM type_techniques
+WHERE type = @type
+ORDER BY tactic, technique;
+
+-- name: ListTechniqueTickets :many
+SELECT ticket_techniques.*
+FROM ticket_techniques
+         JOIN tickets ON tickets.id = ticket_techniques.ticket
+WHERE (sqlc.narg('type') IS NULL OR tickets.type = sqlc.narg('type'))
+  AND datetime(tickets.created) >= datetime(CAST(@since AS TEXT));
+
+------------------------------------------------------------------
+
+-- name: GetCVE :one
+SELECT cves.*,
+       known_exploited.cve IS NOT NULL                    AS kev,
+       coalesce(known_exploited.added, '')                AS kev_added,
+       coalesce(known_exploited.due, '')                  AS kev_due,
+       coalesce(known_exploited.ransomware, FALSE)        AS kev_ransomware,
+       (SELECT COUNT(*)
+        FROM ticket_cves
+        WHERE ticket_cves.cve = cves.id)                  AS ticket_count,
+       (SELECT COUNT(*)
+        FROM ticket_cves
+                 JOIN tickets ON tickets.id = ticket_cves.ticket
+        WHERE ticket_cves.cve = cves.id
+          AND tickets.open)                               AS open_ticket_count
+FROM cves
+         LEFT JOIN known_exploited ON known_exploited.cve = cves.id
+WHERE cves.id = @id;
+
+-- name: ListCVEs :many
+SELECT cves.*,
+       known_exploited.cve IS NOT NULL                    AS kev,
+       coalesce(known_exploited.added, '')                AS kev_added,
+       coalesce(known_exploited.due, '')                  AS kev_due,
+       coalesce(known_exploited.ransomware, FALSE)        AS kev_ransomware,
+       (SELECT COUNT(*)
+        FROM ticket_cves
+        WHERE ticket_cves.cve = cves.id)                  AS ticket_count,
+       (SELECT COUNT(*)
+        FROM ticket_cves
+                 JOIN tickets ON tickets.id = ticket_cves.ticket
+        WHERE ticket_cves.cve = cves.id
+          AND tickets.open)                               AS open_ticket_count,
+       COUNT(*) OVER ()                                   AS total_count
+FROM cves
+         LEFT JOIN known_exploited ON known_exploited.cve = cves.id
+WHERE (sqlc.narg('kev') IS NULL OR (known_exploited.cve IS NOT NULL) = sqlc.narg('kev'))
+  AND (sqlc.narg('min_score') IS NULL OR cves.cvss_score >= sqlc.narg('min_score'))
+ORDER BY cves.cvss_score IS NULL, cves.cvss_score DESC, cves.id DESC
+LIMIT @limit OFFSET @offset;
+
+-- name: ListTicketCVEs :many
+SELECT cves.*,
+       known_exploited.cve IS NOT NULL                    AS kev,
+       coalesce(known_exploited.added, '')                AS kev_added,
+       coalesce(known_exploited.due, '')                  AS kev_due,
+       coalesce(known_exploited.ransomware, FALSE)        AS kev_ransomware,
+       (SELECT COUNT(*)
+        FROM ticket_cves
+        WHERE ticket_cves.cve = cves.id)                  AS ticket_count,
+       (SELECT COUNT(*)
+        FROM ticket_cves
+                 JOIN tickets ON tickets.id = ticket_cves.ticket
+        WHERE ticket_cves.cve = cves.id
+          AND tickets.open)                               AS open_ticket_count
+FROM ticket_cves
+         JOIN cves ON cves.id = ticket_cves.cve
+         LEFT JOIN known_exploited ON known_exploited.cve = cves.id
+WHERE ticket_cves.ticket = @ticket
+ORDER BY cves.id;
+
+-- name: ListCVETickets :many
+SELECT ticket_cves.ticket,
+       tickets.name                        AS ticket_name,
+       tickets.type                        AS ticket_type,
+       tickets.open                        AS ticket_open,
+       ticket_cves.cve,
+       cves.cvss_score,
+       cves.cvss_severity,
+       known_exploited.cve IS NOT NULL     AS kev,
+       ticket_cves.created,
+       COUNT(*) OVER ()                    AS total_count
+FROM ticket_cves
+         JOIN tickets ON tickets.id = ticket_cves.ticket
+         JOIN cves ON cves.id = ticket_cves.cve
+         LEFT JOIN known_exploited ON known_exploited.cve = cves.id
+WHERE (sqlc.narg('cve') IS NULL OR ticket_cves.cve = sqlc.narg('cve'))
+  AND (sqlc.narg('open') IS NULL OR tickets.open = sqlc.narg('open'))
+  AND (sqlc.narg('kev') IS NULL OR (known_exploited.cve IS NOT NULL) = sqlc.narg('kev'))
+  AND (sqlc.narg('min_score') IS NULL OR cves.cvss_score >= sqlc.narg('min_score'))
+ORDER BY kev DESC, cves.cvss_score IS NULL, cves.cvss_score DESC, tickets.created DESC
+LIMIT @limit OFFSET @offset;
+
+-- name: ListCVEsToEnrich :many
+SELECT id
+FROM cves
+WHERE enriched IS NULL
+   OR enriched < @before
+ORDER BY enriched IS NOT NULL, enriched
+LIMIT @limit;
+
+------------------------------------------------------------------
+
+-- name: GetDetectionRule :one
+SELECT detection_rules.*,
+       (SELECT COUNT(*)
+        FROM detection_rule_tickets
+        WHERE detection_rule_tickets.rule = detection_rules.id)   AS ticket_count,
+       (SELECT COUNT(*)
+        FROM detection_rule_tickets
+                 JOIN tickets ON tickets.id = detection_rule_tickets.ticket
+        WHERE detection_rule_tickets.rule = detection_rules.id
+          AND tickets.open)                                       AS open_ticket_count
+FROM detection_rules
+WHERE detection_rules.id = @id;
+
+-- name: FindDetectionRule :one
+SELECT *
+FROM detection_rules
+WHERE id = @rule
+   OR name = @rule
+LIMIT 1;
+
+-- name: ListDetectionRules :many
+SELECT detection_rules.*,
+       (SELECT COUNT(*)
+        FROM detection_rule_tickets
+        WHERE detection_rule_tickets.rule = detection_rules.id)   AS ticket_count,
+       (SELECT COUNT(*)
+        FROM detection_rule_tickets
+                 JOIN tickets ON tickets.id = detection_rule_tickets.ticket
+        WHERE detection_rule_tickets.rule = detection_rules.id
+          AND tickets.open)                                       AS open_ticket_count,
+       COUNT(*) OVER ()                                           AS total_count
+FROM detection_rules
+WHERE (sqlc.narg('status') IS NULL OR detection_rules.status = sqlc.narg('status'))
+ORDER BY detection_rules.updated DESC
+LIMIT @limit OFFSET @offset;
+
+-- name: ListTicketDetectionRules :many
+SELECT detection_rules.*
+FROM detection_rule_tickets
+         JOIN detection_rules ON detection_rules.id = detection_rule_tickets.rule
+WHERE detection_rule_tickets.ticket = @ticket
+ORDER BY detection_rules.name;
+
+-- name: ListDetectionRuleTickets :many
+SELECT tickets.id, tickets.name, tickets.open, tickets.resolution, tickets.created, tickets.resolved
+FROM detection_rule_tickets
+         JOIN tickets ON tickets.id = detection_rule_tickets.ticket
+WHERE detection_rule_tickets.rule = @rule
+ORDER BY tickets.created DESC;
+
+-- name: ListDetectionRuleDispositions :many
+SELECT coalesce(tickets.resolution, '') AS resolution,
+       COUNT(*)                         AS count
+FROM detection_rule_tickets
+         JOIN tickets ON tickets.id = detection_rule_tickets.ticket
+WHERE detection_rule_tickets.rule = @rule
+  AND NOT tickets.open
+GROUP BY coalesce(tickets.resolution, '')
+ORDER BY count DESC, resolution;
+
+------------------------------------------------------------------
+
+-- name: GetAnnouncement :one
+SELECT announcements.*,
+       announcement_receipts.read,
+       announcement_receipts.acknowledged,
+       (SELECT COUNT(*)
+        FROM announcement_receipts AS receipts
+        WHERE receipts.announcement = announcements.id
+          AND receipts.acknowledged IS NOT NULL) AS acknowledged_count
+FROM announcements
+         LEFT JOIN announcement_receipts
+                   ON announcement_receipts.announcement = announcements.id AND announcement_receipts.user = @user
+WHERE announcements.id = @id;
+
+-- name: ListAnnouncements :many
+SELECT announcements.*,
+       announcement_receipts.read,
+       announcement_receipts.acknowledged,
+       (SELECT COUNT(*)
+        FROM announcement_receipts AS receipts
+        WHERE receipts.announcement = announcements.id
+          AND receipts.acknowledged IS NOT NULL) AS acknowledged_count,
+       COUNT(*) OVER ()                         AS total_count
+FROM announcements
+         LEFT JOIN announcement_receipts
+                   ON announcement_receipts.announcement = announcements.id AND announcement_receipts.user = @user
+WHERE (sqlc.narg('active_at') IS NULL OR announcements.expires IS NULL OR announcements.expires > sqlc.narg('active_at'))
+  AND (sqlc.narg('pending') IS NULL OR
+       (announcements.require_ack AND announcement_receipts.acknowledged IS NULL) = sqlc.narg('pending'))
+ORDER BY announcements.created DESC
+LIMIT @limit OFFSET @offset;
+
+-- name: ListAnnouncementReceipts :many
+SELECT users.id AS user,
+       users.name,
+       users.username,
+       announcement_receipts.read,
+       announcement_receipts.acknowledged
+FROM users
+         JOIN announcements ON announcements.id = @announcement
+         LEFT JOIN announcement_receipts
+                   ON announcement_receipts.announcement = announcements.id AND announcement_receipts.user = users.id
+WHERE users.id != 'system'
+  AND users.active
+  AND (sqlc.narg('pending') IS NULL OR CASE
+                                            WHEN announcements.require_ack
+                                                THEN announcement_receipts.acknowledged IS NULL
+                                            ELSE announcement_receipts.read IS NULL END = sqlc.narg('pending'))
+ORDER BY users.username;
+
+------------------------------------------------------------------
+
+-- name: ListTicketActivity :many
+SELECT ticket_activity.*,
+       users.name       AS user_name,
+       COUNT(*) OVER () AS total_count
+FROM ticket_activity
+         LEFT JOIN users ON users.id = ticket_activity.user
+WHERE ticket_activity.ticket = @ticket
+ORDER BY ticket_activity.created, ticket_activity.rowid
+LIMIT @limit OFFSET @offset;
+
+------------------------------------------------------------------
+
+-- name: ListDLPEvents :many
+SELECT dlp_events.*,
+       users.name       AS user_name,
+       COUNT(*) OVER () AS total_count
+FROM dlp_events
+         LEFT JOIN users ON users.id = dlp_events.user
+ORDER BY dlp_events.created DESC, dlp_events.rowid DESC
+LIMIT @limit OFFSET @offset;
+
+------------------------------------------------------------------
+
+-- name: ListFederationPeers :many
+SELECT federation_peers.*, COUNT(*) OVER () AS total_count
+FROM federation_peers
+ORDER BY name
+LIMIT @limit OFFSET @offset;
+
+-- name: GetFederationPeer :one
+SELECT *
+FROM federation_peers
+WHERE id = @id;
+
+-- name: FederationPeerByURL :one
+SELECT *
+FROM federation_peers
+WHERE url = @url;
+
+-- name: ListTicketFederationLinks :many
+SELECT federation_links.*, federation_peers.name AS peer_name, federation_peers.url AS peer_url
+FROM federation_links
+         JOIN federation_peers ON federation_peers.id = federation_links.peer
+WHERE federation_links.ticket = @ticket
+ORDER BY federation_links.created;
+
+-- name: GetFederationLink :one
+SELECT *
+FROM federation_links
+WHERE id = @id;
+
+-- name: ListRemoteFederationLinks :many
+SELECT *
+FROM federation_links
+WHERE peer = @peer
+  AND remote_ticket = @remote_ticket;
+
+------------------------------------------------------------------
+
+-- name: GetDocumentTemplate :one
+SELECT *
+FROM document_templates
+WHERE format = @format;
+
+------------------------------------------------------------------
+
+-- name: ListReportTickets :many
+SELECT tickets.*,
+       users.name     as owner_name,
+       types.singular as type_singular
+FROM tickets
+         LEFT JOIN users ON users.id = tickets.owner
+         LEFT JOIN types ON types.id = tickets.type
+WHERE (sqlc.narg('type') IS NULL OR tickets.type = sqlc.narg('type'))
+  AND datetime(tickets.created) >= datetime(CAST(@since AS TEXT))
+ORDER BY tickets.created;
+
+------------------------------------------------------------------
+
+-- name: ListTicketUsage :many
+SELECT CAST(coalesce(json_extract(state, '$.customer'), '') AS TEXT) AS customer,
+       CAST(strftime('%Y-%m', created) AS TEXT)                     AS month,
+       COUNT(*)                                                      AS tickets
+FROM tickets
+WHERE datetime(created) >= datetime(CAST(@since AS TEXT))
+  AND datetime(created) < datetime(CAST(@until AS TEXT))
+GROUP BY customer, month;
+
+-- name: ListEffortUsage :many
+SELECT CAST(coalesce(json_extract(tickets.state, '$.customer'), '') AS TEXT) AS customer,
+       CAST(strftime('%Y-%m', work_entries.started) AS TEXT)                AS month,
+       CAST(SUM(work_entries.duration) AS INTEGER)                          AS duration
+FROM work_entries
+         JOIN tickets ON tickets.id = work_entries.ticket
+WHERE work_entries.duration IS NOT NULL
+  AND datetime(work_entries.started) >= datetime(CAST(@since AS TEXT))
+  AND datetime(work_entries.started) < datetime(CAST(@until AS TEXT))
+GROUP BY customer, month;
+
+-- name: ListAutomationUsage :many
+SELECT CAST(coalesce(json_extract(tickets.state, '$.customer'), '') AS TEXT) AS customer,
+       CAST(strftime('%Y-%m', playbook_runs.created) AS TEXT)               AS month,
+       COUNT(*)                                                             AS runs
+FROM playbook_runs
+         JOIN tickets ON tickets.id = playbook_runs.ticket
+WHERE datetime(playbook_runs.created) >= datetime(CAST(@since AS TEXT))
+  AND datetime(playbook_runs.created) < datetime(CAST(@until AS TEXT))
+GROUP BY customer, month;
+
+-- name: ListStorageUsage :many
+SELECT CAST(coalesce(json_extract(tickets.state, '$.customer'), '') AS TEXT) AS customer,
+       CAST(strftime('%Y-%m', files.created) AS TEXT)                       AS month,
+       CAST(SUM(files.size) AS INTEGER)                                     AS size
+FROM files
+         JOIN tickets ON tickets.id = files.ticket
+WHERE datetime(files.created) >= datetime(CAST(@since AS TEXT))
+  AND datetime(files.created) < datetime(CAST(@until AS TEXT))
+GROUP BY customer, month;
+
+------------------------------------------------------------------
+
+-- name: ListAutoCloseRules :many
+SELECT auto_close_rules.*, COUNT(*) OVER () as total_count
+FROM auto_close_rules
+ORDER BY name
+LIMIT @limit OFFSET @offset;
+
+-- name: ListEnabledAutoCloseRules :many
+SELECT *
+FROM auto_close_rules
+WHERE enabled
+ORDER BY name;
+
+-- name: GetAutoCloseRule :one
+SELECT *
+FROM auto_close_rules
+WHERE id = @id;
+
+-- name: GetAutoCloseWarning :one
+SELECT *
+FROM auto_close_warnings
+WHERE ticket = @ticket;
+
+-- name: ListInactiveTickets :many
+WITH activity AS (SELECT tickets.id,
+                         max(datetime(tickets.updated),
+                             coalesce((SELECT max(datetime(comments.updated)) FROM comments WHERE comments.ticket = tickets.id), ''),
+                             coalesce((SELECT max(datetime(tasks.updated)) FROM tasks WHERE tasks.ticket = tickets.id), ''),
+                             coalesce((SELECT max(datetime(files.updated)) FROM files WHERE files.ticket = tickets.id), ''),
+                             coalesce((SELECT max(datetime(timeline.created)) FROM timeline WHERE timeline.ticket = tickets.id), '')) AS last_activity
+                  FROM tickets
+                  WHERE tickets.open)
+SELECT tickets.*, CAST(activity.last_activity AS TEXT) AS last_activity
+FROM tickets
+         JOIN activity ON activity.id = tickets.id
+WHERE activity.last_activity < datetime(CAST(@before AS TEXT))
+ORDER BY activity.last_activity;
+
+------------------------------------------------------------------
+
+-- name: ListAlerts :many
+SELECT alerts.*, COUNT(*) OVER () as total_count
+FROM alerts
+WHERE (sqlc.narg('status') IS NULL OR status = sqlc.narg('status'))
+  AND (sqlc.narg('source') IS NULL OR source = sqlc.narg('source'))
+  AND (sqlc.narg('ticket') IS NULL OR ticket = sqlc.narg('ticket'))
+ORDER BY created DESC
+LIMIT @limit OFFSET @offset;
+
+-- name: GetAlert :one
+SELECT *
+FROM alerts
+WHERE id = @id;
+
+-- name: ListTicketAlertSources :many
+SELECT DISTINCT source
+FROM alerts
+WHERE ticket = @ticket
+ORDER BY source;
+
+------------------------------------------------------------------
+
+-- name: ListQueues :many
+SELECT queues.*, COUNT(*) OVER () as total_count
+FROM queues
+ORDER BY name
+LIMIT @limit OFFSET @offset;
+
+-- name: GetQueue :one
+SELECT *
+FROM queues
+WHERE id = @id;
+
+-- name: GetQueueByName :one
+SELECT *
+FROM queues
+WHERE name = @name;
+
+------------------------------------------------------------------
+
+-- name: ListWorkEntries :many
+SELECT work_entries.*, users.name AS user_name
+FROM work_entries
+         LEFT JOIN users ON users.id = work_entries.user
+WHERE work_entries.ticket = @ticket
+ORDER BY work_entries.started;
+
+-- name: ListTicketEffort :many
+SELECT tickets.id,
+       tickets.name,
+       tickets.type,
+       CAST(SUM(work_entries.duration) AS INTEGER) AS duration,
+       COUNT(DISTINCT work_entries.user)           AS analysts
+FROM work_entries
+         JOIN tickets ON tickets.id = work_entries.ticket
+WHERE work_entries.duration IS NOT NULL
+  AND (sqlc.narg('type') IS NULL OR tickets.type = sqlc.narg('type'))
+  AND datetime(work_entries.started) >= datetime(CAST(@since AS TEXT))
+  AND datetime(work_entries.started) < datetime(CAST(@until AS TEXT))
+GROUP BY tickets.id
+ORDER BY duration DESC, tickets.id;
+
+-- name: ListAnalystEffort :many
+SELECT work_entries.user,
+       users.name                                  AS user_name,
+       CAST(SUM(work_entries.duration) AS INTEGER) AS duration,
+       COUNT(DISTINCT work_entries.ticket)         AS tickets
+FROM work_entries
+         JOIN tickets ON tickets.id = work_entries.ticket
+         LEFT JOIN users ON users.id = work_entries.user
+WHERE work_entries.duration IS NOT NULL
+  AND (sqlc.narg('type') IS NULL OR tickets.type = sqlc.narg('type'))
+  AND datetime(work_entries.started) >= datetime(CAST(@since AS TEXT))
+  AND datetime(work_entries.started) < datetime(CAST(@until AS TEXT))
+GROUP BY work_entries.user
+ORDER BY duration DESC, work_entries.user;
+
+------------------------------------------------------------------
+
+-- name: GetTaskOutput :one
+SELECT *
+FROM task_outputs
+WHERE task = @task;
+
+-- name: ListTaskOutputs :many
+SELECT task_outputs.*, tasks.name AS task_name, tasks.open AS task_open, tasks.owner AS task_owner
+FROM task_outputs
+         JOIN tasks ON tasks.id = task_outputs.task
+WHERE task_outputs.ticket = @ticket
+ORDER BY task_outputs.name;
+
+-- name: GetTaskTimer :one
+SELECT *
+FROM task_timers
+WHERE task = @task;
+
+-- name: ListDueTaskTimers :many
+SELECT task_timers.*, tasks.name AS task_name, tasks.owner AS task_owner, tasks.ticket AS task_ticket
+FROM task_timers
+         JOIN tasks ON tasks.id = task_timers.task
+WHERE tasks.open = TRUE
+  AND task_timers.fired IS NULL
+  AND datetime(task_timers.due) <= datetime(CAST(@now AS TEXT))
+ORDER BY task_timers.due;
+
+-- name: ListPendingTaskTimers :many
+SELECT task_timers.task, task_timers.due, tasks.ticket AS task_ticket, tickets.type AS ticket_type
+FROM task_timers
+         JOIN tasks ON tasks.id = task_timers.task
+         JOIN tickets ON tickets.id = tasks.ticket
+WHERE tasks.open = TRUE
+  AND task_timers.fired IS NULL;
+
+------------------------------------------------------------------
+
+-- name: ListFeeds :many
+SELECT *
+FROM feeds
+ORDER BY name;
+
+-- name: MatchFeedIndicators :many
+SELECT feed_indicators.feed, feed_indicators.kind, feeds.imported
+FROM feed_indicators
+         JOIN feeds ON feeds.name = feed_indicators.feed
+WHERE feed_indicators.value = @value
+ORDER BY feed_indicators.feed;
+
 ------------------------------------------------------------------
 
 -- name: GetComment :one
@@ -69,6 +893,14 @@ WHERE ticket = @ticket
 ORDER BY files.created DESC
 LIMIT @limit OFFSET @offset;
 
+-- ListFileBlobs only selects the columns of the first schema, so that the
+-- migrations can list the files before later columns are added.
+-- name: ListFileBlobs :many
+SELECT id, name, blob
+FROM files
+ORDER BY id
+LIMIT @limit OFFSET @offset;
+
 ------------------------------------------------------------------
 
 -- name: GetLink :one
@@ -104,6 +936,32 @@ WHERE trigger = @trigger
 ORDER BY reactions.created DESC
 LIMIT @limit OFFSET @offset;
 
+-- name: GetReactionRunStats :one
+SELECT COUNT(*)                                                     AS runs,
+       CAST(COALESCE(SUM(NOT success), 0) AS INTEGER)               AS failures,
+       CAST(COALESCE(AVG(duration_ms), 0) AS REAL)                  AS avg_duration_ms,
+       CAST(COALESCE(MAX(duration_ms), 0) AS INTEGER)               AS max_duration_ms,
+       CAST(COALESCE(SUM(cpu_ms), 0) AS INTEGER)                    AS total_cpu_ms,
+       CAST(COALESCE(AVG(cpu_ms), 0) AS REAL)                       AS avg_cpu_ms,
+       CAST(COALESCE(MAX(cpu_ms), 0) AS INTEGER)                    AS max_cpu_ms,
+       CAST(COALESCE(MAX(memory_peak), 0) AS INTEGER)               AS max_memory_peak
+FROM reaction_runs
+WHERE reaction = @reaction;
+
+-- name: ListReactionRuns :many
+SELECT *
+FROM reaction_runs
+WHERE reaction = @reaction
+ORDER BY created DESC, rowid DESC
+LIMIT @limit;
+
+-- name: ListAllReactionRuns :many
+SELECT reaction_runs.*, reactions.name AS reaction_name, reactions.action, reactions.trigger
+FROM reaction_runs
+         JOIN reactions ON reactions.id = reaction_runs.reaction
+ORDER BY reaction_runs.created, reaction_runs.id
+LIMIT @limit OFFSET @offset;
+
 ------------------------------------------------------------------
 
 -- name: GetTask :one
@@ -172,6 +1030,90 @@ SELECT *
 FROM users
 WHERE email = @email;
 
+-- name: ListInvitations :many
+SELECT invitations.*, COUNT(*) OVER () as total_count
+FROM invitations
+ORDER BY created DESC
+LIMIT @limit OFFSET @offset;
+
+-- name: GetInvitation :one
+SELECT *
+FROM invitations
+WHERE id = @id;
+
+-- name: InvitationByToken :one
+SELECT *
+FROM invitations
+WHERE token_hash = @token_hash;
+
+-- name: ListAPIUsage :many
+SELECT api_usage.user,
+       api_usage.client,
+       users.username,
+       users.name,
+       CAST(SUM(api_usage.requests) AS INTEGER)                    AS requests,
+       CAST(COALESCE((SELECT today.requests
+                      FROM api_usage AS today
+                      WHERE today.user = api_usage.user
+                        AND today.client = api_usage.client
+                        AND today.day = @today), 0) AS INTEGER) AS today,
+       api_quotas.daily_limit
+FROM api_usage
+         JOIN users ON users.id = api_usage.user
+         LEFT JOIN api_quotas ON api_quotas.user = api_usage.user AND api_quotas.client = api_usage.client
+WHERE api_usage.day >= @since
+  AND api_usage.day <= @until
+GROUP BY api_usage.user, api_usage.client
+ORDER BY requests DESC, api_usage.user, api_usage.client;
+
+-- name: GetAPIUsage :one
+SELECT requests
+FROM api_usage
+WHERE user = @user
+  AND client = @client
+  AND day = @day;
+
+-- name: GetAPIQuota :one
+SELECT *
+FROM api_quotas
+WHERE user = @user
+  AND client = @client;
+
+-- name: ListAPIQuotas :many
+SELECT *
+FROM api_quotas
+ORDER BY user, client;
+
+-- name: ListLogs :many
+SELECT logs.*, COUNT(*) OVER () as total_count
+FROM logs
+WHERE (sqlc.narg('level') IS NULL OR level >= sqlc.narg('level'))
+  AND (sqlc.narg('component') IS NULL OR component = sqlc.narg('component'))
+  AND (sqlc.narg('user') IS NULL OR user = sqlc.narg('user'))
+  AND (sqlc.narg('since') IS NULL OR created >= sqlc.narg('since'))
+  AND (sqlc.narg('until') IS NULL OR created <= sqlc.narg('until'))
+  AND (sqlc.narg('query') IS NULL OR message LIKE '%' || sqlc.narg('query') || '%')
+ORDER BY created DESC, rowid DESC
+LIMIT @limit OFFSET @offset;
+
+-- name: GetPlatformError :one
+SELECT platform_errors.*, tickets.open AS ticket_open
+FROM platform_errors
+         JOIN tickets ON tickets.id = platform_errors.ticket
+WHERE fingerprint = @fingerprint;
+
+-- name: ListPasswordHistory :many
+SELECT passwordHash, created
+FROM password_history
+WHERE user = @user
+ORDER BY created DESC, rowid DESC
+LIMIT @limit;
+
+-- name: CountBcryptPasswordHashes :one
+SELECT COUNT(*)
+FROM users
+WHERE passwordHash LIKE '$2%';
+
 -- name: SystemUser :one
 SELECT *
 FROM users
@@ -199,6 +1141,168 @@ LIMIT @limit OFFSET @offset;
 
 ------------------------------------------------------------------
 
+-- name: GetDeadLetter :one
+SELECT *
+FROM dead_letters
+WHERE id = @id;
+
+-- name: ListDeadLetters :many
+SELECT dead_letters.*, COUNT(*) OVER () as total_count
+FROM dead_letters
+ORDER BY created DESC
+LIMIT @limit OFFSET @offset;
+
+------------------------------------------------------------------
+
+-- name: GetSlackThread :one
+SELECT *
+FROM slack_threads
+WHERE ticket = @ticket;
+
+-- name: GetSlackThreadByTS :one
+SELECT *
+FROM slack_threads
+WHERE channel = @channel
+  AND thread_ts = @thread_ts;
+
+------------------------------------------------------------------
+
+-- name: ListPushSubscriptions :many
+SELECT *
+FROM push_subscriptions
+WHERE user = @user
+ORDER BY created DESC;
+
+------------------------------------------------------------------
+
+-- name: GetDigest :one
+SELECT *
+FROM digests
+WHERE user = @user;
+
+-- name: ListActiveDigests :many
+SELECT digests.*, users.username, users.name, users.email
+FROM digests
+         JOIN users ON users.id = digests.user
+WHERE digests.frequency != 'off'
+  AND users.active = TRUE
+  AND users.email IS NOT NULL
+  AND users.email != '';
+
+-- name: ListOpenTicketsByOwner :many
+SELECT id, name, type, created
+FROM tickets
+WHERE owner = @owner
+  AND open = TRUE
+ORDER BY created DESC
+LIMIT @limit;
+
+-- name: ListOpenTasksByOwner :many
+SELECT id, ticket, name
+FROM tasks
+WHERE owner = @owner
+  AND open = TRUE
+ORDER BY created DESC;
+
+-- name: ListPendingTaskTimersByEscalationOwner :many
+SELECT task
+FROM task_timers
+WHERE escalation_owner = @escalation_owner
+  AND fired IS NULL;
+
+-- name: ListApproachingTaskTimers :many
+SELECT task_timers.due,
+       tasks.name   AS task_name,
+       tickets.id   AS ticket,
+       tickets.name AS ticket_name,
+       tickets.type AS ticket_type
+FROM task_timers
+         JOIN tasks ON tasks.id = task_timers.task
+         JOIN tickets ON tickets.id = tasks.ticket
+WHERE tasks.open = TRUE
+  AND task_timers.fired IS NULL
+  AND (tasks.owner = @user OR tickets.owner = @user)
+  AND datetime(task_timers.due) <= datetime(CAST(@until AS TEXT))
+ORDER BY task_timers.due
+LIMIT @limit;
+
+-- name: ListMentions :many
+SELECT comments.id,
+       comments.ticket,
+       comments.message,
+       comments.created,
+       tickets.name AS ticket_name,
+       tickets.type AS ticket_type,
+       users.name   AS author_name
+FROM comments
+         JOIN tickets ON tickets.id = comments.ticket
+         LEFT JOIN users ON users.id = comments.author
+WHERE comments.message LIKE '%@' || CAST(@username AS TEXT) || '%'
+  AND datetime(comments.created) > datetime(CAST(@since AS TEXT))
+ORDER BY comments.created DESC
+LIMIT @limit;
+
+------------------------------------------------------------------
+
+-- name: GetNotificationRule :one
+SELECT *
+FROM notification_rules
+WHERE id = @id;
+
+-- name: ListNotificationRules :many
+SELECT notification_rules.*, COUNT(*) OVER () as total_count
+FROM notification_rules
+ORDER BY created DESC
+LIMIT @limit OFFSET @offset;
+
+-- name: ListEnabledNotificationRules :many
+SELECT *
+FROM notification_rules
+WHERE enabled = TRUE
+ORDER BY name;
+
+------------------------------------------------------------------
+
+-- name: GetEscalationPolicy :one
+SELECT *
+FROM escalation_policies
+WHERE id = @id;
+
+-- name: ListEscalationPolicies :many
+SELECT escalation_policies.*, COUNT(*) OVER () as total_count
+FROM escalation_policies
+ORDER BY name
+LIMIT @limit OFFSET @offset;
+
+-- name: GetTicketEscalation :one
+SELECT *
+FROM ticket_escalations
+WHERE ticket = @ticket;
+
+-- name: ListDueEscalations :many
+SELECT ticket_escalations.*, tickets.name AS ticket_name, tickets.type AS ticket_type
+FROM ticket_escalations
+         JOIN tickets ON tickets.id = ticket_escalations.ticket
+WHERE tickets.open = TRUE
+  AND tickets.acknowledged IS NULL
+  AND ticket_escalations.acknowledged_at IS NULL
+  AND ticket_escalations.paused_since IS NULL
+  AND ticket_escalations.next_at IS NOT NULL
+  AND datetime(ticket_escalations.next_at) <= datetime(CAST(@now AS TEXT))
+ORDER BY ticket_escalations.next_at;
+
+-- name: ListPendingEscalations :many
+SELECT ticket_escalations.ticket, ticket_escalations.next_at, tickets.type AS ticket_type
+FROM ticket_escalations
+         JOIN tickets ON tickets.id = ticket_escalations.ticket
+WHERE tickets.open = TRUE
+  AND tickets.acknowledged IS NULL
+  AND ticket_escalations.acknowledged_at IS NULL
+  AND ticket_escalations.paused_since IS NULL
+  AND ticket_escalations.next_at IS NOT NULL;
+
+------------------------------------------------------------------
+
 -- name: GetDashboardCounts :many
 SELECT *
 FROM dashboard_counts;
@@ -207,6 +1311,19 @@ FROM dashboard_counts;
 SELECT *
 FROM sidebar;
 
+-- name: CountTicketsPerDay :many
+SELECT CAST(date(created) AS TEXT) as day, COUNT(*) as count
+FROM tickets
+WHERE created >= date('now', '-30 days')
+GROUP BY day
+ORDER BY day;
+
+-- name: CountReactionsByTrigger :many
+SELECT trigger, COUNT(*) as count
+FROM reactions
+GROUP BY trigger
+ORDER BY trigger;
+
 -- name: SearchTickets :many
 SELECT id,
        name,
@@ -219,8 +1336,9 @@ SELECT id,
        COUNT(*) OVER () as total_count
 FROM ticket_search
 WHERE (@query = '' OR (name LIKE '%' || @query || '%'
-    OR description LIKE '%' || @query || '%'
-    OR comment_messages LIKE '%' || @query || '%'
+    -- the description and comments of encrypted tickets are not searchable
+    OR (NOT EXISTS (SELECT 1 FROM ticket_keys WHERE ticket_keys.ticket = ticket_search.id)
+        AND (description LIKE '%' || @query || '%' OR comment_messages LIKE '%' || @query || '%'))
     OR file_names LIKE '%' || @query || '%'
     OR link_names LIKE '%' || @query || '%'
     OR link_urls LIKE '%' || @query || '%'
@@ -283,3 +1401,140 @@ SELECT group_effective_permissions.permission
 FROM group_effective_permissions
 WHERE parent_group_id = @group_id
 ORDER BY permission;
+
+------------------------------------------------------------------
+
+-- name: GetMaintenanceWindow :one
+SELECT *
+FROM maintenance_windows
+WHERE id = @id;
+
+-- name: ListMaintenanceWindows :many
+SELECT maintenance_windows.*, COUNT(*) OVER () as total_count
+FROM maintenance_windows
+ORDER BY starts DESC
+LIMIT @limit OFFSET @offset;
+
+-- name: ListOverlappingMaintenanceWindows :many
+SELECT *
+FROM maintenance_windows
+WHERE datetime(starts) < datetime(CAST(@until AS TEXT))
+  AND datetime(ends) > datetime(CAST(@since AS TEXT))
+ORDER BY starts;
+
+------------------------------------------------------------------
+
+-- name: ListTicketArtifacts :many
+SELECT *
+FROM ticket_artifacts
+WHERE ticket = @ticket
+ORDER BY created, kind, value;
+
+-- name: SearchTicketArtifacts :many
+SELECT tickets.id,
+       tickets.key,
+       tickets.name,
+       tickets.type,
+       tickets.open,
+       ticket_artifacts.created,
+       COUNT(*) OVER () AS total_count
+FROM ticket_artifacts
+         JOIN tickets ON tickets.id = ticket_artifacts.ticket
+WHERE ticket_artifacts.kind = @kind
+  AND ticket_artifacts.value = @value
+ORDER BY ticket_artifacts.created DESC, tickets.id
+LIMIT @limit OFFSET @offset;
+
+-- name: SearchFilesByHash :many
+SELECT files.id,
+       files.name,
+       files.size,
+       files.created,
+       tickets.id   AS ticket,
+       tickets.key  AS ticket_key,
+       tickets.name AS ticket_name
+FROM files
+         JOIN tickets ON tickets.id = files.ticket
+WHERE files.md5 = @hash
+   OR files.sha1 = @hash
+   OR files.sha256 = @hash
+ORDER BY files.created DESC, files.id;
+
+------------------------------------------------------------------
+
+-- name: ListArtifactSightings :many
+SELECT *
+FROM artifact_sightings
+WHERE kind = @kind
+  AND value = @value
+ORDER BY seen DESC, rowid DESC
+LIMIT @limit OFFSET @offset;
+
+------------------------------------------------------------------
+
+-- name: FirstArtifactSighting :one
+SELECT *
+FROM artifact_sightings
+WHERE kind = @kind
+  AND value = @value
+ORDER BY seen, rowid
+LIMIT 1;
+
+------------------------------------------------------------------
+
+-- name: LastArtifactSighting :one
+SELECT *
+FROM artifact_sightings
+WHERE kind = @kind
+  AND value = @value
+ORDER BY seen DESC, rowid DESC
+LIMIT 1;
+
+------------------------------------------------------------------
+
+-- name: CountArtifactSightings :one
+SELECT COUNT(*)
+FROM artifact_sightings
+WHERE kind = @kind
+  AND value = @value;
+
+------------------------------------------------------------------
+
+-- name: GetRedactionProfile :one
+SELECT *
+FROM redaction_profiles
+WHERE id = @id;
+
+-- name: ListRedactionProfiles :many
+SELECT redaction_profiles.*, COUNT(*) OVER () as total_count
+FROM redaction_profiles
+ORDER BY audience, name
+LIMIT @limit OFFSET @offset;
+
+------------------------------------------------------------------
+
+-- name: GetJournalEntry :one
+SELECT *
+FROM request_journal
+WHERE id = @id
+  AND reaction = @reaction;
+
+-- name: ListJournalEntries :many
+SELECT request_journal.*, COUNT(*) OVER () as total_count
+FROM request_journal
+WHERE reaction = @reaction
+ORDER BY created DESC, rowid DESC
+LIMIT @limit OFFSET @offset;
+
+------------------------------------------------------------------
+
+-- name: GetAlertMapping :one
+SELECT *
+FROM alert_mappings
+WHERE id = @id;
+
+-- name: ListAlertMappings :many
+SELECT alert_mappings.*, COUNT(*) OVER () as total_count
+FROM alert_mappings
+ORDER BY name
+LIMIT @limit OFFSET @offset;
@@ -35,3 +35,13 @@ func New(readDB, writeDB *sql.DB) *Queries {
 		WriteDB:      writeDB,
 	}
 }
+
+// WithTx returns queries that read and write in the transaction.
+func (q *Queries) WithTx(tx *sql.Tx) *Queries {
+	return &Queries{
+		ReadQueries:  &ReadQueries{db: tx},
+		WriteQueries: &WriteQueries{db: tx},
+		ReadDB:       q.ReadDB,
+		WriteDB:      q.WriteDB,
+	}
+}
@@ -8,6 +8,132 @@ import (
 	"time"
 )
 
+type Alert struct {
+	ID          string    `json:"id"`
+	Source      string    `json:"source"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Severity    string    `json:"severity"`
+	Data        []byte    `json:"data"`
+	Status      string    `json:"status"`
+	Ticket      *string   `json:"ticket"`
+	Created     time.Time `json:"created"`
+	Updated     time.Time `json:"updated"`
+	Maintenance *string   `json:"maintenance"`
+}
+
+type AlertMapping struct {
+	ID              string    `json:"id"`
+	Name            string    `json:"name"`
+	Source          string    `json:"source"`
+	Template        string    `json:"template"`
+	TemplateVersion int64     `json:"template_version"`
+	Fields          []byte    `json:"fields"`
+	Created         time.Time `json:"created"`
+	Updated         time.Time `json:"updated"`
+}
+
+type Announcement struct {
+	ID         string     `json:"id"`
+	Title      string     `json:"title"`
+	Message    string     `json:"message"`
+	Severity   string     `json:"severity"`
+	RequireAck bool       `json:"require_ack"`
+	Author     *string    `json:"author"`
+	Expires    *time.Time `json:"expires"`
+	Created    time.Time  `json:"created"`
+	Updated    time.Time  `json:"updated"`
+}
+
+type AnnouncementReceipt struct {
+	Announcement string     `json:"announcement"`
+	User         string     `json:"user"`
+	Read         time.Time  `json:"read"`
+	Acknowledged *time.Time `json:"acknowledged"`
+}
+
+type ApiQuota struct {
+	User       string    `json:"user"`
+	Client     string    `json:"client"`
+	DailyLimit int64     `json:"daily_limit"`
+	Created    time.Time `json:"created"`
+	Updated    time.Time `json:"updated"`
+}
+
+type ApiUsage struct {
+	User     string `json:"user"`
+	Client   string `json:"client"`
+	Day      string `json:"day"`
+	Requests int64  `json:"requests"`
+}
+
+type ArtifactSighting struct {
+	ID        string    `json:"id"`
+	Kind      string    `json:"kind"`
+	Value     string    `json:"value"`
+	Ticket    *string   `json:"ticket"`
+	Source    string    `json:"source"`
+	Reference string    `json:"reference"`
+	Seen      time.Time `json:"seen"`
+}
+
+type AutoCloseRule struct {
+	ID           string    `json:"id"`
+	Name         string    `json:"name"`
+	Enabled      bool      `json:"enabled"`
+	Filter       []byte    `json:"filter"`
+	InactiveDays int64     `json:"inactive_days"`
+	WarningDays  int64     `json:"warning_days"`
+	Resolution   string    `json:"resolution"`
+	Created      time.Time `json:"created"`
+	Updated      time.Time `json:"updated"`
+}
+
+type AutoCloseWarning struct {
+	Ticket string    `json:"ticket"`
+	Rule   string    `json:"rule"`
+	Warned time.Time `json:"warned"`
+}
+
+type Campaign struct {
+	ID           string    `json:"id"`
+	Name         string    `json:"name"`
+	Description  string    `json:"description"`
+	Playbook     *string   `json:"playbook"`
+	Inputs       []byte    `json:"inputs"`
+	Created      time.Time `json:"created"`
+	Updated      time.Time `json:"updated"`
+	FeatureKind  *string   `json:"feature_kind"`
+	FeatureValue *string   `json:"feature_value"`
+}
+
+type CampaignTicket struct {
+	Campaign string    `json:"campaign"`
+	Ticket   string    `json:"ticket"`
+	Run      *string   `json:"run"`
+	Created  time.Time `json:"created"`
+}
+
+type CampaignTimeline struct {
+	ID       string    `json:"id"`
+	Campaign string    `json:"campaign"`
+	Ticket   *string   `json:"ticket"`
+	Message  string    `json:"message"`
+	Time     time.Time `json:"time"`
+}
+
+type Cfe struct {
+	ID           string     `json:"id"`
+	Description  string     `json:"description"`
+	CvssScore    *float64   `json:"cvss_score"`
+	CvssSeverity *string    `json:"cvss_severity"`
+	CvssVector   *string    `json:"cvss_vector"`
+	Products     []byte     `json:"products"`
+	Published    *time.Time `json:"published"`
+	Enriched     *time.Time `json:"enriched"`
+	Created      time.Time  `json:"created"`
+}
+
 type Comment struct {
 	ID      string    `json:"id"`
 	Ticket  string    `json:"ticket"`
@@ -22,10 +148,123 @@ type DashboardCount struct {
 	Count int64  `json:"count"`
 }
 
+type DeadLetter struct {
+	ID          string    `json:"id"`
+	Webhook     string    `json:"webhook"`
+	Destination string    `json:"destination"`
+	Payload     []byte    `json:"payload"`
+	Error       string    `json:"error"`
+	Attempts    int64     `json:"attempts"`
+	Created     time.Time `json:"created"`
+	Updated     time.Time `json:"updated"`
+}
+
+type DetectionRule struct {
+	ID          string     `json:"id"`
+	Ticket      string     `json:"ticket"`
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	Format      string     `json:"format"`
+	Content     string     `json:"content"`
+	Status      string     `json:"status"`
+	Author      *string    `json:"author"`
+	Reviewer    *string    `json:"reviewer"`
+	Deployed    *time.Time `json:"deployed"`
+	Created     time.Time  `json:"created"`
+	Updated     time.Time  `json:"updated"`
+}
+
+type DetectionRuleTicket struct {
+	Rule    string    `json:"rule"`
+	Ticket  string    `json:"ticket"`
+	Created time.Time `json:"created"`
+}
+
+type Digest struct {
+	User      string     `json:"user"`
+	Frequency string     `json:"frequency"`
+	LastSent  *time.Time `json:"last_sent"`
+	Created   time.Time  `json:"created"`
+	Updated   time.Time  `json:"updated"`
+}
+
+type DlpEvent struct {
+	ID      string    `json:"id"`
+	Rule    string    `json:"rule"`
+	Export  string    `json:"export"`
+	Ticket  string    `json:"ticket"`
+	User    *string   `json:"user"`
+	Created time.Time `json:"created"`
+}
+
+type DocumentTemplate struct {
+	Format  string    `json:"format"`
+	Name    string    `json:"name"`
+	Content []byte    `json:"content"`
+	Created time.Time `json:"created"`
+	Updated time.Time `json:"updated"`
+}
+
+type EnrichmentCache struct {
+	Enricher string    `json:"enricher"`
+	Value    string    `json:"value"`
+	Result   []byte    `json:"result"`
+	Expires  time.Time `json:"expires"`
+	Created  time.Time `json:"created"`
+}
+
+type EscalationPolicy struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Filter      []byte    `json:"filter"`
+	Steps       []byte    `json:"steps"`
+	Repeat      bool      `json:"repeat"`
+	Created     time.Time `json:"created"`
+	Updated     time.Time `json:"updated"`
+	PauseStates []byte    `json:"pause_states"`
+}
+
 type Feature struct {
 	Key string `json:"key"`
 }
 
+type FederationLink struct {
+	ID           string    `json:"id"`
+	Ticket       string    `json:"ticket"`
+	Peer         string    `json:"peer"`
+	RemoteTicket string    `json:"remote_ticket"`
+	RemoteKey    *string   `json:"remote_key"`
+	RemoteType   string    `json:"remote_type"`
+	RemoteName   string    `json:"remote_name"`
+	RemoteOpen   bool      `json:"remote_open"`
+	Share        bool      `json:"share"`
+	Created      time.Time `json:"created"`
+	Updated      time.Time `json:"updated"`
+}
+
+type FederationPeer struct {
+	ID      string    `json:"id"`
+	Name    string    `json:"name"`
+	Url     string    `json:"url"`
+	Secret  string    `json:"secret"`
+	MaxTlp  string    `json:"max_tlp"`
+	Created time.Time `json:"created"`
+	Updated time.Time `json:"updated"`
+}
+
+type Feed struct {
+	Name       string    `json:"name"`
+	Source     string    `json:"source"`
+	Indicators int64     `json:"indicators"`
+	Imported   time.Time `json:"imported"`
+}
+
+type FeedIndicator struct {
+	Feed  string `json:"feed"`
+	Kind  string `json:"kind"`
+	Value string `json:"value"`
+}
+
 type File struct {
 	ID      string    `json:"id"`
 	Ticket  string    `json:"ticket"`
@@ -34,6 +273,20 @@ type File struct {
 	Size    float64   `json:"size"`
 	Created time.Time `json:"created"`
 	Updated time.Time `json:"updated"`
+	Md5     string    `json:"md5"`
+	Sha1    string    `json:"sha1"`
+	Sha256  string    `json:"sha256"`
+}
+
+type FileCustody struct {
+	ID       string    `json:"id"`
+	Ticket   string    `json:"ticket"`
+	File     string    `json:"file"`
+	FileName string    `json:"file_name"`
+	Action   string    `json:"action"`
+	User     *string   `json:"user"`
+	Sha256   string    `json:"sha256"`
+	Created  time.Time `json:"created"`
 }
 
 type Group struct {
@@ -60,6 +313,36 @@ type GroupInheritance struct {
 	ChildGroupID  string `json:"child_group_id"`
 }
 
+type Invitation struct {
+	ID         string    `json:"id"`
+	Email      string    `json:"email"`
+	Name       string    `json:"name"`
+	Groups     []byte    `json:"groups"`
+	TokenHash  string    `json:"token_hash"`
+	InvitedBy  *string   `json:"invited_by"`
+	Expires    time.Time `json:"expires"`
+	AcceptedBy *string   `json:"accepted_by"`
+	Created    time.Time `json:"created"`
+	Updated    time.Time `json:"updated"`
+}
+
+type KnownExploited struct {
+	Cve        string    `json:"cve"`
+	Name       string    `json:"name"`
+	Added      string    `json:"added"`
+	Due        string    `json:"due"`
+	Ransomware bool      `json:"ransomware"`
+	Updated    time.Time `json:"updated"`
+}
+
+type LegalHold struct {
+	ID         string    `json:"id"`
+	Collection string    `json:"collection"`
+	Reason     string    `json:"reason"`
+	CreatedBy  *string   `json:"created_by"`
+	Created    time.Time `json:"created"`
+}
+
 type Link struct {
 	ID      string    `json:"id"`
 	Ticket  string    `json:"ticket"`
@@ -69,11 +352,101 @@ type Link struct {
 	Updated time.Time `json:"updated"`
 }
 
+type Log struct {
+	ID        string    `json:"id"`
+	Level     int64     `json:"level"`
+	Message   string    `json:"message"`
+	Component string    `json:"component"`
+	User      *string   `json:"user"`
+	Attrs     []byte    `json:"attrs"`
+	Created   time.Time `json:"created"`
+}
+
+type MaintenanceWindow struct {
+	ID      string    `json:"id"`
+	Name    string    `json:"name"`
+	Filter  []byte    `json:"filter"`
+	Starts  time.Time `json:"starts"`
+	Ends    time.Time `json:"ends"`
+	Created time.Time `json:"created"`
+	Updated time.Time `json:"updated"`
+}
+
+type NotificationRule struct {
+	ID       string    `json:"id"`
+	Name     string    `json:"name"`
+	Enabled  bool      `json:"enabled"`
+	Filter   []byte    `json:"filter"`
+	Channel  string    `json:"channel"`
+	Target   string    `json:"target"`
+	Throttle int64     `json:"throttle"`
+	Dedup    int64     `json:"dedup"`
+	Created  time.Time `json:"created"`
+	Updated  time.Time `json:"updated"`
+}
+
 type Param struct {
 	Key   string `json:"key"`
 	Value []byte `json:"value"`
 }
 
+type PasswordHistory struct {
+	ID           string    `json:"id"`
+	User         string    `json:"user"`
+	Passwordhash string    `json:"passwordhash"`
+	Created      time.Time `json:"created"`
+}
+
+type PlatformError struct {
+	Fingerprint string    `json:"fingerprint"`
+	Ticket      string    `json:"ticket"`
+	Occurrences int64     `json:"occurrences"`
+	FirstSeen   time.Time `json:"first_seen"`
+	LastSeen    time.Time `json:"last_seen"`
+}
+
+type Playbook struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Inputs      []byte    `json:"inputs"`
+	Tasks       []byte    `json:"tasks"`
+	Created     time.Time `json:"created"`
+	Updated     time.Time `json:"updated"`
+}
+
+type PlaybookRun struct {
+	ID       string    `json:"id"`
+	Playbook string    `json:"playbook"`
+	Ticket   string    `json:"ticket"`
+	Inputs   []byte    `json:"inputs"`
+	Created  time.Time `json:"created"`
+}
+
+type PlaybookRunTask struct {
+	Run  string `json:"run"`
+	Task string `json:"task"`
+}
+
+type PushSubscription struct {
+	ID       string    `json:"id"`
+	User     string    `json:"user"`
+	Endpoint string    `json:"endpoint"`
+	P256dh   string    `json:"p256dh"`
+	Auth     string    `json:"auth"`
+	Created  time.Time `json:"created"`
+	Updated  time.Time `json:"updated"`
+}
+
+type Queue struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Filter      []byte    `json:"filter"`
+	Created     time.Time `json:"created"`
+	Updated     time.Time `json:"updated"`
+}
+
 type Reaction struct {
 	ID          string    `json:"id"`
 	Name        string    `json:"name"`
@@ -85,6 +458,39 @@ type Reaction struct {
 	Updated     time.Time `json:"updated"`
 }
 
+type ReactionRun struct {
+	ID         string    `json:"id"`
+	Reaction   string    `json:"reaction"`
+	Success    bool      `json:"success"`
+	DurationMs int64     `json:"duration_ms"`
+	CpuMs      int64     `json:"cpu_ms"`
+	MemoryPeak int64     `json:"memory_peak"`
+	Created    time.Time `json:"created"`
+}
+
+type RedactionProfile struct {
+	ID             string    `json:"id"`
+	Name           string    `json:"name"`
+	Audience       string    `json:"audience"`
+	Fields         []byte    `json:"fields"`
+	HideFiles      bool      `json:"hide_files"`
+	HideIdentities bool      `json:"hide_identities"`
+	Created        time.Time `json:"created"`
+	Updated        time.Time `json:"updated"`
+}
+
+type RequestJournal struct {
+	ID        string     `json:"id"`
+	Reaction  string     `json:"reaction"`
+	Request   []byte     `json:"request"`
+	Size      int64      `json:"size"`
+	Truncated bool       `json:"truncated"`
+	Success   bool       `json:"success"`
+	Error     string     `json:"error"`
+	Replayed  *time.Time `json:"replayed"`
+	Created   time.Time  `json:"created"`
+}
+
 type Sidebar struct {
 	ID       string  `json:"id"`
 	Singular string  `json:"singular"`
@@ -93,6 +499,13 @@ type Sidebar struct {
 	Count    int64   `json:"count"`
 }
 
+type SlackThread struct {
+	Ticket   string    `json:"ticket"`
+	Channel  string    `json:"channel"`
+	ThreadTs string    `json:"thread_ts"`
+	Created  time.Time `json:"created"`
+}
+
 type Task struct {
 	ID      string    `json:"id"`
 	Ticket  string    `json:"ticket"`
@@ -103,18 +516,119 @@ type Task struct {
 	Updated time.Time `json:"updated"`
 }
 
+type TaskOutput struct {
+	Task    string    `json:"task"`
+	Ticket  string    `json:"ticket"`
+	Name    string    `json:"name"`
+	Output  []byte    `json:"output"`
+	Created time.Time `json:"created"`
+	Updated time.Time `json:"updated"`
+}
+
+type TaskTimer struct {
+	Task            string     `json:"task"`
+	Due             time.Time  `json:"due"`
+	Action          string     `json:"action"`
+	EscalationName  *string    `json:"escalation_name"`
+	EscalationOwner *string    `json:"escalation_owner"`
+	Result          *string    `json:"result"`
+	EscalationTask  *string    `json:"escalation_task"`
+	Fired           *time.Time `json:"fired"`
+	Created         time.Time  `json:"created"`
+	Updated         time.Time  `json:"updated"`
+}
+
 type Ticket struct {
-	ID          string    `json:"id"`
-	Type        string    `json:"type"`
-	Owner       *string   `json:"owner"`
-	Name        string    `json:"name"`
-	Description string    `json:"description"`
-	Open        bool      `json:"open"`
-	Resolution  *string   `json:"resolution"`
-	Schema      []byte    `json:"schema"`
-	State       []byte    `json:"state"`
-	Created     time.Time `json:"created"`
-	Updated     time.Time `json:"updated"`
+	ID             string     `json:"id"`
+	Type           string     `json:"type"`
+	Owner          *string    `json:"owner"`
+	Name           string     `json:"name"`
+	Description    string     `json:"description"`
+	Open           bool       `json:"open"`
+	Resolution     *string    `json:"resolution"`
+	Schema         []byte     `json:"schema"`
+	State          []byte     `json:"state"`
+	Created        time.Time  `json:"created"`
+	Updated        time.Time  `json:"updated"`
+	Acknowledged   *time.Time `json:"acknowledged"`
+	AcknowledgedBy *string    `json:"acknowledged_by"`
+	Resolved       *time.Time `json:"resolved"`
+	Encrypted      bool       `json:"encrypted"`
+	Key            *string    `json:"key"`
+	ResolvedBy     *string    `json:"resolved_by"`
+	ReopenCount    int64      `json:"reopen_count"`
+}
+
+type TicketActivity struct {
+	ID       string    `json:"id"`
+	Ticket   string    `json:"ticket"`
+	User     *string   `json:"user"`
+	Method   string    `json:"method"`
+	Path     string    `json:"path"`
+	Body     *string   `json:"body"`
+	Status   int64     `json:"status"`
+	Duration int64     `json:"duration"`
+	Created  time.Time `json:"created"`
+}
+
+type TicketArtifact struct {
+	Ticket  string    `json:"ticket"`
+	Kind    string    `json:"kind"`
+	Value   string    `json:"value"`
+	Created time.Time `json:"created"`
+}
+
+type TicketCfe struct {
+	Ticket  string    `json:"ticket"`
+	Cve     string    `json:"cve"`
+	Created time.Time `json:"created"`
+}
+
+type TicketEscalation struct {
+	Ticket         string     `json:"ticket"`
+	Policy         string     `json:"policy"`
+	Step           int64      `json:"step"`
+	NextAt         *time.Time `json:"next_at"`
+	AcknowledgedBy *string    `json:"acknowledged_by"`
+	AcknowledgedAt *time.Time `json:"acknowledged_at"`
+	Created        time.Time  `json:"created"`
+	Updated        time.Time  `json:"updated"`
+	PausedSince    *time.Time `json:"paused_since"`
+	Paused         int64      `json:"paused"`
+}
+
+type TicketFeature struct {
+	Ticket string `json:"ticket"`
+	Kind   string `json:"kind"`
+	Value  string `json:"value"`
+}
+
+type TicketGrant struct {
+	Ticket  string    `json:"ticket"`
+	User    string    `json:"user"`
+	Created time.Time `json:"created"`
+}
+
+type TicketKey struct {
+	Ticket  string    `json:"ticket"`
+	Key     string    `json:"key"`
+	Created time.Time `json:"created"`
+}
+
+type TicketKeySequence struct {
+	Prefix string `json:"prefix"`
+	Year   int64  `json:"year"`
+	Value  int64  `json:"value"`
+}
+
+type TicketReopen struct {
+	ID         string    `json:"id"`
+	Ticket     string    `json:"ticket"`
+	Reason     string    `json:"reason"`
+	Resolution *string   `json:"resolution"`
+	ClosedBy   *string   `json:"closed_by"`
+	ReopenedBy *string   `json:"reopened_by"`
+	Created    time.Time `json:"created"`
 }
 
 type TicketSearch struct {
@@ -134,6 +648,12 @@ type TicketSearch struct {
 	TimelineMessages string    `json:"timeline_messages"`
 }
 
+type TicketTechnique struct {
+	Ticket    string `json:"ticket"`
+	Technique string `json:"technique"`
+	Tactic    string `json:"tactic"`
+}
+
 type Timeline struct {
 	ID      string    `json:"id"`
 	Ticket  string    `json:"ticket"`
@@ -144,13 +664,21 @@ type Timeline struct {
 }
 
 type Type struct {
-	ID       string    `json:"id"`
-	Icon     *string   `json:"icon"`
-	Singular string    `json:"singular"`
-	Plural   string    `json:"plural"`
-	Schema   []byte    `json:"schema"`
-	Created  time.Time `json:"created"`
-	Updated  time.Time `json:"updated"`
+	ID        string    `json:"id"`
+	Icon      *string   `json:"icon"`
+	Singular  string    `json:"singular"`
+	Plural    string    `json:"plural"`
+	Schema    []byte    `json:"schema"`
+	Created   time.Time `json:"created"`
+	Updated   time.Time `json:"updated"`
+	KeyPrefix *string   `json:"key_prefix"`
+	Metrics   []byte    `json:"metrics"`
+}
+
+type TypeTechnique struct {
+	Type      string `json:"type"`
+	Technique string `json:"technique"`
+	Tactic    string `json:"tactic"`
 }
 
 type User struct {
@@ -192,3 +720,15 @@ type Webhook struct {
 	Created     time.Time `json:"created"`
 	Updated     time.Time `json:"updated"`
 }
+
+type WorkEntry struct {
+	ID          string    `json:"id"`
+	Ticket      string    `json:"ticket"`
+	User        string    `json:"user"`
+	Description string    `json:"description"`
+	Started     time.Time `json:"started"`
+	Duration    *int64    `json:"duration"`
+	Manual      bool      `json:"manual"`
+	Created     time.Time `json:"created"`
+	Updated     time.Time `json:"updated"`
+}
@@ -10,57 +10,77 @@ import (
 	"time"
 )
 
-const getComment = `-- name: GetComment :one
+const anyTicketKey = `-- name: AnyTicketKey :one
+SELECT key
+FROM ticket_keys
+LIMIT 1
+`
 
-SELECT comments.id, comments.ticket, comments.author, comments.message, comments.created, comments.updated, users.name as author_name
-FROM comments
-         LEFT JOIN users ON users.id = comments.author
-WHERE comments.id = ?1
+// AnyTicketKey returns one of the wrapped case keys, to check that the
+// master key matches them.
+func (q *ReadQueries) AnyTicketKey(ctx context.Context) (string, error) {
+	row := q.db.QueryRowContext(ctx, anyTicketKey)
+	var key string
+	err := row.Scan(&key)
+	return key, err
+}
+
+const countArtifactSightings = `-- name: CountArtifactSightings :one
+
+SELECT COUNT(*)
+FROM artifact_sightings
+WHERE kind = ?1
+  AND value = ?2
 `
 
-type GetCommentRow struct {
-	ID         string    `json:"id"`
-	Ticket     string    `json:"ticket"`
-	Author     string    `json:"author"`
-	Message    string    `json:"message"`
-	Created    time.Time `json:"created"`
-	Updated    time.Time `json:"updated"`
-	AuthorName *string   `json:"author_name"`
+type CountArtifactSightingsParams struct {
+	Kind  string `json:"kind"`
+	Value string `json:"value"`
 }
 
 // ----------------------------------------------------------------
-func (q *ReadQueries) GetComment(ctx context.Context, id string) (GetCommentRow, error) {
-	row := q.db.QueryRowContext(ctx, getComment, id)
-	var i GetCommentRow
-	err := row.Scan(
-		&i.ID,
-		&i.Ticket,
-		&i.Author,
-		&i.Message,
-		&i.Created,
-		&i.Updated,
-		&i.AuthorName,
-	)
-	return i, err
+func (q *ReadQueries) CountArtifactSightings(ctx context.Context, arg CountArtifactSightingsParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countArtifactSightings, arg.Kind, arg.Value)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
 }
 
-const getDashboardCounts = `-- name: GetDashboardCounts :many
+const countBcryptPasswordHashes = `-- name: CountBcryptPasswordHashes :one
+SELECT COUNT(*)
+FROM users
+WHERE passwordHash LIKE '$2%'
+`
 
-SELECT id, count
-FROM dashboard_counts
+func (q *ReadQueries) CountBcryptPasswordHashes(ctx context.Context) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countBcryptPasswordHashes)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countReactionsByTrigger = `-- name: CountReactionsByTrigger :many
+SELECT trigger, COUNT(*) as count
+FROM reactions
+GROUP BY trigger
+ORDER BY trigger
 `
 
-// ----------------------------------------------------------------
-func (q *ReadQueries) GetDashboardCounts(ctx context.Context) ([]DashboardCount, error) {
-	rows, err := q.db.QueryContext(ctx, getDashboardCounts)
+type CountReactionsByTriggerRow struct {
+	Trigger string `json:"trigger"`
+	Count   int64  `json:"count"`
+}
+
+func (q *ReadQueries) CountReactionsByTrigger(ctx context.Context) ([]CountReactionsByTriggerRow, error) {
+	rows, err := q.db.QueryContext(ctx, countReactionsByTrigger)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []DashboardCount
+	var items []CountReactionsByTriggerRow
 	for rows.Next() {
-		var i DashboardCount
-		if err := rows.Scan(&i.ID, &i.Count); err != nil {
+		var i CountReactionsByTriggerRow
+		if err := rows.Scan(&i.Trigger, &i.Count); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -74,313 +94,5606 @@ func (q *ReadQueries) GetDashboardCounts(ctx context.Context) ([]DashboardCount,
 	return items, nil
 }
 
-const getFeature = `-- name: GetFeature :one
+const countTicketsPerDay = `-- name: CountTicketsPerDay :many
+SELECT CAST(date(created) AS TEXT) as day, COUNT(*) as count
+FROM tickets
+WHERE created >= date('now', '-30 days')
+GROUP BY day
+ORDER BY day
+`
 
-SELECT "key"
-FROM features
-WHERE key = ?1
+type CountTicketsPerDayRow struct {
+	Day   string `json:"day"`
+	Count int64  `json:"count"`
+}
+
+func (q *ReadQueries) CountTicketsPerDay(ctx context.Context) ([]CountTicketsPerDayRow, error) {
+	rows, err := q.db.QueryContext(ctx, countTicketsPerDay)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []CountTicketsPerDayRow
+	for rows.Next() {
+		var i CountTicketsPerDayRow
+		if err := rows.Scan(&i.Day, &i.Count); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const countTicketsPerHour = `-- name: CountTicketsPerHour :many
+SELECT type,
+       CAST(coalesce(json_extract(state, '$.source'), '') AS TEXT) AS source,
+       CAST(strftime('%Y-%m-%d %H:00:00', created) AS TEXT)      AS hour,
+       COUNT(*)                                                  AS count
+FROM tickets
+WHERE datetime(created) >= datetime(CAST(?1 AS TEXT))
+GROUP BY 1, 2, 3
+ORDER BY 3
 `
 
-// ----------------------------------------------------------------
-func (q *ReadQueries) GetFeature(ctx context.Context, key string) (string, error) {
-	row := q.db.QueryRowContext(ctx, getFeature, key)
-	err := row.Scan(&key)
-	return key, err
+type CountTicketsPerHourRow struct {
+	Type   string `json:"type"`
+	Source string `json:"source"`
+	Hour   string `json:"hour"`
+	Count  int64  `json:"count"`
 }
 
-const getFile = `-- name: GetFile :one
+func (q *ReadQueries) CountTicketsPerHour(ctx context.Context, since string) ([]CountTicketsPerHourRow, error) {
+	rows, err := q.db.QueryContext(ctx, countTicketsPerHour, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []CountTicketsPerHourRow
+	for rows.Next() {
+		var i CountTicketsPerHourRow
+		if err := rows.Scan(
+			&i.Type,
+			&i.Source,
+			&i.Hour,
+			&i.Count,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
 
-SELECT id, ticket, name, blob, size, created, updated
-FROM files
-WHERE id = ?1
+const federationPeerByURL = `-- name: FederationPeerByURL :one
+SELECT id, name, url, secret, max_tlp, created, updated
+FROM federation_peers
+WHERE url = ?1
 `
 
-// ----------------------------------------------------------------
-func (q *ReadQueries) GetFile(ctx context.Context, id string) (File, error) {
-	row := q.db.QueryRowContext(ctx, getFile, id)
-	var i File
+func (q *ReadQueries) FederationPeerByURL(ctx context.Context, url string) (FederationPeer, error) {
+	row := q.db.QueryRowContext(ctx, federationPeerByURL, url)
+	var i FederationPeer
 	err := row.Scan(
 		&i.ID,
-		&i.Ticket,
 		&i.Name,
-		&i.Blob,
-		&i.Size,
+		&i.Url,
+		&i.Secret,
+		&i.MaxTlp,
 		&i.Created,
 		&i.Updated,
 	)
 	return i, err
 }
 
-const getGroup = `-- name: GetGroup :one
-
-SELECT id, name, permissions, created, updated
-FROM groups
+const findDetectionRule = `-- name: FindDetectionRule :one
+SELECT id, ticket, name, description, format, content, status, author, reviewer, deployed, created, updated
+FROM detection_rules
 WHERE id = ?1
+   OR name = ?1
+LIMIT 1
 `
 
-// ----------------------------------------------------------------
-func (q *ReadQueries) GetGroup(ctx context.Context, id string) (Group, error) {
-	row := q.db.QueryRowContext(ctx, getGroup, id)
-	var i Group
+func (q *ReadQueries) FindDetectionRule(ctx context.Context, rule string) (DetectionRule, error) {
+	row := q.db.QueryRowContext(ctx, findDetectionRule, rule)
+	var i DetectionRule
 	err := row.Scan(
 		&i.ID,
+		&i.Ticket,
 		&i.Name,
-		&i.Permissions,
+		&i.Description,
+		&i.Format,
+		&i.Content,
+		&i.Status,
+		&i.Author,
+		&i.Reviewer,
+		&i.Deployed,
 		&i.Created,
 		&i.Updated,
 	)
 	return i, err
 }
 
-const getLink = `-- name: GetLink :one
+const firstArtifactSighting = `-- name: FirstArtifactSighting :one
 
-SELECT id, ticket, name, url, created, updated
-FROM links
-WHERE id = ?1
+SELECT id, kind, value, ticket, source, reference, seen
+FROM artifact_sightings
+WHERE kind = ?1
+  AND value = ?2
+ORDER BY seen, rowid
+LIMIT 1
 `
 
+type FirstArtifactSightingParams struct {
+	Kind  string `json:"kind"`
+	Value string `json:"value"`
+}
+
 // ----------------------------------------------------------------
-func (q *ReadQueries) GetLink(ctx context.Context, id string) (Link, error) {
-	row := q.db.QueryRowContext(ctx, getLink, id)
-	var i Link
+func (q *ReadQueries) FirstArtifactSighting(ctx context.Context, arg FirstArtifactSightingParams) (ArtifactSighting, error) {
+	row := q.db.QueryRowContext(ctx, firstArtifactSighting, arg.Kind, arg.Value)
+	var i ArtifactSighting
 	err := row.Scan(
 		&i.ID,
+		&i.Kind,
+		&i.Value,
 		&i.Ticket,
-		&i.Name,
-		&i.Url,
-		&i.Created,
-		&i.Updated,
+		&i.Source,
+		&i.Reference,
+		&i.Seen,
 	)
 	return i, err
 }
 
-const getReaction = `-- name: GetReaction :one
-
-SELECT id, name, "action", actiondata, "trigger", triggerdata, created, updated
-FROM reactions
-WHERE id = ?1
+const getAPIQuota = `-- name: GetAPIQuota :one
+SELECT user, client, daily_limit, created, updated
+FROM api_quotas
+WHERE user = ?1
+  AND client = ?2
 `
 
-// ----------------------------------------------------------------
-func (q *ReadQueries) GetReaction(ctx context.Context, id string) (Reaction, error) {
-	row := q.db.QueryRowContext(ctx, getReaction, id)
-	var i Reaction
+type GetAPIQuotaParams struct {
+	User   string `json:"user"`
+	Client string `json:"client"`
+}
+
+func (q *ReadQueries) GetAPIQuota(ctx context.Context, arg GetAPIQuotaParams) (ApiQuota, error) {
+	row := q.db.QueryRowContext(ctx, getAPIQuota, arg.User, arg.Client)
+	var i ApiQuota
 	err := row.Scan(
-		&i.ID,
-		&i.Name,
-		&i.Action,
-		&i.Actiondata,
-		&i.Trigger,
-		&i.Triggerdata,
+		&i.User,
+		&i.Client,
+		&i.DailyLimit,
 		&i.Created,
 		&i.Updated,
 	)
 	return i, err
 }
 
-const getSidebar = `-- name: GetSidebar :many
-SELECT id, singular, plural, icon, count
-FROM sidebar
+const getAPIUsage = `-- name: GetAPIUsage :one
+SELECT requests
+FROM api_usage
+WHERE user = ?1
+  AND client = ?2
+  AND day = ?3
 `
 
-func (q *ReadQueries) GetSidebar(ctx context.Context) ([]Sidebar, error) {
-	rows, err := q.db.QueryContext(ctx, getSidebar)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-	var items []Sidebar
-	for rows.Next() {
-		var i Sidebar
-		if err := rows.Scan(
-			&i.ID,
-			&i.Singular,
-			&i.Plural,
-			&i.Icon,
-			&i.Count,
-		); err != nil {
-			return nil, err
-		}
-		items = append(items, i)
-	}
-	if err := rows.Close(); err != nil {
-		return nil, err
-	}
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
-	return items, nil
+type GetAPIUsageParams struct {
+	User   string `json:"user"`
+	Client string `json:"client"`
+	Day    string `json:"day"`
 }
 
-const getTask = `-- name: GetTask :one
+func (q *ReadQueries) GetAPIUsage(ctx context.Context, arg GetAPIUsageParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, getAPIUsage, arg.User, arg.Client, arg.Day)
+	var requests int64
+	err := row.Scan(&requests)
+	return requests, err
+}
 
-SELECT tasks.id, tasks.ticket, tasks.owner, tasks.name, tasks.open, tasks.created, tasks.updated, users.name as owner_name, tickets.name as ticket_name, tickets.type as ticket_type
-FROM tasks
-         LEFT JOIN users ON users.id = tasks.owner
-         LEFT JOIN tickets ON tickets.id = tasks.ticket
-WHERE tasks.id = ?1
+const getAlert = `-- name: GetAlert :one
+SELECT id, source, name, description, severity, data, status, ticket, created, updated, maintenance
+FROM alerts
+WHERE id = ?1
 `
 
-type GetTaskRow struct {
-	ID         string    `json:"id"`
-	Ticket     string    `json:"ticket"`
-	Owner      *string   `json:"owner"`
-	Name       string    `json:"name"`
-	Open       bool      `json:"open"`
-	Created    time.Time `json:"created"`
-	Updated    time.Time `json:"updated"`
-	OwnerName  *string   `json:"owner_name"`
-	TicketName *string   `json:"ticket_name"`
-	TicketType *string   `json:"ticket_type"`
-}
-
-// ----------------------------------------------------------------
-func (q *ReadQueries) GetTask(ctx context.Context, id string) (GetTaskRow, error) {
-	row := q.db.QueryRowContext(ctx, getTask, id)
-	var i GetTaskRow
+func (q *ReadQueries) GetAlert(ctx context.Context, id string) (Alert, error) {
+	row := q.db.QueryRowContext(ctx, getAlert, id)
+	var i Alert
 	err := row.Scan(
 		&i.ID,
-		&i.Ticket,
-		&i.Owner,
+		&i.Source,
 		&i.Name,
-		&i.Open,
+		&i.Description,
+		&i.Severity,
+		&i.Data,
+		&i.Status,
+		&i.Ticket,
 		&i.Created,
 		&i.Updated,
-		&i.OwnerName,
-		&i.TicketName,
-		&i.TicketType,
+		&i.Maintenance,
 	)
 	return i, err
 }
 
-const getTimeline = `-- name: GetTimeline :one
+const getAlertMapping = `-- name: GetAlertMapping :one
 
-SELECT id, ticket, message, time, created, updated
-FROM timeline
+SELECT id, name, source, template, template_version, fields, created, updated
+FROM alert_mappings
 WHERE id = ?1
 `
 
 // ----------------------------------------------------------------
-func (q *ReadQueries) GetTimeline(ctx context.Context, id string) (Timeline, error) {
-	row := q.db.QueryRowContext(ctx, getTimeline, id)
-	var i Timeline
+func (q *ReadQueries) GetAlertMapping(ctx context.Context, id string) (AlertMapping, error) {
+	row := q.db.QueryRowContext(ctx, getAlertMapping, id)
+	var i AlertMapping
 	err := row.Scan(
 		&i.ID,
-		&i.Ticket,
-		&i.Message,
-		&i.Time,
+		&i.Name,
+		&i.Source,
+		&i.Template,
+		&i.TemplateVersion,
+		&i.Fields,
 		&i.Created,
 		&i.Updated,
 	)
 	return i, err
 }
 
-const getType = `-- name: GetType :one
+const getAnnouncement = `-- name: GetAnnouncement :one
 
-SELECT id, icon, singular, plural, schema, created, updated
-FROM types
-WHERE id = ?1
+SELECT announcements.id, announcements.title, announcements.message, announcements.severity, announcements.require_ack, announcements.author, announcements.expires, announcements.created, announcements.updated,
+       announcement_receipts.read,
+       announcement_receipts.acknowledged,
+       (SELECT COUNT(*)
+        FROM announcement_receipts AS receipts
+        WHERE receipts.announcement = announcements.id
+          AND receipts.acknowledged IS NOT NULL) AS acknowledged_count
+FROM announcements
+         LEFT JOIN announcement_receipts
+                   ON announcement_receipts.announcement = announcements.id AND announcement_receipts.user = ?1
+WHERE announcements.id = ?2
 `
 
+type GetAnnouncementParams struct {
+	User string `json:"user"`
+	ID   string `json:"id"`
+}
+
+type GetAnnouncementRow struct {
+	ID                string     `json:"id"`
+	Title             string     `json:"title"`
+	Message           string     `json:"message"`
+	Severity          string     `json:"severity"`
+	RequireAck        bool       `json:"require_ack"`
+	Author            *string    `json:"author"`
+	Expires           *time.Time `json:"expires"`
+	Created           time.Time  `json:"created"`
+	Updated           time.Time  `json:"updated"`
+	Read              *time.Time `json:"read"`
+	Acknowledged      *time.Time `json:"acknowledged"`
+	AcknowledgedCount int64      `json:"acknowledged_count"`
+}
+
 // ----------------------------------------------------------------
-func (q *ReadQueries) GetType(ctx context.Context, id string) (Type, error) {
-	row := q.db.QueryRowContext(ctx, getType, id)
-	var i Type
+func (q *ReadQueries) GetAnnouncement(ctx context.Context, arg GetAnnouncementParams) (GetAnnouncementRow, error) {
+	row := q.db.QueryRowContext(ctx, getAnnouncement, arg.User, arg.ID)
+	var i GetAnnouncementRow
 	err := row.Scan(
 		&i.ID,
-		&i.Icon,
-		&i.Singular,
-		&i.Plural,
-		&i.Schema,
+		&i.Title,
+		&i.Message,
+		&i.Severity,
+		&i.RequireAck,
+		&i.Author,
+		&i.Expires,
 		&i.Created,
 		&i.Updated,
+		&i.Read,
+		&i.Acknowledged,
+		&i.AcknowledgedCount,
 	)
 	return i, err
 }
 
-const getUser = `-- name: GetUser :one
-
-SELECT id, username, passwordhash, tokenkey, active, name, email, avatar, lastresetsentat, lastverificationsentat, created, updated
-FROM users
+const getAutoCloseRule = `-- name: GetAutoCloseRule :one
+SELECT id, name, enabled, "filter", inactive_days, warning_days, resolution, created, updated
+FROM auto_close_rules
 WHERE id = ?1
 `
 
-// ----------------------------------------------------------------
-func (q *ReadQueries) GetUser(ctx context.Context, id string) (User, error) {
-	row := q.db.QueryRowContext(ctx, getUser, id)
-	var i User
+func (q *ReadQueries) GetAutoCloseRule(ctx context.Context, id string) (AutoCloseRule, error) {
+	row := q.db.QueryRowContext(ctx, getAutoCloseRule, id)
+	var i AutoCloseRule
 	err := row.Scan(
 		&i.ID,
-		&i.Username,
-		&i.Passwordhash,
-		&i.Tokenkey,
-		&i.Active,
 		&i.Name,
-		&i.Email,
-		&i.Avatar,
-		&i.Lastresetsentat,
-		&i.Lastverificationsentat,
+		&i.Enabled,
+		&i.Filter,
+		&i.InactiveDays,
+		&i.WarningDays,
+		&i.Resolution,
+		&i.Created,
+		&i.Updated,
+	)
+	return i, err
+}
+
+const getAutoCloseWarning = `-- name: GetAutoCloseWarning :one
+SELECT ticket, rule, warned
+FROM auto_close_warnings
+WHERE ticket = ?1
+`
+
+func (q *ReadQueries) GetAutoCloseWarning(ctx context.Context, ticket string) (AutoCloseWarning, error) {
+	row := q.db.QueryRowContext(ctx, getAutoCloseWarning, ticket)
+	var i AutoCloseWarning
+	err := row.Scan(&i.Ticket, &i.Rule, &i.Warned)
+	return i, err
+}
+
+const getCVE = `-- name: GetCVE :one
+
+SELECT cves.id, cves.description, cves.cvss_score, cves.cvss_severity, cves.cvss_vector, cves.products, cves.published, cves.enriched, cves.created,
+       known_exploited.cve IS NOT NULL                    AS kev,
+       coalesce(known_exploited.added, '')                AS kev_added,
+       coalesce(known_exploited.due, '')                  AS kev_due,
+       coalesce(known_exploited.ransomware, FALSE)        AS kev_ransomware,
+       (SELECT COUNT(*)
+        FROM ticket_cves
+        WHERE ticket_cves.cve = cves.id)                  AS ticket_count,
+       (SELECT COUNT(*)
+        FROM ticket_cves
+                 JOIN tickets ON tickets.id = ticket_cves.ticket
+        WHERE ticket_cves.cve = cves.id
+          AND tickets.open)                               AS open_ticket_count
+FROM cves
+         LEFT JOIN known_exploited ON known_exploited.cve = cves.id
+WHERE cves.id = ?1
+`
+
+type GetCVERow struct {
+	ID              string     `json:"id"`
+	Description     string     `json:"description"`
+	CvssScore       *float64   `json:"cvss_score"`
+	CvssSeverity    *string    `json:"cvss_severity"`
+	CvssVector      *string    `json:"cvss_vector"`
+	Products        []byte     `json:"products"`
+	Published       *time.Time `json:"published"`
+	Enriched        *time.Time `json:"enriched"`
+	Created         time.Time  `json:"created"`
+	Kev             bool       `json:"kev"`
+	KevAdded        string     `json:"kev_added"`
+	KevDue          string     `json:"kev_due"`
+	KevRansomware   bool       `json:"kev_ransomware"`
+	TicketCount     int64      `json:"ticket_count"`
+	OpenTicketCount int64      `json:"open_ticket_count"`
+}
+
+// ----------------------------------------------------------------
+func (q *ReadQueries) GetCVE(ctx context.Context, id string) (GetCVERow, error) {
+	row := q.db.QueryRowContext(ctx, getCVE, id)
+	var i GetCVERow
+	err := row.Scan(
+		&i.ID,
+		&i.Description,
+		&i.CvssScore,
+		&i.CvssSeverity,
+		&i.CvssVector,
+		&i.Products,
+		&i.Published,
+		&i.Enriched,
+		&i.Created,
+		&i.Kev,
+		&i.KevAdded,
+		&i.KevDue,
+		&i.KevRansomware,
+		&i.TicketCount,
+		&i.OpenTicketCount,
+	)
+	return i, err
+}
+
+const getCampaign = `-- name: GetCampaign :one
+
+SELECT campaigns.id, campaigns.name, campaigns.description, campaigns.playbook, campaigns.inputs, campaigns.created, campaigns.updated, campaigns.feature_kind, campaigns.feature_value,
+       (SELECT COUNT(*)
+        FROM campaign_tickets
+        WHERE campaign_tickets.campaign = campaigns.id)            AS ticket_count,
+       (SELECT COUNT(*)
+        FROM campaign_tickets
+                 JOIN tickets ON tickets.id = campaign_tickets.ticket
+        WHERE campaign_tickets.campaign = campaigns.id
+          AND tickets.open)                                        AS open_ticket_count,
+       (SELECT COUNT(*)
+        FROM campaign_tickets
+                 JOIN playbook_run_tasks ON playbook_run_tasks.run = campaign_tickets.run
+        WHERE campaign_tickets.campaign = campaigns.id)            AS task_count,
+       (SELECT COUNT(*)
+        FROM campaign_tickets
+                 JOIN playbook_run_tasks ON playbook_run_tasks.run = campaign_tickets.run
+                 JOIN tasks ON tasks.id = playbook_run_tasks.task
+        WHERE campaign_tickets.campaign = campaigns.id
+          AND tasks.open)                                          AS open_task_count
+FROM campaigns
+WHERE campaigns.id = ?1
+`
+
+type GetCampaignRow struct {
+	ID              string    `json:"id"`
+	Name            string    `json:"name"`
+	Description     string    `json:"description"`
+	Playbook        *string   `json:"playbook"`
+	Inputs          []byte    `json:"inputs"`
+	Created         time.Time `json:"created"`
+	Updated         time.Time `json:"updated"`
+	FeatureKind     *string   `json:"feature_kind"`
+	FeatureValue    *string   `json:"feature_value"`
+	TicketCount     int64     `json:"ticket_count"`
+	OpenTicketCount int64     `json:"open_ticket_count"`
+	TaskCount       int64     `json:"task_count"`
+	OpenTaskCount   int64     `json:"open_task_count"`
+}
+
+// ----------------------------------------------------------------
+func (q *ReadQueries) GetCampaign(ctx context.Context, id string) (GetCampaignRow, error) {
+	row := q.db.QueryRowContext(ctx, getCampaign, id)
+	var i GetCampaignRow
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Description,
+		&i.Playbook,
+		&i.Inputs,
+		&i.Created,
+		&i.Updated,
+		&i.FeatureKind,
+		&i.FeatureValue,
+		&i.TicketCount,
+		&i.OpenTicketCount,
+		&i.TaskCount,
+		&i.OpenTaskCount,
+	)
+	return i, err
+}
+
+const getCampaignTicket = `-- name: GetCampaignTicket :one
+SELECT campaign, ticket, run, created
+FROM campaign_tickets
+WHERE campaign = ?1
+  AND ticket = ?2
+`
+
+type GetCampaignTicketParams struct {
+	Campaign string `json:"campaign"`
+	Ticket   string `json:"ticket"`
+}
+
+func (q *ReadQueries) GetCampaignTicket(ctx context.Context, arg GetCampaignTicketParams) (CampaignTicket, error) {
+	row := q.db.QueryRowContext(ctx, getCampaignTicket, arg.Campaign, arg.Ticket)
+	var i CampaignTicket
+	err := row.Scan(
+		&i.Campaign,
+		&i.Ticket,
+		&i.Run,
+		&i.Created,
+	)
+	return i, err
+}
+
+const getComment = `-- name: GetComment :one
+
+SELECT comments.id, comments.ticket, comments.author, comments.message, comments.created, comments.updated, users.name as author_name
+FROM comments
+         LEFT JOIN users ON users.id = comments.author
+WHERE comments.id = ?1
+`
+
+type GetCommentRow struct {
+	ID         string    `json:"id"`
+	Ticket     string    `json:"ticket"`
+	Author     string    `json:"author"`
+	Message    string    `json:"message"`
+	Created    time.Time `json:"created"`
+	Updated    time.Time `json:"updated"`
+	AuthorName *string   `json:"author_name"`
+}
+
+// ----------------------------------------------------------------
+func (q *ReadQueries) GetComment(ctx context.Context, id string) (GetCommentRow, error) {
+	row := q.db.QueryRowContext(ctx, getComment, id)
+	var i GetCommentRow
+	err := row.Scan(
+		&i.ID,
+		&i.Ticket,
+		&i.Author,
+		&i.Message,
+		&i.Created,
+		&i.Updated,
+		&i.AuthorName,
+	)
+	return i, err
+}
+
+const getDashboardCounts = `-- name: GetDashboardCounts :many
+
+SELECT id, count
+FROM dashboard_counts
+`
+
+// ----------------------------------------------------------------
+func (q *ReadQueries) GetDashboardCounts(ctx context.Context) ([]DashboardCount, error) {
+	rows, err := q.db.QueryContext(ctx, getDashboardCounts)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []DashboardCount
+	for rows.Next() {
+		var i DashboardCount
+		if err := rows.Scan(&i.ID, &i.Count); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getDeadLetter = `-- name: GetDeadLetter :one
+
+SELECT id, webhook, destination, payload, error, attempts, created, updated
+FROM dead_letters
+WHERE id = ?1
+`
+
+// ----------------------------------------------------------------
+func (q *ReadQueries) GetDeadLetter(ctx context.Context, id string) (DeadLetter, error) {
+	row := q.db.QueryRowContext(ctx, getDeadLetter, id)
+	var i DeadLetter
+	err := row.Scan(
+		&i.ID,
+		&i.Webhook,
+		&i.Destination,
+		&i.Payload,
+		&i.Error,
+		&i.Attempts,
+		&i.Created,
+		&i.Updated,
+	)
+	return i, err
+}
+
+const getDetectedCampaign = `-- name: GetDetectedCampaign :one
+SELECT id, name, description, playbook, inputs, created, updated, feature_kind, feature_value
+FROM campaigns
+WHERE feature_kind = ?1
+  AND feature_value = ?2
+  AND datetime(updated) >= datetime(CAST(?3 AS TEXT))
+ORDER BY updated DESC
+LIMIT 1
+`
+
+type GetDetectedCampaignParams struct {
+	Kind  *string `json:"kind"`
+	Value *string `json:"value"`
+	Since string  `json:"since"`
+}
+
+func (q *ReadQueries) GetDetectedCampaign(ctx context.Context, arg GetDetectedCampaignParams) (Campaign, error) {
+	row := q.db.QueryRowContext(ctx, getDetectedCampaign, arg.Kind, arg.Value, arg.Since)
+	var i Campaign
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Description,
+		&i.Playbook,
+		&i.Inputs,
+		&i.Created,
+		&i.Updated,
+		&i.FeatureKind,
+		&i.FeatureValue,
+	)
+	return i, err
+}
+
+const getDetectionRule = `-- name: GetDetectionRule :one
+
+SELECT detection_rules.id, detection_rules.ticket, detection_rules.name, detection_rules.description, detection_rules.format, detection_rules.content, detection_rules.status, detection_rules.author, detection_rules.reviewer, detection_rules.deployed, detection_rules.created, detection_rules.updated,
+       (SELECT COUNT(*)
+        FROM detection_rule_tickets
+        WHERE detection_rule_tickets.rule = detection_rules.id)   AS ticket_count,
+       (SELECT COUNT(*)
+        FROM detection_rule_tickets
+                 JOIN tickets ON tickets.id = detection_rule_tickets.ticket
+        WHERE detection_rule_tickets.rule = detection_rules.id
+          AND tickets.open)                                       AS open_ticket_count
+FROM detection_rules
+WHERE detection_rules.id = ?1
+`
+
+type GetDetectionRuleRow struct {
+	ID              string     `json:"id"`
+	Ticket          string     `json:"ticket"`
+	Name            string     `json:"name"`
+	Description     string     `json:"description"`
+	Format          string     `json:"format"`
+	Content         string     `json:"content"`
+	Status          string     `json:"status"`
+	Author          *string    `json:"author"`
+	Reviewer        *string    `json:"reviewer"`
+	Deployed        *time.Time `json:"deployed"`
+	Created         time.Time  `json:"created"`
+	Updated         time.Time  `json:"updated"`
+	TicketCount     int64      `json:"ticket_count"`
+	OpenTicketCount int64      `json:"open_ticket_count"`
+}
+
+// ----------------------------------------------------------------
+func (q *ReadQueries) GetDetectionRule(ctx context.Context, id string) (GetDetectionRuleRow, error) {
+	row := q.db.QueryRowContext(ctx, getDetectionRule, id)
+	var i GetDetectionRuleRow
+	err := row.Scan(
+		&i.ID,
+		&i.Ticket,
+		&i.Name,
+		&i.Description,
+		&i.Format,
+		&i.Content,
+		&i.Status,
+		&i.Author,
+		&i.Reviewer,
+		&i.Deployed,
+		&i.Created,
+		&i.Updated,
+		&i.TicketCount,
+		&i.OpenTicketCount,
+	)
+	return i, err
+}
+
+const getDigest = `-- name: GetDigest :one
+
+SELECT user, frequency, last_sent, created, updated
+FROM digests
+WHERE user = ?1
+`
+
+// ----------------------------------------------------------------
+func (q *ReadQueries) GetDigest(ctx context.Context, user string) (Digest, error) {
+	row := q.db.QueryRowContext(ctx, getDigest, user)
+	var i Digest
+	err := row.Scan(
+		&i.User,
+		&i.Frequency,
+		&i.LastSent,
+		&i.Created,
+		&i.Updated,
+	)
+	return i, err
+}
+
+const getDocumentTemplate = `-- name: GetDocumentTemplate :one
+
+SELECT format, name, content, created, updated
+FROM document_templates
+WHERE format = ?1
+`
+
+// ----------------------------------------------------------------
+func (q *ReadQueries) GetDocumentTemplate(ctx context.Context, format string) (DocumentTemplate, error) {
+	row := q.db.QueryRowContext(ctx, getDocumentTemplate, format)
+	var i DocumentTemplate
+	err := row.Scan(
+		&i.Format,
+		&i.Name,
+		&i.Content,
+		&i.Created,
+		&i.Updated,
+	)
+	return i, err
+}
+
+const getEnrichment = `-- name: GetEnrichment :one
+
+SELECT enricher, value, result, expires, created
+FROM enrichment_cache
+WHERE enricher = ?1
+  AND value = ?2
+  AND expires > ?3
+`
+
+type GetEnrichmentParams struct {
+	Enricher string    `json:"enricher"`
+	Value    string    `json:"value"`
+	Now      time.Time `json:"now"`
+}
+
+// ----------------------------------------------------------------
+func (q *ReadQueries) GetEnrichment(ctx context.Context, arg GetEnrichmentParams) (EnrichmentCache, error) {
+	row := q.db.QueryRowContext(ctx, getEnrichment, arg.Enricher, arg.Value, arg.Now)
+	var i EnrichmentCache
+	err := row.Scan(
+		&i.Enricher,
+		&i.Value,
+		&i.Result,
+		&i.Expires,
+		&i.Created,
+	)
+	return i, err
+}
+
+const getEscalationPolicy = `-- name: GetEscalationPolicy :one
+
+SELECT id, name, "filter", steps, repeat, created, updated, pause_states
+FROM escalation_policies
+WHERE id = ?1
+`
+
+// ----------------------------------------------------------------
+func (q *ReadQueries) GetEscalationPolicy(ctx context.Context, id string) (EscalationPolicy, error) {
+	row := q.db.QueryRowContext(ctx, getEscalationPolicy, id)
+	var i EscalationPolicy
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Filter,
+		&i.Steps,
+		&i.Repeat,
+		&i.Created,
+		&i.Updated,
+		&i.PauseStates,
+	)
+	return i, err
+}
+
+const getFeature = `-- name: GetFeature :one
+
+SELECT "key"
+FROM features
+WHERE key = ?1
+`
+
+// ----------------------------------------------------------------
+func (q *ReadQueries) GetFeature(ctx context.Context, key string) (string, error) {
+	row := q.db.QueryRowContext(ctx, getFeature, key)
+	err := row.Scan(&key)
+	return key, err
+}
+
+const getFederationLink = `-- name: GetFederationLink :one
+SELECT id, ticket, peer, remote_ticket, remote_key, remote_type, remote_name, remote_open, share, created, updated
+FROM federation_links
+WHERE id = ?1
+`
+
+func (q *ReadQueries) GetFederationLink(ctx context.Context, id string) (FederationLink, error) {
+	row := q.db.QueryRowContext(ctx, getFederationLink, id)
+	var i FederationLink
+	err := row.Scan(
+		&i.ID,
+		&i.Ticket,
+		&i.Peer,
+		&i.RemoteTicket,
+		&i.RemoteKey,
+		&i.RemoteType,
+		&i.RemoteName,
+		&i.RemoteOpen,
+		&i.Share,
+		&i.Created,
+		&i.Updated,
+	)
+	return i, err
+}
+
+const getFederationPeer = `-- name: GetFederationPeer :one
+SELECT id, name, url, secret, max_tlp, created, updated
+FROM federation_peers
+WHERE id = ?1
+`
+
+func (q *ReadQueries) GetFederationPeer(ctx context.Context, id string) (FederationPeer, error) {
+	row := q.db.QueryRowContext(ctx, getFederationPeer, id)
+	var i FederationPeer
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Url,
+		&i.Secret,
+		&i.MaxTlp,
 		&i.Created,
 		&i.Updated,
 	)
 	return i, err
 }
 
-const getWebhook = `-- name: GetWebhook :one
-
-SELECT id, collection, destination, name, created, updated
-FROM webhooks
-WHERE id = ?1
+const getFile = `-- name: GetFile :one
+
+SELECT id, ticket, name, blob, size, created, updated, md5, sha1, sha256
+FROM files
+WHERE id = ?1
+`
+
+// ----------------------------------------------------------------
+func (q *ReadQueries) GetFile(ctx context.Context, id string) (File, error) {
+	row := q.db.QueryRowContext(ctx, getFile, id)
+	var i File
+	err := row.Scan(
+		&i.ID,
+		&i.Ticket,
+		&i.Name,
+		&i.Blob,
+		&i.Size,
+		&i.Created,
+		&i.Updated,
+		&i.Md5,
+		&i.Sha1,
+		&i.Sha256,
+	)
+	return i, err
+}
+
+const getGroup = `-- name: GetGroup :one
+
+SELECT id, name, permissions, created, updated
+FROM groups
+WHERE id = ?1
+`
+
+// ----------------------------------------------------------------
+func (q *ReadQueries) GetGroup(ctx context.Context, id string) (Group, error) {
+	row := q.db.QueryRowContext(ctx, getGroup, id)
+	var i Group
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Permissions,
+		&i.Created,
+		&i.Updated,
+	)
+	return i, err
+}
+
+const getInvitation = `-- name: GetInvitation :one
+SELECT id, email, name, "groups", token_hash, invited_by, expires, accepted_by, created, updated
+FROM invitations
+WHERE id = ?1
+`
+
+func (q *ReadQueries) GetInvitation(ctx context.Context, id string) (Invitation, error) {
+	row := q.db.QueryRowContext(ctx, getInvitation, id)
+	var i Invitation
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.Name,
+		&i.Groups,
+		&i.TokenHash,
+		&i.InvitedBy,
+		&i.Expires,
+		&i.AcceptedBy,
+		&i.Created,
+		&i.Updated,
+	)
+	return i, err
+}
+
+const getJournalEntry = `-- name: GetJournalEntry :one
+
+SELECT id, reaction, request, size, truncated, success, error, replayed, created
+FROM request_journal
+WHERE id = ?1
+  AND reaction = ?2
+`
+
+type GetJournalEntryParams struct {
+	ID       string `json:"id"`
+	Reaction string `json:"reaction"`
+}
+
+// ----------------------------------------------------------------
+func (q *ReadQueries) GetJournalEntry(ctx context.Context, arg GetJournalEntryParams) (RequestJournal, error) {
+	row := q.db.QueryRowContext(ctx, getJournalEntry, arg.ID, arg.Reaction)
+	var i RequestJournal
+	err := row.Scan(
+		&i.ID,
+		&i.Reaction,
+		&i.Request,
+		&i.Size,
+		&i.Truncated,
+		&i.Success,
+		&i.Error,
+		&i.Replayed,
+		&i.Created,
+	)
+	return i, err
+}
+
+const getLegalHold = `-- name: GetLegalHold :one
+
+SELECT id, collection, reason, created_by, created
+FROM legal_holds
+WHERE id = ?1
+`
+
+// ----------------------------------------------------------------
+func (q *ReadQueries) GetLegalHold(ctx context.Context, id string) (LegalHold, error) {
+	row := q.db.QueryRowContext(ctx, getLegalHold, id)
+	var i LegalHold
+	err := row.Scan(
+		&i.ID,
+		&i.Collection,
+		&i.Reason,
+		&i.CreatedBy,
+		&i.Created,
+	)
+	return i, err
+}
+
+const getLink = `-- name: GetLink :one
+
+SELECT id, ticket, name, url, created, updated
+FROM links
+WHERE id = ?1
+`
+
+// ----------------------------------------------------------------
+func (q *ReadQueries) GetLink(ctx context.Context, id string) (Link, error) {
+	row := q.db.QueryRowContext(ctx, getLink, id)
+	var i Link
+	err := row.Scan(
+		&i.ID,
+		&i.Ticket,
+		&i.Name,
+		&i.Url,
+		&i.Created,
+		&i.Updated,
+	)
+	return i, err
+}
+
+const getMaintenanceWindow = `-- name: GetMaintenanceWindow :one
+
+SELECT id, name, "filter", starts, ends, created, updated
+FROM maintenance_windows
+WHERE id = ?1
+`
+
+// ----------------------------------------------------------------
+func (q *ReadQueries) GetMaintenanceWindow(ctx context.Context, id string) (MaintenanceWindow, error) {
+	row := q.db.QueryRowContext(ctx, getMaintenanceWindow, id)
+	var i MaintenanceWindow
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Filter,
+		&i.Starts,
+		&i.Ends,
+		&i.Created,
+		&i.Updated,
+	)
+	return i, err
+}
+
+const getNotificationRule = `-- name: GetNotificationRule :one
+
+SELECT id, name, enabled, "filter", channel, target, throttle, dedup, created, updated
+FROM notification_rules
+WHERE id = ?1
+`
+
+// ----------------------------------------------------------------
+func (q *ReadQueries) GetNotificationRule(ctx context.Context, id string) (NotificationRule, error) {
+	row := q.db.QueryRowContext(ctx, getNotificationRule, id)
+	var i NotificationRule
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Enabled,
+		&i.Filter,
+		&i.Channel,
+		&i.Target,
+		&i.Throttle,
+		&i.Dedup,
+		&i.Created,
+		&i.Updated,
+	)
+	return i, err
+}
+
+const getPlatformError = `-- name: GetPlatformError :one
+SELECT platform_errors.fingerprint, platform_errors.ticket, platform_errors.occurrences, platform_errors.first_seen, platform_errors.last_seen, tickets.open AS ticket_open
+FROM platform_errors
+         JOIN tickets ON tickets.id = platform_errors.ticket
+WHERE fingerprint = ?1
+`
+
+type GetPlatformErrorRow struct {
+	Fingerprint string    `json:"fingerprint"`
+	Ticket      string    `json:"ticket"`
+	Occurrences int64     `json:"occurrences"`
+	FirstSeen   time.Time `json:"first_seen"`
+	LastSeen    time.Time `json:"last_seen"`
+	TicketOpen  bool      `json:"ticket_open"`
+}
+
+func (q *ReadQueries) GetPlatformError(ctx context.Context, fingerprint string) (GetPlatformErrorRow, error) {
+	row := q.db.QueryRowContext(ctx, getPlatformError, fingerprint)
+	var i GetPlatformErrorRow
+	err := row.Scan(
+		&i.Fingerprint,
+		&i.Ticket,
+		&i.Occurrences,
+		&i.FirstSeen,
+		&i.LastSeen,
+		&i.TicketOpen,
+	)
+	return i, err
+}
+
+const getPlaybook = `-- name: GetPlaybook :one
+
+SELECT id, name, description, inputs, tasks, created, updated
+FROM playbooks
+WHERE id = ?1
+`
+
+// ----------------------------------------------------------------
+func (q *ReadQueries) GetPlaybook(ctx context.Context, id string) (Playbook, error) {
+	row := q.db.QueryRowContext(ctx, getPlaybook, id)
+	var i Playbook
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Description,
+		&i.Inputs,
+		&i.Tasks,
+		&i.Created,
+		&i.Updated,
+	)
+	return i, err
+}
+
+const getQueue = `-- name: GetQueue :one
+SELECT id, name, description, "filter", created, updated
+FROM queues
+WHERE id = ?1
+`
+
+func (q *ReadQueries) GetQueue(ctx context.Context, id string) (Queue, error) {
+	row := q.db.QueryRowContext(ctx, getQueue, id)
+	var i Queue
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Description,
+		&i.Filter,
+		&i.Created,
+		&i.Updated,
+	)
+	return i, err
+}
+
+const getQueueByName = `-- name: GetQueueByName :one
+SELECT id, name, description, "filter", created, updated
+FROM queues
+WHERE name = ?1
+`
+
+func (q *ReadQueries) GetQueueByName(ctx context.Context, name string) (Queue, error) {
+	row := q.db.QueryRowContext(ctx, getQueueByName, name)
+	var i Queue
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Description,
+		&i.Filter,
+		&i.Created,
+		&i.Updated,
+	)
+	return i, err
+}
+
+const getReaction = `-- name: GetReaction :one
+
+SELECT id, name, "action", actiondata, "trigger", triggerdata, created, updated
+FROM reactions
+WHERE id = ?1
+`
+
+// ----------------------------------------------------------------
+func (q *ReadQueries) GetReaction(ctx context.Context, id string) (Reaction, error) {
+	row := q.db.QueryRowContext(ctx, getReaction, id)
+	var i Reaction
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Action,
+		&i.Actiondata,
+		&i.Trigger,
+		&i.Triggerdata,
+		&i.Created,
+		&i.Updated,
+	)
+	return i, err
+}
+
+const getReactionRunStats = `-- name: GetReactionRunStats :one
+SELECT COUNT(*)                                                     AS runs,
+       CAST(COALESCE(SUM(NOT success), 0) AS INTEGER)               AS failures,
+       CAST(COALESCE(AVG(duration_ms), 0) AS REAL)                  AS avg_duration_ms,
+       CAST(COALESCE(MAX(duration_ms), 0) AS INTEGER)               AS max_duration_ms,
+       CAST(COALESCE(SUM(cpu_ms), 0) AS INTEGER)                    AS total_cpu_ms,
+       CAST(COALESCE(AVG(cpu_ms), 0) AS REAL)                       AS avg_cpu_ms,
+       CAST(COALESCE(MAX(cpu_ms), 0) AS INTEGER)                    AS max_cpu_ms,
+       CAST(COALESCE(MAX(memory_peak), 0) AS INTEGER)               AS max_memory_peak
+FROM reaction_runs
+WHERE reaction = ?1
+`
+
+type GetReactionRunStatsRow struct {
+	Runs          int64   `json:"runs"`
+	Failures      int64   `json:"failures"`
+	AvgDurationMs float64 `json:"avg_duration_ms"`
+	MaxDurationMs int64   `json:"max_duration_ms"`
+	TotalCpuMs    int64   `json:"total_cpu_ms"`
+	AvgCpuMs      float64 `json:"avg_cpu_ms"`
+	MaxCpuMs      int64   `json:"max_cpu_ms"`
+	MaxMemoryPeak int64   `json:"max_memory_peak"`
+}
+
+func (q *ReadQueries) GetReactionRunStats(ctx context.Context, reaction string) (GetReactionRunStatsRow, error) {
+	row := q.db.QueryRowContext(ctx, getReactionRunStats, reaction)
+	var i GetReactionRunStatsRow
+	err := row.Scan(
+		&i.Runs,
+		&i.Failures,
+		&i.AvgDurationMs,
+		&i.MaxDurationMs,
+		&i.TotalCpuMs,
+		&i.AvgCpuMs,
+		&i.MaxCpuMs,
+		&i.MaxMemoryPeak,
+	)
+	return i, err
+}
+
+const getRedactionProfile = `-- name: GetRedactionProfile :one
+
+SELECT id, name, audience, fields, hide_files, hide_identities, created, updated
+FROM redaction_profiles
+WHERE id = ?1
+`
+
+// ----------------------------------------------------------------
+func (q *ReadQueries) GetRedactionProfile(ctx context.Context, id string) (RedactionProfile, error) {
+	row := q.db.QueryRowContext(ctx, getRedactionProfile, id)
+	var i RedactionProfile
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Audience,
+		&i.Fields,
+		&i.HideFiles,
+		&i.HideIdentities,
+		&i.Created,
+		&i.Updated,
+	)
+	return i, err
+}
+
+const getResponseTimes = `-- name: GetResponseTimes :one
+SELECT COUNT(*)                                                                                                 AS tickets,
+       COUNT(tickets.acknowledged)                                                                              AS acknowledged,
+       COUNT(tickets.resolved)                                                                                  AS resolved,
+       CAST(coalesce(AVG(unixepoch(tickets.acknowledged) - unixepoch(tickets.created)), 0) AS REAL)             AS mtta,
+       CAST(coalesce(AVG(unixepoch(tickets.resolved) - unixepoch(tickets.created)
+           - coalesce(ticket_escalations.paused, 0)), 0) AS REAL)                                               AS mttr,
+       COUNT(CASE WHEN tickets.reopen_count > 0 THEN 1 END)                                                     AS reopened,
+       CAST(coalesce(SUM(tickets.reopen_count), 0) AS INTEGER)                                                  AS reopens
+FROM tickets
+         LEFT JOIN ticket_escalations ON ticket_escalations.ticket = tickets.id
+WHERE (?1 IS NULL OR tickets.type = ?1)
+  AND datetime(tickets.created) >= datetime(CAST(?2 AS TEXT))
+`
+
+type GetResponseTimesParams struct {
+	Type  interface{} `json:"type"`
+	Since string      `json:"since"`
+}
+
+type GetResponseTimesRow struct {
+	Tickets      int64   `json:"tickets"`
+	Acknowledged int64   `json:"acknowledged"`
+	Resolved     int64   `json:"resolved"`
+	Mtta         float64 `json:"mtta"`
+	Mttr         float64 `json:"mttr"`
+	Reopened     int64   `json:"reopened"`
+	Reopens      int64   `json:"reopens"`
+}
+
+func (q *ReadQueries) GetResponseTimes(ctx context.Context, arg GetResponseTimesParams) (GetResponseTimesRow, error) {
+	row := q.db.QueryRowContext(ctx, getResponseTimes, arg.Type, arg.Since)
+	var i GetResponseTimesRow
+	err := row.Scan(
+		&i.Tickets,
+		&i.Acknowledged,
+		&i.Resolved,
+		&i.Mtta,
+		&i.Mttr,
+		&i.Reopened,
+		&i.Reopens,
+	)
+	return i, err
+}
+
+const getSidebar = `-- name: GetSidebar :many
+SELECT id, singular, plural, icon, count
+FROM sidebar
+`
+
+func (q *ReadQueries) GetSidebar(ctx context.Context) ([]Sidebar, error) {
+	rows, err := q.db.QueryContext(ctx, getSidebar)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Sidebar
+	for rows.Next() {
+		var i Sidebar
+		if err := rows.Scan(
+			&i.ID,
+			&i.Singular,
+			&i.Plural,
+			&i.Icon,
+			&i.Count,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getSlackThread = `-- name: GetSlackThread :one
+
+SELECT ticket, channel, thread_ts, created
+FROM slack_threads
+WHERE ticket = ?1
+`
+
+// ----------------------------------------------------------------
+func (q *ReadQueries) GetSlackThread(ctx context.Context, ticket string) (SlackThread, error) {
+	row := q.db.QueryRowContext(ctx, getSlackThread, ticket)
+	var i SlackThread
+	err := row.Scan(
+		&i.Ticket,
+		&i.Channel,
+		&i.ThreadTs,
+		&i.Created,
+	)
+	return i, err
+}
+
+const getSlackThreadByTS = `-- name: GetSlackThreadByTS :one
+SELECT ticket, channel, thread_ts, created
+FROM slack_threads
+WHERE channel = ?1
+  AND thread_ts = ?2
+`
+
+type GetSlackThreadByTSParams struct {
+	Channel  string `json:"channel"`
+	ThreadTs string `json:"thread_ts"`
+}
+
+func (q *ReadQueries) GetSlackThreadByTS(ctx context.Context, arg GetSlackThreadByTSParams) (SlackThread, error) {
+	row := q.db.QueryRowContext(ctx, getSlackThreadByTS, arg.Channel, arg.ThreadTs)
+	var i SlackThread
+	err := row.Scan(
+		&i.Ticket,
+		&i.Channel,
+		&i.ThreadTs,
+		&i.Created,
+	)
+	return i, err
+}
+
+const getTask = `-- name: GetTask :one
+
+SELECT tasks.id, tasks.ticket, tasks.owner, tasks.name, tasks.open, tasks.created, tasks.updated, users.name as owner_name, tickets.name as ticket_name, tickets.type as ticket_type
+FROM tasks
+         LEFT JOIN users ON users.id = tasks.owner
+         LEFT JOIN tickets ON tickets.id = tasks.ticket
+WHERE tasks.id = ?1
+`
+
+type GetTaskRow struct {
+	ID         string    `json:"id"`
+	Ticket     string    `json:"ticket"`
+	Owner      *string   `json:"owner"`
+	Name       string    `json:"name"`
+	Open       bool      `json:"open"`
+	Created    time.Time `json:"created"`
+	Updated    time.Time `json:"updated"`
+	OwnerName  *string   `json:"owner_name"`
+	TicketName *string   `json:"ticket_name"`
+	TicketType *string   `json:"ticket_type"`
+}
+
+// ----------------------------------------------------------------
+func (q *ReadQueries) GetTask(ctx context.Context, id string) (GetTaskRow, error) {
+	row := q.db.QueryRowContext(ctx, getTask, id)
+	var i GetTaskRow
+	err := row.Scan(
+		&i.ID,
+		&i.Ticket,
+		&i.Owner,
+		&i.Name,
+		&i.Open,
+		&i.Created,
+		&i.Updated,
+		&i.OwnerName,
+		&i.TicketName,
+		&i.TicketType,
+	)
+	return i, err
+}
+
+const getTaskOutput = `-- name: GetTaskOutput :one
+
+SELECT task, ticket, name, output, created, updated
+FROM task_outputs
+WHERE task = ?1
+`
+
+// ----------------------------------------------------------------
+func (q *ReadQueries) GetTaskOutput(ctx context.Context, task string) (TaskOutput, error) {
+	row := q.db.QueryRowContext(ctx, getTaskOutput, task)
+	var i TaskOutput
+	err := row.Scan(
+		&i.Task,
+		&i.Ticket,
+		&i.Name,
+		&i.Output,
+		&i.Created,
+		&i.Updated,
+	)
+	return i, err
+}
+
+const getTaskTimer = `-- name: GetTaskTimer :one
+SELECT task, due, "action", escalation_name, escalation_owner, result, escalation_task, fired, created, updated
+FROM task_timers
+WHERE task = ?1
+`
+
+func (q *ReadQueries) GetTaskTimer(ctx context.Context, task string) (TaskTimer, error) {
+	row := q.db.QueryRowContext(ctx, getTaskTimer, task)
+	var i TaskTimer
+	err := row.Scan(
+		&i.Task,
+		&i.Due,
+		&i.Action,
+		&i.EscalationName,
+		&i.EscalationOwner,
+		&i.Result,
+		&i.EscalationTask,
+		&i.Fired,
+		&i.Created,
+		&i.Updated,
+	)
+	return i, err
+}
+
+const getTicketEscalation = `-- name: GetTicketEscalation :one
+SELECT ticket, policy, step, next_at, acknowledged_by, acknowledged_at, created, updated, paused_since, paused
+FROM ticket_escalations
+WHERE ticket = ?1
+`
+
+func (q *ReadQueries) GetTicketEscalation(ctx context.Context, ticket string) (TicketEscalation, error) {
+	row := q.db.QueryRowContext(ctx, getTicketEscalation, ticket)
+	var i TicketEscalation
+	err := row.Scan(
+		&i.Ticket,
+		&i.Policy,
+		&i.Step,
+		&i.NextAt,
+		&i.AcknowledgedBy,
+		&i.AcknowledgedAt,
+		&i.Created,
+		&i.Updated,
+		&i.PausedSince,
+		&i.Paused,
+	)
+	return i, err
+}
+
+const getTicketKey = `-- name: GetTicketKey :one
+SELECT key
+FROM ticket_keys
+WHERE ticket = ?1
+`
+
+func (q *ReadQueries) GetTicketKey(ctx context.Context, ticket string) (string, error) {
+	row := q.db.QueryRowContext(ctx, getTicketKey, ticket)
+	var key string
+	err := row.Scan(&key)
+	return key, err
+}
+
+const getTicketLegalHold = `-- name: GetTicketLegalHold :one
+SELECT id, collection, reason, created_by, created
+FROM legal_holds
+WHERE legal_holds.id = ?1
+   OR legal_holds.id IN (SELECT files.id FROM files WHERE files.ticket = ?1)
+ORDER BY legal_holds.created
+LIMIT 1
+`
+
+func (q *ReadQueries) GetTicketLegalHold(ctx context.Context, ticket string) (LegalHold, error) {
+	row := q.db.QueryRowContext(ctx, getTicketLegalHold, ticket)
+	var i LegalHold
+	err := row.Scan(
+		&i.ID,
+		&i.Collection,
+		&i.Reason,
+		&i.CreatedBy,
+		&i.Created,
+	)
+	return i, err
+}
+
+const getTimeline = `-- name: GetTimeline :one
+
+SELECT id, ticket, message, time, created, updated
+FROM timeline
+WHERE id = ?1
+`
+
+// ----------------------------------------------------------------
+func (q *ReadQueries) GetTimeline(ctx context.Context, id string) (Timeline, error) {
+	row := q.db.QueryRowContext(ctx, getTimeline, id)
+	var i Timeline
+	err := row.Scan(
+		&i.ID,
+		&i.Ticket,
+		&i.Message,
+		&i.Time,
+		&i.Created,
+		&i.Updated,
+	)
+	return i, err
+}
+
+const getType = `-- name: GetType :one
+
+SELECT id, icon, singular, plural, schema, created, updated, key_prefix, metrics
+FROM types
+WHERE id = ?1
+`
+
+// ----------------------------------------------------------------
+func (q *ReadQueries) GetType(ctx context.Context, id string) (Type, error) {
+	row := q.db.QueryRowContext(ctx, getType, id)
+	var i Type
+	err := row.Scan(
+		&i.ID,
+		&i.Icon,
+		&i.Singular,
+		&i.Plural,
+		&i.Schema,
+		&i.Created,
+		&i.Updated,
+		&i.KeyPrefix,
+		&i.Metrics,
+	)
+	return i, err
+}
+
+const getUser = `-- name: GetUser :one
+
+SELECT id, username, passwordhash, tokenkey, active, name, email, avatar, lastresetsentat, lastverificationsentat, created, updated
+FROM users
+WHERE id = ?1
+`
+
+// ----------------------------------------------------------------
+func (q *ReadQueries) GetUser(ctx context.Context, id string) (User, error) {
+	row := q.db.QueryRowContext(ctx, getUser, id)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.Passwordhash,
+		&i.Tokenkey,
+		&i.Active,
+		&i.Name,
+		&i.Email,
+		&i.Avatar,
+		&i.Lastresetsentat,
+		&i.Lastverificationsentat,
+		&i.Created,
+		&i.Updated,
+	)
+	return i, err
+}
+
+const getWebhook = `-- name: GetWebhook :one
+
+SELECT id, collection, destination, name, created, updated
+FROM webhooks
+WHERE id = ?1
+`
+
+// ----------------------------------------------------------------
+func (q *ReadQueries) GetWebhook(ctx context.Context, id string) (Webhook, error) {
+	row := q.db.QueryRowContext(ctx, getWebhook, id)
+	var i Webhook
+	err := row.Scan(
+		&i.ID,
+		&i.Collection,
+		&i.Destination,
+		&i.Name,
+		&i.Created,
+		&i.Updated,
+	)
+	return i, err
+}
+
+const hasTicketGrant = `-- name: HasTicketGrant :one
+SELECT EXISTS (SELECT 1
+               FROM ticket_grants
+               WHERE ticket = ?1
+                 AND user = ?2)
+`
+
+type HasTicketGrantParams struct {
+	Ticket string `json:"ticket"`
+	User   string `json:"user"`
+}
+
+func (q *ReadQueries) HasTicketGrant(ctx context.Context, arg HasTicketGrantParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, hasTicketGrant, arg.Ticket, arg.User)
+	var column_1 int64
+	err := row.Scan(&column_1)
+	return column_1, err
+}
+
+const invitationByToken = `-- name: InvitationByToken :one
+SELECT id, email, name, "groups", token_hash, invited_by, expires, accepted_by, created, updated
+FROM invitations
+WHERE token_hash = ?1
+`
+
+func (q *ReadQueries) InvitationByToken(ctx context.Context, tokenHash string) (Invitation, error) {
+	row := q.db.QueryRowContext(ctx, invitationByToken, tokenHash)
+	var i Invitation
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.Name,
+		&i.Groups,
+		&i.TokenHash,
+		&i.InvitedBy,
+		&i.Expires,
+		&i.AcceptedBy,
+		&i.Created,
+		&i.Updated,
+	)
+	return i, err
+}
+
+const isPlaybookTask = `-- name: IsPlaybookTask :one
+SELECT EXISTS (SELECT 1
+               FROM playbook_run_tasks
+                        JOIN playbook_runs ON playbook_runs.id = playbook_run_tasks.run
+               WHERE playbook_runs.ticket = ?1
+                 AND playbook_run_tasks.task = ?2) AS is_playbook_task
+`
+
+type IsPlaybookTaskParams struct {
+	Ticket string `json:"ticket"`
+	Task   string `json:"task"`
+}
+
+func (q *ReadQueries) IsPlaybookTask(ctx context.Context, arg IsPlaybookTaskParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, isPlaybookTask, arg.Ticket, arg.Task)
+	var is_playbook_task int64
+	err := row.Scan(&is_playbook_task)
+	return is_playbook_task, err
+}
+
+const lastArtifactSighting = `-- name: LastArtifactSighting :one
+
+SELECT id, kind, value, ticket, source, reference, seen
+FROM artifact_sightings
+WHERE kind = ?1
+  AND value = ?2
+ORDER BY seen DESC, rowid DESC
+LIMIT 1
+`
+
+type LastArtifactSightingParams struct {
+	Kind  string `json:"kind"`
+	Value string `json:"value"`
+}
+
+// ----------------------------------------------------------------
+func (q *ReadQueries) LastArtifactSighting(ctx context.Context, arg LastArtifactSightingParams) (ArtifactSighting, error) {
+	row := q.db.QueryRowContext(ctx, lastArtifactSighting, arg.Kind, arg.Value)
+	var i ArtifactSighting
+	err := row.Scan(
+		&i.ID,
+		&i.Kind,
+		&i.Value,
+		&i.Ticket,
+		&i.Source,
+		&i.Reference,
+		&i.Seen,
+	)
+	return i, err
+}
+
+const listAPIQuotas = `-- name: ListAPIQuotas :many
+SELECT user, client, daily_limit, created, updated
+FROM api_quotas
+ORDER BY user, client
+`
+
+func (q *ReadQueries) ListAPIQuotas(ctx context.Context) ([]ApiQuota, error) {
+	rows, err := q.db.QueryContext(ctx, listAPIQuotas)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ApiQuota
+	for rows.Next() {
+		var i ApiQuota
+		if err := rows.Scan(
+			&i.User,
+			&i.Client,
+			&i.DailyLimit,
+			&i.Created,
+			&i.Updated,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAPIUsage = `-- name: ListAPIUsage :many
+SELECT api_usage.user,
+       api_usage.client,
+       users.username,
+       users.name,
+       CAST(SUM(api_usage.requests) AS INTEGER)                    AS requests,
+       CAST(COALESCE((SELECT today.requests
+                      FROM api_usage AS today
+                      WHERE today.user = api_usage.user
+                        AND today.client = api_usage.client
+                        AND today.day = ?1), 0) AS INTEGER) AS today,
+       api_quotas.daily_limit
+FROM api_usage
+         JOIN users ON users.id = api_usage.user
+         LEFT JOIN api_quotas ON api_quotas.user = api_usage.user AND api_quotas.client = api_usage.client
+WHERE api_usage.day >= ?2
+  AND api_usage.day <= ?3
+GROUP BY api_usage.user, api_usage.client
+ORDER BY requests DESC, api_usage.user, api_usage.client
+`
+
+type ListAPIUsageParams struct {
+	Today string `json:"today"`
+	Since string `json:"since"`
+	Until string `json:"until"`
+}
+
+type ListAPIUsageRow struct {
+	User       string  `json:"user"`
+	Client     string  `json:"client"`
+	Username   string  `json:"username"`
+	Name       *string `json:"name"`
+	Requests   int64   `json:"requests"`
+	Today      int64   `json:"today"`
+	DailyLimit *int64  `json:"daily_limit"`
+}
+
+func (q *ReadQueries) ListAPIUsage(ctx context.Context, arg ListAPIUsageParams) ([]ListAPIUsageRow, error) {
+	rows, err := q.db.QueryContext(ctx, listAPIUsage, arg.Today, arg.Since, arg.Until)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListAPIUsageRow
+	for rows.Next() {
+		var i ListAPIUsageRow
+		if err := rows.Scan(
+			&i.User,
+			&i.Client,
+			&i.Username,
+			&i.Name,
+			&i.Requests,
+			&i.Today,
+			&i.DailyLimit,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listActiveDigests = `-- name: ListActiveDigests :many
+SELECT digests.user, digests.frequency, digests.last_sent, digests.created, digests.updated, users.username, users.name, users.email
+FROM digests
+         JOIN users ON users.id = digests.user
+WHERE digests.frequency != 'off'
+  AND users.active = TRUE
+  AND users.email IS NOT NULL
+  AND users.email != ''
+`
+
+type ListActiveDigestsRow struct {
+	User      string     `json:"user"`
+	Frequency string     `json:"frequency"`
+	LastSent  *time.Time `json:"last_sent"`
+	Created   time.Time  `json:"created"`
+	Updated   time.Time  `json:"updated"`
+	Username  string     `json:"username"`
+	Name      *string    `json:"name"`
+	Email     *string    `json:"email"`
+}
+
+func (q *ReadQueries) ListActiveDigests(ctx context.Context) ([]ListActiveDigestsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listActiveDigests)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListActiveDigestsRow
+	for rows.Next() {
+		var i ListActiveDigestsRow
+		if err := rows.Scan(
+			&i.User,
+			&i.Frequency,
+			&i.LastSent,
+			&i.Created,
+			&i.Updated,
+			&i.Username,
+			&i.Name,
+			&i.Email,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAlertMappings = `-- name: ListAlertMappings :many
+SELECT alert_mappings.id, alert_mappings.name, alert_mappings.source, alert_mappings.template, alert_mappings.template_version, alert_mappings.fields, alert_mappings.created, alert_mappings.updated, COUNT(*) OVER () as total_count
+FROM alert_mappings
+ORDER BY name
+LIMIT ?2 OFFSET ?1
+`
+
+type ListAlertMappingsParams struct {
+	Offset int64 `json:"offset"`
+	Limit  int64 `json:"limit"`
+}
+
+type ListAlertMappingsRow struct {
+	ID              string    `json:"id"`
+	Name            string    `json:"name"`
+	Source          string    `json:"source"`
+	Template        string    `json:"template"`
+	TemplateVersion int64     `json:"template_version"`
+	Fields          []byte    `json:"fields"`
+	Created         time.Time `json:"created"`
+	Updated         time.Time `json:"updated"`
+	TotalCount      int64     `json:"total_count"`
+}
+
+func (q *ReadQueries) ListAlertMappings(ctx context.Context, arg ListAlertMappingsParams) ([]ListAlertMappingsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listAlertMappings, arg.Offset, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListAlertMappingsRow
+	for rows.Next() {
+		var i ListAlertMappingsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Source,
+			&i.Template,
+			&i.TemplateVersion,
+			&i.Fields,
+			&i.Created,
+			&i.Updated,
+			&i.TotalCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAlerts = `-- name: ListAlerts :many
+
+SELECT alerts.id, alerts.source, alerts.name, alerts.description, alerts.severity, alerts.data, alerts.status, alerts.ticket, alerts.created, alerts.updated, alerts.maintenance, COUNT(*) OVER () as total_count
+FROM alerts
+WHERE (?1 IS NULL OR status = ?1)
+  AND (?2 IS NULL OR source = ?2)
+  AND (?3 IS NULL OR ticket = ?3)
+ORDER BY created DESC
+LIMIT ?5 OFFSET ?4
+`
+
+type ListAlertsParams struct {
+	Status interface{} `json:"status"`
+	Source interface{} `json:"source"`
+	Ticket interface{} `json:"ticket"`
+	Offset int64       `json:"offset"`
+	Limit  int64       `json:"limit"`
+}
+
+type ListAlertsRow struct {
+	ID          string    `json:"id"`
+	Source      string    `json:"source"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Severity    string    `json:"severity"`
+	Data        []byte    `json:"data"`
+	Status      string    `json:"status"`
+	Ticket      *string   `json:"ticket"`
+	Created     time.Time `json:"created"`
+	Updated     time.Time `json:"updated"`
+	Maintenance *string   `json:"maintenance"`
+	TotalCount  int64     `json:"total_count"`
+}
+
+// ----------------------------------------------------------------
+func (q *ReadQueries) ListAlerts(ctx context.Context, arg ListAlertsParams) ([]ListAlertsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listAlerts,
+		arg.Status,
+		arg.Source,
+		arg.Ticket,
+		arg.Offset,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListAlertsRow
+	for rows.Next() {
+		var i ListAlertsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Source,
+			&i.Name,
+			&i.Description,
+			&i.Severity,
+			&i.Data,
+			&i.Status,
+			&i.Ticket,
+			&i.Created,
+			&i.Updated,
+			&i.Maintenance,
+			&i.TotalCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAllReactionRuns = `-- name: ListAllReactionRuns :many
+SELECT reaction_runs.id, reaction_runs.reaction, reaction_runs.success, reaction_runs.duration_ms, reaction_runs.cpu_ms, reaction_runs.memory_peak, reaction_runs.created, reactions.name AS reaction_name, reactions.action, reactions.trigger
+FROM reaction_runs
+         JOIN reactions ON reactions.id = reaction_runs.reaction
+ORDER BY reaction_runs.created, reaction_runs.id
+LIMIT ?2 OFFSET ?1
+`
+
+type ListAllReactionRunsParams struct {
+	Offset int64 `json:"offset"`
+	Limit  int64 `json:"limit"`
+}
+
+type ListAllReactionRunsRow struct {
+	ID           string    `json:"id"`
+	Reaction     string    `json:"reaction"`
+	Success      bool      `json:"success"`
+	DurationMs   int64     `json:"duration_ms"`
+	CpuMs        int64     `json:"cpu_ms"`
+	MemoryPeak   int64     `json:"memory_peak"`
+	Created      time.Time `json:"created"`
+	ReactionName string    `json:"reaction_name"`
+	Action       string    `json:"action"`
+	Trigger      string    `json:"trigger"`
+}
+
+func (q *ReadQueries) ListAllReactionRuns(ctx context.Context, arg ListAllReactionRunsParams) ([]ListAllReactionRunsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listAllReactionRuns, arg.Offset, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListAllReactionRunsRow
+	for rows.Next() {
+		var i ListAllReactionRunsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Reaction,
+			&i.Success,
+			&i.DurationMs,
+			&i.CpuMs,
+			&i.MemoryPeak,
+			&i.Created,
+			&i.ReactionName,
+			&i.Action,
+			&i.Trigger,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAnalystEffort = `-- name: ListAnalystEffort :many
+SELECT work_entries.user,
+       users.name                                  AS user_name,
+       CAST(SUM(work_entries.duration) AS INTEGER) AS duration,
+       COUNT(DISTINCT work_entries.ticket)         AS tickets
+FROM work_entries
+         JOIN tickets ON tickets.id = work_entries.ticket
+         LEFT JOIN users ON users.id = work_entries.user
+WHERE work_entries.duration IS NOT NULL
+  AND (?1 IS NULL OR tickets.type = ?1)
+  AND datetime(work_entries.started) >= datetime(CAST(?2 AS TEXT))
+  AND datetime(work_entries.started) < datetime(CAST(?3 AS TEXT))
+GROUP BY work_entries.user
+ORDER BY duration DESC, work_entries.user
+`
+
+type ListAnalystEffortParams struct {
+	Type  interface{} `json:"type"`
+	Since string      `json:"since"`
+	Until string      `json:"until"`
+}
+
+type ListAnalystEffortRow struct {
+	User     string  `json:"user"`
+	UserName *string `json:"user_name"`
+	Duration int64   `json:"duration"`
+	Tickets  int64   `json:"tickets"`
+}
+
+func (q *ReadQueries) ListAnalystEffort(ctx context.Context, arg ListAnalystEffortParams) ([]ListAnalystEffortRow, error) {
+	rows, err := q.db.QueryContext(ctx, listAnalystEffort, arg.Type, arg.Since, arg.Until)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListAnalystEffortRow
+	for rows.Next() {
+		var i ListAnalystEffortRow
+		if err := rows.Scan(
+			&i.User,
+			&i.UserName,
+			&i.Duration,
+			&i.Tickets,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAnnouncementReceipts = `-- name: ListAnnouncementReceipts :many
+SELECT users.id AS user,
+       users.name,
+       users.username,
+       announcement_receipts.read,
+       announcement_receipts.acknowledged
+FROM users
+         JOIN announcements ON announcements.id = ?1
+         LEFT JOIN announcement_receipts
+                   ON announcement_receipts.announcement = announcements.id AND announcement_receipts.user = users.id
+WHERE users.id != 'system'
+  AND users.active
+  AND (?2 IS NULL OR CASE
+                                            WHEN announcements.require_ack
+                                                THEN announcement_receipts.acknowledged IS NULL
+                                            ELSE announcement_receipts.read IS NULL END = ?2)
+ORDER BY users.username
+`
+
+type ListAnnouncementReceiptsParams struct {
+	Announcement string      `json:"announcement"`
+	Pending      interface{} `json:"pending"`
+}
+
+type ListAnnouncementReceiptsRow struct {
+	User         string     `json:"user"`
+	Name         *string    `json:"name"`
+	Username     string     `json:"username"`
+	Read         *time.Time `json:"read"`
+	Acknowledged *time.Time `json:"acknowledged"`
+}
+
+func (q *ReadQueries) ListAnnouncementReceipts(ctx context.Context, arg ListAnnouncementReceiptsParams) ([]ListAnnouncementReceiptsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listAnnouncementReceipts, arg.Announcement, arg.Pending)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListAnnouncementReceiptsRow
+	for rows.Next() {
+		var i ListAnnouncementReceiptsRow
+		if err := rows.Scan(
+			&i.User,
+			&i.Name,
+			&i.Username,
+			&i.Read,
+			&i.Acknowledged,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAnnouncements = `-- name: ListAnnouncements :many
+SELECT announcements.id, announcements.title, announcements.message, announcements.severity, announcements.require_ack, announcements.author, announcements.expires, announcements.created, announcements.updated,
+       announcement_receipts.read,
+       announcement_receipts.acknowledged,
+       (SELECT COUNT(*)
+        FROM announcement_receipts AS receipts
+        WHERE receipts.announcement = announcements.id
+          AND receipts.acknowledged IS NOT NULL) AS acknowledged_count,
+       COUNT(*) OVER ()                         AS total_count
+FROM announcements
+         LEFT JOIN announcement_receipts
+                   ON announcement_receipts.announcement = announcements.id AND announcement_receipts.user = ?1
+WHERE (?2 IS NULL OR announcements.expires IS NULL OR announcements.expires > ?2)
+  AND (?3 IS NULL OR
+       (announcements.require_ack AND announcement_receipts.acknowledged IS NULL) = ?3)
+ORDER BY announcements.created DESC
+LIMIT ?5 OFFSET ?4
+`
+
+type ListAnnouncementsParams struct {
+	User     string      `json:"user"`
+	ActiveAt interface{} `json:"active_at"`
+	Pending  interface{} `json:"pending"`
+	Offset   int64       `json:"offset"`
+	Limit    int64       `json:"limit"`
+}
+
+type ListAnnouncementsRow struct {
+	ID                string     `json:"id"`
+	Title             string     `json:"title"`
+	Message           string     `json:"message"`
+	Severity          string     `json:"severity"`
+	RequireAck        bool       `json:"require_ack"`
+	Author            *string    `json:"author"`
+	Expires           *time.Time `json:"expires"`
+	Created           time.Time  `json:"created"`
+	Updated           time.Time  `json:"updated"`
+	Read              *time.Time `json:"read"`
+	Acknowledged      *time.Time `json:"acknowledged"`
+	AcknowledgedCount int64      `json:"acknowledged_count"`
+	TotalCount        int64      `json:"total_count"`
+}
+
+func (q *ReadQueries) ListAnnouncements(ctx context.Context, arg ListAnnouncementsParams) ([]ListAnnouncementsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listAnnouncements,
+		arg.User,
+		arg.ActiveAt,
+		arg.Pending,
+		arg.Offset,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListAnnouncementsRow
+	for rows.Next() {
+		var i ListAnnouncementsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.Message,
+			&i.Severity,
+			&i.RequireAck,
+			&i.Author,
+			&i.Expires,
+			&i.Created,
+			&i.Updated,
+			&i.Read,
+			&i.Acknowledged,
+			&i.AcknowledgedCount,
+			&i.TotalCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listApproachingTaskTimers = `-- name: ListApproachingTaskTimers :many
+SELECT task_timers.due,
+       tasks.name   AS task_name,
+       tickets.id   AS ticket,
+       tickets.name AS ticket_name,
+       tickets.type AS ticket_type
+FROM task_timers
+         JOIN tasks ON tasks.id = task_timers.task
+         JOIN tickets ON tickets.id = tasks.ticket
+WHERE tasks.open = TRUE
+  AND task_timers.fired IS NULL
+  AND (tasks.owner = ?1 OR tickets.owner = ?1)
+  AND datetime(task_timers.due) <= datetime(CAST(?2 AS TEXT))
+ORDER BY task_timers.due
+LIMIT ?3
+`
+
+type ListApproachingTaskTimersParams struct {
+	User  *string `json:"user"`
+	Until string  `json:"until"`
+	Limit int64   `json:"limit"`
+}
+
+type ListApproachingTaskTimersRow struct {
+	Due        time.Time `json:"due"`
+	TaskName   string    `json:"task_name"`
+	Ticket     string    `json:"ticket"`
+	TicketName string    `json:"ticket_name"`
+	TicketType string    `json:"ticket_type"`
+}
+
+func (q *ReadQueries) ListApproachingTaskTimers(ctx context.Context, arg ListApproachingTaskTimersParams) ([]ListApproachingTaskTimersRow, error) {
+	rows, err := q.db.QueryContext(ctx, listApproachingTaskTimers, arg.User, arg.Until, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListApproachingTaskTimersRow
+	for rows.Next() {
+		var i ListApproachingTaskTimersRow
+		if err := rows.Scan(
+			&i.Due,
+			&i.TaskName,
+			&i.Ticket,
+			&i.TicketName,
+			&i.TicketType,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listArtifactSightings = `-- name: ListArtifactSightings :many
+
+SELECT id, kind, value, ticket, source, reference, seen
+FROM artifact_sightings
+WHERE kind = ?1
+  AND value = ?2
+ORDER BY seen DESC, rowid DESC
+LIMIT ?4 OFFSET ?3
+`
+
+type ListArtifactSightingsParams struct {
+	Kind   string `json:"kind"`
+	Value  string `json:"value"`
+	Offset int64  `json:"offset"`
+	Limit  int64  `json:"limit"`
+}
+
+// ----------------------------------------------------------------
+func (q *ReadQueries) ListArtifactSightings(ctx context.Context, arg ListArtifactSightingsParams) ([]ArtifactSighting, error) {
+	rows, err := q.db.QueryContext(ctx, listArtifactSightings,
+		arg.Kind,
+		arg.Value,
+		arg.Offset,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ArtifactSighting
+	for rows.Next() {
+		var i ArtifactSighting
+		if err := rows.Scan(
+			&i.ID,
+			&i.Kind,
+			&i.Value,
+			&i.Ticket,
+			&i.Source,
+			&i.Reference,
+			&i.Seen,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAutoCloseRules = `-- name: ListAutoCloseRules :many
+
+SELECT auto_close_rules.id, auto_close_rules.name, auto_close_rules.enabled, auto_close_rules."filter", auto_close_rules.inactive_days, auto_close_rules.warning_days, auto_close_rules.resolution, auto_close_rules.created, auto_close_rules.updated, COUNT(*) OVER () as total_count
+FROM auto_close_rules
+ORDER BY name
+LIMIT ?2 OFFSET ?1
+`
+
+type ListAutoCloseRulesParams struct {
+	Offset int64 `json:"offset"`
+	Limit  int64 `json:"limit"`
+}
+
+type ListAutoCloseRulesRow struct {
+	ID           string    `json:"id"`
+	Name         string    `json:"name"`
+	Enabled      bool      `json:"enabled"`
+	Filter       []byte    `json:"filter"`
+	InactiveDays int64     `json:"inactive_days"`
+	WarningDays  int64     `json:"warning_days"`
+	Resolution   string    `json:"resolution"`
+	Created      time.Time `json:"created"`
+	Updated      time.Time `json:"updated"`
+	TotalCount   int64     `json:"total_count"`
+}
+
+// ----------------------------------------------------------------
+func (q *ReadQueries) ListAutoCloseRules(ctx context.Context, arg ListAutoCloseRulesParams) ([]ListAutoCloseRulesRow, error) {
+	rows, err := q.db.QueryContext(ctx, listAutoCloseRules, arg.Offset, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListAutoCloseRulesRow
+	for rows.Next() {
+		var i ListAutoCloseRulesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Enabled,
+			&i.Filter,
+			&i.InactiveDays,
+			&i.WarningDays,
+			&i.Resolution,
+			&i.Created,
+			&i.Updated,
+			&i.TotalCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAutomationUsage = `-- name: ListAutomationUsage :many
+SELECT CAST(coalesce(json_extract(tickets.state, '$.customer'), '') AS TEXT) AS customer,
+       CAST(strftime('%Y-%m', playbook_runs.created) AS TEXT)               AS month,
+       COUNT(*)                                                             AS runs
+FROM playbook_runs
+         JOIN tickets ON tickets.id = playbook_runs.ticket
+WHERE datetime(playbook_runs.created) >= datetime(CAST(?1 AS TEXT))
+  AND datetime(playbook_runs.created) < datetime(CAST(?2 AS TEXT))
+GROUP BY customer, month
+`
+
+type ListAutomationUsageParams struct {
+	Since string `json:"since"`
+	Until string `json:"until"`
+}
+
+type ListAutomationUsageRow struct {
+	Customer string `json:"customer"`
+	Month    string `json:"month"`
+	Runs     int64  `json:"runs"`
+}
+
+func (q *ReadQueries) ListAutomationUsage(ctx context.Context, arg ListAutomationUsageParams) ([]ListAutomationUsageRow, error) {
+	rows, err := q.db.QueryContext(ctx, listAutomationUsage, arg.Since, arg.Until)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListAutomationUsageRow
+	for rows.Next() {
+		var i ListAutomationUsageRow
+		if err := rows.Scan(&i.Customer, &i.Month, &i.Runs); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listCVETickets = `-- name: ListCVETickets :many
+SELECT ticket_cves.ticket,
+       tickets.name                        AS ticket_name,
+       tickets.type                        AS ticket_type,
+       tickets.open                        AS ticket_open,
+       ticket_cves.cve,
+       cves.cvss_score,
+       cves.cvss_severity,
+       known_exploited.cve IS NOT NULL     AS kev,
+       ticket_cves.created,
+       COUNT(*) OVER ()                    AS total_count
+FROM ticket_cves
+         JOIN tickets ON tickets.id = ticket_cves.ticket
+         JOIN cves ON cves.id = ticket_cves.cve
+         LEFT JOIN known_exploited ON known_exploited.cve = cves.id
+WHERE (?1 IS NULL OR ticket_cves.cve = ?1)
+  AND (?2 IS NULL OR tickets.open = ?2)
+  AND (?3 IS NULL OR (known_exploited.cve IS NOT NULL) = ?3)
+  AND (?4 IS NULL OR cves.cvss_score >= ?4)
+ORDER BY kev DESC, cves.cvss_score IS NULL, cves.cvss_score DESC, tickets.created DESC
+LIMIT ?6 OFFSET ?5
+`
+
+type ListCVETicketsParams struct {
+	Cve      interface{} `json:"cve"`
+	Open     interface{} `json:"open"`
+	Kev      interface{} `json:"kev"`
+	MinScore interface{} `json:"min_score"`
+	Offset   int64       `json:"offset"`
+	Limit    int64       `json:"limit"`
+}
+
+type ListCVETicketsRow struct {
+	Ticket       string    `json:"ticket"`
+	TicketName   string    `json:"ticket_name"`
+	TicketType   string    `json:"ticket_type"`
+	TicketOpen   bool      `json:"ticket_open"`
+	Cve          string    `json:"cve"`
+	CvssScore    *float64  `json:"cvss_score"`
+	CvssSeverity *string   `json:"cvss_severity"`
+	Kev          bool      `json:"kev"`
+	Created      time.Time `json:"created"`
+	TotalCount   int64     `json:"total_count"`
+}
+
+func (q *ReadQueries) ListCVETickets(ctx context.Context, arg ListCVETicketsParams) ([]ListCVETicketsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listCVETickets,
+		arg.Cve,
+		arg.Open,
+		arg.Kev,
+		arg.MinScore,
+		arg.Offset,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListCVETicketsRow
+	for rows.Next() {
+		var i ListCVETicketsRow
+		if err := rows.Scan(
+			&i.Ticket,
+			&i.TicketName,
+			&i.TicketType,
+			&i.TicketOpen,
+			&i.Cve,
+			&i.CvssScore,
+			&i.CvssSeverity,
+			&i.Kev,
+			&i.Created,
+			&i.TotalCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listCVEs = `-- name: ListCVEs :many
+SELECT cves.id, cves.description, cves.cvss_score, cves.cvss_severity, cves.cvss_vector, cves.products, cves.published, cves.enriched, cves.created,
+       known_exploited.cve IS NOT NULL                    AS kev,
+       coalesce(known_exploited.added, '')                AS kev_added,
+       coalesce(known_exploited.due, '')                  AS kev_due,
+       coalesce(known_exploited.ransomware, FALSE)        AS kev_ransomware,
+       (SELECT COUNT(*)
+        FROM ticket_cves
+        WHERE ticket_cves.cve = cves.id)                  AS ticket_count,
+       (SELECT COUNT(*)
+        FROM ticket_cves
+                 JOIN tickets ON tickets.id = ticket_cves.ticket
+        WHERE ticket_cves.cve = cves.id
+          AND tickets.open)                               AS open_ticket_count,
+       COUNT(*) OVER ()                                   AS total_count
+FROM cves
+         LEFT JOIN known_exploited ON known_exploited.cve = cves.id
+WHERE (?1 IS NULL OR (known_exploited.cve IS NOT NULL) = ?1)
+  AND (?2 IS NULL OR cves.cvss_score >= ?2)
+ORDER BY cves.cvss_score IS NULL, cves.cvss_score DESC, cves.id DESC
+LIMIT ?4 OFFSET ?3
+`
+
+type ListCVEsParams struct {
+	Kev      interface{} `json:"kev"`
+	MinScore interface{} `json:"min_score"`
+	Offset   int64       `json:"offset"`
+	Limit    int64       `json:"limit"`
+}
+
+type ListCVEsRow struct {
+	ID              string     `json:"id"`
+	Description     string     `json:"description"`
+	CvssScore       *float64   `json:"cvss_score"`
+	CvssSeverity    *string    `json:"cvss_severity"`
+	CvssVector      *string    `json:"cvss_vector"`
+	Products        []byte     `json:"products"`
+	Published       *time.Time `json:"published"`
+	Enriched        *time.Time `json:"enriched"`
+	Created         time.Time  `json:"created"`
+	Kev             bool       `json:"kev"`
+	KevAdded        string     `json:"kev_added"`
+	KevDue          string     `json:"kev_due"`
+	KevRansomware   bool       `json:"kev_ransomware"`
+	TicketCount     int64      `json:"ticket_count"`
+	OpenTicketCount int64      `json:"open_ticket_count"`
+	TotalCount      int64      `json:"total_count"`
+}
+
+func (q *ReadQueries) ListCVEs(ctx context.Context, arg ListCVEsParams) ([]ListCVEsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listCVEs,
+		arg.Kev,
+		arg.MinScore,
+		arg.Offset,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListCVEsRow
+	for rows.Next() {
+		var i ListCVEsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Description,
+			&i.CvssScore,
+			&i.CvssSeverity,
+			&i.CvssVector,
+			&i.Products,
+			&i.Published,
+			&i.Enriched,
+			&i.Created,
+			&i.Kev,
+			&i.KevAdded,
+			&i.KevDue,
+			&i.KevRansomware,
+			&i.TicketCount,
+			&i.OpenTicketCount,
+			&i.TotalCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listCVEsToEnrich = `-- name: ListCVEsToEnrich :many
+SELECT id
+FROM cves
+WHERE enriched IS NULL
+   OR enriched < ?1
+ORDER BY enriched IS NOT NULL, enriched
+LIMIT ?2
+`
+
+type ListCVEsToEnrichParams struct {
+	Before *time.Time `json:"before"`
+	Limit  int64      `json:"limit"`
+}
+
+func (q *ReadQueries) ListCVEsToEnrich(ctx context.Context, arg ListCVEsToEnrichParams) ([]string, error) {
+	rows, err := q.db.QueryContext(ctx, listCVEsToEnrich, arg.Before, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listCampaignTickets = `-- name: ListCampaignTickets :many
+SELECT campaign_tickets.campaign, campaign_tickets.ticket, campaign_tickets.run, campaign_tickets.created,
+       tickets.name AS ticket_name,
+       tickets.open AS ticket_open,
+       (SELECT COUNT(*)
+        FROM playbook_run_tasks
+        WHERE playbook_run_tasks.run = campaign_tickets.run) AS task_count,
+       (SELECT COUNT(*)
+        FROM playbook_run_tasks
+                 JOIN tasks ON tasks.id = playbook_run_tasks.task
+        WHERE playbook_run_tasks.run = campaign_tickets.run
+          AND tasks.open)                                   AS open_task_count
+FROM campaign_tickets
+         JOIN tickets ON tickets.id = campaign_tickets.ticket
+WHERE campaign_tickets.campaign = ?1
+ORDER BY campaign_tickets.created
+`
+
+type ListCampaignTicketsRow struct {
+	Campaign      string    `json:"campaign"`
+	Ticket        string    `json:"ticket"`
+	Run           *string   `json:"run"`
+	Created       time.Time `json:"created"`
+	TicketName    string    `json:"ticket_name"`
+	TicketOpen    bool      `json:"ticket_open"`
+	TaskCount     int64     `json:"task_count"`
+	OpenTaskCount int64     `json:"open_task_count"`
+}
+
+func (q *ReadQueries) ListCampaignTickets(ctx context.Context, campaign string) ([]ListCampaignTicketsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listCampaignTickets, campaign)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListCampaignTicketsRow
+	for rows.Next() {
+		var i ListCampaignTicketsRow
+		if err := rows.Scan(
+			&i.Campaign,
+			&i.Ticket,
+			&i.Run,
+			&i.Created,
+			&i.TicketName,
+			&i.TicketOpen,
+			&i.TaskCount,
+			&i.OpenTaskCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listCampaignTimeline = `-- name: ListCampaignTimeline :many
+SELECT id, campaign, ticket, message, time
+FROM campaign_timeline
+WHERE campaign = ?1
+ORDER BY time, rowid
+`
+
+func (q *ReadQueries) ListCampaignTimeline(ctx context.Context, campaign string) ([]CampaignTimeline, error) {
+	rows, err := q.db.QueryContext(ctx, listCampaignTimeline, campaign)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []CampaignTimeline
+	for rows.Next() {
+		var i CampaignTimeline
+		if err := rows.Scan(
+			&i.ID,
+			&i.Campaign,
+			&i.Ticket,
+			&i.Message,
+			&i.Time,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listCampaigns = `-- name: ListCampaigns :many
+SELECT campaigns.id, campaigns.name, campaigns.description, campaigns.playbook, campaigns.inputs, campaigns.created, campaigns.updated, campaigns.feature_kind, campaigns.feature_value,
+       (SELECT COUNT(*)
+        FROM campaign_tickets
+        WHERE campaign_tickets.campaign = campaigns.id)            AS ticket_count,
+       (SELECT COUNT(*)
+        FROM campaign_tickets
+                 JOIN tickets ON tickets.id = campaign_tickets.ticket
+        WHERE campaign_tickets.campaign = campaigns.id
+          AND tickets.open)                                        AS open_ticket_count,
+       (SELECT COUNT(*)
+        FROM campaign_tickets
+                 JOIN playbook_run_tasks ON playbook_run_tasks.run = campaign_tickets.run
+        WHERE campaign_tickets.campaign = campaigns.id)            AS task_count,
+       (SELECT COUNT(*)
+        FROM campaign_tickets
+                 JOIN playbook_run_tasks ON playbook_run_tasks.run = campaign_tickets.run
+                 JOIN tasks ON tasks.id = playbook_run_tasks.task
+        WHERE campaign_tickets.campaign = campaigns.id
+          AND tasks.open)                                          AS open_task_count,
+       COUNT(*) OVER () as total_count
+FROM campaigns
+ORDER BY created DESC
+LIMIT ?2 OFFSET ?1
+`
+
+type ListCampaignsParams struct {
+	Offset int64 `json:"offset"`
+	Limit  int64 `json:"limit"`
+}
+
+type ListCampaignsRow struct {
+	ID              string    `json:"id"`
+	Name            string    `json:"name"`
+	Description     string    `json:"description"`
+	Playbook        *string   `json:"playbook"`
+	Inputs          []byte    `json:"inputs"`
+	Created         time.Time `json:"created"`
+	Updated         time.Time `json:"updated"`
+	FeatureKind     *string   `json:"feature_kind"`
+	FeatureValue    *string   `json:"feature_value"`
+	TicketCount     int64     `json:"ticket_count"`
+	OpenTicketCount int64     `json:"open_ticket_count"`
+	TaskCount       int64     `json:"task_count"`
+	OpenTaskCount   int64     `json:"open_task_count"`
+	TotalCount      int64     `json:"total_count"`
+}
+
+func (q *ReadQueries) ListCampaigns(ctx context.Context, arg ListCampaignsParams) ([]ListCampaignsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listCampaigns, arg.Offset, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListCampaignsRow
+	for rows.Next() {
+		var i ListCampaignsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Description,
+			&i.Playbook,
+			&i.Inputs,
+			&i.Created,
+			&i.Updated,
+			&i.FeatureKind,
+			&i.FeatureValue,
+			&i.TicketCount,
+			&i.OpenTicketCount,
+			&i.TaskCount,
+			&i.OpenTaskCount,
+			&i.TotalCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listChildGroups = `-- name: ListChildGroups :many
+SELECT g.id, g.name, g.permissions, g.created, g.updated, group_effective_groups.group_type
+FROM group_effective_groups
+         JOIN groups AS g ON g.id = group_effective_groups.parent_group_id
+WHERE child_group_id = ?1
+ORDER BY group_effective_groups.group_type
+`
+
+type ListChildGroupsRow struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Permissions string    `json:"permissions"`
+	Created     time.Time `json:"created"`
+	Updated     time.Time `json:"updated"`
+	GroupType   string    `json:"group_type"`
+}
+
+func (q *ReadQueries) ListChildGroups(ctx context.Context, groupID string) ([]ListChildGroupsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listChildGroups, groupID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListChildGroupsRow
+	for rows.Next() {
+		var i ListChildGroupsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Permissions,
+			&i.Created,
+			&i.Updated,
+			&i.GroupType,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listComments = `-- name: ListComments :many
+SELECT comments.id, comments.ticket, comments.author, comments.message, comments.created, comments.updated, users.name as author_name, COUNT(*) OVER () as total_count
+FROM comments
+         LEFT JOIN users ON users.id = comments.author
+WHERE ticket = ?1
+   OR ?1 = ''
+ORDER BY comments.created DESC
+LIMIT ?3 OFFSET ?2
+`
+
+type ListCommentsParams struct {
+	Ticket string `json:"ticket"`
+	Offset int64  `json:"offset"`
+	Limit  int64  `json:"limit"`
+}
+
+type ListCommentsRow struct {
+	ID         string    `json:"id"`
+	Ticket     string    `json:"ticket"`
+	Author     string    `json:"author"`
+	Message    string    `json:"message"`
+	Created    time.Time `json:"created"`
+	Updated    time.Time `json:"updated"`
+	AuthorName *string   `json:"author_name"`
+	TotalCount int64     `json:"total_count"`
+}
+
+func (q *ReadQueries) ListComments(ctx context.Context, arg ListCommentsParams) ([]ListCommentsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listComments, arg.Ticket, arg.Offset, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListCommentsRow
+	for rows.Next() {
+		var i ListCommentsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Ticket,
+			&i.Author,
+			&i.Message,
+			&i.Created,
+			&i.Updated,
+			&i.AuthorName,
+			&i.TotalCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listCustodyEvents = `-- name: ListCustodyEvents :many
+
+SELECT file_custody.id, file_custody.ticket, file_custody.file, file_custody.file_name, file_custody."action", file_custody.user, file_custody.sha256, file_custody.created, users.name AS user_name, users.username
+FROM file_custody
+         LEFT JOIN users ON users.id = file_custody.user
+WHERE file_custody.ticket = ?1
+ORDER BY file_custody.created, file_custody.rowid
+`
+
+type ListCustodyEventsRow struct {
+	ID       string    `json:"id"`
+	Ticket   string    `json:"ticket"`
+	File     string    `json:"file"`
+	FileName string    `json:"file_name"`
+	Action   string    `json:"action"`
+	User     *string   `json:"user"`
+	Sha256   string    `json:"sha256"`
+	Created  time.Time `json:"created"`
+	UserName *string   `json:"user_name"`
+	Username *string   `json:"username"`
+}
+
+// ----------------------------------------------------------------
+func (q *ReadQueries) ListCustodyEvents(ctx context.Context, ticket string) ([]ListCustodyEventsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listCustodyEvents, ticket)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListCustodyEventsRow
+	for rows.Next() {
+		var i ListCustodyEventsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Ticket,
+			&i.File,
+			&i.FileName,
+			&i.Action,
+			&i.User,
+			&i.Sha256,
+			&i.Created,
+			&i.UserName,
+			&i.Username,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listDLPEvents = `-- name: ListDLPEvents :many
+
+SELECT dlp_events.id, dlp_events.rule, dlp_events.export, dlp_events.ticket, dlp_events.user, dlp_events.created,
+       users.name       AS user_name,
+       COUNT(*) OVER () AS total_count
+FROM dlp_events
+         LEFT JOIN users ON users.id = dlp_events.user
+ORDER BY dlp_events.created DESC, dlp_events.rowid DESC
+LIMIT ?2 OFFSET ?1
+`
+
+type ListDLPEventsParams struct {
+	Offset int64 `json:"offset"`
+	Limit  int64 `json:"limit"`
+}
+
+type ListDLPEventsRow struct {
+	ID         string    `json:"id"`
+	Rule       string    `json:"rule"`
+	Export     string    `json:"export"`
+	Ticket     string    `json:"ticket"`
+	User       *string   `json:"user"`
+	Created    time.Time `json:"created"`
+	UserName   *string   `json:"user_name"`
+	TotalCount int64     `json:"total_count"`
+}
+
+// ----------------------------------------------------------------
+func (q *ReadQueries) ListDLPEvents(ctx context.Context, arg ListDLPEventsParams) ([]ListDLPEventsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listDLPEvents, arg.Offset, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListDLPEventsRow
+	for rows.Next() {
+		var i ListDLPEventsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Rule,
+			&i.Export,
+			&i.Ticket,
+			&i.User,
+			&i.Created,
+			&i.UserName,
+			&i.TotalCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listDeadLetters = `-- name: ListDeadLetters :many
+SELECT dead_letters.id, dead_letters.webhook, dead_letters.destination, dead_letters.payload, dead_letters.error, dead_letters.attempts, dead_letters.created, dead_letters.updated, COUNT(*) OVER () as total_count
+FROM dead_letters
+ORDER BY created DESC
+LIMIT ?2 OFFSET ?1
+`
+
+type ListDeadLettersParams struct {
+	Offset int64 `json:"offset"`
+	Limit  int64 `json:"limit"`
+}
+
+type ListDeadLettersRow struct {
+	ID          string    `json:"id"`
+	Webhook     string    `json:"webhook"`
+	Destination string    `json:"destination"`
+	Payload     []byte    `json:"payload"`
+	Error       string    `json:"error"`
+	Attempts    int64     `json:"attempts"`
+	Created     time.Time `json:"created"`
+	Updated     time.Time `json:"updated"`
+	TotalCount  int64     `json:"total_count"`
+}
+
+func (q *ReadQueries) ListDeadLetters(ctx context.Context, arg ListDeadLettersParams) ([]ListDeadLettersRow, error) {
+	rows, err := q.db.QueryContext(ctx, listDeadLetters, arg.Offset, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListDeadLettersRow
+	for rows.Next() {
+		var i ListDeadLettersRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Webhook,
+			&i.Destination,
+			&i.Payload,
+			&i.Error,
+			&i.Attempts,
+			&i.Created,
+			&i.Updated,
+			&i.TotalCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listDetectionRuleDispositions = `-- name: ListDetectionRuleDispositions :many
+SELECT coalesce(tickets.resolution, '') AS resolution,
+       COUNT(*)                         AS count
+FROM detection_rule_tickets
+         JOIN tickets ON tickets.id = detection_rule_tickets.ticket
+WHERE detection_rule_tickets.rule = ?1
+  AND NOT tickets.open
+GROUP BY coalesce(tickets.resolution, '')
+ORDER BY count DESC, resolution
+`
+
+type ListDetectionRuleDispositionsRow struct {
+	Resolution string `json:"resolution"`
+	Count      int64  `json:"count"`
+}
+
+func (q *ReadQueries) ListDetectionRuleDispositions(ctx context.Context, rule string) ([]ListDetectionRuleDispositionsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listDetectionRuleDispositions, rule)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListDetectionRuleDispositionsRow
+	for rows.Next() {
+		var i ListDetectionRuleDispositionsRow
+		if err := rows.Scan(&i.Resolution, &i.Count); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listDetectionRuleTickets = `-- name: ListDetectionRuleTickets :many
+SELECT tickets.id, tickets.name, tickets.open, tickets.resolution, tickets.created, tickets.resolved
+FROM detection_rule_tickets
+         JOIN tickets ON tickets.id = detection_rule_tickets.ticket
+WHERE detection_rule_tickets.rule = ?1
+ORDER BY tickets.created DESC
+`
+
+type ListDetectionRuleTicketsRow struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	Open       bool       `json:"open"`
+	Resolution *string    `json:"resolution"`
+	Created    time.Time  `json:"created"`
+	Resolved   *time.Time `json:"resolved"`
+}
+
+func (q *ReadQueries) ListDetectionRuleTickets(ctx context.Context, rule string) ([]ListDetectionRuleTicketsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listDetectionRuleTickets, rule)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListDetectionRuleTicketsRow
+	for rows.Next() {
+		var i ListDetectionRuleTicketsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Open,
+			&i.Resolution,
+			&i.Created,
+			&i.Resolved,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listDetectionRules = `-- name: ListDetectionRules :many
+SELECT detection_rules.id, detection_rules.ticket, detection_rules.name, detection_rules.description, detection_rules.format, detection_rules.content, detection_rules.status, detection_rules.author, detection_rules.reviewer, detection_rules.deployed, detection_rules.created, detection_rules.updated,
+       (SELECT COUNT(*)
+        FROM detection_rule_tickets
+        WHERE detection_rule_tickets.rule = detection_rules.id)   AS ticket_count,
+       (SELECT COUNT(*)
+        FROM detection_rule_tickets
+                 JOIN tickets ON tickets.id = detection_rule_tickets.ticket
+        WHERE detection_rule_tickets.rule = detection_rules.id
+          AND tickets.open)                                       AS open_ticket_count,
+       COUNT(*) OVER ()                                           AS total_count
+FROM detection_rules
+WHERE (?1 IS NULL OR detection_rules.status = ?1)
+ORDER BY detection_rules.updated DESC
+LIMIT ?3 OFFSET ?2
+`
+
+type ListDetectionRulesParams struct {
+	Status interface{} `json:"status"`
+	Offset int64       `json:"offset"`
+	Limit  int64       `json:"limit"`
+}
+
+type ListDetectionRulesRow struct {
+	ID              string     `json:"id"`
+	Ticket          string     `json:"ticket"`
+	Name            string     `json:"name"`
+	Description     string     `json:"description"`
+	Format          string     `json:"format"`
+	Content         string     `json:"content"`
+	Status          string     `json:"status"`
+	Author          *string    `json:"author"`
+	Reviewer        *string    `json:"reviewer"`
+	Deployed        *time.Time `json:"deployed"`
+	Created         time.Time  `json:"created"`
+	Updated         time.Time  `json:"updated"`
+	TicketCount     int64      `json:"ticket_count"`
+	OpenTicketCount int64      `json:"open_ticket_count"`
+	TotalCount      int64      `json:"total_count"`
+}
+
+func (q *ReadQueries) ListDetectionRules(ctx context.Context, arg ListDetectionRulesParams) ([]ListDetectionRulesRow, error) {
+	rows, err := q.db.QueryContext(ctx, listDetectionRules, arg.Status, arg.Offset, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListDetectionRulesRow
+	for rows.Next() {
+		var i ListDetectionRulesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Ticket,
+			&i.Name,
+			&i.Description,
+			&i.Format,
+			&i.Content,
+			&i.Status,
+			&i.Author,
+			&i.Reviewer,
+			&i.Deployed,
+			&i.Created,
+			&i.Updated,
+			&i.TicketCount,
+			&i.OpenTicketCount,
+			&i.TotalCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listDueEscalations = `-- name: ListDueEscalations :many
+SELECT ticket_escalations.ticket, ticket_escalations.policy, ticket_escalations.step, ticket_escalations.next_at, ticket_escalations.acknowledged_by, ticket_escalations.acknowledged_at, ticket_escalations.created, ticket_escalations.updated, ticket_escalations.paused_since, ticket_escalations.paused, tickets.name AS ticket_name, tickets.type AS ticket_type
+FROM ticket_escalations
+         JOIN tickets ON tickets.id = ticket_escalations.ticket
+WHERE tickets.open = TRUE
+  AND tickets.acknowledged IS NULL
+  AND ticket_escalations.acknowledged_at IS NULL
+  AND ticket_escalations.paused_since IS NULL
+  AND ticket_escalations.next_at IS NOT NULL
+  AND datetime(ticket_escalations.next_at) <= datetime(CAST(?1 AS TEXT))
+ORDER BY ticket_escalations.next_at
+`
+
+type ListDueEscalationsRow struct {
+	Ticket         string     `json:"ticket"`
+	Policy         string     `json:"policy"`
+	Step           int64      `json:"step"`
+	NextAt         *time.Time `json:"next_at"`
+	AcknowledgedBy *string    `json:"acknowledged_by"`
+	AcknowledgedAt *time.Time `json:"acknowledged_at"`
+	Created        time.Time  `json:"created"`
+	Updated        time.Time  `json:"updated"`
+	PausedSince    *time.Time `json:"paused_since"`
+	Paused         int64      `json:"paused"`
+	TicketName     string     `json:"ticket_name"`
+	TicketType     string     `json:"ticket_type"`
+}
+
+func (q *ReadQueries) ListDueEscalations(ctx context.Context, now string) ([]ListDueEscalationsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listDueEscalations, now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListDueEscalationsRow
+	for rows.Next() {
+		var i ListDueEscalationsRow
+		if err := rows.Scan(
+			&i.Ticket,
+			&i.Policy,
+			&i.Step,
+			&i.NextAt,
+			&i.AcknowledgedBy,
+			&i.AcknowledgedAt,
+			&i.Created,
+			&i.Updated,
+			&i.PausedSince,
+			&i.Paused,
+			&i.TicketName,
+			&i.TicketType,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listDueTaskTimers = `-- name: ListDueTaskTimers :many
+SELECT task_timers.task, task_timers.due, task_timers."action", task_timers.escalation_name, task_timers.escalation_owner, task_timers.result, task_timers.escalation_task, task_timers.fired, task_timers.created, task_timers.updated, tasks.name AS task_name, tasks.owner AS task_owner, tasks.ticket AS task_ticket
+FROM task_timers
+         JOIN tasks ON tasks.id = task_timers.task
+WHERE tasks.open = TRUE
+  AND task_timers.fired IS NULL
+  AND datetime(task_timers.due) <= datetime(CAST(?1 AS TEXT))
+ORDER BY task_timers.due
+`
+
+type ListDueTaskTimersRow struct {
+	Task            string     `json:"task"`
+	Due             time.Time  `json:"due"`
+	Action          string     `json:"action"`
+	EscalationName  *string    `json:"escalation_name"`
+	EscalationOwner *string    `json:"escalation_owner"`
+	Result          *string    `json:"result"`
+	EscalationTask  *string    `json:"escalation_task"`
+	Fired           *time.Time `json:"fired"`
+	Created         time.Time  `json:"created"`
+	Updated         time.Time  `json:"updated"`
+	TaskName        string     `json:"task_name"`
+	TaskOwner       *string    `json:"task_owner"`
+	TaskTicket      string     `json:"task_ticket"`
+}
+
+func (q *ReadQueries) ListDueTaskTimers(ctx context.Context, now string) ([]ListDueTaskTimersRow, error) {
+	rows, err := q.db.QueryContext(ctx, listDueTaskTimers, now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListDueTaskTimersRow
+	for rows.Next() {
+		var i ListDueTaskTimersRow
+		if err := rows.Scan(
+			&i.Task,
+			&i.Due,
+			&i.Action,
+			&i.EscalationName,
+			&i.EscalationOwner,
+			&i.Result,
+			&i.EscalationTask,
+			&i.Fired,
+			&i.Created,
+			&i.Updated,
+			&i.TaskName,
+			&i.TaskOwner,
+			&i.TaskTicket,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listEffortUsage = `-- name: ListEffortUsage :many
+SELECT CAST(coalesce(json_extract(tickets.state, '$.customer'), '') AS TEXT) AS customer,
+       CAST(strftime('%Y-%m', work_entries.started) AS TEXT)                AS month,
+       CAST(SUM(work_entries.duration) AS INTEGER)                          AS duration
+FROM work_entries
+         JOIN tickets ON tickets.id = work_entries.ticket
+WHERE work_entries.duration IS NOT NULL
+  AND datetime(work_entries.started) >= datetime(CAST(?1 AS TEXT))
+  AND datetime(work_entries.started) < datetime(CAST(?2 AS TEXT))
+GROUP BY customer, month
+`
+
+type ListEffortUsageParams struct {
+	Since string `json:"since"`
+	Until string `json:"until"`
+}
+
+type ListEffortUsageRow struct {
+	Customer string `json:"customer"`
+	Month    string `json:"month"`
+	Duration int64  `json:"duration"`
+}
+
+func (q *ReadQueries) ListEffortUsage(ctx context.Context, arg ListEffortUsageParams) ([]ListEffortUsageRow, error) {
+	rows, err := q.db.QueryContext(ctx, listEffortUsage, arg.Since, arg.Until)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListEffortUsageRow
+	for rows.Next() {
+		var i ListEffortUsageRow
+		if err := rows.Scan(&i.Customer, &i.Month, &i.Duration); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listEnabledAutoCloseRules = `-- name: ListEnabledAutoCloseRules :many
+SELECT id, name, enabled, "filter", inactive_days, warning_days, resolution, created, updated
+FROM auto_close_rules
+WHERE enabled
+ORDER BY name
+`
+
+func (q *ReadQueries) ListEnabledAutoCloseRules(ctx context.Context) ([]AutoCloseRule, error) {
+	rows, err := q.db.QueryContext(ctx, listEnabledAutoCloseRules)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []AutoCloseRule
+	for rows.Next() {
+		var i AutoCloseRule
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Enabled,
+			&i.Filter,
+			&i.InactiveDays,
+			&i.WarningDays,
+			&i.Resolution,
+			&i.Created,
+			&i.Updated,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listEnabledNotificationRules = `-- name: ListEnabledNotificationRules :many
+SELECT id, name, enabled, "filter", channel, target, throttle, dedup, created, updated
+FROM notification_rules
+WHERE enabled = TRUE
+ORDER BY name
+`
+
+func (q *ReadQueries) ListEnabledNotificationRules(ctx context.Context) ([]NotificationRule, error) {
+	rows, err := q.db.QueryContext(ctx, listEnabledNotificationRules)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []NotificationRule
+	for rows.Next() {
+		var i NotificationRule
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Enabled,
+			&i.Filter,
+			&i.Channel,
+			&i.Target,
+			&i.Throttle,
+			&i.Dedup,
+			&i.Created,
+			&i.Updated,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listEnrichments = `-- name: ListEnrichments :many
+SELECT enricher, value, result, expires, created
+FROM enrichment_cache
+WHERE value = ?1
+  AND expires > ?2
+ORDER BY enricher
+`
+
+type ListEnrichmentsParams struct {
+	Value string    `json:"value"`
+	Now   time.Time `json:"now"`
+}
+
+func (q *ReadQueries) ListEnrichments(ctx context.Context, arg ListEnrichmentsParams) ([]EnrichmentCache, error) {
+	rows, err := q.db.QueryContext(ctx, listEnrichments, arg.Value, arg.Now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []EnrichmentCache
+	for rows.Next() {
+		var i EnrichmentCache
+		if err := rows.Scan(
+			&i.Enricher,
+			&i.Value,
+			&i.Result,
+			&i.Expires,
+			&i.Created,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listEscalationPolicies = `-- name: ListEscalationPolicies :many
+SELECT escalation_policies.id, escalation_policies.name, escalation_policies."filter", escalation_policies.steps, escalation_policies.repeat, escalation_policies.created, escalation_policies.updated, escalation_policies.pause_states, COUNT(*) OVER () as total_count
+FROM escalation_policies
+ORDER BY name
+LIMIT ?2 OFFSET ?1
+`
+
+type ListEscalationPoliciesParams struct {
+	Offset int64 `json:"offset"`
+	Limit  int64 `json:"limit"`
+}
+
+type ListEscalationPoliciesRow struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Filter      []byte    `json:"filter"`
+	Steps       []byte    `json:"steps"`
+	Repeat      bool      `json:"repeat"`
+	Created     time.Time `json:"created"`
+	Updated     time.Time `json:"updated"`
+	PauseStates []byte    `json:"pause_states"`
+	TotalCount  int64     `json:"total_count"`
+}
+
+func (q *ReadQueries) ListEscalationPolicies(ctx context.Context, arg ListEscalationPoliciesParams) ([]ListEscalationPoliciesRow, error) {
+	rows, err := q.db.QueryContext(ctx, listEscalationPolicies, arg.Offset, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListEscalationPoliciesRow
+	for rows.Next() {
+		var i ListEscalationPoliciesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Filter,
+			&i.Steps,
+			&i.Repeat,
+			&i.Created,
+			&i.Updated,
+			&i.PauseStates,
+			&i.TotalCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listFeatures = `-- name: ListFeatures :many
+SELECT features."key", COUNT(*) OVER () as total_count
+FROM features
+ORDER BY features.key DESC
+LIMIT ?2 OFFSET ?1
+`
+
+type ListFeaturesParams struct {
+	Offset int64 `json:"offset"`
+	Limit  int64 `json:"limit"`
+}
+
+type ListFeaturesRow struct {
+	Key        string `json:"key"`
+	TotalCount int64  `json:"total_count"`
+}
+
+func (q *ReadQueries) ListFeatures(ctx context.Context, arg ListFeaturesParams) ([]ListFeaturesRow, error) {
+	rows, err := q.db.QueryContext(ctx, listFeatures, arg.Offset, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListFeaturesRow
+	for rows.Next() {
+		var i ListFeaturesRow
+		if err := rows.Scan(&i.Key, &i.TotalCount); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listFederationPeers = `-- name: ListFederationPeers :many
+
+SELECT federation_peers.id, federation_peers.name, federation_peers.url, federation_peers.secret, federation_peers.max_tlp, federation_peers.created, federation_peers.updated, COUNT(*) OVER () AS total_count
+FROM federation_peers
+ORDER BY name
+LIMIT ?2 OFFSET ?1
+`
+
+type ListFederationPeersParams struct {
+	Offset int64 `json:"offset"`
+	Limit  int64 `json:"limit"`
+}
+
+type ListFederationPeersRow struct {
+	ID         string    `json:"id"`
+	Name       string    `json:"name"`
+	Url        string    `json:"url"`
+	Secret     string    `json:"secret"`
+	MaxTlp     string    `json:"max_tlp"`
+	Created    time.Time `json:"created"`
+	Updated    time.Time `json:"updated"`
+	TotalCount int64     `json:"total_count"`
+}
+
+// ----------------------------------------------------------------
+func (q *ReadQueries) ListFederationPeers(ctx context.Context, arg ListFederationPeersParams) ([]ListFederationPeersRow, error) {
+	rows, err := q.db.QueryContext(ctx, listFederationPeers, arg.Offset, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListFederationPeersRow
+	for rows.Next() {
+		var i ListFederationPeersRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Url,
+			&i.Secret,
+			&i.MaxTlp,
+			&i.Created,
+			&i.Updated,
+			&i.TotalCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listFeeds = `-- name: ListFeeds :many
+
+SELECT name, source, indicators, imported
+FROM feeds
+ORDER BY name
+`
+
+// ----------------------------------------------------------------
+func (q *ReadQueries) ListFeeds(ctx context.Context) ([]Feed, error) {
+	rows, err := q.db.QueryContext(ctx, listFeeds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Feed
+	for rows.Next() {
+		var i Feed
+		if err := rows.Scan(
+			&i.Name,
+			&i.Source,
+			&i.Indicators,
+			&i.Imported,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listFileBlobs = `-- name: ListFileBlobs :many
+SELECT id, name, blob
+FROM files
+ORDER BY id
+LIMIT ?2 OFFSET ?1
+`
+
+type ListFileBlobsParams struct {
+	Offset int64 `json:"offset"`
+	Limit  int64 `json:"limit"`
+}
+
+type ListFileBlobsRow struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	Blob string `json:"blob"`
+}
+
+// ListFileBlobs only selects the columns of the first schema, so that the
+// migrations can list the files before later columns are added.
+func (q *ReadQueries) ListFileBlobs(ctx context.Context, arg ListFileBlobsParams) ([]ListFileBlobsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listFileBlobs, arg.Offset, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListFileBlobsRow
+	for rows.Next() {
+		var i ListFileBlobsRow
+		if err := rows.Scan(&i.ID, &i.Name, &i.Blob); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listFiles = `-- name: ListFiles :many
+SELECT files.id, files.ticket, files.name, files.blob, files.size, files.created, files.updated, files.md5, files.sha1, files.sha256, COUNT(*) OVER () as total_count
+FROM files
+WHERE ticket = ?1
+   OR ?1 = ''
+ORDER BY files.created DESC
+LIMIT ?3 OFFSET ?2
+`
+
+type ListFilesParams struct {
+	Ticket string `json:"ticket"`
+	Offset int64  `json:"offset"`
+	Limit  int64  `json:"limit"`
+}
+
+type ListFilesRow struct {
+	ID         string    `json:"id"`
+	Ticket     string    `json:"ticket"`
+	Name       string    `json:"name"`
+	Blob       string    `json:"blob"`
+	Size       float64   `json:"size"`
+	Created    time.Time `json:"created"`
+	Updated    time.Time `json:"updated"`
+	Md5        string    `json:"md5"`
+	Sha1       string    `json:"sha1"`
+	Sha256     string    `json:"sha256"`
+	TotalCount int64     `json:"total_count"`
+}
+
+func (q *ReadQueries) ListFiles(ctx context.Context, arg ListFilesParams) ([]ListFilesRow, error) {
+	rows, err := q.db.QueryContext(ctx, listFiles, arg.Ticket, arg.Offset, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListFilesRow
+	for rows.Next() {
+		var i ListFilesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Ticket,
+			&i.Name,
+			&i.Blob,
+			&i.Size,
+			&i.Created,
+			&i.Updated,
+			&i.Md5,
+			&i.Sha1,
+			&i.Sha256,
+			&i.TotalCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listGroupUsers = `-- name: ListGroupUsers :many
+SELECT users.id, users.username, users.passwordhash, users.tokenkey, users.active, users.name, users.email, users.avatar, users.lastresetsentat, users.lastverificationsentat, users.created, users.updated, uer.group_type
+FROM user_effective_groups uer
+         JOIN users ON users.id = uer.user_id
+WHERE uer.group_id = ?1
+ORDER BY users.name DESC
+`
+
+type ListGroupUsersRow struct {
+	ID                     string     `json:"id"`
+	Username               string     `json:"username"`
+	Passwordhash           string     `json:"passwordhash"`
+	Tokenkey               string     `json:"tokenkey"`
+	Active                 bool       `json:"active"`
+	Name                   *string    `json:"name"`
+	Email                  *string    `json:"email"`
+	Avatar                 *string    `json:"avatar"`
+	Lastresetsentat        *time.Time `json:"lastresetsentat"`
+	Lastverificationsentat *time.Time `json:"lastverificationsentat"`
+	Created                time.Time  `json:"created"`
+	Updated                time.Time  `json:"updated"`
+	GroupType              string     `json:"group_type"`
+}
+
+func (q *ReadQueries) ListGroupUsers(ctx context.Context, groupID string) ([]ListGroupUsersRow, error) {
+	rows, err := q.db.QueryContext(ctx, listGroupUsers, groupID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListGroupUsersRow
+	for rows.Next() {
+		var i ListGroupUsersRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Username,
+			&i.Passwordhash,
+			&i.Tokenkey,
+			&i.Active,
+			&i.Name,
+			&i.Email,
+			&i.Avatar,
+			&i.Lastresetsentat,
+			&i.Lastverificationsentat,
+			&i.Created,
+			&i.Updated,
+			&i.GroupType,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listGroups = `-- name: ListGroups :many
+SELECT g.id, g.name, g.permissions, g.created, g.updated, COUNT(*) OVER () as total_count
+FROM groups AS g
+ORDER BY g.created DESC
+LIMIT ?2 OFFSET ?1
+`
+
+type ListGroupsParams struct {
+	Offset int64 `json:"offset"`
+	Limit  int64 `json:"limit"`
+}
+
+type ListGroupsRow struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Permissions string    `json:"permissions"`
+	Created     time.Time `json:"created"`
+	Updated     time.Time `json:"updated"`
+	TotalCount  int64     `json:"total_count"`
+}
+
+func (q *ReadQueries) ListGroups(ctx context.Context, arg ListGroupsParams) ([]ListGroupsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listGroups, arg.Offset, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListGroupsRow
+	for rows.Next() {
+		var i ListGroupsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Permissions,
+			&i.Created,
+			&i.Updated,
+			&i.TotalCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listInactiveTickets = `-- name: ListInactiveTickets :many
+WITH activity AS (SELECT tickets.id,
+                         max(datetime(tickets.updated),
+                             coalesce((SELECT max(datetime(comments.updated)) FROM comments WHERE comments.ticket = tickets.id), ''),
+                             coalesce((SELECT max(datetime(tasks.updated)) FROM tasks WHERE tasks.ticket = tickets.id), ''),
+                             coalesce((SELECT max(datetime(files.updated)) FROM files WHERE files.ticket = tickets.id), ''),
+                             coalesce((SELECT max(datetime(timeline.created)) FROM timeline WHERE timeline.ticket = tickets.id), '')) AS last_activity
+                  FROM tickets
+                  WHERE tickets.open)
+SELECT tickets.id, tickets.type, tickets.owner, tickets.name, tickets.description, tickets.open, tickets.resolution, tickets.schema, tickets.state, tickets.created, tickets.updated, tickets.acknowledged, tickets.acknowledged_by, tickets.resolved, tickets.encrypted, tickets."key", tickets.resolved_by, tickets.reopen_count, CAST(activity.last_activity AS TEXT) AS last_activity
+FROM tickets
+         JOIN activity ON activity.id = tickets.id
+WHERE activity.last_activity < datetime(CAST(?1 AS TEXT))
+ORDER BY activity.last_activity
+`
+
+type ListInactiveTicketsRow struct {
+	ID             string     `json:"id"`
+	Type           string     `json:"type"`
+	Owner          *string    `json:"owner"`
+	Name           string     `json:"name"`
+	Description    string     `json:"description"`
+	Open           bool       `json:"open"`
+	Resolution     *string    `json:"resolution"`
+	Schema         []byte     `json:"schema"`
+	State          []byte     `json:"state"`
+	Created        time.Time  `json:"created"`
+	Updated        time.Time  `json:"updated"`
+	Acknowledged   *time.Time `json:"acknowledged"`
+	AcknowledgedBy *string    `json:"acknowledged_by"`
+	Resolved       *time.Time `json:"resolved"`
+	Encrypted      bool       `json:"encrypted"`
+	Key            *string    `json:"key"`
+	ResolvedBy     *string    `json:"resolved_by"`
+	ReopenCount    int64      `json:"reopen_count"`
+	LastActivity   string     `json:"last_activity"`
+}
+
+func (q *ReadQueries) ListInactiveTickets(ctx context.Context, before string) ([]ListInactiveTicketsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listInactiveTickets, before)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListInactiveTicketsRow
+	for rows.Next() {
+		var i ListInactiveTicketsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Type,
+			&i.Owner,
+			&i.Name,
+			&i.Description,
+			&i.Open,
+			&i.Resolution,
+			&i.Schema,
+			&i.State,
+			&i.Created,
+			&i.Updated,
+			&i.Acknowledged,
+			&i.AcknowledgedBy,
+			&i.Resolved,
+			&i.Encrypted,
+			&i.Key,
+			&i.ResolvedBy,
+			&i.ReopenCount,
+			&i.LastActivity,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listInvitations = `-- name: ListInvitations :many
+SELECT invitations.id, invitations.email, invitations.name, invitations."groups", invitations.token_hash, invitations.invited_by, invitations.expires, invitations.accepted_by, invitations.created, invitations.updated, COUNT(*) OVER () as total_count
+FROM invitations
+ORDER BY created DESC
+LIMIT ?2 OFFSET ?1
+`
+
+type ListInvitationsParams struct {
+	Offset int64 `json:"offset"`
+	Limit  int64 `json:"limit"`
+}
+
+type ListInvitationsRow struct {
+	ID         string    `json:"id"`
+	Email      string    `json:"email"`
+	Name       string    `json:"name"`
+	Groups     []byte    `json:"groups"`
+	TokenHash  string    `json:"token_hash"`
+	InvitedBy  *string   `json:"invited_by"`
+	Expires    time.Time `json:"expires"`
+	AcceptedBy *string   `json:"accepted_by"`
+	Created    time.Time `json:"created"`
+	Updated    time.Time `json:"updated"`
+	TotalCount int64     `json:"total_count"`
+}
+
+func (q *ReadQueries) ListInvitations(ctx context.Context, arg ListInvitationsParams) ([]ListInvitationsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listInvitations, arg.Offset, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListInvitationsRow
+	for rows.Next() {
+		var i ListInvitationsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Email,
+			&i.Name,
+			&i.Groups,
+			&i.TokenHash,
+			&i.InvitedBy,
+			&i.Expires,
+			&i.AcceptedBy,
+			&i.Created,
+			&i.Updated,
+			&i.TotalCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listJournalEntries = `-- name: ListJournalEntries :many
+SELECT request_journal.id, request_journal.reaction, request_journal.request, request_journal.size, request_journal.truncated, request_journal.success, request_journal.error, request_journal.replayed, request_journal.created, COUNT(*) OVER () as total_count
+FROM request_journal
+WHERE reaction = ?1
+ORDER BY created DESC, rowid DESC
+LIMIT ?3 OFFSET ?2
+`
+
+type ListJournalEntriesParams struct {
+	Reaction string `json:"reaction"`
+	Offset   int64  `json:"offset"`
+	Limit    int64  `json:"limit"`
+}
+
+type ListJournalEntriesRow struct {
+	ID         string     `json:"id"`
+	Reaction   string     `json:"reaction"`
+	Request    []byte     `json:"request"`
+	Size       int64      `json:"size"`
+	Truncated  bool       `json:"truncated"`
+	Success    bool       `json:"success"`
+	Error      string     `json:"error"`
+	Replayed   *time.Time `json:"replayed"`
+	Created    time.Time  `json:"created"`
+	TotalCount int64      `json:"total_count"`
+}
+
+func (q *ReadQueries) ListJournalEntries(ctx context.Context, arg ListJournalEntriesParams) ([]ListJournalEntriesRow, error) {
+	rows, err := q.db.QueryContext(ctx, listJournalEntries, arg.Reaction, arg.Offset, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListJournalEntriesRow
+	for rows.Next() {
+		var i ListJournalEntriesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Reaction,
+			&i.Request,
+			&i.Size,
+			&i.Truncated,
+			&i.Success,
+			&i.Error,
+			&i.Replayed,
+			&i.Created,
+			&i.TotalCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listLegalHolds = `-- name: ListLegalHolds :many
+SELECT legal_holds.id, legal_holds.collection, legal_holds.reason, legal_holds.created_by, legal_holds.created,
+       coalesce(tickets.name, files.name, '') AS name,
+       coalesce(tickets.id, files.ticket, '') AS ticket,
+       users.name                             AS created_by_name,
+       COUNT(*) OVER ()                       as total_count
+FROM legal_holds
+         LEFT JOIN tickets ON legal_holds.collection = 'tickets' AND tickets.id = legal_holds.id
+         LEFT JOIN files ON legal_holds.collection = 'files' AND files.id = legal_holds.id
+         LEFT JOIN users ON users.id = legal_holds.created_by
+ORDER BY legal_holds.created DESC
+LIMIT ?2 OFFSET ?1
+`
+
+type ListLegalHoldsParams struct {
+	Offset int64 `json:"offset"`
+	Limit  int64 `json:"limit"`
+}
+
+type ListLegalHoldsRow struct {
+	ID            string    `json:"id"`
+	Collection    string    `json:"collection"`
+	Reason        string    `json:"reason"`
+	CreatedBy     *string   `json:"created_by"`
+	Created       time.Time `json:"created"`
+	Name          string    `json:"name"`
+	Ticket        string    `json:"ticket"`
+	CreatedByName *string   `json:"created_by_name"`
+	TotalCount    int64     `json:"total_count"`
+}
+
+func (q *ReadQueries) ListLegalHolds(ctx context.Context, arg ListLegalHoldsParams) ([]ListLegalHoldsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listLegalHolds, arg.Offset, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListLegalHoldsRow
+	for rows.Next() {
+		var i ListLegalHoldsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Collection,
+			&i.Reason,
+			&i.CreatedBy,
+			&i.Created,
+			&i.Name,
+			&i.Ticket,
+			&i.CreatedByName,
+			&i.TotalCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listLinks = `-- name: ListLinks :many
+SELECT links.id, links.ticket, links.name, links.url, links.created, links.updated, COUNT(*) OVER () as total_count
+FROM links
+WHERE ticket = ?1
+   OR ?1 = ''
+ORDER BY links.created DESC
+LIMIT ?3 OFFSET ?2
+`
+
+type ListLinksParams struct {
+	Ticket string `json:"ticket"`
+	Offset int64  `json:"offset"`
+	Limit  int64  `json:"limit"`
+}
+
+type ListLinksRow struct {
+	ID         string    `json:"id"`
+	Ticket     string    `json:"ticket"`
+	Name       string    `json:"name"`
+	Url        string    `json:"url"`
+	Created    time.Time `json:"created"`
+	Updated    time.Time `json:"updated"`
+	TotalCount int64     `json:"total_count"`
+}
+
+func (q *ReadQueries) ListLinks(ctx context.Context, arg ListLinksParams) ([]ListLinksRow, error) {
+	rows, err := q.db.QueryContext(ctx, listLinks, arg.Ticket, arg.Offset, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListLinksRow
+	for rows.Next() {
+		var i ListLinksRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Ticket,
+			&i.Name,
+			&i.Url,
+			&i.Created,
+			&i.Updated,
+			&i.TotalCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listLogs = `-- name: ListLogs :many
+SELECT logs.id, logs.level, logs.message, logs.component, logs.user, logs.attrs, logs.created, COUNT(*) OVER () as total_count
+FROM logs
+WHERE (?1 IS NULL OR level >= ?1)
+  AND (?2 IS NULL OR component = ?2)
+  AND (?3 IS NULL OR user = ?3)
+  AND (?4 IS NULL OR created >= ?4)
+  AND (?5 IS NULL OR created <= ?5)
+  AND (?6 IS NULL OR message LIKE '%' || ?6 || '%')
+ORDER BY created DESC, rowid DESC
+LIMIT ?8 OFFSET ?7
+`
+
+type ListLogsParams struct {
+	Level     interface{} `json:"level"`
+	Component interface{} `json:"component"`
+	User      interface{} `json:"user"`
+	Since     interface{} `json:"since"`
+	Until     interface{} `json:"until"`
+	Query     interface{} `json:"query"`
+	Offset    int64       `json:"offset"`
+	Limit     int64       `json:"limit"`
+}
+
+type ListLogsRow struct {
+	ID         string    `json:"id"`
+	Level      int64     `json:"level"`
+	Message    string    `json:"message"`
+	Component  string    `json:"component"`
+	User       *string   `json:"user"`
+	Attrs      []byte    `json:"attrs"`
+	Created    time.Time `json:"created"`
+	TotalCount int64     `json:"total_count"`
+}
+
+func (q *ReadQueries) ListLogs(ctx context.Context, arg ListLogsParams) ([]ListLogsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listLogs,
+		arg.Level,
+		arg.Component,
+		arg.User,
+		arg.Since,
+		arg.Until,
+		arg.Query,
+		arg.Offset,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListLogsRow
+	for rows.Next() {
+		var i ListLogsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Level,
+			&i.Message,
+			&i.Component,
+			&i.User,
+			&i.Attrs,
+			&i.Created,
+			&i.TotalCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listMaintenanceWindows = `-- name: ListMaintenanceWindows :many
+SELECT maintenance_windows.id, maintenance_windows.name, maintenance_windows."filter", maintenance_windows.starts, maintenance_windows.ends, maintenance_windows.created, maintenance_windows.updated, COUNT(*) OVER () as total_count
+FROM maintenance_windows
+ORDER BY starts DESC
+LIMIT ?2 OFFSET ?1
+`
+
+type ListMaintenanceWindowsParams struct {
+	Offset int64 `json:"offset"`
+	Limit  int64 `json:"limit"`
+}
+
+type ListMaintenanceWindowsRow struct {
+	ID         string    `json:"id"`
+	Name       string    `json:"name"`
+	Filter     []byte    `json:"filter"`
+	Starts     time.Time `json:"starts"`
+	Ends       time.Time `json:"ends"`
+	Created    time.Time `json:"created"`
+	Updated    time.Time `json:"updated"`
+	TotalCount int64     `json:"total_count"`
+}
+
+func (q *ReadQueries) ListMaintenanceWindows(ctx context.Context, arg ListMaintenanceWindowsParams) ([]ListMaintenanceWindowsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listMaintenanceWindows, arg.Offset, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListMaintenanceWindowsRow
+	for rows.Next() {
+		var i ListMaintenanceWindowsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Filter,
+			&i.Starts,
+			&i.Ends,
+			&i.Created,
+			&i.Updated,
+			&i.TotalCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listMentions = `-- name: ListMentions :many
+SELECT comments.id,
+       comments.ticket,
+       comments.message,
+       comments.created,
+       tickets.name AS ticket_name,
+       tickets.type AS ticket_type,
+       users.name   AS author_name
+FROM comments
+         JOIN tickets ON tickets.id = comments.ticket
+         LEFT JOIN users ON users.id = comments.author
+WHERE comments.message LIKE '%@' || CAST(?1 AS TEXT) || '%'
+  AND datetime(comments.created) > datetime(CAST(?2 AS TEXT))
+ORDER BY comments.created DESC
+LIMIT ?3
+`
+
+type ListMentionsParams struct {
+	Username string `json:"username"`
+	Since    string `json:"since"`
+	Limit    int64  `json:"limit"`
+}
+
+type ListMentionsRow struct {
+	ID         string    `json:"id"`
+	Ticket     string    `json:"ticket"`
+	Message    string    `json:"message"`
+	Created    time.Time `json:"created"`
+	TicketName string    `json:"ticket_name"`
+	TicketType string    `json:"ticket_type"`
+	AuthorName *string   `json:"author_name"`
+}
+
+func (q *ReadQueries) ListMentions(ctx context.Context, arg ListMentionsParams) ([]ListMentionsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listMentions, arg.Username, arg.Since, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListMentionsRow
+	for rows.Next() {
+		var i ListMentionsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Ticket,
+			&i.Message,
+			&i.Created,
+			&i.TicketName,
+			&i.TicketType,
+			&i.AuthorName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listNotificationRules = `-- name: ListNotificationRules :many
+SELECT notification_rules.id, notification_rules.name, notification_rules.enabled, notification_rules."filter", notification_rules.channel, notification_rules.target, notification_rules.throttle, notification_rules.dedup, notification_rules.created, notification_rules.updated, COUNT(*) OVER () as total_count
+FROM notification_rules
+ORDER BY created DESC
+LIMIT ?2 OFFSET ?1
+`
+
+type ListNotificationRulesParams struct {
+	Offset int64 `json:"offset"`
+	Limit  int64 `json:"limit"`
+}
+
+type ListNotificationRulesRow struct {
+	ID         string    `json:"id"`
+	Name       string    `json:"name"`
+	Enabled    bool      `json:"enabled"`
+	Filter     []byte    `json:"filter"`
+	Channel    string    `json:"channel"`
+	Target     string    `json:"target"`
+	Throttle   int64     `json:"throttle"`
+	Dedup      int64     `json:"dedup"`
+	Created    time.Time `json:"created"`
+	Updated    time.Time `json:"updated"`
+	TotalCount int64     `json:"total_count"`
+}
+
+func (q *ReadQueries) ListNotificationRules(ctx context.Context, arg ListNotificationRulesParams) ([]ListNotificationRulesRow, error) {
+	rows, err := q.db.QueryContext(ctx, listNotificationRules, arg.Offset, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListNotificationRulesRow
+	for rows.Next() {
+		var i ListNotificationRulesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Enabled,
+			&i.Filter,
+			&i.Channel,
+			&i.Target,
+			&i.Throttle,
+			&i.Dedup,
+			&i.Created,
+			&i.Updated,
+			&i.TotalCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listOpenTasksByOwner = `-- name: ListOpenTasksByOwner :many
+SELECT id, ticket, name
+FROM tasks
+WHERE owner = ?1
+  AND open = TRUE
+ORDER BY created DESC
+`
+
+type ListOpenTasksByOwnerRow struct {
+	ID     string `json:"id"`
+	Ticket string `json:"ticket"`
+	Name   string `json:"name"`
+}
+
+func (q *ReadQueries) ListOpenTasksByOwner(ctx context.Context, owner *string) ([]ListOpenTasksByOwnerRow, error) {
+	rows, err := q.db.QueryContext(ctx, listOpenTasksByOwner, owner)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListOpenTasksByOwnerRow
+	for rows.Next() {
+		var i ListOpenTasksByOwnerRow
+		if err := rows.Scan(&i.ID, &i.Ticket, &i.Name); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listOpenTicketsByOwner = `-- name: ListOpenTicketsByOwner :many
+SELECT id, name, type, created
+FROM tickets
+WHERE owner = ?1
+  AND open = TRUE
+ORDER BY created DESC
+LIMIT ?2
+`
+
+type ListOpenTicketsByOwnerParams struct {
+	Owner *string `json:"owner"`
+	Limit int64   `json:"limit"`
+}
+
+type ListOpenTicketsByOwnerRow struct {
+	ID      string    `json:"id"`
+	Name    string    `json:"name"`
+	Type    string    `json:"type"`
+	Created time.Time `json:"created"`
+}
+
+func (q *ReadQueries) ListOpenTicketsByOwner(ctx context.Context, arg ListOpenTicketsByOwnerParams) ([]ListOpenTicketsByOwnerRow, error) {
+	rows, err := q.db.QueryContext(ctx, listOpenTicketsByOwner, arg.Owner, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListOpenTicketsByOwnerRow
+	for rows.Next() {
+		var i ListOpenTicketsByOwnerRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Type,
+			&i.Created,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listOverlappingMaintenanceWindows = `-- name: ListOverlappingMaintenanceWindows :many
+SELECT id, name, "filter", starts, ends, created, updated
+FROM maintenance_windows
+WHERE datetime(starts) < datetime(CAST(?1 AS TEXT))
+  AND datetime(ends) > datetime(CAST(?2 AS TEXT))
+ORDER BY starts
+`
+
+type ListOverlappingMaintenanceWindowsParams struct {
+	Until string `json:"until"`
+	Since string `json:"since"`
+}
+
+func (q *ReadQueries) ListOverlappingMaintenanceWindows(ctx context.Context, arg ListOverlappingMaintenanceWindowsParams) ([]MaintenanceWindow, error) {
+	rows, err := q.db.QueryContext(ctx, listOverlappingMaintenanceWindows, arg.Until, arg.Since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []MaintenanceWindow
+	for rows.Next() {
+		var i MaintenanceWindow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Filter,
+			&i.Starts,
+			&i.Ends,
+			&i.Created,
+			&i.Updated,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listParentGroups = `-- name: ListParentGroups :many
+SELECT g.id, g.name, g.permissions, g.created, g.updated, group_effective_groups.group_type
+FROM group_effective_groups
+         JOIN groups AS g ON g.id = group_effective_groups.child_group_id
+WHERE parent_group_id = ?1
+ORDER BY group_effective_groups.group_type
+`
+
+type ListParentGroupsRow struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Permissions string    `json:"permissions"`
+	Created     time.Time `json:"created"`
+	Updated     time.Time `json:"updated"`
+	GroupType   string    `json:"group_type"`
+}
+
+func (q *ReadQueries) ListParentGroups(ctx context.Context, groupID string) ([]ListParentGroupsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listParentGroups, groupID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListParentGroupsRow
+	for rows.Next() {
+		var i ListParentGroupsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Permissions,
+			&i.Created,
+			&i.Updated,
+			&i.GroupType,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listParentPermissions = `-- name: ListParentPermissions :many
+SELECT group_effective_permissions.permission
+FROM group_effective_permissions
+WHERE parent_group_id = ?1
+ORDER BY permission
+`
+
+func (q *ReadQueries) ListParentPermissions(ctx context.Context, groupID string) ([]string, error) {
+	rows, err := q.db.QueryContext(ctx, listParentPermissions, groupID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var permission string
+		if err := rows.Scan(&permission); err != nil {
+			return nil, err
+		}
+		items = append(items, permission)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listPasswordHistory = `-- name: ListPasswordHistory :many
+SELECT passwordHash, created
+FROM password_history
+WHERE user = ?1
+ORDER BY created DESC, rowid DESC
+LIMIT ?2
+`
+
+type ListPasswordHistoryParams struct {
+	User  string `json:"user"`
+	Limit int64  `json:"limit"`
+}
+
+type ListPasswordHistoryRow struct {
+	Passwordhash string    `json:"passwordhash"`
+	Created      time.Time `json:"created"`
+}
+
+func (q *ReadQueries) ListPasswordHistory(ctx context.Context, arg ListPasswordHistoryParams) ([]ListPasswordHistoryRow, error) {
+	rows, err := q.db.QueryContext(ctx, listPasswordHistory, arg.User, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListPasswordHistoryRow
+	for rows.Next() {
+		var i ListPasswordHistoryRow
+		if err := rows.Scan(&i.Passwordhash, &i.Created); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listPendingEscalations = `-- name: ListPendingEscalations :many
+SELECT ticket_escalations.ticket, ticket_escalations.next_at, tickets.type AS ticket_type
+FROM ticket_escalations
+         JOIN tickets ON tickets.id = ticket_escalations.ticket
+WHERE tickets.open = TRUE
+  AND tickets.acknowledged IS NULL
+  AND ticket_escalations.acknowledged_at IS NULL
+  AND ticket_escalations.paused_since IS NULL
+  AND ticket_escalations.next_at IS NOT NULL
+`
+
+type ListPendingEscalationsRow struct {
+	Ticket     string     `json:"ticket"`
+	NextAt     *time.Time `json:"next_at"`
+	TicketType string     `json:"ticket_type"`
+}
+
+func (q *ReadQueries) ListPendingEscalations(ctx context.Context) ([]ListPendingEscalationsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listPendingEscalations)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListPendingEscalationsRow
+	for rows.Next() {
+		var i ListPendingEscalationsRow
+		if err := rows.Scan(&i.Ticket, &i.NextAt, &i.TicketType); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listPendingTaskTimers = `-- name: ListPendingTaskTimers :many
+SELECT task_timers.task, task_timers.due, tasks.ticket AS task_ticket, tickets.type AS ticket_type
+FROM task_timers
+         JOIN tasks ON tasks.id = task_timers.task
+         JOIN tickets ON tickets.id = tasks.ticket
+WHERE tasks.open = TRUE
+  AND task_timers.fired IS NULL
+`
+
+type ListPendingTaskTimersRow struct {
+	Task       string    `json:"task"`
+	Due        time.Time `json:"due"`
+	TaskTicket string    `json:"task_ticket"`
+	TicketType string    `json:"ticket_type"`
+}
+
+func (q *ReadQueries) ListPendingTaskTimers(ctx context.Context) ([]ListPendingTaskTimersRow, error) {
+	rows, err := q.db.QueryContext(ctx, listPendingTaskTimers)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListPendingTaskTimersRow
+	for rows.Next() {
+		var i ListPendingTaskTimersRow
+		if err := rows.Scan(
+			&i.Task,
+			&i.Due,
+			&i.TaskTicket,
+			&i.TicketType,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listPendingTaskTimersByEscalationOwner = `-- name: ListPendingTaskTimersByEscalationOwner :many
+SELECT task
+FROM task_timers
+WHERE escalation_owner = ?1
+  AND fired IS NULL
+`
+
+func (q *ReadQueries) ListPendingTaskTimersByEscalationOwner(ctx context.Context, escalationOwner *string) ([]string, error) {
+	rows, err := q.db.QueryContext(ctx, listPendingTaskTimersByEscalationOwner, escalationOwner)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var task string
+		if err := rows.Scan(&task); err != nil {
+			return nil, err
+		}
+		items = append(items, task)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listPlaybookRunTasks = `-- name: ListPlaybookRunTasks :many
+SELECT tasks.id, tasks.ticket, tasks.owner, tasks.name, tasks.open, tasks.created, tasks.updated
+FROM playbook_run_tasks
+         JOIN tasks ON tasks.id = playbook_run_tasks.task
+WHERE playbook_run_tasks.run = ?1
+ORDER BY tasks.created
+`
+
+func (q *ReadQueries) ListPlaybookRunTasks(ctx context.Context, run string) ([]Task, error) {
+	rows, err := q.db.QueryContext(ctx, listPlaybookRunTasks, run)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Task
+	for rows.Next() {
+		var i Task
+		if err := rows.Scan(
+			&i.ID,
+			&i.Ticket,
+			&i.Owner,
+			&i.Name,
+			&i.Open,
+			&i.Created,
+			&i.Updated,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listPlaybookRuns = `-- name: ListPlaybookRuns :many
+SELECT playbook_runs.id, playbook_runs.playbook, playbook_runs.ticket, playbook_runs.inputs, playbook_runs.created, playbooks.name AS playbook_name
+FROM playbook_runs
+         JOIN playbooks ON playbooks.id = playbook_runs.playbook
+WHERE playbook_runs.ticket = ?1
+ORDER BY playbook_runs.created
+`
+
+type ListPlaybookRunsRow struct {
+	ID           string    `json:"id"`
+	Playbook     string    `json:"playbook"`
+	Ticket       string    `json:"ticket"`
+	Inputs       []byte    `json:"inputs"`
+	Created      time.Time `json:"created"`
+	PlaybookName string    `json:"playbook_name"`
+}
+
+func (q *ReadQueries) ListPlaybookRuns(ctx context.Context, ticket string) ([]ListPlaybookRunsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listPlaybookRuns, ticket)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListPlaybookRunsRow
+	for rows.Next() {
+		var i ListPlaybookRunsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Playbook,
+			&i.Ticket,
+			&i.Inputs,
+			&i.Created,
+			&i.PlaybookName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listPlaybooks = `-- name: ListPlaybooks :many
+SELECT playbooks.id, playbooks.name, playbooks.description, playbooks.inputs, playbooks.tasks, playbooks.created, playbooks.updated, COUNT(*) OVER () as total_count
+FROM playbooks
+ORDER BY name
+LIMIT ?2 OFFSET ?1
+`
+
+type ListPlaybooksParams struct {
+	Offset int64 `json:"offset"`
+	Limit  int64 `json:"limit"`
+}
+
+type ListPlaybooksRow struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Inputs      []byte    `json:"inputs"`
+	Tasks       []byte    `json:"tasks"`
+	Created     time.Time `json:"created"`
+	Updated     time.Time `json:"updated"`
+	TotalCount  int64     `json:"total_count"`
+}
+
+func (q *ReadQueries) ListPlaybooks(ctx context.Context, arg ListPlaybooksParams) ([]ListPlaybooksRow, error) {
+	rows, err := q.db.QueryContext(ctx, listPlaybooks, arg.Offset, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListPlaybooksRow
+	for rows.Next() {
+		var i ListPlaybooksRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Description,
+			&i.Inputs,
+			&i.Tasks,
+			&i.Created,
+			&i.Updated,
+			&i.TotalCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listPushSubscriptions = `-- name: ListPushSubscriptions :many
+
+SELECT id, user, endpoint, p256dh, auth, created, updated
+FROM push_subscriptions
+WHERE user = ?1
+ORDER BY created DESC
+`
+
+// ----------------------------------------------------------------
+func (q *ReadQueries) ListPushSubscriptions(ctx context.Context, user string) ([]PushSubscription, error) {
+	rows, err := q.db.QueryContext(ctx, listPushSubscriptions, user)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []PushSubscription
+	for rows.Next() {
+		var i PushSubscription
+		if err := rows.Scan(
+			&i.ID,
+			&i.User,
+			&i.Endpoint,
+			&i.P256dh,
+			&i.Auth,
+			&i.Created,
+			&i.Updated,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listQueues = `-- name: ListQueues :many
+
+SELECT queues.id, queues.name, queues.description, queues."filter", queues.created, queues.updated, COUNT(*) OVER () as total_count
+FROM queues
+ORDER BY name
+LIMIT ?2 OFFSET ?1
+`
+
+type ListQueuesParams struct {
+	Offset int64 `json:"offset"`
+	Limit  int64 `json:"limit"`
+}
+
+type ListQueuesRow struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Filter      []byte    `json:"filter"`
+	Created     time.Time `json:"created"`
+	Updated     time.Time `json:"updated"`
+	TotalCount  int64     `json:"total_count"`
+}
+
+// ----------------------------------------------------------------
+func (q *ReadQueries) ListQueues(ctx context.Context, arg ListQueuesParams) ([]ListQueuesRow, error) {
+	rows, err := q.db.QueryContext(ctx, listQueues, arg.Offset, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListQueuesRow
+	for rows.Next() {
+		var i ListQueuesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Description,
+			&i.Filter,
+			&i.Created,
+			&i.Updated,
+			&i.TotalCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listReactionRuns = `-- name: ListReactionRuns :many
+SELECT id, reaction, success, duration_ms, cpu_ms, memory_peak, created
+FROM reaction_runs
+WHERE reaction = ?1
+ORDER BY created DESC, rowid DESC
+LIMIT ?2
+`
+
+type ListReactionRunsParams struct {
+	Reaction string `json:"reaction"`
+	Limit    int64  `json:"limit"`
+}
+
+func (q *ReadQueries) ListReactionRuns(ctx context.Context, arg ListReactionRunsParams) ([]ReactionRun, error) {
+	rows, err := q.db.QueryContext(ctx, listReactionRuns, arg.Reaction, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ReactionRun
+	for rows.Next() {
+		var i ReactionRun
+		if err := rows.Scan(
+			&i.ID,
+			&i.Reaction,
+			&i.Success,
+			&i.DurationMs,
+			&i.CpuMs,
+			&i.MemoryPeak,
+			&i.Created,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listReactions = `-- name: ListReactions :many
+SELECT reactions.id, reactions.name, reactions."action", reactions.actiondata, reactions."trigger", reactions.triggerdata, reactions.created, reactions.updated, COUNT(*) OVER () as total_count
+FROM reactions
+ORDER BY reactions.created DESC
+LIMIT ?2 OFFSET ?1
+`
+
+type ListReactionsParams struct {
+	Offset int64 `json:"offset"`
+	Limit  int64 `json:"limit"`
+}
+
+type ListReactionsRow struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Action      string    `json:"action"`
+	Actiondata  []byte    `json:"actiondata"`
+	Trigger     string    `json:"trigger"`
+	Triggerdata []byte    `json:"triggerdata"`
+	Created     time.Time `json:"created"`
+	Updated     time.Time `json:"updated"`
+	TotalCount  int64     `json:"total_count"`
+}
+
+func (q *ReadQueries) ListReactions(ctx context.Context, arg ListReactionsParams) ([]ListReactionsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listReactions, arg.Offset, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListReactionsRow
+	for rows.Next() {
+		var i ListReactionsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Action,
+			&i.Actiondata,
+			&i.Trigger,
+			&i.Triggerdata,
+			&i.Created,
+			&i.Updated,
+			&i.TotalCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listReactionsByTrigger = `-- name: ListReactionsByTrigger :many
+SELECT reactions.id, reactions.name, reactions."action", reactions.actiondata, reactions."trigger", reactions.triggerdata, reactions.created, reactions.updated, COUNT(*) OVER () as total_count
+FROM reactions
+WHERE trigger = ?1
+ORDER BY reactions.created DESC
+LIMIT ?3 OFFSET ?2
+`
+
+type ListReactionsByTriggerParams struct {
+	Trigger string `json:"trigger"`
+	Offset  int64  `json:"offset"`
+	Limit   int64  `json:"limit"`
+}
+
+type ListReactionsByTriggerRow struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Action      string    `json:"action"`
+	Actiondata  []byte    `json:"actiondata"`
+	Trigger     string    `json:"trigger"`
+	Triggerdata []byte    `json:"triggerdata"`
+	Created     time.Time `json:"created"`
+	Updated     time.Time `json:"updated"`
+	TotalCount  int64     `json:"total_count"`
+}
+
+func (q *ReadQueries) ListReactionsByTrigger(ctx context.Context, arg ListReactionsByTriggerParams) ([]ListReactionsByTriggerRow, error) {
+	rows, err := q.db.QueryContext(ctx, listReactionsByTrigger, arg.Trigger, arg.Offset, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListReactionsByTriggerRow
+	for rows.Next() {
+		var i ListReactionsByTriggerRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Action,
+			&i.Actiondata,
+			&i.Trigger,
+			&i.Triggerdata,
+			&i.Created,
+			&i.Updated,
+			&i.TotalCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listRedactionProfiles = `-- name: ListRedactionProfiles :many
+SELECT redaction_profiles.id, redaction_profiles.name, redaction_profiles.audience, redaction_profiles.fields, redaction_profiles.hide_files, redaction_profiles.hide_identities, redaction_profiles.created, redaction_profiles.updated, COUNT(*) OVER () as total_count
+FROM redaction_profiles
+ORDER BY audience, name
+LIMIT ?2 OFFSET ?1
+`
+
+type ListRedactionProfilesParams struct {
+	Offset int64 `json:"offset"`
+	Limit  int64 `json:"limit"`
+}
+
+type ListRedactionProfilesRow struct {
+	ID             string    `json:"id"`
+	Name           string    `json:"name"`
+	Audience       string    `json:"audience"`
+	Fields         []byte    `json:"fields"`
+	HideFiles      bool      `json:"hide_files"`
+	HideIdentities bool      `json:"hide_identities"`
+	Created        time.Time `json:"created"`
+	Updated        time.Time `json:"updated"`
+	TotalCount     int64     `json:"total_count"`
+}
+
+func (q *ReadQueries) ListRedactionProfiles(ctx context.Context, arg ListRedactionProfilesParams) ([]ListRedactionProfilesRow, error) {
+	rows, err := q.db.QueryContext(ctx, listRedactionProfiles, arg.Offset, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListRedactionProfilesRow
+	for rows.Next() {
+		var i ListRedactionProfilesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Audience,
+			&i.Fields,
+			&i.HideFiles,
+			&i.HideIdentities,
+			&i.Created,
+			&i.Updated,
+			&i.TotalCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listRemoteFederationLinks = `-- name: ListRemoteFederationLinks :many
+SELECT id, ticket, peer, remote_ticket, remote_key, remote_type, remote_name, remote_open, share, created, updated
+FROM federation_links
+WHERE peer = ?1
+  AND remote_ticket = ?2
+`
+
+type ListRemoteFederationLinksParams struct {
+	Peer         string `json:"peer"`
+	RemoteTicket string `json:"remote_ticket"`
+}
+
+func (q *ReadQueries) ListRemoteFederationLinks(ctx context.Context, arg ListRemoteFederationLinksParams) ([]FederationLink, error) {
+	rows, err := q.db.QueryContext(ctx, listRemoteFederationLinks, arg.Peer, arg.RemoteTicket)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []FederationLink
+	for rows.Next() {
+		var i FederationLink
+		if err := rows.Scan(
+			&i.ID,
+			&i.Ticket,
+			&i.Peer,
+			&i.RemoteTicket,
+			&i.RemoteKey,
+			&i.RemoteType,
+			&i.RemoteName,
+			&i.RemoteOpen,
+			&i.Share,
+			&i.Created,
+			&i.Updated,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listReportTickets = `-- name: ListReportTickets :many
+
+SELECT tickets.id, tickets.type, tickets.owner, tickets.name, tickets.description, tickets.open, tickets.resolution, tickets.schema, tickets.state, tickets.created, tickets.updated, tickets.acknowledged, tickets.acknowledged_by, tickets.resolved, tickets.encrypted, tickets."key", tickets.resolved_by, tickets.reopen_count,
+       users.name     as owner_name,
+       types.singular as type_singular
+FROM tickets
+         LEFT JOIN users ON users.id = tickets.owner
+         LEFT JOIN types ON types.id = tickets.type
+WHERE (?1 IS NULL OR tickets.type = ?1)
+  AND datetime(tickets.created) >= datetime(CAST(?2 AS TEXT))
+ORDER BY tickets.created
+`
+
+type ListReportTicketsParams struct {
+	Type  interface{} `json:"type"`
+	Since string      `json:"since"`
+}
+
+type ListReportTicketsRow struct {
+	ID             string     `json:"id"`
+	Type           string     `json:"type"`
+	Owner          *string    `json:"owner"`
+	Name           string     `json:"name"`
+	Description    string     `json:"description"`
+	Open           bool       `json:"open"`
+	Resolution     *string    `json:"resolution"`
+	Schema         []byte     `json:"schema"`
+	State          []byte     `json:"state"`
+	Created        time.Time  `json:"created"`
+	Updated        time.Time  `json:"updated"`
+	Acknowledged   *time.Time `json:"acknowledged"`
+	AcknowledgedBy *string    `json:"acknowledged_by"`
+	Resolved       *time.Time `json:"resolved"`
+	Encrypted      bool       `json:"encrypted"`
+	Key            *string    `json:"key"`
+	ResolvedBy     *string    `json:"resolved_by"`
+	ReopenCount    int64      `json:"reopen_count"`
+	OwnerName      *string    `json:"owner_name"`
+	TypeSingular   *string    `json:"type_singular"`
+}
+
+// ----------------------------------------------------------------
+func (q *ReadQueries) ListReportTickets(ctx context.Context, arg ListReportTicketsParams) ([]ListReportTicketsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listReportTickets, arg.Type, arg.Since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListReportTicketsRow
+	for rows.Next() {
+		var i ListReportTicketsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Type,
+			&i.Owner,
+			&i.Name,
+			&i.Description,
+			&i.Open,
+			&i.Resolution,
+			&i.Schema,
+			&i.State,
+			&i.Created,
+			&i.Updated,
+			&i.Acknowledged,
+			&i.AcknowledgedBy,
+			&i.Resolved,
+			&i.Encrypted,
+			&i.Key,
+			&i.ResolvedBy,
+			&i.ReopenCount,
+			&i.OwnerName,
+			&i.TypeSingular,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listStorageUsage = `-- name: ListStorageUsage :many
+SELECT CAST(coalesce(json_extract(tickets.state, '$.customer'), '') AS TEXT) AS customer,
+       CAST(strftime('%Y-%m', files.created) AS TEXT)                       AS month,
+       CAST(SUM(files.size) AS INTEGER)                                     AS size
+FROM files
+         JOIN tickets ON tickets.id = files.ticket
+WHERE datetime(files.created) >= datetime(CAST(?1 AS TEXT))
+  AND datetime(files.created) < datetime(CAST(?2 AS TEXT))
+GROUP BY customer, month
 `
 
-// ----------------------------------------------------------------
-func (q *ReadQueries) GetWebhook(ctx context.Context, id string) (Webhook, error) {
-	row := q.db.QueryRowContext(ctx, getWebhook, id)
-	var i Webhook
-	err := row.Scan(
-		&i.ID,
-		&i.Collection,
-		&i.Destination,
-		&i.Name,
-		&i.Created,
-		&i.Updated,
-	)
-	return i, err
+type ListStorageUsageParams struct {
+	Since string `json:"since"`
+	Until string `json:"until"`
 }
 
-const listChildGroups = `-- name: ListChildGroups :many
-SELECT g.id, g.name, g.permissions, g.created, g.updated, group_effective_groups.group_type
-FROM group_effective_groups
-         JOIN groups AS g ON g.id = group_effective_groups.parent_group_id
-WHERE child_group_id = ?1
-ORDER BY group_effective_groups.group_type
+type ListStorageUsageRow struct {
+	Customer string `json:"customer"`
+	Month    string `json:"month"`
+	Size     int64  `json:"size"`
+}
+
+func (q *ReadQueries) ListStorageUsage(ctx context.Context, arg ListStorageUsageParams) ([]ListStorageUsageRow, error) {
+	rows, err := q.db.QueryContext(ctx, listStorageUsage, arg.Since, arg.Until)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListStorageUsageRow
+	for rows.Next() {
+		var i ListStorageUsageRow
+		if err := rows.Scan(&i.Customer, &i.Month, &i.Size); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTaskOutputs = `-- name: ListTaskOutputs :many
+SELECT task_outputs.task, task_outputs.ticket, task_outputs.name, task_outputs.output, task_outputs.created, task_outputs.updated, tasks.name AS task_name, tasks.open AS task_open, tasks.owner AS task_owner
+FROM task_outputs
+         JOIN tasks ON tasks.id = task_outputs.task
+WHERE task_outputs.ticket = ?1
+ORDER BY task_outputs.name
 `
 
-type ListChildGroupsRow struct {
-	ID          string    `json:"id"`
-	Name        string    `json:"name"`
-	Permissions string    `json:"permissions"`
-	Created     time.Time `json:"created"`
-	Updated     time.Time `json:"updated"`
-	GroupType   string    `json:"group_type"`
+type ListTaskOutputsRow struct {
+	Task      string    `json:"task"`
+	Ticket    string    `json:"ticket"`
+	Name      string    `json:"name"`
+	Output    []byte    `json:"output"`
+	Created   time.Time `json:"created"`
+	Updated   time.Time `json:"updated"`
+	TaskName  string    `json:"task_name"`
+	TaskOpen  bool      `json:"task_open"`
+	TaskOwner *string   `json:"task_owner"`
 }
 
-func (q *ReadQueries) ListChildGroups(ctx context.Context, groupID string) ([]ListChildGroupsRow, error) {
-	rows, err := q.db.QueryContext(ctx, listChildGroups, groupID)
+func (q *ReadQueries) ListTaskOutputs(ctx context.Context, ticket string) ([]ListTaskOutputsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listTaskOutputs, ticket)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []ListChildGroupsRow
+	var items []ListTaskOutputsRow
 	for rows.Next() {
-		var i ListChildGroupsRow
+		var i ListTaskOutputsRow
 		if err := rows.Scan(
-			&i.ID,
+			&i.Task,
+			&i.Ticket,
 			&i.Name,
-			&i.Permissions,
+			&i.Output,
 			&i.Created,
 			&i.Updated,
-			&i.GroupType,
+			&i.TaskName,
+			&i.TaskOpen,
+			&i.TaskOwner,
 		); err != nil {
 			return nil, err
 		}
@@ -395,50 +5708,61 @@ func (q *ReadQueries) ListChildGroups(ctx context.Context, groupID string) ([]Li
 	return items, nil
 }
 
-const listComments = `-- name: ListComments :many
-SELECT comments.id, comments.ticket, comments.author, comments.message, comments.created, comments.updated, users.name as author_name, COUNT(*) OVER () as total_count
-FROM comments
-         LEFT JOIN users ON users.id = comments.author
+const listTasks = `-- name: ListTasks :many
+SELECT tasks.id, tasks.ticket, tasks.owner, tasks.name, tasks.open, tasks.created, tasks.updated,
+       users.name       as owner_name,
+       tickets.name     as ticket_name,
+       tickets.type     as ticket_type,
+       COUNT(*) OVER () as total_count
+FROM tasks
+         LEFT JOIN users ON users.id = tasks.owner
+         LEFT JOIN tickets ON tickets.id = tasks.ticket
 WHERE ticket = ?1
    OR ?1 = ''
-ORDER BY comments.created DESC
+ORDER BY tasks.created DESC
 LIMIT ?3 OFFSET ?2
 `
 
-type ListCommentsParams struct {
+type ListTasksParams struct {
 	Ticket string `json:"ticket"`
 	Offset int64  `json:"offset"`
 	Limit  int64  `json:"limit"`
 }
 
-type ListCommentsRow struct {
+type ListTasksRow struct {
 	ID         string    `json:"id"`
 	Ticket     string    `json:"ticket"`
-	Author     string    `json:"author"`
-	Message    string    `json:"message"`
+	Owner      *string   `json:"owner"`
+	Name       string    `json:"name"`
+	Open       bool      `json:"open"`
 	Created    time.Time `json:"created"`
 	Updated    time.Time `json:"updated"`
-	AuthorName *string   `json:"author_name"`
+	OwnerName  *string   `json:"owner_name"`
+	TicketName *string   `json:"ticket_name"`
+	TicketType *string   `json:"ticket_type"`
 	TotalCount int64     `json:"total_count"`
 }
 
-func (q *ReadQueries) ListComments(ctx context.Context, arg ListCommentsParams) ([]ListCommentsRow, error) {
-	rows, err := q.db.QueryContext(ctx, listComments, arg.Ticket, arg.Offset, arg.Limit)
+func (q *ReadQueries) ListTasks(ctx context.Context, arg ListTasksParams) ([]ListTasksRow, error) {
+	rows, err := q.db.QueryContext(ctx, listTasks, arg.Ticket, arg.Offset, arg.Limit)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []ListCommentsRow
+	var items []ListTasksRow
 	for rows.Next() {
-		var i ListCommentsRow
+		var i ListTasksRow
 		if err := rows.Scan(
 			&i.ID,
 			&i.Ticket,
-			&i.Author,
-			&i.Message,
+			&i.Owner,
+			&i.Name,
+			&i.Open,
 			&i.Created,
 			&i.Updated,
-			&i.AuthorName,
+			&i.OwnerName,
+			&i.TicketName,
+			&i.TicketType,
 			&i.TotalCount,
 		); err != nil {
 			return nil, err
@@ -454,33 +5778,242 @@ func (q *ReadQueries) ListComments(ctx context.Context, arg ListCommentsParams)
 	return items, nil
 }
 
-const listFeatures = `-- name: ListFeatures :many
-SELECT features."key", COUNT(*) OVER () as total_count
-FROM features
-ORDER BY features.key DESC
-LIMIT ?2 OFFSET ?1
+const listTechniqueTickets = `-- name: ListTechniqueTickets :many
+SELECT ticket_techniques.ticket, ticket_techniques.technique, ticket_techniques.tactic
+FROM ticket_techniques
+         JOIN tickets ON tickets.id = ticket_techniques.ticket
+WHERE (?1 IS NULL OR tickets.type = ?1)
+  AND datetime(tickets.created) >= datetime(CAST(?2 AS TEXT))
 `
 
-type ListFeaturesParams struct {
-	Offset int64 `json:"offset"`
-	Limit  int64 `json:"limit"`
+type ListTechniqueTicketsParams struct {
+	Type  interface{} `json:"type"`
+	Since string      `json:"since"`
 }
 
-type ListFeaturesRow struct {
-	Key        string `json:"key"`
-	TotalCount int64  `json:"total_count"`
+func (q *ReadQueries) ListTechniqueTickets(ctx context.Context, arg ListTechniqueTicketsParams) ([]TicketTechnique, error) {
+	rows, err := q.db.QueryContext(ctx, listTechniqueTickets, arg.Type, arg.Since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []TicketTechnique
+	for rows.Next() {
+		var i TicketTechnique
+		if err := rows.Scan(&i.Ticket, &i.Technique, &i.Tactic); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
 }
 
-func (q *ReadQueries) ListFeatures(ctx context.Context, arg ListFeaturesParams) ([]ListFeaturesRow, error) {
-	rows, err := q.db.QueryContext(ctx, listFeatures, arg.Offset, arg.Limit)
+const listTicketActivity = `-- name: ListTicketActivity :many
+
+SELECT ticket_activity.id, ticket_activity.ticket, ticket_activity.user, ticket_activity.method, ticket_activity.path, ticket_activity.body, ticket_activity.status, ticket_activity.duration, ticket_activity.created,
+       users.name       AS user_name,
+       COUNT(*) OVER () AS total_count
+FROM ticket_activity
+         LEFT JOIN users ON users.id = ticket_activity.user
+WHERE ticket_activity.ticket = ?1
+ORDER BY ticket_activity.created, ticket_activity.rowid
+LIMIT ?3 OFFSET ?2
+`
+
+type ListTicketActivityParams struct {
+	Ticket string `json:"ticket"`
+	Offset int64  `json:"offset"`
+	Limit  int64  `json:"limit"`
+}
+
+type ListTicketActivityRow struct {
+	ID         string    `json:"id"`
+	Ticket     string    `json:"ticket"`
+	User       *string   `json:"user"`
+	Method     string    `json:"method"`
+	Path       string    `json:"path"`
+	Body       *string   `json:"body"`
+	Status     int64     `json:"status"`
+	Duration   int64     `json:"duration"`
+	Created    time.Time `json:"created"`
+	UserName   *string   `json:"user_name"`
+	TotalCount int64     `json:"total_count"`
+}
+
+// ----------------------------------------------------------------
+func (q *ReadQueries) ListTicketActivity(ctx context.Context, arg ListTicketActivityParams) ([]ListTicketActivityRow, error) {
+	rows, err := q.db.QueryContext(ctx, listTicketActivity, arg.Ticket, arg.Offset, arg.Limit)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []ListFeaturesRow
+	var items []ListTicketActivityRow
 	for rows.Next() {
-		var i ListFeaturesRow
-		if err := rows.Scan(&i.Key, &i.TotalCount); err != nil {
+		var i ListTicketActivityRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Ticket,
+			&i.User,
+			&i.Method,
+			&i.Path,
+			&i.Body,
+			&i.Status,
+			&i.Duration,
+			&i.Created,
+			&i.UserName,
+			&i.TotalCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTicketAlertSources = `-- name: ListTicketAlertSources :many
+SELECT DISTINCT source
+FROM alerts
+WHERE ticket = ?1
+ORDER BY source
+`
+
+func (q *ReadQueries) ListTicketAlertSources(ctx context.Context, ticket *string) ([]string, error) {
+	rows, err := q.db.QueryContext(ctx, listTicketAlertSources, ticket)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var source string
+		if err := rows.Scan(&source); err != nil {
+			return nil, err
+		}
+		items = append(items, source)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTicketArtifacts = `-- name: ListTicketArtifacts :many
+
+SELECT ticket, kind, value, created
+FROM ticket_artifacts
+WHERE ticket = ?1
+ORDER BY created, kind, value
+`
+
+// ----------------------------------------------------------------
+func (q *ReadQueries) ListTicketArtifacts(ctx context.Context, ticket string) ([]TicketArtifact, error) {
+	rows, err := q.db.QueryContext(ctx, listTicketArtifacts, ticket)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []TicketArtifact
+	for rows.Next() {
+		var i TicketArtifact
+		if err := rows.Scan(
+			&i.Ticket,
+			&i.Kind,
+			&i.Value,
+			&i.Created,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTicketCVEs = `-- name: ListTicketCVEs :many
+SELECT cves.id, cves.description, cves.cvss_score, cves.cvss_severity, cves.cvss_vector, cves.products, cves.published, cves.enriched, cves.created,
+       known_exploited.cve IS NOT NULL                    AS kev,
+       coalesce(known_exploited.added, '')                AS kev_added,
+       coalesce(known_exploited.due, '')                  AS kev_due,
+       coalesce(known_exploited.ransomware, FALSE)        AS kev_ransomware,
+       (SELECT COUNT(*)
+        FROM ticket_cves
+        WHERE ticket_cves.cve = cves.id)                  AS ticket_count,
+       (SELECT COUNT(*)
+        FROM ticket_cves
+                 JOIN tickets ON tickets.id = ticket_cves.ticket
+        WHERE ticket_cves.cve = cves.id
+          AND tickets.open)                               AS open_ticket_count
+FROM ticket_cves
+         JOIN cves ON cves.id = ticket_cves.cve
+         LEFT JOIN known_exploited ON known_exploited.cve = cves.id
+WHERE ticket_cves.ticket = ?1
+ORDER BY cves.id
+`
+
+type ListTicketCVEsRow struct {
+	ID              string     `json:"id"`
+	Description     string     `json:"description"`
+	CvssScore       *float64   `json:"cvss_score"`
+	CvssSeverity    *string    `json:"cvss_severity"`
+	CvssVector      *string    `json:"cvss_vector"`
+	Products        []byte     `json:"products"`
+	Published       *time.Time `json:"published"`
+	Enriched        *time.Time `json:"enriched"`
+	Created         time.Time  `json:"created"`
+	Kev             bool       `json:"kev"`
+	KevAdded        string     `json:"kev_added"`
+	KevDue          string     `json:"kev_due"`
+	KevRansomware   bool       `json:"kev_ransomware"`
+	TicketCount     int64      `json:"ticket_count"`
+	OpenTicketCount int64      `json:"open_ticket_count"`
+}
+
+func (q *ReadQueries) ListTicketCVEs(ctx context.Context, ticket string) ([]ListTicketCVEsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listTicketCVEs, ticket)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListTicketCVEsRow
+	for rows.Next() {
+		var i ListTicketCVEsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Description,
+			&i.CvssScore,
+			&i.CvssSeverity,
+			&i.CvssVector,
+			&i.Products,
+			&i.Published,
+			&i.Enriched,
+			&i.Created,
+			&i.Kev,
+			&i.KevAdded,
+			&i.KevDue,
+			&i.KevRansomware,
+			&i.TicketCount,
+			&i.OpenTicketCount,
+		); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -494,50 +6027,71 @@ func (q *ReadQueries) ListFeatures(ctx context.Context, arg ListFeaturesParams)
 	return items, nil
 }
 
-const listFiles = `-- name: ListFiles :many
-SELECT files.id, files.ticket, files.name, files.blob, files.size, files.created, files.updated, COUNT(*) OVER () as total_count
-FROM files
-WHERE ticket = ?1
-   OR ?1 = ''
-ORDER BY files.created DESC
-LIMIT ?3 OFFSET ?2
+const listTicketCampaigns = `-- name: ListTicketCampaigns :many
+SELECT campaigns.id, campaigns.name, campaigns.description, campaigns.playbook, campaigns.inputs, campaigns.created, campaigns.updated, campaigns.feature_kind, campaigns.feature_value,
+       (SELECT COUNT(*)
+        FROM campaign_tickets
+        WHERE campaign_tickets.campaign = campaigns.id)            AS ticket_count,
+       (SELECT COUNT(*)
+        FROM campaign_tickets
+                 JOIN tickets ON tickets.id = campaign_tickets.ticket
+        WHERE campaign_tickets.campaign = campaigns.id
+          AND tickets.open)                                        AS open_ticket_count,
+       (SELECT COUNT(*)
+        FROM campaign_tickets
+                 JOIN playbook_run_tasks ON playbook_run_tasks.run = campaign_tickets.run
+        WHERE campaign_tickets.campaign = campaigns.id)            AS task_count,
+       (SELECT COUNT(*)
+        FROM campaign_tickets
+                 JOIN playbook_run_tasks ON playbook_run_tasks.run = campaign_tickets.run
+                 JOIN tasks ON tasks.id = playbook_run_tasks.task
+        WHERE campaign_tickets.campaign = campaigns.id
+          AND tasks.open)                                          AS open_task_count
+FROM campaigns
+         JOIN campaign_tickets AS membership ON membership.campaign = campaigns.id
+WHERE membership.ticket = ?1
+ORDER BY campaigns.created DESC
 `
 
-type ListFilesParams struct {
-	Ticket string `json:"ticket"`
-	Offset int64  `json:"offset"`
-	Limit  int64  `json:"limit"`
-}
-
-type ListFilesRow struct {
-	ID         string    `json:"id"`
-	Ticket     string    `json:"ticket"`
-	Name       string    `json:"name"`
-	Blob       string    `json:"blob"`
-	Size       float64   `json:"size"`
-	Created    time.Time `json:"created"`
-	Updated    time.Time `json:"updated"`
-	TotalCount int64     `json:"total_count"`
+type ListTicketCampaignsRow struct {
+	ID              string    `json:"id"`
+	Name            string    `json:"name"`
+	Description     string    `json:"description"`
+	Playbook        *string   `json:"playbook"`
+	Inputs          []byte    `json:"inputs"`
+	Created         time.Time `json:"created"`
+	Updated         time.Time `json:"updated"`
+	FeatureKind     *string   `json:"feature_kind"`
+	FeatureValue    *string   `json:"feature_value"`
+	TicketCount     int64     `json:"ticket_count"`
+	OpenTicketCount int64     `json:"open_ticket_count"`
+	TaskCount       int64     `json:"task_count"`
+	OpenTaskCount   int64     `json:"open_task_count"`
 }
 
-func (q *ReadQueries) ListFiles(ctx context.Context, arg ListFilesParams) ([]ListFilesRow, error) {
-	rows, err := q.db.QueryContext(ctx, listFiles, arg.Ticket, arg.Offset, arg.Limit)
+func (q *ReadQueries) ListTicketCampaigns(ctx context.Context, ticket string) ([]ListTicketCampaignsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listTicketCampaigns, ticket)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []ListFilesRow
+	var items []ListTicketCampaignsRow
 	for rows.Next() {
-		var i ListFilesRow
+		var i ListTicketCampaignsRow
 		if err := rows.Scan(
 			&i.ID,
-			&i.Ticket,
 			&i.Name,
-			&i.Blob,
-			&i.Size,
+			&i.Description,
+			&i.Playbook,
+			&i.Inputs,
 			&i.Created,
 			&i.Updated,
-			&i.TotalCount,
+			&i.FeatureKind,
+			&i.FeatureValue,
+			&i.TicketCount,
+			&i.OpenTicketCount,
+			&i.TaskCount,
+			&i.OpenTaskCount,
 		); err != nil {
 			return nil, err
 		}
@@ -552,53 +6106,36 @@ func (q *ReadQueries) ListFiles(ctx context.Context, arg ListFilesParams) ([]Lis
 	return items, nil
 }
 
-const listGroupUsers = `-- name: ListGroupUsers :many
-SELECT users.id, users.username, users.passwordhash, users.tokenkey, users.active, users.name, users.email, users.avatar, users.lastresetsentat, users.lastverificationsentat, users.created, users.updated, uer.group_type
-FROM user_effective_groups uer
-         JOIN users ON users.id = uer.user_id
-WHERE uer.group_id = ?1
-ORDER BY users.name DESC
+const listTicketDetectionRules = `-- name: ListTicketDetectionRules :many
+SELECT detection_rules.id, detection_rules.ticket, detection_rules.name, detection_rules.description, detection_rules.format, detection_rules.content, detection_rules.status, detection_rules.author, detection_rules.reviewer, detection_rules.deployed, detection_rules.created, detection_rules.updated
+FROM detection_rule_tickets
+         JOIN detection_rules ON detection_rules.id = detection_rule_tickets.rule
+WHERE detection_rule_tickets.ticket = ?1
+ORDER BY detection_rules.name
 `
 
-type ListGroupUsersRow struct {
-	ID                     string     `json:"id"`
-	Username               string     `json:"username"`
-	Passwordhash           string     `json:"passwordhash"`
-	Tokenkey               string     `json:"tokenkey"`
-	Active                 bool       `json:"active"`
-	Name                   *string    `json:"name"`
-	Email                  *string    `json:"email"`
-	Avatar                 *string    `json:"avatar"`
-	Lastresetsentat        *time.Time `json:"lastresetsentat"`
-	Lastverificationsentat *time.Time `json:"lastverificationsentat"`
-	Created                time.Time  `json:"created"`
-	Updated                time.Time  `json:"updated"`
-	GroupType              string     `json:"group_type"`
-}
-
-func (q *ReadQueries) ListGroupUsers(ctx context.Context, groupID string) ([]ListGroupUsersRow, error) {
-	rows, err := q.db.QueryContext(ctx, listGroupUsers, groupID)
+func (q *ReadQueries) ListTicketDetectionRules(ctx context.Context, ticket string) ([]DetectionRule, error) {
+	rows, err := q.db.QueryContext(ctx, listTicketDetectionRules, ticket)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []ListGroupUsersRow
+	var items []DetectionRule
 	for rows.Next() {
-		var i ListGroupUsersRow
+		var i DetectionRule
 		if err := rows.Scan(
 			&i.ID,
-			&i.Username,
-			&i.Passwordhash,
-			&i.Tokenkey,
-			&i.Active,
+			&i.Ticket,
 			&i.Name,
-			&i.Email,
-			&i.Avatar,
-			&i.Lastresetsentat,
-			&i.Lastverificationsentat,
+			&i.Description,
+			&i.Format,
+			&i.Content,
+			&i.Status,
+			&i.Author,
+			&i.Reviewer,
+			&i.Deployed,
 			&i.Created,
 			&i.Updated,
-			&i.GroupType,
 		); err != nil {
 			return nil, err
 		}
@@ -613,43 +6150,51 @@ func (q *ReadQueries) ListGroupUsers(ctx context.Context, groupID string) ([]Lis
 	return items, nil
 }
 
-const listGroups = `-- name: ListGroups :many
-SELECT g.id, g.name, g.permissions, g.created, g.updated, COUNT(*) OVER () as total_count
-FROM groups AS g
-ORDER BY g.created DESC
-LIMIT ?2 OFFSET ?1
+const listTicketEffort = `-- name: ListTicketEffort :many
+SELECT tickets.id,
+       tickets.name,
+       tickets.type,
+       CAST(SUM(work_entries.duration) AS INTEGER) AS duration,
+       COUNT(DISTINCT work_entries.user)           AS analysts
+FROM work_entries
+         JOIN tickets ON tickets.id = work_entries.ticket
+WHERE work_entries.duration IS NOT NULL
+  AND (?1 IS NULL OR tickets.type = ?1)
+  AND datetime(work_entries.started) >= datetime(CAST(?2 AS TEXT))
+  AND datetime(work_entries.started) < datetime(CAST(?3 AS TEXT))
+GROUP BY tickets.id
+ORDER BY duration DESC, tickets.id
 `
 
-type ListGroupsParams struct {
-	Offset int64 `json:"offset"`
-	Limit  int64 `json:"limit"`
+type ListTicketEffortParams struct {
+	Type  interface{} `json:"type"`
+	Since string      `json:"since"`
+	Until string      `json:"until"`
 }
 
-type ListGroupsRow struct {
-	ID          string    `json:"id"`
-	Name        string    `json:"name"`
-	Permissions string    `json:"permissions"`
-	Created     time.Time `json:"created"`
-	Updated     time.Time `json:"updated"`
-	TotalCount  int64     `json:"total_count"`
+type ListTicketEffortRow struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	Duration int64  `json:"duration"`
+	Analysts int64  `json:"analysts"`
 }
 
-func (q *ReadQueries) ListGroups(ctx context.Context, arg ListGroupsParams) ([]ListGroupsRow, error) {
-	rows, err := q.db.QueryContext(ctx, listGroups, arg.Offset, arg.Limit)
+func (q *ReadQueries) ListTicketEffort(ctx context.Context, arg ListTicketEffortParams) ([]ListTicketEffortRow, error) {
+	rows, err := q.db.QueryContext(ctx, listTicketEffort, arg.Type, arg.Since, arg.Until)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []ListGroupsRow
+	var items []ListTicketEffortRow
 	for rows.Next() {
-		var i ListGroupsRow
+		var i ListTicketEffortRow
 		if err := rows.Scan(
 			&i.ID,
 			&i.Name,
-			&i.Permissions,
-			&i.Created,
-			&i.Updated,
-			&i.TotalCount,
+			&i.Type,
+			&i.Duration,
+			&i.Analysts,
 		); err != nil {
 			return nil, err
 		}
@@ -664,48 +6209,53 @@ func (q *ReadQueries) ListGroups(ctx context.Context, arg ListGroupsParams) ([]L
 	return items, nil
 }
 
-const listLinks = `-- name: ListLinks :many
-SELECT links.id, links.ticket, links.name, links.url, links.created, links.updated, COUNT(*) OVER () as total_count
-FROM links
-WHERE ticket = ?1
-   OR ?1 = ''
-ORDER BY links.created DESC
-LIMIT ?3 OFFSET ?2
+const listTicketFederationLinks = `-- name: ListTicketFederationLinks :many
+SELECT federation_links.id, federation_links.ticket, federation_links.peer, federation_links.remote_ticket, federation_links.remote_key, federation_links.remote_type, federation_links.remote_name, federation_links.remote_open, federation_links.share, federation_links.created, federation_links.updated, federation_peers.name AS peer_name, federation_peers.url AS peer_url
+FROM federation_links
+         JOIN federation_peers ON federation_peers.id = federation_links.peer
+WHERE federation_links.ticket = ?1
+ORDER BY federation_links.created
 `
 
-type ListLinksParams struct {
-	Ticket string `json:"ticket"`
-	Offset int64  `json:"offset"`
-	Limit  int64  `json:"limit"`
-}
-
-type ListLinksRow struct {
-	ID         string    `json:"id"`
-	Ticket     string    `json:"ticket"`
-	Name       string    `json:"name"`
-	Url        string    `json:"url"`
-	Created    time.Time `json:"created"`
-	Updated    time.Time `json:"updated"`
-	TotalCount int64     `json:"total_count"`
+type ListTicketFederationLinksRow struct {
+	ID           string    `json:"id"`
+	Ticket       string    `json:"ticket"`
+	Peer         string    `json:"peer"`
+	RemoteTicket string    `json:"remote_ticket"`
+	RemoteKey    *string   `json:"remote_key"`
+	RemoteType   string    `json:"remote_type"`
+	RemoteName   string    `json:"remote_name"`
+	RemoteOpen   bool      `json:"remote_open"`
+	Share        bool      `json:"share"`
+	Created      time.Time `json:"created"`
+	Updated      time.Time `json:"updated"`
+	PeerName     string    `json:"peer_name"`
+	PeerUrl      string    `json:"peer_url"`
 }
 
-func (q *ReadQueries) ListLinks(ctx context.Context, arg ListLinksParams) ([]ListLinksRow, error) {
-	rows, err := q.db.QueryContext(ctx, listLinks, arg.Ticket, arg.Offset, arg.Limit)
+func (q *ReadQueries) ListTicketFederationLinks(ctx context.Context, ticket string) ([]ListTicketFederationLinksRow, error) {
+	rows, err := q.db.QueryContext(ctx, listTicketFederationLinks, ticket)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []ListLinksRow
+	var items []ListTicketFederationLinksRow
 	for rows.Next() {
-		var i ListLinksRow
+		var i ListTicketFederationLinksRow
 		if err := rows.Scan(
 			&i.ID,
 			&i.Ticket,
-			&i.Name,
-			&i.Url,
+			&i.Peer,
+			&i.RemoteTicket,
+			&i.RemoteKey,
+			&i.RemoteType,
+			&i.RemoteName,
+			&i.RemoteOpen,
+			&i.Share,
 			&i.Created,
 			&i.Updated,
-			&i.TotalCount,
+			&i.PeerName,
+			&i.PeerUrl,
 		); err != nil {
 			return nil, err
 		}
@@ -720,39 +6270,37 @@ func (q *ReadQueries) ListLinks(ctx context.Context, arg ListLinksParams) ([]Lis
 	return items, nil
 }
 
-const listParentGroups = `-- name: ListParentGroups :many
-SELECT g.id, g.name, g.permissions, g.created, g.updated, group_effective_groups.group_type
-FROM group_effective_groups
-         JOIN groups AS g ON g.id = group_effective_groups.child_group_id
-WHERE parent_group_id = ?1
-ORDER BY group_effective_groups.group_type
+const listTicketGrants = `-- name: ListTicketGrants :many
+SELECT ticket_grants.ticket, ticket_grants.user, ticket_grants.created, users.username, users.name AS user_name
+FROM ticket_grants
+         JOIN users ON users.id = ticket_grants.user
+WHERE ticket_grants.ticket = ?1
+ORDER BY ticket_grants.created
 `
 
-type ListParentGroupsRow struct {
-	ID          string    `json:"id"`
-	Name        string    `json:"name"`
-	Permissions string    `json:"permissions"`
-	Created     time.Time `json:"created"`
-	Updated     time.Time `json:"updated"`
-	GroupType   string    `json:"group_type"`
+type ListTicketGrantsRow struct {
+	Ticket   string    `json:"ticket"`
+	User     string    `json:"user"`
+	Created  time.Time `json:"created"`
+	Username string    `json:"username"`
+	UserName *string   `json:"user_name"`
 }
 
-func (q *ReadQueries) ListParentGroups(ctx context.Context, groupID string) ([]ListParentGroupsRow, error) {
-	rows, err := q.db.QueryContext(ctx, listParentGroups, groupID)
+func (q *ReadQueries) ListTicketGrants(ctx context.Context, ticket string) ([]ListTicketGrantsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listTicketGrants, ticket)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []ListParentGroupsRow
+	var items []ListTicketGrantsRow
 	for rows.Next() {
-		var i ListParentGroupsRow
+		var i ListTicketGrantsRow
 		if err := rows.Scan(
-			&i.ID,
-			&i.Name,
-			&i.Permissions,
+			&i.Ticket,
+			&i.User,
 			&i.Created,
-			&i.Updated,
-			&i.GroupType,
+			&i.Username,
+			&i.UserName,
 		); err != nil {
 			return nil, err
 		}
@@ -767,26 +6315,70 @@ func (q *ReadQueries) ListParentGroups(ctx context.Context, groupID string) ([]L
 	return items, nil
 }
 
-const listParentPermissions = `-- name: ListParentPermissions :many
-SELECT group_effective_permissions.permission
-FROM group_effective_permissions
-WHERE parent_group_id = ?1
-ORDER BY permission
+const listTicketMetricNumbers = `-- name: ListTicketMetricNumbers :many
+SELECT CAST(CASE CAST(?1 AS TEXT)
+                WHEN 'day' THEN date(tickets.created)
+                WHEN 'month' THEN date(tickets.created, 'start of month')
+                ELSE date(tickets.created, 'weekday 0', '-6 days') END AS TEXT)                    AS bucket,
+       COUNT(*)                                                                                  AS tickets,
+       COUNT(CASE WHEN json_type(tickets.state, CAST(@path AS TEXT)) IN ('integer', 'real') THEN 1 END) AS count,
+       CAST(coalesce(SUM(CASE WHEN json_type(tickets.state, CAST(@path AS TEXT)) IN ('integer', 'real')
+                                  THEN json_extract(tickets.state, CAST(?2 AS TEXT)) END), 0) AS REAL) AS sum,
+       CAST(coalesce(MIN(CASE WHEN json_type(tickets.state, CAST(@path AS TEXT)) IN ('integer', 'real')
+                                  THEN json_extract(tickets.state, CAST(?2 AS TEXT)) END), 0) AS REAL) AS min,
+       CAST(coalesce(MAX(CASE WHEN json_type(tickets.state, CAST(@path AS TEXT)) IN ('integer', 'real')
+                                  THEN json_extract(tickets.state, CAST(?2 AS TEXT)) END), 0) AS REAL) AS max
+FROM tickets
+WHERE tickets.type = ?3
+  AND datetime(tickets.created) >= datetime(CAST(?4 AS TEXT))
+  AND datetime(tickets.created) < datetime(CAST(?5 AS TEXT))
+GROUP BY bucket
+ORDER BY bucket
 `
 
-func (q *ReadQueries) ListParentPermissions(ctx context.Context, groupID string) ([]string, error) {
-	rows, err := q.db.QueryContext(ctx, listParentPermissions, groupID)
+type ListTicketMetricNumbersParams struct {
+	Interval string `json:"interval"`
+	Path     string `json:"path"`
+	Type     string `json:"type"`
+	Since    string `json:"since"`
+	Until    string `json:"until"`
+}
+
+type ListTicketMetricNumbersRow struct {
+	Bucket  string  `json:"bucket"`
+	Tickets int64   `json:"tickets"`
+	Count   int64   `json:"count"`
+	Sum     float64 `json:"sum"`
+	Min     float64 `json:"min"`
+	Max     float64 `json:"max"`
+}
+
+func (q *ReadQueries) ListTicketMetricNumbers(ctx context.Context, arg ListTicketMetricNumbersParams) ([]ListTicketMetricNumbersRow, error) {
+	rows, err := q.db.QueryContext(ctx, listTicketMetricNumbers,
+		arg.Interval,
+		arg.Path,
+		arg.Type,
+		arg.Since,
+		arg.Until,
+	)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []string
+	var items []ListTicketMetricNumbersRow
 	for rows.Next() {
-		var permission string
-		if err := rows.Scan(&permission); err != nil {
+		var i ListTicketMetricNumbersRow
+		if err := rows.Scan(
+			&i.Bucket,
+			&i.Tickets,
+			&i.Count,
+			&i.Sum,
+			&i.Min,
+			&i.Max,
+		); err != nil {
 			return nil, err
 		}
-		items = append(items, permission)
+		items = append(items, i)
 	}
 	if err := rows.Close(); err != nil {
 		return nil, err
@@ -797,50 +6389,51 @@ func (q *ReadQueries) ListParentPermissions(ctx context.Context, groupID string)
 	return items, nil
 }
 
-const listReactions = `-- name: ListReactions :many
-SELECT reactions.id, reactions.name, reactions."action", reactions.actiondata, reactions."trigger", reactions.triggerdata, reactions.created, reactions.updated, COUNT(*) OVER () as total_count
-FROM reactions
-ORDER BY reactions.created DESC
-LIMIT ?2 OFFSET ?1
+const listTicketMetricValues = `-- name: ListTicketMetricValues :many
+SELECT CAST(CASE CAST(?1 AS TEXT)
+                WHEN 'day' THEN date(tickets.created)
+                WHEN 'month' THEN date(tickets.created, 'start of month')
+                ELSE date(tickets.created, 'weekday 0', '-6 days') END AS TEXT)       AS bucket,
+       CAST(coalesce(json_extract(tickets.state, CAST(?2 AS TEXT)), '') AS TEXT) AS value,
+       COUNT(*)                                                                     AS tickets
+FROM tickets
+WHERE tickets.type = ?3
+  AND datetime(tickets.created) >= datetime(CAST(?4 AS TEXT))
+  AND datetime(tickets.created) < datetime(CAST(?5 AS TEXT))
+GROUP BY bucket, value
+ORDER BY bucket, value
 `
 
-type ListReactionsParams struct {
-	Offset int64 `json:"offset"`
-	Limit  int64 `json:"limit"`
+type ListTicketMetricValuesParams struct {
+	Interval string `json:"interval"`
+	Path     string `json:"path"`
+	Type     string `json:"type"`
+	Since    string `json:"since"`
+	Until    string `json:"until"`
 }
 
-type ListReactionsRow struct {
-	ID          string    `json:"id"`
-	Name        string    `json:"name"`
-	Action      string    `json:"action"`
-	Actiondata  []byte    `json:"actiondata"`
-	Trigger     string    `json:"trigger"`
-	Triggerdata []byte    `json:"triggerdata"`
-	Created     time.Time `json:"created"`
-	Updated     time.Time `json:"updated"`
-	TotalCount  int64     `json:"total_count"`
+type ListTicketMetricValuesRow struct {
+	Bucket  string `json:"bucket"`
+	Value   string `json:"value"`
+	Tickets int64  `json:"tickets"`
 }
 
-func (q *ReadQueries) ListReactions(ctx context.Context, arg ListReactionsParams) ([]ListReactionsRow, error) {
-	rows, err := q.db.QueryContext(ctx, listReactions, arg.Offset, arg.Limit)
+func (q *ReadQueries) ListTicketMetricValues(ctx context.Context, arg ListTicketMetricValuesParams) ([]ListTicketMetricValuesRow, error) {
+	rows, err := q.db.QueryContext(ctx, listTicketMetricValues,
+		arg.Interval,
+		arg.Path,
+		arg.Type,
+		arg.Since,
+		arg.Until,
+	)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []ListReactionsRow
+	var items []ListTicketMetricValuesRow
 	for rows.Next() {
-		var i ListReactionsRow
-		if err := rows.Scan(
-			&i.ID,
-			&i.Name,
-			&i.Action,
-			&i.Actiondata,
-			&i.Trigger,
-			&i.Triggerdata,
-			&i.Created,
-			&i.Updated,
-			&i.TotalCount,
-		); err != nil {
+		var i ListTicketMetricValuesRow
+		if err := rows.Scan(&i.Bucket, &i.Value, &i.Tickets); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -854,51 +6447,48 @@ func (q *ReadQueries) ListReactions(ctx context.Context, arg ListReactionsParams
 	return items, nil
 }
 
-const listReactionsByTrigger = `-- name: ListReactionsByTrigger :many
-SELECT reactions.id, reactions.name, reactions."action", reactions.actiondata, reactions."trigger", reactions.triggerdata, reactions.created, reactions.updated, COUNT(*) OVER () as total_count
-FROM reactions
-WHERE trigger = ?1
-ORDER BY reactions.created DESC
-LIMIT ?3 OFFSET ?2
-`
+const listTicketReopens = `-- name: ListTicketReopens :many
 
-type ListReactionsByTriggerParams struct {
-	Trigger string `json:"trigger"`
-	Offset  int64  `json:"offset"`
-	Limit   int64  `json:"limit"`
-}
+SELECT ticket_reopens.id, ticket_reopens.ticket, ticket_reopens.reason, ticket_reopens.resolution, ticket_reopens.closed_by, ticket_reopens.reopened_by, ticket_reopens.created, closer.name AS closed_by_name, reopener.name AS reopened_by_name
+FROM ticket_reopens
+         LEFT JOIN users AS closer ON closer.id = ticket_reopens.closed_by
+         LEFT JOIN users AS reopener ON reopener.id = ticket_reopens.reopened_by
+WHERE ticket_reopens.ticket = ?1
+ORDER BY ticket_reopens.created
+`
 
-type ListReactionsByTriggerRow struct {
-	ID          string    `json:"id"`
-	Name        string    `json:"name"`
-	Action      string    `json:"action"`
-	Actiondata  []byte    `json:"actiondata"`
-	Trigger     string    `json:"trigger"`
-	Triggerdata []byte    `json:"triggerdata"`
-	Created     time.Time `json:"created"`
-	Updated     time.Time `json:"updated"`
-	TotalCount  int64     `json:"total_count"`
+type ListTicketReopensRow struct {
+	ID             string    `json:"id"`
+	Ticket         string    `json:"ticket"`
+	Reason         string    `json:"reason"`
+	Resolution     *string   `json:"resolution"`
+	ClosedBy       *string   `json:"closed_by"`
+	ReopenedBy     *string   `json:"reopened_by"`
+	Created        time.Time `json:"created"`
+	ClosedByName   *string   `json:"closed_by_name"`
+	ReopenedByName *string   `json:"reopened_by_name"`
 }
 
-func (q *ReadQueries) ListReactionsByTrigger(ctx context.Context, arg ListReactionsByTriggerParams) ([]ListReactionsByTriggerRow, error) {
-	rows, err := q.db.QueryContext(ctx, listReactionsByTrigger, arg.Trigger, arg.Offset, arg.Limit)
+// ----------------------------------------------------------------
+func (q *ReadQueries) ListTicketReopens(ctx context.Context, ticket string) ([]ListTicketReopensRow, error) {
+	rows, err := q.db.QueryContext(ctx, listTicketReopens, ticket)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []ListReactionsByTriggerRow
+	var items []ListTicketReopensRow
 	for rows.Next() {
-		var i ListReactionsByTriggerRow
+		var i ListTicketReopensRow
 		if err := rows.Scan(
 			&i.ID,
-			&i.Name,
-			&i.Action,
-			&i.Actiondata,
-			&i.Trigger,
-			&i.Triggerdata,
+			&i.Ticket,
+			&i.Reason,
+			&i.Resolution,
+			&i.ClosedBy,
+			&i.ReopenedBy,
 			&i.Created,
-			&i.Updated,
-			&i.TotalCount,
+			&i.ClosedByName,
+			&i.ReopenedByName,
 		); err != nil {
 			return nil, err
 		}
@@ -913,63 +6503,71 @@ func (q *ReadQueries) ListReactionsByTrigger(ctx context.Context, arg ListReacti
 	return items, nil
 }
 
-const listTasks = `-- name: ListTasks :many
-SELECT tasks.id, tasks.ticket, tasks.owner, tasks.name, tasks.open, tasks.created, tasks.updated,
-       users.name       as owner_name,
-       tickets.name     as ticket_name,
-       tickets.type     as ticket_type,
-       COUNT(*) OVER () as total_count
-FROM tasks
-         LEFT JOIN users ON users.id = tasks.owner
-         LEFT JOIN tickets ON tickets.id = tasks.ticket
-WHERE ticket = ?1
-   OR ?1 = ''
-ORDER BY tasks.created DESC
-LIMIT ?3 OFFSET ?2
+const listTicketTechniques = `-- name: ListTicketTechniques :many
+
+SELECT ticket, technique, tactic
+FROM ticket_techniques
+WHERE ticket = ?1
+ORDER BY tactic, technique
+`
+
+// ----------------------------------------------------------------
+func (q *ReadQueries) ListTicketTechniques(ctx context.Context, ticket string) ([]TicketTechnique, error) {
+	rows, err := q.db.QueryContext(ctx, listTicketTechniques, ticket)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []TicketTechnique
+	for rows.Next() {
+		var i TicketTechnique
+		if err := rows.Scan(&i.Ticket, &i.Technique, &i.Tactic); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTicketUsage = `-- name: ListTicketUsage :many
+
+SELECT CAST(coalesce(json_extract(state, '$.customer'), '') AS TEXT) AS customer,
+       CAST(strftime('%Y-%m', created) AS TEXT)                     AS month,
+       COUNT(*)                                                      AS tickets
+FROM tickets
+WHERE datetime(created) >= datetime(CAST(?1 AS TEXT))
+  AND datetime(created) < datetime(CAST(?2 AS TEXT))
+GROUP BY customer, month
 `
 
-type ListTasksParams struct {
-	Ticket string `json:"ticket"`
-	Offset int64  `json:"offset"`
-	Limit  int64  `json:"limit"`
+type ListTicketUsageParams struct {
+	Since string `json:"since"`
+	Until string `json:"until"`
 }
 
-type ListTasksRow struct {
-	ID         string    `json:"id"`
-	Ticket     string    `json:"ticket"`
-	Owner      *string   `json:"owner"`
-	Name       string    `json:"name"`
-	Open       bool      `json:"open"`
-	Created    time.Time `json:"created"`
-	Updated    time.Time `json:"updated"`
-	OwnerName  *string   `json:"owner_name"`
-	TicketName *string   `json:"ticket_name"`
-	TicketType *string   `json:"ticket_type"`
-	TotalCount int64     `json:"total_count"`
+type ListTicketUsageRow struct {
+	Customer string `json:"customer"`
+	Month    string `json:"month"`
+	Tickets  int64  `json:"tickets"`
 }
 
-func (q *ReadQueries) ListTasks(ctx context.Context, arg ListTasksParams) ([]ListTasksRow, error) {
-	rows, err := q.db.QueryContext(ctx, listTasks, arg.Ticket, arg.Offset, arg.Limit)
+// ----------------------------------------------------------------
+func (q *ReadQueries) ListTicketUsage(ctx context.Context, arg ListTicketUsageParams) ([]ListTicketUsageRow, error) {
+	rows, err := q.db.QueryContext(ctx, listTicketUsage, arg.Since, arg.Until)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []ListTasksRow
+	var items []ListTicketUsageRow
 	for rows.Next() {
-		var i ListTasksRow
-		if err := rows.Scan(
-			&i.ID,
-			&i.Ticket,
-			&i.Owner,
-			&i.Name,
-			&i.Open,
-			&i.Created,
-			&i.Updated,
-			&i.OwnerName,
-			&i.TicketName,
-			&i.TicketType,
-			&i.TotalCount,
-		); err != nil {
+		var i ListTicketUsageRow
+		if err := rows.Scan(&i.Customer, &i.Month, &i.Tickets); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -984,7 +6582,7 @@ func (q *ReadQueries) ListTasks(ctx context.Context, arg ListTasksParams) ([]Lis
 }
 
 const listTickets = `-- name: ListTickets :many
-SELECT tickets.id, tickets.type, tickets.owner, tickets.name, tickets.description, tickets.open, tickets.resolution, tickets.schema, tickets.state, tickets.created, tickets.updated,
+SELECT tickets.id, tickets.type, tickets.owner, tickets.name, tickets.description, tickets.open, tickets.resolution, tickets.schema, tickets.state, tickets.created, tickets.updated, tickets.acknowledged, tickets.acknowledged_by, tickets.resolved, tickets.encrypted, tickets."key", tickets.resolved_by, tickets.reopen_count,
        users.name       as owner_name,
        types.singular   as type_singular,
        types.plural     as type_plural,
@@ -992,35 +6590,45 @@ SELECT tickets.id, tickets.type, tickets.owner, tickets.name, tickets.descriptio
 FROM tickets
          LEFT JOIN users ON users.id = tickets.owner
          LEFT JOIN types ON types.id = tickets.type
+WHERE ?1 IS NULL
+   OR (tickets.acknowledged IS NOT NULL) = ?1
 ORDER BY tickets.created DESC
-LIMIT ?2 OFFSET ?1
+LIMIT ?3 OFFSET ?2
 `
 
 type ListTicketsParams struct {
-	Offset int64 `json:"offset"`
-	Limit  int64 `json:"limit"`
+	Acknowledged interface{} `json:"acknowledged"`
+	Offset       int64       `json:"offset"`
+	Limit        int64       `json:"limit"`
 }
 
 type ListTicketsRow struct {
-	ID           string    `json:"id"`
-	Type         string    `json:"type"`
-	Owner        *string   `json:"owner"`
-	Name         string    `json:"name"`
-	Description  string    `json:"description"`
-	Open         bool      `json:"open"`
-	Resolution   *string   `json:"resolution"`
-	Schema       []byte    `json:"schema"`
-	State        []byte    `json:"state"`
-	Created      time.Time `json:"created"`
-	Updated      time.Time `json:"updated"`
-	OwnerName    *string   `json:"owner_name"`
-	TypeSingular *string   `json:"type_singular"`
-	TypePlural   *string   `json:"type_plural"`
-	TotalCount   int64     `json:"total_count"`
+	ID             string     `json:"id"`
+	Type           string     `json:"type"`
+	Owner          *string    `json:"owner"`
+	Name           string     `json:"name"`
+	Description    string     `json:"description"`
+	Open           bool       `json:"open"`
+	Resolution     *string    `json:"resolution"`
+	Schema         []byte     `json:"schema"`
+	State          []byte     `json:"state"`
+	Created        time.Time  `json:"created"`
+	Updated        time.Time  `json:"updated"`
+	Acknowledged   *time.Time `json:"acknowledged"`
+	AcknowledgedBy *string    `json:"acknowledged_by"`
+	Resolved       *time.Time `json:"resolved"`
+	Encrypted      bool       `json:"encrypted"`
+	Key            *string    `json:"key"`
+	ResolvedBy     *string    `json:"resolved_by"`
+	ReopenCount    int64      `json:"reopen_count"`
+	OwnerName      *string    `json:"owner_name"`
+	TypeSingular   *string    `json:"type_singular"`
+	TypePlural     *string    `json:"type_plural"`
+	TotalCount     int64      `json:"total_count"`
 }
 
 func (q *ReadQueries) ListTickets(ctx context.Context, arg ListTicketsParams) ([]ListTicketsRow, error) {
-	rows, err := q.db.QueryContext(ctx, listTickets, arg.Offset, arg.Limit)
+	rows, err := q.db.QueryContext(ctx, listTickets, arg.Acknowledged, arg.Offset, arg.Limit)
 	if err != nil {
 		return nil, err
 	}
@@ -1040,6 +6648,13 @@ func (q *ReadQueries) ListTickets(ctx context.Context, arg ListTicketsParams) ([
 			&i.State,
 			&i.Created,
 			&i.Updated,
+			&i.Acknowledged,
+			&i.AcknowledgedBy,
+			&i.Resolved,
+			&i.Encrypted,
+			&i.Key,
+			&i.ResolvedBy,
+			&i.ReopenCount,
 			&i.OwnerName,
 			&i.TypeSingular,
 			&i.TypePlural,
@@ -1058,6 +6673,45 @@ func (q *ReadQueries) ListTickets(ctx context.Context, arg ListTicketsParams) ([
 	return items, nil
 }
 
+const listTicketsWithFeature = `-- name: ListTicketsWithFeature :many
+SELECT ticket_features.ticket
+FROM ticket_features
+         JOIN tickets ON tickets.id = ticket_features.ticket
+WHERE ticket_features.kind = ?1
+  AND ticket_features.value = ?2
+  AND datetime(tickets.created) >= datetime(CAST(?3 AS TEXT))
+ORDER BY tickets.created
+`
+
+type ListTicketsWithFeatureParams struct {
+	Kind  string `json:"kind"`
+	Value string `json:"value"`
+	Since string `json:"since"`
+}
+
+func (q *ReadQueries) ListTicketsWithFeature(ctx context.Context, arg ListTicketsWithFeatureParams) ([]string, error) {
+	rows, err := q.db.QueryContext(ctx, listTicketsWithFeature, arg.Kind, arg.Value, arg.Since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var ticket string
+		if err := rows.Scan(&ticket); err != nil {
+			return nil, err
+		}
+		items = append(items, ticket)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listTimeline = `-- name: ListTimeline :many
 SELECT timeline.id, timeline.ticket, timeline.message, timeline.time, timeline.created, timeline.updated, COUNT(*) OVER () as total_count
 FROM timeline
@@ -1114,8 +6768,38 @@ func (q *ReadQueries) ListTimeline(ctx context.Context, arg ListTimelineParams)
 	return items, nil
 }
 
+const listTypeTechniques = `-- name: ListTypeTechniques :many
+SELECT type, technique, tactic
+FROM type_techniques
+WHERE type = ?1
+ORDER BY tactic, technique
+`
+
+func (q *ReadQueries) ListTypeTechniques(ctx context.Context, type_ string) ([]TypeTechnique, error) {
+	rows, err := q.db.QueryContext(ctx, listTypeTechniques, type_)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []TypeTechnique
+	for rows.Next() {
+		var i TypeTechnique
+		if err := rows.Scan(&i.Type, &i.Technique, &i.Tactic); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listTypes = `-- name: ListTypes :many
-SELECT types.id, types.icon, types.singular, types.plural, types.schema, types.created, types.updated, COUNT(*) OVER () as total_count
+SELECT types.id, types.icon, types.singular, types.plural, types.schema, types.created, types.updated, types.key_prefix, types.metrics, COUNT(*) OVER () as total_count
 FROM types
 ORDER BY created DESC
 LIMIT ?2 OFFSET ?1
@@ -1134,6 +6818,8 @@ type ListTypesRow struct {
 	Schema     []byte    `json:"schema"`
 	Created    time.Time `json:"created"`
 	Updated    time.Time `json:"updated"`
+	KeyPrefix  *string   `json:"key_prefix"`
+	Metrics    []byte    `json:"metrics"`
 	TotalCount int64     `json:"total_count"`
 }
 
@@ -1154,6 +6840,8 @@ func (q *ReadQueries) ListTypes(ctx context.Context, arg ListTypesParams) ([]Lis
 			&i.Schema,
 			&i.Created,
 			&i.Updated,
+			&i.KeyPrefix,
+			&i.Metrics,
 			&i.TotalCount,
 		); err != nil {
 			return nil, err
@@ -1367,6 +7055,100 @@ func (q *ReadQueries) ListWebhooks(ctx context.Context, arg ListWebhooksParams)
 	return items, nil
 }
 
+const listWorkEntries = `-- name: ListWorkEntries :many
+
+SELECT work_entries.id, work_entries.ticket, work_entries.user, work_entries.description, work_entries.started, work_entries.duration, work_entries.manual, work_entries.created, work_entries.updated, users.name AS user_name
+FROM work_entries
+         LEFT JOIN users ON users.id = work_entries.user
+WHERE work_entries.ticket = ?1
+ORDER BY work_entries.started
+`
+
+type ListWorkEntriesRow struct {
+	ID          string    `json:"id"`
+	Ticket      string    `json:"ticket"`
+	User        string    `json:"user"`
+	Description string    `json:"description"`
+	Started     time.Time `json:"started"`
+	Duration    *int64    `json:"duration"`
+	Manual      bool      `json:"manual"`
+	Created     time.Time `json:"created"`
+	Updated     time.Time `json:"updated"`
+	UserName    *string   `json:"user_name"`
+}
+
+// ----------------------------------------------------------------
+func (q *ReadQueries) ListWorkEntries(ctx context.Context, ticket string) ([]ListWorkEntriesRow, error) {
+	rows, err := q.db.QueryContext(ctx, listWorkEntries, ticket)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListWorkEntriesRow
+	for rows.Next() {
+		var i ListWorkEntriesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Ticket,
+			&i.User,
+			&i.Description,
+			&i.Started,
+			&i.Duration,
+			&i.Manual,
+			&i.Created,
+			&i.Updated,
+			&i.UserName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const matchFeedIndicators = `-- name: MatchFeedIndicators :many
+SELECT feed_indicators.feed, feed_indicators.kind, feeds.imported
+FROM feed_indicators
+         JOIN feeds ON feeds.name = feed_indicators.feed
+WHERE feed_indicators.value = ?1
+ORDER BY feed_indicators.feed
+`
+
+type MatchFeedIndicatorsRow struct {
+	Feed     string    `json:"feed"`
+	Kind     string    `json:"kind"`
+	Imported time.Time `json:"imported"`
+}
+
+func (q *ReadQueries) MatchFeedIndicators(ctx context.Context, value string) ([]MatchFeedIndicatorsRow, error) {
+	rows, err := q.db.QueryContext(ctx, matchFeedIndicators, value)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []MatchFeedIndicatorsRow
+	for rows.Next() {
+		var i MatchFeedIndicatorsRow
+		if err := rows.Scan(&i.Feed, &i.Kind, &i.Imported); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const param = `-- name: Param :one
 SELECT "key", value
 FROM _params
@@ -1380,6 +7162,132 @@ func (q *ReadQueries) Param(ctx context.Context, key string) (Param, error) {
 	return i, err
 }
 
+const searchFilesByHash = `-- name: SearchFilesByHash :many
+SELECT files.id,
+       files.name,
+       files.size,
+       files.created,
+       tickets.id   AS ticket,
+       tickets.key  AS ticket_key,
+       tickets.name AS ticket_name
+FROM files
+         JOIN tickets ON tickets.id = files.ticket
+WHERE files.md5 = ?1
+   OR files.sha1 = ?1
+   OR files.sha256 = ?1
+ORDER BY files.created DESC, files.id
+`
+
+type SearchFilesByHashRow struct {
+	ID         string    `json:"id"`
+	Name       string    `json:"name"`
+	Size       float64   `json:"size"`
+	Created    time.Time `json:"created"`
+	Ticket     string    `json:"ticket"`
+	TicketKey  *string   `json:"ticket_key"`
+	TicketName string    `json:"ticket_name"`
+}
+
+func (q *ReadQueries) SearchFilesByHash(ctx context.Context, hash string) ([]SearchFilesByHashRow, error) {
+	rows, err := q.db.QueryContext(ctx, searchFilesByHash, hash)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SearchFilesByHashRow
+	for rows.Next() {
+		var i SearchFilesByHashRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Size,
+			&i.Created,
+			&i.Ticket,
+			&i.TicketKey,
+			&i.TicketName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const searchTicketArtifacts = `-- name: SearchTicketArtifacts :many
+SELECT tickets.id,
+       tickets.key,
+       tickets.name,
+       tickets.type,
+       tickets.open,
+       ticket_artifacts.created,
+       COUNT(*) OVER () AS total_count
+FROM ticket_artifacts
+         JOIN tickets ON tickets.id = ticket_artifacts.ticket
+WHERE ticket_artifacts.kind = ?1
+  AND ticket_artifacts.value = ?2
+ORDER BY ticket_artifacts.created DESC, tickets.id
+LIMIT ?4 OFFSET ?3
+`
+
+type SearchTicketArtifactsParams struct {
+	Kind   string `json:"kind"`
+	Value  string `json:"value"`
+	Offset int64  `json:"offset"`
+	Limit  int64  `json:"limit"`
+}
+
+type SearchTicketArtifactsRow struct {
+	ID         string    `json:"id"`
+	Key        *string   `json:"key"`
+	Name       string    `json:"name"`
+	Type       string    `json:"type"`
+	Open       bool      `json:"open"`
+	Created    time.Time `json:"created"`
+	TotalCount int64     `json:"total_count"`
+}
+
+func (q *ReadQueries) SearchTicketArtifacts(ctx context.Context, arg SearchTicketArtifactsParams) ([]SearchTicketArtifactsRow, error) {
+	rows, err := q.db.QueryContext(ctx, searchTicketArtifacts,
+		arg.Kind,
+		arg.Value,
+		arg.Offset,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SearchTicketArtifactsRow
+	for rows.Next() {
+		var i SearchTicketArtifactsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Key,
+			&i.Name,
+			&i.Type,
+			&i.Open,
+			&i.Created,
+			&i.TotalCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const searchTickets = `-- name: SearchTickets :many
 SELECT id,
        name,
@@ -1392,8 +7300,9 @@ SELECT id,
        COUNT(*) OVER () as total_count
 FROM ticket_search
 WHERE (?1 = '' OR (name LIKE '%' || ?1 || '%'
-    OR description LIKE '%' || ?1 || '%'
-    OR comment_messages LIKE '%' || ?1 || '%'
+    -- the description and comments of encrypted tickets are not searchable
+    OR (NOT EXISTS (SELECT 1 FROM ticket_keys WHERE ticket_keys.ticket = ticket_search.id)
+        AND (description LIKE '%' || ?1 || '%' OR comment_messages LIKE '%' || ?1 || '%'))
     OR file_names LIKE '%' || ?1 || '%'
     OR link_names LIKE '%' || ?1 || '%'
     OR link_urls LIKE '%' || ?1 || '%'
@@ -1492,7 +7401,7 @@ func (q *ReadQueries) SystemUser(ctx context.Context) (User, error) {
 
 const ticket = `-- name: Ticket :one
 
-SELECT tickets.id, tickets.type, tickets.owner, tickets.name, tickets.description, tickets.open, tickets.resolution, tickets.schema, tickets.state, tickets.created, tickets.updated, users.name as owner_name, types.singular as type_singular, types.plural as type_plural
+SELECT tickets.id, tickets.type, tickets.owner, tickets.name, tickets.description, tickets.open, tickets.resolution, tickets.schema, tickets.state, tickets.created, tickets.updated, tickets.acknowledged, tickets.acknowledged_by, tickets.resolved, tickets.encrypted, tickets."key", tickets.resolved_by, tickets.reopen_count, users.name as owner_name, types.singular as type_singular, types.plural as type_plural
 FROM tickets
          LEFT JOIN users ON users.id = tickets.owner
          LEFT JOIN types ON types.id = tickets.type
@@ -1500,20 +7409,27 @@ WHERE tickets.id = ?1
 `
 
 type TicketRow struct {
-	ID           string    `json:"id"`
-	Type         string    `json:"type"`
-	Owner        *string   `json:"owner"`
-	Name         string    `json:"name"`
-	Description  string    `json:"description"`
-	Open         bool      `json:"open"`
-	Resolution   *string   `json:"resolution"`
-	Schema       []byte    `json:"schema"`
-	State        []byte    `json:"state"`
-	Created      time.Time `json:"created"`
-	Updated      time.Time `json:"updated"`
-	OwnerName    *string   `json:"owner_name"`
-	TypeSingular *string   `json:"type_singular"`
-	TypePlural   *string   `json:"type_plural"`
+	ID             string     `json:"id"`
+	Type           string     `json:"type"`
+	Owner          *string    `json:"owner"`
+	Name           string     `json:"name"`
+	Description    string     `json:"description"`
+	Open           bool       `json:"open"`
+	Resolution     *string    `json:"resolution"`
+	Schema         []byte     `json:"schema"`
+	State          []byte     `json:"state"`
+	Created        time.Time  `json:"created"`
+	Updated        time.Time  `json:"updated"`
+	Acknowledged   *time.Time `json:"acknowledged"`
+	AcknowledgedBy *string    `json:"acknowledged_by"`
+	Resolved       *time.Time `json:"resolved"`
+	Encrypted      bool       `json:"encrypted"`
+	Key            *string    `json:"key"`
+	ResolvedBy     *string    `json:"resolved_by"`
+	ReopenCount    int64      `json:"reopen_count"`
+	OwnerName      *string    `json:"owner_name"`
+	TypeSingular   *string    `json:"type_singular"`
+	TypePlural     *string    `json:"type_plural"`
 }
 
 // -----------------------------------------------------------------
@@ -1532,6 +7448,13 @@ func (q *ReadQueries) Ticket(ctx context.Context, id string) (TicketRow, error)
 		&i.State,
 		&i.Created,
 		&i.Updated,
+		&i.Acknowledged,
+		&i.AcknowledgedBy,
+		&i.Resolved,
+		&i.Encrypted,
+		&i.Key,
+		&i.ResolvedBy,
+		&i.ReopenCount,
 		&i.OwnerName,
 		&i.TypeSingular,
 		&i.TypePlural,
@@ -1539,6 +7462,19 @@ func (q *ReadQueries) Ticket(ctx context.Context, id string) (TicketRow, error)
 	return i, err
 }
 
+const ticketIDByKey = `-- name: TicketIDByKey :one
+SELECT id
+FROM tickets
+WHERE key = ?1
+`
+
+func (q *ReadQueries) TicketIDByKey(ctx context.Context, key *string) (string, error) {
+	row := q.db.QueryRowContext(ctx, ticketIDByKey, key)
+	var id string
+	err := row.Scan(&id)
+	return id, err
+}
+
 const userByEmail = `-- name: UserByEmail :one
 SELECT id, username, passwordhash, tokenkey, active, name, email, avatar, lastresetsentat, lastverificationsentat, created, updated
 FROM users
@@ -10,50 +10,2556 @@ import (
 	"time"
 )
 
+const acceptInvitation = `-- name: AcceptInvitation :one
+UPDATE invitations
+SET accepted_by = ?1,
+    updated     = CURRENT_TIMESTAMP
+WHERE id = ?2
+  AND accepted_by IS NULL
+RETURNING id, email, name, "groups", token_hash, invited_by, expires, accepted_by, created, updated
+`
+
+type AcceptInvitationParams struct {
+	AcceptedBy *string `json:"accepted_by"`
+	ID         string  `json:"id"`
+}
+
+func (q *WriteQueries) AcceptInvitation(ctx context.Context, arg AcceptInvitationParams) (Invitation, error) {
+	row := q.db.QueryRowContext(ctx, acceptInvitation, arg.AcceptedBy, arg.ID)
+	var i Invitation
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.Name,
+		&i.Groups,
+		&i.TokenHash,
+		&i.InvitedBy,
+		&i.Expires,
+		&i.AcceptedBy,
+		&i.Created,
+		&i.Updated,
+	)
+	return i, err
+}
+
+const acknowledgeAnnouncement = `-- name: AcknowledgeAnnouncement :exec
+INSERT INTO announcement_receipts (announcement, user, read, acknowledged)
+VALUES (?1, ?2, ?3, ?3)
+ON CONFLICT (announcement, user) DO UPDATE SET acknowledged = coalesce(announcement_receipts.acknowledged, excluded.acknowledged)
+`
+
+type AcknowledgeAnnouncementParams struct {
+	Announcement string    `json:"announcement"`
+	User         string    `json:"user"`
+	Acknowledged time.Time `json:"acknowledged"`
+}
+
+func (q *WriteQueries) AcknowledgeAnnouncement(ctx context.Context, arg AcknowledgeAnnouncementParams) error {
+	_, err := q.db.ExecContext(ctx, acknowledgeAnnouncement, arg.Announcement, arg.User, arg.Acknowledged)
+	return err
+}
+
+const acknowledgeTicket = `-- name: AcknowledgeTicket :one
+UPDATE tickets
+SET acknowledged_by = CASE WHEN acknowledged IS NULL THEN ?1 ELSE acknowledged_by END,
+    acknowledged    = coalesce(acknowledged, CURRENT_TIMESTAMP),
+    updated         = CURRENT_TIMESTAMP
+WHERE id = ?2
+RETURNING id, type, owner, name, description, open, resolution, schema, state, created, updated, acknowledged, acknowledged_by, resolved, encrypted, "key", resolved_by, reopen_count
+`
+
+type AcknowledgeTicketParams struct {
+	AcknowledgedBy *string `json:"acknowledged_by"`
+	ID             string  `json:"id"`
+}
+
+func (q *WriteQueries) AcknowledgeTicket(ctx context.Context, arg AcknowledgeTicketParams) (Ticket, error) {
+	row := q.db.QueryRowContext(ctx, acknowledgeTicket, arg.AcknowledgedBy, arg.ID)
+	var i Ticket
+	err := row.Scan(
+		&i.ID,
+		&i.Type,
+		&i.Owner,
+		&i.Name,
+		&i.Description,
+		&i.Open,
+		&i.Resolution,
+		&i.Schema,
+		&i.State,
+		&i.Created,
+		&i.Updated,
+		&i.Acknowledged,
+		&i.AcknowledgedBy,
+		&i.Resolved,
+		&i.Encrypted,
+		&i.Key,
+		&i.ResolvedBy,
+		&i.ReopenCount,
+	)
+	return i, err
+}
+
+const acknowledgeTicketEscalation = `-- name: AcknowledgeTicketEscalation :one
+UPDATE ticket_escalations
+SET acknowledged_by = ?1,
+    acknowledged_at = CURRENT_TIMESTAMP,
+    next_at         = NULL,
+    updated         = CURRENT_TIMESTAMP
+WHERE ticket = ?2
+  AND acknowledged_at IS NULL
+RETURNING ticket, policy, step, next_at, acknowledged_by, acknowledged_at, created, updated, paused_since, paused
+`
+
+type AcknowledgeTicketEscalationParams struct {
+	AcknowledgedBy *string `json:"acknowledged_by"`
+	Ticket         string  `json:"ticket"`
+}
+
+func (q *WriteQueries) AcknowledgeTicketEscalation(ctx context.Context, arg AcknowledgeTicketEscalationParams) (TicketEscalation, error) {
+	row := q.db.QueryRowContext(ctx, acknowledgeTicketEscalation, arg.AcknowledgedBy, arg.Ticket)
+	var i TicketEscalation
+	err := row.Scan(
+		&i.Ticket,
+		&i.Policy,
+		&i.Step,
+		&i.NextAt,
+		&i.AcknowledgedBy,
+		&i.AcknowledgedAt,
+		&i.Created,
+		&i.Updated,
+		&i.PausedSince,
+		&i.Paused,
+	)
+	return i, err
+}
+
+const addAPIUsage = `-- name: AddAPIUsage :exec
+INSERT INTO api_usage (user, client, day, requests)
+VALUES (?1, ?2, ?3, ?4)
+ON CONFLICT (user, client, day) DO UPDATE SET requests = api_usage.requests + excluded.requests
+`
+
+type AddAPIUsageParams struct {
+	User     string `json:"user"`
+	Client   string `json:"client"`
+	Day      string `json:"day"`
+	Requests int64  `json:"requests"`
+}
+
+func (q *WriteQueries) AddAPIUsage(ctx context.Context, arg AddAPIUsageParams) error {
+	_, err := q.db.ExecContext(ctx, addAPIUsage,
+		arg.User,
+		arg.Client,
+		arg.Day,
+		arg.Requests,
+	)
+	return err
+}
+
+const addArtifactSighting = `-- name: AddArtifactSighting :exec
+
+INSERT INTO artifact_sightings (kind, value, ticket, source, reference)
+VALUES (?1, ?2, ?3, ?4, ?5)
+`
+
+type AddArtifactSightingParams struct {
+	Kind      string  `json:"kind"`
+	Value     string  `json:"value"`
+	Ticket    *string `json:"ticket"`
+	Source    string  `json:"source"`
+	Reference string  `json:"reference"`
+}
+
+// ----------------------------------------------------------------
+func (q *WriteQueries) AddArtifactSighting(ctx context.Context, arg AddArtifactSightingParams) error {
+	_, err := q.db.ExecContext(ctx, addArtifactSighting,
+		arg.Kind,
+		arg.Value,
+		arg.Ticket,
+		arg.Source,
+		arg.Reference,
+	)
+	return err
+}
+
+const addCampaignTicket = `-- name: AddCampaignTicket :one
+INSERT INTO campaign_tickets (campaign, ticket, run)
+VALUES (?1, ?2, ?3)
+RETURNING campaign, ticket, run, created
+`
+
+type AddCampaignTicketParams struct {
+	Campaign string  `json:"campaign"`
+	Ticket   string  `json:"ticket"`
+	Run      *string `json:"run"`
+}
+
+func (q *WriteQueries) AddCampaignTicket(ctx context.Context, arg AddCampaignTicketParams) (CampaignTicket, error) {
+	row := q.db.QueryRowContext(ctx, addCampaignTicket, arg.Campaign, arg.Ticket, arg.Run)
+	var i CampaignTicket
+	err := row.Scan(
+		&i.Campaign,
+		&i.Ticket,
+		&i.Run,
+		&i.Created,
+	)
+	return i, err
+}
+
+const addDetectionRuleTicket = `-- name: AddDetectionRuleTicket :exec
+INSERT OR IGNORE INTO detection_rule_tickets (rule, ticket)
+VALUES (?1, ?2)
+`
+
+type AddDetectionRuleTicketParams struct {
+	Rule   string `json:"rule"`
+	Ticket string `json:"ticket"`
+}
+
+func (q *WriteQueries) AddDetectionRuleTicket(ctx context.Context, arg AddDetectionRuleTicketParams) error {
+	_, err := q.db.ExecContext(ctx, addDetectionRuleTicket, arg.Rule, arg.Ticket)
+	return err
+}
+
+const addPlaybookRunTask = `-- name: AddPlaybookRunTask :exec
+INSERT INTO playbook_run_tasks (run, task)
+VALUES (?1, ?2)
+`
+
+type AddPlaybookRunTaskParams struct {
+	Run  string `json:"run"`
+	Task string `json:"task"`
+}
+
+func (q *WriteQueries) AddPlaybookRunTask(ctx context.Context, arg AddPlaybookRunTaskParams) error {
+	_, err := q.db.ExecContext(ctx, addPlaybookRunTask, arg.Run, arg.Task)
+	return err
+}
+
+const addTicketArtifact = `-- name: AddTicketArtifact :execrows
+
+INSERT OR IGNORE INTO ticket_artifacts (ticket, kind, value)
+VALUES (?1, ?2, ?3)
+`
+
+type AddTicketArtifactParams struct {
+	Ticket string `json:"ticket"`
+	Kind   string `json:"kind"`
+	Value  string `json:"value"`
+}
+
+// ----------------------------------------------------------------
+func (q *WriteQueries) AddTicketArtifact(ctx context.Context, arg AddTicketArtifactParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, addTicketArtifact, arg.Ticket, arg.Kind, arg.Value)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const addTicketCVE = `-- name: AddTicketCVE :exec
+INSERT OR IGNORE INTO ticket_cves (ticket, cve)
+VALUES (?1, ?2)
+`
+
+type AddTicketCVEParams struct {
+	Ticket string `json:"ticket"`
+	Cve    string `json:"cve"`
+}
+
+func (q *WriteQueries) AddTicketCVE(ctx context.Context, arg AddTicketCVEParams) error {
+	_, err := q.db.ExecContext(ctx, addTicketCVE, arg.Ticket, arg.Cve)
+	return err
+}
+
+const addTicketTechnique = `-- name: AddTicketTechnique :exec
+
+INSERT OR IGNORE INTO ticket_techniques (ticket, technique, tactic)
+VALUES (?1, ?2, ?3)
+`
+
+type AddTicketTechniqueParams struct {
+	Ticket    string `json:"ticket"`
+	Technique string `json:"technique"`
+	Tactic    string `json:"tactic"`
+}
+
+// ----------------------------------------------------------------
+func (q *WriteQueries) AddTicketTechnique(ctx context.Context, arg AddTicketTechniqueParams) error {
+	_, err := q.db.ExecContext(ctx, addTicketTechnique, arg.Ticket, arg.Technique, arg.Tactic)
+	return err
+}
+
+const addTypeTechnique = `-- name: AddTypeTechnique :exec
+INSERT OR IGNORE INTO type_techniques (type, technique, tactic)
+VALUES (?1, ?2, ?3)
+`
+
+type AddTypeTechniqueParams struct {
+	Type      string `json:"type"`
+	Technique string `json:"technique"`
+	Tactic    string `json:"tactic"`
+}
+
+func (q *WriteQueries) AddTypeTechnique(ctx context.Context, arg AddTypeTechniqueParams) error {
+	_, err := q.db.ExecContext(ctx, addTypeTechnique, arg.Type, arg.Technique, arg.Tactic)
+	return err
+}
+
+const advanceTicketEscalation = `-- name: AdvanceTicketEscalation :exec
+UPDATE ticket_escalations
+SET step    = ?1,
+    next_at = ?2,
+    updated = CURRENT_TIMESTAMP
+WHERE ticket = ?3
+`
+
+type AdvanceTicketEscalationParams struct {
+	Step   int64      `json:"step"`
+	NextAt *time.Time `json:"next_at"`
+	Ticket string     `json:"ticket"`
+}
+
+func (q *WriteQueries) AdvanceTicketEscalation(ctx context.Context, arg AdvanceTicketEscalationParams) error {
+	_, err := q.db.ExecContext(ctx, advanceTicketEscalation, arg.Step, arg.NextAt, arg.Ticket)
+	return err
+}
+
 const assignGroupToUser = `-- name: AssignGroupToUser :exec
 INSERT INTO user_groups (user_id, group_id)
 VALUES (?1, ?2)
 `
 
-type AssignGroupToUserParams struct {
-	UserID  string `json:"user_id"`
-	GroupID string `json:"group_id"`
+type AssignGroupToUserParams struct {
+	UserID  string `json:"user_id"`
+	GroupID string `json:"group_id"`
+}
+
+func (q *WriteQueries) AssignGroupToUser(ctx context.Context, arg AssignGroupToUserParams) error {
+	_, err := q.db.ExecContext(ctx, assignGroupToUser, arg.UserID, arg.GroupID)
+	return err
+}
+
+const assignParentGroup = `-- name: AssignParentGroup :exec
+INSERT INTO group_inheritance (parent_group_id, child_group_id)
+VALUES (?1, ?2)
+`
+
+type AssignParentGroupParams struct {
+	ParentGroupID string `json:"parent_group_id"`
+	ChildGroupID  string `json:"child_group_id"`
+}
+
+func (q *WriteQueries) AssignParentGroup(ctx context.Context, arg AssignParentGroupParams) error {
+	_, err := q.db.ExecContext(ctx, assignParentGroup, arg.ParentGroupID, arg.ChildGroupID)
+	return err
+}
+
+const claimNextTicket = `-- name: ClaimNextTicket :one
+UPDATE tickets
+SET owner   = ?1,
+    updated = CURRENT_TIMESTAMP
+WHERE id = (SELECT candidates.id
+            FROM tickets AS candidates,
+                 queues
+            WHERE queues.id = ?2
+              AND candidates.open
+              AND candidates.owner IS NULL
+              AND NOT candidates.encrypted
+              AND (coalesce(json_array_length(queues.filter, '$.types'), 0) = 0
+                OR lower(candidates.type) IN (SELECT lower(value) FROM json_each(queues.filter, '$.types')))
+              AND (coalesce(json_array_length(queues.filter, '$.severities'), 0) = 0
+                OR (json_type(candidates.state, '$.severity') = 'text'
+                  AND lower(json_extract(candidates.state, '$.severity')) IN
+                      (SELECT lower(value) FROM json_each(queues.filter, '$.severities'))))
+              AND (coalesce(json_array_length(queues.filter, '$.tags'), 0) = 0
+                OR (json_type(candidates.state, '$.tags') = 'array'
+                  AND EXISTS (SELECT 1
+                              FROM json_each(candidates.state, '$.tags') AS tag
+                              WHERE tag.type = 'text'
+                                AND lower(tag.value) IN (SELECT lower(value) FROM json_each(queues.filter, '$.tags')))))
+            ORDER BY candidates.created, candidates.id
+            LIMIT 1)
+  AND open
+  AND owner IS NULL
+RETURNING id, type, owner, name, description, open, resolution, schema, state, created, updated, acknowledged, acknowledged_by, resolved, encrypted, "key", resolved_by, reopen_count
+`
+
+type ClaimNextTicketParams struct {
+	Owner *string `json:"owner"`
+	Queue string  `json:"queue"`
+}
+
+// ClaimNextTicket assigns the oldest open, unencrypted ticket without an
+// owner that matches the filter of the queue. It is a single statement, so
+// concurrent claims get different tickets and skip none.
+func (q *WriteQueries) ClaimNextTicket(ctx context.Context, arg ClaimNextTicketParams) (Ticket, error) {
+	row := q.db.QueryRowContext(ctx, claimNextTicket, arg.Owner, arg.Queue)
+	var i Ticket
+	err := row.Scan(
+		&i.ID,
+		&i.Type,
+		&i.Owner,
+		&i.Name,
+		&i.Description,
+		&i.Open,
+		&i.Resolution,
+		&i.Schema,
+		&i.State,
+		&i.Created,
+		&i.Updated,
+		&i.Acknowledged,
+		&i.AcknowledgedBy,
+		&i.Resolved,
+		&i.Encrypted,
+		&i.Key,
+		&i.ResolvedBy,
+		&i.ReopenCount,
+	)
+	return i, err
+}
+
+const createAlert = `-- name: CreateAlert :one
+
+INSERT INTO alerts (source, name, description, severity, data, maintenance)
+VALUES (?1, ?2, ?3, ?4, ?5, ?6)
+RETURNING id, source, name, description, severity, data, status, ticket, created, updated, maintenance
+`
+
+type CreateAlertParams struct {
+	Source      string  `json:"source"`
+	Name        string  `json:"name"`
+	Description string  `json:"description"`
+	Severity    string  `json:"severity"`
+	Data        []byte  `json:"data"`
+	Maintenance *string `json:"maintenance"`
+}
+
+// ----------------------------------------------------------------
+func (q *WriteQueries) CreateAlert(ctx context.Context, arg CreateAlertParams) (Alert, error) {
+	row := q.db.QueryRowContext(ctx, createAlert,
+		arg.Source,
+		arg.Name,
+		arg.Description,
+		arg.Severity,
+		arg.Data,
+		arg.Maintenance,
+	)
+	var i Alert
+	err := row.Scan(
+		&i.ID,
+		&i.Source,
+		&i.Name,
+		&i.Description,
+		&i.Severity,
+		&i.Data,
+		&i.Status,
+		&i.Ticket,
+		&i.Created,
+		&i.Updated,
+		&i.Maintenance,
+	)
+	return i, err
+}
+
+const createAlertMapping = `-- name: CreateAlertMapping :one
+
+INSERT INTO alert_mappings (name, source, template, template_version, fields)
+VALUES (?1, ?2, ?3, ?4, ?5)
+RETURNING id, name, source, template, template_version, fields, created, updated
+`
+
+type CreateAlertMappingParams struct {
+	Name            string `json:"name"`
+	Source          string `json:"source"`
+	Template        string `json:"template"`
+	TemplateVersion int64  `json:"template_version"`
+	Fields          []byte `json:"fields"`
+}
+
+// ----------------------------------------------------------------
+func (q *WriteQueries) CreateAlertMapping(ctx context.Context, arg CreateAlertMappingParams) (AlertMapping, error) {
+	row := q.db.QueryRowContext(ctx, createAlertMapping,
+		arg.Name,
+		arg.Source,
+		arg.Template,
+		arg.TemplateVersion,
+		arg.Fields,
+	)
+	var i AlertMapping
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Source,
+		&i.Template,
+		&i.TemplateVersion,
+		&i.Fields,
+		&i.Created,
+		&i.Updated,
+	)
+	return i, err
+}
+
+const createAnnouncement = `-- name: CreateAnnouncement :one
+
+INSERT INTO announcements (title, message, severity, require_ack, author, expires)
+VALUES (?1, ?2, ?3, ?4, ?5, ?6)
+RETURNING id, title, message, severity, require_ack, author, expires, created, updated
+`
+
+type CreateAnnouncementParams struct {
+	Title      string     `json:"title"`
+	Message    string     `json:"message"`
+	Severity   string     `json:"severity"`
+	RequireAck bool       `json:"require_ack"`
+	Author     *string    `json:"author"`
+	Expires    *time.Time `json:"expires"`
+}
+
+// ----------------------------------------------------------------
+func (q *WriteQueries) CreateAnnouncement(ctx context.Context, arg CreateAnnouncementParams) (Announcement, error) {
+	row := q.db.QueryRowContext(ctx, createAnnouncement,
+		arg.Title,
+		arg.Message,
+		arg.Severity,
+		arg.RequireAck,
+		arg.Author,
+		arg.Expires,
+	)
+	var i Announcement
+	err := row.Scan(
+		&i.ID,
+		&i.Title,
+		&i.Message,
+		&i.Severity,
+		&i.RequireAck,
+		&i.Author,
+		&i.Expires,
+		&i.Created,
+		&i.Updated,
+	)
+	return i, err
+}
+
+const createAutoCloseRule = `-- name: CreateAutoCloseRule :one
+
+INSERT INTO auto_close_rules (name, enabled, filter, inactive_days, warning_days, resolution)
+VALUES (?1, ?2, ?3, ?4, ?5, ?6)
+RETURNING id, name, enabled, "filter", inactive_days, warning_days, resolution, created, updated
+`
+
+type CreateAutoCloseRuleParams struct {
+	Name         string `json:"name"`
+	Enabled      bool   `json:"enabled"`
+	Filter       []byte `json:"filter"`
+	InactiveDays int64  `json:"inactive_days"`
+	WarningDays  int64  `json:"warning_days"`
+	Resolution   string `json:"resolution"`
+}
+
+// ----------------------------------------------------------------
+func (q *WriteQueries) CreateAutoCloseRule(ctx context.Context, arg CreateAutoCloseRuleParams) (AutoCloseRule, error) {
+	row := q.db.QueryRowContext(ctx, createAutoCloseRule,
+		arg.Name,
+		arg.Enabled,
+		arg.Filter,
+		arg.InactiveDays,
+		arg.WarningDays,
+		arg.Resolution,
+	)
+	var i AutoCloseRule
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Enabled,
+		&i.Filter,
+		&i.InactiveDays,
+		&i.WarningDays,
+		&i.Resolution,
+		&i.Created,
+		&i.Updated,
+	)
+	return i, err
+}
+
+const createCVE = `-- name: CreateCVE :exec
+
+INSERT OR IGNORE INTO cves (id)
+VALUES (?1)
+`
+
+// ----------------------------------------------------------------
+func (q *WriteQueries) CreateCVE(ctx context.Context, id string) error {
+	_, err := q.db.ExecContext(ctx, createCVE, id)
+	return err
+}
+
+const createCampaign = `-- name: CreateCampaign :one
+
+INSERT INTO campaigns (name, description, playbook, inputs, feature_kind, feature_value)
+VALUES (?1, ?2, ?3, ?4, ?5, ?6)
+RETURNING id, name, description, playbook, inputs, created, updated, feature_kind, feature_value
+`
+
+type CreateCampaignParams struct {
+	Name         string  `json:"name"`
+	Description  string  `json:"description"`
+	Playbook     *string `json:"playbook"`
+	Inputs       []byte  `json:"inputs"`
+	FeatureKind  *string `json:"feature_kind"`
+	FeatureValue *string `json:"feature_value"`
+}
+
+// ----------------------------------------------------------------
+func (q *WriteQueries) CreateCampaign(ctx context.Context, arg CreateCampaignParams) (Campaign, error) {
+	row := q.db.QueryRowContext(ctx, createCampaign,
+		arg.Name,
+		arg.Description,
+		arg.Playbook,
+		arg.Inputs,
+		arg.FeatureKind,
+		arg.FeatureValue,
+	)
+	var i Campaign
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Description,
+		&i.Playbook,
+		&i.Inputs,
+		&i.Created,
+		&i.Updated,
+		&i.FeatureKind,
+		&i.FeatureValue,
+	)
+	return i, err
+}
+
+const createCampaignTimeline = `-- name: CreateCampaignTimeline :one
+INSERT INTO campaign_timeline (campaign, ticket, message)
+VALUES (?1, ?2, ?3)
+RETURNING id, campaign, ticket, message, time
+`
+
+type CreateCampaignTimelineParams struct {
+	Campaign string  `json:"campaign"`
+	Ticket   *string `json:"ticket"`
+	Message  string  `json:"message"`
+}
+
+func (q *WriteQueries) CreateCampaignTimeline(ctx context.Context, arg CreateCampaignTimelineParams) (CampaignTimeline, error) {
+	row := q.db.QueryRowContext(ctx, createCampaignTimeline, arg.Campaign, arg.Ticket, arg.Message)
+	var i CampaignTimeline
+	err := row.Scan(
+		&i.ID,
+		&i.Campaign,
+		&i.Ticket,
+		&i.Message,
+		&i.Time,
+	)
+	return i, err
+}
+
+const createComment = `-- name: CreateComment :one
+INSERT INTO comments (author, message, ticket)
+VALUES (?1, ?2, ?3)
+RETURNING id, ticket, author, message, created, updated
+`
+
+type CreateCommentParams struct {
+	Author  string `json:"author"`
+	Message string `json:"message"`
+	Ticket  string `json:"ticket"`
+}
+
+func (q *WriteQueries) CreateComment(ctx context.Context, arg CreateCommentParams) (Comment, error) {
+	row := q.db.QueryRowContext(ctx, createComment, arg.Author, arg.Message, arg.Ticket)
+	var i Comment
+	err := row.Scan(
+		&i.ID,
+		&i.Ticket,
+		&i.Author,
+		&i.Message,
+		&i.Created,
+		&i.Updated,
+	)
+	return i, err
+}
+
+const createCustodyEvent = `-- name: CreateCustodyEvent :exec
+INSERT INTO file_custody (ticket, file, file_name, action, user, sha256)
+VALUES (?1, ?2, ?3, ?4, ?5, ?6)
+`
+
+type CreateCustodyEventParams struct {
+	Ticket   string  `json:"ticket"`
+	File     string  `json:"file"`
+	FileName string  `json:"file_name"`
+	Action   string  `json:"action"`
+	User     *string `json:"user"`
+	Sha256   string  `json:"sha256"`
+}
+
+func (q *WriteQueries) CreateCustodyEvent(ctx context.Context, arg CreateCustodyEventParams) error {
+	_, err := q.db.ExecContext(ctx, createCustodyEvent,
+		arg.Ticket,
+		arg.File,
+		arg.FileName,
+		arg.Action,
+		arg.User,
+		arg.Sha256,
+	)
+	return err
+}
+
+const createDLPEvent = `-- name: CreateDLPEvent :exec
+
+INSERT INTO dlp_events (rule, export, ticket, user)
+VALUES (?1, ?2, ?3, ?4)
+`
+
+type CreateDLPEventParams struct {
+	Rule   string  `json:"rule"`
+	Export string  `json:"export"`
+	Ticket string  `json:"ticket"`
+	User   *string `json:"user"`
+}
+
+// ----------------------------------------------------------------
+func (q *WriteQueries) CreateDLPEvent(ctx context.Context, arg CreateDLPEventParams) error {
+	_, err := q.db.ExecContext(ctx, createDLPEvent,
+		arg.Rule,
+		arg.Export,
+		arg.Ticket,
+		arg.User,
+	)
+	return err
+}
+
+const createDeadLetter = `-- name: CreateDeadLetter :one
+
+INSERT INTO dead_letters (webhook, destination, payload, error)
+VALUES (?1, ?2, ?3, ?4)
+RETURNING id, webhook, destination, payload, error, attempts, created, updated
+`
+
+type CreateDeadLetterParams struct {
+	Webhook     string `json:"webhook"`
+	Destination string `json:"destination"`
+	Payload     []byte `json:"payload"`
+	Error       string `json:"error"`
+}
+
+// ----------------------------------------------------------------
+func (q *WriteQueries) CreateDeadLetter(ctx context.Context, arg CreateDeadLetterParams) (DeadLetter, error) {
+	row := q.db.QueryRowContext(ctx, createDeadLetter,
+		arg.Webhook,
+		arg.Destination,
+		arg.Payload,
+		arg.Error,
+	)
+	var i DeadLetter
+	err := row.Scan(
+		&i.ID,
+		&i.Webhook,
+		&i.Destination,
+		&i.Payload,
+		&i.Error,
+		&i.Attempts,
+		&i.Created,
+		&i.Updated,
+	)
+	return i, err
+}
+
+const createDetectionRule = `-- name: CreateDetectionRule :one
+
+INSERT INTO detection_rules (ticket, name, description, format, content, author)
+VALUES (?1, ?2, ?3, ?4, ?5, ?6)
+RETURNING id, ticket, name, description, format, content, status, author, reviewer, deployed, created, updated
+`
+
+type CreateDetectionRuleParams struct {
+	Ticket      string  `json:"ticket"`
+	Name        string  `json:"name"`
+	Description string  `json:"description"`
+	Format      string  `json:"format"`
+	Content     string  `json:"content"`
+	Author      *string `json:"author"`
+}
+
+// ----------------------------------------------------------------
+func (q *WriteQueries) CreateDetectionRule(ctx context.Context, arg CreateDetectionRuleParams) (DetectionRule, error) {
+	row := q.db.QueryRowContext(ctx, createDetectionRule,
+		arg.Ticket,
+		arg.Name,
+		arg.Description,
+		arg.Format,
+		arg.Content,
+		arg.Author,
+	)
+	var i DetectionRule
+	err := row.Scan(
+		&i.ID,
+		&i.Ticket,
+		&i.Name,
+		&i.Description,
+		&i.Format,
+		&i.Content,
+		&i.Status,
+		&i.Author,
+		&i.Reviewer,
+		&i.Deployed,
+		&i.Created,
+		&i.Updated,
+	)
+	return i, err
+}
+
+const createEscalationPolicy = `-- name: CreateEscalationPolicy :one
+
+INSERT INTO escalation_policies (name, filter, steps, repeat, pause_states)
+VALUES (?1, ?2, ?3, ?4, ?5)
+RETURNING id, name, "filter", steps, repeat, created, updated, pause_states
+`
+
+type CreateEscalationPolicyParams struct {
+	Name        string `json:"name"`
+	Filter      []byte `json:"filter"`
+	Steps       []byte `json:"steps"`
+	Repeat      bool   `json:"repeat"`
+	PauseStates []byte `json:"pause_states"`
+}
+
+// ----------------------------------------------------------------
+func (q *WriteQueries) CreateEscalationPolicy(ctx context.Context, arg CreateEscalationPolicyParams) (EscalationPolicy, error) {
+	row := q.db.QueryRowContext(ctx, createEscalationPolicy,
+		arg.Name,
+		arg.Filter,
+		arg.Steps,
+		arg.Repeat,
+		arg.PauseStates,
+	)
+	var i EscalationPolicy
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Filter,
+		&i.Steps,
+		&i.Repeat,
+		&i.Created,
+		&i.Updated,
+		&i.PauseStates,
+	)
+	return i, err
+}
+
+const createFeature = `-- name: CreateFeature :one
+
+INSERT INTO features (key)
+VALUES (?1)
+RETURNING "key"
+`
+
+// ----------------------------------------------------------------
+func (q *WriteQueries) CreateFeature(ctx context.Context, key string) (string, error) {
+	row := q.db.QueryRowContext(ctx, createFeature, key)
+	err := row.Scan(&key)
+	return key, err
+}
+
+const createFederationLink = `-- name: CreateFederationLink :one
+INSERT INTO federation_links (ticket, peer, remote_ticket, remote_key, remote_type, remote_name, remote_open)
+VALUES (?1, ?2, ?3, ?4, ?5, ?6, ?7)
+ON CONFLICT (ticket, peer, remote_ticket) DO UPDATE SET remote_key  = excluded.remote_key,
+                                                        remote_type = excluded.remote_type,
+                                                        remote_name = excluded.remote_name,
+                                                        remote_open = excluded.remote_open,
+                                                        updated     = CURRENT_TIMESTAMP
+RETURNING id, ticket, peer, remote_ticket, remote_key, remote_type, remote_name, remote_open, share, created, updated
+`
+
+type CreateFederationLinkParams struct {
+	Ticket       string  `json:"ticket"`
+	Peer         string  `json:"peer"`
+	RemoteTicket string  `json:"remote_ticket"`
+	RemoteKey    *string `json:"remote_key"`
+	RemoteType   string  `json:"remote_type"`
+	RemoteName   string  `json:"remote_name"`
+	RemoteOpen   bool    `json:"remote_open"`
+}
+
+func (q *WriteQueries) CreateFederationLink(ctx context.Context, arg CreateFederationLinkParams) (FederationLink, error) {
+	row := q.db.QueryRowContext(ctx, createFederationLink,
+		arg.Ticket,
+		arg.Peer,
+		arg.RemoteTicket,
+		arg.RemoteKey,
+		arg.RemoteType,
+		arg.RemoteName,
+		arg.RemoteOpen,
+	)
+	var i FederationLink
+	err := row.Scan(
+		&i.ID,
+		&i.Ticket,
+		&i.Peer,
+		&i.RemoteTicket,
+		&i.RemoteKey,
+		&i.RemoteType,
+		&i.RemoteName,
+		&i.RemoteOpen,
+		&i.Share,
+		&i.Created,
+		&i.Updated,
+	)
+	return i, err
+}
+
+const createFederationPeer = `-- name: CreateFederationPeer :one
+
+INSERT INTO federation_peers (name, url, secret, max_tlp)
+VALUES (?1, ?2, ?3, ?4)
+RETURNING id, name, url, secret, max_tlp, created, updated
+`
+
+type CreateFederationPeerParams struct {
+	Name   string `json:"name"`
+	Url    string `json:"url"`
+	Secret string `json:"secret"`
+	MaxTlp string `json:"max_tlp"`
+}
+
+// ----------------------------------------------------------------
+func (q *WriteQueries) CreateFederationPeer(ctx context.Context, arg CreateFederationPeerParams) (FederationPeer, error) {
+	row := q.db.QueryRowContext(ctx, createFederationPeer,
+		arg.Name,
+		arg.Url,
+		arg.Secret,
+		arg.MaxTlp,
+	)
+	var i FederationPeer
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Url,
+		&i.Secret,
+		&i.MaxTlp,
+		&i.Created,
+		&i.Updated,
+	)
+	return i, err
+}
+
+const createFile = `-- name: CreateFile :one
+INSERT INTO files (name, blob, size, ticket)
+VALUES (?1, ?2, ?3, ?4)
+RETURNING id, ticket, name, blob, size, created, updated, md5, sha1, sha256
+`
+
+type CreateFileParams struct {
+	Name   string  `json:"name"`
+	Blob   string  `json:"blob"`
+	Size   float64 `json:"size"`
+	Ticket string  `json:"ticket"`
+}
+
+func (q *WriteQueries) CreateFile(ctx context.Context, arg CreateFileParams) (File, error) {
+	row := q.db.QueryRowContext(ctx, createFile,
+		arg.Name,
+		arg.Blob,
+		arg.Size,
+		arg.Ticket,
+	)
+	var i File
+	err := row.Scan(
+		&i.ID,
+		&i.Ticket,
+		&i.Name,
+		&i.Blob,
+		&i.Size,
+		&i.Created,
+		&i.Updated,
+		&i.Md5,
+		&i.Sha1,
+		&i.Sha256,
+	)
+	return i, err
+}
+
+const createGroup = `-- name: CreateGroup :one
+INSERT INTO groups (name, permissions)
+VALUES (?1, ?2)
+RETURNING id, name, permissions, created, updated
+`
+
+type CreateGroupParams struct {
+	Name        string `json:"name"`
+	Permissions string `json:"permissions"`
+}
+
+func (q *WriteQueries) CreateGroup(ctx context.Context, arg CreateGroupParams) (Group, error) {
+	row := q.db.QueryRowContext(ctx, createGroup, arg.Name, arg.Permissions)
+	var i Group
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Permissions,
+		&i.Created,
+		&i.Updated,
+	)
+	return i, err
+}
+
+const createInvitation = `-- name: CreateInvitation :one
+INSERT INTO invitations (email, name, groups, token_hash, invited_by, expires)
+VALUES (?1, ?2, ?3, ?4, ?5, ?6)
+RETURNING id, email, name, "groups", token_hash, invited_by, expires, accepted_by, created, updated
+`
+
+type CreateInvitationParams struct {
+	Email     string    `json:"email"`
+	Name      string    `json:"name"`
+	Groups    []byte    `json:"groups"`
+	TokenHash string    `json:"token_hash"`
+	InvitedBy *string   `json:"invited_by"`
+	Expires   time.Time `json:"expires"`
+}
+
+func (q *WriteQueries) CreateInvitation(ctx context.Context, arg CreateInvitationParams) (Invitation, error) {
+	row := q.db.QueryRowContext(ctx, createInvitation,
+		arg.Email,
+		arg.Name,
+		arg.Groups,
+		arg.TokenHash,
+		arg.InvitedBy,
+		arg.Expires,
+	)
+	var i Invitation
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.Name,
+		&i.Groups,
+		&i.TokenHash,
+		&i.InvitedBy,
+		&i.Expires,
+		&i.AcceptedBy,
+		&i.Created,
+		&i.Updated,
+	)
+	return i, err
+}
+
+const createJournalEntry = `-- name: CreateJournalEntry :exec
+
+INSERT INTO request_journal (reaction, request, size, truncated, success, error)
+VALUES (?1, ?2, ?3, ?4, ?5, ?6)
+`
+
+type CreateJournalEntryParams struct {
+	Reaction  string `json:"reaction"`
+	Request   []byte `json:"request"`
+	Size      int64  `json:"size"`
+	Truncated bool   `json:"truncated"`
+	Success   bool   `json:"success"`
+	Error     string `json:"error"`
+}
+
+// ----------------------------------------------------------------
+func (q *WriteQueries) CreateJournalEntry(ctx context.Context, arg CreateJournalEntryParams) error {
+	_, err := q.db.ExecContext(ctx, createJournalEntry,
+		arg.Reaction,
+		arg.Request,
+		arg.Size,
+		arg.Truncated,
+		arg.Success,
+		arg.Error,
+	)
+	return err
+}
+
+const createLegalHold = `-- name: CreateLegalHold :one
+INSERT INTO legal_holds (id, collection, reason, created_by)
+VALUES (?1, ?2, ?3, ?4)
+ON CONFLICT (id) DO UPDATE SET reason = excluded.reason
+RETURNING id, collection, reason, created_by, created
+`
+
+type CreateLegalHoldParams struct {
+	ID         string  `json:"id"`
+	Collection string  `json:"collection"`
+	Reason     string  `json:"reason"`
+	CreatedBy  *string `json:"created_by"`
+}
+
+func (q *WriteQueries) CreateLegalHold(ctx context.Context, arg CreateLegalHoldParams) (LegalHold, error) {
+	row := q.db.QueryRowContext(ctx, createLegalHold,
+		arg.ID,
+		arg.Collection,
+		arg.Reason,
+		arg.CreatedBy,
+	)
+	var i LegalHold
+	err := row.Scan(
+		&i.ID,
+		&i.Collection,
+		&i.Reason,
+		&i.CreatedBy,
+		&i.Created,
+	)
+	return i, err
+}
+
+const createLink = `-- name: CreateLink :one
+INSERT INTO links (name, url, ticket)
+VALUES (?1, ?2, ?3)
+RETURNING id, ticket, name, url, created, updated
+`
+
+type CreateLinkParams struct {
+	Name   string `json:"name"`
+	Url    string `json:"url"`
+	Ticket string `json:"ticket"`
+}
+
+func (q *WriteQueries) CreateLink(ctx context.Context, arg CreateLinkParams) (Link, error) {
+	row := q.db.QueryRowContext(ctx, createLink, arg.Name, arg.Url, arg.Ticket)
+	var i Link
+	err := row.Scan(
+		&i.ID,
+		&i.Ticket,
+		&i.Name,
+		&i.Url,
+		&i.Created,
+		&i.Updated,
+	)
+	return i, err
+}
+
+const createLog = `-- name: CreateLog :exec
+INSERT INTO logs (level, message, component, user, attrs, created)
+VALUES (?1, ?2, ?3, ?4, ?5, ?6)
+`
+
+type CreateLogParams struct {
+	Level     int64     `json:"level"`
+	Message   string    `json:"message"`
+	Component string    `json:"component"`
+	User      *string   `json:"user"`
+	Attrs     []byte    `json:"attrs"`
+	Created   time.Time `json:"created"`
+}
+
+func (q *WriteQueries) CreateLog(ctx context.Context, arg CreateLogParams) error {
+	_, err := q.db.ExecContext(ctx, createLog,
+		arg.Level,
+		arg.Message,
+		arg.Component,
+		arg.User,
+		arg.Attrs,
+		arg.Created,
+	)
+	return err
+}
+
+const createMaintenanceWindow = `-- name: CreateMaintenanceWindow :one
+
+INSERT INTO maintenance_windows (name, filter, starts, ends)
+VALUES (?1, ?2, ?3, ?4)
+RETURNING id, name, "filter", starts, ends, created, updated
+`
+
+type CreateMaintenanceWindowParams struct {
+	Name   string    `json:"name"`
+	Filter []byte    `json:"filter"`
+	Starts time.Time `json:"starts"`
+	Ends   time.Time `json:"ends"`
+}
+
+// ----------------------------------------------------------------
+func (q *WriteQueries) CreateMaintenanceWindow(ctx context.Context, arg CreateMaintenanceWindowParams) (MaintenanceWindow, error) {
+	row := q.db.QueryRowContext(ctx, createMaintenanceWindow,
+		arg.Name,
+		arg.Filter,
+		arg.Starts,
+		arg.Ends,
+	)
+	var i MaintenanceWindow
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Filter,
+		&i.Starts,
+		&i.Ends,
+		&i.Created,
+		&i.Updated,
+	)
+	return i, err
+}
+
+const createNotificationRule = `-- name: CreateNotificationRule :one
+
+INSERT INTO notification_rules (name, enabled, filter, channel, target, throttle, dedup)
+VALUES (?1, ?2, ?3, ?4, ?5, ?6, ?7)
+RETURNING id, name, enabled, "filter", channel, target, throttle, dedup, created, updated
+`
+
+type CreateNotificationRuleParams struct {
+	Name     string `json:"name"`
+	Enabled  bool   `json:"enabled"`
+	Filter   []byte `json:"filter"`
+	Channel  string `json:"channel"`
+	Target   string `json:"target"`
+	Throttle int64  `json:"throttle"`
+	Dedup    int64  `json:"dedup"`
+}
+
+// ----------------------------------------------------------------
+func (q *WriteQueries) CreateNotificationRule(ctx context.Context, arg CreateNotificationRuleParams) (NotificationRule, error) {
+	row := q.db.QueryRowContext(ctx, createNotificationRule,
+		arg.Name,
+		arg.Enabled,
+		arg.Filter,
+		arg.Channel,
+		arg.Target,
+		arg.Throttle,
+		arg.Dedup,
+	)
+	var i NotificationRule
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Enabled,
+		&i.Filter,
+		&i.Channel,
+		&i.Target,
+		&i.Throttle,
+		&i.Dedup,
+		&i.Created,
+		&i.Updated,
+	)
+	return i, err
+}
+
+const createParam = `-- name: CreateParam :exec
+INSERT INTO _params (key, value)
+VALUES (?1, ?2)
+RETURNING "key", value
+`
+
+type CreateParamParams struct {
+	Key   string `json:"key"`
+	Value []byte `json:"value"`
+}
+
+func (q *WriteQueries) CreateParam(ctx context.Context, arg CreateParamParams) error {
+	_, err := q.db.ExecContext(ctx, createParam, arg.Key, arg.Value)
+	return err
+}
+
+const createPasswordHistory = `-- name: CreatePasswordHistory :exec
+INSERT INTO password_history (user, passwordHash)
+VALUES (?1, ?2)
+`
+
+type CreatePasswordHistoryParams struct {
+	User         string `json:"user"`
+	PasswordHash string `json:"passwordHash"`
+}
+
+func (q *WriteQueries) CreatePasswordHistory(ctx context.Context, arg CreatePasswordHistoryParams) error {
+	_, err := q.db.ExecContext(ctx, createPasswordHistory, arg.User, arg.PasswordHash)
+	return err
+}
+
+const createPlatformError = `-- name: CreatePlatformError :exec
+INSERT INTO platform_errors (fingerprint, ticket, occurrences, first_seen, last_seen)
+VALUES (?1, ?2, 1, ?3, ?3)
+ON CONFLICT (fingerprint) DO UPDATE SET ticket      = excluded.ticket,
+                                        occurrences = 1,
+                                        first_seen  = excluded.first_seen,
+                                        last_seen   = excluded.last_seen
+`
+
+type CreatePlatformErrorParams struct {
+	Fingerprint string    `json:"fingerprint"`
+	Ticket      string    `json:"ticket"`
+	Seen        time.Time `json:"seen"`
+}
+
+func (q *WriteQueries) CreatePlatformError(ctx context.Context, arg CreatePlatformErrorParams) error {
+	_, err := q.db.ExecContext(ctx, createPlatformError, arg.Fingerprint, arg.Ticket, arg.Seen)
+	return err
+}
+
+const createPlaybook = `-- name: CreatePlaybook :one
+
+INSERT INTO playbooks (name, description, inputs, tasks)
+VALUES (?1, ?2, ?3, ?4)
+RETURNING id, name, description, inputs, tasks, created, updated
+`
+
+type CreatePlaybookParams struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Inputs      []byte `json:"inputs"`
+	Tasks       []byte `json:"tasks"`
+}
+
+// ----------------------------------------------------------------
+func (q *WriteQueries) CreatePlaybook(ctx context.Context, arg CreatePlaybookParams) (Playbook, error) {
+	row := q.db.QueryRowContext(ctx, createPlaybook,
+		arg.Name,
+		arg.Description,
+		arg.Inputs,
+		arg.Tasks,
+	)
+	var i Playbook
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Description,
+		&i.Inputs,
+		&i.Tasks,
+		&i.Created,
+		&i.Updated,
+	)
+	return i, err
+}
+
+const createPlaybookRun = `-- name: CreatePlaybookRun :one
+INSERT INTO playbook_runs (playbook, ticket, inputs)
+VALUES (?1, ?2, ?3)
+RETURNING id, playbook, ticket, inputs, created
+`
+
+type CreatePlaybookRunParams struct {
+	Playbook string `json:"playbook"`
+	Ticket   string `json:"ticket"`
+	Inputs   []byte `json:"inputs"`
+}
+
+func (q *WriteQueries) CreatePlaybookRun(ctx context.Context, arg CreatePlaybookRunParams) (PlaybookRun, error) {
+	row := q.db.QueryRowContext(ctx, createPlaybookRun, arg.Playbook, arg.Ticket, arg.Inputs)
+	var i PlaybookRun
+	err := row.Scan(
+		&i.ID,
+		&i.Playbook,
+		&i.Ticket,
+		&i.Inputs,
+		&i.Created,
+	)
+	return i, err
+}
+
+const createPushSubscription = `-- name: CreatePushSubscription :one
+
+INSERT INTO push_subscriptions (user, endpoint, p256dh, auth)
+VALUES (?1, ?2, ?3, ?4)
+ON CONFLICT (endpoint) DO UPDATE SET user    = excluded.user,
+                                     p256dh  = excluded.p256dh,
+                                     auth    = excluded.auth,
+                                     updated = CURRENT_TIMESTAMP
+RETURNING id, user, endpoint, p256dh, auth, created, updated
+`
+
+type CreatePushSubscriptionParams struct {
+	User     string `json:"user"`
+	Endpoint string `json:"endpoint"`
+	P256dh   string `json:"p256dh"`
+	Auth     string `json:"auth"`
+}
+
+// ----------------------------------------------------------------
+func (q *WriteQueries) CreatePushSubscription(ctx context.Context, arg CreatePushSubscriptionParams) (PushSubscription, error) {
+	row := q.db.QueryRowContext(ctx, createPushSubscription,
+		arg.User,
+		arg.Endpoint,
+		arg.P256dh,
+		arg.Auth,
+	)
+	var i PushSubscription
+	err := row.Scan(
+		&i.ID,
+		&i.User,
+		&i.Endpoint,
+		&i.P256dh,
+		&i.Auth,
+		&i.Created,
+		&i.Updated,
+	)
+	return i, err
+}
+
+const createQueue = `-- name: CreateQueue :one
+
+INSERT INTO queues (name, description, filter)
+VALUES (?1, ?2, ?3)
+RETURNING id, name, description, "filter", created, updated
+`
+
+type CreateQueueParams struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Filter      []byte `json:"filter"`
+}
+
+// ----------------------------------------------------------------
+func (q *WriteQueries) CreateQueue(ctx context.Context, arg CreateQueueParams) (Queue, error) {
+	row := q.db.QueryRowContext(ctx, createQueue, arg.Name, arg.Description, arg.Filter)
+	var i Queue
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Description,
+		&i.Filter,
+		&i.Created,
+		&i.Updated,
+	)
+	return i, err
+}
+
+const createReaction = `-- name: CreateReaction :one
+INSERT INTO reactions (name, action, actiondata, trigger, triggerdata)
+VALUES (?1, ?2, ?3, ?4, ?5)
+RETURNING id, name, "action", actiondata, "trigger", triggerdata, created, updated
+`
+
+type CreateReactionParams struct {
+	Name        string `json:"name"`
+	Action      string `json:"action"`
+	Actiondata  []byte `json:"actiondata"`
+	Trigger     string `json:"trigger"`
+	Triggerdata []byte `json:"triggerdata"`
+}
+
+func (q *WriteQueries) CreateReaction(ctx context.Context, arg CreateReactionParams) (Reaction, error) {
+	row := q.db.QueryRowContext(ctx, createReaction,
+		arg.Name,
+		arg.Action,
+		arg.Actiondata,
+		arg.Trigger,
+		arg.Triggerdata,
+	)
+	var i Reaction
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Action,
+		&i.Actiondata,
+		&i.Trigger,
+		&i.Triggerdata,
+		&i.Created,
+		&i.Updated,
+	)
+	return i, err
+}
+
+const createReactionRun = `-- name: CreateReactionRun :exec
+INSERT INTO reaction_runs (reaction, success, duration_ms, cpu_ms, memory_peak)
+VALUES (?1, ?2, ?3, ?4, ?5)
+`
+
+type CreateReactionRunParams struct {
+	Reaction   string `json:"reaction"`
+	Success    bool   `json:"success"`
+	DurationMs int64  `json:"duration_ms"`
+	CpuMs      int64  `json:"cpu_ms"`
+	MemoryPeak int64  `json:"memory_peak"`
+}
+
+func (q *WriteQueries) CreateReactionRun(ctx context.Context, arg CreateReactionRunParams) error {
+	_, err := q.db.ExecContext(ctx, createReactionRun,
+		arg.Reaction,
+		arg.Success,
+		arg.DurationMs,
+		arg.CpuMs,
+		arg.MemoryPeak,
+	)
+	return err
+}
+
+const createRedactionProfile = `-- name: CreateRedactionProfile :one
+
+INSERT INTO redaction_profiles (name, audience, fields, hide_files, hide_identities)
+VALUES (?1, ?2, ?3, ?4, ?5)
+RETURNING id, name, audience, fields, hide_files, hide_identities, created, updated
+`
+
+type CreateRedactionProfileParams struct {
+	Name           string `json:"name"`
+	Audience       string `json:"audience"`
+	Fields         []byte `json:"fields"`
+	HideFiles      bool   `json:"hide_files"`
+	HideIdentities bool   `json:"hide_identities"`
+}
+
+// ----------------------------------------------------------------
+func (q *WriteQueries) CreateRedactionProfile(ctx context.Context, arg CreateRedactionProfileParams) (RedactionProfile, error) {
+	row := q.db.QueryRowContext(ctx, createRedactionProfile,
+		arg.Name,
+		arg.Audience,
+		arg.Fields,
+		arg.HideFiles,
+		arg.HideIdentities,
+	)
+	var i RedactionProfile
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Audience,
+		&i.Fields,
+		&i.HideFiles,
+		&i.HideIdentities,
+		&i.Created,
+		&i.Updated,
+	)
+	return i, err
+}
+
+const createSlackThread = `-- name: CreateSlackThread :one
+
+INSERT INTO slack_threads (ticket, channel, thread_ts)
+VALUES (?1, ?2, ?3)
+RETURNING ticket, channel, thread_ts, created
+`
+
+type CreateSlackThreadParams struct {
+	Ticket   string `json:"ticket"`
+	Channel  string `json:"channel"`
+	ThreadTs string `json:"thread_ts"`
+}
+
+// ----------------------------------------------------------------
+func (q *WriteQueries) CreateSlackThread(ctx context.Context, arg CreateSlackThreadParams) (SlackThread, error) {
+	row := q.db.QueryRowContext(ctx, createSlackThread, arg.Ticket, arg.Channel, arg.ThreadTs)
+	var i SlackThread
+	err := row.Scan(
+		&i.Ticket,
+		&i.Channel,
+		&i.ThreadTs,
+		&i.Created,
+	)
+	return i, err
+}
+
+const createTask = `-- name: CreateTask :one
+INSERT INTO tasks (name, open, owner, ticket)
+VALUES (?1, ?2, ?3, ?4)
+RETURNING id, ticket, owner, name, open, created, updated
+`
+
+type CreateTaskParams struct {
+	Name   string  `json:"name"`
+	Open   bool    `json:"open"`
+	Owner  *string `json:"owner"`
+	Ticket string  `json:"ticket"`
+}
+
+func (q *WriteQueries) CreateTask(ctx context.Context, arg CreateTaskParams) (Task, error) {
+	row := q.db.QueryRowContext(ctx, createTask,
+		arg.Name,
+		arg.Open,
+		arg.Owner,
+		arg.Ticket,
+	)
+	var i Task
+	err := row.Scan(
+		&i.ID,
+		&i.Ticket,
+		&i.Owner,
+		&i.Name,
+		&i.Open,
+		&i.Created,
+		&i.Updated,
+	)
+	return i, err
+}
+
+const createTicket = `-- name: CreateTicket :one
+INSERT INTO tickets (name, description, open, owner, resolution, schema, state, type, resolved)
+VALUES (?1, ?2, ?3, ?4, ?5, ?6, ?7, ?8,
+        CASE WHEN ?3 THEN NULL ELSE CURRENT_TIMESTAMP END)
+RETURNING id, type, owner, name, description, open, resolution, schema, state, created, updated, acknowledged, acknowledged_by, resolved, encrypted, "key", resolved_by, reopen_count
+`
+
+type CreateTicketParams struct {
+	Name        string  `json:"name"`
+	Description string  `json:"description"`
+	Open        bool    `json:"open"`
+	Owner       *string `json:"owner"`
+	Resolution  *string `json:"resolution"`
+	Schema      []byte  `json:"schema"`
+	State       []byte  `json:"state"`
+	Type        string  `json:"type"`
+}
+
+func (q *WriteQueries) CreateTicket(ctx context.Context, arg CreateTicketParams) (Ticket, error) {
+	row := q.db.QueryRowContext(ctx, createTicket,
+		arg.Name,
+		arg.Description,
+		arg.Open,
+		arg.Owner,
+		arg.Resolution,
+		arg.Schema,
+		arg.State,
+		arg.Type,
+	)
+	var i Ticket
+	err := row.Scan(
+		&i.ID,
+		&i.Type,
+		&i.Owner,
+		&i.Name,
+		&i.Description,
+		&i.Open,
+		&i.Resolution,
+		&i.Schema,
+		&i.State,
+		&i.Created,
+		&i.Updated,
+		&i.Acknowledged,
+		&i.AcknowledgedBy,
+		&i.Resolved,
+		&i.Encrypted,
+		&i.Key,
+		&i.ResolvedBy,
+		&i.ReopenCount,
+	)
+	return i, err
+}
+
+const createTicketActivity = `-- name: CreateTicketActivity :exec
+
+INSERT INTO ticket_activity (ticket, user, method, path, body, status, duration, created)
+VALUES (?1, ?2, ?3, ?4, ?5, ?6, ?7, ?8)
+`
+
+type CreateTicketActivityParams struct {
+	Ticket   string    `json:"ticket"`
+	User     *string   `json:"user"`
+	Method   string    `json:"method"`
+	Path     string    `json:"path"`
+	Body     *string   `json:"body"`
+	Status   int64     `json:"status"`
+	Duration int64     `json:"duration"`
+	Created  time.Time `json:"created"`
+}
+
+// ----------------------------------------------------------------
+func (q *WriteQueries) CreateTicketActivity(ctx context.Context, arg CreateTicketActivityParams) error {
+	_, err := q.db.ExecContext(ctx, createTicketActivity,
+		arg.Ticket,
+		arg.User,
+		arg.Method,
+		arg.Path,
+		arg.Body,
+		arg.Status,
+		arg.Duration,
+		arg.Created,
+	)
+	return err
+}
+
+const createTicketEscalation = `-- name: CreateTicketEscalation :one
+INSERT INTO ticket_escalations (ticket, policy, next_at)
+VALUES (?1, ?2, ?3)
+RETURNING ticket, policy, step, next_at, acknowledged_by, acknowledged_at, created, updated, paused_since, paused
+`
+
+type CreateTicketEscalationParams struct {
+	Ticket string     `json:"ticket"`
+	Policy string     `json:"policy"`
+	NextAt *time.Time `json:"next_at"`
+}
+
+func (q *WriteQueries) CreateTicketEscalation(ctx context.Context, arg CreateTicketEscalationParams) (TicketEscalation, error) {
+	row := q.db.QueryRowContext(ctx, createTicketEscalation, arg.Ticket, arg.Policy, arg.NextAt)
+	var i TicketEscalation
+	err := row.Scan(
+		&i.Ticket,
+		&i.Policy,
+		&i.Step,
+		&i.NextAt,
+		&i.AcknowledgedBy,
+		&i.AcknowledgedAt,
+		&i.Created,
+		&i.Updated,
+		&i.PausedSince,
+		&i.Paused,
+	)
+	return i, err
+}
+
+const createTicketGrant = `-- name: CreateTicketGrant :exec
+INSERT INTO ticket_grants (ticket, user)
+VALUES (?1, ?2)
+ON CONFLICT DO NOTHING
+`
+
+type CreateTicketGrantParams struct {
+	Ticket string `json:"ticket"`
+	User   string `json:"user"`
+}
+
+func (q *WriteQueries) CreateTicketGrant(ctx context.Context, arg CreateTicketGrantParams) error {
+	_, err := q.db.ExecContext(ctx, createTicketGrant, arg.Ticket, arg.User)
+	return err
+}
+
+const createTicketKey = `-- name: CreateTicketKey :exec
+INSERT INTO ticket_keys (ticket, key)
+VALUES (?1, ?2)
+ON CONFLICT DO NOTHING
+`
+
+type CreateTicketKeyParams struct {
+	Ticket string `json:"ticket"`
+	Key    string `json:"key"`
+}
+
+func (q *WriteQueries) CreateTicketKey(ctx context.Context, arg CreateTicketKeyParams) error {
+	_, err := q.db.ExecContext(ctx, createTicketKey, arg.Ticket, arg.Key)
+	return err
+}
+
+const createTicketReopen = `-- name: CreateTicketReopen :one
+INSERT INTO ticket_reopens (ticket, reason, resolution, closed_by, reopened_by)
+VALUES (?1, ?2, ?3, ?4, ?5)
+RETURNING id, ticket, reason, resolution, closed_by, reopened_by, created
+`
+
+type CreateTicketReopenParams struct {
+	Ticket     string  `json:"ticket"`
+	Reason     string  `json:"reason"`
+	Resolution *string `json:"resolution"`
+	ClosedBy   *string `json:"closed_by"`
+	ReopenedBy *string `json:"reopened_by"`
+}
+
+func (q *WriteQueries) CreateTicketReopen(ctx context.Context, arg CreateTicketReopenParams) (TicketReopen, error) {
+	row := q.db.QueryRowContext(ctx, createTicketReopen,
+		arg.Ticket,
+		arg.Reason,
+		arg.Resolution,
+		arg.ClosedBy,
+		arg.ReopenedBy,
+	)
+	var i TicketReopen
+	err := row.Scan(
+		&i.ID,
+		&i.Ticket,
+		&i.Reason,
+		&i.Resolution,
+		&i.ClosedBy,
+		&i.ReopenedBy,
+		&i.Created,
+	)
+	return i, err
+}
+
+const createTimeline = `-- name: CreateTimeline :one
+INSERT INTO timeline (message, ticket, time)
+VALUES (?1, ?2, ?3)
+RETURNING id, ticket, message, time, created, updated
+`
+
+type CreateTimelineParams struct {
+	Message string    `json:"message"`
+	Ticket  string    `json:"ticket"`
+	Time    time.Time `json:"time"`
+}
+
+func (q *WriteQueries) CreateTimeline(ctx context.Context, arg CreateTimelineParams) (Timeline, error) {
+	row := q.db.QueryRowContext(ctx, createTimeline, arg.Message, arg.Ticket, arg.Time)
+	var i Timeline
+	err := row.Scan(
+		&i.ID,
+		&i.Ticket,
+		&i.Message,
+		&i.Time,
+		&i.Created,
+		&i.Updated,
+	)
+	return i, err
+}
+
+const createType = `-- name: CreateType :one
+INSERT INTO types (singular, plural, icon, schema, key_prefix, metrics)
+VALUES (?1, ?2, ?3, ?4, nullif(?5, ''), coalesce(?6, '[]'))
+RETURNING id, icon, singular, plural, schema, created, updated, key_prefix, metrics
+`
+
+type CreateTypeParams struct {
+	Singular  string      `json:"singular"`
+	Plural    string      `json:"plural"`
+	Icon      *string     `json:"icon"`
+	Schema    []byte      `json:"schema"`
+	KeyPrefix interface{} `json:"key_prefix"`
+	Metrics   interface{} `json:"metrics"`
+}
+
+func (q *WriteQueries) CreateType(ctx context.Context, arg CreateTypeParams) (Type, error) {
+	row := q.db.QueryRowContext(ctx, createType,
+		arg.Singular,
+		arg.Plural,
+		arg.Icon,
+		arg.Schema,
+		arg.KeyPrefix,
+		arg.Metrics,
+	)
+	var i Type
+	err := row.Scan(
+		&i.ID,
+		&i.Icon,
+		&i.Singular,
+		&i.Plural,
+		&i.Schema,
+		&i.Created,
+		&i.Updated,
+		&i.KeyPrefix,
+		&i.Metrics,
+	)
+	return i, err
+}
+
+const createUser = `-- name: CreateUser :one
+INSERT INTO users (name, email, username, passwordHash, tokenKey, avatar, active)
+VALUES (?1, ?2, ?3, ?4, ?5, ?6, ?7)
+RETURNING id, username, passwordhash, tokenkey, active, name, email, avatar, lastresetsentat, lastverificationsentat, created, updated
+`
+
+type CreateUserParams struct {
+	Name         *string `json:"name"`
+	Email        *string `json:"email"`
+	Username     string  `json:"username"`
+	PasswordHash string  `json:"passwordHash"`
+	TokenKey     string  `json:"tokenKey"`
+	Avatar       *string `json:"avatar"`
+	Active       bool    `json:"active"`
+}
+
+func (q *WriteQueries) CreateUser(ctx context.Context, arg CreateUserParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, createUser,
+		arg.Name,
+		arg.Email,
+		arg.Username,
+		arg.PasswordHash,
+		arg.TokenKey,
+		arg.Avatar,
+		arg.Active,
+	)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.Passwordhash,
+		&i.Tokenkey,
+		&i.Active,
+		&i.Name,
+		&i.Email,
+		&i.Avatar,
+		&i.Lastresetsentat,
+		&i.Lastverificationsentat,
+		&i.Created,
+		&i.Updated,
+	)
+	return i, err
+}
+
+const createWebhook = `-- name: CreateWebhook :one
+INSERT INTO webhooks (name, collection, destination)
+VALUES (?1, ?2, ?3)
+RETURNING id, collection, destination, name, created, updated
+`
+
+type CreateWebhookParams struct {
+	Name        string `json:"name"`
+	Collection  string `json:"collection"`
+	Destination string `json:"destination"`
+}
+
+func (q *WriteQueries) CreateWebhook(ctx context.Context, arg CreateWebhookParams) (Webhook, error) {
+	row := q.db.QueryRowContext(ctx, createWebhook, arg.Name, arg.Collection, arg.Destination)
+	var i Webhook
+	err := row.Scan(
+		&i.ID,
+		&i.Collection,
+		&i.Destination,
+		&i.Name,
+		&i.Created,
+		&i.Updated,
+	)
+	return i, err
+}
+
+const createWorkEntry = `-- name: CreateWorkEntry :one
+INSERT INTO work_entries (ticket, user, description, started, duration, manual)
+VALUES (?1, ?2, ?3, ?4, ?5, TRUE)
+RETURNING id, ticket, user, description, started, duration, manual, created, updated
+`
+
+type CreateWorkEntryParams struct {
+	Ticket      string    `json:"ticket"`
+	User        string    `json:"user"`
+	Description string    `json:"description"`
+	Started     time.Time `json:"started"`
+	Duration    *int64    `json:"duration"`
+}
+
+func (q *WriteQueries) CreateWorkEntry(ctx context.Context, arg CreateWorkEntryParams) (WorkEntry, error) {
+	row := q.db.QueryRowContext(ctx, createWorkEntry,
+		arg.Ticket,
+		arg.User,
+		arg.Description,
+		arg.Started,
+		arg.Duration,
+	)
+	var i WorkEntry
+	err := row.Scan(
+		&i.ID,
+		&i.Ticket,
+		&i.User,
+		&i.Description,
+		&i.Started,
+		&i.Duration,
+		&i.Manual,
+		&i.Created,
+		&i.Updated,
+	)
+	return i, err
+}
+
+const deleteAPIQuota = `-- name: DeleteAPIQuota :exec
+DELETE
+FROM api_quotas
+WHERE user = ?1
+  AND client = ?2
+`
+
+type DeleteAPIQuotaParams struct {
+	User   string `json:"user"`
+	Client string `json:"client"`
+}
+
+func (q *WriteQueries) DeleteAPIQuota(ctx context.Context, arg DeleteAPIQuotaParams) error {
+	_, err := q.db.ExecContext(ctx, deleteAPIQuota, arg.User, arg.Client)
+	return err
+}
+
+const deleteAlert = `-- name: DeleteAlert :exec
+DELETE
+FROM alerts
+WHERE id = ?1
+`
+
+func (q *WriteQueries) DeleteAlert(ctx context.Context, id string) error {
+	_, err := q.db.ExecContext(ctx, deleteAlert, id)
+	return err
+}
+
+const deleteAlertMapping = `-- name: DeleteAlertMapping :exec
+DELETE
+FROM alert_mappings
+WHERE id = ?1
+`
+
+func (q *WriteQueries) DeleteAlertMapping(ctx context.Context, id string) error {
+	_, err := q.db.ExecContext(ctx, deleteAlertMapping, id)
+	return err
+}
+
+const deleteAnnouncement = `-- name: DeleteAnnouncement :exec
+DELETE
+FROM announcements
+WHERE id = ?1
+`
+
+func (q *WriteQueries) DeleteAnnouncement(ctx context.Context, id string) error {
+	_, err := q.db.ExecContext(ctx, deleteAnnouncement, id)
+	return err
+}
+
+const deleteAutoCloseRule = `-- name: DeleteAutoCloseRule :exec
+DELETE
+FROM auto_close_rules
+WHERE id = ?1
+`
+
+func (q *WriteQueries) DeleteAutoCloseRule(ctx context.Context, id string) error {
+	_, err := q.db.ExecContext(ctx, deleteAutoCloseRule, id)
+	return err
+}
+
+const deleteAutoCloseWarning = `-- name: DeleteAutoCloseWarning :exec
+DELETE
+FROM auto_close_warnings
+WHERE ticket = ?1
+`
+
+func (q *WriteQueries) DeleteAutoCloseWarning(ctx context.Context, ticket string) error {
+	_, err := q.db.ExecContext(ctx, deleteAutoCloseWarning, ticket)
+	return err
+}
+
+const deleteCampaign = `-- name: DeleteCampaign :exec
+DELETE
+FROM campaigns
+WHERE id = ?1
+`
+
+func (q *WriteQueries) DeleteCampaign(ctx context.Context, id string) error {
+	_, err := q.db.ExecContext(ctx, deleteCampaign, id)
+	return err
+}
+
+const deleteComment = `-- name: DeleteComment :exec
+DELETE
+FROM comments
+WHERE id = ?1
+`
+
+func (q *WriteQueries) DeleteComment(ctx context.Context, id string) error {
+	_, err := q.db.ExecContext(ctx, deleteComment, id)
+	return err
+}
+
+const deleteDeadLetter = `-- name: DeleteDeadLetter :exec
+DELETE
+FROM dead_letters
+WHERE id = ?1
+`
+
+func (q *WriteQueries) DeleteDeadLetter(ctx context.Context, id string) error {
+	_, err := q.db.ExecContext(ctx, deleteDeadLetter, id)
+	return err
+}
+
+const deleteDetectionRule = `-- name: DeleteDetectionRule :exec
+DELETE
+FROM detection_rules
+WHERE id = ?1
+`
+
+func (q *WriteQueries) DeleteDetectionRule(ctx context.Context, id string) error {
+	_, err := q.db.ExecContext(ctx, deleteDetectionRule, id)
+	return err
+}
+
+const deleteDocumentTemplate = `-- name: DeleteDocumentTemplate :exec
+DELETE
+FROM document_templates
+WHERE format = ?1
+`
+
+func (q *WriteQueries) DeleteDocumentTemplate(ctx context.Context, format string) error {
+	_, err := q.db.ExecContext(ctx, deleteDocumentTemplate, format)
+	return err
+}
+
+const deleteEscalationPolicy = `-- name: DeleteEscalationPolicy :exec
+DELETE
+FROM escalation_policies
+WHERE id = ?1
+`
+
+func (q *WriteQueries) DeleteEscalationPolicy(ctx context.Context, id string) error {
+	_, err := q.db.ExecContext(ctx, deleteEscalationPolicy, id)
+	return err
+}
+
+const deleteExpiredEnrichments = `-- name: DeleteExpiredEnrichments :exec
+DELETE
+FROM enrichment_cache
+WHERE expires <= ?1
+`
+
+func (q *WriteQueries) DeleteExpiredEnrichments(ctx context.Context, now time.Time) error {
+	_, err := q.db.ExecContext(ctx, deleteExpiredEnrichments, now)
+	return err
+}
+
+const deleteFeature = `-- name: DeleteFeature :exec
+DELETE
+FROM features
+WHERE key = ?1
+`
+
+func (q *WriteQueries) DeleteFeature(ctx context.Context, key string) error {
+	_, err := q.db.ExecContext(ctx, deleteFeature, key)
+	return err
+}
+
+const deleteFederationLink = `-- name: DeleteFederationLink :exec
+DELETE
+FROM federation_links
+WHERE id = ?1
+`
+
+func (q *WriteQueries) DeleteFederationLink(ctx context.Context, id string) error {
+	_, err := q.db.ExecContext(ctx, deleteFederationLink, id)
+	return err
+}
+
+const deleteFederationPeer = `-- name: DeleteFederationPeer :exec
+DELETE
+FROM federation_peers
+WHERE id = ?1
+`
+
+func (q *WriteQueries) DeleteFederationPeer(ctx context.Context, id string) error {
+	_, err := q.db.ExecContext(ctx, deleteFederationPeer, id)
+	return err
+}
+
+const deleteFeed = `-- name: DeleteFeed :exec
+DELETE
+FROM feeds
+WHERE name = ?1
+`
+
+func (q *WriteQueries) DeleteFeed(ctx context.Context, name string) error {
+	_, err := q.db.ExecContext(ctx, deleteFeed, name)
+	return err
+}
+
+const deleteFeedIndicators = `-- name: DeleteFeedIndicators :exec
+DELETE
+FROM feed_indicators
+WHERE feed = ?1
+`
+
+func (q *WriteQueries) DeleteFeedIndicators(ctx context.Context, feed string) error {
+	_, err := q.db.ExecContext(ctx, deleteFeedIndicators, feed)
+	return err
+}
+
+const deleteFile = `-- name: DeleteFile :exec
+DELETE
+FROM files
+WHERE id = ?1
+`
+
+func (q *WriteQueries) DeleteFile(ctx context.Context, id string) error {
+	_, err := q.db.ExecContext(ctx, deleteFile, id)
+	return err
+}
+
+const deleteGroup = `-- name: DeleteGroup :exec
+DELETE
+FROM groups
+WHERE id = ?1
+`
+
+func (q *WriteQueries) DeleteGroup(ctx context.Context, id string) error {
+	_, err := q.db.ExecContext(ctx, deleteGroup, id)
+	return err
+}
+
+const deleteInvitation = `-- name: DeleteInvitation :exec
+DELETE
+FROM invitations
+WHERE id = ?1
+`
+
+func (q *WriteQueries) DeleteInvitation(ctx context.Context, id string) error {
+	_, err := q.db.ExecContext(ctx, deleteInvitation, id)
+	return err
+}
+
+const deleteKnownExploitedBefore = `-- name: DeleteKnownExploitedBefore :exec
+DELETE
+FROM known_exploited
+WHERE updated < ?1
+`
+
+func (q *WriteQueries) DeleteKnownExploitedBefore(ctx context.Context, updated time.Time) error {
+	_, err := q.db.ExecContext(ctx, deleteKnownExploitedBefore, updated)
+	return err
+}
+
+const deleteLegalHold = `-- name: DeleteLegalHold :exec
+DELETE
+FROM legal_holds
+WHERE id = ?1
+`
+
+func (q *WriteQueries) DeleteLegalHold(ctx context.Context, id string) error {
+	_, err := q.db.ExecContext(ctx, deleteLegalHold, id)
+	return err
+}
+
+const deleteLink = `-- name: DeleteLink :exec
+DELETE
+FROM links
+WHERE id = ?1
+`
+
+func (q *WriteQueries) DeleteLink(ctx context.Context, id string) error {
+	_, err := q.db.ExecContext(ctx, deleteLink, id)
+	return err
+}
+
+const deleteLogsBefore = `-- name: DeleteLogsBefore :execrows
+DELETE
+FROM logs
+WHERE created < ?1
+`
+
+func (q *WriteQueries) DeleteLogsBefore(ctx context.Context, created time.Time) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteLogsBefore, created)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteMaintenanceWindow = `-- name: DeleteMaintenanceWindow :exec
+DELETE
+FROM maintenance_windows
+WHERE id = ?1
+`
+
+func (q *WriteQueries) DeleteMaintenanceWindow(ctx context.Context, id string) error {
+	_, err := q.db.ExecContext(ctx, deleteMaintenanceWindow, id)
+	return err
+}
+
+const deleteNotificationRule = `-- name: DeleteNotificationRule :exec
+DELETE
+FROM notification_rules
+WHERE id = ?1
+`
+
+func (q *WriteQueries) DeleteNotificationRule(ctx context.Context, id string) error {
+	_, err := q.db.ExecContext(ctx, deleteNotificationRule, id)
+	return err
+}
+
+const deletePlaybook = `-- name: DeletePlaybook :exec
+DELETE
+FROM playbooks
+WHERE id = ?1
+`
+
+func (q *WriteQueries) DeletePlaybook(ctx context.Context, id string) error {
+	_, err := q.db.ExecContext(ctx, deletePlaybook, id)
+	return err
+}
+
+const deletePushSubscription = `-- name: DeletePushSubscription :exec
+DELETE
+FROM push_subscriptions
+WHERE id = ?1
+  AND user = ?2
+`
+
+type DeletePushSubscriptionParams struct {
+	ID   string `json:"id"`
+	User string `json:"user"`
+}
+
+func (q *WriteQueries) DeletePushSubscription(ctx context.Context, arg DeletePushSubscriptionParams) error {
+	_, err := q.db.ExecContext(ctx, deletePushSubscription, arg.ID, arg.User)
+	return err
+}
+
+const deletePushSubscriptionByEndpoint = `-- name: DeletePushSubscriptionByEndpoint :exec
+DELETE
+FROM push_subscriptions
+WHERE endpoint = ?1
+`
+
+func (q *WriteQueries) DeletePushSubscriptionByEndpoint(ctx context.Context, endpoint string) error {
+	_, err := q.db.ExecContext(ctx, deletePushSubscriptionByEndpoint, endpoint)
+	return err
+}
+
+const deleteQueue = `-- name: DeleteQueue :exec
+DELETE
+FROM queues
+WHERE id = ?1
+`
+
+func (q *WriteQueries) DeleteQueue(ctx context.Context, id string) error {
+	_, err := q.db.ExecContext(ctx, deleteQueue, id)
+	return err
+}
+
+const deleteReaction = `-- name: DeleteReaction :exec
+DELETE
+FROM reactions
+WHERE id = ?1
+`
+
+func (q *WriteQueries) DeleteReaction(ctx context.Context, id string) error {
+	_, err := q.db.ExecContext(ctx, deleteReaction, id)
+	return err
+}
+
+const deleteReactionRunsBefore = `-- name: DeleteReactionRunsBefore :exec
+DELETE
+FROM reaction_runs
+WHERE created < ?1
+`
+
+func (q *WriteQueries) DeleteReactionRunsBefore(ctx context.Context, before time.Time) error {
+	_, err := q.db.ExecContext(ctx, deleteReactionRunsBefore, before)
+	return err
+}
+
+const deleteRedactionProfile = `-- name: DeleteRedactionProfile :exec
+DELETE
+FROM redaction_profiles
+WHERE id = ?1
+`
+
+func (q *WriteQueries) DeleteRedactionProfile(ctx context.Context, id string) error {
+	_, err := q.db.ExecContext(ctx, deleteRedactionProfile, id)
+	return err
+}
+
+const deleteTask = `-- name: DeleteTask :exec
+DELETE
+FROM tasks
+WHERE id = ?1
+`
+
+func (q *WriteQueries) DeleteTask(ctx context.Context, id string) error {
+	_, err := q.db.ExecContext(ctx, deleteTask, id)
+	return err
+}
+
+const deleteTaskTimer = `-- name: DeleteTaskTimer :exec
+DELETE
+FROM task_timers
+WHERE task = ?1
+`
+
+func (q *WriteQueries) DeleteTaskTimer(ctx context.Context, task string) error {
+	_, err := q.db.ExecContext(ctx, deleteTaskTimer, task)
+	return err
+}
+
+const deleteTicket = `-- name: DeleteTicket :exec
+DELETE
+FROM tickets
+WHERE id = ?1
+`
+
+func (q *WriteQueries) DeleteTicket(ctx context.Context, id string) error {
+	_, err := q.db.ExecContext(ctx, deleteTicket, id)
+	return err
+}
+
+const deleteTicketGrant = `-- name: DeleteTicketGrant :exec
+DELETE
+FROM ticket_grants
+WHERE ticket = ?1
+  AND user = ?2
+`
+
+type DeleteTicketGrantParams struct {
+	Ticket string `json:"ticket"`
+	User   string `json:"user"`
+}
+
+func (q *WriteQueries) DeleteTicketGrant(ctx context.Context, arg DeleteTicketGrantParams) error {
+	_, err := q.db.ExecContext(ctx, deleteTicketGrant, arg.Ticket, arg.User)
+	return err
+}
+
+const deleteTicketTechniques = `-- name: DeleteTicketTechniques :exec
+DELETE
+FROM ticket_techniques
+WHERE ticket = ?1
+`
+
+func (q *WriteQueries) DeleteTicketTechniques(ctx context.Context, ticket string) error {
+	_, err := q.db.ExecContext(ctx, deleteTicketTechniques, ticket)
+	return err
+}
+
+const deleteTimeline = `-- name: DeleteTimeline :exec
+DELETE
+FROM timeline
+WHERE id = ?1
+`
+
+func (q *WriteQueries) DeleteTimeline(ctx context.Context, id string) error {
+	_, err := q.db.ExecContext(ctx, deleteTimeline, id)
+	return err
+}
+
+const deleteType = `-- name: DeleteType :exec
+DELETE
+FROM types
+WHERE id = ?1
+`
+
+func (q *WriteQueries) DeleteType(ctx context.Context, id string) error {
+	_, err := q.db.ExecContext(ctx, deleteType, id)
+	return err
+}
+
+const deleteTypeTechniques = `-- name: DeleteTypeTechniques :exec
+DELETE
+FROM type_techniques
+WHERE type = ?1
+`
+
+func (q *WriteQueries) DeleteTypeTechniques(ctx context.Context, type_ string) error {
+	_, err := q.db.ExecContext(ctx, deleteTypeTechniques, type_)
+	return err
+}
+
+const deleteUser = `-- name: DeleteUser :exec
+DELETE
+FROM users
+WHERE id = ?1
+  AND id != 'system'
+`
+
+func (q *WriteQueries) DeleteUser(ctx context.Context, id string) error {
+	_, err := q.db.ExecContext(ctx, deleteUser, id)
+	return err
 }
 
-func (q *WriteQueries) AssignGroupToUser(ctx context.Context, arg AssignGroupToUserParams) error {
-	_, err := q.db.ExecContext(ctx, assignGroupToUser, arg.UserID, arg.GroupID)
+const deleteWebhook = `-- name: DeleteWebhook :exec
+DELETE
+FROM webhooks
+WHERE id = ?1
+`
+
+func (q *WriteQueries) DeleteWebhook(ctx context.Context, id string) error {
+	_, err := q.db.ExecContext(ctx, deleteWebhook, id)
 	return err
 }
 
-const assignParentGroup = `-- name: AssignParentGroup :exec
-INSERT INTO group_inheritance (parent_group_id, child_group_id)
+const deleteWorkEntry = `-- name: DeleteWorkEntry :exec
+DELETE
+FROM work_entries
+WHERE id = ?1
+`
+
+func (q *WriteQueries) DeleteWorkEntry(ctx context.Context, id string) error {
+	_, err := q.db.ExecContext(ctx, deleteWorkEntry, id)
+	return err
+}
+
+const encryptTicket = `-- name: EncryptTicket :one
+UPDATE tickets
+SET encrypted   = TRUE,
+    description = ?1,
+    state       = ?2,
+    updated     = CURRENT_TIMESTAMP
+WHERE id = ?3
+  AND encrypted = FALSE
+RETURNING id, type, owner, name, description, open, resolution, schema, state, created, updated, acknowledged, acknowledged_by, resolved, encrypted, "key", resolved_by, reopen_count
+`
+
+type EncryptTicketParams struct {
+	Description string `json:"description"`
+	State       []byte `json:"state"`
+	ID          string `json:"id"`
+}
+
+func (q *WriteQueries) EncryptTicket(ctx context.Context, arg EncryptTicketParams) (Ticket, error) {
+	row := q.db.QueryRowContext(ctx, encryptTicket, arg.Description, arg.State, arg.ID)
+	var i Ticket
+	err := row.Scan(
+		&i.ID,
+		&i.Type,
+		&i.Owner,
+		&i.Name,
+		&i.Description,
+		&i.Open,
+		&i.Resolution,
+		&i.Schema,
+		&i.State,
+		&i.Created,
+		&i.Updated,
+		&i.Acknowledged,
+		&i.AcknowledgedBy,
+		&i.Resolved,
+		&i.Encrypted,
+		&i.Key,
+		&i.ResolvedBy,
+		&i.ReopenCount,
+	)
+	return i, err
+}
+
+const fireTaskTimer = `-- name: FireTaskTimer :exec
+UPDATE task_timers
+SET result          = ?1,
+    escalation_task = ?2,
+    fired           = ?3,
+    updated         = CURRENT_TIMESTAMP
+WHERE task = ?4
+`
+
+type FireTaskTimerParams struct {
+	Result         *string    `json:"result"`
+	EscalationTask *string    `json:"escalation_task"`
+	Fired          *time.Time `json:"fired"`
+	Task           string     `json:"task"`
+}
+
+func (q *WriteQueries) FireTaskTimer(ctx context.Context, arg FireTaskTimerParams) error {
+	_, err := q.db.ExecContext(ctx, fireTaskTimer,
+		arg.Result,
+		arg.EscalationTask,
+		arg.Fired,
+		arg.Task,
+	)
+	return err
+}
+
+const initParam = `-- name: InitParam :exec
+INSERT INTO _params (key, value)
 VALUES (?1, ?2)
+ON CONFLICT (key) DO NOTHING
 `
 
-type AssignParentGroupParams struct {
-	ParentGroupID string `json:"parent_group_id"`
-	ChildGroupID  string `json:"child_group_id"`
+type InitParamParams struct {
+	Key   string `json:"key"`
+	Value []byte `json:"value"`
 }
 
-func (q *WriteQueries) AssignParentGroup(ctx context.Context, arg AssignParentGroupParams) error {
-	_, err := q.db.ExecContext(ctx, assignParentGroup, arg.ParentGroupID, arg.ChildGroupID)
+// InitParam stores a param unless it exists, concurrent callers store it once.
+func (q *WriteQueries) InitParam(ctx context.Context, arg InitParamParams) error {
+	_, err := q.db.ExecContext(ctx, initParam, arg.Key, arg.Value)
 	return err
 }
 
-const createComment = `-- name: CreateComment :one
-INSERT INTO comments (author, message, ticket)
-VALUES (?1, ?2, ?3)
+const insertComment = `-- name: InsertComment :one
+
+INSERT INTO comments (id, author, message, ticket, created, updated)
+VALUES (?1, ?2, ?3, ?4, ?5, ?6)
 RETURNING id, ticket, author, message, created, updated
 `
 
-type CreateCommentParams struct {
-	Author  string `json:"author"`
-	Message string `json:"message"`
-	Ticket  string `json:"ticket"`
+type InsertCommentParams struct {
+	ID      string    `json:"id"`
+	Author  string    `json:"author"`
+	Message string    `json:"message"`
+	Ticket  string    `json:"ticket"`
+	Created time.Time `json:"created"`
+	Updated time.Time `json:"updated"`
 }
 
-func (q *WriteQueries) CreateComment(ctx context.Context, arg CreateCommentParams) (Comment, error) {
-	row := q.db.QueryRowContext(ctx, createComment, arg.Author, arg.Message, arg.Ticket)
+// ----------------------------------------------------------------
+func (q *WriteQueries) InsertComment(ctx context.Context, arg InsertCommentParams) (Comment, error) {
+	row := q.db.QueryRowContext(ctx, insertComment,
+		arg.ID,
+		arg.Author,
+		arg.Message,
+		arg.Ticket,
+		arg.Created,
+		arg.Updated,
+	)
 	var i Comment
 	err := row.Scan(
 		&i.ID,
@@ -66,39 +2572,55 @@ func (q *WriteQueries) CreateComment(ctx context.Context, arg CreateCommentParam
 	return i, err
 }
 
-const createFeature = `-- name: CreateFeature :one
-
-INSERT INTO features (key)
-VALUES (?1)
-RETURNING "key"
+const insertFeedIndicator = `-- name: InsertFeedIndicator :exec
+INSERT OR IGNORE INTO feed_indicators (feed, kind, value)
+VALUES (?1, ?2, ?3)
 `
 
-// ----------------------------------------------------------------
-func (q *WriteQueries) CreateFeature(ctx context.Context, key string) (string, error) {
-	row := q.db.QueryRowContext(ctx, createFeature, key)
-	err := row.Scan(&key)
-	return key, err
+type InsertFeedIndicatorParams struct {
+	Feed  string `json:"feed"`
+	Kind  string `json:"kind"`
+	Value string `json:"value"`
 }
 
-const createFile = `-- name: CreateFile :one
-INSERT INTO files (name, blob, size, ticket)
-VALUES (?1, ?2, ?3, ?4)
-RETURNING id, ticket, name, blob, size, created, updated
+func (q *WriteQueries) InsertFeedIndicator(ctx context.Context, arg InsertFeedIndicatorParams) error {
+	_, err := q.db.ExecContext(ctx, insertFeedIndicator, arg.Feed, arg.Kind, arg.Value)
+	return err
+}
+
+const insertFile = `-- name: InsertFile :one
+
+INSERT INTO files (id, name, blob, size, ticket, md5, sha1, sha256, created, updated)
+VALUES (?1, ?2, ?3, ?4, ?5, ?6, ?7, ?8, ?9, ?10)
+RETURNING id, ticket, name, blob, size, created, updated, md5, sha1, sha256
 `
 
-type CreateFileParams struct {
-	Name   string  `json:"name"`
-	Blob   string  `json:"blob"`
-	Size   float64 `json:"size"`
-	Ticket string  `json:"ticket"`
+type InsertFileParams struct {
+	ID      string    `json:"id"`
+	Name    string    `json:"name"`
+	Blob    string    `json:"blob"`
+	Size    float64   `json:"size"`
+	Ticket  string    `json:"ticket"`
+	Md5     string    `json:"md5"`
+	Sha1    string    `json:"sha1"`
+	Sha256  string    `json:"sha256"`
+	Created time.Time `json:"created"`
+	Updated time.Time `json:"updated"`
 }
 
-func (q *WriteQueries) CreateFile(ctx context.Context, arg CreateFileParams) (File, error) {
-	row := q.db.QueryRowContext(ctx, createFile,
+// ----------------------------------------------------------------
+func (q *WriteQueries) InsertFile(ctx context.Context, arg InsertFileParams) (File, error) {
+	row := q.db.QueryRowContext(ctx, insertFile,
+		arg.ID,
 		arg.Name,
 		arg.Blob,
 		arg.Size,
 		arg.Ticket,
+		arg.Md5,
+		arg.Sha1,
+		arg.Sha256,
+		arg.Created,
+		arg.Updated,
 	)
 	var i File
 	err := row.Scan(
@@ -109,23 +2631,37 @@ func (q *WriteQueries) CreateFile(ctx context.Context, arg CreateFileParams) (Fi
 		&i.Size,
 		&i.Created,
 		&i.Updated,
+		&i.Md5,
+		&i.Sha1,
+		&i.Sha256,
 	)
 	return i, err
 }
 
-const createGroup = `-- name: CreateGroup :one
-INSERT INTO groups (name, permissions)
-VALUES (?1, ?2)
+const insertGroup = `-- name: InsertGroup :one
+
+INSERT INTO groups (id, name, permissions, created, updated)
+VALUES (?1, ?2, ?3, ?4, ?5)
 RETURNING id, name, permissions, created, updated
 `
 
-type CreateGroupParams struct {
-	Name        string `json:"name"`
-	Permissions string `json:"permissions"`
+type InsertGroupParams struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Permissions string    `json:"permissions"`
+	Created     time.Time `json:"created"`
+	Updated     time.Time `json:"updated"`
 }
 
-func (q *WriteQueries) CreateGroup(ctx context.Context, arg CreateGroupParams) (Group, error) {
-	row := q.db.QueryRowContext(ctx, createGroup, arg.Name, arg.Permissions)
+// ----------------------------------------------------------------
+func (q *WriteQueries) InsertGroup(ctx context.Context, arg InsertGroupParams) (Group, error) {
+	row := q.db.QueryRowContext(ctx, insertGroup,
+		arg.ID,
+		arg.Name,
+		arg.Permissions,
+		arg.Created,
+		arg.Updated,
+	)
 	var i Group
 	err := row.Scan(
 		&i.ID,
@@ -137,20 +2673,32 @@ func (q *WriteQueries) CreateGroup(ctx context.Context, arg CreateGroupParams) (
 	return i, err
 }
 
-const createLink = `-- name: CreateLink :one
-INSERT INTO links (name, url, ticket)
-VALUES (?1, ?2, ?3)
+const insertLink = `-- name: InsertLink :one
+
+INSERT INTO links (id, name, url, ticket, created, updated)
+VALUES (?1, ?2, ?3, ?4, ?5, ?6)
 RETURNING id, ticket, name, url, created, updated
 `
 
-type CreateLinkParams struct {
-	Name   string `json:"name"`
-	Url    string `json:"url"`
-	Ticket string `json:"ticket"`
+type InsertLinkParams struct {
+	ID      string    `json:"id"`
+	Name    string    `json:"name"`
+	Url     string    `json:"url"`
+	Ticket  string    `json:"ticket"`
+	Created time.Time `json:"created"`
+	Updated time.Time `json:"updated"`
 }
 
-func (q *WriteQueries) CreateLink(ctx context.Context, arg CreateLinkParams) (Link, error) {
-	row := q.db.QueryRowContext(ctx, createLink, arg.Name, arg.Url, arg.Ticket)
+// ----------------------------------------------------------------
+func (q *WriteQueries) InsertLink(ctx context.Context, arg InsertLinkParams) (Link, error) {
+	row := q.db.QueryRowContext(ctx, insertLink,
+		arg.ID,
+		arg.Name,
+		arg.Url,
+		arg.Ticket,
+		arg.Created,
+		arg.Updated,
+	)
 	var i Link
 	err := row.Scan(
 		&i.ID,
@@ -163,43 +2711,35 @@ func (q *WriteQueries) CreateLink(ctx context.Context, arg CreateLinkParams) (Li
 	return i, err
 }
 
-const createParam = `-- name: CreateParam :exec
-INSERT INTO _params (key, value)
-VALUES (?1, ?2)
-RETURNING "key", value
-`
-
-type CreateParamParams struct {
-	Key   string `json:"key"`
-	Value []byte `json:"value"`
-}
-
-func (q *WriteQueries) CreateParam(ctx context.Context, arg CreateParamParams) error {
-	_, err := q.db.ExecContext(ctx, createParam, arg.Key, arg.Value)
-	return err
-}
+const insertReaction = `-- name: InsertReaction :one
 
-const createReaction = `-- name: CreateReaction :one
-INSERT INTO reactions (name, action, actiondata, trigger, triggerdata)
-VALUES (?1, ?2, ?3, ?4, ?5)
+INSERT INTO reactions (id, name, action, actiondata, trigger, triggerdata, created, updated)
+VALUES (?1, ?2, ?3, ?4, ?5, ?6, ?7, ?8)
 RETURNING id, name, "action", actiondata, "trigger", triggerdata, created, updated
 `
 
-type CreateReactionParams struct {
-	Name        string `json:"name"`
-	Action      string `json:"action"`
-	Actiondata  []byte `json:"actiondata"`
-	Trigger     string `json:"trigger"`
-	Triggerdata []byte `json:"triggerdata"`
+type InsertReactionParams struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Action      string    `json:"action"`
+	Actiondata  []byte    `json:"actiondata"`
+	Trigger     string    `json:"trigger"`
+	Triggerdata []byte    `json:"triggerdata"`
+	Created     time.Time `json:"created"`
+	Updated     time.Time `json:"updated"`
 }
 
-func (q *WriteQueries) CreateReaction(ctx context.Context, arg CreateReactionParams) (Reaction, error) {
-	row := q.db.QueryRowContext(ctx, createReaction,
+// ----------------------------------------------------------------
+func (q *WriteQueries) InsertReaction(ctx context.Context, arg InsertReactionParams) (Reaction, error) {
+	row := q.db.QueryRowContext(ctx, insertReaction,
+		arg.ID,
 		arg.Name,
 		arg.Action,
 		arg.Actiondata,
 		arg.Trigger,
 		arg.Triggerdata,
+		arg.Created,
+		arg.Updated,
 	)
 	var i Reaction
 	err := row.Scan(
@@ -215,25 +2755,33 @@ func (q *WriteQueries) CreateReaction(ctx context.Context, arg CreateReactionPar
 	return i, err
 }
 
-const createTask = `-- name: CreateTask :one
-INSERT INTO tasks (name, open, owner, ticket)
-VALUES (?1, ?2, ?3, ?4)
+const insertTask = `-- name: InsertTask :one
+
+INSERT INTO tasks (id, name, open, owner, ticket, created, updated)
+VALUES (?1, ?2, ?3, ?4, ?5, ?6, ?7)
 RETURNING id, ticket, owner, name, open, created, updated
 `
 
-type CreateTaskParams struct {
-	Name   string  `json:"name"`
-	Open   bool    `json:"open"`
-	Owner  *string `json:"owner"`
-	Ticket string  `json:"ticket"`
+type InsertTaskParams struct {
+	ID      string    `json:"id"`
+	Name    string    `json:"name"`
+	Open    bool      `json:"open"`
+	Owner   *string   `json:"owner"`
+	Ticket  string    `json:"ticket"`
+	Created time.Time `json:"created"`
+	Updated time.Time `json:"updated"`
 }
 
-func (q *WriteQueries) CreateTask(ctx context.Context, arg CreateTaskParams) (Task, error) {
-	row := q.db.QueryRowContext(ctx, createTask,
+// ----------------------------------------------------------------
+func (q *WriteQueries) InsertTask(ctx context.Context, arg InsertTaskParams) (Task, error) {
+	row := q.db.QueryRowContext(ctx, insertTask,
+		arg.ID,
 		arg.Name,
 		arg.Open,
 		arg.Owner,
 		arg.Ticket,
+		arg.Created,
+		arg.Updated,
 	)
 	var i Task
 	err := row.Scan(
@@ -248,25 +2796,31 @@ func (q *WriteQueries) CreateTask(ctx context.Context, arg CreateTaskParams) (Ta
 	return i, err
 }
 
-const createTicket = `-- name: CreateTicket :one
-INSERT INTO tickets (name, description, open, owner, resolution, schema, state, type)
-VALUES (?1, ?2, ?3, ?4, ?5, ?6, ?7, ?8)
-RETURNING id, type, owner, name, description, open, resolution, schema, state, created, updated
+const insertTicket = `-- name: InsertTicket :one
+
+INSERT INTO tickets (id, name, description, open, owner, resolution, schema, state, type, created, updated)
+VALUES (?1, ?2, ?3, ?4, ?5, ?6, ?7, ?8, ?9, ?10, ?11)
+RETURNING id, type, owner, name, description, open, resolution, schema, state, created, updated, acknowledged, acknowledged_by, resolved, encrypted, "key", resolved_by, reopen_count
 `
 
-type CreateTicketParams struct {
-	Name        string  `json:"name"`
-	Description string  `json:"description"`
-	Open        bool    `json:"open"`
-	Owner       *string `json:"owner"`
-	Resolution  *string `json:"resolution"`
-	Schema      []byte  `json:"schema"`
-	State       []byte  `json:"state"`
-	Type        string  `json:"type"`
+type InsertTicketParams struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Open        bool      `json:"open"`
+	Owner       *string   `json:"owner"`
+	Resolution  *string   `json:"resolution"`
+	Schema      []byte    `json:"schema"`
+	State       []byte    `json:"state"`
+	Type        string    `json:"type"`
+	Created     time.Time `json:"created"`
+	Updated     time.Time `json:"updated"`
 }
 
-func (q *WriteQueries) CreateTicket(ctx context.Context, arg CreateTicketParams) (Ticket, error) {
-	row := q.db.QueryRowContext(ctx, createTicket,
+// ----------------------------------------------------------------
+func (q *WriteQueries) InsertTicket(ctx context.Context, arg InsertTicketParams) (Ticket, error) {
+	row := q.db.QueryRowContext(ctx, insertTicket,
+		arg.ID,
 		arg.Name,
 		arg.Description,
 		arg.Open,
@@ -275,6 +2829,8 @@ func (q *WriteQueries) CreateTicket(ctx context.Context, arg CreateTicketParams)
 		arg.Schema,
 		arg.State,
 		arg.Type,
+		arg.Created,
+		arg.Updated,
 	)
 	var i Ticket
 	err := row.Scan(
@@ -289,24 +2845,43 @@ func (q *WriteQueries) CreateTicket(ctx context.Context, arg CreateTicketParams)
 		&i.State,
 		&i.Created,
 		&i.Updated,
+		&i.Acknowledged,
+		&i.AcknowledgedBy,
+		&i.Resolved,
+		&i.Encrypted,
+		&i.Key,
+		&i.ResolvedBy,
+		&i.ReopenCount,
 	)
 	return i, err
 }
 
-const createTimeline = `-- name: CreateTimeline :one
-INSERT INTO timeline (message, ticket, time)
-VALUES (?1, ?2, ?3)
+const insertTimeline = `-- name: InsertTimeline :one
+
+INSERT INTO timeline (id, message, ticket, time, created, updated)
+VALUES (?1, ?2, ?3, ?4, ?5, ?6)
 RETURNING id, ticket, message, time, created, updated
 `
 
-type CreateTimelineParams struct {
+type InsertTimelineParams struct {
+	ID      string    `json:"id"`
 	Message string    `json:"message"`
 	Ticket  string    `json:"ticket"`
 	Time    time.Time `json:"time"`
+	Created time.Time `json:"created"`
+	Updated time.Time `json:"updated"`
 }
 
-func (q *WriteQueries) CreateTimeline(ctx context.Context, arg CreateTimelineParams) (Timeline, error) {
-	row := q.db.QueryRowContext(ctx, createTimeline, arg.Message, arg.Ticket, arg.Time)
+// ----------------------------------------------------------------
+func (q *WriteQueries) InsertTimeline(ctx context.Context, arg InsertTimelineParams) (Timeline, error) {
+	row := q.db.QueryRowContext(ctx, insertTimeline,
+		arg.ID,
+		arg.Message,
+		arg.Ticket,
+		arg.Time,
+		arg.Created,
+		arg.Updated,
+	)
 	var i Timeline
 	err := row.Scan(
 		&i.ID,
@@ -319,25 +2894,33 @@ func (q *WriteQueries) CreateTimeline(ctx context.Context, arg CreateTimelinePar
 	return i, err
 }
 
-const createType = `-- name: CreateType :one
-INSERT INTO types (singular, plural, icon, schema)
-VALUES (?1, ?2, ?3, ?4)
-RETURNING id, icon, singular, plural, schema, created, updated
+const insertType = `-- name: InsertType :one
+
+INSERT INTO types (id, singular, plural, icon, schema, created, updated)
+VALUES (?1, ?2, ?3, ?4, ?5, ?6, ?7)
+RETURNING id, icon, singular, plural, schema, created, updated, key_prefix, metrics
 `
 
-type CreateTypeParams struct {
-	Singular string  `json:"singular"`
-	Plural   string  `json:"plural"`
-	Icon     *string `json:"icon"`
-	Schema   []byte  `json:"schema"`
+type InsertTypeParams struct {
+	ID       string    `json:"id"`
+	Singular string    `json:"singular"`
+	Plural   string    `json:"plural"`
+	Icon     *string   `json:"icon"`
+	Schema   []byte    `json:"schema"`
+	Created  time.Time `json:"created"`
+	Updated  time.Time `json:"updated"`
 }
 
-func (q *WriteQueries) CreateType(ctx context.Context, arg CreateTypeParams) (Type, error) {
-	row := q.db.QueryRowContext(ctx, createType,
+// ----------------------------------------------------------------
+func (q *WriteQueries) InsertType(ctx context.Context, arg InsertTypeParams) (Type, error) {
+	row := q.db.QueryRowContext(ctx, insertType,
+		arg.ID,
 		arg.Singular,
 		arg.Plural,
 		arg.Icon,
 		arg.Schema,
+		arg.Created,
+		arg.Updated,
 	)
 	var i Type
 	err := row.Scan(
@@ -348,28 +2931,36 @@ func (q *WriteQueries) CreateType(ctx context.Context, arg CreateTypeParams) (Ty
 		&i.Schema,
 		&i.Created,
 		&i.Updated,
+		&i.KeyPrefix,
+		&i.Metrics,
 	)
 	return i, err
 }
 
-const createUser = `-- name: CreateUser :one
-INSERT INTO users (name, email, username, passwordHash, tokenKey, avatar, active)
-VALUES (?1, ?2, ?3, ?4, ?5, ?6, ?7)
+const insertUser = `-- name: InsertUser :one
+
+INSERT INTO users (id, name, email, username, passwordHash, tokenKey, avatar, active, created, updated)
+VALUES (?1, ?2, ?3, ?4, ?5, ?6, ?7, ?8, ?9, ?10)
 RETURNING id, username, passwordhash, tokenkey, active, name, email, avatar, lastresetsentat, lastverificationsentat, created, updated
 `
 
-type CreateUserParams struct {
-	Name         *string `json:"name"`
-	Email        *string `json:"email"`
-	Username     string  `json:"username"`
-	PasswordHash string  `json:"passwordHash"`
-	TokenKey     string  `json:"tokenKey"`
-	Avatar       *string `json:"avatar"`
-	Active       bool    `json:"active"`
+type InsertUserParams struct {
+	ID           string    `json:"id"`
+	Name         *string   `json:"name"`
+	Email        *string   `json:"email"`
+	Username     string    `json:"username"`
+	PasswordHash string    `json:"passwordHash"`
+	TokenKey     string    `json:"tokenKey"`
+	Avatar       *string   `json:"avatar"`
+	Active       bool      `json:"active"`
+	Created      time.Time `json:"created"`
+	Updated      time.Time `json:"updated"`
 }
 
-func (q *WriteQueries) CreateUser(ctx context.Context, arg CreateUserParams) (User, error) {
-	row := q.db.QueryRowContext(ctx, createUser,
+// ----------------------------------------------------------------
+func (q *WriteQueries) InsertUser(ctx context.Context, arg InsertUserParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, insertUser,
+		arg.ID,
 		arg.Name,
 		arg.Email,
 		arg.Username,
@@ -377,6 +2968,8 @@ func (q *WriteQueries) CreateUser(ctx context.Context, arg CreateUserParams) (Us
 		arg.TokenKey,
 		arg.Avatar,
 		arg.Active,
+		arg.Created,
+		arg.Updated,
 	)
 	var i User
 	err := row.Scan(
@@ -396,678 +2989,1269 @@ func (q *WriteQueries) CreateUser(ctx context.Context, arg CreateUserParams) (Us
 	return i, err
 }
 
-const createWebhook = `-- name: CreateWebhook :one
-INSERT INTO webhooks (name, collection, destination)
-VALUES (?1, ?2, ?3)
+const insertWebhook = `-- name: InsertWebhook :one
+
+INSERT INTO webhooks (id, name, collection, destination, created, updated)
+VALUES (?1, ?2, ?3, ?4, ?5, ?6)
 RETURNING id, collection, destination, name, created, updated
 `
 
-type CreateWebhookParams struct {
-	Name        string `json:"name"`
-	Collection  string `json:"collection"`
-	Destination string `json:"destination"`
+type InsertWebhookParams struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Collection  string    `json:"collection"`
+	Destination string    `json:"destination"`
+	Created     time.Time `json:"created"`
+	Updated     time.Time `json:"updated"`
+}
+
+// ----------------------------------------------------------------
+func (q *WriteQueries) InsertWebhook(ctx context.Context, arg InsertWebhookParams) (Webhook, error) {
+	row := q.db.QueryRowContext(ctx, insertWebhook,
+		arg.ID,
+		arg.Name,
+		arg.Collection,
+		arg.Destination,
+		arg.Created,
+		arg.Updated,
+	)
+	var i Webhook
+	err := row.Scan(
+		&i.ID,
+		&i.Collection,
+		&i.Destination,
+		&i.Name,
+		&i.Created,
+		&i.Updated,
+	)
+	return i, err
+}
+
+const pauseTicketEscalation = `-- name: PauseTicketEscalation :exec
+UPDATE ticket_escalations
+SET paused_since = ?1,
+    updated      = CURRENT_TIMESTAMP
+WHERE ticket = ?2
+  AND paused_since IS NULL
+`
+
+type PauseTicketEscalationParams struct {
+	PausedSince *time.Time `json:"paused_since"`
+	Ticket      string     `json:"ticket"`
+}
+
+func (q *WriteQueries) PauseTicketEscalation(ctx context.Context, arg PauseTicketEscalationParams) error {
+	_, err := q.db.ExecContext(ctx, pauseTicketEscalation, arg.PausedSince, arg.Ticket)
+	return err
+}
+
+const postponeTaskTimer = `-- name: PostponeTaskTimer :exec
+UPDATE task_timers
+SET due     = ?1,
+    updated = CURRENT_TIMESTAMP
+WHERE task = ?2
+  AND fired IS NULL
+`
+
+type PostponeTaskTimerParams struct {
+	Due  time.Time `json:"due"`
+	Task string    `json:"task"`
+}
+
+func (q *WriteQueries) PostponeTaskTimer(ctx context.Context, arg PostponeTaskTimerParams) error {
+	_, err := q.db.ExecContext(ctx, postponeTaskTimer, arg.Due, arg.Task)
+	return err
+}
+
+const postponeTicketEscalation = `-- name: PostponeTicketEscalation :exec
+UPDATE ticket_escalations
+SET next_at = ?1,
+    updated = CURRENT_TIMESTAMP
+WHERE ticket = ?2
+`
+
+type PostponeTicketEscalationParams struct {
+	NextAt *time.Time `json:"next_at"`
+	Ticket string     `json:"ticket"`
+}
+
+func (q *WriteQueries) PostponeTicketEscalation(ctx context.Context, arg PostponeTicketEscalationParams) error {
+	_, err := q.db.ExecContext(ctx, postponeTicketEscalation, arg.NextAt, arg.Ticket)
+	return err
+}
+
+const promoteAlert = `-- name: PromoteAlert :one
+UPDATE alerts
+SET status  = 'promoted',
+    ticket  = ?1,
+    updated = CURRENT_TIMESTAMP
+WHERE id = ?2
+  AND status != 'promoted'
+RETURNING id, source, name, description, severity, data, status, ticket, created, updated, maintenance
+`
+
+type PromoteAlertParams struct {
+	Ticket *string `json:"ticket"`
+	ID     string  `json:"id"`
+}
+
+func (q *WriteQueries) PromoteAlert(ctx context.Context, arg PromoteAlertParams) (Alert, error) {
+	row := q.db.QueryRowContext(ctx, promoteAlert, arg.Ticket, arg.ID)
+	var i Alert
+	err := row.Scan(
+		&i.ID,
+		&i.Source,
+		&i.Name,
+		&i.Description,
+		&i.Severity,
+		&i.Data,
+		&i.Status,
+		&i.Ticket,
+		&i.Created,
+		&i.Updated,
+		&i.Maintenance,
+	)
+	return i, err
+}
+
+const pruneJournal = `-- name: PruneJournal :exec
+DELETE
+FROM request_journal
+WHERE request_journal.reaction = ?1
+  AND request_journal.id NOT IN (SELECT kept.id
+                 FROM request_journal AS kept
+                 WHERE kept.reaction = ?1
+                 ORDER BY kept.created DESC, kept.rowid DESC
+                 LIMIT ?2)
+`
+
+type PruneJournalParams struct {
+	Reaction string `json:"reaction"`
+	Keep     int64  `json:"keep"`
+}
+
+func (q *WriteQueries) PruneJournal(ctx context.Context, arg PruneJournalParams) error {
+	_, err := q.db.ExecContext(ctx, pruneJournal, arg.Reaction, arg.Keep)
+	return err
+}
+
+const prunePasswordHistory = `-- name: PrunePasswordHistory :exec
+DELETE
+FROM password_history
+WHERE password_history.user = ?1
+  AND password_history.id NOT IN (SELECT history.id
+                 FROM password_history history
+                 WHERE history.user = ?1
+                 ORDER BY history.created DESC, history.rowid DESC
+                 LIMIT ?2)
+`
+
+type PrunePasswordHistoryParams struct {
+	User string `json:"user"`
+	Keep int64  `json:"keep"`
+}
+
+func (q *WriteQueries) PrunePasswordHistory(ctx context.Context, arg PrunePasswordHistoryParams) error {
+	_, err := q.db.ExecContext(ctx, prunePasswordHistory, arg.User, arg.Keep)
+	return err
+}
+
+const readAnnouncement = `-- name: ReadAnnouncement :exec
+INSERT OR IGNORE INTO announcement_receipts (announcement, user, read)
+VALUES (?1, ?2, ?3)
+`
+
+type ReadAnnouncementParams struct {
+	Announcement string    `json:"announcement"`
+	User         string    `json:"user"`
+	Read         time.Time `json:"read"`
+}
+
+func (q *WriteQueries) ReadAnnouncement(ctx context.Context, arg ReadAnnouncementParams) error {
+	_, err := q.db.ExecContext(ctx, readAnnouncement, arg.Announcement, arg.User, arg.Read)
+	return err
+}
+
+const reassignOpenTasks = `-- name: ReassignOpenTasks :many
+UPDATE tasks
+SET owner   = ?1,
+    updated = CURRENT_TIMESTAMP
+WHERE owner = ?2
+  AND open = TRUE
+RETURNING id
+`
+
+type ReassignOpenTasksParams struct {
+	NewOwner *string `json:"new_owner"`
+	Owner    *string `json:"owner"`
+}
+
+func (q *WriteQueries) ReassignOpenTasks(ctx context.Context, arg ReassignOpenTasksParams) ([]string, error) {
+	rows, err := q.db.QueryContext(ctx, reassignOpenTasks, arg.NewOwner, arg.Owner)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const reassignOpenTickets = `-- name: ReassignOpenTickets :many
+UPDATE tickets
+SET owner   = ?1,
+    updated = CURRENT_TIMESTAMP
+WHERE owner = ?2
+  AND open = TRUE
+RETURNING id
+`
+
+type ReassignOpenTicketsParams struct {
+	NewOwner *string `json:"new_owner"`
+	Owner    *string `json:"owner"`
+}
+
+func (q *WriteQueries) ReassignOpenTickets(ctx context.Context, arg ReassignOpenTicketsParams) ([]string, error) {
+	rows, err := q.db.QueryContext(ctx, reassignOpenTickets, arg.NewOwner, arg.Owner)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const reassignTaskTimers = `-- name: ReassignTaskTimers :many
+UPDATE task_timers
+SET escalation_owner = ?1,
+    updated          = CURRENT_TIMESTAMP
+WHERE escalation_owner = ?2
+  AND fired IS NULL
+RETURNING task
+`
+
+type ReassignTaskTimersParams struct {
+	NewOwner *string `json:"new_owner"`
+	Owner    *string `json:"owner"`
+}
+
+func (q *WriteQueries) ReassignTaskTimers(ctx context.Context, arg ReassignTaskTimersParams) ([]string, error) {
+	rows, err := q.db.QueryContext(ctx, reassignTaskTimers, arg.NewOwner, arg.Owner)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var task string
+		if err := rows.Scan(&task); err != nil {
+			return nil, err
+		}
+		items = append(items, task)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const recordPlatformError = `-- name: RecordPlatformError :one
+UPDATE platform_errors
+SET occurrences = occurrences + 1,
+    last_seen   = ?1
+WHERE fingerprint = ?2
+RETURNING fingerprint, ticket, occurrences, first_seen, last_seen
+`
+
+type RecordPlatformErrorParams struct {
+	Seen        time.Time `json:"seen"`
+	Fingerprint string    `json:"fingerprint"`
 }
 
-func (q *WriteQueries) CreateWebhook(ctx context.Context, arg CreateWebhookParams) (Webhook, error) {
-	row := q.db.QueryRowContext(ctx, createWebhook, arg.Name, arg.Collection, arg.Destination)
-	var i Webhook
+func (q *WriteQueries) RecordPlatformError(ctx context.Context, arg RecordPlatformErrorParams) (PlatformError, error) {
+	row := q.db.QueryRowContext(ctx, recordPlatformError, arg.Seen, arg.Fingerprint)
+	var i PlatformError
 	err := row.Scan(
-		&i.ID,
-		&i.Collection,
-		&i.Destination,
-		&i.Name,
-		&i.Created,
-		&i.Updated,
+		&i.Fingerprint,
+		&i.Ticket,
+		&i.Occurrences,
+		&i.FirstSeen,
+		&i.LastSeen,
 	)
 	return i, err
 }
 
-const deleteComment = `-- name: DeleteComment :exec
+const removeCampaignTicket = `-- name: RemoveCampaignTicket :exec
 DELETE
-FROM comments
-WHERE id = ?1
+FROM campaign_tickets
+WHERE campaign = ?1
+  AND ticket = ?2
 `
 
-func (q *WriteQueries) DeleteComment(ctx context.Context, id string) error {
-	_, err := q.db.ExecContext(ctx, deleteComment, id)
-	return err
+type RemoveCampaignTicketParams struct {
+	Campaign string `json:"campaign"`
+	Ticket   string `json:"ticket"`
 }
 
-const deleteFeature = `-- name: DeleteFeature :exec
-DELETE
-FROM features
-WHERE key = ?1
-`
-
-func (q *WriteQueries) DeleteFeature(ctx context.Context, key string) error {
-	_, err := q.db.ExecContext(ctx, deleteFeature, key)
+func (q *WriteQueries) RemoveCampaignTicket(ctx context.Context, arg RemoveCampaignTicketParams) error {
+	_, err := q.db.ExecContext(ctx, removeCampaignTicket, arg.Campaign, arg.Ticket)
 	return err
 }
 
-const deleteFile = `-- name: DeleteFile :exec
+const removeDetectionRuleTicket = `-- name: RemoveDetectionRuleTicket :exec
 DELETE
-FROM files
-WHERE id = ?1
+FROM detection_rule_tickets
+WHERE rule = ?1
+  AND ticket = ?2
 `
 
-func (q *WriteQueries) DeleteFile(ctx context.Context, id string) error {
-	_, err := q.db.ExecContext(ctx, deleteFile, id)
+type RemoveDetectionRuleTicketParams struct {
+	Rule   string `json:"rule"`
+	Ticket string `json:"ticket"`
+}
+
+func (q *WriteQueries) RemoveDetectionRuleTicket(ctx context.Context, arg RemoveDetectionRuleTicketParams) error {
+	_, err := q.db.ExecContext(ctx, removeDetectionRuleTicket, arg.Rule, arg.Ticket)
 	return err
 }
 
-const deleteGroup = `-- name: DeleteGroup :exec
+const removeGroupFromUser = `-- name: RemoveGroupFromUser :exec
 DELETE
-FROM groups
-WHERE id = ?1
+FROM user_groups
+WHERE user_id = ?1
+  AND group_id = ?2
 `
 
-func (q *WriteQueries) DeleteGroup(ctx context.Context, id string) error {
-	_, err := q.db.ExecContext(ctx, deleteGroup, id)
+type RemoveGroupFromUserParams struct {
+	UserID  string `json:"user_id"`
+	GroupID string `json:"group_id"`
+}
+
+func (q *WriteQueries) RemoveGroupFromUser(ctx context.Context, arg RemoveGroupFromUserParams) error {
+	_, err := q.db.ExecContext(ctx, removeGroupFromUser, arg.UserID, arg.GroupID)
 	return err
 }
 
-const deleteLink = `-- name: DeleteLink :exec
+const removeParentGroup = `-- name: RemoveParentGroup :exec
 DELETE
-FROM links
-WHERE id = ?1
+FROM group_inheritance
+WHERE parent_group_id = ?1
+  AND child_group_id = ?2
 `
 
-func (q *WriteQueries) DeleteLink(ctx context.Context, id string) error {
-	_, err := q.db.ExecContext(ctx, deleteLink, id)
+type RemoveParentGroupParams struct {
+	ParentGroupID string `json:"parent_group_id"`
+	ChildGroupID  string `json:"child_group_id"`
+}
+
+func (q *WriteQueries) RemoveParentGroup(ctx context.Context, arg RemoveParentGroupParams) error {
+	_, err := q.db.ExecContext(ctx, removeParentGroup, arg.ParentGroupID, arg.ChildGroupID)
 	return err
 }
 
-const deleteReaction = `-- name: DeleteReaction :exec
-DELETE
-FROM reactions
-WHERE id = ?1
+const renewInvitation = `-- name: RenewInvitation :one
+UPDATE invitations
+SET token_hash = ?1,
+    expires    = ?2,
+    updated    = CURRENT_TIMESTAMP
+WHERE id = ?3
+  AND accepted_by IS NULL
+RETURNING id, email, name, "groups", token_hash, invited_by, expires, accepted_by, created, updated
 `
 
-func (q *WriteQueries) DeleteReaction(ctx context.Context, id string) error {
-	_, err := q.db.ExecContext(ctx, deleteReaction, id)
-	return err
+type RenewInvitationParams struct {
+	TokenHash string    `json:"token_hash"`
+	Expires   time.Time `json:"expires"`
+	ID        string    `json:"id"`
 }
 
-const deleteTask = `-- name: DeleteTask :exec
-DELETE
-FROM tasks
+func (q *WriteQueries) RenewInvitation(ctx context.Context, arg RenewInvitationParams) (Invitation, error) {
+	row := q.db.QueryRowContext(ctx, renewInvitation, arg.TokenHash, arg.Expires, arg.ID)
+	var i Invitation
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.Name,
+		&i.Groups,
+		&i.TokenHash,
+		&i.InvitedBy,
+		&i.Expires,
+		&i.AcceptedBy,
+		&i.Created,
+		&i.Updated,
+	)
+	return i, err
+}
+
+const reopenTicket = `-- name: ReopenTicket :one
+UPDATE tickets
+SET open         = TRUE,
+    resolved     = NULL,
+    resolved_by  = NULL,
+    reopen_count = reopen_count + 1,
+    updated      = CURRENT_TIMESTAMP
 WHERE id = ?1
+  AND NOT open
+RETURNING id, type, owner, name, description, open, resolution, schema, state, created, updated, acknowledged, acknowledged_by, resolved, encrypted, "key", resolved_by, reopen_count
 `
 
-func (q *WriteQueries) DeleteTask(ctx context.Context, id string) error {
-	_, err := q.db.ExecContext(ctx, deleteTask, id)
-	return err
+func (q *WriteQueries) ReopenTicket(ctx context.Context, id string) (Ticket, error) {
+	row := q.db.QueryRowContext(ctx, reopenTicket, id)
+	var i Ticket
+	err := row.Scan(
+		&i.ID,
+		&i.Type,
+		&i.Owner,
+		&i.Name,
+		&i.Description,
+		&i.Open,
+		&i.Resolution,
+		&i.Schema,
+		&i.State,
+		&i.Created,
+		&i.Updated,
+		&i.Acknowledged,
+		&i.AcknowledgedBy,
+		&i.Resolved,
+		&i.Encrypted,
+		&i.Key,
+		&i.ResolvedBy,
+		&i.ReopenCount,
+	)
+	return i, err
 }
 
-const deleteTicket = `-- name: DeleteTicket :exec
-DELETE
-FROM tickets
-WHERE id = ?1
+const resumeTicketEscalation = `-- name: ResumeTicketEscalation :exec
+UPDATE ticket_escalations
+SET paused_since = NULL,
+    paused       = ?1,
+    next_at      = ?2,
+    updated      = CURRENT_TIMESTAMP
+WHERE ticket = ?3
 `
 
-func (q *WriteQueries) DeleteTicket(ctx context.Context, id string) error {
-	_, err := q.db.ExecContext(ctx, deleteTicket, id)
+type ResumeTicketEscalationParams struct {
+	Paused int64      `json:"paused"`
+	NextAt *time.Time `json:"next_at"`
+	Ticket string     `json:"ticket"`
+}
+
+func (q *WriteQueries) ResumeTicketEscalation(ctx context.Context, arg ResumeTicketEscalationParams) error {
+	_, err := q.db.ExecContext(ctx, resumeTicketEscalation, arg.Paused, arg.NextAt, arg.Ticket)
 	return err
 }
 
-const deleteTimeline = `-- name: DeleteTimeline :exec
-DELETE
-FROM timeline
-WHERE id = ?1
+const setAutoCloseWarning = `-- name: SetAutoCloseWarning :exec
+INSERT INTO auto_close_warnings (ticket, rule, warned)
+VALUES (?1, ?2, ?3)
+ON CONFLICT (ticket) DO UPDATE SET rule   = excluded.rule,
+                                   warned = excluded.warned
 `
 
-func (q *WriteQueries) DeleteTimeline(ctx context.Context, id string) error {
-	_, err := q.db.ExecContext(ctx, deleteTimeline, id)
+type SetAutoCloseWarningParams struct {
+	Ticket string    `json:"ticket"`
+	Rule   string    `json:"rule"`
+	Warned time.Time `json:"warned"`
+}
+
+func (q *WriteQueries) SetAutoCloseWarning(ctx context.Context, arg SetAutoCloseWarningParams) error {
+	_, err := q.db.ExecContext(ctx, setAutoCloseWarning, arg.Ticket, arg.Rule, arg.Warned)
 	return err
 }
 
-const deleteType = `-- name: DeleteType :exec
-DELETE
-FROM types
-WHERE id = ?1
+const setCommentMessage = `-- name: SetCommentMessage :exec
+UPDATE comments
+SET message = ?1
+WHERE id = ?2
 `
 
-func (q *WriteQueries) DeleteType(ctx context.Context, id string) error {
-	_, err := q.db.ExecContext(ctx, deleteType, id)
+type SetCommentMessageParams struct {
+	Message string `json:"message"`
+	ID      string `json:"id"`
+}
+
+func (q *WriteQueries) SetCommentMessage(ctx context.Context, arg SetCommentMessageParams) error {
+	_, err := q.db.ExecContext(ctx, setCommentMessage, arg.Message, arg.ID)
 	return err
 }
 
-const deleteUser = `-- name: DeleteUser :exec
-DELETE
-FROM users
-WHERE id = ?1
-  AND id != 'system'
+const setDetectionRuleStatus = `-- name: SetDetectionRuleStatus :one
+UPDATE detection_rules
+SET status   = ?1,
+    reviewer = coalesce(?2, reviewer),
+    deployed = coalesce(?3, deployed),
+    updated  = CURRENT_TIMESTAMP
+WHERE id = ?4
+RETURNING id, ticket, name, description, format, content, status, author, reviewer, deployed, created, updated
 `
 
-func (q *WriteQueries) DeleteUser(ctx context.Context, id string) error {
-	_, err := q.db.ExecContext(ctx, deleteUser, id)
-	return err
+type SetDetectionRuleStatusParams struct {
+	Status   string     `json:"status"`
+	Reviewer *string    `json:"reviewer"`
+	Deployed *time.Time `json:"deployed"`
+	ID       string     `json:"id"`
 }
 
-const deleteWebhook = `-- name: DeleteWebhook :exec
-DELETE
-FROM webhooks
-WHERE id = ?1
+func (q *WriteQueries) SetDetectionRuleStatus(ctx context.Context, arg SetDetectionRuleStatusParams) (DetectionRule, error) {
+	row := q.db.QueryRowContext(ctx, setDetectionRuleStatus,
+		arg.Status,
+		arg.Reviewer,
+		arg.Deployed,
+		arg.ID,
+	)
+	var i DetectionRule
+	err := row.Scan(
+		&i.ID,
+		&i.Ticket,
+		&i.Name,
+		&i.Description,
+		&i.Format,
+		&i.Content,
+		&i.Status,
+		&i.Author,
+		&i.Reviewer,
+		&i.Deployed,
+		&i.Created,
+		&i.Updated,
+	)
+	return i, err
+}
+
+const setDocumentTemplate = `-- name: SetDocumentTemplate :one
+
+INSERT INTO document_templates (format, name, content)
+VALUES (?1, ?2, ?3)
+ON CONFLICT (format) DO UPDATE SET name    = excluded.name,
+                                   content = excluded.content,
+                                   updated = CURRENT_TIMESTAMP
+RETURNING format, name, content, created, updated
 `
 
-func (q *WriteQueries) DeleteWebhook(ctx context.Context, id string) error {
-	_, err := q.db.ExecContext(ctx, deleteWebhook, id)
-	return err
+type SetDocumentTemplateParams struct {
+	Format  string `json:"format"`
+	Name    string `json:"name"`
+	Content []byte `json:"content"`
 }
 
-const insertComment = `-- name: InsertComment :one
+// ----------------------------------------------------------------
+func (q *WriteQueries) SetDocumentTemplate(ctx context.Context, arg SetDocumentTemplateParams) (DocumentTemplate, error) {
+	row := q.db.QueryRowContext(ctx, setDocumentTemplate, arg.Format, arg.Name, arg.Content)
+	var i DocumentTemplate
+	err := row.Scan(
+		&i.Format,
+		&i.Name,
+		&i.Content,
+		&i.Created,
+		&i.Updated,
+	)
+	return i, err
+}
 
-INSERT INTO comments (id, author, message, ticket, created, updated)
-VALUES (?1, ?2, ?3, ?4, ?5, ?6)
-RETURNING id, ticket, author, message, created, updated
+const setEnrichment = `-- name: SetEnrichment :one
+
+INSERT INTO enrichment_cache (enricher, value, result, expires)
+VALUES (?1, ?2, ?3, ?4)
+ON CONFLICT (enricher, value) DO UPDATE SET result  = excluded.result,
+                                            expires = excluded.expires,
+                                            created = CURRENT_TIMESTAMP
+RETURNING enricher, value, result, expires, created
 `
 
-type InsertCommentParams struct {
-	ID      string    `json:"id"`
-	Author  string    `json:"author"`
-	Message string    `json:"message"`
-	Ticket  string    `json:"ticket"`
-	Created time.Time `json:"created"`
-	Updated time.Time `json:"updated"`
+type SetEnrichmentParams struct {
+	Enricher string    `json:"enricher"`
+	Value    string    `json:"value"`
+	Result   []byte    `json:"result"`
+	Expires  time.Time `json:"expires"`
 }
 
 // ----------------------------------------------------------------
-func (q *WriteQueries) InsertComment(ctx context.Context, arg InsertCommentParams) (Comment, error) {
-	row := q.db.QueryRowContext(ctx, insertComment,
-		arg.ID,
-		arg.Author,
-		arg.Message,
-		arg.Ticket,
-		arg.Created,
-		arg.Updated,
+func (q *WriteQueries) SetEnrichment(ctx context.Context, arg SetEnrichmentParams) (EnrichmentCache, error) {
+	row := q.db.QueryRowContext(ctx, setEnrichment,
+		arg.Enricher,
+		arg.Value,
+		arg.Result,
+		arg.Expires,
 	)
-	var i Comment
+	var i EnrichmentCache
+	err := row.Scan(
+		&i.Enricher,
+		&i.Value,
+		&i.Result,
+		&i.Expires,
+		&i.Created,
+	)
+	return i, err
+}
+
+const setFederationLinkRemoteOpen = `-- name: SetFederationLinkRemoteOpen :exec
+UPDATE federation_links
+SET remote_open = ?1,
+    updated     = CURRENT_TIMESTAMP
+WHERE peer = ?2
+  AND remote_ticket = ?3
+`
+
+type SetFederationLinkRemoteOpenParams struct {
+	RemoteOpen   bool   `json:"remote_open"`
+	Peer         string `json:"peer"`
+	RemoteTicket string `json:"remote_ticket"`
+}
+
+func (q *WriteQueries) SetFederationLinkRemoteOpen(ctx context.Context, arg SetFederationLinkRemoteOpenParams) error {
+	_, err := q.db.ExecContext(ctx, setFederationLinkRemoteOpen, arg.RemoteOpen, arg.Peer, arg.RemoteTicket)
+	return err
+}
+
+const setFederationLinkShare = `-- name: SetFederationLinkShare :one
+UPDATE federation_links
+SET share   = ?1,
+    updated = CURRENT_TIMESTAMP
+WHERE id = ?2
+RETURNING id, ticket, peer, remote_ticket, remote_key, remote_type, remote_name, remote_open, share, created, updated
+`
+
+type SetFederationLinkShareParams struct {
+	Share bool   `json:"share"`
+	ID    string `json:"id"`
+}
+
+func (q *WriteQueries) SetFederationLinkShare(ctx context.Context, arg SetFederationLinkShareParams) (FederationLink, error) {
+	row := q.db.QueryRowContext(ctx, setFederationLinkShare, arg.Share, arg.ID)
+	var i FederationLink
 	err := row.Scan(
 		&i.ID,
 		&i.Ticket,
-		&i.Author,
-		&i.Message,
+		&i.Peer,
+		&i.RemoteTicket,
+		&i.RemoteKey,
+		&i.RemoteType,
+		&i.RemoteName,
+		&i.RemoteOpen,
+		&i.Share,
 		&i.Created,
 		&i.Updated,
 	)
 	return i, err
 }
 
-const insertFile = `-- name: InsertFile :one
+const setFeed = `-- name: SetFeed :one
 
-INSERT INTO files (id, name, blob, size, ticket, created, updated)
-VALUES (?1, ?2, ?3, ?4, ?5, ?6, ?7)
-RETURNING id, ticket, name, blob, size, created, updated
+INSERT INTO feeds (name, source, indicators)
+VALUES (?1, ?2, ?3)
+ON CONFLICT (name) DO UPDATE SET source     = excluded.source,
+                                 indicators = excluded.indicators,
+                                 imported   = CURRENT_TIMESTAMP
+RETURNING name, source, indicators, imported
 `
 
-type InsertFileParams struct {
-	ID      string    `json:"id"`
-	Name    string    `json:"name"`
-	Blob    string    `json:"blob"`
-	Size    float64   `json:"size"`
-	Ticket  string    `json:"ticket"`
-	Created time.Time `json:"created"`
-	Updated time.Time `json:"updated"`
+type SetFeedParams struct {
+	Name       string `json:"name"`
+	Source     string `json:"source"`
+	Indicators int64  `json:"indicators"`
 }
 
 // ----------------------------------------------------------------
-func (q *WriteQueries) InsertFile(ctx context.Context, arg InsertFileParams) (File, error) {
-	row := q.db.QueryRowContext(ctx, insertFile,
+func (q *WriteQueries) SetFeed(ctx context.Context, arg SetFeedParams) (Feed, error) {
+	row := q.db.QueryRowContext(ctx, setFeed, arg.Name, arg.Source, arg.Indicators)
+	var i Feed
+	err := row.Scan(
+		&i.Name,
+		&i.Source,
+		&i.Indicators,
+		&i.Imported,
+	)
+	return i, err
+}
+
+const setFileHashes = `-- name: SetFileHashes :exec
+UPDATE files
+SET md5    = ?1,
+    sha1   = ?2,
+    sha256 = ?3
+WHERE id = ?4
+`
+
+type SetFileHashesParams struct {
+	Md5    string `json:"md5"`
+	Sha1   string `json:"sha1"`
+	Sha256 string `json:"sha256"`
+	ID     string `json:"id"`
+}
+
+func (q *WriteQueries) SetFileHashes(ctx context.Context, arg SetFileHashesParams) error {
+	_, err := q.db.ExecContext(ctx, setFileHashes,
+		arg.Md5,
+		arg.Sha1,
+		arg.Sha256,
 		arg.ID,
+	)
+	return err
+}
+
+const setKnownExploited = `-- name: SetKnownExploited :exec
+INSERT INTO known_exploited (cve, name, added, due, ransomware, updated)
+VALUES (?1, ?2, ?3, ?4, ?5, ?6)
+ON CONFLICT (cve) DO UPDATE SET name       = excluded.name,
+                                added      = excluded.added,
+                                due        = excluded.due,
+                                ransomware = excluded.ransomware,
+                                updated    = excluded.updated
+`
+
+type SetKnownExploitedParams struct {
+	Cve        string    `json:"cve"`
+	Name       string    `json:"name"`
+	Added      string    `json:"added"`
+	Due        string    `json:"due"`
+	Ransomware bool      `json:"ransomware"`
+	Updated    time.Time `json:"updated"`
+}
+
+func (q *WriteQueries) SetKnownExploited(ctx context.Context, arg SetKnownExploitedParams) error {
+	_, err := q.db.ExecContext(ctx, setKnownExploited,
+		arg.Cve,
 		arg.Name,
-		arg.Blob,
-		arg.Size,
-		arg.Ticket,
-		arg.Created,
+		arg.Added,
+		arg.Due,
+		arg.Ransomware,
 		arg.Updated,
 	)
-	var i File
+	return err
+}
+
+const setTaskOutput = `-- name: SetTaskOutput :one
+
+INSERT INTO task_outputs (task, ticket, name, output)
+SELECT tasks.id, tasks.ticket, ?1, ?2
+FROM tasks
+WHERE tasks.id = ?3
+ON CONFLICT (task) DO UPDATE SET name    = excluded.name,
+                                 output  = excluded.output,
+                                 updated = CURRENT_TIMESTAMP
+RETURNING task, ticket, name, output, created, updated
+`
+
+type SetTaskOutputParams struct {
+	Name   string `json:"name"`
+	Output []byte `json:"output"`
+	Task   string `json:"task"`
+}
+
+// ----------------------------------------------------------------
+func (q *WriteQueries) SetTaskOutput(ctx context.Context, arg SetTaskOutputParams) (TaskOutput, error) {
+	row := q.db.QueryRowContext(ctx, setTaskOutput, arg.Name, arg.Output, arg.Task)
+	var i TaskOutput
 	err := row.Scan(
-		&i.ID,
+		&i.Task,
 		&i.Ticket,
 		&i.Name,
-		&i.Blob,
-		&i.Size,
+		&i.Output,
 		&i.Created,
 		&i.Updated,
 	)
 	return i, err
 }
 
-const insertGroup = `-- name: InsertGroup :one
-
-INSERT INTO groups (id, name, permissions, created, updated)
+const setTaskTimer = `-- name: SetTaskTimer :one
+INSERT INTO task_timers (task, due, action, escalation_name, escalation_owner)
 VALUES (?1, ?2, ?3, ?4, ?5)
-RETURNING id, name, permissions, created, updated
+ON CONFLICT (task) DO UPDATE SET due              = excluded.due,
+                                 action           = excluded.action,
+                                 escalation_name  = excluded.escalation_name,
+                                 escalation_owner = excluded.escalation_owner,
+                                 result           = NULL,
+                                 escalation_task  = NULL,
+                                 fired            = NULL,
+                                 updated          = CURRENT_TIMESTAMP
+RETURNING task, due, "action", escalation_name, escalation_owner, result, escalation_task, fired, created, updated
 `
 
-type InsertGroupParams struct {
-	ID          string    `json:"id"`
-	Name        string    `json:"name"`
-	Permissions string    `json:"permissions"`
-	Created     time.Time `json:"created"`
-	Updated     time.Time `json:"updated"`
+type SetTaskTimerParams struct {
+	Task            string    `json:"task"`
+	Due             time.Time `json:"due"`
+	Action          string    `json:"action"`
+	EscalationName  *string   `json:"escalation_name"`
+	EscalationOwner *string   `json:"escalation_owner"`
 }
 
-// ----------------------------------------------------------------
-func (q *WriteQueries) InsertGroup(ctx context.Context, arg InsertGroupParams) (Group, error) {
-	row := q.db.QueryRowContext(ctx, insertGroup,
-		arg.ID,
-		arg.Name,
-		arg.Permissions,
-		arg.Created,
-		arg.Updated,
+func (q *WriteQueries) SetTaskTimer(ctx context.Context, arg SetTaskTimerParams) (TaskTimer, error) {
+	row := q.db.QueryRowContext(ctx, setTaskTimer,
+		arg.Task,
+		arg.Due,
+		arg.Action,
+		arg.EscalationName,
+		arg.EscalationOwner,
 	)
-	var i Group
+	var i TaskTimer
 	err := row.Scan(
-		&i.ID,
-		&i.Name,
-		&i.Permissions,
+		&i.Task,
+		&i.Due,
+		&i.Action,
+		&i.EscalationName,
+		&i.EscalationOwner,
+		&i.Result,
+		&i.EscalationTask,
+		&i.Fired,
 		&i.Created,
 		&i.Updated,
 	)
 	return i, err
 }
 
-const insertLink = `-- name: InsertLink :one
+const setTicketFeature = `-- name: SetTicketFeature :exec
+INSERT OR IGNORE INTO ticket_features (ticket, kind, value)
+VALUES (?1, ?2, ?3)
+`
 
-INSERT INTO links (id, name, url, ticket, created, updated)
-VALUES (?1, ?2, ?3, ?4, ?5, ?6)
-RETURNING id, ticket, name, url, created, updated
+type SetTicketFeatureParams struct {
+	Ticket string `json:"ticket"`
+	Kind   string `json:"kind"`
+	Value  string `json:"value"`
+}
+
+func (q *WriteQueries) SetTicketFeature(ctx context.Context, arg SetTicketFeatureParams) error {
+	_, err := q.db.ExecContext(ctx, setTicketFeature, arg.Ticket, arg.Kind, arg.Value)
+	return err
+}
+
+const startWorkTimer = `-- name: StartWorkTimer :one
+
+INSERT INTO work_entries (ticket, user, description, started)
+VALUES (?1, ?2, ?3, ?4)
+ON CONFLICT DO NOTHING
+RETURNING id, ticket, user, description, started, duration, manual, created, updated
 `
 
-type InsertLinkParams struct {
-	ID      string    `json:"id"`
-	Name    string    `json:"name"`
-	Url     string    `json:"url"`
-	Ticket  string    `json:"ticket"`
-	Created time.Time `json:"created"`
-	Updated time.Time `json:"updated"`
+type StartWorkTimerParams struct {
+	Ticket      string    `json:"ticket"`
+	User        string    `json:"user"`
+	Description string    `json:"description"`
+	Started     time.Time `json:"started"`
 }
 
 // ----------------------------------------------------------------
-func (q *WriteQueries) InsertLink(ctx context.Context, arg InsertLinkParams) (Link, error) {
-	row := q.db.QueryRowContext(ctx, insertLink,
-		arg.ID,
-		arg.Name,
-		arg.Url,
+func (q *WriteQueries) StartWorkTimer(ctx context.Context, arg StartWorkTimerParams) (WorkEntry, error) {
+	row := q.db.QueryRowContext(ctx, startWorkTimer,
 		arg.Ticket,
-		arg.Created,
-		arg.Updated,
+		arg.User,
+		arg.Description,
+		arg.Started,
 	)
-	var i Link
+	var i WorkEntry
 	err := row.Scan(
 		&i.ID,
 		&i.Ticket,
-		&i.Name,
-		&i.Url,
+		&i.User,
+		&i.Description,
+		&i.Started,
+		&i.Duration,
+		&i.Manual,
 		&i.Created,
 		&i.Updated,
 	)
 	return i, err
 }
 
-const insertReaction = `-- name: InsertReaction :one
-
-INSERT INTO reactions (id, name, action, actiondata, trigger, triggerdata, created, updated)
-VALUES (?1, ?2, ?3, ?4, ?5, ?6, ?7, ?8)
-RETURNING id, name, "action", actiondata, "trigger", triggerdata, created, updated
+const stopWorkTimer = `-- name: StopWorkTimer :one
+UPDATE work_entries
+SET duration = max(unixepoch(CAST(?1 AS TEXT)) - unixepoch(started), 0),
+    updated  = CURRENT_TIMESTAMP
+WHERE ticket = ?2
+  AND user = ?3
+  AND duration IS NULL
+RETURNING id, ticket, user, description, started, duration, manual, created, updated
 `
 
-type InsertReactionParams struct {
-	ID          string    `json:"id"`
-	Name        string    `json:"name"`
-	Action      string    `json:"action"`
-	Actiondata  []byte    `json:"actiondata"`
-	Trigger     string    `json:"trigger"`
-	Triggerdata []byte    `json:"triggerdata"`
-	Created     time.Time `json:"created"`
-	Updated     time.Time `json:"updated"`
+type StopWorkTimerParams struct {
+	Stopped string `json:"stopped"`
+	Ticket  string `json:"ticket"`
+	User    string `json:"user"`
 }
 
-// ----------------------------------------------------------------
-func (q *WriteQueries) InsertReaction(ctx context.Context, arg InsertReactionParams) (Reaction, error) {
-	row := q.db.QueryRowContext(ctx, insertReaction,
-		arg.ID,
-		arg.Name,
-		arg.Action,
-		arg.Actiondata,
-		arg.Trigger,
-		arg.Triggerdata,
-		arg.Created,
-		arg.Updated,
-	)
-	var i Reaction
+func (q *WriteQueries) StopWorkTimer(ctx context.Context, arg StopWorkTimerParams) (WorkEntry, error) {
+	row := q.db.QueryRowContext(ctx, stopWorkTimer, arg.Stopped, arg.Ticket, arg.User)
+	var i WorkEntry
 	err := row.Scan(
 		&i.ID,
-		&i.Name,
-		&i.Action,
-		&i.Actiondata,
-		&i.Trigger,
-		&i.Triggerdata,
+		&i.Ticket,
+		&i.User,
+		&i.Description,
+		&i.Started,
+		&i.Duration,
+		&i.Manual,
 		&i.Created,
 		&i.Updated,
 	)
 	return i, err
 }
 
-const insertTask = `-- name: InsertTask :one
+const touchCampaign = `-- name: TouchCampaign :exec
+UPDATE campaigns
+SET updated = CURRENT_TIMESTAMP
+WHERE id = ?1
+`
 
-INSERT INTO tasks (id, name, open, owner, ticket, created, updated)
-VALUES (?1, ?2, ?3, ?4, ?5, ?6, ?7)
-RETURNING id, ticket, owner, name, open, created, updated
+func (q *WriteQueries) TouchCampaign(ctx context.Context, id string) error {
+	_, err := q.db.ExecContext(ctx, touchCampaign, id)
+	return err
+}
+
+const updateAlertMapping = `-- name: UpdateAlertMapping :one
+UPDATE alert_mappings
+SET name             = coalesce(?1, name),
+    source           = coalesce(?2, source),
+    template_version = coalesce(?3, template_version),
+    fields           = coalesce(?4, fields),
+    updated          = CURRENT_TIMESTAMP
+WHERE id = ?5
+RETURNING id, name, source, template, template_version, fields, created, updated
 `
 
-type InsertTaskParams struct {
-	ID      string    `json:"id"`
-	Name    string    `json:"name"`
-	Open    bool      `json:"open"`
-	Owner   *string   `json:"owner"`
-	Ticket  string    `json:"ticket"`
-	Created time.Time `json:"created"`
-	Updated time.Time `json:"updated"`
+type UpdateAlertMappingParams struct {
+	Name            *string `json:"name"`
+	Source          *string `json:"source"`
+	TemplateVersion *int64  `json:"template_version"`
+	Fields          []byte  `json:"fields"`
+	ID              string  `json:"id"`
 }
 
-// ----------------------------------------------------------------
-func (q *WriteQueries) InsertTask(ctx context.Context, arg InsertTaskParams) (Task, error) {
-	row := q.db.QueryRowContext(ctx, insertTask,
-		arg.ID,
+func (q *WriteQueries) UpdateAlertMapping(ctx context.Context, arg UpdateAlertMappingParams) (AlertMapping, error) {
+	row := q.db.QueryRowContext(ctx, updateAlertMapping,
 		arg.Name,
-		arg.Open,
-		arg.Owner,
-		arg.Ticket,
-		arg.Created,
-		arg.Updated,
+		arg.Source,
+		arg.TemplateVersion,
+		arg.Fields,
+		arg.ID,
 	)
-	var i Task
+	var i AlertMapping
 	err := row.Scan(
 		&i.ID,
-		&i.Ticket,
-		&i.Owner,
 		&i.Name,
-		&i.Open,
+		&i.Source,
+		&i.Template,
+		&i.TemplateVersion,
+		&i.Fields,
 		&i.Created,
 		&i.Updated,
 	)
 	return i, err
 }
 
-const insertTicket = `-- name: InsertTicket :one
-
-INSERT INTO tickets (id, name, description, open, owner, resolution, schema, state, type, created, updated)
-VALUES (?1, ?2, ?3, ?4, ?5, ?6, ?7, ?8, ?9, ?10, ?11)
-RETURNING id, type, owner, name, description, open, resolution, schema, state, created, updated
+const updateAlertStatus = `-- name: UpdateAlertStatus :one
+UPDATE alerts
+SET status  = ?1,
+    updated = CURRENT_TIMESTAMP
+WHERE id = ?2
+  AND status != 'promoted'
+RETURNING id, source, name, description, severity, data, status, ticket, created, updated, maintenance
 `
 
-type InsertTicketParams struct {
-	ID          string    `json:"id"`
-	Name        string    `json:"name"`
-	Description string    `json:"description"`
-	Open        bool      `json:"open"`
-	Owner       *string   `json:"owner"`
-	Resolution  *string   `json:"resolution"`
-	Schema      []byte    `json:"schema"`
-	State       []byte    `json:"state"`
-	Type        string    `json:"type"`
-	Created     time.Time `json:"created"`
-	Updated     time.Time `json:"updated"`
+type UpdateAlertStatusParams struct {
+	Status string `json:"status"`
+	ID     string `json:"id"`
 }
 
-// ----------------------------------------------------------------
-func (q *WriteQueries) InsertTicket(ctx context.Context, arg InsertTicketParams) (Ticket, error) {
-	row := q.db.QueryRowContext(ctx, insertTicket,
-		arg.ID,
-		arg.Name,
-		arg.Description,
-		arg.Open,
-		arg.Owner,
-		arg.Resolution,
-		arg.Schema,
-		arg.State,
-		arg.Type,
-		arg.Created,
-		arg.Updated,
-	)
-	var i Ticket
+func (q *WriteQueries) UpdateAlertStatus(ctx context.Context, arg UpdateAlertStatusParams) (Alert, error) {
+	row := q.db.QueryRowContext(ctx, updateAlertStatus, arg.Status, arg.ID)
+	var i Alert
 	err := row.Scan(
 		&i.ID,
-		&i.Type,
-		&i.Owner,
+		&i.Source,
 		&i.Name,
 		&i.Description,
-		&i.Open,
-		&i.Resolution,
-		&i.Schema,
-		&i.State,
+		&i.Severity,
+		&i.Data,
+		&i.Status,
+		&i.Ticket,
 		&i.Created,
 		&i.Updated,
+		&i.Maintenance,
 	)
 	return i, err
 }
 
-const insertTimeline = `-- name: InsertTimeline :one
-
-INSERT INTO timeline (id, message, ticket, time, created, updated)
-VALUES (?1, ?2, ?3, ?4, ?5, ?6)
-RETURNING id, ticket, message, time, created, updated
+const updateAutoCloseRule = `-- name: UpdateAutoCloseRule :one
+UPDATE auto_close_rules
+SET name          = coalesce(?1, name),
+    enabled       = coalesce(?2, enabled),
+    filter        = coalesce(?3, filter),
+    inactive_days = coalesce(?4, inactive_days),
+    warning_days  = coalesce(?5, warning_days),
+    resolution    = coalesce(?6, resolution),
+    updated       = CURRENT_TIMESTAMP
+WHERE id = ?7
+RETURNING id, name, enabled, "filter", inactive_days, warning_days, resolution, created, updated
 `
 
-type InsertTimelineParams struct {
-	ID      string    `json:"id"`
-	Message string    `json:"message"`
-	Ticket  string    `json:"ticket"`
-	Time    time.Time `json:"time"`
-	Created time.Time `json:"created"`
-	Updated time.Time `json:"updated"`
+type UpdateAutoCloseRuleParams struct {
+	Name         *string `json:"name"`
+	Enabled      *bool   `json:"enabled"`
+	Filter       []byte  `json:"filter"`
+	InactiveDays *int64  `json:"inactive_days"`
+	WarningDays  *int64  `json:"warning_days"`
+	Resolution   *string `json:"resolution"`
+	ID           string  `json:"id"`
 }
 
-// ----------------------------------------------------------------
-func (q *WriteQueries) InsertTimeline(ctx context.Context, arg InsertTimelineParams) (Timeline, error) {
-	row := q.db.QueryRowContext(ctx, insertTimeline,
+func (q *WriteQueries) UpdateAutoCloseRule(ctx context.Context, arg UpdateAutoCloseRuleParams) (AutoCloseRule, error) {
+	row := q.db.QueryRowContext(ctx, updateAutoCloseRule,
+		arg.Name,
+		arg.Enabled,
+		arg.Filter,
+		arg.InactiveDays,
+		arg.WarningDays,
+		arg.Resolution,
 		arg.ID,
-		arg.Message,
-		arg.Ticket,
-		arg.Time,
-		arg.Created,
-		arg.Updated,
 	)
-	var i Timeline
+	var i AutoCloseRule
 	err := row.Scan(
 		&i.ID,
-		&i.Ticket,
-		&i.Message,
-		&i.Time,
+		&i.Name,
+		&i.Enabled,
+		&i.Filter,
+		&i.InactiveDays,
+		&i.WarningDays,
+		&i.Resolution,
 		&i.Created,
 		&i.Updated,
 	)
 	return i, err
 }
 
-const insertType = `-- name: InsertType :one
-
-INSERT INTO types (id, singular, plural, icon, schema, created, updated)
-VALUES (?1, ?2, ?3, ?4, ?5, ?6, ?7)
-RETURNING id, icon, singular, plural, schema, created, updated
+const updateCVE = `-- name: UpdateCVE :exec
+UPDATE cves
+SET description   = ?1,
+    cvss_score    = ?2,
+    cvss_severity = ?3,
+    cvss_vector   = ?4,
+    products      = ?5,
+    published     = ?6,
+    enriched      = ?7
+WHERE id = ?8
 `
 
-type InsertTypeParams struct {
-	ID       string    `json:"id"`
-	Singular string    `json:"singular"`
-	Plural   string    `json:"plural"`
-	Icon     *string   `json:"icon"`
-	Schema   []byte    `json:"schema"`
-	Created  time.Time `json:"created"`
-	Updated  time.Time `json:"updated"`
+type UpdateCVEParams struct {
+	Description  string     `json:"description"`
+	CvssScore    *float64   `json:"cvss_score"`
+	CvssSeverity *string    `json:"cvss_severity"`
+	CvssVector   *string    `json:"cvss_vector"`
+	Products     []byte     `json:"products"`
+	Published    *time.Time `json:"published"`
+	Enriched     *time.Time `json:"enriched"`
+	ID           string     `json:"id"`
 }
 
-// ----------------------------------------------------------------
-func (q *WriteQueries) InsertType(ctx context.Context, arg InsertTypeParams) (Type, error) {
-	row := q.db.QueryRowContext(ctx, insertType,
+func (q *WriteQueries) UpdateCVE(ctx context.Context, arg UpdateCVEParams) error {
+	_, err := q.db.ExecContext(ctx, updateCVE,
+		arg.Description,
+		arg.CvssScore,
+		arg.CvssSeverity,
+		arg.CvssVector,
+		arg.Products,
+		arg.Published,
+		arg.Enriched,
 		arg.ID,
-		arg.Singular,
-		arg.Plural,
-		arg.Icon,
-		arg.Schema,
-		arg.Created,
-		arg.Updated,
 	)
-	var i Type
+	return err
+}
+
+const updateComment = `-- name: UpdateComment :one
+UPDATE comments
+SET message = coalesce(?1, message),
+    updated = CURRENT_TIMESTAMP
+WHERE id = ?2
+RETURNING id, ticket, author, message, created, updated
+`
+
+type UpdateCommentParams struct {
+	Message *string `json:"message"`
+	ID      string  `json:"id"`
+}
+
+func (q *WriteQueries) UpdateComment(ctx context.Context, arg UpdateCommentParams) (Comment, error) {
+	row := q.db.QueryRowContext(ctx, updateComment, arg.Message, arg.ID)
+	var i Comment
 	err := row.Scan(
 		&i.ID,
-		&i.Icon,
-		&i.Singular,
-		&i.Plural,
-		&i.Schema,
+		&i.Ticket,
+		&i.Author,
+		&i.Message,
 		&i.Created,
 		&i.Updated,
 	)
 	return i, err
 }
 
-const insertUser = `-- name: InsertUser :one
-
-INSERT INTO users (id, name, email, username, passwordHash, tokenKey, avatar, active, created, updated)
-VALUES (?1, ?2, ?3, ?4, ?5, ?6, ?7, ?8, ?9, ?10)
-RETURNING id, username, passwordhash, tokenkey, active, name, email, avatar, lastresetsentat, lastverificationsentat, created, updated
+const updateDeadLetterAttempt = `-- name: UpdateDeadLetterAttempt :one
+UPDATE dead_letters
+SET error    = ?1,
+    attempts = attempts + 1,
+    updated  = CURRENT_TIMESTAMP
+WHERE id = ?2
+RETURNING id, webhook, destination, payload, error, attempts, created, updated
 `
 
-type InsertUserParams struct {
-	ID           string    `json:"id"`
-	Name         *string   `json:"name"`
-	Email        *string   `json:"email"`
-	Username     string    `json:"username"`
-	PasswordHash string    `json:"passwordHash"`
-	TokenKey     string    `json:"tokenKey"`
-	Avatar       *string   `json:"avatar"`
-	Active       bool      `json:"active"`
-	Created      time.Time `json:"created"`
-	Updated      time.Time `json:"updated"`
+type UpdateDeadLetterAttemptParams struct {
+	Error string `json:"error"`
+	ID    string `json:"id"`
 }
 
-// ----------------------------------------------------------------
-func (q *WriteQueries) InsertUser(ctx context.Context, arg InsertUserParams) (User, error) {
-	row := q.db.QueryRowContext(ctx, insertUser,
-		arg.ID,
-		arg.Name,
-		arg.Email,
-		arg.Username,
-		arg.PasswordHash,
-		arg.TokenKey,
-		arg.Avatar,
-		arg.Active,
-		arg.Created,
-		arg.Updated,
-	)
-	var i User
+func (q *WriteQueries) UpdateDeadLetterAttempt(ctx context.Context, arg UpdateDeadLetterAttemptParams) (DeadLetter, error) {
+	row := q.db.QueryRowContext(ctx, updateDeadLetterAttempt, arg.Error, arg.ID)
+	var i DeadLetter
 	err := row.Scan(
 		&i.ID,
-		&i.Username,
-		&i.Passwordhash,
-		&i.Tokenkey,
-		&i.Active,
-		&i.Name,
-		&i.Email,
-		&i.Avatar,
-		&i.Lastresetsentat,
-		&i.Lastverificationsentat,
+		&i.Webhook,
+		&i.Destination,
+		&i.Payload,
+		&i.Error,
+		&i.Attempts,
 		&i.Created,
 		&i.Updated,
 	)
 	return i, err
 }
 
-const insertWebhook = `-- name: InsertWebhook :one
-
-INSERT INTO webhooks (id, name, collection, destination, created, updated)
-VALUES (?1, ?2, ?3, ?4, ?5, ?6)
-RETURNING id, collection, destination, name, created, updated
+const updateDetectionRule = `-- name: UpdateDetectionRule :one
+UPDATE detection_rules
+SET name        = coalesce(?1, name),
+    description = coalesce(?2, description),
+    format      = coalesce(?3, format),
+    content     = coalesce(?4, content),
+    updated     = CURRENT_TIMESTAMP
+WHERE id = ?5
+RETURNING id, ticket, name, description, format, content, status, author, reviewer, deployed, created, updated
 `
 
-type InsertWebhookParams struct {
-	ID          string    `json:"id"`
-	Name        string    `json:"name"`
-	Collection  string    `json:"collection"`
-	Destination string    `json:"destination"`
-	Created     time.Time `json:"created"`
-	Updated     time.Time `json:"updated"`
+type UpdateDetectionRuleParams struct {
+	Name        *string `json:"name"`
+	Description *string `json:"description"`
+	Format      *string `json:"format"`
+	Content     *string `json:"content"`
+	ID          string  `json:"id"`
 }
 
-// ----------------------------------------------------------------
-func (q *WriteQueries) InsertWebhook(ctx context.Context, arg InsertWebhookParams) (Webhook, error) {
-	row := q.db.QueryRowContext(ctx, insertWebhook,
-		arg.ID,
+func (q *WriteQueries) UpdateDetectionRule(ctx context.Context, arg UpdateDetectionRuleParams) (DetectionRule, error) {
+	row := q.db.QueryRowContext(ctx, updateDetectionRule,
 		arg.Name,
-		arg.Collection,
-		arg.Destination,
-		arg.Created,
-		arg.Updated,
+		arg.Description,
+		arg.Format,
+		arg.Content,
+		arg.ID,
 	)
-	var i Webhook
+	var i DetectionRule
 	err := row.Scan(
 		&i.ID,
-		&i.Collection,
-		&i.Destination,
+		&i.Ticket,
 		&i.Name,
+		&i.Description,
+		&i.Format,
+		&i.Content,
+		&i.Status,
+		&i.Author,
+		&i.Reviewer,
+		&i.Deployed,
 		&i.Created,
 		&i.Updated,
 	)
 	return i, err
 }
 
-const removeGroupFromUser = `-- name: RemoveGroupFromUser :exec
-DELETE
-FROM user_groups
-WHERE user_id = ?1
-  AND group_id = ?2
+const updateDigestSent = `-- name: UpdateDigestSent :exec
+UPDATE digests
+SET last_sent = ?1
+WHERE user = ?2
 `
 
-type RemoveGroupFromUserParams struct {
-	UserID  string `json:"user_id"`
-	GroupID string `json:"group_id"`
+type UpdateDigestSentParams struct {
+	LastSent *time.Time `json:"last_sent"`
+	User     string     `json:"user"`
 }
 
-func (q *WriteQueries) RemoveGroupFromUser(ctx context.Context, arg RemoveGroupFromUserParams) error {
-	_, err := q.db.ExecContext(ctx, removeGroupFromUser, arg.UserID, arg.GroupID)
+func (q *WriteQueries) UpdateDigestSent(ctx context.Context, arg UpdateDigestSentParams) error {
+	_, err := q.db.ExecContext(ctx, updateDigestSent, arg.LastSent, arg.User)
 	return err
 }
 
-const removeParentGroup = `-- name: RemoveParentGroup :exec
-DELETE
-FROM group_inheritance
-WHERE parent_group_id = ?1
-  AND child_group_id = ?2
+const updateEscalationPolicySteps = `-- name: UpdateEscalationPolicySteps :exec
+UPDATE escalation_policies
+SET steps   = ?1,
+    updated = CURRENT_TIMESTAMP
+WHERE id = ?2
 `
 
-type RemoveParentGroupParams struct {
-	ParentGroupID string `json:"parent_group_id"`
-	ChildGroupID  string `json:"child_group_id"`
+type UpdateEscalationPolicyStepsParams struct {
+	Steps []byte `json:"steps"`
+	ID    string `json:"id"`
 }
 
-func (q *WriteQueries) RemoveParentGroup(ctx context.Context, arg RemoveParentGroupParams) error {
-	_, err := q.db.ExecContext(ctx, removeParentGroup, arg.ParentGroupID, arg.ChildGroupID)
+func (q *WriteQueries) UpdateEscalationPolicySteps(ctx context.Context, arg UpdateEscalationPolicyStepsParams) error {
+	_, err := q.db.ExecContext(ctx, updateEscalationPolicySteps, arg.Steps, arg.ID)
 	return err
 }
 
-const updateComment = `-- name: UpdateComment :one
-UPDATE comments
-SET message = coalesce(?1, message)
-WHERE id = ?2
-RETURNING id, ticket, author, message, created, updated
+const updateFederationPeer = `-- name: UpdateFederationPeer :one
+UPDATE federation_peers
+SET name    = coalesce(?1, name),
+    url     = coalesce(?2, url),
+    secret  = coalesce(?3, secret),
+    max_tlp = coalesce(?4, max_tlp),
+    updated = CURRENT_TIMESTAMP
+WHERE id = ?5
+RETURNING id, name, url, secret, max_tlp, created, updated
 `
 
-type UpdateCommentParams struct {
-	Message *string `json:"message"`
-	ID      string  `json:"id"`
+type UpdateFederationPeerParams struct {
+	Name   *string `json:"name"`
+	Url    *string `json:"url"`
+	Secret *string `json:"secret"`
+	MaxTlp *string `json:"max_tlp"`
+	ID     string  `json:"id"`
 }
 
-func (q *WriteQueries) UpdateComment(ctx context.Context, arg UpdateCommentParams) (Comment, error) {
-	row := q.db.QueryRowContext(ctx, updateComment, arg.Message, arg.ID)
-	var i Comment
+func (q *WriteQueries) UpdateFederationPeer(ctx context.Context, arg UpdateFederationPeerParams) (FederationPeer, error) {
+	row := q.db.QueryRowContext(ctx, updateFederationPeer,
+		arg.Name,
+		arg.Url,
+		arg.Secret,
+		arg.MaxTlp,
+		arg.ID,
+	)
+	var i FederationPeer
 	err := row.Scan(
 		&i.ID,
-		&i.Ticket,
-		&i.Author,
-		&i.Message,
+		&i.Name,
+		&i.Url,
+		&i.Secret,
+		&i.MaxTlp,
 		&i.Created,
 		&i.Updated,
 	)
@@ -1078,9 +4262,10 @@ const updateFile = `-- name: UpdateFile :one
 UPDATE files
 SET name = coalesce(?1, name),
     blob = coalesce(?2, blob),
-    size = coalesce(?3, size)
+    size = coalesce(?3, size),
+    updated = CURRENT_TIMESTAMP
 WHERE id = ?4
-RETURNING id, ticket, name, blob, size, created, updated
+RETURNING id, ticket, name, blob, size, created, updated, md5, sha1, sha256
 `
 
 type UpdateFileParams struct {
@@ -1106,6 +4291,9 @@ func (q *WriteQueries) UpdateFile(ctx context.Context, arg UpdateFileParams) (Fi
 		&i.Size,
 		&i.Created,
 		&i.Updated,
+		&i.Md5,
+		&i.Sha1,
+		&i.Sha256,
 	)
 	return i, err
 }
@@ -1113,7 +4301,8 @@ func (q *WriteQueries) UpdateFile(ctx context.Context, arg UpdateFileParams) (Fi
 const updateGroup = `-- name: UpdateGroup :one
 UPDATE groups
 SET name        = coalesce(?1, name),
-    permissions = coalesce(?2, permissions)
+    permissions = coalesce(?2, permissions),
+    updated     = CURRENT_TIMESTAMP
 WHERE id = ?3
 RETURNING id, name, permissions, created, updated
 `
@@ -1137,10 +4326,43 @@ func (q *WriteQueries) UpdateGroup(ctx context.Context, arg UpdateGroupParams) (
 	return i, err
 }
 
+const updateJournalReplay = `-- name: UpdateJournalReplay :one
+UPDATE request_journal
+SET success  = ?1,
+    error    = ?2,
+    replayed = CURRENT_TIMESTAMP
+WHERE id = ?3
+RETURNING id, reaction, request, size, truncated, success, error, replayed, created
+`
+
+type UpdateJournalReplayParams struct {
+	Success bool   `json:"success"`
+	Error   string `json:"error"`
+	ID      string `json:"id"`
+}
+
+func (q *WriteQueries) UpdateJournalReplay(ctx context.Context, arg UpdateJournalReplayParams) (RequestJournal, error) {
+	row := q.db.QueryRowContext(ctx, updateJournalReplay, arg.Success, arg.Error, arg.ID)
+	var i RequestJournal
+	err := row.Scan(
+		&i.ID,
+		&i.Reaction,
+		&i.Request,
+		&i.Size,
+		&i.Truncated,
+		&i.Success,
+		&i.Error,
+		&i.Replayed,
+		&i.Created,
+	)
+	return i, err
+}
+
 const updateLink = `-- name: UpdateLink :one
 UPDATE links
 SET name = coalesce(?1, name),
-    url  = coalesce(?2, url)
+    url  = coalesce(?2, url),
+    updated = CURRENT_TIMESTAMP
 WHERE id = ?3
 RETURNING id, ticket, name, url, created, updated
 `
@@ -1165,6 +4387,98 @@ func (q *WriteQueries) UpdateLink(ctx context.Context, arg UpdateLinkParams) (Li
 	return i, err
 }
 
+const updateMaintenanceWindow = `-- name: UpdateMaintenanceWindow :one
+UPDATE maintenance_windows
+SET name    = coalesce(?1, name),
+    filter  = coalesce(?2, filter),
+    starts  = coalesce(?3, starts),
+    ends    = coalesce(?4, ends),
+    updated = CURRENT_TIMESTAMP
+WHERE id = ?5
+RETURNING id, name, "filter", starts, ends, created, updated
+`
+
+type UpdateMaintenanceWindowParams struct {
+	Name   *string    `json:"name"`
+	Filter []byte     `json:"filter"`
+	Starts *time.Time `json:"starts"`
+	Ends   *time.Time `json:"ends"`
+	ID     string     `json:"id"`
+}
+
+func (q *WriteQueries) UpdateMaintenanceWindow(ctx context.Context, arg UpdateMaintenanceWindowParams) (MaintenanceWindow, error) {
+	row := q.db.QueryRowContext(ctx, updateMaintenanceWindow,
+		arg.Name,
+		arg.Filter,
+		arg.Starts,
+		arg.Ends,
+		arg.ID,
+	)
+	var i MaintenanceWindow
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Filter,
+		&i.Starts,
+		&i.Ends,
+		&i.Created,
+		&i.Updated,
+	)
+	return i, err
+}
+
+const updateNotificationRule = `-- name: UpdateNotificationRule :one
+UPDATE notification_rules
+SET name     = coalesce(?1, name),
+    enabled  = coalesce(?2, enabled),
+    filter   = coalesce(?3, filter),
+    channel  = coalesce(?4, channel),
+    target   = coalesce(?5, target),
+    throttle = coalesce(?6, throttle),
+    dedup    = coalesce(?7, dedup),
+    updated  = CURRENT_TIMESTAMP
+WHERE id = ?8
+RETURNING id, name, enabled, "filter", channel, target, throttle, dedup, created, updated
+`
+
+type UpdateNotificationRuleParams struct {
+	Name     *string `json:"name"`
+	Enabled  *bool   `json:"enabled"`
+	Filter   []byte  `json:"filter"`
+	Channel  *string `json:"channel"`
+	Target   *string `json:"target"`
+	Throttle *int64  `json:"throttle"`
+	Dedup    *int64  `json:"dedup"`
+	ID       string  `json:"id"`
+}
+
+func (q *WriteQueries) UpdateNotificationRule(ctx context.Context, arg UpdateNotificationRuleParams) (NotificationRule, error) {
+	row := q.db.QueryRowContext(ctx, updateNotificationRule,
+		arg.Name,
+		arg.Enabled,
+		arg.Filter,
+		arg.Channel,
+		arg.Target,
+		arg.Throttle,
+		arg.Dedup,
+		arg.ID,
+	)
+	var i NotificationRule
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Enabled,
+		&i.Filter,
+		&i.Channel,
+		&i.Target,
+		&i.Throttle,
+		&i.Dedup,
+		&i.Created,
+		&i.Updated,
+	)
+	return i, err
+}
+
 const updateParam = `-- name: UpdateParam :exec
 UPDATE _params
 SET value = ?1
@@ -1182,13 +4496,90 @@ func (q *WriteQueries) UpdateParam(ctx context.Context, arg UpdateParamParams) e
 	return err
 }
 
+const updatePlaybook = `-- name: UpdatePlaybook :one
+UPDATE playbooks
+SET name        = coalesce(?1, name),
+    description = coalesce(?2, description),
+    inputs      = coalesce(?3, inputs),
+    tasks       = coalesce(?4, tasks),
+    updated     = CURRENT_TIMESTAMP
+WHERE id = ?5
+RETURNING id, name, description, inputs, tasks, created, updated
+`
+
+type UpdatePlaybookParams struct {
+	Name        *string `json:"name"`
+	Description *string `json:"description"`
+	Inputs      []byte  `json:"inputs"`
+	Tasks       []byte  `json:"tasks"`
+	ID          string  `json:"id"`
+}
+
+func (q *WriteQueries) UpdatePlaybook(ctx context.Context, arg UpdatePlaybookParams) (Playbook, error) {
+	row := q.db.QueryRowContext(ctx, updatePlaybook,
+		arg.Name,
+		arg.Description,
+		arg.Inputs,
+		arg.Tasks,
+		arg.ID,
+	)
+	var i Playbook
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Description,
+		&i.Inputs,
+		&i.Tasks,
+		&i.Created,
+		&i.Updated,
+	)
+	return i, err
+}
+
+const updateQueue = `-- name: UpdateQueue :one
+UPDATE queues
+SET name        = coalesce(?1, name),
+    description = coalesce(?2, description),
+    filter      = coalesce(?3, filter),
+    updated     = CURRENT_TIMESTAMP
+WHERE id = ?4
+RETURNING id, name, description, "filter", created, updated
+`
+
+type UpdateQueueParams struct {
+	Name        *string `json:"name"`
+	Description *string `json:"description"`
+	Filter      []byte  `json:"filter"`
+	ID          string  `json:"id"`
+}
+
+func (q *WriteQueries) UpdateQueue(ctx context.Context, arg UpdateQueueParams) (Queue, error) {
+	row := q.db.QueryRowContext(ctx, updateQueue,
+		arg.Name,
+		arg.Description,
+		arg.Filter,
+		arg.ID,
+	)
+	var i Queue
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Description,
+		&i.Filter,
+		&i.Created,
+		&i.Updated,
+	)
+	return i, err
+}
+
 const updateReaction = `-- name: UpdateReaction :one
 UPDATE reactions
 SET name        = coalesce(?1, name),
     action      = coalesce(?2, action),
     actiondata  = coalesce(?3, actiondata),
     trigger     = coalesce(?4, trigger),
-    triggerdata = coalesce(?5, triggerdata)
+    triggerdata = coalesce(?5, triggerdata),
+    updated     = CURRENT_TIMESTAMP
 WHERE id = ?6
 RETURNING id, name, "action", actiondata, "trigger", triggerdata, created, updated
 `
@@ -1225,11 +4616,56 @@ func (q *WriteQueries) UpdateReaction(ctx context.Context, arg UpdateReactionPar
 	return i, err
 }
 
+const updateRedactionProfile = `-- name: UpdateRedactionProfile :one
+UPDATE redaction_profiles
+SET name            = coalesce(?1, name),
+    audience        = coalesce(?2, audience),
+    fields          = coalesce(?3, fields),
+    hide_files      = coalesce(?4, hide_files),
+    hide_identities = coalesce(?5, hide_identities),
+    updated         = CURRENT_TIMESTAMP
+WHERE id = ?6
+RETURNING id, name, audience, fields, hide_files, hide_identities, created, updated
+`
+
+type UpdateRedactionProfileParams struct {
+	Name           *string `json:"name"`
+	Audience       *string `json:"audience"`
+	Fields         []byte  `json:"fields"`
+	HideFiles      *bool   `json:"hide_files"`
+	HideIdentities *bool   `json:"hide_identities"`
+	ID             string  `json:"id"`
+}
+
+func (q *WriteQueries) UpdateRedactionProfile(ctx context.Context, arg UpdateRedactionProfileParams) (RedactionProfile, error) {
+	row := q.db.QueryRowContext(ctx, updateRedactionProfile,
+		arg.Name,
+		arg.Audience,
+		arg.Fields,
+		arg.HideFiles,
+		arg.HideIdentities,
+		arg.ID,
+	)
+	var i RedactionProfile
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Audience,
+		&i.Fields,
+		&i.HideFiles,
+		&i.HideIdentities,
+		&i.Created,
+		&i.Updated,
+	)
+	return i, err
+}
+
 const updateTask = `-- name: UpdateTask :one
 UPDATE tasks
 SET name  = coalesce(?1, name),
     open  = coalesce(?2, open),
-    owner = coalesce(?3, owner)
+    owner = coalesce(?3, owner),
+    updated = CURRENT_TIMESTAMP
 WHERE id = ?4
 RETURNING id, ticket, owner, name, open, created, updated
 `
@@ -1270,9 +4706,18 @@ SET name        = coalesce(?1, name),
     resolution  = coalesce(?5, resolution),
     schema      = coalesce(?6, schema),
     state       = coalesce(?7, state),
-    type        = coalesce(?8, type)
-WHERE id = ?9
-RETURNING id, type, owner, name, description, open, resolution, schema, state, created, updated
+    type        = coalesce(?8, type),
+    resolved    = CASE
+                      WHEN coalesce(?3, open) THEN NULL
+                      WHEN open THEN CURRENT_TIMESTAMP
+                      ELSE resolved END,
+    resolved_by = CASE
+                      WHEN coalesce(?3, open) THEN NULL
+                      WHEN open THEN ?9
+                      ELSE resolved_by END,
+    updated     = CURRENT_TIMESTAMP
+WHERE id = ?10
+RETURNING id, type, owner, name, description, open, resolution, schema, state, created, updated, acknowledged, acknowledged_by, resolved, encrypted, "key", resolved_by, reopen_count
 `
 
 type UpdateTicketParams struct {
@@ -1284,6 +4729,7 @@ type UpdateTicketParams struct {
 	Schema      []byte  `json:"schema"`
 	State       []byte  `json:"state"`
 	Type        *string `json:"type"`
+	ResolvedBy  *string `json:"resolved_by"`
 	ID          string  `json:"id"`
 }
 
@@ -1297,6 +4743,7 @@ func (q *WriteQueries) UpdateTicket(ctx context.Context, arg UpdateTicketParams)
 		arg.Schema,
 		arg.State,
 		arg.Type,
+		arg.ResolvedBy,
 		arg.ID,
 	)
 	var i Ticket
@@ -1312,6 +4759,13 @@ func (q *WriteQueries) UpdateTicket(ctx context.Context, arg UpdateTicketParams)
 		&i.State,
 		&i.Created,
 		&i.Updated,
+		&i.Acknowledged,
+		&i.AcknowledgedBy,
+		&i.Resolved,
+		&i.Encrypted,
+		&i.Key,
+		&i.ResolvedBy,
+		&i.ReopenCount,
 	)
 	return i, err
 }
@@ -1319,7 +4773,8 @@ func (q *WriteQueries) UpdateTicket(ctx context.Context, arg UpdateTicketParams)
 const updateTimeline = `-- name: UpdateTimeline :one
 UPDATE timeline
 SET message = coalesce(?1, message),
-    time    = coalesce(?2, time)
+    time    = coalesce(?2, time),
+    updated = CURRENT_TIMESTAMP
 WHERE id = ?3
 RETURNING id, ticket, message, time, created, updated
 `
@@ -1346,20 +4801,27 @@ func (q *WriteQueries) UpdateTimeline(ctx context.Context, arg UpdateTimelinePar
 
 const updateType = `-- name: UpdateType :one
 UPDATE types
-SET singular = coalesce(?1, singular),
-    plural   = coalesce(?2, plural),
-    icon     = coalesce(?3, icon),
-    schema   = coalesce(?4, schema)
-WHERE id = ?5
-RETURNING id, icon, singular, plural, schema, created, updated
+SET singular   = coalesce(?1, singular),
+    plural     = coalesce(?2, plural),
+    icon       = coalesce(?3, icon),
+    schema     = coalesce(?4, schema),
+    key_prefix = CASE
+                     WHEN ?5 IS NULL THEN key_prefix
+                     ELSE nullif(?5, '') END,
+    metrics    = coalesce(?6, metrics),
+    updated    = CURRENT_TIMESTAMP
+WHERE id = ?7
+RETURNING id, icon, singular, plural, schema, created, updated, key_prefix, metrics
 `
 
 type UpdateTypeParams struct {
-	Singular *string `json:"singular"`
-	Plural   *string `json:"plural"`
-	Icon     *string `json:"icon"`
-	Schema   []byte  `json:"schema"`
-	ID       string  `json:"id"`
+	Singular  *string     `json:"singular"`
+	Plural    *string     `json:"plural"`
+	Icon      *string     `json:"icon"`
+	Schema    []byte      `json:"schema"`
+	KeyPrefix interface{} `json:"key_prefix"`
+	Metrics   []byte      `json:"metrics"`
+	ID        string      `json:"id"`
 }
 
 func (q *WriteQueries) UpdateType(ctx context.Context, arg UpdateTypeParams) (Type, error) {
@@ -1368,6 +4830,8 @@ func (q *WriteQueries) UpdateType(ctx context.Context, arg UpdateTypeParams) (Ty
 		arg.Plural,
 		arg.Icon,
 		arg.Schema,
+		arg.KeyPrefix,
+		arg.Metrics,
 		arg.ID,
 	)
 	var i Type
@@ -1379,6 +4843,8 @@ func (q *WriteQueries) UpdateType(ctx context.Context, arg UpdateTypeParams) (Ty
 		&i.Schema,
 		&i.Created,
 		&i.Updated,
+		&i.KeyPrefix,
+		&i.Metrics,
 	)
 	return i, err
 }
@@ -1393,7 +4859,8 @@ SET name                   = coalesce(?1, name),
     avatar                 = coalesce(?6, avatar),
     active                 = coalesce(?7, active),
     lastResetSentAt        = coalesce(?8, lastResetSentAt),
-    lastVerificationSentAt = coalesce(?9, lastVerificationSentAt)
+    lastVerificationSentAt = coalesce(?9, lastVerificationSentAt),
+    updated                = CURRENT_TIMESTAMP
 WHERE id = ?10
   AND id != 'system'
 RETURNING id, username, passwordhash, tokenkey, active, name, email, avatar, lastresetsentat, lastverificationsentat, created, updated
@@ -1447,7 +4914,8 @@ const updateWebhook = `-- name: UpdateWebhook :one
 UPDATE webhooks
 SET name        = coalesce(?1, name),
     collection  = coalesce(?2, collection),
-    destination = coalesce(?3, destination)
+    destination = coalesce(?3, destination),
+    updated     = CURRENT_TIMESTAMP
 WHERE id = ?4
 RETURNING id, collection, destination, name, created, updated
 `
@@ -1477,3 +4945,58 @@ func (q *WriteQueries) UpdateWebhook(ctx context.Context, arg UpdateWebhookParam
 	)
 	return i, err
 }
+
+const upsertAPIQuota = `-- name: UpsertAPIQuota :one
+INSERT INTO api_quotas (user, client, daily_limit)
+VALUES (?1, ?2, ?3)
+ON CONFLICT (user, client) DO UPDATE SET daily_limit = excluded.daily_limit,
+                                         updated     = CURRENT_TIMESTAMP
+RETURNING user, client, daily_limit, created, updated
+`
+
+type UpsertAPIQuotaParams struct {
+	User       string `json:"user"`
+	Client     string `json:"client"`
+	DailyLimit int64  `json:"daily_limit"`
+}
+
+func (q *WriteQueries) UpsertAPIQuota(ctx context.Context, arg UpsertAPIQuotaParams) (ApiQuota, error) {
+	row := q.db.QueryRowContext(ctx, upsertAPIQuota, arg.User, arg.Client, arg.DailyLimit)
+	var i ApiQuota
+	err := row.Scan(
+		&i.User,
+		&i.Client,
+		&i.DailyLimit,
+		&i.Created,
+		&i.Updated,
+	)
+	return i, err
+}
+
+const upsertDigest = `-- name: UpsertDigest :one
+
+INSERT INTO digests (user, frequency)
+VALUES (?1, ?2)
+ON CONFLICT (user) DO UPDATE SET frequency = excluded.frequency,
+                                 updated   = CURRENT_TIMESTAMP
+RETURNING user, frequency, last_sent, created, updated
+`
+
+type UpsertDigestParams struct {
+	User      string `json:"user"`
+	Frequency string `json:"frequency"`
+}
+
+// ----------------------------------------------------------------
+func (q *WriteQueries) UpsertDigest(ctx context.Context, arg UpsertDigestParams) (Digest, error) {
+	row := q.db.QueryRowContext(ctx, upsertDigest, arg.User, arg.Frequency)
+	var i Digest
+	err := row.Scan(
+		&i.User,
+		&i.Frequency,
+		&i.LastSent,
+		&i.Created,
+		&i.Updated,
+	)
+	return i, err
+}
@@ -18,6 +18,10 @@ var (
 	ReactionsTable = Table{ID: "reactions", Name: "Reactions"}
 	WebhooksTable  = Table{ID: "webhooks", Name: "Webhooks"}
 
+	DetectionRulesTable = Table{ID: "detection_rules", Name: "Detection Rules"}
+	TicketReopensTable  = Table{ID: "ticket_reopens", Name: "Ticket Reopens"}
+	AlertsTable         = Table{ID: "alerts", Name: "Alerts"}
+
 	DashboardCountsTable = Table{ID: "dashboard_counts", Name: "Dashboard Counts"}
 	SidebarTable         = Table{ID: "sidebar", Name: "Sidebar"}
 	UserPermissionTable  = Table{ID: "user_permissions", Name: "User Permissions"}
@@ -41,5 +45,8 @@ func Tables() []Table {
 		GroupsTable,
 		ReactionsTable,
 		WebhooksTable,
+		DetectionRulesTable,
+		TicketReopensTable,
+		AlertsTable,
 	}
 }
@@ -3,6 +3,12 @@ INSERT INTO _params (key, value)
 VALUES (@key, @value)
 RETURNING *;
 
+-- InitParam stores a param unless it exists, concurrent callers store it once.
+-- name: InitParam :exec
+INSERT INTO _params (key, value)
+VALUES (@key, @value)
+ON CONFLICT (key) DO NOTHING;
+
 -- name: UpdateParam :exec
 UPDATE _params
 SET value = @value
@@ -17,8 +23,9 @@ VALUES (@id, @name, @description, @open, @owner, @resolution, @schema, @state, @
 RETURNING *;
 
 -- name: CreateTicket :one
-INSERT INTO tickets (name, description, open, owner, resolution, schema, state, type)
-VALUES (@name, @description, @open, @owner, @resolution, @schema, @state, @type)
+INSERT INTO tickets (name, description, open, owner, resolution, schema, state, type, resolved)
+VALUES (@name, @description, @open, @owner, @resolution, @schema, @state, @type,
+        CASE WHEN @open THEN NULL ELSE CURRENT_TIMESTAMP END)
 RETURNING *;
 
 -- name: UpdateTicket :one
@@ -30,15 +37,596 @@ SET name        = coalesce(sqlc.narg('name'), name),
     resolution  = coalesce(sqlc.narg('resolution'), resolution),
     schema      = coalesce(sqlc.narg('schema'), schema),
     state       = coalesce(sqlc.narg('state'), state),
-    type        = coalesce(sqlc.narg('type'), type)
+    type        = coalesce(sqlc.narg('type'), type),
+    resolved    = CASE
+                      WHEN coalesce(sqlc.narg('open'), open) THEN NULL
+                      WHEN open THEN CURRENT_TIMESTAMP
+                      ELSE resolved END,
+    resolved_by = CASE
+                      WHEN coalesce(sqlc.narg('open'), open) THEN NULL
+                      WHEN open THEN sqlc.narg('resolved_by')
+                      ELSE resolved_by END,
+    updated     = CURRENT_TIMESTAMP
+WHERE id = @id
+RETURNING *;
+
+-- name: ReopenTicket :one
+UPDATE tickets
+SET open         = TRUE,
+    resolved     = NULL,
+    resolved_by  = NULL,
+    reopen_count = reopen_count + 1,
+    updated      = CURRENT_TIMESTAMP
+WHERE id = @id
+  AND NOT open
+RETURNING *;
+
+-- name: CreateTicketReopen :one
+INSERT INTO ticket_reopens (ticket, reason, resolution, closed_by, reopened_by)
+VALUES (@ticket, @reason, @resolution, @closed_by, @reopened_by)
+RETURNING *;
+
+-- name: AcknowledgeTicket :one
+UPDATE tickets
+SET acknowledged_by = CASE WHEN acknowledged IS NULL THEN @acknowledged_by ELSE acknowledged_by END,
+    acknowledged    = coalesce(acknowledged, CURRENT_TIMESTAMP),
+    updated         = CURRENT_TIMESTAMP
 WHERE id = @id
 RETURNING *;
 
+-- name: ReassignOpenTickets :many
+UPDATE tickets
+SET owner   = sqlc.narg('new_owner'),
+    updated = CURRENT_TIMESTAMP
+WHERE owner = @owner
+  AND open = TRUE
+RETURNING id;
+
 -- name: DeleteTicket :exec
 DELETE
 FROM tickets
 WHERE id = @id;
 
+-- name: EncryptTicket :one
+UPDATE tickets
+SET encrypted   = TRUE,
+    description = @description,
+    state       = @state,
+    updated     = CURRENT_TIMESTAMP
+WHERE id = @id
+  AND encrypted = FALSE
+RETURNING *;
+
+-- name: CreateTicketKey :exec
+INSERT INTO ticket_keys (ticket, key)
+VALUES (@ticket, @key)
+ON CONFLICT DO NOTHING;
+
+-- name: CreateTicketGrant :exec
+INSERT INTO ticket_grants (ticket, user)
+VALUES (@ticket, @user)
+ON CONFLICT DO NOTHING;
+
+-- name: DeleteTicketGrant :exec
+DELETE
+FROM ticket_grants
+WHERE ticket = @ticket
+  AND user = @user;
+
+-- name: CreateLegalHold :one
+INSERT INTO legal_holds (id, collection, reason, created_by)
+VALUES (@id, @collection, @reason, @created_by)
+ON CONFLICT (id) DO UPDATE SET reason = excluded.reason
+RETURNING *;
+
+-- name: DeleteLegalHold :exec
+DELETE
+FROM legal_holds
+WHERE id = @id;
+
+-- name: CreateCustodyEvent :exec
+INSERT INTO file_custody (ticket, file, file_name, action, user, sha256)
+VALUES (@ticket, @file, @file_name, @action, @user, @sha256);
+
+------------------------------------------------------------------
+
+-- name: SetEnrichment :one
+INSERT INTO enrichment_cache (enricher, value, result, expires)
+VALUES (@enricher, @value, @result, @expires)
+ON CONFLICT (enricher, value) DO UPDATE SET result  = excluded.result,
+                                            expires = excluded.expires,
+                                            created = CURRENT_TIMESTAMP
+RETURNING *;
+
+-- name: DeleteExpiredEnrichments :exec
+DELETE
+FROM enrichment_cache
+WHERE expires <= @now;
+
+------------------------------------------------------------------
+
+-- name: CreatePlaybook :one
+INSERT INTO playbooks (name, description, inputs, tasks)
+VALUES (@name, @description, @inputs, @tasks)
+RETURNING *;
+
+-- name: UpdatePlaybook :one
+UPDATE playbooks
+SET name        = coalesce(sqlc.narg('name'), name),
+    description = coalesce(sqlc.narg('description'), description),
+    inputs      = coalesce(sqlc.narg('inputs'), inputs),
+    tasks       = coalesce(sqlc.narg('tasks'), tasks),
+    updated     = CURRENT_TIMESTAMP
+WHERE id = @id
+RETURNING *;
+
+-- name: DeletePlaybook :exec
+DELETE
+FROM playbooks
+WHERE id = @id;
+
+-- name: CreatePlaybookRun :one
+INSERT INTO playbook_runs (playbook, ticket, inputs)
+VALUES (@playbook, @ticket, @inputs)
+RETURNING *;
+
+-- name: AddPlaybookRunTask :exec
+INSERT INTO playbook_run_tasks (run, task)
+VALUES (@run, @task);
+
+------------------------------------------------------------------
+
+-- name: CreateCampaign :one
+INSERT INTO campaigns (name, description, playbook, inputs, feature_kind, feature_value)
+VALUES (@name, @description, @playbook, @inputs, @feature_kind, @feature_value)
+RETURNING *;
+
+-- name: TouchCampaign :exec
+UPDATE campaigns
+SET updated = CURRENT_TIMESTAMP
+WHERE id = @id;
+
+-- name: DeleteCampaign :exec
+DELETE
+FROM campaigns
+WHERE id = @id;
+
+-- name: AddCampaignTicket :one
+INSERT INTO campaign_tickets (campaign, ticket, run)
+VALUES (@campaign, @ticket, @run)
+RETURNING *;
+
+-- name: RemoveCampaignTicket :exec
+DELETE
+FROM campaign_tickets
+WHERE campaign = @campaign
+  AND ticket = @ticket;
+
+-- name: CreateCampaignTimeline :one
+INSERT INTO campaign_timeline (campaign, ticket, message)
+VALUES (@campaign, @ticket, @message)
+RETURNING *;
+
+-- name: SetTicketFeature :exec
+INSERT OR IGNORE INTO ticket_features (ticket, kind, value)
+VALUES (@ticket, @kind, @value);
+
+------------------------------------------------------------------
+
+-- name: AddTicketTechnique :exec
+INSERT OR IGNORE INTO ticket_techniques (ticket, technique, tactic)
+VALUES (@ticket, @technique, @tactic);
+
+-- name: DeleteTicketTechniques :exec
+DELETE
+FROM ticket_techniques
+WHERE ticket = @ticket;
+
+-- name: AddTypeTechnique :exec
+INSERT OR IGNORE INTO type_techniques (type, technique, tactic)
+VALUES (@type, @technique, @tactic);
+
+-- name: DeleteTypeTechniques :exec
+DELETE
+FROM type_techniques
+WHERE type = @type;
+
+------------------------------------------------------------------
+
+-- name: CreateCVE :exec
+INSERT OR IGNORE INTO cves (id)
+VALUES (@id);
+
+-- name: UpdateCVE :exec
+UPDATE cves
+SET description   = @description,
+    cvss_score    = @cvss_score,
+    cvss_severity = @cvss_severity,
+    cvss_vector   = @cvss_vector,
+    products      = @products,
+    published     = @published,
+    enriched      = @enriched
+WHERE id = @id;
+
+-- name: AddTicketCVE :exec
+INSERT OR IGNORE INTO ticket_cves (ticket, cve)
+VALUES (@ticket, @cve);
+
+-- name: SetKnownExploited :exec
+INSERT INTO known_exploited (cve, name, added, due, ransomware, updated)
+VALUES (@cve, @name, @added, @due, @ransomware, @updated)
+ON CONFLICT (cve) DO UPDATE SET name       = excluded.name,
+                                added      = excluded.added,
+                                due        = excluded.due,
+                                ransomware = excluded.ransomware,
+                                updated    = excluded.updated;
+
+-- name: DeleteKnownExploitedBefore :exec
+DELETE
+FROM known_exploited
+WHERE updated < @updated;
+
+------------------------------------------------------------------
+
+-- name: CreateDetectionRule :one
+INSERT INTO detection_rules (ticket, name, description, format, content, author)
+VALUES (@ticket, @name, @description, @format, @content, @author)
+RETURNING *;
+
+-- name: UpdateDetectionRule :one
+UPDATE detection_rules
+SET name        = coalesce(sqlc.narg('name'), name),
+    description = coalesce(sqlc.narg('description'), description),
+    format      = coalesce(sqlc.narg('format'), format),
+    content     = coalesce(sqlc.narg('content'), content),
+    updated     = CURRENT_TIMESTAMP
+WHERE id = @id
+RETURNING *;
+
+-- name: SetDetectionRuleStatus :one
+UPDATE detection_rules
+SET status   = @status,
+    reviewer = coalesce(sqlc.narg('reviewer'), reviewer),
+    deployed = coalesce(sqlc.narg('deployed'), deployed),
+    updated  = CURRENT_TIMESTAMP
+WHERE id = @id
+RETURNING *;
+
+-- name: DeleteDetectionRule :exec
+DELETE
+FROM detection_rules
+WHERE id = @id;
+
+-- name: AddDetectionRuleTicket :exec
+INSERT OR IGNORE INTO detection_rule_tickets (rule, ticket)
+VALUES (@rule, @ticket);
+
+-- name: RemoveDetectionRuleTicket :exec
+DELETE
+FROM detection_rule_tickets
+WHERE rule = @rule
+  AND ticket = @ticket;
+
+------------------------------------------------------------------
+
+-- name: CreateAnnouncement :one
+INSERT INTO announcements (title, message, severity, require_ack, author, expires)
+VALUES (@title, @message, @severity, @require_ack, @author, @expires)
+RETURNING *;
+
+-- name: DeleteAnnouncement :exec
+DELETE
+FROM announcements
+WHERE id = @id;
+
+-- name: ReadAnnouncement :exec
+INSERT OR IGNORE INTO announcement_receipts (announcement, user, read)
+VALUES (@announcement, @user, @read);
+
+-- name: AcknowledgeAnnouncement :exec
+INSERT INTO announcement_receipts (announcement, user, read, acknowledged)
+VALUES (@announcement, @user, @acknowledged, @acknowledged)
+ON CONFLICT (announcement, user) DO UPDATE SET acknowledged = coalesce(announcement_receipts.acknowledged, excluded.acknowledged);
+
+------------------------------------------------------------------
+
+-- name: CreateTicketActivity :exec
+INSERT INTO ticket_activity (ticket, user, method, path, body, status, duration, created)
+VALUES (@ticket, @user, @method, @path, @body, @status, @duration, @created);
+
+------------------------------------------------------------------
+
+-- name: CreateDLPEvent :exec
+INSERT INTO dlp_events (rule, export, ticket, user)
+VALUES (@rule, @export, @ticket, @user);
+
+------------------------------------------------------------------
+
+-- name: CreateFederationPeer :one
+INSERT INTO federation_peers (name, url, secret, max_tlp)
+VALUES (@name, @url, @secret, @max_tlp)
+RETURNING *;
+
+-- name: UpdateFederationPeer :one
+UPDATE federation_peers
+SET name    = coalesce(sqlc.narg('name'), name),
+    url     = coalesce(sqlc.narg('url'), url),
+    secret  = coalesce(sqlc.narg('secret'), secret),
+    max_tlp = coalesce(sqlc.narg('max_tlp'), max_tlp),
+    updated = CURRENT_TIMESTAMP
+WHERE id = @id
+RETURNING *;
+
+-- name: DeleteFederationPeer :exec
+DELETE
+FROM federation_peers
+WHERE id = @id;
+
+-- name: CreateFederationLink :one
+INSERT INTO federation_links (ticket, peer, remote_ticket, remote_key, remote_type, remote_name, remote_open)
+VALUES (@ticket, @peer, @remote_ticket, @remote_key, @remote_type, @remote_name, @remote_open)
+ON CONFLICT (ticket, peer, remote_ticket) DO UPDATE SET remote_key  = excluded.remote_key,
+                                                        remote_type = excluded.remote_type,
+                                                        remote_name = excluded.remote_name,
+                                                        remote_open = excluded.remote_open,
+                                                        updated     = CURRENT_TIMESTAMP
+RETURNING *;
+
+-- name: SetFederationLinkRemoteOpen :exec
+UPDATE federation_links
+SET remote_open = @remote_open,
+    updated     = CURRENT_TIMESTAMP
+WHERE peer = @peer
+  AND remote_ticket = @remote_ticket;
+
+-- name: SetFederationLinkShare :one
+UPDATE federation_links
+SET share   = @share,
+    updated = CURRENT_TIMESTAMP
+WHERE id = @id
+RETURNING *;
+
+-- name: DeleteFederationLink :exec
+DELETE
+FROM federation_links
+WHERE id = @id;
+
+------------------------------------------------------------------
+
+-- name: SetDocumentTemplate :one
+INSERT INTO document_templates (format, name, content)
+VALUES (@format, @name, @content)
+ON CONFLICT (format) DO UPDATE SET name    = excluded.name,
+                                   content = excluded.content,
+                                   updated = CURRENT_TIMESTAMP
+RETURNING *;
+
+-- name: DeleteDocumentTemplate :exec
+DELETE
+FROM document_templates
+WHERE format = @format;
+
+------------------------------------------------------------------
+
+-- name: CreateAutoCloseRule :one
+INSERT INTO auto_close_rules (name, enabled, filter, inactive_days, warning_days, resolution)
+VALUES (@name, @enabled, @filter, @inactive_days, @warning_days, @resolution)
+RETURNING *;
+
+-- name: UpdateAutoCloseRule :one
+UPDATE auto_close_rules
+SET name          = coalesce(sqlc.narg('name'), name),
+    enabled       = coalesce(sqlc.narg('enabled'), enabled),
+    filter        = coalesce(sqlc.narg('filter'), filter),
+    inactive_days = coalesce(sqlc.narg('inactive_days'), inactive_days),
+    warning_days  = coalesce(sqlc.narg('warning_days'), warning_days),
+    resolution    = coalesce(sqlc.narg('resolution'), resolution),
+    updated       = CURRENT_TIMESTAMP
+WHERE id = @id
+RETURNING *;
+
+-- name: DeleteAutoCloseRule :exec
+DELETE
+FROM auto_close_rules
+WHERE id = @id;
+
+-- name: SetAutoCloseWarning :exec
+INSERT INTO auto_close_warnings (ticket, rule, warned)
+VALUES (@ticket, @rule, @warned)
+ON CONFLICT (ticket) DO UPDATE SET rule   = excluded.rule,
+                                   warned = excluded.warned;
+
+-- name: DeleteAutoCloseWarning :exec
+DELETE
+FROM auto_close_warnings
+WHERE ticket = @ticket;
+
+------------------------------------------------------------------
+
+-- name: CreateAlert :one
+INSERT INTO alerts (source, name, description, severity, data, maintenance)
+VALUES (@source, @name, @description, @severity, @data, @maintenance)
+RETURNING *;
+
+-- name: UpdateAlertStatus :one
+UPDATE alerts
+SET status  = @status,
+    updated = CURRENT_TIMESTAMP
+WHERE id = @id
+  AND status != 'promoted'
+RETURNING *;
+
+-- name: PromoteAlert :one
+UPDATE alerts
+SET status  = 'promoted',
+    ticket  = @ticket,
+    updated = CURRENT_TIMESTAMP
+WHERE id = @id
+  AND status != 'promoted'
+RETURNING *;
+
+-- name: DeleteAlert :exec
+DELETE
+FROM alerts
+WHERE id = @id;
+
+------------------------------------------------------------------
+
+-- name: CreateQueue :one
+INSERT INTO queues (name, description, filter)
+VALUES (@name, @description, @filter)
+RETURNING *;
+
+-- name: UpdateQueue :one
+UPDATE queues
+SET name        = coalesce(sqlc.narg('name'), name),
+    description = coalesce(sqlc.narg('description'), description),
+    filter      = coalesce(sqlc.narg('filter'), filter),
+    updated     = CURRENT_TIMESTAMP
+WHERE id = @id
+RETURNING *;
+
+-- name: DeleteQueue :exec
+DELETE
+FROM queues
+WHERE id = @id;
+
+-- ClaimNextTicket assigns the oldest open, unencrypted ticket without an
+-- owner that matches the filter of the queue. It is a single statement, so
+-- concurrent claims get different tickets and skip none.
+-- name: ClaimNextTicket :one
+UPDATE tickets
+SET owner   = @owner,
+    updated = CURRENT_TIMESTAMP
+WHERE id = (SELECT candidates.id
+            FROM tickets AS candidates,
+                 queues
+            WHERE queues.id = @queue
+              AND candidates.open
+              AND candidates.owner IS NULL
+              AND NOT candidates.encrypted
+              AND (coalesce(json_array_length(queues.filter, '$.types'), 0) = 0
+                OR lower(candidates.type) IN (SELECT lower(value) FROM json_each(queues.filter, '$.types')))
+              AND (coalesce(json_array_length(queues.filter, '$.severities'), 0) = 0
+                OR (json_type(candidates.state, '$.severity') = 'text'
+                  AND lower(json_extract(candidates.state, '$.severity')) IN
+                      (SELECT lower(value) FROM json_each(queues.filter, '$.severities'))))
+              AND (coalesce(json_array_length(queues.filter, '$.tags'), 0) = 0
+                OR (json_type(candidates.state, '$.tags') = 'array'
+                  AND EXISTS (SELECT 1
+                              FROM json_each(candidates.state, '$.tags') AS tag
+                              WHERE tag.type = 'text'
+                                AND lower(tag.value) IN (SELECT lower(value) FROM json_each(queues.filter, '$.tags')))))
+            ORDER BY candidates.created, candidates.id
+            LIMIT 1)
+  AND open
+  AND owner IS NULL
+RETURNING *;
+
+------------------------------------------------------------------
+
+-- name: StartWorkTimer :one
+INSERT INTO work_entries (ticket, user, description, started)
+VALUES (@ticket, @user, @description, @started)
+ON CONFLICT DO NOTHING
+RETURNING *;
+
+-- name: StopWorkTimer :one
+UPDATE work_entries
+SET duration = max(unixepoch(CAST(@stopped AS TEXT)) - unixepoch(started), 0),
+    updated  = CURRENT_TIMESTAMP
+WHERE ticket = @ticket
+  AND user = @user
+  AND duration IS NULL
+RETURNING *;
+
+-- name: CreateWorkEntry :one
+INSERT INTO work_entries (ticket, user, description, started, duration, manual)
+VALUES (@ticket, @user, @description, @started, @duration, TRUE)
+RETURNING *;
+
+-- name: DeleteWorkEntry :exec
+DELETE
+FROM work_entries
+WHERE id = @id;
+
+------------------------------------------------------------------
+
+-- name: SetTaskOutput :one
+INSERT INTO task_outputs (task, ticket, name, output)
+SELECT tasks.id, tasks.ticket, @name, @output
+FROM tasks
+WHERE tasks.id = @task
+ON CONFLICT (task) DO UPDATE SET name    = excluded.name,
+                                 output  = excluded.output,
+                                 updated = CURRENT_TIMESTAMP
+RETURNING *;
+
+-- name: SetTaskTimer :one
+INSERT INTO task_timers (task, due, action, escalation_name, escalation_owner)
+VALUES (@task, @due, @action, @escalation_name, @escalation_owner)
+ON CONFLICT (task) DO UPDATE SET due              = excluded.due,
+                                 action           = excluded.action,
+                                 escalation_name  = excluded.escalation_name,
+                                 escalation_owner = excluded.escalation_owner,
+                                 result           = NULL,
+                                 escalation_task  = NULL,
+                                 fired            = NULL,
+                                 updated          = CURRENT_TIMESTAMP
+RETURNING *;
+
+-- name: PostponeTaskTimer :exec
+UPDATE task_timers
+SET due     = @due,
+    updated = CURRENT_TIMESTAMP
+WHERE task = @task
+  AND fired IS NULL;
+
+-- name: FireTaskTimer :exec
+UPDATE task_timers
+SET result          = @result,
+    escalation_task = @escalation_task,
+    fired           = @fired,
+    updated         = CURRENT_TIMESTAMP
+WHERE task = @task;
+
+-- name: ReassignTaskTimers :many
+UPDATE task_timers
+SET escalation_owner = sqlc.narg('new_owner'),
+    updated          = CURRENT_TIMESTAMP
+WHERE escalation_owner = @owner
+  AND fired IS NULL
+RETURNING task;
+
+-- name: DeleteTaskTimer :exec
+DELETE
+FROM task_timers
+WHERE task = @task;
+
+------------------------------------------------------------------
+
+-- name: SetFeed :one
+INSERT INTO feeds (name, source, indicators)
+VALUES (@name, @source, @indicators)
+ON CONFLICT (name) DO UPDATE SET source     = excluded.source,
+                                 indicators = excluded.indicators,
+                                 imported   = CURRENT_TIMESTAMP
+RETURNING *;
+
+-- name: DeleteFeedIndicators :exec
+DELETE
+FROM feed_indicators
+WHERE feed = @feed;
+
+-- name: InsertFeedIndicator :exec
+INSERT OR IGNORE INTO feed_indicators (feed, kind, value)
+VALUES (@feed, @kind, @value);
+
+-- name: DeleteFeed :exec
+DELETE
+FROM feeds
+WHERE name = @name;
+
 ------------------------------------------------------------------
 
 -- name: InsertComment :one
@@ -53,10 +641,16 @@ RETURNING *;
 
 -- name: UpdateComment :one
 UPDATE comments
-SET message = coalesce(sqlc.narg('message'), message)
+SET message = coalesce(sqlc.narg('message'), message),
+    updated = CURRENT_TIMESTAMP
 WHERE id = @id
 RETURNING *;
 
+-- name: SetCommentMessage :exec
+UPDATE comments
+SET message = @message
+WHERE id = @id;
+
 -- name: DeleteComment :exec
 DELETE
 FROM comments
@@ -77,8 +671,8 @@ WHERE key = @key;
 ------------------------------------------------------------------
 
 -- name: InsertFile :one
-INSERT INTO files (id, name, blob, size, ticket, created, updated)
-VALUES (@id, @name, @blob, @size, @ticket, @created, @updated)
+INSERT INTO files (id, name, blob, size, ticket, md5, sha1, sha256, created, updated)
+VALUES (@id, @name, @blob, @size, @ticket, @md5, @sha1, @sha256, @created, @updated)
 RETURNING *;
 
 -- name: CreateFile :one
@@ -86,11 +680,19 @@ INSERT INTO files (name, blob, size, ticket)
 VALUES (@name, @blob, @size, @ticket)
 RETURNING *;
 
+-- name: SetFileHashes :exec
+UPDATE files
+SET md5    = @md5,
+    sha1   = @sha1,
+    sha256 = @sha256
+WHERE id = @id;
+
 -- name: UpdateFile :one
 UPDATE files
 SET name = coalesce(sqlc.narg('name'), name),
     blob = coalesce(sqlc.narg('blob'), blob),
-    size = coalesce(sqlc.narg('size'), size)
+    size = coalesce(sqlc.narg('size'), size),
+    updated = CURRENT_TIMESTAMP
 WHERE id = @id
 RETURNING *;
 
@@ -114,7 +716,8 @@ RETURNING *;
 -- name: UpdateLink :one
 UPDATE links
 SET name = coalesce(sqlc.narg('name'), name),
-    url  = coalesce(sqlc.narg('url'), url)
+    url  = coalesce(sqlc.narg('url'), url),
+    updated = CURRENT_TIMESTAMP
 WHERE id = @id
 RETURNING *;
 
@@ -141,7 +744,8 @@ SET name        = coalesce(sqlc.narg('name'), name),
     action      = coalesce(sqlc.narg('action'), action),
     actiondata  = coalesce(sqlc.narg('actiondata'), actiondata),
     trigger     = coalesce(sqlc.narg('trigger'), trigger),
-    triggerdata = coalesce(sqlc.narg('triggerdata'), triggerdata)
+    triggerdata = coalesce(sqlc.narg('triggerdata'), triggerdata),
+    updated     = CURRENT_TIMESTAMP
 WHERE id = @id
 RETURNING *;
 
@@ -150,6 +754,15 @@ DELETE
 FROM reactions
 WHERE id = @id;
 
+-- name: CreateReactionRun :exec
+INSERT INTO reaction_runs (reaction, success, duration_ms, cpu_ms, memory_peak)
+VALUES (@reaction, @success, @duration_ms, @cpu_ms, @memory_peak);
+
+-- name: DeleteReactionRunsBefore :exec
+DELETE
+FROM reaction_runs
+WHERE created < @before;
+
 ------------------------------------------------------------------
 
 -- name: InsertTask :one
@@ -166,10 +779,19 @@ RETURNING *;
 UPDATE tasks
 SET name  = coalesce(sqlc.narg('name'), name),
     open  = coalesce(sqlc.narg('open'), open),
-    owner = coalesce(sqlc.narg('owner'), owner)
+    owner = coalesce(sqlc.narg('owner'), owner),
+    updated = CURRENT_TIMESTAMP
 WHERE id = @id
 RETURNING *;
 
+-- name: ReassignOpenTasks :many
+UPDATE tasks
+SET owner   = sqlc.narg('new_owner'),
+    updated = CURRENT_TIMESTAMP
+WHERE owner = @owner
+  AND open = TRUE
+RETURNING id;
+
 -- name: DeleteTask :exec
 DELETE
 FROM tasks
@@ -190,7 +812,8 @@ RETURNING *;
 -- name: UpdateTimeline :one
 UPDATE timeline
 SET message = coalesce(sqlc.narg('message'), message),
-    time    = coalesce(sqlc.narg('time'), time)
+    time    = coalesce(sqlc.narg('time'), time),
+    updated = CURRENT_TIMESTAMP
 WHERE id = @id
 RETURNING *;
 
@@ -207,16 +830,21 @@ VALUES (@id, @singular, @plural, @icon, @schema, @created, @updated)
 RETURNING *;
 
 -- name: CreateType :one
-INSERT INTO types (singular, plural, icon, schema)
-VALUES (@singular, @plural, @icon, @schema)
+INSERT INTO types (singular, plural, icon, schema, key_prefix, metrics)
+VALUES (@singular, @plural, @icon, @schema, nullif(sqlc.narg('key_prefix'), ''), coalesce(sqlc.narg('metrics'), '[]'))
 RETURNING *;
 
 -- name: UpdateType :one
 UPDATE types
-SET singular = coalesce(sqlc.narg('singular'), singular),
-    plural   = coalesce(sqlc.narg('plural'), plural),
-    icon     = coalesce(sqlc.narg('icon'), icon),
-    schema   = coalesce(sqlc.narg('schema'), schema)
+SET singular   = coalesce(sqlc.narg('singular'), singular),
+    plural     = coalesce(sqlc.narg('plural'), plural),
+    icon       = coalesce(sqlc.narg('icon'), icon),
+    schema     = coalesce(sqlc.narg('schema'), schema),
+    key_prefix = CASE
+                     WHEN sqlc.narg('key_prefix') IS NULL THEN key_prefix
+                     ELSE nullif(sqlc.narg('key_prefix'), '') END,
+    metrics    = coalesce(sqlc.narg('metrics'), metrics),
+    updated    = CURRENT_TIMESTAMP
 WHERE id = @id
 RETURNING *;
 
@@ -247,11 +875,95 @@ SET name                   = coalesce(sqlc.narg('name'), name),
     avatar                 = coalesce(sqlc.narg('avatar'), avatar),
     active                 = coalesce(sqlc.narg('active'), active),
     lastResetSentAt        = coalesce(sqlc.narg('lastResetSentAt'), lastResetSentAt),
-    lastVerificationSentAt = coalesce(sqlc.narg('lastVerificationSentAt'), lastVerificationSentAt)
+    lastVerificationSentAt = coalesce(sqlc.narg('lastVerificationSentAt'), lastVerificationSentAt),
+    updated                = CURRENT_TIMESTAMP
 WHERE id = @id
   AND id != 'system'
 RETURNING *;
 
+-- name: CreateInvitation :one
+INSERT INTO invitations (email, name, groups, token_hash, invited_by, expires)
+VALUES (@email, @name, @groups, @token_hash, @invited_by, @expires)
+RETURNING *;
+
+-- name: RenewInvitation :one
+UPDATE invitations
+SET token_hash = @token_hash,
+    expires    = @expires,
+    updated    = CURRENT_TIMESTAMP
+WHERE id = @id
+  AND accepted_by IS NULL
+RETURNING *;
+
+-- name: AcceptInvitation :one
+UPDATE invitations
+SET accepted_by = @accepted_by,
+    updated     = CURRENT_TIMESTAMP
+WHERE id = @id
+  AND accepted_by IS NULL
+RETURNING *;
+
+-- name: DeleteInvitation :exec
+DELETE
+FROM invitations
+WHERE id = @id;
+
+-- name: AddAPIUsage :exec
+INSERT INTO api_usage (user, client, day, requests)
+VALUES (@user, @client, @day, @requests)
+ON CONFLICT (user, client, day) DO UPDATE SET requests = api_usage.requests + excluded.requests;
+
+-- name: UpsertAPIQuota :one
+INSERT INTO api_quotas (user, client, daily_limit)
+VALUES (@user, @client, @daily_limit)
+ON CONFLICT (user, client) DO UPDATE SET daily_limit = excluded.daily_limit,
+                                         updated     = CURRENT_TIMESTAMP
+RETURNING *;
+
+-- name: DeleteAPIQuota :exec
+DELETE
+FROM api_quotas
+WHERE user = @user
+  AND client = @client;
+
+-- name: CreateLog :exec
+INSERT INTO logs (level, message, component, user, attrs, created)
+VALUES (@level, @message, @component, @user, @attrs, @created);
+
+-- name: DeleteLogsBefore :execrows
+DELETE
+FROM logs
+WHERE created < @created;
+
+-- name: CreatePlatformError :exec
+INSERT INTO platform_errors (fingerprint, ticket, occurrences, first_seen, last_seen)
+VALUES (@fingerprint, @ticket, 1, @seen, @seen)
+ON CONFLICT (fingerprint) DO UPDATE SET ticket      = excluded.ticket,
+                                        occurrences = 1,
+                                        first_seen  = excluded.first_seen,
+                                        last_seen   = excluded.last_seen;
+
+-- name: RecordPlatformError :one
+UPDATE platform_errors
+SET occurrences = occurrences + 1,
+    last_seen   = @seen
+WHERE fingerprint = @fingerprint
+RETURNING *;
+
+-- name: CreatePasswordHistory :exec
+INSERT INTO password_history (user, passwordHash)
+VALUES (@user, @passwordHash);
+
+-- name: PrunePasswordHistory :exec
+DELETE
+FROM password_history
+WHERE password_history.user = @user
+  AND password_history.id NOT IN (SELECT history.id
+                 FROM password_history history
+                 WHERE history.user = @user
+                 ORDER BY history.created DESC, history.rowid DESC
+                 LIMIT @keep);
+
 -- name: DeleteUser :exec
 DELETE
 FROM users
@@ -274,7 +986,8 @@ RETURNING *;
 UPDATE webhooks
 SET name        = coalesce(sqlc.narg('name'), name),
     collection  = coalesce(sqlc.narg('collection'), collection),
-    destination = coalesce(sqlc.narg('destination'), destination)
+    destination = coalesce(sqlc.narg('destination'), destination),
+    updated     = CURRENT_TIMESTAMP
 WHERE id = @id
 RETURNING *;
 
@@ -285,6 +998,155 @@ WHERE id = @id;
 
 ------------------------------------------------------------------
 
+-- name: CreateDeadLetter :one
+INSERT INTO dead_letters (webhook, destination, payload, error)
+VALUES (@webhook, @destination, @payload, @error)
+RETURNING *;
+
+-- name: UpdateDeadLetterAttempt :one
+UPDATE dead_letters
+SET error    = @error,
+    attempts = attempts + 1,
+    updated  = CURRENT_TIMESTAMP
+WHERE id = @id
+RETURNING *;
+
+-- name: DeleteDeadLetter :exec
+DELETE
+FROM dead_letters
+WHERE id = @id;
+
+------------------------------------------------------------------
+
+-- name: CreateSlackThread :one
+INSERT INTO slack_threads (ticket, channel, thread_ts)
+VALUES (@ticket, @channel, @thread_ts)
+RETURNING *;
+
+------------------------------------------------------------------
+
+-- name: CreatePushSubscription :one
+INSERT INTO push_subscriptions (user, endpoint, p256dh, auth)
+VALUES (@user, @endpoint, @p256dh, @auth)
+ON CONFLICT (endpoint) DO UPDATE SET user    = excluded.user,
+                                     p256dh  = excluded.p256dh,
+                                     auth    = excluded.auth,
+                                     updated = CURRENT_TIMESTAMP
+RETURNING *;
+
+-- name: DeletePushSubscription :exec
+DELETE
+FROM push_subscriptions
+WHERE id = @id
+  AND user = @user;
+
+-- name: DeletePushSubscriptionByEndpoint :exec
+DELETE
+FROM push_subscriptions
+WHERE endpoint = @endpoint;
+
+------------------------------------------------------------------
+
+-- name: UpsertDigest :one
+INSERT INTO digests (user, frequency)
+VALUES (@user, @frequency)
+ON CONFLICT (user) DO UPDATE SET frequency = excluded.frequency,
+                                 updated   = CURRENT_TIMESTAMP
+RETURNING *;
+
+-- name: UpdateDigestSent :exec
+UPDATE digests
+SET last_sent = @last_sent
+WHERE user = @user;
+
+------------------------------------------------------------------
+
+-- name: CreateNotificationRule :one
+INSERT INTO notification_rules (name, enabled, filter, channel, target, throttle, dedup)
+VALUES (@name, @enabled, @filter, @channel, @target, @throttle, @dedup)
+RETURNING *;
+
+-- name: UpdateNotificationRule :one
+UPDATE notification_rules
+SET name     = coalesce(sqlc.narg('name'), name),
+    enabled  = coalesce(sqlc.narg('enabled'), enabled),
+    filter   = coalesce(sqlc.narg('filter'), filter),
+    channel  = coalesce(sqlc.narg('channel'), channel),
+    target   = coalesce(sqlc.narg('target'), target),
+    throttle = coalesce(sqlc.narg('throttle'), throttle),
+    dedup    = coalesce(sqlc.narg('dedup'), dedup),
+    updated  = CURRENT_TIMESTAMP
+WHERE id = @id
+RETURNING *;
+
+-- name: DeleteNotificationRule :exec
+DELETE
+FROM notification_rules
+WHERE id = @id;
+
+------------------------------------------------------------------
+
+-- name: CreateEscalationPolicy :one
+INSERT INTO escalation_policies (name, filter, steps, repeat, pause_states)
+VALUES (@name, @filter, @steps, @repeat, @pause_states)
+RETURNING *;
+
+-- name: DeleteEscalationPolicy :exec
+DELETE
+FROM escalation_policies
+WHERE id = @id;
+
+-- name: UpdateEscalationPolicySteps :exec
+UPDATE escalation_policies
+SET steps   = @steps,
+    updated = CURRENT_TIMESTAMP
+WHERE id = @id;
+
+-- name: CreateTicketEscalation :one
+INSERT INTO ticket_escalations (ticket, policy, next_at)
+VALUES (@ticket, @policy, @next_at)
+RETURNING *;
+
+-- name: PostponeTicketEscalation :exec
+UPDATE ticket_escalations
+SET next_at = @next_at,
+    updated = CURRENT_TIMESTAMP
+WHERE ticket = @ticket;
+
+-- name: PauseTicketEscalation :exec
+UPDATE ticket_escalations
+SET paused_since = @paused_since,
+    updated      = CURRENT_TIMESTAMP
+WHERE ticket = @ticket
+  AND paused_since IS NULL;
+
+-- name: ResumeTicketEscalation :exec
+UPDATE ticket_escalations
+SET paused_since = NULL,
+    paused       = @paused,
+    next_at      = @next_at,
+    updated      = CURRENT_TIMESTAMP
+WHERE ticket = @ticket;
+
+-- name: AdvanceTicketEscalation :exec
+UPDATE ticket_escalations
+SET step    = @step,
+    next_at = @next_at,
+    updated = CURRENT_TIMESTAMP
+WHERE ticket = @ticket;
+
+-- name: AcknowledgeTicketEscalation :one
+UPDATE ticket_escalations
+SET acknowledged_by = @acknowledged_by,
+    acknowledged_at = CURRENT_TIMESTAMP,
+    next_at         = NULL,
+    updated         = CURRENT_TIMESTAMP
+WHERE ticket = @ticket
+  AND acknowledged_at IS NULL
+RETURNING *;
+
+------------------------------------------------------------------
+
 -- name: InsertGroup :one
 INSERT INTO groups (id, name, permissions, created, updated)
 VALUES (@id, @name, @permissions, @created, @updated)
@@ -298,7 +1160,8 @@ RETURNING *;
 -- name: UpdateGroup :one
 UPDATE groups
 SET name        = coalesce(sqlc.narg('name'), name),
-    permissions = coalesce(sqlc.narg('permissions'), permissions)
+    permissions = coalesce(sqlc.narg('permissions'), permissions),
+    updated     = CURRENT_TIMESTAMP
 WHERE id = @id
 RETURNING *;
 
@@ -326,3 +1189,106 @@ DELETE
 FROM group_inheritance
 WHERE parent_group_id = @parent_group_id
   AND child_group_id = @child_group_id;
+
+------------------------------------------------------------------
+
+-- name: CreateMaintenanceWindow :one
+INSERT INTO maintenance_windows (name, filter, starts, ends)
+VALUES (@name, @filter, @starts, @ends)
+RETURNING *;
+
+-- name: UpdateMaintenanceWindow :one
+UPDATE maintenance_windows
+SET name    = coalesce(sqlc.narg('name'), name),
+    filter  = coalesce(sqlc.narg('filter'), filter),
+    starts  = coalesce(sqlc.narg('starts'), starts),
+    ends    = coalesce(sqlc.narg('ends'), ends),
+    updated = CURRENT_TIMESTAMP
+WHERE id = @id
+RETURNING *;
+
+-- name: DeleteMaintenanceWindow :exec
+DELETE
+FROM maintenance_windows
+WHERE id = @id;
+
+------------------------------------------------------------------
+
+-- name: AddTicketArtifact :execrows
+INSERT OR IGNORE INTO ticket_artifacts (ticket, kind, value)
+VALUES (@ticket, @kind, @value);
+
+------------------------------------------------------------------
+
+-- name: AddArtifactSighting :exec
+INSERT INTO artifact_sightings (kind, value, ticket, source, reference)
+VALUES (@kind, @value, @ticket, @source, @reference);
+
+------------------------------------------------------------------
+
+-- name: CreateRedactionProfile :one
+INSERT INTO redaction_profiles (name, audience, fields, hide_files, hide_identities)
+VALUES (@name, @audience, @fields, @hide_files, @hide_identities)
+RETURNING *;
+
+-- name: UpdateRedactionProfile :one
+UPDATE redaction_profiles
+SET name            = coalesce(sqlc.narg('name'), name),
+    audience        = coalesce(sqlc.narg('audience'), audience),
+    fields          = coalesce(sqlc.narg('fields'), fields),
+    hide_files      = coalesce(sqlc.narg('hide_files'), hide_files),
+    hide_identities = coalesce(sqlc.narg('hide_identities'), hide_identities),
+    updated         = CURRENT_TIMESTAMP
+WHERE id = @id
+RETURNING *;
+
+-- name: DeleteRedactionProfile :exec
+DELETE
+FROM redaction_profiles
+WHERE id = @id;
+
+------------------------------------------------------------------
+
+-- name: CreateJournalEntry :exec
+INSERT INTO request_journal (reaction, request, size, truncated, success, error)
+VALUES (@reaction, @request, @size, @truncated, @success, @error);
+
+-- name: PruneJournal :exec
+DELETE
+FROM request_journal
+WHERE request_journal.reaction = @reaction
+  AND request_journal.id NOT IN (SELECT kept.id
+                 FROM request_journal AS kept
+                 WHERE kept.reaction = @reaction
+                 ORDER BY kept.created DESC, kept.rowid DESC
+                 LIMIT @keep);
+
+-- name: UpdateJournalReplay :one
+UPDATE request_journal
+SET success  = @success,
+    error    = @error,
+    replayed = CURRENT_TIMESTAMP
+WHERE id = @id
+RETURNING *;
+
+------------------------------------------------------------------
+
+-- name: CreateAlertMapping :one
+INSERT INTO alert_mappings (name, source, template, template_version, fields)
+VALUES (@name, @source, @template, @template_version, @fields)
+RETURNING *;
+
+-- name: UpdateAlertMapping :one
+UPDATE alert_mappings
+SET name             = coalesce(sqlc.narg('name'), name),
+    source           = coalesce(sqlc.narg('source'), source),
+    template_version = coalesce(sqlc.narg('template_version'), template_version),
+    fields           = coalesce(sqlc.narg('fields'), fields),
+    updated          = CURRENT_TIMESTAMP
+WHERE id = @id
+RETURNING *;
+
+-- name: DeleteAlertMapping :exec
+DELETE
+FROM alert_mappings
+WHERE id = @id;
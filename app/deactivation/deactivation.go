@@ -0,0 +1,353 @@
+// Package deactivation deactivates users and hands their open work over to
+// another user or a group, so that tickets, tasks and escalations are not
+// left with somebody who can no longer log in.
+package deactivation
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/SecurityBrewery/catalyst/app/auth/password"
+	"github.com/SecurityBrewery/catalyst/app/database"
+	"github.com/SecurityBrewery/catalyst/app/database/sqlc"
+	"github.com/SecurityBrewery/catalyst/app/digest"
+	"github.com/SecurityBrewery/catalyst/app/escalation"
+	"github.com/SecurityBrewery/catalyst/app/notification"
+	"github.com/SecurityBrewery/catalyst/app/pointer"
+)
+
+var (
+	ErrSystemUser = errors.New("the system user cannot be deactivated")
+	ErrTarget     = errors.New("invalid reassignment target")
+)
+
+// Target receives the work of a deactivated user. Work reassigned to a
+// group is unassigned, so it shows up in the queues of the team, and the
+// group is paged instead of the user. Without a target the work is only
+// unassigned.
+type Target struct {
+	User  string
+	Group string
+}
+
+// Summary lists the items of a user that are, or would be, reassigned.
+type Summary struct {
+	Tickets            []string
+	Tasks              []string
+	TaskTimers         []string
+	EscalationPolicies []string
+	NotificationRules  []string
+	// Digest is set if the scheduled digest of the user is turned off.
+	Digest            bool
+	PushSubscriptions int
+}
+
+// Preview lists the items that Deactivate would reassign without changing
+// anything, so the admin can choose the target.
+func Preview(ctx context.Context, queries *sqlc.Queries, userID string) (*Summary, error) {
+	user, err := queries.GetUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := newSummary()
+
+	// a negative limit lists all tickets
+	tickets, err := queries.ListOpenTicketsByOwner(ctx, sqlc.ListOpenTicketsByOwnerParams{Owner: &user.ID, Limit: -1})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tickets: %w", err)
+	}
+
+	for _, ticket := range tickets {
+		summary.Tickets = append(summary.Tickets, ticket.ID)
+	}
+
+	tasks, err := queries.ListOpenTasksByOwner(ctx, &user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tasks: %w", err)
+	}
+
+	for _, task := range tasks {
+		summary.Tasks = append(summary.Tasks, task.ID)
+	}
+
+	timers, err := queries.ListPendingTaskTimersByEscalationOwner(ctx, &user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list task timers: %w", err)
+	}
+
+	summary.TaskTimers = append(summary.TaskTimers, timers...)
+
+	if err := reassignEscalations(ctx, queries, &user, nil, summary); err != nil {
+		return nil, err
+	}
+
+	if err := reassignNotificationRules(ctx, queries, &user, nil, summary); err != nil {
+		return nil, err
+	}
+
+	if err := collectPersonal(ctx, queries, &user, false, summary); err != nil {
+		return nil, err
+	}
+
+	return summary, nil
+}
+
+// Deactivate blocks the login of a user, revokes all their tokens and push
+// subscriptions, and reassigns their open tickets and tasks, pending task
+// escalations, escalation policy steps and email notification rules.
+func Deactivate(ctx context.Context, queries *sqlc.Queries, userID string, target Target) (*Summary, error) {
+	user, err := queries.GetUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.ID == "system" {
+		return nil, ErrSystemUser
+	}
+
+	if err := validateTarget(ctx, queries, &user, &target); err != nil {
+		return nil, err
+	}
+
+	// a new token key invalidates all tokens signed for the user
+	tokenKey, err := password.GenerateTokenKey()
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := queries.UpdateUser(ctx, sqlc.UpdateUserParams{
+		ID:       user.ID,
+		Active:   pointer.Pointer(false),
+		TokenKey: &tokenKey,
+	}); err != nil {
+		return nil, fmt.Errorf("failed to deactivate user: %w", err)
+	}
+
+	summary := newSummary()
+
+	var newOwner *string
+	if target.User != "" {
+		newOwner = &target.User
+	}
+
+	if summary.Tickets, err = queries.ReassignOpenTickets(ctx, sqlc.ReassignOpenTicketsParams{Owner: &user.ID, NewOwner: newOwner}); err != nil {
+		return nil, fmt.Errorf("failed to reassign tickets: %w", err)
+	}
+
+	if summary.Tasks, err = queries.ReassignOpenTasks(ctx, sqlc.ReassignOpenTasksParams{Owner: &user.ID, NewOwner: newOwner}); err != nil {
+		return nil, fmt.Errorf("failed to reassign tasks: %w", err)
+	}
+
+	if summary.TaskTimers, err = queries.ReassignTaskTimers(ctx, sqlc.ReassignTaskTimersParams{Owner: &user.ID, NewOwner: newOwner}); err != nil {
+		return nil, fmt.Errorf("failed to reassign task timers: %w", err)
+	}
+
+	if err := reassignEscalations(ctx, queries, &user, &target, summary); err != nil {
+		return nil, err
+	}
+
+	if err := reassignNotificationRules(ctx, queries, &user, &target, summary); err != nil {
+		return nil, err
+	}
+
+	if err := collectPersonal(ctx, queries, &user, true, summary); err != nil {
+		return nil, err
+	}
+
+	return summary, nil
+}
+
+func newSummary() *Summary {
+	return &Summary{
+		Tickets:            []string{},
+		Tasks:              []string{},
+		TaskTimers:         []string{},
+		EscalationPolicies: []string{},
+		NotificationRules:  []string{},
+	}
+}
+
+func validateTarget(ctx context.Context, queries *sqlc.Queries, user *sqlc.User, target *Target) error {
+	switch {
+	case target.User != "" && target.Group != "":
+		return fmt.Errorf("%w: reassign to either a user or a group", ErrTarget)
+	case target.User == user.ID:
+		return fmt.Errorf("%w: cannot reassign to the deactivated user", ErrTarget)
+	case target.User != "":
+		targetUser, err := queries.GetUser(ctx, target.User)
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("%w: user %q does not exist", ErrTarget, target.User)
+		} else if err != nil {
+			return err
+		}
+
+		if !targetUser.Active {
+			return fmt.Errorf("%w: user %q is not active", ErrTarget, target.User)
+		}
+	case target.Group != "":
+		if _, err := queries.GetGroup(ctx, target.Group); errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("%w: group %q does not exist", ErrTarget, target.Group)
+		} else if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// reassignEscalations replaces the user in the steps of escalation policies.
+// Without a target the affected policies are only collected.
+func reassignEscalations(ctx context.Context, queries *sqlc.Queries, user *sqlc.User, target *Target, summary *Summary) error {
+	policies, err := database.PaginateItems(ctx, func(ctx context.Context, offset, limit int64) ([]sqlc.ListEscalationPoliciesRow, error) {
+		return queries.ListEscalationPolicies(ctx, sqlc.ListEscalationPoliciesParams{Offset: offset, Limit: limit})
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list escalation policies: %w", err)
+	}
+
+	for _, policy := range policies {
+		steps, err := escalation.ParseSteps(policy.Steps)
+		if err != nil {
+			return fmt.Errorf("failed to parse escalation policy %s: %w", policy.ID, err)
+		}
+
+		if !slices.ContainsFunc(steps, func(step escalation.Step) bool { return slices.Contains(step.Users, user.ID) }) {
+			continue
+		}
+
+		summary.EscalationPolicies = append(summary.EscalationPolicies, policy.ID)
+
+		if target == nil {
+			continue
+		}
+
+		for i := range steps {
+			steps[i] = replaceInStep(steps[i], user.ID, target)
+		}
+
+		b, err := json.Marshal(steps)
+		if err != nil {
+			return err
+		}
+
+		if err := queries.UpdateEscalationPolicySteps(ctx, sqlc.UpdateEscalationPolicyStepsParams{ID: policy.ID, Steps: b}); err != nil {
+			return fmt.Errorf("failed to update escalation policy %s: %w", policy.ID, err)
+		}
+	}
+
+	return nil
+}
+
+func replaceInStep(step escalation.Step, userID string, target *Target) escalation.Step {
+	if !slices.Contains(step.Users, userID) {
+		return step
+	}
+
+	step.Users = slices.DeleteFunc(step.Users, func(u string) bool { return u == userID })
+
+	switch {
+	case target.User != "" && !slices.Contains(step.Users, target.User):
+		step.Users = append(step.Users, target.User)
+	case target.Group != "" && !slices.Contains(step.Groups, target.Group):
+		step.Groups = append(step.Groups, target.Group)
+	}
+
+	return step
+}
+
+// reassignNotificationRules sends the email notification rules of the user
+// to the target user, or disables them if the target has no email. Without a
+// target the affected rules are only collected.
+func reassignNotificationRules(ctx context.Context, queries *sqlc.Queries, user *sqlc.User, target *Target, summary *Summary) error {
+	if user.Email == nil || *user.Email == "" {
+		return nil
+	}
+
+	rules, err := database.PaginateItems(ctx, func(ctx context.Context, offset, limit int64) ([]sqlc.ListNotificationRulesRow, error) {
+		return queries.ListNotificationRules(ctx, sqlc.ListNotificationRulesParams{Offset: offset, Limit: limit})
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list notification rules: %w", err)
+	}
+
+	var email string
+
+	if target != nil && target.User != "" {
+		targetUser, err := queries.GetUser(ctx, target.User)
+		if err != nil {
+			return err
+		}
+
+		if targetUser.Email != nil {
+			email = *targetUser.Email
+		}
+	}
+
+	for _, rule := range rules {
+		if rule.Channel != notification.EmailChannel || rule.Target != *user.Email {
+			continue
+		}
+
+		summary.NotificationRules = append(summary.NotificationRules, rule.ID)
+
+		if target == nil {
+			continue
+		}
+
+		update := sqlc.UpdateNotificationRuleParams{ID: rule.ID}
+		if email != "" {
+			update.Target = &email
+		} else {
+			update.Enabled = pointer.Pointer(false)
+		}
+
+		if _, err := queries.UpdateNotificationRule(ctx, update); err != nil {
+			return fmt.Errorf("failed to update notification rule %s: %w", rule.ID, err)
+		}
+	}
+
+	return nil
+}
+
+// collectPersonal collects the scheduled digest and the push subscriptions
+// of the user, which cannot be handed over, and removes them on apply.
+func collectPersonal(ctx context.Context, queries *sqlc.Queries, user *sqlc.User, apply bool, summary *Summary) error {
+	d, err := queries.GetDigest(ctx, user.ID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("failed to get digest: %w", err)
+	}
+
+	if err == nil && d.Frequency != digest.Off {
+		summary.Digest = true
+
+		if apply {
+			if _, err := queries.UpsertDigest(ctx, sqlc.UpsertDigestParams{User: user.ID, Frequency: digest.Off}); err != nil {
+				return fmt.Errorf("failed to turn off digest: %w", err)
+			}
+		}
+	}
+
+	subscriptions, err := queries.ListPushSubscriptions(ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to list push subscriptions: %w", err)
+	}
+
+	summary.PushSubscriptions = len(subscriptions)
+
+	if !apply {
+		return nil
+	}
+
+	for _, subscription := range subscriptions {
+		if err := queries.DeletePushSubscription(ctx, sqlc.DeletePushSubscriptionParams{ID: subscription.ID, User: user.ID}); err != nil {
+			return fmt.Errorf("failed to delete push subscription: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,175 @@
+package deactivation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/SecurityBrewery/catalyst/app/data"
+	"github.com/SecurityBrewery/catalyst/app/database/sqlc"
+	"github.com/SecurityBrewery/catalyst/app/escalation"
+)
+
+func setup(t *testing.T) (*sqlc.Queries, string, string) {
+	t.Helper()
+
+	ctx := t.Context()
+	queries := data.NewTestDB(t, t.TempDir())
+
+	policy, err := queries.CreateEscalationPolicy(ctx, sqlc.CreateEscalationPolicyParams{
+		Name:        "On call",
+		Filter:      []byte(`{}`),
+		Steps:       []byte(`[{"delay":0,"users":["u_bob_analyst"]},{"delay":30,"groups":["admin"]}]`),
+		PauseStates: []byte(`[]`),
+	})
+	require.NoError(t, err)
+
+	rule, err := queries.CreateNotificationRule(ctx, sqlc.CreateNotificationRuleParams{
+		Name:    "Critical tickets",
+		Enabled: true,
+		Filter:  []byte(`{}`),
+		Channel: "email",
+		Target:  data.AnalystEmail,
+	})
+	require.NoError(t, err)
+
+	_, err = queries.UpsertDigest(ctx, sqlc.UpsertDigestParams{User: "u_bob_analyst", Frequency: "daily"})
+	require.NoError(t, err)
+
+	_, err = queries.CreatePushSubscription(ctx, sqlc.CreatePushSubscriptionParams{User: "u_bob_analyst", Endpoint: "https://push.example.com/1", P256dh: "key", Auth: "auth"})
+	require.NoError(t, err)
+
+	return queries, policy.ID, rule.ID
+}
+
+func TestPreview(t *testing.T) {
+	t.Parallel()
+
+	queries, policyID, ruleID := setup(t)
+
+	summary, err := Preview(t.Context(), queries, "u_bob_analyst")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"test-ticket"}, summary.Tickets)
+	assert.Equal(t, []string{"k_test_task"}, summary.Tasks)
+	assert.Equal(t, []string{policyID}, summary.EscalationPolicies)
+	assert.Equal(t, []string{ruleID}, summary.NotificationRules)
+	assert.True(t, summary.Digest)
+	assert.Equal(t, 1, summary.PushSubscriptions)
+
+	// nothing is changed
+	user, err := queries.GetUser(t.Context(), "u_bob_analyst")
+	require.NoError(t, err)
+	assert.True(t, user.Active)
+}
+
+func TestDeactivate_user(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	queries, policyID, ruleID := setup(t)
+
+	before, err := queries.GetUser(ctx, "u_bob_analyst")
+	require.NoError(t, err)
+
+	summary, err := Deactivate(ctx, queries, "u_bob_analyst", Target{User: "u_admin"})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"test-ticket"}, summary.Tickets)
+	assert.Equal(t, []string{"k_test_task"}, summary.Tasks)
+	assert.Equal(t, []string{policyID}, summary.EscalationPolicies)
+	assert.Equal(t, []string{ruleID}, summary.NotificationRules)
+	assert.True(t, summary.Digest)
+	assert.Equal(t, 1, summary.PushSubscriptions)
+
+	user, err := queries.GetUser(ctx, "u_bob_analyst")
+	require.NoError(t, err)
+	assert.False(t, user.Active)
+	assert.NotEqual(t, before.Tokenkey, user.Tokenkey, "the token key is rotated to revoke all tokens")
+
+	ticket, err := queries.Ticket(ctx, "test-ticket")
+	require.NoError(t, err)
+	assert.Equal(t, "u_admin", *ticket.Owner)
+
+	task, err := queries.GetTask(ctx, "k_test_task")
+	require.NoError(t, err)
+	assert.Equal(t, "u_admin", *task.Owner)
+
+	policy, err := queries.GetEscalationPolicy(ctx, policyID)
+	require.NoError(t, err)
+
+	steps, err := escalation.ParseSteps(policy.Steps)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"u_admin"}, steps[0].Users)
+
+	rule, err := queries.GetNotificationRule(ctx, ruleID)
+	require.NoError(t, err)
+	assert.Equal(t, data.AdminEmail, rule.Target)
+	assert.True(t, rule.Enabled)
+
+	digest, err := queries.GetDigest(ctx, "u_bob_analyst")
+	require.NoError(t, err)
+	assert.Equal(t, "off", digest.Frequency)
+
+	subscriptions, err := queries.ListPushSubscriptions(ctx, "u_bob_analyst")
+	require.NoError(t, err)
+	assert.Empty(t, subscriptions)
+}
+
+func TestDeactivate_group(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	queries, policyID, ruleID := setup(t)
+
+	_, err := Deactivate(ctx, queries, "u_bob_analyst", Target{Group: "analyst"})
+	require.NoError(t, err)
+
+	// work handed to a team is unassigned, so it shows up in the queues
+	ticket, err := queries.Ticket(ctx, "test-ticket")
+	require.NoError(t, err)
+	assert.Nil(t, ticket.Owner)
+
+	policy, err := queries.GetEscalationPolicy(ctx, policyID)
+	require.NoError(t, err)
+
+	steps, err := escalation.ParseSteps(policy.Steps)
+	require.NoError(t, err)
+	assert.Empty(t, steps[0].Users)
+	assert.Equal(t, []string{"analyst"}, steps[0].Groups)
+	assert.Equal(t, []string{"admin"}, steps[1].Groups)
+
+	rule, err := queries.GetNotificationRule(ctx, ruleID)
+	require.NoError(t, err)
+	assert.False(t, rule.Enabled)
+}
+
+func TestDeactivate_invalidTarget(t *testing.T) {
+	t.Parallel()
+
+	queries := data.NewTestDB(t, t.TempDir())
+
+	tests := []struct {
+		name   string
+		user   string
+		target Target
+		want   error
+	}{
+		{"user and group", "u_bob_analyst", Target{User: "u_admin", Group: "admin"}, ErrTarget},
+		{"self", "u_bob_analyst", Target{User: "u_bob_analyst"}, ErrTarget},
+		{"unknown user", "u_bob_analyst", Target{User: "u_unknown"}, ErrTarget},
+		{"unknown group", "u_bob_analyst", Target{Group: "unknown"}, ErrTarget},
+		{"system user", "system", Target{User: "u_admin"}, ErrSystemUser},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Deactivate(t.Context(), queries, tt.user, tt.target)
+			require.ErrorIs(t, err, tt.want)
+		})
+	}
+
+	user, err := queries.GetUser(t.Context(), "u_bob_analyst")
+	require.NoError(t, err)
+	assert.True(t, user.Active)
+}
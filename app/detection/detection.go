@@ -0,0 +1,224 @@
+// Package detection implements the lifecycle of detection rules. A rule is
+// proposed on a ticket, reviewed by a second analyst and deployed to the
+// SIEM by a reaction that is triggered when the rule is approved. Alert
+// tickets raised by a deployed rule are linked to it, so the dispositions
+// of the closed alerts report how well the rule performs.
+package detection
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/SecurityBrewery/catalyst/app/database"
+	"github.com/SecurityBrewery/catalyst/app/database/sqlc"
+	"github.com/SecurityBrewery/catalyst/app/hook"
+	"github.com/SecurityBrewery/catalyst/app/openapi"
+	"github.com/SecurityBrewery/catalyst/app/pointer"
+)
+
+const (
+	StatusProposed = "proposed"
+	StatusReview   = "review"
+	StatusApproved = "approved"
+	StatusDeployed = "deployed"
+	StatusRejected = "rejected"
+	StatusRetired  = "retired"
+
+	// StateField is the field in the state of an alert ticket that names
+	// the rule that raised the alert, either by its ID or its name.
+	StateField = "detection_rule"
+)
+
+var (
+	ErrInvalidTransition = errors.New("invalid status transition")
+	ErrSelfReview        = errors.New("a rule must be approved by another user than its author")
+	ErrNotEditable       = errors.New("only proposed rules and rules in review can be changed")
+
+	// transitions are the statuses that can follow a status.
+	transitions = map[string][]string{
+		StatusProposed: {StatusReview, StatusRejected},
+		StatusReview:   {StatusApproved, StatusProposed, StatusRejected},
+		StatusApproved: {StatusDeployed, StatusReview},
+		StatusDeployed: {StatusRetired},
+		StatusRejected: {StatusProposed},
+		StatusRetired:  {StatusProposed},
+	}
+)
+
+// Transitions returns the statuses that can follow the status.
+func Transitions(status string) []string {
+	return transitions[status]
+}
+
+// Update changes the name, description, format or content of a rule. The
+// content of a rule can only change before it is approved, so deployed
+// rules are always the reviewed ones.
+func Update(ctx context.Context, queries *sqlc.Queries, params sqlc.UpdateDetectionRuleParams) (sqlc.DetectionRule, error) {
+	rule, err := queries.FindDetectionRule(ctx, params.ID)
+	if err != nil {
+		return sqlc.DetectionRule{}, err
+	}
+
+	if rule.Status != StatusProposed && rule.Status != StatusReview {
+		return sqlc.DetectionRule{}, fmt.Errorf("%w, the rule is %s", ErrNotEditable, rule.Status)
+	}
+
+	params.ID = rule.ID
+
+	return queries.UpdateDetectionRule(ctx, params)
+}
+
+// Transition moves a rule to a new status on behalf of a user and records
+// the change in the timeline of the ticket of the rule. The rule before
+// and after the transition are returned.
+func Transition(ctx context.Context, queries *sqlc.Queries, id, status string, user *sqlc.User, comment string) (before, after sqlc.DetectionRule, err error) {
+	before, err = queries.FindDetectionRule(ctx, id)
+	if err != nil {
+		return before, after, err
+	}
+
+	if !slices.Contains(transitions[before.Status], status) {
+		return before, after, fmt.Errorf("%w from %s to %s", ErrInvalidTransition, before.Status, status)
+	}
+
+	params := sqlc.SetDetectionRuleStatusParams{ID: before.ID, Status: status}
+
+	switch status {
+	case StatusApproved:
+		if before.Author != nil && *before.Author == user.ID {
+			return before, after, ErrSelfReview
+		}
+
+		params.Reviewer = &user.ID
+	case StatusDeployed:
+		deployed := time.Now().UTC()
+		params.Deployed = &deployed
+	}
+
+	after, err = queries.SetDetectionRuleStatus(ctx, params)
+	if err != nil {
+		return before, after, err
+	}
+
+	message := fmt.Sprintf("Detection rule %s changed from %s to %s by %s", after.Name, before.Status, status, cmp.Or(pointer.Dereference(user.Name), user.Username))
+	if comment != "" {
+		message += ": " + comment
+	}
+
+	if _, err := queries.CreateTimeline(ctx, sqlc.CreateTimelineParams{
+		Ticket:  after.Ticket,
+		Message: message,
+		Time:    time.Now().UTC(),
+	}); err != nil {
+		return before, after, err
+	}
+
+	return before, after, nil
+}
+
+// Link links an alert ticket to the rule with the given ID or name.
+func Link(ctx context.Context, queries *sqlc.Queries, rule, ticket string) error {
+	r, err := queries.FindDetectionRule(ctx, rule)
+	if err != nil {
+		return err
+	}
+
+	return queries.AddDetectionRuleTicket(ctx, sqlc.AddDetectionRuleTicketParams{Rule: r.ID, Ticket: ticket})
+}
+
+// BindHooks links new tickets to the rule named in the detection_rule field
+// of their state, which is set by the SIEM integration that creates alerts.
+func BindHooks(hooks *hook.Hooks, queries *sqlc.Queries) {
+	hooks.OnRecordAfterCreateRequest.Subscribe(func(ctx context.Context, table string, record any) {
+		if table != database.TicketsTable.ID {
+			return
+		}
+
+		ticket, ok := record.(openapi.Ticket)
+		if !ok || ticket.Encrypted {
+			return
+		}
+
+		rule, ok := ticket.State[StateField].(string)
+		if !ok || rule == "" {
+			return
+		}
+
+		if err := Link(ctx, queries, rule, ticket.Id); errors.Is(err, sql.ErrNoRows) {
+			slog.WarnContext(ctx, "Unknown detection rule in a ticket", "ticket", ticket.Id, "rule", rule)
+		} else if err != nil {
+			slog.ErrorContext(ctx, "Failed to link a ticket to its detection rule", "ticket", ticket.Id, "rule", rule, "error", err)
+		}
+	})
+}
+
+// Disposition classifies the resolution of a closed alert.
+type Disposition string
+
+const (
+	TruePositive  Disposition = "true_positive"
+	FalsePositive Disposition = "false_positive"
+	Other         Disposition = "other"
+)
+
+// Classify maps a free text resolution to a disposition, e.g. "False
+// positive" and "false-positive" are both false positives.
+func Classify(resolution string) Disposition {
+	normalized := strings.Join(strings.FieldsFunc(strings.ToLower(resolution), func(r rune) bool {
+		return r == ' ' || r == '-' || r == '_'
+	}), "_")
+
+	switch Disposition(normalized) {
+	case TruePositive, FalsePositive:
+		return Disposition(normalized)
+	default:
+		return Other
+	}
+}
+
+// Performance summarizes the closed alerts of a rule.
+type Performance struct {
+	Closed         int64
+	TruePositives  int64
+	FalsePositives int64
+	// Precision is the share of true positives of the classified alerts,
+	// nil if no alert was classified yet.
+	Precision    *float64
+	Dispositions []sqlc.ListDetectionRuleDispositionsRow
+}
+
+// Measure aggregates the dispositions of the closed alerts of a rule.
+func Measure(ctx context.Context, queries *sqlc.Queries, rule string) (*Performance, error) {
+	dispositions, err := queries.ListDetectionRuleDispositions(ctx, rule)
+	if err != nil {
+		return nil, err
+	}
+
+	performance := &Performance{Dispositions: dispositions}
+
+	for _, disposition := range dispositions {
+		performance.Closed += disposition.Count
+
+		switch Classify(disposition.Resolution) {
+		case TruePositive:
+			performance.TruePositives += disposition.Count
+		case FalsePositive:
+			performance.FalsePositives += disposition.Count
+		case Other:
+		}
+	}
+
+	if classified := performance.TruePositives + performance.FalsePositives; classified > 0 {
+		precision := float64(performance.TruePositives) / float64(classified)
+		performance.Precision = &precision
+	}
+
+	return performance, nil
+}
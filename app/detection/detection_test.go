@@ -0,0 +1,123 @@
+package detection
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/SecurityBrewery/catalyst/app/data"
+	"github.com/SecurityBrewery/catalyst/app/database"
+	"github.com/SecurityBrewery/catalyst/app/database/sqlc"
+	"github.com/SecurityBrewery/catalyst/app/hook"
+	"github.com/SecurityBrewery/catalyst/app/openapi"
+	"github.com/SecurityBrewery/catalyst/app/pointer"
+)
+
+func TestClassify(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, FalsePositive, Classify("False positive"))
+	assert.Equal(t, FalsePositive, Classify(" false-positive "))
+	assert.Equal(t, TruePositive, Classify("TRUE_POSITIVE"))
+	assert.Equal(t, Other, Classify("Duplicate"))
+	assert.Equal(t, Other, Classify(""))
+}
+
+func TestTransition(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	queries := data.NewTestDB(t, t.TempDir())
+
+	analyst, err := queries.GetUser(ctx, "u_bob_analyst")
+	require.NoError(t, err)
+
+	admin, err := queries.GetUser(ctx, "u_admin")
+	require.NoError(t, err)
+
+	rule, err := queries.CreateDetectionRule(ctx, sqlc.CreateDetectionRuleParams{
+		Ticket:  "test-ticket",
+		Name:    "Encoded PowerShell",
+		Format:  "sigma",
+		Content: "condition: selection",
+		Author:  &analyst.ID,
+	})
+	require.NoError(t, err)
+
+	_, _, err = Transition(ctx, queries, rule.ID, StatusDeployed, &analyst, "")
+	require.ErrorIs(t, err, ErrInvalidTransition)
+
+	_, _, err = Transition(ctx, queries, rule.ID, StatusReview, &analyst, "")
+	require.NoError(t, err)
+
+	_, _, err = Transition(ctx, queries, rule.ID, StatusApproved, &analyst, "")
+	require.ErrorIs(t, err, ErrSelfReview)
+
+	before, after, err := Transition(ctx, queries, rule.Name, StatusApproved, &admin, "looks good")
+	require.NoError(t, err)
+	assert.Equal(t, StatusReview, before.Status)
+	assert.Equal(t, StatusApproved, after.Status)
+	assert.Equal(t, pointer.Pointer(admin.ID), after.Reviewer)
+
+	_, err = Update(ctx, queries, sqlc.UpdateDetectionRuleParams{ID: rule.ID, Content: pointer.Pointer("condition: all")})
+	require.ErrorIs(t, err, ErrNotEditable)
+
+	_, after, err = Transition(ctx, queries, rule.ID, StatusDeployed, &admin, "")
+	require.NoError(t, err)
+	assert.NotNil(t, after.Deployed)
+
+	timeline, err := queries.ListTimeline(ctx, sqlc.ListTimelineParams{Ticket: "test-ticket", Limit: 100})
+	require.NoError(t, err)
+
+	var messages []string
+	for _, entry := range timeline {
+		messages = append(messages, entry.Message)
+	}
+
+	assert.Contains(t, messages, "Detection rule Encoded PowerShell changed from review to approved by Admin User: looks good")
+}
+
+func TestBindHooks(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	queries := data.NewTestDB(t, t.TempDir())
+	hooks := hook.NewHooks()
+
+	BindHooks(hooks, queries)
+
+	rule, err := queries.CreateDetectionRule(ctx, sqlc.CreateDetectionRuleParams{
+		Ticket:  "test-ticket",
+		Name:    "Encoded PowerShell",
+		Format:  "sigma",
+		Content: "condition: selection",
+	})
+	require.NoError(t, err)
+
+	for _, resolution := range []string{"True positive", "False positive", "false-positive", "Duplicate", ""} {
+		ticket, err := queries.CreateTicket(ctx, sqlc.CreateTicketParams{Name: "Alert", Open: true, Type: "alert", Schema: []byte(`{}`), State: []byte(`{}`)})
+		require.NoError(t, err)
+
+		hooks.OnRecordAfterCreateRequest.Publish(ctx, database.TicketsTable.ID, openapi.Ticket{
+			Id:    ticket.ID,
+			State: map[string]any{StateField: "Encoded PowerShell"},
+		})
+
+		if resolution != "" {
+			_, err = queries.UpdateTicket(ctx, sqlc.UpdateTicketParams{ID: ticket.ID, Open: pointer.Pointer(false), Resolution: &resolution})
+			require.NoError(t, err)
+		}
+	}
+
+	tickets, err := queries.ListDetectionRuleTickets(ctx, rule.ID)
+	require.NoError(t, err)
+	assert.Len(t, tickets, 5)
+
+	performance, err := Measure(ctx, queries, rule.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), performance.Closed)
+	assert.Equal(t, int64(1), performance.TruePositives)
+	assert.Equal(t, int64(2), performance.FalsePositives)
+	assert.InDelta(t, 1.0/3, *performance.Precision, 0.001)
+}
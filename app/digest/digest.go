@@ -0,0 +1,288 @@
+// Package digest sends users a daily or weekly summary email of their open
+// tickets, the task deadlines before the next digest and the comments that
+// mention them, instead of one email per event.
+package digest
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"fmt"
+	htmltemplate "html/template"
+	"log/slog"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/SecurityBrewery/catalyst/app/database/sqlc"
+	"github.com/SecurityBrewery/catalyst/app/pointer"
+	"github.com/SecurityBrewery/catalyst/app/settings"
+)
+
+const (
+	Off    = "off"
+	Daily  = "daily"
+	Weekly = "weekly"
+
+	checkInterval = time.Hour
+	// digests are sent on the first check after the interval passed, so
+	// allow them to be a bit early to avoid drifting by an hour each time
+	tolerance = 30 * time.Minute
+	maxItems  = 50
+)
+
+var intervals = map[string]time.Duration{
+	Daily:  24 * time.Hour,
+	Weekly: 7 * 24 * time.Hour,
+}
+
+const plainTextTemplate = `Hello {{ .User }},
+{{ if .Tickets }}
+Your open tickets:
+{{ range .Tickets }}- {{ .Name }}: {{ .URL }}
+{{ end }}{{ end }}{{ if .Deadlines }}
+Deadlines before your next digest:
+{{ range .Deadlines }}- {{ .Task }} in {{ .Ticket }} ({{ .URL }}) is due {{ .Due.Format "2006-01-02 15:04" }}
+{{ end }}{{ end }}{{ if .Mentions }}
+You were mentioned since {{ .Since.Format "2006-01-02 15:04" }}:
+{{ range .Mentions }}- {{ .Author }} in {{ .Ticket }} ({{ .URL }}): {{ .Message }}
+{{ end }}{{ end }}
+Thanks, {{ .AppName }} team
+`
+
+// Sender delivers the digest, usually the mail.Mailer.
+type Sender interface {
+	Send(ctx context.Context, to, subject, plainTextBody, htmlBody string) error
+}
+
+type Digest struct {
+	AppName   string
+	User      string
+	Frequency string
+	Since     time.Time
+	Tickets   []Ticket
+	Deadlines []Deadline
+	Mentions  []Mention
+}
+
+type Ticket struct {
+	Name string
+	URL  string
+}
+
+// Deadline is a timer of a task that the user owns, or of a task of a
+// ticket the user owns, which fires before the next digest and then
+// completes, fails or escalates the task.
+type Deadline struct {
+	Task   string
+	Ticket string
+	URL    string
+	Due    time.Time
+}
+
+type Mention struct {
+	Ticket  string
+	URL     string
+	Author  string
+	Message string
+}
+
+type Digester struct {
+	queries *sqlc.Queries
+	sender  Sender
+	now     func() time.Time
+}
+
+func New(queries *sqlc.Queries, sender Sender) *Digester {
+	return &Digester{
+		queries: queries,
+		sender:  sender,
+		now:     time.Now,
+	}
+}
+
+// ValidFrequency reports whether frequency is off, daily or weekly.
+func ValidFrequency(frequency string) bool {
+	_, ok := intervals[frequency]
+
+	return ok || frequency == Off
+}
+
+// Start checks for due digests every hour until the context is canceled.
+func (d *Digester) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(checkInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := d.SendDue(ctx); err != nil {
+					slog.ErrorContext(ctx, "Failed to send digests", "error", err)
+				}
+			}
+		}
+	}()
+}
+
+// SendDue sends the digests of all users whose interval passed.
+func (d *Digester) SendDue(ctx context.Context) error {
+	digests, err := d.queries.ListActiveDigests(ctx)
+	if err != nil {
+		return err
+	}
+
+	se, err := settings.Load(ctx, d.queries)
+	if err != nil {
+		return err
+	}
+
+	now := d.now()
+
+	for _, digest := range digests {
+		interval, ok := intervals[digest.Frequency]
+		if !ok {
+			continue
+		}
+
+		since := now.Add(-interval)
+		if digest.LastSent != nil {
+			if now.Before(digest.LastSent.Add(interval - tolerance)) {
+				continue
+			}
+
+			since = *digest.LastSent
+		}
+
+		if err := d.send(ctx, se, &digest, since, now.Add(interval)); err != nil {
+			slog.ErrorContext(ctx, "Failed to send digest", "user", digest.User, "error", err)
+
+			continue
+		}
+
+		if err := d.queries.UpdateDigestSent(ctx, sqlc.UpdateDigestSentParams{User: digest.User, LastSent: &now}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (d *Digester) send(ctx context.Context, se *settings.Settings, digest *sqlc.ListActiveDigestsRow, since, until time.Time) error {
+	data, err := d.collect(ctx, se, digest, since, until)
+	if err != nil {
+		return err
+	}
+
+	// nothing to report, skip the mail
+	if len(data.Tickets) == 0 && len(data.Deadlines) == 0 && len(data.Mentions) == 0 {
+		return nil
+	}
+
+	subject, plainTextBody, htmlBody, err := render(&se.Meta.DigestTemplate, data)
+	if err != nil {
+		return err
+	}
+
+	return d.sender.Send(ctx, *digest.Email, subject, plainTextBody, htmlBody)
+}
+
+func (d *Digester) collect(ctx context.Context, se *settings.Settings, digest *sqlc.ListActiveDigestsRow, since, until time.Time) (*Digest, error) {
+	appURL := strings.TrimSuffix(se.Meta.AppURL, "/")
+
+	data := &Digest{
+		AppName:   se.Meta.AppName,
+		User:      digest.Username,
+		Frequency: digest.Frequency,
+		Since:     since,
+	}
+
+	if digest.Name != nil && *digest.Name != "" {
+		data.User = *digest.Name
+	}
+
+	tickets, err := d.queries.ListOpenTicketsByOwner(ctx, sqlc.ListOpenTicketsByOwnerParams{Owner: &digest.User, Limit: maxItems})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tickets: %w", err)
+	}
+
+	for _, ticket := range tickets {
+		data.Tickets = append(data.Tickets, Ticket{
+			Name: ticket.Name,
+			URL:  appURL + "/ui/tickets/" + ticket.Type + "/" + ticket.ID,
+		})
+	}
+
+	timers, err := d.queries.ListApproachingTaskTimers(ctx, sqlc.ListApproachingTaskTimersParams{
+		User:  &digest.User,
+		Until: until.UTC().Format(time.DateTime),
+		Limit: maxItems,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list task timers: %w", err)
+	}
+
+	for _, timer := range timers {
+		data.Deadlines = append(data.Deadlines, Deadline{
+			Task:   timer.TaskName,
+			Ticket: timer.TicketName,
+			URL:    appURL + "/ui/tickets/" + timer.TicketType + "/" + timer.Ticket,
+			Due:    timer.Due,
+		})
+	}
+
+	mentions, err := d.queries.ListMentions(ctx, sqlc.ListMentionsParams{
+		Username: digest.Username,
+		Since:    since.UTC().Format(time.DateTime),
+		Limit:    maxItems,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list mentions: %w", err)
+	}
+
+	for _, mention := range mentions {
+		// LIKE treats the underscores of usernames as wildcards
+		if !strings.Contains(mention.Message, "@"+digest.Username) {
+			continue
+		}
+
+		data.Mentions = append(data.Mentions, Mention{
+			Ticket:  mention.TicketName,
+			URL:     appURL + "/ui/tickets/" + mention.TicketType + "/" + mention.Ticket,
+			Author:  cmp.Or(pointer.Dereference(mention.AuthorName), "Someone"),
+			Message: mention.Message,
+		})
+	}
+
+	return data, nil
+}
+
+func render(emailTemplate *settings.EmailTemplate, data *Digest) (subject, plainTextBody, htmlBody string, err error) {
+	subjectTemplate, err := template.New("subject").Parse(cmp.Or(emailTemplate.Subject, settings.DigestTemplateSubject))
+	if err != nil {
+		return "", "", "", fmt.Errorf("invalid digest subject template: %w", err)
+	}
+
+	htmlTemplate, err := htmltemplate.New("body").Parse(cmp.Or(emailTemplate.Body, settings.DigestTemplateBody))
+	if err != nil {
+		return "", "", "", fmt.Errorf("invalid digest body template: %w", err)
+	}
+
+	var subjectBuffer, plainTextBuffer, htmlBuffer bytes.Buffer
+
+	if err := subjectTemplate.Execute(&subjectBuffer, data); err != nil {
+		return "", "", "", err
+	}
+
+	if err := template.Must(template.New("plain").Parse(plainTextTemplate)).Execute(&plainTextBuffer, data); err != nil {
+		return "", "", "", err
+	}
+
+	if err := htmlTemplate.Execute(&htmlBuffer, data); err != nil {
+		return "", "", "", err
+	}
+
+	return subjectBuffer.String(), plainTextBuffer.String(), htmlBuffer.String(), nil
+}
@@ -0,0 +1,126 @@
+package digest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/SecurityBrewery/catalyst/app/data"
+	"github.com/SecurityBrewery/catalyst/app/database/sqlc"
+	"github.com/SecurityBrewery/catalyst/app/settings"
+)
+
+type mail struct {
+	to, subject, plainTextBody, htmlBody string
+}
+
+type fakeSender struct {
+	mails []mail
+}
+
+func (f *fakeSender) Send(_ context.Context, to, subject, plainTextBody, htmlBody string) error {
+	f.mails = append(f.mails, mail{to: to, subject: subject, plainTextBody: plainTextBody, htmlBody: htmlBody})
+
+	return nil
+}
+
+func TestDigester_SendDue(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	queries := data.NewTestDB(t, t.TempDir())
+	sender := &fakeSender{}
+
+	now := time.Now()
+	d := New(queries, sender)
+	d.now = func() time.Time { return now }
+
+	_, err := queries.UpsertDigest(ctx, sqlc.UpsertDigestParams{User: "u_bob_analyst", Frequency: Weekly})
+	require.NoError(t, err)
+
+	_, err = queries.CreateComment(ctx, sqlc.CreateCommentParams{
+		Ticket:  "test-ticket",
+		Author:  "u_admin",
+		Message: "@u_bob_analyst please check the <script> tag",
+	})
+	require.NoError(t, err)
+
+	owner := "u_bob_analyst"
+
+	for name, due := range map[string]time.Time{"Contain host": now.Add(48 * time.Hour), "Write report": now.Add(30 * 24 * time.Hour)} {
+		task, err := queries.CreateTask(ctx, sqlc.CreateTaskParams{Name: name, Open: true, Owner: &owner, Ticket: "test-ticket"})
+		require.NoError(t, err)
+
+		_, err = queries.SetTaskTimer(ctx, sqlc.SetTaskTimerParams{Task: task.ID, Due: due, Action: "escalate"})
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, d.SendDue(ctx))
+	require.Len(t, sender.mails, 1)
+
+	digest := sender.mails[0]
+	assert.Equal(t, data.AnalystEmail, digest.to)
+	assert.Equal(t, "Your weekly Catalyst digest", digest.subject)
+	assert.Contains(t, digest.htmlBody, "Hello Bob Analyst")
+	assert.Contains(t, digest.htmlBody, "/ui/tickets/incident/test-ticket")
+	assert.Contains(t, digest.htmlBody, "Admin User in")
+	assert.Contains(t, digest.htmlBody, "&lt;script&gt;")
+	assert.Contains(t, digest.plainTextBody, "@u_bob_analyst please check the <script> tag")
+	assert.Contains(t, digest.htmlBody, "Contain host in")
+	assert.NotContains(t, digest.htmlBody, "Write report", "deadlines after the next digest are left out")
+	assert.Contains(t, digest.plainTextBody, "Contain host in")
+
+	// not due again before a week passed
+	now = now.Add(24 * time.Hour)
+	require.NoError(t, d.SendDue(ctx))
+	assert.Len(t, sender.mails, 1)
+
+	now = now.Add(6 * 24 * time.Hour)
+	require.NoError(t, d.SendDue(ctx))
+	require.Len(t, sender.mails, 2)
+	assert.NotContains(t, sender.mails[1].htmlBody, "mentioned", "mentions are only reported once")
+
+	_, err = queries.UpsertDigest(ctx, sqlc.UpsertDigestParams{User: "u_bob_analyst", Frequency: Off})
+	require.NoError(t, err)
+
+	now = now.Add(30 * 24 * time.Hour)
+	require.NoError(t, d.SendDue(ctx))
+	assert.Len(t, sender.mails, 2)
+}
+
+func Test_render(t *testing.T) {
+	t.Parallel()
+
+	data := &Digest{
+		AppName:   "Catalyst",
+		User:      "Bob",
+		Frequency: Daily,
+		Tickets:   []Ticket{{Name: "Phishing", URL: "https://catalyst.example.com/ui/tickets/alert/t_1"}},
+	}
+
+	subject, plainTextBody, htmlBody, err := render(&settings.EmailTemplate{}, data)
+	require.NoError(t, err)
+	assert.Equal(t, "Your daily Catalyst digest", subject)
+	assert.Contains(t, plainTextBody, "- Phishing: https://catalyst.example.com/ui/tickets/alert/t_1")
+	assert.Contains(t, htmlBody, `<a href="https://catalyst.example.com/ui/tickets/alert/t_1">Phishing</a>`)
+
+	subject, _, htmlBody, err = render(&settings.EmailTemplate{Subject: "Digest for {{ .User }}", Body: "{{ len .Tickets }} tickets"}, data)
+	require.NoError(t, err)
+	assert.Equal(t, "Digest for Bob", subject)
+	assert.Equal(t, "1 tickets", htmlBody)
+
+	_, _, _, err = render(&settings.EmailTemplate{Body: "{{ .Unknown"}, data)
+	require.Error(t, err)
+}
+
+func TestValidFrequency(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, ValidFrequency(Off))
+	assert.True(t, ValidFrequency(Daily))
+	assert.True(t, ValidFrequency(Weekly))
+	assert.False(t, ValidFrequency("hourly"))
+}
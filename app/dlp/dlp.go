@@ -0,0 +1,198 @@
+// Package dlp enforces the data loss prevention rules of the settings.
+// Exports check the tickets they contain against the rules before any data
+// leaves the instance, and blocked attempts are recorded for audits.
+package dlp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"github.com/SecurityBrewery/catalyst/app/auth/usercontext"
+	"github.com/SecurityBrewery/catalyst/app/database"
+	"github.com/SecurityBrewery/catalyst/app/database/sqlc"
+	"github.com/SecurityBrewery/catalyst/app/settings"
+)
+
+// The kinds of exports the rules can be limited to.
+const (
+	ExportCSV    = "csv"
+	ExportBackup = "backup"
+	ExportReport = "report"
+)
+
+var (
+	ErrBlocked     = errors.New("the export is blocked by a data loss prevention rule")
+	ErrInvalidRule = errors.New("invalid data loss prevention rule")
+)
+
+// Exports returns the kinds of exports.
+func Exports() []string {
+	return []string{ExportCSV, ExportBackup, ExportReport}
+}
+
+// Validate checks that the rules have a name, match something and only
+// name known kinds of exports.
+func Validate(rules []settings.DLPRule) error {
+	for i, rule := range rules {
+		if rule.Name == "" {
+			return fmt.Errorf("%w: rule %d has no name", ErrInvalidRule, i+1)
+		}
+
+		if len(rule.TLP) == 0 && len(rule.Tags) == 0 {
+			return fmt.Errorf("%w: rule %s matches no TLP level or tag", ErrInvalidRule, rule.Name)
+		}
+
+		for _, export := range rule.Exports {
+			if !slices.Contains(Exports(), export) {
+				return fmt.Errorf("%w: rule %s has the unknown export %s", ErrInvalidRule, rule.Name, export)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Check returns ErrBlocked if one of the tickets matches a rule that
+// applies to the export, all tickets are checked if none are given. The
+// blocked attempt is recorded with the user of the context, scheduled
+// exports are recorded without a user.
+func Check(ctx context.Context, queries *sqlc.Queries, export string, tickets ...string) error {
+	se, err := settings.Load(ctx, queries)
+	if err != nil {
+		return err
+	}
+
+	var rules []settings.DLPRule
+
+	for _, rule := range se.DLP.Rules {
+		if len(rule.Exports) == 0 || slices.Contains(rule.Exports, export) {
+			rules = append(rules, rule)
+		}
+	}
+
+	if len(rules) == 0 {
+		return nil
+	}
+
+	states, err := ticketStates(ctx, queries, tickets)
+	if err != nil {
+		return err
+	}
+
+	for _, ticket := range states {
+		for _, rule := range rules {
+			if !Matches(&rule, ticket.state) {
+				continue
+			}
+
+			var userID *string
+			if user, ok := usercontext.UserFromContext(ctx); ok {
+				userID = &user.ID
+			}
+
+			if err := queries.CreateDLPEvent(ctx, sqlc.CreateDLPEventParams{
+				Rule:   rule.Name,
+				Export: export,
+				Ticket: ticket.id,
+				User:   userID,
+			}); err != nil {
+				slog.ErrorContext(ctx, "Failed to record a blocked export", "rule", rule.Name, "ticket", ticket.id, "error", err)
+			}
+
+			return fmt.Errorf("%w %s, ticket %s", ErrBlocked, rule.Name, ticket.id)
+		}
+	}
+
+	return nil
+}
+
+type ticketState struct {
+	id    string
+	state map[string]any
+}
+
+// ticketStates loads the states of the tickets. The content of encrypted
+// tickets is unknown, so they are skipped.
+func ticketStates(ctx context.Context, queries *sqlc.Queries, ids []string) ([]ticketState, error) {
+	var states []ticketState
+
+	add := func(id string, state []byte, encrypted bool) error {
+		if encrypted {
+			return nil
+		}
+
+		var m map[string]any
+		if err := json.Unmarshal(state, &m); err != nil {
+			return fmt.Errorf("invalid state of ticket %s: %w", id, err)
+		}
+
+		states = append(states, ticketState{id: id, state: m})
+
+		return nil
+	}
+
+	if len(ids) > 0 {
+		for _, id := range ids {
+			ticket, err := queries.Ticket(ctx, id)
+			if err != nil {
+				return nil, err
+			}
+
+			if err := add(ticket.ID, ticket.State, ticket.Encrypted); err != nil {
+				return nil, err
+			}
+		}
+
+		return states, nil
+	}
+
+	tickets, err := database.PaginateItems(ctx, func(ctx context.Context, offset, limit int64) ([]sqlc.ListTicketsRow, error) {
+		return queries.ListTickets(ctx, sqlc.ListTicketsParams{Offset: offset, Limit: limit})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, ticket := range tickets {
+		if err := add(ticket.ID, ticket.State, ticket.Encrypted); err != nil {
+			return nil, err
+		}
+	}
+
+	return states, nil
+}
+
+// Matches reports whether a ticket state matches the rule. The TLP level is
+// read from the state field "tlp" with or without the "TLP:" prefix, tags
+// from the list in the state field "tags".
+func Matches(rule *settings.DLPRule, state map[string]any) bool {
+	if tlp, ok := state["tlp"].(string); ok && tlp != "" {
+		if slices.ContainsFunc(rule.TLP, func(level string) bool { return strings.EqualFold(normalizeTLP(level), normalizeTLP(tlp)) }) {
+			return true
+		}
+	}
+
+	tags, _ := state["tags"].([]any)
+
+	for _, tag := range tags {
+		if tag, ok := tag.(string); ok && slices.ContainsFunc(rule.Tags, func(t string) bool { return strings.EqualFold(t, tag) }) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func normalizeTLP(level string) string {
+	level = strings.TrimSpace(level)
+	if len(level) > 4 && strings.EqualFold(level[:4], "tlp:") {
+		level = level[4:]
+	}
+
+	return level
+}
@@ -0,0 +1,71 @@
+package dlp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/SecurityBrewery/catalyst/app/auth/usercontext"
+	"github.com/SecurityBrewery/catalyst/app/data"
+	"github.com/SecurityBrewery/catalyst/app/database/sqlc"
+	"github.com/SecurityBrewery/catalyst/app/settings"
+)
+
+func TestMatches(t *testing.T) {
+	t.Parallel()
+
+	rule := &settings.DLPRule{Name: "restricted", TLP: []string{"TLP:RED"}, Tags: []string{"Restricted"}}
+
+	assert.True(t, Matches(rule, map[string]any{"tlp": "red"}))
+	assert.True(t, Matches(rule, map[string]any{"tlp": "TLP:RED"}))
+	assert.True(t, Matches(rule, map[string]any{"tags": []any{"phishing", "restricted"}}))
+	assert.False(t, Matches(rule, map[string]any{"tlp": "AMBER", "tags": []any{"phishing"}}))
+	assert.False(t, Matches(rule, map[string]any{}))
+}
+
+func TestValidate(t *testing.T) {
+	t.Parallel()
+
+	require.NoError(t, Validate([]settings.DLPRule{{Name: "red", TLP: []string{"RED"}, Exports: []string{ExportCSV}}}))
+	require.ErrorIs(t, Validate([]settings.DLPRule{{TLP: []string{"RED"}}}), ErrInvalidRule)
+	require.ErrorIs(t, Validate([]settings.DLPRule{{Name: "empty"}}), ErrInvalidRule)
+	require.ErrorIs(t, Validate([]settings.DLPRule{{Name: "stix", TLP: []string{"RED"}, Exports: []string{"stix"}}}), ErrInvalidRule)
+}
+
+func TestCheck(t *testing.T) {
+	t.Parallel()
+
+	ctx := usercontext.UserContext(t.Context(), &sqlc.User{ID: "u_admin"})
+	queries := data.NewTestDB(t, t.TempDir())
+
+	_, err := settings.Update(ctx, queries, func(se *settings.Settings) {
+		se.DLP.Rules = []settings.DLPRule{
+			{Name: "No amber backups", TLP: []string{"AMBER"}, Exports: []string{ExportBackup}},
+			{Name: "Restricted", Tags: []string{"restricted"}},
+		}
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, Check(ctx, queries, ExportCSV))
+	require.ErrorIs(t, Check(ctx, queries, ExportBackup), ErrBlocked)
+
+	ticket, err := queries.CreateTicket(ctx, sqlc.CreateTicketParams{Name: "Leak", Open: true, Type: "incident", Schema: []byte(`{}`), State: []byte(`{"tags": ["Restricted"]}`)})
+	require.NoError(t, err)
+
+	require.ErrorIs(t, Check(t.Context(), queries, ExportCSV), ErrBlocked)
+	require.NoError(t, Check(ctx, queries, ExportCSV, "test-ticket"))
+
+	events, err := queries.ListDLPEvents(ctx, sqlc.ListDLPEventsParams{Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+
+	assert.Equal(t, "Restricted", events[0].Rule)
+	assert.Equal(t, ExportCSV, events[0].Export)
+	assert.Equal(t, ticket.ID, events[0].Ticket)
+	assert.Nil(t, events[0].User)
+
+	assert.Equal(t, "No amber backups", events[1].Rule)
+	assert.Equal(t, "test-ticket", events[1].Ticket)
+	assert.Equal(t, "Admin User", *events[1].UserName)
+}
@@ -0,0 +1,310 @@
+// Package docx writes Word documents for legal and management workflows
+// that need editable documents rather than PDFs. Documents are built on a
+// template: all parts of the template, like styles, headers, footers and
+// the page layout, are kept and only the body of the document is replaced.
+// The content uses the styles Title, Subtitle, Heading1, Heading2 and
+// TableGrid, so a template restyles the document by defining them.
+package docx
+
+import (
+	"archive/zip"
+	"bytes"
+	"embed"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"strings"
+)
+
+// ContentType is the media type of Word documents.
+const ContentType = "application/vnd.openxmlformats-officedocument.wordprocessingml.document"
+
+const (
+	documentPart = "word/document.xml"
+	stylesPart   = "word/styles.xml"
+
+	maxPartSize = 32 << 20
+
+	// textWidth is the width of the text on A4 pages with the margins of
+	// the default template in twentieths of a point.
+	textWidth = 9072
+)
+
+var ErrInvalidTemplate = errors.New("invalid DOCX template")
+
+//go:embed all:template
+var defaultTemplate embed.FS
+
+type part struct {
+	name    string
+	content []byte
+}
+
+// Document is a Word document that is written on a template.
+type Document struct {
+	parts []part
+	root  string
+	tail  string
+	body  bytes.Buffer
+}
+
+// New starts a document on the template, the default template is used if
+// the template is empty.
+func New(template []byte) (*Document, error) {
+	parts, err := templateParts(template)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &Document{parts: parts}
+
+	for _, p := range parts {
+		if p.name == documentPart {
+			if d.root, d.tail, err = splitBody(p.content); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if d.root == "" {
+		return nil, fmt.Errorf("%w: missing %s", ErrInvalidTemplate, documentPart)
+	}
+
+	return d, nil
+}
+
+// ValidateTemplate checks that the template is a Word document or template
+// with styles.
+func ValidateTemplate(template []byte) error {
+	_, err := New(template)
+
+	return err
+}
+
+func templateParts(template []byte) ([]part, error) {
+	if len(template) == 0 {
+		return embeddedParts()
+	}
+
+	archive, err := zip.NewReader(bytes.NewReader(template), int64(len(template)))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidTemplate, err)
+	}
+
+	var (
+		parts  []part
+		styles bool
+	)
+
+	for _, file := range archive.File {
+		if file.FileInfo().IsDir() {
+			continue
+		}
+
+		content, err := readPart(file)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidTemplate, err)
+		}
+
+		// a Word template (.dotx) becomes a document
+		if file.Name == "[Content_Types].xml" {
+			content = bytes.ReplaceAll(content, []byte("wordprocessingml.template.main+xml"), []byte("wordprocessingml.document.main+xml"))
+		}
+
+		styles = styles || file.Name == stylesPart
+
+		parts = append(parts, part{name: file.Name, content: content})
+	}
+
+	if !styles {
+		return nil, fmt.Errorf("%w: missing %s", ErrInvalidTemplate, stylesPart)
+	}
+
+	return parts, nil
+}
+
+func readPart(file *zip.File) ([]byte, error) {
+	if file.UncompressedSize64 > maxPartSize {
+		return nil, fmt.Errorf("%s is too large", file.Name)
+	}
+
+	r, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	return io.ReadAll(io.LimitReader(r, maxPartSize))
+}
+
+func embeddedParts() ([]part, error) {
+	var parts []part
+
+	err := fs.WalkDir(defaultTemplate, "template", func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+
+		content, err := defaultTemplate.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		parts = append(parts, part{name: strings.TrimPrefix(path, "template/"), content: content})
+
+		return nil
+	})
+
+	return parts, err
+}
+
+// splitBody splits the main part of the template into everything up to the
+// start of the body and the section properties with the page layout at the
+// end of the body. The content of the template body is dropped.
+func splitBody(document []byte) (root, tail string, err error) {
+	s := string(document)
+
+	start := strings.Index(s, "<w:body>")
+	end := strings.LastIndex(s, "</w:body>")
+
+	if start < 0 || end < start {
+		return "", "", fmt.Errorf("%w: %s has no body", ErrInvalidTemplate, documentPart)
+	}
+
+	root = s[:start+len("<w:body>")]
+	tail = s[end:]
+
+	if section := strings.LastIndex(s[:end], "<w:sectPr"); section > start {
+		tail = s[section:]
+	}
+
+	return root, tail, nil
+}
+
+// Title adds the title of the document.
+func (d *Document) Title(text string) {
+	d.paragraph("Title", text)
+}
+
+// Subtitle adds a line below the title, e.g. when and by whom the document
+// was generated.
+func (d *Document) Subtitle(text string) {
+	d.paragraph("Subtitle", text)
+}
+
+// Heading adds a section heading.
+func (d *Document) Heading(text string) {
+	d.paragraph("Heading1", text)
+}
+
+// Subheading adds a heading within a section.
+func (d *Document) Subheading(text string) {
+	d.paragraph("Heading2", text)
+}
+
+// Paragraph adds text, line breaks are kept.
+func (d *Document) Paragraph(text string) {
+	d.paragraph("", text)
+}
+
+func (d *Document) paragraph(style, text string) {
+	d.body.WriteString("<w:p>")
+
+	if style != "" {
+		d.body.WriteString(`<w:pPr><w:pStyle w:val="` + style + `"/></w:pPr>`)
+	}
+
+	d.run(text)
+	d.body.WriteString("</w:p>")
+}
+
+func (d *Document) run(text string) {
+	d.body.WriteString("<w:r>")
+
+	for i, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		if i > 0 {
+			d.body.WriteString("<w:br/>")
+		}
+
+		d.body.WriteString(`<w:t xml:space="preserve">`)
+		_ = xml.EscapeText(&d.body, []byte(line))
+		d.body.WriteString("</w:t>")
+	}
+
+	d.body.WriteString("</w:r>")
+}
+
+// Table adds a table with a header row that is repeated on every page.
+func (d *Document) Table(header []string, rows [][]string) {
+	width := textWidth / max(len(header), 1)
+
+	d.body.WriteString(`<w:tbl><w:tblPr><w:tblStyle w:val="TableGrid"/><w:tblW w:w="5000" w:type="pct"/>`)
+	d.body.WriteString(`<w:tblLook w:val="04A0" w:firstRow="1" w:lastRow="0" w:firstColumn="0" w:lastColumn="0" w:noHBand="1" w:noVBand="1"/></w:tblPr>`)
+	d.body.WriteString("<w:tblGrid>")
+
+	for range header {
+		fmt.Fprintf(&d.body, `<w:gridCol w:w="%d"/>`, width)
+	}
+
+	d.body.WriteString("</w:tblGrid>")
+
+	d.row(header, true)
+
+	for _, row := range rows {
+		d.row(row, false)
+	}
+
+	// a paragraph keeps consecutive tables apart
+	d.body.WriteString("</w:tbl><w:p/>")
+}
+
+func (d *Document) row(cells []string, header bool) {
+	d.body.WriteString("<w:tr>")
+
+	if header {
+		d.body.WriteString("<w:trPr><w:tblHeader/></w:trPr>")
+	}
+
+	for _, cell := range cells {
+		d.body.WriteString("<w:tc><w:p>")
+		d.run(cell)
+		d.body.WriteString("</w:p></w:tc>")
+	}
+
+	d.body.WriteString("</w:tr>")
+}
+
+// Write writes the document as DOCX file.
+func (d *Document) Write(w io.Writer) error {
+	archive := zip.NewWriter(w)
+
+	for _, p := range d.parts {
+		f, err := archive.Create(p.name)
+		if err != nil {
+			return err
+		}
+
+		content := p.content
+		if p.name == documentPart {
+			content = []byte(d.root + d.body.String() + d.tail)
+		}
+
+		if _, err := f.Write(content); err != nil {
+			return err
+		}
+	}
+
+	return archive.Close()
+}
+
+// Bytes returns the document as DOCX file.
+func (d *Document) Bytes() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := d.Write(&buf); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
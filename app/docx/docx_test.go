@@ -0,0 +1,171 @@
+package docx
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func parts(t *testing.T, document []byte) map[string]string {
+	t.Helper()
+
+	archive, err := zip.NewReader(bytes.NewReader(document), int64(len(document)))
+	require.NoError(t, err)
+
+	result := map[string]string{}
+
+	for _, file := range archive.File {
+		r, err := file.Open()
+		require.NoError(t, err)
+
+		b, err := io.ReadAll(r)
+		require.NoError(t, err)
+		require.NoError(t, r.Close())
+
+		result[file.Name] = string(b)
+	}
+
+	return result
+}
+
+func wellFormed(t *testing.T, s string) {
+	t.Helper()
+
+	decoder := xml.NewDecoder(bytes.NewBufferString(s))
+
+	for {
+		_, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			return
+		}
+
+		require.NoError(t, err)
+	}
+}
+
+func TestTicketDocument(t *testing.T) {
+	t.Parallel()
+
+	document, err := TicketDocument(nil, &Ticket{
+		ID:          "test-ticket",
+		Key:         "IR-2024-0001",
+		Name:        "Phishing <urgent> & more",
+		Type:        "Incident",
+		Open:        true,
+		Description: "First line\nsecond line\n\nNew paragraph",
+		State:       map[string]any{"tlp": "AMBER", "tags": []any{"phishing", "mail"}},
+		Tasks:       []Task{{Name: "Block sender", Owner: "Bob Analyst", Open: false}},
+		Comments:    []Comment{{Author: "Bob Analyst", Message: "Sender blocked"}},
+		Files:       []File{{Name: "mail.eml", Size: 2048}},
+		Timeline:    []Event{{Message: "Mail reported"}},
+		Links:       []Link{{Name: "Mail", URL: "https://example.com/?a=1&b=2"}},
+	}, "Admin User (u_admin)", time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+
+	files := parts(t, document)
+	require.Contains(t, files, "[Content_Types].xml")
+	require.Contains(t, files, stylesPart)
+
+	body := files[documentPart]
+	wellFormed(t, body)
+
+	assert.Contains(t, body, `<w:pStyle w:val="Title"/></w:pPr><w:r><w:t xml:space="preserve">IR-2024-0001 Phishing &lt;urgent&gt; &amp; more</w:t>`)
+	assert.Contains(t, body, "Generated 2024-03-01 12:00 UTC by Admin User (u_admin)")
+	assert.Contains(t, body, `First line</w:t><w:br/><w:t xml:space="preserve">second line`)
+	assert.Contains(t, body, "phishing, mail")
+	assert.Contains(t, body, "Block sender")
+	assert.Contains(t, body, "mail.eml")
+	assert.Contains(t, body, "2.0 KB")
+	assert.Contains(t, body, "https://example.com/?a=1&amp;b=2")
+	assert.Contains(t, body, `<w:pgSz w:w="11906" w:h="16838"/>`)
+}
+
+func TestReportDocument(t *testing.T) {
+	t.Parallel()
+
+	document, err := ReportDocument(nil, &Report{
+		Since:        time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+		Acknowledged: 1,
+		MTTA:         90 * time.Minute,
+		Tickets: []ReportTicket{
+			{ID: "t1", Key: "IR-2024-0001", Name: "Phishing", Type: "Incident", Open: true},
+			{ID: "t2", Name: "Malware", Type: "Incident"},
+			{ID: "t3", Name: "Question", Type: "Request"},
+		},
+	}, "Admin User (u_admin)", time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+
+	body := parts(t, document)[documentPart]
+	wellFormed(t, body)
+
+	assert.Contains(t, body, "Tickets created from 2024-02-01 00:00 UTC to 2024-03-01 12:00 UTC")
+	assert.Contains(t, body, "1.5 hours")
+	assert.Contains(t, body, `<w:t xml:space="preserve">Incident</w:t></w:r></w:p></w:tc><w:tc><w:p><w:r><w:t xml:space="preserve">2</w:t></w:r></w:p></w:tc><w:tc><w:p><w:r><w:t xml:space="preserve">1</w:t>`)
+	assert.Contains(t, body, "IR-2024-0001")
+	assert.Contains(t, body, "t2")
+}
+
+func template(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+
+	archive := zip.NewWriter(&buf)
+
+	for name, content := range files {
+		f, err := archive.Create(name)
+		require.NoError(t, err)
+
+		_, err = f.Write([]byte(content))
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, archive.Close())
+
+	return buf.Bytes()
+}
+
+func TestNew_Template(t *testing.T) {
+	t.Parallel()
+
+	custom := template(t, map[string]string{
+		"[Content_Types].xml":          `<Types><Override PartName="/word/document.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.template.main+xml"/></Types>`,
+		"word/styles.xml":              `<w:styles><w:style w:styleId="Title"><w:name w:val="Corporate Title"/></w:style></w:styles>`,
+		"word/header1.xml":             `<w:hdr><w:p><w:r><w:t>ACME Corp - Confidential</w:t></w:r></w:p></w:hdr>`,
+		"word/_rels/document.xml.rels": `<Relationships/>`,
+		documentPart:                   `<w:document xmlns:w="w" xmlns:r="r"><w:body><w:p><w:r><w:t>Template text</w:t></w:r></w:p><w:sectPr><w:headerReference w:type="default" r:id="rId7"/></w:sectPr></w:body></w:document>`,
+	})
+
+	d, err := New(custom)
+	require.NoError(t, err)
+
+	d.Title("Report")
+
+	document, err := d.Bytes()
+	require.NoError(t, err)
+
+	files := parts(t, document)
+
+	assert.Contains(t, files["word/header1.xml"], "ACME Corp - Confidential")
+	assert.Contains(t, files["word/styles.xml"], "Corporate Title")
+	assert.Contains(t, files["[Content_Types].xml"], "wordprocessingml.document.main+xml")
+	assert.NotContains(t, files[documentPart], "Template text")
+	assert.Equal(t, `<w:document xmlns:w="w" xmlns:r="r"><w:body><w:p><w:pPr><w:pStyle w:val="Title"/></w:pPr><w:r><w:t xml:space="preserve">Report</w:t></w:r></w:p><w:sectPr><w:headerReference w:type="default" r:id="rId7"/></w:sectPr></w:body></w:document>`, files[documentPart])
+}
+
+func TestValidateTemplate(t *testing.T) {
+	t.Parallel()
+
+	require.NoError(t, ValidateTemplate(nil))
+	require.ErrorIs(t, ValidateTemplate([]byte("not a zip")), ErrInvalidTemplate)
+	require.ErrorIs(t, ValidateTemplate(template(t, map[string]string{documentPart: `<w:document><w:body></w:body></w:document>`})), ErrInvalidTemplate)
+	require.ErrorIs(t, ValidateTemplate(template(t, map[string]string{stylesPart: `<w:styles/>`})), ErrInvalidTemplate)
+	require.NoError(t, ValidateTemplate(template(t, map[string]string{stylesPart: `<w:styles/>`, documentPart: `<w:document><w:body></w:body></w:document>`})))
+}
@@ -0,0 +1,302 @@
+package docx
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Ticket is the content of a ticket document, encrypted content must be
+// decrypted.
+type Ticket struct {
+	ID          string
+	Key         string
+	Name        string
+	Type        string
+	Owner       string
+	Open        bool
+	Resolution  string
+	Created     time.Time
+	Updated     time.Time
+	Description string
+	State       map[string]any
+	Tasks       []Task
+	Comments    []Comment
+	Files       []File
+	Timeline    []Event
+	Links       []Link
+}
+
+type Task struct {
+	Name  string
+	Owner string
+	Open  bool
+}
+
+type Comment struct {
+	Author  string
+	Created time.Time
+	Message string
+}
+
+type File struct {
+	Name    string
+	Size    int64
+	Created time.Time
+}
+
+type Event struct {
+	Time    time.Time
+	Message string
+}
+
+type Link struct {
+	Name string
+	URL  string
+}
+
+// TicketDocument renders a ticket with its tasks, comments, files, timeline
+// and links.
+func TicketDocument(template []byte, ticket *Ticket, generatedBy string, generated time.Time) ([]byte, error) {
+	d, err := New(template)
+	if err != nil {
+		return nil, err
+	}
+
+	d.Title(cmp.Or(ticket.Key, ticket.ID) + " " + ticket.Name)
+	d.Subtitle("Generated " + formatTime(generated) + " by " + generatedBy)
+
+	d.Heading("Details")
+
+	details := [][]string{
+		{"ID", ticket.ID},
+		{"Type", ticket.Type},
+		{"Owner", cmp.Or(ticket.Owner, "-")},
+		{"Status", status(ticket.Open)},
+	}
+
+	if ticket.Resolution != "" {
+		details = append(details, []string{"Resolution", ticket.Resolution})
+	}
+
+	details = append(details, []string{"Created", formatTime(ticket.Created)}, []string{"Updated", formatTime(ticket.Updated)})
+
+	for _, field := range slices.Sorted(maps.Keys(ticket.State)) {
+		details = append(details, []string{field, formatValue(ticket.State[field])})
+	}
+
+	d.Table([]string{"Field", "Value"}, details)
+
+	if ticket.Description != "" {
+		d.Heading("Description")
+
+		for _, paragraph := range strings.Split(strings.ReplaceAll(ticket.Description, "\r\n", "\n"), "\n\n") {
+			if paragraph = strings.TrimSpace(paragraph); paragraph != "" {
+				d.Paragraph(paragraph)
+			}
+		}
+	}
+
+	if len(ticket.Tasks) > 0 {
+		d.Heading("Tasks")
+
+		rows := make([][]string, 0, len(ticket.Tasks))
+		for _, task := range ticket.Tasks {
+			rows = append(rows, []string{task.Name, cmp.Or(task.Owner, "-"), status(task.Open)})
+		}
+
+		d.Table([]string{"Task", "Owner", "Status"}, rows)
+	}
+
+	if len(ticket.Comments) > 0 {
+		d.Heading("Comments")
+
+		for _, comment := range ticket.Comments {
+			d.Subheading(comment.Author + ", " + formatTime(comment.Created))
+			d.Paragraph(comment.Message)
+		}
+	}
+
+	if len(ticket.Files) > 0 {
+		d.Heading("Files")
+
+		rows := make([][]string, 0, len(ticket.Files))
+		for _, file := range ticket.Files {
+			rows = append(rows, []string{file.Name, formatSize(file.Size), formatTime(file.Created)})
+		}
+
+		d.Table([]string{"Name", "Size", "Uploaded"}, rows)
+	}
+
+	if len(ticket.Timeline) > 0 {
+		d.Heading("Timeline")
+
+		rows := make([][]string, 0, len(ticket.Timeline))
+		for _, event := range ticket.Timeline {
+			rows = append(rows, []string{formatTime(event.Time), event.Message})
+		}
+
+		d.Table([]string{"Time", "Event"}, rows)
+	}
+
+	if len(ticket.Links) > 0 {
+		d.Heading("Links")
+
+		rows := make([][]string, 0, len(ticket.Links))
+		for _, link := range ticket.Links {
+			rows = append(rows, []string{link.Name, link.URL})
+		}
+
+		d.Table([]string{"Name", "URL"}, rows)
+	}
+
+	return d.Bytes()
+}
+
+// Report is the content of a report on the tickets created in a period.
+type Report struct {
+	Type         string
+	Since        time.Time
+	Acknowledged int64
+	Resolved     int64
+	MTTA         time.Duration
+	MTTR         time.Duration
+	Tickets      []ReportTicket
+}
+
+type ReportTicket struct {
+	ID      string
+	Key     string
+	Name    string
+	Type    string
+	Owner   string
+	Open    bool
+	Created time.Time
+}
+
+// ReportDocument renders a report with the response times, the number of
+// tickets per type and a list of the tickets.
+func ReportDocument(template []byte, report *Report, generatedBy string, generated time.Time) ([]byte, error) {
+	d, err := New(template)
+	if err != nil {
+		return nil, err
+	}
+
+	title := "Ticket Report"
+	if report.Type != "" {
+		title += ": " + report.Type
+	}
+
+	d.Title(title)
+	d.Subtitle(fmt.Sprintf("Tickets created from %s to %s, generated by %s", formatTime(report.Since), formatTime(generated), generatedBy))
+
+	open := 0
+	perType := map[string][2]int{}
+
+	for _, ticket := range report.Tickets {
+		counts := perType[ticket.Type]
+		counts[0]++
+
+		if ticket.Open {
+			open++
+			counts[1]++
+		}
+
+		perType[ticket.Type] = counts
+	}
+
+	d.Heading("Summary")
+	d.Table([]string{"Metric", "Value"}, [][]string{
+		{"Tickets", fmt.Sprint(len(report.Tickets))},
+		{"Open", fmt.Sprint(open)},
+		{"Acknowledged", fmt.Sprint(report.Acknowledged)},
+		{"Resolved", fmt.Sprint(report.Resolved)},
+		{"Mean time to acknowledge", formatDuration(report.MTTA)},
+		{"Mean time to resolve", formatDuration(report.MTTR)},
+	})
+
+	if len(perType) > 0 {
+		d.Heading("Tickets per Type")
+
+		rows := make([][]string, 0, len(perType))
+		for _, ticketType := range slices.Sorted(maps.Keys(perType)) {
+			rows = append(rows, []string{ticketType, fmt.Sprint(perType[ticketType][0]), fmt.Sprint(perType[ticketType][1])})
+		}
+
+		d.Table([]string{"Type", "Tickets", "Open"}, rows)
+	}
+
+	d.Heading("Tickets")
+
+	if len(report.Tickets) == 0 {
+		d.Paragraph("No tickets were created in this period.")
+	} else {
+		rows := make([][]string, 0, len(report.Tickets))
+		for _, ticket := range report.Tickets {
+			rows = append(rows, []string{cmp.Or(ticket.Key, ticket.ID), ticket.Name, ticket.Type, cmp.Or(ticket.Owner, "-"), status(ticket.Open), formatTime(ticket.Created)})
+		}
+
+		d.Table([]string{"Ticket", "Name", "Type", "Owner", "Status", "Created"}, rows)
+	}
+
+	return d.Bytes()
+}
+
+func status(open bool) string {
+	if open {
+		return "Open"
+	}
+
+	return "Closed"
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format("2006-01-02 15:04 MST")
+}
+
+func formatSize(size int64) string {
+	switch {
+	case size >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(size)/(1<<20))
+	case size >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(size)/(1<<10))
+	default:
+		return fmt.Sprintf("%d B", size)
+	}
+}
+
+func formatDuration(d time.Duration) string {
+	if d <= 0 {
+		return "-"
+	}
+
+	return fmt.Sprintf("%.1f hours", d.Hours())
+}
+
+// formatValue formats a value of the ticket state, lists are joined and
+// objects are written as JSON.
+func formatValue(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return "-"
+	case string:
+		return v
+	case []any:
+		items := make([]string, 0, len(v))
+		for _, item := range v {
+			items = append(items, formatValue(item))
+		}
+
+		return strings.Join(items, ", ")
+	case map[string]any:
+		b, _ := json.Marshal(v)
+
+		return string(b)
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,139 @@
+// Package enrichment caches the results of enrichment lookups, like the
+// reputation of a domain, in the database. The cache is shared by all
+// tickets and reactions, so that common indicators are only looked up once
+// per TTL, which keeps the lookups within third-party quotas.
+package enrichment
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/SecurityBrewery/catalyst/app/canonical"
+	"github.com/SecurityBrewery/catalyst/app/database/sqlc"
+	"github.com/SecurityBrewery/catalyst/app/settings"
+)
+
+const DefaultTTL = 24 * time.Hour
+
+var ErrNotCached = errors.New("enrichment result not cached")
+
+type Entry struct {
+	Enricher string
+	Value    string
+	Result   json.RawMessage
+	Expires  time.Time
+}
+
+type Cache struct {
+	queries *sqlc.Queries
+	now     func() time.Time
+}
+
+func New(queries *sqlc.Queries) *Cache {
+	return &Cache{
+		queries: queries,
+		now:     time.Now,
+	}
+}
+
+// Get returns the cached result of an enricher for an artifact value.
+func (c *Cache) Get(ctx context.Context, enricher, value string) (*Entry, error) {
+	cached, err := c.queries.GetEnrichment(ctx, sqlc.GetEnrichmentParams{
+		Enricher: enricher,
+		Value:    Key(value),
+		Now:      c.now().UTC(),
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotCached
+	} else if err != nil {
+		return nil, err
+	}
+
+	return mapEntry(&cached), nil
+}
+
+// List returns the cached results of all enrichers for an artifact value.
+func (c *Cache) List(ctx context.Context, value string) ([]*Entry, error) {
+	cached, err := c.queries.ListEnrichments(ctx, sqlc.ListEnrichmentsParams{
+		Value: Key(value),
+		Now:   c.now().UTC(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]*Entry, 0, len(cached))
+	for _, entry := range cached {
+		entries = append(entries, mapEntry(&entry))
+	}
+
+	return entries, nil
+}
+
+// Set caches the result of an enricher for an artifact value for the TTL of
+// the enricher and removes expired results.
+func (c *Cache) Set(ctx context.Context, enricher, value string, result json.RawMessage) (*Entry, error) {
+	se, err := settings.Load(ctx, c.queries)
+	if err != nil {
+		return nil, err
+	}
+
+	now := c.now().UTC()
+
+	if err := c.queries.DeleteExpiredEnrichments(ctx, now); err != nil {
+		return nil, err
+	}
+
+	cached, err := c.queries.SetEnrichment(ctx, sqlc.SetEnrichmentParams{
+		Enricher: enricher,
+		Value:    Key(value),
+		Result:   result,
+		Expires:  now.Add(TTL(&se.EnrichmentCache, enricher)).Truncate(time.Second),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return mapEntry(&cached), nil
+}
+
+// TTL returns how long the results of an enricher are cached.
+func TTL(config *settings.EnrichmentCache, enricher string) time.Duration {
+	if ttl, ok := config.TTLs[enricher]; ok && ttl > 0 {
+		return time.Duration(ttl) * time.Second
+	}
+
+	if config.DefaultTTL > 0 {
+		return time.Duration(config.DefaultTTL) * time.Second
+	}
+
+	return DefaultTTL
+}
+
+// Key returns the canonical form of an artifact value, so that different
+// spellings of the same indicator share a cache entry. Other values are only
+// trimmed.
+func Key(value string) string {
+	value = strings.TrimSpace(value)
+
+	if kind := canonical.Detect(value); kind != "" {
+		if key, err := canonical.Canonicalize(kind, value); err == nil {
+			return key
+		}
+	}
+
+	return value
+}
+
+func mapEntry(cached *sqlc.EnrichmentCache) *Entry {
+	return &Entry{
+		Enricher: cached.Enricher,
+		Value:    cached.Value,
+		Result:   cached.Result,
+		Expires:  cached.Expires,
+	}
+}
@@ -0,0 +1,74 @@
+package enrichment
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/SecurityBrewery/catalyst/app/data"
+	"github.com/SecurityBrewery/catalyst/app/settings"
+)
+
+func TestCache(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	queries := data.NewTestDB(t, t.TempDir())
+
+	_, err := settings.Update(ctx, queries, func(s *settings.Settings) {
+		s.EnrichmentCache.TTLs = map[string]int{"virustotal": 3600}
+	})
+	require.NoError(t, err)
+
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	c := New(queries)
+	c.now = func() time.Time { return now }
+
+	_, err = c.Get(ctx, "virustotal", "example.com")
+	require.ErrorIs(t, err, ErrNotCached)
+
+	entry, err := c.Set(ctx, "virustotal", "Example.COM.", json.RawMessage(`{"malicious":3}`))
+	require.NoError(t, err)
+	assert.Equal(t, "example.com", entry.Value)
+	assert.Equal(t, now.Add(time.Hour), entry.Expires)
+
+	_, err = c.Set(ctx, "whois", "example.com", json.RawMessage(`{"registrar":"Example"}`))
+	require.NoError(t, err)
+
+	// a defanged spelling hits the same entry
+	entry, err = c.Get(ctx, "virustotal", "example[.]com")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"malicious":3}`, string(entry.Result))
+
+	entries, err := c.List(ctx, "EXAMPLE.com")
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "virustotal", entries[0].Enricher)
+	assert.Equal(t, "whois", entries[1].Enricher)
+
+	// the results expire per enricher
+	now = now.Add(2 * time.Hour)
+
+	_, err = c.Get(ctx, "virustotal", "example.com")
+	require.ErrorIs(t, err, ErrNotCached)
+
+	entry, err = c.Get(ctx, "whois", "example.com")
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2025, 6, 2, 12, 0, 0, 0, time.UTC), entry.Expires)
+
+	entries, err = c.List(ctx, "example.com")
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "whois", entries[0].Enricher)
+}
+
+func TestKey(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "http://evil.example/", Key("hxxp://EVIL[.]example"))
+	assert.Equal(t, "d41d8cd98f00b204e9800998ecf8427e", Key(" D41D8CD98F00B204E9800998ECF8427E "))
+	assert.Equal(t, "some user name", Key(" some user name "))
+}
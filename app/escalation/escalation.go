@@ -0,0 +1,376 @@
+// Package escalation re-notifies the next person or team of an escalation
+// policy while a ticket stays unacknowledged.
+//
+// The escalation of a ticket is paused while the ticket state field "status"
+// is one of the pause states of its policy, e.g. "waiting for customer". The
+// next step is postponed by the paused time, which is also excluded from the
+// time to resolve of the ticket.
+package escalation
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/SecurityBrewery/catalyst/app/database"
+	"github.com/SecurityBrewery/catalyst/app/database/sqlc"
+	"github.com/SecurityBrewery/catalyst/app/hook"
+	"github.com/SecurityBrewery/catalyst/app/maintenance"
+	"github.com/SecurityBrewery/catalyst/app/openapi"
+	"github.com/SecurityBrewery/catalyst/app/push"
+	"github.com/SecurityBrewery/catalyst/app/settings"
+)
+
+const checkInterval = time.Minute
+
+// Filter selects the tickets a policy applies to. Empty lists match all
+// tickets, the severity is read from the ticket state field "severity".
+type Filter struct {
+	Types      []string `json:"types,omitempty"`
+	Severities []string `json:"severities,omitempty"`
+}
+
+// Step notifies users and the members of groups Delay minutes after the
+// ticket was created or the previous step was notified.
+type Step struct {
+	Delay  int      `json:"delay"`
+	Users  []string `json:"users,omitempty"`
+	Groups []string `json:"groups,omitempty"`
+}
+
+// Sender delivers emails, usually the mail.Mailer.
+type Sender interface {
+	Send(ctx context.Context, to, subject, plainTextBody, htmlBody string) error
+}
+
+// Pusher delivers push notifications, usually the push.Notifier.
+type Pusher interface {
+	Send(ctx context.Context, user string, message push.Message) error
+}
+
+type Escalator struct {
+	queries *sqlc.Queries
+	mailer  Sender
+	pusher  Pusher
+	now     func() time.Time
+}
+
+func New(queries *sqlc.Queries, mailer Sender, pusher Pusher) *Escalator {
+	return &Escalator{
+		queries: queries,
+		mailer:  mailer,
+		pusher:  pusher,
+		now:     time.Now,
+	}
+}
+
+// BindHooks starts the escalation of new and updated tickets that match a
+// policy and pauses or resumes it on status changes. Tickets are escalated at
+// most once.
+func BindHooks(hooks *hook.Hooks, queries *sqlc.Queries, mailer Sender, pusher Pusher) *Escalator {
+	e := New(queries, mailer, pusher)
+
+	start := func(ctx context.Context, table string, record any) {
+		if table != database.TicketsTable.ID {
+			return
+		}
+
+		ticket, ok := record.(openapi.Ticket)
+		if !ok {
+			return
+		}
+
+		if ticket.Open && ticket.Acknowledged == nil {
+			if err := e.Track(ctx, ticket); err != nil {
+				slog.ErrorContext(ctx, "Failed to start escalation", "ticket", ticket.Id, "error", err)
+			}
+		}
+
+		if err := e.UpdatePause(ctx, ticket); err != nil {
+			slog.ErrorContext(ctx, "Failed to pause escalation", "ticket", ticket.Id, "error", err)
+		}
+	}
+
+	hooks.OnRecordAfterCreateRequest.Subscribe(start)
+	hooks.OnRecordAfterUpdateRequest.Subscribe(start)
+
+	return e
+}
+
+func ParseSteps(data []byte) ([]Step, error) {
+	var steps []Step
+	if err := json.Unmarshal(data, &steps); err != nil {
+		return nil, err
+	}
+
+	return steps, nil
+}
+
+func ParseFilter(data []byte) (Filter, error) {
+	var filter Filter
+	err := json.Unmarshal(data, &filter)
+
+	return filter, err
+}
+
+func (f *Filter) Matches(ticket *openapi.Ticket) bool {
+	severity, _ := ticket.State["severity"].(string)
+
+	return matchAny(f.Types, ticket.Type) && matchAny(f.Severities, severity)
+}
+
+func ParsePauseStates(data []byte) ([]string, error) {
+	var states []string
+	if len(data) == 0 {
+		return states, nil
+	}
+
+	err := json.Unmarshal(data, &states)
+
+	return states, err
+}
+
+func matchAny(values []string, value string) bool {
+	return len(values) == 0 || slices.ContainsFunc(values, func(v string) bool { return strings.EqualFold(v, value) })
+}
+
+// Track escalates the ticket with the first policy, by name, that matches.
+func (e *Escalator) Track(ctx context.Context, ticket openapi.Ticket) error {
+	if _, err := e.queries.GetTicketEscalation(ctx, ticket.Id); err == nil {
+		return nil // already escalated
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+
+	policies, err := database.PaginateItems(ctx, func(ctx context.Context, offset, limit int64) ([]sqlc.ListEscalationPoliciesRow, error) {
+		return e.queries.ListEscalationPolicies(ctx, sqlc.ListEscalationPoliciesParams{Limit: limit, Offset: offset})
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, policy := range policies {
+		filter, err := ParseFilter(policy.Filter)
+		if err != nil || !filter.Matches(&ticket) {
+			continue
+		}
+
+		steps, err := ParseSteps(policy.Steps)
+		if err != nil || len(steps) == 0 {
+			continue
+		}
+
+		nextAt := e.now().Add(time.Duration(steps[0].Delay) * time.Minute)
+
+		_, err = e.queries.CreateTicketEscalation(ctx, sqlc.CreateTicketEscalationParams{
+			Ticket: ticket.Id,
+			Policy: policy.ID,
+			NextAt: &nextAt,
+		})
+
+		return err
+	}
+
+	return nil
+}
+
+// UpdatePause pauses the escalation of an open ticket whose status is a pause
+// state of its policy. Once the ticket leaves the pause state or is closed,
+// the paused time is added to the escalation and the next step is postponed
+// by it.
+func (e *Escalator) UpdatePause(ctx context.Context, ticket openapi.Ticket) error {
+	escalation, err := e.queries.GetTicketEscalation(ctx, ticket.Id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	policy, err := e.queries.GetEscalationPolicy(ctx, escalation.Policy)
+	if err != nil {
+		return err
+	}
+
+	states, err := ParsePauseStates(policy.PauseStates)
+	if err != nil {
+		return fmt.Errorf("invalid pause states of escalation policy %s: %w", policy.ID, err)
+	}
+
+	status, _ := ticket.State["status"].(string)
+	pause := ticket.Open && len(states) > 0 && matchAny(states, status)
+	now := e.now().UTC()
+
+	switch {
+	case pause && escalation.PausedSince == nil:
+		return e.queries.PauseTicketEscalation(ctx, sqlc.PauseTicketEscalationParams{Ticket: ticket.Id, PausedSince: &now})
+	case !pause && escalation.PausedSince != nil:
+		paused := max(now.Sub(*escalation.PausedSince), 0)
+
+		nextAt := escalation.NextAt
+		if nextAt != nil {
+			postponed := nextAt.Add(paused)
+			nextAt = &postponed
+		}
+
+		return e.queries.ResumeTicketEscalation(ctx, sqlc.ResumeTicketEscalationParams{
+			Ticket: ticket.Id,
+			Paused: escalation.Paused + int64(paused.Seconds()),
+			NextAt: nextAt,
+		})
+	}
+
+	return nil
+}
+
+// Start checks for due escalation steps every minute until the context is
+// canceled.
+func (e *Escalator) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(checkInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := e.Escalate(ctx); err != nil {
+					slog.ErrorContext(ctx, "Failed to escalate tickets", "error", err)
+				}
+			}
+		}
+	}()
+}
+
+// Escalate notifies the recipients of all due steps and schedules the next
+// step. After the last step the chain starts again if the policy repeats,
+// otherwise it ends.
+func (e *Escalator) Escalate(ctx context.Context) error {
+	now := e.now()
+
+	due, err := e.queries.ListDueEscalations(ctx, now.UTC().Format(time.DateTime))
+	if err != nil {
+		return err
+	}
+
+	se, err := settings.Load(ctx, e.queries)
+	if err != nil {
+		return err
+	}
+
+	for _, escalation := range due {
+		// paused while the ticket is in maintenance, see maintenance.Pauser
+		if paused, err := maintenance.Covers(ctx, e.queries, escalation.Ticket, now); err != nil {
+			return err
+		} else if paused {
+			continue
+		}
+
+		policy, err := e.queries.GetEscalationPolicy(ctx, escalation.Policy)
+		if err != nil {
+			return err
+		}
+
+		steps, err := ParseSteps(policy.Steps)
+		if err != nil {
+			return fmt.Errorf("invalid steps of escalation policy %s: %w", policy.ID, err)
+		}
+
+		step := int(escalation.Step)
+		if step < len(steps) {
+			e.notify(ctx, se, &escalation, &steps[step])
+		}
+
+		params := sqlc.AdvanceTicketEscalationParams{Ticket: escalation.Ticket, Step: int64(step + 1)}
+
+		if params.Step >= int64(len(steps)) && policy.Repeat {
+			params.Step = 0
+		}
+
+		if params.Step < int64(len(steps)) {
+			nextAt := now.Add(time.Duration(steps[params.Step].Delay) * time.Minute)
+			params.NextAt = &nextAt
+		}
+
+		if err := e.queries.AdvanceTicketEscalation(ctx, params); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (e *Escalator) notify(ctx context.Context, se *settings.Settings, escalation *sqlc.ListDueEscalationsRow, step *Step) {
+	recipients, err := e.recipients(ctx, step)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to resolve escalation recipients", "ticket", escalation.Ticket, "error", err)
+
+		return
+	}
+
+	url := strings.TrimSuffix(se.Meta.AppURL, "/") + "/ui/tickets/" + escalation.TicketType + "/" + escalation.Ticket
+	subject := "Escalation: " + escalation.TicketName + " is not acknowledged"
+	body := fmt.Sprintf("The ticket %q was not acknowledged yet and is escalated to you.\n\n%s", escalation.TicketName, url)
+
+	for _, user := range recipients {
+		if user.Email != nil && *user.Email != "" && e.mailer != nil {
+			if err := e.mailer.Send(ctx, *user.Email, subject, body, ""); err != nil {
+				slog.ErrorContext(ctx, "Failed to send escalation mail", "ticket", escalation.Ticket, "user", user.ID, "error", err)
+			}
+		}
+
+		if e.pusher != nil {
+			if err := e.pusher.Send(ctx, user.ID, push.Message{Title: subject, Body: escalation.TicketName, URL: url}); err != nil {
+				slog.ErrorContext(ctx, "Failed to push escalation", "ticket", escalation.Ticket, "user", user.ID, "error", err)
+			}
+		}
+	}
+}
+
+// recipients returns the active users of a step and the members of its
+// groups, each only once.
+func (e *Escalator) recipients(ctx context.Context, step *Step) ([]sqlc.User, error) {
+	var users []sqlc.User
+
+	seen := map[string]bool{}
+
+	add := func(user sqlc.User) {
+		if user.Active && !seen[user.ID] {
+			seen[user.ID] = true
+
+			users = append(users, user)
+		}
+	}
+
+	for _, id := range step.Users {
+		user, err := e.queries.GetUser(ctx, id)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				continue
+			}
+
+			return nil, err
+		}
+
+		add(user)
+	}
+
+	for _, group := range step.Groups {
+		members, err := e.queries.ListGroupUsers(ctx, group)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, member := range members {
+			add(sqlc.User{ID: member.ID, Username: member.Username, Active: member.Active, Name: member.Name, Email: member.Email})
+		}
+	}
+
+	return users, nil
+}
@@ -0,0 +1,200 @@
+package escalation
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/SecurityBrewery/catalyst/app/data"
+	"github.com/SecurityBrewery/catalyst/app/database/sqlc"
+	"github.com/SecurityBrewery/catalyst/app/openapi"
+	"github.com/SecurityBrewery/catalyst/app/push"
+)
+
+type fakeMailer struct {
+	to []string
+}
+
+func (f *fakeMailer) Send(_ context.Context, to, _, _, _ string) error {
+	f.to = append(f.to, to)
+
+	return nil
+}
+
+type fakePusher struct {
+	users []string
+}
+
+func (f *fakePusher) Send(_ context.Context, user string, _ push.Message) error {
+	f.users = append(f.users, user)
+
+	return nil
+}
+
+func TestFilter_Matches(t *testing.T) {
+	t.Parallel()
+
+	ticket := openapi.Ticket{Type: "incident", State: map[string]any{"severity": "High"}}
+
+	assert.True(t, (&Filter{}).Matches(&ticket))
+	assert.True(t, (&Filter{Types: []string{"incident"}, Severities: []string{"high"}}).Matches(&ticket))
+	assert.False(t, (&Filter{Types: []string{"alert"}}).Matches(&ticket))
+	assert.False(t, (&Filter{Severities: []string{"Low"}}).Matches(&ticket))
+}
+
+func newTestEscalator(t *testing.T, repeat bool, steps ...Step) (*Escalator, *fakeMailer, *fakePusher, *time.Time) {
+	t.Helper()
+
+	queries := data.NewTestDB(t, t.TempDir())
+
+	stepsJSON, err := json.Marshal(steps)
+	require.NoError(t, err)
+
+	_, err = queries.CreateEscalationPolicy(t.Context(), sqlc.CreateEscalationPolicyParams{
+		Name:        "Incidents",
+		Filter:      []byte(`{"types":["incident"]}`),
+		Steps:       stepsJSON,
+		Repeat:      repeat,
+		PauseStates: []byte(`["waiting for customer"]`),
+	})
+	require.NoError(t, err)
+
+	mailer := &fakeMailer{}
+	pusher := &fakePusher{}
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	e := New(queries, mailer, pusher)
+	e.now = func() time.Time { return now }
+
+	return e, mailer, pusher, &now
+}
+
+func TestEscalator_Escalate(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+
+	e, mailer, pusher, now := newTestEscalator(t, false,
+		Step{Delay: 15, Users: []string{"u_bob_analyst"}},
+		Step{Delay: 30, Users: []string{"u_bob_analyst"}, Groups: []string{"admin"}},
+	)
+
+	require.NoError(t, e.Track(ctx, openapi.Ticket{Id: "test-ticket", Type: "incident", Open: true}))
+
+	escalation, err := e.queries.GetTicketEscalation(ctx, "test-ticket")
+	require.NoError(t, err)
+	require.NotNil(t, escalation.NextAt)
+	assert.Equal(t, now.Add(15*time.Minute), escalation.NextAt.UTC())
+
+	// not due yet
+	*now = now.Add(10 * time.Minute)
+	require.NoError(t, e.Escalate(ctx))
+	assert.Empty(t, mailer.to)
+
+	*now = now.Add(5 * time.Minute)
+	require.NoError(t, e.Escalate(ctx))
+	assert.Equal(t, []string{"analyst@catalyst-soar.com"}, mailer.to)
+	assert.Equal(t, []string{"u_bob_analyst"}, pusher.users)
+
+	*now = now.Add(30 * time.Minute)
+	require.NoError(t, e.Escalate(ctx))
+	assert.Equal(t, []string{"analyst@catalyst-soar.com", "analyst@catalyst-soar.com", "admin@catalyst-soar.com"}, mailer.to)
+
+	// the chain ends after the last step
+	escalation, err = e.queries.GetTicketEscalation(ctx, "test-ticket")
+	require.NoError(t, err)
+	assert.Nil(t, escalation.NextAt)
+
+	*now = now.Add(time.Hour)
+	require.NoError(t, e.Escalate(ctx))
+	assert.Len(t, mailer.to, 3)
+}
+
+func TestEscalator_Acknowledge(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+
+	e, mailer, _, now := newTestEscalator(t, true, Step{Delay: 5, Users: []string{"u_bob_analyst"}})
+
+	require.NoError(t, e.Track(ctx, openapi.Ticket{Id: "test-ticket", Type: "incident", Open: true}))
+
+	// the policy repeats until the ticket is acknowledged
+	for range 3 {
+		*now = now.Add(5 * time.Minute)
+		require.NoError(t, e.Escalate(ctx))
+	}
+
+	assert.Len(t, mailer.to, 3)
+
+	bob := "u_bob_analyst"
+
+	escalation, err := e.queries.AcknowledgeTicketEscalation(ctx, sqlc.AcknowledgeTicketEscalationParams{AcknowledgedBy: &bob, Ticket: "test-ticket"})
+	require.NoError(t, err)
+	assert.Nil(t, escalation.NextAt)
+	assert.NotNil(t, escalation.AcknowledgedAt)
+
+	*now = now.Add(time.Hour)
+	require.NoError(t, e.Escalate(ctx))
+	assert.Len(t, mailer.to, 3)
+
+	// a later update does not start the chain again
+	require.NoError(t, e.Track(ctx, openapi.Ticket{Id: "test-ticket", Type: "incident", Open: true}))
+
+	escalation, err = e.queries.GetTicketEscalation(ctx, "test-ticket")
+	require.NoError(t, err)
+	assert.NotNil(t, escalation.AcknowledgedAt)
+}
+
+func TestEscalator_UpdatePause(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+
+	e, mailer, _, now := newTestEscalator(t, false, Step{Delay: 15, Users: []string{"u_bob_analyst"}})
+
+	ticket := openapi.Ticket{Id: "test-ticket", Type: "incident", Open: true, State: map[string]any{"status": "Waiting for customer"}}
+
+	require.NoError(t, e.Track(ctx, ticket))
+	require.NoError(t, e.UpdatePause(ctx, ticket))
+
+	escalation, err := e.queries.GetTicketEscalation(ctx, "test-ticket")
+	require.NoError(t, err)
+	require.NotNil(t, escalation.PausedSince)
+
+	// paused escalations are not due
+	*now = now.Add(time.Hour)
+	require.NoError(t, e.Escalate(ctx))
+	assert.Empty(t, mailer.to)
+
+	ticket.State = map[string]any{"status": "in progress"}
+	require.NoError(t, e.UpdatePause(ctx, ticket))
+
+	escalation, err = e.queries.GetTicketEscalation(ctx, "test-ticket")
+	require.NoError(t, err)
+	assert.Nil(t, escalation.PausedSince)
+	assert.Equal(t, int64(3600), escalation.Paused)
+	require.NotNil(t, escalation.NextAt)
+	assert.Equal(t, now.Add(15*time.Minute), escalation.NextAt.UTC())
+
+	*now = now.Add(15 * time.Minute)
+	require.NoError(t, e.Escalate(ctx))
+	assert.Len(t, mailer.to, 1)
+}
+
+func TestEscalator_Track_NoMatch(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+
+	e, _, _, _ := newTestEscalator(t, false, Step{Delay: 5, Users: []string{"u_bob_analyst"}})
+
+	require.NoError(t, e.Track(ctx, openapi.Ticket{Id: "test-ticket", Type: "alert", Open: true}))
+
+	_, err := e.queries.GetTicketEscalation(ctx, "test-ticket")
+	require.Error(t, err)
+}
@@ -0,0 +1,336 @@
+// Package export periodically writes flattened ticket, task and job fact
+// tables as CSV files to an S3 compatible bucket, so that BI teams can
+// analyze the SOC performance in their own tooling. The jobs are the runs of
+// the reactions.
+package export
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"net/http"
+	"path"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/SecurityBrewery/catalyst/app/database"
+	"github.com/SecurityBrewery/catalyst/app/database/sqlc"
+	"github.com/SecurityBrewery/catalyst/app/dlp"
+	"github.com/SecurityBrewery/catalyst/app/pointer"
+	"github.com/SecurityBrewery/catalyst/app/s3"
+	"github.com/SecurityBrewery/catalyst/app/settings"
+)
+
+const (
+	checkInterval = time.Hour
+	// DefaultInterval is the number of hours between exports if the
+	// settings do not set one.
+	DefaultInterval = 24
+
+	timestampFormat = "20060102T150405Z"
+)
+
+var ErrDisabled = errors.New("metrics export is disabled")
+
+type Exporter struct {
+	queries *sqlc.Queries
+	client  *http.Client
+	now     func() time.Time
+
+	mu   sync.Mutex
+	last time.Time
+}
+
+func New(queries *sqlc.Queries) *Exporter {
+	return &Exporter{
+		queries: queries,
+		client:  &http.Client{Timeout: time.Minute},
+		now:     time.Now,
+	}
+}
+
+// Start checks every hour whether an export is due until the context is
+// canceled.
+func (e *Exporter) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(checkInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := e.exportDue(ctx); err != nil {
+					slog.ErrorContext(ctx, "Failed to export metrics", "error", err)
+				}
+			}
+		}
+	}()
+}
+
+func (e *Exporter) exportDue(ctx context.Context) error {
+	se, err := settings.Load(ctx, e.queries)
+	if err != nil {
+		return err
+	}
+
+	if !se.MetricsExport.Enabled {
+		return nil
+	}
+
+	interval := DefaultInterval * time.Hour
+	if se.MetricsExport.Interval > 0 {
+		interval = time.Duration(se.MetricsExport.Interval) * time.Hour
+	}
+
+	e.mu.Lock()
+	due := e.last.IsZero() || e.now().Sub(e.last) >= interval
+	e.mu.Unlock()
+
+	if !due {
+		return nil
+	}
+
+	_, err = e.Export(ctx)
+
+	return err
+}
+
+// Export writes the fact tables and returns the keys of the written objects.
+// The keys are partitioned by date, e.g. prefix/tickets/date=2025-03-01/.
+func (e *Exporter) Export(ctx context.Context) ([]string, error) {
+	se, err := settings.Load(ctx, e.queries)
+	if err != nil {
+		return nil, err
+	}
+
+	if !se.MetricsExport.Enabled {
+		return nil, ErrDisabled
+	}
+
+	if err := dlp.Check(ctx, e.queries, dlp.ExportCSV); err != nil {
+		return nil, err
+	}
+
+	now := e.now().UTC()
+
+	tickets, err := e.ticketFacts(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect tickets: %w", err)
+	}
+
+	tasks, err := e.taskFacts(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect tasks: %w", err)
+	}
+
+	jobs, err := e.jobFacts(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect jobs: %w", err)
+	}
+
+	config := &s3.Config{
+		Endpoint:        se.MetricsExport.Endpoint,
+		Region:          se.MetricsExport.Region,
+		AccessKeyID:     se.MetricsExport.AccessKeyID,
+		SecretAccessKey: se.MetricsExport.SecretAccessKey,
+	}
+
+	var keys []string
+
+	for name, rows := range map[string][][]string{"tickets": tickets, "tasks": tasks, "jobs": jobs} {
+		body, err := encodeCSV(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		key := path.Join(se.MetricsExport.Prefix, name, "date="+now.Format(time.DateOnly), name+"-"+now.Format(timestampFormat)+".csv")
+
+		if err := s3.PutObject(ctx, e.client, config, se.MetricsExport.Bucket, key, "text/csv", body, now); err != nil {
+			return nil, err
+		}
+
+		keys = append(keys, key)
+	}
+
+	slices.Sort(keys)
+
+	e.mu.Lock()
+	e.last = now
+	e.mu.Unlock()
+
+	return keys, nil
+}
+
+// ticketFacts returns a header and one row per ticket. Scalar values of the
+// ticket state become state_<key> columns.
+func (e *Exporter) ticketFacts(ctx context.Context) ([][]string, error) {
+	tickets, err := database.PaginateItems(ctx, func(ctx context.Context, offset, limit int64) ([]sqlc.ListTicketsRow, error) {
+		return e.queries.ListTickets(ctx, sqlc.ListTicketsParams{Offset: offset, Limit: limit})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	states := make([]map[string]string, len(tickets))
+	stateKeys := map[string]bool{}
+
+	for i, ticket := range tickets {
+		states[i] = flattenState(ticket.State)
+		for key := range states[i] {
+			stateKeys[key] = true
+		}
+	}
+
+	keys := slices.Sorted(maps.Keys(stateKeys))
+
+	header := []string{
+		"id", "type", "name", "owner", "open", "resolution", "created", "updated",
+		"acknowledged", "acknowledged_by", "resolved", "time_to_acknowledge", "time_to_resolve",
+	}
+	for _, key := range keys {
+		header = append(header, "state_"+key)
+	}
+
+	rows := [][]string{header}
+
+	for i, ticket := range tickets {
+		row := []string{
+			ticket.ID,
+			ticket.Type,
+			ticket.Name,
+			pointer.Dereference(ticket.Owner),
+			strconv.FormatBool(ticket.Open),
+			pointer.Dereference(ticket.Resolution),
+			formatTime(&ticket.Created),
+			formatTime(&ticket.Updated),
+			formatTime(ticket.Acknowledged),
+			pointer.Dereference(ticket.AcknowledgedBy),
+			formatTime(ticket.Resolved),
+			seconds(ticket.Created, ticket.Acknowledged),
+			seconds(ticket.Created, ticket.Resolved),
+		}
+
+		for _, key := range keys {
+			row = append(row, states[i][key])
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+func (e *Exporter) taskFacts(ctx context.Context) ([][]string, error) {
+	tasks, err := database.PaginateItems(ctx, func(ctx context.Context, offset, limit int64) ([]sqlc.ListTasksRow, error) {
+		return e.queries.ListTasks(ctx, sqlc.ListTasksParams{Offset: offset, Limit: limit})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	rows := [][]string{{"id", "ticket", "ticket_type", "name", "owner", "open", "created", "updated"}}
+
+	for _, task := range tasks {
+		rows = append(rows, []string{
+			task.ID,
+			task.Ticket,
+			pointer.Dereference(task.TicketType),
+			task.Name,
+			pointer.Dereference(task.Owner),
+			strconv.FormatBool(task.Open),
+			formatTime(&task.Created),
+			formatTime(&task.Updated),
+		})
+	}
+
+	return rows, nil
+}
+
+// jobFacts returns a header and one row per reaction run.
+func (e *Exporter) jobFacts(ctx context.Context) ([][]string, error) {
+	runs, err := database.PaginateItems(ctx, func(ctx context.Context, offset, limit int64) ([]sqlc.ListAllReactionRunsRow, error) {
+		return e.queries.ListAllReactionRuns(ctx, sqlc.ListAllReactionRunsParams{Offset: offset, Limit: limit})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	rows := [][]string{{"id", "reaction", "reaction_name", "trigger", "action", "success", "duration_ms", "cpu_ms", "memory_peak", "created"}}
+
+	for _, run := range runs {
+		rows = append(rows, []string{
+			run.ID,
+			run.Reaction,
+			run.ReactionName,
+			run.Trigger,
+			run.Action,
+			strconv.FormatBool(run.Success),
+			strconv.FormatInt(run.DurationMs, 10),
+			strconv.FormatInt(run.CpuMs, 10),
+			strconv.FormatInt(run.MemoryPeak, 10),
+			formatTime(&run.Created),
+		})
+	}
+
+	return rows, nil
+}
+
+func flattenState(state []byte) map[string]string {
+	var values map[string]any
+	if err := json.Unmarshal(state, &values); err != nil {
+		return nil
+	}
+
+	flat := map[string]string{}
+
+	for key, value := range values {
+		switch v := value.(type) {
+		case string:
+			flat[key] = v
+		case float64:
+			flat[key] = strconv.FormatFloat(v, 'f', -1, 64)
+		case bool:
+			flat[key] = strconv.FormatBool(v)
+		}
+	}
+
+	return flat
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+
+	return t.UTC().Format(time.RFC3339)
+}
+
+// seconds returns the seconds from start to end, or an empty string if end
+// is not set.
+func seconds(start time.Time, end *time.Time) string {
+	if end == nil {
+		return ""
+	}
+
+	return strconv.FormatInt(int64(end.Sub(start).Seconds()), 10)
+}
+
+func encodeCSV(rows [][]string) ([]byte, error) {
+	var buf bytes.Buffer
+
+	w := csv.NewWriter(&buf)
+	if err := w.WriteAll(rows); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,144 @@
+package export
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/SecurityBrewery/catalyst/app/data"
+	"github.com/SecurityBrewery/catalyst/app/database/sqlc"
+	"github.com/SecurityBrewery/catalyst/app/settings"
+)
+
+type fakeS3 struct {
+	mu      sync.Mutex
+	objects map[string]string
+	auth    []string
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+
+	f.mu.Lock()
+	f.objects[r.URL.EscapedPath()] = string(body)
+	f.auth = append(f.auth, r.Header.Get("Authorization"))
+	f.mu.Unlock()
+
+	if sum := sha256.Sum256(body); r.Header.Get("X-Amz-Content-Sha256") != hex.EncodeToString(sum[:]) {
+		w.WriteHeader(http.StatusBadRequest)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func TestExporter_Export(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	queries := data.NewTestDB(t, t.TempDir())
+
+	s3 := &fakeS3{objects: map[string]string{}}
+	server := httptest.NewServer(s3)
+	t.Cleanup(server.Close)
+
+	e := New(queries)
+	e.now = func() time.Time { return time.Date(2025, 3, 1, 6, 0, 0, 0, time.UTC) }
+
+	_, err := e.Export(ctx)
+	require.ErrorIs(t, err, ErrDisabled)
+
+	_, err = settings.Update(ctx, queries, func(se *settings.Settings) {
+		se.MetricsExport = settings.MetricsExport{
+			Enabled:         true,
+			Endpoint:        server.URL,
+			Region:          "eu-central-1",
+			Bucket:          "soc-metrics",
+			Prefix:          "catalyst",
+			AccessKeyID:     "AKIDEXAMPLE",
+			SecretAccessKey: "secret",
+		}
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, queries.CreateReactionRun(ctx, sqlc.CreateReactionRunParams{
+		Reaction:   "r-test-webhook",
+		Success:    true,
+		DurationMs: 120,
+		CpuMs:      30,
+		MemoryPeak: 4096,
+	}))
+
+	keys, err := e.Export(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"catalyst/jobs/date=2025-03-01/jobs-20250301T060000Z.csv",
+		"catalyst/tasks/date=2025-03-01/tasks-20250301T060000Z.csv",
+		"catalyst/tickets/date=2025-03-01/tickets-20250301T060000Z.csv",
+	}, keys)
+
+	tickets := s3.objects["/soc-metrics/catalyst/tickets/date%3D2025-03-01/tickets-20250301T060000Z.csv"]
+	assert.True(t, strings.HasPrefix(tickets, "id,type,name,owner,open,resolution,created,updated,acknowledged,acknowledged_by,resolved,time_to_acknowledge,time_to_resolve"))
+	assert.Contains(t, tickets, "test-ticket,incident,")
+
+	tasks := s3.objects["/soc-metrics/catalyst/tasks/date%3D2025-03-01/tasks-20250301T060000Z.csv"]
+	assert.Contains(t, tasks, "k_test_task,test-ticket,incident,")
+
+	jobs := s3.objects["/soc-metrics/catalyst/jobs/date%3D2025-03-01/jobs-20250301T060000Z.csv"]
+	assert.True(t, strings.HasPrefix(jobs, "id,reaction,reaction_name,trigger,action,success,duration_ms,cpu_ms,memory_peak,created"))
+	assert.Contains(t, jobs, ",r-test-webhook,")
+	assert.Contains(t, jobs, ",true,120,30,4096,")
+
+	require.Len(t, s3.auth, 3)
+	assert.True(t, strings.HasPrefix(s3.auth[0], "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20250301/eu-central-1/s3/aws4_request, SignedHeaders=host;x-amz-content-sha256;x-amz-date, Signature="))
+}
+
+func TestExporter_exportDue(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	queries := data.NewTestDB(t, t.TempDir())
+
+	s3 := &fakeS3{objects: map[string]string{}}
+	server := httptest.NewServer(s3)
+	t.Cleanup(server.Close)
+
+	_, err := settings.Update(ctx, queries, func(se *settings.Settings) {
+		se.MetricsExport = settings.MetricsExport{Enabled: true, Interval: 6, Endpoint: server.URL, Region: "us-east-1", Bucket: "b"}
+	})
+	require.NoError(t, err)
+
+	now := time.Date(2025, 3, 1, 6, 0, 0, 0, time.UTC)
+
+	e := New(queries)
+	e.now = func() time.Time { return now }
+
+	require.NoError(t, e.exportDue(ctx))
+	assert.Len(t, s3.auth, 3)
+
+	now = now.Add(5 * time.Hour)
+	require.NoError(t, e.exportDue(ctx))
+	assert.Len(t, s3.auth, 3)
+
+	now = now.Add(time.Hour)
+	require.NoError(t, e.exportDue(ctx))
+	assert.Len(t, s3.auth, 6)
+}
+
+func Test_flattenState(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, map[string]string{"severity": "High", "score": "4.5", "vip": "true"},
+		flattenState([]byte(`{"severity":"High","score":4.5,"vip":true,"tags":["a"],"nested":{"x":1}}`)))
+	assert.Nil(t, flattenState([]byte(`invalid`)))
+}
@@ -0,0 +1,241 @@
+// Package expression evaluates CEL expressions against the variables of a
+// ticket. Expressions can read the variable ticket, the named outputs of its
+// tasks as tasks.<name>.output, in reactions the triggering record and in
+// playbooks the inputs the playbook was attached with, e.g.
+//
+//	tasks.lookup.output.score > 80 && ticket.state.severity == "High"
+//
+// CEL has no loops or side effects and the cost of an evaluation is limited,
+// so expressions from users are safe to evaluate.
+package expression
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/google/cel-go/cel"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"github.com/SecurityBrewery/catalyst/app/database/sqlc"
+)
+
+// costLimit bounds the work of a single evaluation.
+const costLimit = 100_000
+
+var (
+	ErrInvalid = errors.New("invalid expression")
+
+	templateExpression = regexp.MustCompile(`\{\{(.*?)\}\}`)
+	validName          = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+	environment = sync.OnceValues(func() (*cel.Env, error) {
+		return cel.NewEnv(
+			cel.Variable("ticket", cel.MapType(cel.StringType, cel.DynType)),
+			cel.Variable("tasks", cel.MapType(cel.StringType, cel.DynType)),
+			cel.Variable("record", cel.MapType(cel.StringType, cel.DynType)),
+			cel.Variable("inputs", cel.MapType(cel.StringType, cel.DynType)),
+			cel.CrossTypeNumericComparisons(true),
+		)
+	})
+)
+
+// Variables are the values an expression can read.
+type Variables struct {
+	Ticket map[string]any
+	Tasks  map[string]any
+	Record map[string]any
+	Inputs map[string]any
+}
+
+// Load returns the variables of a ticket. The content of encrypted tickets
+// is not available to expressions.
+func Load(ctx context.Context, queries *sqlc.Queries, ticketID string) (*Variables, error) {
+	ticket, err := queries.Ticket(ctx, ticketID)
+	if err != nil {
+		return nil, err
+	}
+
+	state := map[string]any{}
+	if !ticket.Encrypted {
+		_ = json.Unmarshal(ticket.State, &state)
+	}
+
+	outputs, err := queries.ListTaskOutputs(ctx, ticketID)
+	if err != nil {
+		return nil, err
+	}
+
+	tasks := map[string]any{}
+
+	for _, output := range outputs {
+		var value any
+		if err := json.Unmarshal(output.Output, &value); err != nil {
+			return nil, fmt.Errorf("invalid output of task %s: %w", output.Task, err)
+		}
+
+		task := map[string]any{
+			"id":     output.Task,
+			"name":   output.TaskName,
+			"open":   output.TaskOpen,
+			"output": value,
+		}
+
+		if output.TaskOwner != nil {
+			task["owner"] = *output.TaskOwner
+		}
+
+		tasks[output.Name] = task
+	}
+
+	variables := &Variables{
+		Ticket: map[string]any{
+			"id":    ticket.ID,
+			"type":  ticket.Type,
+			"name":  ticket.Name,
+			"open":  ticket.Open,
+			"state": state,
+		},
+		Tasks: tasks,
+	}
+
+	if ticket.Owner != nil {
+		variables.Ticket["owner"] = *ticket.Owner
+	}
+
+	if ticket.Resolution != nil {
+		variables.Ticket["resolution"] = *ticket.Resolution
+	}
+
+	return variables, nil
+}
+
+// ValidateName checks that a task output name can be used as a variable,
+// e.g. tasks.lookup.
+func ValidateName(name string) error {
+	if !validName.MatchString(name) {
+		return fmt.Errorf("invalid variable name %q, only letters, digits and underscores are allowed", name)
+	}
+
+	return nil
+}
+
+// Check compiles the expression without evaluating it.
+func Check(expression string) error {
+	_, err := compile(expression)
+
+	return err
+}
+
+// Eval evaluates the expression and returns its result as a JSON value.
+func Eval(expression string, variables *Variables) (any, error) {
+	program, err := compile(expression)
+	if err != nil {
+		return nil, err
+	}
+
+	if variables == nil {
+		variables = &Variables{}
+	}
+
+	out, _, err := program.Eval(map[string]any{
+		"ticket": orEmpty(variables.Ticket),
+		"tasks":  orEmpty(variables.Tasks),
+		"record": orEmpty(variables.Record),
+		"inputs": orEmpty(variables.Inputs),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to evaluate %q: %w", expression, err)
+	}
+
+	value, err := out.ConvertToNative(reflect.TypeFor[*structpb.Value]())
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert the result of %q: %w", expression, err)
+	}
+
+	return value.(*structpb.Value).AsInterface(), nil
+}
+
+// Condition evaluates an expression that must return a boolean.
+func Condition(expression string, variables *Variables) (bool, error) {
+	value, err := Eval(expression, variables)
+	if err != nil {
+		return false, err
+	}
+
+	result, ok := value.(bool)
+	if !ok {
+		return false, fmt.Errorf("%w: %q does not return a boolean", ErrInvalid, expression)
+	}
+
+	return result, nil
+}
+
+// CheckTemplate compiles the expressions of a template without evaluating
+// them.
+func CheckTemplate(template string) error {
+	var errs []error
+
+	for _, match := range templateExpression.FindAllStringSubmatch(template, -1) {
+		errs = append(errs, Check(strings.TrimSpace(match[1])))
+	}
+
+	return errors.Join(errs...)
+}
+
+// Render replaces every {{ expression }} in the template with its result.
+// Strings are inserted as they are, other values as JSON.
+func Render(template string, variables *Variables) (string, error) {
+	var errs []error
+
+	rendered := templateExpression.ReplaceAllStringFunc(template, func(match string) string {
+		value, err := Eval(strings.TrimSpace(templateExpression.FindStringSubmatch(match)[1]), variables)
+		if err != nil {
+			errs = append(errs, err)
+
+			return match
+		}
+
+		if s, ok := value.(string); ok {
+			return s
+		}
+
+		b, err := json.Marshal(value)
+		if err != nil {
+			errs = append(errs, err)
+
+			return match
+		}
+
+		return string(b)
+	})
+
+	return rendered, errors.Join(errs...)
+}
+
+func compile(expression string) (cel.Program, error) {
+	env, err := environment()
+	if err != nil {
+		return nil, err
+	}
+
+	ast, issues := env.Compile(expression)
+	if issues.Err() != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalid, issues.Err())
+	}
+
+	return env.Program(ast, cel.CostLimit(costLimit))
+}
+
+func orEmpty(m map[string]any) map[string]any {
+	if m == nil {
+		return map[string]any{}
+	}
+
+	return m
+}
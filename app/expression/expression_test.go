@@ -0,0 +1,104 @@
+package expression
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/SecurityBrewery/catalyst/app/data"
+	"github.com/SecurityBrewery/catalyst/app/database/sqlc"
+)
+
+func TestEval(t *testing.T) {
+	t.Parallel()
+
+	variables := &Variables{
+		Ticket: map[string]any{"name": "Phishing", "state": map[string]any{"severity": "High"}},
+		Tasks:  map[string]any{"lookup": map[string]any{"output": map[string]any{"score": 85.0, "tags": []any{"c2"}}}},
+		Inputs: map[string]any{"indicator": "evil.example"},
+	}
+
+	tests := []struct {
+		expression string
+		want       any
+		wantErr    bool
+	}{
+		{expression: `tasks.lookup.output.score > 80`, want: true},
+		{expression: `tasks.lookup.output.score > 80 && ticket.state.severity == "Low"`, want: false},
+		{expression: `ticket.name + " (" + ticket.state.severity + ")"`, want: "Phishing (High)"},
+		{expression: `tasks.lookup.output.tags`, want: []any{"c2"}},
+		{expression: `has(tasks.missing)`, want: false},
+		{expression: `"block " + inputs.indicator`, want: "block evil.example"},
+		{expression: `tasks.missing.output`, wantErr: true},
+		{expression: `ticket.name +`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := Eval(tt.expression, variables)
+			if tt.wantErr {
+				require.Error(t, err)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestCondition(t *testing.T) {
+	t.Parallel()
+
+	ok, err := Condition(`record.open`, &Variables{Record: map[string]any{"open": true}})
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	_, err = Condition(`"yes"`, nil)
+	require.ErrorIs(t, err, ErrInvalid)
+}
+
+func TestRender(t *testing.T) {
+	t.Parallel()
+
+	variables := &Variables{Tasks: map[string]any{"lookup": map[string]any{"output": map[string]any{"score": 85.0, "ip": "198.51.100.7"}}}}
+
+	rendered, err := Render(`block {{ tasks.lookup.output.ip }} with score {{tasks.lookup.output.score}}`, variables)
+	require.NoError(t, err)
+	assert.Equal(t, "block 198.51.100.7 with score 85", rendered)
+
+	_, err = Render(`{{ tasks.missing.output }}`, variables)
+	require.Error(t, err)
+}
+
+func TestCheckTemplate(t *testing.T) {
+	t.Parallel()
+
+	require.NoError(t, CheckTemplate(`block {{ inputs.indicator }} on {{ inputs.device }}`))
+	require.NoError(t, CheckTemplate(`no expressions`))
+	require.ErrorIs(t, CheckTemplate(`block {{ inputs.indicator + }}`), ErrInvalid)
+}
+
+func TestLoad(t *testing.T) {
+	t.Parallel()
+
+	queries := data.NewTestDB(t, t.TempDir())
+
+	_, err := queries.SetTaskOutput(t.Context(), sqlc.SetTaskOutputParams{
+		Task:   "k_test_task",
+		Name:   "lookup",
+		Output: []byte(`{"score":85}`),
+	})
+	require.NoError(t, err)
+
+	variables, err := Load(t.Context(), queries, "test-ticket")
+	require.NoError(t, err)
+
+	ok, err := Condition(`tasks.lookup.output.score > 80 && tasks.lookup.name == "Test Task" && ticket.id == "test-ticket"`, variables)
+	require.NoError(t, err)
+	assert.True(t, ok)
+}
@@ -0,0 +1,350 @@
+// Package federation links tickets across Catalyst instances, e.g. of
+// regional SOCs. Both instances register each other as peers with a shared
+// secret and sign their requests with it. Linked tickets exchange their
+// comments and status changes in both directions, but only while the TLP
+// level of the ticket is at most the level the peer is trusted with.
+package federation
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/SecurityBrewery/catalyst/app/database/sqlc"
+	"github.com/SecurityBrewery/catalyst/app/hook"
+	"github.com/SecurityBrewery/catalyst/app/settings"
+)
+
+const (
+	// BasePath is the path the federation endpoints are mounted at.
+	BasePath = "/integrations/federation"
+
+	instanceHeader  = "X-Catalyst-Instance"
+	timestampHeader = "X-Catalyst-Timestamp"
+	signatureHeader = "X-Catalyst-Signature"
+
+	maxBodySize  = 1 << 20
+	maxClockSkew = 5 * time.Minute
+)
+
+var (
+	ErrNotShareable   = errors.New("the TLP level of the ticket does not allow sharing it with the peer")
+	ErrInvalidTLP     = errors.New("invalid TLP level")
+	ErrRemoteNotFound = errors.New("the ticket does not exist on the peer or is not shared with this instance")
+
+	// tlpLevels are the TLP 2.0 levels from the least to the most restricted.
+	tlpLevels = []string{"CLEAR", "GREEN", "AMBER", "AMBER+STRICT", "RED"}
+)
+
+type Federation struct {
+	queries *sqlc.Queries
+	hooks   *hook.Hooks
+	client  *http.Client
+	replays *replayCache
+	now     func() time.Time
+}
+
+func New(queries *sqlc.Queries, hooks *hook.Hooks) *Federation {
+	return &Federation{
+		queries: queries,
+		hooks:   hooks,
+		client:  &http.Client{Timeout: 10 * time.Second},
+		replays: newReplayCache(),
+		now:     time.Now,
+	}
+}
+
+// Ticket is the summary of a ticket that is shared with a peer.
+type Ticket struct {
+	ID   string  `json:"id"`
+	Key  *string `json:"key,omitempty"`
+	Name string  `json:"name"`
+	Type string  `json:"type"`
+	Open bool    `json:"open"`
+	TLP  string  `json:"tlp"`
+}
+
+// Update is a change of a linked ticket that is sent to the peer. Ticket is
+// the ID of the ticket on the sending instance.
+type Update struct {
+	Ticket  string `json:"ticket"`
+	Author  string `json:"author,omitempty"`
+	Comment string `json:"comment,omitempty"`
+	Open    *bool  `json:"open,omitempty"`
+}
+
+type linkRequest struct {
+	// Ticket is the ID or key of the ticket on the receiving instance.
+	Ticket string `json:"ticket"`
+	Remote Ticket `json:"remote"`
+}
+
+// NormalizeTLP returns the TLP 2.0 name of a level, e.g. "AMBER" for
+// "tlp:amber" and "CLEAR" for the former "WHITE".
+func NormalizeTLP(level string) (string, error) {
+	level = strings.ToUpper(strings.TrimSpace(level))
+	level = strings.TrimPrefix(level, "TLP:")
+
+	if level == "WHITE" {
+		level = "CLEAR"
+	}
+
+	if !slices.Contains(tlpLevels, level) {
+		return "", fmt.Errorf("%w %q", ErrInvalidTLP, level)
+	}
+
+	return level, nil
+}
+
+// TicketTLP returns the TLP level of a ticket from the "tlp" field of its
+// state. Tickets without a valid level are treated as TLP:AMBER, so they are
+// only shared with peers that are trusted with restricted information.
+func TicketTLP(state map[string]any) string {
+	if tlp, ok := state["tlp"].(string); ok {
+		if level, err := NormalizeTLP(tlp); err == nil {
+			return level
+		}
+	}
+
+	return "AMBER"
+}
+
+// Shareable reports whether a ticket with the TLP level can be shared with
+// a peer that is trusted up to maxTLP.
+func Shareable(tlp, maxTLP string) bool {
+	maxLevel, err := NormalizeTLP(maxTLP)
+	if err != nil {
+		return false
+	}
+
+	return slices.Index(tlpLevels, tlp) <= slices.Index(tlpLevels, maxLevel)
+}
+
+// summary returns the shared summary of a ticket, or ErrNotShareable if the
+// ticket must not be shared with the peer. Encrypted tickets are never
+// shared.
+func (f *Federation) summary(ctx context.Context, id string, peer *sqlc.FederationPeer) (*Ticket, error) {
+	ticket, err := f.queries.Ticket(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if ticket.Encrypted {
+		return nil, ErrNotShareable
+	}
+
+	var state map[string]any
+	if err := json.Unmarshal(ticket.State, &state); err != nil {
+		return nil, fmt.Errorf("invalid state of ticket %s: %w", ticket.ID, err)
+	}
+
+	tlp := TicketTLP(state)
+	if !Shareable(tlp, peer.MaxTlp) {
+		return nil, ErrNotShareable
+	}
+
+	return &Ticket{
+		ID:   ticket.ID,
+		Key:  ticket.Key,
+		Name: ticket.Name,
+		Type: ticket.Type,
+		Open: ticket.Open,
+		TLP:  tlp,
+	}, nil
+}
+
+// Link links a local ticket to a ticket of a peer. The peer creates the
+// reverse link, so updates are shared in both directions.
+func (f *Federation) Link(ctx context.Context, ticket, peerID, remoteTicket string) (sqlc.FederationLink, error) {
+	peer, err := f.queries.GetFederationPeer(ctx, peerID)
+	if err != nil {
+		return sqlc.FederationLink{}, err
+	}
+
+	local, err := f.summary(ctx, ticket, &peer)
+	if err != nil {
+		return sqlc.FederationLink{}, err
+	}
+
+	var remote Ticket
+	if err := f.call(ctx, &peer, http.MethodPost, "/links", linkRequest{Ticket: remoteTicket, Remote: *local}, &remote); err != nil {
+		return sqlc.FederationLink{}, err
+	}
+
+	link, err := f.queries.CreateFederationLink(ctx, sqlc.CreateFederationLinkParams{
+		Ticket:       local.ID,
+		Peer:         peer.ID,
+		RemoteTicket: remote.ID,
+		RemoteKey:    remote.Key,
+		RemoteType:   remote.Type,
+		RemoteName:   remote.Name,
+		RemoteOpen:   remote.Open,
+	})
+	if err != nil {
+		return sqlc.FederationLink{}, err
+	}
+
+	if err := f.timeline(ctx, local.ID, fmt.Sprintf("Linked to ticket %s in %s", remoteName(&remote), peer.Name)); err != nil {
+		return sqlc.FederationLink{}, err
+	}
+
+	return link, nil
+}
+
+// Resolve fetches the summary of a ticket of a peer by its ID or key.
+func (f *Federation) Resolve(ctx context.Context, peerID, remoteTicket string) (*Ticket, error) {
+	peer, err := f.queries.GetFederationPeer(ctx, peerID)
+	if err != nil {
+		return nil, err
+	}
+
+	var remote Ticket
+	if err := f.call(ctx, &peer, http.MethodGet, "/tickets/"+url.PathEscape(remoteTicket), nil, &remote); err != nil {
+		return nil, err
+	}
+
+	return &remote, nil
+}
+
+// call sends a signed request to a peer.
+func (f *Federation) call(ctx context.Context, peer *sqlc.FederationPeer, method, path string, request, response any) error {
+	se, err := settings.Load(ctx, f.queries)
+	if err != nil {
+		return err
+	}
+
+	var body []byte
+	if request != nil {
+		if body, err = json.Marshal(request); err != nil {
+			return err
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimSuffix(peer.Url, "/")+BasePath+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	timestamp := f.now().Unix()
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(instanceHeader, strings.TrimSuffix(se.Meta.AppURL, "/"))
+	req.Header.Set(timestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(signatureHeader, sign(peer.Secret, timestamp, method, req.URL.RequestURI(), body))
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach peer %s: %w", peer.Name, err)
+	}
+	defer resp.Body.Close()
+
+	b, err := io.ReadAll(io.LimitReader(resp.Body, maxBodySize))
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return ErrRemoteNotFound
+	case resp.StatusCode >= http.StatusBadRequest:
+		return fmt.Errorf("peer %s responded with %s: %s", peer.Name, resp.Status, strings.TrimSpace(string(b)))
+	case response == nil:
+		return nil
+	default:
+		return json.Unmarshal(b, response)
+	}
+}
+
+// sign computes the signature of a request. It covers the method and the
+// request target, so a signed body cannot be replayed to another endpoint.
+func sign(secret string, timestamp int64, method, target string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	_, _ = fmt.Fprintf(mac, "v2:%d:%s:%s:%s", timestamp, method, target, body)
+
+	return "v2=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// verifySignature checks the signature of a request against the secret of
+// the peer. The request target is the unmodified RequestURI, the router may
+// rewrite the path before the request gets here.
+func verifySignature(secret string, r *http.Request, body []byte, now time.Time) error {
+	timestamp, err := strconv.ParseInt(r.Header.Get(timestampHeader), 10, 64)
+	if err != nil {
+		return errors.New("missing timestamp")
+	}
+
+	if math.Abs(now.Sub(time.Unix(timestamp, 0)).Seconds()) > maxClockSkew.Seconds() {
+		return errors.New("timestamp too old")
+	}
+
+	if !hmac.Equal([]byte(sign(secret, timestamp, r.Method, r.RequestURI, body)), []byte(r.Header.Get(signatureHeader))) {
+		return errors.New("signature mismatch")
+	}
+
+	return nil
+}
+
+// replayCache remembers the signatures of the accepted requests while their
+// timestamps are within the allowed clock skew, so a captured request cannot
+// be sent again.
+type replayCache struct {
+	mux  sync.Mutex
+	seen map[string]time.Time
+}
+
+func newReplayCache() *replayCache {
+	return &replayCache{seen: map[string]time.Time{}}
+}
+
+// add returns false if the signature was already seen.
+func (c *replayCache) add(timestamp, signature string, now time.Time) bool {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	// a request is accepted up to maxClockSkew before and after its
+	// timestamp, older entries can no longer be replayed
+	for key, seen := range c.seen {
+		if now.Sub(seen) > 2*maxClockSkew {
+			delete(c.seen, key)
+		}
+	}
+
+	key := timestamp + ":" + signature
+	if _, ok := c.seen[key]; ok {
+		return false
+	}
+
+	c.seen[key] = now
+
+	return true
+}
+
+// TicketURL returns the URL of a ticket in the UI of a peer.
+func TicketURL(peerURL, ticketType, id string) string {
+	return strings.TrimSuffix(peerURL, "/") + "/ui/tickets/" + url.PathEscape(ticketType) + "/" + url.PathEscape(id)
+}
+
+// ValidatePeerURL checks that the URL of a peer is an absolute HTTP URL and
+// returns it without a trailing slash, like it is sent by the peer.
+func ValidatePeerURL(peerURL string) (string, error) {
+	u, err := url.Parse(peerURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", fmt.Errorf("invalid peer URL %q", peerURL)
+	}
+
+	return strings.TrimSuffix(peerURL, "/"), nil
+}
@@ -0,0 +1,218 @@
+package federation
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/SecurityBrewery/catalyst/app/data"
+	"github.com/SecurityBrewery/catalyst/app/database/sqlc"
+	"github.com/SecurityBrewery/catalyst/app/hook"
+	"github.com/SecurityBrewery/catalyst/app/pointer"
+	"github.com/SecurityBrewery/catalyst/app/settings"
+)
+
+const testSecret = "shared-secret"
+
+func TestNormalizeTLP(t *testing.T) {
+	t.Parallel()
+
+	for level, want := range map[string]string{"TLP:RED": "RED", "amber+strict": "AMBER+STRICT", " tlp:white ": "CLEAR", "Green": "GREEN"} {
+		got, err := NormalizeTLP(level)
+		require.NoError(t, err)
+		assert.Equal(t, want, got)
+	}
+
+	_, err := NormalizeTLP("BLUE")
+	require.ErrorIs(t, err, ErrInvalidTLP)
+}
+
+func TestShareable(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, Shareable("GREEN", "AMBER"))
+	assert.True(t, Shareable("AMBER", "AMBER"))
+	assert.False(t, Shareable("RED", "AMBER"))
+	assert.False(t, Shareable(TicketTLP(map[string]any{}), "GREEN"))
+	assert.False(t, Shareable("CLEAR", "invalid"))
+}
+
+func Test_verifySignature(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1700000000, 0)
+	body := `{"ticket":"t1"}`
+
+	request := func(method, target, body string) *http.Request {
+		r := httptest.NewRequest(method, target, strings.NewReader(body))
+		r.Header.Set(timestampHeader, "1700000000")
+		r.Header.Set(signatureHeader, sign(testSecret, now.Unix(), http.MethodPost, BasePath+"/updates", []byte(`{"ticket":"t1"}`)))
+
+		return r
+	}
+
+	require.NoError(t, verifySignature(testSecret, request(http.MethodPost, BasePath+"/updates", body), []byte(body), now))
+	require.Error(t, verifySignature("wrong", request(http.MethodPost, BasePath+"/updates", body), []byte(body), now))
+	require.Error(t, verifySignature(testSecret, request(http.MethodPost, BasePath+"/updates", body), []byte(`{"ticket":"t2"}`), now))
+	require.Error(t, verifySignature(testSecret, request(http.MethodPost, BasePath+"/updates", body), []byte(body), now.Add(time.Hour)))
+	require.Error(t, verifySignature(testSecret, request(http.MethodPost, BasePath+"/links", body), []byte(body), now))
+	require.Error(t, verifySignature(testSecret, request(http.MethodPut, BasePath+"/updates", body), []byte(body), now))
+}
+
+func Test_replayCache(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1700000000, 0)
+	cache := newReplayCache()
+
+	assert.True(t, cache.add("1700000000", "v2=a", now))
+	assert.False(t, cache.add("1700000000", "v2=a", now.Add(time.Minute)))
+	assert.True(t, cache.add("1700000000", "v2=b", now))
+
+	// entries expire once the timestamp is outside the allowed clock skew
+	assert.True(t, cache.add("1700000000", "v2=a", now.Add(3*maxClockSkew)))
+}
+
+type instance struct {
+	queries *sqlc.Queries
+	fed     *Federation
+	server  *httptest.Server
+}
+
+func newInstance(t *testing.T) *instance {
+	t.Helper()
+
+	queries := data.NewTestDB(t, t.TempDir())
+	fed := New(queries, hook.NewHooks())
+
+	r := chi.NewRouter()
+	r.Mount(BasePath, fed.Routes())
+
+	server := httptest.NewServer(r)
+	t.Cleanup(server.Close)
+
+	_, err := settings.Update(t.Context(), queries, func(se *settings.Settings) {
+		se.Meta.AppURL = server.URL + "/"
+	})
+	require.NoError(t, err)
+
+	return &instance{queries: queries, fed: fed, server: server}
+}
+
+func (i *instance) addPeer(t *testing.T, name string, other *instance, maxTLP string) sqlc.FederationPeer {
+	t.Helper()
+
+	peer, err := i.queries.CreateFederationPeer(t.Context(), sqlc.CreateFederationPeerParams{
+		Name:   name,
+		Url:    other.server.URL,
+		Secret: testSecret,
+		MaxTlp: maxTLP,
+	})
+	require.NoError(t, err)
+
+	return peer
+}
+
+func timeline(t *testing.T, queries *sqlc.Queries, ticket string) []string {
+	t.Helper()
+
+	entries, err := queries.ListTimeline(t.Context(), sqlc.ListTimelineParams{Ticket: ticket, Limit: 100})
+	require.NoError(t, err)
+
+	var messages []string
+	for _, entry := range entries {
+		messages = append(messages, entry.Message)
+	}
+
+	return messages
+}
+
+func TestFederation(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	emea, apac := newInstance(t), newInstance(t)
+
+	toAPAC := emea.addPeer(t, "SOC APAC", apac, "GREEN")
+	apac.addPeer(t, "SOC EMEA", emea, "AMBER")
+
+	remote, err := apac.queries.CreateTicket(ctx, sqlc.CreateTicketParams{Name: "Phishing wave", Open: true, Type: "incident", Schema: []byte(`{}`), State: []byte(`{"tlp": "TLP:GREEN"}`)})
+	require.NoError(t, err)
+
+	// the test ticket is TLP:AMBER, but the APAC SOC is only trusted with TLP:GREEN
+	_, err = emea.fed.Link(ctx, "test-ticket", toAPAC.ID, remote.ID)
+	require.ErrorIs(t, err, ErrNotShareable)
+
+	_, err = emea.queries.UpdateFederationPeer(ctx, sqlc.UpdateFederationPeerParams{ID: toAPAC.ID, MaxTlp: pointer.Pointer("AMBER")})
+	require.NoError(t, err)
+
+	link, err := emea.fed.Link(ctx, "test-ticket", toAPAC.ID, remote.ID)
+	require.NoError(t, err)
+	assert.Equal(t, remote.ID, link.RemoteTicket)
+	assert.Equal(t, "Phishing wave", link.RemoteName)
+	assert.True(t, link.RemoteOpen)
+
+	// the peer links back
+	fromEMEA, err := apac.queries.FederationPeerByURL(ctx, emea.server.URL)
+	require.NoError(t, err)
+
+	backlinks, err := apac.queries.ListRemoteFederationLinks(ctx, sqlc.ListRemoteFederationLinksParams{Peer: fromEMEA.ID, RemoteTicket: "test-ticket"})
+	require.NoError(t, err)
+	require.Len(t, backlinks, 1)
+	assert.Equal(t, remote.ID, backlinks[0].Ticket)
+	assert.Contains(t, timeline(t, apac.queries, remote.ID), "Linked to ticket Test Ticket in SOC EMEA")
+
+	resolved, err := emea.fed.Resolve(ctx, toAPAC.ID, remote.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "GREEN", resolved.TLP)
+
+	_, err = emea.fed.Resolve(ctx, toAPAC.ID, "unknown")
+	require.ErrorIs(t, err, ErrRemoteNotFound)
+
+	// comments and status changes are shared in both directions
+	emea.fed.share(ctx, "test-ticket", &Update{Ticket: "test-ticket", Author: "Bob Analyst", Comment: "Blocked the sender domain"})
+	apac.fed.share(ctx, remote.ID, &Update{Ticket: remote.ID, Open: pointer.Pointer(false)})
+
+	comments, err := apac.queries.ListComments(ctx, sqlc.ListCommentsParams{Ticket: remote.ID, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, comments, 1)
+	assert.Equal(t, "Bob Analyst in SOC EMEA: Blocked the sender domain", comments[0].Message)
+
+	links, err := emea.queries.ListTicketFederationLinks(ctx, "test-ticket")
+	require.NoError(t, err)
+	require.Len(t, links, 1)
+	assert.False(t, links[0].RemoteOpen)
+	assert.Contains(t, timeline(t, emea.queries, "test-ticket"), "Linked ticket Phishing wave in SOC APAC was closed")
+
+	// updates stop once the ticket is restricted
+	_, err = emea.queries.UpdateTicket(ctx, sqlc.UpdateTicketParams{ID: "test-ticket", State: []byte(`{"tlp": "RED"}`)})
+	require.NoError(t, err)
+
+	emea.fed.share(ctx, "test-ticket", &Update{Ticket: "test-ticket", Author: "Bob Analyst", Comment: "Secret details"})
+
+	comments, err = apac.queries.ListComments(ctx, sqlc.ListCommentsParams{Ticket: remote.ID, Limit: 10})
+	require.NoError(t, err)
+	assert.Len(t, comments, 1)
+}
+
+func TestFederation_UnknownPeer(t *testing.T) {
+	t.Parallel()
+
+	emea := newInstance(t)
+
+	req, err := http.NewRequestWithContext(t.Context(), http.MethodPost, emea.server.URL+BasePath+"/updates", strings.NewReader(`{"ticket":"t1"}`))
+	require.NoError(t, err)
+	req.Header.Set(instanceHeader, "https://unknown.example.com")
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+}
@@ -0,0 +1,319 @@
+package federation
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/SecurityBrewery/catalyst/app/auth/usercontext"
+	"github.com/SecurityBrewery/catalyst/app/database"
+	"github.com/SecurityBrewery/catalyst/app/database/sqlc"
+	"github.com/SecurityBrewery/catalyst/app/openapi"
+	"github.com/SecurityBrewery/catalyst/app/ticketkey"
+)
+
+type (
+	peerKey           struct{}
+	fromFederationKey struct{}
+)
+
+// Routes returns the endpoints that peers call.
+func (f *Federation) Routes() http.Handler {
+	r := chi.NewRouter()
+
+	r.Use(f.verify)
+
+	r.Get("/tickets/{id}", f.handleTicket)
+	r.Post("/links", f.handleLink)
+	r.Post("/updates", f.handleUpdate)
+
+	return r
+}
+
+// verify checks that the request was signed by a registered peer with the
+// shared secret and adds the peer to the context.
+func (f *Federation) verify(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		peer, err := f.queries.FederationPeerByURL(r.Context(), strings.TrimSuffix(r.Header.Get(instanceHeader), "/"))
+		if errors.Is(err, sql.ErrNoRows) {
+			http.Error(w, "Unknown peer", http.StatusUnauthorized)
+
+			return
+		} else if err != nil {
+			slog.ErrorContext(r.Context(), "Failed to find federation peer", "error", err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBodySize))
+		if err != nil {
+			http.Error(w, "Request body too large", http.StatusRequestEntityTooLarge)
+
+			return
+		}
+
+		if err := verifySignature(peer.Secret, r, body, f.now()); err != nil {
+			slog.WarnContext(r.Context(), "Invalid federation request", "peer", peer.Name, "error", err)
+			http.Error(w, "Invalid signature", http.StatusUnauthorized)
+
+			return
+		}
+
+		if !f.replays.add(r.Header.Get(timestampHeader), r.Header.Get(signatureHeader), f.now()) {
+			slog.WarnContext(r.Context(), "Replayed federation request", "peer", peer.Name)
+			http.Error(w, "Replayed request", http.StatusUnauthorized)
+
+			return
+		}
+
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), peerKey{}, &peer)))
+	})
+}
+
+func peerFromContext(ctx context.Context) *sqlc.FederationPeer {
+	peer, _ := ctx.Value(peerKey{}).(*sqlc.FederationPeer)
+
+	return peer
+}
+
+func isFromFederation(ctx context.Context) bool {
+	fromFederation, _ := ctx.Value(fromFederationKey{}).(bool)
+
+	return fromFederation
+}
+
+// systemContext marks the context as originating from a peer, so the change
+// is not sent back, and authenticates it as the system user.
+func (f *Federation) systemContext(ctx context.Context) (context.Context, *sqlc.User, error) {
+	systemUser, err := f.queries.SystemUser(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to find system user: %w", err)
+	}
+
+	ctx = context.WithValue(ctx, fromFederationKey{}, true)
+
+	return usercontext.UserContext(ctx, &systemUser), &systemUser, nil
+}
+
+// sharedTicket returns the summary of a local ticket by its ID or key and
+// writes a 404 if the ticket does not exist or must not be shared with the
+// peer.
+func (f *Federation) sharedTicket(w http.ResponseWriter, r *http.Request, idOrKey string) (*Ticket, bool) {
+	id, err := ticketkey.Resolve(r.Context(), f.queries, idOrKey)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Failed to resolve ticket key", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+
+		return nil, false
+	}
+
+	ticket, err := f.summary(r.Context(), id, peerFromContext(r.Context()))
+	if errors.Is(err, sql.ErrNoRows) || errors.Is(err, ErrNotShareable) {
+		http.NotFound(w, r)
+
+		return nil, false
+	} else if err != nil {
+		slog.ErrorContext(r.Context(), "Failed to load shared ticket", "ticket", id, "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+
+		return nil, false
+	}
+
+	return ticket, true
+}
+
+func (f *Federation) handleTicket(w http.ResponseWriter, r *http.Request) {
+	ticket, ok := f.sharedTicket(w, r, chi.URLParam(r, "id"))
+	if !ok {
+		return
+	}
+
+	writeJSON(w, ticket)
+}
+
+func (f *Federation) handleLink(w http.ResponseWriter, r *http.Request) {
+	var request linkRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil || request.Remote.ID == "" {
+		http.Error(w, "Invalid link request", http.StatusBadRequest)
+
+		return
+	}
+
+	ticket, ok := f.sharedTicket(w, r, request.Ticket)
+	if !ok {
+		return
+	}
+
+	peer := peerFromContext(r.Context())
+
+	if _, err := f.queries.CreateFederationLink(r.Context(), sqlc.CreateFederationLinkParams{
+		Ticket:       ticket.ID,
+		Peer:         peer.ID,
+		RemoteTicket: request.Remote.ID,
+		RemoteKey:    request.Remote.Key,
+		RemoteType:   request.Remote.Type,
+		RemoteName:   request.Remote.Name,
+		RemoteOpen:   request.Remote.Open,
+	}); err != nil {
+		slog.ErrorContext(r.Context(), "Failed to link federated ticket", "ticket", ticket.ID, "peer", peer.Name, "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+
+		return
+	}
+
+	if err := f.timeline(r.Context(), ticket.ID, fmt.Sprintf("Linked to ticket %s in %s", remoteName(&request.Remote), peer.Name)); err != nil {
+		slog.ErrorContext(r.Context(), "Failed to add timeline entry", "ticket", ticket.ID, "error", err)
+	}
+
+	writeJSON(w, ticket)
+}
+
+// handleUpdate applies an update of a remote ticket to the local tickets
+// that are linked to it and still shared with the peer.
+func (f *Federation) handleUpdate(w http.ResponseWriter, r *http.Request) {
+	var update Update
+	if err := json.NewDecoder(r.Body).Decode(&update); err != nil || update.Ticket == "" {
+		http.Error(w, "Invalid update", http.StatusBadRequest)
+
+		return
+	}
+
+	peer := peerFromContext(r.Context())
+
+	links, err := f.queries.ListRemoteFederationLinks(r.Context(), sqlc.ListRemoteFederationLinksParams{Peer: peer.ID, RemoteTicket: update.Ticket})
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Failed to list federated links", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+
+		return
+	}
+
+	if len(links) == 0 {
+		http.NotFound(w, r)
+
+		return
+	}
+
+	if update.Open != nil {
+		if err := f.queries.SetFederationLinkRemoteOpen(r.Context(), sqlc.SetFederationLinkRemoteOpenParams{
+			Peer:         peer.ID,
+			RemoteTicket: update.Ticket,
+			RemoteOpen:   *update.Open,
+		}); err != nil {
+			slog.ErrorContext(r.Context(), "Failed to update federated links", "error", err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+
+			return
+		}
+	}
+
+	for _, link := range links {
+		if !link.Share {
+			continue
+		}
+
+		if _, err := f.summary(r.Context(), link.Ticket, peer); err != nil {
+			continue // the ticket is no longer shared with the peer
+		}
+
+		if err := f.apply(r.Context(), peer, &link, &update); err != nil {
+			slog.ErrorContext(r.Context(), "Failed to apply federated update", "ticket", link.Ticket, "peer", peer.Name, "error", err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+
+			return
+		}
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (f *Federation) apply(ctx context.Context, peer *sqlc.FederationPeer, link *sqlc.FederationLink, update *Update) error {
+	if update.Open != nil {
+		status := "closed"
+		if *update.Open {
+			status = "reopened"
+		}
+
+		if err := f.timeline(ctx, link.Ticket, fmt.Sprintf("Linked ticket %s in %s was %s", remoteLinkName(link), peer.Name, status)); err != nil {
+			return err
+		}
+	}
+
+	if update.Comment == "" {
+		return nil
+	}
+
+	ctx, systemUser, err := f.systemContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	params := sqlc.CreateCommentParams{
+		Author:  systemUser.ID,
+		Message: fmt.Sprintf("%s in %s: %s", update.Author, peer.Name, update.Comment),
+		Ticket:  link.Ticket,
+	}
+
+	f.hooks.OnRecordBeforeCreateRequest.Publish(ctx, database.CommentsTable.ID, params)
+
+	comment, err := f.queries.CreateComment(ctx, params)
+	if err != nil {
+		return err
+	}
+
+	f.hooks.OnRecordAfterCreateRequest.Publish(ctx, database.CommentsTable.ID, openapi.Comment{
+		Author:  comment.Author,
+		Created: comment.Created,
+		Id:      comment.ID,
+		Message: comment.Message,
+		Ticket:  comment.Ticket,
+		Updated: comment.Updated,
+	})
+
+	return nil
+}
+
+func (f *Federation) timeline(ctx context.Context, ticket, message string) error {
+	_, err := f.queries.CreateTimeline(ctx, sqlc.CreateTimelineParams{
+		Ticket:  ticket,
+		Message: message,
+		Time:    time.Now().UTC(),
+	})
+
+	return err
+}
+
+func remoteName(ticket *Ticket) string {
+	if ticket.Key != nil {
+		return *ticket.Key
+	}
+
+	return ticket.Name
+}
+
+func remoteLinkName(link *sqlc.FederationLink) string {
+	if link.RemoteKey != nil {
+		return *link.RemoteKey
+	}
+
+	return link.RemoteName
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+
+	_ = json.NewEncoder(w).Encode(v)
+}
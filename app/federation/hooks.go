@@ -0,0 +1,96 @@
+package federation
+
+import (
+	"cmp"
+	"context"
+	"log/slog"
+	"net/http"
+
+	"github.com/SecurityBrewery/catalyst/app/database"
+	"github.com/SecurityBrewery/catalyst/app/hook"
+	"github.com/SecurityBrewery/catalyst/app/openapi"
+	"github.com/SecurityBrewery/catalyst/app/pointer"
+)
+
+// BindHooks sends new comments and status changes of linked tickets to the
+// peers. Changes that were received from a peer are not sent back.
+func (f *Federation) BindHooks() {
+	f.hooks.OnRecordAfterCreateRequest.Subscribe(func(ctx context.Context, table string, record any) {
+		if table != database.CommentsTable.ID || isFromFederation(ctx) {
+			return
+		}
+
+		comment, ok := record.(openapi.Comment)
+		if !ok {
+			return
+		}
+
+		author := comment.Author
+		if user, err := f.queries.GetUser(ctx, comment.Author); err == nil {
+			author = cmp.Or(pointer.Dereference(user.Name), user.Username)
+		}
+
+		// don't block the request on the peers
+		go f.share(context.WithoutCancel(ctx), comment.Ticket, &Update{Ticket: comment.Ticket, Author: author, Comment: comment.Message})
+	})
+
+	f.hooks.OnRecordAfterUpdateRequest.Subscribe(func(ctx context.Context, table string, record any) {
+		if table != database.TicketsTable.ID || isFromFederation(ctx) {
+			return
+		}
+
+		ticket, ok := record.(openapi.Ticket)
+		if !ok {
+			return
+		}
+
+		previous, ok := hook.Previous(ctx)
+		if !ok {
+			return
+		}
+
+		if previous, ok := previous.(openapi.Ticket); !ok || previous.Open == ticket.Open {
+			return
+		}
+
+		go f.share(context.WithoutCancel(ctx), ticket.Id, &Update{Ticket: ticket.Id, Open: &ticket.Open})
+	})
+}
+
+// share sends an update of a ticket to the peers of its links that share
+// updates, if the ticket may still be shared with them.
+func (f *Federation) share(ctx context.Context, ticket string, update *Update) {
+	links, err := f.queries.ListTicketFederationLinks(ctx, ticket)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to list federated links", "ticket", ticket, "error", err)
+
+		return
+	}
+
+	sent := map[string]bool{}
+
+	for _, link := range links {
+		if !link.Share || sent[link.Peer] {
+			continue
+		}
+
+		peer, err := f.queries.GetFederationPeer(ctx, link.Peer)
+		if err != nil {
+			slog.ErrorContext(ctx, "Failed to load federation peer", "peer", link.Peer, "error", err)
+
+			continue
+		}
+
+		if _, err := f.summary(ctx, ticket, &peer); err != nil {
+			continue // the ticket is no longer shared with the peer
+		}
+
+		if err := f.call(ctx, &peer, http.MethodPost, "/updates", update, nil); err != nil {
+			slog.ErrorContext(ctx, "Failed to share ticket update", "ticket", ticket, "peer", peer.Name, "error", err)
+
+			continue
+		}
+
+		sent[link.Peer] = true
+	}
+}
@@ -0,0 +1,267 @@
+// Package feed imports threat intel indicator feeds without access to the
+// public internet. Feeds are uploaded as files or fetched from an internal
+// mirror, and must be signed with one of the ed25519 keys listed in the
+// trusted_keys file of the feeds directory.
+//
+// A feed lists one indicator per line. Empty lines and lines starting with
+// # are ignored. Indicators are stored in their canonical form, lines that
+// are not a domain, IP address, hash, URL, email address or CVE ID are
+// skipped.
+package feed
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/SecurityBrewery/catalyst/app/canonical"
+	"github.com/SecurityBrewery/catalyst/app/database/sqlc"
+)
+
+const (
+	trustedKeysFile = "trusted_keys"
+
+	// SignatureSuffix is appended to the URL of a mirrored feed to fetch its
+	// detached signature.
+	SignatureSuffix = ".sig"
+
+	// maxSize limits the size of a feed and its signature.
+	maxSize = 64 << 20
+)
+
+var (
+	ErrInvalidSignature = errors.New("invalid feed signature")
+	ErrInvalidName      = errors.New("invalid feed name")
+
+	validName = regexp.MustCompile(`^[a-z0-9_-]+$`)
+)
+
+// Result describes an imported feed.
+type Result struct {
+	Feed *sqlc.Feed
+	// Skipped counts the lines that are not an indicator.
+	Skipped int
+}
+
+type indicator struct {
+	Kind  string
+	Value string
+}
+
+// Loader imports feeds into the database.
+type Loader struct {
+	queries     *sqlc.Queries
+	trustedKeys []ed25519.PublicKey
+	client      *http.Client
+}
+
+// New creates the feeds folder in the data directory if needed and reads the
+// trusted keys from it.
+func New(queries *sqlc.Queries, dir string) (*Loader, error) {
+	dir = filepath.Join(dir, "feeds")
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create feed directory: %w", err)
+	}
+
+	trustedKeys, err := readTrustedKeys(filepath.Join(dir, trustedKeysFile))
+	if err != nil {
+		return nil, err
+	}
+
+	return &Loader{
+		queries:     queries,
+		trustedKeys: trustedKeys,
+		client:      &http.Client{Timeout: time.Minute},
+	}, nil
+}
+
+// Import verifies the signature of an uploaded feed and replaces the
+// indicators of the feed with its content.
+func (l *Loader) Import(ctx context.Context, name, source string, content, signature []byte) (*Result, error) {
+	if !validName.MatchString(name) {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidName, name)
+	}
+
+	if err := l.verify(content, signature); err != nil {
+		return nil, err
+	}
+
+	indicators, skipped, err := parse(content)
+	if err != nil {
+		return nil, err
+	}
+
+	feed, err := l.queries.SetFeed(ctx, sqlc.SetFeedParams{
+		Name:       name,
+		Source:     source,
+		Indicators: int64(len(indicators)),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := l.queries.DeleteFeedIndicators(ctx, name); err != nil {
+		return nil, err
+	}
+
+	for _, indicator := range indicators {
+		if err := l.queries.InsertFeedIndicator(ctx, sqlc.InsertFeedIndicatorParams{
+			Feed:  name,
+			Kind:  indicator.Kind,
+			Value: indicator.Value,
+		}); err != nil {
+			return nil, fmt.Errorf("failed to store indicator %s: %w", indicator.Value, err)
+		}
+	}
+
+	return &Result{Feed: &feed, Skipped: skipped}, nil
+}
+
+// Mirror fetches a feed and its signature from an internal mirror and
+// imports it. The signature is read from the URL of the feed with the
+// suffix .sig.
+func (l *Loader) Mirror(ctx context.Context, name, url string) (*Result, error) {
+	content, err := l.fetch(ctx, url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch feed: %w", err)
+	}
+
+	signature, err := l.fetch(ctx, url+SignatureSuffix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch feed signature: %w", err)
+	}
+
+	return l.Import(ctx, name, url, content, signature)
+}
+
+func (l *Loader) fetch(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := l.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	b, err := io.ReadAll(io.LimitReader(resp.Body, maxSize+1))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(b) > maxSize {
+		return nil, fmt.Errorf("larger than %d bytes", maxSize)
+	}
+
+	return b, nil
+}
+
+func (l *Loader) verify(content, signature []byte) error {
+	if len(l.trustedKeys) == 0 {
+		return errors.New("no trusted feed keys configured")
+	}
+
+	// signatures are accepted raw or base64 encoded
+	if len(signature) != ed25519.SignatureSize {
+		if decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(signature))); err == nil {
+			signature = decoded
+		}
+	}
+
+	for _, key := range l.trustedKeys {
+		if ed25519.Verify(key, content, signature) {
+			return nil
+		}
+	}
+
+	return ErrInvalidSignature
+}
+
+func parse(content []byte) ([]indicator, int, error) {
+	var (
+		indicators []indicator
+		skipped    int
+		seen       = map[string]bool{}
+	)
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		kind := canonical.Detect(line)
+		if kind == "" {
+			skipped++
+
+			continue
+		}
+
+		value, err := canonical.Canonicalize(kind, line)
+		if err != nil {
+			skipped++
+
+			continue
+		}
+
+		if !seen[value] {
+			seen[value] = true
+			indicators = append(indicators, indicator{Kind: kind, Value: value})
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, 0, fmt.Errorf("failed to read feed: %w", err)
+	}
+
+	return indicators, skipped, nil
+}
+
+func readTrustedKeys(path string) ([]ed25519.PublicKey, error) {
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read trusted feed keys: %w", err)
+	}
+
+	var keys []ed25519.PublicKey
+
+	scanner := bufio.NewScanner(bytes.NewReader(b))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		key, err := base64.StdEncoding.DecodeString(line)
+		if err != nil || len(key) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("invalid trusted feed key %q", line)
+		}
+
+		keys = append(keys, key)
+	}
+
+	return keys, nil
+}
@@ -0,0 +1,116 @@
+package feed
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/SecurityBrewery/catalyst/app/data"
+	"github.com/SecurityBrewery/catalyst/app/database/sqlc"
+)
+
+var testFeed = []byte(`# test feed
+Evil.Example.com.
+198.51.100.7
+hxxps://evil[.]example.com/payload
+evil.example.com
+not an indicator
+`)
+
+func newTestLoader(t *testing.T) (*Loader, *sqlc.Queries, ed25519.PrivateKey) {
+	t.Helper()
+
+	publicKey, privateKey, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	queries := data.NewTestDB(t, dir)
+
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "feeds"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "feeds", trustedKeysFile), []byte(base64.StdEncoding.EncodeToString(publicKey)+"\n"), 0o600))
+
+	l, err := New(queries, dir)
+	require.NoError(t, err)
+
+	return l, queries, privateKey
+}
+
+func TestLoader_Import(t *testing.T) {
+	t.Parallel()
+
+	l, queries, privateKey := newTestLoader(t)
+
+	result, err := l.Import(t.Context(), "test", "upload", testFeed, ed25519.Sign(privateKey, testFeed))
+	require.NoError(t, err)
+
+	assert.Equal(t, "test", result.Feed.Name)
+	assert.Equal(t, "upload", result.Feed.Source)
+	assert.EqualValues(t, 3, result.Feed.Indicators)
+	assert.Equal(t, 1, result.Skipped)
+
+	matches, err := queries.MatchFeedIndicators(t.Context(), "evil.example.com")
+	require.NoError(t, err)
+	require.Len(t, matches, 1)
+	assert.Equal(t, "domain", matches[0].Kind)
+
+	// a new import replaces the indicators
+	updated := []byte("203.0.113.1\n")
+	result, err = l.Import(t.Context(), "test", "upload", updated, []byte(base64.StdEncoding.EncodeToString(ed25519.Sign(privateKey, updated))))
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, result.Feed.Indicators)
+
+	matches, err = queries.MatchFeedIndicators(t.Context(), "evil.example.com")
+	require.NoError(t, err)
+	assert.Empty(t, matches)
+}
+
+func TestLoader_Import_invalid(t *testing.T) {
+	t.Parallel()
+
+	l, queries, privateKey := newTestLoader(t)
+
+	_, otherKey, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+
+	_, err = l.Import(t.Context(), "test", "upload", testFeed, ed25519.Sign(otherKey, testFeed))
+	require.ErrorIs(t, err, ErrInvalidSignature)
+
+	_, err = l.Import(t.Context(), "../test", "upload", testFeed, ed25519.Sign(privateKey, testFeed))
+	require.ErrorIs(t, err, ErrInvalidName)
+
+	feeds, err := queries.ListFeeds(t.Context())
+	require.NoError(t, err)
+	assert.Empty(t, feeds)
+}
+
+func TestLoader_Mirror(t *testing.T) {
+	t.Parallel()
+
+	l, _, privateKey := newTestLoader(t)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/feeds/test.txt", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write(testFeed)
+	})
+	mux.HandleFunc("/feeds/test.txt.sig", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write(ed25519.Sign(privateKey, testFeed))
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	result, err := l.Mirror(t.Context(), "mirror", server.URL+"/feeds/test.txt")
+	require.NoError(t, err)
+	assert.Equal(t, server.URL+"/feeds/test.txt", result.Feed.Source)
+	assert.EqualValues(t, 3, result.Feed.Indicators)
+
+	_, err = l.Mirror(t.Context(), "mirror", server.URL+"/feeds/missing.txt")
+	require.Error(t, err)
+}
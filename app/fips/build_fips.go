@@ -0,0 +1,6 @@
+//go:build fips
+
+package fips
+
+// build is set by the fips build tag.
+const build = true
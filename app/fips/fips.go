@@ -0,0 +1,92 @@
+// Package fips restricts the cryptography of Catalyst to FIPS 140-3
+// approved algorithms, as required by government deployments.
+//
+// The mode is enabled with the fips build tag or the --fips flag and needs
+// the Go Cryptographic Module in FIPS 140-3 mode, i.e. a binary built with
+// GOFIPS140=v1.0.0 or run with GODEBUG=fips140=on. The module restricts TLS
+// and the primitives of the standard library, this package covers the
+// algorithms Catalyst chooses itself: passwords are hashed with
+// PBKDF2-HMAC-SHA256 instead of bcrypt and SMTP connections use TLS 1.2 or
+// later with approved cipher suites. Tokens (HMAC-SHA256, ECDSA P-256),
+// ticket encryption (AES-GCM) and signatures (Ed25519) are approved in both
+// modes.
+package fips
+
+import (
+	"context"
+	"crypto/fips140"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync/atomic"
+
+	"github.com/SecurityBrewery/catalyst/app/database/sqlc"
+)
+
+var ErrModuleInactive = errors.New("the Go Cryptographic Module is not in FIPS 140-3 mode, build with GOFIPS140=v1.0.0 or run with GODEBUG=fips140=on")
+
+var enabled atomic.Bool
+
+// Required reports whether FIPS mode is requested by the flag or the build.
+func Required(flag bool) bool {
+	return flag || build
+}
+
+// Enable switches Catalyst to FIPS mode. It fails if the Go Cryptographic
+// Module does not run in FIPS 140-3 mode.
+func Enable() error {
+	if !fips140.Enabled() {
+		return ErrModuleInactive
+	}
+
+	enabled.Store(true)
+
+	return nil
+}
+
+// Enabled reports whether Catalyst runs in FIPS mode.
+func Enabled() bool {
+	return enabled.Load()
+}
+
+// Check validates the data of an instance at startup in FIPS mode. Users
+// with bcrypt password hashes cannot log in until their password is reset,
+// they are logged as a warning so that admins can reset them.
+func Check(ctx context.Context, queries *sqlc.Queries) error {
+	count, err := queries.CountBcryptPasswordHashes(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to count password hashes: %w", err)
+	}
+
+	if count > 0 {
+		slog.WarnContext(ctx, "Users with password hashes that are not FIPS approved must reset their password", "users", count)
+	}
+
+	return nil
+}
+
+// TLSConfig returns the TLS configuration of outgoing connections. In FIPS
+// mode only TLS 1.2 and 1.3 with approved cipher suites and curves are used.
+func TLSConfig(serverName string) *tls.Config {
+	return tlsConfig(serverName, Enabled())
+}
+
+func tlsConfig(serverName string, approved bool) *tls.Config {
+	config := &tls.Config{
+		ServerName: serverName,
+		MinVersion: tls.VersionTLS12,
+	}
+
+	if approved {
+		config.CipherSuites = []uint16{
+			tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+			tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
+			tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+			tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+		}
+		config.CurvePreferences = []tls.CurveID{tls.CurveP256, tls.CurveP384, tls.CurveP521}
+	}
+
+	return config
+}
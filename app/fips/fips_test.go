@@ -0,0 +1,36 @@
+package fips
+
+import (
+	"crypto/fips140"
+	"crypto/tls"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnable(t *testing.T) {
+	t.Parallel()
+
+	if fips140.Enabled() {
+		t.Skip("the Go Cryptographic Module runs in FIPS 140-3 mode")
+	}
+
+	require.ErrorIs(t, Enable(), ErrModuleInactive)
+	assert.False(t, Enabled())
+}
+
+func TestTLSConfig(t *testing.T) {
+	t.Parallel()
+
+	config := tlsConfig("mail.example.com", false)
+
+	assert.Equal(t, "mail.example.com", config.ServerName)
+	assert.Equal(t, uint16(tls.VersionTLS12), config.MinVersion)
+	assert.Empty(t, config.CipherSuites)
+
+	config = tlsConfig("mail.example.com", true)
+
+	assert.Contains(t, config.CipherSuites, tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256)
+	assert.NotContains(t, config.CurvePreferences, tls.X25519)
+}
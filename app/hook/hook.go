@@ -1,17 +1,66 @@
 package hook
 
-import "context"
+import (
+	"context"
+	"maps"
+	"slices"
+	"sync"
+)
 
 type Hook struct {
+	mux         sync.RWMutex
 	subscribers []func(ctx context.Context, table string, record any)
+	registered  map[string]func(ctx context.Context, table string, record any)
 }
 
 func (h *Hook) Publish(ctx context.Context, table string, record any) {
-	for _, subscriber := range h.subscribers {
+	h.mux.RLock()
+	subscribers := make([]func(ctx context.Context, table string, record any), 0, len(h.subscribers)+len(h.registered))
+	subscribers = append(subscribers, h.subscribers...)
+
+	for _, name := range slices.Sorted(maps.Keys(h.registered)) {
+		subscribers = append(subscribers, h.registered[name])
+	}
+	h.mux.RUnlock()
+
+	for _, subscriber := range subscribers {
 		subscriber(ctx, table, record)
 	}
 }
 
 func (h *Hook) Subscribe(fn func(ctx context.Context, table string, record any)) {
+	h.mux.Lock()
+	defer h.mux.Unlock()
+
 	h.subscribers = append(h.subscribers, fn)
 }
+
+// Register adds a named subscriber at runtime. Registered subscribers run
+// after the subscribers added with Subscribe, ordered by name. Registering a
+// name again replaces the previous subscriber.
+func (h *Hook) Register(name string, fn func(ctx context.Context, table string, record any)) {
+	h.mux.Lock()
+	defer h.mux.Unlock()
+
+	if h.registered == nil {
+		h.registered = make(map[string]func(ctx context.Context, table string, record any))
+	}
+
+	h.registered[name] = fn
+}
+
+// Unregister removes a subscriber added with Register.
+func (h *Hook) Unregister(name string) {
+	h.mux.Lock()
+	defer h.mux.Unlock()
+
+	delete(h.registered, name)
+}
+
+// Registered returns the names of all subscribers added with Register.
+func (h *Hook) Registered() []string {
+	h.mux.RLock()
+	defer h.mux.RUnlock()
+
+	return slices.Sorted(maps.Keys(h.registered))
+}
@@ -124,3 +124,43 @@ func TestHook_Subscribe(t *testing.T) {
 		})
 	}
 }
+
+func TestHook_Register(t *testing.T) {
+	t.Parallel()
+
+	h := &Hook{}
+
+	var calls int
+
+	h.Register("counter", func(_ context.Context, _ string, _ any) { calls++ })
+	h.Publish(t.Context(), "tickets", nil)
+
+	if calls != 1 {
+		t.Errorf("Hook.Register() calls = %v, want 1", calls)
+	}
+
+	if got := h.Registered(); len(got) != 1 || got[0] != "counter" {
+		t.Errorf("Hook.Registered() = %v, want [counter]", got)
+	}
+
+	h.Unregister("counter")
+	h.Publish(t.Context(), "tickets", nil)
+
+	if calls != 1 {
+		t.Errorf("Hook.Unregister() calls = %v, want 1", calls)
+	}
+}
+
+func TestHooks_Hook(t *testing.T) {
+	t.Parallel()
+
+	hooks := NewHooks()
+
+	if h, ok := hooks.Hook("OnRecordAfterCreateRequest"); !ok || h != hooks.OnRecordAfterCreateRequest {
+		t.Errorf("Hooks.Hook() did not return OnRecordAfterCreateRequest")
+	}
+
+	if _, ok := hooks.Hook("unknown"); ok {
+		t.Errorf("Hooks.Hook() found unknown hook")
+	}
+}
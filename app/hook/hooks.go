@@ -23,3 +23,22 @@ func NewHooks() *Hooks {
 		OnRecordAfterDeleteRequest:  &Hook{},
 	}
 }
+
+// Hook returns the hook for an event name like "OnRecordAfterCreateRequest",
+// so extensions can register subscribers by name at runtime.
+func (h *Hooks) Hook(event string) (*Hook, bool) {
+	hooks := map[string]*Hook{
+		"OnRecordsListRequest":        h.OnRecordsListRequest,
+		"OnRecordViewRequest":         h.OnRecordViewRequest,
+		"OnRecordBeforeCreateRequest": h.OnRecordBeforeCreateRequest,
+		"OnRecordAfterCreateRequest":  h.OnRecordAfterCreateRequest,
+		"OnRecordBeforeUpdateRequest": h.OnRecordBeforeUpdateRequest,
+		"OnRecordAfterUpdateRequest":  h.OnRecordAfterUpdateRequest,
+		"OnRecordBeforeDeleteRequest": h.OnRecordBeforeDeleteRequest,
+		"OnRecordAfterDeleteRequest":  h.OnRecordAfterDeleteRequest,
+	}
+
+	hook, ok := hooks[event]
+
+	return hook, ok
+}
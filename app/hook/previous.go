@@ -0,0 +1,19 @@
+package hook
+
+import "context"
+
+type previousKey struct{}
+
+// WithPrevious returns a context that carries the record as it was before an
+// update, so that subscribers of OnRecordAfterUpdateRequest can tell which
+// fields changed.
+func WithPrevious(ctx context.Context, record any) context.Context {
+	return context.WithValue(ctx, previousKey{}, record)
+}
+
+// Previous returns the record before the update, if the context carries it.
+func Previous(ctx context.Context) (any, bool) {
+	record := ctx.Value(previousKey{})
+
+	return record, record != nil
+}
@@ -0,0 +1,275 @@
+// Package invitation lets admins invite users by email. The invitee gets a
+// link with a random token to set up a local account, which is created with
+// the groups of the invitation. Only the hash of the token is stored.
+package invitation
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/SecurityBrewery/catalyst/app/auth/password"
+	"github.com/SecurityBrewery/catalyst/app/database/sqlc"
+	"github.com/SecurityBrewery/catalyst/app/settings"
+)
+
+var (
+	ErrInvalid    = errors.New("invalid or expired invitation")
+	ErrUserExists = errors.New("a user with this email or username already exists")
+	ErrPending    = errors.New("the email already has a pending invitation")
+	ErrEmail      = errors.New("invalid email")
+	ErrGroup      = errors.New("group does not exist")
+)
+
+const (
+	subject  = "You have been invited to {APP_NAME}"
+	bodyText = `Hello,
+You have been invited to {APP_NAME}.
+Click on the link below to set up your account:
+
+{ACTION_URL}
+
+Thanks, {APP_NAME} team`
+	bodyHTML = `<p>Hello,</p>
+<p>You have been invited to {APP_NAME}.</p>
+<p>Click on the button below to set up your account.</p>
+<p>
+  <a class="btn" href="{ACTION_URL}" target="_blank" rel="noopener">Accept invitation</a>
+</p>
+<p>
+  Thanks,<br/>
+  {APP_NAME} team
+</p>`
+)
+
+// Sender delivers emails, usually the mail.Mailer.
+type Sender interface {
+	Send(ctx context.Context, to, subject, plainTextBody, htmlBody string) error
+}
+
+// Sent is a created or renewed invitation. The link is only known right
+// after the token was issued, so it is returned to the admin in case the
+// email could not be sent.
+type Sent struct {
+	Invitation sqlc.Invitation
+	Link       string
+	Mailed     bool
+}
+
+// Create invites an email address to join with the given groups. The
+// invitation expires after the duration of the verification token.
+func Create(ctx context.Context, queries *sqlc.Queries, mailer Sender, email, name string, groups []string, invitedBy string) (*Sent, error) {
+	email = strings.TrimSpace(email)
+	if email == "" || !strings.Contains(email, "@") {
+		return nil, fmt.Errorf("%w %q", ErrEmail, email)
+	}
+
+	if _, err := queries.UserByEmail(ctx, &email); err == nil {
+		return nil, ErrUserExists
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	for _, group := range groups {
+		if _, err := queries.GetGroup(ctx, group); errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: %q", ErrGroup, group)
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to get group: %w", err)
+		}
+	}
+
+	groupsJSON, err := json.Marshal(append([]string{}, groups...))
+	if err != nil {
+		return nil, err
+	}
+
+	s, err := settings.Load(ctx, queries)
+	if err != nil {
+		return nil, err
+	}
+
+	token, tokenHash, err := newToken()
+	if err != nil {
+		return nil, err
+	}
+
+	var inviter *string
+	if invitedBy != "" {
+		inviter = &invitedBy
+	}
+
+	invitation, err := queries.CreateInvitation(ctx, sqlc.CreateInvitationParams{
+		Email:     email,
+		Name:      name,
+		Groups:    groupsJSON,
+		TokenHash: tokenHash,
+		InvitedBy: inviter,
+		Expires:   expires(s),
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "UNIQUE constraint failed") {
+			return nil, ErrPending
+		}
+
+		return nil, fmt.Errorf("failed to create invitation: %w", err)
+	}
+
+	return send(ctx, s, mailer, invitation, token), nil
+}
+
+// Resend issues a new token for a pending invitation, which invalidates the
+// previous link, and extends the expiry.
+func Resend(ctx context.Context, queries *sqlc.Queries, mailer Sender, id string) (*Sent, error) {
+	s, err := settings.Load(ctx, queries)
+	if err != nil {
+		return nil, err
+	}
+
+	token, tokenHash, err := newToken()
+	if err != nil {
+		return nil, err
+	}
+
+	invitation, err := queries.RenewInvitation(ctx, sqlc.RenewInvitationParams{
+		ID:        id,
+		TokenHash: tokenHash,
+		Expires:   expires(s),
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrInvalid
+		}
+
+		return nil, fmt.Errorf("failed to renew invitation: %w", err)
+	}
+
+	return send(ctx, s, mailer, invitation, token), nil
+}
+
+// Accept creates an active local user for a pending invitation. The
+// password must meet the password policy.
+func Accept(ctx context.Context, queries *sqlc.Queries, token, username, name, plaintext string) (*sqlc.User, error) {
+	invitation, err := queries.InvitationByToken(ctx, hashToken(token))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrInvalid
+		}
+
+		return nil, fmt.Errorf("failed to get invitation: %w", err)
+	}
+
+	if invitation.AcceptedBy != nil || time.Now().After(invitation.Expires) {
+		return nil, ErrInvalid
+	}
+
+	if username == "" {
+		return nil, errors.New("username is required")
+	}
+
+	if _, err := queries.UserByUserName(ctx, username); err == nil {
+		return nil, ErrUserExists
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	if err := password.Check(ctx, queries, "", plaintext); err != nil {
+		return nil, err
+	}
+
+	passwordHash, tokenKey, err := password.Hash(plaintext)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	if name == "" {
+		name = invitation.Name
+	}
+
+	user, err := queries.CreateUser(ctx, sqlc.CreateUserParams{
+		Name:         &name,
+		Email:        &invitation.Email,
+		Username:     username,
+		PasswordHash: passwordHash,
+		TokenKey:     tokenKey,
+		Active:       true,
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "UNIQUE constraint failed") {
+			return nil, ErrUserExists
+		}
+
+		return nil, fmt.Errorf("failed to create user: %w", err)
+	}
+
+	if err := password.RecordHistory(ctx, queries, user.ID, passwordHash); err != nil {
+		return nil, err
+	}
+
+	for _, group := range Groups(invitation.Groups) {
+		if err := queries.AssignGroupToUser(ctx, sqlc.AssignGroupToUserParams{UserID: user.ID, GroupID: group}); err != nil {
+			return nil, fmt.Errorf("failed to assign group %q: %w", group, err)
+		}
+	}
+
+	if _, err := queries.AcceptInvitation(ctx, sqlc.AcceptInvitationParams{ID: invitation.ID, AcceptedBy: &user.ID}); err != nil {
+		return nil, fmt.Errorf("failed to accept invitation: %w", err)
+	}
+
+	return &user, nil
+}
+
+// Groups decodes the groups column of an invitation.
+func Groups(raw []byte) []string {
+	groups := []string{}
+	_ = json.Unmarshal(raw, &groups)
+
+	return groups
+}
+
+func send(ctx context.Context, s *settings.Settings, mailer Sender, invitation sqlc.Invitation, token string) *Sent {
+	link := strings.TrimSuffix(s.Meta.AppURL, "/") + "/ui/invitation?token=" + url.QueryEscape(token)
+
+	replacer := strings.NewReplacer("{APP_NAME}", s.Meta.AppName, "{ACTION_URL}", link)
+
+	sent := &Sent{Invitation: invitation, Link: link}
+
+	if mailer != nil {
+		if err := mailer.Send(ctx, invitation.Email, replacer.Replace(subject), replacer.Replace(bodyText), replacer.Replace(bodyHTML)); err != nil {
+			slog.ErrorContext(ctx, "Failed to send invitation", "invitation", invitation.ID, "error", err)
+		} else {
+			sent.Mailed = true
+		}
+	}
+
+	return sent
+}
+
+func expires(s *settings.Settings) time.Time {
+	return time.Now().UTC().Add(time.Duration(s.RecordVerificationToken.Duration) * time.Second)
+}
+
+func newToken() (token, tokenHash string, err error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+
+	token = hex.EncodeToString(b)
+
+	return token, hashToken(token), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+
+	return hex.EncodeToString(sum[:])
+}
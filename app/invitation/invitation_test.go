@@ -0,0 +1,110 @@
+package invitation_test
+
+import (
+	"context"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/SecurityBrewery/catalyst/app/auth/password"
+	"github.com/SecurityBrewery/catalyst/app/data"
+	"github.com/SecurityBrewery/catalyst/app/invitation"
+)
+
+type fakeMailer struct {
+	bodies []string
+}
+
+func (f *fakeMailer) Send(_ context.Context, _, _, plainTextBody, _ string) error {
+	f.bodies = append(f.bodies, plainTextBody)
+
+	return nil
+}
+
+func token(t *testing.T, link string) string {
+	t.Helper()
+
+	u, err := url.Parse(link)
+	require.NoError(t, err)
+
+	return u.Query().Get("token")
+}
+
+func TestInvitation(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	queries := data.NewTestDB(t, t.TempDir())
+	mailer := &fakeMailer{}
+
+	sent, err := invitation.Create(ctx, queries, mailer, "new.analyst@catalyst-soar.com", "New Analyst", []string{"analyst"}, "u_admin")
+	require.NoError(t, err)
+	assert.True(t, sent.Mailed)
+	require.Len(t, mailer.bodies, 1)
+	assert.Contains(t, mailer.bodies[0], sent.Link)
+	assert.NotContains(t, sent.Invitation.TokenHash, token(t, sent.Link), "only the hash of the token is stored")
+
+	_, err = invitation.Create(ctx, queries, mailer, "new.analyst@catalyst-soar.com", "", nil, "u_admin")
+	require.ErrorIs(t, err, invitation.ErrPending)
+
+	_, err = invitation.Create(ctx, queries, mailer, data.AnalystEmail, "", nil, "u_admin")
+	require.ErrorIs(t, err, invitation.ErrUserExists)
+
+	_, err = invitation.Create(ctx, queries, mailer, "other@catalyst-soar.com", "", []string{"does-not-exist"}, "u_admin")
+	require.ErrorIs(t, err, invitation.ErrGroup)
+
+	// resending invalidates the previous link
+	resent, err := invitation.Resend(ctx, queries, mailer, sent.Invitation.ID)
+	require.NoError(t, err)
+	assert.NotEqual(t, sent.Link, resent.Link)
+
+	_, err = invitation.Accept(ctx, queries, token(t, sent.Link), "new_analyst", "", "Correct-Horse-1")
+	require.ErrorIs(t, err, invitation.ErrInvalid)
+
+	_, err = invitation.Accept(ctx, queries, token(t, resent.Link), "new_analyst", "", "short")
+	require.ErrorIs(t, err, password.ErrPolicy)
+
+	user, err := invitation.Accept(ctx, queries, token(t, resent.Link), "new_analyst", "", "Correct-Horse-1")
+	require.NoError(t, err)
+	assert.True(t, user.Active)
+	assert.Equal(t, "New Analyst", *user.Name)
+	require.NoError(t, password.Compare(user.Passwordhash, "Correct-Horse-1"))
+
+	groups, err := queries.ListUserGroups(ctx, user.ID)
+	require.NoError(t, err)
+	require.Len(t, groups, 1)
+	assert.Equal(t, "analyst", groups[0].ID)
+
+	// an invitation can only be accepted once
+	_, err = invitation.Accept(ctx, queries, token(t, resent.Link), "another_analyst", "", "Correct-Horse-1")
+	require.ErrorIs(t, err, invitation.ErrInvalid)
+
+	_, err = invitation.Resend(ctx, queries, mailer, sent.Invitation.ID)
+	require.ErrorIs(t, err, invitation.ErrInvalid)
+
+	stored, err := queries.GetInvitation(ctx, sent.Invitation.ID)
+	require.NoError(t, err)
+	assert.Equal(t, &user.ID, stored.AcceptedBy)
+}
+
+func TestAccept_expired(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	queries := data.NewTestDB(t, t.TempDir())
+
+	sent, err := invitation.Create(ctx, queries, nil, "new.analyst@catalyst-soar.com", "", nil, "")
+	require.NoError(t, err)
+	assert.False(t, sent.Mailed)
+
+	_, err = queries.WriteDB.ExecContext(ctx, "UPDATE invitations SET expires = datetime('now', '-1 minute')")
+	require.NoError(t, err)
+
+	_, err = invitation.Accept(ctx, queries, token(t, sent.Link), "new_analyst", "", "Correct-Horse-1")
+	require.ErrorIs(t, err, invitation.ErrInvalid)
+
+	_, err = queries.UserByUserName(ctx, "new_analyst")
+	require.Error(t, err)
+}
@@ -0,0 +1,139 @@
+// Package listener opens the listeners of the HTTP server. Catalyst can
+// listen on several addresses at once, e.g. on IPv4 and IPv6 and on a Unix
+// socket for a local reverse proxy, each with its own TLS certificate and
+// with the PROXY protocol for load balancers that do not terminate HTTP.
+//
+// A listener is configured with an address and optional query parameters:
+//
+//	:8090                                     TCP on all IPv4 and IPv6 addresses
+//	tcp4://0.0.0.0:8090                       TCP on IPv4 only
+//	tcp6://[::1]:8090                         TCP on IPv6 only
+//	unix:///run/catalyst/catalyst.sock        Unix socket
+//	:8443?tls_cert=cert.pem&tls_key=key.pem   TLS with a certificate and key
+//	:8090?proxy_protocol=true                 PROXY protocol v1 and v2
+package listener
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/SecurityBrewery/catalyst/app/fips"
+)
+
+var ErrInvalidListener = errors.New("invalid listener")
+
+// Config is a listener of the HTTP server.
+type Config struct {
+	Network       string
+	Address       string
+	TLSCertFile   string
+	TLSKeyFile    string
+	ProxyProtocol bool
+}
+
+// Parse parses the configuration of a listener.
+func Parse(spec string) (Config, error) {
+	address, query, _ := strings.Cut(spec, "?")
+
+	config := Config{Network: "tcp", Address: address}
+
+	if network, rest, ok := strings.Cut(address, "://"); ok {
+		config.Network, config.Address = network, rest
+	}
+
+	switch config.Network {
+	case "tcp", "tcp4", "tcp6":
+		if _, _, err := net.SplitHostPort(config.Address); err != nil {
+			return Config{}, fmt.Errorf("%w %q: %w", ErrInvalidListener, spec, err)
+		}
+	case "unix":
+		if config.Address == "" {
+			return Config{}, fmt.Errorf("%w %q: missing socket path", ErrInvalidListener, spec)
+		}
+	default:
+		return Config{}, fmt.Errorf("%w %q: unknown network %q, use tcp, tcp4, tcp6 or unix", ErrInvalidListener, spec, config.Network)
+	}
+
+	values, err := url.ParseQuery(query)
+	if err != nil {
+		return Config{}, fmt.Errorf("%w %q: %w", ErrInvalidListener, spec, err)
+	}
+
+	for key := range values {
+		switch key {
+		case "tls_cert", "tls_key", "proxy_protocol":
+		default:
+			return Config{}, fmt.Errorf("%w %q: unknown option %q", ErrInvalidListener, spec, key)
+		}
+	}
+
+	config.TLSCertFile, config.TLSKeyFile = values.Get("tls_cert"), values.Get("tls_key")
+	if (config.TLSCertFile == "") != (config.TLSKeyFile == "") {
+		return Config{}, fmt.Errorf("%w %q: tls_cert and tls_key must be set together", ErrInvalidListener, spec)
+	}
+
+	if value := values.Get("proxy_protocol"); value != "" {
+		if config.ProxyProtocol, err = strconv.ParseBool(value); err != nil {
+			return Config{}, fmt.Errorf("%w %q: invalid proxy_protocol: %w", ErrInvalidListener, spec, err)
+		}
+	}
+
+	return config, nil
+}
+
+// TLS reports whether the listener serves TLS.
+func (c *Config) TLS() bool {
+	return c.TLSCertFile != ""
+}
+
+func (c *Config) String() string {
+	return c.Network + "://" + c.Address
+}
+
+// Listen opens the listener. A stale Unix socket of a previous run is
+// removed first.
+func (c *Config) Listen(ctx context.Context) (net.Listener, error) {
+	var tlsConfig *tls.Config
+
+	if c.TLS() {
+		certificate, err := tls.LoadX509KeyPair(c.TLSCertFile, c.TLSKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load the TLS certificate of %s: %w", c, err)
+		}
+
+		tlsConfig = fips.TLSConfig("")
+		tlsConfig.Certificates = []tls.Certificate{certificate}
+		tlsConfig.NextProtos = []string{"h2", "http/1.1"}
+	}
+
+	if c.Network == "unix" {
+		if err := os.Remove(c.Address); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("failed to remove the socket %s: %w", c.Address, err)
+		}
+	}
+
+	var lc net.ListenConfig
+
+	l, err := lc.Listen(ctx, c.Network, c.Address)
+	if err != nil {
+		return nil, err
+	}
+
+	// the PROXY header is sent before the TLS handshake
+	if c.ProxyProtocol {
+		l = &proxyListener{Listener: l}
+	}
+
+	if tlsConfig != nil {
+		l = tls.NewListener(l, tlsConfig)
+	}
+
+	return l, nil
+}
@@ -0,0 +1,100 @@
+package listener
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		spec    string
+		want    Config
+		wantErr bool
+	}{
+		{spec: ":8090", want: Config{Network: "tcp", Address: ":8090"}},
+		{spec: "tcp4://0.0.0.0:8090", want: Config{Network: "tcp4", Address: "0.0.0.0:8090"}},
+		{spec: "tcp6://[::]:8090", want: Config{Network: "tcp6", Address: "[::]:8090"}},
+		{spec: "unix:///run/catalyst.sock", want: Config{Network: "unix", Address: "/run/catalyst.sock"}},
+		{
+			spec: ":8443?tls_cert=cert.pem&tls_key=key.pem&proxy_protocol=true",
+			want: Config{Network: "tcp", Address: ":8443", TLSCertFile: "cert.pem", TLSKeyFile: "key.pem", ProxyProtocol: true},
+		},
+		{spec: "8090", wantErr: true},
+		{spec: "udp://:8090", wantErr: true},
+		{spec: "unix://", wantErr: true},
+		{spec: ":8443?tls_cert=cert.pem", wantErr: true},
+		{spec: ":8090?proxy_protocol=maybe", wantErr: true},
+		{spec: ":8090?unknown=1", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := Parse(tt.spec)
+			if tt.wantErr {
+				require.ErrorIs(t, err, ErrInvalidListener)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestListen(t *testing.T) {
+	t.Parallel()
+
+	socket := filepath.Join(t.TempDir(), "catalyst.sock")
+
+	for _, spec := range []string{"tcp4://127.0.0.1:0", "unix://" + socket, "tcp4://127.0.0.1:0?proxy_protocol=true"} {
+		t.Run(spec, func(t *testing.T) {
+			t.Parallel()
+
+			config, err := Parse(spec)
+			require.NoError(t, err)
+
+			l, err := config.Listen(t.Context())
+			require.NoError(t, err)
+
+			server := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_, _ = io.WriteString(w, r.RemoteAddr)
+			})}
+
+			go func() { _ = server.Serve(l) }()
+
+			t.Cleanup(func() { _ = server.Close() })
+
+			conn, err := net.Dial(l.Addr().Network(), l.Addr().String())
+			require.NoError(t, err)
+
+			defer conn.Close()
+
+			if config.ProxyProtocol {
+				_, err = io.WriteString(conn, "PROXY TCP4 192.0.2.1 192.0.2.2 5678 80\r\n")
+				require.NoError(t, err)
+			}
+
+			_, err = fmt.Fprint(conn, "GET / HTTP/1.1\r\nHost: catalyst\r\nConnection: close\r\n\r\n")
+			require.NoError(t, err)
+
+			response, err := io.ReadAll(conn)
+			require.NoError(t, err)
+			assert.Contains(t, string(response), "200 OK")
+
+			if config.ProxyProtocol {
+				assert.Contains(t, string(response), "192.0.2.1:5678")
+			}
+		})
+	}
+}
@@ -0,0 +1,184 @@
+package listener
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// proxyHeaderTimeout limits the time to read the PROXY header, so clients
+// that connect without sending one do not hold connections open.
+const proxyHeaderTimeout = 10 * time.Second
+
+var (
+	proxyV1Prefix    = []byte("PROXY ")
+	proxyV2Signature = []byte("\r\n\r\n\x00\r\nQUIT\n")
+
+	errInvalidProxyHeader = errors.New("invalid PROXY protocol header")
+)
+
+// proxyListener accepts connections that start with a PROXY protocol
+// header. The header is read on the first use of the connection, so a slow
+// client does not block the listener.
+type proxyListener struct {
+	net.Listener
+}
+
+func (l *proxyListener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+
+	return &proxyConn{Conn: conn, reader: bufio.NewReader(conn)}, nil
+}
+
+// proxyConn reports the client address of the PROXY header as its remote
+// address. Connections without a valid header are closed.
+type proxyConn struct {
+	net.Conn
+
+	reader *bufio.Reader
+
+	once       sync.Once
+	remoteAddr net.Addr
+	err        error
+}
+
+func (c *proxyConn) Read(b []byte) (int, error) {
+	c.once.Do(c.readHeader)
+
+	if c.err != nil {
+		return 0, c.err
+	}
+
+	return c.reader.Read(b)
+}
+
+func (c *proxyConn) RemoteAddr() net.Addr {
+	c.once.Do(c.readHeader)
+
+	if c.remoteAddr != nil {
+		return c.remoteAddr
+	}
+
+	return c.Conn.RemoteAddr()
+}
+
+func (c *proxyConn) readHeader() {
+	if err := c.SetReadDeadline(time.Now().Add(proxyHeaderTimeout)); err != nil {
+		c.err = err
+
+		return
+	}
+
+	c.remoteAddr, c.err = readProxyHeader(c.reader)
+
+	if err := c.SetReadDeadline(time.Time{}); err != nil && c.err == nil {
+		c.err = err
+	}
+
+	if c.err != nil {
+		_ = c.Close()
+	}
+}
+
+// readProxyHeader reads a PROXY protocol v1 or v2 header. The address is
+// nil for headers without an address, e.g. health checks of the load
+// balancer.
+func readProxyHeader(r *bufio.Reader) (net.Addr, error) {
+	signature, err := r.Peek(len(proxyV1Prefix))
+	if err != nil {
+		return nil, err
+	}
+
+	if bytes.Equal(signature, proxyV1Prefix) {
+		return readProxyV1(r)
+	}
+
+	signature, err = r.Peek(len(proxyV2Signature))
+	if err != nil {
+		return nil, err
+	}
+
+	if bytes.Equal(signature, proxyV2Signature) {
+		return readProxyV2(r)
+	}
+
+	return nil, errInvalidProxyHeader
+}
+
+// readProxyV1 reads a header like "PROXY TCP4 192.0.2.1 192.0.2.2 5678 80".
+func readProxyV1(r *bufio.Reader) (net.Addr, error) {
+	// the longest v1 header has 107 bytes
+	line, err := r.ReadSlice('\n')
+	if err != nil || len(line) > 107 || !bytes.HasSuffix(line, []byte("\r\n")) {
+		return nil, errInvalidProxyHeader
+	}
+
+	fields := strings.Fields(string(line))
+
+	switch {
+	case len(fields) == 2 && fields[1] == "UNKNOWN":
+		return nil, nil
+	case len(fields) != 6 || (fields[1] != "TCP4" && fields[1] != "TCP6"):
+		return nil, errInvalidProxyHeader
+	}
+
+	ip := net.ParseIP(fields[2])
+	port, err := strconv.ParseUint(fields[4], 10, 16)
+
+	if ip == nil || err != nil {
+		return nil, errInvalidProxyHeader
+	}
+
+	return &net.TCPAddr{IP: ip, Port: int(port)}, nil
+}
+
+// readProxyV2 reads a binary header. Addresses of other families than TCP
+// over IPv4 and IPv6, and the TLVs after the addresses, are skipped.
+func readProxyV2(r *bufio.Reader) (net.Addr, error) {
+	header := make([]byte, 16)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, err
+	}
+
+	version, command, family := header[12]>>4, header[12]&0x0f, header[13]
+	if version != 2 || command > 1 {
+		return nil, errInvalidProxyHeader
+	}
+
+	payload := make([]byte, binary.BigEndian.Uint16(header[14:16]))
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return nil, err
+	}
+
+	// LOCAL connections of the load balancer itself
+	if command == 0 {
+		return nil, nil
+	}
+
+	switch family {
+	case 0x11:
+		if len(payload) < 12 {
+			return nil, errInvalidProxyHeader
+		}
+
+		return &net.TCPAddr{IP: net.IP(payload[0:4]), Port: int(binary.BigEndian.Uint16(payload[8:10]))}, nil
+	case 0x21:
+		if len(payload) < 36 {
+			return nil, errInvalidProxyHeader
+		}
+
+		return &net.TCPAddr{IP: net.IP(payload[0:16]), Port: int(binary.BigEndian.Uint16(payload[32:34]))}, nil
+	default:
+		return nil, nil
+	}
+}
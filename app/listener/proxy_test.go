@@ -0,0 +1,98 @@
+package listener
+
+import (
+	"bufio"
+	"bytes"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_readProxyHeader(t *testing.T) {
+	t.Parallel()
+
+	v2 := func(command, family byte, addresses ...byte) []byte {
+		header := append([]byte{}, proxyV2Signature...)
+		header = append(header, 0x20|command, family, 0, byte(len(addresses)))
+
+		return append(header, addresses...)
+	}
+
+	tests := []struct {
+		name    string
+		header  []byte
+		want    net.Addr
+		wantErr bool
+	}{
+		{
+			name:   "v1 TCP4",
+			header: []byte("PROXY TCP4 192.0.2.1 192.0.2.2 5678 80\r\n"),
+			want:   &net.TCPAddr{IP: net.ParseIP("192.0.2.1"), Port: 5678},
+		},
+		{
+			name:   "v1 TCP6",
+			header: []byte("PROXY TCP6 2001:db8::1 2001:db8::2 5678 80\r\n"),
+			want:   &net.TCPAddr{IP: net.ParseIP("2001:db8::1"), Port: 5678},
+		},
+		{
+			name:   "v1 UNKNOWN",
+			header: []byte("PROXY UNKNOWN\r\n"),
+		},
+		{
+			name:    "v1 invalid address",
+			header:  []byte("PROXY TCP4 invalid 192.0.2.2 5678 80\r\n"),
+			wantErr: true,
+		},
+		{
+			name:   "v2 IPv4",
+			header: v2(1, 0x11, 192, 0, 2, 1, 192, 0, 2, 2, 0x16, 0x2e, 0, 80),
+			want:   &net.TCPAddr{IP: net.IP{192, 0, 2, 1}, Port: 5678},
+		},
+		{
+			name: "v2 IPv6 with TLV",
+			header: v2(1, 0x21,
+				0x20, 0x01, 0x0d, 0xb8, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1,
+				0x20, 0x01, 0x0d, 0xb8, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 2,
+				0x16, 0x2e, 0, 80,
+				0x04, 0, 1, 0),
+			want: &net.TCPAddr{IP: net.ParseIP("2001:db8::1"), Port: 5678},
+		},
+		{
+			name:   "v2 LOCAL",
+			header: v2(0, 0x00),
+		},
+		{
+			name:    "v2 truncated address",
+			header:  v2(1, 0x11, 192, 0, 2, 1),
+			wantErr: true,
+		},
+		{
+			name:    "no header",
+			header:  []byte("GET / HTTP/1.1\r\n\r\n"),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			r := bufio.NewReader(bytes.NewReader(append(tt.header, "GET /"...)))
+
+			got, err := readProxyHeader(r)
+			if tt.wantErr {
+				require.Error(t, err)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+
+			rest, err := r.Peek(5)
+			require.NoError(t, err)
+			assert.Equal(t, "GET /", string(rest), "the header must be consumed")
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package logsink
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/SecurityBrewery/catalyst/app/settings"
+)
+
+const (
+	defaultMaxSizeMB = 100
+	defaultMaxFiles  = 5
+)
+
+// fileWriter appends the records as JSON lines to <name>.log. Once the file
+// exceeds the maximum size it is rotated to <name>.log.1, the previous
+// rotated files are moved up by one and the oldest is removed.
+type fileWriter struct {
+	path     string
+	maxSize  int64
+	maxFiles int
+	file     *os.File
+	size     int64
+}
+
+func newFileWriter(config *settings.LogSink, dir string) (*fileWriter, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create logs directory: %w", err)
+	}
+
+	w := &fileWriter{
+		path:     filepath.Join(dir, config.Name+".log"),
+		maxSize:  int64(defaultMaxSizeMB) << 20,
+		maxFiles: defaultMaxFiles,
+	}
+
+	if config.MaxSizeMB > 0 {
+		w.maxSize = int64(config.MaxSizeMB) << 20
+	}
+
+	if config.MaxFiles > 0 {
+		w.maxFiles = config.MaxFiles
+	}
+
+	if err := w.open(); err != nil {
+		return nil, err
+	}
+
+	return w, nil
+}
+
+func (w *fileWriter) open() error {
+	f, err := os.OpenFile(w.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+
+		return err
+	}
+
+	w.file = f
+	w.size = info.Size()
+
+	return nil
+}
+
+func (w *fileWriter) write(_ context.Context, records []Record) error {
+	var buf bytes.Buffer
+
+	encoder := json.NewEncoder(&buf)
+
+	for _, record := range records {
+		if err := encoder.Encode(record); err != nil {
+			return err
+		}
+	}
+
+	if w.size > 0 && w.size+int64(buf.Len()) > w.maxSize {
+		if err := w.rotate(); err != nil {
+			return err
+		}
+	}
+
+	n, err := w.file.Write(buf.Bytes())
+	w.size += int64(n)
+
+	return err
+}
+
+func (w *fileWriter) rotate() error {
+	if err := w.file.Close(); err != nil {
+		return err
+	}
+
+	// the file is opened again even if the rotation failed, to keep logging
+	return errors.Join(w.shift(), w.open())
+}
+
+func (w *fileWriter) shift() error {
+	_ = os.Remove(fmt.Sprintf("%s.%d", w.path, w.maxFiles))
+
+	for i := w.maxFiles - 1; i >= 1; i-- {
+		if err := os.Rename(fmt.Sprintf("%s.%d", w.path, i), fmt.Sprintf("%s.%d", w.path, i+1)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	return os.Rename(w.path, w.path+".1")
+}
+
+func (w *fileWriter) close() error {
+	return w.file.Close()
+}
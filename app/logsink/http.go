@@ -0,0 +1,61 @@
+package logsink
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/SecurityBrewery/catalyst/app/settings"
+)
+
+// httpWriter posts each batch of records as a JSON array.
+type httpWriter struct {
+	url     string
+	headers map[string]string
+	client  *http.Client
+}
+
+func newHTTPWriter(config *settings.LogSink) *httpWriter {
+	return &httpWriter{url: config.URL, headers: config.Headers, client: &http.Client{}}
+}
+
+func (w *httpWriter) write(ctx context.Context, records []Record) error {
+	body, err := json.Marshal(records)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	for key, value := range w.headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+
+		return fmt.Errorf("%s: %s", resp.Status, message)
+	}
+
+	return nil
+}
+
+func (w *httpWriter) close() error {
+	w.client.CloseIdleConnections()
+
+	return nil
+}
@@ -0,0 +1,389 @@
+// Package logsink forwards the application log and the audit log of record
+// changes to external systems, so that Catalyst events land in the SIEM.
+// Sinks are syslog servers that accept RFC 5424 messages over TLS, HTTP
+// endpoints that receive batches of JSON records, and files with rotation.
+//
+// Records are forwarded in the background. Like the application log, records
+// are dropped for sinks that cannot keep up and the errors of the sinks go to
+// stderr, logging them would feed them back into the sinks.
+package logsink
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/SecurityBrewery/catalyst/app/applog"
+	"github.com/SecurityBrewery/catalyst/app/auth/usercontext"
+	"github.com/SecurityBrewery/catalyst/app/database"
+	"github.com/SecurityBrewery/catalyst/app/database/sqlc"
+	"github.com/SecurityBrewery/catalyst/app/hook"
+	"github.com/SecurityBrewery/catalyst/app/redact"
+	"github.com/SecurityBrewery/catalyst/app/settings"
+)
+
+const (
+	defaultLevel   = slog.LevelInfo
+	reloadInterval = time.Minute
+	flushInterval  = time.Second
+	bufferSize     = 4096
+	batchSize      = 100
+	writeTimeout   = 10 * time.Second
+)
+
+// Record is a record of the application log or the audit log, as it is sent
+// to the sinks.
+type Record struct {
+	Stream    string         `json:"stream"`
+	Time      time.Time      `json:"time"`
+	Level     string         `json:"level"`
+	Message   string         `json:"message"`
+	Component string         `json:"component,omitempty"`
+	User      string         `json:"user,omitempty"`
+	Attrs     map[string]any `json:"attrs,omitempty"`
+	// Action, Collection and Record describe the change of an audit record.
+	Action     string `json:"action,omitempty"`
+	Collection string `json:"collection,omitempty"`
+	Record     string `json:"record,omitempty"`
+}
+
+// writer delivers batches of records to a sink.
+type writer interface {
+	write(ctx context.Context, records []Record) error
+	close() error
+}
+
+type sink struct {
+	name    string
+	level   slog.Level
+	streams []string
+	writer  writer
+	records chan Record
+	done    chan struct{}
+}
+
+// Forwarder sends the records to the sinks of the settings, which are
+// reloaded every minute.
+type Forwarder struct {
+	queries *sqlc.Queries
+	logs    *applog.Log
+	dir     string
+
+	mu       sync.Mutex
+	sinks    []*sink
+	config   []byte
+	redactor *redact.Redactor
+}
+
+// New returns a forwarder that writes the file sinks to the logs folder of
+// the data directory and forwards the changes of the hooks to the audit
+// stream.
+func New(queries *sqlc.Queries, logs *applog.Log, hooks *hook.Hooks, dir string) *Forwarder {
+	f := &Forwarder{
+		queries: queries,
+		logs:    logs,
+		dir:     filepath.Join(dir, "logs"),
+	}
+
+	hooks.OnRecordAfterCreateRequest.Subscribe(func(ctx context.Context, table string, record any) {
+		f.audit(ctx, database.CreateAction, table, record)
+	})
+	hooks.OnRecordAfterUpdateRequest.Subscribe(func(ctx context.Context, table string, record any) {
+		f.audit(ctx, database.UpdateAction, table, record)
+	})
+	hooks.OnRecordAfterDeleteRequest.Subscribe(func(ctx context.Context, table string, record any) {
+		f.audit(ctx, database.DeleteAction, table, record)
+	})
+
+	return f
+}
+
+// Start forwards the records until the context is canceled.
+func (f *Forwarder) Start(ctx context.Context) {
+	entries, cancel := f.logs.Subscribe()
+
+	go func() {
+		defer cancel()
+		defer f.stop()
+
+		f.reload(ctx)
+
+		ticker := time.NewTicker(reloadInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				f.reload(ctx)
+			case entry := <-entries:
+				f.forward(Record{
+					Stream:    settings.LogStreamApp,
+					Time:      entry.Created.UTC(),
+					Level:     applog.LevelName(entry.Level),
+					Message:   entry.Message,
+					Component: entry.Component,
+					User:      entry.User,
+					Attrs:     entry.Attrs,
+				}, entry.Level)
+			}
+		}
+	}()
+}
+
+func (f *Forwarder) audit(ctx context.Context, action, collection string, record any) {
+	r := Record{
+		Stream:     settings.LogStreamAudit,
+		Time:       time.Now().UTC(),
+		Level:      applog.LevelName(slog.LevelInfo),
+		Message:    fmt.Sprintf("Record %sd", action),
+		Action:     action,
+		Collection: collection,
+		Record:     recordID(record),
+	}
+
+	if user, ok := usercontext.UserFromContext(ctx); ok {
+		r.User = user.ID
+	}
+
+	f.forward(r, slog.LevelInfo)
+}
+
+// recordID returns the id of a record of a hook, which are API types or
+// query parameters.
+func recordID(record any) string {
+	b, err := json.Marshal(record)
+	if err != nil {
+		return ""
+	}
+
+	var r struct {
+		ID string `json:"id"`
+	}
+
+	_ = json.Unmarshal(b, &r)
+
+	return r.ID
+}
+
+func (f *Forwarder) forward(record Record, level slog.Level) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if len(f.sinks) == 0 {
+		return
+	}
+
+	record.Message = f.redactor.String(record.Message)
+
+	// the attributes are shared with the other subscribers of the log
+	if len(record.Attrs) > 0 {
+		attrs := make(map[string]any, len(record.Attrs))
+
+		for key, value := range record.Attrs {
+			if s, ok := value.(string); ok {
+				value = f.redactor.String(s)
+			}
+
+			attrs[key] = value
+		}
+
+		record.Attrs = attrs
+	}
+
+	for _, s := range f.sinks {
+		if !slices.Contains(s.streams, record.Stream) || (record.Stream == settings.LogStreamApp && level < s.level) {
+			continue
+		}
+
+		select {
+		case s.records <- record:
+		default:
+		}
+	}
+}
+
+// reload starts the sinks of the settings if they changed.
+func (f *Forwarder) reload(ctx context.Context) {
+	se, err := settings.Load(ctx, f.queries)
+	if err != nil {
+		if ctx.Err() == nil {
+			fmt.Fprintln(os.Stderr, "failed to load log sinks:", err)
+		}
+
+		return
+	}
+
+	redactor, err := redact.Load(ctx, f.queries)
+	if err != nil {
+		if ctx.Err() == nil {
+			fmt.Fprintln(os.Stderr, "failed to load secrets of log sinks:", err)
+		}
+
+		return
+	}
+
+	config, err := json.Marshal(se.LogSinks)
+	if err != nil {
+		return
+	}
+
+	f.mu.Lock()
+	f.redactor = redactor
+
+	if bytes.Equal(config, f.config) {
+		f.mu.Unlock()
+
+		return
+	}
+
+	previous := f.sinks
+	f.sinks = nil
+	f.config = config
+	f.mu.Unlock()
+
+	// the previous sinks are closed first, they may write the same files
+	closeSinks(previous)
+
+	var sinks []*sink
+
+	for _, c := range se.LogSinks {
+		s, err := f.newSink(&c)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to start log sink %s: %v\n", c.Name, err)
+
+			continue
+		}
+
+		sinks = append(sinks, s)
+	}
+
+	f.mu.Lock()
+	f.sinks = sinks
+	f.mu.Unlock()
+}
+
+func (f *Forwarder) stop() {
+	f.mu.Lock()
+	previous := f.sinks
+	f.sinks = nil
+	f.config = nil
+	f.mu.Unlock()
+
+	closeSinks(previous)
+}
+
+func closeSinks(sinks []*sink) {
+	for _, s := range sinks {
+		close(s.records)
+	}
+
+	for _, s := range sinks {
+		<-s.done
+	}
+}
+
+func (f *Forwarder) newSink(config *settings.LogSink) (*sink, error) {
+	level := defaultLevel
+
+	if config.Level != "" {
+		var err error
+		if level, err = applog.ParseLevel(config.Level); err != nil {
+			return nil, err
+		}
+	}
+
+	w, err := newWriter(config, f.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	streams := config.Streams
+	if len(streams) == 0 {
+		streams = []string{settings.LogStreamApp, settings.LogStreamAudit}
+	}
+
+	s := &sink{
+		name:    config.Name,
+		level:   level,
+		streams: streams,
+		writer:  w,
+		records: make(chan Record, bufferSize),
+		done:    make(chan struct{}),
+	}
+
+	go s.run()
+
+	return s, nil
+}
+
+func newWriter(config *settings.LogSink, dir string) (writer, error) {
+	switch config.Type {
+	case settings.LogSinkSyslog:
+		return newSyslogWriter(config)
+	case settings.LogSinkHTTP:
+		return newHTTPWriter(config), nil
+	case settings.LogSinkFile:
+		return newFileWriter(config, dir)
+	default:
+		return nil, fmt.Errorf("unknown log sink type %q", config.Type)
+	}
+}
+
+// run writes the records in batches until the records are closed. Batches
+// that fail are dropped, the sink is retried with the next batch.
+func (s *sink) run() {
+	defer close(s.done)
+
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]Record, 0, batchSize)
+
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), writeTimeout)
+		defer cancel()
+
+		if err := s.writer.write(ctx, batch); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to forward %d records to log sink %s: %v\n", len(batch), s.name, err)
+		}
+
+		batch = batch[:0]
+	}
+
+	defer func() {
+		flush()
+
+		if err := s.writer.close(); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to close log sink %s: %v\n", s.name, err)
+		}
+	}()
+
+	for {
+		select {
+		case record, ok := <-s.records:
+			if !ok {
+				return
+			}
+
+			batch = append(batch, record)
+			if len(batch) == batchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
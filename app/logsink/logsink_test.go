@@ -0,0 +1,249 @@
+package logsink
+
+import (
+	"bufio"
+	"crypto/tls"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/SecurityBrewery/catalyst/app/applog"
+	"github.com/SecurityBrewery/catalyst/app/auth/usercontext"
+	"github.com/SecurityBrewery/catalyst/app/data"
+	"github.com/SecurityBrewery/catalyst/app/database"
+	"github.com/SecurityBrewery/catalyst/app/hook"
+	"github.com/SecurityBrewery/catalyst/app/openapi"
+	"github.com/SecurityBrewery/catalyst/app/settings"
+)
+
+func readLines(t *testing.T, path string) []Record {
+	t.Helper()
+
+	b, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+
+	require.NoError(t, err)
+
+	var records []Record
+
+	for line := range strings.Lines(string(b)) {
+		var record Record
+		require.NoError(t, json.Unmarshal([]byte(line), &record))
+
+		records = append(records, record)
+	}
+
+	return records
+}
+
+func TestForwarder(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	queries := data.NewTestDB(t, dir)
+
+	var (
+		mu       sync.Mutex
+		received []Record
+		auth     string
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var batch []Record
+		if err := json.NewDecoder(r.Body).Decode(&batch); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+
+			return
+		}
+
+		mu.Lock()
+		received = append(received, batch...)
+		auth = r.Header.Get("Authorization")
+		mu.Unlock()
+	}))
+	t.Cleanup(server.Close)
+
+	_, err := settings.Update(t.Context(), queries, func(s *settings.Settings) {
+		s.LogSinks = []settings.LogSink{
+			{Name: "siem", Type: settings.LogSinkHTTP, Streams: []string{settings.LogStreamAudit}, URL: server.URL, Headers: map[string]string{"Authorization": "Bearer siem-secret-token"}},
+			{Name: "archive", Type: settings.LogSinkFile, Level: "warn"},
+		}
+	})
+	require.NoError(t, err)
+
+	logs := applog.New(queries)
+	hooks := hook.NewHooks()
+
+	f := New(queries, logs, hooks, dir)
+	f.Start(t.Context())
+
+	logger := slog.New(logs.Handler(slog.DiscardHandler))
+
+	user, err := queries.GetUser(t.Context(), "u_bob_analyst")
+	require.NoError(t, err)
+
+	ctx := usercontext.UserContext(t.Context(), &user)
+
+	// the sinks are started in the background
+	require.Eventually(t, func() bool {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+
+		return len(f.sinks) == 2
+	}, 5*time.Second, 10*time.Millisecond)
+
+	logger.InfoContext(ctx, "below the level of the file sink")
+	logger.ErrorContext(ctx, "Failed to call API", "token", "siem-secret-token", "status", 401)
+	hooks.OnRecordAfterCreateRequest.Publish(ctx, database.TicketsTable.ID, openapi.Ticket{Id: "test-ticket"})
+
+	archive := filepath.Join(dir, "logs", "archive.log")
+
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+
+		return len(received) == 1 && len(readLines(t, archive)) == 2
+	}, 5*time.Second, 10*time.Millisecond)
+
+	mu.Lock()
+	assert.Equal(t, "Bearer siem-secret-token", auth)
+	assert.Equal(t, settings.LogStreamAudit, received[0].Stream)
+	assert.Equal(t, database.CreateAction, received[0].Action)
+	assert.Equal(t, database.TicketsTable.ID, received[0].Collection)
+	assert.Equal(t, "test-ticket", received[0].Record)
+	assert.Equal(t, "u_bob_analyst", received[0].User)
+	mu.Unlock()
+
+	// the audit records are forwarded directly, so the order of the
+	// streams is not fixed
+	records := readLines(t, archive)
+	slices.SortFunc(records, func(a, b Record) int { return strings.Compare(a.Stream, b.Stream) })
+
+	assert.Equal(t, "Failed to call API", records[0].Message)
+	assert.Equal(t, "error", records[0].Level)
+	assert.Equal(t, "logsink", records[0].Component)
+	assert.Equal(t, map[string]any{"token": "********", "status": float64(401)}, records[0].Attrs)
+	assert.Equal(t, settings.LogStreamAudit, records[1].Stream)
+}
+
+func TestFileWriter_rotate(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	w, err := newFileWriter(&settings.LogSink{Name: "archive", MaxFiles: 2}, dir)
+	require.NoError(t, err)
+
+	w.maxSize = 100
+
+	for i := range 5 {
+		require.NoError(t, w.write(t.Context(), []Record{{Stream: settings.LogStreamApp, Message: strconv.Itoa(i)}}))
+	}
+
+	require.NoError(t, w.close())
+
+	path := filepath.Join(dir, "archive.log")
+
+	// each record fills a file, the oldest are removed
+	assert.Equal(t, "4", readLines(t, path)[0].Message)
+	assert.Equal(t, "3", readLines(t, path+".1")[0].Message)
+	assert.Equal(t, "2", readLines(t, path+".2")[0].Message)
+	assert.NoFileExists(t, path+".3")
+
+	// the size of an existing file counts
+	w, err = newFileWriter(&settings.LogSink{Name: "archive"}, dir)
+	require.NoError(t, err)
+	assert.Positive(t, w.size)
+	require.NoError(t, w.close())
+}
+
+func TestSyslogWriter(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewUnstartedServer(nil)
+	server.StartTLS()
+	t.Cleanup(server.Close)
+
+	// a syslog server with the certificate of the test server
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{Certificates: server.TLS.Certificates, MinVersion: tls.VersionTLS12})
+	require.NoError(t, err)
+
+	t.Cleanup(func() { listener.Close() })
+
+	messages := make(chan string, 10)
+
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		r := bufio.NewReader(conn)
+
+		for {
+			length, err := r.ReadString(' ')
+			if err != nil {
+				return
+			}
+
+			n, _ := strconv.Atoi(strings.TrimSpace(length))
+			message := make([]byte, n)
+
+			if _, err := io.ReadFull(r, message); err != nil {
+				return
+			}
+
+			messages <- string(message)
+		}
+	}()
+
+	ca := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+
+	w, err := newSyslogWriter(&settings.LogSink{Name: "siem", Type: settings.LogSinkSyslog, Address: listener.Addr().String(), CA: string(ca)})
+	require.NoError(t, err)
+
+	w.hostname = "catalyst.example.com"
+
+	created := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	require.NoError(t, w.write(t.Context(), []Record{
+		{Stream: settings.LogStreamApp, Time: created, Level: "error", Message: "Failed to run reaction"},
+		{Stream: settings.LogStreamAudit, Time: created, Level: "info", Message: "Record deleted", Action: "delete"},
+	}))
+	require.NoError(t, w.close())
+
+	assert.Equal(t, `<131>1 2025-06-01T12:00:00.000000Z catalyst.example.com catalyst - app - {"stream":"app","time":"2025-06-01T12:00:00Z","level":"error","message":"Failed to run reaction"}`, <-messages)
+	assert.Equal(t, `<110>1 2025-06-01T12:00:00.000000Z catalyst.example.com catalyst - audit - {"stream":"audit","time":"2025-06-01T12:00:00Z","level":"info","message":"Record deleted","action":"delete"}`, <-messages)
+
+	// an unknown certificate is rejected
+	w, err = newSyslogWriter(&settings.LogSink{Name: "siem", Type: settings.LogSinkSyslog, Address: server.Listener.Addr().String()})
+	require.NoError(t, err)
+	require.Error(t, w.write(t.Context(), []Record{{Stream: settings.LogStreamApp}}))
+}
+
+func Test_severity(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, 7, severity("debug"))
+	assert.Equal(t, 6, severity("info"))
+	assert.Equal(t, 4, severity("warn"))
+	assert.Equal(t, 3, severity("error"))
+	assert.Equal(t, 6, severity(""))
+}
@@ -0,0 +1,152 @@
+package logsink
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"strconv"
+
+	"github.com/SecurityBrewery/catalyst/app/settings"
+)
+
+const (
+	appName         = "catalyst"
+	syslogTimestamp = "2006-01-02T15:04:05.000000Z07:00"
+
+	// facilities of RFC 5424
+	facilityLocal0 = 16
+	facilityAudit  = 13
+)
+
+// syslogWriter sends RFC 5424 messages with the octet counting framing of
+// RFC 5425 over TLS. The message is the record as JSON, which most SIEMs
+// parse. The connection is opened on the first write and again after a
+// failed write.
+type syslogWriter struct {
+	address  string
+	tls      *tls.Config
+	hostname string
+	conn     net.Conn
+}
+
+func newSyslogWriter(config *settings.LogSink) (*syslogWriter, error) {
+	host, _, err := net.SplitHostPort(config.Address)
+	if err != nil {
+		return nil, err
+	}
+
+	tlsConfig := &tls.Config{ServerName: host, MinVersion: tls.VersionTLS12}
+
+	if config.CA != "" {
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM([]byte(config.CA)) {
+			return nil, errors.New("invalid CA certificate")
+		}
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		hostname = "-"
+	}
+
+	return &syslogWriter{address: config.Address, tls: tlsConfig, hostname: hostname}, nil
+}
+
+func (w *syslogWriter) write(ctx context.Context, records []Record) error {
+	var buf bytes.Buffer
+
+	for _, record := range records {
+		message, err := w.message(&record)
+		if err != nil {
+			return err
+		}
+
+		buf.WriteString(strconv.Itoa(len(message)))
+		buf.WriteByte(' ')
+		buf.Write(message)
+	}
+
+	if w.conn == nil {
+		dialer := &tls.Dialer{Config: w.tls}
+
+		conn, err := dialer.DialContext(ctx, "tcp", w.address)
+		if err != nil {
+			return err
+		}
+
+		w.conn = conn
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = w.conn.SetWriteDeadline(deadline)
+	}
+
+	if _, err := w.conn.Write(buf.Bytes()); err != nil {
+		_ = w.close()
+
+		return err
+	}
+
+	return nil
+}
+
+// message formats a record like
+// <134>1 2025-06-01T12:00:00.000000Z host catalyst - app - {"stream":"app",...}.
+func (w *syslogWriter) message(record *Record) ([]byte, error) {
+	msg, err := json.Marshal(record)
+	if err != nil {
+		return nil, err
+	}
+
+	facility := facilityLocal0
+	if record.Stream == settings.LogStreamAudit {
+		facility = facilityAudit
+	}
+
+	header := fmt.Sprintf("<%d>1 %s %s %s - %s - ",
+		facility*8+severity(record.Level),
+		record.Time.UTC().Format(syslogTimestamp),
+		w.hostname,
+		appName,
+		record.Stream,
+	)
+
+	return append([]byte(header), msg...), nil
+}
+
+func (w *syslogWriter) close() error {
+	if w.conn == nil {
+		return nil
+	}
+
+	err := w.conn.Close()
+	w.conn = nil
+
+	return err
+}
+
+// severity returns the syslog severity of a level.
+func severity(level string) int {
+	var l slog.Level
+	if err := l.UnmarshalText([]byte(level)); err != nil {
+		return 6
+	}
+
+	switch {
+	case l >= slog.LevelError:
+		return 3 // error
+	case l >= slog.LevelWarn:
+		return 4 // warning
+	case l >= slog.LevelInfo:
+		return 6 // informational
+	default:
+		return 7 // debug
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/wneessen/go-mail"
 
 	"github.com/SecurityBrewery/catalyst/app/database/sqlc"
+	"github.com/SecurityBrewery/catalyst/app/fips"
 	"github.com/SecurityBrewery/catalyst/app/settings"
 )
 
@@ -96,6 +97,10 @@ func mailClient(settings *settings.Settings) (*mail.Client, error) {
 		opts = append(opts, mail.WithSSL())
 	}
 
+	if fips.Enabled() {
+		opts = append(opts, mail.WithTLSConfig(fips.TLSConfig(settings.SMTP.Host)))
+	}
+
 	if settings.SMTP.LocalName != "" {
 		opts = append(opts, mail.WithHELO(settings.SMTP.LocalName))
 	}
@@ -0,0 +1,271 @@
+// Package maintenance silences planned changes. A maintenance window has a
+// time range and a filter of alert sources and ticket types. While a window
+// is active, new alerts of its sources are tagged with the window, and the
+// notifications of matching tickets are suppressed. The escalations and task
+// timers of matching tickets are paused, their due times are postponed by
+// the time the ticket spent in maintenance.
+package maintenance
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/SecurityBrewery/catalyst/app/database/sqlc"
+)
+
+const checkInterval = time.Minute
+
+// Filter selects the alerts and tickets of a window. Empty lists match
+// everything. A ticket matches the sources if one of its alerts came from a
+// source, so that a window for a single source does not silence all
+// tickets.
+type Filter struct {
+	Sources []string `json:"sources,omitempty"`
+	Types   []string `json:"types,omitempty"`
+}
+
+func ParseFilter(data []byte) (Filter, error) {
+	var filter Filter
+	if len(data) == 0 {
+		return filter, nil
+	}
+
+	err := json.Unmarshal(data, &filter)
+
+	return filter, err
+}
+
+// MatchesAlert reports whether an alert of the source is covered by the
+// filter. Ticket types do not apply to alerts.
+func (f *Filter) MatchesAlert(source string) bool {
+	return matchAny(f.Sources, source)
+}
+
+// MatchesTicket reports whether a ticket of the type, promoted from alerts of
+// the sources, is covered by the filter.
+func (f *Filter) MatchesTicket(ticketType string, sources []string) bool {
+	if !matchAny(f.Types, ticketType) {
+		return false
+	}
+
+	return len(f.Sources) == 0 || slices.ContainsFunc(sources, func(source string) bool { return matchAny(f.Sources, source) })
+}
+
+func matchAny(values []string, value string) bool {
+	return len(values) == 0 || slices.ContainsFunc(values, func(v string) bool { return strings.EqualFold(v, value) })
+}
+
+// active returns the windows that are active at the time.
+func active(ctx context.Context, queries *sqlc.Queries, now time.Time) ([]sqlc.MaintenanceWindow, error) {
+	return overlapping(ctx, queries, now, now.Add(time.Second))
+}
+
+// overlapping returns the windows that are active at some time between since
+// and until.
+func overlapping(ctx context.Context, queries *sqlc.Queries, since, until time.Time) ([]sqlc.MaintenanceWindow, error) {
+	return queries.ListOverlappingMaintenanceWindows(ctx, sqlc.ListOverlappingMaintenanceWindowsParams{
+		Since: since.UTC().Format(time.DateTime),
+		Until: until.UTC().Format(time.DateTime),
+	})
+}
+
+// AlertWindow returns the id of the first active window that covers an alert
+// of the source, or nil if there is none.
+func AlertWindow(ctx context.Context, queries *sqlc.Queries, source string, now time.Time) (*string, error) {
+	windows, err := active(ctx, queries, now)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, window := range windows {
+		filter, err := ParseFilter(window.Filter)
+		if err != nil {
+			slog.ErrorContext(ctx, "Invalid maintenance window filter", "window", window.ID, "error", err)
+
+			continue
+		}
+
+		if filter.MatchesAlert(source) {
+			return &window.ID, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// Covers reports whether an active window covers the ticket.
+func Covers(ctx context.Context, queries *sqlc.Queries, ticket string, now time.Time) (bool, error) {
+	windows, err := active(ctx, queries, now)
+	if err != nil || len(windows) == 0 {
+		return false, err
+	}
+
+	t, err := queries.Ticket(ctx, ticket)
+	if err != nil {
+		return false, err
+	}
+
+	m := &matcher{queries: queries, windows: windows, sources: map[string][]string{}}
+
+	return m.covered(ctx, ticket, t.Type, now, now.Add(time.Second)) > 0, nil
+}
+
+// matcher matches tickets against windows and caches the alert sources of
+// the tickets.
+type matcher struct {
+	queries *sqlc.Queries
+	windows []sqlc.MaintenanceWindow
+	sources map[string][]string
+}
+
+// covered returns how long the ticket was covered by the windows between
+// since and until. If windows overlap, the longest coverage counts.
+func (m *matcher) covered(ctx context.Context, ticket, ticketType string, since, until time.Time) time.Duration {
+	var longest time.Duration
+
+	for _, window := range m.windows {
+		filter, err := ParseFilter(window.Filter)
+		if err != nil {
+			slog.ErrorContext(ctx, "Invalid maintenance window filter", "window", window.ID, "error", err)
+
+			continue
+		}
+
+		if len(filter.Sources) > 0 {
+			if _, ok := m.sources[ticket]; !ok {
+				sources, err := m.queries.ListTicketAlertSources(ctx, &ticket)
+				if err != nil {
+					slog.ErrorContext(ctx, "Failed to list alert sources of ticket", "ticket", ticket, "error", err)
+				}
+
+				m.sources[ticket] = sources
+			}
+		}
+
+		if !filter.MatchesTicket(ticketType, m.sources[ticket]) {
+			continue
+		}
+
+		start := maxTime(since, window.Starts)
+		end := minTime(until, window.Ends)
+
+		longest = max(longest, end.Sub(start))
+	}
+
+	return longest
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+
+	return b
+}
+
+func minTime(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+
+	return b
+}
+
+// Pauser postpones the escalations and task timers of tickets in
+// maintenance.
+type Pauser struct {
+	queries *sqlc.Queries
+	now     func() time.Time
+	last    time.Time
+}
+
+func New(queries *sqlc.Queries) *Pauser {
+	p := &Pauser{
+		queries: queries,
+		now:     time.Now,
+	}
+
+	p.last = p.now().UTC()
+
+	return p
+}
+
+// Start pauses the timers every minute until the context is canceled.
+func (p *Pauser) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(checkInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := p.Pause(ctx); err != nil {
+					slog.ErrorContext(ctx, "Failed to pause timers for maintenance", "error", err)
+				}
+			}
+		}
+	}()
+}
+
+// Pause postpones the pending escalations and task timers of the tickets
+// that were in maintenance since the last pause, by the time they were in
+// maintenance.
+func (p *Pauser) Pause(ctx context.Context) error {
+	now := p.now().UTC()
+	since := p.last
+
+	windows, err := overlapping(ctx, p.queries, since, now)
+	if err != nil {
+		return err
+	}
+
+	p.last = now
+
+	if len(windows) == 0 {
+		return nil
+	}
+
+	m := &matcher{queries: p.queries, windows: windows, sources: map[string][]string{}}
+
+	escalations, err := p.queries.ListPendingEscalations(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, escalation := range escalations {
+		paused := m.covered(ctx, escalation.Ticket, escalation.TicketType, since, now)
+		if paused <= 0 {
+			continue
+		}
+
+		nextAt := escalation.NextAt.Add(paused)
+
+		if err := p.queries.PostponeTicketEscalation(ctx, sqlc.PostponeTicketEscalationParams{Ticket: escalation.Ticket, NextAt: &nextAt}); err != nil {
+			return err
+		}
+	}
+
+	timers, err := p.queries.ListPendingTaskTimers(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, timer := range timers {
+		paused := m.covered(ctx, timer.TaskTicket, timer.TicketType, since, now)
+		if paused <= 0 {
+			continue
+		}
+
+		if err := p.queries.PostponeTaskTimer(ctx, sqlc.PostponeTaskTimerParams{Task: timer.Task, Due: timer.Due.Add(paused)}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,147 @@
+package maintenance
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/SecurityBrewery/catalyst/app/data"
+	"github.com/SecurityBrewery/catalyst/app/database/sqlc"
+)
+
+func createWindow(t *testing.T, queries *sqlc.Queries, filter Filter, starts, ends time.Time) sqlc.MaintenanceWindow {
+	t.Helper()
+
+	b, err := json.Marshal(filter)
+	require.NoError(t, err)
+
+	window, err := queries.CreateMaintenanceWindow(t.Context(), sqlc.CreateMaintenanceWindowParams{
+		Name:   "Planned change",
+		Filter: b,
+		Starts: starts,
+		Ends:   ends,
+	})
+	require.NoError(t, err)
+
+	return window
+}
+
+func TestFilter_MatchesTicket(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		filter  Filter
+		sources []string
+		want    bool
+	}{
+		{name: "empty", filter: Filter{}, want: true},
+		{name: "type", filter: Filter{Types: []string{"Incident"}}, want: true},
+		{name: "other type", filter: Filter{Types: []string{"alert"}}, want: false},
+		{name: "source of an alert", filter: Filter{Sources: []string{"splunk"}}, sources: []string{"crowdstrike", "splunk"}, want: true},
+		{name: "no alert of the source", filter: Filter{Sources: []string{"splunk"}}, sources: []string{"crowdstrike"}, want: false},
+		{name: "no alerts", filter: Filter{Sources: []string{"splunk"}}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, tt.filter.MatchesTicket("incident", tt.sources))
+		})
+	}
+}
+
+func TestAlertWindow(t *testing.T) {
+	t.Parallel()
+
+	queries := data.NewTestDB(t, t.TempDir())
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	window := createWindow(t, queries, Filter{Sources: []string{"splunk"}}, now.Add(-time.Hour), now.Add(time.Hour))
+	createWindow(t, queries, Filter{}, now.Add(-3*time.Hour), now.Add(-2*time.Hour))
+
+	id, err := AlertWindow(t.Context(), queries, "Splunk", now)
+	require.NoError(t, err)
+	assert.Equal(t, &window.ID, id)
+
+	id, err = AlertWindow(t.Context(), queries, "crowdstrike", now)
+	require.NoError(t, err)
+	assert.Nil(t, id)
+
+	// after the end of the window
+	id, err = AlertWindow(t.Context(), queries, "splunk", now.Add(time.Hour))
+	require.NoError(t, err)
+	assert.Nil(t, id)
+}
+
+func TestCovers(t *testing.T) {
+	t.Parallel()
+
+	queries := data.NewTestDB(t, t.TempDir())
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	covered, err := Covers(t.Context(), queries, "test-ticket", now)
+	require.NoError(t, err)
+	assert.False(t, covered)
+
+	createWindow(t, queries, Filter{Types: []string{"incident"}}, now.Add(-time.Hour), now.Add(time.Hour))
+
+	covered, err = Covers(t.Context(), queries, "test-ticket", now)
+	require.NoError(t, err)
+	assert.True(t, covered)
+
+	covered, err = Covers(t.Context(), queries, "test-ticket", now.Add(2*time.Hour))
+	require.NoError(t, err)
+	assert.False(t, covered)
+}
+
+func TestPauser_Pause(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	queries := data.NewTestDB(t, t.TempDir())
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	p := New(queries)
+	p.now = func() time.Time { return now }
+	p.last = now
+
+	due := now.Add(30 * time.Minute)
+
+	_, err := queries.SetTaskTimer(ctx, sqlc.SetTaskTimerParams{Task: "k_test_task", Due: due, Action: "complete"})
+	require.NoError(t, err)
+
+	policy, err := queries.CreateEscalationPolicy(ctx, sqlc.CreateEscalationPolicyParams{Name: "On call", Filter: []byte(`{}`), Steps: []byte(`[{"delay": 30}]`), PauseStates: []byte(`[]`)})
+	require.NoError(t, err)
+
+	_, err = queries.CreateTicketEscalation(ctx, sqlc.CreateTicketEscalationParams{Ticket: "test-ticket", Policy: policy.ID, NextAt: &due})
+	require.NoError(t, err)
+
+	// the window starts 10 minutes after the last pause and ends 5 minutes
+	// before this pause
+	createWindow(t, queries, Filter{Types: []string{"incident"}}, now.Add(10*time.Minute), now.Add(25*time.Minute))
+
+	now = now.Add(30 * time.Minute)
+	require.NoError(t, p.Pause(ctx))
+
+	timer, err := queries.GetTaskTimer(ctx, "k_test_task")
+	require.NoError(t, err)
+	assert.Equal(t, due.Add(15*time.Minute), timer.Due.UTC())
+
+	escalation, err := queries.GetTicketEscalation(ctx, "test-ticket")
+	require.NoError(t, err)
+	require.NotNil(t, escalation.NextAt)
+	assert.Equal(t, due.Add(15*time.Minute), escalation.NextAt.UTC())
+
+	// the window was already paused
+	now = now.Add(time.Minute)
+	require.NoError(t, p.Pause(ctx))
+
+	timer, err = queries.GetTaskTimer(ctx, "k_test_task")
+	require.NoError(t, err)
+	assert.Equal(t, due.Add(15*time.Minute), timer.Due.UTC())
+}
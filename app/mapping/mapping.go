@@ -0,0 +1,123 @@
+// Package mapping turns the events of security tools into alerts. A mapping
+// selects the name, the description and the severity of an alert from an
+// event with gjson paths, the event is kept as the data of the alert.
+//
+// Catalyst ships versioned templates for common sources, which admins clone
+// and customize. A clone remembers the version of its template, so that it
+// can be upgraded when a newer version is shipped.
+package mapping
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/tidwall/gjson"
+)
+
+var (
+	ErrInvalidEvent  = errors.New("the event is not a JSON object")
+	ErrInvalidFields = errors.New("invalid mapping")
+)
+
+// Fields are the gjson paths of the fields of an alert in an event.
+type Fields struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Severity    string `json:"severity,omitempty"`
+	// Severities maps values of the severity to the severities of
+	// Catalyst, like "informational" to "Low". Values are also looked up in
+	// lower case.
+	Severities map[string]string `json:"severities,omitempty"`
+	// Levels map numeric severities that are not in Severities, the first
+	// level whose minimum the value reaches applies.
+	Levels []Level `json:"levels,omitempty"`
+	// Plugin names a plugin whose map function rewrites the event before
+	// the paths are applied, e.g. to decode a nested payload.
+	Plugin string `json:"plugin,omitempty"`
+}
+
+type Level struct {
+	Min      float64 `json:"min"`
+	Severity string  `json:"severity"`
+}
+
+// Validate checks that the fields have a name path and that the levels are
+// sorted.
+func (f Fields) Validate() error {
+	if f.Name == "" {
+		return fmt.Errorf("%w: the name path is required", ErrInvalidFields)
+	}
+
+	if !slices.IsSortedFunc(f.Levels, func(a, b Level) int { return cmp.Compare(b.Min, a.Min) }) {
+		return fmt.Errorf("%w: the levels must be sorted by their minimum, the highest first", ErrInvalidFields)
+	}
+
+	return nil
+}
+
+// Alert is the alert that a mapping produces from an event.
+type Alert struct {
+	Source      string
+	Name        string
+	Description string
+	Severity    string
+	Data        map[string]any
+}
+
+// Apply maps an event of the source to an alert.
+func Apply(source string, fields Fields, event []byte) (*Alert, error) {
+	if !gjson.ValidBytes(event) {
+		return nil, ErrInvalidEvent
+	}
+
+	data, ok := gjson.ParseBytes(event).Value().(map[string]any)
+	if !ok {
+		return nil, ErrInvalidEvent
+	}
+
+	name := gjson.GetBytes(event, fields.Name).String()
+	if name == "" {
+		return nil, fmt.Errorf("%w: the event has no value at the name path %q", ErrInvalidEvent, fields.Name)
+	}
+
+	alert := &Alert{Source: source, Name: name, Data: data}
+
+	if fields.Description != "" {
+		alert.Description = gjson.GetBytes(event, fields.Description).String()
+	}
+
+	if fields.Severity != "" {
+		alert.Severity = fields.severity(gjson.GetBytes(event, fields.Severity))
+	}
+
+	return alert, nil
+}
+
+func (f Fields) severity(value gjson.Result) string {
+	if !value.Exists() {
+		return ""
+	}
+
+	if severity, ok := f.Severities[value.String()]; ok {
+		return severity
+	}
+
+	if severity, ok := f.Severities[strings.ToLower(value.String())]; ok {
+		return severity
+	}
+
+	if value.Type == gjson.Number || (value.Type == gjson.String && gjson.Parse(value.Str).Type == gjson.Number) {
+		number := value.Float()
+
+		for _, level := range f.Levels {
+			if number >= level.Min {
+				return level.Severity
+			}
+		}
+	}
+
+	return value.String()
+}
@@ -0,0 +1,67 @@
+package mapping
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTemplates(t *testing.T) {
+	t.Parallel()
+
+	events := map[string]string{
+		"sentinel":    `{"AlertName":"Suspicious sign-in","Description":"Impossible travel","AlertSeverity":"Informational"}`,
+		"elastic":     `{"kibana.alert.rule.name":"Malware Prevention Alert","kibana.alert.reason":"malware on ws-042","kibana.alert.severity":"critical"}`,
+		"crowdstrike": `{"event":{"DetectName":"Credential Theft","DetectDescription":"LSASS access","SeverityName":"High"}}`,
+		"wazuh":       `{"rule":{"description":"sshd: brute force","level":10},"full_log":"Failed password for root"}`,
+		"suricata":    `{"event_type":"alert","alert":{"signature":"ET SCAN Nmap","category":"Attempted Information Leak","severity":2}}`,
+	}
+
+	expected := map[string][3]string{
+		"sentinel":    {"Suspicious sign-in", "Impossible travel", "Low"},
+		"elastic":     {"Malware Prevention Alert", "malware on ws-042", "Critical"},
+		"crowdstrike": {"Credential Theft", "LSASS access", "High"},
+		"wazuh":       {"sshd: brute force", "Failed password for root", "High"},
+		"suricata":    {"ET SCAN Nmap", "Attempted Information Leak", "Medium"},
+	}
+
+	require.Len(t, Templates(), len(events))
+
+	for _, template := range Templates() {
+		require.NoError(t, template.Fields.Validate(), template.ID)
+
+		alert, err := Apply(template.Source, template.Fields, []byte(events[template.ID]))
+		require.NoError(t, err, template.ID)
+
+		assert.Equal(t, template.ID, alert.Source)
+		assert.Equal(t, expected[template.ID], [3]string{alert.Name, alert.Description, alert.Severity}, template.ID)
+		assert.NotEmpty(t, alert.Data)
+	}
+}
+
+func TestApply(t *testing.T) {
+	t.Parallel()
+
+	fields := Fields{Name: "title", Severity: "level", Levels: []Level{{Min: 5, Severity: "High"}, {Min: 0, Severity: "Low"}}}
+
+	alert, err := Apply("siem", fields, []byte(`{"title":"Port scan","level":"7"}`))
+	require.NoError(t, err)
+	assert.Equal(t, "High", alert.Severity)
+	assert.Empty(t, alert.Description)
+
+	alert, err = Apply("siem", fields, []byte(`{"title":"Port scan","level":"severe"}`))
+	require.NoError(t, err)
+	assert.Equal(t, "severe", alert.Severity)
+
+	for _, event := range []string{`[1]`, `not json`, `{"other":"value"}`} {
+		_, err = Apply("siem", fields, []byte(event))
+		require.ErrorIs(t, err, ErrInvalidEvent, event)
+	}
+
+	require.ErrorIs(t, Fields{}.Validate(), ErrInvalidFields)
+	require.ErrorIs(t, Fields{Name: "title", Levels: []Level{{Min: 0}, {Min: 5}}}.Validate(), ErrInvalidFields)
+
+	_, ok := Lookup("unknown")
+	assert.False(t, ok)
+}
@@ -0,0 +1,111 @@
+package mapping
+
+import (
+	"slices"
+	"strings"
+)
+
+// Template is a mapping that Catalyst ships for a common source. Its version
+// is increased whenever its fields change.
+type Template struct {
+	ID      string
+	Name    string
+	Source  string
+	Version int
+	Fields  Fields
+}
+
+// the severities of the sources that name them like Catalyst
+var namedSeverities = map[string]string{
+	"informational": "Low",
+	"low":           "Low",
+	"medium":        "Medium",
+	"high":          "High",
+	"critical":      "Critical",
+}
+
+var templates = []Template{
+	{
+		ID:      "sentinel",
+		Name:    "Microsoft Sentinel",
+		Source:  "sentinel",
+		Version: 1,
+		Fields: Fields{
+			Name:        "AlertName",
+			Description: "Description",
+			Severity:    "AlertSeverity",
+			Severities:  namedSeverities,
+		},
+	},
+	{
+		ID:      "elastic",
+		Name:    "Elastic Security",
+		Source:  "elastic",
+		Version: 1,
+		Fields: Fields{
+			Name:        `kibana\.alert\.rule\.name`,
+			Description: `kibana\.alert\.reason`,
+			Severity:    `kibana\.alert\.severity`,
+			Severities:  namedSeverities,
+		},
+	},
+	{
+		ID:      "crowdstrike",
+		Name:    "CrowdStrike Falcon",
+		Source:  "crowdstrike",
+		Version: 1,
+		Fields: Fields{
+			Name:        "event.DetectName",
+			Description: "event.DetectDescription",
+			Severity:    "event.SeverityName",
+			Severities:  namedSeverities,
+		},
+	},
+	{
+		ID:      "wazuh",
+		Name:    "Wazuh",
+		Source:  "wazuh",
+		Version: 1,
+		Fields: Fields{
+			Name:        "rule.description",
+			Description: "full_log",
+			Severity:    "rule.level",
+			Levels: []Level{
+				{Min: 12, Severity: "Critical"},
+				{Min: 8, Severity: "High"},
+				{Min: 4, Severity: "Medium"},
+				{Min: 0, Severity: "Low"},
+			},
+		},
+	},
+	{
+		ID:      "suricata",
+		Name:    "Suricata EVE",
+		Source:  "suricata",
+		Version: 1,
+		Fields: Fields{
+			Name:        "alert.signature",
+			Description: "alert.category",
+			Severity:    "alert.severity",
+			// 1 is the highest priority of Suricata
+			Severities: map[string]string{"1": "High", "2": "Medium", "3": "Low", "4": "Low"},
+		},
+	},
+}
+
+// Templates returns the templates sorted by name.
+func Templates() []Template {
+	return slices.SortedFunc(slices.Values(templates), func(a, b Template) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+}
+
+// Lookup returns the template with the id.
+func Lookup(id string) (Template, bool) {
+	i := slices.IndexFunc(templates, func(t Template) bool { return t.ID == id })
+	if i < 0 {
+		return Template{}, false
+	}
+
+	return templates[i], true
+}
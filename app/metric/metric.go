@@ -0,0 +1,105 @@
+// Package metric aggregates the custom fields of ticket types over time. An
+// admin marks numeric and enum properties of the schema of a type as metrics,
+// the stats then count the tickets per value of an enum or sum the numbers,
+// in buckets of a day, a week or a month.
+package metric
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+const (
+	KindEnum   = "enum"
+	KindNumber = "number"
+)
+
+const (
+	IntervalDay   = "day"
+	IntervalWeek  = "week"
+	IntervalMonth = "month"
+)
+
+// Intervals are the sizes of the buckets, weeks start on Monday.
+var Intervals = []string{IntervalDay, IntervalWeek, IntervalMonth}
+
+var ErrInvalidMetric = errors.New("invalid metric")
+
+type property struct {
+	Type any   `json:"type"`
+	Enum []any `json:"enum"`
+}
+
+// Kind returns whether the field of the schema is an enum or a number.
+func Kind(schema []byte, field string) (string, error) {
+	var s struct {
+		Properties map[string]property `json:"properties"`
+	}
+
+	if len(schema) > 0 {
+		if err := json.Unmarshal(schema, &s); err != nil {
+			return "", fmt.Errorf("%w: the schema is not a JSON object: %w", ErrInvalidMetric, err)
+		}
+	}
+
+	// the field is quoted in the JSON path of the queries
+	if strings.ContainsAny(field, `"\`) {
+		return "", fmt.Errorf("%w: the field %q contains a quote or a backslash", ErrInvalidMetric, field)
+	}
+
+	p, ok := s.Properties[field]
+	if !ok {
+		return "", fmt.Errorf("%w: the schema has no field %q", ErrInvalidMetric, field)
+	}
+
+	if len(p.Enum) > 0 {
+		return KindEnum, nil
+	}
+
+	if hasType(p.Type, "number") || hasType(p.Type, "integer") {
+		return KindNumber, nil
+	}
+
+	return "", fmt.Errorf("%w: the field %q is neither a number nor an enum", ErrInvalidMetric, field)
+}
+
+// Validate checks that the fields are distinct numeric or enum fields of the
+// schema.
+func Validate(schema []byte, fields []string) error {
+	var errs []error
+
+	for i, field := range fields {
+		if slices.Contains(fields[:i], field) {
+			errs = append(errs, fmt.Errorf("%w: the field %q is listed twice", ErrInvalidMetric, field))
+
+			continue
+		}
+
+		if _, err := Kind(schema, field); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Path is the SQLite JSON path of the field in the state of a ticket.
+func Path(field string) string {
+	return `$."` + field + `"`
+}
+
+// hasType reports whether the JSON schema type, a string or a list of
+// strings, includes name.
+func hasType(t any, name string) bool {
+	switch t := t.(type) {
+	case string:
+		return t == name
+	case []any:
+		return slices.Contains(t, any(name))
+	default:
+		return false
+	}
+}
@@ -0,0 +1,71 @@
+package metric_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/SecurityBrewery/catalyst/app/metric"
+)
+
+const schema = `{
+	"type": "object",
+	"properties": {
+		"brand": { "title": "Brand", "type": "string", "enum": ["Microsoft", "DHL", "PayPal"] },
+		"recipients": { "title": "Recipients", "type": "integer" },
+		"loss": { "title": "Loss", "type": ["number", "null"] },
+		"sender": { "title": "Sender", "type": "string" }
+	}
+}`
+
+func TestKind(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		field   string
+		want    string
+		wantErr bool
+	}{
+		{field: "brand", want: metric.KindEnum},
+		{field: "recipients", want: metric.KindNumber},
+		{field: "loss", want: metric.KindNumber},
+		{field: "sender", wantErr: true},
+		{field: "missing", wantErr: true},
+		{field: `brand"`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.field, func(t *testing.T) {
+			t.Parallel()
+
+			kind, err := metric.Kind([]byte(schema), tt.field)
+			if tt.wantErr {
+				require.ErrorIs(t, err, metric.ErrInvalidMetric)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, kind)
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	t.Parallel()
+
+	require.NoError(t, metric.Validate([]byte(schema), []string{"brand", "recipients"}))
+	require.NoError(t, metric.Validate(nil, nil))
+
+	err := metric.Validate([]byte(schema), []string{"brand", "sender", "brand"})
+	require.ErrorIs(t, err, metric.ErrInvalidMetric)
+	assert.Contains(t, err.Error(), `"sender" is neither a number nor an enum`)
+	assert.Contains(t, err.Error(), `"brand" is listed twice`)
+}
+
+func TestPath(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, `$."brand targeted"`, metric.Path("brand targeted"))
+}
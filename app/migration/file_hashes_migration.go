@@ -0,0 +1,61 @@
+package migration
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/SecurityBrewery/catalyst/app/database"
+	"github.com/SecurityBrewery/catalyst/app/database/sqlc"
+	"github.com/SecurityBrewery/catalyst/app/upload"
+)
+
+// fileHashesMigration hashes the files that were uploaded before the files
+// had hashes.
+type fileHashesMigration struct{}
+
+func newFileHashesMigration() func() (migration, error) {
+	return func() (migration, error) {
+		return fileHashesMigration{}, nil
+	}
+}
+
+func (fileHashesMigration) name() string { return "050_hash_files" }
+
+func (fileHashesMigration) up(ctx context.Context, queries *sqlc.Queries, _ string, uploader *upload.Uploader) error {
+	files, err := database.PaginateItems(ctx, func(ctx context.Context, offset, limit int64) ([]sqlc.ListFileBlobsRow, error) {
+		return queries.ListFileBlobs(ctx, sqlc.ListFileBlobsParams{Limit: limit, Offset: offset})
+	})
+	if err != nil {
+		return fmt.Errorf("list files: %w", err)
+	}
+
+	for _, file := range files {
+		content, _, _, err := uploader.File(ctx, file.ID, file.Blob)
+		if err != nil {
+			// a missing upload must not block the start, the file keeps
+			// empty hashes
+			slog.WarnContext(ctx, "Cannot hash file", "file", file.ID, "error", err)
+
+			continue
+		}
+
+		hashes, err := upload.Hash(content)
+		content.Close()
+
+		if err != nil {
+			return fmt.Errorf("hash file %s: %w", file.ID, err)
+		}
+
+		if err := queries.SetFileHashes(ctx, sqlc.SetFileHashesParams{
+			ID:     file.ID,
+			Md5:    hashes.MD5,
+			Sha1:   hashes.SHA1,
+			Sha256: hashes.SHA256,
+		}); err != nil {
+			return fmt.Errorf("store hashes of file %s: %w", file.ID, err)
+		}
+	}
+
+	return nil
+}
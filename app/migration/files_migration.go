@@ -34,8 +34,8 @@ func (filesMigration) up(ctx context.Context, queries *sqlc.Queries, dir string,
 		return fmt.Errorf("open old uploads root: %w", err)
 	}
 
-	files, err := database.PaginateItems(ctx, func(ctx context.Context, offset, limit int64) ([]sqlc.ListFilesRow, error) {
-		return queries.ListFiles(ctx, sqlc.ListFilesParams{Limit: limit, Offset: offset})
+	files, err := database.PaginateItems(ctx, func(ctx context.Context, offset, limit int64) ([]sqlc.ListFileBlobsRow, error) {
+		return queries.ListFileBlobs(ctx, sqlc.ListFileBlobsParams{Limit: limit, Offset: offset})
 	})
 	if err != nil {
 		return fmt.Errorf("list files: %w", err)
@@ -47,7 +47,7 @@ func (filesMigration) up(ctx context.Context, queries *sqlc.Queries, dir string,
 			return fmt.Errorf("read file %s: %w", file.Blob, err)
 		}
 
-		if _, err := uploader.CreateFile(file.ID, file.Name, data); err != nil {
+		if _, err := uploader.CreateFile(ctx, file.ID, file.Name, data); err != nil {
 			return err
 		}
 	}
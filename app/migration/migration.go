@@ -47,3 +47,18 @@ func Apply(ctx context.Context, queries *sqlc.Queries, dir string, uploader *upl
 
 	return nil
 }
+
+// Check returns an error unless all migrations are applied, for a database
+// that cannot be migrated because it is opened read-only.
+func Check(ctx context.Context, queries *sqlc.Queries) error {
+	currentVersion, err := version(ctx, queries.ReadDB)
+	if err != nil {
+		return err
+	}
+
+	if currentVersion != Latest() {
+		return fmt.Errorf("the database has schema version %d, migrate it to %d first", currentVersion, Latest())
+	}
+
+	return nil
+}
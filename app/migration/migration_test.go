@@ -4,9 +4,11 @@ import (
 	"testing"
 
 	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/SecurityBrewery/catalyst/app/database"
+	"github.com/SecurityBrewery/catalyst/app/database/sqlc"
 	"github.com/SecurityBrewery/catalyst/app/upload"
 )
 
@@ -20,3 +22,38 @@ func TestApply(t *testing.T) {
 
 	require.NoError(t, Apply(t.Context(), queries, dir, uploader))
 }
+
+func TestFileHashesMigration(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	queries := database.TestDB(t, dir)
+	uploader, err := upload.New(dir)
+	require.NoError(t, err)
+
+	require.NoError(t, Apply(t.Context(), queries, dir, uploader))
+
+	ticket, err := queries.CreateTicket(t.Context(), sqlc.CreateTicketParams{Name: "Phishing", Open: true, Type: "incident", Schema: []byte(`{}`), State: []byte(`{}`)})
+	require.NoError(t, err)
+
+	blob, err := uploader.CreateFile(t.Context(), "b_evidence", "evidence.txt", []byte("evidence"))
+	require.NoError(t, err)
+
+	for id, blob := range map[string]string{"b_evidence": blob, "b_missing": "missing.txt"} {
+		_, err = queries.InsertFile(t.Context(), sqlc.InsertFileParams{ID: id, Name: blob, Blob: blob, Size: 8, Ticket: ticket.ID})
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, fileHashesMigration{}.up(t.Context(), queries, dir, uploader))
+
+	file, err := queries.GetFile(t.Context(), "b_evidence")
+	require.NoError(t, err)
+	assert.Equal(t, "14e10d570047667f904261e6d08f520f", file.Md5)
+	assert.Equal(t, "100ec4462dec34f6824f7e65f377076ce5d51ced", file.Sha1)
+	assert.Equal(t, "ee8250fb76e094b34b471f13a73dbbe51d1ae142e9df59d7c0d31ec20f0a0a8e", file.Sha256)
+
+	// a missing upload keeps empty hashes
+	missing, err := queries.GetFile(t.Context(), "b_missing")
+	require.NoError(t, err)
+	assert.Empty(t, missing.Sha256)
+}
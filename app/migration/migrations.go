@@ -8,6 +8,53 @@ var migrationGenerators = []func() (migration, error){
 	newFilesMigration(),
 	newSQLMigration("002_create_defaultdata"),
 	newSQLMigration("003_create_groups"),
+	newSQLMigration("004_create_dead_letters"),
+	newSQLMigration("005_create_slack_threads"),
+	newSQLMigration("006_create_push_subscriptions"),
+	newSQLMigration("007_create_digests"),
+	newSQLMigration("008_create_notification_rules"),
+	newSQLMigration("009_create_escalations"),
+	newSQLMigration("010_add_ticket_acknowledgement"),
+	newSQLMigration("011_create_ticket_encryption"),
+	newSQLMigration("012_create_legal_holds"),
+	newSQLMigration("013_create_file_custody"),
+	newSQLMigration("014_create_reaction_runs"),
+	newSQLMigration("015_create_enrichment_cache"),
+	newSQLMigration("016_create_feeds"),
+	newSQLMigration("017_create_task_timers"),
+	newSQLMigration("018_create_task_outputs"),
+	newSQLMigration("019_create_playbooks"),
+	newSQLMigration("020_create_campaigns"),
+	newSQLMigration("021_create_campaign_detection"),
+	newSQLMigration("022_create_attack_techniques"),
+	newSQLMigration("023_create_cves"),
+	newSQLMigration("024_create_detection_rules"),
+	newSQLMigration("025_create_announcements"),
+	newSQLMigration("026_create_ticket_activity"),
+	newSQLMigration("027_create_dlp_events"),
+	newSQLMigration("028_add_ticket_keys"),
+	newSQLMigration("029_create_federation"),
+	newSQLMigration("030_create_document_templates"),
+	newSQLMigration("031_create_auto_close_rules"),
+	newSQLMigration("032_create_ticket_reopens"),
+	newSQLMigration("033_create_alerts"),
+	newSQLMigration("034_create_queues"),
+	newSQLMigration("035_create_work_entries"),
+	newSQLMigration("036_create_password_history"),
+	newSQLMigration("037_create_invitations"),
+	newSQLMigration("038_create_api_usage"),
+	newSQLMigration("039_create_logs"),
+	newSQLMigration("040_create_platform_errors"),
+	newSQLMigration("041_create_maintenance_windows"),
+	newSQLMigration("042_add_escalation_pauses"),
+	newSQLMigration("043_create_ticket_artifacts"),
+	newSQLMigration("044_create_artifact_sightings"),
+	newSQLMigration("045_create_redaction_profiles"),
+	newSQLMigration("046_create_request_journal"),
+	newSQLMigration("047_create_alert_mappings"),
+	newSQLMigration("048_add_type_metrics"),
+	newSQLMigration("049_add_file_hashes"),
+	newFileHashesMigration(),
 }
 
 func migrations(version int) ([]migration, error) {
@@ -32,3 +79,8 @@ func migrations(version int) ([]migration, error) {
 
 	return migrations, nil
 }
+
+// Latest returns the database version after all migrations are applied.
+func Latest() int {
+	return len(migrationGenerators)
+}
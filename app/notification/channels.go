@@ -0,0 +1,130 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const (
+	EmailChannel     = "email"
+	SlackChannel     = "slack"
+	WebhookChannel   = "webhook"
+	PagerDutyChannel = "pagerduty"
+
+	defaultPagerDutyURL = "https://events.pagerduty.com/v2/enqueue"
+)
+
+// Channels lists the channels a rule can deliver to.
+func Channels() []string {
+	return []string{EmailChannel, SlackChannel, WebhookChannel, PagerDutyChannel}
+}
+
+// Sender delivers emails, usually the mail.Mailer.
+type Sender interface {
+	Send(ctx context.Context, to, subject, plainTextBody, htmlBody string) error
+}
+
+// channel delivers an event to the target of a rule: email addresses, a
+// Slack incoming webhook URL, a webhook URL or a PagerDuty routing key.
+type channel func(ctx context.Context, target string, event *Event) error
+
+func (r *Router) channels() map[string]channel {
+	return map[string]channel{
+		EmailChannel:     r.sendEmail,
+		SlackChannel:     r.sendSlack,
+		WebhookChannel:   r.sendWebhook,
+		PagerDutyChannel: r.sendPagerDuty,
+	}
+}
+
+func summary(event *Event) string {
+	text := fmt.Sprintf("Ticket %s: %s", event.Action, event.Name)
+	if event.Severity != "" {
+		text += " (" + event.Severity + ")"
+	}
+
+	return text
+}
+
+func (r *Router) sendEmail(ctx context.Context, target string, event *Event) error {
+	body := summary(event) + "\n\n" + event.URL
+
+	for address := range strings.SplitSeq(target, ",") {
+		if err := r.mailer.Send(ctx, strings.TrimSpace(address), summary(event), body, ""); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *Router) sendSlack(ctx context.Context, target string, event *Event) error {
+	return r.postJSON(ctx, target, map[string]string{
+		"text": fmt.Sprintf("%s <%s|open>", summary(event), event.URL),
+	})
+}
+
+func (r *Router) sendWebhook(ctx context.Context, target string, event *Event) error {
+	return r.postJSON(ctx, target, event)
+}
+
+func (r *Router) sendPagerDuty(ctx context.Context, target string, event *Event) error {
+	return r.postJSON(ctx, r.pagerDutyURL, map[string]any{
+		"routing_key":  target,
+		"event_action": "trigger",
+		"dedup_key":    event.Ticket,
+		"links":        []map[string]string{{"href": event.URL, "text": "Open in Catalyst"}},
+		"payload": map[string]any{
+			"summary":        summary(event),
+			"source":         "catalyst",
+			"severity":       pagerDutySeverity(event.Severity),
+			"custom_details": event,
+		},
+	})
+}
+
+// pagerDutySeverity maps the ticket severity to critical, error, warning
+// or info.
+func pagerDutySeverity(severity string) string {
+	switch strings.ToLower(severity) {
+	case "critical":
+		return "critical"
+	case "high":
+		return "error"
+	case "medium":
+		return "warning"
+	default:
+		return "info"
+	}
+}
+
+func (r *Router) postJSON(ctx context.Context, url string, payload any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("%s returned %s", req.URL.Host, resp.Status)
+	}
+
+	return nil
+}
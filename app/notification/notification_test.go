@@ -0,0 +1,192 @@
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/SecurityBrewery/catalyst/app/data"
+	"github.com/SecurityBrewery/catalyst/app/database/sqlc"
+	"github.com/SecurityBrewery/catalyst/app/openapi"
+)
+
+type fakeMailer struct {
+	to []string
+}
+
+func (f *fakeMailer) Send(_ context.Context, to, _, _, _ string) error {
+	f.to = append(f.to, to)
+
+	return nil
+}
+
+type recorder struct {
+	mu       sync.Mutex
+	requests map[string][]string
+}
+
+func (r *recorder) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+
+	r.mu.Lock()
+	r.requests[req.URL.Path] = append(r.requests[req.URL.Path], string(body))
+	r.mu.Unlock()
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func TestFilter_Matches(t *testing.T) {
+	t.Parallel()
+
+	ticket := openapi.Ticket{
+		Id:   "t_1",
+		Name: "Ransomware",
+		Type: "incident",
+		State: map[string]any{
+			"severity": "High",
+			"customer": "ACME",
+			"tags":     []any{"ransomware", "windows"},
+		},
+	}
+	event := newEvent("create", "https://catalyst.example.com/", ticket)
+
+	assert.Equal(t, "https://catalyst.example.com/ui/tickets/incident/t_1", event.URL)
+
+	tests := []struct {
+		name   string
+		filter Filter
+		want   bool
+	}{
+		{name: "empty", filter: Filter{}, want: true},
+		{name: "event", filter: Filter{Events: []string{"update"}}, want: false},
+		{name: "type", filter: Filter{Types: []string{"alert", "incident"}}, want: true},
+		{name: "other type", filter: Filter{Types: []string{"alert"}}, want: false},
+		{name: "severity", filter: Filter{Severities: []string{"high", "critical"}}, want: true},
+		{name: "customer", filter: Filter{Customers: []string{"Initech"}}, want: false},
+		{name: "tag", filter: Filter{Tags: []string{"windows"}}, want: true},
+		{name: "other tag", filter: Filter{Tags: []string{"linux"}}, want: false},
+		{name: "all", filter: Filter{Events: []string{"create"}, Types: []string{"incident"}, Customers: []string{"acme"}, Tags: []string{"ransomware"}}, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, tt.filter.Matches(&event))
+		})
+	}
+}
+
+func TestRouter_Route(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	queries := data.NewTestDB(t, t.TempDir())
+	mailer := &fakeMailer{}
+
+	rec := &recorder{requests: map[string][]string{}}
+	server := httptest.NewServer(rec)
+	t.Cleanup(server.Close)
+
+	now := time.Now()
+	r := New(queries, mailer)
+	r.pagerDutyURL = server.URL + "/pagerduty"
+	r.now = func() time.Time { return now }
+
+	createRule := func(name string, filter Filter, channel, target string, throttle, dedup int64) {
+		t.Helper()
+
+		filterJSON, err := json.Marshal(filter)
+		require.NoError(t, err)
+
+		_, err = queries.CreateNotificationRule(ctx, sqlc.CreateNotificationRuleParams{
+			Name: name, Enabled: true, Filter: filterJSON, Channel: channel, Target: target, Throttle: throttle, Dedup: dedup,
+		})
+		require.NoError(t, err)
+	}
+
+	createRule("high to pagerduty", Filter{Severities: []string{"High"}}, PagerDutyChannel, "routing-key", 0, 3600)
+	createRule("acme to slack", Filter{Customers: []string{"ACME"}}, SlackChannel, server.URL+"/slack", 600, 0)
+	createRule("all to webhook", Filter{}, WebhookChannel, server.URL+"/webhook", 0, 0)
+	createRule("alerts by mail", Filter{Types: []string{"alert"}}, EmailChannel, "soc@example.com, lead@example.com", 0, 0)
+
+	event := newEvent("update", "https://catalyst.example.com", openapi.Ticket{
+		Id: "t_1", Name: "Ransomware", Type: "incident", State: map[string]any{"severity": "High", "customer": "ACME"},
+	})
+
+	r.Route(ctx, &event)
+
+	require.Len(t, rec.requests["/pagerduty"], 1)
+	assert.Contains(t, rec.requests["/pagerduty"][0], `"routing_key":"routing-key"`)
+	assert.Contains(t, rec.requests["/pagerduty"][0], `"severity":"error"`)
+	assert.Len(t, rec.requests["/slack"], 1)
+	assert.Len(t, rec.requests["/webhook"], 1)
+	assert.Empty(t, mailer.to)
+
+	// pagerduty dedups the same event, slack is throttled
+	now = now.Add(time.Minute)
+	r.Route(ctx, &event)
+
+	assert.Len(t, rec.requests["/pagerduty"], 1)
+	assert.Len(t, rec.requests["/slack"], 1)
+	assert.Len(t, rec.requests["/webhook"], 2)
+
+	other := newEvent("create", "https://catalyst.example.com", openapi.Ticket{
+		Id: "t_2", Name: "Phishing", Type: "alert", State: map[string]any{"severity": "High", "customer": "ACME"},
+	})
+
+	r.Route(ctx, &other)
+
+	assert.Len(t, rec.requests["/pagerduty"], 2)
+	assert.Len(t, rec.requests["/slack"], 1)
+	assert.Equal(t, []string{"soc@example.com", "lead@example.com"}, mailer.to)
+
+	now = now.Add(time.Hour)
+	r.Route(ctx, &event)
+
+	assert.Len(t, rec.requests["/pagerduty"], 3)
+	assert.Len(t, rec.requests["/slack"], 2)
+}
+
+func TestRouter_Route_maintenance(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	queries := data.NewTestDB(t, t.TempDir())
+
+	rec := &recorder{requests: map[string][]string{}}
+	server := httptest.NewServer(rec)
+	t.Cleanup(server.Close)
+
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	r := New(queries, &fakeMailer{})
+	r.now = func() time.Time { return now }
+
+	_, err := queries.CreateNotificationRule(ctx, sqlc.CreateNotificationRuleParams{
+		Name: "all to webhook", Enabled: true, Filter: []byte(`{}`), Channel: WebhookChannel, Target: server.URL + "/webhook",
+	})
+	require.NoError(t, err)
+
+	_, err = queries.CreateMaintenanceWindow(ctx, sqlc.CreateMaintenanceWindowParams{
+		Name: "Firewall upgrade", Filter: []byte(`{"types": ["incident"]}`), Starts: now.Add(-time.Hour), Ends: now.Add(time.Hour),
+	})
+	require.NoError(t, err)
+
+	event := newEvent("update", "https://catalyst.example.com", openapi.Ticket{Id: "test-ticket", Name: "Test Ticket", Type: "incident"})
+
+	// suppressed during the window
+	r.Route(ctx, &event)
+	assert.Empty(t, rec.requests["/webhook"])
+
+	now = now.Add(time.Hour)
+	r.Route(ctx, &event)
+	assert.Len(t, rec.requests["/webhook"], 1)
+}
@@ -0,0 +1,157 @@
+// Package notification routes ticket events to email, Slack, webhooks and
+// PagerDuty according to the notification rules configured by admins.
+package notification
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/SecurityBrewery/catalyst/app/database"
+	"github.com/SecurityBrewery/catalyst/app/database/sqlc"
+	"github.com/SecurityBrewery/catalyst/app/hook"
+	"github.com/SecurityBrewery/catalyst/app/maintenance"
+	"github.com/SecurityBrewery/catalyst/app/openapi"
+	"github.com/SecurityBrewery/catalyst/app/settings"
+)
+
+// MaxWindow is the longest throttle or dedup window of a rule.
+const MaxWindow = 7 * 24 * time.Hour
+
+type Router struct {
+	queries      *sqlc.Queries
+	mailer       Sender
+	client       *http.Client
+	pagerDutyURL string
+	now          func() time.Time
+
+	mu sync.Mutex
+	// lastSent is the time of the last notification per rule for throttling
+	lastSent map[string]time.Time
+	// seen is the time an event was last sent per rule, ticket and action
+	// for deduplication
+	seen map[string]time.Time
+}
+
+func New(queries *sqlc.Queries, mailer Sender) *Router {
+	return &Router{
+		queries:      queries,
+		mailer:       mailer,
+		client:       &http.Client{Timeout: 10 * time.Second},
+		pagerDutyURL: defaultPagerDutyURL,
+		now:          time.Now,
+		lastSent:     map[string]time.Time{},
+		seen:         map[string]time.Time{},
+	}
+}
+
+func BindHooks(hooks *hook.Hooks, queries *sqlc.Queries, mailer Sender) *Router {
+	r := New(queries, mailer)
+
+	hooks.OnRecordAfterCreateRequest.Subscribe(func(ctx context.Context, table string, record any) {
+		r.ticketEvent(ctx, database.CreateAction, table, record)
+	})
+	hooks.OnRecordAfterUpdateRequest.Subscribe(func(ctx context.Context, table string, record any) {
+		r.ticketEvent(ctx, database.UpdateAction, table, record)
+	})
+
+	return r
+}
+
+func (r *Router) ticketEvent(ctx context.Context, action, table string, record any) {
+	if table != database.TicketsTable.ID {
+		return
+	}
+
+	ticket, ok := record.(openapi.Ticket)
+	if !ok {
+		return
+	}
+
+	se, err := settings.Load(ctx, r.queries)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to load settings", "error", err)
+
+		return
+	}
+
+	event := newEvent(action, se.Meta.AppURL, ticket)
+
+	// don't block the request on slow channels
+	go r.Route(context.WithoutCancel(ctx), &event)
+}
+
+// Route sends the event to the channel of every enabled rule that matches,
+// unless the rule is throttled or already sent the same event recently.
+func (r *Router) Route(ctx context.Context, event *Event) {
+	rules, err := r.queries.ListEnabledNotificationRules(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to list notification rules", "error", err)
+
+		return
+	}
+
+	if paused, err := maintenance.Covers(ctx, r.queries, event.Ticket, r.now()); err != nil {
+		slog.ErrorContext(ctx, "Failed to check maintenance windows", "ticket", event.Ticket, "error", err)
+	} else if paused {
+		return
+	}
+
+	channels := r.channels()
+
+	for _, rule := range rules {
+		filter, err := ParseFilter(rule.Filter)
+		if err != nil {
+			slog.ErrorContext(ctx, "Invalid notification rule filter", "rule", rule.ID, "error", err)
+
+			continue
+		}
+
+		if !filter.Matches(event) || !r.allow(&rule, event) {
+			continue
+		}
+
+		send, ok := channels[rule.Channel]
+		if !ok {
+			slog.ErrorContext(ctx, "Unknown notification channel", "rule", rule.ID, "channel", rule.Channel)
+
+			continue
+		}
+
+		if err := send(ctx, rule.Target, event); err != nil {
+			slog.ErrorContext(ctx, "Failed to send notification", "rule", rule.ID, "channel", rule.Channel, "error", err)
+		}
+	}
+}
+
+// allow applies the dedup window and the throttle of a rule and records the
+// event if it may be sent.
+func (r *Router) allow(rule *sqlc.NotificationRule, event *Event) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	key := rule.ID + "/" + event.Ticket + "/" + event.Action
+
+	if last, ok := r.seen[key]; ok && now.Sub(last) < time.Duration(rule.Dedup)*time.Second {
+		return false
+	}
+
+	if last, ok := r.lastSent[rule.ID]; ok && now.Sub(last) < time.Duration(rule.Throttle)*time.Second {
+		return false
+	}
+
+	r.seen[key] = now
+	r.lastSent[rule.ID] = now
+
+	// forget events outside of any window to bound the memory
+	for k, t := range r.seen {
+		if now.Sub(t) > MaxWindow {
+			delete(r.seen, k)
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,90 @@
+package notification
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+
+	"github.com/SecurityBrewery/catalyst/app/database"
+	"github.com/SecurityBrewery/catalyst/app/openapi"
+)
+
+// Filter selects the ticket events a rule applies to. Empty lists match
+// everything. Severity and customer are read from the ticket state fields
+// "severity" and "customer", tags from the list in the state field "tags".
+type Filter struct {
+	Events     []string `json:"events,omitempty"`
+	Types      []string `json:"types,omitempty"`
+	Severities []string `json:"severities,omitempty"`
+	Customers  []string `json:"customers,omitempty"`
+	Tags       []string `json:"tags,omitempty"`
+}
+
+// Event is a ticket change that is routed to the channels of the matching
+// rules.
+type Event struct {
+	Action   string   `json:"action"`
+	Ticket   string   `json:"ticket"`
+	Name     string   `json:"name"`
+	Type     string   `json:"type"`
+	Severity string   `json:"severity,omitempty"`
+	Customer string   `json:"customer,omitempty"`
+	Tags     []string `json:"tags,omitempty"`
+	URL      string   `json:"url"`
+}
+
+func ParseFilter(data []byte) (Filter, error) {
+	var filter Filter
+	if len(data) == 0 {
+		return filter, nil
+	}
+
+	err := json.Unmarshal(data, &filter)
+
+	return filter, err
+}
+
+func newEvent(action, appURL string, ticket openapi.Ticket) Event {
+	event := Event{
+		Action: action,
+		Ticket: ticket.Id,
+		Name:   ticket.Name,
+		Type:   ticket.Type,
+		URL:    strings.TrimSuffix(appURL, "/") + "/ui/tickets/" + ticket.Type + "/" + ticket.Id,
+	}
+
+	event.Severity, _ = ticket.State["severity"].(string)
+	event.Customer, _ = ticket.State["customer"].(string)
+
+	if tags, ok := ticket.State["tags"].([]any); ok {
+		for _, tag := range tags {
+			if tag, ok := tag.(string); ok {
+				event.Tags = append(event.Tags, tag)
+			}
+		}
+	}
+
+	return event
+}
+
+// Matches reports whether the event passes all conditions of the filter.
+func (f *Filter) Matches(event *Event) bool {
+	events := f.Events
+	if len(events) == 0 {
+		events = []string{database.CreateAction, database.UpdateAction}
+	}
+
+	return slices.Contains(events, event.Action) &&
+		matchAny(f.Types, event.Type) &&
+		matchAny(f.Severities, event.Severity) &&
+		matchAny(f.Customers, event.Customer) &&
+		(len(f.Tags) == 0 || slices.ContainsFunc(event.Tags, func(tag string) bool { return matchAny(f.Tags, tag) }))
+}
+
+func matchAny(values []string, value string) bool {
+	if len(values) == 0 {
+		return true
+	}
+
+	return slices.ContainsFunc(values, func(v string) bool { return strings.EqualFold(v, value) })
+}
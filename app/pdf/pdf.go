@@ -0,0 +1,129 @@
+// Package pdf renders plain text documents, like the chain of custody and
+// the usage reports, as PDF without external dependencies.
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+const (
+	pageWidth    = 595 // A4 in points
+	pageHeight   = 842
+	margin       = 50
+	fontSize     = 9
+	titleSize    = 14
+	leading      = 12
+	linesPerPage = (pageHeight - 2*margin) / leading
+	// maxLineLength fits a line of Helvetica into the page width.
+	maxLineLength = 105
+)
+
+// Render renders a title and lines of text on A4 pages with the standard
+// Helvetica font, which needs no embedded font data.
+func Render(title string, lines []string) []byte {
+	var wrapped []string
+	for _, line := range lines {
+		wrapped = append(wrapped, wrap(line, maxLineLength)...)
+	}
+
+	var pages [][]string
+
+	for len(wrapped) > 0 || len(pages) == 0 {
+		n := min(len(wrapped), linesPerPage-2) // the first two lines hold the title
+		pages = append(pages, wrapped[:n])
+		wrapped = wrapped[n:]
+	}
+
+	var (
+		buf     bytes.Buffer
+		offsets []int
+	)
+
+	object := func(body string) {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	buf.WriteString("%PDF-1.4\n")
+
+	// objects 1-3 are the catalog, the page tree and the font, followed by
+	// a page and a content stream per page
+	kids := make([]string, 0, len(pages))
+	for i := range pages {
+		kids = append(kids, fmt.Sprintf("%d 0 R", 4+2*i))
+	}
+
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+
+	for i, page := range pages {
+		var content strings.Builder
+
+		fmt.Fprintf(&content, "BT\n/F1 %d Tf\n%d TL\n%d %d Td\n", titleSize, leading, margin, pageHeight-margin)
+		fmt.Fprintf(&content, "(%s) Tj\nT* T*\n/F1 %d Tf\n", escape(fmt.Sprintf("%s (page %d of %d)", title, i+1, len(pages))), fontSize)
+
+		for _, line := range page {
+			fmt.Fprintf(&content, "(%s) Tj T*\n", escape(line))
+		}
+
+		content.WriteString("ET")
+
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>", pageWidth, pageHeight, 5+2*i))
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", content.Len(), content.String()))
+	}
+
+	xref := buf.Len()
+
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	return buf.Bytes()
+}
+
+// escape escapes a string for a PDF string literal. Characters outside of
+// printable ASCII are replaced, as the standard fonts cannot show them.
+func escape(s string) string {
+	var b strings.Builder
+
+	for _, r := range s {
+		switch {
+		case r == '\\' || r == '(' || r == ')':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r < 0x20 || r > 0x7e:
+			b.WriteByte('?')
+		default:
+			b.WriteRune(r)
+		}
+	}
+
+	return b.String()
+}
+
+// wrap splits a line into lines of at most width characters, preferring
+// to break at spaces. Continuation lines are indented.
+func wrap(line string, width int) []string {
+	var lines []string
+
+	for len([]rune(line)) > width {
+		runes := []rune(line)
+
+		cut := width
+		if i := strings.LastIndex(string(runes[:width]), " "); i > width/2 {
+			cut = len([]rune(string(runes[:width])[:i]))
+		}
+
+		lines = append(lines, strings.TrimRight(string(runes[:cut]), " "))
+		line = "    " + strings.TrimLeft(string(runes[cut:]), " ")
+	}
+
+	return append(lines, line)
+}
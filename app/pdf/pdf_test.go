@@ -0,0 +1,23 @@
+package pdf
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRender(t *testing.T) {
+	t.Parallel()
+
+	lines := make([]string, 150)
+	for i := range lines {
+		lines[i] = "line"
+	}
+
+	document := string(Render("Title", lines))
+
+	assert.Contains(t, document, "/Count 3")
+	assert.Contains(t, document, "(Title \\(page 3 of 3\\)) Tj")
+	assert.Equal(t, `a\\b \(c\) ?`, escape(`a\b (c) ü`))
+	assert.Equal(t, []string{"aaa bbb", "    ccc"}, wrap("aaa bbb ccc", 8))
+}
@@ -0,0 +1,288 @@
+// Package platform opens tickets for critical errors of Catalyst itself, like
+// a failed backup or a corrupted database, so that the SOC notices when its
+// own tooling degrades. Critical errors are logged with applog.Critical. An
+// error that repeats while its ticket is open only counts the occurrences on
+// that ticket.
+package platform
+
+import (
+	"cmp"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/SecurityBrewery/catalyst/app/applog"
+	"github.com/SecurityBrewery/catalyst/app/auth/usercontext"
+	"github.com/SecurityBrewery/catalyst/app/database"
+	"github.com/SecurityBrewery/catalyst/app/database/sqlc"
+	"github.com/SecurityBrewery/catalyst/app/hook"
+	"github.com/SecurityBrewery/catalyst/app/openapi"
+	"github.com/SecurityBrewery/catalyst/app/redact"
+	"github.com/SecurityBrewery/catalyst/app/settings"
+)
+
+const (
+	DefaultTicketType = "platform"
+
+	checkInterval = time.Hour
+)
+
+var ErrCorrupted = errors.New("database is corrupted")
+
+type Monitor struct {
+	queries *sqlc.Queries
+	hooks   *hook.Hooks
+	logs    *applog.Log
+}
+
+func New(queries *sqlc.Queries, hooks *hook.Hooks, logs *applog.Log) *Monitor {
+	return &Monitor{
+		queries: queries,
+		hooks:   hooks,
+		logs:    logs,
+	}
+}
+
+// Start reports the critical errors of the log and checks the integrity of
+// the database every hour until the context is canceled.
+func (m *Monitor) Start(ctx context.Context) {
+	entries, cancel := m.logs.Subscribe()
+
+	go func() {
+		defer cancel()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case entry := <-entries:
+				if !entry.Critical() {
+					continue
+				}
+
+				// not critical itself, so that it does not loop
+				if err := m.Report(ctx, &entry); err != nil && ctx.Err() == nil {
+					slog.ErrorContext(ctx, "Failed to report platform error", "message", entry.Message, "error", err)
+				}
+			}
+		}
+	}()
+
+	go func() {
+		ticker := time.NewTicker(checkInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := m.CheckIntegrity(ctx); err != nil && ctx.Err() == nil {
+					slog.ErrorContext(ctx, "Database integrity check failed", "error", err, applog.Critical)
+				}
+			}
+		}
+	}()
+}
+
+// CheckIntegrity runs a quick check of the database, which finds corrupted
+// pages and indexes.
+func (m *Monitor) CheckIntegrity(ctx context.Context) error {
+	rows, err := m.queries.ReadDB.QueryContext(ctx, "PRAGMA quick_check")
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var problems []string
+
+	for rows.Next() {
+		var problem string
+		if err := rows.Scan(&problem); err != nil {
+			return err
+		}
+
+		if problem != "ok" {
+			problems = append(problems, problem)
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("%w: %s", ErrCorrupted, strings.Join(problems, "; "))
+	}
+
+	return nil
+}
+
+// Report opens a platform ticket for a critical error or, if the ticket of
+// the error is still open, counts the occurrence on it.
+func (m *Monitor) Report(ctx context.Context, entry *applog.Entry) error {
+	se, err := settings.Load(ctx, m.queries)
+	if err != nil {
+		return err
+	}
+
+	if !se.PlatformTickets.Enabled {
+		return nil
+	}
+
+	id := fingerprint(entry)
+	seen := entry.Created.UTC()
+
+	existing, err := m.queries.GetPlatformError(ctx, id)
+	if err == nil && existing.TicketOpen {
+		recorded, err := m.queries.RecordPlatformError(ctx, sqlc.RecordPlatformErrorParams{Fingerprint: id, Seen: seen})
+		if err != nil {
+			return err
+		}
+
+		return m.updateTicket(ctx, existing.Ticket, recorded.Occurrences, seen)
+	} else if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+
+	ticketType := cmp.Or(se.PlatformTickets.TicketType, DefaultTicketType)
+
+	ticket, err := m.createTicket(ctx, ticketType, entry)
+	if err != nil {
+		return err
+	}
+
+	return m.queries.CreatePlatformError(ctx, sqlc.CreatePlatformErrorParams{Fingerprint: id, Ticket: ticket, Seen: seen})
+}
+
+// fingerprint identifies an error by its component and message. The
+// attributes hold details like ids that differ between occurrences, so they
+// are not part of the fingerprint.
+func fingerprint(entry *applog.Entry) string {
+	sum := sha256.Sum256([]byte(entry.Component + "\n" + entry.Message))
+
+	return hex.EncodeToString(sum[:8])
+}
+
+func (m *Monitor) createTicket(ctx context.Context, typeID string, entry *applog.Entry) (string, error) {
+	ticketType, err := m.queries.GetType(ctx, typeID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get ticket type %q: %w", typeID, err)
+	}
+
+	systemUser, err := m.queries.SystemUser(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to find system user: %w", err)
+	}
+
+	redactor, err := redact.Load(ctx, m.queries)
+	if err != nil {
+		return "", err
+	}
+
+	ctx = usercontext.UserContext(ctx, &systemUser)
+
+	name := "Platform error: " + entry.Message
+	if entry.Component != "" {
+		name = fmt.Sprintf("Platform error in %s: %s", entry.Component, entry.Message)
+	}
+
+	name = redactor.String(name)
+	description := redactor.String(describe(entry))
+
+	state := map[string]any{
+		"component":   entry.Component,
+		"occurrences": 1,
+		"last_seen":   entry.Created.UTC().Format(time.RFC3339),
+	}
+
+	stateJSON, err := json.Marshal(state)
+	if err != nil {
+		return "", err
+	}
+
+	m.hooks.OnRecordBeforeCreateRequest.Publish(ctx, database.TicketsTable.ID, map[string]string{
+		"name":        name,
+		"description": description,
+	})
+
+	ticket, err := m.queries.CreateTicket(ctx, sqlc.CreateTicketParams{
+		Name:        name,
+		Description: description,
+		Open:        true,
+		Type:        ticketType.ID,
+		Schema:      ticketType.Schema,
+		State:       stateJSON,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	m.hooks.OnRecordAfterCreateRequest.Publish(ctx, database.TicketsTable.ID, openapi.Ticket{
+		Created:     ticket.Created,
+		Description: ticket.Description,
+		Id:          ticket.ID,
+		Name:        ticket.Name,
+		Open:        ticket.Open,
+		Type:        ticket.Type,
+		State:       state,
+		Updated:     ticket.Updated,
+	})
+
+	return ticket.ID, nil
+}
+
+// describe lists the message and the attributes of the first occurrence.
+func describe(entry *applog.Entry) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "Catalyst logged a critical error at %s:\n\n%s\n", entry.Created.UTC().Format(time.RFC3339), entry.Message)
+
+	keys := slices.Sorted(maps.Keys(entry.Attrs))
+	keys = slices.DeleteFunc(keys, func(key string) bool { return key == applog.CriticalKey })
+
+	if len(keys) > 0 {
+		b.WriteString("\n")
+
+		for _, key := range keys {
+			fmt.Fprintf(&b, "- %s: %v\n", key, entry.Attrs[key])
+		}
+	}
+
+	b.WriteString("\nLater occurrences are counted on this ticket while it is open.")
+
+	return b.String()
+}
+
+// updateTicket sets the occurrences in the state of the ticket. The other
+// fields of the state are kept.
+func (m *Monitor) updateTicket(ctx context.Context, id string, occurrences int64, seen time.Time) error {
+	ticket, err := m.queries.Ticket(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	state := map[string]any{}
+	_ = json.Unmarshal(ticket.State, &state)
+
+	state["occurrences"] = occurrences
+	state["last_seen"] = seen.Format(time.RFC3339)
+
+	stateJSON, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+
+	_, err = m.queries.UpdateTicket(ctx, sqlc.UpdateTicketParams{ID: id, State: stateJSON})
+
+	return err
+}
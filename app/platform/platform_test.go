@@ -0,0 +1,150 @@
+package platform
+
+import (
+	"encoding/json"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/SecurityBrewery/catalyst/app/applog"
+	"github.com/SecurityBrewery/catalyst/app/data"
+	"github.com/SecurityBrewery/catalyst/app/database/sqlc"
+	"github.com/SecurityBrewery/catalyst/app/hook"
+	"github.com/SecurityBrewery/catalyst/app/pointer"
+	"github.com/SecurityBrewery/catalyst/app/settings"
+)
+
+func enable(t *testing.T, queries *sqlc.Queries) {
+	t.Helper()
+
+	_, err := settings.Update(t.Context(), queries, func(s *settings.Settings) {
+		s.PlatformTickets.Enabled = true
+	})
+	require.NoError(t, err)
+}
+
+// platformTickets returns the ids of the platform tickets.
+func platformTickets(t *testing.T, queries *sqlc.Queries) []string {
+	t.Helper()
+
+	rows, err := queries.ReadDB.QueryContext(t.Context(), "SELECT id FROM tickets WHERE type = ? ORDER BY created, rowid", DefaultTicketType)
+	require.NoError(t, err)
+
+	defer rows.Close()
+
+	var ids []string
+
+	for rows.Next() {
+		var id string
+		require.NoError(t, rows.Scan(&id))
+
+		ids = append(ids, id)
+	}
+
+	require.NoError(t, rows.Err())
+
+	return ids
+}
+
+func TestMonitor_Report(t *testing.T) {
+	t.Parallel()
+
+	queries := data.NewTestDB(t, t.TempDir())
+	m := New(queries, hook.NewHooks(), applog.New(queries))
+
+	entry := &applog.Entry{
+		Level:     slog.LevelError,
+		Message:   "Failed to stream backup",
+		Component: "backup",
+		Attrs:     map[string]any{"location": "s3://backups/catalyst.zip", "error": "connection reset", applog.CriticalKey: true},
+		Created:   time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC),
+	}
+
+	// disabled by default
+	require.NoError(t, m.Report(t.Context(), entry))
+	assert.Empty(t, platformTickets(t, queries))
+
+	enable(t, queries)
+
+	require.NoError(t, m.Report(t.Context(), entry))
+
+	ids := platformTickets(t, queries)
+	require.Len(t, ids, 1)
+
+	ticket, err := queries.Ticket(t.Context(), ids[0])
+	require.NoError(t, err)
+	assert.Equal(t, "Platform error in backup: Failed to stream backup", ticket.Name)
+	assert.Contains(t, ticket.Description, "- error: connection reset\n- location: s3://backups/catalyst.zip\n")
+	assert.NotContains(t, ticket.Description, "- "+applog.CriticalKey)
+
+	// a repeated error is counted on the open ticket
+	entry.Created = entry.Created.Add(time.Hour)
+	entry.Attrs["location"] = "s3://backups/other.zip"
+
+	require.NoError(t, m.Report(t.Context(), entry))
+	assert.Equal(t, ids, platformTickets(t, queries))
+
+	ticket, err = queries.Ticket(t.Context(), ids[0])
+	require.NoError(t, err)
+
+	var state map[string]any
+	require.NoError(t, json.Unmarshal(ticket.State, &state))
+	assert.Equal(t, map[string]any{"component": "backup", "occurrences": float64(2), "last_seen": "2025-06-01T13:00:00Z"}, state)
+
+	// a closed ticket is not reopened, the error opens a new one
+	_, err = queries.UpdateTicket(t.Context(), sqlc.UpdateTicketParams{ID: ids[0], Open: pointer.Pointer(false)})
+	require.NoError(t, err)
+
+	require.NoError(t, m.Report(t.Context(), entry))
+	assert.Len(t, platformTickets(t, queries), 2)
+
+	// a different error opens its own ticket
+	require.NoError(t, m.Report(t.Context(), &applog.Entry{Message: "Database integrity check failed", Component: "platform", Created: entry.Created}))
+	assert.Len(t, platformTickets(t, queries), 3)
+}
+
+func TestMonitor_Start(t *testing.T) {
+	t.Parallel()
+
+	queries := data.NewTestDB(t, t.TempDir())
+	enable(t, queries)
+
+	logs := applog.New(queries)
+	New(queries, hook.NewHooks(), logs).Start(t.Context())
+
+	logger := slog.New(logs.Handler(slog.DiscardHandler))
+
+	logger.Error("Failed to send digest")
+	logger.Error("Failed to record reaction run", "reaction_id", "r_test", applog.Critical)
+
+	require.Eventually(t, func() bool {
+		return len(platformTickets(t, queries)) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	ticket, err := queries.Ticket(t.Context(), platformTickets(t, queries)[0])
+	require.NoError(t, err)
+	assert.Equal(t, "Platform error in platform: Failed to record reaction run", ticket.Name)
+}
+
+func TestMonitor_CheckIntegrity(t *testing.T) {
+	t.Parallel()
+
+	queries := data.NewTestDB(t, t.TempDir())
+
+	require.NoError(t, New(queries, hook.NewHooks(), applog.New(queries)).CheckIntegrity(t.Context()))
+}
+
+func Test_fingerprint(t *testing.T) {
+	t.Parallel()
+
+	a := fingerprint(&applog.Entry{Component: "backup", Message: "Failed to stream backup", Attrs: map[string]any{"location": "a"}})
+	b := fingerprint(&applog.Entry{Component: "backup", Message: "Failed to stream backup", Attrs: map[string]any{"location": "b"}})
+	c := fingerprint(&applog.Entry{Component: "reaction/action", Message: "Failed to stream backup"})
+
+	assert.Equal(t, a, b)
+	assert.NotEqual(t, a, c)
+	assert.Len(t, a, 16)
+}
@@ -0,0 +1,258 @@
+// Package playbook attaches playbooks from the global library to tickets. A
+// playbook declares typed inputs and a list of task templates, so that one
+// playbook, e.g. "Block indicator", can be attached to many tickets with
+// different parameters. Task names and owners are templates that can read
+// the inputs and the ticket, e.g.
+//
+//	Block {{ inputs.indicator }} on {{ inputs.firewall }}
+package playbook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/SecurityBrewery/catalyst/app/database/sqlc"
+	"github.com/SecurityBrewery/catalyst/app/expression"
+)
+
+const (
+	TypeString  = "string"
+	TypeNumber  = "number"
+	TypeBoolean = "boolean"
+)
+
+var ErrInvalidInputs = errors.New("invalid playbook inputs")
+
+// Types returns the supported input types.
+func Types() []string {
+	return []string{TypeString, TypeNumber, TypeBoolean}
+}
+
+// Input is a parameter of a playbook. Inputs without a default value must be
+// given when the playbook is attached.
+type Input struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	Description string `json:"description,omitempty"`
+	Default     any    `json:"default,omitempty"`
+}
+
+func (i *Input) Required() bool {
+	return i.Default == nil
+}
+
+// Task is the template of a task that is created when the playbook is
+// attached to a ticket.
+type Task struct {
+	Name  string `json:"name"`
+	Owner string `json:"owner,omitempty"`
+}
+
+// Run is a playbook attached to a ticket with the tasks it created.
+type Run struct {
+	Run   sqlc.PlaybookRun
+	Tasks []sqlc.Task
+}
+
+func ParseInputs(data []byte) ([]Input, error) {
+	var inputs []Input
+	if err := json.Unmarshal(data, &inputs); err != nil {
+		return nil, err
+	}
+
+	return inputs, nil
+}
+
+func ParseTasks(data []byte) ([]Task, error) {
+	var tasks []Task
+	if err := json.Unmarshal(data, &tasks); err != nil {
+		return nil, err
+	}
+
+	return tasks, nil
+}
+
+// Validate checks the inputs and task templates of a playbook.
+func Validate(inputs []Input, tasks []Task) error {
+	seen := map[string]bool{}
+
+	for _, input := range inputs {
+		if err := expression.ValidateName(input.Name); err != nil {
+			return err
+		}
+
+		if seen[input.Name] {
+			return fmt.Errorf("duplicate input %q", input.Name)
+		}
+
+		seen[input.Name] = true
+
+		if !slices.Contains(Types(), input.Type) {
+			return fmt.Errorf("input %q has the unknown type %q", input.Name, input.Type)
+		}
+
+		if !input.Required() && !hasType(input.Default, input.Type) {
+			return fmt.Errorf("the default of input %q is not a %s", input.Name, input.Type)
+		}
+	}
+
+	if len(tasks) == 0 {
+		return errors.New("a playbook needs at least one task")
+	}
+
+	for i, task := range tasks {
+		if task.Name == "" {
+			return fmt.Errorf("task %d has no name", i+1)
+		}
+
+		if err := expression.CheckTemplate(task.Name); err != nil {
+			return fmt.Errorf("invalid name of task %d: %w", i+1, err)
+		}
+
+		if err := expression.CheckTemplate(task.Owner); err != nil {
+			return fmt.Errorf("invalid owner of task %d: %w", i+1, err)
+		}
+	}
+
+	return nil
+}
+
+// Resolve checks the given values against the inputs of a playbook and fills
+// in the defaults of missing values.
+func Resolve(inputs []Input, values map[string]any) (map[string]any, error) {
+	resolved := make(map[string]any, len(inputs))
+
+	for _, input := range inputs {
+		value, ok := values[input.Name]
+		if !ok || value == nil {
+			if input.Required() {
+				return nil, fmt.Errorf("%w: %q is required", ErrInvalidInputs, input.Name)
+			}
+
+			value = input.Default
+		}
+
+		if !hasType(value, input.Type) {
+			return nil, fmt.Errorf("%w: %q must be a %s", ErrInvalidInputs, input.Name, input.Type)
+		}
+
+		resolved[input.Name] = value
+	}
+
+	for name := range values {
+		if !slices.ContainsFunc(inputs, func(input Input) bool { return input.Name == name }) {
+			return nil, fmt.Errorf("%w: unknown input %q", ErrInvalidInputs, name)
+		}
+	}
+
+	return resolved, nil
+}
+
+// Attach creates the tasks of a playbook on a ticket. The task templates
+// are rendered with the resolved inputs before any task is created, so an
+// invalid template does not leave a partially attached playbook.
+func Attach(ctx context.Context, queries *sqlc.Queries, playbookID, ticketID string, values map[string]any) (*Run, error) {
+	playbook, err := queries.GetPlaybook(ctx, playbookID)
+	if err != nil {
+		return nil, err
+	}
+
+	inputs, err := ParseInputs(playbook.Inputs)
+	if err != nil {
+		return nil, fmt.Errorf("invalid inputs of playbook %s: %w", playbook.ID, err)
+	}
+
+	templates, err := ParseTasks(playbook.Tasks)
+	if err != nil {
+		return nil, fmt.Errorf("invalid tasks of playbook %s: %w", playbook.ID, err)
+	}
+
+	resolved, err := Resolve(inputs, values)
+	if err != nil {
+		return nil, err
+	}
+
+	variables, err := expression.Load(ctx, queries, ticketID)
+	if err != nil {
+		return nil, err
+	}
+
+	variables.Inputs = resolved
+
+	params := make([]sqlc.CreateTaskParams, 0, len(templates))
+
+	for _, template := range templates {
+		name, err := expression.Render(template.Name, variables)
+		if err != nil {
+			return nil, err
+		}
+
+		owner, err := expression.Render(template.Owner, variables)
+		if err != nil {
+			return nil, err
+		}
+
+		task := sqlc.CreateTaskParams{Name: name, Open: true, Ticket: ticketID}
+		if owner != "" {
+			task.Owner = &owner
+		}
+
+		params = append(params, task)
+	}
+
+	b, err := json.Marshal(resolved)
+	if err != nil {
+		return nil, err
+	}
+
+	run, err := queries.CreatePlaybookRun(ctx, sqlc.CreatePlaybookRunParams{
+		Playbook: playbook.ID,
+		Ticket:   ticketID,
+		Inputs:   b,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	tasks := make([]sqlc.Task, 0, len(params))
+
+	for _, param := range params {
+		task, err := queries.CreateTask(ctx, param)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := queries.AddPlaybookRunTask(ctx, sqlc.AddPlaybookRunTaskParams{Run: run.ID, Task: task.ID}); err != nil {
+			return nil, err
+		}
+
+		tasks = append(tasks, task)
+	}
+
+	return &Run{Run: run, Tasks: tasks}, nil
+}
+
+func hasType(value any, typ string) bool {
+	switch typ {
+	case TypeString:
+		_, ok := value.(string)
+
+		return ok
+	case TypeNumber:
+		switch value.(type) {
+		case float64, float32, int, int64:
+			return true
+		}
+
+		return false
+	case TypeBoolean:
+		_, ok := value.(bool)
+
+		return ok
+	default:
+		return false
+	}
+}
@@ -0,0 +1,120 @@
+package playbook
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/SecurityBrewery/catalyst/app/data"
+	"github.com/SecurityBrewery/catalyst/app/database/sqlc"
+)
+
+var blockIndicator = struct {
+	Inputs []Input
+	Tasks  []Task
+}{
+	Inputs: []Input{
+		{Name: "indicator", Type: TypeString},
+		{Name: "firewall", Type: TypeString, Default: "edge"},
+		{Name: "notify", Type: TypeBoolean, Default: false},
+	},
+	Tasks: []Task{
+		{Name: "Block {{ inputs.indicator }} on {{ inputs.firewall }}"},
+		{Name: "Check {{ ticket.name }} for {{ inputs.indicator }}", Owner: "u_bob_analyst"},
+	},
+}
+
+func TestValidate(t *testing.T) {
+	t.Parallel()
+
+	require.NoError(t, Validate(blockIndicator.Inputs, blockIndicator.Tasks))
+
+	tests := []struct {
+		name   string
+		inputs []Input
+		tasks  []Task
+		want   string
+	}{
+		{name: "no tasks", want: "at least one task"},
+		{name: "invalid name", inputs: []Input{{Name: "the indicator", Type: TypeString}}, tasks: blockIndicator.Tasks, want: "invalid variable name"},
+		{name: "duplicate input", inputs: []Input{{Name: "a", Type: TypeString}, {Name: "a", Type: TypeNumber}}, tasks: blockIndicator.Tasks, want: "duplicate input"},
+		{name: "unknown type", inputs: []Input{{Name: "a", Type: "date"}}, tasks: blockIndicator.Tasks, want: "unknown type"},
+		{name: "invalid default", inputs: []Input{{Name: "a", Type: TypeNumber, Default: "ten"}}, tasks: blockIndicator.Tasks, want: "is not a number"},
+		{name: "invalid template", tasks: []Task{{Name: "Block {{ inputs.indicator + }}"}}, want: "invalid name of task 1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.ErrorContains(t, Validate(tt.inputs, tt.tasks), tt.want)
+		})
+	}
+}
+
+func TestResolve(t *testing.T) {
+	t.Parallel()
+
+	resolved, err := Resolve(blockIndicator.Inputs, map[string]any{"indicator": "evil.example", "notify": true})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"indicator": "evil.example", "firewall": "edge", "notify": true}, resolved)
+
+	_, err = Resolve(blockIndicator.Inputs, map[string]any{})
+	require.ErrorIs(t, err, ErrInvalidInputs)
+	assert.ErrorContains(t, err, `"indicator" is required`)
+
+	_, err = Resolve(blockIndicator.Inputs, map[string]any{"indicator": 42.0})
+	require.ErrorIs(t, err, ErrInvalidInputs)
+
+	_, err = Resolve(blockIndicator.Inputs, map[string]any{"indicator": "evil.example", "device": "edge"})
+	require.ErrorIs(t, err, ErrInvalidInputs)
+	assert.ErrorContains(t, err, `unknown input "device"`)
+}
+
+func TestAttach(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	queries := data.NewTestDB(t, t.TempDir())
+
+	inputs, err := json.Marshal(blockIndicator.Inputs)
+	require.NoError(t, err)
+
+	tasks, err := json.Marshal(blockIndicator.Tasks)
+	require.NoError(t, err)
+
+	playbook, err := queries.CreatePlaybook(ctx, sqlc.CreatePlaybookParams{Name: "Block indicator", Inputs: inputs, Tasks: tasks})
+	require.NoError(t, err)
+
+	run, err := Attach(ctx, queries, playbook.ID, "test-ticket", map[string]any{"indicator": "evil.example"})
+	require.NoError(t, err)
+
+	require.Len(t, run.Tasks, 2)
+	assert.Equal(t, "Block evil.example on edge", run.Tasks[0].Name)
+	assert.Nil(t, run.Tasks[0].Owner)
+	assert.Equal(t, "Check Test Ticket for evil.example", run.Tasks[1].Name)
+	assert.Equal(t, "u_bob_analyst", *run.Tasks[1].Owner)
+	assert.JSONEq(t, `{"indicator":"evil.example","firewall":"edge","notify":false}`, string(run.Run.Inputs))
+
+	runTasks, err := queries.ListPlaybookRunTasks(ctx, run.Run.ID)
+	require.NoError(t, err)
+	assert.Len(t, runTasks, 2)
+
+	// the same playbook with other parameters
+	_, err = Attach(ctx, queries, playbook.ID, "test-ticket", map[string]any{"indicator": "198.51.100.7", "firewall": "dmz"})
+	require.NoError(t, err)
+
+	runs, err := queries.ListPlaybookRuns(ctx, "test-ticket")
+	require.NoError(t, err)
+	assert.Len(t, runs, 2)
+
+	// missing inputs create no tasks
+	_, err = Attach(ctx, queries, playbook.ID, "test-ticket", nil)
+	require.ErrorIs(t, err, ErrInvalidInputs)
+
+	runs, err = queries.ListPlaybookRuns(ctx, "test-ticket")
+	require.NoError(t, err)
+	assert.Len(t, runs, 2)
+}
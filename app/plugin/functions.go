@@ -0,0 +1,160 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+const (
+	validateFunction = "validate"
+	enrichFunction   = "enrich"
+	mapFunction      = "map"
+)
+
+// functions are the exports that return an output as a packed
+// (ptr << 32 | len) i64, an empty output means the plugin has no result.
+var functions = []string{validateFunction, enrichFunction, mapFunction}
+
+var ErrNoMapper = errors.New("no plugin with a map function")
+
+// ValidationError is returned by Validate if a plugin rejects a record.
+type ValidationError struct {
+	Plugin string
+	Errors []string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("rejected by plugin %s: %s", e.Plugin, strings.Join(e.Errors, "; "))
+}
+
+type recordInput struct {
+	Table  string `json:"table"`
+	Record any    `json:"record"`
+}
+
+type validation struct {
+	Errors []string `json:"errors"`
+}
+
+// Validate passes a record that is about to be stored to the validate
+// function of every plugin, ordered by name. The function receives the table
+// and the record like a hook and returns {"errors": [...]} to reject the
+// record, or no output to accept it.
+func (m *Manager) Validate(ctx context.Context, table string, record any) error {
+	input, err := json.Marshal(recordInput{Table: table, Record: record})
+	if err != nil {
+		return err
+	}
+
+	for _, p := range m.exporting(validateFunction) {
+		output, err := p.callOutput(ctx, validateFunction, input)
+		if err != nil {
+			return fmt.Errorf("plugin %s: %w", p.Name, err)
+		}
+
+		if len(output) == 0 {
+			continue
+		}
+
+		var v validation
+		if err := json.Unmarshal(output, &v); err != nil {
+			return fmt.Errorf("plugin %s returned an invalid validation: %w", p.Name, err)
+		}
+
+		if len(v.Errors) > 0 {
+			return &ValidationError{Plugin: p.Name, Errors: v.Errors}
+		}
+	}
+
+	return nil
+}
+
+// Enrich passes a record that is about to be stored through the enrich
+// function of every plugin, ordered by name. The function receives the table
+// and the record like a hook and returns the enriched record, or no output
+// to keep it. The fields of the output are decoded into record, which must
+// be a pointer.
+func (m *Manager) Enrich(ctx context.Context, table string, record any) error {
+	for _, p := range m.exporting(enrichFunction) {
+		input, err := json.Marshal(recordInput{Table: table, Record: record})
+		if err != nil {
+			return err
+		}
+
+		output, err := p.callOutput(ctx, enrichFunction, input)
+		if err != nil {
+			return fmt.Errorf("plugin %s: %w", p.Name, err)
+		}
+
+		if len(output) == 0 {
+			continue
+		}
+
+		if err := json.Unmarshal(output, record); err != nil {
+			return fmt.Errorf("plugin %s returned an invalid record: %w", p.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// Map passes an event of an alert source to the map function of the named
+// plugin. The function receives the source and the event and returns the
+// event the paths of an alert mapping are applied to, or no output to keep
+// the event.
+func (m *Manager) Map(ctx context.Context, name, source string, event []byte) ([]byte, error) {
+	p, err := m.mapper(name)
+	if err != nil {
+		return nil, err
+	}
+
+	input, err := json.Marshal(map[string]any{"source": source, "event": json.RawMessage(event)})
+	if err != nil {
+		return nil, err
+	}
+
+	output, err := p.callOutput(ctx, mapFunction, input)
+	if err != nil {
+		return nil, fmt.Errorf("plugin %s: %w", p.Name, err)
+	}
+
+	if len(output) == 0 {
+		return event, nil
+	}
+
+	return output, nil
+}
+
+// CheckMapper returns ErrNoMapper if the named plugin is not loaded or has
+// no map function.
+func (m *Manager) CheckMapper(name string) error {
+	_, err := m.mapper(name)
+
+	return err
+}
+
+func (m *Manager) mapper(name string) (*Plugin, error) {
+	for _, p := range m.exporting(mapFunction) {
+		if p.Name == name {
+			return p, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: %q", ErrNoMapper, name)
+}
+
+// exporting returns the loaded plugins that export the function, ordered by
+// name. A nil manager has no plugins.
+func (m *Manager) exporting(function string) []*Plugin {
+	if m == nil {
+		return nil
+	}
+
+	return slices.DeleteFunc(m.List(), func(p *Plugin) bool {
+		return !slices.Contains(p.Functions, function)
+	})
+}
@@ -0,0 +1,120 @@
+package plugin
+
+import (
+	"crypto/ed25519"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// functionModule is a minimal plugin with a function that always returns
+// output, which must be shorter than 64 bytes.
+func functionModule(function, output string) []byte {
+	return wasmModule(
+		// type section: (i32, i32) -> i64, (i32) -> i32
+		wasmSection(0x01, 0x02, 0x60, 0x02, 0x7f, 0x7f, 0x01, 0x7e, 0x60, 0x01, 0x7f, 0x01, 0x7f),
+		// function section
+		wasmSection(0x03, 0x02, 0x01, 0x00),
+		// memory section: one page
+		wasmSection(0x05, 0x01, 0x00, 0x01),
+		// export section: memory, malloc, function
+		wasmSection(0x07, concat(
+			[]byte{0x03},
+			wasmName("memory"), []byte{0x02, 0x00},
+			wasmName("malloc"), []byte{0x00, 0x00},
+			wasmName(function), []byte{0x00, 0x01},
+		)...),
+		// code section: malloc returns 1024, the function returns (0 << 32 | len)
+		wasmSection(0x0a,
+			0x02,
+			0x05, 0x00, 0x41, 0x80, 0x08, 0x0b,
+			0x04, 0x00, 0x42, byte(len(output)), 0x0b,
+		),
+		// data section: the output at offset 0
+		wasmSection(0x0b, concat(
+			[]byte{0x01, 0x00, 0x41, 0x00, 0x0b},
+			wasmName(output),
+		)...),
+	)
+}
+
+func install(t *testing.T, m *Manager, privateKey ed25519.PrivateKey, name string, wasm []byte) *Plugin {
+	t.Helper()
+
+	p, err := m.Install(t.Context(), name, wasm, ed25519.Sign(privateKey, wasm))
+	require.NoError(t, err)
+
+	return p
+}
+
+func TestManager_Validate(t *testing.T) {
+	t.Parallel()
+
+	m, _, privateKey := newTestManager(t)
+
+	require.NoError(t, m.Validate(t.Context(), "tickets", map[string]any{"name": "Phishing"}))
+
+	p := install(t, m, privateKey, "accept", functionModule(validateFunction, ""))
+	assert.Equal(t, []string{validateFunction}, p.Functions)
+
+	require.NoError(t, m.Validate(t.Context(), "tickets", map[string]any{"name": "Phishing"}))
+
+	install(t, m, privateKey, "owner", functionModule(validateFunction, `{"errors":["missing owner"]}`))
+
+	err := m.Validate(t.Context(), "tickets", map[string]any{"name": "Phishing"})
+
+	var validationErr *ValidationError
+	require.ErrorAs(t, err, &validationErr)
+	assert.Equal(t, "owner", validationErr.Plugin)
+	assert.Equal(t, []string{"missing owner"}, validationErr.Errors)
+}
+
+func TestManager_Enrich(t *testing.T) {
+	t.Parallel()
+
+	m, _, privateKey := newTestManager(t)
+
+	type ticket struct {
+		Name  string         `json:"name"`
+		State map[string]any `json:"state"`
+	}
+
+	install(t, m, privateKey, "keep", functionModule(enrichFunction, ""))
+
+	record := &ticket{Name: "Phishing", State: map[string]any{"severity": "Low"}}
+	require.NoError(t, m.Enrich(t.Context(), "tickets", record))
+	assert.Equal(t, &ticket{Name: "Phishing", State: map[string]any{"severity": "Low"}}, record)
+
+	install(t, m, privateKey, "severity", functionModule(enrichFunction, `{"state":{"severity":"High"}}`))
+
+	require.NoError(t, m.Enrich(t.Context(), "tickets", record))
+	assert.Equal(t, &ticket{Name: "Phishing", State: map[string]any{"severity": "High"}}, record)
+}
+
+func TestManager_Map(t *testing.T) {
+	t.Parallel()
+
+	m, _, privateKey := newTestManager(t)
+
+	install(t, m, privateKey, "lift", functionModule(mapFunction, `{"name":"Lifted"}`))
+	install(t, m, privateKey, "keep", functionModule(mapFunction, ""))
+	install(t, m, privateKey, "other", functionModule(validateFunction, ""))
+
+	event, err := m.Map(t.Context(), "lift", "wazuh", []byte(`{"rule":{"name":"Lifted"}}`))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"name":"Lifted"}`, string(event))
+
+	event, err = m.Map(t.Context(), "keep", "wazuh", []byte(`{"rule":{"name":"Kept"}}`))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"rule":{"name":"Kept"}}`, string(event))
+
+	require.NoError(t, m.CheckMapper("lift"))
+	require.ErrorIs(t, m.CheckMapper("other"), ErrNoMapper)
+
+	_, err = m.Map(t.Context(), "other", "wazuh", []byte(`{}`))
+	require.ErrorIs(t, err, ErrNoMapper)
+
+	_, err = m.Map(t.Context(), "unknown", "wazuh", []byte(`{}`))
+	require.ErrorIs(t, err, ErrNoMapper)
+}
@@ -0,0 +1,136 @@
+package plugin
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/SecurityBrewery/catalyst/app/auth"
+	"github.com/SecurityBrewery/catalyst/app/auth/usercontext"
+)
+
+const maxRequestBody = 1 << 20
+
+// httpRequest is the request passed to a plugin. The credentials of the
+// caller are not passed on, the plugin gets the id and the scopes of the
+// authenticated user instead.
+type httpRequest struct {
+	Method  string              `json:"method"`
+	Path    string              `json:"path"`
+	Query   map[string][]string `json:"query"`
+	Headers map[string][]string `json:"headers"`
+	Body    string              `json:"body"`
+	User    string              `json:"user"`
+	Scopes  []string            `json:"scopes"`
+}
+
+type httpResponse struct {
+	Status  int               `json:"status"`
+	Headers map[string]string `json:"headers"`
+	Body    string            `json:"body"`
+}
+
+// ServeHTTP routes /{plugin}/{path} to the http function of the plugin. The
+// function receives the request as JSON, without the credentials of the
+// caller but with the id and the scopes of the user, and returns the response
+// as JSON with status, headers and body.
+func (m *Manager) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	name, path, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+
+	p, ok := m.Get(name)
+	if !ok || !p.HTTP {
+		http.NotFound(w, r)
+
+		return
+	}
+
+	auth.RequireScopes(p.Permissions)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p.serveHTTP(w, r, "/"+path)
+	})).ServeHTTP(w, r)
+}
+
+func (p *Plugin) serveHTTP(w http.ResponseWriter, r *http.Request, path string) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxRequestBody))
+	if err != nil {
+		http.Error(w, "request body too large", http.StatusRequestEntityTooLarge)
+
+		return
+	}
+
+	input, err := json.Marshal(newHTTPRequest(r, path, body))
+	if err != nil {
+		http.Error(w, "failed to encode request", http.StatusInternalServerError)
+
+		return
+	}
+
+	output, err := p.callOutput(r.Context(), httpFunction, input)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "Plugin request failed", "plugin", p.Name, "error", err)
+		http.Error(w, "plugin request failed", http.StatusBadGateway)
+
+		return
+	}
+
+	var response httpResponse
+	if err := json.Unmarshal(output, &response); err != nil {
+		slog.ErrorContext(r.Context(), "Invalid plugin response", "plugin", p.Name, "error", err)
+		http.Error(w, "invalid plugin response", http.StatusBadGateway)
+
+		return
+	}
+
+	for key, value := range response.Headers {
+		w.Header().Set(key, value)
+	}
+
+	if response.Status == 0 {
+		response.Status = http.StatusOK
+	}
+
+	w.WriteHeader(response.Status)
+
+	_, _ = w.Write([]byte(response.Body))
+}
+
+func newHTTPRequest(r *http.Request, path string, body []byte) httpRequest {
+	request := httpRequest{
+		Method:  r.Method,
+		Path:    path,
+		Query:   r.URL.Query(),
+		Headers: forwardedHeaders(r.Header),
+		Body:    string(body),
+		Scopes:  []string{},
+	}
+
+	if user, ok := usercontext.UserFromContext(r.Context()); ok {
+		request.User = user.ID
+	}
+
+	if permissions, ok := usercontext.PermissionFromContext(r.Context()); ok {
+		request.Scopes = permissions
+	}
+
+	return request
+}
+
+// forwardedHeaders drops the credentials and the internal headers of
+// Catalyst from the headers passed to a plugin.
+func forwardedHeaders(header http.Header) map[string][]string {
+	forwarded := make(map[string][]string, len(header))
+
+	for key, values := range header {
+		switch key = http.CanonicalHeaderKey(key); {
+		case key == "Authorization", key == "Proxy-Authorization", key == "Cookie":
+			continue
+		case strings.HasPrefix(key, "X-Catalyst-"):
+			continue
+		}
+
+		forwarded[key] = values
+	}
+
+	return forwarded
+}
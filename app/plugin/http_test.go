@@ -0,0 +1,128 @@
+package plugin
+
+import (
+	"crypto/ed25519"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/SecurityBrewery/catalyst/app/auth/usercontext"
+	"github.com/SecurityBrewery/catalyst/app/database/sqlc"
+)
+
+const httpTestResponse = `{"status":201,"body":"hello"}`
+
+// httpTestModule is a minimal plugin with an http function that always
+// returns httpTestResponse and requires the ticket:read scope.
+var httpTestModule = wasmModule(
+	// type section: (i32, i32) -> i64, (i32) -> i32
+	wasmSection(0x01, 0x02, 0x60, 0x02, 0x7f, 0x7f, 0x01, 0x7e, 0x60, 0x01, 0x7f, 0x01, 0x7f),
+	// function section
+	wasmSection(0x03, 0x02, 0x01, 0x00),
+	// memory section: one page
+	wasmSection(0x05, 0x01, 0x00, 0x01),
+	// export section: memory, malloc, http
+	wasmSection(0x07, concat(
+		[]byte{0x03},
+		wasmName("memory"), []byte{0x02, 0x00},
+		wasmName("malloc"), []byte{0x00, 0x00},
+		wasmName("http"), []byte{0x00, 0x01},
+	)...),
+	// code section: malloc returns 1024, http returns (0 << 32 | len)
+	wasmSection(0x0a,
+		0x02,
+		0x05, 0x00, 0x41, 0x80, 0x08, 0x0b,
+		0x04, 0x00, 0x42, byte(len(httpTestResponse)), 0x0b,
+	),
+	// data section: the response at offset 0
+	wasmSection(0x0b, concat(
+		[]byte{0x01, 0x00, 0x41, 0x00, 0x0b},
+		wasmName(httpTestResponse),
+	)...),
+	// custom section: required scopes
+	wasmSection(0x00, concat(wasmName(permissionsSection), []byte(`["ticket:read"]`))...),
+)
+
+func wasmModule(sections ...[]byte) []byte {
+	return concat(append([][]byte{{0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00}}, sections...)...)
+}
+
+func wasmSection(id byte, content ...byte) []byte {
+	return concat([]byte{id, byte(len(content))}, content)
+}
+
+func wasmName(name string) []byte {
+	return concat([]byte{byte(len(name))}, []byte(name))
+}
+
+func concat(parts ...[]byte) []byte {
+	var b []byte
+	for _, part := range parts {
+		b = append(b, part...)
+	}
+
+	return b
+}
+
+func TestManager_ServeHTTP(t *testing.T) {
+	t.Parallel()
+
+	m, _, privateKey := newTestManager(t)
+
+	p, err := m.Install(t.Context(), "intake", httpTestModule, ed25519.Sign(privateKey, httpTestModule))
+	require.NoError(t, err)
+	assert.True(t, p.HTTP)
+	assert.Equal(t, []string{"ticket:read"}, p.Permissions)
+
+	tests := []struct {
+		name        string
+		path        string
+		permissions []string
+		wantStatus  int
+		wantBody    string
+	}{
+		{name: "allowed", path: "/intake/form", permissions: []string{"ticket:read"}, wantStatus: http.StatusCreated, wantBody: "hello"},
+		{name: "missing scope", path: "/intake/form", permissions: []string{"file:read"}, wantStatus: http.StatusUnauthorized},
+		{name: "unknown plugin", path: "/unknown/form", permissions: []string{"ticket:read"}, wantStatus: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			r := httptest.NewRequest(http.MethodPost, tt.path, nil)
+			r = usercontext.PermissionRequest(r, tt.permissions)
+			w := httptest.NewRecorder()
+
+			m.ServeHTTP(w, r)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+
+			if tt.wantBody != "" {
+				assert.Equal(t, tt.wantBody, w.Body.String())
+			}
+		})
+	}
+}
+
+func Test_newHTTPRequest(t *testing.T) {
+	t.Parallel()
+
+	r := httptest.NewRequest(http.MethodPost, "/intake/form?a=b", nil)
+	r.Header.Set("Authorization", "Bearer secret")
+	r.Header.Set("Cookie", "session=secret")
+	r.Header.Set("X-Catalyst-Ticket", "ticket")
+	r.Header.Set("Content-Type", "application/json")
+	r = usercontext.UserRequest(r, &sqlc.User{ID: "u_bob"})
+	r = usercontext.PermissionRequest(r, []string{"ticket:read"})
+
+	request := newHTTPRequest(r, "/form", []byte("{}"))
+
+	assert.Equal(t, map[string][]string{"Content-Type": {"application/json"}}, request.Headers)
+	assert.Equal(t, "u_bob", request.User)
+	assert.Equal(t, []string{"ticket:read"}, request.Scopes)
+	assert.Equal(t, "/form", request.Path)
+	assert.Equal(t, "{}", request.Body)
+}
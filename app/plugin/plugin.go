@@ -0,0 +1,536 @@
+// Package plugin runs server side extensions compiled to WebAssembly.
+//
+// A plugin is a WebAssembly module that exports a memory, a function
+// malloc(size i32) i32 and one function (ptr i32, len i32) per hook event it
+// handles, named like the event, e.g. OnRecordAfterCreateRequest. The
+// function receives a JSON object with the table and the record. Plugins can
+// call the host function catalyst.log(ptr i32, len i32) to write a log line.
+//
+// Plugins can check and change records before they are stored by exporting
+// validate(ptr i32, len i32) i64 and enrich(ptr i32, len i32) i64, and turn
+// the events of alert sources into the form of an alert mapping by exporting
+// map(ptr i32, len i32) i64, see Validate, Enrich and Map.
+//
+// A plugin can serve HTTP requests under /api/ext/{name} by exporting
+// http(ptr i32, len i32) i64, see ServeHTTP. The scopes required to call
+// these routes are read from the custom section catalyst.permissions, a JSON
+// array of scopes. Without that section only admins can call them.
+//
+// Plugins are loaded from the plugins directory and must be signed with one
+// of the ed25519 keys listed in its trusted_keys file.
+package plugin
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
+
+	"github.com/SecurityBrewery/catalyst/app/hook"
+)
+
+const (
+	trustedKeysFile    = "trusted_keys"
+	permissionsSection = "catalyst.permissions"
+	httpFunction       = "http"
+	callTimeout        = 5 * time.Second
+)
+
+var (
+	validName = regexp.MustCompile(`^[a-z0-9_-]+$`)
+
+	hookEvents = []string{
+		"OnRecordsListRequest",
+		"OnRecordViewRequest",
+		"OnRecordBeforeCreateRequest",
+		"OnRecordAfterCreateRequest",
+		"OnRecordBeforeUpdateRequest",
+		"OnRecordAfterUpdateRequest",
+		"OnRecordBeforeDeleteRequest",
+		"OnRecordAfterDeleteRequest",
+	}
+)
+
+type Plugin struct {
+	Name  string
+	Hooks []string
+	// Functions are the exported validate, enrich and map functions.
+	Functions   []string
+	HTTP        bool
+	Permissions []string
+
+	mux    sync.Mutex
+	module api.Module
+}
+
+type Manager struct {
+	dir         string
+	hooks       *hook.Hooks
+	runtime     wazero.Runtime
+	trustedKeys []ed25519.PublicKey
+
+	// install serializes the changes of the plugin directory.
+	install   sync.Mutex
+	instances atomic.Uint64
+
+	mux     sync.Mutex
+	plugins map[string]*Plugin
+}
+
+// New creates the plugin directory if needed and loads all plugins in it.
+// Plugins that fail to load are skipped.
+func New(ctx context.Context, dir string, hooks *hook.Hooks) (*Manager, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create plugin directory: %w", err)
+	}
+
+	trustedKeys, err := readTrustedKeys(filepath.Join(dir, trustedKeysFile))
+	if err != nil {
+		return nil, err
+	}
+
+	runtime := wazero.NewRuntimeWithConfig(ctx, wazero.NewRuntimeConfig().
+		WithCloseOnContextDone(true).
+		WithCustomSections(true))
+
+	if _, err := runtime.NewHostModuleBuilder("catalyst").
+		NewFunctionBuilder().WithFunc(hostLog).Export("log").
+		Instantiate(ctx); err != nil {
+		return nil, fmt.Errorf("failed to create host module: %w", err)
+	}
+
+	m := &Manager{
+		dir:         dir,
+		hooks:       hooks,
+		runtime:     runtime,
+		trustedKeys: trustedKeys,
+		plugins:     map[string]*Plugin{},
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read plugin directory: %w", err)
+	}
+
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), ".wasm")
+		if !ok || entry.IsDir() {
+			continue
+		}
+
+		if err := m.loadFile(ctx, name); err != nil {
+			slog.ErrorContext(ctx, "Failed to load plugin", "name", name, "error", err)
+		}
+	}
+
+	return m, nil
+}
+
+// Install verifies, loads and stores a plugin, replacing an installed plugin
+// with the same name. An installed plugin keeps running and its files are
+// kept if the new plugin fails to load or to be stored.
+func (m *Manager) Install(ctx context.Context, name string, wasm, signature []byte) (*Plugin, error) {
+	if !validName.MatchString(name) {
+		return nil, fmt.Errorf("invalid plugin name %q", name)
+	}
+
+	if err := m.verify(wasm, signature); err != nil {
+		return nil, err
+	}
+
+	m.install.Lock()
+	defer m.install.Unlock()
+
+	p, err := m.load(ctx, name, wasm)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := writeFile(m.dir, name+".wasm.sig", signature); err != nil {
+		_ = p.module.Close(ctx)
+
+		return nil, fmt.Errorf("failed to store plugin signature: %w", err)
+	}
+
+	if err := writeFile(m.dir, name+".wasm", wasm); err != nil {
+		_ = p.module.Close(ctx)
+
+		return nil, fmt.Errorf("failed to store plugin: %w", err)
+	}
+
+	if err := m.activate(ctx, p); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// Remove unloads a plugin and deletes it from the plugin directory.
+func (m *Manager) Remove(ctx context.Context, name string) error {
+	if !validName.MatchString(name) {
+		return fmt.Errorf("invalid plugin name %q", name)
+	}
+
+	m.install.Lock()
+	defer m.install.Unlock()
+
+	if err := m.unload(ctx, name); err != nil {
+		return err
+	}
+
+	if err := os.Remove(filepath.Join(m.dir, name+".wasm")); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to delete plugin: %w", err)
+	}
+
+	if err := os.Remove(filepath.Join(m.dir, name+".wasm.sig")); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to delete plugin signature: %w", err)
+	}
+
+	return nil
+}
+
+// List returns the loaded plugins ordered by name.
+func (m *Manager) List() []*Plugin {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	plugins := make([]*Plugin, 0, len(m.plugins))
+	for _, p := range m.plugins {
+		plugins = append(plugins, p)
+	}
+
+	slices.SortFunc(plugins, func(a, b *Plugin) int { return strings.Compare(a.Name, b.Name) })
+
+	return plugins
+}
+
+// Get returns a loaded plugin by name.
+func (m *Manager) Get(name string) (*Plugin, bool) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	p, ok := m.plugins[name]
+
+	return p, ok
+}
+
+func (m *Manager) Close(ctx context.Context) error {
+	return m.runtime.Close(ctx)
+}
+
+func (m *Manager) loadFile(ctx context.Context, name string) error {
+	wasm, err := os.ReadFile(filepath.Join(m.dir, name+".wasm"))
+	if err != nil {
+		return fmt.Errorf("failed to read plugin: %w", err)
+	}
+
+	signature, err := os.ReadFile(filepath.Join(m.dir, name+".wasm.sig"))
+	if err != nil {
+		return fmt.Errorf("failed to read plugin signature: %w", err)
+	}
+
+	if err := m.verify(wasm, signature); err != nil {
+		return err
+	}
+
+	p, err := m.load(ctx, name, wasm)
+	if err != nil {
+		return err
+	}
+
+	return m.activate(ctx, p)
+}
+
+func (m *Manager) verify(wasm, signature []byte) error {
+	if len(m.trustedKeys) == 0 {
+		return errors.New("no trusted plugin keys configured")
+	}
+
+	for _, key := range m.trustedKeys {
+		if ed25519.Verify(key, wasm, signature) {
+			return nil
+		}
+	}
+
+	return errors.New("invalid plugin signature")
+}
+
+// load compiles and instantiates a plugin. A loaded plugin with the same
+// name keeps running until the new plugin is activated.
+func (m *Manager) load(ctx context.Context, name string, wasm []byte) (*Plugin, error) {
+	compiled, err := m.runtime.CompileModule(ctx, wasm)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile plugin: %w", err)
+	}
+
+	// module names are unique in the runtime
+	instance := fmt.Sprintf("%s#%d", name, m.instances.Add(1))
+
+	module, err := m.runtime.InstantiateModule(ctx, compiled, wazero.NewModuleConfig().WithName(instance))
+	if err != nil {
+		return nil, fmt.Errorf("failed to instantiate plugin: %w", err)
+	}
+
+	if module.ExportedFunction("malloc") == nil {
+		_ = module.Close(ctx)
+
+		return nil, errors.New("plugin does not export malloc")
+	}
+
+	permissions, err := declaredPermissions(compiled)
+	if err != nil {
+		_ = module.Close(ctx)
+
+		return nil, err
+	}
+
+	p := &Plugin{
+		Name:        name,
+		HTTP:        module.ExportedFunction(httpFunction) != nil,
+		Permissions: permissions,
+		module:      module,
+	}
+
+	for _, function := range functions {
+		if module.ExportedFunction(function) != nil {
+			p.Functions = append(p.Functions, function)
+		}
+	}
+
+	for _, event := range hookEvents {
+		if module.ExportedFunction(event) == nil {
+			continue
+		}
+
+		if _, ok := m.hooks.Hook(event); ok {
+			p.Hooks = append(p.Hooks, event)
+		}
+	}
+
+	return p, nil
+}
+
+// activate registers the hooks of a loaded plugin and replaces the plugin
+// with the same name, which is closed.
+func (m *Manager) activate(ctx context.Context, p *Plugin) error {
+	m.mux.Lock()
+	previous, ok := m.plugins[p.Name]
+	m.plugins[p.Name] = p
+	m.mux.Unlock()
+
+	for _, event := range p.Hooks {
+		if h, ok := m.hooks.Hook(event); ok {
+			h.Register(hookName(p.Name), p.handler(event))
+		}
+	}
+
+	slog.InfoContext(ctx, "Loaded plugin", "name", p.Name, "hooks", p.Hooks)
+
+	if !ok {
+		return nil
+	}
+
+	for _, event := range previous.Hooks {
+		if slices.Contains(p.Hooks, event) {
+			continue
+		}
+
+		if h, ok := m.hooks.Hook(event); ok {
+			h.Unregister(hookName(p.Name))
+		}
+	}
+
+	previous.mux.Lock()
+	defer previous.mux.Unlock()
+
+	return previous.module.Close(ctx)
+}
+
+func (m *Manager) unload(ctx context.Context, name string) error {
+	m.mux.Lock()
+	p, ok := m.plugins[name]
+	delete(m.plugins, name)
+	m.mux.Unlock()
+
+	if !ok {
+		return nil
+	}
+
+	for _, event := range p.Hooks {
+		if h, ok := m.hooks.Hook(event); ok {
+			h.Unregister(hookName(name))
+		}
+	}
+
+	p.mux.Lock()
+	defer p.mux.Unlock()
+
+	return p.module.Close(ctx)
+}
+
+func (p *Plugin) handler(event string) func(ctx context.Context, table string, record any) {
+	return func(ctx context.Context, table string, record any) {
+		input, err := json.Marshal(map[string]any{"table": table, "record": record})
+		if err != nil {
+			slog.ErrorContext(ctx, "Failed to marshal plugin input", "plugin", p.Name, "error", err)
+
+			return
+		}
+
+		if _, err := p.call(ctx, event, input); err != nil {
+			slog.ErrorContext(ctx, "Plugin failed", "plugin", p.Name, "event", event, "error", err)
+		}
+	}
+}
+
+// call copies the input into the plugin memory and calls the exported
+// function. Module instances are not safe for concurrent use.
+func (p *Plugin) call(ctx context.Context, function string, input []byte) ([]uint64, error) {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), callTimeout)
+	defer cancel()
+
+	res, err := p.module.ExportedFunction("malloc").Call(ctx, uint64(len(input)))
+	if err != nil {
+		return nil, fmt.Errorf("malloc failed: %w", err)
+	}
+
+	ptr := api.DecodeU32(res[0])
+
+	if !p.module.Memory().Write(ptr, input) {
+		return nil, errors.New("input exceeds plugin memory")
+	}
+
+	res, err = p.module.ExportedFunction(function).Call(ctx, uint64(ptr), uint64(len(input)))
+	if err != nil {
+		return nil, fmt.Errorf("%s failed: %w", function, err)
+	}
+
+	return res, nil
+}
+
+// callOutput calls a function that returns its output as a packed
+// (ptr << 32 | len) i64 and returns a copy of the output.
+func (p *Plugin) callOutput(ctx context.Context, function string, input []byte) ([]byte, error) {
+	res, err := p.call(ctx, function, input)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(res) != 1 {
+		return nil, fmt.Errorf("%s must return an i64", function)
+	}
+
+	p.mux.Lock()
+	defer p.mux.Unlock()
+
+	output, ok := p.module.Memory().Read(uint32(res[0]>>32), uint32(res[0])) //nolint:gosec
+	if !ok {
+		return nil, fmt.Errorf("%s returned output outside of plugin memory", function)
+	}
+
+	return bytes.Clone(output), nil
+}
+
+func declaredPermissions(compiled wazero.CompiledModule) ([]string, error) {
+	for _, section := range compiled.CustomSections() {
+		if section.Name() != permissionsSection {
+			continue
+		}
+
+		var permissions []string
+		if err := json.Unmarshal(section.Data(), &permissions); err != nil {
+			return nil, fmt.Errorf("invalid %s section: %w", permissionsSection, err)
+		}
+
+		return permissions, nil
+	}
+
+	return []string{"admin"}, nil
+}
+
+func hostLog(ctx context.Context, module api.Module, ptr, size uint32) {
+	message, ok := module.Memory().Read(ptr, size)
+	if !ok {
+		return
+	}
+
+	name, _, _ := strings.Cut(module.Name(), "#")
+
+	slog.InfoContext(ctx, string(message), "plugin", name)
+}
+
+// writeFile replaces a file of the plugin directory atomically.
+func writeFile(dir, name string, data []byte) error {
+	f, err := os.CreateTemp(dir, "."+name+"-*")
+	if err != nil {
+		return err
+	}
+
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+
+		return err
+	}
+
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+
+		return err
+	}
+
+	return os.Rename(f.Name(), filepath.Join(dir, name))
+}
+
+func hookName(plugin string) string {
+	return "plugin:" + plugin
+}
+
+func readTrustedKeys(path string) ([]ed25519.PublicKey, error) {
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read trusted plugin keys: %w", err)
+	}
+
+	var keys []ed25519.PublicKey
+
+	scanner := bufio.NewScanner(bytes.NewReader(b))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		key, err := base64.StdEncoding.DecodeString(line)
+		if err != nil || len(key) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("invalid trusted plugin key %q", line)
+		}
+
+		keys = append(keys, key)
+	}
+
+	return keys, nil
+}
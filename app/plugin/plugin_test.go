@@ -0,0 +1,141 @@
+package plugin
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/SecurityBrewery/catalyst/app/hook"
+)
+
+// testModule is a minimal plugin that passes its OnRecordAfterCreateRequest
+// input to catalyst.log. Its malloc always returns offset 1024.
+var testModule = []byte{
+	0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00,
+	// type section: (i32, i32) -> (), (i32) -> i32
+	0x01, 0x0b, 0x02, 0x60, 0x02, 0x7f, 0x7f, 0x00, 0x60, 0x01, 0x7f, 0x01, 0x7f,
+	// import section: catalyst.log
+	0x02, 0x10, 0x01, 0x08, 'c', 'a', 't', 'a', 'l', 'y', 's', 't', 0x03, 'l', 'o', 'g', 0x00, 0x00,
+	// function section
+	0x03, 0x03, 0x02, 0x01, 0x00,
+	// memory section: one page
+	0x05, 0x03, 0x01, 0x00, 0x01,
+	// export section: memory, malloc, OnRecordAfterCreateRequest
+	0x07, 0x30, 0x03,
+	0x06, 'm', 'e', 'm', 'o', 'r', 'y', 0x02, 0x00,
+	0x06, 'm', 'a', 'l', 'l', 'o', 'c', 0x00, 0x01,
+	0x1a, 'O', 'n', 'R', 'e', 'c', 'o', 'r', 'd', 'A', 'f', 't', 'e', 'r', 'C', 'r', 'e', 'a', 't', 'e', 'R', 'e', 'q', 'u', 'e', 's', 't', 0x00, 0x02,
+	// code section
+	0x0a, 0x10, 0x02,
+	0x05, 0x00, 0x41, 0x80, 0x08, 0x0b,
+	0x08, 0x00, 0x20, 0x00, 0x20, 0x01, 0x10, 0x00, 0x0b,
+}
+
+func newTestManager(t *testing.T) (*Manager, *hook.Hooks, ed25519.PrivateKey) {
+	t.Helper()
+
+	publicKey, privateKey, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, trustedKeysFile), []byte(base64.StdEncoding.EncodeToString(publicKey)+"\n"), 0o600))
+
+	hooks := hook.NewHooks()
+
+	m, err := New(t.Context(), dir, hooks)
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = m.Close(context.Background()) })
+
+	return m, hooks, privateKey
+}
+
+func TestManager_Install(t *testing.T) {
+	t.Parallel()
+
+	m, hooks, privateKey := newTestManager(t)
+
+	p, err := m.Install(t.Context(), "test", testModule, ed25519.Sign(privateKey, testModule))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"OnRecordAfterCreateRequest"}, p.Hooks)
+	assert.Equal(t, []string{"plugin:test"}, hooks.OnRecordAfterCreateRequest.Registered())
+
+	_, err = p.call(t.Context(), "OnRecordAfterCreateRequest", []byte(`{"table":"tickets"}`))
+	require.NoError(t, err)
+
+	// the plugin is loaded again on startup
+	reloaded, err := New(t.Context(), m.dir, hook.NewHooks())
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = reloaded.Close(context.Background()) })
+
+	require.Len(t, reloaded.List(), 1)
+
+	require.NoError(t, m.Remove(t.Context(), "test"))
+	assert.Empty(t, m.List())
+	assert.Empty(t, hooks.OnRecordAfterCreateRequest.Registered())
+	assert.NoFileExists(t, filepath.Join(m.dir, "test.wasm"))
+}
+
+func TestManager_Install_Invalid(t *testing.T) {
+	t.Parallel()
+
+	m, _, privateKey := newTestManager(t)
+
+	_, otherKey, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+
+	_, err = m.Install(t.Context(), "test", testModule, ed25519.Sign(otherKey, testModule))
+	require.ErrorContains(t, err, "invalid plugin signature")
+
+	_, err = m.Install(t.Context(), "../test", testModule, ed25519.Sign(privateKey, testModule))
+	require.ErrorContains(t, err, "invalid plugin name")
+
+	assert.Empty(t, m.List())
+}
+
+func TestManager_Install_Replace(t *testing.T) {
+	t.Parallel()
+
+	m, hooks, privateKey := newTestManager(t)
+
+	installed, err := m.Install(t.Context(), "test", testModule, ed25519.Sign(privateKey, testModule))
+	require.NoError(t, err)
+
+	// a plugin that fails to load does not replace the installed plugin
+	invalid := []byte("not wasm")
+
+	_, err = m.Install(t.Context(), "test", invalid, ed25519.Sign(privateKey, invalid))
+	require.ErrorContains(t, err, "failed to compile plugin")
+
+	p, ok := m.Get("test")
+	require.True(t, ok)
+	assert.Same(t, installed, p)
+	assert.Equal(t, []string{"plugin:test"}, hooks.OnRecordAfterCreateRequest.Registered())
+
+	_, err = p.call(t.Context(), "OnRecordAfterCreateRequest", []byte(`{"table":"tickets"}`))
+	require.NoError(t, err)
+
+	stored, err := os.ReadFile(filepath.Join(m.dir, "test.wasm"))
+	require.NoError(t, err)
+	assert.Equal(t, testModule, stored)
+
+	// a valid plugin replaces the installed plugin
+	replaced, err := m.Install(t.Context(), "test", testModule, ed25519.Sign(privateKey, testModule))
+	require.NoError(t, err)
+	assert.NotSame(t, installed, replaced)
+	assert.Equal(t, []string{"plugin:test"}, hooks.OnRecordAfterCreateRequest.Registered())
+
+	_, err = replaced.call(t.Context(), "OnRecordAfterCreateRequest", []byte(`{"table":"tickets"}`))
+	require.NoError(t, err)
+
+	entries, err := os.ReadDir(m.dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 3)
+}
@@ -0,0 +1,134 @@
+package push
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	recordSize = 4096
+	saltSize   = 16
+	// a single record holds the payload, the delimiter and the tag
+	maxPayloadSize = recordSize - saltSize - 4 - 1 - 65 - 1 - 16
+)
+
+// encrypt encrypts a push message for a subscription with the aes128gcm
+// content encoding defined in RFC 8291.
+func encrypt(p256dh, authSecret string, payload []byte) ([]byte, error) {
+	if len(payload) > maxPayloadSize {
+		return nil, fmt.Errorf("payload too large: %d bytes", len(payload))
+	}
+
+	uaPublicBytes, err := decodeKey(p256dh)
+	if err != nil {
+		return nil, fmt.Errorf("invalid p256dh key: %w", err)
+	}
+
+	auth, err := decodeKey(authSecret)
+	if err != nil {
+		return nil, fmt.Errorf("invalid auth secret: %w", err)
+	}
+
+	if len(auth) != 16 {
+		return nil, errors.New("invalid auth secret length")
+	}
+
+	uaPublic, err := ecdh.P256().NewPublicKey(uaPublicBytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid p256dh key: %w", err)
+	}
+
+	asPrivate, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	asPublic := asPrivate.PublicKey().Bytes()
+
+	salt := make([]byte, saltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+
+	ecdhSecret, err := asPrivate.ECDH(uaPublic)
+	if err != nil {
+		return nil, err
+	}
+
+	keyInfo := "WebPush: info\x00" + string(uaPublicBytes) + string(asPublic)
+
+	ikm, err := hkdf.Key(sha256.New, ecdhSecret, auth, keyInfo, 32)
+	if err != nil {
+		return nil, err
+	}
+
+	cek, err := hkdf.Key(sha256.New, ikm, salt, "Content-Encoding: aes128gcm\x00", 16)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce, err := hkdf.Key(sha256.New, ikm, salt, "Content-Encoding: nonce\x00", 12)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(cek)
+	if err != nil {
+		return nil, err
+	}
+
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	header := make([]byte, 0, saltSize+4+1+len(asPublic))
+	header = append(header, salt...)
+	header = binary.BigEndian.AppendUint32(header, recordSize)
+	header = append(header, byte(len(asPublic)))
+	header = append(header, asPublic...)
+
+	// 0x02 marks the last record
+	plaintext := append(append([]byte{}, payload...), 0x02)
+
+	return gcm.Seal(header, nonce, plaintext, nil), nil
+}
+
+// decodeKey decodes the base64url keys of a PushSubscription, with or
+// without padding.
+func decodeKey(s string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+}
+
+// ValidateSubscription checks the endpoint and keys a browser returned from
+// PushManager.subscribe.
+func ValidateSubscription(endpoint, p256dh, authSecret string) error {
+	if !strings.HasPrefix(endpoint, "https://") {
+		return errors.New("push endpoint must use https")
+	}
+
+	uaPublic, err := decodeKey(p256dh)
+	if err != nil {
+		return fmt.Errorf("invalid p256dh key: %w", err)
+	}
+
+	if _, err := ecdh.P256().NewPublicKey(uaPublic); err != nil {
+		return fmt.Errorf("invalid p256dh key: %w", err)
+	}
+
+	auth, err := decodeKey(authSecret)
+	if err != nil || len(auth) != 16 {
+		return errors.New("invalid auth secret")
+	}
+
+	return nil
+}
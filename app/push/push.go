@@ -0,0 +1,203 @@
+// Package push sends Web Push notifications to the browsers of users, so
+// they learn about high severity assignments even when Catalyst is not open.
+package push
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/SecurityBrewery/catalyst/app/auth/usercontext"
+	"github.com/SecurityBrewery/catalyst/app/database"
+	"github.com/SecurityBrewery/catalyst/app/database/sqlc"
+	"github.com/SecurityBrewery/catalyst/app/hook"
+	"github.com/SecurityBrewery/catalyst/app/openapi"
+	"github.com/SecurityBrewery/catalyst/app/settings"
+)
+
+const messageTTL = 24 * time.Hour
+
+// Message is the JSON payload the service worker receives.
+type Message struct {
+	Title string `json:"title"`
+	Body  string `json:"body"`
+	URL   string `json:"url"`
+}
+
+type Notifier struct {
+	queries *sqlc.Queries
+	client  *http.Client
+	now     func() time.Time
+
+	mu sync.Mutex
+	// owners remembers the notified owner per open ticket, so updates of
+	// other fields don't notify again
+	owners map[string]string
+}
+
+func New(queries *sqlc.Queries) *Notifier {
+	return &Notifier{
+		queries: queries,
+		client:  &http.Client{Timeout: 10 * time.Second},
+		now:     time.Now,
+		owners:  map[string]string{},
+	}
+}
+
+// BindHooks notifies the owner of a high severity ticket when it is
+// assigned to them.
+func BindHooks(hooks *hook.Hooks, queries *sqlc.Queries) *Notifier {
+	n := New(queries)
+
+	assigned := func(ctx context.Context, table string, record any) {
+		if table != database.TicketsTable.ID {
+			return
+		}
+
+		ticket, ok := record.(openapi.Ticket)
+		if !ok || !n.newAssignment(ticket) {
+			return
+		}
+
+		// users don't need a notification for assigning themselves
+		if user, ok := usercontext.UserFromContext(ctx); ok && user.ID == *ticket.Owner {
+			return
+		}
+
+		go n.notifyAssignment(context.WithoutCancel(ctx), ticket)
+	}
+
+	hooks.OnRecordAfterCreateRequest.Subscribe(assigned)
+	hooks.OnRecordAfterUpdateRequest.Subscribe(assigned)
+
+	return n
+}
+
+func (n *Notifier) newAssignment(ticket openapi.Ticket) bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if !ticket.Open || ticket.Owner == nil {
+		delete(n.owners, ticket.Id)
+
+		return false
+	}
+
+	if !isHighSeverity(ticket.State) || n.owners[ticket.Id] == *ticket.Owner {
+		return false
+	}
+
+	n.owners[ticket.Id] = *ticket.Owner
+
+	return true
+}
+
+func isHighSeverity(state map[string]any) bool {
+	severity, _ := state["severity"].(string)
+
+	return strings.EqualFold(severity, "high") || strings.EqualFold(severity, "critical")
+}
+
+func (n *Notifier) notifyAssignment(ctx context.Context, ticket openapi.Ticket) {
+	se, err := settings.Load(ctx, n.queries)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to load settings", "error", err)
+
+		return
+	}
+
+	if err := n.Send(ctx, *ticket.Owner, Message{
+		Title: "High severity ticket assigned",
+		Body:  ticket.Name,
+		URL:   strings.TrimSuffix(se.Meta.AppURL, "/") + "/ui/tickets/" + ticket.Type + "/" + ticket.Id,
+	}); err != nil {
+		slog.ErrorContext(ctx, "Failed to send push notification", "ticket", ticket.Id, "error", err)
+	}
+}
+
+// Send pushes a message to all browsers the user subscribed. Subscriptions
+// that the push service reports as expired are removed.
+func (n *Notifier) Send(ctx context.Context, user string, message Message) error {
+	subscriptions, err := n.queries.ListPushSubscriptions(ctx, user)
+	if err != nil {
+		return err
+	}
+
+	if len(subscriptions) == 0 {
+		return nil
+	}
+
+	key, err := vapidKey(ctx, n.queries)
+	if err != nil {
+		return err
+	}
+
+	se, err := settings.Load(ctx, n.queries)
+	if err != nil {
+		return err
+	}
+
+	subject := se.Meta.AppURL
+	if se.Meta.SenderAddress != "" {
+		subject = "mailto:" + se.Meta.SenderAddress
+	}
+
+	payload, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+
+	for _, subscription := range subscriptions {
+		if err := n.send(ctx, key, subject, subscription, payload); err != nil {
+			slog.ErrorContext(ctx, "Failed to push to subscription", "subscription", subscription.ID, "error", err)
+		}
+	}
+
+	return nil
+}
+
+func (n *Notifier) send(ctx context.Context, key *ecdsa.PrivateKey, subject string, subscription sqlc.PushSubscription, payload []byte) error {
+	body, err := encrypt(subscription.P256dh, subscription.Auth, payload)
+	if err != nil {
+		return err
+	}
+
+	authorization, err := vapidAuthorization(key, subscription.Endpoint, subject, n.now())
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, subscription.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Authorization", authorization)
+	req.Header.Set("Content-Encoding", "aes128gcm")
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("TTL", fmt.Sprint(int(messageTTL.Seconds())))
+	req.Header.Set("Urgency", "high")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+		return n.queries.DeletePushSubscriptionByEndpoint(ctx, subscription.Endpoint)
+	case resp.StatusCode >= http.StatusBadRequest:
+		return fmt.Errorf("push service returned %s", resp.Status)
+	}
+
+	return nil
+}
@@ -0,0 +1,243 @@
+package push
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/SecurityBrewery/catalyst/app/data"
+	"github.com/SecurityBrewery/catalyst/app/database"
+	"github.com/SecurityBrewery/catalyst/app/database/sqlc"
+	"github.com/SecurityBrewery/catalyst/app/hook"
+	"github.com/SecurityBrewery/catalyst/app/openapi"
+	"github.com/SecurityBrewery/catalyst/app/pointer"
+)
+
+type testBrowser struct {
+	key  *ecdh.PrivateKey
+	auth []byte
+}
+
+func newTestBrowser(t *testing.T) *testBrowser {
+	t.Helper()
+
+	key, err := ecdh.P256().GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	auth := make([]byte, 16)
+	_, err = rand.Read(auth)
+	require.NoError(t, err)
+
+	return &testBrowser{key: key, auth: auth}
+}
+
+func (b *testBrowser) p256dh() string {
+	return base64.RawURLEncoding.EncodeToString(b.key.PublicKey().Bytes())
+}
+
+func (b *testBrowser) authSecret() string {
+	return base64.RawURLEncoding.EncodeToString(b.auth)
+}
+
+// decrypt is the receiving side of RFC 8291.
+func (b *testBrowser) decrypt(t *testing.T, body []byte) []byte {
+	t.Helper()
+
+	salt := body[:16]
+	assert.Equal(t, uint32(recordSize), binary.BigEndian.Uint32(body[16:20]))
+
+	idLen := int(body[20])
+	asPublicBytes := body[21 : 21+idLen]
+
+	asPublic, err := ecdh.P256().NewPublicKey(asPublicBytes)
+	require.NoError(t, err)
+
+	ecdhSecret, err := b.key.ECDH(asPublic)
+	require.NoError(t, err)
+
+	keyInfo := "WebPush: info\x00" + string(b.key.PublicKey().Bytes()) + string(asPublicBytes)
+
+	ikm, err := hkdf.Key(sha256.New, ecdhSecret, b.auth, keyInfo, 32)
+	require.NoError(t, err)
+
+	cek, err := hkdf.Key(sha256.New, ikm, salt, "Content-Encoding: aes128gcm\x00", 16)
+	require.NoError(t, err)
+
+	nonce, err := hkdf.Key(sha256.New, ikm, salt, "Content-Encoding: nonce\x00", 12)
+	require.NoError(t, err)
+
+	block, err := aes.NewCipher(cek)
+	require.NoError(t, err)
+
+	gcm, err := cipher.NewGCM(block)
+	require.NoError(t, err)
+
+	plaintext, err := gcm.Open(nil, nonce, body[21+idLen:], nil)
+	require.NoError(t, err)
+
+	require.Equal(t, byte(0x02), plaintext[len(plaintext)-1])
+
+	return plaintext[:len(plaintext)-1]
+}
+
+func Test_encrypt(t *testing.T) {
+	t.Parallel()
+
+	browser := newTestBrowser(t)
+
+	body, err := encrypt(browser.p256dh(), browser.authSecret()+"==", []byte(`{"title":"hello"}`))
+	require.NoError(t, err)
+
+	assert.JSONEq(t, `{"title":"hello"}`, string(browser.decrypt(t, body)))
+
+	_, err = encrypt(browser.p256dh(), browser.authSecret(), make([]byte, recordSize))
+	require.Error(t, err)
+
+	_, err = encrypt("invalid", browser.authSecret(), nil)
+	require.Error(t, err)
+}
+
+func TestValidateSubscription(t *testing.T) {
+	t.Parallel()
+
+	browser := newTestBrowser(t)
+
+	require.NoError(t, ValidateSubscription("https://push.example.com/abc", browser.p256dh(), browser.authSecret()))
+	require.Error(t, ValidateSubscription("http://push.example.com/abc", browser.p256dh(), browser.authSecret()))
+	require.Error(t, ValidateSubscription("https://push.example.com/abc", "AAAA", browser.authSecret()))
+	require.Error(t, ValidateSubscription("https://push.example.com/abc", browser.p256dh(), "AAAA"))
+}
+
+func TestPublicKey(t *testing.T) {
+	t.Parallel()
+
+	queries := data.NewTestDB(t, t.TempDir())
+
+	publicKey, err := PublicKey(t.Context(), queries)
+	require.NoError(t, err)
+
+	raw, err := base64.RawURLEncoding.DecodeString(publicKey)
+	require.NoError(t, err)
+	assert.Len(t, raw, 65)
+
+	// the key pair is generated once
+	again, err := PublicKey(t.Context(), queries)
+	require.NoError(t, err)
+	assert.Equal(t, publicKey, again)
+
+	key, err := vapidKey(t.Context(), queries)
+	require.NoError(t, err)
+
+	authorization, err := vapidAuthorization(key, "https://push.example.com/send/abc", "mailto:soc@example.com", time.Now())
+	require.NoError(t, err)
+
+	token, k, ok := strings.Cut(strings.TrimPrefix(authorization, "vapid t="), ", k=")
+	require.True(t, ok)
+	assert.Equal(t, publicKey, k)
+
+	claims := jwt.MapClaims{}
+	_, err = jwt.ParseWithClaims(token, claims, func(*jwt.Token) (any, error) { return &key.PublicKey, nil },
+		jwt.WithValidMethods([]string{"ES256"}))
+	require.NoError(t, err)
+	assert.Equal(t, "https://push.example.com", claims["aud"])
+	assert.Equal(t, "mailto:soc@example.com", claims["sub"])
+}
+
+func TestBindHooks(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	queries := data.NewTestDB(t, t.TempDir())
+	hooks := hook.NewHooks()
+	browser := newTestBrowser(t)
+
+	received := make(chan Message, 10)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "aes128gcm", r.Header.Get("Content-Encoding"))
+		assert.True(t, strings.HasPrefix(r.Header.Get("Authorization"), "vapid t="))
+
+		body, _ := io.ReadAll(r.Body)
+
+		var message Message
+		assert.NoError(t, json.Unmarshal(browser.decrypt(t, body), &message))
+
+		received <- message
+
+		if r.URL.Path == "/expired" {
+			w.WriteHeader(http.StatusGone)
+
+			return
+		}
+
+		w.WriteHeader(http.StatusCreated)
+	}))
+	t.Cleanup(server.Close)
+
+	_, err := queries.CreatePushSubscription(ctx, sqlc.CreatePushSubscriptionParams{
+		User: "u_bob_analyst", Endpoint: server.URL + "/expired", P256dh: browser.p256dh(), Auth: browser.authSecret(),
+	})
+	require.NoError(t, err)
+
+	BindHooks(hooks, queries)
+
+	ticket := openapi.Ticket{
+		Id:    "test-ticket",
+		Name:  "Ransomware on ws042",
+		Type:  "incident",
+		Open:  true,
+		Owner: pointer.Pointer("u_bob_analyst"),
+		State: map[string]any{"severity": "Low"},
+	}
+
+	// low severity tickets are not pushed
+	hooks.OnRecordAfterUpdateRequest.Publish(ctx, database.TicketsTable.ID, ticket)
+
+	ticket.State = map[string]any{"severity": "High"}
+	hooks.OnRecordAfterUpdateRequest.Publish(ctx, database.TicketsTable.ID, ticket)
+
+	select {
+	case message := <-received:
+		assert.Equal(t, "Ransomware on ws042", message.Body)
+		assert.True(t, strings.HasSuffix(message.URL, "/ui/tickets/incident/test-ticket"))
+	case <-time.After(5 * time.Second):
+		t.Fatal("no push notification received")
+	}
+
+	// the push service reported the subscription as expired
+	require.Eventually(t, func() bool {
+		subscriptions, err := queries.ListPushSubscriptions(ctx, "u_bob_analyst")
+
+		return err == nil && len(subscriptions) == 0
+	}, 5*time.Second, 10*time.Millisecond)
+
+	_, err = queries.CreatePushSubscription(ctx, sqlc.CreatePushSubscriptionParams{
+		User: "u_bob_analyst", Endpoint: server.URL + "/active", P256dh: browser.p256dh(), Auth: browser.authSecret(),
+	})
+	require.NoError(t, err)
+
+	// the owner did not change, so there is no new notification
+	hooks.OnRecordAfterUpdateRequest.Publish(ctx, database.TicketsTable.ID, ticket)
+
+	select {
+	case <-received:
+		t.Fatal("unexpected push notification")
+	case <-time.After(100 * time.Millisecond):
+	}
+}
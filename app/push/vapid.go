@@ -0,0 +1,122 @@
+package push
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/SecurityBrewery/catalyst/app/database/sqlc"
+	"github.com/SecurityBrewery/catalyst/app/settings"
+)
+
+const vapidExpiration = 12 * time.Hour
+
+// PublicKey returns the VAPID public key that browsers need to subscribe,
+// as URL-safe base64 of the uncompressed P-256 point. The key pair is
+// generated and stored in the settings on first use.
+func PublicKey(ctx context.Context, queries *sqlc.Queries) (string, error) {
+	key, err := vapidKey(ctx, queries)
+	if err != nil {
+		return "", err
+	}
+
+	return encodePublicKey(key)
+}
+
+func vapidKey(ctx context.Context, queries *sqlc.Queries) (*ecdsa.PrivateKey, error) {
+	se, err := settings.Load(ctx, queries)
+	if err != nil {
+		return nil, err
+	}
+
+	if se.WebPush.VAPIDPrivateKey == "" {
+		privateKey, publicKey, err := generateVAPIDKey()
+		if err != nil {
+			return nil, err
+		}
+
+		se, err = settings.Update(ctx, queries, func(se *settings.Settings) {
+			// keep a key that was generated concurrently
+			if se.WebPush.VAPIDPrivateKey == "" {
+				se.WebPush.VAPIDPrivateKey = privateKey
+				se.WebPush.VAPIDPublicKey = publicKey
+			}
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to save VAPID key: %w", err)
+		}
+	}
+
+	der, err := base64.RawURLEncoding.DecodeString(se.WebPush.VAPIDPrivateKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid VAPID key: %w", err)
+	}
+
+	key, err := x509.ParseECPrivateKey(der)
+	if err != nil {
+		return nil, fmt.Errorf("invalid VAPID key: %w", err)
+	}
+
+	return key, nil
+}
+
+func generateVAPIDKey() (privateKey, publicKey string, err error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return "", "", err
+	}
+
+	der, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return "", "", err
+	}
+
+	publicKey, err = encodePublicKey(key)
+	if err != nil {
+		return "", "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(der), publicKey, nil
+}
+
+func encodePublicKey(key *ecdsa.PrivateKey) (string, error) {
+	publicKey, err := key.PublicKey.ECDH()
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(publicKey.Bytes()), nil
+}
+
+// vapidAuthorization returns the Authorization header for a push service
+// as defined in RFC 8292.
+func vapidAuthorization(key *ecdsa.PrivateKey, endpoint, subject string, now time.Time) (string, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", err
+	}
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{
+		"aud": u.Scheme + "://" + u.Host,
+		"exp": now.Add(vapidExpiration).Unix(),
+		"sub": subject,
+	}).SignedString(key)
+	if err != nil {
+		return "", err
+	}
+
+	publicKey, err := encodePublicKey(key)
+	if err != nil {
+		return "", err
+	}
+
+	return "vapid t=" + token + ", k=" + publicKey, nil
+}
@@ -0,0 +1,32 @@
+// Package queue describes the ticket queues of the triage. A queue is a
+// named filter on the open tickets without an owner, analysts claim the
+// oldest ticket of a queue one after another instead of picking tickets
+// from a list, so two analysts never work on the same ticket.
+//
+// The filter of a queue lists types, severities and tags, compared without
+// regard to case. Empty lists match all tickets, the severity is read from
+// the ticket state field "severity" and the tags from the list in the
+// ticket state field "tags", a ticket matches the tags if it has any of
+// them. The claim query in the database package applies the filter.
+package queue
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var ErrInvalidQueue = errors.New("invalid queue")
+
+// Validate checks the name of a queue, which is part of the URL to claim
+// tickets.
+func Validate(name string) error {
+	switch {
+	case strings.TrimSpace(name) == "":
+		return fmt.Errorf("%w: missing name", ErrInvalidQueue)
+	case strings.ContainsAny(name, "/?#"):
+		return fmt.Errorf("%w: the name must not contain '/', '?' or '#'", ErrInvalidQueue)
+	}
+
+	return nil
+}
@@ -0,0 +1,16 @@
+package queue
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidate(t *testing.T) {
+	t.Parallel()
+
+	require.NoError(t, Validate("phishing"))
+	require.NoError(t, Validate("Tier 1"))
+	require.ErrorIs(t, Validate(" "), ErrInvalidQueue)
+	require.ErrorIs(t, Validate("tier/1"), ErrInvalidQueue)
+}
@@ -4,33 +4,90 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"log/slog"
 	"time"
 
+	"github.com/SecurityBrewery/catalyst/app/applog"
 	"github.com/SecurityBrewery/catalyst/app/auth"
 	"github.com/SecurityBrewery/catalyst/app/database/sqlc"
 	"github.com/SecurityBrewery/catalyst/app/reaction/action/python"
 	"github.com/SecurityBrewery/catalyst/app/reaction/action/webhook"
+	"github.com/SecurityBrewery/catalyst/app/redact"
+	"github.com/SecurityBrewery/catalyst/app/settings"
+	"github.com/SecurityBrewery/catalyst/app/telemetry"
 )
 
-func Run(ctx context.Context, url string, queries *sqlc.Queries, actionName string, actionData, payload json.RawMessage) ([]byte, error) {
+// runRetention is how long the resource usage of a run is kept.
+const runRetention = 30 * 24 * time.Hour
+
+func Run(ctx context.Context, config *settings.Settings, queries *sqlc.Queries, reactionID, actionName string, actionData, payload json.RawMessage) ([]byte, error) {
 	action, err := decode(actionName, actionData)
 	if err != nil {
 		return nil, err
 	}
 
+	var token string
+
 	if a, ok := action.(authenticatedAction); ok {
-		token, err := systemToken(ctx, queries)
+		token, err = systemToken(ctx, queries, reactionID)
 		if err != nil {
 			return nil, fmt.Errorf("failed to get system token: %w", err)
 		}
 
 		a.SetEnv([]string{
-			"CATALYST_APP_URL=" + url,
+			"CATALYST_APP_URL=" + config.Meta.AppURL,
 			"CATALYST_TOKEN=" + token,
 		})
 	}
 
-	return action.Run(ctx, payload)
+	var host string
+	if a, ok := action.(outboundAction); ok {
+		host = a.Host()
+	}
+
+	if err := outbound.wait(ctx, matchingLimits(config.RateLimits, reactionID, host)); err != nil {
+		return nil, fmt.Errorf("rate limited: %w", err)
+	}
+
+	telemetry.RecordAutomationRun(ctx, queries, actionName)
+
+	start := time.Now()
+
+	output, err := action.Run(ctx, payload)
+
+	recordRun(ctx, queries, reactionID, action, err == nil, time.Since(start))
+
+	// the output and the errors are returned to callers and written to logs,
+	// mask the secrets and the token of the run before
+	redactor, redactErr := redact.Load(ctx, queries, token)
+	if redactErr != nil {
+		return nil, fmt.Errorf("failed to load secrets: %w", redactErr)
+	}
+
+	return redactor.Bytes(output), redactor.Error(err)
+}
+
+// recordRun stores the resource usage of a run. The CPU time and the peak
+// memory are only known for actions that run processes.
+func recordRun(ctx context.Context, queries *sqlc.Queries, reactionID string, action action, success bool, duration time.Duration) {
+	run := sqlc.CreateReactionRunParams{
+		Reaction:   reactionID,
+		Success:    success,
+		DurationMs: duration.Milliseconds(),
+	}
+
+	if a, ok := action.(measuredAction); ok {
+		cpuTime, memoryPeak := a.Usage()
+		run.CpuMs, run.MemoryPeak = cpuTime.Milliseconds(), memoryPeak
+	}
+
+	if err := queries.CreateReactionRun(ctx, run); err != nil {
+		slog.ErrorContext(ctx, "Failed to record reaction run", "error", err, "reaction_id", reactionID, applog.Critical)
+	}
+
+	if err := queries.DeleteReactionRunsBefore(ctx, time.Now().UTC().Add(-runRetention)); err != nil {
+		slog.ErrorContext(ctx, "Failed to delete old reaction runs", "error", err)
+	}
 }
 
 type action interface {
@@ -41,6 +98,14 @@ type authenticatedAction interface {
 	SetEnv(env []string)
 }
 
+type outboundAction interface {
+	Host() string
+}
+
+type measuredAction interface {
+	Usage() (cpuTime time.Duration, memoryPeak int64)
+}
+
 func decode(actionName string, actionData json.RawMessage) (action, error) {
 	switch actionName {
 	case "python":
@@ -62,11 +127,13 @@ func decode(actionName string, actionData json.RawMessage) (action, error) {
 	}
 }
 
-func systemToken(ctx context.Context, queries *sqlc.Queries) (string, error) {
+// systemToken returns a token of the system user acting for the reaction.
+// Scripts exchange it for tokens with fewer scopes before passing them on.
+func systemToken(ctx context.Context, queries *sqlc.Queries, reactionID string) (string, error) {
 	user, err := queries.SystemUser(ctx)
 	if err != nil {
 		return "", fmt.Errorf("failed to find system auth record: %w", err)
 	}
 
-	return auth.CreateAccessToken(ctx, &user, auth.All(), time.Hour, queries)
+	return auth.CreateAutomationToken(ctx, &user, auth.All(), time.Hour, reactionID, "", queries)
 }
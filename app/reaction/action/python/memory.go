@@ -0,0 +1,25 @@
+//go:build !windows
+
+package python
+
+import (
+	"os"
+	"runtime"
+	"syscall"
+)
+
+// memoryPeak returns the maximum resident set size of an exited process in
+// bytes.
+func memoryPeak(state *os.ProcessState) int64 {
+	rusage, ok := state.SysUsage().(*syscall.Rusage)
+	if !ok {
+		return 0
+	}
+
+	// macOS reports bytes, the other systems kilobytes
+	if runtime.GOOS == "darwin" {
+		return int64(rusage.Maxrss)
+	}
+
+	return int64(rusage.Maxrss) * 1024
+}
@@ -0,0 +1,9 @@
+package python
+
+import "os"
+
+// memoryPeak is not available on Windows, the rusage has no resident set
+// size.
+func memoryPeak(*os.ProcessState) int64 {
+	return 0
+}
@@ -8,6 +8,7 @@ import (
 	"os"
 	"os/exec"
 	"strings"
+	"time"
 )
 
 type Python struct {
@@ -15,13 +16,24 @@ type Python struct {
 	Script       string `json:"script"`
 
 	env []string
+
+	cpuTime    time.Duration
+	memoryPeak int64
 }
 
 func (a *Python) SetEnv(env []string) {
 	a.env = env
 }
 
+// Usage returns the CPU time and the peak memory of the processes of the
+// last run.
+func (a *Python) Usage() (cpuTime time.Duration, memoryPeak int64) {
+	return a.cpuTime, a.memoryPeak
+}
+
 func (a *Python) Run(ctx context.Context, payload json.RawMessage) ([]byte, error) {
+	a.cpuTime, a.memoryPeak = 0, 0
+
 	tempDir, err := os.MkdirTemp("", "catalyst_action")
 	if err != nil {
 		return nil, err
@@ -29,7 +41,7 @@ func (a *Python) Run(ctx context.Context, payload json.RawMessage) ([]byte, erro
 
 	defer os.RemoveAll(tempDir)
 
-	b, err := pythonSetup(ctx, tempDir)
+	b, err := a.pythonSetup(ctx, tempDir)
 	if err != nil {
 		var ee *exec.ExitError
 		if errors.As(err, &ee) {
@@ -62,14 +74,14 @@ func (a *Python) Run(ctx context.Context, payload json.RawMessage) ([]byte, erro
 	return b, nil
 }
 
-func pythonSetup(ctx context.Context, tempDir string) ([]byte, error) {
+func (a *Python) pythonSetup(ctx context.Context, tempDir string) ([]byte, error) {
 	pythonPath, err := findExec("python3", "python")
 	if err != nil {
 		return nil, fmt.Errorf("python or python3 binary not found, %w", err)
 	}
 
 	// setup virtual environment
-	return exec.CommandContext(ctx, pythonPath, "-m", "venv", tempDir+"/venv").Output()
+	return a.output(exec.CommandContext(ctx, pythonPath, "-m", "venv", tempDir+"/venv"))
 }
 
 func (a *Python) pythonInstallRequirements(ctx context.Context, tempDir string) ([]byte, error) {
@@ -88,7 +100,7 @@ func (a *Python) pythonInstallRequirements(ctx context.Context, tempDir string)
 	// install dependencies
 	pipPath := tempDir + "/venv/bin/pip"
 
-	return exec.CommandContext(ctx, pipPath, "install", "-r", requirementsPath).Output()
+	return a.output(exec.CommandContext(ctx, pipPath, "install", "-r", requirementsPath))
 }
 
 func (a *Python) pythonRunScript(ctx context.Context, tempDir, payload string) ([]byte, error) {
@@ -104,7 +116,19 @@ func (a *Python) pythonRunScript(ctx context.Context, tempDir, payload string) (
 
 	cmd.Env = a.env
 
-	return cmd.Output()
+	return a.output(cmd)
+}
+
+// output runs the command and adds its resource usage to the run.
+func (a *Python) output(cmd *exec.Cmd) ([]byte, error) {
+	b, err := cmd.Output()
+
+	if cmd.ProcessState != nil {
+		a.cpuTime += cmd.ProcessState.UserTime() + cmd.ProcessState.SystemTime()
+		a.memoryPeak = max(a.memoryPeak, memoryPeak(cmd.ProcessState))
+	}
+
+	return b, err
 }
 
 func findExec(name ...string) (string, error) {
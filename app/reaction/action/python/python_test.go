@@ -2,9 +2,11 @@ package python_test
 
 import (
 	"encoding/json"
+	"runtime"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/SecurityBrewery/catalyst/app/reaction/action/python"
 )
@@ -102,3 +104,19 @@ func TestPython_Run(t *testing.T) {
 		})
 	}
 }
+
+func TestPython_Usage(t *testing.T) {
+	t.Parallel()
+
+	a := &python.Python{Script: "data = bytearray(64 * 1024 * 1024)\nsum(range(1000000))"}
+
+	_, err := a.Run(t.Context(), json.RawMessage("{}"))
+	require.NoError(t, err)
+
+	cpuTime, memoryPeak := a.Usage()
+	assert.Positive(t, cpuTime)
+
+	if runtime.GOOS != "windows" {
+		assert.Greater(t, memoryPeak, int64(64*1024*1024))
+	}
+}
@@ -0,0 +1,105 @@
+package action
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/SecurityBrewery/catalyst/app/settings"
+)
+
+// limiter delays the runs that exceed an outbound rate limit until the next
+// window starts, instead of failing them, so no alert is lost.
+type limiter struct {
+	now func() time.Time
+
+	mux     sync.Mutex
+	windows map[string]*limitWindow
+}
+
+type limitWindow struct {
+	start time.Time
+	count int
+}
+
+var outbound = newLimiter()
+
+func newLimiter() *limiter {
+	return &limiter{
+		now:     time.Now,
+		windows: map[string]*limitWindow{},
+	}
+}
+
+// wait blocks until each of the rate limits allows another request.
+func (l *limiter) wait(ctx context.Context, limits []settings.RateLimit) error {
+	for _, limit := range limits {
+		key := "reaction:" + limit.Reaction
+		if limit.Host != "" {
+			key = "host:" + limit.Host
+		}
+
+		for {
+			delay := l.reserve(key, limit.Requests, time.Duration(limit.Period)*time.Second)
+			if delay == 0 {
+				break
+			}
+
+			slog.InfoContext(ctx, "Reaction is rate limited", "limit", key, "delay", delay)
+
+			timer := time.NewTimer(delay)
+
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+
+				return ctx.Err()
+			case <-timer.C:
+			}
+		}
+	}
+
+	return nil
+}
+
+// reserve counts a request if the limit allows it, otherwise it returns the
+// time until the current window ends.
+func (l *limiter) reserve(key string, requests int, period time.Duration) time.Duration {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+
+	now := l.now()
+
+	w, ok := l.windows[key]
+	if !ok || now.Sub(w.start) >= period {
+		w = &limitWindow{start: now}
+		l.windows[key] = w
+	}
+
+	if w.count >= requests {
+		return w.start.Add(period).Sub(now)
+	}
+
+	w.count++
+
+	return 0
+}
+
+// matchingLimits returns the rate limits of a reaction run.
+func matchingLimits(limits []settings.RateLimit, reactionID, host string) []settings.RateLimit {
+	var matching []settings.RateLimit
+
+	for _, limit := range limits {
+		if limit.Requests <= 0 || limit.Period <= 0 {
+			continue
+		}
+
+		if (limit.Reaction != "" && limit.Reaction == reactionID) || (limit.Host != "" && strings.EqualFold(limit.Host, host)) {
+			matching = append(matching, limit)
+		}
+	}
+
+	return matching
+}
@@ -0,0 +1,61 @@
+package action
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/SecurityBrewery/catalyst/app/settings"
+)
+
+func TestLimiter_reserve(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	l := newLimiter()
+	l.now = func() time.Time { return now }
+
+	assert.Zero(t, l.reserve("host:example.com", 2, time.Minute))
+	assert.Zero(t, l.reserve("host:example.com", 2, time.Minute))
+	assert.Equal(t, time.Minute, l.reserve("host:example.com", 2, time.Minute))
+
+	// other keys have their own window
+	assert.Zero(t, l.reserve("reaction:r-test", 2, time.Minute))
+
+	now = now.Add(40 * time.Second)
+	assert.Equal(t, 20*time.Second, l.reserve("host:example.com", 2, time.Minute))
+
+	now = now.Add(20 * time.Second)
+	assert.Zero(t, l.reserve("host:example.com", 2, time.Minute))
+}
+
+func TestLimiter_wait(t *testing.T) {
+	t.Parallel()
+
+	l := newLimiter()
+	limits := []settings.RateLimit{{Host: "example.com", Requests: 1, Period: 3600}}
+
+	require.NoError(t, l.wait(t.Context(), limits))
+
+	ctx, cancel := context.WithTimeout(t.Context(), 10*time.Millisecond)
+	defer cancel()
+
+	require.ErrorIs(t, l.wait(ctx, limits), context.DeadlineExceeded)
+}
+
+func TestMatchingLimits(t *testing.T) {
+	t.Parallel()
+
+	limits := []settings.RateLimit{
+		{Host: "www.VirusTotal.com", Requests: 4, Period: 60},
+		{Reaction: "r-enrich", Requests: 10, Period: 60},
+		{Host: "api.shodan.io", Requests: 0, Period: 60},
+	}
+
+	assert.Equal(t, limits[:1], matchingLimits(limits, "r-other", "www.virustotal.com"))
+	assert.Equal(t, limits[:2], matchingLimits(limits, "r-enrich", "www.virustotal.com"))
+	assert.Empty(t, matchingLimits(limits, "r-other", "api.shodan.io"))
+}
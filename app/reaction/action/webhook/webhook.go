@@ -5,6 +5,7 @@ import (
 	"context"
 	"encoding/json"
 	"net/http"
+	"net/url"
 )
 
 type Webhook struct {
@@ -12,6 +13,16 @@ type Webhook struct {
 	URL     string            `json:"url"`
 }
 
+// Host returns the host the webhook is sent to.
+func (a *Webhook) Host() string {
+	u, err := url.Parse(a.URL)
+	if err != nil {
+		return ""
+	}
+
+	return u.Hostname()
+}
+
 func (a *Webhook) Run(ctx context.Context, payload json.RawMessage) ([]byte, error) {
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.URL, bytes.NewReader(payload))
 	if err != nil {
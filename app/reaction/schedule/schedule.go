@@ -9,6 +9,7 @@ import (
 
 	"github.com/go-co-op/gocron/v2"
 
+	"github.com/SecurityBrewery/catalyst/app/applog"
 	"github.com/SecurityBrewery/catalyst/app/database"
 	"github.com/SecurityBrewery/catalyst/app/database/sqlc"
 	"github.com/SecurityBrewery/catalyst/app/reaction/action"
@@ -56,12 +57,12 @@ func (s *Scheduler) AddReaction(reaction *sqlc.Reaction) error {
 			func(ctx context.Context) {
 				settings, err := settings.Load(ctx, s.queries)
 				if err != nil {
-					slog.ErrorContext(ctx, "Failed to load settings", "error", err)
+					slog.ErrorContext(ctx, "Failed to load settings", "error", err, applog.Critical)
 
 					return
 				}
 
-				_, err = action.Run(ctx, settings.Meta.AppURL, s.queries, reaction.Action, reaction.Actiondata, json.RawMessage("{}"))
+				_, err = action.Run(ctx, settings, s.queries, reaction.ID, reaction.Action, reaction.Actiondata, json.RawMessage("{}"))
 				if err != nil {
 					slog.ErrorContext(ctx, "Failed to run schedule reaction", "error", err, "reaction_id", reaction.ID)
 				}
@@ -0,0 +1,68 @@
+package hook
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+// Change holds the old and new value of a changed field.
+type Change struct {
+	Old any `json:"old"`
+	New any `json:"new"`
+}
+
+// changes returns the fields that differ between the previous and the
+// updated record. Fields are dot separated paths into the JSON form of the
+// records, e.g. state.severity. Missing fields have the value nil.
+func changes(previous, record any, fields []string) (map[string]Change, error) {
+	old, err := toMap(previous)
+	if err != nil {
+		return nil, err
+	}
+
+	updated, err := toMap(record)
+	if err != nil {
+		return nil, err
+	}
+
+	result := map[string]Change{}
+
+	for _, field := range fields {
+		oldValue, newValue := lookup(old, field), lookup(updated, field)
+		if !reflect.DeepEqual(oldValue, newValue) {
+			result[field] = Change{Old: oldValue, New: newValue}
+		}
+	}
+
+	return result, nil
+}
+
+func toMap(record any) (map[string]any, error) {
+	b, err := json.Marshal(record)
+	if err != nil {
+		return nil, err
+	}
+
+	var m map[string]any
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+func lookup(record map[string]any, field string) any {
+	var value any = record
+
+	for _, key := range strings.Split(field, ".") {
+		m, ok := value.(map[string]any)
+		if !ok {
+			return nil
+		}
+
+		value = m[key]
+	}
+
+	return value
+}
@@ -0,0 +1,26 @@
+package hook
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChanges(t *testing.T) {
+	t.Parallel()
+
+	previous := map[string]any{"open": true, "name": "Phishing", "state": map[string]any{"severity": "low"}}
+	record := map[string]any{"open": true, "name": "Phishing Mail", "state": map[string]any{"severity": "high"}}
+
+	changed, err := changes(previous, record, []string{"open", "state.severity", "state.missing"})
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]Change{
+		"state.severity": {Old: "low", New: "high"},
+	}, changed)
+
+	changed, err = changes(previous, record, []string{"open"})
+	require.NoError(t, err)
+	assert.Empty(t, changed)
+}
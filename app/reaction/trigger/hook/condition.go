@@ -0,0 +1,77 @@
+package hook
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/SecurityBrewery/catalyst/app/database"
+	"github.com/SecurityBrewery/catalyst/app/database/sqlc"
+	"github.com/SecurityBrewery/catalyst/app/expression"
+)
+
+// variables returns the expression variables of a record: the record itself
+// and, for tickets and records of a ticket, the ticket and its task outputs.
+func variables(ctx context.Context, queries *sqlc.Queries, collection string, record any) (*expression.Variables, error) {
+	variables := &expression.Variables{}
+
+	// delete events only pass the ID
+	if id, ok := record.(string); ok {
+		variables.Record = map[string]any{"id": id}
+	} else {
+		m, err := toMap(record)
+		if err != nil {
+			return nil, err
+		}
+
+		variables.Record = m
+	}
+
+	ticketID, _ := variables.Record["ticket"].(string)
+	if collection == database.TicketsTable.ID {
+		ticketID, _ = variables.Record["id"].(string)
+	}
+
+	if ticketID == "" {
+		return variables, nil
+	}
+
+	loaded, err := expression.Load(ctx, queries, ticketID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return variables, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	variables.Ticket, variables.Tasks = loaded.Ticket, loaded.Tasks
+
+	return variables, nil
+}
+
+// evaluate checks the condition of a hook and renders its inputs.
+func evaluate(hook *Hook, variables *expression.Variables) (map[string]string, bool, error) {
+	if hook.Condition != "" {
+		ok, err := expression.Condition(hook.Condition, variables)
+		if err != nil || !ok {
+			return nil, false, err
+		}
+	}
+
+	if len(hook.Inputs) == 0 {
+		return nil, true, nil
+	}
+
+	inputs := make(map[string]string, len(hook.Inputs))
+
+	for name, template := range hook.Inputs {
+		rendered, err := expression.Render(template, variables)
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to render input %s: %w", name, err)
+		}
+
+		inputs[name] = rendered
+	}
+
+	return inputs, true, nil
+}
@@ -0,0 +1,48 @@
+package hook
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/SecurityBrewery/catalyst/app/data"
+	"github.com/SecurityBrewery/catalyst/app/database/sqlc"
+)
+
+func TestEvaluate(t *testing.T) {
+	t.Parallel()
+
+	queries := data.NewTestDB(t, t.TempDir())
+
+	_, err := queries.SetTaskOutput(t.Context(), sqlc.SetTaskOutputParams{
+		Task:   "k_test_task",
+		Name:   "lookup",
+		Output: []byte(`{"score":85,"ip":"198.51.100.7"}`),
+	})
+	require.NoError(t, err)
+
+	// a comment of the test ticket
+	vars, err := variables(t.Context(), queries, "comments", map[string]any{"id": "c_new", "ticket": "test-ticket", "message": "done"})
+	require.NoError(t, err)
+
+	inputs, ok, err := evaluate(&Hook{
+		Condition: `record.message == "done" && tasks.lookup.output.score > 80`,
+		Inputs:    map[string]string{"ip": "{{ tasks.lookup.output.ip }}"},
+	}, vars)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, map[string]string{"ip": "198.51.100.7"}, inputs)
+
+	_, ok, err = evaluate(&Hook{Condition: `tasks.lookup.output.score > 90`}, vars)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	// the record of delete events is the ID
+	vars, err = variables(t.Context(), queries, "tickets", "test-ticket")
+	require.NoError(t, err)
+
+	_, ok, err = evaluate(&Hook{Condition: `has(tasks.lookup) && ticket.id == record.id`}, vars)
+	require.NoError(t, err)
+	assert.True(t, ok)
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/SecurityBrewery/catalyst/app/auth/usercontext"
 	"github.com/SecurityBrewery/catalyst/app/database"
 	"github.com/SecurityBrewery/catalyst/app/database/sqlc"
+	"github.com/SecurityBrewery/catalyst/app/expression"
 	"github.com/SecurityBrewery/catalyst/app/hook"
 	"github.com/SecurityBrewery/catalyst/app/reaction/action"
 	"github.com/SecurityBrewery/catalyst/app/settings"
@@ -20,6 +21,30 @@ import (
 type Hook struct {
 	Collections []string `json:"collections"`
 	Events      []string `json:"events"`
+	// Fields limits update events to changes of these fields, e.g. open or
+	// state.severity. The previous record is only known for tickets, so
+	// reactions with fields are not triggered by updates of other records.
+	Fields []string `json:"fields,omitempty"`
+	// Condition is an expression that must be true to trigger the reaction,
+	// see the expression package.
+	Condition string `json:"condition,omitempty"`
+	// Inputs are templates with {{ expressions }} that are rendered into
+	// the payload.
+	Inputs map[string]string `json:"inputs,omitempty"`
+}
+
+// Payload is the webhook payload with the changed fields of an update.
+type Payload struct {
+	webhook.Payload
+
+	Changes map[string]Change `json:"changes,omitempty"`
+	Inputs  map[string]string `json:"inputs,omitempty"`
+}
+
+type match struct {
+	reaction *sqlc.ListReactionsByTriggerRow
+	hook     Hook
+	changes  map[string]Change
 }
 
 func BindHooks(hooks *hook.Hooks, queries *sqlc.Queries, test bool) {
@@ -42,37 +67,28 @@ func bindHook(ctx context.Context, queries *sqlc.Queries, event, collection stri
 		return
 	}
 
+	previous, _ := hook.Previous(ctx)
+
 	if !test {
-		go mustRunHook(context.Background(), queries, collection, event, record, user) //nolint:contextcheck
+		go mustRunHook(context.Background(), queries, collection, event, previous, record, user) //nolint:contextcheck
 	} else {
-		mustRunHook(ctx, queries, collection, event, record, user)
+		mustRunHook(ctx, queries, collection, event, previous, record, user)
 	}
 }
 
-func mustRunHook(ctx context.Context, queries *sqlc.Queries, collection, event string, record any, auth *sqlc.User) {
-	if err := runHook(ctx, queries, collection, event, record, auth); err != nil {
+func mustRunHook(ctx context.Context, queries *sqlc.Queries, collection, event string, previous, record any, auth *sqlc.User) {
+	if err := runHook(ctx, queries, collection, event, previous, record, auth); err != nil {
 		slog.ErrorContext(ctx, fmt.Sprintf("failed to run hook reaction: %v", err))
 	}
 }
 
-func runHook(ctx context.Context, queries *sqlc.Queries, collection, event string, record any, auth *sqlc.User) error {
-	payload, err := json.Marshal(&webhook.Payload{
-		Action:     event,
-		Collection: collection,
-		Record:     record,
-		Auth:       auth,
-		Admin:      nil,
-	})
-	if err != nil {
-		return fmt.Errorf("failed to marshal webhook payload: %w", err)
-	}
-
-	hooks, err := findByHookTrigger(ctx, queries, collection, event)
+func runHook(ctx context.Context, queries *sqlc.Queries, collection, event string, previous, record any, auth *sqlc.User) error {
+	matches, err := findByHookTrigger(ctx, queries, collection, event, previous, record)
 	if err != nil {
 		return fmt.Errorf("failed to find hook by trigger: %w", err)
 	}
 
-	if len(hooks) == 0 {
+	if len(matches) == 0 {
 		return nil
 	}
 
@@ -81,10 +97,51 @@ func runHook(ctx context.Context, queries *sqlc.Queries, collection, event strin
 		return fmt.Errorf("failed to load settings: %w", err)
 	}
 
-	var errs []error
+	var (
+		errs []error
+		vars *expression.Variables
+	)
+
+	for _, match := range matches {
+		var inputs map[string]string
+
+		if match.hook.Condition != "" || len(match.hook.Inputs) > 0 {
+			if vars == nil {
+				if vars, err = variables(ctx, queries, collection, record); err != nil {
+					return fmt.Errorf("failed to load expression variables: %w", err)
+				}
+			}
+
+			var ok bool
+
+			inputs, ok, err = evaluate(&match.hook, vars)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("failed to evaluate hook reaction %s: %w", match.reaction.ID, err))
+
+				continue
+			}
 
-	for _, hook := range hooks {
-		_, err = action.Run(ctx, settings.Meta.AppURL, queries, hook.Action, hook.Actiondata, payload)
+			if !ok {
+				continue
+			}
+		}
+
+		payload, err := json.Marshal(&Payload{
+			Payload: webhook.Payload{
+				Action:     event,
+				Collection: collection,
+				Record:     record,
+				Auth:       auth,
+				Admin:      nil,
+			},
+			Changes: match.changes,
+			Inputs:  inputs,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to marshal webhook payload: %w", err)
+		}
+
+		_, err = action.Run(ctx, settings, queries, match.reaction.ID, match.reaction.Action, match.reaction.Actiondata, payload)
 		if err != nil {
 			errs = append(errs, fmt.Errorf("failed to run hook reaction: %w", err))
 		}
@@ -93,7 +150,7 @@ func runHook(ctx context.Context, queries *sqlc.Queries, collection, event strin
 	return errors.Join(errs...)
 }
 
-func findByHookTrigger(ctx context.Context, queries *sqlc.Queries, collection, event string) ([]*sqlc.ListReactionsByTriggerRow, error) {
+func findByHookTrigger(ctx context.Context, queries *sqlc.Queries, collection, event string, previous, record any) ([]match, error) {
 	reactions, err := database.PaginateItems(ctx, func(ctx context.Context, offset, limit int64) ([]sqlc.ListReactionsByTriggerRow, error) {
 		return queries.ListReactionsByTrigger(ctx, sqlc.ListReactionsByTriggerParams{Trigger: "hook", Limit: limit, Offset: offset})
 	})
@@ -105,7 +162,7 @@ func findByHookTrigger(ctx context.Context, queries *sqlc.Queries, collection, e
 		return nil, nil
 	}
 
-	var matchedRecords []*sqlc.ListReactionsByTriggerRow
+	var matches []match
 
 	for _, reaction := range reactions {
 		var hook Hook
@@ -113,10 +170,29 @@ func findByHookTrigger(ctx context.Context, queries *sqlc.Queries, collection, e
 			return nil, err
 		}
 
-		if slices.Contains(hook.Collections, collection) && slices.Contains(hook.Events, event) {
-			matchedRecords = append(matchedRecords, &reaction)
+		if !slices.Contains(hook.Collections, collection) || !slices.Contains(hook.Events, event) {
+			continue
+		}
+
+		if event != database.UpdateAction || len(hook.Fields) == 0 {
+			matches = append(matches, match{reaction: &reaction, hook: hook})
+
+			continue
+		}
+
+		if previous == nil {
+			continue
+		}
+
+		changed, err := changes(previous, record, hook.Fields)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compare records: %w", err)
+		}
+
+		if len(changed) > 0 {
+			matches = append(matches, match{reaction: &reaction, hook: hook, changes: changed})
 		}
 	}
 
-	return matchedRecords, nil
+	return matches, nil
 }
@@ -0,0 +1,49 @@
+package hook
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/SecurityBrewery/catalyst/app/data"
+	"github.com/SecurityBrewery/catalyst/app/database"
+	"github.com/SecurityBrewery/catalyst/app/database/sqlc"
+)
+
+func TestFindByHookTrigger_fields(t *testing.T) {
+	t.Parallel()
+
+	queries := data.NewTestDB(t, t.TempDir())
+
+	_, err := queries.InsertReaction(t.Context(), sqlc.InsertReactionParams{
+		ID:          "r-test-severity",
+		Name:        "Severity",
+		Action:      "webhook",
+		Actiondata:  []byte(`{"url":"http://127.0.0.1:12345"}`),
+		Trigger:     "hook",
+		Triggerdata: json.RawMessage(`{"collections":["tickets"],"events":["update"],"fields":["state.severity"]}`),
+		Created:     time.Now(),
+		Updated:     time.Now(),
+	})
+	require.NoError(t, err)
+
+	previous := map[string]any{"name": "Phishing", "state": map[string]any{"severity": "low"}}
+
+	matches, err := findByHookTrigger(t.Context(), queries, "tickets", database.UpdateAction, previous, map[string]any{"name": "Renamed", "state": map[string]any{"severity": "low"}})
+	require.NoError(t, err)
+	assert.Empty(t, matches)
+
+	matches, err = findByHookTrigger(t.Context(), queries, "tickets", database.UpdateAction, previous, map[string]any{"name": "Phishing", "state": map[string]any{"severity": "high"}})
+	require.NoError(t, err)
+	require.Len(t, matches, 1)
+	assert.Equal(t, "r-test-severity", matches[0].reaction.ID)
+	assert.Equal(t, map[string]Change{"state.severity": {Old: "low", New: "high"}}, matches[0].changes)
+
+	// without the previous record the changes are unknown
+	matches, err = findByHookTrigger(t.Context(), queries, "tickets", database.UpdateAction, nil, map[string]any{"state": map[string]any{"severity": "high"}})
+	require.NoError(t, err)
+	assert.Empty(t, matches)
+}
@@ -0,0 +1,159 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+
+	"github.com/SecurityBrewery/catalyst/app/database/sqlc"
+	"github.com/SecurityBrewery/catalyst/app/reaction/action"
+	"github.com/SecurityBrewery/catalyst/app/redact"
+	"github.com/SecurityBrewery/catalyst/app/settings"
+)
+
+const (
+	// MaxJournalBody is the size up to which the body of a request is kept
+	// in the journal, larger bodies are cut and cannot be replayed.
+	MaxJournalBody = 256 * 1024
+	// journalSize is the number of requests kept per reaction.
+	journalSize = 100
+)
+
+var ErrTruncated = errors.New("the body of the request was cut in the journal, it cannot be replayed")
+
+// sensitiveHeaders are masked in the journal, as they carry credentials.
+var sensitiveHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "X-Api-Key"}
+
+// journal keeps a request of a webhook trigger with the outcome of its run.
+// Credentials, the secrets known to Catalyst and the body fields of the
+// trigger's journal_redact paths are masked.
+func journal(ctx context.Context, queries *sqlc.Queries, reactionID string, trigger *Webhook, request *Request, runErr error) {
+	redactor, err := redact.Load(ctx, queries)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to load secrets for the request journal", "reaction", reactionID, "error", err.Error())
+
+		return
+	}
+
+	entry, size, truncated := journalRequest(request, trigger.JournalRedact, redactor)
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to marshal journal entry", "reaction", reactionID, "error", err.Error())
+
+		return
+	}
+
+	params := sqlc.CreateJournalEntryParams{
+		Reaction:  reactionID,
+		Request:   data,
+		Size:      int64(size),
+		Truncated: truncated,
+		Success:   runErr == nil,
+	}
+
+	if runErr != nil {
+		params.Error = redactor.String(runErr.Error())
+	}
+
+	if err := queries.CreateJournalEntry(ctx, params); err != nil {
+		slog.ErrorContext(ctx, "failed to store journal entry", "reaction", reactionID, "error", err.Error())
+
+		return
+	}
+
+	if err := queries.PruneJournal(ctx, sqlc.PruneJournalParams{Reaction: reactionID, Keep: journalSize}); err != nil {
+		slog.ErrorContext(ctx, "failed to prune request journal", "reaction", reactionID, "error", err.Error())
+	}
+}
+
+// journalRequest returns the masked copy of a request that is kept in the
+// journal, with the size of the original body and whether it was cut.
+func journalRequest(request *Request, redactPaths []string, redactor *redact.Redactor) (*Request, int, bool) {
+	entry := *request
+	entry.Headers = request.Headers.Clone()
+
+	for _, header := range sensitiveHeaders {
+		if entry.Headers.Get(header) != "" {
+			entry.Headers.Set(header, redact.Mask)
+		}
+	}
+
+	for key, values := range entry.Headers {
+		for i, value := range values {
+			entry.Headers[key][i] = redactor.String(value)
+		}
+	}
+
+	if !entry.IsBase64Encoded && gjson.Valid(entry.Body) {
+		for _, path := range redactPaths {
+			if !gjson.Get(entry.Body, path).Exists() {
+				continue
+			}
+
+			if body, err := sjson.Set(entry.Body, path, redact.Mask); err == nil {
+				entry.Body = body
+			}
+		}
+	}
+
+	entry.Body = redactor.String(entry.Body)
+
+	size := len(request.Body)
+	if len(entry.Body) <= MaxJournalBody {
+		return &entry, size, false
+	}
+
+	entry.Body = strings.ToValidUTF8(entry.Body[:MaxJournalBody], "")
+
+	return &entry, size, true
+}
+
+// Replay runs the current action of a reaction with a request of its
+// journal, so that requests that failed before the action was fixed are
+// processed. The outcome is recorded on the entry.
+func Replay(ctx context.Context, queries *sqlc.Queries, reactionID, entryID string) (*sqlc.RequestJournal, []byte, error) {
+	entry, err := queries.GetJournalEntry(ctx, sqlc.GetJournalEntryParams{ID: entryID, Reaction: reactionID})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if entry.Truncated {
+		return nil, nil, ErrTruncated
+	}
+
+	reaction, err := queries.GetReaction(ctx, reactionID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	config, err := settings.Load(ctx, queries)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	output, runErr := action.Run(ctx, config, queries, reaction.ID, reaction.Action, reaction.Actiondata, entry.Request)
+
+	params := sqlc.UpdateJournalReplayParams{ID: entry.ID, Success: runErr == nil}
+
+	if runErr != nil {
+		redactor, err := redact.Load(ctx, queries)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		params.Error = redactor.String(runErr.Error())
+	}
+
+	entry, err = queries.UpdateJournalReplay(ctx, params)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &entry, output, nil
+}
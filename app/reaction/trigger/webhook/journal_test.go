@@ -0,0 +1,41 @@
+package webhook
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/SecurityBrewery/catalyst/app/redact"
+)
+
+func Test_journalRequest(t *testing.T) {
+	t.Parallel()
+
+	request := &Request{
+		Method:  http.MethodPost,
+		Path:    "/reaction/alerts",
+		Headers: http.Header{"Authorization": {"Bearer token"}, "X-Source": {"siem sk-secret"}},
+		Body:    `{"user":{"email":"alice@example.com"},"alert":"login failed","key":"sk-secret"}`,
+	}
+
+	entry, size, truncated := journalRequest(request, []string{"user.email", "missing"}, redact.New("sk-secret"))
+
+	assert.False(t, truncated)
+	assert.Equal(t, len(request.Body), size)
+	assert.Equal(t, redact.Mask, entry.Headers.Get("Authorization"))
+	assert.Equal(t, "siem "+redact.Mask, entry.Headers.Get("X-Source"))
+	assert.JSONEq(t, `{"user":{"email":"********"},"alert":"login failed","key":"********"}`, entry.Body)
+	assert.Equal(t, "Bearer token", request.Headers.Get("Authorization"), "the request itself is not changed")
+
+	large := &Request{Headers: http.Header{}, Body: strings.Repeat("ä", MaxJournalBody)}
+
+	entry, size, truncated = journalRequest(large, nil, redact.New())
+
+	assert.True(t, truncated)
+	assert.Equal(t, 2*MaxJournalBody, size)
+	assert.LessOrEqual(t, len(entry.Body), MaxJournalBody)
+	assert.True(t, utf8.ValidString(entry.Body))
+}
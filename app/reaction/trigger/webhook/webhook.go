@@ -21,6 +21,12 @@ import (
 type Webhook struct {
 	Token string `json:"token"`
 	Path  string `json:"path"`
+	// Journal keeps the requests, so that they can be replayed once a broken
+	// action is fixed.
+	Journal bool `json:"journal,omitempty"`
+	// JournalRedact are the paths of JSON body fields that are masked in the
+	// journal, like user.email.
+	JournalRedact []string `json:"journal_redact,omitempty"`
 }
 
 const prefix = "/reaction/"
@@ -31,13 +37,20 @@ func BindHooks(router chi.Router, queries *sqlc.Queries) {
 
 func handle(queries *sqlc.Queries) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		reaction, payload, status, err := parseRequest(queries, r)
+		reaction, trigger, request, status, err := parseRequest(queries, r)
 		if err != nil {
 			http.Error(w, err.Error(), status)
 
 			return
 		}
 
+		payload, err := json.Marshal(request)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+
+			return
+		}
+
 		settings, err := settings.Load(r.Context(), queries)
 		if err != nil {
 			http.Error(w, "failed to load settings: "+err.Error(), http.StatusInternalServerError)
@@ -45,7 +58,12 @@ func handle(queries *sqlc.Queries) http.HandlerFunc {
 			return
 		}
 
-		output, err := action.Run(r.Context(), settings.Meta.AppURL, queries, reaction.Action, reaction.Actiondata, payload)
+		output, err := action.Run(r.Context(), settings, queries, reaction.ID, reaction.Action, reaction.Actiondata, payload)
+
+		if trigger.Journal {
+			journal(r.Context(), queries, reaction.ID, trigger, request, err)
+		}
+
 		if err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 
@@ -58,49 +76,44 @@ func handle(queries *sqlc.Queries) http.HandlerFunc {
 	}
 }
 
-func parseRequest(queries *sqlc.Queries, r *http.Request) (*sqlc.ListReactionsByTriggerRow, []byte, int, error) {
+func parseRequest(queries *sqlc.Queries, r *http.Request) (*sqlc.ListReactionsByTriggerRow, *Webhook, *Request, int, error) {
 	if !strings.HasPrefix(r.URL.Path, prefix) {
-		return nil, nil, http.StatusNotFound, fmt.Errorf("wrong prefix")
+		return nil, nil, nil, http.StatusNotFound, fmt.Errorf("wrong prefix")
 	}
 
 	reactionName := strings.TrimPrefix(r.URL.Path, prefix)
 
 	reaction, trigger, found, err := findByWebhookTrigger(r.Context(), queries, reactionName)
 	if err != nil {
-		return nil, nil, http.StatusNotFound, err
+		return nil, nil, nil, http.StatusNotFound, err
 	}
 
 	if !found {
-		return nil, nil, http.StatusNotFound, fmt.Errorf("reaction not found")
+		return nil, nil, nil, http.StatusNotFound, fmt.Errorf("reaction not found")
 	}
 
 	if trigger.Token != "" {
 		auth := r.Header.Get("Authorization")
 
 		if !strings.HasPrefix(auth, "Bearer ") {
-			return nil, nil, http.StatusUnauthorized, fmt.Errorf("missing token")
+			return nil, nil, nil, http.StatusUnauthorized, fmt.Errorf("missing token")
 		}
 
 		if trigger.Token != strings.TrimPrefix(auth, "Bearer ") {
-			return nil, nil, http.StatusUnauthorized, fmt.Errorf("invalid token")
+			return nil, nil, nil, http.StatusUnauthorized, fmt.Errorf("invalid token")
 		}
 	}
 
 	body, isBase64Encoded := webhook.EncodeBody(r.Body)
 
-	payload, err := json.Marshal(&Request{
+	return reaction, trigger, &Request{
 		Method:          r.Method,
 		Path:            r.URL.EscapedPath(),
 		Headers:         r.Header,
 		Query:           r.URL.Query(),
 		Body:            body,
 		IsBase64Encoded: isBase64Encoded,
-	})
-	if err != nil {
-		return nil, nil, http.StatusInternalServerError, err
-	}
-
-	return reaction, payload, http.StatusOK, nil
+	}, http.StatusOK, nil
 }
 
 func findByWebhookTrigger(ctx context.Context, queries *sqlc.Queries, path string) (*sqlc.ListReactionsByTriggerRow, *Webhook, bool, error) {
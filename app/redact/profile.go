@@ -0,0 +1,74 @@
+package redact
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/SecurityBrewery/catalyst/app/database/sqlc"
+	"github.com/SecurityBrewery/catalyst/app/docx"
+)
+
+// Audiences are the audiences that tickets are exported for with a
+// redaction profile.
+var Audiences = []string{"customer", "management", "legal"}
+
+// Profile hides parts of a ticket that is exported for an audience.
+type Profile struct {
+	// Fields are the fields of the ticket state that are left out, matched
+	// regardless of case.
+	Fields []string
+	// HideFiles masks the names of the files.
+	HideFiles bool
+	// HideIdentities masks the owners of the ticket and its tasks, the
+	// authors of the comments and the user that exported the ticket.
+	HideIdentities bool
+}
+
+// ParseProfile returns the profile of a stored redaction profile.
+func ParseProfile(profile *sqlc.RedactionProfile) (*Profile, error) {
+	var fields []string
+	if err := json.Unmarshal(profile.Fields, &fields); err != nil {
+		return nil, fmt.Errorf("invalid fields of redaction profile %s: %w", profile.ID, err)
+	}
+
+	return &Profile{Fields: fields, HideFiles: profile.HideFiles, HideIdentities: profile.HideIdentities}, nil
+}
+
+// Ticket applies the profile to the content of a ticket export and returns
+// the user that exported it, which is masked with the identities.
+func (p *Profile) Ticket(ticket *docx.Ticket, exportedBy string) string {
+	for _, field := range slices.Collect(maps.Keys(ticket.State)) {
+		if slices.ContainsFunc(p.Fields, func(hidden string) bool { return strings.EqualFold(hidden, field) }) {
+			delete(ticket.State, field)
+		}
+	}
+
+	if p.HideFiles {
+		for i := range ticket.Files {
+			ticket.Files[i].Name = Mask
+		}
+	}
+
+	if !p.HideIdentities {
+		return exportedBy
+	}
+
+	if ticket.Owner != "" {
+		ticket.Owner = Mask
+	}
+
+	for i := range ticket.Tasks {
+		if ticket.Tasks[i].Owner != "" {
+			ticket.Tasks[i].Owner = Mask
+		}
+	}
+
+	for i := range ticket.Comments {
+		ticket.Comments[i].Author = Mask
+	}
+
+	return Mask
+}
@@ -0,0 +1,49 @@
+package redact
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/SecurityBrewery/catalyst/app/database/sqlc"
+	"github.com/SecurityBrewery/catalyst/app/docx"
+)
+
+func newTestTicket() *docx.Ticket {
+	return &docx.Ticket{
+		Owner:    "Bob Analyst",
+		State:    map[string]any{"tlp": "AMBER", "Reporter": "alice@example.com"},
+		Tasks:    []docx.Task{{Name: "Block sender", Owner: "Bob Analyst"}, {Name: "Inform user"}},
+		Comments: []docx.Comment{{Author: "Bob Analyst", Message: "Sender blocked"}},
+		Files:    []docx.File{{Name: "alice-mailbox.pst", Size: 1024}},
+	}
+}
+
+func TestProfile_Ticket(t *testing.T) {
+	t.Parallel()
+
+	profile, err := ParseProfile(&sqlc.RedactionProfile{Fields: []byte(`["reporter"]`), HideFiles: true, HideIdentities: true})
+	require.NoError(t, err)
+
+	ticket := newTestTicket()
+
+	assert.Equal(t, Mask, profile.Ticket(ticket, "Admin User (u_admin)"))
+	assert.Equal(t, map[string]any{"tlp": "AMBER"}, ticket.State)
+	assert.Equal(t, Mask, ticket.Owner)
+	assert.Equal(t, Mask, ticket.Tasks[0].Owner)
+	assert.Empty(t, ticket.Tasks[1].Owner, "unassigned tasks stay unassigned")
+	assert.Equal(t, Mask, ticket.Comments[0].Author)
+	assert.Equal(t, "Sender blocked", ticket.Comments[0].Message)
+	assert.Equal(t, Mask, ticket.Files[0].Name)
+	assert.Equal(t, int64(1024), ticket.Files[0].Size)
+
+	// an empty profile changes nothing
+	ticket = newTestTicket()
+
+	assert.Equal(t, "Admin User (u_admin)", (&Profile{}).Ticket(ticket, "Admin User (u_admin)"))
+	assert.Equal(t, newTestTicket(), ticket)
+
+	_, err = ParseProfile(&sqlc.RedactionProfile{Fields: []byte(`{`)})
+	require.Error(t, err)
+}
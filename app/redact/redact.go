@@ -0,0 +1,228 @@
+// Package redact masks the values of secrets before text is stored, so that
+// API keys and tokens do not leak through job output, audit entries, error
+// messages or ticket comments. The secrets are the ones registered in
+// Catalyst: the secrets of the settings, the shared secrets of federation
+// peers, the tokens of webhook reactions and the header values of webhook
+// actions and log sinks, which usually carry API keys.
+//
+// Redaction profiles hide parts of a ticket that is exported for an
+// audience, like custom fields, file names and the identities of the people
+// that worked on it.
+package redact
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"slices"
+	"strings"
+
+	"github.com/SecurityBrewery/catalyst/app/database"
+	"github.com/SecurityBrewery/catalyst/app/database/sqlc"
+	"github.com/SecurityBrewery/catalyst/app/settings"
+)
+
+// Mask replaces the secrets, like the redacted values of the API.
+const Mask = "********"
+
+// minSecretLength is the length below which values are not masked, short
+// values like "true" or "json" would mask too much unrelated text.
+const minSecretLength = 8
+
+// Redactor masks a fixed set of secrets. The zero value masks nothing.
+type Redactor struct {
+	replacer *strings.Replacer
+}
+
+// New returns a redactor for the secrets. Longer secrets are masked first,
+// so a secret that contains another one is masked as a whole.
+func New(secrets ...string) *Redactor {
+	secrets = slices.DeleteFunc(slices.Clone(secrets), func(secret string) bool {
+		return len(secret) < minSecretLength
+	})
+
+	// secrets in JSON documents are escaped, e.g. quotes and backslashes
+	for _, secret := range secrets {
+		if escaped := jsonEscape(secret); escaped != secret {
+			secrets = append(secrets, escaped)
+		}
+	}
+
+	if len(secrets) == 0 {
+		return &Redactor{}
+	}
+
+	slices.SortFunc(secrets, func(a, b string) int {
+		return cmp.Or(cmp.Compare(len(b), len(a)), strings.Compare(a, b))
+	})
+	secrets = slices.Compact(secrets)
+
+	pairs := make([]string, 0, 2*len(secrets))
+	for _, secret := range secrets {
+		pairs = append(pairs, secret, Mask)
+	}
+
+	return &Redactor{replacer: strings.NewReplacer(pairs...)}
+}
+
+// Load returns a redactor for the secrets registered in Catalyst, and the
+// extra secrets.
+func Load(ctx context.Context, queries *sqlc.Queries, extra ...string) (*Redactor, error) {
+	config, err := settings.Load(ctx, queries)
+	if err != nil {
+		return nil, err
+	}
+
+	secrets := append(settingsSecrets(config), extra...)
+
+	peers, err := database.PaginateItems(ctx, func(ctx context.Context, offset, limit int64) ([]sqlc.ListFederationPeersRow, error) {
+		return queries.ListFederationPeers(ctx, sqlc.ListFederationPeersParams{Offset: offset, Limit: limit})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, peer := range peers {
+		secrets = append(secrets, peer.Secret)
+	}
+
+	reactions, err := database.PaginateItems(ctx, func(ctx context.Context, offset, limit int64) ([]sqlc.ListReactionsRow, error) {
+		return queries.ListReactions(ctx, sqlc.ListReactionsParams{Offset: offset, Limit: limit})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, reaction := range reactions {
+		secrets = append(secrets, reactionSecrets(reaction.Triggerdata, reaction.Actiondata)...)
+	}
+
+	return New(secrets...), nil
+}
+
+func settingsSecrets(config *settings.Settings) []string {
+	secrets := []string{
+		config.SMTP.Password,
+		config.RecordAuthToken.Secret,
+		config.RecordPasswordResetToken.Secret,
+		config.RecordVerificationToken.Secret,
+		config.Slack.SigningSecret,
+		config.Slack.BotToken,
+		config.WebPush.VAPIDPrivateKey,
+		config.MetricsExport.SecretAccessKey,
+		config.BackupStorage.SecretAccessKey,
+		config.BackupStorage.AzureAccountKey,
+		config.BackupStorage.GCSSecret,
+		config.CVEEnrichment.NVDAPIKey,
+	}
+
+	for _, sink := range config.LogSinks {
+		secrets = append(secrets, headerSecrets(sink.Headers)...)
+	}
+
+	secrets = append(secrets, headerSecrets(config.BackupNotification.Headers)...)
+
+	return secrets
+}
+
+// reactionSecrets returns the token of a webhook trigger and the header
+// values of a webhook action. Other reactions have no fields with secrets.
+func reactionSecrets(triggerData, actionData []byte) []string {
+	var trigger struct {
+		Token string `json:"token"`
+	}
+
+	var action struct {
+		Headers map[string]string `json:"headers"`
+	}
+
+	var secrets []string
+
+	if json.Unmarshal(triggerData, &trigger) == nil {
+		secrets = append(secrets, trigger.Token)
+	}
+
+	if json.Unmarshal(actionData, &action) == nil {
+		secrets = append(secrets, headerSecrets(action.Headers)...)
+	}
+
+	return secrets
+}
+
+// headerSecrets returns the values of HTTP headers, which usually carry API
+// keys.
+func headerSecrets(headers map[string]string) []string {
+	var secrets []string
+
+	for _, value := range headers {
+		// keep the scheme of authorization headers out of the secret,
+		// e.g. "Bearer <key>"
+		if scheme, credentials, ok := strings.Cut(value, " "); ok && !strings.Contains(credentials, " ") && scheme != "" {
+			secrets = append(secrets, credentials)
+		}
+
+		secrets = append(secrets, value)
+	}
+
+	return secrets
+}
+
+func jsonEscape(s string) string {
+	b, err := json.Marshal(s)
+	if err != nil {
+		return s
+	}
+
+	return string(b[1 : len(b)-1])
+}
+
+// String masks the secrets in s.
+func (r *Redactor) String(s string) string {
+	if r == nil || r.replacer == nil {
+		return s
+	}
+
+	return r.replacer.Replace(s)
+}
+
+// Bytes masks the secrets in b.
+func (r *Redactor) Bytes(b []byte) []byte {
+	if r == nil || r.replacer == nil {
+		return b
+	}
+
+	return []byte(r.replacer.Replace(string(b)))
+}
+
+// JSON masks the secrets in a JSON document. If masking a secret would
+// break the document, e.g. a secret that spans an escape sequence, the whole
+// document is replaced with the mask as a JSON string.
+func (r *Redactor) JSON(b []byte) []byte {
+	masked := r.Bytes(b)
+	if json.Valid(masked) {
+		return masked
+	}
+
+	return []byte(`"` + Mask + `"`)
+}
+
+// Error masks the secrets in the message of err. The error chain is lost,
+// so only errors that are stored or shown should be redacted.
+func (r *Redactor) Error(err error) error {
+	if err == nil || r == nil || r.replacer == nil {
+		return err
+	}
+
+	message := r.replacer.Replace(err.Error())
+	if message == err.Error() {
+		return err
+	}
+
+	return redactedError(message)
+}
+
+type redactedError string
+
+func (e redactedError) Error() string {
+	return string(e)
+}
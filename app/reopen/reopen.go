@@ -0,0 +1,119 @@
+// Package reopen notifies the user who closed a ticket when the ticket is
+// reopened, so the closer learns why the resolution did not hold.
+package reopen
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/SecurityBrewery/catalyst/app/database"
+	"github.com/SecurityBrewery/catalyst/app/database/sqlc"
+	"github.com/SecurityBrewery/catalyst/app/hook"
+	"github.com/SecurityBrewery/catalyst/app/openapi"
+	"github.com/SecurityBrewery/catalyst/app/pointer"
+	"github.com/SecurityBrewery/catalyst/app/push"
+	"github.com/SecurityBrewery/catalyst/app/settings"
+)
+
+// Sender delivers emails, usually the mail.Mailer.
+type Sender interface {
+	Send(ctx context.Context, to, subject, plainTextBody, htmlBody string) error
+}
+
+// Pusher delivers push notifications, usually the push.Notifier.
+type Pusher interface {
+	Send(ctx context.Context, user string, message push.Message) error
+}
+
+type Notifier struct {
+	queries *sqlc.Queries
+	mailer  Sender
+	pusher  Pusher
+}
+
+func New(queries *sqlc.Queries, mailer Sender, pusher Pusher) *Notifier {
+	return &Notifier{
+		queries: queries,
+		mailer:  mailer,
+		pusher:  pusher,
+	}
+}
+
+func BindHooks(hooks *hook.Hooks, queries *sqlc.Queries, mailer Sender, pusher Pusher) *Notifier {
+	n := New(queries, mailer, pusher)
+
+	hooks.OnRecordAfterCreateRequest.Subscribe(func(ctx context.Context, table string, record any) {
+		if table != database.TicketReopensTable.ID {
+			return
+		}
+
+		reopen, ok := record.(openapi.TicketReopen)
+		if !ok {
+			return
+		}
+
+		// don't block the request on slow channels
+		go func() {
+			if err := n.Notify(context.WithoutCancel(ctx), &reopen); err != nil {
+				slog.ErrorContext(ctx, "Failed to notify about reopened ticket", "ticket", reopen.Ticket, "error", err)
+			}
+		}()
+	})
+
+	return n
+}
+
+// Notify sends the reason of a reopen to the user who closed the ticket.
+// Nobody is notified if the closer reopened the ticket or is inactive.
+func (n *Notifier) Notify(ctx context.Context, reopen *openapi.TicketReopen) error {
+	if reopen.ClosedBy == nil || pointer.Dereference(reopen.ReopenedBy) == *reopen.ClosedBy {
+		return nil
+	}
+
+	closer, err := n.queries.GetUser(ctx, *reopen.ClosedBy)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	if !closer.Active {
+		return nil
+	}
+
+	ticket, err := n.queries.Ticket(ctx, reopen.Ticket)
+	if err != nil {
+		return err
+	}
+
+	se, err := settings.Load(ctx, n.queries)
+	if err != nil {
+		return err
+	}
+
+	reopenedBy := cmp.Or(pointer.Dereference(reopen.ReopenedByName), pointer.Dereference(reopen.ReopenedBy), "Someone")
+
+	url := strings.TrimSuffix(se.Meta.AppURL, "/") + "/ui/tickets/" + ticket.Type + "/" + ticket.ID
+	subject := "Reopened ticket: " + ticket.Name
+	body := fmt.Sprintf("%s reopened the ticket %q that you closed as %q.\n\nReason: %s\n\n%s",
+		reopenedBy, ticket.Name, pointer.Dereference(reopen.Resolution), reopen.Reason, url)
+
+	if closer.Email != nil && *closer.Email != "" && n.mailer != nil {
+		if err := n.mailer.Send(ctx, *closer.Email, subject, body, ""); err != nil {
+			slog.ErrorContext(ctx, "Failed to send reopen notification", "ticket", ticket.ID, "user", closer.ID, "error", err)
+		}
+	}
+
+	if n.pusher != nil {
+		if err := n.pusher.Send(ctx, closer.ID, push.Message{Title: subject, Body: reopen.Reason, URL: url}); err != nil {
+			slog.ErrorContext(ctx, "Failed to push reopen notification", "ticket", ticket.ID, "user", closer.ID, "error", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,92 @@
+package reopen
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/SecurityBrewery/catalyst/app/data"
+	"github.com/SecurityBrewery/catalyst/app/openapi"
+	"github.com/SecurityBrewery/catalyst/app/pointer"
+	"github.com/SecurityBrewery/catalyst/app/push"
+)
+
+type fakeMailer struct {
+	to []string
+}
+
+func (f *fakeMailer) Send(_ context.Context, to, _, _, _ string) error {
+	f.to = append(f.to, to)
+
+	return nil
+}
+
+type fakePusher struct {
+	users []string
+}
+
+func (f *fakePusher) Send(_ context.Context, user string, _ push.Message) error {
+	f.users = append(f.users, user)
+
+	return nil
+}
+
+func TestNotifier_Notify(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		closedBy   *string
+		reopenedBy *string
+		wantMails  []string
+		wantPushes []string
+	}{
+		{
+			name:       "closer is notified",
+			closedBy:   pointer.Pointer("u_bob_analyst"),
+			reopenedBy: pointer.Pointer("u_admin"),
+			wantMails:  []string{"analyst@catalyst-soar.com"},
+			wantPushes: []string{"u_bob_analyst"},
+		},
+		{
+			name:       "closer reopened the ticket",
+			closedBy:   pointer.Pointer("u_bob_analyst"),
+			reopenedBy: pointer.Pointer("u_bob_analyst"),
+		},
+		{
+			name:       "closer is unknown",
+			reopenedBy: pointer.Pointer("u_admin"),
+		},
+		{
+			name:       "closer was deleted",
+			closedBy:   pointer.Pointer("u_deleted"),
+			reopenedBy: pointer.Pointer("u_admin"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mailer := &fakeMailer{}
+			pusher := &fakePusher{}
+			n := New(data.NewTestDB(t, t.TempDir()), mailer, pusher)
+
+			require.NoError(t, n.Notify(t.Context(), &openapi.TicketReopen{
+				Id:         "r_test",
+				Ticket:     "test-ticket",
+				Reason:     "New evidence",
+				Resolution: pointer.Pointer("false positive"),
+				ClosedBy:   tt.closedBy,
+				ReopenedBy: tt.reopenedBy,
+				Created:    time.Now(),
+			}))
+
+			assert.Equal(t, tt.wantMails, mailer.to)
+			assert.Equal(t, tt.wantPushes, pusher.users)
+		})
+	}
+}
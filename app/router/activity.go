@@ -0,0 +1,115 @@
+package router
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+
+	"github.com/SecurityBrewery/catalyst/app/auth/usercontext"
+	"github.com/SecurityBrewery/catalyst/app/database/sqlc"
+	"github.com/SecurityBrewery/catalyst/app/redact"
+)
+
+const (
+	// ActivityHeader names the ticket a client is working in. Requests with
+	// this header are recorded in the activity log of the ticket if the
+	// "activity" feature flag is set.
+	ActivityHeader = "X-Catalyst-Ticket"
+
+	// maxActivityBody limits the size of recorded request bodies, larger
+	// bodies are recorded without their content.
+	maxActivityBody = 64 << 10
+)
+
+// recordActivity records the requests that carry the activity header, so
+// the actions taken while working in a ticket can be replayed in an
+// after-action review. The JSON bodies of mutating requests are recorded
+// with the request.
+func recordActivity(queries *sqlc.Queries) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ticket := r.Header.Get(ActivityHeader)
+			if ticket == "" || !hasFeature(r.Context(), queries, "activity") {
+				next.ServeHTTP(w, r)
+
+				return
+			}
+
+			body := activityBody(r)
+
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			start := time.Now()
+
+			next.ServeHTTP(ww, r)
+
+			var userID *string
+			if user, ok := usercontext.UserFromContext(r.Context()); ok {
+				userID = &user.ID
+			}
+
+			path := r.URL.Path
+			if r.URL.RawQuery != "" {
+				path += "?" + r.URL.RawQuery
+			}
+
+			// the activity log is readable by all users of the ticket, keep
+			// the secrets out of it, like an API key in a reaction update
+			redactor, err := redact.Load(r.Context(), queries)
+			if err != nil {
+				slog.ErrorContext(r.Context(), "Failed to load secrets, ticket activity not recorded", "ticket", ticket, "error", err)
+
+				return
+			}
+
+			path = redactor.String(path)
+
+			if body != nil {
+				*body = redactor.String(*body)
+			}
+
+			if err := queries.CreateTicketActivity(r.Context(), sqlc.CreateTicketActivityParams{
+				Ticket:   ticket,
+				User:     userID,
+				Method:   r.Method,
+				Path:     path,
+				Body:     body,
+				Status:   int64(ww.Status()),
+				Duration: time.Since(start).Milliseconds(),
+				Created:  start.UTC(),
+			}); err != nil {
+				slog.ErrorContext(r.Context(), "Failed to record ticket activity", "ticket", ticket, "error", err)
+			}
+		})
+	}
+}
+
+// activityBody returns the JSON body of a mutating request and restores it
+// for the next handler.
+func activityBody(r *http.Request) *string {
+	if !isMutatingMethod(r) || r.Body == nil {
+		return nil
+	}
+
+	if mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type")); err != nil || mediaType != "application/json" {
+		return nil
+	}
+
+	b, err := io.ReadAll(io.LimitReader(r.Body, maxActivityBody+1))
+	r.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(b), r.Body), r.Body}
+
+	if err != nil || len(b) > maxActivityBody {
+		return nil
+	}
+
+	body := string(b)
+
+	return &body
+}
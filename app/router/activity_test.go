@@ -0,0 +1,67 @@
+package router
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/SecurityBrewery/catalyst/app/auth/usercontext"
+	"github.com/SecurityBrewery/catalyst/app/data"
+	"github.com/SecurityBrewery/catalyst/app/database/sqlc"
+)
+
+func Test_recordActivity(t *testing.T) {
+	t.Parallel()
+
+	queries := data.NewTestDB(t, t.TempDir())
+	mw := recordActivity(queries)
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// the handler still reads the full body
+		b, _ := io.ReadAll(r.Body)
+		if r.Method == http.MethodPost {
+			assert.JSONEq(t, `{"name": "Isolate host"}`, string(b))
+		}
+
+		w.WriteHeader(http.StatusTeapot)
+	})
+
+	request := func(method, target, body string) {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		req.Header.Set(ActivityHeader, "test-ticket")
+		req.Header.Set("Content-Type", "application/json")
+		req = req.WithContext(usercontext.UserContext(t.Context(), &sqlc.User{ID: "u_bob_analyst"}))
+
+		rr := httptest.NewRecorder()
+		mw(next).ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusTeapot, rr.Code)
+	}
+
+	// nothing is recorded without the feature flag
+	request(http.MethodGet, "/api/tickets/test-ticket", "")
+
+	_, err := queries.CreateFeature(t.Context(), "activity")
+	require.NoError(t, err)
+
+	request(http.MethodGet, "/api/tickets/test-ticket?view=full", "")
+	request(http.MethodPost, "/api/tasks", `{"name": "Isolate host"}`)
+
+	activity, err := queries.ListTicketActivity(t.Context(), sqlc.ListTicketActivityParams{Ticket: "test-ticket", Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, activity, 2)
+
+	assert.Equal(t, http.MethodGet, activity[0].Method)
+	assert.Equal(t, "/api/tickets/test-ticket?view=full", activity[0].Path)
+	assert.Nil(t, activity[0].Body)
+
+	assert.Equal(t, http.MethodPost, activity[1].Method)
+	assert.JSONEq(t, `{"name": "Isolate host"}`, *activity[1].Body)
+	assert.Equal(t, int64(http.StatusTeapot), activity[1].Status)
+	assert.Equal(t, "u_bob_analyst", *activity[1].User)
+	assert.Equal(t, "Bob Analyst", *activity[1].UserName)
+}
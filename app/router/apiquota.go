@@ -0,0 +1,42 @@
+package router
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/SecurityBrewery/catalyst/app/apiusage"
+	"github.com/SecurityBrewery/catalyst/app/auth/usercontext"
+)
+
+// apiQuota counts the authenticated API requests of each client and rejects
+// the requests over its daily quota until the quota resets at midnight UTC.
+func apiQuota(usage *apiusage.Counter) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user, ok := usercontext.UserFromContext(r.Context())
+			if !ok {
+				next.ServeHTTP(w, r)
+
+				return
+			}
+
+			now := time.Now()
+
+			err := usage.Record(r.Context(), user.ID, usercontext.ActorFromContext(r.Context()), now)
+			if errors.Is(err, apiusage.ErrQuotaExceeded) {
+				w.Header().Set("Retry-After", strconv.Itoa(int(apiusage.Reset(now).Seconds())+1))
+				http.Error(w, "Daily API quota exceeded", http.StatusTooManyRequests)
+
+				return
+			} else if err != nil {
+				// a failure to count must not block the API
+				slog.ErrorContext(r.Context(), "Failed to record API usage", "user", user.ID, "error", err)
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/SecurityBrewery/catalyst/app/apiusage"
+	"github.com/SecurityBrewery/catalyst/app/auth/usercontext"
+	"github.com/SecurityBrewery/catalyst/app/data"
+	"github.com/SecurityBrewery/catalyst/app/database/sqlc"
+)
+
+func Test_apiQuota(t *testing.T) {
+	t.Parallel()
+
+	queries := data.NewTestDB(t, t.TempDir())
+	mw := apiQuota(apiusage.NewCounter(queries))
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})
+
+	user, err := queries.GetUser(t.Context(), "u_bob_analyst")
+	require.NoError(t, err)
+
+	_, err = queries.UpsertAPIQuota(t.Context(), sqlc.UpsertAPIQuotaParams{User: user.ID, DailyLimit: 1})
+	require.NoError(t, err)
+
+	request := func() *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		req := usercontext.UserRequest(httptest.NewRequest(http.MethodGet, "/api/tickets", nil).WithContext(t.Context()), &user)
+		mw(next).ServeHTTP(rr, req)
+
+		return rr
+	}
+
+	assert.Equal(t, http.StatusTeapot, request().Code)
+
+	rr := request()
+	assert.Equal(t, http.StatusTooManyRequests, rr.Code)
+	assert.NotEmpty(t, rr.Header().Get("Retry-After"))
+
+	// unauthenticated requests are not counted
+	rr = httptest.NewRecorder()
+	mw(next).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/config", nil).WithContext(t.Context()))
+	assert.Equal(t, http.StatusTeapot, rr.Code)
+}
@@ -0,0 +1,114 @@
+package router
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"golang.org/x/net/websocket"
+
+	"github.com/SecurityBrewery/catalyst/app/backup"
+	"github.com/SecurityBrewery/catalyst/app/database/sqlc"
+)
+
+// backupJobMessage is a message of the backup progress, like the BackupJob of
+// the API.
+type backupJobMessage struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	Location   string     `json:"location"`
+	Base       string     `json:"base,omitempty"`
+	Status     string     `json:"status"`
+	Stage      string     `json:"stage,omitempty"`
+	Files      int        `json:"files"`
+	TotalFiles int        `json:"total_files"`
+	Size       int64      `json:"size"`
+	Excluded   []string   `json:"excluded,omitempty"`
+	Error      string     `json:"error,omitempty"`
+	Created    time.Time  `json:"created"`
+	Finished   *time.Time `json:"finished,omitempty"`
+	Expires    *time.Time `json:"expires,omitempty"`
+}
+
+func newBackupJobMessage(job *backup.Job) backupJobMessage {
+	return backupJobMessage{
+		ID:         job.ID,
+		Name:       job.Name,
+		Location:   job.Location,
+		Base:       job.Base,
+		Status:     string(job.Status),
+		Stage:      string(job.Stage),
+		Files:      job.Files,
+		TotalFiles: job.TotalFiles,
+		Size:       job.Size,
+		Excluded:   job.Excluded,
+		Error:      job.Error,
+		Created:    job.Created,
+		Finished:   job.Finished,
+		Expires:    job.Expires,
+	}
+}
+
+// backupProgress streams the progress of a backup job as JSON messages over a
+// WebSocket, so that long running backups can be followed instead of polling
+// the job, e.g. with
+// websocat -H "Authorization: Bearer $TOKEN" wss://catalyst.example.com/api/backup/jobs/$ID/progress.
+// Browsers pass a socket token of /auth/socket-token as token parameter.
+// The current state of the job is sent first, the connection is closed after
+// the job finished.
+func backupProgress(queries *sqlc.Queries, backups *backup.Manager) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := chi.URLParam(r, "id")
+
+		if _, err := backups.Job(id); errors.Is(err, backup.ErrJobNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+
+			return
+		}
+
+		server := webSocketServer(queries, func(conn *websocket.Conn) {
+			defer conn.Close()
+
+			// subscribe before reading the job, so that no update is missed
+			updates, cancel := backups.SubscribeJobs()
+			defer cancel()
+
+			job, err := backups.Job(id)
+			if err != nil {
+				return
+			}
+
+			if err := websocket.JSON.Send(conn, newBackupJobMessage(job)); err != nil || job.Finished != nil {
+				return
+			}
+
+			// the client sends nothing, the read fails once it disconnects
+			closed := make(chan struct{})
+
+			go func() {
+				_, _ = io.Copy(io.Discard, conn)
+
+				close(closed)
+			}()
+
+			for {
+				select {
+				case <-closed:
+					return
+				case update := <-updates:
+					if update.ID != id {
+						continue
+					}
+
+					if err := websocket.JSON.Send(conn, newBackupJobMessage(&update)); err != nil || update.Finished != nil {
+						return
+					}
+				}
+			}
+		})
+
+		server.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,130 @@
+package router
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/websocket"
+
+	"github.com/SecurityBrewery/catalyst/app/backup"
+	"github.com/SecurityBrewery/catalyst/app/data"
+	"github.com/SecurityBrewery/catalyst/app/upload"
+)
+
+// slowTarget delays every write, so that the backup is still running when
+// the client connects.
+type slowTarget struct{}
+
+type slowWriter struct{}
+
+func (slowTarget) Create(context.Context, string) (backup.TargetWriter, error) {
+	return slowWriter{}, nil
+}
+
+func (slowTarget) Location(name string) string {
+	return "slow://" + name
+}
+
+func (slowWriter) Write(p []byte) (int, error) {
+	time.Sleep(time.Millisecond)
+
+	return len(p), nil
+}
+
+func (slowWriter) Close() error { return nil }
+
+func (slowWriter) Abort(context.Context) error { return nil }
+
+func newBackupProgressServer(t *testing.T) (*backup.Manager, *httptest.Server) {
+	t.Helper()
+
+	dir := t.TempDir()
+
+	uploader, err := upload.New(dir)
+	require.NoError(t, err)
+
+	queries := data.NewTestDB(t, dir)
+
+	backups, err := backup.New(queries, uploader, dir, backup.Config{})
+	require.NoError(t, err)
+
+	r := chi.NewRouter()
+	r.Handle("/api/backup/jobs/{id}/progress", backupProgress(queries, backups))
+
+	server := httptest.NewServer(r)
+	t.Cleanup(server.Close)
+
+	return backups, server
+}
+
+func Test_backupProgress(t *testing.T) {
+	t.Parallel()
+
+	backups, server := newBackupProgressServer(t)
+
+	job, err := backups.Stream(t.Context(), slowTarget{})
+	require.NoError(t, err)
+
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/api/backup/jobs/" + job.ID + "/progress"
+
+	conn, err := websocket.Dial(url, "", "http://localhost/")
+	require.NoError(t, err)
+
+	t.Cleanup(func() { conn.Close() })
+
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(10*time.Second)))
+
+	// the messages end with the finished job
+	var messages []backupJobMessage
+
+	for {
+		var message backupJobMessage
+		require.NoError(t, websocket.JSON.Receive(conn, &message))
+
+		messages = append(messages, message)
+
+		if message.Finished != nil {
+			break
+		}
+	}
+
+	last := messages[len(messages)-1]
+	assert.Equal(t, job.ID, last.ID)
+	assert.Equal(t, "succeeded", last.Status)
+	assert.Equal(t, "uploads", last.Stage)
+	assert.Equal(t, last.TotalFiles, last.Files)
+	assert.Positive(t, last.Size)
+
+	// a finished job is sent once
+	conn, err = websocket.Dial(url, "", "http://localhost/")
+	require.NoError(t, err)
+
+	t.Cleanup(func() { conn.Close() })
+
+	var message backupJobMessage
+	require.NoError(t, websocket.JSON.Receive(conn, &message))
+	assert.Equal(t, last, message)
+}
+
+func Test_backupProgress_notFound(t *testing.T) {
+	t.Parallel()
+
+	_, server := newBackupProgressServer(t)
+
+	req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, server.URL+"/api/backup/jobs/bj_unknown/progress", nil)
+	require.NoError(t, err)
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
@@ -0,0 +1,51 @@
+package router
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// conditionalGet extends etag with a Last-Modified header derived from the
+// "updated" field of the returned record. Requests with a matching
+// If-Modified-Since header are answered with a 304. Lists get no
+// Last-Modified header, deleting a record does not change the latest update
+// of the others, so they are only validated by their ETag.
+func conditionalGet(next http.Handler) http.Handler {
+	return etag(next, ifModifiedSince)
+}
+
+func ifModifiedSince(w http.ResponseWriter, r *http.Request, body []byte) bool {
+	lastModified, ok := lastModified(body)
+	if !ok {
+		return false
+	}
+
+	w.Header().Set("Last-Modified", lastModified.Format(http.TimeFormat))
+
+	ifModifiedSince, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	if err != nil {
+		return false
+	}
+
+	return !lastModified.After(ifModifiedSince)
+}
+
+// lastModified returns the "updated" timestamp of a JSON record, truncated to
+// the precision of HTTP dates.
+func lastModified(body []byte) (time.Time, bool) {
+	if bytes.HasPrefix(bytes.TrimSpace(body), []byte("[")) {
+		return time.Time{}, false
+	}
+
+	var record struct {
+		Updated time.Time `json:"updated"`
+	}
+
+	if err := json.Unmarshal(body, &record); err != nil || record.Updated.IsZero() {
+		return time.Time{}, false
+	}
+
+	return record.Updated.UTC().Truncate(time.Second), true
+}
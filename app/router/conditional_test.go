@@ -0,0 +1,128 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_conditionalGet_etag(t *testing.T) {
+	t.Parallel()
+
+	handler := conditionalGet(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"test"}`))
+	}))
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/tickets/test", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `{"id":"test"}`, rr.Body.String())
+
+	tag := rr.Header().Get("ETag")
+	assert.NotEmpty(t, tag)
+
+	req := httptest.NewRequest(http.MethodGet, "/tickets/test", nil)
+	req.Header.Set("If-None-Match", tag)
+
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusNotModified, rr.Code)
+	assert.Empty(t, rr.Body.String())
+
+	req = httptest.NewRequest(http.MethodGet, "/tickets/test", nil)
+	req.Header.Set("If-None-Match", `W/"other"`)
+
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+}
+
+func Test_conditionalGet_lastModified(t *testing.T) {
+	t.Parallel()
+
+	handler := conditionalGet(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"a","updated":"2025-06-21T22:21:26.271Z"}`))
+	}))
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/tickets/a", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "Sat, 21 Jun 2025 22:21:26 GMT", rr.Header().Get("Last-Modified"))
+
+	req := httptest.NewRequest(http.MethodGet, "/tickets/a", nil)
+	req.Header.Set("If-Modified-Since", "Sat, 21 Jun 2025 22:21:26 GMT")
+
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusNotModified, rr.Code)
+
+	req = httptest.NewRequest(http.MethodGet, "/tickets/a", nil)
+	req.Header.Set("If-Modified-Since", time.Date(2025, 6, 21, 0, 0, 0, 0, time.UTC).Format(http.TimeFormat))
+
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+}
+
+func Test_conditionalGet_listDelete(t *testing.T) {
+	t.Parallel()
+
+	records := []string{
+		`{"id":"a","updated":"2025-06-21T22:21:26.271Z"}`,
+		`{"id":"b","updated":"2024-01-01T00:00:00Z"}`,
+	}
+
+	handler := conditionalGet(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte("[" + strings.Join(records, ",") + "]"))
+	}))
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/tickets", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Empty(t, rr.Header().Get("Last-Modified"))
+
+	tag := rr.Header().Get("ETag")
+
+	// deleting b keeps the latest update of the list
+	records = records[:1]
+
+	req := httptest.NewRequest(http.MethodGet, "/tickets", nil)
+	req.Header.Set("If-None-Match", tag)
+	req.Header.Set("If-Modified-Since", "Sat, 21 Jun 2025 22:21:26 GMT")
+
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, "["+records[0]+"]", rr.Body.String())
+
+	req = httptest.NewRequest(http.MethodGet, "/tickets", nil)
+	req.Header.Set("If-Modified-Since", "Sat, 21 Jun 2025 22:21:26 GMT")
+
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+}
+
+func Test_lastModified(t *testing.T) {
+	t.Parallel()
+
+	_, ok := lastModified([]byte(`{"id":"a"}`))
+	assert.False(t, ok)
+
+	_, ok = lastModified([]byte(`not json`))
+	assert.False(t, ok)
+
+	_, ok = lastModified([]byte(`[{"updated":"2025-06-21T22:21:26.271Z"}]`))
+	assert.False(t, ok)
+
+	got, ok := lastModified([]byte(`{"updated":"2025-06-21T22:21:26.271Z"}`))
+	assert.True(t, ok)
+	assert.Equal(t, time.Date(2025, 6, 21, 22, 21, 26, 0, time.UTC), got)
+}
@@ -0,0 +1,132 @@
+package router
+
+import (
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/SecurityBrewery/catalyst/app/database/sqlc"
+	"github.com/SecurityBrewery/catalyst/app/settings"
+)
+
+const (
+	corsAllowedMethods = "GET, HEAD, POST, PUT, PATCH, DELETE"
+	corsExposedHeaders = "Content-Disposition, ETag, X-Total-Count"
+)
+
+// corsPolicy answers cross-origin requests of browsers with the CORS
+// settings, so that internal portals and separately hosted frontends can
+// call the API. Requests of origins that are not allowed get no CORS headers
+// and are blocked by the browser. Preflight requests are answered without
+// calling the next handler.
+func corsPolicy(queries *sqlc.Queries) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			origin := r.Header.Get("Origin")
+			if origin == "" {
+				next.ServeHTTP(w, r)
+
+				return
+			}
+
+			var config settings.CORS
+
+			if s, err := settings.Load(r.Context(), queries); err != nil {
+				slog.ErrorContext(r.Context(), "Failed to load CORS settings", "error", err)
+			} else {
+				config = s.CORS
+			}
+
+			setCORSHeaders(w.Header(), r, config, origin)
+
+			if r.Method == http.MethodOptions {
+				w.WriteHeader(http.StatusNoContent)
+
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func setCORSHeaders(header http.Header, r *http.Request, config settings.CORS, origin string) {
+	header.Add("Vary", "Origin")
+
+	allowedOrigin := corsAllowedOrigin(config, origin)
+	if allowedOrigin == "" {
+		return
+	}
+
+	header.Set("Access-Control-Allow-Origin", allowedOrigin)
+
+	if config.AllowCredentials && allowedOrigin != "*" {
+		header.Set("Access-Control-Allow-Credentials", "true")
+	}
+
+	if r.Method != http.MethodOptions {
+		header.Set("Access-Control-Expose-Headers", corsExposedHeaders)
+
+		return
+	}
+
+	header.Set("Access-Control-Allow-Methods", corsAllowedMethods)
+
+	if len(config.AllowedHeaders) > 0 {
+		header.Set("Access-Control-Allow-Headers", strings.Join(config.AllowedHeaders, ", "))
+	} else if requested := r.Header.Get("Access-Control-Request-Headers"); requested != "" {
+		header.Set("Access-Control-Allow-Headers", requested)
+	}
+
+	if config.MaxAge > 0 {
+		header.Set("Access-Control-Max-Age", strconv.Itoa(config.MaxAge))
+	}
+}
+
+// corsAllowedOrigin returns the value of the Access-Control-Allow-Origin
+// header, or an empty string if the origin is not allowed. Without allowed
+// origins all origins are allowed, like before the policy was configurable.
+// Origins that are only allowed by "*" get a literal "*" without
+// credentials. The settings API rejects "*" with allowCredentials, but the
+// environment can still set both.
+func corsAllowedOrigin(config settings.CORS, origin string) string {
+	if len(config.AllowedOrigins) == 0 {
+		return "*"
+	}
+
+	if slices.ContainsFunc(config.AllowedOrigins, func(allowed string) bool { return matchOrigin(allowed, origin) }) {
+		return origin
+	}
+
+	if slices.Contains(config.AllowedOrigins, "*") {
+		return "*"
+	}
+
+	return ""
+}
+
+// matchOrigin matches an origin against an allowed origin, which may contain
+// a wildcard subdomain like "https://*.example.com".
+func matchOrigin(allowed, origin string) bool {
+	if strings.EqualFold(allowed, origin) {
+		return true
+	}
+
+	prefix, suffix, ok := strings.Cut(allowed, "*.")
+	if !ok {
+		return false
+	}
+
+	origin = strings.ToLower(origin)
+	prefix, suffix = strings.ToLower(prefix), "."+strings.ToLower(suffix)
+
+	if !strings.HasPrefix(origin, prefix) || !strings.HasSuffix(origin, suffix) {
+		return false
+	}
+
+	subdomain := strings.TrimSuffix(strings.TrimPrefix(origin, prefix), suffix)
+
+	return subdomain != "" && !strings.ContainsAny(subdomain, "/:@")
+}
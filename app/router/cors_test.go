@@ -0,0 +1,120 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/SecurityBrewery/catalyst/app/data"
+	"github.com/SecurityBrewery/catalyst/app/settings"
+)
+
+func Test_matchOrigin(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		allowed string
+		origin  string
+		want    bool
+	}{
+		{"*", "https://portal.example.com", false},
+		{"https://portal.example.com", "https://portal.example.com", true},
+		{"https://portal.example.com", "https://PORTAL.example.com", true},
+		{"https://portal.example.com", "http://portal.example.com", false},
+		{"https://portal.example.com", "https://portal.example.com:8443", false},
+		{"https://*.example.com", "https://portal.example.com", true},
+		{"https://*.example.com", "https://a.b.example.com", true},
+		{"https://*.example.com", "https://example.com", false},
+		{"https://*.example.com", "https://evil.com/.example.com", false},
+		{"https://*.example.com", "https://portal.example.com.evil.com", false},
+		{"https://*.example.com", "http://portal.example.com", false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, matchOrigin(tt.allowed, tt.origin), "%s %s", tt.allowed, tt.origin)
+	}
+}
+
+func Test_corsPolicy(t *testing.T) {
+	t.Parallel()
+
+	queries := data.NewTestDB(t, t.TempDir())
+	mw := corsPolicy(queries)
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})
+
+	request := func(method, origin string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		req := httptest.NewRequest(method, "/api/tickets", nil).WithContext(t.Context())
+		req.Header.Set("Origin", origin)
+		req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+		req.Header.Set("Access-Control-Request-Headers", "Authorization, Content-Type")
+		mw(next).ServeHTTP(rr, req)
+
+		return rr
+	}
+
+	// all origins are allowed without a policy
+	rr := request(http.MethodGet, "https://portal.example.com")
+	assert.Equal(t, http.StatusTeapot, rr.Code)
+	assert.Equal(t, "*", rr.Header().Get("Access-Control-Allow-Origin"))
+	assert.Empty(t, rr.Header().Get("Access-Control-Allow-Credentials"))
+
+	rr = request(http.MethodOptions, "https://portal.example.com")
+	assert.Equal(t, http.StatusNoContent, rr.Code)
+	assert.Equal(t, "Authorization, Content-Type", rr.Header().Get("Access-Control-Allow-Headers"))
+
+	_, err := settings.Update(t.Context(), queries, func(s *settings.Settings) {
+		s.CORS = settings.CORS{
+			AllowedOrigins:   []string{"https://*.example.com"},
+			AllowedHeaders:   []string{"Authorization"},
+			AllowCredentials: true,
+			MaxAge:           600,
+		}
+	})
+	require.NoError(t, err)
+
+	rr = request(http.MethodGet, "https://portal.example.com")
+	assert.Equal(t, http.StatusTeapot, rr.Code)
+	assert.Equal(t, "https://portal.example.com", rr.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "true", rr.Header().Get("Access-Control-Allow-Credentials"))
+	assert.Equal(t, "Origin", rr.Header().Get("Vary"))
+	assert.Equal(t, corsExposedHeaders, rr.Header().Get("Access-Control-Expose-Headers"))
+
+	rr = request(http.MethodOptions, "https://portal.example.com")
+	assert.Equal(t, http.StatusNoContent, rr.Code)
+	assert.Equal(t, corsAllowedMethods, rr.Header().Get("Access-Control-Allow-Methods"))
+	assert.Equal(t, "Authorization", rr.Header().Get("Access-Control-Allow-Headers"))
+	assert.Equal(t, "600", rr.Header().Get("Access-Control-Max-Age"))
+
+	// other origins get no CORS headers
+	rr = request(http.MethodGet, "https://evil.com")
+	assert.Equal(t, http.StatusTeapot, rr.Code)
+	assert.Empty(t, rr.Header().Get("Access-Control-Allow-Origin"))
+	assert.Empty(t, rr.Header().Get("Access-Control-Allow-Credentials"))
+
+	// "*" is never combined with credentials, e.g. if the environment sets
+	// both
+	_, err = settings.Update(t.Context(), queries, func(s *settings.Settings) {
+		s.CORS = settings.CORS{AllowedOrigins: []string{"https://*.example.com", "*"}, AllowCredentials: true}
+	})
+	require.NoError(t, err)
+
+	rr = request(http.MethodGet, "https://evil.com")
+	assert.Equal(t, "*", rr.Header().Get("Access-Control-Allow-Origin"))
+	assert.Empty(t, rr.Header().Get("Access-Control-Allow-Credentials"))
+
+	rr = request(http.MethodGet, "https://portal.example.com")
+	assert.Equal(t, "https://portal.example.com", rr.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "true", rr.Header().Get("Access-Control-Allow-Credentials"))
+
+	// requests without an origin are not CORS requests
+	rr = httptest.NewRecorder()
+	mw(next).ServeHTTP(rr, httptest.NewRequest(http.MethodOptions, "/api/tickets", nil).WithContext(t.Context()))
+	assert.Equal(t, http.StatusTeapot, rr.Code)
+	assert.Empty(t, rr.Header().Get("Vary"))
+}
@@ -2,12 +2,10 @@ package router
 
 import (
 	"context"
-	"log/slog"
 	"net/http"
 	"slices"
 	"strings"
 
-	"github.com/SecurityBrewery/catalyst/app/database"
 	"github.com/SecurityBrewery/catalyst/app/database/sqlc"
 )
 
@@ -28,6 +26,7 @@ func demoMode(queries *sqlc.Queries) func(http.Handler) http.Handler {
 func isCriticalPath(r *http.Request) bool {
 	// Define critical paths that should not be accessed in demo mode
 	criticalPaths := []string{
+		"/api/admin",
 		"/api/files",
 		"/api/groups",
 		"/api/reactions",
@@ -50,30 +49,11 @@ func isCriticalMethod(r *http.Request) bool {
 }
 
 func isDemoMode(ctx context.Context, queries *sqlc.Queries) bool {
-	var demoMode bool
-
-	if err := database.Paginate(ctx, func(ctx context.Context, offset, limit int64) (nextPage bool, err error) {
-		slog.InfoContext(ctx, "Checking for demo mode", "offset", offset, "limit", limit)
-
-		features, err := queries.ListFeatures(ctx, sqlc.ListFeaturesParams{Offset: offset, Limit: limit})
-		if err != nil {
-			return false, err
-		}
-
-		for _, feature := range features {
-			if feature.Key == "demo" {
-				demoMode = true
-
-				return false, nil // Stop pagination if demo mode is found
-			}
-		}
-
-		return true, nil
-	}); err != nil {
-		slog.ErrorContext(ctx, "Failed to check demo mode", "error", err)
+	return hasFeature(ctx, queries, "demo")
+}
 
-		return false
-	}
+func hasFeature(ctx context.Context, queries *sqlc.Queries, key string) bool {
+	_, err := queries.GetFeature(ctx, key)
 
-	return demoMode
+	return err == nil
 }
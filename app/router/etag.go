@@ -0,0 +1,152 @@
+package router
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// maxTaggedResponse limits the size of the responses that are buffered to
+// compute an ETag, larger responses are streamed without one.
+const maxTaggedResponse = 4 << 20
+
+// precondition sets a validator header derived from a buffered response body
+// and reports whether the client's copy, as described by the request's
+// conditional headers, is still current.
+type precondition func(w http.ResponseWriter, r *http.Request, body []byte) bool
+
+// etag buffers successful JSON responses of GET requests, sets a weak ETag
+// header derived from the body and answers matching If-None-Match requests
+// with a 304. The preconditions are only consulted if the request has no
+// If-None-Match header, see RFC 9110 13.1.3. All other responses, like file
+// downloads and exports, are streamed without buffering.
+func etag(next http.Handler, preconditions ...precondition) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || strings.HasSuffix(r.URL.Path, "/download") {
+			next.ServeHTTP(w, r)
+
+			return
+		}
+
+		buf := &bufferedResponseWriter{ResponseWriter: w, status: http.StatusOK}
+
+		next.ServeHTTP(buf, r)
+
+		if buf.passThrough || !buf.wroteHeader {
+			return
+		}
+
+		body := buf.body.Bytes()
+
+		tag := weakETag(body)
+		w.Header().Set("ETag", tag)
+
+		ifNoneMatch := r.Header.Get("If-None-Match")
+		notModified := etagMatches(ifNoneMatch, tag)
+
+		for _, precondition := range preconditions {
+			if precondition(w, r, body) && ifNoneMatch == "" {
+				notModified = true
+			}
+		}
+
+		if notModified {
+			w.Header().Del("Content-Length")
+			w.WriteHeader(http.StatusNotModified)
+
+			return
+		}
+
+		w.WriteHeader(buf.status)
+		_, _ = w.Write(body)
+	})
+}
+
+func weakETag(body []byte) string {
+	sum := sha256.Sum256(body)
+
+	return `W/"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+func etagMatches(ifNoneMatch, tag string) bool {
+	if ifNoneMatch == "" {
+		return false
+	}
+
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(tag, "W/") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// bufferedResponseWriter holds back successful JSON responses up to
+// maxTaggedResponse. Everything else is passed through to the underlying
+// writer as soon as the status is known.
+type bufferedResponseWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	passThrough bool
+	body        bytes.Buffer
+}
+
+func (b *bufferedResponseWriter) WriteHeader(status int) {
+	if b.wroteHeader {
+		return
+	}
+
+	b.wroteHeader = true
+	b.status = status
+
+	if status != http.StatusOK || !isJSON(b.Header()) || contentLength(b.Header()) > maxTaggedResponse {
+		b.passThrough = true
+		b.ResponseWriter.WriteHeader(status)
+	}
+}
+
+func (b *bufferedResponseWriter) Write(p []byte) (int, error) {
+	b.WriteHeader(http.StatusOK)
+
+	if b.passThrough {
+		return b.ResponseWriter.Write(p)
+	}
+
+	if b.body.Len()+len(p) > maxTaggedResponse {
+		// too large to be tagged, stream the rest of the response
+		b.passThrough = true
+		b.ResponseWriter.WriteHeader(b.status)
+
+		if _, err := b.ResponseWriter.Write(b.body.Bytes()); err != nil {
+			return 0, err
+		}
+
+		b.body.Reset()
+
+		return b.ResponseWriter.Write(p)
+	}
+
+	return b.body.Write(p)
+}
+
+func isJSON(header http.Header) bool {
+	mediaType, _, err := mime.ParseMediaType(header.Get("Content-Type"))
+
+	return err == nil && mediaType == "application/json"
+}
+
+func contentLength(header http.Header) int64 {
+	length, err := strconv.ParseInt(header.Get("Content-Length"), 10, 64)
+	if err != nil {
+		return -1
+	}
+
+	return length
+}
@@ -0,0 +1,89 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_etag(t *testing.T) {
+	t.Parallel()
+
+	handler := etag(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"test"}`))
+	}))
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/tickets/test", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `{"id":"test"}`, rr.Body.String())
+
+	tag := rr.Header().Get("ETag")
+	assert.NotEmpty(t, tag)
+
+	req := httptest.NewRequest(http.MethodGet, "/tickets/test", nil)
+	req.Header.Set("If-None-Match", tag)
+
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusNotModified, rr.Code)
+	assert.Empty(t, rr.Body.String())
+
+	req = httptest.NewRequest(http.MethodGet, "/tickets/test", nil)
+	req.Header.Set("If-None-Match", `W/"other"`)
+
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+}
+
+func Test_etagMatches(t *testing.T) {
+	t.Parallel()
+
+	assert.False(t, etagMatches("", `W/"a"`))
+	assert.True(t, etagMatches(`W/"a"`, `W/"a"`))
+	assert.True(t, etagMatches(`"a"`, `W/"a"`))
+	assert.True(t, etagMatches(`W/"b", W/"a"`, `W/"a"`))
+	assert.True(t, etagMatches("*", `W/"a"`))
+	assert.False(t, etagMatches(`W/"b"`, `W/"a"`))
+}
+
+func Test_etag_passThrough(t *testing.T) {
+	t.Parallel()
+
+	large := strings.Repeat("a", maxTaggedResponse+1)
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+	}{
+		{name: "not json", contentType: "application/zip", body: "archive"},
+		{name: "no content type", body: "archive"},
+		{name: "large json", contentType: "application/json", body: `"` + large + `"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			handler := etag(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				if tt.contentType != "" {
+					w.Header().Set("Content-Type", tt.contentType)
+				}
+
+				_, _ = w.Write([]byte(tt.body[:1]))
+				_, _ = w.Write([]byte(tt.body[1:]))
+			}))
+
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/backups/test", nil))
+			assert.Equal(t, http.StatusOK, rr.Code)
+			assert.Equal(t, tt.body, rr.Body.String())
+			assert.Empty(t, rr.Header().Get("ETag"))
+		})
+	}
+}
@@ -1,12 +1,17 @@
 package router
 
 import (
+	"io/fs"
+	"log/slog"
 	"net/http"
 	"net/http/httputil"
 	"net/url"
 	"os"
+	"path"
 	"strings"
 
+	"github.com/SecurityBrewery/catalyst/app/database/sqlc"
+	"github.com/SecurityBrewery/catalyst/app/settings"
 	"github.com/SecurityBrewery/catalyst/ui"
 )
 
@@ -21,17 +26,37 @@ func staticFiles(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	vueStatic(w, r)
+	vueStatic(ui.UI())(w, r)
 }
 
-func vueStatic(w http.ResponseWriter, r *http.Request) {
-	handler := http.FileServer(http.FS(ui.UI()))
+// vueStatic serves the files of the UI bundle and falls back to index.html
+// for all other paths, so the client side history routing works on reload.
+func vueStatic(fsys fs.FS) http.HandlerFunc {
+	handler := http.StripPrefix("/ui", http.FileServer(http.FS(fsys)))
 
-	if strings.HasPrefix(r.URL.Path, "/ui/assets/") {
-		handler = http.StripPrefix("/ui", handler)
-	} else {
-		r.URL.Path = "/"
+	return func(w http.ResponseWriter, r *http.Request) {
+		name := strings.TrimPrefix(path.Clean(strings.TrimPrefix(r.URL.Path, "/ui")), "/")
+
+		if info, err := fs.Stat(fsys, name); name == "" || err != nil || info.IsDir() {
+			r.URL.Path = "/ui/"
+		}
+
+		handler.ServeHTTP(w, r)
 	}
+}
+
+func customCSS(queries *sqlc.Queries) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s, err := settings.Load(r.Context(), queries)
+		if err != nil {
+			slog.ErrorContext(r.Context(), "Failed to load settings", "error", err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 
-	handler.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/css; charset=utf-8")
+
+		_, _ = w.Write([]byte(s.Branding.CustomCSS))
+	}
 }
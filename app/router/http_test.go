@@ -4,6 +4,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"testing/fstest"
 )
 
 func TestStaticFiles_DevServer(t *testing.T) {
@@ -28,3 +29,37 @@ func TestStaticFiles_VueStatic(t *testing.T) {
 		t.Error("expected a status code from vueStatic")
 	}
 }
+
+func TestVueStatic_Fallback(t *testing.T) {
+	t.Parallel()
+
+	fsys := fstest.MapFS{
+		"index.html":    {Data: []byte("index")},
+		"favicon.ico":   {Data: []byte("icon")},
+		"assets/app.js": {Data: []byte("app")},
+	}
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{path: "/ui/", want: "index"},
+		{path: "/ui/favicon.ico", want: "icon"},
+		{path: "/ui/assets/app.js", want: "app"},
+		{path: "/ui/tickets/incident/t_123", want: "index"},
+		{path: "/ui/assets/missing.js", want: "index"},
+		{path: "/ui/assets", want: "index"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			t.Parallel()
+
+			rec := httptest.NewRecorder()
+			vueStatic(fsys)(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			if got := rec.Body.String(); got != tt.want {
+				t.Errorf("vueStatic(%q) = %q, want %q", tt.path, got, tt.want)
+			}
+		})
+	}
+}
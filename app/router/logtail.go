@@ -0,0 +1,112 @@
+package router
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"time"
+
+	"golang.org/x/net/websocket"
+
+	"github.com/SecurityBrewery/catalyst/app/applog"
+	"github.com/SecurityBrewery/catalyst/app/database/sqlc"
+)
+
+// logTailEntry is a message of the log tail, like the LogEntry of the API.
+type logTailEntry struct {
+	Level     string         `json:"level"`
+	Message   string         `json:"message"`
+	Component string         `json:"component"`
+	User      string         `json:"user,omitempty"`
+	Attrs     map[string]any `json:"attrs"`
+	Created   time.Time      `json:"created"`
+}
+
+type logTailFilter struct {
+	level     slog.Level
+	component string
+	user      string
+}
+
+func (f *logTailFilter) match(entry *applog.Entry) bool {
+	return entry.Level >= f.level &&
+		(f.component == "" || entry.Component == f.component) &&
+		(f.user == "" || entry.User == f.user)
+}
+
+// logTail streams the new log records as JSON messages over a WebSocket, for
+// live troubleshooting, e.g. with
+// websocat -H "Authorization: Bearer $TOKEN" wss://catalyst.example.com/api/logs/tail?level=debug.
+// Browsers pass a socket token of /auth/socket-token as token parameter.
+// It has the level, component and user filters of the log search and
+// receives all records, regardless of the stored level.
+func logTail(queries *sqlc.Queries, logs *applog.Log) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		filter, err := parseLogTailFilter(r.URL.Query())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+
+			return
+		}
+
+		server := webSocketServer(queries, func(conn *websocket.Conn) {
+			defer conn.Close()
+
+			entries, cancel := logs.Subscribe()
+			defer cancel()
+
+			// the client sends nothing, the read fails once it disconnects
+			closed := make(chan struct{})
+
+			go func() {
+				_, _ = io.Copy(io.Discard, conn)
+
+				close(closed)
+			}()
+
+			for {
+				select {
+				case <-closed:
+					return
+				case entry := <-entries:
+					if !filter.match(&entry) {
+						continue
+					}
+
+					if err := websocket.JSON.Send(conn, logTailEntry{
+						Level:     applog.LevelName(entry.Level),
+						Message:   entry.Message,
+						Component: entry.Component,
+						User:      entry.User,
+						Attrs:     entry.Attrs,
+						Created:   entry.Created,
+					}); err != nil {
+						return
+					}
+				}
+			}
+		})
+
+		server.ServeHTTP(w, r)
+	})
+}
+
+func parseLogTailFilter(query url.Values) (*logTailFilter, error) {
+	filter := &logTailFilter{
+		level:     slog.LevelDebug,
+		component: query.Get("component"),
+		user:      query.Get("user"),
+	}
+
+	if level := query.Get("level"); level != "" {
+		parsed, err := applog.ParseLevel(level)
+		if err != nil {
+			return nil, err
+		}
+
+		filter.level = parsed
+	}
+
+	return filter, nil
+}
@@ -0,0 +1,76 @@
+package router
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/websocket"
+
+	"github.com/SecurityBrewery/catalyst/app/applog"
+	"github.com/SecurityBrewery/catalyst/app/data"
+)
+
+func Test_logTail(t *testing.T) {
+	t.Parallel()
+
+	queries := data.NewTestDB(t, t.TempDir())
+	logs := applog.New(queries)
+	logger := slog.New(logs.Handler(slog.DiscardHandler))
+
+	server := httptest.NewServer(logTail(queries, logs))
+	t.Cleanup(server.Close)
+
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "?level=warn&component=reaction"
+
+	conn, err := websocket.Dial(url, "", "http://localhost/")
+	require.NoError(t, err)
+
+	t.Cleanup(func() { conn.Close() })
+
+	// the handler subscribes after the handshake, log until it receives
+	done := make(chan struct{})
+	defer close(done)
+
+	go func() {
+		ticker := time.NewTicker(10 * time.Millisecond)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				logger.Error("Failed to send mail", applog.ComponentKey, "mail")
+				logger.Info("Running reaction", applog.ComponentKey, "reaction")
+				logger.Error("Failed to run reaction", applog.ComponentKey, "reaction", "reaction", "r_test")
+			}
+		}
+	}()
+
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+
+	var entry logTailEntry
+	require.NoError(t, websocket.JSON.Receive(conn, &entry))
+
+	assert.Equal(t, "error", entry.Level)
+	assert.Equal(t, "Failed to run reaction", entry.Message)
+	assert.Equal(t, "reaction", entry.Component)
+	assert.Equal(t, map[string]any{"reaction": "r_test"}, entry.Attrs)
+}
+
+func Test_logTail_invalidLevel(t *testing.T) {
+	t.Parallel()
+
+	queries := data.NewTestDB(t, t.TempDir())
+
+	rr := httptest.NewRecorder()
+	logTail(queries, applog.New(queries)).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/logs/tail?level=verbose", nil))
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
@@ -0,0 +1,173 @@
+package router
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// rateLimiter allows a fixed number of requests per client IP in each window.
+type rateLimiter struct {
+	limit  int
+	window time.Duration
+
+	mux     sync.Mutex
+	clients map[string]*rateWindow
+	swept   time.Time
+}
+
+type rateWindow struct {
+	start time.Time
+	count int
+}
+
+func newRateLimiter(limit int, window time.Duration) *rateLimiter {
+	return &rateLimiter{
+		limit:   limit,
+		window:  window,
+		clients: map[string]*rateWindow{},
+	}
+}
+
+func (l *rateLimiter) allow(client string, now time.Time) bool {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+
+	// drop expired windows so the map does not grow unbounded, once per
+	// window, so that a request does not have to scan all clients
+	if now.Sub(l.swept) >= l.window {
+		for key, w := range l.clients {
+			if now.Sub(w.start) >= l.window {
+				delete(l.clients, key)
+			}
+		}
+
+		l.swept = now
+	}
+
+	w, ok := l.clients[client]
+	if !ok || now.Sub(w.start) >= l.window {
+		w = &rateWindow{start: now}
+		l.clients[client] = w
+	}
+
+	if w.count >= l.limit {
+		return false
+	}
+
+	w.count++
+
+	return true
+}
+
+// publicRateLimit limits the requests to unauthenticated endpoints that
+// create records, like the intake form. Clients are identified by the
+// address of the connection, X-Forwarded-For is only honored if the
+// connection comes from one of the trusted proxies.
+func publicRateLimit(limiter *rateLimiter, trustedProxies []netip.Prefix) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodPost && r.URL.Path == "/api/intake" && !limiter.allow(clientIP(r, trustedProxies), time.Now()) {
+				w.Header().Set("Retry-After", strconv.Itoa(int(limiter.window.Seconds())))
+				http.Error(w, "Too many requests", http.StatusTooManyRequests)
+
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+type peerKey struct{}
+
+// socketPeer keeps the address of the connection before middleware.RealIP
+// replaces the remote address with the client supplied forwarding headers.
+func socketPeer(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), peerKey{}, r.RemoteAddr)))
+	})
+}
+
+func clientIP(r *http.Request, trustedProxies []netip.Prefix) string {
+	remoteAddr, ok := r.Context().Value(peerKey{}).(string)
+	if !ok {
+		remoteAddr = r.RemoteAddr
+	}
+
+	peer := remoteAddr
+	if host, _, err := net.SplitHostPort(remoteAddr); err == nil {
+		peer = host
+	}
+
+	if !isTrustedProxy(peer, trustedProxies) {
+		return peer
+	}
+
+	// the last address that was not added by a trusted proxy is the client
+	forwarded := strings.Split(r.Header.Get("X-Forwarded-For"), ",")
+	for i := len(forwarded) - 1; i >= 0; i-- {
+		address := strings.TrimSpace(forwarded[i])
+		if address == "" {
+			continue
+		}
+
+		if !isTrustedProxy(address, trustedProxies) {
+			return address
+		}
+	}
+
+	return peer
+}
+
+func isTrustedProxy(address string, trustedProxies []netip.Prefix) bool {
+	addr, err := netip.ParseAddr(address)
+	if err != nil {
+		return false
+	}
+
+	addr = addr.Unmap()
+
+	for _, prefix := range trustedProxies {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ParseTrustedProxies parses proxy addresses and CIDR ranges.
+func ParseTrustedProxies(values []string) ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(values))
+
+	for _, value := range values {
+		if strings.Contains(value, "/") {
+			prefix, err := netip.ParsePrefix(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid trusted proxy %q: %w", value, err)
+			}
+
+			prefixes = append(prefixes, prefix.Masked())
+
+			continue
+		}
+
+		addr, err := netip.ParseAddr(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", value, err)
+		}
+
+		addr = addr.Unmap()
+
+		prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+	}
+
+	return prefixes, nil
+}
@@ -0,0 +1,114 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimiter_Allow(t *testing.T) {
+	t.Parallel()
+
+	limiter := newRateLimiter(2, time.Minute)
+	now := time.Now()
+
+	assert.True(t, limiter.allow("a", now))
+	assert.True(t, limiter.allow("a", now))
+	assert.False(t, limiter.allow("a", now))
+	assert.True(t, limiter.allow("b", now))
+
+	// a new window starts after the window expired
+	assert.True(t, limiter.allow("a", now.Add(time.Minute)))
+}
+
+func TestRateLimiter_sweep(t *testing.T) {
+	t.Parallel()
+
+	limiter := newRateLimiter(1, time.Minute)
+	now := time.Now()
+
+	assert.True(t, limiter.allow("a", now))
+	assert.True(t, limiter.allow("b", now.Add(30*time.Second)))
+	assert.Len(t, limiter.clients, 2)
+
+	// the expired window of a is dropped in the sweep of the next window,
+	// the window of b is still running
+	assert.True(t, limiter.allow("c", now.Add(time.Minute)))
+	assert.Len(t, limiter.clients, 2)
+	assert.False(t, limiter.allow("b", now.Add(time.Minute)))
+
+	// no sweep within the window, an expired client still gets a new window
+	assert.True(t, limiter.allow("b", now.Add(90*time.Second)))
+	assert.Len(t, limiter.clients, 2)
+}
+
+func TestPublicRateLimit(t *testing.T) {
+	t.Parallel()
+
+	handler := publicRateLimit(newRateLimiter(1, time.Hour), nil)(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	tests := []struct {
+		name         string
+		method       string
+		path         string
+		forwardedFor string
+		want         int
+	}{
+		{name: "first submission", method: http.MethodPost, path: "/api/intake", want: http.StatusOK},
+		{name: "second submission", method: http.MethodPost, path: "/api/intake", want: http.StatusTooManyRequests},
+		{name: "form is not limited", method: http.MethodGet, path: "/api/intake", want: http.StatusOK},
+		{name: "other paths are not limited", method: http.MethodPost, path: "/api/tickets", want: http.StatusOK},
+		{name: "forwarding headers are ignored", method: http.MethodPost, path: "/api/intake", forwardedFor: "198.51.100.7", want: http.StatusTooManyRequests},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, tt.path, nil)
+		if tt.forwardedFor != "" {
+			req.Header.Set("X-Forwarded-For", tt.forwardedFor)
+		}
+
+		rec := httptest.NewRecorder()
+		socketPeer(middleware.RealIP(handler)).ServeHTTP(rec, req)
+
+		assert.Equal(t, tt.want, rec.Code, tt.name)
+	}
+}
+
+func Test_clientIP(t *testing.T) {
+	t.Parallel()
+
+	trusted, err := ParseTrustedProxies([]string{"10.0.0.0/8", "192.0.2.1"})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name         string
+		remoteAddr   string
+		forwardedFor string
+		want         string
+	}{
+		{name: "direct", remoteAddr: "198.51.100.7:1234", want: "198.51.100.7"},
+		{name: "untrusted proxy", remoteAddr: "198.51.100.7:1234", forwardedFor: "203.0.113.9", want: "198.51.100.7"},
+		{name: "trusted proxy", remoteAddr: "192.0.2.1:1234", forwardedFor: "203.0.113.9", want: "203.0.113.9"},
+		{name: "spoofed chain", remoteAddr: "192.0.2.1:1234", forwardedFor: "1.2.3.4, 203.0.113.9, 10.1.2.3", want: "203.0.113.9"},
+		{name: "no header", remoteAddr: "192.0.2.1:1234", want: "192.0.2.1"},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodPost, "/api/intake", nil)
+		req.RemoteAddr = tt.remoteAddr
+
+		if tt.forwardedFor != "" {
+			req.Header.Set("X-Forwarded-For", tt.forwardedFor)
+		}
+
+		assert.Equal(t, tt.want, clientIP(req, trusted), tt.name)
+	}
+
+	_, err = ParseTrustedProxies([]string{"proxy"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,39 @@
+package router
+
+import (
+	"net/http"
+	"slices"
+)
+
+// readOnlyMode rejects all mutating requests when the server is started with
+// --read-only. This allows running an instance against a replicated database
+// to expose dashboards and search without risking writes. The mode is a
+// process setting, a replica cannot be switched by a row in its database.
+func readOnlyMode(readOnly bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if !readOnly {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if isMutatingMethod(r) && !isReadOnlyAllowedPath(r) {
+				w.Header().Set("Allow", "GET, HEAD")
+				http.Error(w, "Cannot modify data in read-only mode", http.StatusMethodNotAllowed)
+
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func isMutatingMethod(r *http.Request) bool {
+	return !slices.Contains([]string{http.MethodHead, http.MethodGet, http.MethodOptions}, r.Method)
+}
+
+func isReadOnlyAllowedPath(r *http.Request) bool {
+	// login and the token exchanges are required to read data and do not
+	// modify the database
+	return r.URL.Path == "/auth/local/login" || r.URL.Path == "/auth/token" || r.URL.Path == "/auth/socket-token"
+}
@@ -0,0 +1,69 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_isMutatingMethod(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		method string
+		want   bool
+	}{
+		{http.MethodPost, true},
+		{http.MethodPatch, true},
+		{http.MethodDelete, true},
+		{http.MethodGet, false},
+		{http.MethodHead, false},
+		{http.MethodOptions, false},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, "/", nil)
+		assert.Equal(t, tt.want, isMutatingMethod(req))
+	}
+}
+
+func Test_readOnlyModeMiddleware(t *testing.T) {
+	t.Parallel()
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})
+
+	// not read-only
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/api/tickets", nil).WithContext(t.Context())
+	readOnlyMode(false)(next).ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusTeapot, rr.Code)
+
+	// read-only mode
+	mw := readOnlyMode(true)
+
+	rr = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodPost, "/api/tickets", nil).WithContext(t.Context())
+	mw(next).ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusMethodNotAllowed, rr.Code)
+
+	// reads are allowed
+	rr = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodGet, "/api/tickets", nil).WithContext(t.Context())
+	mw(next).ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusTeapot, rr.Code)
+
+	// login is allowed
+	rr = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodPost, "/auth/local/login", nil).WithContext(t.Context())
+	mw(next).ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusTeapot, rr.Code)
+
+	// the token exchange is allowed
+	rr = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodPost, "/auth/token", nil).WithContext(t.Context())
+	mw(next).ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusTeapot, rr.Code)
+}
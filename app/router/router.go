@@ -3,45 +3,61 @@ package router
 import (
 	"log/slog"
 	"net/http"
+	"net/netip"
 	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
-	"github.com/google/martian/v3/cors"
 
+	"github.com/SecurityBrewery/catalyst/app/apiusage"
+	"github.com/SecurityBrewery/catalyst/app/applog"
 	"github.com/SecurityBrewery/catalyst/app/auth"
+	"github.com/SecurityBrewery/catalyst/app/backup"
 	"github.com/SecurityBrewery/catalyst/app/database/sqlc"
+	"github.com/SecurityBrewery/catalyst/app/federation"
 	"github.com/SecurityBrewery/catalyst/app/mail"
+	"github.com/SecurityBrewery/catalyst/app/plugin"
 	"github.com/SecurityBrewery/catalyst/app/service"
+	"github.com/SecurityBrewery/catalyst/app/slack"
 	"github.com/SecurityBrewery/catalyst/app/upload"
 )
 
-func New(service *service.Service, queries *sqlc.Queries, uploader *upload.Uploader, mailer *mail.Mailer) (*chi.Mux, error) {
+func New(service *service.Service, queries *sqlc.Queries, uploader *upload.Uploader, mailer *mail.Mailer, plugins *plugin.Manager, slackApp *slack.Slack, fed *federation.Federation, logs *applog.Log, backups *backup.Manager, usage *apiusage.Counter, readOnly bool, trustedProxies []netip.Prefix) (*chi.Mux, error) {
 	r := chi.NewRouter()
 
 	// middleware for the router
-	r.Use(func(next http.Handler) http.Handler {
-		return http.Handler(cors.NewHandler(next))
-	})
+	r.Use(corsPolicy(queries))
 	r.Use(demoMode(queries))
+	r.Use(readOnlyMode(readOnly))
 	r.Use(middleware.RequestID)
+	r.Use(socketPeer)
 	r.Use(middleware.RealIP)
 	r.Use(middleware.Logger)
 	r.Use(middleware.Timeout(time.Second * 60))
 	r.Use(middleware.Recoverer)
+	r.Use(middleware.Compress(5))
+	r.Use(resolveTicketKeys(queries))
 
 	// base routes
 	r.Get("/", func(w http.ResponseWriter, r *http.Request) {
 		http.Redirect(w, r, "/ui/", http.StatusFound)
 	})
+	r.Get("/ui/custom.css", customCSS(queries))
 	r.Get("/ui/*", staticFiles)
 	r.Get("/health", healthHandler(queries))
 
 	// auth routes
 	r.Mount("/auth", auth.Server(queries, mailer))
 
+	// integration routes, authenticated by request signatures
+	r.Mount("/integrations/slack", slackApp.Routes())
+	r.Mount(federation.BasePath, fed.Routes())
+
 	// API routes
-	r.With(auth.Middleware(queries)).Mount("/api", http.StripPrefix("/api", service))
+	r.With(auth.Middleware(queries), auth.RequireScopes([]string{auth.SettingsReadPermission}), apiQuota(usage)).Handle("/api/logs/tail", logTail(queries, logs))
+	r.With(auth.Middleware(queries), auth.RequireScopes([]string{auth.SettingsWritePermission}), apiQuota(usage)).Handle("/api/backup/jobs/{id}/progress", backupProgress(queries, backups))
+	r.With(auth.Middleware(queries), apiQuota(usage)).Mount("/api/ext", http.StripPrefix("/api/ext", plugins))
+	r.With(publicRateLimit(newRateLimiter(10, time.Hour), trustedProxies), auth.Middleware(queries), apiQuota(usage), recordActivity(queries), conditionalGet).Mount("/api", http.StripPrefix("/api", service))
 
 	uploadHandler, err := tusRoutes(queries, uploader)
 	if err != nil {
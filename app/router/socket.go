@@ -0,0 +1,66 @@
+package router
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+
+	"golang.org/x/net/websocket"
+
+	"github.com/SecurityBrewery/catalyst/app/auth"
+	"github.com/SecurityBrewery/catalyst/app/database/sqlc"
+	"github.com/SecurityBrewery/catalyst/app/settings"
+)
+
+var errOriginNotAllowed = errors.New("origin not allowed")
+
+// webSocketServer returns a WebSocket server for the handler. Browsers cannot
+// set the Authorization header of a WebSocket and pass a socket token in the
+// URL instead, which any site can do for them. The origin of those requests
+// must be the request host, the app URL or one of the CORS origins. Requests
+// with the token in the header are not sent by browsers and are not checked.
+func webSocketServer(queries *sqlc.Queries, handler websocket.Handler) websocket.Server {
+	return websocket.Server{
+		Handshake: func(_ *websocket.Config, r *http.Request) error {
+			if !auth.QueryCredentials(r.Context()) {
+				return nil
+			}
+
+			s, err := settings.Load(r.Context(), queries)
+			if err != nil {
+				slog.ErrorContext(r.Context(), "Failed to load settings", "error", err)
+
+				return err
+			}
+
+			if !webSocketOriginAllowed(r, s) {
+				return errOriginNotAllowed
+			}
+
+			return nil
+		},
+		Handler: handler,
+	}
+}
+
+func webSocketOriginAllowed(r *http.Request, s *settings.Settings) bool {
+	origin, err := url.Parse(r.Header.Get("Origin"))
+	if err != nil || origin.Host == "" {
+		return false
+	}
+
+	if strings.EqualFold(origin.Host, r.Host) {
+		return true
+	}
+
+	if appURL, err := url.Parse(s.Meta.AppURL); err == nil && strings.EqualFold(origin.Scheme+"://"+origin.Host, appURL.Scheme+"://"+appURL.Host) {
+		return true
+	}
+
+	return slices.ContainsFunc(s.CORS.AllowedOrigins, func(allowed string) bool {
+		return matchOrigin(allowed, origin.Scheme+"://"+origin.Host)
+	})
+}
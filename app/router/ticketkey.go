@@ -0,0 +1,125 @@
+package router
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/SecurityBrewery/catalyst/app/database/sqlc"
+	"github.com/SecurityBrewery/catalyst/app/ticketkey"
+)
+
+// resolveTicketKeys replaces ticket keys like IR-2024-0042 with the ticket
+// IDs before the request is routed, so every API route accepts both forms.
+// Keys are resolved in the path segment after a "tickets" segment, in the
+// "ticket" query parameter, in the activity header and in the "ticket" field
+// of JSON objects that are sent to create or update records.
+func resolveTicketKeys(queries *sqlc.Queries) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			resolve := func(s string) string {
+				id, err := ticketkey.Resolve(r.Context(), queries, s)
+				if err != nil {
+					slog.ErrorContext(r.Context(), "Failed to resolve ticket key", "key", s, "error", err)
+
+					return s
+				}
+
+				return id
+			}
+
+			segments := strings.Split(r.URL.Path, "/")
+			for i := 1; i < len(segments); i++ {
+				if segments[i-1] == "tickets" && ticketkey.IsKey(segments[i]) {
+					segments[i] = resolve(segments[i])
+				}
+			}
+
+			if path := strings.Join(segments, "/"); path != r.URL.Path {
+				r.URL.Path = path
+				r.URL.RawPath = ""
+			}
+
+			if query := r.URL.Query(); ticketkey.IsKey(query.Get("ticket")) {
+				query.Set("ticket", resolve(query.Get("ticket")))
+				r.URL.RawQuery = query.Encode()
+			}
+
+			if ticket := r.Header.Get(ActivityHeader); ticketkey.IsKey(ticket) {
+				r.Header.Set(ActivityHeader, resolve(ticket))
+			}
+
+			if err := resolveBodyTicketKey(r, resolve); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// maxTicketKeyBody is the size up to which JSON bodies are searched for
+// ticket keys, larger bodies are imports and uploads that have none.
+const maxTicketKeyBody = 1 << 20
+
+// resolveBodyTicketKey replaces a ticket key in the "ticket" field of a JSON
+// object body with the ticket ID.
+func resolveBodyTicketKey(r *http.Request, resolve func(string) string) error {
+	if r.Method != http.MethodPost && r.Method != http.MethodPut && r.Method != http.MethodPatch || r.Body == nil {
+		return nil
+	}
+
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType != "application/json" {
+		return nil
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxTicketKeyBody+1))
+	if err != nil {
+		return err
+	}
+
+	if len(body) > maxTicketKeyBody {
+		r.Body = readCloser{Reader: io.MultiReader(bytes.NewReader(body), r.Body), Closer: r.Body}
+
+		return nil
+	}
+
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(body, &object); err != nil {
+		return nil //nolint:nilerr // the handlers reject invalid bodies
+	}
+
+	var ticket string
+	if err := json.Unmarshal(object["ticket"], &ticket); err != nil || !ticketkey.IsKey(ticket) {
+		return nil //nolint:nilerr // not a ticket key
+	}
+
+	object["ticket"], err = json.Marshal(resolve(ticket))
+	if err != nil {
+		return err
+	}
+
+	body, err = json.Marshal(object)
+	if err != nil {
+		return err
+	}
+
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	r.ContentLength = int64(len(body))
+	r.Header.Del("Content-Length")
+
+	return nil
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
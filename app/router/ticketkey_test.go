@@ -0,0 +1,81 @@
+package router
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/SecurityBrewery/catalyst/app/data"
+	"github.com/SecurityBrewery/catalyst/app/database/sqlc"
+	"github.com/SecurityBrewery/catalyst/app/pointer"
+)
+
+func Test_resolveTicketKeys(t *testing.T) {
+	t.Parallel()
+
+	queries := data.NewTestDB(t, t.TempDir())
+
+	typ, err := queries.CreateType(t.Context(), sqlc.CreateTypeParams{Singular: "Incident Response", Plural: "Incident Responses", Schema: []byte(`{}`), KeyPrefix: pointer.Pointer("IR")})
+	require.NoError(t, err)
+
+	ticket, err := queries.CreateTicket(t.Context(), sqlc.CreateTicketParams{Name: "Ransomware", Open: true, Type: typ.ID, Schema: []byte(`{}`), State: []byte(`{}`)})
+	require.NoError(t, err)
+
+	row, err := queries.Ticket(t.Context(), ticket.ID)
+	require.NoError(t, err)
+
+	key := pointer.Dereference(row.Key)
+	require.NotEmpty(t, key)
+
+	api := chi.NewRouter()
+	api.Get("/tickets/{id}/comments", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(chi.URLParam(r, "id") + " " + r.URL.Query().Get("ticket") + " " + r.Header.Get(ActivityHeader)))
+	})
+
+	api.Post("/comments", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(w, r.Body)
+	})
+
+	r := chi.NewRouter()
+	r.Use(resolveTicketKeys(queries))
+	r.Mount("/api", http.StripPrefix("/api", api))
+
+	request := func(target, header string) string {
+		req := httptest.NewRequest(http.MethodGet, target, nil).WithContext(t.Context())
+		req.Header.Set(ActivityHeader, header)
+
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		return rr.Body.String()
+	}
+
+	assert.Equal(t, ticket.ID+" "+ticket.ID+" "+ticket.ID, request("/api/tickets/"+key+"/comments?ticket="+key, key))
+	assert.Equal(t, ticket.ID+"  ", request("/api/tickets/"+ticket.ID+"/comments", ""))
+
+	// unknown keys are passed on unchanged
+	assert.Equal(t, "IR-1999-0001  ", request("/api/tickets/IR-1999-0001/comments", ""))
+
+	post := func(contentType, body string) string {
+		req := httptest.NewRequest(http.MethodPost, "/api/comments", strings.NewReader(body)).WithContext(t.Context())
+		req.Header.Set("Content-Type", contentType)
+
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		return rr.Body.String()
+	}
+
+	assert.JSONEq(t, `{"ticket":"`+ticket.ID+`","message":"IR-2024-0042"}`, post("application/json; charset=utf-8", `{"ticket":"`+key+`","message":"IR-2024-0042"}`))
+	assert.JSONEq(t, `{"ticket":"`+ticket.ID+`"}`, post("application/json", `{"ticket":"`+ticket.ID+`"}`))
+	assert.Equal(t, `{"ticket":"`+key+`"}`, post("text/plain", `{"ticket":"`+key+`"}`))
+	assert.Equal(t, `[{"ticket":"`+key+`"}]`, post("application/json", `[{"ticket":"`+key+`"}]`))
+}
@@ -11,19 +11,18 @@ import (
 	"github.com/go-chi/chi/v5"
 	"github.com/tus/tusd/v2/pkg/filelocker"
 	tusd "github.com/tus/tusd/v2/pkg/handler"
-	"github.com/tus/tusd/v2/pkg/rootstore"
 
 	"github.com/SecurityBrewery/catalyst/app/auth"
+	"github.com/SecurityBrewery/catalyst/app/custody"
 	"github.com/SecurityBrewery/catalyst/app/database"
 	"github.com/SecurityBrewery/catalyst/app/database/sqlc"
 	"github.com/SecurityBrewery/catalyst/app/upload"
 )
 
 func tusRoutes(queries *sqlc.Queries, u *upload.Uploader) (http.Handler, error) {
-	store := rootstore.New(u.Root)
 	locker := filelocker.New(u.Root.Name())
 	composer := tusd.NewStoreComposer()
-	store.UseIn(composer)
+	u.UseIn(composer)
 	locker.UseIn(composer)
 
 	// Create a new HTTP handler for the tusd server by providing a configuration.
@@ -62,17 +61,42 @@ func tusRoutes(queries *sqlc.Queries, u *upload.Uploader) (http.Handler, error)
 				filename = hook.Upload.ID
 			}
 
-			_, err := queries.InsertFile(hook.Context, sqlc.InsertFileParams{
+			// the upload is in the storage once it is finished
+			content, _, err := u.Storage.Open(hook.Context, hook.Upload.Storage["Path"])
+			if err != nil {
+				return tusd.HTTPResponse{}, fmt.Errorf("failed to open uploaded file: %w", err)
+			}
+
+			hashes, err := upload.Hash(content)
+			content.Close()
+
+			if err != nil {
+				return tusd.HTTPResponse{}, err
+			}
+
+			file, err := queries.InsertFile(hook.Context, sqlc.InsertFileParams{
 				ID:      hook.Upload.ID,
 				Name:    filename,
 				Blob:    path.Base(hook.Upload.Storage["Path"]),
 				Size:    float64(hook.Upload.Size),
 				Ticket:  hook.HTTPRequest.Header.Get("X-Ticket-ID"),
+				Md5:     hashes.MD5,
+				Sha1:    hashes.SHA1,
+				Sha256:  hashes.SHA256,
 				Created: time.Now().UTC(),
 				Updated: time.Now().UTC(),
 			})
+			if err != nil {
+				return tusd.HTTPResponse{}, err
+			}
+
+			content, _, err = u.Storage.Open(hook.Context, hook.Upload.Storage["Path"])
+			if err != nil {
+				return tusd.HTTPResponse{}, fmt.Errorf("failed to open uploaded file: %w", err)
+			}
+			defer content.Close()
 
-			return tusd.HTTPResponse{}, err
+			return tusd.HTTPResponse{}, custody.Record(hook.Context, queries, &file, custody.Upload, content)
 		},
 	})
 	if err != nil {
@@ -0,0 +1,404 @@
+// Package s3 reads and writes objects of S3 compatible buckets with path
+// style requests signed with AWS Signature Version 4, which S3 and most S3
+// compatible stores accept.
+package s3
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	amzDateFormat = "20060102T150405Z"
+	amzDayFormat  = "20060102"
+
+	// PartSize is the size of the parts of an upload. S3 needs at least
+	// 5 MiB for all parts but the last and allows at most 10000 parts, so
+	// objects can be up to about 160 GiB.
+	PartSize = 16 << 20
+	maxParts = 10000
+)
+
+var (
+	ErrTooLarge = errors.New("the object exceeds the maximum number of parts")
+	ErrNotFound = errors.New("the object does not exist")
+)
+
+// Config is the endpoint and the credentials of a bucket.
+type Config struct {
+	Endpoint        string
+	Region          string
+	AccessKeyID     string
+	SecretAccessKey string
+}
+
+// PutObject uploads the body as a single object.
+func PutObject(ctx context.Context, client *http.Client, config *Config, bucket, key, contentType string, body []byte, now time.Time) error {
+	resp, err := do(ctx, client, config, http.MethodPut, bucket, key, nil, body, contentType, now)
+	if err != nil {
+		return fmt.Errorf("failed to upload %s: %w", key, err)
+	}
+
+	return resp.Body.Close()
+}
+
+// GetObject returns the content and the size of an object, the caller must
+// close the content.
+func GetObject(ctx context.Context, client *http.Client, config *Config, bucket, key string, now time.Time) (io.ReadCloser, int64, error) {
+	resp, err := do(ctx, client, config, http.MethodGet, bucket, key, nil, nil, "", now)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to download %s: %w", key, err)
+	}
+
+	return resp.Body, resp.ContentLength, nil
+}
+
+// DeleteObject deletes an object. Deleting an object that does not exist
+// succeeds.
+func DeleteObject(ctx context.Context, client *http.Client, config *Config, bucket, key string, now time.Time) error {
+	resp, err := do(ctx, client, config, http.MethodDelete, bucket, key, nil, nil, "", now)
+	if err != nil {
+		return fmt.Errorf("failed to delete %s: %w", key, err)
+	}
+
+	return resp.Body.Close()
+}
+
+// Object is an object of a listing.
+type Object struct {
+	Key  string `xml:"Key"`
+	Size int64  `xml:"Size"`
+}
+
+// ListObjects lists the objects whose keys start with the prefix. The
+// objects are listed in pages of up to 1000 objects.
+func ListObjects(ctx context.Context, client *http.Client, config *Config, bucket, prefix string, now func() time.Time) ([]Object, error) {
+	var objects []Object
+
+	token := ""
+
+	for {
+		query := url.Values{"list-type": {"2"}, "prefix": {prefix}}
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+
+		resp, err := do(ctx, client, config, http.MethodGet, bucket, "", query, nil, "", now())
+		if err != nil {
+			return nil, fmt.Errorf("failed to list %s: %w", bucket, err)
+		}
+
+		var result struct {
+			Contents              []Object `xml:"Contents"`
+			IsTruncated           bool     `xml:"IsTruncated"`
+			NextContinuationToken string   `xml:"NextContinuationToken"`
+		}
+
+		err = xml.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to list %s: invalid response", bucket)
+		}
+
+		objects = append(objects, result.Contents...)
+
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			return objects, nil
+		}
+
+		token = result.NextContinuationToken
+	}
+}
+
+// Upload streams an object to a bucket in parts of PartSize, so that only
+// one part is held in memory. Close completes the upload, Abort discards
+// the uploaded parts.
+type Upload struct {
+	ctx         context.Context //nolint:containedctx
+	client      *http.Client
+	config      *Config
+	bucket, key string
+	now         func() time.Time
+
+	id    string
+	buf   []byte
+	parts []completedPart
+	size  int64
+}
+
+type completedPart struct {
+	PartNumber int    `xml:"PartNumber"`
+	ETag       string `xml:"ETag"`
+}
+
+// NewUpload starts a multipart upload.
+func NewUpload(ctx context.Context, client *http.Client, config *Config, bucket, key, contentType string, now func() time.Time) (*Upload, error) {
+	resp, err := do(ctx, client, config, http.MethodPost, bucket, key, url.Values{"uploads": {""}}, nil, contentType, now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to start upload of %s: %w", key, err)
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		UploadID string `xml:"UploadId"`
+	}
+
+	if err := xml.NewDecoder(resp.Body).Decode(&result); err != nil || result.UploadID == "" {
+		return nil, fmt.Errorf("failed to start upload of %s: invalid response", key)
+	}
+
+	return &Upload{
+		ctx:    ctx,
+		client: client,
+		config: config,
+		bucket: bucket,
+		key:    key,
+		now:    now,
+		id:     result.UploadID,
+		buf:    make([]byte, 0, PartSize),
+	}, nil
+}
+
+// Size returns the number of bytes written.
+func (u *Upload) Size() int64 {
+	return u.size
+}
+
+func (u *Upload) Write(p []byte) (int, error) {
+	written := 0
+
+	for len(p) > 0 {
+		n := min(len(p), PartSize-len(u.buf))
+		u.buf = append(u.buf, p[:n]...)
+		p = p[n:]
+		written += n
+
+		if len(u.buf) == PartSize {
+			if err := u.flush(); err != nil {
+				return written, err
+			}
+		}
+	}
+
+	u.size += int64(written)
+
+	return written, nil
+}
+
+func (u *Upload) flush() error {
+	if len(u.parts) == maxParts {
+		return ErrTooLarge
+	}
+
+	number := len(u.parts) + 1
+	query := url.Values{"partNumber": {strconv.Itoa(number)}, "uploadId": {u.id}}
+
+	resp, err := do(u.ctx, u.client, u.config, http.MethodPut, u.bucket, u.key, query, u.buf, "", u.now())
+	if err != nil {
+		return fmt.Errorf("failed to upload part %d of %s: %w", number, u.key, err)
+	}
+
+	if err := resp.Body.Close(); err != nil {
+		return err
+	}
+
+	u.parts = append(u.parts, completedPart{PartNumber: number, ETag: resp.Header.Get("ETag")})
+	u.buf = u.buf[:0]
+
+	return nil
+}
+
+// Close uploads the last part and completes the upload.
+func (u *Upload) Close() error {
+	if len(u.buf) > 0 || len(u.parts) == 0 {
+		if err := u.flush(); err != nil {
+			return err
+		}
+	}
+
+	body, err := xml.Marshal(struct {
+		XMLName xml.Name        `xml:"CompleteMultipartUpload"`
+		Parts   []completedPart `xml:"Part"`
+	}{Parts: u.parts})
+	if err != nil {
+		return err
+	}
+
+	resp, err := do(u.ctx, u.client, u.config, http.MethodPost, u.bucket, u.key, url.Values{"uploadId": {u.id}}, body, "application/xml", u.now())
+	if err != nil {
+		return fmt.Errorf("failed to complete upload of %s: %w", u.key, err)
+	}
+	defer resp.Body.Close()
+
+	// the completion can fail after the status is sent, with an error in
+	// the body
+	var result struct {
+		XMLName xml.Name
+		Message string `xml:"Message"`
+	}
+
+	if err := xml.NewDecoder(resp.Body).Decode(&result); err == nil && result.XMLName.Local == "Error" {
+		return fmt.Errorf("failed to complete upload of %s: %s", u.key, result.Message)
+	}
+
+	return nil
+}
+
+// Abort discards the uploaded parts. It uses ctx instead of the context of
+// the upload, which may be canceled already.
+func (u *Upload) Abort(ctx context.Context) error {
+	resp, err := do(ctx, u.client, u.config, http.MethodDelete, u.bucket, u.key, url.Values{"uploadId": {u.id}}, nil, "", u.now())
+	if err != nil {
+		return fmt.Errorf("failed to abort upload of %s: %w", u.key, err)
+	}
+
+	return resp.Body.Close()
+}
+
+// do sends a signed request and returns the response of a successful
+// request, whose body the caller must close.
+func do(ctx context.Context, client *http.Client, config *Config, method, bucket, key string, query url.Values, body []byte, contentType string, now time.Time) (*http.Response, error) {
+	// a request without a key is a request for the bucket
+	path := "/" + escapePath(bucket)
+	if key != "" {
+		path += "/" + escapePath(key)
+	}
+
+	target := strings.TrimSuffix(config.Endpoint, "/") + path
+	if len(query) > 0 {
+		target += "?" + canonicalQuery(query)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, target, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	sign(req, config, path, canonicalQuery(query), body, now)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		defer resp.Body.Close()
+
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+
+		if resp.StatusCode == http.StatusNotFound {
+			return nil, fmt.Errorf("%w: %s: %s", ErrNotFound, resp.Status, message)
+		}
+
+		return nil, fmt.Errorf("%s: %s", resp.Status, message)
+	}
+
+	return resp, nil
+}
+
+func sign(req *http.Request, config *Config, path, query string, body []byte, now time.Time) {
+	now = now.UTC()
+	amzDate := now.Format(amzDateFormat)
+	payloadHash := hashHex(body)
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	signedHeaders := "host;x-amz-content-sha256;x-amz-date"
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		path,
+		query,
+		"host:" + req.URL.Host,
+		"x-amz-content-sha256:" + payloadHash,
+		"x-amz-date:" + amzDate,
+		"",
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := now.Format(amzDayFormat) + "/" + config.Region + "/s3/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hashHex([]byte(canonicalRequest))
+
+	key := hmacSHA256([]byte("AWS4"+config.SecretAccessKey), now.Format(amzDayFormat))
+	key = hmacSHA256(key, config.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf(
+		"AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		config.AccessKeyID, scope, signedHeaders, signature,
+	))
+}
+
+// canonicalQuery sorts the parameters by name and percent-encodes them like
+// the path, including slashes.
+func canonicalQuery(query url.Values) string {
+	var params []string
+
+	for name, values := range query {
+		for _, value := range values {
+			params = append(params, escape(name, false)+"="+escape(value, false))
+		}
+	}
+
+	// names and values are escaped already, so a byte wise sort works
+	slices.Sort(params)
+
+	return strings.Join(params, "&")
+}
+
+func hashHex(data []byte) string {
+	sum := sha256.Sum256(data)
+
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+
+	return mac.Sum(nil)
+}
+
+// escapePath percent-encodes everything but unreserved characters and
+// slashes, as required for the canonical request.
+func escapePath(path string) string {
+	return escape(path, true)
+}
+
+func escape(s string, slash bool) string {
+	var b strings.Builder
+
+	for _, c := range []byte(s) {
+		switch {
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9',
+			c == '-', c == '_', c == '.', c == '~', slash && c == '/':
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+
+	return b.String()
+}
@@ -0,0 +1,291 @@
+package s3
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeS3 implements the object, listing and multipart upload requests of
+// S3. Listings have pages of pageSize objects.
+type fakeS3 struct {
+	mu       sync.Mutex
+	objects  map[string][]byte
+	uploads  map[string]map[int][]byte
+	aborted  []string
+	pageSize int
+}
+
+func newFakeS3(t *testing.T) (*fakeS3, *Config) {
+	t.Helper()
+
+	f := &fakeS3{objects: map[string][]byte{}, uploads: map[string]map[int][]byte{}, pageSize: 1000}
+
+	server := httptest.NewServer(f)
+	t.Cleanup(server.Close)
+
+	return f, &Config{Endpoint: server.URL, Region: "eu-central-1", AccessKeyID: "AKIDEXAMPLE", SecretAccessKey: "secret"}
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+
+	if sum := sha256.Sum256(body); r.Header.Get("X-Amz-Content-Sha256") != hex.EncodeToString(sum[:]) ||
+		!strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/") {
+		w.WriteHeader(http.StatusForbidden)
+
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	query := r.URL.Query()
+	key := r.URL.EscapedPath()
+
+	switch {
+	case r.Method == http.MethodGet && query.Get("list-type") == "2":
+		f.list(w, key, query)
+	case r.Method == http.MethodGet:
+		object, ok := f.objects[key]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+
+			return
+		}
+
+		_, _ = w.Write(object)
+	case r.Method == http.MethodDelete && !query.Has("uploadId"):
+		delete(f.objects, key)
+
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodPut && !query.Has("uploadId"):
+		f.objects[key] = body
+	case r.Method == http.MethodPost && query.Has("uploads"):
+		id := fmt.Sprintf("upload-%d", len(f.uploads)+1)
+		f.uploads[id] = map[int][]byte{}
+
+		_, _ = fmt.Fprintf(w, "<InitiateMultipartUploadResult><UploadId>%s</UploadId></InitiateMultipartUploadResult>", id)
+	case r.Method == http.MethodPut:
+		number, _ := strconv.Atoi(query.Get("partNumber"))
+		f.uploads[query.Get("uploadId")][number] = body
+
+		w.Header().Set("ETag", fmt.Sprintf(`"etag-%d"`, number))
+	case r.Method == http.MethodPost:
+		var complete struct {
+			Parts []completedPart `xml:"Part"`
+		}
+
+		if err := xml.Unmarshal(body, &complete); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+
+			return
+		}
+
+		var object []byte
+
+		parts := f.uploads[query.Get("uploadId")]
+		for _, part := range complete.Parts {
+			if part.ETag != fmt.Sprintf(`"etag-%d"`, part.PartNumber) {
+				_, _ = io.WriteString(w, "<Error><Message>invalid part</Message></Error>")
+
+				return
+			}
+
+			object = append(object, parts[part.PartNumber]...)
+		}
+
+		f.objects[key] = object
+		delete(f.uploads, query.Get("uploadId"))
+
+		_, _ = io.WriteString(w, "<CompleteMultipartUploadResult></CompleteMultipartUploadResult>")
+	case r.Method == http.MethodDelete:
+		f.aborted = append(f.aborted, query.Get("uploadId"))
+		delete(f.uploads, query.Get("uploadId"))
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func (f *fakeS3) list(w http.ResponseWriter, bucket string, query url.Values) {
+	var keys []string
+
+	for name := range f.objects {
+		key, _ := url.PathUnescape(strings.TrimPrefix(name, bucket+"/"))
+		if strings.HasPrefix(name, bucket+"/") && strings.HasPrefix(key, query.Get("prefix")) {
+			keys = append(keys, key)
+		}
+	}
+
+	slices.Sort(keys)
+
+	// the continuation token is the last key of the previous page
+	start := 0
+	if token := query.Get("continuation-token"); token != "" {
+		start, _ = slices.BinarySearch(keys, token)
+		start++
+	}
+
+	end := min(start+f.pageSize, len(keys))
+
+	_, _ = io.WriteString(w, "<ListBucketResult>")
+
+	for _, key := range keys[start:end] {
+		_, _ = fmt.Fprintf(w, "<Contents><Key>%s</Key><Size>%d</Size></Contents>", key, len(f.objects[bucket+"/"+escapePath(key)]))
+	}
+
+	if end < len(keys) {
+		_, _ = fmt.Fprintf(w, "<IsTruncated>true</IsTruncated><NextContinuationToken>%s</NextContinuationToken>", keys[end-1])
+	}
+
+	_, _ = io.WriteString(w, "</ListBucketResult>")
+}
+
+func TestPutObject(t *testing.T) {
+	t.Parallel()
+
+	f, config := newFakeS3(t)
+
+	require.NoError(t, PutObject(t.Context(), http.DefaultClient, config, "bucket", "a/file name.csv", "text/csv", []byte("a,b"), time.Now()))
+	assert.Equal(t, "a,b", string(f.objects["/bucket/a/file%20name.csv"]))
+
+	config.AccessKeyID = "wrong"
+
+	err := PutObject(t.Context(), http.DefaultClient, config, "bucket", "b.csv", "text/csv", nil, time.Now())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "403 Forbidden")
+}
+
+func TestGetObject(t *testing.T) {
+	t.Parallel()
+
+	_, config := newFakeS3(t)
+
+	require.NoError(t, PutObject(t.Context(), http.DefaultClient, config, "bucket", "a/file.txt", "text/plain", []byte("content"), time.Now()))
+
+	content, size, err := GetObject(t.Context(), http.DefaultClient, config, "bucket", "a/file.txt", time.Now())
+	require.NoError(t, err)
+
+	defer content.Close()
+
+	data, err := io.ReadAll(content)
+	require.NoError(t, err)
+
+	assert.Equal(t, "content", string(data))
+	assert.Equal(t, int64(7), size)
+
+	_, _, err = GetObject(t.Context(), http.DefaultClient, config, "bucket", "missing.txt", time.Now())
+	require.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestDeleteObject(t *testing.T) {
+	t.Parallel()
+
+	f, config := newFakeS3(t)
+
+	require.NoError(t, PutObject(t.Context(), http.DefaultClient, config, "bucket", "a.txt", "text/plain", []byte("a"), time.Now()))
+	require.NoError(t, DeleteObject(t.Context(), http.DefaultClient, config, "bucket", "a.txt", time.Now()))
+	assert.Empty(t, f.objects)
+
+	require.NoError(t, DeleteObject(t.Context(), http.DefaultClient, config, "bucket", "a.txt", time.Now()))
+}
+
+func TestListObjects(t *testing.T) {
+	t.Parallel()
+
+	f, config := newFakeS3(t)
+	f.pageSize = 2
+
+	for _, key := range []string{"uploads/a.info", "uploads/a/file name.txt", "uploads/b.info", "uploads/b/c.txt", "other/d.txt"} {
+		require.NoError(t, PutObject(t.Context(), http.DefaultClient, config, "bucket", key, "", []byte(key), time.Now()))
+	}
+
+	objects, err := ListObjects(t.Context(), http.DefaultClient, config, "bucket", "uploads/", time.Now)
+	require.NoError(t, err)
+
+	assert.Equal(t, []Object{
+		{Key: "uploads/a.info", Size: 14},
+		{Key: "uploads/a/file name.txt", Size: 23},
+		{Key: "uploads/b.info", Size: 14},
+		{Key: "uploads/b/c.txt", Size: 15},
+	}, objects)
+}
+
+func TestUpload(t *testing.T) {
+	t.Parallel()
+
+	f, config := newFakeS3(t)
+
+	u, err := NewUpload(t.Context(), http.DefaultClient, config, "bucket", "backups/large.zip", "application/zip", time.Now)
+	require.NoError(t, err)
+
+	data := bytes.Repeat([]byte("0123456789abcdef"), PartSize/16+100)
+
+	// written in uneven chunks across the part boundary
+	_, err = io.CopyBuffer(u, bytes.NewReader(data), make([]byte, 1000003))
+	require.NoError(t, err)
+	require.NoError(t, u.Close())
+
+	assert.Equal(t, int64(len(data)), u.Size())
+	assert.Equal(t, data, f.objects["/bucket/backups/large.zip"])
+	assert.Empty(t, f.uploads)
+}
+
+func TestUpload_empty(t *testing.T) {
+	t.Parallel()
+
+	f, config := newFakeS3(t)
+
+	u, err := NewUpload(t.Context(), http.DefaultClient, config, "bucket", "empty", "", time.Now)
+	require.NoError(t, err)
+	require.NoError(t, u.Close())
+
+	assert.Contains(t, f.objects, "/bucket/empty")
+	assert.Empty(t, f.objects["/bucket/empty"])
+}
+
+func TestUpload_Abort(t *testing.T) {
+	t.Parallel()
+
+	f, config := newFakeS3(t)
+
+	u, err := NewUpload(t.Context(), http.DefaultClient, config, "bucket", "aborted", "", time.Now)
+	require.NoError(t, err)
+
+	_, err = u.Write([]byte("partial"))
+	require.NoError(t, err)
+	require.NoError(t, u.Abort(t.Context()))
+
+	assert.Equal(t, []string{"upload-1"}, f.aborted)
+	assert.NotContains(t, f.objects, "/bucket/aborted")
+}
+
+func Test_escapePath(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "a/date%3D2025-03-01/file%20name_~.csv", escapePath("a/date=2025-03-01/file name_~.csv"))
+}
+
+func Test_canonicalQuery(t *testing.T) {
+	t.Parallel()
+
+	assert.Empty(t, canonicalQuery(nil))
+	assert.Equal(t, "uploads=", canonicalQuery(url.Values{"uploads": {""}}))
+	assert.Equal(t, "partNumber=2&uploadId=a%2Fb%3D", canonicalQuery(url.Values{"uploadId": {"a/b="}, "partNumber": {"2"}}))
+	assert.True(t, slices.IsSorted(strings.Split(canonicalQuery(url.Values{"b": {"1"}, "a": {"2", "1"}}), "&")))
+}
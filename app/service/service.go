@@ -1,28 +1,86 @@
 package service
 
 import (
+	"bytes"
+	"cmp"
 	"context"
+	"database/sql"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"log/slog"
+	"maps"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 	"time"
 
+	"github.com/getkin/kin-openapi/openapi3"
+
+	"github.com/SecurityBrewery/catalyst/app/anomaly"
+	"github.com/SecurityBrewery/catalyst/app/apiusage"
+	"github.com/SecurityBrewery/catalyst/app/applog"
+	"github.com/SecurityBrewery/catalyst/app/artifact"
+	"github.com/SecurityBrewery/catalyst/app/attack"
 	"github.com/SecurityBrewery/catalyst/app/auth"
 	"github.com/SecurityBrewery/catalyst/app/auth/password"
+	"github.com/SecurityBrewery/catalyst/app/auth/usercontext"
+	"github.com/SecurityBrewery/catalyst/app/autoclose"
+	"github.com/SecurityBrewery/catalyst/app/backup"
+	"github.com/SecurityBrewery/catalyst/app/billing"
+	"github.com/SecurityBrewery/catalyst/app/bundle"
+	"github.com/SecurityBrewery/catalyst/app/campaign"
+	"github.com/SecurityBrewery/catalyst/app/canonical"
+	"github.com/SecurityBrewery/catalyst/app/casekey"
+	"github.com/SecurityBrewery/catalyst/app/custody"
+	"github.com/SecurityBrewery/catalyst/app/cve"
 	"github.com/SecurityBrewery/catalyst/app/database"
 	"github.com/SecurityBrewery/catalyst/app/database/sqlc"
+	"github.com/SecurityBrewery/catalyst/app/deactivation"
+	"github.com/SecurityBrewery/catalyst/app/detection"
+	"github.com/SecurityBrewery/catalyst/app/digest"
+	"github.com/SecurityBrewery/catalyst/app/dlp"
+	"github.com/SecurityBrewery/catalyst/app/docx"
+	"github.com/SecurityBrewery/catalyst/app/enrichment"
+	"github.com/SecurityBrewery/catalyst/app/export"
+	"github.com/SecurityBrewery/catalyst/app/expression"
+	"github.com/SecurityBrewery/catalyst/app/federation"
+	"github.com/SecurityBrewery/catalyst/app/feed"
 	"github.com/SecurityBrewery/catalyst/app/hook"
+	"github.com/SecurityBrewery/catalyst/app/invitation"
+	"github.com/SecurityBrewery/catalyst/app/mail"
+	"github.com/SecurityBrewery/catalyst/app/maintenance"
+	"github.com/SecurityBrewery/catalyst/app/mapping"
+	"github.com/SecurityBrewery/catalyst/app/metric"
+	"github.com/SecurityBrewery/catalyst/app/migration"
+	"github.com/SecurityBrewery/catalyst/app/notification"
 	"github.com/SecurityBrewery/catalyst/app/openapi"
+	"github.com/SecurityBrewery/catalyst/app/platform"
+	"github.com/SecurityBrewery/catalyst/app/playbook"
+	"github.com/SecurityBrewery/catalyst/app/plugin"
 	"github.com/SecurityBrewery/catalyst/app/pointer"
+	"github.com/SecurityBrewery/catalyst/app/push"
+	"github.com/SecurityBrewery/catalyst/app/queue"
 	"github.com/SecurityBrewery/catalyst/app/reaction/schedule"
+	webhooktrigger "github.com/SecurityBrewery/catalyst/app/reaction/trigger/webhook"
+	"github.com/SecurityBrewery/catalyst/app/redact"
 	"github.com/SecurityBrewery/catalyst/app/settings"
+	"github.com/SecurityBrewery/catalyst/app/tasktimer"
+	"github.com/SecurityBrewery/catalyst/app/telemetry"
+	"github.com/SecurityBrewery/catalyst/app/ticketkey"
 	"github.com/SecurityBrewery/catalyst/app/upload"
+	"github.com/SecurityBrewery/catalyst/app/webhook"
 )
 
 const (
 	defaultLimit  = 100
 	defaultOffset = 0
+
+	redacted = "********"
 )
 
 var _ openapi.StrictServerInterface = (*Service)(nil)
@@ -32,14 +90,24 @@ type Service struct {
 	hooks     *hook.Hooks
 	uploader  *upload.Uploader
 	scheduler *schedule.Scheduler
+	plugins   *plugin.Manager
+	keyring   *casekey.Keyring
+	signer    *custody.Signer
+	backups   *backup.Manager
+	feeds     *feed.Loader
 }
 
-func New(queries *sqlc.Queries, hooks *hook.Hooks, uploader *upload.Uploader, scheduler *schedule.Scheduler) *Service {
+func New(queries *sqlc.Queries, hooks *hook.Hooks, uploader *upload.Uploader, scheduler *schedule.Scheduler, plugins *plugin.Manager, keyring *casekey.Keyring, signer *custody.Signer, backups *backup.Manager, feeds *feed.Loader) *Service {
 	return &Service{
 		queries:   queries,
 		hooks:     hooks,
 		uploader:  uploader,
 		scheduler: scheduler,
+		plugins:   plugins,
+		keyring:   keyring,
+		signer:    signer,
+		backups:   backups,
+		feeds:     feeds,
 	}
 }
 
@@ -53,13 +121,29 @@ func (s *Service) ListComments(ctx context.Context, request openapi.ListComments
 		return nil, err
 	}
 
+	keys := map[string][]byte{}
+
 	response := make([]openapi.ExtendedComment, 0, len(comments))
 	for _, comment := range comments {
+		key, ok := keys[comment.Ticket]
+		if !ok && casekey.IsEncrypted(comment.Message) {
+			if key, _, err = s.caseKey(ctx, comment.Ticket); err != nil {
+				return nil, err
+			}
+
+			keys[comment.Ticket] = key
+		}
+
+		message, err := decryptText(key, comment.Message)
+		if err != nil {
+			return nil, err
+		}
+
 		response = append(response, openapi.ExtendedComment{
 			Author:     comment.Author,
 			Created:    comment.Created,
 			Id:         comment.ID,
-			Message:    comment.Message,
+			Message:    message,
 			Ticket:     comment.Ticket,
 			Updated:    comment.Updated,
 			AuthorName: pointer.Dereference(comment.AuthorName),
@@ -84,9 +168,19 @@ func (s *Service) ListComments(ctx context.Context, request openapi.ListComments
 func (s *Service) CreateComment(ctx context.Context, request openapi.CreateCommentRequestObject) (openapi.CreateCommentResponseObject, error) {
 	s.hooks.OnRecordBeforeCreateRequest.Publish(ctx, database.CommentsTable.ID, request.Body)
 
+	plaintext, err := s.maskSecrets(ctx, request.Body.Message)
+	if err != nil {
+		return nil, err
+	}
+
+	message, err := s.encryptMessage(ctx, request.Body.Ticket, plaintext)
+	if err != nil {
+		return nil, err
+	}
+
 	comment, err := s.queries.CreateComment(ctx, sqlc.CreateCommentParams{
 		Author:  request.Body.Author,
-		Message: request.Body.Message,
+		Message: message,
 		Ticket:  request.Body.Ticket,
 	})
 	if err != nil {
@@ -97,12 +191,12 @@ func (s *Service) CreateComment(ctx context.Context, request openapi.CreateComme
 		Author:  comment.Author,
 		Created: comment.Created,
 		Id:      comment.ID,
-		Message: comment.Message,
+		Message: plaintext,
 		Ticket:  comment.Ticket,
 		Updated: comment.Updated,
 	}
 
-	s.hooks.OnRecordAfterCreateRequest.Publish(ctx, database.CommentsTable.ID, response)
+	s.hooks.OnRecordAfterCreateRequest.Publish(ctx, database.CommentsTable.ID, redactComment(response, casekey.IsEncrypted(comment.Message)))
 
 	return openapi.CreateComment200JSONResponse(response), nil
 }
@@ -126,12 +220,17 @@ func (s *Service) GetComment(ctx context.Context, request openapi.GetCommentRequ
 		return nil, err
 	}
 
+	message, err := s.decryptMessage(ctx, comment.Ticket, comment.Message)
+	if err != nil {
+		return nil, err
+	}
+
 	response := openapi.ExtendedComment{
 		Author:     comment.Author,
 		AuthorName: pointer.Dereference(comment.AuthorName),
 		Created:    comment.Created,
 		Id:         comment.ID,
-		Message:    comment.Message,
+		Message:    message,
 		Ticket:     comment.Ticket,
 		Updated:    comment.Updated,
 	}
@@ -144,24 +243,50 @@ func (s *Service) GetComment(ctx context.Context, request openapi.GetCommentRequ
 func (s *Service) UpdateComment(ctx context.Context, request openapi.UpdateCommentRequestObject) (openapi.UpdateCommentResponseObject, error) {
 	s.hooks.OnRecordBeforeUpdateRequest.Publish(ctx, database.CommentsTable.ID, request.Body)
 
+	message := request.Body.Message
+
+	if message != nil {
+		existing, err := s.queries.GetComment(ctx, request.Id)
+		if err != nil {
+			return nil, err
+		}
+
+		masked, err := s.maskSecrets(ctx, *message)
+		if err != nil {
+			return nil, err
+		}
+
+		encrypted, err := s.encryptMessage(ctx, existing.Ticket, masked)
+		if err != nil {
+			return nil, err
+		}
+
+		message = &encrypted
+	}
+
 	comment, err := s.queries.UpdateComment(ctx, sqlc.UpdateCommentParams{
-		Message: request.Body.Message,
+		Message: message,
 		ID:      request.Id,
 	})
 	if err != nil {
 		return nil, err
 	}
 
+	plaintext, err := s.decryptMessage(ctx, comment.Ticket, comment.Message)
+	if err != nil {
+		return nil, err
+	}
+
 	response := openapi.Comment{
 		Author:  comment.Author,
 		Created: comment.Created,
 		Id:      comment.ID,
-		Message: comment.Message,
+		Message: plaintext,
 		Ticket:  comment.Ticket,
 		Updated: comment.Updated,
 	}
 
-	s.hooks.OnRecordAfterUpdateRequest.Publish(ctx, database.CommentsTable.ID, response)
+	s.hooks.OnRecordAfterUpdateRequest.Publish(ctx, database.CommentsTable.ID, redactComment(response, casekey.IsEncrypted(comment.Message)))
 
 	return openapi.UpdateComment200JSONResponse(response), nil
 }
@@ -185,6 +310,207 @@ func (s *Service) GetDashboardCounts(ctx context.Context, _ openapi.GetDashboard
 	return openapi.GetDashboardCounts200JSONResponse(response), nil
 }
 
+// responseTimesWindow is the default period of the response time stats.
+const responseTimesWindow = 30 * 24 * time.Hour
+
+func (s *Service) GetResponseTimes(ctx context.Context, request openapi.GetResponseTimesRequestObject) (openapi.GetResponseTimesResponseObject, error) {
+	since := time.Now().Add(-responseTimesWindow)
+	if request.Params.Since != nil {
+		since = *request.Params.Since
+	}
+
+	times, err := s.queries.GetResponseTimes(ctx, sqlc.GetResponseTimesParams{
+		Type:  request.Params.Type,
+		Since: since.UTC().Format(time.DateTime),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return openapi.GetResponseTimes200JSONResponse{
+		Tickets:      int(times.Tickets),
+		Acknowledged: int(times.Acknowledged),
+		Resolved:     int(times.Resolved),
+		Mtta:         float32(times.Mtta),
+		Mttr:         float32(times.Mttr),
+		Reopened:     int(times.Reopened),
+		Reopens:      int(times.Reopens),
+	}, nil
+}
+
+// GetEffort sums the finished work on tickets, by ticket and by analyst.
+func (s *Service) GetEffort(ctx context.Context, request openapi.GetEffortRequestObject) (openapi.GetEffortResponseObject, error) {
+	until := time.Now()
+	if request.Params.Until != nil {
+		until = *request.Params.Until
+	}
+
+	since := until.Add(-responseTimesWindow)
+	if request.Params.Since != nil {
+		since = *request.Params.Since
+	}
+
+	tickets, err := s.queries.ListTicketEffort(ctx, sqlc.ListTicketEffortParams{
+		Type:  request.Params.Type,
+		Since: since.UTC().Format(time.DateTime),
+		Until: until.UTC().Format(time.DateTime),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	analysts, err := s.queries.ListAnalystEffort(ctx, sqlc.ListAnalystEffortParams{
+		Type:  request.Params.Type,
+		Since: since.UTC().Format(time.DateTime),
+		Until: until.UTC().Format(time.DateTime),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := openapi.GetEffort200JSONResponse{
+		Tickets:  make([]openapi.TicketEffort, 0, len(tickets)),
+		Analysts: make([]openapi.AnalystEffort, 0, len(analysts)),
+	}
+
+	for _, ticket := range tickets {
+		response.Tickets = append(response.Tickets, openapi.TicketEffort{
+			Ticket:   ticket.ID,
+			Name:     ticket.Name,
+			Type:     ticket.Type,
+			Duration: int(ticket.Duration),
+			Analysts: int(ticket.Analysts),
+		})
+	}
+
+	for _, analyst := range analysts {
+		response.Analysts = append(response.Analysts, openapi.AnalystEffort{
+			User:     analyst.User,
+			UserName: analyst.UserName,
+			Duration: int(analyst.Duration),
+			Tickets:  int(analyst.Tickets),
+		})
+	}
+
+	return response, nil
+}
+
+// metricWindow is the default period of the metric series.
+const metricWindow = 90 * 24 * time.Hour
+
+// GetMetricSeries counts the tickets of a type per value of an enum metric,
+// or sums a numeric metric, in buckets of a day, a week or a month.
+func (s *Service) GetMetricSeries(ctx context.Context, request openapi.GetMetricSeriesRequestObject) (openapi.GetMetricSeriesResponseObject, error) {
+	interval := metric.IntervalWeek
+	if request.Params.Interval != nil {
+		interval = string(*request.Params.Interval)
+	}
+
+	if !slices.Contains(metric.Intervals, interval) {
+		return openapi.GetMetricSeries400JSONResponse{
+			Status:  http.StatusBadRequest,
+			Error:   "Bad Request",
+			Message: fmt.Sprintf("unknown interval %q, expected one of %s", interval, strings.Join(metric.Intervals, ", ")),
+		}, nil
+	}
+
+	t, err := s.queries.GetType(ctx, request.Type)
+	if err != nil {
+		return nil, err
+	}
+
+	if !slices.Contains(unmarshalMetrics(t.Metrics), request.Field) {
+		return openapi.GetMetricSeries400JSONResponse{
+			Status:  http.StatusBadRequest,
+			Error:   "Bad Request",
+			Message: fmt.Sprintf("the field %q is not a metric of the type %s", request.Field, t.ID),
+		}, nil
+	}
+
+	kind, err := metric.Kind(t.Schema, request.Field)
+	if err != nil {
+		return openapi.GetMetricSeries400JSONResponse{
+			Status:  http.StatusBadRequest,
+			Error:   "Bad Request",
+			Message: err.Error(),
+		}, nil
+	}
+
+	until := time.Now()
+	if request.Params.Until != nil {
+		until = *request.Params.Until
+	}
+
+	since := until.Add(-metricWindow)
+	if request.Params.Since != nil {
+		since = *request.Params.Since
+	}
+
+	response := openapi.GetMetricSeries200JSONResponse{
+		Type:     t.ID,
+		Field:    request.Field,
+		Kind:     openapi.MetricSeriesKind(kind),
+		Interval: interval,
+		Buckets:  []openapi.MetricBucket{},
+	}
+
+	if kind == metric.KindEnum {
+		rows, err := s.queries.ListTicketMetricValues(ctx, sqlc.ListTicketMetricValuesParams{
+			Interval: interval,
+			Path:     metric.Path(request.Field),
+			Type:     t.ID,
+			Since:    since.UTC().Format(time.DateTime),
+			Until:    until.UTC().Format(time.DateTime),
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		// the rows are sorted by bucket, one row per value
+		for _, row := range rows {
+			if len(response.Buckets) == 0 || response.Buckets[len(response.Buckets)-1].Start != row.Bucket {
+				response.Buckets = append(response.Buckets, openapi.MetricBucket{Start: row.Bucket, Values: &map[string]int{}})
+			}
+
+			bucket := &response.Buckets[len(response.Buckets)-1]
+			bucket.Tickets += int(row.Tickets)
+			(*bucket.Values)[row.Value] = int(row.Tickets)
+		}
+
+		return response, nil
+	}
+
+	rows, err := s.queries.ListTicketMetricNumbers(ctx, sqlc.ListTicketMetricNumbersParams{
+		Interval: interval,
+		Path:     metric.Path(request.Field),
+		Type:     t.ID,
+		Since:    since.UTC().Format(time.DateTime),
+		Until:    until.UTC().Format(time.DateTime),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		bucket := openapi.MetricBucket{
+			Start:   row.Bucket,
+			Tickets: int(row.Tickets),
+			Count:   pointer.Pointer(int(row.Count)),
+			Sum:     pointer.Pointer(float32(row.Sum)),
+		}
+
+		if row.Count > 0 {
+			bucket.Avg = pointer.Pointer(float32(row.Sum / float64(row.Count)))
+			bucket.Min = pointer.Pointer(float32(row.Min))
+			bucket.Max = pointer.Pointer(float32(row.Max))
+		}
+
+		response.Buckets = append(response.Buckets, bucket)
+	}
+
+	return response, nil
+}
+
 func (s *Service) ListFiles(ctx context.Context, request openapi.ListFilesRequestObject) (openapi.ListFilesResponseObject, error) {
 	files, err := s.queries.ListFiles(ctx, sqlc.ListFilesParams{
 		Ticket: toString(request.Params.Ticket, ""),
@@ -202,6 +528,9 @@ func (s *Service) ListFiles(ctx context.Context, request openapi.ListFilesReques
 			Id:      file.ID,
 			Name:    file.Name,
 			Size:    file.Size,
+			Md5:     file.Md5,
+			Sha1:    file.Sha1,
+			Sha256:  file.Sha256,
 			Ticket:  file.Ticket,
 			Updated: file.Updated,
 		})
@@ -227,7 +556,12 @@ func (s *Service) CreateFile(ctx context.Context, request openapi.CreateFileRequ
 
 	id := database.GenerateID("b")
 
-	uniqName, err := s.uploader.CreateFile(id, request.Body.Name, []byte(request.Body.Blob))
+	uniqName, err := s.uploader.CreateFile(ctx, id, request.Body.Name, []byte(request.Body.Blob))
+	if err != nil {
+		return nil, err
+	}
+
+	hashes, err := upload.Hash(strings.NewReader(request.Body.Blob))
 	if err != nil {
 		return nil, err
 	}
@@ -238,6 +572,9 @@ func (s *Service) CreateFile(ctx context.Context, request openapi.CreateFileRequ
 		Blob:    uniqName,
 		Size:    float64(len(request.Body.Blob)),
 		Ticket:  request.Body.Ticket,
+		Md5:     hashes.MD5,
+		Sha1:    hashes.SHA1,
+		Sha256:  hashes.SHA256,
 		Created: time.Now().UTC(),
 		Updated: time.Now().UTC(),
 	})
@@ -245,6 +582,10 @@ func (s *Service) CreateFile(ctx context.Context, request openapi.CreateFileRequ
 		return nil, err
 	}
 
+	if err := custody.Record(ctx, s.queries, &file, custody.Upload, strings.NewReader(request.Body.Blob)); err != nil {
+		return nil, err
+	}
+
 	s.hooks.OnRecordAfterCreateRequest.Publish(ctx, database.FilesTable.ID, file)
 
 	return openapi.CreateFile200JSONResponse(openapi.File{
@@ -252,12 +593,22 @@ func (s *Service) CreateFile(ctx context.Context, request openapi.CreateFileRequ
 		Id:      file.ID,
 		Name:    file.Name,
 		Size:    file.Size,
+		Md5:     file.Md5,
+		Sha1:    file.Sha1,
+		Sha256:  file.Sha256,
 		Ticket:  file.Ticket,
 		Updated: file.Updated,
 	}), nil
 }
 
 func (s *Service) DeleteFile(ctx context.Context, request openapi.DeleteFileRequestObject) (openapi.DeleteFileResponseObject, error) {
+	hold, err := s.queries.GetLegalHold(ctx, request.Id)
+	if err == nil {
+		return openapi.DeleteFile403JSONResponse(legalHoldError("The file is under legal hold", &hold)), nil
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
 	s.hooks.OnRecordBeforeDeleteRequest.Publish(ctx, database.FilesTable.ID, request.Id)
 
 	f, err := s.queries.GetFile(ctx, request.Id)
@@ -265,7 +616,19 @@ func (s *Service) DeleteFile(ctx context.Context, request openapi.DeleteFileRequ
 		return nil, err
 	}
 
-	if err := s.uploader.DeleteFile(f.ID, f.Blob); err != nil {
+	content, _, _, err := s.uploader.File(ctx, f.ID, f.Blob)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get file from uploader: %w", err)
+	}
+
+	err = custody.Record(ctx, s.queries, &f, custody.Delete, content)
+	content.Close()
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.uploader.DeleteFile(ctx, f.ID, f.Blob); err != nil {
 		return nil, fmt.Errorf("failed to delete file from uploader: %w", err)
 	}
 
@@ -289,6 +652,9 @@ func (s *Service) GetFile(ctx context.Context, request openapi.GetFileRequestObj
 		Id:      file.ID,
 		Name:    file.Name,
 		Size:    file.Size,
+		Md5:     file.Md5,
+		Sha1:    file.Sha1,
+		Sha256:  file.Sha256,
 		Ticket:  file.Ticket,
 		Updated: file.Updated,
 	}
@@ -298,319 +664,422 @@ func (s *Service) GetFile(ctx context.Context, request openapi.GetFileRequestObj
 	return openapi.GetFile200JSONResponse(response), nil
 }
 
-func (s *Service) ListLinks(ctx context.Context, request openapi.ListLinksRequestObject) (openapi.ListLinksResponseObject, error) {
-	links, err := s.queries.ListLinks(ctx, sqlc.ListLinksParams{
-		Ticket: toString(request.Params.Ticket, ""),
-		Offset: toInt64(request.Params.Offset, defaultOffset),
-		Limit:  toInt64(request.Params.Limit, defaultLimit),
-	})
+func (s *Service) GetTicketCustody(ctx context.Context, request openapi.GetTicketCustodyRequestObject) (openapi.GetTicketCustodyResponseObject, error) {
+	user, ok := usercontext.UserFromContext(ctx)
+	if !ok {
+		return nil, errors.New("missing user")
+	}
+
+	ticket, err := s.queries.Ticket(ctx, request.Id)
 	if err != nil {
 		return nil, err
 	}
 
-	response := make([]openapi.Link, 0, len(links))
-	for _, link := range links {
-		response = append(response, openapi.Link{
-			Id:      link.ID,
-			Name:    link.Name,
-			Created: link.Created,
-			Updated: link.Updated,
-			Url:     link.Url,
-			Ticket:  link.Ticket,
-		})
+	events, err := s.queries.ListCustodyEvents(ctx, ticket.ID)
+	if err != nil {
+		return nil, err
 	}
 
-	s.hooks.OnRecordsListRequest.Publish(ctx, database.LinksTable.ID, response)
-
-	totalCount := 0
-	if len(links) > 0 {
-		totalCount = int(links[0].TotalCount)
-	}
+	document := custody.Document(&ticket, events, pointer.Dereference(user.Name)+" ("+user.Username+")", time.Now())
 
-	return openapi.ListLinks200JSONResponse{
-		Body: response,
-		Headers: openapi.ListLinks200ResponseHeaders{
-			XTotalCount: totalCount,
+	return openapi.GetTicketCustody200ApplicationpdfResponse{
+		Body:          bytes.NewReader(document),
+		ContentLength: int64(len(document)),
+		Headers: openapi.GetTicketCustody200ResponseHeaders{
+			ContentDisposition: "attachment; filename=\"chain-of-custody-" + ticket.ID + ".pdf\"",
+			XSignature:         s.signer.Sign(document),
 		},
 	}, nil
 }
 
-func (s *Service) DownloadFile(ctx context.Context, request openapi.DownloadFileRequestObject) (openapi.DownloadFileResponseObject, error) {
-	file, err := s.queries.GetFile(ctx, request.Id)
-	if err != nil {
-		return nil, err
+func (s *Service) GetCustodyKey(_ context.Context, _ openapi.GetCustodyKeyRequestObject) (openapi.GetCustodyKeyResponseObject, error) {
+	return openapi.GetCustodyKey200JSONResponse{
+		Algorithm: "Ed25519",
+		PublicKey: s.signer.PublicKey(),
+	}, nil
+}
+
+const (
+	docxFormat          = "docx"
+	maxDocxTemplateSize = 10 << 20
+)
+
+func (s *Service) GetTicketDocx(ctx context.Context, request openapi.GetTicketDocxRequestObject) (openapi.GetTicketDocxResponseObject, error) {
+	user, ok := usercontext.UserFromContext(ctx)
+	if !ok {
+		return nil, errors.New("missing user")
 	}
 
-	f, contentType, size, err := s.uploader.File(file.ID, file.Blob)
+	ticket, err := s.queries.Ticket(ctx, request.Id)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get file from uploader: %w", err)
+		return nil, err
 	}
 
-	return openapi.DownloadFile200ApplicationoctetStreamResponse{
-		Body:          f,
-		ContentLength: size,
-		Headers: openapi.DownloadFile200ResponseHeaders{
-			ContentDisposition: "attachment; filename=\"" + file.Name + "\"",
-			ContentType:        contentType,
-		},
-	}, nil
-}
-
-func (s *Service) CreateLink(ctx context.Context, request openapi.CreateLinkRequestObject) (openapi.CreateLinkResponseObject, error) {
-	s.hooks.OnRecordBeforeCreateRequest.Publish(ctx, database.LinksTable.ID, request.Body)
+	if err := dlp.Check(ctx, s.queries, dlp.ExportReport, ticket.ID); errors.Is(err, dlp.ErrBlocked) {
+		return openapi.GetTicketDocx403JSONResponse{
+			Status:  http.StatusForbidden,
+			Error:   "Forbidden",
+			Message: err.Error(),
+		}, nil
+	} else if err != nil {
+		return nil, err
+	}
 
-	link, err := s.queries.CreateLink(ctx, sqlc.CreateLinkParams{
-		Name:   request.Body.Name,
-		Url:    request.Body.Url,
-		Ticket: request.Body.Ticket,
-	})
+	content, err := s.docxTicket(ctx, &ticket)
 	if err != nil {
 		return nil, err
 	}
 
-	response := openapi.Link{
-		Created: link.Created,
-		Id:      link.ID,
-		Name:    link.Name,
-		Ticket:  link.Ticket,
-		Updated: link.Updated,
-		Url:     link.Url,
-	}
+	generatedBy := pointer.Dereference(user.Name) + " (" + user.Username + ")"
 
-	s.hooks.OnRecordAfterCreateRequest.Publish(ctx, database.LinksTable.ID, response)
+	if request.Params.Profile != nil {
+		stored, err := s.queries.GetRedactionProfile(ctx, *request.Params.Profile)
+		if errors.Is(err, sql.ErrNoRows) {
+			return openapi.GetTicketDocx400JSONResponse{
+				Status:  http.StatusBadRequest,
+				Error:   "Bad Request",
+				Message: "unknown redaction profile " + *request.Params.Profile,
+			}, nil
+		} else if err != nil {
+			return nil, err
+		}
 
-	return openapi.CreateLink200JSONResponse(response), nil
-}
+		profile, err := redact.ParseProfile(&stored)
+		if err != nil {
+			return nil, err
+		}
 
-func (s *Service) DeleteLink(ctx context.Context, request openapi.DeleteLinkRequestObject) (openapi.DeleteLinkResponseObject, error) {
-	s.hooks.OnRecordBeforeDeleteRequest.Publish(ctx, database.LinksTable.ID, request.Id)
+		generatedBy = profile.Ticket(content, generatedBy)
+	}
 
-	err := s.queries.DeleteLink(ctx, request.Id)
+	template, err := s.docxTemplate(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	s.hooks.OnRecordAfterDeleteRequest.Publish(ctx, database.LinksTable.ID, request.Id)
+	document, err := docx.TicketDocument(template, content, generatedBy, time.Now())
+	if err != nil {
+		return nil, err
+	}
 
-	return openapi.DeleteLink204Response{}, nil
+	return openapi.GetTicketDocx200ApplicationvndOpenxmlformatsOfficedocumentWordprocessingmlDocumentResponse{
+		Body:          bytes.NewReader(document),
+		ContentLength: int64(len(document)),
+		Headers: openapi.GetTicketDocx200ResponseHeaders{
+			ContentDisposition: "attachment; filename=\"" + cmp.Or(pointer.Dereference(ticket.Key), ticket.ID) + ".docx\"",
+		},
+	}, nil
 }
 
-func (s *Service) GetLink(ctx context.Context, request openapi.GetLinkRequestObject) (openapi.GetLinkResponseObject, error) {
-	link, err := s.queries.GetLink(ctx, request.Id)
+// docxTicket collects the decrypted content of a ticket document.
+func (s *Service) docxTicket(ctx context.Context, ticket *sqlc.TicketRow) (*docx.Ticket, error) {
+	description, state, err := s.ticketContent(ctx, ticket.ID, ticket.Description, ticket.State)
 	if err != nil {
 		return nil, err
 	}
 
-	response := openapi.Link{
-		Id:      link.ID,
-		Name:    link.Name,
-		Created: link.Created,
-		Updated: link.Updated,
-		Url:     link.Url,
-		Ticket:  link.Ticket,
+	content := &docx.Ticket{
+		ID:          ticket.ID,
+		Key:         pointer.Dereference(ticket.Key),
+		Name:        ticket.Name,
+		Type:        cmp.Or(pointer.Dereference(ticket.TypeSingular), ticket.Type),
+		Owner:       pointer.Dereference(ticket.OwnerName),
+		Open:        ticket.Open,
+		Resolution:  pointer.Dereference(ticket.Resolution),
+		Created:     ticket.Created,
+		Updated:     ticket.Updated,
+		Description: description,
+		State:       state,
 	}
 
-	s.hooks.OnRecordViewRequest.Publish(ctx, database.LinksTable.ID, response)
-
-	return openapi.GetLink200JSONResponse(response), nil
-}
+	tasks, err := database.PaginateItems(ctx, func(ctx context.Context, offset, limit int64) ([]sqlc.ListTasksRow, error) {
+		return s.queries.ListTasks(ctx, sqlc.ListTasksParams{Ticket: ticket.ID, Offset: offset, Limit: limit})
+	})
+	if err != nil {
+		return nil, err
+	}
 
-func (s *Service) UpdateLink(ctx context.Context, request openapi.UpdateLinkRequestObject) (openapi.UpdateLinkResponseObject, error) {
-	s.hooks.OnRecordBeforeUpdateRequest.Publish(ctx, database.LinksTable.ID, request.Body)
+	for _, task := range tasks {
+		content.Tasks = append(content.Tasks, docx.Task{Name: task.Name, Owner: pointer.Dereference(task.OwnerName), Open: task.Open})
+	}
 
-	link, err := s.queries.UpdateLink(ctx, sqlc.UpdateLinkParams{
-		ID:   request.Id,
-		Name: request.Body.Name,
-		Url:  request.Body.Url,
+	comments, err := database.PaginateItems(ctx, func(ctx context.Context, offset, limit int64) ([]sqlc.ListCommentsRow, error) {
+		return s.queries.ListComments(ctx, sqlc.ListCommentsParams{Ticket: ticket.ID, Offset: offset, Limit: limit})
 	})
 	if err != nil {
 		return nil, err
 	}
 
-	response := openapi.Link{
-		Created: link.Created,
-		Id:      link.ID,
-		Name:    link.Name,
-		Ticket:  link.Ticket,
-		Updated: link.Updated,
-		Url:     link.Url,
-	}
+	for _, comment := range comments {
+		message, err := s.decryptMessage(ctx, ticket.ID, comment.Message)
+		if err != nil {
+			return nil, err
+		}
 
-	s.hooks.OnRecordAfterUpdateRequest.Publish(ctx, database.LinksTable.ID, response)
+		content.Comments = append(content.Comments, docx.Comment{Author: cmp.Or(pointer.Dereference(comment.AuthorName), comment.Author), Created: comment.Created, Message: message})
+	}
 
-	return openapi.UpdateLink200JSONResponse(response), nil
-}
+	timeline, err := database.PaginateItems(ctx, func(ctx context.Context, offset, limit int64) ([]sqlc.ListTimelineRow, error) {
+		return s.queries.ListTimeline(ctx, sqlc.ListTimelineParams{Ticket: ticket.ID, Offset: offset, Limit: limit})
+	})
+	if err != nil {
+		return nil, err
+	}
 
-func (s *Service) ListReactions(ctx context.Context, request openapi.ListReactionsRequestObject) (openapi.ListReactionsResponseObject, error) {
-	reactions, err := s.queries.ListReactions(ctx, sqlc.ListReactionsParams{
-		Offset: toInt64(request.Params.Offset, defaultOffset),
-		Limit:  toInt64(request.Params.Limit, defaultLimit),
+	files, err := database.PaginateItems(ctx, func(ctx context.Context, offset, limit int64) ([]sqlc.ListFilesRow, error) {
+		return s.queries.ListFiles(ctx, sqlc.ListFilesParams{Ticket: ticket.ID, Offset: offset, Limit: limit})
 	})
 	if err != nil {
 		return nil, err
 	}
 
-	response := make([]openapi.Reaction, 0, len(reactions))
-	for _, reaction := range reactions {
-		response = append(response, openapi.Reaction{
-			Action:      reaction.Action,
-			Actiondata:  unmarshal(reaction.Actiondata),
-			Created:     reaction.Created,
-			Id:          reaction.ID,
-			Name:        reaction.Name,
-			Trigger:     reaction.Trigger,
-			Triggerdata: unmarshal(reaction.Triggerdata),
-			Updated:     reaction.Updated,
-		})
+	for _, file := range files {
+		content.Files = append(content.Files, docx.File{Name: file.Name, Size: int64(file.Size), Created: file.Created})
 	}
 
-	s.hooks.OnRecordsListRequest.Publish(ctx, database.ReactionsTable.ID, response)
+	for _, event := range timeline {
+		content.Timeline = append(content.Timeline, docx.Event{Time: event.Time, Message: event.Message})
+	}
 
-	totalCount := 0
-	if len(reactions) > 0 {
-		totalCount = int(reactions[0].TotalCount)
+	links, err := database.PaginateItems(ctx, func(ctx context.Context, offset, limit int64) ([]sqlc.ListLinksRow, error) {
+		return s.queries.ListLinks(ctx, sqlc.ListLinksParams{Ticket: ticket.ID, Offset: offset, Limit: limit})
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	return openapi.ListReactions200JSONResponse{
-		Body: response,
-		Headers: openapi.ListReactions200ResponseHeaders{
-			XTotalCount: totalCount,
-		},
-	}, nil
+	for _, link := range links {
+		content.Links = append(content.Links, docx.Link{Name: link.Name, URL: link.Url})
+	}
+
+	return content, nil
 }
 
-func (s *Service) CreateReaction(ctx context.Context, request openapi.CreateReactionRequestObject) (openapi.CreateReactionResponseObject, error) {
-	s.hooks.OnRecordBeforeCreateRequest.Publish(ctx, database.ReactionsTable.ID, request.Body)
+func (s *Service) GetReportDocx(ctx context.Context, request openapi.GetReportDocxRequestObject) (openapi.GetReportDocxResponseObject, error) {
+	user, ok := usercontext.UserFromContext(ctx)
+	if !ok {
+		return nil, errors.New("missing user")
+	}
 
-	reaction, err := s.queries.CreateReaction(ctx, sqlc.CreateReactionParams{
-		Name:        request.Body.Name,
-		Action:      request.Body.Action,
-		Trigger:     request.Body.Trigger,
-		Actiondata:  marshal(request.Body.Actiondata),
-		Triggerdata: marshal(request.Body.Triggerdata),
+	since := time.Now().Add(-responseTimesWindow)
+	if request.Params.Since != nil {
+		since = *request.Params.Since
+	}
+
+	tickets, err := s.queries.ListReportTickets(ctx, sqlc.ListReportTicketsParams{
+		Type:  request.Params.Type,
+		Since: since.UTC().Format(time.DateTime),
 	})
 	if err != nil {
 		return nil, err
 	}
 
-	if err := s.scheduler.AddReaction(&reaction); err != nil {
-		slog.ErrorContext(ctx, "Failed to add reaction to scheduler", "error", err)
-	}
-
-	response := openapi.Reaction{
-		Action:      reaction.Action,
-		Actiondata:  unmarshal(reaction.Actiondata),
-		Created:     reaction.Created,
-		Id:          reaction.ID,
-		Name:        reaction.Name,
-		Trigger:     reaction.Trigger,
-		Triggerdata: unmarshal(reaction.Triggerdata),
-		Updated:     reaction.Updated,
+	report := &docx.Report{
+		Type:  pointer.Dereference(request.Params.Type),
+		Since: since,
 	}
 
-	s.hooks.OnRecordAfterCreateRequest.Publish(ctx, database.ReactionsTable.ID, response)
+	ids := make([]string, 0, len(tickets))
 
-	return openapi.CreateReaction200JSONResponse(response), nil
-}
+	for _, ticket := range tickets {
+		ids = append(ids, ticket.ID)
+
+		report.Tickets = append(report.Tickets, docx.ReportTicket{
+			ID:      ticket.ID,
+			Key:     pointer.Dereference(ticket.Key),
+			Name:    ticket.Name,
+			Type:    cmp.Or(pointer.Dereference(ticket.TypeSingular), ticket.Type),
+			Owner:   pointer.Dereference(ticket.OwnerName),
+			Open:    ticket.Open,
+			Created: ticket.Created,
+		})
+	}
 
-func (s *Service) DeleteReaction(ctx context.Context, request openapi.DeleteReactionRequestObject) (openapi.DeleteReactionResponseObject, error) {
-	s.hooks.OnRecordBeforeDeleteRequest.Publish(ctx, database.ReactionsTable.ID, request.Id)
+	// without tickets there is nothing to check, dlp.Check would check all
+	if len(ids) > 0 {
+		if err := dlp.Check(ctx, s.queries, dlp.ExportReport, ids...); errors.Is(err, dlp.ErrBlocked) {
+			return openapi.GetReportDocx403JSONResponse{
+				Status:  http.StatusForbidden,
+				Error:   "Forbidden",
+				Message: err.Error(),
+			}, nil
+		} else if err != nil {
+			return nil, err
+		}
+	}
 
-	err := s.queries.DeleteReaction(ctx, request.Id)
+	times, err := s.queries.GetResponseTimes(ctx, sqlc.GetResponseTimesParams{
+		Type:  request.Params.Type,
+		Since: since.UTC().Format(time.DateTime),
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	s.scheduler.RemoveReaction(request.Id)
-
-	s.hooks.OnRecordAfterDeleteRequest.Publish(ctx, database.ReactionsTable.ID, request.Id)
-
-	return openapi.DeleteReaction204Response{}, nil
-}
+	report.Acknowledged = times.Acknowledged
+	report.Resolved = times.Resolved
+	report.MTTA = time.Duration(times.Mtta * float64(time.Second))
+	report.MTTR = time.Duration(times.Mttr * float64(time.Second))
 
-func (s *Service) GetReaction(ctx context.Context, request openapi.GetReactionRequestObject) (openapi.GetReactionResponseObject, error) {
-	reaction, err := s.queries.GetReaction(ctx, request.Id)
+	template, err := s.docxTemplate(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	response := openapi.Reaction{
-		Action:      reaction.Action,
-		Actiondata:  unmarshal(reaction.Actiondata),
-		Created:     reaction.Created,
-		Id:          reaction.ID,
-		Name:        reaction.Name,
-		Trigger:     reaction.Trigger,
-		Triggerdata: unmarshal(reaction.Triggerdata),
-		Updated:     reaction.Updated,
+	document, err := docx.ReportDocument(template, report, pointer.Dereference(user.Name)+" ("+user.Username+")", time.Now())
+	if err != nil {
+		return nil, err
 	}
 
-	s.hooks.OnRecordViewRequest.Publish(ctx, database.ReactionsTable.ID, response)
-
-	return openapi.GetReaction200JSONResponse(response), nil
+	return openapi.GetReportDocx200ApplicationvndOpenxmlformatsOfficedocumentWordprocessingmlDocumentResponse{
+		Body:          bytes.NewReader(document),
+		ContentLength: int64(len(document)),
+		Headers: openapi.GetReportDocx200ResponseHeaders{
+			ContentDisposition: "attachment; filename=\"ticket-report-" + time.Now().UTC().Format(time.DateOnly) + ".docx\"",
+		},
+	}, nil
 }
 
-func (s *Service) UpdateReaction(ctx context.Context, request openapi.UpdateReactionRequestObject) (openapi.UpdateReactionResponseObject, error) {
-	s.hooks.OnRecordBeforeUpdateRequest.Publish(ctx, database.ReactionsTable.ID, request.Body)
+// usageReportMonths is the default number of months of the usage report,
+// including the current month.
+const usageReportMonths = 12
 
-	reaction, err := s.queries.UpdateReaction(ctx, sqlc.UpdateReactionParams{
-		ID:          request.Id,
-		Name:        request.Body.Name,
-		Action:      request.Body.Action,
-		Trigger:     request.Body.Trigger,
-		Actiondata:  marshalPointer(request.Body.Actiondata),
-		Triggerdata: marshalPointer(request.Body.Triggerdata),
-	})
+func (s *Service) GetUsageReport(ctx context.Context, request openapi.GetUsageReportRequestObject) (openapi.GetUsageReportResponseObject, error) {
+	until := time.Now().UTC()
+	if request.Params.Until != nil {
+		until = *request.Params.Until
+	}
+
+	since := time.Date(until.Year(), until.Month()-usageReportMonths+1, 1, 0, 0, 0, 0, time.UTC)
+	if request.Params.Since != nil {
+		since = *request.Params.Since
+	}
+
+	usage, err := billing.Report(ctx, s.queries, since, until)
 	if err != nil {
 		return nil, err
 	}
 
-	s.scheduler.RemoveReaction(request.Id)
+	filename := "usage-report-" + since.UTC().Format(time.DateOnly) + "-" + until.UTC().Format(time.DateOnly)
 
-	if err := s.scheduler.AddReaction(&reaction); err != nil {
-		slog.ErrorContext(ctx, "Failed to add reaction to scheduler", "error", err)
+	switch pointer.Dereference(request.Params.Format) {
+	case openapi.GetUsageReportParamsFormatCsv:
+		document, err := billing.CSV(usage)
+		if err != nil {
+			return nil, err
+		}
+
+		return openapi.GetUsageReport200TextcsvResponse{
+			Body:          bytes.NewReader(document),
+			ContentLength: int64(len(document)),
+			Headers: openapi.GetUsageReport200ResponseHeaders{
+				ContentDisposition: "attachment; filename=\"" + filename + ".csv\"",
+			},
+		}, nil
+	case openapi.GetUsageReportParamsFormatPdf:
+		document := billing.PDF(usage, since, until)
+
+		return openapi.GetUsageReport200ApplicationpdfResponse{
+			Body:          bytes.NewReader(document),
+			ContentLength: int64(len(document)),
+			Headers: openapi.GetUsageReport200ResponseHeaders{
+				ContentDisposition: "attachment; filename=\"" + filename + ".pdf\"",
+			},
+		}, nil
+	}
+
+	response := make([]openapi.CustomerUsage, 0, len(usage))
+	for _, u := range usage {
+		response = append(response, openapi.CustomerUsage{
+			Month:          u.Month,
+			Customer:       u.Customer,
+			Tickets:        int(u.Tickets),
+			EffortHours:    float32(u.Effort.Hours()),
+			AutomationRuns: int(u.AutomationRuns),
+			StorageBytes:   u.StorageBytes,
+		})
 	}
 
-	response := openapi.Reaction{
-		Action:      reaction.Action,
-		Actiondata:  unmarshal(reaction.Actiondata),
-		Created:     reaction.Created,
-		Id:          reaction.ID,
-		Name:        reaction.Name,
-		Trigger:     reaction.Trigger,
-		Triggerdata: unmarshal(reaction.Triggerdata),
-		Updated:     reaction.Updated,
+	return openapi.GetUsageReport200JSONResponse{Body: response}, nil
+}
+
+// docxTemplate returns the uploaded template of Word documents, or nil for
+// the built-in template.
+func (s *Service) docxTemplate(ctx context.Context) ([]byte, error) {
+	template, err := s.queries.GetDocumentTemplate(ctx, docxFormat)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
 	}
 
-	s.hooks.OnRecordAfterUpdateRequest.Publish(ctx, database.ReactionsTable.ID, response)
+	return template.Content, nil
+}
 
-	return openapi.UpdateReaction200JSONResponse(response), nil
+func (s *Service) GetDocxTemplate(ctx context.Context, _ openapi.GetDocxTemplateRequestObject) (openapi.GetDocxTemplateResponseObject, error) {
+	name := "template.docx"
+
+	template, err := s.queries.GetDocumentTemplate(ctx, docxFormat)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		d, err := docx.New(nil)
+		if err != nil {
+			return nil, err
+		}
+
+		if template.Content, err = d.Bytes(); err != nil {
+			return nil, err
+		}
+	case err != nil:
+		return nil, err
+	default:
+		name = template.Name
+	}
+
+	return openapi.GetDocxTemplate200ApplicationvndOpenxmlformatsOfficedocumentWordprocessingmlDocumentResponse{
+		Body:          bytes.NewReader(template.Content),
+		ContentLength: int64(len(template.Content)),
+		Headers: openapi.GetDocxTemplate200ResponseHeaders{
+			ContentDisposition: "attachment; filename=\"" + name + "\"",
+		},
+	}, nil
 }
 
-func (s *Service) GetSidebar(ctx context.Context, _ openapi.GetSidebarRequestObject) (openapi.GetSidebarResponseObject, error) {
-	sidebar, err := s.queries.GetSidebar(ctx)
+func (s *Service) SetDocxTemplate(ctx context.Context, request openapi.SetDocxTemplateRequestObject) (openapi.SetDocxTemplateResponseObject, error) {
+	content, err := io.ReadAll(io.LimitReader(request.Body, maxDocxTemplateSize+1))
 	if err != nil {
 		return nil, err
 	}
 
-	response := make([]openapi.Sidebar, 0, len(sidebar))
-	for _, s := range sidebar {
-		response = append(response, openapi.Sidebar{
-			Id:       s.ID,
-			Singular: s.Singular,
-			Plural:   s.Plural,
-			Icon:     s.Icon,
-			Count:    int(s.Count),
-		})
+	if len(content) > maxDocxTemplateSize {
+		return openapi.SetDocxTemplate400JSONResponse{Status: http.StatusBadRequest, Error: "Bad Request", Message: "The template is larger than 10 MB"}, nil
 	}
 
-	s.hooks.OnRecordsListRequest.Publish(ctx, database.SidebarTable.ID, response)
+	if err := docx.ValidateTemplate(content); err != nil {
+		return openapi.SetDocxTemplate400JSONResponse{Status: http.StatusBadRequest, Error: "Bad Request", Message: err.Error()}, nil
+	}
 
-	return openapi.GetSidebar200JSONResponse(response), nil
+	if _, err := s.queries.SetDocumentTemplate(ctx, sqlc.SetDocumentTemplateParams{
+		Format:  docxFormat,
+		Name:    filepath.Base(toString(request.Params.Name, "template.docx")),
+		Content: content,
+	}); err != nil {
+		return nil, err
+	}
+
+	return openapi.SetDocxTemplate204Response{}, nil
 }
 
-func (s *Service) ListTasks(ctx context.Context, request openapi.ListTasksRequestObject) (openapi.ListTasksResponseObject, error) {
-	tasks, err := s.queries.ListTasks(ctx, sqlc.ListTasksParams{
+func (s *Service) DeleteDocxTemplate(ctx context.Context, _ openapi.DeleteDocxTemplateRequestObject) (openapi.DeleteDocxTemplateResponseObject, error) {
+	if err := s.queries.DeleteDocumentTemplate(ctx, docxFormat); err != nil {
+		return nil, err
+	}
+
+	return openapi.DeleteDocxTemplate204Response{}, nil
+}
+
+func (s *Service) ListLinks(ctx context.Context, request openapi.ListLinksRequestObject) (openapi.ListLinksResponseObject, error) {
+	links, err := s.queries.ListLinks(ctx, sqlc.ListLinksParams{
 		Ticket: toString(request.Params.Ticket, ""),
 		Offset: toInt64(request.Params.Offset, defaultOffset),
 		Limit:  toInt64(request.Params.Limit, defaultLimit),
@@ -619,82 +1088,3478 @@ func (s *Service) ListTasks(ctx context.Context, request openapi.ListTasksReques
 		return nil, err
 	}
 
-	response := make([]openapi.ExtendedTask, 0, len(tasks))
-	for _, task := range tasks {
-		response = append(response, openapi.ExtendedTask{
-			Id:         task.ID,
-			Name:       task.Name,
-			Created:    task.Created,
-			Updated:    task.Updated,
-			Open:       task.Open,
-			Owner:      task.Owner,
-			Ticket:     task.Ticket,
-			OwnerName:  task.OwnerName,
-			TicketName: pointer.Dereference(task.TicketName),
-			TicketType: pointer.Dereference(task.TicketType),
+	response := make([]openapi.Link, 0, len(links))
+	for _, link := range links {
+		response = append(response, openapi.Link{
+			Id:      link.ID,
+			Name:    link.Name,
+			Created: link.Created,
+			Updated: link.Updated,
+			Url:     link.Url,
+			Ticket:  link.Ticket,
 		})
 	}
 
-	s.hooks.OnRecordsListRequest.Publish(ctx, database.TasksTable.ID, response)
+	s.hooks.OnRecordsListRequest.Publish(ctx, database.LinksTable.ID, response)
 
 	totalCount := 0
-	if len(tasks) > 0 {
-		totalCount = int(tasks[0].TotalCount)
+	if len(links) > 0 {
+		totalCount = int(links[0].TotalCount)
 	}
 
-	return openapi.ListTasks200JSONResponse{
+	return openapi.ListLinks200JSONResponse{
 		Body: response,
-		Headers: openapi.ListTasks200ResponseHeaders{
+		Headers: openapi.ListLinks200ResponseHeaders{
 			XTotalCount: totalCount,
 		},
 	}, nil
 }
 
-func (s *Service) CreateTask(ctx context.Context, request openapi.CreateTaskRequestObject) (openapi.CreateTaskResponseObject, error) {
-	s.hooks.OnRecordBeforeCreateRequest.Publish(ctx, database.TasksTable.ID, request.Body)
-
-	task, err := s.queries.CreateTask(ctx, sqlc.CreateTaskParams{
-		Name:   request.Body.Name,
-		Open:   request.Body.Open,
-		Owner:  request.Body.Owner,
-		Ticket: request.Body.Ticket,
-	})
+func (s *Service) DownloadFile(ctx context.Context, request openapi.DownloadFileRequestObject) (openapi.DownloadFileResponseObject, error) {
+	file, err := s.queries.GetFile(ctx, request.Id)
 	if err != nil {
 		return nil, err
 	}
 
-	response := openapi.Task{
-		Created: task.Created,
-		Id:      task.ID,
-		Name:    task.Name,
-		Open:    task.Open,
-		Owner:   task.Owner,
-		Ticket:  task.Ticket,
-		Updated: task.Updated,
+	f, _, _, err := s.uploader.File(ctx, file.ID, file.Blob)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get file from uploader: %w", err)
 	}
 
-	s.hooks.OnRecordAfterCreateRequest.Publish(ctx, database.TasksTable.ID, response)
-
-	return openapi.CreateTask200JSONResponse(response), nil
-}
-
-func (s *Service) DeleteTask(ctx context.Context, request openapi.DeleteTaskRequestObject) (openapi.DeleteTaskResponseObject, error) {
-	s.hooks.OnRecordBeforeDeleteRequest.Publish(ctx, database.TasksTable.ID, request.Id)
+	err = custody.Record(ctx, s.queries, &file, custody.Download, f)
+	f.Close()
 
-	err := s.queries.DeleteTask(ctx, request.Id)
 	if err != nil {
 		return nil, err
 	}
 
-	s.hooks.OnRecordAfterDeleteRequest.Publish(ctx, database.TasksTable.ID, request.Id)
-
-	return openapi.DeleteTask204Response{}, nil
-}
-
-func (s *Service) GetTask(ctx context.Context, request openapi.GetTaskRequestObject) (openapi.GetTaskResponseObject, error) {
-	task, err := s.queries.GetTask(ctx, request.Id)
+	// the file is opened again, the content of a remote storage cannot be
+	// rewound
+	f, contentType, size, err := s.uploader.File(ctx, file.ID, file.Blob)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("failed to get file from uploader: %w", err)
+	}
+
+	return openapi.DownloadFile200ApplicationoctetStreamResponse{
+		Body:          f,
+		ContentLength: size,
+		Headers: openapi.DownloadFile200ResponseHeaders{
+			ContentDisposition: "attachment; filename=\"" + file.Name + "\"",
+			ContentType:        contentType,
+		},
+	}, nil
+}
+
+func (s *Service) CreateLink(ctx context.Context, request openapi.CreateLinkRequestObject) (openapi.CreateLinkResponseObject, error) {
+	s.hooks.OnRecordBeforeCreateRequest.Publish(ctx, database.LinksTable.ID, request.Body)
+
+	link, err := s.queries.CreateLink(ctx, sqlc.CreateLinkParams{
+		Name:   request.Body.Name,
+		Url:    request.Body.Url,
+		Ticket: request.Body.Ticket,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := openapi.Link{
+		Created: link.Created,
+		Id:      link.ID,
+		Name:    link.Name,
+		Ticket:  link.Ticket,
+		Updated: link.Updated,
+		Url:     link.Url,
+	}
+
+	s.hooks.OnRecordAfterCreateRequest.Publish(ctx, database.LinksTable.ID, response)
+
+	return openapi.CreateLink200JSONResponse(response), nil
+}
+
+func (s *Service) DeleteLink(ctx context.Context, request openapi.DeleteLinkRequestObject) (openapi.DeleteLinkResponseObject, error) {
+	s.hooks.OnRecordBeforeDeleteRequest.Publish(ctx, database.LinksTable.ID, request.Id)
+
+	err := s.queries.DeleteLink(ctx, request.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	s.hooks.OnRecordAfterDeleteRequest.Publish(ctx, database.LinksTable.ID, request.Id)
+
+	return openapi.DeleteLink204Response{}, nil
+}
+
+func (s *Service) GetLink(ctx context.Context, request openapi.GetLinkRequestObject) (openapi.GetLinkResponseObject, error) {
+	link, err := s.queries.GetLink(ctx, request.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	response := openapi.Link{
+		Id:      link.ID,
+		Name:    link.Name,
+		Created: link.Created,
+		Updated: link.Updated,
+		Url:     link.Url,
+		Ticket:  link.Ticket,
+	}
+
+	s.hooks.OnRecordViewRequest.Publish(ctx, database.LinksTable.ID, response)
+
+	return openapi.GetLink200JSONResponse(response), nil
+}
+
+func (s *Service) UpdateLink(ctx context.Context, request openapi.UpdateLinkRequestObject) (openapi.UpdateLinkResponseObject, error) {
+	s.hooks.OnRecordBeforeUpdateRequest.Publish(ctx, database.LinksTable.ID, request.Body)
+
+	link, err := s.queries.UpdateLink(ctx, sqlc.UpdateLinkParams{
+		ID:   request.Id,
+		Name: request.Body.Name,
+		Url:  request.Body.Url,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := openapi.Link{
+		Created: link.Created,
+		Id:      link.ID,
+		Name:    link.Name,
+		Ticket:  link.Ticket,
+		Updated: link.Updated,
+		Url:     link.Url,
+	}
+
+	s.hooks.OnRecordAfterUpdateRequest.Publish(ctx, database.LinksTable.ID, response)
+
+	return openapi.UpdateLink200JSONResponse(response), nil
+}
+
+func (s *Service) ListReactions(ctx context.Context, request openapi.ListReactionsRequestObject) (openapi.ListReactionsResponseObject, error) {
+	reactions, err := s.queries.ListReactions(ctx, sqlc.ListReactionsParams{
+		Offset: toInt64(request.Params.Offset, defaultOffset),
+		Limit:  toInt64(request.Params.Limit, defaultLimit),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]openapi.Reaction, 0, len(reactions))
+	for _, reaction := range reactions {
+		response = append(response, openapi.Reaction{
+			Action:      reaction.Action,
+			Actiondata:  unmarshal(reaction.Actiondata),
+			Created:     reaction.Created,
+			Id:          reaction.ID,
+			Name:        reaction.Name,
+			Trigger:     reaction.Trigger,
+			Triggerdata: unmarshal(reaction.Triggerdata),
+			Updated:     reaction.Updated,
+		})
+	}
+
+	s.hooks.OnRecordsListRequest.Publish(ctx, database.ReactionsTable.ID, response)
+
+	totalCount := 0
+	if len(reactions) > 0 {
+		totalCount = int(reactions[0].TotalCount)
+	}
+
+	return openapi.ListReactions200JSONResponse{
+		Body: response,
+		Headers: openapi.ListReactions200ResponseHeaders{
+			XTotalCount: totalCount,
+		},
+	}, nil
+}
+
+func (s *Service) CreateReaction(ctx context.Context, request openapi.CreateReactionRequestObject) (openapi.CreateReactionResponseObject, error) {
+	s.hooks.OnRecordBeforeCreateRequest.Publish(ctx, database.ReactionsTable.ID, request.Body)
+
+	reaction, err := s.queries.CreateReaction(ctx, sqlc.CreateReactionParams{
+		Name:        request.Body.Name,
+		Action:      request.Body.Action,
+		Trigger:     request.Body.Trigger,
+		Actiondata:  marshal(request.Body.Actiondata),
+		Triggerdata: marshal(request.Body.Triggerdata),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.scheduler.AddReaction(&reaction); err != nil {
+		slog.ErrorContext(ctx, "Failed to add reaction to scheduler", "error", err)
+	}
+
+	response := openapi.Reaction{
+		Action:      reaction.Action,
+		Actiondata:  unmarshal(reaction.Actiondata),
+		Created:     reaction.Created,
+		Id:          reaction.ID,
+		Name:        reaction.Name,
+		Trigger:     reaction.Trigger,
+		Triggerdata: unmarshal(reaction.Triggerdata),
+		Updated:     reaction.Updated,
+	}
+
+	s.hooks.OnRecordAfterCreateRequest.Publish(ctx, database.ReactionsTable.ID, response)
+
+	return openapi.CreateReaction200JSONResponse(response), nil
+}
+
+func (s *Service) DeleteReaction(ctx context.Context, request openapi.DeleteReactionRequestObject) (openapi.DeleteReactionResponseObject, error) {
+	s.hooks.OnRecordBeforeDeleteRequest.Publish(ctx, database.ReactionsTable.ID, request.Id)
+
+	err := s.queries.DeleteReaction(ctx, request.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	s.scheduler.RemoveReaction(request.Id)
+
+	s.hooks.OnRecordAfterDeleteRequest.Publish(ctx, database.ReactionsTable.ID, request.Id)
+
+	return openapi.DeleteReaction204Response{}, nil
+}
+
+func (s *Service) GetReaction(ctx context.Context, request openapi.GetReactionRequestObject) (openapi.GetReactionResponseObject, error) {
+	reaction, err := s.queries.GetReaction(ctx, request.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	response := openapi.Reaction{
+		Action:      reaction.Action,
+		Actiondata:  unmarshal(reaction.Actiondata),
+		Created:     reaction.Created,
+		Id:          reaction.ID,
+		Name:        reaction.Name,
+		Trigger:     reaction.Trigger,
+		Triggerdata: unmarshal(reaction.Triggerdata),
+		Updated:     reaction.Updated,
+	}
+
+	s.hooks.OnRecordViewRequest.Publish(ctx, database.ReactionsTable.ID, response)
+
+	return openapi.GetReaction200JSONResponse(response), nil
+}
+
+func (s *Service) ListReactionJournal(ctx context.Context, request openapi.ListReactionJournalRequestObject) (openapi.ListReactionJournalResponseObject, error) {
+	reaction, err := s.queries.GetReaction(ctx, request.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := s.queries.ListJournalEntries(ctx, sqlc.ListJournalEntriesParams{
+		Reaction: reaction.ID,
+		Offset:   toInt64(request.Params.Offset, defaultOffset),
+		Limit:    toInt64(request.Params.Limit, defaultLimit),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]openapi.JournalEntry, 0, len(entries))
+	for _, entry := range entries {
+		response = append(response, mapJournalEntry(sqlc.RequestJournal{
+			ID:        entry.ID,
+			Reaction:  entry.Reaction,
+			Request:   entry.Request,
+			Size:      entry.Size,
+			Truncated: entry.Truncated,
+			Success:   entry.Success,
+			Error:     entry.Error,
+			Replayed:  entry.Replayed,
+			Created:   entry.Created,
+		}))
+	}
+
+	totalCount := 0
+	if len(entries) > 0 {
+		totalCount = int(entries[0].TotalCount)
+	}
+
+	return openapi.ListReactionJournal200JSONResponse{
+		Body: response,
+		Headers: openapi.ListReactionJournal200ResponseHeaders{
+			XTotalCount: totalCount,
+		},
+	}, nil
+}
+
+func (s *Service) ReplayReactionJournal(ctx context.Context, request openapi.ReplayReactionJournalRequestObject) (openapi.ReplayReactionJournalResponseObject, error) {
+	entry, output, err := webhooktrigger.Replay(ctx, s.queries, request.Id, request.Entry)
+	if errors.Is(err, sql.ErrNoRows) {
+		return openapi.ReplayReactionJournal404JSONResponse{
+			Status:  http.StatusNotFound,
+			Error:   "Not Found",
+			Message: "The request is not in the journal of the reaction",
+		}, nil
+	} else if errors.Is(err, webhooktrigger.ErrTruncated) {
+		return openapi.ReplayReactionJournal400JSONResponse{
+			Status:  http.StatusBadRequest,
+			Error:   "Bad Request",
+			Message: err.Error(),
+		}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return openapi.ReplayReactionJournal200JSONResponse{
+		Entry:  mapJournalEntry(*entry),
+		Output: string(output),
+	}, nil
+}
+
+func mapJournalEntry(entry sqlc.RequestJournal) openapi.JournalEntry {
+	var request map[string]any
+	if err := json.Unmarshal(entry.Request, &request); err != nil {
+		slog.Error("Invalid journaled request", "entry", entry.ID, "error", err)
+	}
+
+	var journalError *string
+	if entry.Error != "" {
+		journalError = &entry.Error
+	}
+
+	return openapi.JournalEntry{
+		Id:        entry.ID,
+		Reaction:  entry.Reaction,
+		Request:   request,
+		Size:      entry.Size,
+		Truncated: entry.Truncated,
+		Success:   entry.Success,
+		Error:     journalError,
+		Replayed:  entry.Replayed,
+		Created:   entry.Created,
+	}
+}
+
+func (s *Service) GetReactionStats(ctx context.Context, request openapi.GetReactionStatsRequestObject) (openapi.GetReactionStatsResponseObject, error) {
+	reaction, err := s.queries.GetReaction(ctx, request.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	stats, err := s.queries.GetReactionRunStats(ctx, reaction.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	runs, err := s.queries.ListReactionRuns(ctx, sqlc.ListReactionRunsParams{Reaction: reaction.ID, Limit: 10})
+	if err != nil {
+		return nil, err
+	}
+
+	recentRuns := make([]openapi.ReactionRun, 0, len(runs))
+	for _, run := range runs {
+		recentRuns = append(recentRuns, openapi.ReactionRun{
+			Success:    run.Success,
+			DurationMs: int(run.DurationMs),
+			CpuMs:      int(run.CpuMs),
+			MemoryPeak: run.MemoryPeak,
+			Created:    run.Created,
+		})
+	}
+
+	return openapi.GetReactionStats200JSONResponse{
+		Runs:          int(stats.Runs),
+		Failures:      int(stats.Failures),
+		AvgDurationMs: float32(stats.AvgDurationMs),
+		MaxDurationMs: int(stats.MaxDurationMs),
+		TotalCpuMs:    int(stats.TotalCpuMs),
+		AvgCpuMs:      float32(stats.AvgCpuMs),
+		MaxCpuMs:      int(stats.MaxCpuMs),
+		MaxMemoryPeak: stats.MaxMemoryPeak,
+		RecentRuns:    recentRuns,
+	}, nil
+}
+
+func (s *Service) UpdateReaction(ctx context.Context, request openapi.UpdateReactionRequestObject) (openapi.UpdateReactionResponseObject, error) {
+	s.hooks.OnRecordBeforeUpdateRequest.Publish(ctx, database.ReactionsTable.ID, request.Body)
+
+	reaction, err := s.queries.UpdateReaction(ctx, sqlc.UpdateReactionParams{
+		ID:          request.Id,
+		Name:        request.Body.Name,
+		Action:      request.Body.Action,
+		Trigger:     request.Body.Trigger,
+		Actiondata:  marshalPointer(request.Body.Actiondata),
+		Triggerdata: marshalPointer(request.Body.Triggerdata),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.scheduler.RemoveReaction(request.Id)
+
+	if err := s.scheduler.AddReaction(&reaction); err != nil {
+		slog.ErrorContext(ctx, "Failed to add reaction to scheduler", "error", err)
+	}
+
+	response := openapi.Reaction{
+		Action:      reaction.Action,
+		Actiondata:  unmarshal(reaction.Actiondata),
+		Created:     reaction.Created,
+		Id:          reaction.ID,
+		Name:        reaction.Name,
+		Trigger:     reaction.Trigger,
+		Triggerdata: unmarshal(reaction.Triggerdata),
+		Updated:     reaction.Updated,
+	}
+
+	s.hooks.OnRecordAfterUpdateRequest.Publish(ctx, database.ReactionsTable.ID, response)
+
+	return openapi.UpdateReaction200JSONResponse(response), nil
+}
+
+func (s *Service) ListBackups(_ context.Context, _ openapi.ListBackupsRequestObject) (openapi.ListBackupsResponseObject, error) {
+	backups, err := s.backups.List()
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]openapi.Backup, 0, len(backups))
+	for _, b := range backups {
+		response = append(response, mapBackup(&b))
+	}
+
+	return openapi.ListBackups200JSONResponse(response), nil
+}
+
+func (s *Service) CreateBackup(ctx context.Context, request openapi.CreateBackupRequestObject) (openapi.CreateBackupResponseObject, error) {
+	if request.Params.Target != nil {
+		return s.streamBackup(ctx, request)
+	}
+
+	if pointer.Dereference(request.Params.Async) {
+		return s.createBackupJob(ctx, request)
+	}
+
+	b, err := s.backups.Create(ctx, toString(request.Params.Base, ""), pointer.Dereference(request.Params.Exclude)...)
+	if errors.Is(err, backup.ErrNotFound) {
+		return openapi.CreateBackup404JSONResponse(errBackupNotFound), nil
+	} else if errors.Is(err, backup.ErrInvalidExclusion) {
+		return openapi.CreateBackup400JSONResponse{
+			Status:  http.StatusBadRequest,
+			Error:   "Bad Request",
+			Message: err.Error(),
+		}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return openapi.CreateBackup200JSONResponse(mapBackup(b)), nil
+}
+
+// createBackupJob starts a job that creates the backup in the backups folder.
+func (s *Service) createBackupJob(ctx context.Context, request openapi.CreateBackupRequestObject) (openapi.CreateBackupResponseObject, error) {
+	job, err := s.backups.CreateJob(ctx, toString(request.Params.Base, ""), pointer.Dereference(request.Params.Exclude)...)
+	if errors.Is(err, backup.ErrNotFound) {
+		return openapi.CreateBackup404JSONResponse(errBackupNotFound), nil
+	} else if errors.Is(err, backup.ErrInvalidExclusion) {
+		return openapi.CreateBackup400JSONResponse{
+			Status:  http.StatusBadRequest,
+			Error:   "Bad Request",
+			Message: err.Error(),
+		}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return openapi.CreateBackup202JSONResponse(mapBackupJob(job)), nil
+}
+
+// streamBackup starts a job that streams a full backup to the target, as the
+// base of an incremental backup is only in the backups folder.
+func (s *Service) streamBackup(ctx context.Context, request openapi.CreateBackupRequestObject) (openapi.CreateBackupResponseObject, error) {
+	if request.Params.Base != nil {
+		return openapi.CreateBackup400JSONResponse{
+			Status:  http.StatusBadRequest,
+			Error:   "Bad Request",
+			Message: "Incremental backups can only be stored in the backups folder",
+		}, nil
+	}
+
+	// the backup leaves the server like a download
+	if err := dlp.Check(ctx, s.queries, dlp.ExportBackup); errors.Is(err, dlp.ErrBlocked) {
+		return openapi.CreateBackup403JSONResponse{
+			Status:  http.StatusForbidden,
+			Error:   "Forbidden",
+			Message: err.Error(),
+		}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	se, err := settings.Load(ctx, s.queries)
+	if err != nil {
+		return nil, err
+	}
+
+	target, err := backup.NewTarget(*request.Params.Target, &se.BackupStorage)
+	if err != nil {
+		return openapi.CreateBackup400JSONResponse{
+			Status:  http.StatusBadRequest,
+			Error:   "Bad Request",
+			Message: err.Error(),
+		}, nil
+	}
+
+	job, err := s.backups.Stream(ctx, target, pointer.Dereference(request.Params.Exclude)...)
+	if errors.Is(err, backup.ErrInvalidExclusion) {
+		return openapi.CreateBackup400JSONResponse{
+			Status:  http.StatusBadRequest,
+			Error:   "Bad Request",
+			Message: err.Error(),
+		}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return openapi.CreateBackup202JSONResponse(mapBackupJob(job)), nil
+}
+
+func (s *Service) GetBackupJob(_ context.Context, request openapi.GetBackupJobRequestObject) (openapi.GetBackupJobResponseObject, error) {
+	job, err := s.backups.Job(request.Id)
+	if errors.Is(err, backup.ErrJobNotFound) {
+		return openapi.GetBackupJob404JSONResponse{
+			Status:  http.StatusNotFound,
+			Error:   "Not Found",
+			Message: "The backup job does not exist",
+		}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return openapi.GetBackupJob200JSONResponse(mapBackupJob(job)), nil
+}
+
+func (s *Service) DownloadBackupJob(ctx context.Context, request openapi.DownloadBackupJobRequestObject) (openapi.DownloadBackupJobResponseObject, error) {
+	if err := dlp.Check(ctx, s.queries, dlp.ExportBackup); errors.Is(err, dlp.ErrBlocked) {
+		return openapi.DownloadBackupJob403JSONResponse{
+			Status:  http.StatusForbidden,
+			Error:   "Forbidden",
+			Message: err.Error(),
+		}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	f, job, err := s.backups.Download(request.Id)
+	switch {
+	case errors.Is(err, backup.ErrJobNotFound):
+		return openapi.DownloadBackupJob404JSONResponse{
+			Status:  http.StatusNotFound,
+			Error:   "Not Found",
+			Message: "The backup job does not exist or its download expired",
+		}, nil
+	case errors.Is(err, backup.ErrNotFound):
+		return openapi.DownloadBackupJob404JSONResponse(errBackupNotFound), nil
+	case errors.Is(err, backup.ErrNoDownload):
+		return openapi.DownloadBackupJob400JSONResponse{
+			Status:  http.StatusBadRequest,
+			Error:   "Bad Request",
+			Message: err.Error(),
+		}, nil
+	case err != nil:
+		return nil, err
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+
+		return nil, err
+	}
+
+	return openapi.DownloadBackupJob200ApplicationzipResponse{
+		Body:          f,
+		ContentLength: info.Size(),
+		Headers: openapi.DownloadBackupJob200ResponseHeaders{
+			ContentDisposition: "attachment; filename=\"" + job.Name + "\"",
+		},
+	}, nil
+}
+
+func (s *Service) GetBackupStorageSettings(ctx context.Context, _ openapi.GetBackupStorageSettingsRequestObject) (openapi.GetBackupStorageSettingsResponseObject, error) {
+	se, err := settings.Load(ctx, s.queries)
+	if err != nil {
+		return nil, err
+	}
+
+	return openapi.GetBackupStorageSettings200JSONResponse(mapBackupStorageSettings(&se.BackupStorage)), nil
+}
+
+func (s *Service) UpdateBackupStorageSettings(ctx context.Context, request openapi.UpdateBackupStorageSettingsRequestObject) (openapi.UpdateBackupStorageSettingsResponseObject, error) {
+	current, err := settings.Load(ctx, s.queries)
+	if err != nil {
+		return nil, err
+	}
+
+	storage := settings.BackupStorage{
+		Endpoint:        request.Body.Endpoint,
+		Region:          request.Body.Region,
+		Bucket:          request.Body.Bucket,
+		AccessKeyID:     request.Body.AccessKeyId,
+		SecretAccessKey: request.Body.SecretAccessKey,
+		Directory:       pointer.Dereference(request.Body.Directory),
+		AzureAccount:    pointer.Dereference(request.Body.AzureAccount),
+		AzureAccountKey: pointer.Dereference(request.Body.AzureAccountKey),
+		AzureContainer:  pointer.Dereference(request.Body.AzureContainer),
+		AzureEndpoint:   pointer.Dereference(request.Body.AzureEndpoint),
+		GCSBucket:       pointer.Dereference(request.Body.GcsBucket),
+		GCSAccessKeyID:  pointer.Dereference(request.Body.GcsAccessKeyId),
+		GCSSecret:       pointer.Dereference(request.Body.GcsSecret),
+		GCSEndpoint:     pointer.Dereference(request.Body.GcsEndpoint),
+	}
+
+	// the redacted values from GetBackupStorageSettings keep the stored
+	// secrets
+	if storage.SecretAccessKey == redacted {
+		storage.SecretAccessKey = current.BackupStorage.SecretAccessKey
+	}
+
+	if storage.AzureAccountKey == redacted {
+		storage.AzureAccountKey = current.BackupStorage.AzureAccountKey
+	}
+
+	if storage.GCSSecret == redacted {
+		storage.GCSSecret = current.BackupStorage.GCSSecret
+	}
+
+	if err := settings.ValidateBackupStorage(storage); err != nil {
+		return openapi.UpdateBackupStorageSettings400JSONResponse{
+			Status:  http.StatusBadRequest,
+			Error:   "Bad Request",
+			Message: err.Error(),
+		}, nil
+	}
+
+	se, err := settings.Update(ctx, s.queries, func(settings *settings.Settings) {
+		settings.BackupStorage = storage
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to save backup storage settings: %w", err)
+	}
+
+	return openapi.UpdateBackupStorageSettings200JSONResponse(mapBackupStorageSettings(&se.BackupStorage)), nil
+}
+
+func (s *Service) GetBackupNotificationSettings(ctx context.Context, _ openapi.GetBackupNotificationSettingsRequestObject) (openapi.GetBackupNotificationSettingsResponseObject, error) {
+	se, err := settings.Load(ctx, s.queries)
+	if err != nil {
+		return nil, err
+	}
+
+	return openapi.GetBackupNotificationSettings200JSONResponse(mapBackupNotificationSettings(&se.BackupNotification)), nil
+}
+
+func (s *Service) UpdateBackupNotificationSettings(ctx context.Context, request openapi.UpdateBackupNotificationSettingsRequestObject) (openapi.UpdateBackupNotificationSettingsResponseObject, error) {
+	notification := settings.BackupNotification{
+		URL:     request.Body.Url,
+		Slack:   request.Body.Slack,
+		Headers: pointer.Dereference(request.Body.Headers),
+	}
+
+	if err := settings.ValidateBackupNotification(notification); err != nil {
+		return openapi.UpdateBackupNotificationSettings400JSONResponse{
+			Status:  http.StatusBadRequest,
+			Error:   "Bad Request",
+			Message: err.Error(),
+		}, nil
+	}
+
+	se, err := settings.Update(ctx, s.queries, func(settings *settings.Settings) {
+		// the redacted values from GetBackupNotificationSettings keep the
+		// stored header values
+		for key, value := range notification.Headers {
+			if value == redacted {
+				notification.Headers[key] = settings.BackupNotification.Headers[key]
+			}
+		}
+
+		settings.BackupNotification = notification
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to save backup notification settings: %w", err)
+	}
+
+	return openapi.UpdateBackupNotificationSettings200JSONResponse(mapBackupNotificationSettings(&se.BackupNotification)), nil
+}
+
+func mapBackupNotificationSettings(config *settings.BackupNotification) openapi.BackupNotificationSettings {
+	notificationSettings := openapi.BackupNotificationSettings{
+		Url:   config.URL,
+		Slack: config.Slack,
+	}
+
+	if len(config.Headers) > 0 {
+		headers := make(map[string]string, len(config.Headers))
+		for key := range config.Headers {
+			headers[key] = redacted
+		}
+
+		notificationSettings.Headers = &headers
+	}
+
+	return notificationSettings
+}
+
+func (s *Service) GetBackupRetentionSettings(ctx context.Context, _ openapi.GetBackupRetentionSettingsRequestObject) (openapi.GetBackupRetentionSettingsResponseObject, error) {
+	se, err := settings.Load(ctx, s.queries)
+	if err != nil {
+		return nil, err
+	}
+
+	return openapi.GetBackupRetentionSettings200JSONResponse(mapBackupRetentionSettings(&se.BackupRetention)), nil
+}
+
+func (s *Service) UpdateBackupRetentionSettings(ctx context.Context, request openapi.UpdateBackupRetentionSettingsRequestObject) (openapi.UpdateBackupRetentionSettingsResponseObject, error) {
+	retention := settings.BackupRetention{
+		Daily:  request.Body.Daily,
+		Weekly: request.Body.Weekly,
+	}
+
+	if err := settings.ValidateBackupRetention(retention); err != nil {
+		return openapi.UpdateBackupRetentionSettings400JSONResponse{
+			Status:  http.StatusBadRequest,
+			Error:   "Bad Request",
+			Message: err.Error(),
+		}, nil
+	}
+
+	se, err := settings.Update(ctx, s.queries, func(settings *settings.Settings) {
+		settings.BackupRetention = retention
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to save backup retention settings: %w", err)
+	}
+
+	return openapi.UpdateBackupRetentionSettings200JSONResponse(mapBackupRetentionSettings(&se.BackupRetention)), nil
+}
+
+func mapBackupRetentionSettings(config *settings.BackupRetention) openapi.BackupRetentionSettings {
+	return openapi.BackupRetentionSettings{
+		Daily:  config.Daily,
+		Weekly: config.Weekly,
+	}
+}
+
+func mapBackupStorageSettings(config *settings.BackupStorage) openapi.BackupStorageSettings {
+	storageSettings := openapi.BackupStorageSettings{
+		Endpoint:    config.Endpoint,
+		Region:      config.Region,
+		Bucket:      config.Bucket,
+		AccessKeyId: config.AccessKeyID,
+	}
+
+	if config.SecretAccessKey != "" {
+		storageSettings.SecretAccessKey = redacted
+	}
+
+	if config.Directory != "" {
+		storageSettings.Directory = &config.Directory
+	}
+
+	if config.AzureAccount != "" {
+		storageSettings.AzureAccount = &config.AzureAccount
+		storageSettings.AzureContainer = &config.AzureContainer
+	}
+
+	if config.AzureAccountKey != "" {
+		storageSettings.AzureAccountKey = pointer.Pointer(redacted)
+	}
+
+	if config.AzureEndpoint != "" {
+		storageSettings.AzureEndpoint = &config.AzureEndpoint
+	}
+
+	if config.GCSBucket != "" {
+		storageSettings.GcsBucket = &config.GCSBucket
+		storageSettings.GcsAccessKeyId = &config.GCSAccessKeyID
+	}
+
+	if config.GCSSecret != "" {
+		storageSettings.GcsSecret = pointer.Pointer(redacted)
+	}
+
+	if config.GCSEndpoint != "" {
+		storageSettings.GcsEndpoint = &config.GCSEndpoint
+	}
+
+	return storageSettings
+}
+
+var errBackupNotFound = openapi.Error{
+	Status:  http.StatusNotFound,
+	Error:   "Not Found",
+	Message: "The backup does not exist",
+}
+
+func (s *Service) DeleteBackup(_ context.Context, request openapi.DeleteBackupRequestObject) (openapi.DeleteBackupResponseObject, error) {
+	err := s.backups.Delete(request.Name)
+
+	switch {
+	case errors.Is(err, backup.ErrNotFound):
+		return openapi.DeleteBackup404JSONResponse(errBackupNotFound), nil
+	case errors.Is(err, backup.ErrInUse):
+		return openapi.DeleteBackup400JSONResponse{
+			Status:  http.StatusBadRequest,
+			Error:   "Bad Request",
+			Message: err.Error(),
+		}, nil
+	case err != nil:
+		return nil, err
+	}
+
+	return openapi.DeleteBackup204Response{}, nil
+}
+
+func (s *Service) DownloadBackup(ctx context.Context, request openapi.DownloadBackupRequestObject) (openapi.DownloadBackupResponseObject, error) {
+	if err := dlp.Check(ctx, s.queries, dlp.ExportBackup); errors.Is(err, dlp.ErrBlocked) {
+		return openapi.DownloadBackup403JSONResponse{
+			Status:  http.StatusForbidden,
+			Error:   "Forbidden",
+			Message: err.Error(),
+		}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	f, err := s.backups.Open(request.Name)
+	if errors.Is(err, backup.ErrNotFound) {
+		return openapi.DownloadBackup404JSONResponse(errBackupNotFound), nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+
+		return nil, err
+	}
+
+	return openapi.DownloadBackup200ApplicationzipResponse{
+		Body:          f,
+		ContentLength: info.Size(),
+		Headers: openapi.DownloadBackup200ResponseHeaders{
+			ContentDisposition: "attachment; filename=\"" + request.Name + "\"",
+		},
+	}, nil
+}
+
+func (s *Service) VerifyBackup(ctx context.Context, request openapi.VerifyBackupRequestObject) (openapi.VerifyBackupResponseObject, error) {
+	archive, size, cleanup, err := s.backupArchive(request.Params.Name, request.Body)
+	if errors.Is(err, backup.ErrNotFound) {
+		return openapi.VerifyBackup404JSONResponse(errBackupNotFound), nil
+	} else if isUnreadableBackup(err) {
+		return openapi.VerifyBackup200JSONResponse{
+			Valid:                false,
+			CurrentSchemaVersion: migration.Latest(),
+			Files:                []openapi.BackupFileCheck{},
+			Bases:                []string{},
+			Errors:               []string{err.Error()},
+		}, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+
+	report := backup.Verify(ctx, archive, size, migration.Latest())
+	s.backups.VerifyBases(archive, size, report)
+
+	return openapi.VerifyBackup200JSONResponse(mapBackupVerification(report)), nil
+}
+
+func mapBackupVerification(report *backup.Report) openapi.BackupVerification {
+	files := make([]openapi.BackupFileCheck, 0, len(report.Files))
+	for _, file := range report.Files {
+		check := openapi.BackupFileCheck{Path: file.Path, Valid: file.Valid}
+		if file.Error != "" {
+			check.Error = &file.Error
+		}
+
+		files = append(files, check)
+	}
+
+	var version *string
+	if report.Version != "" {
+		version = &report.Version
+	}
+
+	return openapi.BackupVerification{
+		Valid:                report.Valid,
+		Created:              report.Created,
+		Version:              version,
+		SchemaVersion:        report.Schema,
+		CurrentSchemaVersion: report.CurrentSchema,
+		Compatible:           report.Compatible,
+		Files:                files,
+		Bases:                append([]string{}, report.Bases...),
+		Errors:               append([]string{}, report.Errors...),
+	}
+}
+
+func (s *Service) PreviewBackup(ctx context.Context, request openapi.PreviewBackupRequestObject) (openapi.PreviewBackupResponseObject, error) {
+	archive, size, cleanup, err := s.backupArchive(request.Params.Name, request.Body)
+	if errors.Is(err, backup.ErrNotFound) {
+		return openapi.PreviewBackup404JSONResponse(errBackupNotFound), nil
+	} else if isUnreadableBackup(err) {
+		return openapi.PreviewBackup400JSONResponse{
+			Status:  http.StatusBadRequest,
+			Error:   "Bad Request",
+			Message: err.Error(),
+		}, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+
+	diff, err := backup.Preview(ctx, s.queries, s.uploader, archive, size)
+	if errors.Is(err, backup.ErrInvalid) {
+		return openapi.PreviewBackup400JSONResponse{
+			Status:  http.StatusBadRequest,
+			Error:   "Bad Request",
+			Message: err.Error(),
+		}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return openapi.PreviewBackup200JSONResponse(mapBackupPreview(diff)), nil
+}
+
+func mapBackupPreview(diff *backup.Diff) openapi.BackupPreview {
+	tables := make([]openapi.BackupTableDiff, 0, len(diff.Tables))
+	for _, table := range diff.Tables {
+		tables = append(tables, openapi.BackupTableDiff{
+			Table:   table.Table,
+			Added:   table.Added,
+			Removed: table.Removed,
+			Changed: table.Changed,
+		})
+	}
+
+	return openapi.BackupPreview{
+		Created:       diff.Created,
+		SchemaVersion: diff.Schema,
+		Tables:        tables,
+		Uploads: openapi.BackupUploadDiff{
+			Added:   append([]string{}, diff.Uploads.Added...),
+			Removed: append([]string{}, diff.Uploads.Removed...),
+			Missing: append([]string{}, diff.Uploads.Missing...),
+		},
+	}
+}
+
+func (s *Service) ValidateBackup(ctx context.Context, request openapi.ValidateBackupRequestObject) (openapi.ValidateBackupResponseObject, error) {
+	archive, size, cleanup, err := s.backupArchive(request.Params.Name, request.Body)
+	if errors.Is(err, backup.ErrNotFound) {
+		return openapi.ValidateBackup404JSONResponse(errBackupNotFound), nil
+	} else if isUnreadableBackup(err) {
+		return openapi.ValidateBackup200JSONResponse{
+			Valid: false,
+			Verification: openapi.BackupVerification{
+				Valid:                false,
+				CurrentSchemaVersion: migration.Latest(),
+				Files:                []openapi.BackupFileCheck{},
+				Bases:                []string{},
+				Errors:               []string{err.Error()},
+			},
+			Errors: []string{err.Error()},
+		}, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+
+	validation, err := s.backups.Validate(ctx, archive, size, backup.Selection{
+		Tables:  pointer.Dereference(request.Params.Table),
+		Uploads: pointer.Dereference(request.Params.Upload),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := openapi.ValidateBackup200JSONResponse{
+		Valid:        validation.Valid,
+		Verification: mapBackupVerification(validation.Report),
+		Errors:       append([]string{}, validation.Errors...),
+	}
+
+	if validation.Diff != nil {
+		preview := mapBackupPreview(validation.Diff)
+		response.Preview = &preview
+	}
+
+	return response, nil
+}
+
+// backupArchive opens the archive of a stored backup or, without a name, of
+// the uploaded one. The zip reader needs random access, so the upload is
+// buffered on disk. Encrypted backups are decrypted.
+func (s *Service) backupArchive(name *string, body io.Reader) (io.ReaderAt, int64, func(), error) {
+	var (
+		f       *os.File
+		cleanup func()
+		err     error
+	)
+
+	if name != nil {
+		if f, err = s.backups.Open(*name); err != nil {
+			return nil, 0, nil, err
+		}
+
+		cleanup = func() { f.Close() }
+	} else {
+		if f, err = os.CreateTemp("", "catalyst-backup-*.zip"); err != nil {
+			return nil, 0, nil, err
+		}
+
+		cleanup = func() {
+			f.Close()
+			os.Remove(f.Name())
+		}
+
+		if _, err := io.Copy(f, body); err != nil {
+			cleanup()
+
+			return nil, 0, nil, fmt.Errorf("failed to read backup: %w", err)
+		}
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		cleanup()
+
+		return nil, 0, nil, err
+	}
+
+	archive, size, err := s.backups.Archive(f, info.Size())
+	if err != nil {
+		cleanup()
+
+		return nil, 0, nil, err
+	}
+
+	return archive, size, cleanup, nil
+}
+
+// isUnreadableBackup reports whether a backup cannot be decrypted.
+func isUnreadableBackup(err error) bool {
+	return errors.Is(err, backup.ErrEncrypted) || errors.Is(err, backup.ErrDecrypt) || errors.Is(err, backup.ErrInvalid)
+}
+
+func mapBackup(b *backup.Info) openapi.Backup {
+	response := openapi.Backup{
+		Name:      b.Name,
+		Size:      b.Size,
+		Created:   b.Created,
+		Encrypted: b.Encrypted,
+	}
+
+	if b.Base != "" {
+		response.Base = &b.Base
+	}
+
+	if len(b.Excluded) > 0 {
+		response.Excluded = &b.Excluded
+	}
+
+	if b.Version != "" {
+		response.Version = &b.Version
+	}
+
+	return response
+}
+
+func mapBackupJob(j *backup.Job) openapi.BackupJob {
+	response := openapi.BackupJob{
+		Id:         j.ID,
+		Name:       j.Name,
+		Location:   j.Location,
+		Status:     openapi.BackupJobStatus(j.Status),
+		Files:      j.Files,
+		TotalFiles: j.TotalFiles,
+		Size:       j.Size,
+		Created:    j.Created,
+		Finished:   j.Finished,
+		Expires:    j.Expires,
+	}
+
+	if j.Base != "" {
+		response.Base = &j.Base
+	}
+
+	if j.Stage != "" {
+		response.Stage = pointer.Pointer(openapi.BackupJobStage(j.Stage))
+	}
+
+	if len(j.Excluded) > 0 {
+		response.Excluded = &j.Excluded
+	}
+
+	if j.Error != "" {
+		response.Error = &j.Error
+	}
+
+	return response
+}
+
+func (s *Service) Canonicalize(_ context.Context, request openapi.CanonicalizeRequestObject) (openapi.CanonicalizeResponseObject, error) {
+	artifacts, invalid := canonical.Collapse(string(pointer.Dereference(request.Body.Kind)), request.Body.Values)
+
+	response := openapi.CanonicalizeResponse{
+		Artifacts: make([]openapi.CanonicalArtifact, 0, len(artifacts)),
+		Invalid:   make([]string, 0, len(invalid)),
+	}
+
+	for _, artifact := range artifacts {
+		response.Artifacts = append(response.Artifacts, openapi.CanonicalArtifact{
+			Kind:      artifact.Kind,
+			Value:     artifact.Value,
+			Originals: artifact.Originals,
+		})
+	}
+
+	response.Invalid = append(response.Invalid, invalid...)
+
+	return openapi.Canonicalize200JSONResponse(response), nil
+}
+
+func (s *Service) GetSidebar(ctx context.Context, _ openapi.GetSidebarRequestObject) (openapi.GetSidebarResponseObject, error) {
+	sidebar, err := s.queries.GetSidebar(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]openapi.Sidebar, 0, len(sidebar))
+	for _, s := range sidebar {
+		response = append(response, openapi.Sidebar{
+			Id:       s.ID,
+			Singular: s.Singular,
+			Plural:   s.Plural,
+			Icon:     s.Icon,
+			Count:    int(s.Count),
+		})
+	}
+
+	s.hooks.OnRecordsListRequest.Publish(ctx, database.SidebarTable.ID, response)
+
+	return openapi.GetSidebar200JSONResponse(response), nil
+}
+
+func (s *Service) ListTasks(ctx context.Context, request openapi.ListTasksRequestObject) (openapi.ListTasksResponseObject, error) {
+	tasks, err := s.queries.ListTasks(ctx, sqlc.ListTasksParams{
+		Ticket: toString(request.Params.Ticket, ""),
+		Offset: toInt64(request.Params.Offset, defaultOffset),
+		Limit:  toInt64(request.Params.Limit, defaultLimit),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]openapi.ExtendedTask, 0, len(tasks))
+	for _, task := range tasks {
+		response = append(response, openapi.ExtendedTask{
+			Id:         task.ID,
+			Name:       task.Name,
+			Created:    task.Created,
+			Updated:    task.Updated,
+			Open:       task.Open,
+			Owner:      task.Owner,
+			Ticket:     task.Ticket,
+			OwnerName:  task.OwnerName,
+			TicketName: pointer.Dereference(task.TicketName),
+			TicketType: pointer.Dereference(task.TicketType),
+		})
+	}
+
+	s.hooks.OnRecordsListRequest.Publish(ctx, database.TasksTable.ID, response)
+
+	totalCount := 0
+	if len(tasks) > 0 {
+		totalCount = int(tasks[0].TotalCount)
+	}
+
+	return openapi.ListTasks200JSONResponse{
+		Body: response,
+		Headers: openapi.ListTasks200ResponseHeaders{
+			XTotalCount: totalCount,
+		},
+	}, nil
+}
+
+func (s *Service) CreateTask(ctx context.Context, request openapi.CreateTaskRequestObject) (openapi.CreateTaskResponseObject, error) {
+	s.hooks.OnRecordBeforeCreateRequest.Publish(ctx, database.TasksTable.ID, request.Body)
+
+	task, err := s.queries.CreateTask(ctx, sqlc.CreateTaskParams{
+		Name:   request.Body.Name,
+		Open:   request.Body.Open,
+		Owner:  request.Body.Owner,
+		Ticket: request.Body.Ticket,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := openapi.Task{
+		Created: task.Created,
+		Id:      task.ID,
+		Name:    task.Name,
+		Open:    task.Open,
+		Owner:   task.Owner,
+		Ticket:  task.Ticket,
+		Updated: task.Updated,
+	}
+
+	s.hooks.OnRecordAfterCreateRequest.Publish(ctx, database.TasksTable.ID, response)
+
+	return openapi.CreateTask200JSONResponse(response), nil
+}
+
+func (s *Service) DeleteTask(ctx context.Context, request openapi.DeleteTaskRequestObject) (openapi.DeleteTaskResponseObject, error) {
+	s.hooks.OnRecordBeforeDeleteRequest.Publish(ctx, database.TasksTable.ID, request.Id)
+
+	err := s.queries.DeleteTask(ctx, request.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	s.hooks.OnRecordAfterDeleteRequest.Publish(ctx, database.TasksTable.ID, request.Id)
+
+	return openapi.DeleteTask204Response{}, nil
+}
+
+var errNoTaskOutput = openapi.Error{
+	Status:  http.StatusNotFound,
+	Error:   "Not Found",
+	Message: "The task has no output",
+}
+
+func (s *Service) GetTaskOutput(ctx context.Context, request openapi.GetTaskOutputRequestObject) (openapi.GetTaskOutputResponseObject, error) {
+	output, err := s.queries.GetTaskOutput(ctx, request.Id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return openapi.GetTaskOutput404JSONResponse(errNoTaskOutput), nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return openapi.GetTaskOutput200JSONResponse(mapTaskOutput(&output)), nil
+}
+
+func (s *Service) SetTaskOutput(ctx context.Context, request openapi.SetTaskOutputRequestObject) (openapi.SetTaskOutputResponseObject, error) {
+	if err := expression.ValidateName(request.Body.Name); err != nil {
+		return nil, err
+	}
+
+	value, err := json.Marshal(request.Body.Output)
+	if err != nil {
+		return nil, err
+	}
+
+	// outputs are mostly set by automations, which have the secrets at hand
+	redactor, err := redact.Load(ctx, s.queries)
+	if err != nil {
+		return nil, err
+	}
+
+	output, err := s.queries.SetTaskOutput(ctx, sqlc.SetTaskOutputParams{
+		Task:   request.Id,
+		Name:   request.Body.Name,
+		Output: redactor.JSON(value),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return openapi.SetTaskOutput200JSONResponse(mapTaskOutput(&output)), nil
+}
+
+func mapTaskOutput(output *sqlc.TaskOutput) openapi.TaskOutput {
+	return openapi.TaskOutput{
+		Task:    output.Task,
+		Name:    output.Name,
+		Output:  json.RawMessage(output.Output),
+		Updated: output.Updated,
+	}
+}
+
+func (s *Service) EvaluateExpression(ctx context.Context, request openapi.EvaluateExpressionRequestObject) (openapi.EvaluateExpressionResponseObject, error) {
+	variables, err := expression.Load(ctx, s.queries, request.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := expression.Eval(request.Body.Expression, variables)
+	if err != nil {
+		return openapi.EvaluateExpression400JSONResponse{
+			Status:  http.StatusBadRequest,
+			Error:   "Bad Request",
+			Message: err.Error(),
+		}, nil
+	}
+
+	return openapi.EvaluateExpression200JSONResponse{Value: value}, nil
+}
+
+var errNoTaskTimer = openapi.Error{
+	Status:  http.StatusNotFound,
+	Error:   "Not Found",
+	Message: "The task has no timer",
+}
+
+func (s *Service) GetTaskTimer(ctx context.Context, request openapi.GetTaskTimerRequestObject) (openapi.GetTaskTimerResponseObject, error) {
+	timer, err := s.queries.GetTaskTimer(ctx, request.Id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return openapi.GetTaskTimer404JSONResponse(errNoTaskTimer), nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return openapi.GetTaskTimer200JSONResponse(mapTaskTimer(&timer)), nil
+}
+
+func (s *Service) SetTaskTimer(ctx context.Context, request openapi.SetTaskTimerRequestObject) (openapi.SetTaskTimerResponseObject, error) {
+	timer, err := tasktimer.New(s.queries).Set(ctx,
+		request.Id,
+		time.Duration(request.Body.Timeout)*time.Minute,
+		string(request.Body.Action),
+		request.Body.EscalationName,
+		request.Body.EscalationOwner,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return openapi.SetTaskTimer200JSONResponse(mapTaskTimer(timer)), nil
+}
+
+func (s *Service) DeleteTaskTimer(ctx context.Context, request openapi.DeleteTaskTimerRequestObject) (openapi.DeleteTaskTimerResponseObject, error) {
+	if err := s.queries.DeleteTaskTimer(ctx, request.Id); err != nil {
+		return nil, err
+	}
+
+	return openapi.DeleteTaskTimer204Response{}, nil
+}
+
+func mapTaskTimer(timer *sqlc.TaskTimer) openapi.TaskTimer {
+	return openapi.TaskTimer{
+		Task:            timer.Task,
+		Due:             timer.Due,
+		Action:          openapi.TaskTimerAction(timer.Action),
+		EscalationName:  timer.EscalationName,
+		EscalationOwner: timer.EscalationOwner,
+		Result:          (*openapi.TaskTimerResult)(timer.Result),
+		EscalationTask:  timer.EscalationTask,
+		Fired:           timer.Fired,
+	}
+}
+
+func (s *Service) ListPlaybooks(ctx context.Context, request openapi.ListPlaybooksRequestObject) (openapi.ListPlaybooksResponseObject, error) {
+	playbooks, err := s.queries.ListPlaybooks(ctx, sqlc.ListPlaybooksParams{
+		Offset: toInt64(request.Params.Offset, defaultOffset),
+		Limit:  toInt64(request.Params.Limit, defaultLimit),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]openapi.Playbook, 0, len(playbooks))
+	for _, p := range playbooks {
+		response = append(response, mapPlaybook(sqlc.Playbook{
+			ID:          p.ID,
+			Name:        p.Name,
+			Description: p.Description,
+			Inputs:      p.Inputs,
+			Tasks:       p.Tasks,
+			Created:     p.Created,
+			Updated:     p.Updated,
+		}))
+	}
+
+	totalCount := 0
+	if len(playbooks) > 0 {
+		totalCount = int(playbooks[0].TotalCount)
+	}
+
+	return openapi.ListPlaybooks200JSONResponse{
+		Body: response,
+		Headers: openapi.ListPlaybooks200ResponseHeaders{
+			XTotalCount: totalCount,
+		},
+	}, nil
+}
+
+func (s *Service) CreatePlaybook(ctx context.Context, request openapi.CreatePlaybookRequestObject) (openapi.CreatePlaybookResponseObject, error) {
+	inputs, tasks := toPlaybookInputs(request.Body.Inputs), toPlaybookTasks(request.Body.Tasks)
+
+	if err := playbook.Validate(inputs, tasks); err != nil {
+		return openapi.CreatePlaybook400JSONResponse{
+			Status:  http.StatusBadRequest,
+			Error:   "Bad Request",
+			Message: err.Error(),
+		}, nil
+	}
+
+	inputsData, err := json.Marshal(inputs)
+	if err != nil {
+		return nil, err
+	}
+
+	tasksData, err := json.Marshal(tasks)
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := s.queries.CreatePlaybook(ctx, sqlc.CreatePlaybookParams{
+		Name:        request.Body.Name,
+		Description: pointer.Dereference(request.Body.Description),
+		Inputs:      inputsData,
+		Tasks:       tasksData,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return openapi.CreatePlaybook200JSONResponse(mapPlaybook(p)), nil
+}
+
+func (s *Service) GetPlaybook(ctx context.Context, request openapi.GetPlaybookRequestObject) (openapi.GetPlaybookResponseObject, error) {
+	p, err := s.queries.GetPlaybook(ctx, request.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	return openapi.GetPlaybook200JSONResponse(mapPlaybook(p)), nil
+}
+
+func (s *Service) UpdatePlaybook(ctx context.Context, request openapi.UpdatePlaybookRequestObject) (openapi.UpdatePlaybookResponseObject, error) {
+	current, err := s.queries.GetPlaybook(ctx, request.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	inputs, err := playbook.ParseInputs(current.Inputs)
+	if err != nil {
+		return nil, err
+	}
+
+	tasks, err := playbook.ParseTasks(current.Tasks)
+	if err != nil {
+		return nil, err
+	}
+
+	if request.Body.Inputs != nil {
+		inputs = toPlaybookInputs(*request.Body.Inputs)
+	}
+
+	if request.Body.Tasks != nil {
+		tasks = toPlaybookTasks(*request.Body.Tasks)
+	}
+
+	if err := playbook.Validate(inputs, tasks); err != nil {
+		return openapi.UpdatePlaybook400JSONResponse{
+			Status:  http.StatusBadRequest,
+			Error:   "Bad Request",
+			Message: err.Error(),
+		}, nil
+	}
+
+	inputsData, err := json.Marshal(inputs)
+	if err != nil {
+		return nil, err
+	}
+
+	tasksData, err := json.Marshal(tasks)
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := s.queries.UpdatePlaybook(ctx, sqlc.UpdatePlaybookParams{
+		ID:          request.Id,
+		Name:        request.Body.Name,
+		Description: request.Body.Description,
+		Inputs:      inputsData,
+		Tasks:       tasksData,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return openapi.UpdatePlaybook200JSONResponse(mapPlaybook(p)), nil
+}
+
+func (s *Service) DeletePlaybook(ctx context.Context, request openapi.DeletePlaybookRequestObject) (openapi.DeletePlaybookResponseObject, error) {
+	if err := s.queries.DeletePlaybook(ctx, request.Id); err != nil {
+		return nil, err
+	}
+
+	return openapi.DeletePlaybook204Response{}, nil
+}
+
+func (s *Service) ListPlaybookInputs(ctx context.Context, request openapi.ListPlaybookInputsRequestObject) (openapi.ListPlaybookInputsResponseObject, error) {
+	p, err := s.queries.GetPlaybook(ctx, request.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	return openapi.ListPlaybookInputs200JSONResponse(mapPlaybook(p).Inputs), nil
+}
+
+func (s *Service) ListTicketPlaybooks(ctx context.Context, request openapi.ListTicketPlaybooksRequestObject) (openapi.ListTicketPlaybooksResponseObject, error) {
+	runs, err := s.queries.ListPlaybookRuns(ctx, request.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]openapi.PlaybookRun, 0, len(runs))
+
+	for _, run := range runs {
+		tasks, err := s.queries.ListPlaybookRunTasks(ctx, run.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		response = append(response, mapPlaybookRun(sqlc.PlaybookRun{
+			ID:       run.ID,
+			Playbook: run.Playbook,
+			Ticket:   run.Ticket,
+			Inputs:   run.Inputs,
+			Created:  run.Created,
+		}, run.PlaybookName, tasks))
+	}
+
+	return openapi.ListTicketPlaybooks200JSONResponse(response), nil
+}
+
+func (s *Service) AttachPlaybook(ctx context.Context, request openapi.AttachPlaybookRequestObject) (openapi.AttachPlaybookResponseObject, error) {
+	p, err := s.queries.GetPlaybook(ctx, request.Body.Playbook)
+	if err != nil {
+		return nil, err
+	}
+
+	run, err := playbook.Attach(ctx, s.queries, p.ID, request.Id, pointer.Dereference(request.Body.Inputs))
+	if errors.Is(err, playbook.ErrInvalidInputs) {
+		return openapi.AttachPlaybook400JSONResponse{
+			Status:  http.StatusBadRequest,
+			Error:   "Bad Request",
+			Message: err.Error(),
+		}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	s.publishPlaybookTasks(ctx, *run)
+
+	return openapi.AttachPlaybook200JSONResponse(mapPlaybookRun(run.Run, p.Name, run.Tasks)), nil
+}
+
+// publishPlaybookTasks runs the create hooks of the tasks created by
+// playbooks, so that reactions and notifications see them like other tasks.
+func (s *Service) publishPlaybookTasks(ctx context.Context, runs ...playbook.Run) {
+	for _, run := range runs {
+		for _, task := range mapPlaybookRun(run.Run, "", run.Tasks).Tasks {
+			s.hooks.OnRecordAfterCreateRequest.Publish(ctx, database.TasksTable.ID, task)
+		}
+	}
+}
+
+func toPlaybookInputs(inputs []openapi.PlaybookInput) []playbook.Input {
+	result := make([]playbook.Input, 0, len(inputs))
+	for _, input := range inputs {
+		result = append(result, playbook.Input{
+			Name:        input.Name,
+			Type:        string(input.Type),
+			Description: pointer.Dereference(input.Description),
+			Default:     pointer.Dereference(input.Default),
+		})
+	}
+
+	return result
+}
+
+func toPlaybookTasks(tasks []openapi.PlaybookTask) []playbook.Task {
+	result := make([]playbook.Task, 0, len(tasks))
+	for _, task := range tasks {
+		result = append(result, playbook.Task{
+			Name:  task.Name,
+			Owner: pointer.Dereference(task.Owner),
+		})
+	}
+
+	return result
+}
+
+func mapPlaybook(p sqlc.Playbook) openapi.Playbook {
+	inputs, err := playbook.ParseInputs(p.Inputs)
+	if err != nil {
+		slog.Error("Invalid playbook inputs", "playbook", p.ID, "error", err)
+	}
+
+	tasks, err := playbook.ParseTasks(p.Tasks)
+	if err != nil {
+		slog.Error("Invalid playbook tasks", "playbook", p.ID, "error", err)
+	}
+
+	response := openapi.Playbook{
+		Id:          p.ID,
+		Name:        p.Name,
+		Description: p.Description,
+		Inputs:      make([]openapi.PlaybookInput, 0, len(inputs)),
+		Tasks:       make([]openapi.PlaybookTask, 0, len(tasks)),
+		Created:     p.Created,
+		Updated:     p.Updated,
+	}
+
+	for _, input := range inputs {
+		mapped := openapi.PlaybookInput{
+			Name:     input.Name,
+			Type:     openapi.PlaybookInputType(input.Type),
+			Required: pointer.Pointer(input.Required()),
+		}
+
+		if input.Description != "" {
+			mapped.Description = &input.Description
+		}
+
+		if !input.Required() {
+			mapped.Default = &input.Default
+		}
+
+		response.Inputs = append(response.Inputs, mapped)
+	}
+
+	for _, task := range tasks {
+		mapped := openapi.PlaybookTask{Name: task.Name}
+		if task.Owner != "" {
+			mapped.Owner = &task.Owner
+		}
+
+		response.Tasks = append(response.Tasks, mapped)
+	}
+
+	return response
+}
+
+func mapPlaybookRun(run sqlc.PlaybookRun, playbookName string, tasks []sqlc.Task) openapi.PlaybookRun {
+	inputs := map[string]any{}
+	if err := json.Unmarshal(run.Inputs, &inputs); err != nil {
+		slog.Error("Invalid playbook run inputs", "run", run.ID, "error", err)
+	}
+
+	response := openapi.PlaybookRun{
+		Id:           run.ID,
+		Playbook:     run.Playbook,
+		PlaybookName: playbookName,
+		Ticket:       run.Ticket,
+		Inputs:       inputs,
+		Tasks:        make([]openapi.Task, 0, len(tasks)),
+		Created:      run.Created,
+	}
+
+	for _, task := range tasks {
+		response.Tasks = append(response.Tasks, openapi.Task{
+			Created: task.Created,
+			Id:      task.ID,
+			Name:    task.Name,
+			Open:    task.Open,
+			Owner:   task.Owner,
+			Ticket:  task.Ticket,
+			Updated: task.Updated,
+		})
+	}
+
+	return response
+}
+
+func (s *Service) ListCampaigns(ctx context.Context, request openapi.ListCampaignsRequestObject) (openapi.ListCampaignsResponseObject, error) {
+	campaigns, err := s.queries.ListCampaigns(ctx, sqlc.ListCampaignsParams{
+		Offset: toInt64(request.Params.Offset, defaultOffset),
+		Limit:  toInt64(request.Params.Limit, defaultLimit),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]openapi.Campaign, 0, len(campaigns))
+	for _, c := range campaigns {
+		response = append(response, mapCampaign(sqlc.GetCampaignRow{
+			ID:              c.ID,
+			Name:            c.Name,
+			Description:     c.Description,
+			Playbook:        c.Playbook,
+			Inputs:          c.Inputs,
+			Created:         c.Created,
+			Updated:         c.Updated,
+			FeatureKind:     c.FeatureKind,
+			FeatureValue:    c.FeatureValue,
+			TicketCount:     c.TicketCount,
+			OpenTicketCount: c.OpenTicketCount,
+			TaskCount:       c.TaskCount,
+			OpenTaskCount:   c.OpenTaskCount,
+		}))
+	}
+
+	totalCount := 0
+	if len(campaigns) > 0 {
+		totalCount = int(campaigns[0].TotalCount)
+	}
+
+	return openapi.ListCampaigns200JSONResponse{
+		Body: response,
+		Headers: openapi.ListCampaigns200ResponseHeaders{
+			XTotalCount: totalCount,
+		},
+	}, nil
+}
+
+func (s *Service) CreateCampaign(ctx context.Context, request openapi.CreateCampaignRequestObject) (openapi.CreateCampaignResponseObject, error) {
+	c, runs, err := campaign.Create(ctx, s.queries,
+		request.Body.Name,
+		pointer.Dereference(request.Body.Description),
+		request.Body.Playbook,
+		pointer.Dereference(request.Body.Inputs),
+		pointer.Dereference(request.Body.Tickets),
+	)
+	if errors.Is(err, playbook.ErrInvalidInputs) {
+		return openapi.CreateCampaign400JSONResponse{
+			Status:  http.StatusBadRequest,
+			Error:   "Bad Request",
+			Message: err.Error(),
+		}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	s.publishPlaybookTasks(ctx, runs...)
+
+	row, err := s.queries.GetCampaign(ctx, c.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return openapi.CreateCampaign200JSONResponse(mapCampaign(row)), nil
+}
+
+func (s *Service) GetCampaign(ctx context.Context, request openapi.GetCampaignRequestObject) (openapi.GetCampaignResponseObject, error) {
+	c, err := s.queries.GetCampaign(ctx, request.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	return openapi.GetCampaign200JSONResponse(mapCampaign(c)), nil
+}
+
+func (s *Service) DeleteCampaign(ctx context.Context, request openapi.DeleteCampaignRequestObject) (openapi.DeleteCampaignResponseObject, error) {
+	if err := s.queries.DeleteCampaign(ctx, request.Id); err != nil {
+		return nil, err
+	}
+
+	return openapi.DeleteCampaign204Response{}, nil
+}
+
+func (s *Service) ListCampaignTickets(ctx context.Context, request openapi.ListCampaignTicketsRequestObject) (openapi.ListCampaignTicketsResponseObject, error) {
+	tickets, err := s.campaignTickets(ctx, request.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	return openapi.ListCampaignTickets200JSONResponse(tickets), nil
+}
+
+func (s *Service) AddCampaignTickets(ctx context.Context, request openapi.AddCampaignTicketsRequestObject) (openapi.AddCampaignTicketsResponseObject, error) {
+	runs, err := campaign.AddTickets(ctx, s.queries, request.Id, request.Body.Tickets)
+	if err != nil {
+		return nil, err
+	}
+
+	s.publishPlaybookTasks(ctx, runs...)
+
+	tickets, err := s.campaignTickets(ctx, request.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	return openapi.AddCampaignTickets200JSONResponse(tickets), nil
+}
+
+func (s *Service) RemoveCampaignTicket(ctx context.Context, request openapi.RemoveCampaignTicketRequestObject) (openapi.RemoveCampaignTicketResponseObject, error) {
+	if err := s.queries.RemoveCampaignTicket(ctx, sqlc.RemoveCampaignTicketParams{
+		Campaign: request.Id,
+		Ticket:   request.Ticket,
+	}); err != nil {
+		return nil, err
+	}
+
+	return openapi.RemoveCampaignTicket204Response{}, nil
+}
+
+func (s *Service) campaignTickets(ctx context.Context, id string) ([]openapi.CampaignTicket, error) {
+	tickets, err := s.queries.ListCampaignTickets(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]openapi.CampaignTicket, 0, len(tickets))
+	for _, ticket := range tickets {
+		open := int64(0)
+		if ticket.TicketOpen {
+			open = 1
+		}
+
+		response = append(response, openapi.CampaignTicket{
+			Ticket:     ticket.Ticket,
+			TicketName: ticket.TicketName,
+			TicketOpen: ticket.TicketOpen,
+			Run:        ticket.Run,
+			Tasks:      int(ticket.TaskCount),
+			OpenTasks:  int(ticket.OpenTaskCount),
+			Completed:  campaign.Completed(ticket.TaskCount, ticket.OpenTaskCount, 1, open),
+			Created:    ticket.Created,
+		})
+	}
+
+	return response, nil
+}
+
+func mapCampaign(c sqlc.GetCampaignRow) openapi.Campaign {
+	inputs := map[string]any{}
+	if err := json.Unmarshal(c.Inputs, &inputs); err != nil {
+		slog.Error("Invalid campaign inputs", "campaign", c.ID, "error", err)
+	}
+
+	return openapi.Campaign{
+		Id:           c.ID,
+		Name:         c.Name,
+		Description:  c.Description,
+		Playbook:     c.Playbook,
+		Inputs:       inputs,
+		Tickets:      int(c.TicketCount),
+		OpenTickets:  int(c.OpenTicketCount),
+		Tasks:        int(c.TaskCount),
+		OpenTasks:    int(c.OpenTaskCount),
+		Completed:    campaign.Completed(c.TaskCount, c.OpenTaskCount, c.TicketCount, c.OpenTicketCount),
+		FeatureKind:  (*openapi.CampaignFeatureKind)(c.FeatureKind),
+		FeatureValue: c.FeatureValue,
+		Created:      c.Created,
+		Updated:      c.Updated,
+	}
+}
+
+func (s *Service) ListCampaignTimeline(ctx context.Context, request openapi.ListCampaignTimelineRequestObject) (openapi.ListCampaignTimelineResponseObject, error) {
+	timeline, err := s.queries.ListCampaignTimeline(ctx, request.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]openapi.CampaignTimeline, 0, len(timeline))
+	for _, entry := range timeline {
+		response = append(response, openapi.CampaignTimeline{
+			Id:       entry.ID,
+			Campaign: entry.Campaign,
+			Ticket:   entry.Ticket,
+			Message:  entry.Message,
+			Time:     entry.Time,
+		})
+	}
+
+	return openapi.ListCampaignTimeline200JSONResponse(response), nil
+}
+
+func (s *Service) ListTicketCampaigns(ctx context.Context, request openapi.ListTicketCampaignsRequestObject) (openapi.ListTicketCampaignsResponseObject, error) {
+	campaigns, err := s.queries.ListTicketCampaigns(ctx, request.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]openapi.Campaign, 0, len(campaigns))
+	for _, c := range campaigns {
+		response = append(response, mapCampaign(sqlc.GetCampaignRow(c)))
+	}
+
+	return openapi.ListTicketCampaigns200JSONResponse(response), nil
+}
+
+func (s *Service) GetCampaignDetectionSettings(ctx context.Context, _ openapi.GetCampaignDetectionSettingsRequestObject) (openapi.GetCampaignDetectionSettingsResponseObject, error) {
+	se, err := settings.Load(ctx, s.queries)
+	if err != nil {
+		return nil, err
+	}
+
+	return openapi.GetCampaignDetectionSettings200JSONResponse(mapCampaignDetectionSettings(&se.CampaignDetection)), nil
+}
+
+func (s *Service) UpdateCampaignDetectionSettings(ctx context.Context, request openapi.UpdateCampaignDetectionSettingsRequestObject) (openapi.UpdateCampaignDetectionSettingsResponseObject, error) {
+	if request.Body.Window < 0 || request.Body.MinTickets < 0 {
+		return nil, errors.New("the campaign detection window and minimum tickets must not be negative")
+	}
+
+	se, err := settings.Update(ctx, s.queries, func(settings *settings.Settings) {
+		settings.CampaignDetection.Enabled = request.Body.Enabled
+		settings.CampaignDetection.Window = request.Body.Window
+		settings.CampaignDetection.MinTickets = request.Body.MinTickets
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to save campaign detection settings: %w", err)
+	}
+
+	return openapi.UpdateCampaignDetectionSettings200JSONResponse(mapCampaignDetectionSettings(&se.CampaignDetection)), nil
+}
+
+func mapCampaignDetectionSettings(config *settings.CampaignDetection) openapi.CampaignDetectionSettings {
+	return openapi.CampaignDetectionSettings{
+		Enabled:    config.Enabled,
+		Window:     cmp.Or(config.Window, campaign.DefaultWindow),
+		MinTickets: cmp.Or(config.MinTickets, campaign.DefaultMinTickets),
+	}
+}
+
+func (s *Service) ListTicketTechniques(ctx context.Context, request openapi.ListTicketTechniquesRequestObject) (openapi.ListTicketTechniquesResponseObject, error) {
+	tags, err := s.ticketTechniques(ctx, request.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	return openapi.ListTicketTechniques200JSONResponse(tags), nil
+}
+
+func (s *Service) SetTicketTechniques(ctx context.Context, request openapi.SetTicketTechniquesRequestObject) (openapi.SetTicketTechniquesResponseObject, error) {
+	err := attack.SetTicketTechniques(ctx, s.queries, request.Id, toAttackTags(*request.Body))
+	if errors.Is(err, attack.ErrInvalidTechniques) {
+		return openapi.SetTicketTechniques400JSONResponse{
+			Status:  http.StatusBadRequest,
+			Error:   "Bad Request",
+			Message: err.Error(),
+		}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	tags, err := s.ticketTechniques(ctx, request.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	return openapi.SetTicketTechniques200JSONResponse(tags), nil
+}
+
+func (s *Service) ticketTechniques(ctx context.Context, id string) ([]openapi.AttackTag, error) {
+	techniques, err := s.queries.ListTicketTechniques(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	tags := make([]attack.Tag, 0, len(techniques))
+	for _, technique := range techniques {
+		tags = append(tags, attack.Tag{Technique: technique.Technique, Tactic: technique.Tactic})
+	}
+
+	return s.mapAttackTags(ctx, tags)
+}
+
+func (s *Service) ListTypeTechniques(ctx context.Context, request openapi.ListTypeTechniquesRequestObject) (openapi.ListTypeTechniquesResponseObject, error) {
+	tags, err := s.typeTechniques(ctx, request.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	return openapi.ListTypeTechniques200JSONResponse(tags), nil
+}
+
+func (s *Service) SetTypeTechniques(ctx context.Context, request openapi.SetTypeTechniquesRequestObject) (openapi.SetTypeTechniquesResponseObject, error) {
+	err := attack.SetTypeTechniques(ctx, s.queries, request.Id, toAttackTags(*request.Body))
+	if errors.Is(err, attack.ErrInvalidTechniques) {
+		return openapi.SetTypeTechniques400JSONResponse{
+			Status:  http.StatusBadRequest,
+			Error:   "Bad Request",
+			Message: err.Error(),
+		}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	tags, err := s.typeTechniques(ctx, request.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	return openapi.SetTypeTechniques200JSONResponse(tags), nil
+}
+
+func (s *Service) typeTechniques(ctx context.Context, id string) ([]openapi.AttackTag, error) {
+	techniques, err := s.queries.ListTypeTechniques(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	tags := make([]attack.Tag, 0, len(techniques))
+	for _, technique := range techniques {
+		tags = append(tags, attack.Tag{Technique: technique.Technique, Tactic: technique.Tactic})
+	}
+
+	return s.mapAttackTags(ctx, tags)
+}
+
+func toAttackTags(tags []openapi.AttackTag) []attack.Tag {
+	result := make([]attack.Tag, 0, len(tags))
+	for _, tag := range tags {
+		result = append(result, attack.Tag{Technique: tag.Technique, Tactic: pointer.Dereference(tag.Tactic)})
+	}
+
+	return result
+}
+
+// mapAttackTags adds the names of the techniques and tactics from the
+// catalog, they are empty for techniques that are missing in the catalog.
+func (s *Service) mapAttackTags(ctx context.Context, tags []attack.Tag) ([]openapi.AttackTag, error) {
+	catalog, err := attack.Load(ctx, s.queries)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]openapi.AttackTag, 0, len(tags))
+	for _, tag := range tags {
+		technique, _ := catalog.Technique(tag.Technique)
+		tactic, _ := catalog.Tactic(tag.Tactic)
+
+		response = append(response, openapi.AttackTag{
+			Technique:     tag.Technique,
+			Tactic:        &tag.Tactic,
+			TechniqueName: &technique.Name,
+			TacticName:    &tactic.Name,
+		})
+	}
+
+	return response, nil
+}
+
+func (s *Service) GetAttackCatalog(ctx context.Context, _ openapi.GetAttackCatalogRequestObject) (openapi.GetAttackCatalogResponseObject, error) {
+	catalog, err := attack.Load(ctx, s.queries)
+	if err != nil {
+		return nil, err
+	}
+
+	return openapi.GetAttackCatalog200JSONResponse(mapAttackCatalog(catalog)), nil
+}
+
+func (s *Service) UpdateAttackCatalog(ctx context.Context, request openapi.UpdateAttackCatalogRequestObject) (openapi.UpdateAttackCatalogResponseObject, error) {
+	b, err := json.Marshal(request.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	catalog, err := attack.Parse(b)
+	if err != nil {
+		return openapi.UpdateAttackCatalog400JSONResponse{
+			Status:  http.StatusBadRequest,
+			Error:   "Bad Request",
+			Message: err.Error(),
+		}, nil
+	}
+
+	if err := attack.Save(ctx, s.queries, catalog); err != nil {
+		return nil, fmt.Errorf("failed to save the ATT&CK catalog: %w", err)
+	}
+
+	return openapi.UpdateAttackCatalog200JSONResponse(mapAttackCatalog(catalog)), nil
+}
+
+func (s *Service) ResetAttackCatalog(ctx context.Context, _ openapi.ResetAttackCatalogRequestObject) (openapi.ResetAttackCatalogResponseObject, error) {
+	catalog := attack.Bundled()
+
+	if err := attack.Save(ctx, s.queries, catalog); err != nil {
+		return nil, fmt.Errorf("failed to save the ATT&CK catalog: %w", err)
+	}
+
+	return openapi.ResetAttackCatalog200JSONResponse(mapAttackCatalog(catalog)), nil
+}
+
+func mapAttackCatalog(catalog *attack.Catalog) openapi.AttackCatalog {
+	response := openapi.AttackCatalog{
+		Version:    catalog.Version,
+		Tactics:    make([]openapi.AttackTactic, 0, len(catalog.Tactics)),
+		Techniques: make([]openapi.AttackTechnique, 0, len(catalog.Techniques)),
+	}
+
+	for _, tactic := range catalog.Tactics {
+		response.Tactics = append(response.Tactics, openapi.AttackTactic{Id: tactic.ID, Shortname: tactic.Shortname, Name: tactic.Name})
+	}
+
+	for _, technique := range catalog.Techniques {
+		response.Techniques = append(response.Techniques, openapi.AttackTechnique{Id: technique.ID, Name: technique.Name, Tactics: technique.Tactics})
+	}
+
+	return response
+}
+
+// attackMatrixWindow is the default period of the ATT&CK matrix.
+const attackMatrixWindow = 365 * 24 * time.Hour
+
+func (s *Service) GetAttackMatrix(ctx context.Context, request openapi.GetAttackMatrixRequestObject) (openapi.GetAttackMatrixResponseObject, error) {
+	since := time.Now().Add(-attackMatrixWindow)
+	if request.Params.Since != nil {
+		since = *request.Params.Since
+	}
+
+	catalog, err := attack.Load(ctx, s.queries)
+	if err != nil {
+		return nil, err
+	}
+
+	tags, err := s.queries.ListTechniqueTickets(ctx, sqlc.ListTechniqueTicketsParams{
+		Type:  request.Params.Type,
+		Since: since.UTC().Format(time.DateTime),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	matrix := catalog.Matrix(tags)
+
+	response := openapi.AttackMatrix{
+		Version: matrix.Version,
+		Tickets: matrix.Tickets,
+		Tactics: make([]openapi.AttackMatrixTactic, 0, len(matrix.Tactics)),
+	}
+
+	for _, tactic := range matrix.Tactics {
+		techniques := make([]openapi.AttackMatrixTechnique, 0, len(tactic.Techniques))
+		for _, technique := range tactic.Techniques {
+			techniques = append(techniques, mapAttackMatrixTechnique(technique))
+		}
+
+		response.Tactics = append(response.Tactics, openapi.AttackMatrixTactic{
+			Id:         tactic.ID,
+			Shortname:  tactic.Shortname,
+			Name:       tactic.Name,
+			Tickets:    tactic.Tickets,
+			Techniques: techniques,
+		})
+	}
+
+	return openapi.GetAttackMatrix200JSONResponse(response), nil
+}
+
+func mapAttackMatrixTechnique(technique attack.MatrixTechnique) openapi.AttackMatrixTechnique {
+	response := openapi.AttackMatrixTechnique{
+		Id:      technique.ID,
+		Name:    technique.Name,
+		Tickets: technique.Tickets,
+	}
+
+	if len(technique.Subtechniques) > 0 {
+		subtechniques := make([]openapi.AttackMatrixTechnique, 0, len(technique.Subtechniques))
+		for _, sub := range technique.Subtechniques {
+			subtechniques = append(subtechniques, mapAttackMatrixTechnique(sub))
+		}
+
+		response.Subtechniques = &subtechniques
+	}
+
+	return response
+}
+
+func (s *Service) ListCVEs(ctx context.Context, request openapi.ListCVEsRequestObject) (openapi.ListCVEsResponseObject, error) {
+	cves, err := s.queries.ListCVEs(ctx, sqlc.ListCVEsParams{
+		Kev:      request.Params.Kev,
+		MinScore: request.Params.MinScore,
+		Offset:   toInt64(request.Params.Offset, defaultOffset),
+		Limit:    toInt64(request.Params.Limit, defaultLimit),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]openapi.CVE, 0, len(cves))
+	for _, c := range cves {
+		response = append(response, mapCVE(sqlc.GetCVERow{
+			ID:              c.ID,
+			Description:     c.Description,
+			CvssScore:       c.CvssScore,
+			CvssSeverity:    c.CvssSeverity,
+			CvssVector:      c.CvssVector,
+			Products:        c.Products,
+			Published:       c.Published,
+			Enriched:        c.Enriched,
+			Created:         c.Created,
+			Kev:             c.Kev,
+			KevAdded:        c.KevAdded,
+			KevDue:          c.KevDue,
+			KevRansomware:   c.KevRansomware,
+			TicketCount:     c.TicketCount,
+			OpenTicketCount: c.OpenTicketCount,
+		}))
+	}
+
+	totalCount := 0
+	if len(cves) > 0 {
+		totalCount = int(cves[0].TotalCount)
+	}
+
+	return openapi.ListCVEs200JSONResponse{
+		Body: response,
+		Headers: openapi.ListCVEs200ResponseHeaders{
+			XTotalCount: totalCount,
+		},
+	}, nil
+}
+
+func (s *Service) GetCVE(ctx context.Context, request openapi.GetCVERequestObject) (openapi.GetCVEResponseObject, error) {
+	c, err := s.queries.GetCVE(ctx, strings.ToUpper(request.Id))
+	if err != nil {
+		return nil, err
+	}
+
+	return openapi.GetCVE200JSONResponse(mapCVE(c)), nil
+}
+
+func (s *Service) EnrichCVE(ctx context.Context, request openapi.EnrichCVERequestObject) (openapi.EnrichCVEResponseObject, error) {
+	id := strings.ToUpper(request.Id)
+
+	if _, err := s.queries.GetCVE(ctx, id); err != nil {
+		return nil, err
+	}
+
+	err := cve.New(s.queries).Enrich(ctx, id)
+	if errors.Is(err, cve.ErrDisabled) {
+		return openapi.EnrichCVE404JSONResponse{
+			Status:  http.StatusNotFound,
+			Error:   "Not Found",
+			Message: "CVE enrichment is disabled",
+		}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	c, err := s.queries.GetCVE(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return openapi.EnrichCVE200JSONResponse(mapCVE(c)), nil
+}
+
+func (s *Service) ListTicketCVEs(ctx context.Context, request openapi.ListTicketCVEsRequestObject) (openapi.ListTicketCVEsResponseObject, error) {
+	cves, err := s.queries.ListTicketCVEs(ctx, request.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]openapi.CVE, 0, len(cves))
+	for _, c := range cves {
+		response = append(response, mapCVE(sqlc.GetCVERow(c)))
+	}
+
+	return openapi.ListTicketCVEs200JSONResponse(response), nil
+}
+
+func (s *Service) ListTicketArtifacts(ctx context.Context, request openapi.ListTicketArtifactsRequestObject) (openapi.ListTicketArtifactsResponseObject, error) {
+	artifacts, err := s.queries.ListTicketArtifacts(ctx, request.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]openapi.TicketArtifact, 0, len(artifacts))
+	for _, a := range artifacts {
+		response = append(response, openapi.TicketArtifact{Kind: a.Kind, Value: a.Value, Created: a.Created})
+	}
+
+	return openapi.ListTicketArtifacts200JSONResponse(response), nil
+}
+
+func (s *Service) AddTicketArtifacts(ctx context.Context, request openapi.AddTicketArtifactsRequestObject) (openapi.AddTicketArtifactsResponseObject, error) {
+	// the lines of the CSV and the positions in the list would be ambiguous
+	if request.Body.Csv != nil && request.Body.Artifacts != nil {
+		return openapi.AddTicketArtifacts400JSONResponse{
+			Status:  http.StatusBadRequest,
+			Error:   "Bad Request",
+			Message: "Send either csv or artifacts",
+		}, nil
+	}
+
+	rows, err := artifact.ParseCSV(pointer.Dereference(request.Body.Csv))
+	if errors.Is(err, artifact.ErrInvalidInput) {
+		return openapi.AddTicketArtifacts400JSONResponse{
+			Status:  http.StatusBadRequest,
+			Error:   "Bad Request",
+			Message: err.Error(),
+		}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	for i, a := range pointer.Dereference(request.Body.Artifacts) {
+		rows = append(rows, artifact.Row{Line: i + 1, Kind: pointer.Dereference(a.Kind), Value: a.Value})
+	}
+
+	results, err := artifact.Add(ctx, s.queries, request.Id, rows)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return openapi.AddTicketArtifacts404JSONResponse{
+			Status:  http.StatusNotFound,
+			Error:   "Not Found",
+			Message: "The ticket does not exist",
+		}, nil
+	case errors.Is(err, artifact.ErrInvalidInput):
+		return openapi.AddTicketArtifacts400JSONResponse{
+			Status:  http.StatusBadRequest,
+			Error:   "Bad Request",
+			Message: err.Error(),
+		}, nil
+	case err != nil:
+		return nil, err
+	}
+
+	return openapi.AddTicketArtifacts200JSONResponse(mapArtifactResults(results)), nil
+}
+
+// canonicalArtifact returns the kind and the canonical form of an artifact
+// value. The kind is detected if it is empty.
+func canonicalArtifact(kind, value string) (string, string, error) {
+	if kind == "" {
+		kind = canonical.Detect(value)
+	}
+
+	value, err := canonical.Canonicalize(kind, value)
+	if err != nil {
+		if kind == "" {
+			return "", "", errors.New("the kind could not be detected")
+		}
+
+		return "", "", err
+	}
+
+	return kind, value, nil
+}
+
+func (s *Service) SearchArtifacts(ctx context.Context, request openapi.SearchArtifactsRequestObject) (openapi.SearchArtifactsResponseObject, error) {
+	kind, value, err := canonicalArtifact(pointer.Dereference(request.Params.Type), request.Params.Value)
+	if err != nil {
+		return openapi.SearchArtifacts400JSONResponse{
+			Status:  http.StatusBadRequest,
+			Error:   "Bad Request",
+			Message: err.Error(),
+		}, nil
+	}
+
+	tickets, err := s.queries.SearchTicketArtifacts(ctx, sqlc.SearchTicketArtifactsParams{
+		Kind:   kind,
+		Value:  value,
+		Offset: toInt64(request.Params.Offset, defaultOffset),
+		Limit:  toInt64(request.Params.Limit, defaultLimit),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	feeds, err := s.queries.MatchFeedIndicators(ctx, value)
+	if err != nil {
+		return nil, err
+	}
+
+	enrichments, err := enrichment.New(s.queries).List(ctx, value)
+	if err != nil {
+		return nil, err
+	}
+
+	response := openapi.ArtifactSearchResult{
+		Kind:        kind,
+		Value:       value,
+		Tickets:     make([]openapi.ArtifactTicket, 0, len(tickets)),
+		Feeds:       make([]openapi.FeedMatch, 0, len(feeds)),
+		Enrichments: make([]openapi.Enrichment, 0, len(enrichments)),
+		Files:       []openapi.ArtifactFile{},
+	}
+
+	if kind == canonical.Hash {
+		files, err := s.queries.SearchFilesByHash(ctx, value)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, file := range files {
+			response.Files = append(response.Files, openapi.ArtifactFile{
+				Id:         file.ID,
+				Name:       file.Name,
+				Size:       file.Size,
+				Ticket:     file.Ticket,
+				TicketKey:  file.TicketKey,
+				TicketName: file.TicketName,
+				Uploaded:   file.Created,
+			})
+		}
+	}
+
+	for _, ticket := range tickets {
+		response.TicketCount = int(ticket.TotalCount)
+		response.Tickets = append(response.Tickets, openapi.ArtifactTicket{
+			Id:    ticket.ID,
+			Key:   ticket.Key,
+			Name:  ticket.Name,
+			Type:  ticket.Type,
+			Open:  ticket.Open,
+			Added: ticket.Created,
+		})
+	}
+
+	for _, match := range feeds {
+		response.Feeds = append(response.Feeds, openapi.FeedMatch{
+			Feed:     match.Feed,
+			Kind:     match.Kind,
+			Imported: match.Imported,
+		})
+	}
+
+	for _, entry := range enrichments {
+		response.Enrichments = append(response.Enrichments, mapEnrichment(entry))
+	}
+
+	return openapi.SearchArtifacts200JSONResponse(response), nil
+}
+
+func (s *Service) ListArtifactSightings(ctx context.Context, request openapi.ListArtifactSightingsRequestObject) (openapi.ListArtifactSightingsResponseObject, error) {
+	kind, value, err := canonicalArtifact(pointer.Dereference(request.Params.Kind), request.Value)
+	if err != nil {
+		return openapi.ListArtifactSightings400JSONResponse{
+			Status:  http.StatusBadRequest,
+			Error:   "Bad Request",
+			Message: err.Error(),
+		}, nil
+	}
+
+	count, err := s.queries.CountArtifactSightings(ctx, sqlc.CountArtifactSightingsParams{Kind: kind, Value: value})
+	if err != nil {
+		return nil, err
+	}
+
+	sightings, err := s.queries.ListArtifactSightings(ctx, sqlc.ListArtifactSightingsParams{
+		Kind:   kind,
+		Value:  value,
+		Offset: toInt64(request.Params.Offset, defaultOffset),
+		Limit:  toInt64(request.Params.Limit, defaultLimit),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := openapi.ArtifactSightings{
+		Kind:      kind,
+		Value:     value,
+		Count:     int(count),
+		Sightings: make([]openapi.ArtifactSighting, 0, len(sightings)),
+	}
+
+	if count > 0 {
+		first, err := s.queries.FirstArtifactSighting(ctx, sqlc.FirstArtifactSightingParams{Kind: kind, Value: value})
+		if err != nil {
+			return nil, err
+		}
+
+		last, err := s.queries.LastArtifactSighting(ctx, sqlc.LastArtifactSightingParams{Kind: kind, Value: value})
+		if err != nil {
+			return nil, err
+		}
+
+		response.FirstSeen, response.LastSeen = &first.Seen, &last.Seen
+	}
+
+	for _, sighting := range sightings {
+		response.Sightings = append(response.Sightings, openapi.ArtifactSighting{
+			Id:        sighting.ID,
+			Kind:      sighting.Kind,
+			Value:     sighting.Value,
+			Ticket:    sighting.Ticket,
+			Source:    openapi.ArtifactSightingSource(sighting.Source),
+			Reference: sighting.Reference,
+			Seen:      sighting.Seen,
+		})
+	}
+
+	return openapi.ListArtifactSightings200JSONResponse(response), nil
+}
+
+func mapArtifactResults(results []artifact.Result) openapi.BulkArtifactsResult {
+	response := openapi.BulkArtifactsResult{Results: make([]openapi.ArtifactResult, 0, len(results))}
+
+	for _, r := range results {
+		switch r.Status {
+		case artifact.Added:
+			response.Added++
+		case artifact.Duplicate:
+			response.Duplicates++
+		case artifact.Invalid:
+			response.Invalid++
+		}
+
+		result := openapi.ArtifactResult{
+			Line:   r.Line,
+			Input:  r.Input,
+			Kind:   r.Kind,
+			Status: openapi.ArtifactResultStatus(r.Status),
+		}
+
+		if r.Value != "" {
+			result.Value = &r.Value
+		}
+
+		if r.Error != "" {
+			result.Error = &r.Error
+		}
+
+		response.Results = append(response.Results, result)
+	}
+
+	return response
+}
+
+func (s *Service) ListCVETickets(ctx context.Context, request openapi.ListCVETicketsRequestObject) (openapi.ListCVETicketsResponseObject, error) {
+	var id *string
+	if request.Params.Cve != nil {
+		id = pointer.Pointer(strings.ToUpper(*request.Params.Cve))
+	}
+
+	tickets, err := s.queries.ListCVETickets(ctx, sqlc.ListCVETicketsParams{
+		Cve:      id,
+		Open:     request.Params.Open,
+		Kev:      request.Params.Kev,
+		MinScore: request.Params.MinScore,
+		Offset:   toInt64(request.Params.Offset, defaultOffset),
+		Limit:    toInt64(request.Params.Limit, defaultLimit),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]openapi.CVETicket, 0, len(tickets))
+	for _, ticket := range tickets {
+		response = append(response, openapi.CVETicket{
+			Ticket:       ticket.Ticket,
+			TicketName:   ticket.TicketName,
+			TicketType:   ticket.TicketType,
+			TicketOpen:   ticket.TicketOpen,
+			Cve:          ticket.Cve,
+			CvssScore:    ticket.CvssScore,
+			CvssSeverity: ticket.CvssSeverity,
+			Kev:          ticket.Kev,
+			Created:      ticket.Created,
+		})
+	}
+
+	totalCount := 0
+	if len(tickets) > 0 {
+		totalCount = int(tickets[0].TotalCount)
+	}
+
+	return openapi.ListCVETickets200JSONResponse{
+		Body: response,
+		Headers: openapi.ListCVETickets200ResponseHeaders{
+			XTotalCount: totalCount,
+		},
+	}, nil
+}
+
+func mapCVE(c sqlc.GetCVERow) openapi.CVE {
+	products := []string{}
+	if err := json.Unmarshal(c.Products, &products); err != nil {
+		slog.Error("Invalid CVE products", "cve", c.ID, "error", err)
+	}
+
+	response := openapi.CVE{
+		Id:            c.ID,
+		Description:   c.Description,
+		CvssScore:     c.CvssScore,
+		CvssSeverity:  c.CvssSeverity,
+		CvssVector:    c.CvssVector,
+		Products:      products,
+		Published:     c.Published,
+		Enriched:      c.Enriched,
+		Kev:           c.Kev,
+		KevRansomware: c.KevRansomware,
+		Tickets:       int(c.TicketCount),
+		OpenTickets:   int(c.OpenTicketCount),
+		Created:       c.Created,
+	}
+
+	if c.Kev {
+		response.KevAdded = &c.KevAdded
+		response.KevDue = &c.KevDue
+	}
+
+	return response
+}
+
+func (s *Service) GetCVESettings(ctx context.Context, _ openapi.GetCVESettingsRequestObject) (openapi.GetCVESettingsResponseObject, error) {
+	se, err := settings.Load(ctx, s.queries)
+	if err != nil {
+		return nil, err
+	}
+
+	return openapi.GetCVESettings200JSONResponse(mapCVESettings(&se.CVEEnrichment)), nil
+}
+
+func (s *Service) UpdateCVESettings(ctx context.Context, request openapi.UpdateCVESettingsRequestObject) (openapi.UpdateCVESettingsResponseObject, error) {
+	for _, u := range []string{request.Body.NvdUrl, request.Body.OsvUrl, request.Body.KevUrl} {
+		if u == "" {
+			continue
+		}
+
+		parsed, err := url.Parse(u)
+		if err != nil || (parsed.Scheme != "https" && parsed.Scheme != "http") || parsed.Host == "" {
+			return nil, fmt.Errorf("invalid CVE enrichment URL %q", u)
+		}
+	}
+
+	se, err := settings.Update(ctx, s.queries, func(settings *settings.Settings) {
+		settings.CVEEnrichment.Enabled = request.Body.Enabled
+		settings.CVEEnrichment.NVDURL = request.Body.NvdUrl
+		settings.CVEEnrichment.OSVURL = request.Body.OsvUrl
+		settings.CVEEnrichment.KEVURL = request.Body.KevUrl
+
+		// the redacted value from GetCVESettings keeps the stored secret
+		if request.Body.NvdApiKey != redacted {
+			settings.CVEEnrichment.NVDAPIKey = request.Body.NvdApiKey
+		}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to save CVE enrichment settings: %w", err)
+	}
+
+	return openapi.UpdateCVESettings200JSONResponse(mapCVESettings(&se.CVEEnrichment)), nil
+}
+
+func mapCVESettings(config *settings.CVEEnrichment) openapi.CVESettings {
+	cveSettings := openapi.CVESettings{
+		Enabled: config.Enabled,
+		NvdUrl:  cmp.Or(config.NVDURL, cve.DefaultNVDURL),
+		OsvUrl:  cmp.Or(config.OSVURL, cve.DefaultOSVURL),
+		KevUrl:  cmp.Or(config.KEVURL, cve.DefaultKEVURL),
+	}
+
+	if config.NVDAPIKey != "" {
+		cveSettings.NvdApiKey = redacted
+	}
+
+	return cveSettings
+}
+
+func (s *Service) ListDetectionRules(ctx context.Context, request openapi.ListDetectionRulesRequestObject) (openapi.ListDetectionRulesResponseObject, error) {
+	var status *string
+	if request.Params.Status != nil {
+		status = pointer.Pointer(string(*request.Params.Status))
+	}
+
+	rules, err := s.queries.ListDetectionRules(ctx, sqlc.ListDetectionRulesParams{
+		Status: status,
+		Offset: toInt64(request.Params.Offset, defaultOffset),
+		Limit:  toInt64(request.Params.Limit, defaultLimit),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]openapi.DetectionRule, 0, len(rules))
+	for _, r := range rules {
+		response = append(response, mapDetectionRule(sqlc.GetDetectionRuleRow{
+			ID:              r.ID,
+			Ticket:          r.Ticket,
+			Name:            r.Name,
+			Description:     r.Description,
+			Format:          r.Format,
+			Content:         r.Content,
+			Status:          r.Status,
+			Author:          r.Author,
+			Reviewer:        r.Reviewer,
+			Deployed:        r.Deployed,
+			Created:         r.Created,
+			Updated:         r.Updated,
+			TicketCount:     r.TicketCount,
+			OpenTicketCount: r.OpenTicketCount,
+		}))
+	}
+
+	totalCount := 0
+	if len(rules) > 0 {
+		totalCount = int(rules[0].TotalCount)
+	}
+
+	return openapi.ListDetectionRules200JSONResponse{
+		Body: response,
+		Headers: openapi.ListDetectionRules200ResponseHeaders{
+			XTotalCount: totalCount,
+		},
+	}, nil
+}
+
+func (s *Service) CreateDetectionRule(ctx context.Context, request openapi.CreateDetectionRuleRequestObject) (openapi.CreateDetectionRuleResponseObject, error) {
+	var author *string
+	if user, ok := usercontext.UserFromContext(ctx); ok {
+		author = &user.ID
+	}
+
+	rule, err := s.queries.CreateDetectionRule(ctx, sqlc.CreateDetectionRuleParams{
+		Ticket:      request.Body.Ticket,
+		Name:        request.Body.Name,
+		Description: pointer.Dereference(request.Body.Description),
+		Format:      cmp.Or(pointer.Dereference(request.Body.Format), "sigma"),
+		Content:     request.Body.Content,
+		Author:      author,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := s.detectionRule(ctx, rule.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.hooks.OnRecordAfterCreateRequest.Publish(ctx, database.DetectionRulesTable.ID, response)
+
+	return openapi.CreateDetectionRule200JSONResponse(response), nil
+}
+
+func (s *Service) GetDetectionRule(ctx context.Context, request openapi.GetDetectionRuleRequestObject) (openapi.GetDetectionRuleResponseObject, error) {
+	response, err := s.detectionRule(ctx, request.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	return openapi.GetDetectionRule200JSONResponse(response), nil
+}
+
+func (s *Service) UpdateDetectionRule(ctx context.Context, request openapi.UpdateDetectionRuleRequestObject) (openapi.UpdateDetectionRuleResponseObject, error) {
+	previous, err := s.detectionRule(ctx, request.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := detection.Update(ctx, s.queries, sqlc.UpdateDetectionRuleParams{
+		ID:          request.Id,
+		Name:        request.Body.Name,
+		Description: request.Body.Description,
+		Format:      request.Body.Format,
+		Content:     request.Body.Content,
+	}); errors.Is(err, detection.ErrNotEditable) {
+		return openapi.UpdateDetectionRule400JSONResponse{
+			Status:  http.StatusBadRequest,
+			Error:   "Bad Request",
+			Message: err.Error(),
+		}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	response, err := s.detectionRule(ctx, request.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	s.hooks.OnRecordAfterUpdateRequest.Publish(hook.WithPrevious(ctx, previous), database.DetectionRulesTable.ID, response)
+
+	return openapi.UpdateDetectionRule200JSONResponse(response), nil
+}
+
+func (s *Service) DeleteDetectionRule(ctx context.Context, request openapi.DeleteDetectionRuleRequestObject) (openapi.DeleteDetectionRuleResponseObject, error) {
+	if err := s.queries.DeleteDetectionRule(ctx, request.Id); err != nil {
+		return nil, err
+	}
+
+	s.hooks.OnRecordAfterDeleteRequest.Publish(ctx, database.DetectionRulesTable.ID, request.Id)
+
+	return openapi.DeleteDetectionRule204Response{}, nil
+}
+
+// TransitionDetectionRule moves a rule through its lifecycle. The update
+// hooks carry the previous rule, so a reaction that is triggered by changes
+// of the status can deploy approved rules to the SIEM and report the
+// deployment back with another transition.
+func (s *Service) TransitionDetectionRule(ctx context.Context, request openapi.TransitionDetectionRuleRequestObject) (openapi.TransitionDetectionRuleResponseObject, error) {
+	user, ok := usercontext.UserFromContext(ctx)
+	if !ok {
+		return nil, errors.New("missing user")
+	}
+
+	previous, err := s.detectionRule(ctx, request.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, _, err := detection.Transition(ctx, s.queries, request.Id, string(request.Body.Status), user, pointer.Dereference(request.Body.Comment)); errors.Is(err, detection.ErrInvalidTransition) || errors.Is(err, detection.ErrSelfReview) {
+		return openapi.TransitionDetectionRule400JSONResponse{
+			Status:  http.StatusBadRequest,
+			Error:   "Bad Request",
+			Message: err.Error(),
+		}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	response, err := s.detectionRule(ctx, request.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	s.hooks.OnRecordAfterUpdateRequest.Publish(hook.WithPrevious(ctx, previous), database.DetectionRulesTable.ID, response)
+
+	return openapi.TransitionDetectionRule200JSONResponse(response), nil
+}
+
+func (s *Service) ListDetectionRuleTickets(ctx context.Context, request openapi.ListDetectionRuleTicketsRequestObject) (openapi.ListDetectionRuleTicketsResponseObject, error) {
+	tickets, err := s.detectionRuleTickets(ctx, request.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	return openapi.ListDetectionRuleTickets200JSONResponse(tickets), nil
+}
+
+func (s *Service) AddDetectionRuleTickets(ctx context.Context, request openapi.AddDetectionRuleTicketsRequestObject) (openapi.AddDetectionRuleTicketsResponseObject, error) {
+	for _, ticket := range request.Body.Tickets {
+		if err := s.queries.AddDetectionRuleTicket(ctx, sqlc.AddDetectionRuleTicketParams{
+			Rule:   request.Id,
+			Ticket: ticket,
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	tickets, err := s.detectionRuleTickets(ctx, request.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	return openapi.AddDetectionRuleTickets200JSONResponse(tickets), nil
+}
+
+func (s *Service) RemoveDetectionRuleTicket(ctx context.Context, request openapi.RemoveDetectionRuleTicketRequestObject) (openapi.RemoveDetectionRuleTicketResponseObject, error) {
+	if err := s.queries.RemoveDetectionRuleTicket(ctx, sqlc.RemoveDetectionRuleTicketParams{
+		Rule:   request.Id,
+		Ticket: request.Ticket,
+	}); err != nil {
+		return nil, err
+	}
+
+	return openapi.RemoveDetectionRuleTicket204Response{}, nil
+}
+
+func (s *Service) GetDetectionRulePerformance(ctx context.Context, request openapi.GetDetectionRulePerformanceRequestObject) (openapi.GetDetectionRulePerformanceResponseObject, error) {
+	if _, err := s.queries.GetDetectionRule(ctx, request.Id); err != nil {
+		return nil, err
+	}
+
+	performance, err := detection.Measure(ctx, s.queries, request.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	dispositions := make([]openapi.DetectionRuleDisposition, 0, len(performance.Dispositions))
+	for _, d := range performance.Dispositions {
+		dispositions = append(dispositions, openapi.DetectionRuleDisposition{
+			Resolution:  d.Resolution,
+			Disposition: openapi.Disposition(detection.Classify(d.Resolution)),
+			Count:       int(d.Count),
+		})
+	}
+
+	return openapi.GetDetectionRulePerformance200JSONResponse{
+		Closed:         int(performance.Closed),
+		TruePositives:  int(performance.TruePositives),
+		FalsePositives: int(performance.FalsePositives),
+		Precision:      performance.Precision,
+		Dispositions:   dispositions,
+	}, nil
+}
+
+func (s *Service) ListTicketDetectionRules(ctx context.Context, request openapi.ListTicketDetectionRulesRequestObject) (openapi.ListTicketDetectionRulesResponseObject, error) {
+	rules, err := s.queries.ListTicketDetectionRules(ctx, request.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]openapi.DetectionRule, 0, len(rules))
+	for _, r := range rules {
+		rule, err := s.detectionRule(ctx, r.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		response = append(response, rule)
+	}
+
+	return openapi.ListTicketDetectionRules200JSONResponse(response), nil
+}
+
+func (s *Service) detectionRule(ctx context.Context, id string) (openapi.DetectionRule, error) {
+	rule, err := s.queries.GetDetectionRule(ctx, id)
+	if err != nil {
+		return openapi.DetectionRule{}, err
+	}
+
+	return mapDetectionRule(rule), nil
+}
+
+func (s *Service) detectionRuleTickets(ctx context.Context, id string) ([]openapi.DetectionRuleTicket, error) {
+	tickets, err := s.queries.ListDetectionRuleTickets(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]openapi.DetectionRuleTicket, 0, len(tickets))
+	for _, t := range tickets {
+		ticket := openapi.DetectionRuleTicket{
+			Id:         t.ID,
+			Name:       t.Name,
+			Open:       t.Open,
+			Resolution: t.Resolution,
+			Created:    t.Created,
+			Resolved:   t.Resolved,
+		}
+
+		if !t.Open {
+			ticket.Disposition = pointer.Pointer(openapi.Disposition(detection.Classify(pointer.Dereference(t.Resolution))))
+		}
+
+		response = append(response, ticket)
+	}
+
+	return response, nil
+}
+
+func mapDetectionRule(r sqlc.GetDetectionRuleRow) openapi.DetectionRule {
+	transitions := []openapi.DetectionRuleStatus{}
+	for _, status := range detection.Transitions(r.Status) {
+		transitions = append(transitions, openapi.DetectionRuleStatus(status))
+	}
+
+	return openapi.DetectionRule{
+		Id:          r.ID,
+		Ticket:      r.Ticket,
+		Name:        r.Name,
+		Description: r.Description,
+		Format:      r.Format,
+		Content:     r.Content,
+		Status:      openapi.DetectionRuleStatus(r.Status),
+		Transitions: transitions,
+		Author:      r.Author,
+		Reviewer:    r.Reviewer,
+		Deployed:    r.Deployed,
+		Tickets:     int(r.TicketCount),
+		OpenTickets: int(r.OpenTicketCount),
+		Created:     r.Created,
+		Updated:     r.Updated,
+	}
+}
+
+func (s *Service) ListTicketActivity(ctx context.Context, request openapi.ListTicketActivityRequestObject) (openapi.ListTicketActivityResponseObject, error) {
+	activity, err := s.queries.ListTicketActivity(ctx, sqlc.ListTicketActivityParams{
+		Ticket: request.Id,
+		Offset: toInt64(request.Params.Offset, defaultOffset),
+		Limit:  toInt64(request.Params.Limit, defaultLimit),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]openapi.TicketActivity, 0, len(activity))
+	for _, a := range activity {
+		response = append(response, openapi.TicketActivity{
+			Id:       a.ID,
+			Ticket:   a.Ticket,
+			User:     a.User,
+			UserName: a.UserName,
+			Method:   a.Method,
+			Path:     a.Path,
+			Body:     a.Body,
+			Status:   int(a.Status),
+			Duration: int(a.Duration),
+			Created:  a.Created,
+		})
+	}
+
+	totalCount := 0
+	if len(activity) > 0 {
+		totalCount = int(activity[0].TotalCount)
+	}
+
+	return openapi.ListTicketActivity200JSONResponse{
+		Body: response,
+		Headers: openapi.ListTicketActivity200ResponseHeaders{
+			XTotalCount: totalCount,
+		},
+	}, nil
+}
+
+func (s *Service) ListFederationPeers(ctx context.Context, request openapi.ListFederationPeersRequestObject) (openapi.ListFederationPeersResponseObject, error) {
+	peers, err := s.queries.ListFederationPeers(ctx, sqlc.ListFederationPeersParams{
+		Offset: toInt64(request.Params.Offset, defaultOffset),
+		Limit:  toInt64(request.Params.Limit, defaultLimit),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]openapi.FederationPeer, 0, len(peers))
+	for _, peer := range peers {
+		response = append(response, mapFederationPeer(&sqlc.FederationPeer{
+			ID:      peer.ID,
+			Name:    peer.Name,
+			Url:     peer.Url,
+			Secret:  peer.Secret,
+			MaxTlp:  peer.MaxTlp,
+			Created: peer.Created,
+			Updated: peer.Updated,
+		}))
+	}
+
+	totalCount := 0
+	if len(peers) > 0 {
+		totalCount = int(peers[0].TotalCount)
+	}
+
+	return openapi.ListFederationPeers200JSONResponse{
+		Body: response,
+		Headers: openapi.ListFederationPeers200ResponseHeaders{
+			XTotalCount: totalCount,
+		},
+	}, nil
+}
+
+func (s *Service) CreateFederationPeer(ctx context.Context, request openapi.CreateFederationPeerRequestObject) (openapi.CreateFederationPeerResponseObject, error) {
+	peerURL, err := federation.ValidatePeerURL(request.Body.Url)
+	if err != nil {
+		return openapi.CreateFederationPeer400JSONResponse{Status: http.StatusBadRequest, Error: "Bad Request", Message: err.Error()}, nil
+	}
+
+	maxTLP, err := federation.NormalizeTLP(request.Body.MaxTlp)
+	if err != nil {
+		return openapi.CreateFederationPeer400JSONResponse{Status: http.StatusBadRequest, Error: "Bad Request", Message: err.Error()}, nil
+	}
+
+	peer, err := s.queries.CreateFederationPeer(ctx, sqlc.CreateFederationPeerParams{
+		Name:   request.Body.Name,
+		Url:    peerURL,
+		Secret: request.Body.Secret,
+		MaxTlp: maxTLP,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return openapi.CreateFederationPeer200JSONResponse(mapFederationPeer(&peer)), nil
+}
+
+func (s *Service) UpdateFederationPeer(ctx context.Context, request openapi.UpdateFederationPeerRequestObject) (openapi.UpdateFederationPeerResponseObject, error) {
+	params := sqlc.UpdateFederationPeerParams{
+		ID:     request.Id,
+		Name:   request.Body.Name,
+		Secret: request.Body.Secret,
+	}
+
+	// the redacted value from ListFederationPeers keeps the stored secret
+	if params.Secret != nil && *params.Secret == redacted {
+		params.Secret = nil
+	}
+
+	if request.Body.Url != nil {
+		peerURL, err := federation.ValidatePeerURL(*request.Body.Url)
+		if err != nil {
+			return openapi.UpdateFederationPeer400JSONResponse{Status: http.StatusBadRequest, Error: "Bad Request", Message: err.Error()}, nil
+		}
+
+		params.Url = &peerURL
+	}
+
+	if request.Body.MaxTlp != nil {
+		maxTLP, err := federation.NormalizeTLP(*request.Body.MaxTlp)
+		if err != nil {
+			return openapi.UpdateFederationPeer400JSONResponse{Status: http.StatusBadRequest, Error: "Bad Request", Message: err.Error()}, nil
+		}
+
+		params.MaxTlp = &maxTLP
+	}
+
+	peer, err := s.queries.UpdateFederationPeer(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	return openapi.UpdateFederationPeer200JSONResponse(mapFederationPeer(&peer)), nil
+}
+
+func (s *Service) DeleteFederationPeer(ctx context.Context, request openapi.DeleteFederationPeerRequestObject) (openapi.DeleteFederationPeerResponseObject, error) {
+	if err := s.queries.DeleteFederationPeer(ctx, request.Id); err != nil {
+		return nil, err
+	}
+
+	return openapi.DeleteFederationPeer204Response{}, nil
+}
+
+func mapFederationPeer(peer *sqlc.FederationPeer) openapi.FederationPeer {
+	return openapi.FederationPeer{
+		Id:      peer.ID,
+		Name:    peer.Name,
+		Url:     peer.Url,
+		Secret:  redacted,
+		MaxTlp:  peer.MaxTlp,
+		Created: peer.Created,
+		Updated: peer.Updated,
+	}
+}
+
+func (s *Service) GetFederatedTicket(ctx context.Context, request openapi.GetFederatedTicketRequestObject) (openapi.GetFederatedTicketResponseObject, error) {
+	peer, err := s.queries.GetFederationPeer(ctx, request.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	ticket, err := federation.New(s.queries, s.hooks).Resolve(ctx, peer.ID, request.Ticket)
+	if errors.Is(err, federation.ErrRemoteNotFound) {
+		return openapi.GetFederatedTicket404JSONResponse{Status: http.StatusNotFound, Error: "Not Found", Message: err.Error()}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return openapi.GetFederatedTicket200JSONResponse{
+		Id:   ticket.ID,
+		Key:  ticket.Key,
+		Name: ticket.Name,
+		Type: ticket.Type,
+		Open: ticket.Open,
+		Tlp:  ticket.TLP,
+		Url:  federation.TicketURL(peer.Url, ticket.Type, ticket.ID),
+	}, nil
+}
+
+func (s *Service) ListTicketFederationLinks(ctx context.Context, request openapi.ListTicketFederationLinksRequestObject) (openapi.ListTicketFederationLinksResponseObject, error) {
+	links, err := s.queries.ListTicketFederationLinks(ctx, request.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]openapi.FederationLink, 0, len(links))
+	for _, link := range links {
+		response = append(response, mapFederationLink(&sqlc.FederationLink{
+			ID:           link.ID,
+			Ticket:       link.Ticket,
+			Peer:         link.Peer,
+			RemoteTicket: link.RemoteTicket,
+			RemoteKey:    link.RemoteKey,
+			RemoteType:   link.RemoteType,
+			RemoteName:   link.RemoteName,
+			RemoteOpen:   link.RemoteOpen,
+			Share:        link.Share,
+			Created:      link.Created,
+			Updated:      link.Updated,
+		}, link.PeerName, link.PeerUrl))
+	}
+
+	return openapi.ListTicketFederationLinks200JSONResponse(response), nil
+}
+
+func (s *Service) CreateTicketFederationLink(ctx context.Context, request openapi.CreateTicketFederationLinkRequestObject) (openapi.CreateTicketFederationLinkResponseObject, error) {
+	link, err := federation.New(s.queries, s.hooks).Link(ctx, request.Id, request.Body.Peer, request.Body.RemoteTicket)
+	if errors.Is(err, federation.ErrNotShareable) {
+		return openapi.CreateTicketFederationLink400JSONResponse{Status: http.StatusBadRequest, Error: "Bad Request", Message: err.Error()}, nil
+	} else if errors.Is(err, federation.ErrRemoteNotFound) {
+		return openapi.CreateTicketFederationLink404JSONResponse{Status: http.StatusNotFound, Error: "Not Found", Message: err.Error()}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	response, err := s.federationLink(ctx, &link)
+	if err != nil {
+		return nil, err
+	}
+
+	return openapi.CreateTicketFederationLink200JSONResponse(response), nil
+}
+
+func (s *Service) UpdateTicketFederationLink(ctx context.Context, request openapi.UpdateTicketFederationLinkRequestObject) (openapi.UpdateTicketFederationLinkResponseObject, error) {
+	if _, err := s.ticketFederationLink(ctx, request.Id, request.Link); err != nil {
+		return nil, err
+	}
+
+	link, err := s.queries.SetFederationLinkShare(ctx, sqlc.SetFederationLinkShareParams{ID: request.Link, Share: request.Body.Share})
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := s.federationLink(ctx, &link)
+	if err != nil {
+		return nil, err
+	}
+
+	return openapi.UpdateTicketFederationLink200JSONResponse(response), nil
+}
+
+func (s *Service) DeleteTicketFederationLink(ctx context.Context, request openapi.DeleteTicketFederationLinkRequestObject) (openapi.DeleteTicketFederationLinkResponseObject, error) {
+	if _, err := s.ticketFederationLink(ctx, request.Id, request.Link); err != nil {
+		return nil, err
+	}
+
+	if err := s.queries.DeleteFederationLink(ctx, request.Link); err != nil {
+		return nil, err
+	}
+
+	return openapi.DeleteTicketFederationLink204Response{}, nil
+}
+
+// ticketFederationLink returns a federated link of a ticket.
+func (s *Service) ticketFederationLink(ctx context.Context, ticket, id string) (sqlc.FederationLink, error) {
+	link, err := s.queries.GetFederationLink(ctx, id)
+	if err != nil {
+		return sqlc.FederationLink{}, err
+	}
+
+	if link.Ticket != ticket {
+		return sqlc.FederationLink{}, fmt.Errorf("federated link %s does not belong to ticket %s", id, ticket)
+	}
+
+	return link, nil
+}
+
+func (s *Service) federationLink(ctx context.Context, link *sqlc.FederationLink) (openapi.FederationLink, error) {
+	peer, err := s.queries.GetFederationPeer(ctx, link.Peer)
+	if err != nil {
+		return openapi.FederationLink{}, err
+	}
+
+	return mapFederationLink(link, peer.Name, peer.Url), nil
+}
+
+func mapFederationLink(link *sqlc.FederationLink, peerName, peerURL string) openapi.FederationLink {
+	return openapi.FederationLink{
+		Id:           link.ID,
+		Ticket:       link.Ticket,
+		Peer:         link.Peer,
+		PeerName:     peerName,
+		RemoteTicket: link.RemoteTicket,
+		RemoteKey:    link.RemoteKey,
+		RemoteName:   link.RemoteName,
+		RemoteOpen:   link.RemoteOpen,
+		RemoteUrl:    federation.TicketURL(peerURL, link.RemoteType, link.RemoteTicket),
+		Share:        link.Share,
+		Created:      link.Created,
+		Updated:      link.Updated,
+	}
+}
+
+func (s *Service) ListAnnouncements(ctx context.Context, request openapi.ListAnnouncementsRequestObject) (openapi.ListAnnouncementsResponseObject, error) {
+	user, ok := usercontext.UserFromContext(ctx)
+	if !ok {
+		return nil, errors.New("missing user")
+	}
+
+	var activeAt *time.Time
+	if !pointer.Dereference(request.Params.IncludeExpired) {
+		activeAt = pointer.Pointer(time.Now().UTC())
+	}
+
+	announcements, err := s.queries.ListAnnouncements(ctx, sqlc.ListAnnouncementsParams{
+		User:     user.ID,
+		ActiveAt: activeAt,
+		Pending:  request.Params.Pending,
+		Offset:   toInt64(request.Params.Offset, defaultOffset),
+		Limit:    toInt64(request.Params.Limit, defaultLimit),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]openapi.Announcement, 0, len(announcements))
+	for _, a := range announcements {
+		response = append(response, mapAnnouncement(sqlc.GetAnnouncementRow{
+			ID:                a.ID,
+			Title:             a.Title,
+			Message:           a.Message,
+			Severity:          a.Severity,
+			RequireAck:        a.RequireAck,
+			Author:            a.Author,
+			Expires:           a.Expires,
+			Created:           a.Created,
+			Updated:           a.Updated,
+			Read:              a.Read,
+			Acknowledged:      a.Acknowledged,
+			AcknowledgedCount: a.AcknowledgedCount,
+		}))
+	}
+
+	totalCount := 0
+	if len(announcements) > 0 {
+		totalCount = int(announcements[0].TotalCount)
+	}
+
+	return openapi.ListAnnouncements200JSONResponse{
+		Body: response,
+		Headers: openapi.ListAnnouncements200ResponseHeaders{
+			XTotalCount: totalCount,
+		},
+	}, nil
+}
+
+func (s *Service) CreateAnnouncement(ctx context.Context, request openapi.CreateAnnouncementRequestObject) (openapi.CreateAnnouncementResponseObject, error) {
+	var author *string
+	if user, ok := usercontext.UserFromContext(ctx); ok {
+		author = &user.ID
+	}
+
+	a, err := s.queries.CreateAnnouncement(ctx, sqlc.CreateAnnouncementParams{
+		Title:      request.Body.Title,
+		Message:    pointer.Dereference(request.Body.Message),
+		Severity:   cmp.Or(string(pointer.Dereference(request.Body.Severity)), "info"),
+		RequireAck: pointer.Dereference(request.Body.RequireAcknowledgment),
+		Author:     author,
+		Expires:    request.Body.Expires,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := s.announcement(ctx, a.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return openapi.CreateAnnouncement200JSONResponse(response), nil
+}
+
+func (s *Service) GetAnnouncement(ctx context.Context, request openapi.GetAnnouncementRequestObject) (openapi.GetAnnouncementResponseObject, error) {
+	response, err := s.announcement(ctx, request.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	return openapi.GetAnnouncement200JSONResponse(response), nil
+}
+
+func (s *Service) DeleteAnnouncement(ctx context.Context, request openapi.DeleteAnnouncementRequestObject) (openapi.DeleteAnnouncementResponseObject, error) {
+	if err := s.queries.DeleteAnnouncement(ctx, request.Id); err != nil {
+		return nil, err
+	}
+
+	return openapi.DeleteAnnouncement204Response{}, nil
+}
+
+func (s *Service) ReadAnnouncement(ctx context.Context, request openapi.ReadAnnouncementRequestObject) (openapi.ReadAnnouncementResponseObject, error) {
+	user, ok := usercontext.UserFromContext(ctx)
+	if !ok {
+		return nil, errors.New("missing user")
+	}
+
+	if _, err := s.announcement(ctx, request.Id); err != nil {
+		return nil, err
+	}
+
+	if err := s.queries.ReadAnnouncement(ctx, sqlc.ReadAnnouncementParams{
+		Announcement: request.Id,
+		User:         user.ID,
+		Read:         time.Now().UTC(),
+	}); err != nil {
+		return nil, err
+	}
+
+	response, err := s.announcement(ctx, request.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	return openapi.ReadAnnouncement200JSONResponse(response), nil
+}
+
+// AcknowledgeAnnouncement records the acknowledgment of the current user,
+// which also marks the announcement as read. Acknowledging it again keeps
+// the first acknowledgment.
+func (s *Service) AcknowledgeAnnouncement(ctx context.Context, request openapi.AcknowledgeAnnouncementRequestObject) (openapi.AcknowledgeAnnouncementResponseObject, error) {
+	user, ok := usercontext.UserFromContext(ctx)
+	if !ok {
+		return nil, errors.New("missing user")
+	}
+
+	if _, err := s.announcement(ctx, request.Id); err != nil {
+		return nil, err
+	}
+
+	if err := s.queries.AcknowledgeAnnouncement(ctx, sqlc.AcknowledgeAnnouncementParams{
+		Announcement: request.Id,
+		User:         user.ID,
+		Acknowledged: time.Now().UTC(),
+	}); err != nil {
+		return nil, err
+	}
+
+	response, err := s.announcement(ctx, request.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	return openapi.AcknowledgeAnnouncement200JSONResponse(response), nil
+}
+
+func (s *Service) ListAnnouncementReceipts(ctx context.Context, request openapi.ListAnnouncementReceiptsRequestObject) (openapi.ListAnnouncementReceiptsResponseObject, error) {
+	if _, err := s.announcement(ctx, request.Id); err != nil {
+		return nil, err
+	}
+
+	receipts, err := s.queries.ListAnnouncementReceipts(ctx, sqlc.ListAnnouncementReceiptsParams{
+		Announcement: request.Id,
+		Pending:      request.Params.Pending,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]openapi.AnnouncementReceipt, 0, len(receipts))
+	for _, r := range receipts {
+		response = append(response, openapi.AnnouncementReceipt{
+			User:         r.User,
+			Name:         r.Name,
+			Username:     r.Username,
+			Read:         r.Read,
+			Acknowledged: r.Acknowledged,
+		})
+	}
+
+	return openapi.ListAnnouncementReceipts200JSONResponse(response), nil
+}
+
+// announcement returns an announcement with the read receipt of the
+// current user.
+func (s *Service) announcement(ctx context.Context, id string) (openapi.Announcement, error) {
+	var userID string
+	if user, ok := usercontext.UserFromContext(ctx); ok {
+		userID = user.ID
+	}
+
+	a, err := s.queries.GetAnnouncement(ctx, sqlc.GetAnnouncementParams{ID: id, User: userID})
+	if err != nil {
+		return openapi.Announcement{}, err
+	}
+
+	return mapAnnouncement(a), nil
+}
+
+func mapAnnouncement(a sqlc.GetAnnouncementRow) openapi.Announcement {
+	return openapi.Announcement{
+		Id:                    a.ID,
+		Title:                 a.Title,
+		Message:               a.Message,
+		Severity:              openapi.AnnouncementSeverity(a.Severity),
+		RequireAcknowledgment: a.RequireAck,
+		Author:                a.Author,
+		Expires:               a.Expires,
+		Read:                  a.Read,
+		Acknowledged:          a.Acknowledged,
+		Acknowledgments:       int(a.AcknowledgedCount),
+		Created:               a.Created,
+	}
+}
+
+func (s *Service) GetTask(ctx context.Context, request openapi.GetTaskRequestObject) (openapi.GetTaskResponseObject, error) {
+	task, err := s.queries.GetTask(ctx, request.Id)
+	if err != nil {
+		return nil, err
 	}
 
 	response := openapi.ExtendedTask{
@@ -710,489 +4575,4706 @@ func (s *Service) GetTask(ctx context.Context, request openapi.GetTaskRequestObj
 		TicketType: pointer.Dereference(task.TicketType),
 	}
 
-	s.hooks.OnRecordViewRequest.Publish(ctx, database.TasksTable.ID, response)
+	s.hooks.OnRecordViewRequest.Publish(ctx, database.TasksTable.ID, response)
+
+	return openapi.GetTask200JSONResponse(response), nil
+}
+
+func (s *Service) UpdateTask(ctx context.Context, request openapi.UpdateTaskRequestObject) (openapi.UpdateTaskResponseObject, error) {
+	s.hooks.OnRecordBeforeUpdateRequest.Publish(ctx, database.TasksTable.ID, request.Body)
+
+	task, err := s.queries.UpdateTask(ctx, sqlc.UpdateTaskParams{
+		ID:    request.Id,
+		Name:  request.Body.Name,
+		Open:  request.Body.Open,
+		Owner: request.Body.Owner,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := openapi.Task{
+		Created: task.Created,
+		Id:      task.ID,
+		Name:    task.Name,
+		Open:    task.Open,
+		Owner:   task.Owner,
+		Ticket:  task.Ticket,
+		Updated: task.Updated,
+	}
+
+	s.hooks.OnRecordAfterUpdateRequest.Publish(ctx, database.TasksTable.ID, response)
+
+	return openapi.UpdateTask200JSONResponse(response), nil
+}
+
+func (s *Service) SearchTickets(ctx context.Context, request openapi.SearchTicketsRequestObject) (openapi.SearchTicketsResponseObject, error) {
+	tickets, err := s.queries.SearchTickets(ctx, sqlc.SearchTicketsParams{
+		Query:  request.Params.Query,
+		Type:   request.Params.Type,
+		Open:   request.Params.Open,
+		Offset: toInt64(request.Params.Offset, defaultOffset),
+		Limit:  toInt64(request.Params.Limit, defaultLimit),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]openapi.TicketSearch, 0, len(tickets))
+
+	for _, ticket := range tickets {
+		description, state, err := s.ticketContent(ctx, ticket.ID, ticket.Description, ticket.State)
+		if err != nil {
+			return nil, err
+		}
+
+		response = append(response, openapi.TicketSearch{
+			Created:     ticket.Created,
+			Description: description,
+			Id:          ticket.ID,
+			Name:        ticket.Name,
+			Open:        ticket.Open,
+			OwnerName:   pointer.Dereference(ticket.OwnerName),
+			State:       state,
+			Type:        ticket.Type,
+		})
+	}
+
+	s.hooks.OnRecordsListRequest.Publish(ctx, database.TicketsTable.ID, response)
+
+	totalCount := 0
+	if len(tickets) > 0 {
+		totalCount = int(tickets[0].TotalCount)
+	}
+
+	return openapi.SearchTickets200JSONResponse{
+		Body: response,
+		Headers: openapi.SearchTickets200ResponseHeaders{
+			XTotalCount: totalCount,
+		},
+	}, nil
+}
+
+func (s *Service) ListTickets(ctx context.Context, request openapi.ListTicketsRequestObject) (openapi.ListTicketsResponseObject, error) {
+	tickets, err := s.queries.ListTickets(ctx, sqlc.ListTicketsParams{
+		Acknowledged: request.Params.Acknowledged,
+		Offset:       toInt64(request.Params.Offset, defaultOffset),
+		Limit:        toInt64(request.Params.Limit, defaultLimit),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]openapi.ExtendedTicket, 0, len(tickets))
+	for _, ticket := range tickets {
+		description, state, err := s.ticketContent(ctx, ticket.ID, ticket.Description, ticket.State)
+		if err != nil {
+			return nil, err
+		}
+
+		response = append(response, openapi.ExtendedTicket{
+			Created:        ticket.Created,
+			Description:    description,
+			Id:             ticket.ID,
+			Key:            ticket.Key,
+			Name:           ticket.Name,
+			Open:           ticket.Open,
+			Owner:          ticket.Owner,
+			OwnerName:      ticket.OwnerName,
+			Resolution:     ticket.Resolution,
+			Type:           ticket.Type,
+			Schema:         unmarshal(ticket.Schema),
+			State:          state,
+			TypePlural:     pointer.Dereference(ticket.TypePlural),
+			TypeSingular:   pointer.Dereference(ticket.TypeSingular),
+			Acknowledged:   ticket.Acknowledged,
+			AcknowledgedBy: ticket.AcknowledgedBy,
+			Resolved:       ticket.Resolved,
+			ResolvedBy:     ticket.ResolvedBy,
+			ReopenCount:    int(ticket.ReopenCount),
+			Encrypted:      ticket.Encrypted,
+			Updated:        ticket.Updated,
+		})
+	}
+
+	s.hooks.OnRecordsListRequest.Publish(ctx, "tickets", response)
+
+	totalCount := 0
+	if len(tickets) > 0 {
+		totalCount = int(tickets[0].TotalCount)
+	}
+
+	return openapi.ListTickets200JSONResponse{
+		Body: response,
+		Headers: openapi.ListTickets200ResponseHeaders{
+			XTotalCount: totalCount,
+		},
+	}, nil
+}
+
+func (s *Service) CreateTicket(ctx context.Context, request openapi.CreateTicketRequestObject) (openapi.CreateTicketResponseObject, error) {
+	if rejected, err := s.applyPlugins(ctx, database.TicketsTable.ID, request.Body); err != nil {
+		return nil, err
+	} else if rejected != nil {
+		return openapi.CreateTicket400JSONResponse(*rejected), nil
+	}
+
+	s.hooks.OnRecordBeforeCreateRequest.Publish(ctx, database.TicketsTable.ID, request.Body)
+
+	ticket, err := s.queries.CreateTicket(ctx, sqlc.CreateTicketParams{
+		Name:        request.Body.Name,
+		Description: request.Body.Description,
+		Owner:       request.Body.Owner,
+		Open:        request.Body.Open,
+		Resolution:  request.Body.Resolution,
+		Type:        request.Body.Type,
+		State:       marshal(request.Body.State),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if ticket.Key, err = s.ticketKey(ctx, ticket.ID); err != nil {
+		return nil, err
+	}
+
+	response := openapi.Ticket{
+		Created:        ticket.Created,
+		Description:    ticket.Description,
+		Id:             ticket.ID,
+		Key:            ticket.Key,
+		Name:           ticket.Name,
+		Open:           ticket.Open,
+		Owner:          ticket.Owner,
+		Resolution:     ticket.Resolution,
+		Schema:         unmarshal(ticket.Schema),
+		State:          unmarshal(ticket.State),
+		Type:           ticket.Type,
+		Acknowledged:   ticket.Acknowledged,
+		AcknowledgedBy: ticket.AcknowledgedBy,
+		Resolved:       ticket.Resolved,
+		ResolvedBy:     ticket.ResolvedBy,
+		ReopenCount:    int(ticket.ReopenCount),
+		Updated:        ticket.Updated,
+	}
+
+	s.hooks.OnRecordAfterCreateRequest.Publish(ctx, database.TicketsTable.ID, response)
+
+	return openapi.CreateTicket200JSONResponse(response), nil
+}
+
+// applyPlugins passes a record that is about to be stored through the
+// enrich and validate functions of the plugins, updates only hold the
+// changed fields. A rejected record is returned as an error response.
+func (s *Service) applyPlugins(ctx context.Context, table string, record any) (*openapi.Error, error) {
+	if err := s.plugins.Enrich(ctx, table, record); err != nil {
+		return nil, err
+	}
+
+	var validationErr *plugin.ValidationError
+	if err := s.plugins.Validate(ctx, table, record); errors.As(err, &validationErr) {
+		return &openapi.Error{
+			Status:  http.StatusBadRequest,
+			Error:   "Bad Request",
+			Message: validationErr.Error(),
+		}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return nil, nil
+}
+
+// ticketKey returns the key of a new ticket, which the database allocates
+// after the ticket is inserted.
+func (s *Service) ticketKey(ctx context.Context, id string) (*string, error) {
+	ticket, err := s.queries.Ticket(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return ticket.Key, nil
+}
+
+func (s *Service) ListAlerts(ctx context.Context, request openapi.ListAlertsRequestObject) (openapi.ListAlertsResponseObject, error) {
+	alerts, err := s.queries.ListAlerts(ctx, sqlc.ListAlertsParams{
+		Status: (*string)(request.Params.Status),
+		Source: request.Params.Source,
+		Ticket: request.Params.Ticket,
+		Offset: toInt64(request.Params.Offset, defaultOffset),
+		Limit:  toInt64(request.Params.Limit, defaultLimit),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]openapi.Alert, 0, len(alerts))
+	for _, alert := range alerts {
+		response = append(response, mapAlert(sqlc.Alert{
+			ID:          alert.ID,
+			Source:      alert.Source,
+			Name:        alert.Name,
+			Description: alert.Description,
+			Severity:    alert.Severity,
+			Data:        alert.Data,
+			Status:      alert.Status,
+			Ticket:      alert.Ticket,
+			Created:     alert.Created,
+			Updated:     alert.Updated,
+		}))
+	}
+
+	s.hooks.OnRecordsListRequest.Publish(ctx, database.AlertsTable.ID, response)
+
+	totalCount := 0
+	if len(alerts) > 0 {
+		totalCount = int(alerts[0].TotalCount)
+	}
+
+	return openapi.ListAlerts200JSONResponse{
+		Body: response,
+		Headers: openapi.ListAlerts200ResponseHeaders{
+			XTotalCount: totalCount,
+		},
+	}, nil
+}
+
+func (s *Service) CreateAlert(ctx context.Context, request openapi.CreateAlertRequestObject) (openapi.CreateAlertResponseObject, error) {
+	s.hooks.OnRecordBeforeCreateRequest.Publish(ctx, database.AlertsTable.ID, request.Body)
+
+	window, err := maintenance.AlertWindow(ctx, s.queries, request.Body.Source, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	alert, err := s.queries.CreateAlert(ctx, sqlc.CreateAlertParams{
+		Source:      request.Body.Source,
+		Name:        request.Body.Name,
+		Description: pointer.Dereference(request.Body.Description),
+		Severity:    pointer.Dereference(request.Body.Severity),
+		Data:        marshalPointer(request.Body.Data),
+		Maintenance: window,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := artifact.Sight(ctx, s.queries, nil, artifact.SourceAlert, alert.ID, alert.Data); err != nil {
+		return nil, err
+	}
+
+	response := mapAlert(alert)
+
+	s.hooks.OnRecordAfterCreateRequest.Publish(ctx, database.AlertsTable.ID, response)
+
+	return openapi.CreateAlert200JSONResponse(response), nil
+}
+
+func (s *Service) GetAlert(ctx context.Context, request openapi.GetAlertRequestObject) (openapi.GetAlertResponseObject, error) {
+	alert, err := s.queries.GetAlert(ctx, request.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	response := mapAlert(alert)
+
+	s.hooks.OnRecordViewRequest.Publish(ctx, database.AlertsTable.ID, response)
+
+	return openapi.GetAlert200JSONResponse(response), nil
+}
+
+var errAlertPromoted = openapi.Error{
+	Status:  http.StatusBadRequest,
+	Error:   "Bad Request",
+	Message: "The alert is already promoted",
+}
+
+func (s *Service) UpdateAlert(ctx context.Context, request openapi.UpdateAlertRequestObject) (openapi.UpdateAlertResponseObject, error) {
+	if request.Body.Status != openapi.AlertUpdateStatusNew && request.Body.Status != openapi.AlertUpdateStatusDismissed {
+		return nil, fmt.Errorf("unknown alert status %q", request.Body.Status)
+	}
+
+	s.hooks.OnRecordBeforeUpdateRequest.Publish(ctx, database.AlertsTable.ID, request.Body)
+
+	alert, err := s.queries.UpdateAlertStatus(ctx, sqlc.UpdateAlertStatusParams{
+		ID:     request.Id,
+		Status: string(request.Body.Status),
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		if _, err := s.queries.GetAlert(ctx, request.Id); err != nil {
+			return nil, err
+		}
+
+		return openapi.UpdateAlert400JSONResponse(errAlertPromoted), nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	response := mapAlert(alert)
+
+	s.hooks.OnRecordAfterUpdateRequest.Publish(ctx, database.AlertsTable.ID, response)
+
+	return openapi.UpdateAlert200JSONResponse(response), nil
+}
+
+func (s *Service) DeleteAlert(ctx context.Context, request openapi.DeleteAlertRequestObject) (openapi.DeleteAlertResponseObject, error) {
+	s.hooks.OnRecordBeforeDeleteRequest.Publish(ctx, database.AlertsTable.ID, request.Id)
+
+	if err := s.queries.DeleteAlert(ctx, request.Id); err != nil {
+		return nil, err
+	}
+
+	s.hooks.OnRecordAfterDeleteRequest.Publish(ctx, database.AlertsTable.ID, request.Id)
+
+	return openapi.DeleteAlert204Response{}, nil
+}
+
+// TestAlert returns what ingesting an alert and promoting it to a ticket of
+// the type would result in, without creating either.
+func (s *Service) TestAlert(ctx context.Context, request openapi.TestAlertRequestObject) (openapi.TestAlertResponseObject, error) {
+	errs := []string{}
+
+	if strings.TrimSpace(request.Body.Source) == "" {
+		errs = append(errs, "The source of the alert is required")
+	}
+
+	if strings.TrimSpace(request.Body.Name) == "" {
+		errs = append(errs, "The name of the alert is required")
+	}
+
+	if _, err := s.queries.GetType(ctx, request.Params.Type); errors.Is(err, sql.ErrNoRows) {
+		errs = append(errs, fmt.Sprintf("The ticket type %s does not exist", request.Params.Type))
+	} else if err != nil {
+		return nil, err
+	}
+
+	window, err := maintenance.AlertWindow(ctx, s.queries, request.Body.Source, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	alert := sqlc.Alert{
+		Source:      request.Body.Source,
+		Name:        request.Body.Name,
+		Description: pointer.Dereference(request.Body.Description),
+		Severity:    pointer.Dereference(request.Body.Severity),
+		Data:        marshalPointer(request.Body.Data),
+		Created:     time.Now(),
+	}
+
+	indicators := []openapi.AlertIndicator{}
+	for _, indicator := range artifact.Extract(alert.Data) {
+		indicators = append(indicators, openapi.AlertIndicator{Kind: indicator.Kind, Value: indicator.Value})
+	}
+
+	return openapi.TestAlert200JSONResponse{
+		Valid:  len(errs) == 0,
+		Errors: errs,
+		Ticket: openapi.NewTicket{
+			Type:        request.Params.Type,
+			Name:        alert.Name,
+			Description: alertDescription([]sqlc.Alert{alert}),
+			Open:        true,
+			Schema:      map[string]any{},
+			State:       alertState([]sqlc.Alert{alert}),
+		},
+		Indicators:  indicators,
+		Maintenance: window,
+	}, nil
+}
+
+func (s *Service) ListAlertMappingTemplates(_ context.Context, _ openapi.ListAlertMappingTemplatesRequestObject) (openapi.ListAlertMappingTemplatesResponseObject, error) {
+	templates := mapping.Templates()
+
+	response := make([]openapi.AlertMappingTemplate, 0, len(templates))
+	for _, template := range templates {
+		response = append(response, openapi.AlertMappingTemplate{
+			Id:      template.ID,
+			Name:    template.Name,
+			Source:  template.Source,
+			Version: template.Version,
+			Fields:  mapAlertMappingFields(template.Fields),
+		})
+	}
+
+	return openapi.ListAlertMappingTemplates200JSONResponse(response), nil
+}
+
+func (s *Service) ListAlertMappings(ctx context.Context, request openapi.ListAlertMappingsRequestObject) (openapi.ListAlertMappingsResponseObject, error) {
+	mappings, err := s.queries.ListAlertMappings(ctx, sqlc.ListAlertMappingsParams{
+		Offset: toInt64(request.Params.Offset, defaultOffset),
+		Limit:  toInt64(request.Params.Limit, defaultLimit),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]openapi.AlertMapping, 0, len(mappings))
+	for _, m := range mappings {
+		response = append(response, mapAlertMapping(sqlc.AlertMapping{
+			ID:              m.ID,
+			Name:            m.Name,
+			Source:          m.Source,
+			Template:        m.Template,
+			TemplateVersion: m.TemplateVersion,
+			Fields:          m.Fields,
+			Created:         m.Created,
+			Updated:         m.Updated,
+		}))
+	}
+
+	totalCount := 0
+	if len(mappings) > 0 {
+		totalCount = int(mappings[0].TotalCount)
+	}
+
+	return openapi.ListAlertMappings200JSONResponse{
+		Body: response,
+		Headers: openapi.ListAlertMappings200ResponseHeaders{
+			XTotalCount: totalCount,
+		},
+	}, nil
+}
+
+func (s *Service) CreateAlertMapping(ctx context.Context, request openapi.CreateAlertMappingRequestObject) (openapi.CreateAlertMappingResponseObject, error) {
+	params := sqlc.CreateAlertMappingParams{
+		Name:   request.Body.Name,
+		Source: pointer.Dereference(request.Body.Source),
+	}
+
+	var fields mapping.Fields
+
+	if request.Body.Template != nil {
+		template, ok := mapping.Lookup(*request.Body.Template)
+		if !ok {
+			return openapi.CreateAlertMapping400JSONResponse{
+				Status:  http.StatusBadRequest,
+				Error:   "Bad Request",
+				Message: fmt.Sprintf("unknown mapping template %q", *request.Body.Template),
+			}, nil
+		}
+
+		params.Template = template.ID
+		params.TemplateVersion = int64(template.Version)
+		fields = template.Fields
+
+		if params.Source == "" {
+			params.Source = template.Source
+		}
+	}
+
+	if request.Body.Fields != nil {
+		fields = toMappingFields(*request.Body.Fields)
+	}
+
+	if err := s.validateMappingFields(fields); err != nil {
+		return openapi.CreateAlertMapping400JSONResponse{
+			Status:  http.StatusBadRequest,
+			Error:   "Bad Request",
+			Message: err.Error(),
+		}, nil
+	}
+
+	var err error
+	if params.Fields, err = json.Marshal(fields); err != nil {
+		return nil, err
+	}
+
+	m, err := s.queries.CreateAlertMapping(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	return openapi.CreateAlertMapping200JSONResponse(mapAlertMapping(m)), nil
+}
+
+func (s *Service) GetAlertMapping(ctx context.Context, request openapi.GetAlertMappingRequestObject) (openapi.GetAlertMappingResponseObject, error) {
+	m, err := s.queries.GetAlertMapping(ctx, request.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	return openapi.GetAlertMapping200JSONResponse(mapAlertMapping(m)), nil
+}
+
+func (s *Service) UpdateAlertMapping(ctx context.Context, request openapi.UpdateAlertMappingRequestObject) (openapi.UpdateAlertMappingResponseObject, error) {
+	params := sqlc.UpdateAlertMappingParams{
+		ID:     request.Id,
+		Name:   request.Body.Name,
+		Source: request.Body.Source,
+	}
+
+	var fields *mapping.Fields
+
+	if request.Body.Fields != nil {
+		fields = pointer.Pointer(toMappingFields(*request.Body.Fields))
+	}
+
+	// An upgrade replaces customized fields, the template is the newer
+	// source of truth.
+	if pointer.Dereference(request.Body.Upgrade) {
+		current, err := s.queries.GetAlertMapping(ctx, request.Id)
+		if err != nil {
+			return nil, err
+		}
+
+		template, ok := mapping.Lookup(current.Template)
+		if !ok {
+			return openapi.UpdateAlertMapping400JSONResponse{
+				Status:  http.StatusBadRequest,
+				Error:   "Bad Request",
+				Message: "the mapping was not cloned from a template",
+			}, nil
+		}
+
+		fields = &template.Fields
+		params.TemplateVersion = pointer.Pointer(int64(template.Version))
+	}
+
+	if fields != nil {
+		if err := s.validateMappingFields(*fields); err != nil {
+			return openapi.UpdateAlertMapping400JSONResponse{
+				Status:  http.StatusBadRequest,
+				Error:   "Bad Request",
+				Message: err.Error(),
+			}, nil
+		}
+
+		var err error
+		if params.Fields, err = json.Marshal(fields); err != nil {
+			return nil, err
+		}
+	}
+
+	m, err := s.queries.UpdateAlertMapping(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	return openapi.UpdateAlertMapping200JSONResponse(mapAlertMapping(m)), nil
+}
+
+func (s *Service) DeleteAlertMapping(ctx context.Context, request openapi.DeleteAlertMappingRequestObject) (openapi.DeleteAlertMappingResponseObject, error) {
+	if err := s.queries.DeleteAlertMapping(ctx, request.Id); err != nil {
+		return nil, err
+	}
+
+	return openapi.DeleteAlertMapping204Response{}, nil
+}
+
+// alertMapping returns the source and the fields of an alert mapping or a
+// template, ok is false if neither has the ID.
+func (s *Service) alertMapping(ctx context.Context, id string) (source string, fields mapping.Fields, ok bool, err error) {
+	m, err := s.queries.GetAlertMapping(ctx, id)
+
+	switch {
+	case err == nil:
+		if err := json.Unmarshal(m.Fields, &fields); err != nil {
+			return "", fields, false, err
+		}
+
+		return m.Source, fields, true, nil
+	case errors.Is(err, sql.ErrNoRows):
+		template, ok := mapping.Lookup(id)
+
+		return template.Source, template.Fields, ok, nil
+	default:
+		return "", fields, false, err
+	}
+}
+
+// validateMappingFields checks the fields of an alert mapping and that their
+// plugin, if any, has a map function.
+func (s *Service) validateMappingFields(fields mapping.Fields) error {
+	if err := fields.Validate(); err != nil {
+		return err
+	}
+
+	if fields.Plugin != "" {
+		return s.plugins.CheckMapper(fields.Plugin)
+	}
+
+	return nil
+}
+
+// mapEvent passes an event to the map function of the plugin of the
+// mapping fields, if any, and applies the fields to the result.
+func (s *Service) mapEvent(ctx context.Context, source string, fields mapping.Fields, event []byte) (*mapping.Alert, error) {
+	if fields.Plugin != "" {
+		var err error
+		if event, err = s.plugins.Map(ctx, fields.Plugin, source, event); err != nil {
+			return nil, err
+		}
+	}
+
+	return mapping.Apply(source, fields, event)
+}
+
+func (s *Service) IngestAlertEvent(ctx context.Context, request openapi.IngestAlertEventRequestObject) (openapi.IngestAlertEventResponseObject, error) {
+	source, fields, ok, err := s.alertMapping(ctx, request.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !ok {
+		return openapi.IngestAlertEvent404JSONResponse{
+			Status:  http.StatusNotFound,
+			Error:   "Not Found",
+			Message: fmt.Sprintf("no alert mapping or template with the ID %q", request.Id),
+		}, nil
+	}
+
+	event, err := json.Marshal(request.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	alert, err := s.mapEvent(ctx, source, fields, event)
+	if errors.Is(err, mapping.ErrInvalidEvent) || errors.Is(err, plugin.ErrNoMapper) {
+		return openapi.IngestAlertEvent400JSONResponse{
+			Status:  http.StatusBadRequest,
+			Error:   "Bad Request",
+			Message: err.Error(),
+		}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	response, err := s.CreateAlert(ctx, openapi.CreateAlertRequestObject{
+		Body: &openapi.NewAlert{
+			Source:      alert.Source,
+			Name:        alert.Name,
+			Description: &alert.Description,
+			Severity:    &alert.Severity,
+			Data:        &alert.Data,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	created, ok := response.(openapi.CreateAlert200JSONResponse)
+	if !ok {
+		return nil, fmt.Errorf("unexpected create alert response %T", response)
+	}
+
+	return openapi.IngestAlertEvent200JSONResponse(created), nil
+}
+
+// TestAlertMapping returns the alert that ingesting an event with a mapping
+// would create, or why the mapping fails, without storing anything.
+func (s *Service) TestAlertMapping(ctx context.Context, request openapi.TestAlertMappingRequestObject) (openapi.TestAlertMappingResponseObject, error) {
+	source, fields, ok, err := s.alertMapping(ctx, request.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !ok {
+		return openapi.TestAlertMapping404JSONResponse{
+			Status:  http.StatusNotFound,
+			Error:   "Not Found",
+			Message: fmt.Sprintf("no alert mapping or template with the ID %q", request.Id),
+		}, nil
+	}
+
+	errs := []string{}
+
+	if err := s.validateMappingFields(fields); err != nil {
+		errs = append(errs, err.Error())
+	}
+
+	event, err := json.Marshal(request.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	alert, err := s.mapEvent(ctx, source, fields, event)
+	if errors.Is(err, mapping.ErrInvalidEvent) || errors.Is(err, plugin.ErrNoMapper) {
+		errs = append(errs, err.Error())
+	} else if err != nil {
+		return nil, err
+	}
+
+	response := openapi.TestAlertMapping200JSONResponse{
+		Valid:  len(errs) == 0,
+		Errors: errs,
+	}
+
+	if alert != nil {
+		response.Alert = &openapi.NewAlert{
+			Source:      alert.Source,
+			Name:        alert.Name,
+			Description: &alert.Description,
+			Severity:    &alert.Severity,
+			Data:        &alert.Data,
+		}
+	}
+
+	return response, nil
+}
+
+func mapAlertMapping(m sqlc.AlertMapping) openapi.AlertMapping {
+	var fields mapping.Fields
+	if err := json.Unmarshal(m.Fields, &fields); err != nil {
+		slog.Error("Invalid alert mapping fields", "mapping", m.ID, "error", err)
+	}
+
+	response := openapi.AlertMapping{
+		Id:      m.ID,
+		Name:    m.Name,
+		Source:  m.Source,
+		Fields:  mapAlertMappingFields(fields),
+		Created: m.Created,
+		Updated: m.Updated,
+	}
+
+	if template, ok := mapping.Lookup(m.Template); ok {
+		response.Template = &m.Template
+		response.TemplateVersion = pointer.Pointer(int(m.TemplateVersion))
+		response.UpgradeAvailable = int64(template.Version) > m.TemplateVersion
+	}
+
+	return response
+}
+
+func mapAlertMappingFields(fields mapping.Fields) openapi.AlertMappingFields {
+	response := openapi.AlertMappingFields{
+		Name: fields.Name,
+	}
+
+	if fields.Description != "" {
+		response.Description = &fields.Description
+	}
+
+	if fields.Severity != "" {
+		response.Severity = &fields.Severity
+	}
+
+	if len(fields.Severities) > 0 {
+		response.Severities = &fields.Severities
+	}
+
+	if len(fields.Levels) > 0 {
+		levels := make([]openapi.AlertMappingLevel, 0, len(fields.Levels))
+		for _, level := range fields.Levels {
+			levels = append(levels, openapi.AlertMappingLevel{Min: float32(level.Min), Severity: level.Severity})
+		}
+
+		response.Levels = &levels
+	}
+
+	if fields.Plugin != "" {
+		response.Plugin = &fields.Plugin
+	}
+
+	return response
+}
+
+func toMappingFields(fields openapi.AlertMappingFields) mapping.Fields {
+	result := mapping.Fields{
+		Name:        fields.Name,
+		Description: pointer.Dereference(fields.Description),
+		Severity:    pointer.Dereference(fields.Severity),
+		Severities:  pointer.Dereference(fields.Severities),
+		Plugin:      pointer.Dereference(fields.Plugin),
+	}
+
+	for _, level := range pointer.Dereference(fields.Levels) {
+		result.Levels = append(result.Levels, mapping.Level{Min: float64(level.Min), Severity: level.Severity})
+	}
+
+	return result
+}
+
+// PromoteAlerts turns alerts into a ticket. Several alerts are grouped into
+// a single ticket, either a new one or an existing one. Every alert is
+// added to the timeline of the ticket at the time it was raised, and the
+// indicators in its data are recorded as sightings on the ticket.
+func (s *Service) PromoteAlerts(ctx context.Context, request openapi.PromoteAlertsRequestObject) (openapi.PromoteAlertsResponseObject, error) {
+	badRequest := func(format string, args ...any) openapi.PromoteAlerts400JSONResponse {
+		return openapi.PromoteAlerts400JSONResponse{
+			Status:  http.StatusBadRequest,
+			Error:   "Bad Request",
+			Message: fmt.Sprintf(format, args...),
+		}
+	}
+
+	if len(request.Body.Alerts) == 0 {
+		return badRequest("At least one alert is required"), nil
+	}
+
+	alerts := make([]sqlc.Alert, 0, len(request.Body.Alerts))
+
+	for _, id := range request.Body.Alerts {
+		alert, err := s.queries.GetAlert(ctx, id)
+		if errors.Is(err, sql.ErrNoRows) {
+			return badRequest("The alert %s does not exist", id), nil
+		} else if err != nil {
+			return nil, err
+		}
+
+		if alert.Status == string(openapi.AlertStatusPromoted) {
+			return badRequest("The alert %s is already promoted", id), nil
+		}
+
+		alerts = append(alerts, alert)
+	}
+
+	var ticket openapi.Ticket
+
+	if request.Body.Ticket != nil {
+		var err error
+		if ticket, err = s.ticket(ctx, *request.Body.Ticket); err != nil {
+			return nil, err
+		}
+	} else {
+		if request.Body.Type == nil || *request.Body.Type == "" {
+			return badRequest("The type of the new ticket is required"), nil
+		}
+
+		resp, err := s.CreateTicket(ctx, openapi.CreateTicketRequestObject{Body: &openapi.NewTicket{
+			Type:        *request.Body.Type,
+			Name:        cmp.Or(pointer.Dereference(request.Body.Name), alerts[0].Name),
+			Description: alertDescription(alerts),
+			Open:        true,
+			Owner:       request.Body.Owner,
+			Schema:      map[string]any{},
+			State:       alertState(alerts),
+		}})
+		if err != nil {
+			return nil, err
+		}
+
+		switch resp := resp.(type) {
+		case openapi.CreateTicket200JSONResponse:
+			ticket = openapi.Ticket(resp)
+		case openapi.CreateTicket400JSONResponse:
+			return badRequest("%s", resp.Message), nil
+		default:
+			return nil, fmt.Errorf("unexpected create ticket response %T", resp)
+		}
+	}
+
+	for _, alert := range alerts {
+		promoted, err := s.queries.PromoteAlert(ctx, sqlc.PromoteAlertParams{ID: alert.ID, Ticket: &ticket.Id})
+		if errors.Is(err, sql.ErrNoRows) {
+			// promoted concurrently
+			return badRequest("The alert %s is already promoted", alert.ID), nil
+		} else if err != nil {
+			return nil, err
+		}
+
+		if _, err := s.queries.CreateTimeline(ctx, sqlc.CreateTimelineParams{
+			Ticket:  ticket.Id,
+			Message: fmt.Sprintf("Alert %s from %s", alert.Name, alert.Source),
+			Time:    alert.Created,
+		}); err != nil {
+			return nil, err
+		}
+
+		if err := artifact.Sight(ctx, s.queries, &ticket.Id, artifact.SourceCorrelation, alert.ID, alert.Data); err != nil {
+			return nil, err
+		}
+
+		s.hooks.OnRecordAfterUpdateRequest.Publish(ctx, database.AlertsTable.ID, mapAlert(promoted))
+	}
+
+	return openapi.PromoteAlerts200JSONResponse(ticket), nil
+}
+
+// alertDescription is the description of a ticket promoted from alerts, a
+// list of the alerts if there are several.
+func alertDescription(alerts []sqlc.Alert) string {
+	if len(alerts) == 1 {
+		return alerts[0].Description
+	}
+
+	var b strings.Builder
+
+	for _, alert := range alerts {
+		fmt.Fprintf(&b, "- **%s** (%s, %s)", alert.Name, alert.Source, alert.Created.UTC().Format(time.DateTime))
+
+		if alert.Description != "" {
+			b.WriteString(": " + alert.Description)
+		}
+
+		b.WriteString("\n")
+	}
+
+	return b.String()
+}
+
+// alertState keeps the source of the alerts, which is used in the ticket
+// statistics, and the severity of the first alert that has one.
+func alertState(alerts []sqlc.Alert) map[string]any {
+	state := map[string]any{"source": alerts[0].Source}
+
+	for _, alert := range alerts {
+		if alert.Severity != "" {
+			state["severity"] = alert.Severity
+
+			break
+		}
+	}
+
+	return state
+}
+
+func mapAlert(alert sqlc.Alert) openapi.Alert {
+	return openapi.Alert{
+		Id:          alert.ID,
+		Source:      alert.Source,
+		Name:        alert.Name,
+		Description: alert.Description,
+		Severity:    alert.Severity,
+		Data:        unmarshal(alert.Data),
+		Status:      openapi.AlertStatus(alert.Status),
+		Ticket:      alert.Ticket,
+		Maintenance: alert.Maintenance,
+		Created:     alert.Created,
+		Updated:     alert.Updated,
+	}
+}
+
+func (s *Service) ListQueues(ctx context.Context, request openapi.ListQueuesRequestObject) (openapi.ListQueuesResponseObject, error) {
+	queues, err := s.queries.ListQueues(ctx, sqlc.ListQueuesParams{
+		Offset: toInt64(request.Params.Offset, defaultOffset),
+		Limit:  toInt64(request.Params.Limit, defaultLimit),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]openapi.Queue, 0, len(queues))
+	for _, q := range queues {
+		response = append(response, mapQueue(sqlc.Queue{
+			ID:          q.ID,
+			Name:        q.Name,
+			Description: q.Description,
+			Filter:      q.Filter,
+			Created:     q.Created,
+			Updated:     q.Updated,
+		}))
+	}
+
+	totalCount := 0
+	if len(queues) > 0 {
+		totalCount = int(queues[0].TotalCount)
+	}
+
+	return openapi.ListQueues200JSONResponse{
+		Body: response,
+		Headers: openapi.ListQueues200ResponseHeaders{
+			XTotalCount: totalCount,
+		},
+	}, nil
+}
+
+func (s *Service) CreateQueue(ctx context.Context, request openapi.CreateQueueRequestObject) (openapi.CreateQueueResponseObject, error) {
+	if err := queue.Validate(request.Body.Name); err != nil {
+		return nil, err
+	}
+
+	filter, err := json.Marshal(request.Body.Filter)
+	if err != nil {
+		return nil, err
+	}
+
+	q, err := s.queries.CreateQueue(ctx, sqlc.CreateQueueParams{
+		Name:        request.Body.Name,
+		Description: pointer.Dereference(request.Body.Description),
+		Filter:      filter,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return openapi.CreateQueue200JSONResponse(mapQueue(q)), nil
+}
+
+func (s *Service) GetQueue(ctx context.Context, request openapi.GetQueueRequestObject) (openapi.GetQueueResponseObject, error) {
+	q, err := s.queries.GetQueue(ctx, request.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	return openapi.GetQueue200JSONResponse(mapQueue(q)), nil
+}
+
+func (s *Service) UpdateQueue(ctx context.Context, request openapi.UpdateQueueRequestObject) (openapi.UpdateQueueResponseObject, error) {
+	if request.Body.Name != nil {
+		if err := queue.Validate(*request.Body.Name); err != nil {
+			return nil, err
+		}
+	}
+
+	params := sqlc.UpdateQueueParams{
+		ID:          request.Id,
+		Name:        request.Body.Name,
+		Description: request.Body.Description,
+	}
+
+	if request.Body.Filter != nil {
+		var err error
+		if params.Filter, err = json.Marshal(request.Body.Filter); err != nil {
+			return nil, err
+		}
+	}
+
+	q, err := s.queries.UpdateQueue(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	return openapi.UpdateQueue200JSONResponse(mapQueue(q)), nil
+}
+
+func (s *Service) DeleteQueue(ctx context.Context, request openapi.DeleteQueueRequestObject) (openapi.DeleteQueueResponseObject, error) {
+	if err := s.queries.DeleteQueue(ctx, request.Id); err != nil {
+		return nil, err
+	}
+
+	return openapi.DeleteQueue204Response{}, nil
+}
+
+var errUnknownQueue = openapi.Error{
+	Status:  http.StatusNotFound,
+	Error:   "Not Found",
+	Message: "The queue does not exist",
+}
+
+// ClaimNextQueueTicket assigns the oldest unassigned ticket of the queue to
+// the user. Selecting and assigning the ticket is a single statement, so
+// concurrent claims always get different tickets. Encrypted tickets are
+// never part of a queue, as only granted users may see them.
+func (s *Service) ClaimNextQueueTicket(ctx context.Context, request openapi.ClaimNextQueueTicketRequestObject) (openapi.ClaimNextQueueTicketResponseObject, error) {
+	user, ok := usercontext.UserFromContext(ctx)
+	if !ok {
+		return nil, errors.New("no user in context")
+	}
+
+	q, err := s.queries.GetQueueByName(ctx, request.Name)
+	if errors.Is(err, sql.ErrNoRows) {
+		return openapi.ClaimNextQueueTicket404JSONResponse(errUnknownQueue), nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	claimed, err := s.queries.ClaimNextTicket(ctx, sqlc.ClaimNextTicketParams{
+		Owner: &user.ID,
+		Queue: q.ID,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return openapi.ClaimNextQueueTicket204Response{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	// the claim only sets the owner
+	unclaimed := claimed
+	unclaimed.Owner = nil
+
+	previous, err := s.mapTicket(ctx, &unclaimed)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := s.mapTicket(ctx, &claimed)
+	if err != nil {
+		return nil, err
+	}
+
+	s.hooks.OnRecordAfterUpdateRequest.Publish(hook.WithPrevious(ctx, previous), database.TicketsTable.ID, response)
+
+	return openapi.ClaimNextQueueTicket200JSONResponse(response), nil
+}
+
+func mapQueue(q sqlc.Queue) openapi.Queue {
+	var filter openapi.QueueFilter
+	if err := json.Unmarshal(q.Filter, &filter); err != nil {
+		slog.Error("Invalid queue filter", "queue", q.ID, "error", err)
+	}
+
+	return openapi.Queue{
+		Id:          q.ID,
+		Name:        q.Name,
+		Description: q.Description,
+		Filter:      filter,
+		Created:     q.Created,
+		Updated:     q.Updated,
+	}
+}
+
+func (s *Service) DeleteTicket(ctx context.Context, request openapi.DeleteTicketRequestObject) (openapi.DeleteTicketResponseObject, error) {
+	hold, err := s.queries.GetTicketLegalHold(ctx, request.Id)
+	if err == nil {
+		message := "The ticket is under legal hold"
+		if hold.Collection == database.FilesTable.ID {
+			message = "A file of the ticket is under legal hold"
+		}
+
+		return openapi.DeleteTicket403JSONResponse(legalHoldError(message, &hold)), nil
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	s.hooks.OnRecordBeforeDeleteRequest.Publish(ctx, database.TicketsTable.ID, request.Id)
+
+	err = s.queries.DeleteTicket(ctx, request.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	s.hooks.OnRecordAfterDeleteRequest.Publish(ctx, database.TicketsTable.ID, request.Id)
+
+	return openapi.DeleteTicket204Response{}, nil
+}
+
+func (s *Service) GetTicket(ctx context.Context, request openapi.GetTicketRequestObject) (openapi.GetTicketResponseObject, error) {
+	ticket, err := s.queries.Ticket(ctx, request.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	description, state, err := s.ticketContent(ctx, ticket.ID, ticket.Description, ticket.State)
+	if err != nil {
+		return nil, err
+	}
+
+	response := openapi.ExtendedTicket{
+		Created:        ticket.Created,
+		Description:    description,
+		Id:             ticket.ID,
+		Key:            ticket.Key,
+		Name:           ticket.Name,
+		Open:           ticket.Open,
+		Owner:          ticket.Owner,
+		OwnerName:      ticket.OwnerName,
+		Resolution:     ticket.Resolution,
+		Schema:         unmarshal(ticket.Schema),
+		State:          state,
+		Type:           ticket.Type,
+		TypePlural:     pointer.Dereference(ticket.TypePlural),
+		TypeSingular:   pointer.Dereference(ticket.TypeSingular),
+		Acknowledged:   ticket.Acknowledged,
+		AcknowledgedBy: ticket.AcknowledgedBy,
+		Resolved:       ticket.Resolved,
+		ResolvedBy:     ticket.ResolvedBy,
+		ReopenCount:    int(ticket.ReopenCount),
+		Encrypted:      ticket.Encrypted,
+		Updated:        ticket.Updated,
+	}
+
+	s.hooks.OnRecordViewRequest.Publish(ctx, database.TicketsTable.ID, response)
+
+	return openapi.GetTicket200JSONResponse(response), nil
+}
+
+func (s *Service) UpdateTicket(ctx context.Context, request openapi.UpdateTicketRequestObject) (openapi.UpdateTicketResponseObject, error) {
+	if rejected, err := s.applyPlugins(ctx, database.TicketsTable.ID, request.Body); err != nil {
+		return nil, err
+	} else if rejected != nil {
+		return openapi.UpdateTicket400JSONResponse(*rejected), nil
+	}
+
+	s.hooks.OnRecordBeforeUpdateRequest.Publish(ctx, database.TicketsTable.ID, request.Body)
+
+	previous, err := s.previousTicket(ctx, request.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	description, state := request.Body.Description, marshalPointer(request.Body.State)
+
+	if description != nil || state != nil {
+		key, encrypted, err := s.caseKey(ctx, request.Id)
+		if err != nil {
+			return nil, err
+		}
+
+		if encrypted {
+			if description, state, err = encryptContent(key, description, state); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	var resolvedBy *string
+	if user, ok := usercontext.UserFromContext(ctx); ok {
+		resolvedBy = &user.ID
+	}
+
+	ticket, err := s.queries.UpdateTicket(ctx, sqlc.UpdateTicketParams{
+		Name:        request.Body.Name,
+		Description: description,
+		Open:        request.Body.Open,
+		Owner:       request.Body.Owner,
+		Resolution:  request.Body.Resolution,
+		Schema:      marshalPointer(request.Body.Schema),
+		State:       state,
+		Type:        request.Body.Type,
+		ResolvedBy:  resolvedBy,
+		ID:          request.Id,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := s.mapTicket(ctx, &ticket)
+	if err != nil {
+		return nil, err
+	}
+
+	s.hooks.OnRecordAfterUpdateRequest.Publish(hook.WithPrevious(ctx, previous), database.TicketsTable.ID, redactTicket(response))
+
+	return openapi.UpdateTicket200JSONResponse(response), nil
+}
+
+// previousTicket returns a ticket before an update, like it is passed to the
+// update hooks.
+func (s *Service) previousTicket(ctx context.Context, id string) (openapi.Ticket, error) {
+	ticket, err := s.ticket(ctx, id)
+	if err != nil {
+		return openapi.Ticket{}, err
+	}
+
+	return redactTicket(ticket), nil
+}
+
+// ticket returns a single ticket, decrypted if the user is granted access.
+func (s *Service) ticket(ctx context.Context, id string) (openapi.Ticket, error) {
+	row, err := s.queries.Ticket(ctx, id)
+	if err != nil {
+		return openapi.Ticket{}, err
+	}
+
+	return s.mapTicket(ctx, &sqlc.Ticket{
+		ID:             row.ID,
+		Key:            row.Key,
+		Type:           row.Type,
+		Owner:          row.Owner,
+		Name:           row.Name,
+		Description:    row.Description,
+		Open:           row.Open,
+		Resolution:     row.Resolution,
+		Schema:         row.Schema,
+		State:          row.State,
+		Created:        row.Created,
+		Updated:        row.Updated,
+		Acknowledged:   row.Acknowledged,
+		AcknowledgedBy: row.AcknowledgedBy,
+		Resolved:       row.Resolved,
+		ResolvedBy:     row.ResolvedBy,
+		ReopenCount:    row.ReopenCount,
+		Encrypted:      row.Encrypted,
+	})
+}
+
+var errNotGranted = errors.New("the user is not granted access to the case key of the ticket")
+
+// encryptedStateKey holds the encrypted state of an encrypted ticket.
+const encryptedStateKey = "$encrypted"
+
+func (s *Service) EncryptTicket(ctx context.Context, request openapi.EncryptTicketRequestObject) (openapi.EncryptTicketResponseObject, error) {
+	user, ok := usercontext.UserFromContext(ctx)
+	if !ok {
+		return nil, errors.New("missing user")
+	}
+
+	ticket, err := s.queries.Ticket(ctx, request.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	if ticket.Encrypted {
+		return nil, errors.New("the ticket is already encrypted")
+	}
+
+	_, wrapped, err := s.keyring.NewCaseKey()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.queries.CreateTicketKey(ctx, sqlc.CreateTicketKeyParams{Ticket: ticket.ID, Key: wrapped}); err != nil {
+		return nil, err
+	}
+
+	// a previous, interrupted attempt may have stored a key already
+	wrapped, err = s.queries.GetTicketKey(ctx, ticket.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := s.keyring.Unwrap(wrapped)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, grantee := range []*string{&user.ID, ticket.Owner} {
+		if grantee == nil {
+			continue
+		}
+
+		if err := s.queries.CreateTicketGrant(ctx, sqlc.CreateTicketGrantParams{Ticket: ticket.ID, User: *grantee}); err != nil {
+			return nil, err
+		}
+	}
+
+	comments, err := database.PaginateItems(ctx, func(ctx context.Context, offset, limit int64) ([]sqlc.ListCommentsRow, error) {
+		return s.queries.ListComments(ctx, sqlc.ListCommentsParams{Ticket: ticket.ID, Offset: offset, Limit: limit})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, comment := range comments {
+		if casekey.IsEncrypted(comment.Message) {
+			continue
+		}
+
+		message, err := casekey.Encrypt(key, []byte(comment.Message))
+		if err != nil {
+			return nil, err
+		}
+
+		if err := s.queries.SetCommentMessage(ctx, sqlc.SetCommentMessageParams{ID: comment.ID, Message: message}); err != nil {
+			return nil, err
+		}
+	}
+
+	description, state, err := encryptContent(key, &ticket.Description, ticket.State)
+	if err != nil {
+		return nil, err
+	}
+
+	encrypted, err := s.queries.EncryptTicket(ctx, sqlc.EncryptTicketParams{
+		ID:          ticket.ID,
+		Description: *description,
+		State:       state,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := s.mapTicket(ctx, &encrypted)
+	if err != nil {
+		return nil, err
+	}
+
+	s.hooks.OnRecordAfterUpdateRequest.Publish(ctx, database.TicketsTable.ID, redactTicket(response))
+
+	return openapi.EncryptTicket200JSONResponse(response), nil
+}
+
+func (s *Service) ListTicketGrants(ctx context.Context, request openapi.ListTicketGrantsRequestObject) (openapi.ListTicketGrantsResponseObject, error) {
+	if _, err := s.grantedCaseKey(ctx, request.Id); err != nil {
+		return nil, err
+	}
+
+	grants, err := s.ticketGrants(ctx, request.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	return openapi.ListTicketGrants200JSONResponse(grants), nil
+}
+
+func (s *Service) CreateTicketGrant(ctx context.Context, request openapi.CreateTicketGrantRequestObject) (openapi.CreateTicketGrantResponseObject, error) {
+	if _, err := s.grantedCaseKey(ctx, request.Id); err != nil {
+		return nil, err
+	}
+
+	if err := s.queries.CreateTicketGrant(ctx, sqlc.CreateTicketGrantParams{Ticket: request.Id, User: request.Body.User}); err != nil {
+		return nil, err
+	}
+
+	grants, err := s.ticketGrants(ctx, request.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	return openapi.CreateTicketGrant200JSONResponse(grants), nil
+}
+
+func (s *Service) DeleteTicketGrant(ctx context.Context, request openapi.DeleteTicketGrantRequestObject) (openapi.DeleteTicketGrantResponseObject, error) {
+	if _, err := s.grantedCaseKey(ctx, request.Id); err != nil {
+		return nil, err
+	}
+
+	grants, err := s.ticketGrants(ctx, request.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(grants) == 1 && grants[0].User == request.User {
+		return nil, errors.New("the last grant of an encrypted ticket cannot be revoked")
+	}
+
+	if err := s.queries.DeleteTicketGrant(ctx, sqlc.DeleteTicketGrantParams{Ticket: request.Id, User: request.User}); err != nil {
+		return nil, err
+	}
+
+	return openapi.DeleteTicketGrant204Response{}, nil
+}
+
+func (s *Service) ticketGrants(ctx context.Context, ticket string) ([]openapi.TicketGrant, error) {
+	grants, err := s.queries.ListTicketGrants(ctx, ticket)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]openapi.TicketGrant, 0, len(grants))
+	for _, grant := range grants {
+		response = append(response, openapi.TicketGrant{
+			Ticket:   grant.Ticket,
+			User:     grant.User,
+			Username: grant.Username,
+			UserName: grant.UserName,
+			Created:  grant.Created,
+		})
+	}
+
+	return response, nil
+}
+
+// caseKey returns the case key of an encrypted ticket, or nil if the user is
+// not granted access to it. Tickets that are not encrypted have no case key.
+func (s *Service) caseKey(ctx context.Context, ticket string) (key []byte, encrypted bool, err error) {
+	wrapped, err := s.queries.GetTicketKey(ctx, ticket)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, false, nil
+	} else if err != nil {
+		return nil, false, err
+	}
+
+	user, ok := usercontext.UserFromContext(ctx)
+	if !ok {
+		return nil, true, nil
+	}
+
+	granted, err := s.queries.HasTicketGrant(ctx, sqlc.HasTicketGrantParams{Ticket: ticket, User: user.ID})
+	if err != nil {
+		return nil, true, err
+	}
+
+	if granted == 0 {
+		return nil, true, nil
+	}
+
+	key, err = s.keyring.Unwrap(wrapped)
+	if err != nil {
+		return nil, true, err
+	}
+
+	return key, true, nil
+}
+
+// grantedCaseKey returns the case key of an encrypted ticket and fails if the
+// ticket is not encrypted or the user is not granted access to it.
+func (s *Service) grantedCaseKey(ctx context.Context, ticket string) ([]byte, error) {
+	key, encrypted, err := s.caseKey(ctx, ticket)
+	if err != nil {
+		return nil, err
+	}
+
+	if !encrypted {
+		return nil, errors.New("the ticket is not encrypted")
+	}
+
+	if key == nil {
+		return nil, errNotGranted
+	}
+
+	return key, nil
+}
+
+// ticketContent returns the description and state of a ticket, decrypted or
+// redacted for encrypted tickets.
+func (s *Service) ticketContent(ctx context.Context, ticket, description string, state json.RawMessage) (string, map[string]any, error) {
+	if !casekey.IsEncrypted(description) {
+		return description, unmarshal(state), nil
+	}
+
+	key, encrypted, err := s.caseKey(ctx, ticket)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if !encrypted {
+		return description, unmarshal(state), nil
+	}
+
+	if description, err = decryptText(key, description); err != nil {
+		return "", nil, err
+	}
+
+	decryptedState, err := decryptState(key, state)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return description, decryptedState, nil
+}
+
+func (s *Service) mapTicket(ctx context.Context, ticket *sqlc.Ticket) (openapi.Ticket, error) {
+	description, state, err := s.ticketContent(ctx, ticket.ID, ticket.Description, ticket.State)
+	if err != nil {
+		return openapi.Ticket{}, err
+	}
+
+	return openapi.Ticket{
+		Created:        ticket.Created,
+		Description:    description,
+		Id:             ticket.ID,
+		Key:            ticket.Key,
+		Name:           ticket.Name,
+		Open:           ticket.Open,
+		Owner:          ticket.Owner,
+		Resolution:     ticket.Resolution,
+		Schema:         unmarshal(ticket.Schema),
+		State:          state,
+		Type:           ticket.Type,
+		Acknowledged:   ticket.Acknowledged,
+		AcknowledgedBy: ticket.AcknowledgedBy,
+		Resolved:       ticket.Resolved,
+		ResolvedBy:     ticket.ResolvedBy,
+		ReopenCount:    int(ticket.ReopenCount),
+		Encrypted:      ticket.Encrypted,
+		Updated:        ticket.Updated,
+	}, nil
+}
+
+// encryptMessage encrypts a comment message if the ticket is encrypted.
+func (s *Service) encryptMessage(ctx context.Context, ticket, message string) (string, error) {
+	key, encrypted, err := s.caseKey(ctx, ticket)
+	if err != nil || !encrypted {
+		return message, err
+	}
+
+	if key == nil {
+		return "", errNotGranted
+	}
+
+	return casekey.Encrypt(key, []byte(message))
+}
+
+// decryptMessage decrypts a comment message of an encrypted ticket.
+func (s *Service) decryptMessage(ctx context.Context, ticket, message string) (string, error) {
+	if !casekey.IsEncrypted(message) {
+		return message, nil
+	}
+
+	key, _, err := s.caseKey(ctx, ticket)
+	if err != nil {
+		return "", err
+	}
+
+	return decryptText(key, message)
+}
+
+// encryptContent encrypts the description and state of a ticket. A nil key
+// means the user is not granted access to the case key.
+func encryptContent(key []byte, description *string, state json.RawMessage) (*string, json.RawMessage, error) {
+	if key == nil {
+		return nil, nil, errNotGranted
+	}
+
+	if description != nil {
+		encrypted, err := casekey.Encrypt(key, []byte(*description))
+		if err != nil {
+			return nil, nil, err
+		}
+
+		description = &encrypted
+	}
+
+	if state != nil {
+		encrypted, err := casekey.Encrypt(key, state)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if state, err = json.Marshal(map[string]string{encryptedStateKey: encrypted}); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return description, state, nil
+}
+
+// decryptText decrypts an encrypted value, or redacts it without a key.
+func decryptText(key []byte, value string) (string, error) {
+	if !casekey.IsEncrypted(value) {
+		return value, nil
+	}
+
+	if key == nil {
+		return redacted, nil
+	}
+
+	plaintext, err := casekey.Decrypt(key, value)
+	if err != nil {
+		return "", err
+	}
+
+	return string(plaintext), nil
+}
+
+// decryptState decrypts the state of an encrypted ticket, or returns an empty
+// state without a key.
+func decryptState(key []byte, state json.RawMessage) (map[string]any, error) {
+	wrapper := unmarshal(state)
+
+	encrypted, ok := wrapper[encryptedStateKey].(string)
+	if !ok || !casekey.IsEncrypted(encrypted) {
+		return wrapper, nil
+	}
+
+	if key == nil {
+		return map[string]any{}, nil
+	}
+
+	plaintext, err := casekey.Decrypt(key, encrypted)
+	if err != nil {
+		return nil, err
+	}
+
+	return unmarshal(plaintext), nil
+}
+
+// redactTicket removes the content of encrypted tickets before they are
+// passed to hooks, so that webhooks and integrations do not leak it.
+func redactTicket(ticket openapi.Ticket) openapi.Ticket {
+	if ticket.Encrypted {
+		ticket.Description = redacted
+		ticket.State = map[string]any{}
+	}
+
+	return ticket
+}
+
+// maskSecrets masks the registered secrets in a text before it is stored,
+// so that automations that post their output as comments do not leak them.
+func (s *Service) maskSecrets(ctx context.Context, text string) (string, error) {
+	redactor, err := redact.Load(ctx, s.queries)
+	if err != nil {
+		return "", err
+	}
+
+	return redactor.String(text), nil
+}
+
+func redactComment(comment openapi.Comment, encrypted bool) openapi.Comment {
+	if encrypted {
+		comment.Message = redacted
+	}
+
+	return comment
+}
+
+func (s *Service) ListTimeline(ctx context.Context, request openapi.ListTimelineRequestObject) (openapi.ListTimelineResponseObject, error) {
+	timeline, err := s.queries.ListTimeline(ctx, sqlc.ListTimelineParams{
+		Ticket: toString(request.Params.Ticket, ""),
+		Offset: toInt64(request.Params.Offset, defaultOffset),
+		Limit:  toInt64(request.Params.Limit, defaultLimit),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]openapi.TimelineEntry, 0, len(timeline))
+	for _, timeline := range timeline {
+		response = append(response, openapi.TimelineEntry{
+			Id:      timeline.ID,
+			Message: timeline.Message,
+			Created: timeline.Created,
+			Updated: timeline.Updated,
+			Time:    timeline.Time,
+			Ticket:  timeline.Ticket,
+		})
+	}
+
+	s.hooks.OnRecordsListRequest.Publish(ctx, database.TimelinesTable.ID, response)
+
+	totalCount := 0
+	if len(timeline) > 0 {
+		totalCount = int(timeline[0].TotalCount)
+	}
+
+	return openapi.ListTimeline200JSONResponse{
+		Body: response,
+		Headers: openapi.ListTimeline200ResponseHeaders{
+			XTotalCount: totalCount,
+		},
+	}, nil
+}
+
+func (s *Service) CreateTimeline(ctx context.Context, request openapi.CreateTimelineRequestObject) (openapi.CreateTimelineResponseObject, error) {
+	s.hooks.OnRecordBeforeCreateRequest.Publish(ctx, database.TimelinesTable.ID, request.Body)
+
+	timeline, err := s.queries.CreateTimeline(ctx, sqlc.CreateTimelineParams{
+		Message: request.Body.Message,
+		Time:    request.Body.Time,
+		Ticket:  request.Body.Ticket,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := openapi.TimelineEntry{
+		Created: timeline.Created,
+		Id:      timeline.ID,
+		Message: timeline.Message,
+		Ticket:  timeline.Ticket,
+		Time:    timeline.Time,
+		Updated: timeline.Updated,
+	}
+
+	s.hooks.OnRecordAfterCreateRequest.Publish(ctx, database.TimelinesTable.ID, response)
+
+	return openapi.CreateTimeline200JSONResponse(response), nil
+}
+
+func (s *Service) DeleteTimeline(ctx context.Context, request openapi.DeleteTimelineRequestObject) (openapi.DeleteTimelineResponseObject, error) {
+	s.hooks.OnRecordBeforeDeleteRequest.Publish(ctx, database.TimelinesTable.ID, request.Id)
+
+	err := s.queries.DeleteTimeline(ctx, request.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	s.hooks.OnRecordAfterDeleteRequest.Publish(ctx, database.TimelinesTable.ID, request.Id)
+
+	return openapi.DeleteTimeline204Response{}, nil
+}
+
+func (s *Service) GetTimeline(ctx context.Context, request openapi.GetTimelineRequestObject) (openapi.GetTimelineResponseObject, error) {
+	timeline, err := s.queries.GetTimeline(ctx, request.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	response := openapi.TimelineEntry{
+		Id:      timeline.ID,
+		Message: timeline.Message,
+		Created: timeline.Created,
+		Updated: timeline.Updated,
+		Time:    timeline.Time,
+		Ticket:  timeline.Ticket,
+	}
+
+	s.hooks.OnRecordViewRequest.Publish(ctx, database.TimelinesTable.ID, response)
+
+	return openapi.GetTimeline200JSONResponse(response), nil
+}
+
+func (s *Service) UpdateTimeline(ctx context.Context, request openapi.UpdateTimelineRequestObject) (openapi.UpdateTimelineResponseObject, error) {
+	s.hooks.OnRecordBeforeUpdateRequest.Publish(ctx, database.TimelinesTable.ID, request.Body)
+
+	timeline, err := s.queries.UpdateTimeline(ctx, sqlc.UpdateTimelineParams{
+		ID:      request.Id,
+		Message: request.Body.Message,
+		Time:    request.Body.Time,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := openapi.TimelineEntry{
+		Created: timeline.Created,
+		Id:      timeline.ID,
+		Message: timeline.Message,
+		Ticket:  timeline.Ticket,
+		Time:    timeline.Time,
+		Updated: timeline.Updated,
+	}
+
+	s.hooks.OnRecordAfterUpdateRequest.Publish(ctx, database.TimelinesTable.ID, response)
+
+	return openapi.UpdateTimeline200JSONResponse(response), nil
+}
+
+func (s *Service) ListTypes(ctx context.Context, request openapi.ListTypesRequestObject) (openapi.ListTypesResponseObject, error) {
+	types, err := s.queries.ListTypes(ctx, sqlc.ListTypesParams{
+		Offset: toInt64(request.Params.Offset, defaultOffset),
+		Limit:  toInt64(request.Params.Limit, defaultLimit),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]openapi.Type, 0, len(types))
+	for _, t := range types {
+		response = append(response, openapi.Type{
+			Created:   t.Created,
+			Icon:      t.Icon,
+			Id:        t.ID,
+			Plural:    t.Plural,
+			Schema:    unmarshal(t.Schema),
+			Singular:  t.Singular,
+			KeyPrefix: t.KeyPrefix,
+			Metrics:   unmarshalMetrics(t.Metrics),
+			Updated:   t.Updated,
+		})
+	}
+
+	s.hooks.OnRecordsListRequest.Publish(ctx, database.TypesTable.ID, response)
+
+	totalCount := 0
+	if len(types) > 0 {
+		totalCount = int(types[0].TotalCount)
+	}
+
+	return openapi.ListTypes200JSONResponse{
+		Body: response,
+		Headers: openapi.ListTypes200ResponseHeaders{
+			XTotalCount: totalCount,
+		},
+	}, nil
+}
+
+func (s *Service) CreateType(ctx context.Context, request openapi.CreateTypeRequestObject) (openapi.CreateTypeResponseObject, error) {
+	if err := ticketkey.ValidatePrefix(pointer.Dereference(request.Body.KeyPrefix)); err != nil {
+		return openapi.CreateType400JSONResponse{
+			Status:  http.StatusBadRequest,
+			Error:   "Bad Request",
+			Message: err.Error(),
+		}, nil
+	}
+
+	if err := metric.Validate(marshal(request.Body.Schema), pointer.Dereference(request.Body.Metrics)); err != nil {
+		return openapi.CreateType400JSONResponse{
+			Status:  http.StatusBadRequest,
+			Error:   "Bad Request",
+			Message: err.Error(),
+		}, nil
+	}
+
+	s.hooks.OnRecordBeforeCreateRequest.Publish(ctx, database.TypesTable.ID, request.Body)
+
+	t, err := s.queries.CreateType(ctx, sqlc.CreateTypeParams{
+		Icon:      request.Body.Icon,
+		Plural:    request.Body.Plural,
+		Singular:  request.Body.Singular,
+		Schema:    marshal(request.Body.Schema),
+		KeyPrefix: request.Body.KeyPrefix,
+		Metrics:   marshalMetrics(request.Body.Metrics),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := openapi.Type{
+		Created:   t.Created,
+		Icon:      t.Icon,
+		Id:        t.ID,
+		Plural:    t.Plural,
+		Schema:    unmarshal(t.Schema),
+		Singular:  t.Singular,
+		KeyPrefix: t.KeyPrefix,
+		Metrics:   unmarshalMetrics(t.Metrics),
+		Updated:   t.Updated,
+	}
+
+	s.hooks.OnRecordAfterCreateRequest.Publish(ctx, database.TypesTable.ID, response)
+
+	return openapi.CreateType200JSONResponse(response), nil
+}
+
+func (s *Service) DeleteType(ctx context.Context, request openapi.DeleteTypeRequestObject) (openapi.DeleteTypeResponseObject, error) {
+	s.hooks.OnRecordBeforeDeleteRequest.Publish(ctx, database.TypesTable.ID, request.Id)
+
+	if err := s.queries.DeleteType(ctx, request.Id); err != nil {
+		return nil, fmt.Errorf("failed to delete type: %w", err)
+	}
+
+	s.hooks.OnRecordAfterDeleteRequest.Publish(ctx, database.TypesTable.ID, request.Id)
+
+	return openapi.DeleteType204Response{}, nil
+}
+
+func (s *Service) GetType(ctx context.Context, request openapi.GetTypeRequestObject) (openapi.GetTypeResponseObject, error) {
+	t, err := s.queries.GetType(ctx, request.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	response := openapi.Type{
+		Created:   t.Created,
+		Icon:      t.Icon,
+		Id:        t.ID,
+		Plural:    t.Plural,
+		Schema:    unmarshal(t.Schema),
+		Singular:  t.Singular,
+		KeyPrefix: t.KeyPrefix,
+		Metrics:   unmarshalMetrics(t.Metrics),
+		Updated:   t.Updated,
+	}
+
+	s.hooks.OnRecordViewRequest.Publish(ctx, database.TypesTable.ID, response)
+
+	return openapi.GetType200JSONResponse(response), nil
+}
+
+func (s *Service) UpdateType(ctx context.Context, request openapi.UpdateTypeRequestObject) (openapi.UpdateTypeResponseObject, error) {
+	if err := ticketkey.ValidatePrefix(pointer.Dereference(request.Body.KeyPrefix)); err != nil {
+		return openapi.UpdateType400JSONResponse{
+			Status:  http.StatusBadRequest,
+			Error:   "Bad Request",
+			Message: err.Error(),
+		}, nil
+	}
+
+	// a new schema must still have the metrics
+	if request.Body.Schema != nil || request.Body.Metrics != nil {
+		current, err := s.queries.GetType(ctx, request.Id)
+		if err != nil {
+			return nil, err
+		}
+
+		schema := current.Schema
+		if request.Body.Schema != nil {
+			schema = marshal(*request.Body.Schema)
+		}
+
+		metrics := unmarshalMetrics(current.Metrics)
+		if request.Body.Metrics != nil {
+			metrics = *request.Body.Metrics
+		}
+
+		if err := metric.Validate(schema, metrics); err != nil {
+			return openapi.UpdateType400JSONResponse{
+				Status:  http.StatusBadRequest,
+				Error:   "Bad Request",
+				Message: err.Error(),
+			}, nil
+		}
+	}
+
+	s.hooks.OnRecordBeforeUpdateRequest.Publish(ctx, database.TypesTable.ID, request.Body)
+
+	params := sqlc.UpdateTypeParams{
+		ID:        request.Id,
+		Icon:      request.Body.Icon,
+		Plural:    request.Body.Plural,
+		Singular:  request.Body.Singular,
+		KeyPrefix: request.Body.KeyPrefix,
+		Metrics:   marshalMetrics(request.Body.Metrics),
+	}
+
+	// without a schema the stored schema is kept
+	if request.Body.Schema != nil {
+		params.Schema = marshal(*request.Body.Schema)
+	}
+
+	t, err := s.queries.UpdateType(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	response := openapi.Type{
+		Created:   t.Created,
+		Icon:      t.Icon,
+		Id:        t.ID,
+		Plural:    t.Plural,
+		Schema:    unmarshal(t.Schema),
+		Singular:  t.Singular,
+		KeyPrefix: t.KeyPrefix,
+		Metrics:   unmarshalMetrics(t.Metrics),
+		Updated:   t.Updated,
+	}
+
+	s.hooks.OnRecordAfterUpdateRequest.Publish(ctx, database.TypesTable.ID, response)
+
+	return openapi.UpdateType200JSONResponse(response), nil
+}
+
+func (s *Service) ListUsers(ctx context.Context, request openapi.ListUsersRequestObject) (openapi.ListUsersResponseObject, error) {
+	users, err := s.queries.ListUsers(ctx, sqlc.ListUsersParams{
+		Offset: toInt64(request.Params.Offset, defaultOffset),
+		Limit:  toInt64(request.Params.Limit, defaultLimit),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]openapi.User, 0, len(users))
+	for _, user := range users {
+		response = append(response, openapi.User{
+			Avatar:                 user.Avatar,
+			Created:                user.Created,
+			Email:                  user.Email,
+			Id:                     user.ID,
+			LastResetSentAt:        user.Lastresetsentat,
+			LastVerificationSentAt: user.Lastverificationsentat,
+			Name:                   user.Name,
+			Updated:                user.Updated,
+			Username:               user.Username,
+			Active:                 user.Active,
+		})
+	}
+
+	s.hooks.OnRecordsListRequest.Publish(ctx, database.UsersTable.ID, response)
+
+	totalCount := 0
+	if len(users) > 0 {
+		totalCount = int(users[0].TotalCount)
+	}
+
+	return openapi.ListUsers200JSONResponse{
+		Body: response,
+		Headers: openapi.ListUsers200ResponseHeaders{
+			XTotalCount: totalCount,
+		},
+	}, nil
+}
+
+func (s *Service) CreateUser(ctx context.Context, request openapi.CreateUserRequestObject) (openapi.CreateUserResponseObject, error) {
+	s.hooks.OnRecordBeforeCreateRequest.Publish(ctx, database.UsersTable.ID, request.Body)
+
+	tokenKey, err := password.GenerateTokenKey()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate token key: %w", err)
+	}
+
+	user, err := s.queries.CreateUser(ctx, sqlc.CreateUserParams{
+		Name:         request.Body.Name,
+		Email:        request.Body.Email,
+		Username:     request.Body.Username,
+		PasswordHash: "",
+		TokenKey:     tokenKey,
+		Avatar:       request.Body.Avatar,
+		Active:       request.Body.Active,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := openapi.User{
+		Avatar:                 user.Avatar,
+		Created:                user.Created,
+		Email:                  user.Email,
+		Id:                     user.ID,
+		LastResetSentAt:        user.Lastresetsentat,
+		LastVerificationSentAt: user.Lastverificationsentat,
+		Name:                   user.Name,
+		Updated:                user.Updated,
+		Username:               user.Username,
+		Active:                 user.Active,
+	}
+
+	s.hooks.OnRecordAfterCreateRequest.Publish(ctx, database.UsersTable.ID, response)
+
+	return openapi.CreateUser200JSONResponse(response), nil
+}
+
+func (s *Service) DeleteUser(ctx context.Context, request openapi.DeleteUserRequestObject) (openapi.DeleteUserResponseObject, error) {
+	s.hooks.OnRecordBeforeDeleteRequest.Publish(ctx, database.UsersTable.ID, request.Id)
+
+	err := s.queries.DeleteUser(ctx, request.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	s.hooks.OnRecordAfterDeleteRequest.Publish(ctx, database.UsersTable.ID, request.Id)
+
+	return openapi.DeleteUser204Response{}, nil
+}
+
+func (s *Service) GetUser(ctx context.Context, request openapi.GetUserRequestObject) (openapi.GetUserResponseObject, error) {
+	user, err := s.queries.GetUser(ctx, request.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	response := openapi.User{
+		Avatar:                 user.Avatar,
+		Created:                user.Created,
+		Email:                  user.Email,
+		Id:                     user.ID,
+		LastResetSentAt:        user.Lastresetsentat,
+		LastVerificationSentAt: user.Lastverificationsentat,
+		Name:                   user.Name,
+		Updated:                user.Updated,
+		Username:               user.Username,
+		Active:                 user.Active,
+	}
+
+	s.hooks.OnRecordViewRequest.Publish(ctx, database.UsersTable.ID, response)
+
+	return openapi.GetUser200JSONResponse(response), nil
+}
+
+func (s *Service) UpdateUser(ctx context.Context, request openapi.UpdateUserRequestObject) (openapi.UpdateUserResponseObject, error) {
+	s.hooks.OnRecordBeforeUpdateRequest.Publish(ctx, database.UsersTable.ID, request.Body)
+
+	var passwordHash, tokenHash *string
+
+	switch {
+	case request.Body.Password == nil && request.Body.PasswordConfirm == nil:
+	case request.Body.Password != nil && request.Body.PasswordConfirm != nil:
+		if *request.Body.Password != *request.Body.PasswordConfirm {
+			return nil, errors.New("passwords do not match")
+		}
+
+		if err := password.Check(ctx, s.queries, request.Id, *request.Body.Password); err != nil {
+			if errors.Is(err, password.ErrPolicy) {
+				return openapi.UpdateUser400JSONResponse{Status: http.StatusBadRequest, Error: "Bad Request", Message: err.Error()}, nil
+			}
+
+			return nil, err
+		}
+
+		passwordHashS, tokenHashS, err := password.Hash(*request.Body.Password)
+		if err != nil {
+			return nil, fmt.Errorf("failed to hash password: %w", err)
+		}
+
+		passwordHash = &passwordHashS
+		tokenHash = &tokenHashS
+	default:
+		return nil, errors.New("password and password confirm must be provided together")
+	}
+
+	user, err := s.queries.UpdateUser(ctx, sqlc.UpdateUserParams{
+		Name:         request.Body.Name,
+		Email:        request.Body.Email,
+		Username:     request.Body.Username,
+		PasswordHash: passwordHash,
+		TokenKey:     tokenHash,
+		Avatar:       request.Body.Avatar,
+		Active:       request.Body.Active,
+		ID:           request.Id,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if passwordHash != nil {
+		if err := password.RecordHistory(ctx, s.queries, user.ID, *passwordHash); err != nil {
+			return nil, err
+		}
+	}
+
+	response := openapi.User{
+		Avatar:                 user.Avatar,
+		Created:                user.Created,
+		Email:                  user.Email,
+		Id:                     user.ID,
+		LastResetSentAt:        user.Lastresetsentat,
+		LastVerificationSentAt: user.Lastverificationsentat,
+		Name:                   user.Name,
+		Updated:                user.Updated,
+		Username:               user.Username,
+		Active:                 user.Active,
+	}
+
+	s.hooks.OnRecordAfterUpdateRequest.Publish(ctx, "users", response)
+
+	return openapi.UpdateUser200JSONResponse(response), nil
+}
+
+func (s *Service) PreviewUserDeactivation(ctx context.Context, request openapi.PreviewUserDeactivationRequestObject) (openapi.PreviewUserDeactivationResponseObject, error) {
+	summary, err := deactivation.Preview(ctx, s.queries, request.Id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return openapi.PreviewUserDeactivation404JSONResponse{Status: http.StatusNotFound, Error: "Not Found", Message: "The user does not exist"}, nil
+		}
+
+		return nil, err
+	}
+
+	return openapi.PreviewUserDeactivation200JSONResponse(mapDeactivationSummary(summary)), nil
+}
+
+func (s *Service) DeactivateUser(ctx context.Context, request openapi.DeactivateUserRequestObject) (openapi.DeactivateUserResponseObject, error) {
+	s.hooks.OnRecordBeforeUpdateRequest.Publish(ctx, database.UsersTable.ID, request.Body)
+
+	summary, err := deactivation.Deactivate(ctx, s.queries, request.Id, deactivation.Target{
+		User:  toString(request.Body.ReassignUser, ""),
+		Group: toString(request.Body.ReassignGroup, ""),
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return openapi.DeactivateUser404JSONResponse{Status: http.StatusNotFound, Error: "Not Found", Message: "The user does not exist"}, nil
+		case errors.Is(err, deactivation.ErrTarget), errors.Is(err, deactivation.ErrSystemUser):
+			return openapi.DeactivateUser400JSONResponse{Status: http.StatusBadRequest, Error: "Bad Request", Message: err.Error()}, nil
+		}
+
+		return nil, err
+	}
+
+	user, err := s.queries.GetUser(ctx, request.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	s.hooks.OnRecordAfterUpdateRequest.Publish(ctx, database.UsersTable.ID, openapi.User{
+		Avatar:                 user.Avatar,
+		Created:                user.Created,
+		Email:                  user.Email,
+		Id:                     user.ID,
+		LastResetSentAt:        user.Lastresetsentat,
+		LastVerificationSentAt: user.Lastverificationsentat,
+		Name:                   user.Name,
+		Updated:                user.Updated,
+		Username:               user.Username,
+		Active:                 user.Active,
+	})
+
+	return openapi.DeactivateUser200JSONResponse(mapDeactivationSummary(summary)), nil
+}
+
+func mapDeactivationSummary(summary *deactivation.Summary) openapi.DeactivationSummary {
+	return openapi.DeactivationSummary{
+		Tickets:            append([]string{}, summary.Tickets...),
+		Tasks:              append([]string{}, summary.Tasks...),
+		TaskTimers:         append([]string{}, summary.TaskTimers...),
+		EscalationPolicies: append([]string{}, summary.EscalationPolicies...),
+		NotificationRules:  append([]string{}, summary.NotificationRules...),
+		Digest:             summary.Digest,
+		PushSubscriptions:  summary.PushSubscriptions,
+	}
+}
+
+func (s *Service) ListGroups(ctx context.Context, request openapi.ListGroupsRequestObject) (openapi.ListGroupsResponseObject, error) {
+	groups, err := s.queries.ListGroups(ctx, sqlc.ListGroupsParams{
+		Offset: toInt64(request.Params.Offset, defaultOffset),
+		Limit:  toInt64(request.Params.Limit, defaultLimit),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]openapi.Group, 0, len(groups))
+
+	for _, group := range groups {
+		response = append(response, openapi.Group{
+			Created:     group.Created,
+			Id:          group.ID,
+			Name:        group.Name,
+			Permissions: auth.FromJSONArray(ctx, group.Permissions),
+			Updated:     group.Updated,
+		})
+	}
+
+	s.hooks.OnRecordsListRequest.Publish(ctx, database.GroupsTable.ID, response)
+
+	totalCount := 0
+	if len(groups) > 0 {
+		totalCount = int(groups[0].TotalCount)
+	}
+
+	return openapi.ListGroups200JSONResponse{
+		Body: response,
+		Headers: openapi.ListGroups200ResponseHeaders{
+			XTotalCount: totalCount,
+		},
+	}, nil
+}
+
+func (s *Service) CreateGroup(ctx context.Context, request openapi.CreateGroupRequestObject) (openapi.CreateGroupResponseObject, error) {
+	s.hooks.OnRecordBeforeCreateRequest.Publish(ctx, database.GroupsTable.ID, request.Body)
+
+	group, err := s.queries.CreateGroup(ctx, sqlc.CreateGroupParams{
+		Name:        request.Body.Name,
+		Permissions: auth.ToJSONArray(ctx, request.Body.Permissions),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := openapi.Group{
+		Created:     group.Created,
+		Id:          group.ID,
+		Name:        group.Name,
+		Permissions: auth.FromJSONArray(ctx, group.Permissions),
+		Updated:     group.Updated,
+	}
+
+	s.hooks.OnRecordAfterCreateRequest.Publish(ctx, database.GroupsTable.ID, response)
+
+	return openapi.CreateGroup200JSONResponse(response), nil
+}
+
+func (s *Service) DeleteGroup(ctx context.Context, request openapi.DeleteGroupRequestObject) (openapi.DeleteGroupResponseObject, error) {
+	s.hooks.OnRecordBeforeDeleteRequest.Publish(ctx, database.GroupsTable.ID, request.Id)
+
+	if request.Id == "admin" {
+		return nil, errors.New("cannot delete the admin group")
+	}
+
+	err := s.queries.DeleteGroup(ctx, request.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	s.hooks.OnRecordAfterDeleteRequest.Publish(ctx, database.GroupsTable.ID, request.Id)
+
+	return openapi.DeleteGroup204Response{}, nil
+}
+
+func (s *Service) GetGroup(ctx context.Context, request openapi.GetGroupRequestObject) (openapi.GetGroupResponseObject, error) {
+	group, err := s.queries.GetGroup(ctx, request.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	response := openapi.Group{
+		Created:     group.Created,
+		Id:          group.ID,
+		Name:        group.Name,
+		Permissions: auth.FromJSONArray(ctx, group.Permissions),
+		Updated:     group.Updated,
+	}
+
+	s.hooks.OnRecordViewRequest.Publish(ctx, database.GroupsTable.ID, response)
+
+	return openapi.GetGroup200JSONResponse(response), nil
+}
+
+func (s *Service) UpdateGroup(ctx context.Context, request openapi.UpdateGroupRequestObject) (openapi.UpdateGroupResponseObject, error) {
+	s.hooks.OnRecordBeforeUpdateRequest.Publish(ctx, database.GroupsTable.ID, request.Body)
+
+	if request.Id == "admin" {
+		return nil, errors.New("cannot update the admin group")
+	}
+
+	var permissions *string
+
+	if request.Body.Permissions != nil {
+		p := auth.ToJSONArray(ctx, *request.Body.Permissions)
+		permissions = &p
+	}
+
+	group, err := s.queries.UpdateGroup(ctx, sqlc.UpdateGroupParams{
+		Name:        request.Body.Name,
+		Permissions: permissions,
+		ID:          request.Id,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := openapi.Group{
+		Created:     group.Created,
+		Id:          group.ID,
+		Name:        group.Name,
+		Permissions: auth.FromJSONArray(ctx, group.Permissions),
+		Updated:     group.Updated,
+	}
+
+	s.hooks.OnRecordAfterUpdateRequest.Publish(ctx, database.GroupsTable.ID, response)
+
+	return openapi.UpdateGroup200JSONResponse(response), nil
+}
+
+func (s *Service) AddGroupParent(ctx context.Context, request openapi.AddGroupParentRequestObject) (openapi.AddGroupParentResponseObject, error) {
+	s.hooks.OnRecordBeforeCreateRequest.Publish(ctx, database.GroupParentTable.ID, request.Body)
+
+	err := s.queries.AssignParentGroup(ctx, sqlc.AssignParentGroupParams{
+		ChildGroupID:  request.Id,
+		ParentGroupID: request.Body.GroupId,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.hooks.OnRecordAfterCreateRequest.Publish(ctx, database.GroupParentTable.ID, request.Body)
+
+	return openapi.AddGroupParent201Response{}, nil
+}
+
+func (s *Service) RemoveGroupParent(ctx context.Context, request openapi.RemoveGroupParentRequestObject) (openapi.RemoveGroupParentResponseObject, error) {
+	s.hooks.OnRecordBeforeDeleteRequest.Publish(ctx, database.GroupParentTable.ID, request.Id)
+
+	err := s.queries.RemoveParentGroup(ctx, sqlc.RemoveParentGroupParams{
+		ChildGroupID:  request.Id,
+		ParentGroupID: request.ParentGroupId,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.hooks.OnRecordAfterDeleteRequest.Publish(ctx, database.GroupParentTable.ID, request.Id)
+
+	return openapi.RemoveGroupParent204Response{}, nil
+}
+
+func (s *Service) AddUserGroup(ctx context.Context, request openapi.AddUserGroupRequestObject) (openapi.AddUserGroupResponseObject, error) {
+	s.hooks.OnRecordBeforeCreateRequest.Publish(ctx, database.UserGroupTable.ID, request.Body)
+
+	err := s.queries.AssignGroupToUser(ctx, sqlc.AssignGroupToUserParams{
+		UserID:  request.Id,
+		GroupID: request.Body.GroupId,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.hooks.OnRecordAfterCreateRequest.Publish(ctx, database.UserGroupTable.ID, request.Body)
+
+	return openapi.AddUserGroup201Response{}, nil
+}
+
+func (s *Service) RemoveUserGroup(ctx context.Context, request openapi.RemoveUserGroupRequestObject) (openapi.RemoveUserGroupResponseObject, error) {
+	s.hooks.OnRecordBeforeDeleteRequest.Publish(ctx, database.UserGroupTable.ID, request.Id)
+
+	err := s.queries.RemoveGroupFromUser(ctx, sqlc.RemoveGroupFromUserParams{
+		UserID:  request.Id,
+		GroupID: request.GroupId,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.hooks.OnRecordAfterDeleteRequest.Publish(ctx, database.UserGroupTable.ID, request.Id)
+
+	return openapi.RemoveUserGroup204Response{}, nil
+}
+
+func (s *Service) ListUserPermissions(ctx context.Context, request openapi.ListUserPermissionsRequestObject) (openapi.ListUserPermissionsResponseObject, error) {
+	permissions, err := s.queries.ListUserPermissions(ctx, request.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	s.hooks.OnRecordsListRequest.Publish(ctx, database.UserPermissionTable.ID, permissions)
+
+	return openapi.ListUserPermissions200JSONResponse(permissions), nil
+}
+
+func (s *Service) ListUserGroups(ctx context.Context, request openapi.ListUserGroupsRequestObject) (openapi.ListUserGroupsResponseObject, error) {
+	groups, err := s.queries.ListUserGroups(ctx, request.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]openapi.UserGroup, 0, len(groups))
+	for _, group := range groups {
+		response = append(response, openapi.UserGroup{
+			Created:     group.Created,
+			Id:          group.ID,
+			Name:        group.Name,
+			Permissions: auth.FromJSONArray(ctx, group.Permissions),
+			Type:        group.GroupType,
+			Updated:     group.Updated,
+		})
+	}
+
+	s.hooks.OnRecordsListRequest.Publish(ctx, database.UserGroupTable.ID, response)
+
+	return openapi.ListUserGroups200JSONResponse(response), nil
+}
+
+func (s *Service) ListGroupUsers(ctx context.Context, request openapi.ListGroupUsersRequestObject) (openapi.ListGroupUsersResponseObject, error) {
+	users, err := s.queries.ListGroupUsers(ctx, request.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]openapi.GroupUser, 0, len(users))
+	for _, user := range users {
+		response = append(response, openapi.GroupUser{
+			Avatar:                 user.Avatar,
+			Created:                user.Created,
+			Email:                  user.Email,
+			Id:                     user.ID,
+			LastResetSentAt:        user.Lastresetsentat,
+			LastVerificationSentAt: user.Lastverificationsentat,
+			Name:                   user.Name,
+			Updated:                user.Updated,
+			Username:               user.Username,
+			Active:                 user.Active,
+			Type:                   user.GroupType,
+		})
+	}
+
+	s.hooks.OnRecordsListRequest.Publish(ctx, database.GroupUserTable.ID, response)
+
+	return openapi.ListGroupUsers200JSONResponse(response), nil
+}
+
+func (s *Service) ListParentPermissions(ctx context.Context, request openapi.ListParentPermissionsRequestObject) (openapi.ListParentPermissionsResponseObject, error) {
+	permissions, err := s.queries.ListParentPermissions(ctx, request.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	s.hooks.OnRecordsListRequest.Publish(ctx, database.GroupPermissionTable.ID, permissions)
+
+	return openapi.ListParentPermissions200JSONResponse(permissions), nil
+}
+
+func (s *Service) ListParentGroups(ctx context.Context, request openapi.ListParentGroupsRequestObject) (openapi.ListParentGroupsResponseObject, error) {
+	groups, err := s.queries.ListParentGroups(ctx, request.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]openapi.UserGroup, 0, len(groups))
+	for _, group := range groups {
+		response = append(response, openapi.UserGroup{
+			Created:     group.Created,
+			Id:          group.ID,
+			Name:        group.Name,
+			Permissions: auth.FromJSONArray(ctx, group.Permissions),
+			Type:        group.GroupType,
+			Updated:     group.Updated,
+		})
+	}
+
+	s.hooks.OnRecordsListRequest.Publish(ctx, database.GroupParentTable.ID, response)
+
+	return openapi.ListParentGroups200JSONResponse(response), nil
+}
+
+func (s *Service) ListChildGroups(ctx context.Context, request openapi.ListChildGroupsRequestObject) (openapi.ListChildGroupsResponseObject, error) {
+	groups, err := s.queries.ListChildGroups(ctx, request.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]openapi.UserGroup, 0, len(groups))
+	for _, group := range groups {
+		response = append(response, openapi.UserGroup{
+			Created:     group.Created,
+			Id:          group.ID,
+			Name:        group.Name,
+			Permissions: auth.FromJSONArray(ctx, group.Permissions),
+			Type:        group.GroupType,
+			Updated:     group.Updated,
+		})
+	}
+
+	s.hooks.OnRecordsListRequest.Publish(ctx, database.GroupChildTable.ID, response)
+
+	return openapi.ListChildGroups200JSONResponse(response), nil
+}
+
+func (s *Service) ListWebhooks(ctx context.Context, request openapi.ListWebhooksRequestObject) (openapi.ListWebhooksResponseObject, error) {
+	webhooks, err := s.queries.ListWebhooks(ctx, sqlc.ListWebhooksParams{
+		Offset: toInt64(request.Params.Offset, defaultOffset),
+		Limit:  toInt64(request.Params.Limit, defaultLimit),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]openapi.Webhook, 0, len(webhooks))
+	for _, webhook := range webhooks {
+		response = append(response, openapi.Webhook{
+			Id:   webhook.ID,
+			Name: webhook.Name,
+		})
+	}
+
+	s.hooks.OnRecordsListRequest.Publish(ctx, database.WebhooksTable.ID, response)
+
+	totalCount := 0
+	if len(webhooks) > 0 {
+		totalCount = int(webhooks[0].TotalCount)
+	}
+
+	return openapi.ListWebhooks200JSONResponse{
+		Body: response,
+		Headers: openapi.ListWebhooks200ResponseHeaders{
+			XTotalCount: totalCount,
+		},
+	}, nil
+}
+
+func (s *Service) CreateWebhook(ctx context.Context, request openapi.CreateWebhookRequestObject) (openapi.CreateWebhookResponseObject, error) {
+	s.hooks.OnRecordBeforeCreateRequest.Publish(ctx, database.WebhooksTable.ID, request.Body)
+
+	webhook, err := s.queries.CreateWebhook(ctx, sqlc.CreateWebhookParams{
+		Name:        request.Body.Name,
+		Destination: request.Body.Destination,
+		Collection:  request.Body.Collection,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := openapi.Webhook{
+		Id:          webhook.ID,
+		Name:        webhook.Name,
+		Created:     webhook.Created,
+		Updated:     webhook.Updated,
+		Destination: webhook.Destination,
+		Collection:  webhook.Collection,
+	}
+
+	s.hooks.OnRecordAfterCreateRequest.Publish(ctx, database.WebhooksTable.ID, response)
+
+	return openapi.CreateWebhook200JSONResponse(response), nil
+}
+
+func (s *Service) DeleteWebhook(ctx context.Context, request openapi.DeleteWebhookRequestObject) (openapi.DeleteWebhookResponseObject, error) {
+	s.hooks.OnRecordBeforeDeleteRequest.Publish(ctx, database.WebhooksTable.ID, request.Id)
+
+	err := s.queries.DeleteWebhook(ctx, request.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	s.hooks.OnRecordAfterDeleteRequest.Publish(ctx, database.WebhooksTable.ID, request.Id)
+
+	return openapi.DeleteWebhook204Response{}, nil
+}
+
+func (s *Service) GetWebhook(ctx context.Context, request openapi.GetWebhookRequestObject) (openapi.GetWebhookResponseObject, error) {
+	webhook, err := s.queries.GetWebhook(ctx, request.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	response := openapi.Webhook{
+		Id:          webhook.ID,
+		Name:        webhook.Name,
+		Created:     webhook.Created,
+		Updated:     webhook.Updated,
+		Destination: webhook.Destination,
+		Collection:  webhook.Collection,
+	}
+
+	s.hooks.OnRecordViewRequest.Publish(ctx, database.WebhooksTable.ID, response)
+
+	return openapi.GetWebhook200JSONResponse(response), nil
+}
+
+func (s *Service) UpdateWebhook(ctx context.Context, request openapi.UpdateWebhookRequestObject) (openapi.UpdateWebhookResponseObject, error) {
+	s.hooks.OnRecordBeforeUpdateRequest.Publish(ctx, database.WebhooksTable.ID, request.Body)
+
+	webhook, err := s.queries.UpdateWebhook(ctx, sqlc.UpdateWebhookParams{
+		ID:          request.Id,
+		Name:        request.Body.Name,
+		Destination: request.Body.Destination,
+		Collection:  request.Body.Collection,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := openapi.Webhook{
+		Id:          webhook.ID,
+		Name:        webhook.Name,
+		Created:     webhook.Created,
+		Updated:     webhook.Updated,
+		Destination: webhook.Destination,
+		Collection:  webhook.Collection,
+	}
+
+	s.hooks.OnRecordAfterUpdateRequest.Publish(ctx, database.WebhooksTable.ID, response)
+
+	return openapi.UpdateWebhook200JSONResponse(response), nil
+}
+
+func (s *Service) ListNotificationRules(ctx context.Context, request openapi.ListNotificationRulesRequestObject) (openapi.ListNotificationRulesResponseObject, error) {
+	rules, err := s.queries.ListNotificationRules(ctx, sqlc.ListNotificationRulesParams{
+		Offset: toInt64(request.Params.Offset, defaultOffset),
+		Limit:  toInt64(request.Params.Limit, defaultLimit),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]openapi.NotificationRule, 0, len(rules))
+	for _, rule := range rules {
+		response = append(response, mapNotificationRule(sqlc.NotificationRule{
+			ID:       rule.ID,
+			Name:     rule.Name,
+			Enabled:  rule.Enabled,
+			Filter:   rule.Filter,
+			Channel:  rule.Channel,
+			Target:   rule.Target,
+			Throttle: rule.Throttle,
+			Dedup:    rule.Dedup,
+			Created:  rule.Created,
+			Updated:  rule.Updated,
+		}))
+	}
+
+	totalCount := 0
+	if len(rules) > 0 {
+		totalCount = int(rules[0].TotalCount)
+	}
+
+	return openapi.ListNotificationRules200JSONResponse{
+		Body: response,
+		Headers: openapi.ListNotificationRules200ResponseHeaders{
+			XTotalCount: totalCount,
+		},
+	}, nil
+}
+
+func (s *Service) CreateNotificationRule(ctx context.Context, request openapi.CreateNotificationRuleRequestObject) (openapi.CreateNotificationRuleResponseObject, error) {
+	if err := validateNotificationRule(string(request.Body.Channel), request.Body.Throttle, request.Body.Dedup); err != nil {
+		return nil, err
+	}
+
+	filter, err := json.Marshal(request.Body.Filter)
+	if err != nil {
+		return nil, err
+	}
+
+	rule, err := s.queries.CreateNotificationRule(ctx, sqlc.CreateNotificationRuleParams{
+		Name:     request.Body.Name,
+		Enabled:  request.Body.Enabled,
+		Filter:   filter,
+		Channel:  string(request.Body.Channel),
+		Target:   request.Body.Target,
+		Throttle: int64(request.Body.Throttle),
+		Dedup:    int64(request.Body.Dedup),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return openapi.CreateNotificationRule200JSONResponse(mapNotificationRule(rule)), nil
+}
+
+func (s *Service) GetNotificationRule(ctx context.Context, request openapi.GetNotificationRuleRequestObject) (openapi.GetNotificationRuleResponseObject, error) {
+	rule, err := s.queries.GetNotificationRule(ctx, request.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	return openapi.GetNotificationRule200JSONResponse(mapNotificationRule(rule)), nil
+}
+
+func (s *Service) UpdateNotificationRule(ctx context.Context, request openapi.UpdateNotificationRuleRequestObject) (openapi.UpdateNotificationRuleResponseObject, error) {
+	rule, err := s.queries.GetNotificationRule(ctx, request.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	params := sqlc.UpdateNotificationRuleParams{
+		ID:       request.Id,
+		Name:     request.Body.Name,
+		Enabled:  request.Body.Enabled,
+		Target:   request.Body.Target,
+		Channel:  (*string)(request.Body.Channel),
+		Throttle: toInt64Pointer(request.Body.Throttle),
+		Dedup:    toInt64Pointer(request.Body.Dedup),
+	}
+
+	if request.Body.Filter != nil {
+		if params.Filter, err = json.Marshal(request.Body.Filter); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := validateNotificationRule(
+		pointer.Dereference(cmp.Or(params.Channel, &rule.Channel)),
+		int(pointer.Dereference(cmp.Or(params.Throttle, &rule.Throttle))),
+		int(pointer.Dereference(cmp.Or(params.Dedup, &rule.Dedup))),
+	); err != nil {
+		return nil, err
+	}
+
+	rule, err = s.queries.UpdateNotificationRule(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	return openapi.UpdateNotificationRule200JSONResponse(mapNotificationRule(rule)), nil
+}
+
+func (s *Service) DeleteNotificationRule(ctx context.Context, request openapi.DeleteNotificationRuleRequestObject) (openapi.DeleteNotificationRuleResponseObject, error) {
+	if err := s.queries.DeleteNotificationRule(ctx, request.Id); err != nil {
+		return nil, err
+	}
+
+	return openapi.DeleteNotificationRule204Response{}, nil
+}
+
+func validateNotificationRule(channel string, throttle, dedup int) error {
+	if !slices.Contains(notification.Channels(), channel) {
+		return fmt.Errorf("unknown notification channel %q", channel)
+	}
+
+	maxWindow := int(notification.MaxWindow.Seconds())
+	if throttle < 0 || throttle > maxWindow || dedup < 0 || dedup > maxWindow {
+		return fmt.Errorf("throttle and dedup must be between 0 and %d seconds", maxWindow)
+	}
+
+	return nil
+}
+
+func mapNotificationRule(rule sqlc.NotificationRule) openapi.NotificationRule {
+	var filter openapi.NotificationFilter
+	if err := json.Unmarshal(rule.Filter, &filter); err != nil {
+		slog.Error("Invalid notification rule filter", "rule", rule.ID, "error", err)
+	}
+
+	return openapi.NotificationRule{
+		Id:       rule.ID,
+		Name:     rule.Name,
+		Enabled:  rule.Enabled,
+		Filter:   filter,
+		Channel:  openapi.NotificationRuleChannel(rule.Channel),
+		Target:   rule.Target,
+		Throttle: int(rule.Throttle),
+		Dedup:    int(rule.Dedup),
+		Created:  rule.Created,
+		Updated:  rule.Updated,
+	}
+}
+
+func (s *Service) ListMaintenanceWindows(ctx context.Context, request openapi.ListMaintenanceWindowsRequestObject) (openapi.ListMaintenanceWindowsResponseObject, error) {
+	windows, err := s.queries.ListMaintenanceWindows(ctx, sqlc.ListMaintenanceWindowsParams{
+		Offset: toInt64(request.Params.Offset, defaultOffset),
+		Limit:  toInt64(request.Params.Limit, defaultLimit),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+
+	response := make([]openapi.MaintenanceWindow, 0, len(windows))
+	for _, window := range windows {
+		response = append(response, mapMaintenanceWindow(sqlc.MaintenanceWindow{
+			ID:      window.ID,
+			Name:    window.Name,
+			Filter:  window.Filter,
+			Starts:  window.Starts,
+			Ends:    window.Ends,
+			Created: window.Created,
+			Updated: window.Updated,
+		}, now))
+	}
+
+	totalCount := 0
+	if len(windows) > 0 {
+		totalCount = int(windows[0].TotalCount)
+	}
+
+	return openapi.ListMaintenanceWindows200JSONResponse{
+		Body: response,
+		Headers: openapi.ListMaintenanceWindows200ResponseHeaders{
+			XTotalCount: totalCount,
+		},
+	}, nil
+}
+
+func (s *Service) CreateMaintenanceWindow(ctx context.Context, request openapi.CreateMaintenanceWindowRequestObject) (openapi.CreateMaintenanceWindowResponseObject, error) {
+	if err := validateMaintenanceWindow(request.Body.Starts, request.Body.Ends); err != nil {
+		return nil, err
+	}
+
+	filter, err := json.Marshal(request.Body.Filter)
+	if err != nil {
+		return nil, err
+	}
+
+	window, err := s.queries.CreateMaintenanceWindow(ctx, sqlc.CreateMaintenanceWindowParams{
+		Name:   request.Body.Name,
+		Filter: filter,
+		Starts: request.Body.Starts.UTC(),
+		Ends:   request.Body.Ends.UTC(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return openapi.CreateMaintenanceWindow200JSONResponse(mapMaintenanceWindow(window, time.Now())), nil
+}
+
+func (s *Service) GetMaintenanceWindow(ctx context.Context, request openapi.GetMaintenanceWindowRequestObject) (openapi.GetMaintenanceWindowResponseObject, error) {
+	window, err := s.queries.GetMaintenanceWindow(ctx, request.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	return openapi.GetMaintenanceWindow200JSONResponse(mapMaintenanceWindow(window, time.Now())), nil
+}
+
+func (s *Service) UpdateMaintenanceWindow(ctx context.Context, request openapi.UpdateMaintenanceWindowRequestObject) (openapi.UpdateMaintenanceWindowResponseObject, error) {
+	window, err := s.queries.GetMaintenanceWindow(ctx, request.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	params := sqlc.UpdateMaintenanceWindowParams{
+		ID:   request.Id,
+		Name: request.Body.Name,
+	}
+
+	if request.Body.Starts != nil {
+		params.Starts = pointer.Pointer(request.Body.Starts.UTC())
+	}
+
+	if request.Body.Ends != nil {
+		params.Ends = pointer.Pointer(request.Body.Ends.UTC())
+	}
+
+	if request.Body.Filter != nil {
+		if params.Filter, err = json.Marshal(request.Body.Filter); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := validateMaintenanceWindow(
+		pointer.Dereference(cmp.Or(params.Starts, &window.Starts)),
+		pointer.Dereference(cmp.Or(params.Ends, &window.Ends)),
+	); err != nil {
+		return nil, err
+	}
+
+	window, err = s.queries.UpdateMaintenanceWindow(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	return openapi.UpdateMaintenanceWindow200JSONResponse(mapMaintenanceWindow(window, time.Now())), nil
+}
+
+func (s *Service) DeleteMaintenanceWindow(ctx context.Context, request openapi.DeleteMaintenanceWindowRequestObject) (openapi.DeleteMaintenanceWindowResponseObject, error) {
+	if err := s.queries.DeleteMaintenanceWindow(ctx, request.Id); err != nil {
+		return nil, err
+	}
+
+	return openapi.DeleteMaintenanceWindow204Response{}, nil
+}
+
+func validateMaintenanceWindow(starts, ends time.Time) error {
+	if !ends.After(starts) {
+		return errors.New("a maintenance window must end after it starts")
+	}
+
+	return nil
+}
+
+func mapMaintenanceWindow(window sqlc.MaintenanceWindow, now time.Time) openapi.MaintenanceWindow {
+	var filter openapi.MaintenanceFilter
+	if err := json.Unmarshal(window.Filter, &filter); err != nil {
+		slog.Error("Invalid maintenance window filter", "window", window.ID, "error", err)
+	}
+
+	return openapi.MaintenanceWindow{
+		Id:      window.ID,
+		Name:    window.Name,
+		Filter:  filter,
+		Starts:  window.Starts,
+		Ends:    window.Ends,
+		Active:  !now.Before(window.Starts) && now.Before(window.Ends),
+		Created: window.Created,
+		Updated: window.Updated,
+	}
+}
+
+func (s *Service) ListRedactionProfiles(ctx context.Context, request openapi.ListRedactionProfilesRequestObject) (openapi.ListRedactionProfilesResponseObject, error) {
+	profiles, err := s.queries.ListRedactionProfiles(ctx, sqlc.ListRedactionProfilesParams{
+		Offset: toInt64(request.Params.Offset, defaultOffset),
+		Limit:  toInt64(request.Params.Limit, defaultLimit),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]openapi.RedactionProfile, 0, len(profiles))
+	for _, profile := range profiles {
+		response = append(response, mapRedactionProfile(sqlc.RedactionProfile{
+			ID:             profile.ID,
+			Name:           profile.Name,
+			Audience:       profile.Audience,
+			Fields:         profile.Fields,
+			HideFiles:      profile.HideFiles,
+			HideIdentities: profile.HideIdentities,
+			Created:        profile.Created,
+			Updated:        profile.Updated,
+		}))
+	}
+
+	totalCount := 0
+	if len(profiles) > 0 {
+		totalCount = int(profiles[0].TotalCount)
+	}
+
+	return openapi.ListRedactionProfiles200JSONResponse{
+		Body: response,
+		Headers: openapi.ListRedactionProfiles200ResponseHeaders{
+			XTotalCount: totalCount,
+		},
+	}, nil
+}
+
+func (s *Service) CreateRedactionProfile(ctx context.Context, request openapi.CreateRedactionProfileRequestObject) (openapi.CreateRedactionProfileResponseObject, error) {
+	if !slices.Contains(redact.Audiences, request.Body.Audience) {
+		return openapi.CreateRedactionProfile400JSONResponse{
+			Status:  http.StatusBadRequest,
+			Error:   "Bad Request",
+			Message: unknownAudienceMessage(request.Body.Audience),
+		}, nil
+	}
+
+	fields := []string{}
+	if request.Body.Fields != nil {
+		fields = *request.Body.Fields
+	}
+
+	fieldsJSON, err := json.Marshal(fields)
+	if err != nil {
+		return nil, err
+	}
+
+	profile, err := s.queries.CreateRedactionProfile(ctx, sqlc.CreateRedactionProfileParams{
+		Name:           request.Body.Name,
+		Audience:       request.Body.Audience,
+		Fields:         fieldsJSON,
+		HideFiles:      pointer.Dereference(request.Body.HideFiles),
+		HideIdentities: pointer.Dereference(request.Body.HideIdentities),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return openapi.CreateRedactionProfile200JSONResponse(mapRedactionProfile(profile)), nil
+}
+
+func (s *Service) GetRedactionProfile(ctx context.Context, request openapi.GetRedactionProfileRequestObject) (openapi.GetRedactionProfileResponseObject, error) {
+	profile, err := s.queries.GetRedactionProfile(ctx, request.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	return openapi.GetRedactionProfile200JSONResponse(mapRedactionProfile(profile)), nil
+}
+
+func (s *Service) UpdateRedactionProfile(ctx context.Context, request openapi.UpdateRedactionProfileRequestObject) (openapi.UpdateRedactionProfileResponseObject, error) {
+	if request.Body.Audience != nil && !slices.Contains(redact.Audiences, *request.Body.Audience) {
+		return openapi.UpdateRedactionProfile400JSONResponse{
+			Status:  http.StatusBadRequest,
+			Error:   "Bad Request",
+			Message: unknownAudienceMessage(*request.Body.Audience),
+		}, nil
+	}
+
+	params := sqlc.UpdateRedactionProfileParams{
+		ID:             request.Id,
+		Name:           request.Body.Name,
+		Audience:       request.Body.Audience,
+		HideFiles:      request.Body.HideFiles,
+		HideIdentities: request.Body.HideIdentities,
+	}
+
+	if request.Body.Fields != nil {
+		var err error
+		if params.Fields, err = json.Marshal(request.Body.Fields); err != nil {
+			return nil, err
+		}
+	}
+
+	profile, err := s.queries.UpdateRedactionProfile(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	return openapi.UpdateRedactionProfile200JSONResponse(mapRedactionProfile(profile)), nil
+}
+
+func (s *Service) DeleteRedactionProfile(ctx context.Context, request openapi.DeleteRedactionProfileRequestObject) (openapi.DeleteRedactionProfileResponseObject, error) {
+	if err := s.queries.DeleteRedactionProfile(ctx, request.Id); err != nil {
+		return nil, err
+	}
+
+	return openapi.DeleteRedactionProfile204Response{}, nil
+}
+
+func unknownAudienceMessage(audience string) string {
+	return fmt.Sprintf("unknown audience %q, expected one of %s", audience, strings.Join(redact.Audiences, ", "))
+}
+
+func mapRedactionProfile(profile sqlc.RedactionProfile) openapi.RedactionProfile {
+	fields := []string{}
+	if err := json.Unmarshal(profile.Fields, &fields); err != nil {
+		slog.Error("Invalid redaction profile fields", "profile", profile.ID, "error", err)
+	}
+
+	return openapi.RedactionProfile{
+		Id:             profile.ID,
+		Name:           profile.Name,
+		Audience:       profile.Audience,
+		Fields:         fields,
+		HideFiles:      profile.HideFiles,
+		HideIdentities: profile.HideIdentities,
+		Created:        profile.Created,
+		Updated:        profile.Updated,
+	}
+}
+
+func (s *Service) ListEscalationPolicies(ctx context.Context, request openapi.ListEscalationPoliciesRequestObject) (openapi.ListEscalationPoliciesResponseObject, error) {
+	policies, err := s.queries.ListEscalationPolicies(ctx, sqlc.ListEscalationPoliciesParams{
+		Offset: toInt64(request.Params.Offset, defaultOffset),
+		Limit:  toInt64(request.Params.Limit, defaultLimit),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]openapi.EscalationPolicy, 0, len(policies))
+	for _, policy := range policies {
+		response = append(response, mapEscalationPolicy(sqlc.EscalationPolicy{
+			ID:      policy.ID,
+			Name:    policy.Name,
+			Filter:  policy.Filter,
+			Steps:   policy.Steps,
+			Repeat:  policy.Repeat,
+			Created: policy.Created,
+			Updated: policy.Updated,
+		}))
+	}
+
+	totalCount := 0
+	if len(policies) > 0 {
+		totalCount = int(policies[0].TotalCount)
+	}
+
+	return openapi.ListEscalationPolicies200JSONResponse{
+		Body: response,
+		Headers: openapi.ListEscalationPolicies200ResponseHeaders{
+			XTotalCount: totalCount,
+		},
+	}, nil
+}
+
+func (s *Service) CreateEscalationPolicy(ctx context.Context, request openapi.CreateEscalationPolicyRequestObject) (openapi.CreateEscalationPolicyResponseObject, error) {
+	if err := validateEscalationSteps(request.Body.Steps); err != nil {
+		return nil, err
+	}
+
+	filter, err := json.Marshal(request.Body.Filter)
+	if err != nil {
+		return nil, err
+	}
+
+	steps, err := json.Marshal(request.Body.Steps)
+	if err != nil {
+		return nil, err
+	}
+
+	pauseStates, err := json.Marshal(pointer.Dereference(request.Body.PauseStates))
+	if err != nil {
+		return nil, err
+	}
+
+	policy, err := s.queries.CreateEscalationPolicy(ctx, sqlc.CreateEscalationPolicyParams{
+		Name:        request.Body.Name,
+		Filter:      filter,
+		Steps:       steps,
+		Repeat:      request.Body.Repeat,
+		PauseStates: pauseStates,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return openapi.CreateEscalationPolicy200JSONResponse(mapEscalationPolicy(policy)), nil
+}
+
+func (s *Service) GetEscalationPolicy(ctx context.Context, request openapi.GetEscalationPolicyRequestObject) (openapi.GetEscalationPolicyResponseObject, error) {
+	policy, err := s.queries.GetEscalationPolicy(ctx, request.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	return openapi.GetEscalationPolicy200JSONResponse(mapEscalationPolicy(policy)), nil
+}
+
+func (s *Service) DeleteEscalationPolicy(ctx context.Context, request openapi.DeleteEscalationPolicyRequestObject) (openapi.DeleteEscalationPolicyResponseObject, error) {
+	if err := s.queries.DeleteEscalationPolicy(ctx, request.Id); err != nil {
+		return nil, err
+	}
+
+	return openapi.DeleteEscalationPolicy204Response{}, nil
+}
+
+func validateEscalationSteps(steps []openapi.EscalationStep) error {
+	if len(steps) == 0 {
+		return errors.New("an escalation policy needs at least one step")
+	}
+
+	for i, step := range steps {
+		if step.Delay < 1 {
+			return fmt.Errorf("the delay of step %d must be at least one minute", i+1)
+		}
+
+		if len(pointer.Dereference(step.Users)) == 0 && len(pointer.Dereference(step.Groups)) == 0 {
+			return fmt.Errorf("step %d has no users or groups to notify", i+1)
+		}
+	}
+
+	return nil
+}
+
+func mapEscalationPolicy(policy sqlc.EscalationPolicy) openapi.EscalationPolicy {
+	var filter openapi.EscalationFilter
+	if err := json.Unmarshal(policy.Filter, &filter); err != nil {
+		slog.Error("Invalid escalation policy filter", "policy", policy.ID, "error", err)
+	}
+
+	steps := []openapi.EscalationStep{}
+	if err := json.Unmarshal(policy.Steps, &steps); err != nil {
+		slog.Error("Invalid escalation policy steps", "policy", policy.ID, "error", err)
+	}
+
+	pauseStates := []string{}
+	if err := json.Unmarshal(policy.PauseStates, &pauseStates); err != nil {
+		slog.Error("Invalid escalation policy pause states", "policy", policy.ID, "error", err)
+	}
+
+	return openapi.EscalationPolicy{
+		Id:          policy.ID,
+		Name:        policy.Name,
+		Filter:      filter,
+		Steps:       steps,
+		Repeat:      policy.Repeat,
+		PauseStates: pauseStates,
+		Created:     policy.Created,
+		Updated:     policy.Updated,
+	}
+}
+
+func (s *Service) ListAutoCloseRules(ctx context.Context, request openapi.ListAutoCloseRulesRequestObject) (openapi.ListAutoCloseRulesResponseObject, error) {
+	rules, err := s.queries.ListAutoCloseRules(ctx, sqlc.ListAutoCloseRulesParams{
+		Offset: toInt64(request.Params.Offset, defaultOffset),
+		Limit:  toInt64(request.Params.Limit, defaultLimit),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]openapi.AutoCloseRule, 0, len(rules))
+	for _, rule := range rules {
+		response = append(response, mapAutoCloseRule(sqlc.AutoCloseRule{
+			ID:           rule.ID,
+			Name:         rule.Name,
+			Enabled:      rule.Enabled,
+			Filter:       rule.Filter,
+			InactiveDays: rule.InactiveDays,
+			WarningDays:  rule.WarningDays,
+			Resolution:   rule.Resolution,
+			Created:      rule.Created,
+			Updated:      rule.Updated,
+		}))
+	}
+
+	totalCount := 0
+	if len(rules) > 0 {
+		totalCount = int(rules[0].TotalCount)
+	}
+
+	return openapi.ListAutoCloseRules200JSONResponse{
+		Body: response,
+		Headers: openapi.ListAutoCloseRules200ResponseHeaders{
+			XTotalCount: totalCount,
+		},
+	}, nil
+}
+
+func (s *Service) CreateAutoCloseRule(ctx context.Context, request openapi.CreateAutoCloseRuleRequestObject) (openapi.CreateAutoCloseRuleResponseObject, error) {
+	if err := autoclose.Validate(request.Body.Name, request.Body.Resolution, request.Body.InactiveDays, request.Body.WarningDays); err != nil {
+		return nil, err
+	}
+
+	filter, err := json.Marshal(request.Body.Filter)
+	if err != nil {
+		return nil, err
+	}
+
+	rule, err := s.queries.CreateAutoCloseRule(ctx, sqlc.CreateAutoCloseRuleParams{
+		Name:         request.Body.Name,
+		Enabled:      request.Body.Enabled,
+		Filter:       filter,
+		InactiveDays: int64(request.Body.InactiveDays),
+		WarningDays:  int64(request.Body.WarningDays),
+		Resolution:   request.Body.Resolution,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return openapi.CreateAutoCloseRule200JSONResponse(mapAutoCloseRule(rule)), nil
+}
+
+func (s *Service) GetAutoCloseRule(ctx context.Context, request openapi.GetAutoCloseRuleRequestObject) (openapi.GetAutoCloseRuleResponseObject, error) {
+	rule, err := s.queries.GetAutoCloseRule(ctx, request.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	return openapi.GetAutoCloseRule200JSONResponse(mapAutoCloseRule(rule)), nil
+}
+
+func (s *Service) UpdateAutoCloseRule(ctx context.Context, request openapi.UpdateAutoCloseRuleRequestObject) (openapi.UpdateAutoCloseRuleResponseObject, error) {
+	rule, err := s.queries.GetAutoCloseRule(ctx, request.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	params := sqlc.UpdateAutoCloseRuleParams{
+		ID:           request.Id,
+		Name:         request.Body.Name,
+		Enabled:      request.Body.Enabled,
+		InactiveDays: toInt64Pointer(request.Body.InactiveDays),
+		WarningDays:  toInt64Pointer(request.Body.WarningDays),
+		Resolution:   request.Body.Resolution,
+	}
+
+	if request.Body.Filter != nil {
+		if params.Filter, err = json.Marshal(request.Body.Filter); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := autoclose.Validate(
+		pointer.Dereference(cmp.Or(params.Name, &rule.Name)),
+		pointer.Dereference(cmp.Or(params.Resolution, &rule.Resolution)),
+		int(pointer.Dereference(cmp.Or(params.InactiveDays, &rule.InactiveDays))),
+		int(pointer.Dereference(cmp.Or(params.WarningDays, &rule.WarningDays))),
+	); err != nil {
+		return nil, err
+	}
+
+	rule, err = s.queries.UpdateAutoCloseRule(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	return openapi.UpdateAutoCloseRule200JSONResponse(mapAutoCloseRule(rule)), nil
+}
+
+func (s *Service) DeleteAutoCloseRule(ctx context.Context, request openapi.DeleteAutoCloseRuleRequestObject) (openapi.DeleteAutoCloseRuleResponseObject, error) {
+	if err := s.queries.DeleteAutoCloseRule(ctx, request.Id); err != nil {
+		return nil, err
+	}
+
+	return openapi.DeleteAutoCloseRule204Response{}, nil
+}
+
+func mapAutoCloseRule(rule sqlc.AutoCloseRule) openapi.AutoCloseRule {
+	var filter openapi.AutoCloseFilter
+	if err := json.Unmarshal(rule.Filter, &filter); err != nil {
+		slog.Error("Invalid automatic closing rule filter", "rule", rule.ID, "error", err)
+	}
+
+	return openapi.AutoCloseRule{
+		Id:           rule.ID,
+		Name:         rule.Name,
+		Enabled:      rule.Enabled,
+		Filter:       filter,
+		InactiveDays: int(rule.InactiveDays),
+		WarningDays:  int(rule.WarningDays),
+		Resolution:   rule.Resolution,
+		Created:      rule.Created,
+		Updated:      rule.Updated,
+	}
+}
+
+var errNotEscalated = openapi.Error{
+	Status:  http.StatusNotFound,
+	Error:   "Not Found",
+	Message: "The ticket is not escalated",
+}
+
+func (s *Service) ListLegalHolds(ctx context.Context, request openapi.ListLegalHoldsRequestObject) (openapi.ListLegalHoldsResponseObject, error) {
+	holds, err := s.queries.ListLegalHolds(ctx, sqlc.ListLegalHoldsParams{
+		Offset: toInt64(request.Params.Offset, defaultOffset),
+		Limit:  toInt64(request.Params.Limit, defaultLimit),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]openapi.LegalHold, 0, len(holds))
+	for _, hold := range holds {
+		response = append(response, openapi.LegalHold{
+			Id:            hold.ID,
+			Collection:    openapi.LegalHoldCollection(hold.Collection),
+			Name:          hold.Name,
+			Ticket:        hold.Ticket,
+			Reason:        hold.Reason,
+			CreatedBy:     hold.CreatedBy,
+			CreatedByName: hold.CreatedByName,
+			Created:       hold.Created,
+		})
+	}
+
+	totalCount := 0
+	if len(holds) > 0 {
+		totalCount = int(holds[0].TotalCount)
+	}
+
+	return openapi.ListLegalHolds200JSONResponse{
+		Body: response,
+		Headers: openapi.ListLegalHolds200ResponseHeaders{
+			XTotalCount: totalCount,
+		},
+	}, nil
+}
+
+func (s *Service) SetTicketLegalHold(ctx context.Context, request openapi.SetTicketLegalHoldRequestObject) (openapi.SetTicketLegalHoldResponseObject, error) {
+	ticket, err := s.queries.Ticket(ctx, request.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	hold, err := s.createLegalHold(ctx, database.TicketsTable.ID, ticket.ID, request.Body.Reason)
+	if err != nil {
+		return nil, err
+	}
+
+	hold.Name, hold.Ticket = ticket.Name, ticket.ID
+
+	return openapi.SetTicketLegalHold200JSONResponse(hold), nil
+}
+
+func (s *Service) DeleteTicketLegalHold(ctx context.Context, request openapi.DeleteTicketLegalHoldRequestObject) (openapi.DeleteTicketLegalHoldResponseObject, error) {
+	if err := s.queries.DeleteLegalHold(ctx, request.Id); err != nil {
+		return nil, err
+	}
+
+	return openapi.DeleteTicketLegalHold204Response{}, nil
+}
+
+func (s *Service) SetFileLegalHold(ctx context.Context, request openapi.SetFileLegalHoldRequestObject) (openapi.SetFileLegalHoldResponseObject, error) {
+	file, err := s.queries.GetFile(ctx, request.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	hold, err := s.createLegalHold(ctx, database.FilesTable.ID, file.ID, request.Body.Reason)
+	if err != nil {
+		return nil, err
+	}
+
+	hold.Name, hold.Ticket = file.Name, file.Ticket
+
+	return openapi.SetFileLegalHold200JSONResponse(hold), nil
+}
+
+func (s *Service) DeleteFileLegalHold(ctx context.Context, request openapi.DeleteFileLegalHoldRequestObject) (openapi.DeleteFileLegalHoldResponseObject, error) {
+	if err := s.queries.DeleteLegalHold(ctx, request.Id); err != nil {
+		return nil, err
+	}
+
+	return openapi.DeleteFileLegalHold204Response{}, nil
+}
+
+func (s *Service) createLegalHold(ctx context.Context, collection, id, reason string) (openapi.LegalHold, error) {
+	if reason == "" {
+		return openapi.LegalHold{}, errors.New("a legal hold requires a reason")
+	}
+
+	var createdBy *string
+	if user, ok := usercontext.UserFromContext(ctx); ok {
+		createdBy = &user.ID
+	}
+
+	hold, err := s.queries.CreateLegalHold(ctx, sqlc.CreateLegalHoldParams{
+		ID:         id,
+		Collection: collection,
+		Reason:     reason,
+		CreatedBy:  createdBy,
+	})
+	if err != nil {
+		return openapi.LegalHold{}, err
+	}
+
+	return openapi.LegalHold{
+		Id:         hold.ID,
+		Collection: openapi.LegalHoldCollection(hold.Collection),
+		Reason:     hold.Reason,
+		CreatedBy:  hold.CreatedBy,
+		Created:    hold.Created,
+	}, nil
+}
+
+func legalHoldError(message string, hold *sqlc.LegalHold) openapi.Error {
+	return openapi.Error{
+		Status:  http.StatusForbidden,
+		Error:   "Forbidden",
+		Message: message + ": " + hold.Reason,
+	}
+}
+
+func (s *Service) GetTicketEscalation(ctx context.Context, request openapi.GetTicketEscalationRequestObject) (openapi.GetTicketEscalationResponseObject, error) {
+	escalation, err := s.queries.GetTicketEscalation(ctx, request.Id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return openapi.GetTicketEscalation404JSONResponse(errNotEscalated), nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return openapi.GetTicketEscalation200JSONResponse(mapTicketEscalation(escalation)), nil
+}
+
+func (s *Service) AcknowledgeTicket(ctx context.Context, request openapi.AcknowledgeTicketRequestObject) (openapi.AcknowledgeTicketResponseObject, error) {
+	user, ok := usercontext.UserFromContext(ctx)
+	if !ok {
+		return nil, errors.New("missing user")
+	}
+
+	ticket, err := s.queries.AcknowledgeTicket(ctx, sqlc.AcknowledgeTicketParams{
+		ID:             request.Id,
+		AcknowledgedBy: &user.ID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.queries.AcknowledgeTicketEscalation(ctx, sqlc.AcknowledgeTicketEscalationParams{
+		Ticket:         request.Id,
+		AcknowledgedBy: &user.ID,
+	}); err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	response, err := s.mapTicket(ctx, &ticket)
+	if err != nil {
+		return nil, err
+	}
+
+	s.hooks.OnRecordAfterUpdateRequest.Publish(ctx, database.TicketsTable.ID, redactTicket(response))
+
+	return openapi.AcknowledgeTicket200JSONResponse(response), nil
+}
+
+var (
+	errTicketOpen = openapi.Error{
+		Status:  http.StatusBadRequest,
+		Error:   "Bad Request",
+		Message: "The ticket is not closed",
+	}
+	errMissingReopenReason = openapi.Error{
+		Status:  http.StatusBadRequest,
+		Error:   "Bad Request",
+		Message: "A reason is required to reopen a ticket",
+	}
+)
+
+// ReopenTicket opens a closed ticket again. The reason and the user who
+// closed the ticket are kept, the selected playbook tasks are opened again.
+func (s *Service) ReopenTicket(ctx context.Context, request openapi.ReopenTicketRequestObject) (openapi.ReopenTicketResponseObject, error) {
+	user, ok := usercontext.UserFromContext(ctx)
+	if !ok {
+		return nil, errors.New("missing user")
+	}
+
+	reason := strings.TrimSpace(request.Body.Reason)
+	if reason == "" {
+		return openapi.ReopenTicket400JSONResponse(errMissingReopenReason), nil
+	}
+
+	previous, err := s.previousTicket(ctx, request.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	if previous.Open {
+		return openapi.ReopenTicket400JSONResponse(errTicketOpen), nil
+	}
+
+	tasks := pointer.Dereference(request.Body.Tasks)
+
+	for _, task := range tasks {
+		isPlaybookTask, err := s.queries.IsPlaybookTask(ctx, sqlc.IsPlaybookTaskParams{Ticket: previous.Id, Task: task})
+		if err != nil {
+			return nil, err
+		}
+
+		if isPlaybookTask == 0 {
+			return openapi.ReopenTicket400JSONResponse{
+				Status:  http.StatusBadRequest,
+				Error:   "Bad Request",
+				Message: fmt.Sprintf("The task %s is not a playbook task of the ticket", task),
+			}, nil
+		}
+	}
+
+	ticket, err := s.queries.ReopenTicket(ctx, previous.Id)
+	if errors.Is(err, sql.ErrNoRows) {
+		// reopened concurrently
+		return openapi.ReopenTicket400JSONResponse(errTicketOpen), nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	reopen, err := s.queries.CreateTicketReopen(ctx, sqlc.CreateTicketReopenParams{
+		Ticket:     ticket.ID,
+		Reason:     reason,
+		Resolution: previous.Resolution,
+		ClosedBy:   previous.ResolvedBy,
+		ReopenedBy: &user.ID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := s.mapTicket(ctx, &ticket)
+	if err != nil {
+		return nil, err
+	}
+
+	s.hooks.OnRecordAfterUpdateRequest.Publish(hook.WithPrevious(ctx, previous), database.TicketsTable.ID, redactTicket(response))
+
+	for _, task := range tasks {
+		if _, err := s.UpdateTask(ctx, openapi.UpdateTaskRequestObject{
+			Id:   task,
+			Body: &openapi.TaskUpdate{Open: pointer.Pointer(true)},
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	s.hooks.OnRecordAfterCreateRequest.Publish(ctx, database.TicketReopensTable.ID, mapTicketReopen(sqlc.ListTicketReopensRow{
+		ID:             reopen.ID,
+		Ticket:         reopen.Ticket,
+		Reason:         reopen.Reason,
+		Resolution:     reopen.Resolution,
+		ClosedBy:       reopen.ClosedBy,
+		ReopenedBy:     reopen.ReopenedBy,
+		Created:        reopen.Created,
+		ReopenedByName: user.Name,
+	}))
+
+	return openapi.ReopenTicket200JSONResponse(response), nil
+}
+
+func (s *Service) ListTicketReopens(ctx context.Context, request openapi.ListTicketReopensRequestObject) (openapi.ListTicketReopensResponseObject, error) {
+	reopens, err := s.queries.ListTicketReopens(ctx, request.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]openapi.TicketReopen, 0, len(reopens))
+	for _, reopen := range reopens {
+		response = append(response, mapTicketReopen(reopen))
+	}
+
+	return openapi.ListTicketReopens200JSONResponse(response), nil
+}
+
+func mapTicketReopen(reopen sqlc.ListTicketReopensRow) openapi.TicketReopen {
+	return openapi.TicketReopen{
+		Id:             reopen.ID,
+		Ticket:         reopen.Ticket,
+		Reason:         reopen.Reason,
+		Resolution:     reopen.Resolution,
+		ClosedBy:       reopen.ClosedBy,
+		ClosedByName:   reopen.ClosedByName,
+		ReopenedBy:     reopen.ReopenedBy,
+		ReopenedByName: reopen.ReopenedByName,
+		Created:        reopen.Created,
+	}
+}
+
+var (
+	errWorkTimerRunning = openapi.Error{
+		Status:  http.StatusBadRequest,
+		Error:   "Bad Request",
+		Message: "A work timer is already running on the ticket",
+	}
+	errNoWorkTimer = openapi.Error{
+		Status:  http.StatusBadRequest,
+		Error:   "Bad Request",
+		Message: "No work timer is running on the ticket",
+	}
+	errInvalidDuration = openapi.Error{
+		Status:  http.StatusBadRequest,
+		Error:   "Bad Request",
+		Message: "The duration must be positive",
+	}
+)
+
+func (s *Service) ListWorkEntries(ctx context.Context, request openapi.ListWorkEntriesRequestObject) (openapi.ListWorkEntriesResponseObject, error) {
+	entries, err := s.queries.ListWorkEntries(ctx, request.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]openapi.WorkEntry, 0, len(entries))
+	for _, entry := range entries {
+		response = append(response, mapWorkEntry(sqlc.WorkEntry{
+			ID:          entry.ID,
+			Ticket:      entry.Ticket,
+			User:        entry.User,
+			Description: entry.Description,
+			Started:     entry.Started,
+			Duration:    entry.Duration,
+			Manual:      entry.Manual,
+			Created:     entry.Created,
+			Updated:     entry.Updated,
+		}, entry.UserName))
+	}
+
+	return openapi.ListWorkEntries200JSONResponse(response), nil
+}
+
+func (s *Service) CreateWorkEntry(ctx context.Context, request openapi.CreateWorkEntryRequestObject) (openapi.CreateWorkEntryResponseObject, error) {
+	user, ok := usercontext.UserFromContext(ctx)
+	if !ok {
+		return nil, errors.New("no user in context")
+	}
+
+	if request.Body.Duration <= 0 {
+		return openapi.CreateWorkEntry400JSONResponse(errInvalidDuration), nil
+	}
+
+	started := time.Now()
+	if request.Body.Started != nil {
+		started = *request.Body.Started
+	}
+
+	entry, err := s.queries.CreateWorkEntry(ctx, sqlc.CreateWorkEntryParams{
+		Ticket:      request.Id,
+		User:        user.ID,
+		Description: pointer.Dereference(request.Body.Description),
+		Started:     started.UTC().Truncate(time.Second),
+		Duration:    pointer.Pointer(int64(request.Body.Duration)),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return openapi.CreateWorkEntry200JSONResponse(mapWorkEntry(entry, user.Name)), nil
+}
+
+// StartWorkTimer starts a timer of the user on the ticket. The time is
+// recorded when the timer is stopped.
+func (s *Service) StartWorkTimer(ctx context.Context, request openapi.StartWorkTimerRequestObject) (openapi.StartWorkTimerResponseObject, error) {
+	user, ok := usercontext.UserFromContext(ctx)
+	if !ok {
+		return nil, errors.New("no user in context")
+	}
+
+	var description string
+	if request.Body != nil {
+		description = pointer.Dereference(request.Body.Description)
+	}
+
+	entry, err := s.queries.StartWorkTimer(ctx, sqlc.StartWorkTimerParams{
+		Ticket:      request.Id,
+		User:        user.ID,
+		Description: description,
+		Started:     time.Now().UTC().Truncate(time.Second),
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return openapi.StartWorkTimer400JSONResponse(errWorkTimerRunning), nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return openapi.StartWorkTimer200JSONResponse(mapWorkEntry(entry, user.Name)), nil
+}
+
+func (s *Service) StopWorkTimer(ctx context.Context, request openapi.StopWorkTimerRequestObject) (openapi.StopWorkTimerResponseObject, error) {
+	user, ok := usercontext.UserFromContext(ctx)
+	if !ok {
+		return nil, errors.New("no user in context")
+	}
+
+	entry, err := s.queries.StopWorkTimer(ctx, sqlc.StopWorkTimerParams{
+		Ticket:  request.Id,
+		User:    user.ID,
+		Stopped: time.Now().UTC().Format(time.DateTime),
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return openapi.StopWorkTimer400JSONResponse(errNoWorkTimer), nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return openapi.StopWorkTimer200JSONResponse(mapWorkEntry(entry, user.Name)), nil
+}
+
+func (s *Service) DeleteWorkEntry(ctx context.Context, request openapi.DeleteWorkEntryRequestObject) (openapi.DeleteWorkEntryResponseObject, error) {
+	if err := s.queries.DeleteWorkEntry(ctx, request.Id); err != nil {
+		return nil, err
+	}
+
+	return openapi.DeleteWorkEntry204Response{}, nil
+}
+
+func mapWorkEntry(entry sqlc.WorkEntry, userName *string) openapi.WorkEntry {
+	var duration *int
+	if entry.Duration != nil {
+		duration = pointer.Pointer(int(*entry.Duration))
+	}
+
+	return openapi.WorkEntry{
+		Id:          entry.ID,
+		Ticket:      entry.Ticket,
+		User:        entry.User,
+		UserName:    userName,
+		Description: entry.Description,
+		Started:     entry.Started,
+		Duration:    duration,
+		Manual:      entry.Manual,
+		Created:     entry.Created,
+		Updated:     entry.Updated,
+	}
+}
+
+func mapTicketEscalation(escalation sqlc.TicketEscalation) openapi.TicketEscalation {
+	return openapi.TicketEscalation{
+		Ticket:         escalation.Ticket,
+		Policy:         escalation.Policy,
+		Step:           int(escalation.Step),
+		NextAt:         escalation.NextAt,
+		AcknowledgedBy: escalation.AcknowledgedBy,
+		AcknowledgedAt: escalation.AcknowledgedAt,
+		PausedSince:    escalation.PausedSince,
+		Paused:         int(escalation.Paused),
+		Created:        escalation.Created,
+		Updated:        escalation.Updated,
+	}
+}
+
+func (s *Service) ListDeadLetters(ctx context.Context, request openapi.ListDeadLettersRequestObject) (openapi.ListDeadLettersResponseObject, error) {
+	deadLetters, err := s.queries.ListDeadLetters(ctx, sqlc.ListDeadLettersParams{
+		Offset: toInt64(request.Params.Offset, defaultOffset),
+		Limit:  toInt64(request.Params.Limit, defaultLimit),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]openapi.DeadLetter, 0, len(deadLetters))
+	for _, deadLetter := range deadLetters {
+		response = append(response, openapi.DeadLetter{
+			Attempts:    int(deadLetter.Attempts),
+			Created:     deadLetter.Created,
+			Destination: deadLetter.Destination,
+			Error:       deadLetter.Error,
+			Id:          deadLetter.ID,
+			Payload:     unmarshal(deadLetter.Payload),
+			Updated:     deadLetter.Updated,
+			Webhook:     deadLetter.Webhook,
+		})
+	}
+
+	totalCount := 0
+	if len(deadLetters) > 0 {
+		totalCount = int(deadLetters[0].TotalCount)
+	}
+
+	return openapi.ListDeadLetters200JSONResponse{
+		Body: response,
+		Headers: openapi.ListDeadLetters200ResponseHeaders{
+			XTotalCount: totalCount,
+		},
+	}, nil
+}
+
+func (s *Service) GetDeadLetter(ctx context.Context, request openapi.GetDeadLetterRequestObject) (openapi.GetDeadLetterResponseObject, error) {
+	deadLetter, err := s.queries.GetDeadLetter(ctx, request.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	return openapi.GetDeadLetter200JSONResponse{
+		Attempts:    int(deadLetter.Attempts),
+		Created:     deadLetter.Created,
+		Destination: deadLetter.Destination,
+		Error:       deadLetter.Error,
+		Id:          deadLetter.ID,
+		Payload:     unmarshal(deadLetter.Payload),
+		Updated:     deadLetter.Updated,
+		Webhook:     deadLetter.Webhook,
+	}, nil
+}
+
+func (s *Service) DeleteDeadLetter(ctx context.Context, request openapi.DeleteDeadLetterRequestObject) (openapi.DeleteDeadLetterResponseObject, error) {
+	if err := s.queries.DeleteDeadLetter(ctx, request.Id); err != nil {
+		return nil, err
+	}
+
+	return openapi.DeleteDeadLetter204Response{}, nil
+}
+
+func (s *Service) ReplayDeadLetter(ctx context.Context, request openapi.ReplayDeadLetterRequestObject) (openapi.ReplayDeadLetterResponseObject, error) {
+	delivered, err := webhook.Replay(ctx, s.queries, request.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	return openapi.ReplayDeadLetter200JSONResponse{Delivered: delivered}, nil
+}
+
+func (s *Service) ListPlugins(_ context.Context, _ openapi.ListPluginsRequestObject) (openapi.ListPluginsResponseObject, error) {
+	plugins := s.plugins.List()
+
+	response := make([]openapi.Plugin, 0, len(plugins))
+	for _, p := range plugins {
+		response = append(response, mapPlugin(p))
+	}
+
+	return openapi.ListPlugins200JSONResponse(response), nil
+}
+
+func (s *Service) InstallPlugin(ctx context.Context, request openapi.InstallPluginRequestObject) (openapi.InstallPluginResponseObject, error) {
+	p, err := s.plugins.Install(ctx, request.Body.Name, request.Body.Wasm, request.Body.Signature)
+	if err != nil {
+		return nil, err
+	}
+
+	return openapi.InstallPlugin200JSONResponse(mapPlugin(p)), nil
+}
+
+func (s *Service) DeletePlugin(ctx context.Context, request openapi.DeletePluginRequestObject) (openapi.DeletePluginResponseObject, error) {
+	if err := s.plugins.Remove(ctx, request.Name); err != nil {
+		return nil, err
+	}
+
+	return openapi.DeletePlugin204Response{}, nil
+}
+
+var errBundleBlocked = openapi.Error{
+	Status:  http.StatusBadRequest,
+	Error:   "Bad Request",
+	Message: "The bundle violates a blocking policy, see the findings report",
+}
+
+func (s *Service) ScanBundle(_ context.Context, request openapi.ScanBundleRequestObject) (openapi.ScanBundleResponseObject, error) {
+	report, err := bundleReport(*request.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	return openapi.ScanBundle200JSONResponse(report), nil
+}
+
+// ImportBundle creates the playbooks and reactions of a bundle. The import
+// must be confirmed with the digest of the findings report, which is
+// returned again if the confirmation is missing or the bundle changed.
+func (s *Service) ImportBundle(ctx context.Context, request openapi.ImportBundleRequestObject) (openapi.ImportBundleResponseObject, error) {
+	report, err := bundleReport(request.Body.Bundle)
+	if err != nil {
+		return nil, err
+	}
+
+	if report.Blocked {
+		return openapi.ImportBundle400JSONResponse(errBundleBlocked), nil
+	}
+
+	if pointer.Dereference(request.Body.Confirm) != report.Digest {
+		return openapi.ImportBundle409JSONResponse(report), nil
+	}
+
+	for _, p := range request.Body.Bundle.Playbooks {
+		if err := playbook.Validate(toPlaybookInputs(p.Inputs), toPlaybookTasks(p.Tasks)); err != nil {
+			return openapi.ImportBundle400JSONResponse{
+				Status:  http.StatusBadRequest,
+				Error:   "Bad Request",
+				Message: fmt.Sprintf("playbook %q: %s", p.Name, err),
+			}, nil
+		}
+	}
+
+	imported := openapi.ImportedBundle{
+		Playbooks: []openapi.Playbook{},
+		Reactions: []openapi.Reaction{},
+	}
+
+	for _, p := range request.Body.Bundle.Playbooks {
+		response, err := s.CreatePlaybook(ctx, openapi.CreatePlaybookRequestObject{Body: &p})
+		if err != nil {
+			return nil, err
+		}
+
+		created, ok := response.(openapi.CreatePlaybook200JSONResponse)
+		if !ok {
+			return nil, fmt.Errorf("failed to create playbook %q", p.Name)
+		}
+
+		imported.Playbooks = append(imported.Playbooks, openapi.Playbook(created))
+	}
+
+	for _, r := range request.Body.Bundle.Reactions {
+		response, err := s.CreateReaction(ctx, openapi.CreateReactionRequestObject{Body: &r})
+		if err != nil {
+			return nil, err
+		}
+
+		created, ok := response.(openapi.CreateReaction200JSONResponse)
+		if !ok {
+			return nil, fmt.Errorf("failed to create reaction %q", r.Name)
+		}
+
+		imported.Reactions = append(imported.Reactions, openapi.Reaction(created))
+	}
+
+	var userID string
+	if user, ok := usercontext.UserFromContext(ctx); ok {
+		userID = user.ID
+	}
+
+	slog.InfoContext(ctx, "Imported bundle", "user", userID, "digest", report.Digest, "findings", len(report.Findings),
+		"playbooks", len(imported.Playbooks), "reactions", len(imported.Reactions))
+
+	return openapi.ImportBundle200JSONResponse(imported), nil
+}
+
+func bundleReport(b openapi.Bundle) (openapi.BundleReport, error) {
+	playbooks := make([]bundle.Playbook, 0, len(b.Playbooks))
+	for _, p := range b.Playbooks {
+		playbooks = append(playbooks, bundle.Playbook{Name: p.Name, Inputs: p.Inputs, Tasks: p.Tasks})
+	}
+
+	reactions := make([]bundle.Reaction, 0, len(b.Reactions))
+	for _, r := range b.Reactions {
+		reactions = append(reactions, bundle.Reaction{
+			Name:        r.Name,
+			Action:      r.Action,
+			Actiondata:  r.Actiondata,
+			Trigger:     r.Trigger,
+			Triggerdata: r.Triggerdata,
+		})
+	}
+
+	digest, err := bundle.Digest(playbooks, reactions)
+	if err != nil {
+		return openapi.BundleReport{}, err
+	}
+
+	findings := bundle.Scan(playbooks, reactions)
+
+	report := openapi.BundleReport{
+		Findings: make([]openapi.BundleFinding, 0, len(findings)),
+		Blocked:  bundle.Blocked(findings),
+		Digest:   digest,
+	}
+
+	for _, f := range findings {
+		report.Findings = append(report.Findings, openapi.BundleFinding{
+			Severity: openapi.BundleFindingSeverity(f.Severity),
+			Rule:     f.Rule,
+			Item:     f.Item,
+			Message:  f.Message,
+		})
+	}
 
-	return openapi.GetTask200JSONResponse(response), nil
+	return report, nil
 }
 
-func (s *Service) UpdateTask(ctx context.Context, request openapi.UpdateTaskRequestObject) (openapi.UpdateTaskResponseObject, error) {
-	s.hooks.OnRecordBeforeUpdateRequest.Publish(ctx, database.TasksTable.ID, request.Body)
-
-	task, err := s.queries.UpdateTask(ctx, sqlc.UpdateTaskParams{
-		ID:    request.Id,
-		Name:  request.Body.Name,
-		Open:  request.Body.Open,
-		Owner: request.Body.Owner,
-	})
-	if err != nil {
-		return nil, err
+func mapPlugin(p *plugin.Plugin) openapi.Plugin {
+	hooks := p.Hooks
+	if hooks == nil {
+		hooks = []string{}
 	}
 
-	response := openapi.Task{
-		Created: task.Created,
-		Id:      task.ID,
-		Name:    task.Name,
-		Open:    task.Open,
-		Owner:   task.Owner,
-		Ticket:  task.Ticket,
-		Updated: task.Updated,
+	functions := p.Functions
+	if functions == nil {
+		functions = []string{}
 	}
 
-	s.hooks.OnRecordAfterUpdateRequest.Publish(ctx, database.TasksTable.ID, response)
-
-	return openapi.UpdateTask200JSONResponse(response), nil
+	return openapi.Plugin{
+		Hooks:       hooks,
+		Functions:   functions,
+		Http:        p.HTTP,
+		Name:        p.Name,
+		Permissions: p.Permissions,
+	}
 }
 
-func (s *Service) SearchTickets(ctx context.Context, request openapi.SearchTicketsRequestObject) (openapi.SearchTicketsResponseObject, error) {
-	tickets, err := s.queries.SearchTickets(ctx, sqlc.SearchTicketsParams{
-		Query:  request.Params.Query,
-		Type:   request.Params.Type,
-		Open:   request.Params.Open,
-		Offset: toInt64(request.Params.Offset, defaultOffset),
-		Limit:  toInt64(request.Params.Limit, defaultLimit),
-	})
+func (s *Service) ListFeeds(ctx context.Context, _ openapi.ListFeedsRequestObject) (openapi.ListFeedsResponseObject, error) {
+	feeds, err := s.queries.ListFeeds(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	response := make([]openapi.TicketSearch, 0, len(tickets))
-
-	for _, ticket := range tickets {
-		response = append(response, openapi.TicketSearch{
-			Created:     ticket.Created,
-			Description: ticket.Description,
-			Id:          ticket.ID,
-			Name:        ticket.Name,
-			Open:        ticket.Open,
-			OwnerName:   pointer.Dereference(ticket.OwnerName),
-			State:       unmarshal(ticket.State),
-			Type:        ticket.Type,
-		})
+	response := make([]openapi.Feed, 0, len(feeds))
+	for _, f := range feeds {
+		response = append(response, mapFeed(&f))
 	}
 
-	s.hooks.OnRecordsListRequest.Publish(ctx, database.TicketsTable.ID, response)
+	return openapi.ListFeeds200JSONResponse(response), nil
+}
 
-	totalCount := 0
-	if len(tickets) > 0 {
-		totalCount = int(tickets[0].TotalCount)
+func (s *Service) ImportFeed(ctx context.Context, request openapi.ImportFeedRequestObject) (openapi.ImportFeedResponseObject, error) {
+	var (
+		result *feed.Result
+		err    error
+	)
+
+	switch {
+	case request.Body.Url != nil && request.Body.Content == nil:
+		result, err = s.feeds.Mirror(ctx, request.Body.Name, *request.Body.Url)
+	case request.Body.Url == nil && request.Body.Content != nil && request.Body.Signature != nil:
+		result, err = s.feeds.Import(ctx, request.Body.Name, "upload", *request.Body.Content, *request.Body.Signature)
+	default:
+		return openapi.ImportFeed400JSONResponse{
+			Status:  http.StatusBadRequest,
+			Error:   "Bad Request",
+			Message: "either content and signature or url are required",
+		}, nil
+	}
+
+	if errors.Is(err, feed.ErrInvalidName) || errors.Is(err, feed.ErrInvalidSignature) {
+		return openapi.ImportFeed400JSONResponse{
+			Status:  http.StatusBadRequest,
+			Error:   "Bad Request",
+			Message: err.Error(),
+		}, nil
+	} else if err != nil {
+		return nil, err
 	}
 
-	return openapi.SearchTickets200JSONResponse{
-		Body: response,
-		Headers: openapi.SearchTickets200ResponseHeaders{
-			XTotalCount: totalCount,
-		},
+	return openapi.ImportFeed200JSONResponse{
+		Feed:    mapFeed(result.Feed),
+		Skipped: result.Skipped,
 	}, nil
 }
 
-func (s *Service) ListTickets(ctx context.Context, request openapi.ListTicketsRequestObject) (openapi.ListTicketsResponseObject, error) {
-	tickets, err := s.queries.ListTickets(ctx, sqlc.ListTicketsParams{
-		Offset: toInt64(request.Params.Offset, defaultOffset),
-		Limit:  toInt64(request.Params.Limit, defaultLimit),
-	})
+func (s *Service) DeleteFeed(ctx context.Context, request openapi.DeleteFeedRequestObject) (openapi.DeleteFeedResponseObject, error) {
+	if err := s.queries.DeleteFeed(ctx, request.Name); err != nil {
+		return nil, err
+	}
+
+	return openapi.DeleteFeed204Response{}, nil
+}
+
+func (s *Service) MatchFeeds(ctx context.Context, request openapi.MatchFeedsRequestObject) (openapi.MatchFeedsResponseObject, error) {
+	matches, err := s.queries.MatchFeedIndicators(ctx, enrichment.Key(request.Params.Value))
 	if err != nil {
 		return nil, err
 	}
 
-	response := make([]openapi.ExtendedTicket, 0, len(tickets))
-	for _, ticket := range tickets {
-		response = append(response, openapi.ExtendedTicket{
-			Created:      ticket.Created,
-			Description:  ticket.Description,
-			Id:           ticket.ID,
-			Name:         ticket.Name,
-			Open:         ticket.Open,
-			Owner:        ticket.Owner,
-			OwnerName:    ticket.OwnerName,
-			Resolution:   ticket.Resolution,
-			Type:         ticket.Type,
-			Schema:       unmarshal(ticket.Schema),
-			State:        unmarshal(ticket.State),
-			TypePlural:   pointer.Dereference(ticket.TypePlural),
-			TypeSingular: pointer.Dereference(ticket.TypeSingular),
-			Updated:      ticket.Updated,
+	response := make([]openapi.FeedMatch, 0, len(matches))
+	for _, match := range matches {
+		response = append(response, openapi.FeedMatch{
+			Feed:     match.Feed,
+			Kind:     match.Kind,
+			Imported: match.Imported,
 		})
 	}
 
-	s.hooks.OnRecordsListRequest.Publish(ctx, "tickets", response)
+	return openapi.MatchFeeds200JSONResponse(response), nil
+}
 
-	totalCount := 0
-	if len(tickets) > 0 {
-		totalCount = int(tickets[0].TotalCount)
+func mapFeed(f *sqlc.Feed) openapi.Feed {
+	return openapi.Feed{
+		Name:       f.Name,
+		Source:     f.Source,
+		Indicators: int(f.Indicators),
+		Imported:   f.Imported,
 	}
+}
 
-	return openapi.ListTickets200JSONResponse{
-		Body: response,
-		Headers: openapi.ListTickets200ResponseHeaders{
-			XTotalCount: totalCount,
-		},
-	}, nil
+func (s *Service) ListWebhookEvents(_ context.Context, _ openapi.ListWebhookEventsRequestObject) (openapi.ListWebhookEventsResponseObject, error) {
+	events := webhook.EventTypes()
+
+	response := make([]openapi.WebhookEvent, 0, len(events))
+	for _, event := range events {
+		response = append(response, openapi.WebhookEvent{
+			Action:     event.Action,
+			Collection: event.Collection,
+			Schema:     event.Schema,
+			Version:    event.Version,
+		})
+	}
+
+	return openapi.ListWebhookEvents200JSONResponse(response), nil
 }
 
-func (s *Service) CreateTicket(ctx context.Context, request openapi.CreateTicketRequestObject) (openapi.CreateTicketResponseObject, error) {
-	s.hooks.OnRecordBeforeCreateRequest.Publish(ctx, database.TicketsTable.ID, request.Body)
+func (s *Service) GetEventCatalog(_ context.Context, _ openapi.GetEventCatalogRequestObject) (openapi.GetEventCatalogResponseObject, error) {
+	entries := webhook.Catalog()
+
+	response := make([]openapi.EventCatalogEntry, 0, len(entries))
+	for _, entry := range entries {
+		response = append(response, openapi.EventCatalogEntry{
+			Action:        entry.Action,
+			Collection:    entry.Collection,
+			Schema:        entry.Schema,
+			Version:       entry.Version,
+			PayloadSchema: entry.PayloadSchema,
+			Example:       entry.Example,
+		})
+	}
 
-	ticket, err := s.queries.CreateTicket(ctx, sqlc.CreateTicketParams{
-		Name:        request.Body.Name,
-		Description: request.Body.Description,
-		Owner:       request.Body.Owner,
-		Open:        request.Body.Open,
-		Resolution:  request.Body.Resolution,
-		Type:        request.Body.Type,
-		State:       marshal(request.Body.State),
+	return openapi.GetEventCatalog200JSONResponse(response), nil
+}
+
+func (s *Service) GetConfig(ctx context.Context, _ openapi.GetConfigRequestObject) (openapi.GetConfigResponseObject, error) {
+	flags := []string{}
+
+	features, err := database.PaginateItems(ctx, func(ctx context.Context, offset, limit int64) ([]sqlc.ListFeaturesRow, error) {
+		return s.queries.ListFeatures(ctx, sqlc.ListFeaturesParams{Limit: limit, Offset: offset})
 	})
 	if err != nil {
 		return nil, err
 	}
 
-	response := openapi.Ticket{
-		Created:     ticket.Created,
-		Description: ticket.Description,
-		Id:          ticket.ID,
-		Name:        ticket.Name,
-		Open:        ticket.Open,
-		Owner:       ticket.Owner,
-		Resolution:  ticket.Resolution,
-		Schema:      unmarshal(ticket.Schema),
-		State:       unmarshal(ticket.State),
-		Type:        ticket.Type,
-		Updated:     ticket.Updated,
+	for _, feature := range features {
+		flags = append(flags, feature.Key)
 	}
 
-	s.hooks.OnRecordAfterCreateRequest.Publish(ctx, database.TicketsTable.ID, response)
+	tables := []openapi.Table{}
+	for _, table := range database.Tables() {
+		tables = append(tables, openapi.Table{
+			Id:   table.ID,
+			Name: table.Name,
+		})
+	}
 
-	return openapi.CreateTicket200JSONResponse(response), nil
-}
+	response := openapi.Config{
+		Flags:       flags,
+		Permissions: auth.All(),
+		Tables:      tables,
+	}
 
-func (s *Service) DeleteTicket(ctx context.Context, request openapi.DeleteTicketRequestObject) (openapi.DeleteTicketResponseObject, error) {
-	s.hooks.OnRecordBeforeDeleteRequest.Publish(ctx, database.TicketsTable.ID, request.Id)
+	return openapi.GetConfig200JSONResponse(response), nil
+}
 
-	err := s.queries.DeleteTicket(ctx, request.Id)
+func (s *Service) GetSettings(ctx context.Context, _ openapi.GetSettingsRequestObject) (openapi.GetSettingsResponseObject, error) {
+	settings, err := settings.Load(ctx, s.queries)
 	if err != nil {
 		return nil, err
 	}
 
-	s.hooks.OnRecordAfterDeleteRequest.Publish(ctx, database.TicketsTable.ID, request.Id)
+	return openapi.GetSettings200JSONResponse(mapSettings(settings)), nil
+}
 
-	return openapi.DeleteTicket204Response{}, nil
+func (s *Service) UpdateSettings(ctx context.Context, request openapi.UpdateSettingsRequestObject) (openapi.UpdateSettingsResponseObject, error) {
+	se, err := settings.Update(ctx, s.queries, func(settings *settings.Settings) {
+		settings.Meta.AppName = request.Body.Meta.AppName
+		settings.Meta.AppURL = request.Body.Meta.AppUrl
+		settings.Meta.SenderAddress = request.Body.Meta.SenderAddress
+		settings.Meta.SenderName = request.Body.Meta.SenderName
+		settings.SMTP.Enabled = request.Body.Smtp.Enabled
+		settings.SMTP.Host = request.Body.Smtp.Host
+		settings.SMTP.Port = request.Body.Smtp.Port
+		settings.SMTP.Username = request.Body.Smtp.Username
+		settings.SMTP.Password = request.Body.Smtp.Password
+		settings.SMTP.AuthMethod = request.Body.Smtp.AuthMethod
+		settings.SMTP.TLS = request.Body.Smtp.Tls
+		settings.SMTP.LocalName = request.Body.Smtp.LocalName
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to save settings: %w", err)
+	}
+
+	return openapi.UpdateSettings200JSONResponse(mapSettings(se)), err
 }
 
-func (s *Service) GetTicket(ctx context.Context, request openapi.GetTicketRequestObject) (openapi.GetTicketResponseObject, error) {
-	ticket, err := s.queries.Ticket(ctx, request.Id)
+func (s *Service) GetBranding(ctx context.Context, _ openapi.GetBrandingRequestObject) (openapi.GetBrandingResponseObject, error) {
+	settings, err := settings.Load(ctx, s.queries)
 	if err != nil {
 		return nil, err
 	}
 
-	response := openapi.ExtendedTicket{
-		Created:      ticket.Created,
-		Description:  ticket.Description,
-		Id:           ticket.ID,
-		Name:         ticket.Name,
-		Open:         ticket.Open,
-		Owner:        ticket.Owner,
-		OwnerName:    ticket.OwnerName,
-		Resolution:   ticket.Resolution,
-		Schema:       unmarshal(ticket.Schema),
-		State:        unmarshal(ticket.State),
-		Type:         ticket.Type,
-		TypePlural:   pointer.Dereference(ticket.TypePlural),
-		TypeSingular: pointer.Dereference(ticket.TypeSingular),
-		Updated:      ticket.Updated,
+	return openapi.GetBranding200JSONResponse(mapBranding(settings.Branding)), nil
+}
+
+func (s *Service) UpdateBranding(ctx context.Context, request openapi.UpdateBrandingRequestObject) (openapi.UpdateBrandingResponseObject, error) {
+	se, err := settings.Update(ctx, s.queries, func(settings *settings.Settings) {
+		settings.Branding.Title = request.Body.Title
+		settings.Branding.Logo = request.Body.Logo
+		settings.Branding.LoginBanner = request.Body.LoginBanner
+		settings.Branding.CustomCSS = request.Body.CustomCss
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to save branding: %w", err)
 	}
 
-	s.hooks.OnRecordViewRequest.Publish(ctx, database.TicketsTable.ID, response)
+	return openapi.UpdateBranding200JSONResponse(mapBranding(se.Branding)), nil
+}
 
-	return openapi.GetTicket200JSONResponse(response), nil
+func mapBranding(branding settings.Branding) openapi.Branding {
+	return openapi.Branding{
+		CustomCss:   branding.CustomCSS,
+		LoginBanner: branding.LoginBanner,
+		Logo:        branding.Logo,
+		Title:       branding.Title,
+	}
 }
 
-func (s *Service) UpdateTicket(ctx context.Context, request openapi.UpdateTicketRequestObject) (openapi.UpdateTicketResponseObject, error) {
-	s.hooks.OnRecordBeforeUpdateRequest.Publish(ctx, database.TicketsTable.ID, request.Body)
+var errIntakeDisabled = openapi.Error{
+	Status:  http.StatusNotFound,
+	Error:   "Not Found",
+	Message: "The intake form is disabled",
+}
 
-	ticket, err := s.queries.UpdateTicket(ctx, sqlc.UpdateTicketParams{
-		Name:        request.Body.Name,
-		Description: request.Body.Description,
-		Open:        request.Body.Open,
-		Owner:       request.Body.Owner,
-		Resolution:  request.Body.Resolution,
-		Schema:      marshalPointer(request.Body.Schema),
-		State:       marshalPointer(request.Body.State),
-		Type:        request.Body.Type,
-		ID:          request.Id,
-	})
+func (s *Service) GetIntakeForm(ctx context.Context, _ openapi.GetIntakeFormRequestObject) (openapi.GetIntakeFormResponseObject, error) {
+	intakeType, enabled, err := s.intakeType(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	response := openapi.Ticket{
-		Created:     ticket.Created,
-		Description: ticket.Description,
-		Id:          ticket.ID,
-		Name:        ticket.Name,
-		Open:        ticket.Open,
-		Owner:       ticket.Owner,
-		Resolution:  ticket.Resolution,
-		Schema:      unmarshal(ticket.Schema),
-		State:       unmarshal(ticket.State),
-		Type:        ticket.Type,
-		Updated:     ticket.Updated,
+	if !enabled {
+		return openapi.GetIntakeForm404JSONResponse(errIntakeDisabled), nil
 	}
 
-	s.hooks.OnRecordAfterUpdateRequest.Publish(ctx, database.TicketsTable.ID, response)
-
-	return openapi.UpdateTicket200JSONResponse(response), nil
+	return openapi.GetIntakeForm200JSONResponse{
+		Schema: unmarshal(intakeType.Schema),
+		Title:  intakeType.Singular,
+		Type:   intakeType.ID,
+	}, nil
 }
 
-func (s *Service) ListTimeline(ctx context.Context, request openapi.ListTimelineRequestObject) (openapi.ListTimelineResponseObject, error) {
-	timeline, err := s.queries.ListTimeline(ctx, sqlc.ListTimelineParams{
-		Ticket: toString(request.Params.Ticket, ""),
-		Offset: toInt64(request.Params.Offset, defaultOffset),
-		Limit:  toInt64(request.Params.Limit, defaultLimit),
-	})
+func (s *Service) SubmitIntake(ctx context.Context, request openapi.SubmitIntakeRequestObject) (openapi.SubmitIntakeResponseObject, error) {
+	intakeType, enabled, err := s.intakeType(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	response := make([]openapi.TimelineEntry, 0, len(timeline))
-	for _, timeline := range timeline {
-		response = append(response, openapi.TimelineEntry{
-			Id:      timeline.ID,
-			Message: timeline.Message,
-			Created: timeline.Created,
-			Updated: timeline.Updated,
-			Time:    timeline.Time,
-			Ticket:  timeline.Ticket,
-		})
+	if !enabled {
+		return openapi.SubmitIntake404JSONResponse(errIntakeDisabled), nil
 	}
 
-	s.hooks.OnRecordsListRequest.Publish(ctx, database.TimelinesTable.ID, response)
+	if err := validateState(intakeType.Schema, request.Body.State); err != nil {
+		return openapi.SubmitIntake400JSONResponse{
+			Status:  http.StatusBadRequest,
+			Error:   "Bad Request",
+			Message: err.Error(),
+		}, nil
+	}
 
-	totalCount := 0
-	if len(timeline) > 0 {
-		totalCount = int(timeline[0].TotalCount)
+	// reports are created by the system user, so hooks and webhooks
+	// see an authenticated record
+	systemUser, err := s.queries.SystemUser(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find system user: %w", err)
 	}
 
-	return openapi.ListTimeline200JSONResponse{
-		Body: response,
-		Headers: openapi.ListTimeline200ResponseHeaders{
-			XTotalCount: totalCount,
-		},
-	}, nil
-}
+	ctx = usercontext.UserContext(ctx, &systemUser)
 
-func (s *Service) CreateTimeline(ctx context.Context, request openapi.CreateTimelineRequestObject) (openapi.CreateTimelineResponseObject, error) {
-	s.hooks.OnRecordBeforeCreateRequest.Publish(ctx, database.TimelinesTable.ID, request.Body)
+	s.hooks.OnRecordBeforeCreateRequest.Publish(ctx, database.TicketsTable.ID, request.Body)
 
-	timeline, err := s.queries.CreateTimeline(ctx, sqlc.CreateTimelineParams{
-		Message: request.Body.Message,
-		Time:    request.Body.Time,
-		Ticket:  request.Body.Ticket,
+	ticket, err := s.queries.CreateTicket(ctx, sqlc.CreateTicketParams{
+		Name:        request.Body.Name,
+		Description: request.Body.Description,
+		Open:        true,
+		Type:        intakeType.ID,
+		Schema:      intakeType.Schema,
+		State:       marshal(request.Body.State),
 	})
 	if err != nil {
 		return nil, err
 	}
 
-	response := openapi.TimelineEntry{
-		Created: timeline.Created,
-		Id:      timeline.ID,
-		Message: timeline.Message,
-		Ticket:  timeline.Ticket,
-		Time:    timeline.Time,
-		Updated: timeline.Updated,
+	if ticket.Key, err = s.ticketKey(ctx, ticket.ID); err != nil {
+		return nil, err
 	}
 
-	s.hooks.OnRecordAfterCreateRequest.Publish(ctx, database.TimelinesTable.ID, response)
+	s.hooks.OnRecordAfterCreateRequest.Publish(ctx, database.TicketsTable.ID, openapi.Ticket{
+		Created:        ticket.Created,
+		Description:    ticket.Description,
+		Id:             ticket.ID,
+		Key:            ticket.Key,
+		Name:           ticket.Name,
+		Open:           ticket.Open,
+		Owner:          ticket.Owner,
+		Resolution:     ticket.Resolution,
+		Schema:         unmarshal(ticket.Schema),
+		State:          unmarshal(ticket.State),
+		Type:           ticket.Type,
+		Acknowledged:   ticket.Acknowledged,
+		AcknowledgedBy: ticket.AcknowledgedBy,
+		Resolved:       ticket.Resolved,
+		ResolvedBy:     ticket.ResolvedBy,
+		ReopenCount:    int(ticket.ReopenCount),
+		Updated:        ticket.Updated,
+	})
+
+	return openapi.SubmitIntake200JSONResponse{Id: ticket.ID}, nil
+}
+
+// intakeType returns the ticket type of the intake form and whether the
+// intake form is enabled.
+func (s *Service) intakeType(ctx context.Context) (sqlc.Type, bool, error) {
+	se, err := settings.Load(ctx, s.queries)
+	if err != nil {
+		return sqlc.Type{}, false, err
+	}
+
+	if !se.Intake.Enabled || se.Intake.Type == "" {
+		return sqlc.Type{}, false, nil
+	}
+
+	intakeType, err := s.queries.GetType(ctx, se.Intake.Type)
+	if err != nil {
+		return sqlc.Type{}, false, fmt.Errorf("failed to get intake type: %w", err)
+	}
 
-	return openapi.CreateTimeline200JSONResponse(response), nil
+	return intakeType, true, nil
 }
 
-func (s *Service) DeleteTimeline(ctx context.Context, request openapi.DeleteTimelineRequestObject) (openapi.DeleteTimelineResponseObject, error) {
-	s.hooks.OnRecordBeforeDeleteRequest.Publish(ctx, database.TimelinesTable.ID, request.Id)
+// validateState checks the custom fields of a ticket against the JSON schema
+// of its type.
+func validateState(schema []byte, state map[string]any) error {
+	var typeSchema openapi3.Schema
+	if len(schema) > 0 {
+		if err := json.Unmarshal(schema, &typeSchema); err != nil {
+			return fmt.Errorf("the schema of the ticket type is invalid: %w", err)
+		}
+	}
 
-	err := s.queries.DeleteTimeline(ctx, request.Id)
-	if err != nil {
-		return nil, err
+	if state == nil {
+		state = map[string]any{}
 	}
 
-	s.hooks.OnRecordAfterDeleteRequest.Publish(ctx, database.TimelinesTable.ID, request.Id)
+	if err := typeSchema.VisitJSON(state); err != nil {
+		var schemaErr *openapi3.SchemaError
+		if errors.As(err, &schemaErr) {
+			if field := strings.Join(schemaErr.JSONPointer(), "."); field != "" {
+				return fmt.Errorf("invalid field %q: %s", field, schemaErr.Reason)
+			}
 
-	return openapi.DeleteTimeline204Response{}, nil
+			return fmt.Errorf("invalid state: %s", schemaErr.Reason)
+		}
+
+		return fmt.Errorf("invalid state: %w", err)
+	}
+
+	return nil
 }
 
-func (s *Service) GetTimeline(ctx context.Context, request openapi.GetTimelineRequestObject) (openapi.GetTimelineResponseObject, error) {
-	timeline, err := s.queries.GetTimeline(ctx, request.Id)
+func (s *Service) GetIntakeSettings(ctx context.Context, _ openapi.GetIntakeSettingsRequestObject) (openapi.GetIntakeSettingsResponseObject, error) {
+	se, err := settings.Load(ctx, s.queries)
 	if err != nil {
 		return nil, err
 	}
 
-	response := openapi.TimelineEntry{
-		Id:      timeline.ID,
-		Message: timeline.Message,
-		Created: timeline.Created,
-		Updated: timeline.Updated,
-		Time:    timeline.Time,
-		Ticket:  timeline.Ticket,
+	return openapi.GetIntakeSettings200JSONResponse{
+		Enabled: se.Intake.Enabled,
+		Type:    se.Intake.Type,
+	}, nil
+}
+
+func (s *Service) UpdateIntakeSettings(ctx context.Context, request openapi.UpdateIntakeSettingsRequestObject) (openapi.UpdateIntakeSettingsResponseObject, error) {
+	if request.Body.Enabled {
+		if _, err := s.queries.GetType(ctx, request.Body.Type); errors.Is(err, sql.ErrNoRows) {
+			return openapi.UpdateIntakeSettings400JSONResponse{
+				Status:  http.StatusBadRequest,
+				Error:   "Bad Request",
+				Message: fmt.Sprintf("unknown ticket type %q", request.Body.Type),
+			}, nil
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to get intake type: %w", err)
+		}
 	}
 
-	s.hooks.OnRecordViewRequest.Publish(ctx, database.TimelinesTable.ID, response)
+	se, err := settings.Update(ctx, s.queries, func(settings *settings.Settings) {
+		settings.Intake.Enabled = request.Body.Enabled
+		settings.Intake.Type = request.Body.Type
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to save intake settings: %w", err)
+	}
 
-	return openapi.GetTimeline200JSONResponse(response), nil
+	return openapi.UpdateIntakeSettings200JSONResponse{
+		Enabled: se.Intake.Enabled,
+		Type:    se.Intake.Type,
+	}, nil
 }
 
-func (s *Service) UpdateTimeline(ctx context.Context, request openapi.UpdateTimelineRequestObject) (openapi.UpdateTimelineResponseObject, error) {
-	s.hooks.OnRecordBeforeUpdateRequest.Publish(ctx, database.TimelinesTable.ID, request.Body)
-
-	timeline, err := s.queries.UpdateTimeline(ctx, sqlc.UpdateTimelineParams{
-		ID:      request.Id,
-		Message: request.Body.Message,
-		Time:    request.Body.Time,
-	})
+func (s *Service) GetSlackSettings(ctx context.Context, _ openapi.GetSlackSettingsRequestObject) (openapi.GetSlackSettingsResponseObject, error) {
+	se, err := settings.Load(ctx, s.queries)
 	if err != nil {
 		return nil, err
 	}
 
-	response := openapi.TimelineEntry{
-		Created: timeline.Created,
-		Id:      timeline.ID,
-		Message: timeline.Message,
-		Ticket:  timeline.Ticket,
-		Time:    timeline.Time,
-		Updated: timeline.Updated,
+	return openapi.GetSlackSettings200JSONResponse(mapSlackSettings(&se.Slack)), nil
+}
+
+func (s *Service) UpdateSlackSettings(ctx context.Context, request openapi.UpdateSlackSettingsRequestObject) (openapi.UpdateSlackSettingsResponseObject, error) {
+	if request.Body.Enabled {
+		if _, err := s.queries.GetType(ctx, request.Body.TicketType); err != nil {
+			return nil, fmt.Errorf("invalid Slack ticket type %q: %w", request.Body.TicketType, err)
+		}
 	}
 
-	s.hooks.OnRecordAfterUpdateRequest.Publish(ctx, database.TimelinesTable.ID, response)
+	se, err := settings.Update(ctx, s.queries, func(settings *settings.Settings) {
+		settings.Slack.Enabled = request.Body.Enabled
+		settings.Slack.TicketType = request.Body.TicketType
 
-	return openapi.UpdateTimeline200JSONResponse(response), nil
-}
+		// the redacted value from GetSlackSettings keeps the stored secret
+		if request.Body.SigningSecret != redacted {
+			settings.Slack.SigningSecret = request.Body.SigningSecret
+		}
 
-func (s *Service) ListTypes(ctx context.Context, request openapi.ListTypesRequestObject) (openapi.ListTypesResponseObject, error) {
-	types, err := s.queries.ListTypes(ctx, sqlc.ListTypesParams{
-		Offset: toInt64(request.Params.Offset, defaultOffset),
-		Limit:  toInt64(request.Params.Limit, defaultLimit),
+		if request.Body.BotToken != redacted {
+			settings.Slack.BotToken = request.Body.BotToken
+		}
 	})
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("failed to save Slack settings: %w", err)
 	}
 
-	response := make([]openapi.Type, 0, len(types))
-	for _, t := range types {
-		response = append(response, openapi.Type{
-			Created:  t.Created,
-			Icon:     t.Icon,
-			Id:       t.ID,
-			Plural:   t.Plural,
-			Schema:   unmarshal(t.Schema),
-			Singular: t.Singular,
-			Updated:  t.Updated,
-		})
+	return openapi.UpdateSlackSettings200JSONResponse(mapSlackSettings(&se.Slack)), nil
+}
+
+func mapSlackSettings(config *settings.Slack) openapi.SlackSettings {
+	slackSettings := openapi.SlackSettings{
+		Enabled:    config.Enabled,
+		TicketType: config.TicketType,
 	}
 
-	s.hooks.OnRecordsListRequest.Publish(ctx, database.TypesTable.ID, response)
+	if config.SigningSecret != "" {
+		slackSettings.SigningSecret = redacted
+	}
 
-	totalCount := 0
-	if len(types) > 0 {
-		totalCount = int(types[0].TotalCount)
+	if config.BotToken != "" {
+		slackSettings.BotToken = redacted
 	}
 
-	return openapi.ListTypes200JSONResponse{
-		Body: response,
-		Headers: openapi.ListTypes200ResponseHeaders{
-			XTotalCount: totalCount,
-		},
-	}, nil
+	return slackSettings
 }
 
-func (s *Service) CreateType(ctx context.Context, request openapi.CreateTypeRequestObject) (openapi.CreateTypeResponseObject, error) {
-	s.hooks.OnRecordBeforeCreateRequest.Publish(ctx, database.TypesTable.ID, request.Body)
-
-	t, err := s.queries.CreateType(ctx, sqlc.CreateTypeParams{
-		Icon:     request.Body.Icon,
-		Plural:   request.Body.Plural,
-		Singular: request.Body.Singular,
-		Schema:   marshal(request.Body.Schema),
-	})
+func (s *Service) GetMetricsExportSettings(ctx context.Context, _ openapi.GetMetricsExportSettingsRequestObject) (openapi.GetMetricsExportSettingsResponseObject, error) {
+	se, err := settings.Load(ctx, s.queries)
 	if err != nil {
 		return nil, err
 	}
 
-	response := openapi.Type{
-		Created:  t.Created,
-		Icon:     t.Icon,
-		Id:       t.ID,
-		Plural:   t.Plural,
-		Schema:   unmarshal(t.Schema),
-		Singular: t.Singular,
-		Updated:  t.Updated,
+	return openapi.GetMetricsExportSettings200JSONResponse(mapMetricsExportSettings(&se.MetricsExport)), nil
+}
+
+func (s *Service) UpdateMetricsExportSettings(ctx context.Context, request openapi.UpdateMetricsExportSettingsRequestObject) (openapi.UpdateMetricsExportSettingsResponseObject, error) {
+	if request.Body.Enabled {
+		endpoint, err := url.Parse(request.Body.Endpoint)
+		if err != nil || (endpoint.Scheme != "https" && endpoint.Scheme != "http") || endpoint.Host == "" {
+			return nil, fmt.Errorf("invalid metrics export endpoint %q", request.Body.Endpoint)
+		}
+
+		if request.Body.Bucket == "" || request.Body.Region == "" {
+			return nil, errors.New("the metrics export needs a bucket and a region")
+		}
 	}
 
-	s.hooks.OnRecordAfterCreateRequest.Publish(ctx, database.TypesTable.ID, response)
+	if request.Body.Interval < 0 {
+		return nil, errors.New("the metrics export interval must not be negative")
+	}
 
-	return openapi.CreateType200JSONResponse(response), nil
+	se, err := settings.Update(ctx, s.queries, func(settings *settings.Settings) {
+		settings.MetricsExport.Enabled = request.Body.Enabled
+		settings.MetricsExport.Interval = request.Body.Interval
+		settings.MetricsExport.Endpoint = request.Body.Endpoint
+		settings.MetricsExport.Region = request.Body.Region
+		settings.MetricsExport.Bucket = request.Body.Bucket
+		settings.MetricsExport.Prefix = request.Body.Prefix
+		settings.MetricsExport.AccessKeyID = request.Body.AccessKeyId
+
+		// the redacted value from GetMetricsExportSettings keeps the stored secret
+		if request.Body.SecretAccessKey != redacted {
+			settings.MetricsExport.SecretAccessKey = request.Body.SecretAccessKey
+		}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to save metrics export settings: %w", err)
+	}
+
+	return openapi.UpdateMetricsExportSettings200JSONResponse(mapMetricsExportSettings(&se.MetricsExport)), nil
 }
 
-func (s *Service) DeleteType(ctx context.Context, request openapi.DeleteTypeRequestObject) (openapi.DeleteTypeResponseObject, error) {
-	s.hooks.OnRecordBeforeDeleteRequest.Publish(ctx, database.TypesTable.ID, request.Id)
+func (s *Service) RunMetricsExport(ctx context.Context, _ openapi.RunMetricsExportRequestObject) (openapi.RunMetricsExportResponseObject, error) {
+	keys, err := export.New(s.queries).Export(ctx)
+	if errors.Is(err, export.ErrDisabled) {
+		return openapi.RunMetricsExport404JSONResponse{
+			Status:  http.StatusNotFound,
+			Error:   "Not Found",
+			Message: "The metrics export is disabled",
+		}, nil
+	} else if errors.Is(err, dlp.ErrBlocked) {
+		return openapi.RunMetricsExport403JSONResponse{
+			Status:  http.StatusForbidden,
+			Error:   "Forbidden",
+			Message: err.Error(),
+		}, nil
+	} else if err != nil {
+		return nil, err
+	}
 
-	if err := s.queries.DeleteType(ctx, request.Id); err != nil {
-		return nil, fmt.Errorf("failed to delete type: %w", err)
+	return openapi.RunMetricsExport200JSONResponse{Objects: keys}, nil
+}
+
+func mapMetricsExportSettings(config *settings.MetricsExport) openapi.MetricsExportSettings {
+	exportSettings := openapi.MetricsExportSettings{
+		Enabled:     config.Enabled,
+		Interval:    cmp.Or(config.Interval, export.DefaultInterval),
+		Endpoint:    config.Endpoint,
+		Region:      config.Region,
+		Bucket:      config.Bucket,
+		Prefix:      config.Prefix,
+		AccessKeyId: config.AccessKeyID,
 	}
 
-	s.hooks.OnRecordAfterDeleteRequest.Publish(ctx, database.TypesTable.ID, request.Id)
+	if config.SecretAccessKey != "" {
+		exportSettings.SecretAccessKey = redacted
+	}
 
-	return openapi.DeleteType204Response{}, nil
+	return exportSettings
 }
 
-func (s *Service) GetType(ctx context.Context, request openapi.GetTypeRequestObject) (openapi.GetTypeResponseObject, error) {
-	t, err := s.queries.GetType(ctx, request.Id)
+func (s *Service) GetDLPSettings(ctx context.Context, _ openapi.GetDLPSettingsRequestObject) (openapi.GetDLPSettingsResponseObject, error) {
+	se, err := settings.Load(ctx, s.queries)
 	if err != nil {
 		return nil, err
 	}
 
-	response := openapi.Type{
-		Created:  t.Created,
-		Icon:     t.Icon,
-		Id:       t.ID,
-		Plural:   t.Plural,
-		Schema:   unmarshal(t.Schema),
-		Singular: t.Singular,
-		Updated:  t.Updated,
-	}
+	return openapi.GetDLPSettings200JSONResponse(mapDLPSettings(&se.DLP)), nil
+}
 
-	s.hooks.OnRecordViewRequest.Publish(ctx, database.TypesTable.ID, response)
+func (s *Service) UpdateDLPSettings(ctx context.Context, request openapi.UpdateDLPSettingsRequestObject) (openapi.UpdateDLPSettingsResponseObject, error) {
+	rules := make([]settings.DLPRule, 0, len(request.Body.Rules))
+	for _, rule := range request.Body.Rules {
+		var exports []string
+		for _, export := range pointer.Dereference(rule.Exports) {
+			exports = append(exports, string(export))
+		}
 
-	return openapi.GetType200JSONResponse(response), nil
-}
+		rules = append(rules, settings.DLPRule{
+			Name:    rule.Name,
+			TLP:     pointer.Dereference(rule.Tlp),
+			Tags:    pointer.Dereference(rule.Tags),
+			Exports: exports,
+		})
+	}
 
-func (s *Service) UpdateType(ctx context.Context, request openapi.UpdateTypeRequestObject) (openapi.UpdateTypeResponseObject, error) {
-	s.hooks.OnRecordBeforeUpdateRequest.Publish(ctx, database.TypesTable.ID, request.Body)
+	if err := dlp.Validate(rules); err != nil {
+		return openapi.UpdateDLPSettings400JSONResponse{
+			Status:  http.StatusBadRequest,
+			Error:   "Bad Request",
+			Message: err.Error(),
+		}, nil
+	}
 
-	t, err := s.queries.UpdateType(ctx, sqlc.UpdateTypeParams{
-		ID:       request.Id,
-		Icon:     request.Body.Icon,
-		Plural:   request.Body.Plural,
-		Singular: request.Body.Singular,
-		Schema:   marshalPointer(request.Body.Schema),
+	se, err := settings.Update(ctx, s.queries, func(settings *settings.Settings) {
+		settings.DLP.Rules = rules
 	})
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("failed to save data loss prevention settings: %w", err)
 	}
 
-	response := openapi.Type{
-		Created:  t.Created,
-		Icon:     t.Icon,
-		Id:       t.ID,
-		Plural:   t.Plural,
-		Schema:   unmarshal(t.Schema),
-		Singular: t.Singular,
-		Updated:  t.Updated,
-	}
+	return openapi.UpdateDLPSettings200JSONResponse(mapDLPSettings(&se.DLP)), nil
+}
 
-	s.hooks.OnRecordAfterUpdateRequest.Publish(ctx, database.TypesTable.ID, response)
+func mapDLPSettings(config *settings.DLP) openapi.DLPSettings {
+	rules := make([]openapi.DLPRule, 0, len(config.Rules))
+	for _, rule := range config.Rules {
+		exports := make([]openapi.DLPRuleExports, 0, len(rule.Exports))
+		for _, export := range rule.Exports {
+			exports = append(exports, openapi.DLPRuleExports(export))
+		}
 
-	return openapi.UpdateType200JSONResponse(response), nil
+		rules = append(rules, openapi.DLPRule{
+			Name:    rule.Name,
+			Tlp:     pointer.Pointer(append([]string{}, rule.TLP...)),
+			Tags:    pointer.Pointer(append([]string{}, rule.Tags...)),
+			Exports: &exports,
+		})
+	}
+
+	return openapi.DLPSettings{Rules: rules}
 }
 
-func (s *Service) ListUsers(ctx context.Context, request openapi.ListUsersRequestObject) (openapi.ListUsersResponseObject, error) {
-	users, err := s.queries.ListUsers(ctx, sqlc.ListUsersParams{
+func (s *Service) ListDLPEvents(ctx context.Context, request openapi.ListDLPEventsRequestObject) (openapi.ListDLPEventsResponseObject, error) {
+	events, err := s.queries.ListDLPEvents(ctx, sqlc.ListDLPEventsParams{
 		Offset: toInt64(request.Params.Offset, defaultOffset),
 		Limit:  toInt64(request.Params.Limit, defaultLimit),
 	})
@@ -1200,170 +9282,163 @@ func (s *Service) ListUsers(ctx context.Context, request openapi.ListUsersReques
 		return nil, err
 	}
 
-	response := make([]openapi.User, 0, len(users))
-	for _, user := range users {
-		response = append(response, openapi.User{
-			Avatar:                 user.Avatar,
-			Created:                user.Created,
-			Email:                  user.Email,
-			Id:                     user.ID,
-			LastResetSentAt:        user.Lastresetsentat,
-			LastVerificationSentAt: user.Lastverificationsentat,
-			Name:                   user.Name,
-			Updated:                user.Updated,
-			Username:               user.Username,
-			Active:                 user.Active,
+	response := make([]openapi.DLPEvent, 0, len(events))
+	for _, event := range events {
+		response = append(response, openapi.DLPEvent{
+			Id:       event.ID,
+			Rule:     event.Rule,
+			Export:   event.Export,
+			Ticket:   event.Ticket,
+			User:     event.User,
+			UserName: event.UserName,
+			Created:  event.Created,
 		})
 	}
 
-	s.hooks.OnRecordsListRequest.Publish(ctx, database.UsersTable.ID, response)
-
 	totalCount := 0
-	if len(users) > 0 {
-		totalCount = int(users[0].TotalCount)
+	if len(events) > 0 {
+		totalCount = int(events[0].TotalCount)
 	}
 
-	return openapi.ListUsers200JSONResponse{
+	return openapi.ListDLPEvents200JSONResponse{
 		Body: response,
-		Headers: openapi.ListUsers200ResponseHeaders{
+		Headers: openapi.ListDLPEvents200ResponseHeaders{
 			XTotalCount: totalCount,
 		},
 	}, nil
 }
 
-func (s *Service) CreateUser(ctx context.Context, request openapi.CreateUserRequestObject) (openapi.CreateUserResponseObject, error) {
-	s.hooks.OnRecordBeforeCreateRequest.Publish(ctx, database.UsersTable.ID, request.Body)
-
-	tokenKey, err := password.GenerateTokenKey()
-	if err != nil {
-		return nil, fmt.Errorf("failed to generate token key: %w", err)
-	}
-
-	user, err := s.queries.CreateUser(ctx, sqlc.CreateUserParams{
-		Name:         request.Body.Name,
-		Email:        request.Body.Email,
-		Username:     request.Body.Username,
-		PasswordHash: "",
-		TokenKey:     tokenKey,
-		Avatar:       request.Body.Avatar,
-		Active:       request.Body.Active,
-	})
+func (s *Service) GetAnomalySettings(ctx context.Context, _ openapi.GetAnomalySettingsRequestObject) (openapi.GetAnomalySettingsResponseObject, error) {
+	se, err := settings.Load(ctx, s.queries)
 	if err != nil {
 		return nil, err
 	}
 
-	response := openapi.User{
-		Avatar:                 user.Avatar,
-		Created:                user.Created,
-		Email:                  user.Email,
-		Id:                     user.ID,
-		LastResetSentAt:        user.Lastresetsentat,
-		LastVerificationSentAt: user.Lastverificationsentat,
-		Name:                   user.Name,
-		Updated:                user.Updated,
-		Username:               user.Username,
-		Active:                 user.Active,
-	}
-
-	s.hooks.OnRecordAfterCreateRequest.Publish(ctx, database.UsersTable.ID, response)
-
-	return openapi.CreateUser200JSONResponse(response), nil
+	return openapi.GetAnomalySettings200JSONResponse(mapAnomalySettings(&se.AnomalyDetection)), nil
 }
 
-func (s *Service) DeleteUser(ctx context.Context, request openapi.DeleteUserRequestObject) (openapi.DeleteUserResponseObject, error) {
-	s.hooks.OnRecordBeforeDeleteRequest.Publish(ctx, database.UsersTable.ID, request.Id)
+func (s *Service) UpdateAnomalySettings(ctx context.Context, request openapi.UpdateAnomalySettingsRequestObject) (openapi.UpdateAnomalySettingsResponseObject, error) {
+	if request.Body.Enabled {
+		if _, err := s.queries.GetType(ctx, request.Body.TicketType); err != nil {
+			return nil, fmt.Errorf("invalid anomaly ticket type %q: %w", request.Body.TicketType, err)
+		}
+	}
 
-	err := s.queries.DeleteUser(ctx, request.Id)
+	if request.Body.Sensitivity < 0 || request.Body.MinCount < 0 || request.Body.SilenceHours < 0 {
+		return nil, errors.New("the anomaly detection thresholds must not be negative")
+	}
+
+	se, err := settings.Update(ctx, s.queries, func(settings *settings.Settings) {
+		settings.AnomalyDetection.Enabled = request.Body.Enabled
+		settings.AnomalyDetection.TicketType = request.Body.TicketType
+		settings.AnomalyDetection.Sensitivity = float64(request.Body.Sensitivity)
+		settings.AnomalyDetection.MinCount = request.Body.MinCount
+		settings.AnomalyDetection.SilenceHours = request.Body.SilenceHours
+	})
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("failed to save anomaly detection settings: %w", err)
 	}
 
-	s.hooks.OnRecordAfterDeleteRequest.Publish(ctx, database.UsersTable.ID, request.Id)
+	return openapi.UpdateAnomalySettings200JSONResponse(mapAnomalySettings(&se.AnomalyDetection)), nil
+}
 
-	return openapi.DeleteUser204Response{}, nil
+func mapAnomalySettings(config *settings.AnomalyDetection) openapi.AnomalySettings {
+	return openapi.AnomalySettings{
+		Enabled:      config.Enabled,
+		TicketType:   config.TicketType,
+		Sensitivity:  float32(cmp.Or(config.Sensitivity, anomaly.DefaultSensitivity)),
+		MinCount:     cmp.Or(config.MinCount, anomaly.DefaultMinCount),
+		SilenceHours: cmp.Or(config.SilenceHours, anomaly.DefaultSilenceHours),
+	}
 }
 
-func (s *Service) GetUser(ctx context.Context, request openapi.GetUserRequestObject) (openapi.GetUserResponseObject, error) {
-	user, err := s.queries.GetUser(ctx, request.Id)
+func (s *Service) GetPlatformTicketSettings(ctx context.Context, _ openapi.GetPlatformTicketSettingsRequestObject) (openapi.GetPlatformTicketSettingsResponseObject, error) {
+	se, err := settings.Load(ctx, s.queries)
 	if err != nil {
 		return nil, err
 	}
 
-	response := openapi.User{
-		Avatar:                 user.Avatar,
-		Created:                user.Created,
-		Email:                  user.Email,
-		Id:                     user.ID,
-		LastResetSentAt:        user.Lastresetsentat,
-		LastVerificationSentAt: user.Lastverificationsentat,
-		Name:                   user.Name,
-		Updated:                user.Updated,
-		Username:               user.Username,
-		Active:                 user.Active,
-	}
-
-	s.hooks.OnRecordViewRequest.Publish(ctx, database.UsersTable.ID, response)
-
-	return openapi.GetUser200JSONResponse(response), nil
+	return openapi.GetPlatformTicketSettings200JSONResponse(mapPlatformTicketSettings(&se.PlatformTickets)), nil
 }
 
-func (s *Service) UpdateUser(ctx context.Context, request openapi.UpdateUserRequestObject) (openapi.UpdateUserResponseObject, error) {
-	s.hooks.OnRecordBeforeUpdateRequest.Publish(ctx, database.UsersTable.ID, request.Body)
-
-	var passwordHash, tokenHash *string
-
-	switch {
-	case request.Body.Password == nil && request.Body.PasswordConfirm == nil:
-	case request.Body.Password != nil && request.Body.PasswordConfirm != nil:
-		if *request.Body.Password != *request.Body.PasswordConfirm {
-			return nil, errors.New("passwords do not match")
-		}
-
-		passwordHashS, tokenHashS, err := password.Hash(*request.Body.Password)
-		if err != nil {
-			return nil, fmt.Errorf("failed to hash password: %w", err)
+func (s *Service) UpdatePlatformTicketSettings(ctx context.Context, request openapi.UpdatePlatformTicketSettingsRequestObject) (openapi.UpdatePlatformTicketSettingsResponseObject, error) {
+	if request.Body.Enabled {
+		ticketType := cmp.Or(request.Body.TicketType, platform.DefaultTicketType)
+		if _, err := s.queries.GetType(ctx, ticketType); err != nil {
+			return nil, fmt.Errorf("invalid platform ticket type %q: %w", ticketType, err)
 		}
-
-		passwordHash = &passwordHashS
-		tokenHash = &tokenHashS
-	default:
-		return nil, errors.New("password and password confirm must be provided together")
 	}
 
-	user, err := s.queries.UpdateUser(ctx, sqlc.UpdateUserParams{
-		Name:         request.Body.Name,
-		Email:        request.Body.Email,
-		Username:     request.Body.Username,
-		PasswordHash: passwordHash,
-		TokenKey:     tokenHash,
-		Avatar:       request.Body.Avatar,
-		Active:       request.Body.Active,
-		ID:           request.Id,
+	se, err := settings.Update(ctx, s.queries, func(settings *settings.Settings) {
+		settings.PlatformTickets.Enabled = request.Body.Enabled
+		settings.PlatformTickets.TicketType = request.Body.TicketType
 	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to save platform ticket settings: %w", err)
+	}
+
+	return openapi.UpdatePlatformTicketSettings200JSONResponse(mapPlatformTicketSettings(&se.PlatformTickets)), nil
+}
+
+func mapPlatformTicketSettings(config *settings.PlatformTickets) openapi.PlatformTicketSettings {
+	return openapi.PlatformTicketSettings{
+		Enabled:    config.Enabled,
+		TicketType: cmp.Or(config.TicketType, platform.DefaultTicketType),
+	}
+}
+
+func (s *Service) GetPasswordPolicy(ctx context.Context, _ openapi.GetPasswordPolicyRequestObject) (openapi.GetPasswordPolicyResponseObject, error) {
+	se, err := settings.Load(ctx, s.queries)
 	if err != nil {
 		return nil, err
 	}
 
-	response := openapi.User{
-		Avatar:                 user.Avatar,
-		Created:                user.Created,
-		Email:                  user.Email,
-		Id:                     user.ID,
-		LastResetSentAt:        user.Lastresetsentat,
-		LastVerificationSentAt: user.Lastverificationsentat,
-		Name:                   user.Name,
-		Updated:                user.Updated,
-		Username:               user.Username,
-		Active:                 user.Active,
+	return openapi.GetPasswordPolicy200JSONResponse(mapPasswordPolicy(&se.PasswordPolicy)), nil
+}
+
+func (s *Service) UpdatePasswordPolicy(ctx context.Context, request openapi.UpdatePasswordPolicyRequestObject) (openapi.UpdatePasswordPolicyResponseObject, error) {
+	policy := settings.PasswordPolicy{
+		MinLength:        request.Body.MinLength,
+		RequireUppercase: request.Body.RequireUppercase,
+		RequireLowercase: request.Body.RequireLowercase,
+		RequireDigit:     request.Body.RequireDigit,
+		RequireSymbol:    request.Body.RequireSymbol,
+		History:          request.Body.History,
+		MaxAge:           request.Body.MaxAge,
+		BreachCheck:      request.Body.BreachCheck,
+		BreachCheckURL:   request.Body.BreachCheckUrl,
 	}
 
-	s.hooks.OnRecordAfterUpdateRequest.Publish(ctx, "users", response)
+	if err := settings.ValidatePasswordPolicy(policy); err != nil {
+		return openapi.UpdatePasswordPolicy400JSONResponse{Status: http.StatusBadRequest, Error: "Bad Request", Message: err.Error()}, nil
+	}
 
-	return openapi.UpdateUser200JSONResponse(response), nil
+	se, err := settings.Update(ctx, s.queries, func(settings *settings.Settings) {
+		settings.PasswordPolicy = policy
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to save password policy: %w", err)
+	}
+
+	return openapi.UpdatePasswordPolicy200JSONResponse(mapPasswordPolicy(&se.PasswordPolicy)), nil
 }
 
-func (s *Service) ListGroups(ctx context.Context, request openapi.ListGroupsRequestObject) (openapi.ListGroupsResponseObject, error) {
-	groups, err := s.queries.ListGroups(ctx, sqlc.ListGroupsParams{
+func mapPasswordPolicy(policy *settings.PasswordPolicy) openapi.PasswordPolicy {
+	return openapi.PasswordPolicy{
+		MinLength:        cmp.Or(policy.MinLength, password.DefaultMinLength),
+		RequireUppercase: policy.RequireUppercase,
+		RequireLowercase: policy.RequireLowercase,
+		RequireDigit:     policy.RequireDigit,
+		RequireSymbol:    policy.RequireSymbol,
+		History:          policy.History,
+		MaxAge:           policy.MaxAge,
+		BreachCheck:      policy.BreachCheck,
+		BreachCheckUrl:   cmp.Or(policy.BreachCheckURL, password.DefaultBreachCheckURL),
+	}
+}
+
+func (s *Service) ListInvitations(ctx context.Context, request openapi.ListInvitationsRequestObject) (openapi.ListInvitationsResponseObject, error) {
+	invitations, err := s.queries.ListInvitations(ctx, sqlc.ListInvitationsParams{
 		Offset: toInt64(request.Params.Offset, defaultOffset),
 		Limit:  toInt64(request.Params.Limit, defaultLimit),
 	})
@@ -1371,431 +9446,615 @@ func (s *Service) ListGroups(ctx context.Context, request openapi.ListGroupsRequ
 		return nil, err
 	}
 
-	response := make([]openapi.Group, 0, len(groups))
-
-	for _, group := range groups {
-		response = append(response, openapi.Group{
-			Created:     group.Created,
-			Id:          group.ID,
-			Name:        group.Name,
-			Permissions: auth.FromJSONArray(ctx, group.Permissions),
-			Updated:     group.Updated,
-		})
+	response := make([]openapi.Invitation, 0, len(invitations))
+	for _, i := range invitations {
+		response = append(response, mapInvitation(&sqlc.Invitation{
+			ID:         i.ID,
+			Email:      i.Email,
+			Name:       i.Name,
+			Groups:     i.Groups,
+			InvitedBy:  i.InvitedBy,
+			Expires:    i.Expires,
+			AcceptedBy: i.AcceptedBy,
+			Created:    i.Created,
+			Updated:    i.Updated,
+		}))
 	}
 
-	s.hooks.OnRecordsListRequest.Publish(ctx, database.GroupsTable.ID, response)
-
 	totalCount := 0
-	if len(groups) > 0 {
-		totalCount = int(groups[0].TotalCount)
+	if len(invitations) > 0 {
+		totalCount = int(invitations[0].TotalCount)
 	}
 
-	return openapi.ListGroups200JSONResponse{
+	return openapi.ListInvitations200JSONResponse{
 		Body: response,
-		Headers: openapi.ListGroups200ResponseHeaders{
+		Headers: openapi.ListInvitations200ResponseHeaders{
 			XTotalCount: totalCount,
 		},
 	}, nil
 }
 
-func (s *Service) CreateGroup(ctx context.Context, request openapi.CreateGroupRequestObject) (openapi.CreateGroupResponseObject, error) {
-	s.hooks.OnRecordBeforeCreateRequest.Publish(ctx, database.GroupsTable.ID, request.Body)
+func (s *Service) CreateInvitation(ctx context.Context, request openapi.CreateInvitationRequestObject) (openapi.CreateInvitationResponseObject, error) {
+	var invitedBy string
+	if user, ok := usercontext.UserFromContext(ctx); ok {
+		invitedBy = user.ID
+	}
 
-	group, err := s.queries.CreateGroup(ctx, sqlc.CreateGroupParams{
-		Name:        request.Body.Name,
-		Permissions: auth.ToJSONArray(ctx, request.Body.Permissions),
-	})
+	sent, err := invitation.Create(ctx, s.queries, mail.New(s.queries), request.Body.Email, toString(request.Body.Name, ""), pointer.Dereference(request.Body.Groups), invitedBy)
 	if err != nil {
-		return nil, err
-	}
+		switch {
+		case errors.Is(err, invitation.ErrEmail), errors.Is(err, invitation.ErrGroup):
+			return openapi.CreateInvitation400JSONResponse{Status: http.StatusBadRequest, Error: "Bad Request", Message: err.Error()}, nil
+		case errors.Is(err, invitation.ErrUserExists), errors.Is(err, invitation.ErrPending):
+			return openapi.CreateInvitation409JSONResponse{Status: http.StatusConflict, Error: "Conflict", Message: err.Error()}, nil
+		}
 
-	response := openapi.Group{
-		Created:     group.Created,
-		Id:          group.ID,
-		Name:        group.Name,
-		Permissions: auth.FromJSONArray(ctx, group.Permissions),
-		Updated:     group.Updated,
+		return nil, err
 	}
 
-	s.hooks.OnRecordAfterCreateRequest.Publish(ctx, database.GroupsTable.ID, response)
-
-	return openapi.CreateGroup200JSONResponse(response), nil
+	return openapi.CreateInvitation200JSONResponse(mapSentInvitation(sent)), nil
 }
 
-func (s *Service) DeleteGroup(ctx context.Context, request openapi.DeleteGroupRequestObject) (openapi.DeleteGroupResponseObject, error) {
-	s.hooks.OnRecordBeforeDeleteRequest.Publish(ctx, database.GroupsTable.ID, request.Id)
-
-	if request.Id == "admin" {
-		return nil, errors.New("cannot delete the admin group")
-	}
-
-	err := s.queries.DeleteGroup(ctx, request.Id)
+func (s *Service) ResendInvitation(ctx context.Context, request openapi.ResendInvitationRequestObject) (openapi.ResendInvitationResponseObject, error) {
+	sent, err := invitation.Resend(ctx, s.queries, mail.New(s.queries), request.Id)
 	if err != nil {
+		if errors.Is(err, invitation.ErrInvalid) {
+			return openapi.ResendInvitation404JSONResponse{Status: http.StatusNotFound, Error: "Not Found", Message: "The invitation does not exist or is accepted"}, nil
+		}
+
 		return nil, err
 	}
 
-	s.hooks.OnRecordAfterDeleteRequest.Publish(ctx, database.GroupsTable.ID, request.Id)
-
-	return openapi.DeleteGroup204Response{}, nil
+	return openapi.ResendInvitation200JSONResponse(mapSentInvitation(sent)), nil
 }
 
-func (s *Service) GetGroup(ctx context.Context, request openapi.GetGroupRequestObject) (openapi.GetGroupResponseObject, error) {
-	group, err := s.queries.GetGroup(ctx, request.Id)
-	if err != nil {
+func (s *Service) DeleteInvitation(ctx context.Context, request openapi.DeleteInvitationRequestObject) (openapi.DeleteInvitationResponseObject, error) {
+	if err := s.queries.DeleteInvitation(ctx, request.Id); err != nil {
 		return nil, err
 	}
 
-	response := openapi.Group{
-		Created:     group.Created,
-		Id:          group.ID,
-		Name:        group.Name,
-		Permissions: auth.FromJSONArray(ctx, group.Permissions),
-		Updated:     group.Updated,
-	}
+	return openapi.DeleteInvitation204Response{}, nil
+}
 
-	s.hooks.OnRecordViewRequest.Publish(ctx, database.GroupsTable.ID, response)
+func mapInvitation(i *sqlc.Invitation) openapi.Invitation {
+	return openapi.Invitation{
+		Id:         i.ID,
+		Email:      i.Email,
+		Name:       i.Name,
+		Groups:     invitation.Groups(i.Groups),
+		InvitedBy:  i.InvitedBy,
+		Expires:    i.Expires,
+		AcceptedBy: i.AcceptedBy,
+		Created:    i.Created,
+		Updated:    i.Updated,
+	}
+}
 
-	return openapi.GetGroup200JSONResponse(response), nil
+func mapSentInvitation(sent *invitation.Sent) openapi.SentInvitation {
+	return openapi.SentInvitation{
+		Invitation: mapInvitation(&sent.Invitation),
+		Link:       sent.Link,
+		MailSent:   sent.Mailed,
+	}
 }
 
-func (s *Service) UpdateGroup(ctx context.Context, request openapi.UpdateGroupRequestObject) (openapi.UpdateGroupResponseObject, error) {
-	s.hooks.OnRecordBeforeUpdateRequest.Publish(ctx, database.GroupsTable.ID, request.Body)
+func (s *Service) ListLogs(ctx context.Context, request openapi.ListLogsRequestObject) (openapi.ListLogsResponseObject, error) {
+	params := sqlc.ListLogsParams{
+		Component: request.Params.Component,
+		User:      request.Params.User,
+		Query:     request.Params.Query,
+		Offset:    toInt64(request.Params.Offset, defaultOffset),
+		Limit:     toInt64(request.Params.Limit, defaultLimit),
+	}
 
-	if request.Id == "admin" {
-		return nil, errors.New("cannot update the admin group")
+	if request.Params.Level != nil {
+		level, err := applog.ParseLevel(string(*request.Params.Level))
+		if err != nil {
+			return openapi.ListLogs400JSONResponse{Status: http.StatusBadRequest, Error: "Bad Request", Message: err.Error()}, nil
+		}
+
+		params.Level = int64(level)
 	}
 
-	var permissions *string
+	if request.Params.Since != nil {
+		params.Since = request.Params.Since.UTC()
+	}
 
-	if request.Body.Permissions != nil {
-		p := auth.ToJSONArray(ctx, *request.Body.Permissions)
-		permissions = &p
+	if request.Params.Until != nil {
+		params.Until = request.Params.Until.UTC()
 	}
 
-	group, err := s.queries.UpdateGroup(ctx, sqlc.UpdateGroupParams{
-		Name:        request.Body.Name,
-		Permissions: permissions,
-		ID:          request.Id,
-	})
+	logs, err := s.queries.ListLogs(ctx, params)
 	if err != nil {
 		return nil, err
 	}
 
-	response := openapi.Group{
-		Created:     group.Created,
-		Id:          group.ID,
-		Name:        group.Name,
-		Permissions: auth.FromJSONArray(ctx, group.Permissions),
-		Updated:     group.Updated,
+	response := make([]openapi.LogEntry, 0, len(logs))
+	for _, l := range logs {
+		response = append(response, openapi.LogEntry{
+			Id:        l.ID,
+			Level:     applog.LevelName(slog.Level(l.Level)),
+			Message:   l.Message,
+			Component: l.Component,
+			User:      l.User,
+			Attrs:     unmarshal(l.Attrs),
+			Created:   l.Created,
+		})
 	}
 
-	s.hooks.OnRecordAfterUpdateRequest.Publish(ctx, database.GroupsTable.ID, response)
+	totalCount := 0
+	if len(logs) > 0 {
+		totalCount = int(logs[0].TotalCount)
+	}
 
-	return openapi.UpdateGroup200JSONResponse(response), nil
+	return openapi.ListLogs200JSONResponse{
+		Body: response,
+		Headers: openapi.ListLogs200ResponseHeaders{
+			XTotalCount: totalCount,
+		},
+	}, nil
 }
 
-func (s *Service) AddGroupParent(ctx context.Context, request openapi.AddGroupParentRequestObject) (openapi.AddGroupParentResponseObject, error) {
-	s.hooks.OnRecordBeforeCreateRequest.Publish(ctx, database.GroupParentTable.ID, request.Body)
-
-	err := s.queries.AssignParentGroup(ctx, sqlc.AssignParentGroupParams{
-		ChildGroupID:  request.Id,
-		ParentGroupID: request.Body.GroupId,
-	})
+func (s *Service) GetLogSettings(ctx context.Context, _ openapi.GetLogSettingsRequestObject) (openapi.GetLogSettingsResponseObject, error) {
+	se, err := settings.Load(ctx, s.queries)
 	if err != nil {
 		return nil, err
 	}
 
-	s.hooks.OnRecordAfterCreateRequest.Publish(ctx, database.GroupParentTable.ID, request.Body)
-
-	return openapi.AddGroupParent201Response{}, nil
+	return openapi.GetLogSettings200JSONResponse(mapLogSettings(&se.Logs)), nil
 }
 
-func (s *Service) RemoveGroupParent(ctx context.Context, request openapi.RemoveGroupParentRequestObject) (openapi.RemoveGroupParentResponseObject, error) {
-	s.hooks.OnRecordBeforeDeleteRequest.Publish(ctx, database.GroupParentTable.ID, request.Id)
+func (s *Service) UpdateLogSettings(ctx context.Context, request openapi.UpdateLogSettingsRequestObject) (openapi.UpdateLogSettingsResponseObject, error) {
+	config := settings.Logs{
+		Level:         string(request.Body.Level),
+		RetentionDays: request.Body.RetentionDays,
+	}
 
-	err := s.queries.RemoveParentGroup(ctx, sqlc.RemoveParentGroupParams{
-		ChildGroupID:  request.Id,
-		ParentGroupID: request.ParentGroupId,
+	if err := settings.ValidateLogs(config); err != nil {
+		return openapi.UpdateLogSettings400JSONResponse{Status: http.StatusBadRequest, Error: "Bad Request", Message: err.Error()}, nil
+	}
+
+	se, err := settings.Update(ctx, s.queries, func(settings *settings.Settings) {
+		settings.Logs = config
 	})
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("failed to save log settings: %w", err)
 	}
 
-	s.hooks.OnRecordAfterDeleteRequest.Publish(ctx, database.GroupParentTable.ID, request.Id)
-
-	return openapi.RemoveGroupParent204Response{}, nil
+	return openapi.UpdateLogSettings200JSONResponse(mapLogSettings(&se.Logs)), nil
 }
 
-func (s *Service) AddUserGroup(ctx context.Context, request openapi.AddUserGroupRequestObject) (openapi.AddUserGroupResponseObject, error) {
-	s.hooks.OnRecordBeforeCreateRequest.Publish(ctx, database.UserGroupTable.ID, request.Body)
-
-	err := s.queries.AssignGroupToUser(ctx, sqlc.AssignGroupToUserParams{
-		UserID:  request.Id,
-		GroupID: request.Body.GroupId,
-	})
+func (s *Service) GetLogSinks(ctx context.Context, _ openapi.GetLogSinksRequestObject) (openapi.GetLogSinksResponseObject, error) {
+	se, err := settings.Load(ctx, s.queries)
 	if err != nil {
 		return nil, err
 	}
 
-	s.hooks.OnRecordAfterCreateRequest.Publish(ctx, database.UserGroupTable.ID, request.Body)
-
-	return openapi.AddUserGroup201Response{}, nil
+	return openapi.GetLogSinks200JSONResponse(mapLogSinks(se.LogSinks)), nil
 }
 
-func (s *Service) RemoveUserGroup(ctx context.Context, request openapi.RemoveUserGroupRequestObject) (openapi.RemoveUserGroupResponseObject, error) {
-	s.hooks.OnRecordBeforeDeleteRequest.Publish(ctx, database.UserGroupTable.ID, request.Id)
+func (s *Service) UpdateLogSinks(ctx context.Context, request openapi.UpdateLogSinksRequestObject) (openapi.UpdateLogSinksResponseObject, error) {
+	sinks := make([]settings.LogSink, 0, len(*request.Body))
+	for _, sink := range *request.Body {
+		streams := make([]string, 0, len(sink.Streams))
+		for _, stream := range sink.Streams {
+			streams = append(streams, string(stream))
+		}
 
-	err := s.queries.RemoveGroupFromUser(ctx, sqlc.RemoveGroupFromUserParams{
-		UserID:  request.Id,
-		GroupID: request.GroupId,
+		sinks = append(sinks, settings.LogSink{
+			Name:      sink.Name,
+			Type:      string(sink.Type),
+			Streams:   streams,
+			Level:     string(pointer.Dereference(sink.Level)),
+			Address:   pointer.Dereference(sink.Address),
+			CA:        pointer.Dereference(sink.Ca),
+			URL:       pointer.Dereference(sink.Url),
+			Headers:   pointer.Dereference(sink.Headers),
+			MaxSizeMB: pointer.Dereference(sink.MaxSizeMb),
+			MaxFiles:  pointer.Dereference(sink.MaxFiles),
+		})
+	}
+
+	if err := settings.ValidateLogSinks(sinks); err != nil {
+		return openapi.UpdateLogSinks400JSONResponse{Status: http.StatusBadRequest, Error: "Bad Request", Message: err.Error()}, nil
+	}
+
+	se, err := settings.Update(ctx, s.queries, func(settings *settings.Settings) {
+		// the redacted values from GetLogSinks keep the stored header values
+		// of the sink with the same name
+		for i := range sinks {
+			for key, value := range sinks[i].Headers {
+				if value != redacted {
+					continue
+				}
+
+				for _, stored := range settings.LogSinks {
+					if stored.Name == sinks[i].Name {
+						sinks[i].Headers[key] = stored.Headers[key]
+					}
+				}
+			}
+		}
+
+		settings.LogSinks = sinks
 	})
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("failed to save log sinks: %w", err)
 	}
 
-	s.hooks.OnRecordAfterDeleteRequest.Publish(ctx, database.UserGroupTable.ID, request.Id)
+	return openapi.UpdateLogSinks200JSONResponse(mapLogSinks(se.LogSinks)), nil
+}
 
-	return openapi.RemoveUserGroup204Response{}, nil
+func mapLogSinks(sinks []settings.LogSink) []openapi.LogSink {
+	logSinks := make([]openapi.LogSink, 0, len(sinks))
+	for _, sink := range sinks {
+		streams := make([]openapi.LogSinkStreams, 0, len(sink.Streams))
+		for _, stream := range sink.Streams {
+			streams = append(streams, openapi.LogSinkStreams(stream))
+		}
+
+		logSink := openapi.LogSink{
+			Name:    sink.Name,
+			Type:    openapi.LogSinkType(sink.Type),
+			Streams: streams,
+		}
+
+		if sink.Level != "" {
+			logSink.Level = pointer.Pointer(openapi.LogSinkLevel(sink.Level))
+		}
+
+		if sink.Address != "" {
+			logSink.Address = &sink.Address
+		}
+
+		if sink.CA != "" {
+			logSink.Ca = &sink.CA
+		}
+
+		if sink.URL != "" {
+			logSink.Url = &sink.URL
+		}
+
+		if len(sink.Headers) > 0 {
+			headers := make(map[string]string, len(sink.Headers))
+			for key := range sink.Headers {
+				headers[key] = redacted
+			}
+
+			logSink.Headers = &headers
+		}
+
+		if sink.MaxSizeMB > 0 {
+			logSink.MaxSizeMb = &sink.MaxSizeMB
+		}
+
+		if sink.MaxFiles > 0 {
+			logSink.MaxFiles = &sink.MaxFiles
+		}
+
+		logSinks = append(logSinks, logSink)
+	}
+
+	return logSinks
 }
 
-func (s *Service) ListUserPermissions(ctx context.Context, request openapi.ListUserPermissionsRequestObject) (openapi.ListUserPermissionsResponseObject, error) {
-	permissions, err := s.queries.ListUserPermissions(ctx, request.Id)
+// mapLogSettings returns the log settings with the defaults for zero values.
+func mapLogSettings(config *settings.Logs) openapi.LogSettings {
+	level, err := applog.ParseLevel(config.Level)
 	if err != nil {
-		return nil, err
+		level = applog.DefaultLevel
 	}
 
-	s.hooks.OnRecordsListRequest.Publish(ctx, database.UserPermissionTable.ID, permissions)
+	retentionDays := config.RetentionDays
+	if retentionDays <= 0 {
+		retentionDays = applog.DefaultRetentionDays
+	}
 
-	return openapi.ListUserPermissions200JSONResponse(permissions), nil
+	return openapi.LogSettings{
+		Level:         openapi.LogSettingsLevel(applog.LevelName(level)),
+		RetentionDays: retentionDays,
+	}
 }
 
-func (s *Service) ListUserGroups(ctx context.Context, request openapi.ListUserGroupsRequestObject) (openapi.ListUserGroupsResponseObject, error) {
-	groups, err := s.queries.ListUserGroups(ctx, request.Id)
+func (s *Service) GetCorsSettings(ctx context.Context, _ openapi.GetCorsSettingsRequestObject) (openapi.GetCorsSettingsResponseObject, error) {
+	se, err := settings.Load(ctx, s.queries)
 	if err != nil {
 		return nil, err
 	}
 
-	response := make([]openapi.UserGroup, 0, len(groups))
-	for _, group := range groups {
-		response = append(response, openapi.UserGroup{
-			Created:     group.Created,
-			Id:          group.ID,
-			Name:        group.Name,
-			Permissions: auth.FromJSONArray(ctx, group.Permissions),
-			Type:        group.GroupType,
-			Updated:     group.Updated,
-		})
-	}
+	return openapi.GetCorsSettings200JSONResponse(mapCorsSettings(&se.CORS)), nil
+}
 
-	s.hooks.OnRecordsListRequest.Publish(ctx, database.UserGroupTable.ID, response)
+func (s *Service) UpdateCorsSettings(ctx context.Context, request openapi.UpdateCorsSettingsRequestObject) (openapi.UpdateCorsSettingsResponseObject, error) {
+	config := settings.CORS{
+		AllowedOrigins:   request.Body.AllowedOrigins,
+		AllowedHeaders:   request.Body.AllowedHeaders,
+		AllowCredentials: request.Body.AllowCredentials,
+		MaxAge:           request.Body.MaxAge,
+	}
 
-	return openapi.ListUserGroups200JSONResponse(response), nil
-}
+	if err := settings.ValidateCORS(config); err != nil {
+		return openapi.UpdateCorsSettings400JSONResponse{Status: http.StatusBadRequest, Error: "Bad Request", Message: err.Error()}, nil
+	}
 
-func (s *Service) ListGroupUsers(ctx context.Context, request openapi.ListGroupUsersRequestObject) (openapi.ListGroupUsersResponseObject, error) {
-	users, err := s.queries.ListGroupUsers(ctx, request.Id)
+	se, err := settings.Update(ctx, s.queries, func(settings *settings.Settings) {
+		settings.CORS = config
+	})
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("failed to save CORS settings: %w", err)
 	}
 
-	response := make([]openapi.GroupUser, 0, len(users))
-	for _, user := range users {
-		response = append(response, openapi.GroupUser{
-			Avatar:                 user.Avatar,
-			Created:                user.Created,
-			Email:                  user.Email,
-			Id:                     user.ID,
-			LastResetSentAt:        user.Lastresetsentat,
-			LastVerificationSentAt: user.Lastverificationsentat,
-			Name:                   user.Name,
-			Updated:                user.Updated,
-			Username:               user.Username,
-			Active:                 user.Active,
-			Type:                   user.GroupType,
-		})
+	return openapi.UpdateCorsSettings200JSONResponse(mapCorsSettings(&se.CORS)), nil
+}
+
+func mapCorsSettings(config *settings.CORS) openapi.CorsSettings {
+	return openapi.CorsSettings{
+		AllowedOrigins:   append([]string{}, config.AllowedOrigins...),
+		AllowedHeaders:   append([]string{}, config.AllowedHeaders...),
+		AllowCredentials: config.AllowCredentials,
+		MaxAge:           config.MaxAge,
 	}
+}
 
-	s.hooks.OnRecordsListRequest.Publish(ctx, database.GroupUserTable.ID, response)
+var errNotCached = openapi.Error{
+	Status:  http.StatusNotFound,
+	Error:   "Not Found",
+	Message: "The enrichment result is not cached",
+}
+
+func (s *Service) GetEnrichment(ctx context.Context, request openapi.GetEnrichmentRequestObject) (openapi.GetEnrichmentResponseObject, error) {
+	entry, err := enrichment.New(s.queries).Get(ctx, request.Enricher, request.Params.Value)
+	if errors.Is(err, enrichment.ErrNotCached) {
+		return openapi.GetEnrichment404JSONResponse(errNotCached), nil
+	} else if err != nil {
+		return nil, err
+	}
 
-	return openapi.ListGroupUsers200JSONResponse(response), nil
+	return openapi.GetEnrichment200JSONResponse(mapEnrichment(entry)), nil
 }
 
-func (s *Service) ListParentPermissions(ctx context.Context, request openapi.ListParentPermissionsRequestObject) (openapi.ListParentPermissionsResponseObject, error) {
-	permissions, err := s.queries.ListParentPermissions(ctx, request.Id)
+func (s *Service) SetEnrichment(ctx context.Context, request openapi.SetEnrichmentRequestObject) (openapi.SetEnrichmentResponseObject, error) {
+	result, err := json.Marshal(request.Body.Result)
 	if err != nil {
 		return nil, err
 	}
 
-	s.hooks.OnRecordsListRequest.Publish(ctx, database.GroupPermissionTable.ID, permissions)
+	entry, err := enrichment.New(s.queries).Set(ctx, request.Enricher, request.Body.Value, result)
+	if err != nil {
+		return nil, err
+	}
 
-	return openapi.ListParentPermissions200JSONResponse(permissions), nil
+	return openapi.SetEnrichment200JSONResponse(mapEnrichment(entry)), nil
 }
 
-func (s *Service) ListParentGroups(ctx context.Context, request openapi.ListParentGroupsRequestObject) (openapi.ListParentGroupsResponseObject, error) {
-	groups, err := s.queries.ListParentGroups(ctx, request.Id)
+func mapEnrichment(entry *enrichment.Entry) openapi.Enrichment {
+	return openapi.Enrichment{
+		Enricher: entry.Enricher,
+		Value:    entry.Value,
+		Result:   entry.Result,
+		Expires:  entry.Expires,
+	}
+}
+
+func (s *Service) GetEnrichmentSettings(ctx context.Context, _ openapi.GetEnrichmentSettingsRequestObject) (openapi.GetEnrichmentSettingsResponseObject, error) {
+	se, err := settings.Load(ctx, s.queries)
 	if err != nil {
 		return nil, err
 	}
 
-	response := make([]openapi.UserGroup, 0, len(groups))
-	for _, group := range groups {
-		response = append(response, openapi.UserGroup{
-			Created:     group.Created,
-			Id:          group.ID,
-			Name:        group.Name,
-			Permissions: auth.FromJSONArray(ctx, group.Permissions),
-			Type:        group.GroupType,
-			Updated:     group.Updated,
-		})
+	return openapi.GetEnrichmentSettings200JSONResponse(mapEnrichmentSettings(&se.EnrichmentCache)), nil
+}
+
+func (s *Service) UpdateEnrichmentSettings(ctx context.Context, request openapi.UpdateEnrichmentSettingsRequestObject) (openapi.UpdateEnrichmentSettingsResponseObject, error) {
+	if request.Body.DefaultTtl < 0 {
+		return nil, errors.New("the default TTL must not be negative")
 	}
 
-	s.hooks.OnRecordsListRequest.Publish(ctx, database.GroupParentTable.ID, response)
+	for enricher, ttl := range request.Body.Ttls {
+		if ttl <= 0 {
+			return nil, fmt.Errorf("the TTL of %s must be positive", enricher)
+		}
+	}
 
-	return openapi.ListParentGroups200JSONResponse(response), nil
+	se, err := settings.Update(ctx, s.queries, func(settings *settings.Settings) {
+		settings.EnrichmentCache.DefaultTTL = request.Body.DefaultTtl
+		settings.EnrichmentCache.TTLs = request.Body.Ttls
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to save enrichment cache settings: %w", err)
+	}
+
+	return openapi.UpdateEnrichmentSettings200JSONResponse(mapEnrichmentSettings(&se.EnrichmentCache)), nil
 }
 
-func (s *Service) ListChildGroups(ctx context.Context, request openapi.ListChildGroupsRequestObject) (openapi.ListChildGroupsResponseObject, error) {
-	groups, err := s.queries.ListChildGroups(ctx, request.Id)
+func mapEnrichmentSettings(config *settings.EnrichmentCache) openapi.EnrichmentSettings {
+	ttls := map[string]int{}
+	maps.Copy(ttls, config.TTLs)
+
+	return openapi.EnrichmentSettings{
+		DefaultTtl: cmp.Or(config.DefaultTTL, int(enrichment.DefaultTTL.Seconds())),
+		Ttls:       ttls,
+	}
+}
+
+func (s *Service) GetRateLimits(ctx context.Context, _ openapi.GetRateLimitsRequestObject) (openapi.GetRateLimitsResponseObject, error) {
+	se, err := settings.Load(ctx, s.queries)
 	if err != nil {
 		return nil, err
 	}
 
-	response := make([]openapi.UserGroup, 0, len(groups))
-	for _, group := range groups {
-		response = append(response, openapi.UserGroup{
-			Created:     group.Created,
-			Id:          group.ID,
-			Name:        group.Name,
-			Permissions: auth.FromJSONArray(ctx, group.Permissions),
-			Type:        group.GroupType,
-			Updated:     group.Updated,
+	return openapi.GetRateLimits200JSONResponse(mapRateLimits(se.RateLimits)), nil
+}
+
+func (s *Service) UpdateRateLimits(ctx context.Context, request openapi.UpdateRateLimitsRequestObject) (openapi.UpdateRateLimitsResponseObject, error) {
+	limits := make([]settings.RateLimit, 0, len(*request.Body))
+	for _, limit := range *request.Body {
+		limits = append(limits, settings.RateLimit{
+			Host:     pointer.Dereference(limit.Host),
+			Reaction: pointer.Dereference(limit.Reaction),
+			Requests: limit.Requests,
+			Period:   limit.Period,
 		})
 	}
 
-	s.hooks.OnRecordsListRequest.Publish(ctx, database.GroupChildTable.ID, response)
-
-	return openapi.ListChildGroups200JSONResponse(response), nil
-}
+	if err := settings.ValidateRateLimits(limits); err != nil {
+		return nil, err
+	}
 
-func (s *Service) ListWebhooks(ctx context.Context, request openapi.ListWebhooksRequestObject) (openapi.ListWebhooksResponseObject, error) {
-	webhooks, err := s.queries.ListWebhooks(ctx, sqlc.ListWebhooksParams{
-		Offset: toInt64(request.Params.Offset, defaultOffset),
-		Limit:  toInt64(request.Params.Limit, defaultLimit),
+	se, err := settings.Update(ctx, s.queries, func(settings *settings.Settings) {
+		settings.RateLimits = limits
 	})
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("failed to save rate limits: %w", err)
 	}
 
-	response := make([]openapi.Webhook, 0, len(webhooks))
-	for _, webhook := range webhooks {
-		response = append(response, openapi.Webhook{
-			Id:   webhook.ID,
-			Name: webhook.Name,
-		})
-	}
+	return openapi.UpdateRateLimits200JSONResponse(mapRateLimits(se.RateLimits)), nil
+}
 
-	s.hooks.OnRecordsListRequest.Publish(ctx, database.WebhooksTable.ID, response)
+func mapRateLimits(limits []settings.RateLimit) []openapi.RateLimit {
+	rateLimits := make([]openapi.RateLimit, 0, len(limits))
+	for _, limit := range limits {
+		rateLimit := openapi.RateLimit{Requests: limit.Requests, Period: limit.Period}
+		if limit.Host != "" {
+			rateLimit.Host = &limit.Host
+		}
 
-	totalCount := 0
-	if len(webhooks) > 0 {
-		totalCount = int(webhooks[0].TotalCount)
+		if limit.Reaction != "" {
+			rateLimit.Reaction = &limit.Reaction
+		}
+
+		rateLimits = append(rateLimits, rateLimit)
 	}
 
-	return openapi.ListWebhooks200JSONResponse{
-		Body: response,
-		Headers: openapi.ListWebhooks200ResponseHeaders{
-			XTotalCount: totalCount,
-		},
-	}, nil
+	return rateLimits
 }
 
-func (s *Service) CreateWebhook(ctx context.Context, request openapi.CreateWebhookRequestObject) (openapi.CreateWebhookResponseObject, error) {
-	s.hooks.OnRecordBeforeCreateRequest.Publish(ctx, database.WebhooksTable.ID, request.Body)
+func (s *Service) GetDigestSettings(ctx context.Context, _ openapi.GetDigestSettingsRequestObject) (openapi.GetDigestSettingsResponseObject, error) {
+	user, ok := usercontext.UserFromContext(ctx)
+	if !ok {
+		return nil, errors.New("missing user")
+	}
 
-	webhook, err := s.queries.CreateWebhook(ctx, sqlc.CreateWebhookParams{
-		Name:        request.Body.Name,
-		Destination: request.Body.Destination,
-		Collection:  request.Body.Collection,
-	})
+	d, err := s.queries.GetDigest(ctx, user.ID)
 	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return openapi.GetDigestSettings200JSONResponse{Frequency: openapi.Off}, nil
+		}
+
 		return nil, err
 	}
 
-	response := openapi.Webhook{
-		Id:          webhook.ID,
-		Name:        webhook.Name,
-		Created:     webhook.Created,
-		Updated:     webhook.Updated,
-		Destination: webhook.Destination,
-		Collection:  webhook.Collection,
+	return openapi.GetDigestSettings200JSONResponse{
+		Frequency: openapi.DigestSettingsFrequency(d.Frequency),
+		LastSent:  d.LastSent,
+	}, nil
+}
+
+func (s *Service) UpdateDigestSettings(ctx context.Context, request openapi.UpdateDigestSettingsRequestObject) (openapi.UpdateDigestSettingsResponseObject, error) {
+	user, ok := usercontext.UserFromContext(ctx)
+	if !ok {
+		return nil, errors.New("missing user")
 	}
 
-	s.hooks.OnRecordAfterCreateRequest.Publish(ctx, database.WebhooksTable.ID, response)
+	if !digest.ValidFrequency(string(request.Body.Frequency)) {
+		return nil, fmt.Errorf("invalid digest frequency %q", request.Body.Frequency)
+	}
 
-	return openapi.CreateWebhook200JSONResponse(response), nil
-}
+	d, err := s.queries.UpsertDigest(ctx, sqlc.UpsertDigestParams{
+		User:      user.ID,
+		Frequency: string(request.Body.Frequency),
+	})
+	if err != nil {
+		return nil, err
+	}
 
-func (s *Service) DeleteWebhook(ctx context.Context, request openapi.DeleteWebhookRequestObject) (openapi.DeleteWebhookResponseObject, error) {
-	s.hooks.OnRecordBeforeDeleteRequest.Publish(ctx, database.WebhooksTable.ID, request.Id)
+	return openapi.UpdateDigestSettings200JSONResponse{
+		Frequency: openapi.DigestSettingsFrequency(d.Frequency),
+		LastSent:  d.LastSent,
+	}, nil
+}
 
-	err := s.queries.DeleteWebhook(ctx, request.Id)
+func (s *Service) GetPushKey(ctx context.Context, _ openapi.GetPushKeyRequestObject) (openapi.GetPushKeyResponseObject, error) {
+	publicKey, err := push.PublicKey(ctx, s.queries)
 	if err != nil {
 		return nil, err
 	}
 
-	s.hooks.OnRecordAfterDeleteRequest.Publish(ctx, database.WebhooksTable.ID, request.Id)
-
-	return openapi.DeleteWebhook204Response{}, nil
+	return openapi.GetPushKey200JSONResponse{PublicKey: publicKey}, nil
 }
 
-func (s *Service) GetWebhook(ctx context.Context, request openapi.GetWebhookRequestObject) (openapi.GetWebhookResponseObject, error) {
-	webhook, err := s.queries.GetWebhook(ctx, request.Id)
+func (s *Service) ListPushSubscriptions(ctx context.Context, _ openapi.ListPushSubscriptionsRequestObject) (openapi.ListPushSubscriptionsResponseObject, error) {
+	user, ok := usercontext.UserFromContext(ctx)
+	if !ok {
+		return nil, errors.New("missing user")
+	}
+
+	subscriptions, err := s.queries.ListPushSubscriptions(ctx, user.ID)
 	if err != nil {
 		return nil, err
 	}
 
-	response := openapi.Webhook{
-		Id:          webhook.ID,
-		Name:        webhook.Name,
-		Created:     webhook.Created,
-		Updated:     webhook.Updated,
-		Destination: webhook.Destination,
-		Collection:  webhook.Collection,
+	response := make([]openapi.PushSubscription, 0, len(subscriptions))
+	for _, subscription := range subscriptions {
+		response = append(response, openapi.PushSubscription{
+			Id:       subscription.ID,
+			Endpoint: subscription.Endpoint,
+			Created:  subscription.Created,
+		})
 	}
 
-	s.hooks.OnRecordViewRequest.Publish(ctx, database.WebhooksTable.ID, response)
-
-	return openapi.GetWebhook200JSONResponse(response), nil
+	return openapi.ListPushSubscriptions200JSONResponse(response), nil
 }
 
-func (s *Service) UpdateWebhook(ctx context.Context, request openapi.UpdateWebhookRequestObject) (openapi.UpdateWebhookResponseObject, error) {
-	s.hooks.OnRecordBeforeUpdateRequest.Publish(ctx, database.WebhooksTable.ID, request.Body)
+func (s *Service) CreatePushSubscription(ctx context.Context, request openapi.CreatePushSubscriptionRequestObject) (openapi.CreatePushSubscriptionResponseObject, error) {
+	user, ok := usercontext.UserFromContext(ctx)
+	if !ok {
+		return nil, errors.New("missing user")
+	}
 
-	webhook, err := s.queries.UpdateWebhook(ctx, sqlc.UpdateWebhookParams{
-		ID:          request.Id,
-		Name:        request.Body.Name,
-		Destination: request.Body.Destination,
-		Collection:  request.Body.Collection,
+	if err := push.ValidateSubscription(request.Body.Endpoint, request.Body.Keys.P256dh, request.Body.Keys.Auth); err != nil {
+		return nil, err
+	}
+
+	subscription, err := s.queries.CreatePushSubscription(ctx, sqlc.CreatePushSubscriptionParams{
+		User:     user.ID,
+		Endpoint: request.Body.Endpoint,
+		P256dh:   request.Body.Keys.P256dh,
+		Auth:     request.Body.Keys.Auth,
 	})
 	if err != nil {
 		return nil, err
 	}
 
-	response := openapi.Webhook{
-		Id:          webhook.ID,
-		Name:        webhook.Name,
-		Created:     webhook.Created,
-		Updated:     webhook.Updated,
-		Destination: webhook.Destination,
-		Collection:  webhook.Collection,
+	return openapi.CreatePushSubscription200JSONResponse{
+		Id:       subscription.ID,
+		Endpoint: subscription.Endpoint,
+		Created:  subscription.Created,
+	}, nil
+}
+
+func (s *Service) DeletePushSubscription(ctx context.Context, request openapi.DeletePushSubscriptionRequestObject) (openapi.DeletePushSubscriptionResponseObject, error) {
+	user, ok := usercontext.UserFromContext(ctx)
+	if !ok {
+		return nil, errors.New("missing user")
 	}
 
-	s.hooks.OnRecordAfterUpdateRequest.Publish(ctx, database.WebhooksTable.ID, response)
+	if err := s.queries.DeletePushSubscription(ctx, sqlc.DeletePushSubscriptionParams{
+		ID:   request.Id,
+		User: user.ID,
+	}); err != nil {
+		return nil, err
+	}
 
-	return openapi.UpdateWebhook200JSONResponse(response), nil
+	return openapi.DeletePushSubscription204Response{}, nil
 }
 
-func (s *Service) GetConfig(ctx context.Context, _ openapi.GetConfigRequestObject) (openapi.GetConfigResponseObject, error) {
+func (s *Service) GetEffectiveSettings(ctx context.Context, _ openapi.GetEffectiveSettingsRequestObject) (openapi.GetEffectiveSettingsResponseObject, error) {
+	se, err := settings.Load(ctx, s.queries)
+	if err != nil {
+		return nil, err
+	}
+
 	flags := []string{}
 
 	features, err := database.PaginateItems(ctx, func(ctx context.Context, offset, limit int64) ([]sqlc.ListFeaturesRow, error) {
@@ -1809,52 +10068,144 @@ func (s *Service) GetConfig(ctx context.Context, _ openapi.GetConfigRequestObjec
 		flags = append(flags, feature.Key)
 	}
 
-	tables := []openapi.Table{}
-	for _, table := range database.Tables() {
-		tables = append(tables, openapi.Table{
-			Id:   table.ID,
-			Name: table.Name,
-		})
+	overrides := settings.EnvOverrides()
+	if overrides == nil {
+		overrides = []string{}
 	}
 
-	response := openapi.Config{
-		Flags:       flags,
-		Permissions: auth.All(),
-		Tables:      tables,
+	redactedSettings, err := settings.Redacted(se, redacted)
+	if err != nil {
+		return nil, err
 	}
 
-	return openapi.GetConfig200JSONResponse(response), nil
+	effective, err := json.Marshal(redactedSettings)
+	if err != nil {
+		return nil, err
+	}
+
+	return openapi.GetEffectiveSettings200JSONResponse(openapi.EffectiveSettings{
+		Settings:  unmarshal(effective),
+		Flags:     flags,
+		Overrides: overrides,
+	}), nil
 }
 
-func (s *Service) GetSettings(ctx context.Context, _ openapi.GetSettingsRequestObject) (openapi.GetSettingsResponseObject, error) {
-	settings, err := settings.Load(ctx, s.queries)
+func (s *Service) GetUsage(ctx context.Context, _ openapi.GetUsageRequestObject) (openapi.GetUsageResponseObject, error) {
+	usage, err := telemetry.Collect(ctx, s.queries)
 	if err != nil {
 		return nil, err
 	}
 
-	return openapi.GetSettings200JSONResponse(mapSettings(settings)), nil
+	return openapi.GetUsage200JSONResponse(openapi.Usage{
+		AutomationRuns:     usage.AutomationRuns,
+		Enabled:            usage.Enabled,
+		Flags:              usage.Flags,
+		ReactionsByTrigger: usage.ReactionsByTrigger,
+		TicketsPerDay:      usage.TicketsPerDay,
+	}), nil
 }
 
-func (s *Service) UpdateSettings(ctx context.Context, request openapi.UpdateSettingsRequestObject) (openapi.UpdateSettingsResponseObject, error) {
-	se, err := settings.Update(ctx, s.queries, func(settings *settings.Settings) {
-		settings.Meta.AppName = request.Body.Meta.AppName
-		settings.Meta.AppURL = request.Body.Meta.AppUrl
-		settings.Meta.SenderAddress = request.Body.Meta.SenderAddress
-		settings.Meta.SenderName = request.Body.Meta.SenderName
-		settings.SMTP.Enabled = request.Body.Smtp.Enabled
-		settings.SMTP.Host = request.Body.Smtp.Host
-		settings.SMTP.Port = request.Body.Smtp.Port
-		settings.SMTP.Username = request.Body.Smtp.Username
-		settings.SMTP.Password = request.Body.Smtp.Password
-		settings.SMTP.AuthMethod = request.Body.Smtp.AuthMethod
-		settings.SMTP.TLS = request.Body.Smtp.Tls
-		settings.SMTP.LocalName = request.Body.Smtp.LocalName
+// apiUsageDays is the default period of the API usage report.
+const apiUsageDays = 30
+
+func (s *Service) ListUserAPIUsage(ctx context.Context, request openapi.ListUserAPIUsageRequestObject) (openapi.ListUserAPIUsageResponseObject, error) {
+	now := time.Now().UTC()
+
+	until := now
+	if request.Params.Until != nil {
+		until = *request.Params.Until
+	}
+
+	since := until.AddDate(0, 0, -apiUsageDays)
+	if request.Params.Since != nil {
+		since = *request.Params.Since
+	}
+
+	usage, err := apiusage.Report(ctx, s.queries, since, until, now)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]openapi.UserAPIUsage, 0, len(usage))
+	for _, row := range usage {
+		response = append(response, openapi.UserAPIUsage{
+			User:          row.User,
+			Username:      row.Username,
+			Name:          pointer.Dereference(row.Name),
+			Client:        row.Client,
+			Requests:      row.Requests,
+			RequestsToday: row.Today,
+			DailyLimit:    row.DailyLimit,
+		})
+	}
+
+	return openapi.ListUserAPIUsage200JSONResponse(response), nil
+}
+
+func (s *Service) ListAPIQuotas(ctx context.Context, _ openapi.ListAPIQuotasRequestObject) (openapi.ListAPIQuotasResponseObject, error) {
+	quotas, err := s.queries.ListAPIQuotas(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]openapi.APIQuota, 0, len(quotas))
+	for _, quota := range quotas {
+		response = append(response, mapAPIQuota(quota))
+	}
+
+	return openapi.ListAPIQuotas200JSONResponse(response), nil
+}
+
+func (s *Service) SetAPIQuota(ctx context.Context, request openapi.SetAPIQuotaRequestObject) (openapi.SetAPIQuotaResponseObject, error) {
+	if request.Body.DailyLimit < 1 {
+		return openapi.SetAPIQuota400JSONResponse{
+			Status:  http.StatusBadRequest,
+			Error:   "Bad Request",
+			Message: "The daily limit must be at least 1",
+		}, nil
+	}
+
+	if _, err := s.queries.GetUser(ctx, request.Body.User); errors.Is(err, sql.ErrNoRows) {
+		return openapi.SetAPIQuota400JSONResponse{
+			Status:  http.StatusBadRequest,
+			Error:   "Bad Request",
+			Message: fmt.Sprintf("The user %q does not exist", request.Body.User),
+		}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	quota, err := s.queries.UpsertAPIQuota(ctx, sqlc.UpsertAPIQuotaParams{
+		User:       request.Body.User,
+		Client:     toString(request.Body.Client, ""),
+		DailyLimit: request.Body.DailyLimit,
 	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to save settings: %w", err)
+		return nil, err
 	}
 
-	return openapi.UpdateSettings200JSONResponse(mapSettings(se)), err
+	return openapi.SetAPIQuota200JSONResponse(mapAPIQuota(quota)), nil
+}
+
+func (s *Service) DeleteAPIQuota(ctx context.Context, request openapi.DeleteAPIQuotaRequestObject) (openapi.DeleteAPIQuotaResponseObject, error) {
+	if err := s.queries.DeleteAPIQuota(ctx, sqlc.DeleteAPIQuotaParams{
+		User:   request.Params.User,
+		Client: toString(request.Params.Client, ""),
+	}); err != nil {
+		return nil, err
+	}
+
+	return openapi.DeleteAPIQuota204Response{}, nil
+}
+
+func mapAPIQuota(quota sqlc.ApiQuota) openapi.APIQuota {
+	return openapi.APIQuota{
+		User:       quota.User,
+		Client:     quota.Client,
+		DailyLimit: quota.DailyLimit,
+		Created:    quota.Created,
+		Updated:    quota.Updated,
+	}
 }
 
 func toString(value *string, defaultValue string) string {
@@ -1873,6 +10224,14 @@ func toInt64(value *int, defaultValue int64) int64 {
 	return int64(*value)
 }
 
+func toInt64Pointer(i *int) *int64 {
+	if i == nil {
+		return nil
+	}
+
+	return pointer.Pointer(int64(*i))
+}
+
 func marshal(state map[string]any) json.RawMessage {
 	b, _ := json.Marshal(state) //nolint:errchkjson
 
@@ -1889,6 +10248,28 @@ func marshalPointer(state *map[string]any) json.RawMessage {
 	return b
 }
 
+// unmarshalMetrics returns the metrics of a type, never nil.
+func unmarshalMetrics(data []byte) []string {
+	metrics := []string{}
+	if err := json.Unmarshal(data, &metrics); err != nil {
+		return []string{}
+	}
+
+	return metrics
+}
+
+// marshalMetrics returns nil without metrics, so that the stored metrics are
+// kept or default to none.
+func marshalMetrics(metrics *[]string) []byte {
+	if metrics == nil {
+		return nil
+	}
+
+	b, _ := json.Marshal(*metrics) //nolint:errchkjson
+
+	return b
+}
+
 func unmarshal(data json.RawMessage) map[string]any {
 	var m map[string]any
 
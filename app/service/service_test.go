@@ -1,19 +1,35 @@
 package service
 
 import (
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
 	"encoding/json"
+	"fmt"
 	"io"
+	"maps"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/SecurityBrewery/catalyst/app/auth/usercontext"
+	"github.com/SecurityBrewery/catalyst/app/backup"
+	"github.com/SecurityBrewery/catalyst/app/casekey"
+	"github.com/SecurityBrewery/catalyst/app/custody"
 	"github.com/SecurityBrewery/catalyst/app/data"
 	"github.com/SecurityBrewery/catalyst/app/database"
 	"github.com/SecurityBrewery/catalyst/app/database/sqlc"
+	"github.com/SecurityBrewery/catalyst/app/enrichment"
+	"github.com/SecurityBrewery/catalyst/app/feed"
 	"github.com/SecurityBrewery/catalyst/app/hook"
+	"github.com/SecurityBrewery/catalyst/app/metric"
 	"github.com/SecurityBrewery/catalyst/app/migration"
 	"github.com/SecurityBrewery/catalyst/app/openapi"
+	"github.com/SecurityBrewery/catalyst/app/pointer"
 	"github.com/SecurityBrewery/catalyst/app/upload"
 )
 
@@ -29,7 +45,19 @@ func newTestService(t *testing.T) *Service {
 	err = migration.Apply(t.Context(), queries, dir, uploader)
 	require.NoError(t, err)
 
-	return New(queries, hooks, uploader, nil)
+	keyring, err := casekey.Load(dir, "")
+	require.NoError(t, err)
+
+	signer, err := custody.Load(dir)
+	require.NoError(t, err)
+
+	backups, err := backup.New(queries, uploader, dir, backup.Config{})
+	require.NoError(t, err)
+
+	feeds, err := feed.New(queries, dir)
+	require.NoError(t, err)
+
+	return New(queries, hooks, uploader, nil, nil, keyring, signer, backups, feeds)
 }
 
 func Test_toString(t *testing.T) {
@@ -130,3 +158,830 @@ func TestService_DownloadFile_Errors(t *testing.T) {
 	_, err = s.DownloadFile(t.Context(), openapi.DownloadFileRequestObject{Id: "f_invalid_base64"})
 	require.Error(t, err)
 }
+
+func TestService_Intake(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	s := newTestService(t)
+
+	form, err := s.GetIntakeForm(ctx, openapi.GetIntakeFormRequestObject{})
+	require.NoError(t, err)
+	assert.IsType(t, openapi.GetIntakeForm404JSONResponse{}, form)
+
+	_, err = s.UpdateIntakeSettings(ctx, openapi.UpdateIntakeSettingsRequestObject{
+		Body: &openapi.IntakeSettings{Enabled: true, Type: "incident"},
+	})
+	require.NoError(t, err)
+
+	form, err = s.GetIntakeForm(ctx, openapi.GetIntakeFormRequestObject{})
+	require.NoError(t, err)
+	require.IsType(t, openapi.GetIntakeForm200JSONResponse{}, form)
+	assert.Equal(t, "incident", form.(openapi.GetIntakeForm200JSONResponse).Type)
+
+	receipt, err := s.SubmitIntake(ctx, openapi.SubmitIntakeRequestObject{
+		Body: &openapi.NewIntake{
+			Name:        "Phishing mail",
+			Description: "Suspicious invoice",
+			State:       map[string]any{"severity": "Low"},
+		},
+	})
+	require.NoError(t, err)
+	require.IsType(t, openapi.SubmitIntake200JSONResponse{}, receipt)
+
+	ticket, err := s.queries.Ticket(ctx, receipt.(openapi.SubmitIntake200JSONResponse).Id)
+	require.NoError(t, err)
+	assert.Equal(t, "incident", ticket.Type)
+	assert.True(t, ticket.Open)
+	assert.JSONEq(t, `{"severity":"Low"}`, string(ticket.State))
+
+	// the state must match the schema of the type
+	for _, state := range []map[string]any{nil, {"severity": "Unknown"}} {
+		receipt, err = s.SubmitIntake(ctx, openapi.SubmitIntakeRequestObject{
+			Body: &openapi.NewIntake{Name: "Phishing mail", State: state},
+		})
+		require.NoError(t, err)
+		assert.IsType(t, openapi.SubmitIntake400JSONResponse{}, receipt)
+	}
+
+	settings, err := s.UpdateIntakeSettings(ctx, openapi.UpdateIntakeSettingsRequestObject{
+		Body: &openapi.IntakeSettings{Enabled: true, Type: "unknown"},
+	})
+	require.NoError(t, err)
+	assert.IsType(t, openapi.UpdateIntakeSettings400JSONResponse{}, settings)
+}
+
+func TestService_AcknowledgeTicket(t *testing.T) {
+	t.Parallel()
+
+	s := newTestService(t)
+
+	policy, err := s.queries.CreateEscalationPolicy(t.Context(), sqlc.CreateEscalationPolicyParams{
+		Name:        "Incidents",
+		Filter:      []byte(`{}`),
+		Steps:       []byte(`[{"delay":5,"users":["u_admin"]}]`),
+		PauseStates: []byte(`[]`),
+	})
+	require.NoError(t, err)
+
+	nextAt := time.Now().Add(5 * time.Minute)
+
+	_, err = s.queries.CreateTicketEscalation(t.Context(), sqlc.CreateTicketEscalationParams{Ticket: "test-ticket", Policy: policy.ID, NextAt: &nextAt})
+	require.NoError(t, err)
+
+	ctx := usercontext.UserContext(t.Context(), &sqlc.User{ID: "u_bob_analyst"})
+
+	resp, err := s.AcknowledgeTicket(ctx, openapi.AcknowledgeTicketRequestObject{Id: "test-ticket"})
+	require.NoError(t, err)
+	require.IsType(t, openapi.AcknowledgeTicket200JSONResponse{}, resp)
+
+	ticket := resp.(openapi.AcknowledgeTicket200JSONResponse)
+	require.NotNil(t, ticket.Acknowledged)
+	assert.Equal(t, "u_bob_analyst", *ticket.AcknowledgedBy)
+
+	escalation, err := s.queries.GetTicketEscalation(t.Context(), "test-ticket")
+	require.NoError(t, err)
+	assert.Nil(t, escalation.NextAt)
+	assert.Equal(t, "u_bob_analyst", *escalation.AcknowledgedBy)
+
+	// the first acknowledgement is kept
+	ctx = usercontext.UserContext(t.Context(), &sqlc.User{ID: "u_admin"})
+
+	resp, err = s.AcknowledgeTicket(ctx, openapi.AcknowledgeTicketRequestObject{Id: "test-ticket"})
+	require.NoError(t, err)
+	assert.Equal(t, "u_bob_analyst", *resp.(openapi.AcknowledgeTicket200JSONResponse).AcknowledgedBy)
+
+	unacknowledged := false
+
+	list, err := s.ListTickets(t.Context(), openapi.ListTicketsRequestObject{Params: openapi.ListTicketsParams{Acknowledged: &unacknowledged}})
+	require.NoError(t, err)
+
+	for _, ticket := range list.(openapi.ListTickets200JSONResponse).Body {
+		assert.NotEqual(t, "test-ticket", ticket.Id)
+	}
+}
+
+func TestService_EncryptTicket(t *testing.T) {
+	t.Parallel()
+
+	s := newTestService(t)
+
+	analyst := usercontext.UserContext(t.Context(), &sqlc.User{ID: "u_bob_analyst"})
+	admin := usercontext.UserContext(t.Context(), &sqlc.User{ID: "u_admin"})
+
+	resp, err := s.EncryptTicket(admin, openapi.EncryptTicketRequestObject{Id: "test-ticket"})
+	require.NoError(t, err)
+
+	encrypted := resp.(openapi.EncryptTicket200JSONResponse)
+	assert.True(t, encrypted.Encrypted)
+	assert.Equal(t, "This is a test ticket.", encrypted.Description)
+	assert.Equal(t, map[string]any{"tlp": "AMBER"}, encrypted.State)
+
+	_, err = s.EncryptTicket(admin, openapi.EncryptTicketRequestObject{Id: "test-ticket"})
+	require.Error(t, err)
+
+	// the stored values are encrypted
+	stored, err := s.queries.Ticket(t.Context(), "test-ticket")
+	require.NoError(t, err)
+	assert.True(t, casekey.IsEncrypted(stored.Description))
+	assert.NotContains(t, string(stored.State), "AMBER")
+
+	comment, err := s.queries.GetComment(t.Context(), "c_test_comment")
+	require.NoError(t, err)
+	assert.True(t, casekey.IsEncrypted(comment.Message))
+
+	// the owner was granted access
+	ticket, err := s.GetTicket(analyst, openapi.GetTicketRequestObject{Id: "test-ticket"})
+	require.NoError(t, err)
+	assert.Equal(t, "This is a test ticket.", ticket.(openapi.GetTicket200JSONResponse).Description)
+
+	comments, err := s.ListComments(analyst, openapi.ListCommentsRequestObject{Params: openapi.ListCommentsParams{Ticket: pointer.Pointer("test-ticket")}})
+	require.NoError(t, err)
+	assert.Equal(t, "Initial comment on the test ticket.", comments.(openapi.ListComments200JSONResponse).Body[0].Message)
+
+	// other users only see redacted content
+	other := usercontext.UserContext(t.Context(), &sqlc.User{ID: "u_other"})
+
+	ticket, err = s.GetTicket(other, openapi.GetTicketRequestObject{Id: "test-ticket"})
+	require.NoError(t, err)
+	assert.Equal(t, redacted, ticket.(openapi.GetTicket200JSONResponse).Description)
+	assert.Empty(t, ticket.(openapi.GetTicket200JSONResponse).State)
+
+	commentResp, err := s.GetComment(other, openapi.GetCommentRequestObject{Id: "c_test_comment"})
+	require.NoError(t, err)
+	assert.Equal(t, redacted, commentResp.(openapi.GetComment200JSONResponse).Message)
+
+	_, err = s.CreateComment(other, openapi.CreateCommentRequestObject{Body: &openapi.NewComment{Author: "u_other", Message: "leak", Ticket: "test-ticket"}})
+	require.ErrorIs(t, err, errNotGranted)
+
+	description := "Updated"
+	_, err = s.UpdateTicket(other, openapi.UpdateTicketRequestObject{Id: "test-ticket", Body: &openapi.TicketUpdate{Description: &description}})
+	require.ErrorIs(t, err, errNotGranted)
+
+	_, err = s.ListTicketGrants(other, openapi.ListTicketGrantsRequestObject{Id: "test-ticket"})
+	require.ErrorIs(t, err, errNotGranted)
+
+	// granted users can update the ticket
+	updated, err := s.UpdateTicket(analyst, openapi.UpdateTicketRequestObject{Id: "test-ticket", Body: &openapi.TicketUpdate{Description: &description}})
+	require.NoError(t, err)
+	assert.Equal(t, "Updated", updated.(openapi.UpdateTicket200JSONResponse).Description)
+
+	// the description of encrypted tickets is not searchable
+	search, err := s.SearchTickets(analyst, openapi.SearchTicketsRequestObject{Params: openapi.SearchTicketsParams{Query: &description}})
+	require.NoError(t, err)
+	assert.Empty(t, search.(openapi.SearchTickets200JSONResponse).Body)
+
+	search, err = s.SearchTickets(analyst, openapi.SearchTicketsRequestObject{Params: openapi.SearchTicketsParams{Query: pointer.Pointer("Test Ticket")}})
+	require.NoError(t, err)
+	require.Len(t, search.(openapi.SearchTickets200JSONResponse).Body, 1)
+	assert.Equal(t, "Updated", search.(openapi.SearchTickets200JSONResponse).Body[0].Description)
+
+	// grants
+	grants, err := s.ListTicketGrants(analyst, openapi.ListTicketGrantsRequestObject{Id: "test-ticket"})
+	require.NoError(t, err)
+	assert.Len(t, grants.(openapi.ListTicketGrants200JSONResponse), 2)
+
+	_, err = s.DeleteTicketGrant(analyst, openapi.DeleteTicketGrantRequestObject{Id: "test-ticket", User: "u_admin"})
+	require.NoError(t, err)
+
+	_, err = s.DeleteTicketGrant(analyst, openapi.DeleteTicketGrantRequestObject{Id: "test-ticket", User: "u_bob_analyst"})
+	require.Error(t, err)
+
+	ticket, err = s.GetTicket(admin, openapi.GetTicketRequestObject{Id: "test-ticket"})
+	require.NoError(t, err)
+	assert.Equal(t, redacted, ticket.(openapi.GetTicket200JSONResponse).Description)
+}
+
+func TestService_LegalHold(t *testing.T) {
+	t.Parallel()
+
+	s := newTestService(t)
+	ctx := usercontext.UserContext(t.Context(), &sqlc.User{ID: "u_admin"})
+
+	_, err := s.SetFileLegalHold(ctx, openapi.SetFileLegalHoldRequestObject{Id: "b_test_file", Body: &openapi.LegalHoldUpdate{Reason: "Litigation"}})
+	require.NoError(t, err)
+
+	resp, err := s.DeleteFile(ctx, openapi.DeleteFileRequestObject{Id: "b_test_file"})
+	require.NoError(t, err)
+	assert.Equal(t, "The file is under legal hold: Litigation", resp.(openapi.DeleteFile403JSONResponse).Message)
+
+	// the ticket of a held file cannot be deleted either
+	ticketResp, err := s.DeleteTicket(ctx, openapi.DeleteTicketRequestObject{Id: "test-ticket"})
+	require.NoError(t, err)
+	assert.Equal(t, "A file of the ticket is under legal hold: Litigation", ticketResp.(openapi.DeleteTicket403JSONResponse).Message)
+
+	// the database blocks deletions that bypass the API
+	require.Error(t, s.queries.DeleteTicket(ctx, "test-ticket"))
+
+	holds, err := s.ListLegalHolds(ctx, openapi.ListLegalHoldsRequestObject{})
+	require.NoError(t, err)
+
+	body := holds.(openapi.ListLegalHolds200JSONResponse).Body
+	require.Len(t, body, 1)
+	assert.Equal(t, "hello.txt", body[0].Name)
+	assert.Equal(t, "test-ticket", body[0].Ticket)
+	assert.Equal(t, "u_admin", *body[0].CreatedBy)
+
+	_, err = s.SetTicketLegalHold(ctx, openapi.SetTicketLegalHoldRequestObject{Id: "test-ticket", Body: &openapi.LegalHoldUpdate{}})
+	require.Error(t, err)
+
+	_, err = s.DeleteFileLegalHold(ctx, openapi.DeleteFileLegalHoldRequestObject{Id: "b_test_file"})
+	require.NoError(t, err)
+
+	ticketResp, err = s.DeleteTicket(ctx, openapi.DeleteTicketRequestObject{Id: "test-ticket"})
+	require.NoError(t, err)
+	assert.IsType(t, openapi.DeleteTicket204Response{}, ticketResp)
+}
+
+func TestService_GetTicketCustody(t *testing.T) {
+	t.Parallel()
+
+	s := newTestService(t)
+	ctx := usercontext.UserContext(t.Context(), &sqlc.User{ID: "u_bob_analyst", Username: "bob", Name: pointer.Pointer("Bob Analyst")})
+
+	created, err := s.CreateFile(ctx, openapi.CreateFileRequestObject{Body: &openapi.NewFile{Name: "evidence.txt", Blob: "evidence", Ticket: "test-ticket"}})
+	require.NoError(t, err)
+
+	download, err := s.DownloadFile(ctx, openapi.DownloadFileRequestObject{Id: created.(openapi.CreateFile200JSONResponse).Id})
+	require.NoError(t, err)
+
+	// the download still returns the whole file after it was hashed
+	content, err := io.ReadAll(download.(openapi.DownloadFile200ApplicationoctetStreamResponse).Body)
+	require.NoError(t, err)
+	assert.Equal(t, "evidence", string(content))
+
+	resp, err := s.GetTicketCustody(ctx, openapi.GetTicketCustodyRequestObject{Id: "test-ticket"})
+	require.NoError(t, err)
+
+	custodyResp := resp.(openapi.GetTicketCustody200ApplicationpdfResponse)
+
+	document, err := io.ReadAll(custodyResp.Body)
+	require.NoError(t, err)
+	assert.Contains(t, string(document), "(File: evidence.txt")
+	assert.Contains(t, string(document), "by Bob Analyst \\(bob\\)) Tj")
+	assert.Equal(t, 2, strings.Count(string(document), "Bob Analyst \\(u_bob_analyst\\)) Tj"))
+
+	key, err := s.GetCustodyKey(ctx, openapi.GetCustodyKeyRequestObject{})
+	require.NoError(t, err)
+
+	publicKey, err := base64.StdEncoding.DecodeString(key.(openapi.GetCustodyKey200JSONResponse).PublicKey)
+	require.NoError(t, err)
+
+	signature, err := base64.StdEncoding.DecodeString(custodyResp.Headers.XSignature)
+	require.NoError(t, err)
+	assert.True(t, ed25519.Verify(publicKey, document, signature))
+}
+
+func TestService_GetReactionStats(t *testing.T) {
+	t.Parallel()
+
+	s := newTestService(t)
+	ctx := t.Context()
+
+	for _, run := range []sqlc.CreateReactionRunParams{
+		{Reaction: "r-test-hook", Success: true, DurationMs: 100, CpuMs: 40, MemoryPeak: 10 << 20},
+		{Reaction: "r-test-hook", Success: false, DurationMs: 300, CpuMs: 80, MemoryPeak: 30 << 20},
+		{Reaction: "r-test-webhook", Success: true, DurationMs: 5000, CpuMs: 4000, MemoryPeak: 1 << 30},
+	} {
+		require.NoError(t, s.queries.CreateReactionRun(ctx, run))
+	}
+
+	resp, err := s.GetReactionStats(ctx, openapi.GetReactionStatsRequestObject{Id: "r-test-hook"})
+	require.NoError(t, err)
+
+	stats := resp.(openapi.GetReactionStats200JSONResponse)
+	assert.Equal(t, 2, stats.Runs)
+	assert.Equal(t, 1, stats.Failures)
+	assert.InDelta(t, 200, stats.AvgDurationMs, 0.01)
+	assert.Equal(t, 300, stats.MaxDurationMs)
+	assert.Equal(t, 120, stats.TotalCpuMs)
+	assert.Equal(t, 80, stats.MaxCpuMs)
+	assert.Equal(t, int64(30<<20), stats.MaxMemoryPeak)
+	require.Len(t, stats.RecentRuns, 2)
+	assert.False(t, stats.RecentRuns[0].Success)
+}
+
+func TestService_GetResponseTimes(t *testing.T) {
+	t.Parallel()
+
+	s := newTestService(t)
+	ctx := t.Context()
+
+	_, err := s.queries.AcknowledgeTicket(ctx, sqlc.AcknowledgeTicketParams{ID: "test-ticket"})
+	require.NoError(t, err)
+
+	closed := false
+
+	_, err = s.queries.UpdateTicket(ctx, sqlc.UpdateTicketParams{ID: "test-ticket", Open: &closed})
+	require.NoError(t, err)
+
+	ticket, err := s.queries.Ticket(ctx, "test-ticket")
+	require.NoError(t, err)
+	require.NotNil(t, ticket.Resolved)
+
+	since := ticket.Created.Add(-time.Minute)
+
+	resp, err := s.GetResponseTimes(ctx, openapi.GetResponseTimesRequestObject{Params: openapi.GetResponseTimesParams{Since: &since}})
+	require.NoError(t, err)
+
+	times := resp.(openapi.GetResponseTimes200JSONResponse)
+	assert.GreaterOrEqual(t, times.Tickets, 1)
+	assert.GreaterOrEqual(t, times.Acknowledged, 1)
+	assert.GreaterOrEqual(t, times.Resolved, 1)
+	assert.Positive(t, times.Mttr)
+
+	reopened := true
+
+	ticket2, err := s.queries.UpdateTicket(ctx, sqlc.UpdateTicketParams{ID: "test-ticket", Open: &reopened})
+	require.NoError(t, err)
+	assert.Nil(t, ticket2.Resolved)
+}
+
+func TestService_ReopenTicket(t *testing.T) {
+	t.Parallel()
+
+	s := newTestService(t)
+	ctx := t.Context()
+	analyst := usercontext.UserContext(ctx, &sqlc.User{ID: "u_bob_analyst"})
+	admin := usercontext.UserContext(ctx, &sqlc.User{ID: "u_admin", Name: pointer.Pointer("Admin")})
+
+	playbook, err := s.queries.CreatePlaybook(ctx, sqlc.CreatePlaybookParams{Name: "Phishing", Inputs: []byte(`[]`), Tasks: []byte(`[]`)})
+	require.NoError(t, err)
+
+	run, err := s.queries.CreatePlaybookRun(ctx, sqlc.CreatePlaybookRunParams{Playbook: playbook.ID, Ticket: "test-ticket", Inputs: []byte(`{}`)})
+	require.NoError(t, err)
+
+	require.NoError(t, s.queries.AddPlaybookRunTask(ctx, sqlc.AddPlaybookRunTaskParams{Run: run.ID, Task: "k_test_task"}))
+
+	_, err = s.queries.UpdateTask(ctx, sqlc.UpdateTaskParams{ID: "k_test_task", Open: pointer.Pointer(false)})
+	require.NoError(t, err)
+
+	resp, err := s.ReopenTicket(admin, openapi.ReopenTicketRequestObject{Id: "test-ticket", Body: &openapi.ReopenTicket{Reason: "New evidence"}})
+	require.NoError(t, err)
+	require.IsType(t, openapi.ReopenTicket400JSONResponse{}, resp)
+
+	_, err = s.UpdateTicket(analyst, openapi.UpdateTicketRequestObject{Id: "test-ticket", Body: &openapi.TicketUpdate{Open: pointer.Pointer(false), Resolution: pointer.Pointer("false positive")}})
+	require.NoError(t, err)
+
+	resp, err = s.ReopenTicket(admin, openapi.ReopenTicketRequestObject{Id: "test-ticket", Body: &openapi.ReopenTicket{Reason: " "}})
+	require.NoError(t, err)
+	require.IsType(t, openapi.ReopenTicket400JSONResponse{}, resp)
+
+	resp, err = s.ReopenTicket(admin, openapi.ReopenTicketRequestObject{Id: "test-ticket", Body: &openapi.ReopenTicket{Reason: "New evidence", Tasks: &[]string{"k_unknown"}}})
+	require.NoError(t, err)
+	require.IsType(t, openapi.ReopenTicket400JSONResponse{}, resp)
+
+	var published []openapi.TicketReopen
+
+	s.hooks.OnRecordAfterCreateRequest.Subscribe(func(_ context.Context, table string, record any) {
+		if table == database.TicketReopensTable.ID {
+			published = append(published, record.(openapi.TicketReopen))
+		}
+	})
+
+	resp, err = s.ReopenTicket(admin, openapi.ReopenTicketRequestObject{Id: "test-ticket", Body: &openapi.ReopenTicket{Reason: "New evidence", Tasks: &[]string{"k_test_task"}}})
+	require.NoError(t, err)
+
+	ticket := resp.(openapi.ReopenTicket200JSONResponse)
+	assert.True(t, ticket.Open)
+	assert.Equal(t, 1, ticket.ReopenCount)
+	assert.Nil(t, ticket.ResolvedBy)
+
+	task, err := s.queries.GetTask(ctx, "k_test_task")
+	require.NoError(t, err)
+	assert.True(t, task.Open)
+
+	require.Len(t, published, 1)
+	assert.Equal(t, "u_bob_analyst", pointer.Dereference(published[0].ClosedBy))
+	assert.Equal(t, "Admin", pointer.Dereference(published[0].ReopenedByName))
+
+	reopens, err := s.ListTicketReopens(ctx, openapi.ListTicketReopensRequestObject{Id: "test-ticket"})
+	require.NoError(t, err)
+
+	list := reopens.(openapi.ListTicketReopens200JSONResponse)
+	require.Len(t, list, 1)
+	assert.Equal(t, "New evidence", list[0].Reason)
+	assert.Equal(t, "false positive", pointer.Dereference(list[0].Resolution))
+	assert.Equal(t, "Bob Analyst", pointer.Dereference(list[0].ClosedByName))
+
+	since := time.Now().Add(-24 * time.Hour * 365 * 5)
+
+	times, err := s.GetResponseTimes(ctx, openapi.GetResponseTimesRequestObject{Params: openapi.GetResponseTimesParams{Since: &since}})
+	require.NoError(t, err)
+	assert.Equal(t, 1, times.(openapi.GetResponseTimes200JSONResponse).Reopened)
+	assert.Equal(t, 1, times.(openapi.GetResponseTimes200JSONResponse).Reopens)
+}
+
+func TestService_PromoteAlerts(t *testing.T) {
+	t.Parallel()
+
+	s := newTestService(t)
+	ctx := t.Context()
+
+	create := func(name, severity string) string {
+		t.Helper()
+
+		resp, err := s.CreateAlert(ctx, openapi.CreateAlertRequestObject{Body: &openapi.NewAlert{
+			Source:   "edr",
+			Name:     name,
+			Severity: pointer.Pointer(severity),
+		}})
+		require.NoError(t, err)
+
+		return resp.(openapi.CreateAlert200JSONResponse).Id
+	}
+
+	first := create("Suspicious login", "")
+	second := create("Malware detected", "High")
+	third := create("Port scan", "Low")
+
+	resp, err := s.PromoteAlerts(ctx, openapi.PromoteAlertsRequestObject{Body: &openapi.PromoteAlerts{Alerts: []string{first}}})
+	require.NoError(t, err)
+	require.IsType(t, openapi.PromoteAlerts400JSONResponse{}, resp)
+
+	resp, err = s.PromoteAlerts(ctx, openapi.PromoteAlertsRequestObject{Body: &openapi.PromoteAlerts{Alerts: []string{"r_unknown"}, Type: pointer.Pointer("incident")}})
+	require.NoError(t, err)
+	require.IsType(t, openapi.PromoteAlerts400JSONResponse{}, resp)
+
+	resp, err = s.PromoteAlerts(ctx, openapi.PromoteAlertsRequestObject{Body: &openapi.PromoteAlerts{Alerts: []string{first, second}, Type: pointer.Pointer("incident")}})
+	require.NoError(t, err)
+
+	ticket := resp.(openapi.PromoteAlerts200JSONResponse)
+	assert.Equal(t, "Suspicious login", ticket.Name)
+	assert.Contains(t, ticket.Description, "Malware detected")
+	assert.Equal(t, "High", ticket.State["severity"])
+
+	alert, err := s.GetAlert(ctx, openapi.GetAlertRequestObject{Id: second})
+	require.NoError(t, err)
+	assert.Equal(t, openapi.AlertStatusPromoted, alert.(openapi.GetAlert200JSONResponse).Status)
+	assert.Equal(t, ticket.Id, pointer.Dereference(alert.(openapi.GetAlert200JSONResponse).Ticket))
+
+	update, err := s.UpdateAlert(ctx, openapi.UpdateAlertRequestObject{Id: second, Body: &openapi.AlertUpdate{Status: openapi.AlertUpdateStatusDismissed}})
+	require.NoError(t, err)
+	require.IsType(t, openapi.UpdateAlert400JSONResponse{}, update)
+
+	resp, err = s.PromoteAlerts(ctx, openapi.PromoteAlertsRequestObject{Body: &openapi.PromoteAlerts{Alerts: []string{first}, Ticket: pointer.Pointer("test-ticket")}})
+	require.NoError(t, err)
+	require.IsType(t, openapi.PromoteAlerts400JSONResponse{}, resp)
+
+	resp, err = s.PromoteAlerts(ctx, openapi.PromoteAlertsRequestObject{Body: &openapi.PromoteAlerts{Alerts: []string{third}, Ticket: pointer.Pointer("test-ticket")}})
+	require.NoError(t, err)
+	assert.Equal(t, "test-ticket", resp.(openapi.PromoteAlerts200JSONResponse).Id)
+
+	timeline, err := s.queries.ListTimeline(ctx, sqlc.ListTimelineParams{Ticket: "test-ticket", Limit: 100})
+	require.NoError(t, err)
+	assert.True(t, slices.ContainsFunc(timeline, func(entry sqlc.ListTimelineRow) bool {
+		return entry.Message == "Alert Port scan from edr"
+	}))
+
+	alerts, err := s.ListAlerts(ctx, openapi.ListAlertsRequestObject{Params: openapi.ListAlertsParams{Status: pointer.Pointer(openapi.AlertStatusNew)}})
+	require.NoError(t, err)
+	assert.Empty(t, alerts.(openapi.ListAlerts200JSONResponse).Body)
+}
+
+func TestService_SearchArtifacts(t *testing.T) {
+	t.Parallel()
+
+	s := newTestService(t)
+	ctx := t.Context()
+
+	_, err := s.AddTicketArtifacts(ctx, openapi.AddTicketArtifactsRequestObject{
+		Id:   "test-ticket",
+		Body: &openapi.BulkArtifacts{Artifacts: &[]openapi.NewArtifact{{Value: "evil.example"}}},
+	})
+	require.NoError(t, err)
+
+	_, err = enrichment.New(s.queries).Set(ctx, "whois", "evil.example", json.RawMessage(`{"registrar":"Example"}`))
+	require.NoError(t, err)
+
+	resp, err := s.SearchArtifacts(ctx, openapi.SearchArtifactsRequestObject{Params: openapi.SearchArtifactsParams{Value: "EVIL[.]example"}})
+	require.NoError(t, err)
+
+	result := resp.(openapi.SearchArtifacts200JSONResponse)
+	assert.Equal(t, "domain", result.Kind)
+	assert.Equal(t, "evil.example", result.Value)
+	assert.Equal(t, 1, result.TicketCount)
+	require.Len(t, result.Tickets, 1)
+	assert.Equal(t, "test-ticket", result.Tickets[0].Id)
+	assert.Empty(t, result.Feeds)
+	require.Len(t, result.Enrichments, 1)
+	assert.Equal(t, "whois", result.Enrichments[0].Enricher)
+
+	// the type limits the search to one kind
+	resp, err = s.SearchArtifacts(ctx, openapi.SearchArtifactsRequestObject{Params: openapi.SearchArtifactsParams{Value: "https://evil.example", Type: pointer.Pointer("url")}})
+	require.NoError(t, err)
+	assert.Empty(t, resp.(openapi.SearchArtifacts200JSONResponse).Tickets)
+
+	resp, err = s.SearchArtifacts(ctx, openapi.SearchArtifactsRequestObject{Params: openapi.SearchArtifactsParams{Value: "evil.example", Type: pointer.Pointer("ip")}})
+	require.NoError(t, err)
+	require.IsType(t, openapi.SearchArtifacts400JSONResponse{}, resp)
+}
+
+func TestService_ClaimNextQueueTicket(t *testing.T) {
+	t.Parallel()
+
+	s := newTestService(t)
+	ctx := t.Context()
+	analyst := usercontext.UserContext(ctx, &sqlc.User{ID: "u_bob_analyst"})
+	admin := usercontext.UserContext(ctx, &sqlc.User{ID: "u_admin"})
+
+	_, err := s.CreateQueue(ctx, openapi.CreateQueueRequestObject{Body: &openapi.NewQueue{
+		Name:   "high",
+		Filter: openapi.QueueFilter{Types: &[]string{"incident"}, Severities: &[]string{"High"}},
+	}})
+	require.NoError(t, err)
+
+	create := func(severity string) string {
+		t.Helper()
+
+		resp, err := s.CreateTicket(ctx, openapi.CreateTicketRequestObject{Body: &openapi.NewTicket{
+			Type:   "incident",
+			Name:   severity + " incident",
+			Open:   true,
+			Schema: map[string]any{},
+			State:  map[string]any{"severity": severity},
+		}})
+		require.NoError(t, err)
+
+		return resp.(openapi.CreateTicket200JSONResponse).Id
+	}
+
+	create("Low")
+	first := create("High")
+	second := create("High")
+
+	claimed := map[string]string{}
+
+	for _, ctx := range []context.Context{analyst, admin} {
+		resp, err := s.ClaimNextQueueTicket(ctx, openapi.ClaimNextQueueTicketRequestObject{Name: "high"})
+		require.NoError(t, err)
+
+		ticket := resp.(openapi.ClaimNextQueueTicket200JSONResponse)
+		claimed[ticket.Id] = pointer.Dereference(ticket.Owner)
+	}
+
+	// both tickets are created in the same second, their order is unknown
+	assert.ElementsMatch(t, []string{first, second}, slices.Collect(maps.Keys(claimed)))
+	assert.ElementsMatch(t, []string{"u_bob_analyst", "u_admin"}, slices.Collect(maps.Values(claimed)))
+
+	resp, err := s.ClaimNextQueueTicket(analyst, openapi.ClaimNextQueueTicketRequestObject{Name: "high"})
+	require.NoError(t, err)
+	require.IsType(t, openapi.ClaimNextQueueTicket204Response{}, resp)
+
+	resp, err = s.ClaimNextQueueTicket(analyst, openapi.ClaimNextQueueTicketRequestObject{Name: "unknown"})
+	require.NoError(t, err)
+	require.IsType(t, openapi.ClaimNextQueueTicket404JSONResponse{}, resp)
+}
+
+func TestService_WorkTimers(t *testing.T) {
+	t.Parallel()
+
+	s := newTestService(t)
+	ctx := t.Context()
+	analyst := usercontext.UserContext(ctx, &sqlc.User{ID: "u_bob_analyst", Name: pointer.Pointer("Bob Analyst")})
+	admin := usercontext.UserContext(ctx, &sqlc.User{ID: "u_admin"})
+
+	resp, err := s.StopWorkTimer(analyst, openapi.StopWorkTimerRequestObject{Id: "test-ticket"})
+	require.NoError(t, err)
+	require.IsType(t, openapi.StopWorkTimer400JSONResponse{}, resp)
+
+	started, err := s.StartWorkTimer(analyst, openapi.StartWorkTimerRequestObject{Id: "test-ticket", Body: &openapi.StartWorkTimer{Description: pointer.Pointer("Triage")}})
+	require.NoError(t, err)
+	assert.Nil(t, started.(openapi.StartWorkTimer200JSONResponse).Duration)
+
+	started, err = s.StartWorkTimer(analyst, openapi.StartWorkTimerRequestObject{Id: "test-ticket"})
+	require.NoError(t, err)
+	require.IsType(t, openapi.StartWorkTimer400JSONResponse{}, started)
+
+	// timers of other users run independently
+	started, err = s.StartWorkTimer(admin, openapi.StartWorkTimerRequestObject{Id: "test-ticket"})
+	require.NoError(t, err)
+	require.IsType(t, openapi.StartWorkTimer200JSONResponse{}, started)
+
+	resp, err = s.StopWorkTimer(analyst, openapi.StopWorkTimerRequestObject{Id: "test-ticket"})
+	require.NoError(t, err)
+
+	stopped := resp.(openapi.StopWorkTimer200JSONResponse)
+	require.NotNil(t, stopped.Duration)
+	assert.Equal(t, "Triage", stopped.Description)
+
+	created, err := s.CreateWorkEntry(analyst, openapi.CreateWorkEntryRequestObject{Id: "test-ticket", Body: &openapi.NewWorkEntry{Duration: 0}})
+	require.NoError(t, err)
+	require.IsType(t, openapi.CreateWorkEntry400JSONResponse{}, created)
+
+	_, err = s.CreateWorkEntry(analyst, openapi.CreateWorkEntryRequestObject{Id: "test-ticket", Body: &openapi.NewWorkEntry{Duration: 3600, Description: pointer.Pointer("Report")}})
+	require.NoError(t, err)
+
+	entries, err := s.ListWorkEntries(ctx, openapi.ListWorkEntriesRequestObject{Id: "test-ticket"})
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+
+	effort, err := s.GetEffort(ctx, openapi.GetEffortRequestObject{Params: openapi.GetEffortParams{Until: pointer.Pointer(time.Now().Add(time.Minute))}})
+	require.NoError(t, err)
+
+	report := effort.(openapi.GetEffort200JSONResponse)
+
+	// the running timer of the admin is not included
+	require.Len(t, report.Tickets, 1)
+	assert.Equal(t, "test-ticket", report.Tickets[0].Ticket)
+	assert.Equal(t, 3600+*stopped.Duration, report.Tickets[0].Duration)
+	assert.Equal(t, 1, report.Tickets[0].Analysts)
+	require.Len(t, report.Analysts, 1)
+	assert.Equal(t, "Bob Analyst", pointer.Dereference(report.Analysts[0].UserName))
+	assert.Equal(t, 1, report.Analysts[0].Tickets)
+}
+
+func TestService_Announcements(t *testing.T) {
+	t.Parallel()
+
+	s := newTestService(t)
+	admin := usercontext.UserContext(t.Context(), &sqlc.User{ID: "u_admin"})
+	analyst := usercontext.UserContext(t.Context(), &sqlc.User{ID: "u_bob_analyst"})
+
+	expired := time.Now().Add(-time.Hour)
+
+	_, err := s.CreateAnnouncement(admin, openapi.CreateAnnouncementRequestObject{Body: &openapi.NewAnnouncement{Title: "Maintenance", Expires: &expired}})
+	require.NoError(t, err)
+
+	created, err := s.CreateAnnouncement(admin, openapi.CreateAnnouncementRequestObject{Body: &openapi.NewAnnouncement{
+		Title:                 "Ransomware outbreak",
+		RequireAcknowledgment: pointer.Pointer(true),
+	}})
+	require.NoError(t, err)
+
+	id := created.(openapi.CreateAnnouncement200JSONResponse).Id
+
+	pending, err := s.ListAnnouncements(analyst, openapi.ListAnnouncementsRequestObject{Params: openapi.ListAnnouncementsParams{Pending: pointer.Pointer(true)}})
+	require.NoError(t, err)
+
+	body := pending.(openapi.ListAnnouncements200JSONResponse).Body
+	require.Len(t, body, 1)
+	assert.Equal(t, id, body[0].Id)
+	assert.Equal(t, openapi.AnnouncementSeverity("info"), body[0].Severity)
+
+	all, err := s.ListAnnouncements(analyst, openapi.ListAnnouncementsRequestObject{Params: openapi.ListAnnouncementsParams{IncludeExpired: pointer.Pointer(true)}})
+	require.NoError(t, err)
+	assert.Len(t, all.(openapi.ListAnnouncements200JSONResponse).Body, 2)
+
+	read, err := s.ReadAnnouncement(analyst, openapi.ReadAnnouncementRequestObject{Id: id})
+	require.NoError(t, err)
+	assert.NotNil(t, read.(openapi.ReadAnnouncement200JSONResponse).Read)
+	assert.Nil(t, read.(openapi.ReadAnnouncement200JSONResponse).Acknowledged)
+
+	receipts, err := s.ListAnnouncementReceipts(admin, openapi.ListAnnouncementReceiptsRequestObject{Id: id, Params: openapi.ListAnnouncementReceiptsParams{Pending: pointer.Pointer(true)}})
+	require.NoError(t, err)
+
+	var users []string
+	for _, receipt := range receipts.(openapi.ListAnnouncementReceipts200JSONResponse) {
+		users = append(users, receipt.User)
+	}
+
+	assert.Contains(t, users, "u_bob_analyst")
+	assert.NotContains(t, users, "system")
+
+	acknowledged, err := s.AcknowledgeAnnouncement(analyst, openapi.AcknowledgeAnnouncementRequestObject{Id: id})
+	require.NoError(t, err)
+
+	announcement := acknowledged.(openapi.AcknowledgeAnnouncement200JSONResponse)
+	assert.NotNil(t, announcement.Acknowledged)
+	assert.Equal(t, 1, announcement.Acknowledgments)
+
+	receipts, err = s.ListAnnouncementReceipts(admin, openapi.ListAnnouncementReceiptsRequestObject{Id: id, Params: openapi.ListAnnouncementReceiptsParams{Pending: pointer.Pointer(false)}})
+	require.NoError(t, err)
+	require.Len(t, receipts.(openapi.ListAnnouncementReceipts200JSONResponse), 1)
+	assert.Equal(t, "u_bob_analyst", receipts.(openapi.ListAnnouncementReceipts200JSONResponse)[0].User)
+
+	pending, err = s.ListAnnouncements(analyst, openapi.ListAnnouncementsRequestObject{Params: openapi.ListAnnouncementsParams{Pending: pointer.Pointer(true)}})
+	require.NoError(t, err)
+	assert.Empty(t, pending.(openapi.ListAnnouncements200JSONResponse).Body)
+}
+
+func TestService_GetMetricSeries(t *testing.T) {
+	t.Parallel()
+
+	s := newTestService(t)
+	ctx := t.Context()
+
+	schema := map[string]any{"type": "object", "properties": map[string]any{
+		"brand":      map[string]any{"type": "string", "enum": []any{"DHL", "PayPal"}},
+		"recipients": map[string]any{"type": "integer"},
+	}}
+
+	resp, err := s.CreateType(ctx, openapi.CreateTypeRequestObject{Body: &openapi.NewType{
+		Singular: "Phishing",
+		Plural:   "Phishing",
+		Schema:   schema,
+		Metrics:  &[]string{"brand", "recipients"},
+	}})
+	require.NoError(t, err)
+
+	typ := resp.(openapi.CreateType200JSONResponse)
+	assert.Equal(t, []string{"brand", "recipients"}, typ.Metrics)
+
+	for i, ticket := range []struct {
+		created string
+		state   string
+	}{
+		{created: "2025-06-02T09:00:00Z", state: `{"brand":"DHL","recipients":10}`},
+		{created: "2025-06-04T09:00:00Z", state: `{"brand":"PayPal","recipients":30}`},
+		{created: "2025-06-08T09:00:00Z", state: `{"brand":"DHL"}`},
+		{created: "2025-06-10T09:00:00Z", state: `{"brand":"DHL","recipients":5}`},
+	} {
+		created, err := time.Parse(time.RFC3339, ticket.created)
+		require.NoError(t, err)
+
+		_, err = s.queries.InsertTicket(ctx, sqlc.InsertTicketParams{
+			ID:      fmt.Sprintf("phishing-%d", i),
+			Name:    "Phishing",
+			Type:    typ.Id,
+			Schema:  marshal(schema),
+			State:   json.RawMessage(ticket.state),
+			Open:    true,
+			Created: created,
+			Updated: created,
+		})
+		require.NoError(t, err)
+	}
+
+	since := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	until := time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)
+	params := openapi.GetMetricSeriesParams{Since: &since, Until: &until}
+
+	brands, err := s.GetMetricSeries(ctx, openapi.GetMetricSeriesRequestObject{Type: typ.Id, Field: "brand", Params: params})
+	require.NoError(t, err)
+
+	assert.Equal(t, openapi.GetMetricSeries200JSONResponse{
+		Type:     typ.Id,
+		Field:    "brand",
+		Kind:     openapi.MetricSeriesKind(metric.KindEnum),
+		Interval: metric.IntervalWeek,
+		Buckets: []openapi.MetricBucket{
+			{Start: "2025-06-02", Tickets: 3, Values: &map[string]int{"DHL": 2, "PayPal": 1}},
+			{Start: "2025-06-09", Tickets: 1, Values: &map[string]int{"DHL": 1}},
+		},
+	}, brands)
+
+	month := openapi.GetMetricSeriesParamsInterval(metric.IntervalMonth)
+	params.Interval = &month
+
+	recipients, err := s.GetMetricSeries(ctx, openapi.GetMetricSeriesRequestObject{Type: typ.Id, Field: "recipients", Params: params})
+	require.NoError(t, err)
+
+	series := recipients.(openapi.GetMetricSeries200JSONResponse)
+	require.Len(t, series.Buckets, 1)
+	assert.Equal(t, "2025-06-01", series.Buckets[0].Start)
+	assert.Equal(t, 4, series.Buckets[0].Tickets)
+	assert.Equal(t, 3, *series.Buckets[0].Count)
+	assert.InDelta(t, 45, *series.Buckets[0].Sum, 0.001)
+	assert.InDelta(t, 15, *series.Buckets[0].Avg, 0.001)
+	assert.InDelta(t, 5, *series.Buckets[0].Min, 0.001)
+	assert.InDelta(t, 30, *series.Buckets[0].Max, 0.001)
+
+	// a new schema must keep the metrics, without a schema the schema is kept
+	update, err := s.UpdateType(ctx, openapi.UpdateTypeRequestObject{Id: typ.Id, Body: &openapi.TypeUpdate{
+		Schema: &map[string]any{"type": "object", "properties": map[string]any{}},
+	}})
+	require.NoError(t, err)
+	assert.IsType(t, openapi.UpdateType400JSONResponse{}, update)
+
+	update, err = s.UpdateType(ctx, openapi.UpdateTypeRequestObject{Id: typ.Id, Body: &openapi.TypeUpdate{Metrics: &[]string{"brand"}}})
+	require.NoError(t, err)
+
+	updated := update.(openapi.UpdateType200JSONResponse)
+	assert.Equal(t, []string{"brand"}, updated.Metrics)
+	assert.Equal(t, schema["properties"], updated.Schema["properties"])
+
+	notMetric, err := s.GetMetricSeries(ctx, openapi.GetMetricSeriesRequestObject{Type: typ.Id, Field: "recipients", Params: params})
+	require.NoError(t, err)
+	assert.IsType(t, openapi.GetMetricSeries400JSONResponse{}, notMetric)
+}
+
+func TestService_FileHashes(t *testing.T) {
+	t.Parallel()
+
+	s := newTestService(t)
+	ctx := t.Context()
+
+	created, err := s.CreateFile(ctx, openapi.CreateFileRequestObject{Body: &openapi.NewFile{Name: "evidence.txt", Blob: "evidence", Ticket: "test-ticket"}})
+	require.NoError(t, err)
+
+	file := created.(openapi.CreateFile200JSONResponse)
+	assert.Equal(t, "14e10d570047667f904261e6d08f520f", file.Md5)
+	assert.Equal(t, "100ec4462dec34f6824f7e65f377076ce5d51ced", file.Sha1)
+	assert.Equal(t, "ee8250fb76e094b34b471f13a73dbbe51d1ae142e9df59d7c0d31ec20f0a0a8e", file.Sha256)
+
+	for _, hash := range []string{file.Md5, file.Sha1, strings.ToUpper(file.Sha256)} {
+		resp, err := s.SearchArtifacts(ctx, openapi.SearchArtifactsRequestObject{Params: openapi.SearchArtifactsParams{Value: hash}})
+		require.NoError(t, err)
+
+		result := resp.(openapi.SearchArtifacts200JSONResponse)
+		require.Len(t, result.Files, 1, hash)
+		assert.Equal(t, file.Id, result.Files[0].Id)
+		assert.Equal(t, "test-ticket", result.Files[0].Ticket)
+		assert.Equal(t, "Test Ticket", result.Files[0].TicketName)
+	}
+
+	resp, err := s.SearchArtifacts(ctx, openapi.SearchArtifactsRequestObject{Params: openapi.SearchArtifactsParams{Value: "example.com"}})
+	require.NoError(t, err)
+	assert.Empty(t, resp.(openapi.SearchArtifacts200JSONResponse).Files)
+}
@@ -0,0 +1,232 @@
+package settings
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+const envPrefix = "CATALYST_"
+
+// envSetting is a settings field that can be set by an environment variable.
+type envSetting struct {
+	name  string
+	index []int
+}
+
+// envSettings maps environment variables to all settings fields. The name of
+// a variable is derived from the JSON path of the field, e.g.
+// CATALYST_SMTP_PASSWORD for smtp.password or CATALYST_META_APP_URL for
+// meta.appUrl. Nested structs are flattened, lists of strings are comma
+// separated and all other lists and maps are given as JSON. Every variable
+// can also be provided as a file by appending _FILE to its name, e.g.
+// CATALYST_SMTP_PASSWORD_FILE=/run/secrets/smtp_password.
+var envSettings = sync.OnceValue(func() []envSetting {
+	return collectEnvSettings(reflect.TypeFor[Settings](), "", nil)
+})
+
+// envAliases are the short names of frequently used variables.
+var envAliases = map[string]string{
+	"APP_NAME":                    "META_APP_NAME",
+	"APP_URL":                     "META_APP_URL",
+	"SENDER_NAME":                 "META_SENDER_NAME",
+	"SENDER_ADDRESS":              "META_SENDER_ADDRESS",
+	"AUTH_TOKEN_SECRET":           "RECORD_AUTH_TOKEN_SECRET",
+	"AUTH_TOKEN_DURATION":         "RECORD_AUTH_TOKEN_DURATION",
+	"RESET_TOKEN_SECRET":          "RECORD_PASSWORD_RESET_TOKEN_SECRET",
+	"RESET_TOKEN_DURATION":        "RECORD_PASSWORD_RESET_TOKEN_DURATION",
+	"VERIFICATION_TOKEN_SECRET":   "RECORD_VERIFICATION_TOKEN_SECRET",
+	"VERIFICATION_TOKEN_DURATION": "RECORD_VERIFICATION_TOKEN_DURATION",
+}
+
+func collectEnvSettings(t reflect.Type, prefix string, index []int) []envSetting {
+	var settings []envSetting
+
+	for i := range t.NumField() {
+		field := t.Field(i)
+
+		tag, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if tag == "" || tag == "-" {
+			continue
+		}
+
+		name := prefix + envName(tag)
+		fieldIndex := append(slices.Clone(index), i)
+
+		if field.Type.Kind() == reflect.Struct {
+			settings = append(settings, collectEnvSettings(field.Type, name+"_", fieldIndex)...)
+
+			continue
+		}
+
+		settings = append(settings, envSetting{name: name, index: fieldIndex})
+	}
+
+	return settings
+}
+
+// envName converts a JSON name like "vapidPublicKey" to "VAPID_PUBLIC_KEY".
+func envName(jsonName string) string {
+	var b strings.Builder
+
+	runes := []rune(jsonName)
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) && !unicode.IsUpper(runes[i-1]) {
+			b.WriteRune('_')
+		}
+
+		b.WriteRune(unicode.ToUpper(r))
+	}
+
+	return b.String()
+}
+
+// names returns the variables of the setting in the order of precedence.
+func (e envSetting) names() []string {
+	names := []string{envPrefix + e.name}
+
+	for alias, name := range envAliases {
+		if name == e.name {
+			names = append(names, envPrefix+alias)
+		}
+	}
+
+	return names
+}
+
+// EnvOverrides returns the names of all environment variables that override
+// settings. A variable set directly takes precedence over its _FILE variant.
+func EnvOverrides() []string {
+	var names []string
+
+	for _, setting := range envSettings() {
+		for _, name := range setting.names() {
+			if _, ok := os.LookupEnv(name); ok {
+				names = append(names, name)
+
+				break
+			} else if _, ok := os.LookupEnv(name + "_FILE"); ok {
+				names = append(names, name+"_FILE")
+
+				break
+			}
+		}
+	}
+
+	return names
+}
+
+// ApplyEnv overrides the settings with values from the environment.
+func ApplyEnv(s *Settings) error {
+	_, err := applyEnv(s)
+
+	return err
+}
+
+// applyEnv overrides the settings with values from the environment and
+// returns the overridden settings.
+func applyEnv(s *Settings) ([]envSetting, error) {
+	var applied []envSetting
+
+	v := reflect.ValueOf(s).Elem()
+
+	for _, setting := range envSettings() {
+		for _, name := range setting.names() {
+			value, ok, err := lookupEnv(name)
+			if err != nil {
+				return nil, err
+			}
+
+			if !ok {
+				continue
+			}
+
+			if err := setValue(v.FieldByIndex(setting.index), value); err != nil {
+				return nil, fmt.Errorf("invalid value for %s: %w", name, err)
+			}
+
+			applied = append(applied, setting)
+
+			break
+		}
+	}
+
+	return applied, nil
+}
+
+func lookupEnv(name string) (string, bool, error) {
+	if value, ok := os.LookupEnv(name); ok {
+		return value, true, nil
+	}
+
+	path, ok := os.LookupEnv(name + "_FILE")
+	if !ok {
+		return "", false, nil
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to read %s_FILE: %w", name, err)
+	}
+
+	return strings.TrimRight(string(b), "\r\n"), true, nil
+}
+
+func setValue(field reflect.Value, value string) error {
+	switch {
+	case field.Kind() == reflect.String:
+		field.SetString(value)
+	case field.Kind() == reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+
+		field.SetBool(b)
+	case field.Kind() == reflect.Int:
+		i, err := strconv.Atoi(value)
+		if err != nil {
+			return err
+		}
+
+		field.SetInt(int64(i))
+	case field.Kind() == reflect.Float64:
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return err
+		}
+
+		field.SetFloat(f)
+	case field.Kind() == reflect.Slice && field.Type().Elem().Kind() == reflect.String:
+		field.Set(reflect.ValueOf(parseList(value)))
+	default:
+		target := reflect.New(field.Type())
+		if err := json.Unmarshal([]byte(value), target.Interface()); err != nil {
+			return err
+		}
+
+		field.Set(target.Elem())
+	}
+
+	return nil
+}
+
+// parseList parses a comma separated list, e.g.
+// "https://a.example.com, https://b.example.com".
+func parseList(value string) []string {
+	var list []string
+
+	for item := range strings.SplitSeq(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+
+	return list
+}
@@ -0,0 +1,75 @@
+package settings_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/SecurityBrewery/catalyst/app/settings"
+)
+
+func TestApplyEnv(t *testing.T) {
+	secretFile := filepath.Join(t.TempDir(), "smtp_password")
+	require.NoError(t, os.WriteFile(secretFile, []byte("from-file\n"), 0o600))
+
+	t.Setenv("CATALYST_APP_URL", "https://catalyst.example.com")
+	t.Setenv("CATALYST_SMTP_PORT", "2525")
+	t.Setenv("CATALYST_SMTP_TLS", "true")
+	t.Setenv("CATALYST_SMTP_PASSWORD_FILE", secretFile)
+	t.Setenv("CATALYST_CORS_ALLOWED_ORIGINS", "https://portal.example.com, https://*.example.org")
+
+	s := &settings.Settings{}
+	require.NoError(t, settings.ApplyEnv(s))
+
+	assert.Equal(t, "https://catalyst.example.com", s.Meta.AppURL)
+	assert.Equal(t, 2525, s.SMTP.Port)
+	assert.True(t, s.SMTP.TLS)
+	assert.Equal(t, "from-file", s.SMTP.Password)
+	assert.Equal(t, []string{"https://portal.example.com", "https://*.example.org"}, s.CORS.AllowedOrigins)
+
+	assert.ElementsMatch(t, []string{
+		"CATALYST_APP_URL",
+		"CATALYST_SMTP_PORT",
+		"CATALYST_SMTP_TLS",
+		"CATALYST_SMTP_PASSWORD_FILE",
+		"CATALYST_CORS_ALLOWED_ORIGINS",
+	}, settings.EnvOverrides())
+
+	// environment variables take precedence over files
+	t.Setenv("CATALYST_SMTP_PASSWORD", "from-env")
+	require.NoError(t, settings.ApplyEnv(s))
+	assert.Equal(t, "from-env", s.SMTP.Password)
+}
+
+func TestApplyEnv_AllSettings(t *testing.T) {
+	t.Setenv("CATALYST_WEB_PUSH_VAPID_PRIVATE_KEY", "private")
+	t.Setenv("CATALYST_ANOMALY_DETECTION_SENSITIVITY", "2.5")
+	t.Setenv("CATALYST_META_DIGEST_TEMPLATE_SUBJECT", "Digest")
+	t.Setenv("CATALYST_BACKUP_NOTIFICATION_HEADERS", `{"Authorization":"Bearer token"}`)
+	t.Setenv("CATALYST_RATE_LIMITS", `[{"host":"example.com","requests":10,"period":60}]`)
+	t.Setenv("CATALYST_META_APP_URL", "https://canonical.example.com")
+	t.Setenv("CATALYST_APP_URL", "https://alias.example.com")
+
+	s := &settings.Settings{}
+	require.NoError(t, settings.ApplyEnv(s))
+
+	assert.Equal(t, "private", s.WebPush.VAPIDPrivateKey)
+	assert.InDelta(t, 2.5, s.AnomalyDetection.Sensitivity, 0)
+	assert.Equal(t, "Digest", s.Meta.DigestTemplate.Subject)
+	assert.Equal(t, map[string]string{"Authorization": "Bearer token"}, s.BackupNotification.Headers)
+	assert.Equal(t, []settings.RateLimit{{Host: "example.com", Requests: 10, Period: 60}}, s.RateLimits)
+
+	// the full name takes precedence over the alias
+	assert.Equal(t, "https://canonical.example.com", s.Meta.AppURL)
+}
+
+func TestApplyEnv_Invalid(t *testing.T) {
+	t.Setenv("CATALYST_SMTP_PORT", "not-a-number")
+
+	err := settings.ApplyEnv(&settings.Settings{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "CATALYST_SMTP_PORT")
+}
@@ -0,0 +1,59 @@
+package settings
+
+import (
+	"encoding/json"
+	"reflect"
+)
+
+// Redacted returns a copy of the settings with the values of all fields
+// tagged as secret replaced by the mask. Empty secrets stay empty, so that
+// unset secrets can be told apart. The values of secret maps are replaced
+// one by one, e.g. the headers that carry API keys.
+func Redacted(s *Settings, mask string) (*Settings, error) {
+	// a JSON round trip copies the lists and maps of the cached settings
+	data, err := json.Marshal(s)
+	if err != nil {
+		return nil, err
+	}
+
+	var redacted Settings
+	if err := json.Unmarshal(data, &redacted); err != nil {
+		return nil, err
+	}
+
+	redact(reflect.ValueOf(&redacted).Elem(), mask)
+
+	return &redacted, nil
+}
+
+func redact(v reflect.Value, mask string) {
+	switch v.Kind() { //nolint:exhaustive // other kinds hold no secrets
+	case reflect.Struct:
+		for i := range v.NumField() {
+			field := v.Field(i)
+
+			if v.Type().Field(i).Tag.Get("secret") == "true" {
+				redactValue(field, mask)
+			} else {
+				redact(field, mask)
+			}
+		}
+	case reflect.Slice:
+		for i := range v.Len() {
+			redact(v.Index(i), mask)
+		}
+	}
+}
+
+func redactValue(v reflect.Value, mask string) {
+	switch v.Kind() { //nolint:exhaustive // secrets are strings or maps of strings
+	case reflect.String:
+		if v.String() != "" {
+			v.SetString(mask)
+		}
+	case reflect.Map:
+		for _, key := range v.MapKeys() {
+			v.SetMapIndex(key, reflect.ValueOf(mask))
+		}
+	}
+}
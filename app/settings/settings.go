@@ -7,16 +7,36 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"reflect"
 
 	"github.com/SecurityBrewery/catalyst/app/database/sqlc"
 )
 
 type Settings struct {
-	Meta                     Meta        `json:"meta"`
-	SMTP                     SMTP        `json:"smtp"`
-	RecordAuthToken          TokenConfig `json:"recordAuthToken"`
-	RecordPasswordResetToken TokenConfig `json:"recordPasswordResetToken"`
-	RecordVerificationToken  TokenConfig `json:"recordVerificationToken"`
+	Meta                     Meta               `json:"meta"`
+	SMTP                     SMTP               `json:"smtp"`
+	RecordAuthToken          TokenConfig        `json:"recordAuthToken"`
+	RecordPasswordResetToken TokenConfig        `json:"recordPasswordResetToken"`
+	RecordVerificationToken  TokenConfig        `json:"recordVerificationToken"`
+	Branding                 Branding           `json:"branding"`
+	Intake                   Intake             `json:"intake"`
+	Slack                    Slack              `json:"slack"`
+	WebPush                  WebPush            `json:"webPush"`
+	MetricsExport            MetricsExport      `json:"metricsExport"`
+	AnomalyDetection         AnomalyDetection   `json:"anomalyDetection"`
+	RateLimits               []RateLimit        `json:"rateLimits"`
+	EnrichmentCache          EnrichmentCache    `json:"enrichmentCache"`
+	CampaignDetection        CampaignDetection  `json:"campaignDetection"`
+	CVEEnrichment            CVEEnrichment      `json:"cveEnrichment"`
+	DLP                      DLP                `json:"dlp"`
+	CORS                     CORS               `json:"cors"`
+	PasswordPolicy           PasswordPolicy     `json:"passwordPolicy"`
+	Logs                     Logs               `json:"logs"`
+	BackupStorage            BackupStorage      `json:"backupStorage"`
+	BackupRetention          BackupRetention    `json:"backupRetention"`
+	BackupNotification       BackupNotification `json:"backupNotification"`
+	LogSinks                 []LogSink          `json:"logSinks"`
+	PlatformTickets          PlatformTickets    `json:"platformTickets"`
 }
 
 type Meta struct {
@@ -25,6 +45,276 @@ type Meta struct {
 	SenderName            string        `json:"senderName"`
 	SenderAddress         string        `json:"senderAddress"`
 	ResetPasswordTemplate EmailTemplate `json:"resetPasswordTemplate"`
+	// DigestTemplate is rendered with html/template, unlike the other
+	// templates, because the digest contains lists of tickets.
+	DigestTemplate EmailTemplate `json:"digestTemplate"`
+}
+
+type Branding struct {
+	Title       string `json:"title"`
+	Logo        string `json:"logo"`
+	LoginBanner string `json:"loginBanner"`
+	CustomCSS   string `json:"customCss"`
+}
+
+// Intake configures the public form to report tickets without an account.
+type Intake struct {
+	Enabled bool   `json:"enabled"`
+	Type    string `json:"type"`
+}
+
+// Slack configures the Slack app used to create tickets from Slack and to
+// mirror comments into Slack threads.
+type Slack struct {
+	Enabled       bool   `json:"enabled"`
+	SigningSecret string `json:"signingSecret" secret:"true"`
+	BotToken      string `json:"botToken" secret:"true"`
+	TicketType    string `json:"ticketType"`
+}
+
+// WebPush holds the VAPID key pair that identifies this server to push
+// services. It is generated on first use.
+type WebPush struct {
+	VAPIDPublicKey  string `json:"vapidPublicKey"`
+	VAPIDPrivateKey string `json:"vapidPrivateKey" secret:"true"`
+}
+
+// MetricsExport configures the periodic export of ticket, task and job
+// facts as CSV files to an S3 compatible bucket.
+type MetricsExport struct {
+	Enabled bool `json:"enabled"`
+	// Interval between two exports in hours.
+	Interval        int    `json:"interval"`
+	Endpoint        string `json:"endpoint"`
+	Region          string `json:"region"`
+	Bucket          string `json:"bucket"`
+	Prefix          string `json:"prefix"`
+	AccessKeyID     string `json:"accessKeyId"`
+	SecretAccessKey string `json:"secretAccessKey" secret:"true"`
+}
+
+// BackupStorage configures the S3 compatible bucket, the Azure Blob Storage
+// container, the Google Cloud Storage bucket and the directory that backups
+// can be streamed to instead of the backups folder. The S3 bucket is disabled
+// without an endpoint, the container without an account, the GCS bucket
+// without a name, the directory without a path.
+type BackupStorage struct {
+	Endpoint        string `json:"endpoint"`
+	Region          string `json:"region"`
+	Bucket          string `json:"bucket"`
+	AccessKeyID     string `json:"accessKeyId"`
+	SecretAccessKey string `json:"secretAccessKey" secret:"true"`
+	// Directory is an absolute path, like a mounted network share, for
+	// deployments without an S3 compatible store.
+	Directory string `json:"directory"`
+
+	AzureAccount string `json:"azureAccount"`
+	// AzureAccountKey is the base64 encoded key of the storage account.
+	AzureAccountKey string `json:"azureAccountKey" secret:"true"`
+	AzureContainer  string `json:"azureContainer"`
+	// AzureEndpoint replaces https://<account>.blob.core.windows.net, e.g.
+	// for the Azurite emulator.
+	AzureEndpoint string `json:"azureEndpoint"`
+
+	// GCSBucket is accessed with the HMAC key of a service account through
+	// the S3 compatible XML API of Google Cloud Storage.
+	GCSBucket      string `json:"gcsBucket"`
+	GCSAccessKeyID string `json:"gcsAccessKeyId"`
+	GCSSecret      string `json:"gcsSecret" secret:"true"`
+	// GCSEndpoint replaces https://storage.googleapis.com, e.g. for an
+	// emulator.
+	GCSEndpoint string `json:"gcsEndpoint"`
+}
+
+// BackupRetention configures which backups of the backups folder are kept,
+// the others are deleted. With both counts zero, all backups are kept.
+type BackupRetention struct {
+	// Daily is the number of days of which the newest backup is kept.
+	Daily int `json:"daily"`
+	// Weekly is the number of weeks of which the newest backup is kept.
+	Weekly int `json:"weekly"`
+}
+
+// BackupNotification configures the webhook that is called when a backup
+// finishes or fails. It is disabled without a URL.
+type BackupNotification struct {
+	// URL is secret, the URLs of Slack incoming webhooks are credentials.
+	URL string `json:"url" secret:"true"`
+	// Slack posts a message for Slack incoming webhooks instead of the
+	// backup as JSON.
+	Slack   bool              `json:"slack"`
+	Headers map[string]string `json:"headers" secret:"true"`
+}
+
+// AnomalyDetection configures the detection of ticket volume spikes and
+// silent ticket sources. Zero values use the defaults of the anomaly package.
+type AnomalyDetection struct {
+	Enabled bool `json:"enabled"`
+	// TicketType of the alert tickets.
+	TicketType string `json:"ticketType"`
+	// Sensitivity is the number of standard deviations above the mean
+	// hourly volume that counts as a spike.
+	Sensitivity float64 `json:"sensitivity"`
+	// MinCount is the minimum number of tickets of a spike, and of the
+	// expected tickets of a silent source.
+	MinCount int `json:"minCount"`
+	// SilenceHours without tickets after which a source counts as silent.
+	SilenceHours int `json:"silenceHours"`
+}
+
+// PlatformTickets configures the tickets that are opened for critical errors
+// of Catalyst itself. Zero values use the defaults of the platform package.
+type PlatformTickets struct {
+	Enabled bool `json:"enabled"`
+	// TicketType of the platform tickets.
+	TicketType string `json:"ticketType"`
+}
+
+// RateLimit limits the outbound requests of reactions, so that alert storms
+// do not get API keys banned. A host limit applies to the webhook requests to
+// that host, a reaction limit to all runs of the reaction, which covers
+// scripts that call external APIs themselves.
+type RateLimit struct {
+	Host     string `json:"host"`
+	Reaction string `json:"reaction"`
+	// Requests allowed per period.
+	Requests int `json:"requests"`
+	// Period in seconds.
+	Period int `json:"period"`
+}
+
+// EnrichmentCache configures how long enrichment results are cached. Zero
+// values use the default of the enrichment package.
+type EnrichmentCache struct {
+	// DefaultTTL in seconds.
+	DefaultTTL int `json:"defaultTtl"`
+	// TTLs in seconds per enricher.
+	TTLs map[string]int `json:"ttls"`
+}
+
+// CampaignDetection configures the grouping of tickets that share a sender,
+// subject, URL or payload hash into campaigns. Zero values use the defaults
+// of the campaign package.
+type CampaignDetection struct {
+	Enabled bool `json:"enabled"`
+	// Window in hours in which tickets must be created to be grouped.
+	Window int `json:"window"`
+	// MinTickets that share a value before a campaign is created.
+	MinTickets int `json:"minTickets"`
+}
+
+// CVEEnrichment configures the lookup of CVEs referenced in tickets. Empty
+// URLs use the public services, which can be replaced with internal mirrors.
+type CVEEnrichment struct {
+	Enabled   bool   `json:"enabled"`
+	NVDURL    string `json:"nvdUrl"`
+	NVDAPIKey string `json:"nvdApiKey" secret:"true"`
+	OSVURL    string `json:"osvUrl"`
+	KEVURL    string `json:"kevUrl"`
+}
+
+// DLP holds the data loss prevention rules that are checked before tickets
+// leave the instance in an export.
+type DLP struct {
+	Rules []DLPRule `json:"rules"`
+}
+
+// DLPRule blocks exports that contain a ticket with one of the TLP levels
+// or tags of the rule. Exports limits the rule to these kinds of exports,
+// an empty list applies it to all of them.
+type DLPRule struct {
+	Name    string   `json:"name"`
+	TLP     []string `json:"tlp,omitempty"`
+	Tags    []string `json:"tags,omitempty"`
+	Exports []string `json:"exports,omitempty"`
+}
+
+// CORS configures which browser origins may call the API, e.g. internal
+// portals that embed Catalyst data or a separately hosted UI. Without allowed
+// origins every origin may call the API without credentials.
+type CORS struct {
+	// AllowedOrigins like "https://portal.example.com", "*" or
+	// "https://*.example.com" for all subdomains.
+	AllowedOrigins []string `json:"allowedOrigins"`
+	// AllowedHeaders the browser may send. Empty allows the requested headers.
+	AllowedHeaders []string `json:"allowedHeaders"`
+	// AllowCredentials allows cookies and authorization headers from the
+	// allowed origins.
+	AllowCredentials bool `json:"allowCredentials"`
+	// MaxAge in seconds browsers may cache a preflight response.
+	MaxAge int `json:"maxAge"`
+}
+
+// PasswordPolicy configures the rules for the passwords of local users.
+// Zero values disable a rule, except MinLength which uses the default of the
+// password package.
+type PasswordPolicy struct {
+	MinLength        int  `json:"minLength"`
+	RequireUppercase bool `json:"requireUppercase"`
+	RequireLowercase bool `json:"requireLowercase"`
+	RequireDigit     bool `json:"requireDigit"`
+	RequireSymbol    bool `json:"requireSymbol"`
+	// History is the number of previous passwords that must not be reused.
+	History int `json:"history"`
+	// MaxAge in days after which a password expires and must be reset.
+	MaxAge int `json:"maxAge"`
+	// BreachCheck rejects passwords that are known from data breaches. Only
+	// the first five characters of the SHA-1 hash of a password are sent.
+	BreachCheck bool `json:"breachCheck"`
+	// BreachCheckURL of a Have I Been Pwned compatible range API, empty uses
+	// the public service.
+	BreachCheckURL string `json:"breachCheckUrl"`
+}
+
+// Logs configures the application log that is kept in the database. Zero
+// values use the defaults of the applog package.
+type Logs struct {
+	// Level is the minimum level of the stored records, like "info".
+	Level string `json:"level"`
+	// RetentionDays after which records are deleted.
+	RetentionDays int `json:"retentionDays"`
+}
+
+// Types of log sinks.
+const (
+	LogSinkSyslog = "syslog"
+	LogSinkHTTP   = "http"
+	LogSinkFile   = "file"
+)
+
+// Streams a log sink can forward.
+const (
+	LogStreamApp   = "app"
+	LogStreamAudit = "audit"
+)
+
+// LogSink forwards the application log and the audit log of record changes
+// to an external system like a SIEM.
+type LogSink struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+	// Streams are "app" and "audit", both if empty.
+	Streams []string `json:"streams"`
+	// Level is the minimum level of application log records, "info" if
+	// empty.
+	Level string `json:"level"`
+
+	// Address of a syslog server that accepts RFC 5424 messages over TLS,
+	// like siem.example.com:6514.
+	Address string `json:"address"`
+	// CA is a PEM encoded certificate to verify the syslog server with,
+	// instead of the system certificates.
+	CA string `json:"ca"`
+
+	// URL of an HTTP endpoint that receives batches of records as JSON.
+	URL     string            `json:"url"`
+	Headers map[string]string `json:"headers" secret:"true"`
+
+	// MaxSizeMB of a log file before it is rotated, and the number of
+	// rotated files to keep. Files are written to the logs folder of the
+	// data directory.
+	MaxSizeMB int `json:"maxSizeMb"`
+	MaxFiles  int `json:"maxFiles"`
 }
 
 type EmailTemplate struct {
@@ -37,24 +327,46 @@ type SMTP struct {
 	Host       string `json:"host"`
 	Port       int    `json:"port"`
 	Username   string `json:"username"`
-	Password   string `json:"password"`
+	Password   string `json:"password" secret:"true"`
 	AuthMethod string `json:"authMethod"`
 	TLS        bool   `json:"tls"`
 	LocalName  string `json:"localName"`
 }
 
 type TokenConfig struct {
-	Secret   string `json:"secret"`
+	Secret   string `json:"secret" secret:"true"`
 	Duration int    `json:"duration"`
 }
 
+// Load returns the stored settings, overridden by the environment, see
+// envSettings.
 func Load(ctx context.Context, queries *sqlc.Queries) (*Settings, error) {
-	param, err := queries.Param(ctx, "settings")
+	settings, err := load(ctx, queries)
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return initSettings(ctx, queries)
+		return nil, err
+	}
+
+	if err := ApplyEnv(settings); err != nil {
+		return nil, err
+	}
+
+	return settings, nil
+}
+
+// load returns the stored settings without the environment. The default
+// settings are stored on first use, the stored settings are read again as
+// another caller may have stored them first.
+func load(ctx context.Context, queries *sqlc.Queries) (*Settings, error) {
+	param, err := queries.Param(ctx, "settings")
+	if errors.Is(err, sql.ErrNoRows) {
+		if err := initSettings(ctx, queries); err != nil {
+			return nil, fmt.Errorf("failed to store default settings: %w", err)
 		}
 
+		param, err = queries.Param(ctx, "settings")
+	}
+
+	if err != nil {
 		return nil, fmt.Errorf("failed to get settings: %w", err)
 	}
 
@@ -77,6 +389,24 @@ const (
 <p>
   Thanks,<br/>
   {APP_NAME} team
+</p>`
+	DigestTemplateSubject = `Your {{ .Frequency }} {{ .AppName }} digest`
+	DigestTemplateBody    = `<p>Hello {{ .User }},</p>
+{{ if .Tickets }}<p>Your open tickets:</p>
+<ul>
+{{ range .Tickets }}  <li><a href="{{ .URL }}">{{ .Name }}</a></li>
+{{ end }}</ul>
+{{ end }}{{ if .Deadlines }}<p>Deadlines before your next digest:</p>
+<ul>
+{{ range .Deadlines }}  <li>{{ .Task }} in <a href="{{ .URL }}">{{ .Ticket }}</a> is due {{ .Due.Format "2006-01-02 15:04" }}</li>
+{{ end }}</ul>
+{{ end }}{{ if .Mentions }}<p>You were mentioned since {{ .Since.Format "2006-01-02 15:04" }}:</p>
+<ul>
+{{ range .Mentions }}  <li>{{ .Author }} in <a href="{{ .URL }}">{{ .Ticket }}</a>: {{ .Message }}</li>
+{{ end }}</ul>
+{{ end }}<p>
+  Thanks,<br/>
+  {{ .AppName }} team
 </p>`
 	verificationTemplateBody = `<p>Hello,</p>
 <p>Thank you for joining us at {APP_NAME}.</p>
@@ -90,7 +420,7 @@ const (
 </p>`
 )
 
-func initSettings(ctx context.Context, queries *sqlc.Queries) (*Settings, error) {
+func initSettings(ctx context.Context, queries *sqlc.Queries) error {
 	s := &Settings{
 		Meta: Meta{
 			AppName:       "Catalyst",
@@ -101,6 +431,10 @@ func initSettings(ctx context.Context, queries *sqlc.Queries) (*Settings, error)
 				Subject: "Reset your {APP_NAME} password",
 				Body:    resetPasswordTemplateBody,
 			},
+			DigestTemplate: EmailTemplate{
+				Subject: DigestTemplateSubject,
+				Body:    DigestTemplateBody,
+			},
 		},
 		SMTP: SMTP{
 			Host: "smtp.example.com",
@@ -122,27 +456,72 @@ func initSettings(ctx context.Context, queries *sqlc.Queries) (*Settings, error)
 
 	b, err := json.Marshal(s)
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal default settings: %w", err)
+		return fmt.Errorf("failed to marshal default settings: %w", err)
 	}
 
-	if err := queries.CreateParam(ctx, sqlc.CreateParamParams{
+	return queries.InitParam(ctx, sqlc.InitParamParams{
 		Key:   "settings",
 		Value: b,
-	}); err != nil {
+	})
+}
+
+// Update changes the stored settings. The update sees the settings as Load
+// returns them, but values that come from the environment are not stored
+// unless the update changes them. The settings are read and written in a
+// transaction, so concurrent updates do not lose changes.
+func Update(ctx context.Context, queries *sqlc.Queries, update func(settings *Settings)) (*Settings, error) {
+	tx, err := queries.WriteDB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck // fails after the commit
+
+	settings, err := updateStored(ctx, queries.WithTx(tx), update)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit settings: %w", err)
+	}
+
+	if err := ApplyEnv(settings); err != nil {
 		return nil, err
 	}
 
-	return s, nil
+	return settings, nil
 }
 
-func Update(ctx context.Context, queries *sqlc.Queries, update func(settings *Settings)) (*Settings, error) {
-	settings, err := Load(ctx, queries)
+func updateStored(ctx context.Context, queries *sqlc.Queries, update func(settings *Settings)) (*Settings, error) {
+	stored, err := load(ctx, queries)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load settings: %w", err)
 	}
 
+	settings, err := clone(stored)
+	if err != nil {
+		return nil, err
+	}
+
+	overridden, err := applyEnv(settings)
+	if err != nil {
+		return nil, err
+	}
+
+	fromEnv, err := clone(settings)
+	if err != nil {
+		return nil, err
+	}
+
 	update(settings)
 
+	current, env, previous := reflect.ValueOf(settings).Elem(), reflect.ValueOf(fromEnv).Elem(), reflect.ValueOf(stored).Elem()
+	for _, setting := range overridden {
+		if reflect.DeepEqual(current.FieldByIndex(setting.index).Interface(), env.FieldByIndex(setting.index).Interface()) {
+			current.FieldByIndex(setting.index).Set(previous.FieldByIndex(setting.index))
+		}
+	}
+
 	data, err := json.Marshal(settings)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal settings: %w", err)
@@ -157,3 +536,17 @@ func Update(ctx context.Context, queries *sqlc.Queries, update func(settings *Se
 
 	return settings, nil
 }
+
+func clone(settings *Settings) (*Settings, error) {
+	data, err := json.Marshal(settings)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal settings: %w", err)
+	}
+
+	var c Settings
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal settings: %w", err)
+	}
+
+	return &c, nil
+}
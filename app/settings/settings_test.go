@@ -1,6 +1,7 @@
 package settings_test
 
 import (
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -26,3 +27,79 @@ func TestUpdateSettings(t *testing.T) {
 	require.Equal(t, "UpdatedApp", got.Meta.AppName, "AppName should match after saving and loading settings")
 	require.Equal(t, "https://example.com", got.Meta.AppURL, "AppURL should match after saving and loading settings")
 }
+
+func TestUpdateSettings_Env(t *testing.T) {
+	t.Setenv("CATALYST_SMTP_PASSWORD", "from-env")
+	t.Setenv("CATALYST_APP_NAME", "EnvApp")
+
+	queries := data.NewTestDB(t, t.TempDir())
+
+	got, err := settings.Load(t.Context(), queries)
+	require.NoError(t, err)
+	require.Equal(t, "from-env", got.SMTP.Password)
+
+	// saving the loaded settings does not store the environment
+	updated, err := settings.Update(t.Context(), queries, func(settings *settings.Settings) {
+		settings.SMTP.Host = "smtp.example.org"
+	})
+	require.NoError(t, err)
+	require.Equal(t, "from-env", updated.SMTP.Password)
+
+	param, err := queries.Param(t.Context(), "settings")
+	require.NoError(t, err)
+	require.NotContains(t, string(param.Value), "from-env")
+	require.NotContains(t, string(param.Value), "EnvApp")
+	require.Contains(t, string(param.Value), "smtp.example.org")
+}
+
+func TestUpdateSettings_Concurrent(t *testing.T) {
+	t.Parallel()
+
+	queries := data.NewTestDB(t, t.TempDir())
+
+	_, err := queries.WriteDB.ExecContext(t.Context(), "DELETE FROM _params WHERE key = 'settings'")
+	require.NoError(t, err)
+
+	// the default settings are stored once
+	var wg sync.WaitGroup
+
+	errs := make(chan error, 20)
+
+	for range 10 {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			_, err := settings.Load(t.Context(), queries)
+			errs <- err
+		}()
+	}
+
+	wg.Wait()
+
+	// concurrent updates do not lose changes
+	for range 10 {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			_, err := settings.Update(t.Context(), queries, func(settings *settings.Settings) {
+				settings.Meta.AppName += "+"
+			})
+			errs <- err
+		}()
+	}
+
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		require.NoError(t, err)
+	}
+
+	got, err := settings.Load(t.Context(), queries)
+	require.NoError(t, err)
+	require.Equal(t, "Catalyst++++++++++", got.Meta.AppName)
+}
@@ -0,0 +1,298 @@
+package settings
+
+import (
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/mail"
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+const minSecretLength = 16
+
+// Validate checks the settings for misconfigurations and returns all found
+// problems joined into a single error.
+func (s *Settings) Validate() error {
+	var errs []error
+
+	if u, err := url.Parse(s.Meta.AppURL); err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		errs = append(errs, fmt.Errorf("meta.appUrl %q must be an absolute http(s) URL", s.Meta.AppURL))
+	}
+
+	if _, err := mail.ParseAddress(s.Meta.SenderAddress); err != nil {
+		errs = append(errs, fmt.Errorf("meta.senderAddress %q is not a valid email address", s.Meta.SenderAddress))
+	}
+
+	errs = append(errs,
+		s.RecordAuthToken.validate("recordAuthToken"),
+		s.RecordPasswordResetToken.validate("recordPasswordResetToken"),
+		s.RecordVerificationToken.validate("recordVerificationToken"),
+	)
+
+	if s.SMTP.Enabled {
+		if s.SMTP.Host == "" {
+			errs = append(errs, errors.New("smtp.host must be set when SMTP is enabled"))
+		}
+
+		if s.SMTP.Port <= 0 || s.SMTP.Port > 65535 {
+			errs = append(errs, fmt.Errorf("smtp.port %d is not a valid port", s.SMTP.Port))
+		}
+
+		if s.SMTP.Username == "" || s.SMTP.Password == "" {
+			errs = append(errs, errors.New("smtp.username and smtp.password must be set when SMTP is enabled"))
+		}
+	}
+
+	errs = append(errs, ValidateRateLimits(s.RateLimits), ValidateCORS(s.CORS), ValidatePasswordPolicy(s.PasswordPolicy), ValidateLogs(s.Logs), ValidateBackupStorage(s.BackupStorage), ValidateBackupRetention(s.BackupRetention), ValidateBackupNotification(s.BackupNotification), ValidateLogSinks(s.LogSinks))
+
+	return errors.Join(errs...)
+}
+
+// ValidateBackupStorage checks the endpoint of the backup bucket, the Azure
+// container, the GCS bucket and the backup directory, if they are configured.
+func ValidateBackupStorage(b BackupStorage) error {
+	var errs []error
+
+	if b.Directory != "" && !filepath.IsAbs(b.Directory) {
+		errs = append(errs, fmt.Errorf("backupStorage.directory %q must be an absolute path", b.Directory))
+	}
+
+	if b.AzureAccount != "" {
+		if b.AzureContainer == "" {
+			errs = append(errs, errors.New("backupStorage.azureContainer must be set when the Azure account is configured"))
+		}
+
+		if _, err := base64.StdEncoding.DecodeString(b.AzureAccountKey); err != nil || b.AzureAccountKey == "" {
+			errs = append(errs, errors.New("backupStorage.azureAccountKey must be the base64 encoded key of the storage account"))
+		}
+
+		if u, err := url.Parse(b.AzureEndpoint); b.AzureEndpoint != "" && (err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https")) {
+			errs = append(errs, fmt.Errorf("backupStorage.azureEndpoint %q must be an absolute http(s) URL", b.AzureEndpoint))
+		}
+	}
+
+	if b.GCSBucket != "" {
+		if b.GCSAccessKeyID == "" || b.GCSSecret == "" {
+			errs = append(errs, errors.New("backupStorage.gcsAccessKeyId and backupStorage.gcsSecret must be set when the GCS bucket is configured"))
+		}
+
+		if u, err := url.Parse(b.GCSEndpoint); b.GCSEndpoint != "" && (err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https")) {
+			errs = append(errs, fmt.Errorf("backupStorage.gcsEndpoint %q must be an absolute http(s) URL", b.GCSEndpoint))
+		}
+	}
+
+	if b.Endpoint == "" {
+		return errors.Join(errs...)
+	}
+
+	if u, err := url.Parse(b.Endpoint); err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		errs = append(errs, fmt.Errorf("backupStorage.endpoint %q must be an absolute http(s) URL", b.Endpoint))
+	}
+
+	if b.Bucket == "" || b.Region == "" {
+		errs = append(errs, errors.New("backupStorage.bucket and backupStorage.region must be set when the backup storage is configured"))
+	}
+
+	return errors.Join(errs...)
+}
+
+// ValidateBackupRetention checks that the retention counts are not negative.
+func ValidateBackupRetention(r BackupRetention) error {
+	if r.Daily < 0 || r.Weekly < 0 {
+		return errors.New("backupRetention.daily and backupRetention.weekly must not be negative")
+	}
+
+	return nil
+}
+
+// ValidateBackupNotification checks the URL of the backup webhook, if it is
+// configured.
+func ValidateBackupNotification(n BackupNotification) error {
+	if n.URL == "" {
+		return nil
+	}
+
+	if u, err := url.Parse(n.URL); err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		return fmt.Errorf("backupNotification.url %q must be an absolute http(s) URL", n.URL)
+	}
+
+	return nil
+}
+
+// logSinkName is the name of a log sink, which is also the name of its file.
+var logSinkName = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,63}$`)
+
+// ValidateLogSinks checks that the log sinks have unique names and the
+// destination of their type.
+func ValidateLogSinks(sinks []LogSink) error {
+	var errs []error
+
+	names := map[string]bool{}
+
+	for i, sink := range sinks {
+		name := fmt.Sprintf("logSinks[%d]", i)
+
+		if !logSinkName.MatchString(sink.Name) {
+			errs = append(errs, fmt.Errorf("%s.name %q must be lowercase letters, digits, - and _", name, sink.Name))
+		} else if names[sink.Name] {
+			errs = append(errs, fmt.Errorf("%s.name %q is used twice", name, sink.Name))
+		}
+
+		names[sink.Name] = true
+
+		errs = append(errs, sink.validate(name))
+	}
+
+	return errors.Join(errs...)
+}
+
+func (s LogSink) validate(name string) error {
+	var errs []error
+
+	for _, stream := range s.Streams {
+		if stream != LogStreamApp && stream != LogStreamAudit {
+			errs = append(errs, fmt.Errorf("%s.streams %q must be app or audit", name, stream))
+		}
+	}
+
+	var level slog.Level
+	if s.Level != "" && level.UnmarshalText([]byte(s.Level)) != nil {
+		errs = append(errs, fmt.Errorf("%s.level %q must be debug, info, warn or error", name, s.Level))
+	}
+
+	switch s.Type {
+	case LogSinkSyslog:
+		if _, port, err := net.SplitHostPort(s.Address); err != nil || port == "" {
+			errs = append(errs, fmt.Errorf("%s.address %q must be a host and port", name, s.Address))
+		}
+
+		if s.CA != "" {
+			if block, _ := pem.Decode([]byte(s.CA)); block == nil {
+				errs = append(errs, fmt.Errorf("%s.ca must be a PEM encoded certificate", name))
+			}
+		}
+	case LogSinkHTTP:
+		if u, err := url.Parse(s.URL); err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+			errs = append(errs, fmt.Errorf("%s.url %q must be an absolute http(s) URL", name, s.URL))
+		}
+	case LogSinkFile:
+		if s.MaxSizeMB < 0 || s.MaxFiles < 0 {
+			errs = append(errs, fmt.Errorf("%s.maxSizeMb and %s.maxFiles must not be negative", name, name))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("%s.type %q must be syslog, http or file", name, s.Type))
+	}
+
+	return errors.Join(errs...)
+}
+
+// ValidateLogs checks the level of the stored records and the retention.
+func ValidateLogs(l Logs) error {
+	var errs []error
+
+	var level slog.Level
+	if l.Level != "" && level.UnmarshalText([]byte(l.Level)) != nil {
+		errs = append(errs, fmt.Errorf("logs.level %q must be debug, info, warn or error", l.Level))
+	}
+
+	if l.RetentionDays < 0 {
+		errs = append(errs, errors.New("logs.retentionDays must not be negative"))
+	}
+
+	return errors.Join(errs...)
+}
+
+// ValidateRateLimits checks that each rate limit has either a host or a
+// reaction and a positive rate.
+func ValidateRateLimits(limits []RateLimit) error {
+	var errs []error
+
+	for i, limit := range limits {
+		errs = append(errs, limit.validate(fmt.Sprintf("rateLimits[%d]", i)))
+	}
+
+	return errors.Join(errs...)
+}
+
+// ValidateCORS checks that the allowed origins are "*" or origins without a
+// path, and that credentials are only allowed for explicit origins.
+func ValidateCORS(c CORS) error {
+	var errs []error
+
+	for i, origin := range c.AllowedOrigins {
+		if origin == "*" {
+			if c.AllowCredentials {
+				errs = append(errs, fmt.Errorf("cors.allowedOrigins[%d] \"*\" must not be combined with allowCredentials", i))
+			}
+
+			continue
+		}
+
+		u, err := url.Parse(strings.Replace(origin, "*.", "wildcard.", 1))
+		if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") || u.Path != "" || u.RawQuery != "" || u.User != nil {
+			errs = append(errs, fmt.Errorf("cors.allowedOrigins[%d] %q must be an origin like https://portal.example.com", i, origin))
+		}
+	}
+
+	if c.MaxAge < 0 {
+		errs = append(errs, errors.New("cors.maxAge must not be negative"))
+	}
+
+	return errors.Join(errs...)
+}
+
+// ValidatePasswordPolicy checks that the limits of the password policy are
+// not negative and that the breach check URL is absolute.
+func ValidatePasswordPolicy(p PasswordPolicy) error {
+	var errs []error
+
+	if p.MinLength < 0 || p.History < 0 || p.MaxAge < 0 {
+		errs = append(errs, errors.New("passwordPolicy.minLength, history and maxAge must not be negative"))
+	}
+
+	if p.BreachCheckURL != "" {
+		if u, err := url.Parse(p.BreachCheckURL); err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+			errs = append(errs, fmt.Errorf("passwordPolicy.breachCheckUrl %q must be an absolute http(s) URL", p.BreachCheckURL))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (l RateLimit) validate(name string) error {
+	var errs []error
+
+	if (l.Host == "") == (l.Reaction == "") {
+		errs = append(errs, fmt.Errorf("%s must either set a host or a reaction", name))
+	}
+
+	if l.Requests <= 0 {
+		errs = append(errs, fmt.Errorf("%s.requests must be positive", name))
+	}
+
+	if l.Period <= 0 {
+		errs = append(errs, fmt.Errorf("%s.period must be positive", name))
+	}
+
+	return errors.Join(errs...)
+}
+
+func (t TokenConfig) validate(name string) error {
+	var errs []error
+
+	if len(t.Secret) < minSecretLength {
+		errs = append(errs, fmt.Errorf("%s.secret must be at least %d characters long", name, minSecretLength))
+	}
+
+	if t.Duration <= 0 {
+		errs = append(errs, fmt.Errorf("%s.duration must be positive", name))
+	}
+
+	return errors.Join(errs...)
+}
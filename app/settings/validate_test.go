@@ -0,0 +1,155 @@
+package settings_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/SecurityBrewery/catalyst/app/data"
+	"github.com/SecurityBrewery/catalyst/app/settings"
+)
+
+func TestSettings_Validate(t *testing.T) {
+	t.Parallel()
+
+	queries := data.NewTestDB(t, t.TempDir())
+
+	s, err := settings.Load(t.Context(), queries)
+	require.NoError(t, err)
+
+	require.NoError(t, s.Validate(), "default settings should be valid")
+
+	s.Meta.AppURL = "localhost"
+	s.RecordAuthToken.Secret = "short"
+	s.SMTP.Enabled = true
+	s.SMTP.Host = ""
+	s.RateLimits = []settings.RateLimit{
+		{Host: "www.virustotal.com", Requests: 4, Period: 60},
+		{Host: "api.shodan.io", Reaction: "r-enrich", Requests: 1},
+	}
+
+	err = s.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "meta.appUrl")
+	assert.Contains(t, err.Error(), "recordAuthToken.secret")
+	assert.Contains(t, err.Error(), "smtp.host")
+	assert.Contains(t, err.Error(), "smtp.username")
+	assert.NotContains(t, err.Error(), "rateLimits[0]")
+	assert.Contains(t, err.Error(), "rateLimits[1] must either set a host or a reaction")
+	assert.Contains(t, err.Error(), "rateLimits[1].period")
+}
+
+func TestValidateCORS(t *testing.T) {
+	t.Parallel()
+
+	require.NoError(t, settings.ValidateCORS(settings.CORS{}))
+	require.NoError(t, settings.ValidateCORS(settings.CORS{AllowedOrigins: []string{"*"}}))
+	require.NoError(t, settings.ValidateCORS(settings.CORS{
+		AllowedOrigins:   []string{"https://portal.example.com", "https://*.example.org", "http://localhost:3000"},
+		AllowCredentials: true,
+	}))
+
+	err := settings.ValidateCORS(settings.CORS{
+		AllowedOrigins:   []string{"*", "portal.example.com", "https://example.com/ui"},
+		AllowCredentials: true,
+		MaxAge:           -1,
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `cors.allowedOrigins[0] "*" must not be combined with allowCredentials`)
+	assert.Contains(t, err.Error(), `cors.allowedOrigins[1] "portal.example.com"`)
+	assert.Contains(t, err.Error(), `cors.allowedOrigins[2] "https://example.com/ui"`)
+	assert.Contains(t, err.Error(), "cors.maxAge")
+}
+
+func TestValidatePasswordPolicy(t *testing.T) {
+	t.Parallel()
+
+	require.NoError(t, settings.ValidatePasswordPolicy(settings.PasswordPolicy{}))
+	require.NoError(t, settings.ValidatePasswordPolicy(settings.PasswordPolicy{MinLength: 12, History: 5, MaxAge: 90, BreachCheck: true, BreachCheckURL: "https://hibp.example.com"}))
+
+	err := settings.ValidatePasswordPolicy(settings.PasswordPolicy{History: -1, BreachCheckURL: "hibp.example.com"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "must not be negative")
+	assert.Contains(t, err.Error(), "passwordPolicy.breachCheckUrl")
+}
+
+func TestValidateLogs(t *testing.T) {
+	t.Parallel()
+
+	require.NoError(t, settings.ValidateLogs(settings.Logs{}))
+	require.NoError(t, settings.ValidateLogs(settings.Logs{Level: "debug", RetentionDays: 30}))
+
+	err := settings.ValidateLogs(settings.Logs{Level: "verbose", RetentionDays: -1})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `logs.level "verbose"`)
+	assert.Contains(t, err.Error(), "logs.retentionDays")
+}
+
+func TestValidateBackupStorage(t *testing.T) {
+	t.Parallel()
+
+	require.NoError(t, settings.ValidateBackupStorage(settings.BackupStorage{}))
+	require.NoError(t, settings.ValidateBackupStorage(settings.BackupStorage{Endpoint: "https://s3.eu-central-1.amazonaws.com", Region: "eu-central-1", Bucket: "backups"}))
+
+	err := settings.ValidateBackupStorage(settings.BackupStorage{Endpoint: "s3.eu-central-1.amazonaws.com"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "backupStorage.endpoint")
+	assert.Contains(t, err.Error(), "backupStorage.bucket")
+
+	require.NoError(t, settings.ValidateBackupStorage(settings.BackupStorage{AzureAccount: "catalyst", AzureAccountKey: "a2V5", AzureContainer: "backups"}))
+
+	err = settings.ValidateBackupStorage(settings.BackupStorage{AzureAccount: "catalyst", AzureAccountKey: "not base64!", AzureEndpoint: "127.0.0.1:10000"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "backupStorage.azureContainer")
+	assert.Contains(t, err.Error(), "backupStorage.azureAccountKey")
+	assert.Contains(t, err.Error(), "backupStorage.azureEndpoint")
+
+	require.NoError(t, settings.ValidateBackupStorage(settings.BackupStorage{GCSBucket: "backups", GCSAccessKeyID: "GOOG1E", GCSSecret: "secret"}))
+
+	err = settings.ValidateBackupStorage(settings.BackupStorage{GCSBucket: "backups", GCSEndpoint: "storage.googleapis.com"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "backupStorage.gcsAccessKeyId")
+	assert.Contains(t, err.Error(), "backupStorage.gcsEndpoint")
+}
+
+func TestValidateBackupRetention(t *testing.T) {
+	t.Parallel()
+
+	require.NoError(t, settings.ValidateBackupRetention(settings.BackupRetention{}))
+	require.NoError(t, settings.ValidateBackupRetention(settings.BackupRetention{Daily: 7, Weekly: 4}))
+	require.Error(t, settings.ValidateBackupRetention(settings.BackupRetention{Daily: -1}))
+}
+
+func TestValidateBackupNotification(t *testing.T) {
+	t.Parallel()
+
+	require.NoError(t, settings.ValidateBackupNotification(settings.BackupNotification{}))
+	require.NoError(t, settings.ValidateBackupNotification(settings.BackupNotification{URL: "https://hooks.slack.com/services/T0/B0/x", Slack: true}))
+	require.ErrorContains(t, settings.ValidateBackupNotification(settings.BackupNotification{URL: "hooks.slack.com"}), "backupNotification.url")
+}
+
+func TestValidateLogSinks(t *testing.T) {
+	t.Parallel()
+
+	require.NoError(t, settings.ValidateLogSinks([]settings.LogSink{
+		{Name: "siem", Type: settings.LogSinkSyslog, Address: "siem.example.com:6514", Streams: []string{settings.LogStreamAudit}},
+		{Name: "splunk-hec", Type: settings.LogSinkHTTP, URL: "https://splunk.example.com/services/collector", Level: "warn"},
+		{Name: "archive", Type: settings.LogSinkFile, MaxSizeMB: 10},
+	}))
+
+	err := settings.ValidateLogSinks([]settings.LogSink{
+		{Name: "siem", Type: settings.LogSinkSyslog, Address: "siem.example.com", CA: "not a certificate"},
+		{Name: "siem", Type: settings.LogSinkHTTP, URL: "/collector", Streams: []string{"debug"}},
+		{Name: "../archive", Type: "kafka", Level: "verbose"},
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `logSinks[0].address "siem.example.com"`)
+	assert.Contains(t, err.Error(), "logSinks[0].ca")
+	assert.Contains(t, err.Error(), `logSinks[1].name "siem" is used twice`)
+	assert.Contains(t, err.Error(), `logSinks[1].url "/collector"`)
+	assert.Contains(t, err.Error(), `logSinks[1].streams "debug"`)
+	assert.Contains(t, err.Error(), `logSinks[2].name "../archive"`)
+	assert.Contains(t, err.Error(), `logSinks[2].type "kafka"`)
+	assert.Contains(t, err.Error(), `logSinks[2].level "verbose"`)
+}
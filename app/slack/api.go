@@ -0,0 +1,111 @@
+package slack
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+type postMessageRequest struct {
+	Channel  string `json:"channel"`
+	ThreadTS string `json:"thread_ts,omitempty"`
+	Text     string `json:"text"`
+}
+
+type apiResponse struct {
+	OK    bool   `json:"ok"`
+	Error string `json:"error"`
+}
+
+type postMessageResponse struct {
+	apiResponse
+
+	TS string `json:"ts"`
+}
+
+type usersInfoResponse struct {
+	apiResponse
+
+	User struct {
+		Profile struct {
+			Email string `json:"email"`
+		} `json:"profile"`
+	} `json:"user"`
+}
+
+// postMessage posts a message to a channel, or to a thread if threadTS is
+// set, and returns the timestamp of the new message.
+func (s *Slack) postMessage(ctx context.Context, token, channel, threadTS, text string) (string, error) {
+	body, err := json.Marshal(postMessageRequest{
+		Channel:  channel,
+		ThreadTS: threadTS,
+		Text:     text,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.apiURL+"/chat.postMessage", bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+
+	var response postMessageResponse
+	if err := s.call(req, token, &response, &response.apiResponse); err != nil {
+		return "", err
+	}
+
+	return response.TS, nil
+}
+
+// userEmail returns the email address of a Slack user. The app needs the
+// users:read.email scope for this.
+func (s *Slack) userEmail(ctx context.Context, token, user string) (string, error) {
+	form := url.Values{"user": {user}}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.apiURL+"/users.info", strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	var response usersInfoResponse
+	if err := s.call(req, token, &response, &response.apiResponse); err != nil {
+		return "", err
+	}
+
+	if response.User.Profile.Email == "" {
+		return "", errors.New("slack user has no email address")
+	}
+
+	return response.User.Profile.Email, nil
+}
+
+func (s *Slack) call(req *http.Request, token string, response any, status *apiResponse) error {
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	if err := json.NewDecoder(resp.Body).Decode(response); err != nil {
+		return fmt.Errorf("failed to decode Slack response: %w", err)
+	}
+
+	if !status.OK {
+		return errors.New("slack: " + status.Error)
+	}
+
+	return nil
+}
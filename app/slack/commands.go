@@ -0,0 +1,282 @@
+package slack
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/SecurityBrewery/catalyst/app/auth"
+	"github.com/SecurityBrewery/catalyst/app/auth/usercontext"
+	"github.com/SecurityBrewery/catalyst/app/database/sqlc"
+	"github.com/SecurityBrewery/catalyst/app/openapi"
+	"github.com/SecurityBrewery/catalyst/app/playbook"
+	"github.com/SecurityBrewery/catalyst/app/pointer"
+	"github.com/SecurityBrewery/catalyst/app/settings"
+)
+
+const commandUsage = "Usage:\n" +
+	"• `%[1]s <ticket name>` create a ticket\n" +
+	"• `%[1]s assign <ticket id> <username|me>` assign a ticket\n" +
+	"• `%[1]s close <ticket id> [resolution]` close a ticket\n" +
+	"• `%[1]s note <ticket id> <text>` add a comment to a ticket\n" +
+	"• `%[1]s done <task id>` complete a task\n" +
+	"• `%[1]s playbook <ticket id> <playbook id> [input=value ...]` run a playbook on a ticket"
+
+var errUnknownUser = errors.New("unknown user")
+
+// chatCommand is a ChatOps command that is executed as the linked Catalyst
+// user and returns the reply for Slack.
+type chatCommand func(ctx context.Context, user *sqlc.User, args []string) (string, error)
+
+func (s *Slack) chatCommands() map[string]chatCommand {
+	return map[string]chatCommand{
+		"assign":   s.assignCommand,
+		"close":    s.closeCommand,
+		"note":     s.noteCommand,
+		"done":     s.doneCommand,
+		"playbook": s.playbookCommand,
+	}
+}
+
+// runCommand links the Slack user to a Catalyst user by email address and
+// executes the command with the permissions of that user.
+func (s *Slack) runCommand(ctx context.Context, config *settings.Slack, slackUser string, command chatCommand, args []string) string {
+	user, err := s.linkedUser(ctx, config, slackUser)
+	if err != nil {
+		return "Your Slack account is not linked to an active Catalyst user with the same email address."
+	}
+
+	permissions, err := s.queries.ListUserPermissions(ctx, user.ID)
+	if err != nil {
+		return "Failed to load your permissions."
+	}
+
+	if !auth.HasScopes(permissions, []string{auth.TicketWritePermission}) {
+		return "You are missing the " + auth.TicketWritePermission + " permission."
+	}
+
+	ctx = usercontext.UserContext(ctx, user)
+	ctx = usercontext.PermissionContext(ctx, permissions)
+
+	reply, err := command(ctx, user, args)
+
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return "Not found."
+	case errors.Is(err, errUnknownUser):
+		return "Unknown Catalyst user."
+	case err != nil:
+		return "Failed to run the command: " + err.Error()
+	}
+
+	return reply
+}
+
+func (s *Slack) linkedUser(ctx context.Context, config *settings.Slack, slackUser string) (*sqlc.User, error) {
+	email, err := s.userEmail(ctx, config.BotToken, slackUser)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.queries.UserByEmail(ctx, &email)
+	if err != nil {
+		return nil, err
+	}
+
+	if !user.Active {
+		return nil, fmt.Errorf("user %s is inactive", user.ID)
+	}
+
+	return &user, nil
+}
+
+func (s *Slack) assignCommand(ctx context.Context, user *sqlc.User, args []string) (string, error) {
+	if len(args) != 2 {
+		return "Usage: assign <ticket id> <username|me>", nil
+	}
+
+	owner := user
+	if args[1] != "me" {
+		found, err := s.queries.UserByUserName(ctx, strings.TrimPrefix(args[1], "@"))
+		if err != nil {
+			return "", errUnknownUser
+		}
+
+		owner = &found
+	}
+
+	if _, err := s.queries.Ticket(ctx, args[0]); err != nil {
+		return "", err
+	}
+
+	response, err := s.api.UpdateTicket(ctx, openapi.UpdateTicketRequestObject{
+		Id:   args[0],
+		Body: &openapi.UpdateTicketJSONRequestBody{Owner: &owner.ID},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if rejected, ok := response.(openapi.UpdateTicket400JSONResponse); ok {
+		return rejected.Message, nil
+	}
+
+	return fmt.Sprintf("Assigned %s to %s.", args[0], owner.Username), nil
+}
+
+func (s *Slack) closeCommand(ctx context.Context, _ *sqlc.User, args []string) (string, error) {
+	if len(args) == 0 {
+		return "Usage: close <ticket id> [resolution]", nil
+	}
+
+	if _, err := s.queries.Ticket(ctx, args[0]); err != nil {
+		return "", err
+	}
+
+	update := openapi.UpdateTicketJSONRequestBody{Open: pointer.Pointer(false)}
+	if len(args) > 1 {
+		resolution := strings.Join(args[1:], " ")
+		update.Resolution = &resolution
+	}
+
+	response, err := s.api.UpdateTicket(ctx, openapi.UpdateTicketRequestObject{Id: args[0], Body: &update})
+	if err != nil {
+		return "", err
+	}
+
+	if rejected, ok := response.(openapi.UpdateTicket400JSONResponse); ok {
+		return rejected.Message, nil
+	}
+
+	return fmt.Sprintf("Closed %s.", args[0]), nil
+}
+
+func (s *Slack) noteCommand(ctx context.Context, user *sqlc.User, args []string) (string, error) {
+	if len(args) < 2 {
+		return "Usage: note <ticket id> <text>", nil
+	}
+
+	if _, err := s.queries.Ticket(ctx, args[0]); err != nil {
+		return "", err
+	}
+
+	if _, err := s.api.CreateComment(ctx, openapi.CreateCommentRequestObject{
+		Body: &openapi.CreateCommentJSONRequestBody{
+			Author:  user.ID,
+			Message: strings.Join(args[1:], " "),
+			Ticket:  args[0],
+		},
+	}); err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("Added a comment to %s.", args[0]), nil
+}
+
+func (s *Slack) doneCommand(ctx context.Context, _ *sqlc.User, args []string) (string, error) {
+	if len(args) != 1 {
+		return "Usage: done <task id>", nil
+	}
+
+	task, err := s.queries.GetTask(ctx, args[0])
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := s.api.UpdateTask(ctx, openapi.UpdateTaskRequestObject{
+		Id:   task.ID,
+		Body: &openapi.UpdateTaskJSONRequestBody{Open: pointer.Pointer(false)},
+	}); err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("Completed task %q of %s.", task.Name, task.Ticket), nil
+}
+
+func (s *Slack) playbookCommand(ctx context.Context, _ *sqlc.User, args []string) (string, error) {
+	if len(args) < 2 {
+		return "Usage: playbook <ticket id> <playbook id> [input=value ...]", nil
+	}
+
+	if _, err := s.queries.Ticket(ctx, args[0]); err != nil {
+		return "", err
+	}
+
+	p, err := s.queries.GetPlaybook(ctx, args[1])
+	if err != nil {
+		return "", err
+	}
+
+	inputs, err := playbook.ParseInputs(p.Inputs)
+	if err != nil {
+		return "", err
+	}
+
+	values, err := inputValues(inputs, args[2:])
+	if err != nil {
+		return "Invalid inputs: " + err.Error(), nil
+	}
+
+	response, err := s.api.AttachPlaybook(ctx, openapi.AttachPlaybookRequestObject{
+		Id:   args[0],
+		Body: &openapi.AttachPlaybookJSONRequestBody{Playbook: p.ID, Inputs: &values},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	switch response := response.(type) {
+	case openapi.AttachPlaybook200JSONResponse:
+		return fmt.Sprintf("Ran playbook %q on %s, it created %d tasks.", p.Name, args[0], len(response.Tasks)), nil
+	case openapi.AttachPlaybook400JSONResponse:
+		return response.Message, nil
+	default:
+		return "", fmt.Errorf("unexpected attach playbook response %T", response)
+	}
+}
+
+// inputValues converts the name=value arguments of a command to the types of
+// the playbook inputs. Unknown inputs are kept as strings and rejected when
+// the playbook is attached.
+func inputValues(inputs []playbook.Input, args []string) (map[string]any, error) {
+	values := make(map[string]any, len(args))
+
+	for _, arg := range args {
+		name, value, ok := strings.Cut(arg, "=")
+		if !ok {
+			return nil, fmt.Errorf("expected name=value, got %q", arg)
+		}
+
+		i := slices.IndexFunc(inputs, func(input playbook.Input) bool { return input.Name == name })
+		if i < 0 {
+			values[name] = value
+
+			continue
+		}
+
+		switch inputs[i].Type {
+		case playbook.TypeNumber:
+			number, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return nil, fmt.Errorf("%q must be a number", name)
+			}
+
+			values[name] = number
+		case playbook.TypeBoolean:
+			b, err := strconv.ParseBool(value)
+			if err != nil {
+				return nil, fmt.Errorf("%q must be true or false", name)
+			}
+
+			values[name] = b
+		default:
+			values[name] = value
+		}
+	}
+
+	return values, nil
+}
@@ -0,0 +1,242 @@
+package slack
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/SecurityBrewery/catalyst/app/database"
+	"github.com/SecurityBrewery/catalyst/app/database/sqlc"
+	"github.com/SecurityBrewery/catalyst/app/openapi"
+	"github.com/SecurityBrewery/catalyst/app/settings"
+)
+
+type interaction struct {
+	Type       string `json:"type"`
+	CallbackID string `json:"callback_id"`
+	User       struct {
+		ID string `json:"id"`
+	} `json:"user"`
+	Channel struct {
+		ID string `json:"id"`
+	} `json:"channel"`
+	Message struct {
+		Text     string `json:"text"`
+		TS       string `json:"ts"`
+		ThreadTS string `json:"thread_ts"`
+	} `json:"message"`
+}
+
+type eventCallback struct {
+	Type      string `json:"type"`
+	Challenge string `json:"challenge"`
+	Event     struct {
+		Type     string `json:"type"`
+		Subtype  string `json:"subtype"`
+		Channel  string `json:"channel"`
+		User     string `json:"user"`
+		BotID    string `json:"bot_id"`
+		Text     string `json:"text"`
+		TS       string `json:"ts"`
+		ThreadTS string `json:"thread_ts"`
+	} `json:"event"`
+}
+
+// handleCommand runs a ChatOps command like "/catalyst close t_1 Duplicate",
+// or creates a ticket from "/catalyst Suspicious login on vpn01" and starts a
+// thread for it.
+func (s *Slack) handleCommand(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+
+		return
+	}
+
+	text := r.PostForm.Get("text")
+	fields := strings.Fields(text)
+
+	if len(fields) == 0 || fields[0] == "help" {
+		ephemeral(w, fmt.Sprintf(commandUsage, r.PostForm.Get("command")))
+
+		return
+	}
+
+	se, err := settings.Load(ctx, s.queries)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to load settings", "error", err)
+		ephemeral(w, "Failed to load the Slack settings.")
+
+		return
+	}
+
+	user, channel := r.PostForm.Get("user_id"), r.PostForm.Get("channel_id")
+
+	if command, ok := s.chatCommands()[strings.ToLower(fields[0])]; ok {
+		ephemeral(w, s.runCommand(ctx, &se.Slack, user, command, fields[1:]))
+
+		return
+	}
+
+	ticket, err := s.createTicket(ctx, &se.Slack, ticketName(text), "Reported in Slack by <@"+user+">.\n\n"+text, channel, "")
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to create ticket from Slack", "error", err)
+		ephemeral(w, "Failed to create ticket.")
+
+		return
+	}
+
+	if err := s.announce(ctx, &se.Slack, se.Meta.AppURL, ticket, channel, "", user); err != nil {
+		slog.ErrorContext(ctx, "Failed to announce ticket in Slack", "ticket", ticket.ID, "error", err)
+	}
+
+	ephemeral(w, fmt.Sprintf("Created ticket <%s|%s>.", ticketURL(se.Meta.AppURL, ticket), ticket.Name))
+}
+
+// handleInteraction creates a ticket from a message shortcut. The ticket is
+// linked to the thread of the message.
+func (s *Slack) handleInteraction(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+
+		return
+	}
+
+	var payload interaction
+	if err := json.Unmarshal([]byte(r.PostForm.Get("payload")), &payload); err != nil {
+		http.Error(w, "Invalid payload", http.StatusBadRequest)
+
+		return
+	}
+
+	if payload.Type != "message_action" {
+		w.WriteHeader(http.StatusOK)
+
+		return
+	}
+
+	se, err := settings.Load(ctx, s.queries)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to load settings", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+
+		return
+	}
+
+	threadTS := payload.Message.ThreadTS
+	if threadTS == "" {
+		threadTS = payload.Message.TS
+	}
+
+	ticket, err := s.createTicket(ctx, &se.Slack, ticketName(payload.Message.Text), payload.Message.Text, payload.Channel.ID, threadTS)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to create ticket from Slack", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+
+		return
+	}
+
+	if err := s.announce(ctx, &se.Slack, se.Meta.AppURL, ticket, payload.Channel.ID, threadTS, payload.User.ID); err != nil {
+		slog.ErrorContext(ctx, "Failed to announce ticket in Slack", "ticket", ticket.ID, "error", err)
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// handleEvent answers the URL verification of the Events API and adds
+// replies in linked threads as comments.
+func (s *Slack) handleEvent(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var callback eventCallback
+	if err := json.NewDecoder(r.Body).Decode(&callback); err != nil {
+		http.Error(w, "Invalid payload", http.StatusBadRequest)
+
+		return
+	}
+
+	if callback.Type == "url_verification" {
+		w.Header().Set("Content-Type", "text/plain")
+
+		_, _ = w.Write([]byte(callback.Challenge))
+
+		return
+	}
+
+	event := callback.Event
+
+	// only plain replies by users, not our own messages or edits
+	if callback.Type != "event_callback" || event.Type != "message" || event.Subtype != "" || event.BotID != "" ||
+		event.ThreadTS == "" || event.ThreadTS == event.TS {
+		w.WriteHeader(http.StatusOK)
+
+		return
+	}
+
+	thread, err := s.queries.GetSlackThreadByTS(ctx, sqlc.GetSlackThreadByTSParams{
+		Channel:  event.Channel,
+		ThreadTs: event.ThreadTS,
+	})
+	if err != nil {
+		// not a thread of a Catalyst ticket
+		w.WriteHeader(http.StatusOK)
+
+		return
+	}
+
+	if err := s.createComment(r, thread.Ticket, event.User, event.Text); err != nil {
+		slog.ErrorContext(ctx, "Failed to mirror Slack reply", "ticket", thread.Ticket, "error", err)
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *Slack) createComment(r *http.Request, ticket, slackUser, text string) error {
+	ctx, err := s.systemContext(r.Context())
+	if err != nil {
+		return err
+	}
+
+	systemUser, err := s.queries.SystemUser(ctx)
+	if err != nil {
+		return err
+	}
+
+	params := sqlc.CreateCommentParams{
+		Author:  systemUser.ID,
+		Message: fmt.Sprintf("<@%s> in Slack: %s", slackUser, text),
+		Ticket:  ticket,
+	}
+
+	s.hooks.OnRecordBeforeCreateRequest.Publish(ctx, database.CommentsTable.ID, params)
+
+	comment, err := s.queries.CreateComment(ctx, params)
+	if err != nil {
+		return err
+	}
+
+	s.hooks.OnRecordAfterCreateRequest.Publish(ctx, database.CommentsTable.ID, openapi.Comment{
+		Author:  comment.Author,
+		Created: comment.Created,
+		Id:      comment.ID,
+		Message: comment.Message,
+		Ticket:  comment.Ticket,
+		Updated: comment.Updated,
+	})
+
+	return nil
+}
+
+func ephemeral(w http.ResponseWriter, text string) {
+	w.Header().Set("Content-Type", "application/json")
+
+	_ = json.NewEncoder(w).Encode(map[string]string{
+		"response_type": "ephemeral",
+		"text":          text,
+	})
+}
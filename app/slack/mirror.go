@@ -0,0 +1,57 @@
+package slack
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/SecurityBrewery/catalyst/app/database"
+	"github.com/SecurityBrewery/catalyst/app/openapi"
+	"github.com/SecurityBrewery/catalyst/app/settings"
+)
+
+// BindHooks mirrors comments on tickets created from Slack into their thread.
+func (s *Slack) BindHooks() {
+	s.hooks.OnRecordAfterCreateRequest.Subscribe(func(ctx context.Context, table string, record any) {
+		if table != database.CommentsTable.ID || isFromSlack(ctx) {
+			return
+		}
+
+		comment, ok := record.(openapi.Comment)
+		if !ok {
+			return
+		}
+
+		// don't block the request on the Slack API
+		go s.mirrorComment(context.WithoutCancel(ctx), comment)
+	})
+}
+
+func (s *Slack) mirrorComment(ctx context.Context, comment openapi.Comment) {
+	thread, err := s.queries.GetSlackThread(ctx, comment.Ticket)
+	if err != nil {
+		return // ticket was not created from Slack
+	}
+
+	se, err := settings.Load(ctx, s.queries)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to load settings", "error", err)
+
+		return
+	}
+
+	if !se.Slack.Enabled || se.Slack.BotToken == "" {
+		return
+	}
+
+	author := comment.Author
+	if user, err := s.queries.GetUser(ctx, comment.Author); err == nil {
+		author = user.Username
+		if user.Name != nil && *user.Name != "" {
+			author = *user.Name
+		}
+	}
+
+	if _, err := s.postMessage(ctx, se.Slack.BotToken, thread.Channel, thread.ThreadTs, "*"+author+"*: "+comment.Message); err != nil {
+		slog.ErrorContext(ctx, "Failed to mirror comment to Slack", "ticket", comment.Ticket, "error", err)
+	}
+}
@@ -0,0 +1,128 @@
+// Package slack integrates Catalyst with a Slack app. Tickets can be created
+// with a slash command or a message shortcut, and the comments of these
+// tickets are mirrored between Catalyst and the Slack thread. The slash
+// command also runs ChatOps commands as the Catalyst user linked to the
+// Slack account.
+package slack
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/SecurityBrewery/catalyst/app/database/sqlc"
+	"github.com/SecurityBrewery/catalyst/app/hook"
+	"github.com/SecurityBrewery/catalyst/app/openapi"
+	"github.com/SecurityBrewery/catalyst/app/settings"
+)
+
+const (
+	defaultAPIURL = "https://slack.com/api"
+	maxBodySize   = 1 << 20
+	maxClockSkew  = 5 * time.Minute
+)
+
+type Slack struct {
+	queries *sqlc.Queries
+	hooks   *hook.Hooks
+	api     openapi.StrictServerInterface
+	client  *http.Client
+	apiURL  string
+	now     func() time.Time
+}
+
+// New creates the Slack integration. ChatOps commands are executed through
+// api, so they publish the same hooks as the REST API.
+func New(queries *sqlc.Queries, hooks *hook.Hooks, api openapi.StrictServerInterface) *Slack {
+	return &Slack{
+		queries: queries,
+		hooks:   hooks,
+		api:     api,
+		client:  &http.Client{Timeout: 10 * time.Second},
+		apiURL:  defaultAPIURL,
+		now:     time.Now,
+	}
+}
+
+// Routes returns the endpoints configured as request URLs in the Slack app.
+func (s *Slack) Routes() http.Handler {
+	r := chi.NewRouter()
+
+	r.Use(s.verify)
+
+	r.Post("/commands", s.handleCommand)
+	r.Post("/interactions", s.handleInteraction)
+	r.Post("/events", s.handleEvent)
+
+	return r
+}
+
+// verify checks that the request was signed by Slack with the signing secret.
+func (s *Slack) verify(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		se, err := settings.Load(r.Context(), s.queries)
+		if err != nil {
+			slog.ErrorContext(r.Context(), "Failed to load Slack settings", "error", err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+
+			return
+		}
+
+		if !se.Slack.Enabled || se.Slack.SigningSecret == "" {
+			http.NotFound(w, r)
+
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBodySize))
+		if err != nil {
+			http.Error(w, "Request body too large", http.StatusRequestEntityTooLarge)
+
+			return
+		}
+
+		if err := verifySignature(se.Slack.SigningSecret, r.Header, body, s.now()); err != nil {
+			slog.WarnContext(r.Context(), "Invalid Slack request", "error", err)
+			http.Error(w, "Invalid signature", http.StatusUnauthorized)
+
+			return
+		}
+
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func verifySignature(secret string, header http.Header, body []byte, now time.Time) error {
+	timestamp, err := strconv.ParseInt(header.Get("X-Slack-Request-Timestamp"), 10, 64)
+	if err != nil {
+		return errors.New("missing timestamp")
+	}
+
+	if math.Abs(now.Sub(time.Unix(timestamp, 0)).Seconds()) > maxClockSkew.Seconds() {
+		return errors.New("timestamp too old")
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	_, _ = fmt.Fprintf(mac, "v0:%d:%s", timestamp, body)
+
+	expected := "v0=" + hex.EncodeToString(mac.Sum(nil))
+
+	if !hmac.Equal([]byte(expected), []byte(header.Get("X-Slack-Signature"))) {
+		return errors.New("signature mismatch")
+	}
+
+	return nil
+}
@@ -0,0 +1,329 @@
+package slack
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/SecurityBrewery/catalyst/app/data"
+	"github.com/SecurityBrewery/catalyst/app/database/sqlc"
+	"github.com/SecurityBrewery/catalyst/app/hook"
+	"github.com/SecurityBrewery/catalyst/app/openapi"
+	"github.com/SecurityBrewery/catalyst/app/pointer"
+	"github.com/SecurityBrewery/catalyst/app/service"
+	"github.com/SecurityBrewery/catalyst/app/settings"
+	"github.com/SecurityBrewery/catalyst/app/upload"
+)
+
+const testSecret = "8f742231b10e8888abcd99yyyzzz85a5"
+
+type fakeSlackAPI struct {
+	mu       sync.Mutex
+	messages []postMessageRequest
+	emails   map[string]string
+}
+
+func (f *fakeSlackAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch r.URL.Path {
+	case "/chat.postMessage":
+		var message postMessageRequest
+		_ = json.NewDecoder(r.Body).Decode(&message)
+
+		f.messages = append(f.messages, message)
+
+		_, _ = fmt.Fprintf(w, `{"ok":true,"ts":"1700000000.%06d"}`, len(f.messages))
+	case "/users.info":
+		email, ok := f.emails[r.FormValue("user")]
+		if !ok {
+			_, _ = w.Write([]byte(`{"ok":false,"error":"user_not_found"}`))
+
+			return
+		}
+
+		_, _ = fmt.Fprintf(w, `{"ok":true,"user":{"profile":{"email":%q}}}`, email)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (f *fakeSlackAPI) Messages() []postMessageRequest {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return append([]postMessageRequest{}, f.messages...)
+}
+
+func newTestSlack(t *testing.T) (*Slack, *sqlc.Queries, *fakeSlackAPI) {
+	t.Helper()
+
+	dir := t.TempDir()
+	queries := data.NewTestDB(t, dir)
+	hooks := hook.NewHooks()
+
+	_, err := settings.Update(t.Context(), queries, func(se *settings.Settings) {
+		se.Meta.AppURL = "https://catalyst.example.com"
+		se.Slack = settings.Slack{
+			Enabled:       true,
+			SigningSecret: testSecret,
+			BotToken:      "xoxb-test",
+			TicketType:    "incident",
+		}
+	})
+	require.NoError(t, err)
+
+	uploader, err := upload.New(dir)
+	require.NoError(t, err)
+
+	api := &fakeSlackAPI{emails: map[string]string{
+		"U_ANALYST": data.AnalystEmail,
+		"U_VIEWER":  "viewer@catalyst-soar.com",
+	}}
+	server := httptest.NewServer(api)
+	t.Cleanup(server.Close)
+
+	s := New(queries, hooks, service.New(queries, hooks, uploader, nil, nil, nil, nil, nil, nil))
+	s.apiURL = server.URL
+
+	return s, queries, api
+}
+
+func signedRequest(t *testing.T, path, contentType, body string) *http.Request {
+	t.Helper()
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	mac := hmac.New(sha256.New, []byte(testSecret))
+	_, _ = mac.Write([]byte("v0:" + timestamp + ":" + body))
+
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("X-Slack-Request-Timestamp", timestamp)
+	req.Header.Set("X-Slack-Signature", "v0="+hex.EncodeToString(mac.Sum(nil)))
+
+	return req
+}
+
+func Test_verifySignature(t *testing.T) {
+	t.Parallel()
+
+	// example from https://api.slack.com/authentication/verifying-requests-from-slack
+	body := []byte("token=xyzz0WbapA4vBCDEFasx0q6G&team_id=T1DC2JH3J&team_domain=testteamnow&channel_id=G8PSS9T3V&channel_name=foobar&user_id=U2CERLKJA&user_name=roadrunner&command=%2Fwebhook-collect&text=&response_url=https%3A%2F%2Fhooks.slack.com%2Fcommands%2FT1DC2JH3J%2F397700885554%2F96rGlfmibIGlgcZRskXaIFfN&trigger_id=398738663015.47445629121.803a0bc887a14d10d2c447fce8b6703c")
+	now := time.Unix(1531420618, 0)
+
+	header := http.Header{}
+	header.Set("X-Slack-Request-Timestamp", "1531420618")
+	header.Set("X-Slack-Signature", "v0=a2114d57b48eac39b9ad189dd8316235a7b4a8d21a10bd27519666489c69b503")
+
+	require.NoError(t, verifySignature(testSecret, header, body, now))
+	require.Error(t, verifySignature("wrong", header, body, now))
+	require.Error(t, verifySignature(testSecret, header, append(body, 'x'), now))
+	require.Error(t, verifySignature(testSecret, header, body, now.Add(10*time.Minute)))
+	require.Error(t, verifySignature(testSecret, http.Header{}, body, now))
+}
+
+func Test_ticketName(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "Phishing mail", ticketName("  Phishing mail\nwith details"))
+	assert.Equal(t, "Slack message", ticketName(""))
+	assert.Len(t, []rune(ticketName(strings.Repeat("ä", 200))), maxTicketNameLength)
+}
+
+func TestSlack_Unsigned(t *testing.T) {
+	t.Parallel()
+
+	s, _, _ := newTestSlack(t)
+
+	req := httptest.NewRequest(http.MethodPost, "/commands", strings.NewReader("text=test"))
+	rec := httptest.NewRecorder()
+
+	s.Routes().ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
+
+func TestSlack_Command(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	s, queries, api := newTestSlack(t)
+
+	form := url.Values{
+		"command":    {"/catalyst"},
+		"text":       {"Suspicious login on vpn01"},
+		"user_id":    {"U123"},
+		"channel_id": {"C123"},
+	}
+
+	rec := httptest.NewRecorder()
+	s.Routes().ServeHTTP(rec, signedRequest(t, "/commands", "application/x-www-form-urlencoded", form.Encode()))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "Created ticket")
+
+	messages := api.Messages()
+	require.Len(t, messages, 1)
+	assert.Equal(t, "C123", messages[0].Channel)
+	assert.Empty(t, messages[0].ThreadTS)
+	assert.Contains(t, messages[0].Text, "https://catalyst.example.com/ui/tickets/incident/")
+
+	thread, err := queries.GetSlackThreadByTS(ctx, sqlc.GetSlackThreadByTSParams{Channel: "C123", ThreadTs: "1700000000.000001"})
+	require.NoError(t, err)
+
+	ticket, err := queries.Ticket(ctx, thread.Ticket)
+	require.NoError(t, err)
+	assert.Equal(t, "Suspicious login on vpn01", ticket.Name)
+	assert.Equal(t, "incident", ticket.Type)
+
+	// a reply in the thread becomes a comment
+	event := `{"type":"event_callback","event":{"type":"message","channel":"C123","user":"U456","text":"Blocked the IP","ts":"1700000001.000000","thread_ts":"1700000000.000001"}}`
+
+	rec = httptest.NewRecorder()
+	s.Routes().ServeHTTP(rec, signedRequest(t, "/events", "application/json", event))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	comments, err := queries.ListComments(ctx, sqlc.ListCommentsParams{Ticket: thread.Ticket, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, comments, 1)
+	assert.Equal(t, "<@U456> in Slack: Blocked the IP", comments[0].Message)
+}
+
+func TestSlack_Interaction(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	s, queries, api := newTestSlack(t)
+
+	payload := `{"type":"message_action","user":{"id":"U123"},"channel":{"id":"C123"},"message":{"text":"Malware alert\nHost ws042","ts":"1690000000.000100"}}`
+	form := url.Values{"payload": {payload}}
+
+	rec := httptest.NewRecorder()
+	s.Routes().ServeHTTP(rec, signedRequest(t, "/interactions", "application/x-www-form-urlencoded", form.Encode()))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	messages := api.Messages()
+	require.Len(t, messages, 1)
+	assert.Equal(t, "1690000000.000100", messages[0].ThreadTS)
+
+	thread, err := queries.GetSlackThreadByTS(ctx, sqlc.GetSlackThreadByTSParams{Channel: "C123", ThreadTs: "1690000000.000100"})
+	require.NoError(t, err)
+
+	ticket, err := queries.Ticket(ctx, thread.Ticket)
+	require.NoError(t, err)
+	assert.Equal(t, "Malware alert", ticket.Name)
+	assert.Equal(t, "Malware alert\nHost ws042", ticket.Description)
+
+	// comments in Catalyst are posted to the thread
+	s.mirrorComment(ctx, openapi.Comment{Author: "u_bob_analyst", Message: "Isolated the host", Ticket: thread.Ticket})
+
+	messages = api.Messages()
+	require.Len(t, messages, 2)
+	assert.Equal(t, "1690000000.000100", messages[1].ThreadTS)
+	assert.Contains(t, messages[1].Text, "Isolated the host")
+}
+
+func TestSlack_URLVerification(t *testing.T) {
+	t.Parallel()
+
+	s, _, _ := newTestSlack(t)
+
+	rec := httptest.NewRecorder()
+	s.Routes().ServeHTTP(rec, signedRequest(t, "/events", "application/json", `{"type":"url_verification","challenge":"abc123"}`))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "abc123", rec.Body.String())
+}
+
+func TestSlack_ChatOps(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	s, queries, _ := newTestSlack(t)
+
+	_, err := queries.InsertUser(ctx, sqlc.InsertUserParams{
+		ID:       "u_viewer",
+		Username: "u_viewer",
+		Email:    pointer.Pointer("viewer@catalyst-soar.com"),
+		Active:   true,
+		Created:  time.Now(),
+		Updated:  time.Now(),
+	})
+	require.NoError(t, err)
+
+	command := func(user, text string) string {
+		t.Helper()
+
+		form := url.Values{"command": {"/catalyst"}, "text": {text}, "user_id": {user}, "channel_id": {"C123"}}
+
+		rec := httptest.NewRecorder()
+		s.Routes().ServeHTTP(rec, signedRequest(t, "/commands", "application/x-www-form-urlencoded", form.Encode()))
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		var reply map[string]string
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &reply))
+
+		return reply["text"]
+	}
+
+	assert.Contains(t, command("U_ANALYST", "help"), "assign <ticket id>")
+	assert.Contains(t, command("U_UNKNOWN", "close test-ticket"), "not linked")
+	assert.Contains(t, command("U_VIEWER", "close test-ticket"), "missing the ticket:write permission")
+	assert.Equal(t, "Not found.", command("U_ANALYST", "close missing-ticket"))
+	assert.Equal(t, "Unknown Catalyst user.", command("U_ANALYST", "assign test-ticket nobody"))
+
+	assert.Equal(t, "Assigned test-ticket to u_admin.", command("U_ANALYST", "assign test-ticket u_admin"))
+	assert.Equal(t, "Added a comment to test-ticket.", command("U_ANALYST", "note test-ticket Blocked the IP"))
+
+	p, err := queries.CreatePlaybook(ctx, sqlc.CreatePlaybookParams{
+		Name:   "Block indicator",
+		Inputs: []byte(`[{"name":"indicator","type":"string"},{"name":"hours","type":"number"}]`),
+		Tasks:  []byte(`[{"name":"Block {{ inputs.indicator }} for {{ inputs.hours }}h"}]`),
+	})
+	require.NoError(t, err)
+
+	assert.Contains(t, command("U_ANALYST", "playbook test-ticket"), "Usage: playbook")
+	assert.Equal(t, `Invalid inputs: "hours" must be a number`, command("U_ANALYST", "playbook test-ticket "+p.ID+" indicator=10.0.0.1 hours=many"))
+	assert.Equal(t, `Ran playbook "Block indicator" on test-ticket, it created 1 tasks.`, command("U_ANALYST", "playbook test-ticket "+p.ID+" indicator=10.0.0.1 hours=24"))
+	assert.Equal(t, "Closed test-ticket.", command("U_ANALYST", "close test-ticket False positive"))
+	assert.Contains(t, command("U_ANALYST", "done k_test_task"), "Completed task")
+
+	ticket, err := queries.Ticket(ctx, "test-ticket")
+	require.NoError(t, err)
+	assert.Equal(t, pointer.Pointer("u_admin"), ticket.Owner)
+	assert.False(t, ticket.Open)
+	assert.Equal(t, pointer.Pointer("False positive"), ticket.Resolution)
+
+	comments, err := queries.ListComments(ctx, sqlc.ListCommentsParams{Ticket: "test-ticket", Limit: 100})
+	require.NoError(t, err)
+	assert.Condition(t, func() bool {
+		for _, comment := range comments {
+			if comment.Author == "u_bob_analyst" && comment.Message == "Blocked the IP" {
+				return true
+			}
+		}
+
+		return false
+	})
+
+	task, err := queries.GetTask(ctx, "k_test_task")
+	require.NoError(t, err)
+	assert.False(t, task.Open)
+}
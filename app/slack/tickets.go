@@ -0,0 +1,122 @@
+package slack
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/SecurityBrewery/catalyst/app/auth/usercontext"
+	"github.com/SecurityBrewery/catalyst/app/database"
+	"github.com/SecurityBrewery/catalyst/app/database/sqlc"
+	"github.com/SecurityBrewery/catalyst/app/openapi"
+	"github.com/SecurityBrewery/catalyst/app/settings"
+)
+
+const maxTicketNameLength = 80
+
+type fromSlackKey struct{}
+
+// systemContext marks the context as originating from Slack, so the change
+// is not mirrored back, and authenticates it as the system user.
+func (s *Slack) systemContext(ctx context.Context) (context.Context, error) {
+	systemUser, err := s.queries.SystemUser(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find system user: %w", err)
+	}
+
+	ctx = context.WithValue(ctx, fromSlackKey{}, true)
+
+	return usercontext.UserContext(ctx, &systemUser), nil
+}
+
+func isFromSlack(ctx context.Context) bool {
+	fromSlack, _ := ctx.Value(fromSlackKey{}).(bool)
+
+	return fromSlack
+}
+
+// createTicket creates an open ticket of the configured type and links it to
+// the Slack thread.
+func (s *Slack) createTicket(ctx context.Context, config *settings.Slack, name, description, channel, threadTS string) (sqlc.Ticket, error) {
+	ticketType, err := s.queries.GetType(ctx, config.TicketType)
+	if err != nil {
+		return sqlc.Ticket{}, fmt.Errorf("failed to get ticket type %q: %w", config.TicketType, err)
+	}
+
+	ctx, err = s.systemContext(ctx)
+	if err != nil {
+		return sqlc.Ticket{}, err
+	}
+
+	s.hooks.OnRecordBeforeCreateRequest.Publish(ctx, database.TicketsTable.ID, map[string]string{
+		"name":        name,
+		"description": description,
+	})
+
+	ticket, err := s.queries.CreateTicket(ctx, sqlc.CreateTicketParams{
+		Name:        name,
+		Description: description,
+		Open:        true,
+		Type:        ticketType.ID,
+		Schema:      ticketType.Schema,
+		State:       []byte("{}"),
+	})
+	if err != nil {
+		return sqlc.Ticket{}, err
+	}
+
+	s.hooks.OnRecordAfterCreateRequest.Publish(ctx, database.TicketsTable.ID, openapi.Ticket{
+		Created:     ticket.Created,
+		Description: ticket.Description,
+		Id:          ticket.ID,
+		Name:        ticket.Name,
+		Open:        ticket.Open,
+		Type:        ticket.Type,
+		State:       map[string]any{},
+		Updated:     ticket.Updated,
+	})
+
+	return ticket, nil
+}
+
+// announce posts the new ticket to Slack and remembers the thread for
+// mirroring comments. If threadTS is empty, a new thread is started.
+func (s *Slack) announce(ctx context.Context, config *settings.Slack, appURL string, ticket sqlc.Ticket, channel, threadTS, user string) error {
+	text := fmt.Sprintf("<@%s> created ticket <%s|%s>. Replies in this thread are added as comments.", user, ticketURL(appURL, ticket), ticket.Name)
+
+	ts, err := s.postMessage(ctx, config.BotToken, channel, threadTS, text)
+	if err != nil {
+		return err
+	}
+
+	if threadTS == "" {
+		threadTS = ts
+	}
+
+	_, err = s.queries.CreateSlackThread(ctx, sqlc.CreateSlackThreadParams{
+		Ticket:   ticket.ID,
+		Channel:  channel,
+		ThreadTs: threadTS,
+	})
+
+	return err
+}
+
+func ticketURL(appURL string, ticket sqlc.Ticket) string {
+	return strings.TrimSuffix(appURL, "/") + "/ui/tickets/" + ticket.Type + "/" + ticket.ID
+}
+
+// ticketName uses the first line of a message as ticket name.
+func ticketName(text string) string {
+	name, _, _ := strings.Cut(strings.TrimSpace(text), "\n")
+
+	if runes := []rune(name); len(runes) > maxTicketNameLength {
+		name = string(runes[:maxTicketNameLength-1]) + "…"
+	}
+
+	if name == "" {
+		return "Slack message"
+	}
+
+	return name
+}
@@ -0,0 +1,97 @@
+package storage
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/SecurityBrewery/catalyst/app/azblob"
+)
+
+// Azure keeps the files in a prefix of an Azure Blob Storage container.
+type Azure struct {
+	config    *azblob.Config
+	client    *http.Client
+	container string
+	prefix    string
+}
+
+// NewAzure returns the storage of a URL like azure://container/prefix.
+func NewAzure(config Config) (*Azure, error) {
+	u, err := url.Parse(config.URL)
+	if err != nil || u.Scheme != "azure" || u.Host == "" {
+		return nil, fmt.Errorf("%w: %q is not like azure://container/prefix", ErrInvalidStorage, config.URL)
+	}
+
+	if config.AzureAccount == "" {
+		return nil, fmt.Errorf("%w: the Azure storage account is not configured", ErrInvalidStorage)
+	}
+
+	if _, err := base64.StdEncoding.DecodeString(config.AzureAccountKey); err != nil {
+		return nil, fmt.Errorf("%w: the Azure account key is not base64 encoded", ErrInvalidStorage)
+	}
+
+	return &Azure{
+		config: &azblob.Config{
+			Account:  config.AzureAccount,
+			Key:      config.AzureAccountKey,
+			Endpoint: config.Endpoint,
+		},
+		client:    &http.Client{Timeout: 10 * time.Minute},
+		container: u.Host,
+		prefix:    strings.Trim(u.Path, "/"),
+	}, nil
+}
+
+func (a *Azure) Open(ctx context.Context, name string) (io.ReadCloser, int64, error) {
+	content, size, err := azblob.GetBlob(ctx, a.client, a.config, a.container, a.key(name), time.Now())
+	if errors.Is(err, azblob.ErrNotFound) {
+		return nil, 0, fmt.Errorf("%w: %w", fs.ErrNotExist, err)
+	}
+
+	return content, size, err
+}
+
+func (a *Azure) Create(ctx context.Context, name, contentType string) (Writer, error) {
+	return azblob.NewUpload(ctx, a.client, a.config, a.container, a.key(name), contentType, time.Now)
+}
+
+func (a *Azure) Remove(ctx context.Context, name string) error {
+	if err := azblob.DeleteBlob(ctx, a.client, a.config, a.container, a.key(name), time.Now()); err != nil && !errors.Is(err, azblob.ErrNotFound) {
+		return err
+	}
+
+	return nil
+}
+
+func (a *Azure) Walk(ctx context.Context, fn func(name string, size int64) error) error {
+	prefix := ""
+	if a.prefix != "" {
+		prefix = a.prefix + "/"
+	}
+
+	blobs, err := azblob.ListBlobs(ctx, a.client, a.config, a.container, prefix, time.Now)
+	if err != nil {
+		return err
+	}
+
+	for _, blob := range blobs {
+		if err := fn(strings.TrimPrefix(blob.Name, prefix), blob.Size); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (a *Azure) key(name string) string {
+	return path.Join(a.prefix, name)
+}
@@ -0,0 +1,101 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Filesystem keeps the files in a directory, with the names as paths.
+type Filesystem struct {
+	root *os.Root
+}
+
+func NewFilesystem(root *os.Root) *Filesystem {
+	return &Filesystem{root: root}
+}
+
+func (f *Filesystem) Open(_ context.Context, name string) (io.ReadCloser, int64, error) {
+	file, err := f.root.Open(filepath.FromSlash(name))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+
+		return nil, 0, err
+	}
+
+	return file, info.Size(), nil
+}
+
+func (f *Filesystem) Create(_ context.Context, name, _ string) (Writer, error) {
+	// the directories of the name are created one by one, os.Root has no
+	// MkdirAll yet
+	dir := path.Dir(name)
+	if dir != "." {
+		parts := strings.Split(dir, "/")
+
+		for i := range parts {
+			err := f.root.Mkdir(filepath.Join(parts[:i+1]...), 0o755)
+			if err != nil && !errors.Is(err, fs.ErrExist) {
+				return nil, err
+			}
+		}
+	}
+
+	file, err := f.root.Create(filepath.FromSlash(name))
+	if err != nil {
+		return nil, err
+	}
+
+	return &fileWriter{File: file, root: f.root, name: name}, nil
+}
+
+// Remove deletes a file and its directory, if it is empty then.
+func (f *Filesystem) Remove(_ context.Context, name string) error {
+	if err := f.root.Remove(filepath.FromSlash(name)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	if dir := path.Dir(name); dir != "." {
+		// fails if other files are left
+		_ = f.root.Remove(filepath.FromSlash(dir))
+	}
+
+	return nil
+}
+
+func (f *Filesystem) Walk(_ context.Context, fn func(name string, size int64) error) error {
+	return fs.WalkDir(f.root.FS(), ".", func(name string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+
+		return fn(name, info.Size())
+	})
+}
+
+type fileWriter struct {
+	*os.File
+	root *os.Root
+	name string
+}
+
+func (w *fileWriter) Abort(context.Context) error {
+	w.File.Close()
+
+	return w.root.Remove(filepath.FromSlash(w.name))
+}
@@ -0,0 +1,118 @@
+package storage
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/SecurityBrewery/catalyst/app/s3"
+)
+
+// S3 keeps the files in a prefix of an S3 compatible bucket, which may be a
+// GCS bucket.
+type S3 struct {
+	config *s3.Config
+	client *http.Client
+	bucket string
+	prefix string
+}
+
+// NewS3 returns the storage of a URL like s3://bucket/prefix.
+func NewS3(config Config) (*S3, error) {
+	u, err := url.Parse(config.URL)
+	if err != nil || u.Scheme != "s3" || u.Host == "" {
+		return nil, fmt.Errorf("%w: %q is not like s3://bucket/prefix", ErrInvalidStorage, config.URL)
+	}
+
+	if config.Endpoint == "" {
+		return nil, fmt.Errorf("%w: the endpoint of the bucket is not configured", ErrInvalidStorage)
+	}
+
+	return newS3(u, config), nil
+}
+
+// GCSEndpoint is the S3 compatible XML API of Google Cloud Storage.
+const GCSEndpoint = "https://storage.googleapis.com"
+
+// NewGCS returns the storage of a URL like gs://bucket/prefix. The XML API
+// of GCS accepts S3 requests signed with an HMAC key, so the files are kept
+// like in an S3 bucket.
+func NewGCS(config Config) (*S3, error) {
+	u, err := url.Parse(config.URL)
+	if err != nil || u.Scheme != "gs" || u.Host == "" {
+		return nil, fmt.Errorf("%w: %q is not like gs://bucket/prefix", ErrInvalidStorage, config.URL)
+	}
+
+	config.Endpoint = cmp.Or(config.Endpoint, GCSEndpoint)
+	config.Region = cmp.Or(config.Region, "auto")
+
+	return newS3(u, config), nil
+}
+
+func newS3(u *url.URL, config Config) *S3 {
+	return &S3{
+		config: &s3.Config{
+			Endpoint:        config.Endpoint,
+			Region:          config.Region,
+			AccessKeyID:     config.AccessKeyID,
+			SecretAccessKey: config.SecretAccessKey,
+		},
+		client: &http.Client{Timeout: 10 * time.Minute},
+		bucket: u.Host,
+		prefix: strings.Trim(u.Path, "/"),
+	}
+}
+
+func (s *S3) Open(ctx context.Context, name string) (io.ReadCloser, int64, error) {
+	content, size, err := s3.GetObject(ctx, s.client, s.config, s.bucket, s.key(name), time.Now())
+	if errors.Is(err, s3.ErrNotFound) {
+		return nil, 0, fmt.Errorf("%w: %w", fs.ErrNotExist, err)
+	}
+
+	return content, size, err
+}
+
+func (s *S3) Create(ctx context.Context, name, contentType string) (Writer, error) {
+	return s3.NewUpload(ctx, s.client, s.config, s.bucket, s.key(name), contentType, time.Now)
+}
+
+func (s *S3) Remove(ctx context.Context, name string) error {
+	// some S3 compatible stores answer 404 for objects that do not exist
+	if err := s3.DeleteObject(ctx, s.client, s.config, s.bucket, s.key(name), time.Now()); err != nil && !errors.Is(err, s3.ErrNotFound) {
+		return err
+	}
+
+	return nil
+}
+
+func (s *S3) Walk(ctx context.Context, fn func(name string, size int64) error) error {
+	prefix := ""
+	if s.prefix != "" {
+		prefix = s.prefix + "/"
+	}
+
+	objects, err := s3.ListObjects(ctx, s.client, s.config, s.bucket, prefix, time.Now)
+	if err != nil {
+		return err
+	}
+
+	for _, object := range objects {
+		if err := fn(strings.TrimPrefix(object.Key, prefix), object.Size); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *S3) key(name string) string {
+	return path.Join(s.prefix, name)
+}
@@ -0,0 +1,78 @@
+// Package storage keeps the uploaded files, in the uploads directory or in
+// a bucket of an object store.
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+)
+
+var ErrInvalidStorage = errors.New("invalid storage")
+
+// Storage keeps files by slash separated names.
+type Storage interface {
+	// Open returns the content and the size of a file. It returns an error
+	// wrapping fs.ErrNotExist if the file does not exist.
+	Open(ctx context.Context, name string) (io.ReadCloser, int64, error)
+	// Create returns a writer for a file, which is stored once the writer is
+	// closed. An existing file is replaced.
+	Create(ctx context.Context, name, contentType string) (Writer, error)
+	// Remove deletes a file. Removing a file that does not exist succeeds.
+	Remove(ctx context.Context, name string) error
+	// Walk calls fn with the name and the size of every file.
+	Walk(ctx context.Context, fn func(name string, size int64) error) error
+}
+
+type Writer interface {
+	io.WriteCloser
+	// Abort discards an incomplete file.
+	Abort(ctx context.Context) error
+}
+
+// Config selects the storage, it is given on startup.
+type Config struct {
+	// URL is like s3://bucket/prefix, azure://container/prefix or
+	// gs://bucket/prefix. The files are kept in the uploads directory if it
+	// is empty.
+	URL string
+	// Endpoint of the object store, it defaults to the blob service of the
+	// account for Azure and to GCSEndpoint for GCS.
+	Endpoint string
+	// Region of the bucket, auto for GCS by default.
+	Region string
+	// AccessKeyID and SecretAccessKey are the credentials of S3, or an HMAC
+	// key of GCS.
+	AccessKeyID     string
+	SecretAccessKey string
+	AzureAccount    string
+	// AzureAccountKey is the base64 encoded account key.
+	AzureAccountKey string
+}
+
+// New returns the storage of the config. root is the uploads directory,
+// which is the storage if no URL is configured.
+func New(root *os.Root, config Config) (Storage, error) {
+	if config.URL == "" {
+		return NewFilesystem(root), nil
+	}
+
+	u, err := url.Parse(config.URL)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %q is not like s3://bucket/prefix, azure://container/prefix or gs://bucket/prefix", ErrInvalidStorage, config.URL)
+	}
+
+	switch u.Scheme {
+	case "s3":
+		return NewS3(config)
+	case "azure":
+		return NewAzure(config)
+	case "gs":
+		return NewGCS(config)
+	default:
+		return nil, fmt.Errorf("%w: %q is not like s3://bucket/prefix, azure://container/prefix or gs://bucket/prefix", ErrInvalidStorage, config.URL)
+	}
+}
@@ -0,0 +1,121 @@
+package storage
+
+import (
+	"io"
+	"io/fs"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	root, err := os.OpenRoot(t.TempDir())
+	require.NoError(t, err)
+
+	t.Cleanup(func() { root.Close() })
+
+	store, err := New(root, Config{})
+	require.NoError(t, err)
+	assert.IsType(t, &Filesystem{}, store)
+
+	store, err = New(root, Config{URL: "s3://bucket/uploads", Endpoint: "http://localhost:9000"})
+	require.NoError(t, err)
+	assert.Equal(t, "uploads/b_1.info", store.(*S3).key("b_1.info"))
+
+	_, err = New(root, Config{URL: "s3://bucket"})
+	require.ErrorIs(t, err, ErrInvalidStorage)
+
+	store, err = New(root, Config{URL: "azure://container", AzureAccount: "account"})
+	require.NoError(t, err)
+	assert.Equal(t, "b_1.info", store.(*Azure).key("b_1.info"))
+
+	_, err = New(root, Config{URL: "azure://container"})
+	require.ErrorIs(t, err, ErrInvalidStorage)
+
+	_, err = New(root, Config{URL: "azure://container", AzureAccount: "account", AzureAccountKey: "not base64!"})
+	require.ErrorIs(t, err, ErrInvalidStorage)
+
+	store, err = New(root, Config{URL: "gs://bucket/catalyst/uploads"})
+	require.NoError(t, err)
+	assert.Equal(t, GCSEndpoint, store.(*S3).config.Endpoint)
+	assert.Equal(t, "auto", store.(*S3).config.Region)
+	assert.Equal(t, "catalyst/uploads/b_1.info", store.(*S3).key("b_1.info"))
+
+	_, err = New(root, Config{URL: "ftp://bucket"})
+	require.ErrorIs(t, err, ErrInvalidStorage)
+}
+
+func TestFilesystem(t *testing.T) {
+	t.Parallel()
+
+	root, err := os.OpenRoot(t.TempDir())
+	require.NoError(t, err)
+
+	t.Cleanup(func() { root.Close() })
+
+	store := NewFilesystem(root)
+
+	w, err := store.Create(t.Context(), "b_1/file.txt", "text/plain")
+	require.NoError(t, err)
+
+	_, err = io.WriteString(w, "content")
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	w, err = store.Create(t.Context(), "b_1.info", "application/json")
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	content, size, err := store.Open(t.Context(), "b_1/file.txt")
+	require.NoError(t, err)
+
+	data, err := io.ReadAll(content)
+	require.NoError(t, err)
+	require.NoError(t, content.Close())
+
+	assert.Equal(t, "content", string(data))
+	assert.Equal(t, int64(7), size)
+
+	files := map[string]int64{}
+	require.NoError(t, store.Walk(t.Context(), func(name string, size int64) error {
+		files[name] = size
+
+		return nil
+	}))
+	assert.Equal(t, map[string]int64{"b_1/file.txt": 7, "b_1.info": 0}, files)
+
+	require.NoError(t, store.Remove(t.Context(), "b_1/file.txt"))
+	require.NoError(t, store.Remove(t.Context(), "b_1/file.txt"))
+
+	_, _, err = store.Open(t.Context(), "b_1/file.txt")
+	require.ErrorIs(t, err, fs.ErrNotExist)
+
+	// the empty directory is removed with the file
+	_, err = root.Stat("b_1")
+	require.ErrorIs(t, err, fs.ErrNotExist)
+}
+
+func TestFilesystem_Abort(t *testing.T) {
+	t.Parallel()
+
+	root, err := os.OpenRoot(t.TempDir())
+	require.NoError(t, err)
+
+	t.Cleanup(func() { root.Close() })
+
+	store := NewFilesystem(root)
+
+	w, err := store.Create(t.Context(), "b_1/file.txt", "")
+	require.NoError(t, err)
+
+	_, err = io.WriteString(w, "partial")
+	require.NoError(t, err)
+	require.NoError(t, w.Abort(t.Context()))
+
+	_, _, err = store.Open(t.Context(), "b_1/file.txt")
+	require.ErrorIs(t, err, fs.ErrNotExist)
+}
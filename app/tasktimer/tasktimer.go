@@ -0,0 +1,173 @@
+// Package tasktimer closes tasks that stay open longer than their timeout,
+// so that the work on a ticket does not stall on forgotten manual steps. A
+// timed out task is completed, failed, or closed and followed by an
+// escalation task. Each outcome is recorded in the timeline of the ticket.
+package tasktimer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/SecurityBrewery/catalyst/app/database/sqlc"
+	"github.com/SecurityBrewery/catalyst/app/maintenance"
+	"github.com/SecurityBrewery/catalyst/app/pointer"
+)
+
+const (
+	ActionComplete = "complete"
+	ActionFail     = "fail"
+	ActionEscalate = "escalate"
+
+	ResultCompleted = "completed"
+	ResultFailed    = "failed"
+	ResultEscalated = "escalated"
+
+	checkInterval = time.Minute
+)
+
+// Actions returns the supported timeout actions.
+func Actions() []string {
+	return []string{ActionComplete, ActionFail, ActionEscalate}
+}
+
+type Timer struct {
+	queries *sqlc.Queries
+	now     func() time.Time
+}
+
+func New(queries *sqlc.Queries) *Timer {
+	return &Timer{
+		queries: queries,
+		now:     time.Now,
+	}
+}
+
+// Set starts the timer of a task, a previous timer of the task is replaced.
+// Escalation tasks are named after the task unless a name is given and are
+// owned by the owner of the task unless an owner is given.
+func (t *Timer) Set(ctx context.Context, task string, timeout time.Duration, action string, escalationName, escalationOwner *string) (*sqlc.TaskTimer, error) {
+	if !slices.Contains(Actions(), action) {
+		return nil, fmt.Errorf("unknown timeout action %q", action)
+	}
+
+	if timeout <= 0 {
+		return nil, errors.New("timeout must be positive")
+	}
+
+	timer, err := t.queries.SetTaskTimer(ctx, sqlc.SetTaskTimerParams{
+		Task:            task,
+		Due:             t.now().UTC().Add(timeout).Truncate(time.Second),
+		Action:          action,
+		EscalationName:  escalationName,
+		EscalationOwner: escalationOwner,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &timer, nil
+}
+
+// Start fires due timers every minute until the context is canceled.
+func (t *Timer) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(checkInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := t.Fire(ctx); err != nil {
+					slog.ErrorContext(ctx, "Failed to fire task timers", "error", err)
+				}
+			}
+		}
+	}()
+}
+
+// Fire applies the action of all due timers of open tasks. Timers fire only
+// once, closing the task before its timeout cancels the timer.
+func (t *Timer) Fire(ctx context.Context) error {
+	now := t.now().UTC()
+
+	due, err := t.queries.ListDueTaskTimers(ctx, now.Format(time.DateTime))
+	if err != nil {
+		return err
+	}
+
+	for _, timer := range due {
+		// paused while the ticket is in maintenance, see maintenance.Pauser
+		if paused, err := maintenance.Covers(ctx, t.queries, timer.TaskTicket, now); err != nil {
+			return err
+		} else if paused {
+			continue
+		}
+
+		if err := t.fire(ctx, &timer, now); err != nil {
+			return fmt.Errorf("failed to fire timer of task %s: %w", timer.Task, err)
+		}
+	}
+
+	return nil
+}
+
+func (t *Timer) fire(ctx context.Context, timer *sqlc.ListDueTaskTimersRow, now time.Time) error {
+	if _, err := t.queries.UpdateTask(ctx, sqlc.UpdateTaskParams{ID: timer.Task, Open: pointer.Pointer(false)}); err != nil {
+		return err
+	}
+
+	params := sqlc.FireTaskTimerParams{Task: timer.Task, Fired: &now}
+
+	var message string
+
+	switch timer.Action {
+	case ActionComplete:
+		params.Result = pointer.Pointer(ResultCompleted)
+		message = fmt.Sprintf("Task %q was completed automatically after its timeout", timer.TaskName)
+	case ActionFail:
+		params.Result = pointer.Pointer(ResultFailed)
+		message = fmt.Sprintf("Task %q failed after its timeout", timer.TaskName)
+	case ActionEscalate:
+		name := pointer.Dereference(timer.EscalationName)
+		if name == "" {
+			name = "Escalation: " + timer.TaskName
+		}
+
+		owner := timer.EscalationOwner
+		if owner == nil {
+			owner = timer.TaskOwner
+		}
+
+		escalation, err := t.queries.CreateTask(ctx, sqlc.CreateTaskParams{
+			Name:   name,
+			Open:   true,
+			Owner:  owner,
+			Ticket: timer.TaskTicket,
+		})
+		if err != nil {
+			return err
+		}
+
+		params.Result = pointer.Pointer(ResultEscalated)
+		params.EscalationTask = &escalation.ID
+		message = fmt.Sprintf("Task %q timed out and was escalated to %q", timer.TaskName, name)
+	default:
+		return fmt.Errorf("unknown timeout action %q", timer.Action)
+	}
+
+	if _, err := t.queries.CreateTimeline(ctx, sqlc.CreateTimelineParams{
+		Message: message,
+		Ticket:  timer.TaskTicket,
+		Time:    now,
+	}); err != nil {
+		return err
+	}
+
+	return t.queries.FireTaskTimer(ctx, params)
+}
@@ -0,0 +1,120 @@
+package tasktimer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/SecurityBrewery/catalyst/app/data"
+	"github.com/SecurityBrewery/catalyst/app/database/sqlc"
+	"github.com/SecurityBrewery/catalyst/app/pointer"
+)
+
+func newTestTimer(t *testing.T) (*Timer, *time.Time) {
+	t.Helper()
+
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	timer := New(data.NewTestDB(t, t.TempDir()))
+	timer.now = func() time.Time { return now }
+
+	return timer, &now
+}
+
+func TestTimer_Fire(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		action  string
+		result  string
+		message string
+	}{
+		{name: "complete", action: ActionComplete, result: ResultCompleted, message: `Task "Test Task" was completed automatically after its timeout`},
+		{name: "fail", action: ActionFail, result: ResultFailed, message: `Task "Test Task" failed after its timeout`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := t.Context()
+			timer, now := newTestTimer(t)
+
+			_, err := timer.Set(ctx, "k_test_task", 30*time.Minute, tt.action, nil, nil)
+			require.NoError(t, err)
+
+			// not due yet
+			*now = now.Add(20 * time.Minute)
+			require.NoError(t, timer.Fire(ctx))
+
+			task, err := timer.queries.GetTask(ctx, "k_test_task")
+			require.NoError(t, err)
+			assert.True(t, task.Open)
+
+			*now = now.Add(10 * time.Minute)
+			require.NoError(t, timer.Fire(ctx))
+
+			task, err = timer.queries.GetTask(ctx, "k_test_task")
+			require.NoError(t, err)
+			assert.False(t, task.Open)
+
+			fired, err := timer.queries.GetTaskTimer(ctx, "k_test_task")
+			require.NoError(t, err)
+			assert.Equal(t, pointer.Pointer(tt.result), fired.Result)
+			require.NotNil(t, fired.Fired)
+
+			timeline, err := timer.queries.ListTimeline(ctx, sqlc.ListTimelineParams{Ticket: "test-ticket", Limit: 10})
+			require.NoError(t, err)
+			require.NotEmpty(t, timeline)
+			assert.Equal(t, tt.message, timeline[0].Message)
+		})
+	}
+}
+
+func TestTimer_Fire_escalate(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	timer, now := newTestTimer(t)
+
+	_, err := timer.Set(ctx, "k_test_task", time.Hour, ActionEscalate, pointer.Pointer("Call the on-call lead"), nil)
+	require.NoError(t, err)
+
+	*now = now.Add(time.Hour)
+	require.NoError(t, timer.Fire(ctx))
+
+	fired, err := timer.queries.GetTaskTimer(ctx, "k_test_task")
+	require.NoError(t, err)
+	assert.Equal(t, pointer.Pointer(ResultEscalated), fired.Result)
+	require.NotNil(t, fired.EscalationTask)
+
+	escalation, err := timer.queries.GetTask(ctx, *fired.EscalationTask)
+	require.NoError(t, err)
+	assert.Equal(t, "Call the on-call lead", escalation.Name)
+	assert.True(t, escalation.Open)
+	assert.Equal(t, pointer.Pointer("u_bob_analyst"), escalation.Owner)
+	assert.Equal(t, "test-ticket", escalation.Ticket)
+
+	// timers fire only once
+	*now = now.Add(time.Hour)
+	require.NoError(t, timer.Fire(ctx))
+
+	tasks, err := timer.queries.ListTasks(ctx, sqlc.ListTasksParams{Ticket: "test-ticket", Limit: 10})
+	require.NoError(t, err)
+	assert.Len(t, tasks, 2)
+}
+
+func TestTimer_Set_invalid(t *testing.T) {
+	t.Parallel()
+
+	timer, _ := newTestTimer(t)
+
+	_, err := timer.Set(t.Context(), "k_test_task", time.Hour, "snooze", nil, nil)
+	require.Error(t, err)
+
+	_, err = timer.Set(t.Context(), "k_test_task", 0, ActionComplete, nil, nil)
+	require.Error(t, err)
+}
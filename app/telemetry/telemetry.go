@@ -0,0 +1,90 @@
+// Package telemetry collects anonymous usage statistics for capacity
+// planning. Collection is opt-in via the "telemetry" feature flag and the
+// statistics are only exposed locally, nothing is sent anywhere.
+package telemetry
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/SecurityBrewery/catalyst/app/counter"
+	"github.com/SecurityBrewery/catalyst/app/database"
+	"github.com/SecurityBrewery/catalyst/app/database/sqlc"
+)
+
+const Flag = "telemetry"
+
+var automationRuns = counter.NewCounter()
+
+type Usage struct {
+	Enabled            bool
+	TicketsPerDay      map[string]int
+	AutomationRuns     map[string]int
+	ReactionsByTrigger map[string]int
+	Flags              []string
+}
+
+func Enabled(ctx context.Context, queries *sqlc.Queries) bool {
+	_, err := queries.GetFeature(ctx, Flag)
+
+	return err == nil
+}
+
+// RecordAutomationRun counts a reaction action run, if telemetry is enabled.
+func RecordAutomationRun(ctx context.Context, queries *sqlc.Queries, action string) {
+	if !Enabled(ctx, queries) {
+		return
+	}
+
+	automationRuns.Increment(action)
+}
+
+// Collect aggregates the usage statistics. If telemetry is disabled, only
+// Enabled is set.
+func Collect(ctx context.Context, queries *sqlc.Queries) (*Usage, error) {
+	usage := &Usage{
+		TicketsPerDay:      map[string]int{},
+		AutomationRuns:     map[string]int{},
+		ReactionsByTrigger: map[string]int{},
+		Flags:              []string{},
+	}
+
+	if !Enabled(ctx, queries) {
+		return usage, nil
+	}
+
+	usage.Enabled = true
+
+	ticketsPerDay, err := queries.CountTicketsPerDay(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count tickets: %w", err)
+	}
+
+	for _, day := range ticketsPerDay {
+		usage.TicketsPerDay[day.Day] = int(day.Count)
+	}
+
+	reactions, err := queries.CountReactionsByTrigger(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count reactions: %w", err)
+	}
+
+	for _, reaction := range reactions {
+		usage.ReactionsByTrigger[reaction.Trigger] = int(reaction.Count)
+	}
+
+	features, err := database.PaginateItems(ctx, func(ctx context.Context, offset, limit int64) ([]sqlc.ListFeaturesRow, error) {
+		return queries.ListFeatures(ctx, sqlc.ListFeaturesParams{Limit: limit, Offset: offset})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list features: %w", err)
+	}
+
+	for _, feature := range features {
+		usage.Flags = append(usage.Flags, feature.Key)
+	}
+
+	usage.AutomationRuns = automationRuns.Counts()
+
+	return usage, nil
+}
@@ -0,0 +1,35 @@
+package telemetry_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/SecurityBrewery/catalyst/app/data"
+	"github.com/SecurityBrewery/catalyst/app/telemetry"
+)
+
+func TestCollect(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	queries := data.NewTestDB(t, t.TempDir())
+
+	usage, err := telemetry.Collect(ctx, queries)
+	require.NoError(t, err)
+	assert.False(t, usage.Enabled)
+	assert.Empty(t, usage.ReactionsByTrigger)
+
+	_, err = queries.CreateFeature(ctx, telemetry.Flag)
+	require.NoError(t, err)
+
+	telemetry.RecordAutomationRun(ctx, queries, "python")
+
+	usage, err = telemetry.Collect(ctx, queries)
+	require.NoError(t, err)
+	assert.True(t, usage.Enabled)
+	assert.Contains(t, usage.Flags, telemetry.Flag)
+	assert.NotEmpty(t, usage.ReactionsByTrigger)
+	assert.GreaterOrEqual(t, usage.AutomationRuns["python"], 1)
+}
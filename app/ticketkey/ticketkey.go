@@ -0,0 +1,56 @@
+// Package ticketkey handles the human-readable keys of tickets, e.g.
+// IR-2024-0042. A key is made of the key prefix of the ticket type, the
+// year the ticket was created and a counter that restarts every year. The
+// keys are allocated by the database when a ticket is inserted, the
+// internal IDs of tickets stay unchanged.
+package ticketkey
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+
+	"github.com/SecurityBrewery/catalyst/app/database/sqlc"
+)
+
+var (
+	ErrInvalidPrefix = errors.New("invalid key prefix")
+
+	prefixPattern = regexp.MustCompile(`^[A-Z][A-Z0-9]{0,9}$`)
+	keyPattern    = regexp.MustCompile(`^[A-Z][A-Z0-9]{0,9}-[0-9]{4}-[0-9]{4,}$`)
+)
+
+// ValidatePrefix checks that a prefix has one to ten upper case letters and
+// digits and starts with a letter. The empty prefix disables keys.
+func ValidatePrefix(prefix string) error {
+	if prefix != "" && !prefixPattern.MatchString(prefix) {
+		return fmt.Errorf("%w %q, use up to ten upper case letters and digits starting with a letter", ErrInvalidPrefix, prefix)
+	}
+
+	return nil
+}
+
+// IsKey reports whether s has the form of a ticket key.
+func IsKey(s string) bool {
+	return keyPattern.MatchString(s)
+}
+
+// Resolve returns the ID of the ticket with the key. Anything that is not
+// a key of an existing ticket is returned unchanged, so IDs and unknown
+// keys fail later like any other unknown ID.
+func Resolve(ctx context.Context, queries *sqlc.Queries, idOrKey string) (string, error) {
+	if !IsKey(idOrKey) {
+		return idOrKey, nil
+	}
+
+	id, err := queries.TicketIDByKey(ctx, &idOrKey)
+	if errors.Is(err, sql.ErrNoRows) {
+		return idOrKey, nil
+	} else if err != nil {
+		return "", err
+	}
+
+	return id, nil
+}
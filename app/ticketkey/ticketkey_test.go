@@ -0,0 +1,95 @@
+package ticketkey
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/SecurityBrewery/catalyst/app/data"
+	"github.com/SecurityBrewery/catalyst/app/database/sqlc"
+	"github.com/SecurityBrewery/catalyst/app/pointer"
+)
+
+func TestValidatePrefix(t *testing.T) {
+	t.Parallel()
+
+	require.NoError(t, ValidatePrefix("IR"))
+	require.NoError(t, ValidatePrefix("SOC2"))
+	require.NoError(t, ValidatePrefix(""))
+	require.ErrorIs(t, ValidatePrefix("ir"), ErrInvalidPrefix)
+	require.ErrorIs(t, ValidatePrefix("2IR"), ErrInvalidPrefix)
+	require.ErrorIs(t, ValidatePrefix("IR-1"), ErrInvalidPrefix)
+	require.ErrorIs(t, ValidatePrefix("ABCDEFGHIJK"), ErrInvalidPrefix)
+}
+
+func TestIsKey(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, IsKey("IR-2024-0042"))
+	assert.True(t, IsKey("SOC2-2024-12345"))
+	assert.False(t, IsKey("test-ticket"))
+	assert.False(t, IsKey("t0123456789abcd"))
+	assert.False(t, IsKey("IR-24-0042"))
+	assert.False(t, IsKey("IR-2024-42"))
+}
+
+func TestAllocation(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	queries := data.NewTestDB(t, t.TempDir())
+	year := strconv.Itoa(time.Now().UTC().Year())
+
+	incidentResponse, err := queries.CreateType(ctx, sqlc.CreateTypeParams{Singular: "Incident Response", Plural: "Incident Responses", Schema: []byte(`{}`), KeyPrefix: pointer.Pointer("IR")})
+	require.NoError(t, err)
+
+	forensics, err := queries.CreateType(ctx, sqlc.CreateTypeParams{Singular: "Forensics", Plural: "Forensics", Schema: []byte(`{}`), KeyPrefix: pointer.Pointer("IR")})
+	require.NoError(t, err)
+
+	create := func(typ string) sqlc.TicketRow {
+		t.Helper()
+
+		ticket, err := queries.CreateTicket(ctx, sqlc.CreateTicketParams{Name: "Ticket", Open: true, Type: typ, Schema: []byte(`{}`), State: []byte(`{}`)})
+		require.NoError(t, err)
+
+		row, err := queries.Ticket(ctx, ticket.ID)
+		require.NoError(t, err)
+
+		return row
+	}
+
+	first := create(incidentResponse.ID)
+	assert.Equal(t, "IR-"+year+"-0001", pointer.Dereference(first.Key))
+
+	// types with the same prefix share the counter
+	second := create(forensics.ID)
+	assert.Equal(t, "IR-"+year+"-0002", pointer.Dereference(second.Key))
+
+	// types without a prefix have no keys
+	assert.Nil(t, create("incident").Key)
+
+	// changing the prefix keeps the keys of existing tickets
+	_, err = queries.UpdateType(ctx, sqlc.UpdateTypeParams{ID: forensics.ID, KeyPrefix: pointer.Pointer("DF")})
+	require.NoError(t, err)
+
+	assert.Equal(t, "DF-"+year+"-0001", pointer.Dereference(create(forensics.ID).Key))
+
+	second, err = queries.Ticket(ctx, second.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "IR-"+year+"-0002", pointer.Dereference(second.Key))
+
+	id, err := Resolve(ctx, queries, "IR-"+year+"-0002")
+	require.NoError(t, err)
+	assert.Equal(t, second.ID, id)
+
+	id, err = Resolve(ctx, queries, "IR-"+year+"-0099")
+	require.NoError(t, err)
+	assert.Equal(t, "IR-"+year+"-0099", id)
+
+	id, err = Resolve(ctx, queries, "test-ticket")
+	require.NoError(t, err)
+	assert.Equal(t, "test-ticket", id)
+}
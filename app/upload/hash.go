@@ -0,0 +1,33 @@
+package upload
+
+import (
+	"crypto/md5"  //nolint:gosec // MD5 and SHA-1 are indicators, not used for security
+	"crypto/sha1" //nolint:gosec
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+)
+
+// Hashes are the lowercase hex hashes of the content of a file, which
+// analysts look up as indicators.
+type Hashes struct {
+	MD5    string
+	SHA1   string
+	SHA256 string
+}
+
+// Hash reads r once and returns its MD5, SHA-1 and SHA-256 hashes.
+func Hash(r io.Reader) (Hashes, error) {
+	md5Hash, sha1Hash, sha256Hash := md5.New(), sha1.New(), sha256.New() //nolint:gosec
+
+	if _, err := io.Copy(io.MultiWriter(md5Hash, sha1Hash, sha256Hash), r); err != nil {
+		return Hashes{}, fmt.Errorf("failed to hash file: %w", err)
+	}
+
+	return Hashes{
+		MD5:    hex.EncodeToString(md5Hash.Sum(nil)),
+		SHA1:   hex.EncodeToString(sha1Hash.Sum(nil)),
+		SHA256: hex.EncodeToString(sha256Hash.Sum(nil)),
+	}, nil
+}
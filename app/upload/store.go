@@ -0,0 +1,158 @@
+package upload
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path/filepath"
+
+	tusd "github.com/tus/tusd/v2/pkg/handler"
+	"github.com/tus/tusd/v2/pkg/rootstore"
+)
+
+var errFinished = errors.New("the upload is finished")
+
+// UseIn sets the tusd data store of the uploads in the composer. The chunks
+// of an upload are written to the uploads directory, a finished upload is
+// moved to the storage.
+func (u *Uploader) UseIn(composer *tusd.StoreComposer) {
+	if u.local() {
+		rootstore.New(u.Root).UseIn(composer)
+
+		return
+	}
+
+	store := dataStore{RootStore: rootstore.New(u.Root), uploader: u}
+
+	composer.UseCore(store)
+	composer.UseTerminater(store)
+	composer.UseLengthDeferrer(store)
+}
+
+// dataStore keeps the unfinished uploads in the uploads directory and reads
+// the finished uploads from the storage.
+type dataStore struct {
+	rootstore.RootStore
+	uploader *Uploader
+}
+
+func (s dataStore) NewUpload(ctx context.Context, info tusd.FileInfo) (tusd.Upload, error) {
+	upload, err := s.RootStore.NewUpload(ctx, info)
+	if err != nil {
+		return nil, err
+	}
+
+	return &stagedUpload{Upload: upload, store: s}, nil
+}
+
+func (s dataStore) GetUpload(ctx context.Context, id string) (tusd.Upload, error) {
+	upload, err := s.RootStore.GetUpload(ctx, id)
+	if errors.Is(err, tusd.ErrNotFound) {
+		return s.storedUpload(ctx, id)
+	} else if err != nil {
+		return nil, err
+	}
+
+	return &stagedUpload{Upload: upload, store: s}, nil
+}
+
+func (s dataStore) storedUpload(ctx context.Context, id string) (tusd.Upload, error) {
+	content, _, err := s.uploader.Storage.Open(ctx, id+".info")
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, tusd.ErrNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	defer content.Close()
+
+	var info tusd.FileInfo
+	if err := json.NewDecoder(content).Decode(&info); err != nil {
+		return nil, fmt.Errorf("failed to decode file info of %s: %w", id, err)
+	}
+
+	// the info is written when the upload is created
+	info.Offset = info.Size
+
+	return &storedUpload{info: info, uploader: s.uploader}, nil
+}
+
+func (s dataStore) AsTerminatableUpload(upload tusd.Upload) tusd.TerminatableUpload {
+	return upload.(tusd.TerminatableUpload)
+}
+
+func (s dataStore) AsLengthDeclarableUpload(upload tusd.Upload) tusd.LengthDeclarableUpload {
+	return upload.(tusd.LengthDeclarableUpload)
+}
+
+// stagedUpload is an upload in the uploads directory.
+type stagedUpload struct {
+	tusd.Upload
+	store dataStore
+}
+
+// FinishUpload moves the upload to the storage.
+func (u *stagedUpload) FinishUpload(ctx context.Context) error {
+	info, err := u.GetInfo(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := u.store.uploader.move(ctx, info.Storage["Path"], info.MetaData["filetype"]); err != nil {
+		return err
+	}
+
+	if err := u.store.uploader.move(ctx, info.Storage["InfoPath"], "application/json"); err != nil {
+		return err
+	}
+
+	// fails if other files are left
+	_ = u.store.uploader.Root.Remove(filepath.Dir(filepath.FromSlash(info.Storage["Path"])))
+
+	return nil
+}
+
+func (u *stagedUpload) Terminate(ctx context.Context) error {
+	return u.store.RootStore.AsTerminatableUpload(u.Upload).Terminate(ctx)
+}
+
+func (u *stagedUpload) DeclareLength(ctx context.Context, length int64) error {
+	return u.store.RootStore.AsLengthDeclarableUpload(u.Upload).DeclareLength(ctx, length)
+}
+
+// storedUpload is a finished upload in the storage.
+type storedUpload struct {
+	info     tusd.FileInfo
+	uploader *Uploader
+}
+
+func (u *storedUpload) GetInfo(context.Context) (tusd.FileInfo, error) {
+	return u.info, nil
+}
+
+func (u *storedUpload) WriteChunk(context.Context, int64, io.Reader) (int64, error) {
+	return 0, errFinished
+}
+
+func (u *storedUpload) GetReader(ctx context.Context) (io.ReadCloser, error) {
+	content, _, err := u.uploader.Storage.Open(ctx, u.info.Storage["Path"])
+
+	return content, err
+}
+
+func (u *storedUpload) FinishUpload(context.Context) error {
+	return nil
+}
+
+func (u *storedUpload) Terminate(ctx context.Context) error {
+	return errors.Join(
+		u.uploader.Storage.Remove(ctx, u.info.Storage["Path"]),
+		u.uploader.Storage.Remove(ctx, u.info.Storage["InfoPath"]),
+	)
+}
+
+func (u *storedUpload) DeclareLength(context.Context, int64) error {
+	return errFinished
+}
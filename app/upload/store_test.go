@@ -0,0 +1,202 @@
+package upload
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/fs"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	tusd "github.com/tus/tusd/v2/pkg/handler"
+
+	"github.com/SecurityBrewery/catalyst/app/storage"
+)
+
+// memStorage keeps the files in memory, like a bucket.
+type memStorage struct {
+	mu    sync.Mutex
+	files map[string][]byte
+}
+
+func (m *memStorage) Open(_ context.Context, name string) (io.ReadCloser, int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	data, ok := m.files[name]
+	if !ok {
+		return nil, 0, fs.ErrNotExist
+	}
+
+	return io.NopCloser(bytes.NewReader(data)), int64(len(data)), nil
+}
+
+func (m *memStorage) Create(_ context.Context, name, _ string) (storage.Writer, error) {
+	return &memWriter{storage: m, name: name}, nil
+}
+
+func (m *memStorage) Remove(_ context.Context, name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.files, name)
+
+	return nil
+}
+
+func (m *memStorage) Walk(_ context.Context, fn func(name string, size int64) error) error {
+	for name, data := range m.files {
+		if err := fn(name, int64(len(data))); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+type memWriter struct {
+	bytes.Buffer
+	storage *memStorage
+	name    string
+}
+
+func (w *memWriter) Close() error {
+	w.storage.mu.Lock()
+	defer w.storage.mu.Unlock()
+
+	w.storage.files[w.name] = w.Bytes()
+
+	return nil
+}
+
+func (w *memWriter) Abort(context.Context) error {
+	return nil
+}
+
+func newRemoteUploader(t *testing.T) (*Uploader, *memStorage) {
+	t.Helper()
+
+	uploader, err := New(t.TempDir())
+	require.NoError(t, err)
+
+	t.Cleanup(func() { uploader.Root.Close() })
+
+	bucket := &memStorage{files: map[string][]byte{}}
+	uploader.Storage = bucket
+
+	return uploader, bucket
+}
+
+func TestUploader_remote(t *testing.T) {
+	t.Parallel()
+
+	uploader, bucket := newRemoteUploader(t)
+
+	blob, err := uploader.CreateFile(t.Context(), "b_1", "evidence.txt", []byte("evidence"))
+	require.NoError(t, err)
+	assert.Contains(t, bucket.files, "b_1.info")
+	assert.Equal(t, "evidence", string(bucket.files["b_1/"+blob]))
+
+	content, contentType, size, err := uploader.File(t.Context(), "b_1", blob)
+	require.NoError(t, err)
+
+	data, err := io.ReadAll(content)
+	require.NoError(t, err)
+
+	assert.Equal(t, "evidence", string(data))
+	assert.Equal(t, "text/plain; charset=utf-8", contentType)
+	assert.Equal(t, int64(8), size)
+
+	require.NoError(t, uploader.DeleteFile(t.Context(), "b_1", blob))
+	assert.Empty(t, bucket.files)
+}
+
+func TestUploader_Store(t *testing.T) {
+	t.Parallel()
+
+	uploader, bucket := newRemoteUploader(t)
+
+	// an upload that was restored to the uploads directory
+	require.NoError(t, uploader.Root.Mkdir("b_1", 0o755))
+	require.NoError(t, writeFile(uploader.Root, "b_1.info", "{}"))
+	require.NoError(t, writeFile(uploader.Root, "b_1/file.txt", "restored"))
+
+	require.NoError(t, uploader.Store(t.Context(), "b_1", "file.txt"))
+	require.NoError(t, uploader.Store(t.Context(), "b_2", "missing.txt"))
+
+	assert.Equal(t, map[string][]byte{"b_1.info": []byte("{}"), "b_1/file.txt": []byte("restored")}, bucket.files)
+
+	_, err := uploader.Root.Stat("b_1")
+	require.ErrorIs(t, err, fs.ErrNotExist)
+}
+
+func TestDataStore(t *testing.T) {
+	t.Parallel()
+
+	uploader, bucket := newRemoteUploader(t)
+
+	composer := tusd.NewStoreComposer()
+	uploader.UseIn(composer)
+
+	upload, err := composer.Core.NewUpload(t.Context(), tusd.FileInfo{
+		ID:       "b_1",
+		Size:     8,
+		MetaData: tusd.MetaData{"filetype": "text/plain"},
+		Storage:  map[string]string{"Path": "b_1/file.txt"},
+	})
+	require.NoError(t, err)
+
+	_, err = upload.WriteChunk(t.Context(), 0, strings.NewReader("evidence"))
+	require.NoError(t, err)
+
+	// unfinished uploads are in the uploads directory
+	assert.Empty(t, bucket.files)
+
+	require.NoError(t, upload.FinishUpload(t.Context()))
+
+	assert.Equal(t, "evidence", string(bucket.files["b_1/file.txt"]))
+	assert.Contains(t, bucket.files, "b_1.info")
+
+	_, err = uploader.Root.Stat("b_1")
+	require.ErrorIs(t, err, fs.ErrNotExist)
+
+	// finished uploads are read from the storage
+	upload, err = composer.Core.GetUpload(t.Context(), "b_1")
+	require.NoError(t, err)
+
+	info, err := upload.GetInfo(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, int64(8), info.Offset)
+
+	content, err := upload.GetReader(t.Context())
+	require.NoError(t, err)
+
+	data, err := io.ReadAll(content)
+	require.NoError(t, err)
+	assert.Equal(t, "evidence", string(data))
+
+	require.NoError(t, composer.Terminater.AsTerminatableUpload(upload).Terminate(t.Context()))
+	assert.Empty(t, bucket.files)
+
+	_, err = composer.Core.GetUpload(t.Context(), "b_1")
+	require.ErrorIs(t, err, tusd.ErrNotFound)
+}
+
+func writeFile(root *os.Root, name, content string) error {
+	f, err := root.Create(name)
+	if err != nil {
+		return err
+	}
+
+	if _, err := f.WriteString(content); err != nil {
+		f.Close()
+
+		return err
+	}
+
+	return f.Close()
+}
@@ -1,22 +1,38 @@
 package upload
 
 import (
+	"bytes"
+	"context"
 	"crypto/rand"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"io/fs"
 	"net/http"
 	"os"
 	"path"
 	"path/filepath"
 	"strings"
+
+	"github.com/SecurityBrewery/catalyst/app/storage"
 )
 
+// Uploader keeps the uploaded files in the storage. Root is the uploads
+// directory, which is the storage by default. With another storage, it only
+// holds the uploads that are not finished yet.
 type Uploader struct {
-	Root *os.Root
+	Root    *os.Root
+	Storage storage.Storage
 }
 
+// New keeps the uploaded files in the uploads directory in dir.
 func New(dir string) (*Uploader, error) {
+	return NewWithStorage(dir, storage.Config{})
+}
+
+// NewWithStorage keeps the uploaded files in the storage of the config.
+func NewWithStorage(dir string, config storage.Config) (*Uploader, error) {
 	uploadsDir := path.Join(dir, "uploads")
 
 	if err := os.MkdirAll(uploadsDir, 0o755); err != nil {
@@ -28,11 +44,26 @@ func New(dir string) (*Uploader, error) {
 		return nil, fmt.Errorf("failed to open uploads directory: %w", err)
 	}
 
+	store, err := storage.New(root, config)
+	if err != nil {
+		root.Close()
+
+		return nil, err
+	}
+
 	return &Uploader{
-		Root: root,
+		Root:    root,
+		Storage: store,
 	}, nil
 }
 
+// local reports whether the storage is the uploads directory.
+func (u *Uploader) local() bool {
+	_, ok := u.Storage.(*storage.Filesystem)
+
+	return ok
+}
+
 type InfoFileMetaData struct {
 	Filename     string `json:"filename"`
 	Filetype     string `json:"filetype"`
@@ -58,7 +89,7 @@ type InfoFile struct {
 	Storage        InfoFileStorage  `json:"Storage"`
 }
 
-func (u *Uploader) CreateFile(id string, filename string, blob []byte) (string, error) {
+func (u *Uploader) CreateFile(ctx context.Context, id string, filename string, blob []byte) (string, error) {
 	filename = filepath.Base(filename)
 
 	infoFilePath, filePath := u.Paths(id, filename)
@@ -87,39 +118,30 @@ func (u *Uploader) CreateFile(id string, filename string, blob []byte) (string,
 		},
 	}
 
-	if err := u.Root.Mkdir(id, 0o755); err != nil {
-		return "", fmt.Errorf("failed to create directory for file %s: %w", id, err)
-	}
-
-	file, err := u.Root.Create(infoFilePath)
+	info, err := json.Marshal(infoFileData)
 	if err != nil {
-		return "", fmt.Errorf("failed to create file info %s: %w", infoFilePath, err)
-	}
-	defer file.Close()
-
-	if err := json.NewEncoder(file).Encode(infoFileData); err != nil {
 		return "", fmt.Errorf("failed to encode file info %s: %w", infoFilePath, err)
 	}
 
-	file, err = u.Root.Create(filePath)
-	if err != nil {
-		return "", fmt.Errorf("failed to create file %s: %w", filePath, err)
+	if err := u.write(ctx, filePath, fileType, bytes.NewReader(blob)); err != nil {
+		return "", fmt.Errorf("failed to write blob to file %s: %w", filePath, err)
 	}
-	defer file.Close()
 
-	if _, err := file.Write(blob); err != nil {
-		return "", fmt.Errorf("failed to write blob to file %s: %w", filePath, err)
+	if err := u.write(ctx, infoFilePath, "application/json", bytes.NewReader(info)); err != nil {
+		return "", fmt.Errorf("failed to write file info %s: %w", infoFilePath, err)
 	}
 
 	return path.Base(filePath), nil
 }
 
-func (u *Uploader) File(id, name string) (*os.File, string, int64, error) {
+// File returns the content, the content type and the size of an uploaded
+// file. The caller must close the content.
+func (u *Uploader) File(ctx context.Context, id, name string) (io.ReadCloser, string, int64, error) {
 	infoFilePath := id + ".info"
 
-	infoFile, err := u.Root.Open(infoFilePath)
+	infoFile, _, err := u.Storage.Open(ctx, infoFilePath)
 	if err != nil {
-		if os.IsNotExist(err) {
+		if errors.Is(err, fs.ErrNotExist) {
 			return nil, "", 0, fmt.Errorf("file info %s does not exist", infoFilePath)
 		}
 
@@ -134,27 +156,85 @@ func (u *Uploader) File(id, name string) (*os.File, string, int64, error) {
 
 	filePath := path.Join(id, name)
 
-	info, err := u.Root.Stat(filePath)
-	if os.IsNotExist(err) {
-		return nil, "", 0, fmt.Errorf("file %s does not exist", filePath)
-	}
-
-	f, err := u.Root.Open(filePath)
+	f, size, err := u.Storage.Open(ctx, filePath)
 	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, "", 0, fmt.Errorf("file %s does not exist", filePath)
+		}
+
 		return nil, "", 0, fmt.Errorf("failed to open file %s: %w", filePath, err)
 	}
 
-	return f, infoFileData.MetaData.Filetype, info.Size(), nil
+	return f, infoFileData.MetaData.Filetype, size, nil
 }
 
-func (u *Uploader) DeleteFile(id, name string) error {
+func (u *Uploader) DeleteFile(ctx context.Context, id, name string) error {
 	return errors.Join(
-		u.Root.Remove(path.Join(id, name)),
-		u.Root.Remove(id),
-		u.Root.Remove(id+".info"),
+		u.Storage.Remove(ctx, path.Join(id, name)),
+		u.Storage.Remove(ctx, id+".info"),
 	)
 }
 
+// Store moves an upload that is still in the uploads directory, e.g. after
+// a restore, to the storage. It does nothing if the uploads directory is the
+// storage or the upload is not there.
+func (u *Uploader) Store(ctx context.Context, id, name string) error {
+	if u.local() {
+		return nil
+	}
+
+	infoFilePath, filePath := id+".info", path.Join(id, name)
+
+	if _, err := u.Root.Stat(filePath); errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+
+	for _, name := range []string{filePath, infoFilePath} {
+		if err := u.move(ctx, name, ""); err != nil {
+			return err
+		}
+	}
+
+	// fails if other files are left
+	_ = u.Root.Remove(id)
+
+	return nil
+}
+
+// move copies a file of the uploads directory to the storage and removes
+// it from the directory. A missing file is skipped.
+func (u *Uploader) move(ctx context.Context, name, contentType string) error {
+	f, err := u.Root.Open(filepath.FromSlash(name))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	err = u.write(ctx, name, contentType, f)
+	f.Close()
+
+	if err != nil {
+		return fmt.Errorf("failed to store %s: %w", name, err)
+	}
+
+	return u.Root.Remove(filepath.FromSlash(name))
+}
+
+// write writes a file to the storage.
+func (u *Uploader) write(ctx context.Context, name, contentType string, r io.Reader) error {
+	w, err := u.Storage.Create(ctx, name, contentType)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(w, r); err != nil {
+		return errors.Join(err, w.Abort(context.WithoutCancel(ctx)))
+	}
+
+	return w.Close()
+}
+
 func (u *Uploader) Paths(id string, filename string) (infoFilePath, filePath string) {
 	infoFilePath = id + ".info"
 	ext := path.Ext(filename)
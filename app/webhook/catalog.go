@@ -0,0 +1,230 @@
+package webhook
+
+import (
+	"cmp"
+	"encoding/json"
+	"maps"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/SecurityBrewery/catalyst/app/database"
+)
+
+// exampleTime is the time used in example payloads, so that the catalog does
+// not change between requests.
+var exampleTime = time.Date(2025, time.January, 1, 12, 0, 0, 0, time.UTC)
+
+var (
+	timeType      = reflect.TypeFor[time.Time]()
+	marshalerType = reflect.TypeFor[json.Marshaler]()
+)
+
+// CatalogEntry describes an event type with the JSON Schema of its whole
+// payload and an example payload, so that consumers can be built without
+// reading the source.
+type CatalogEntry struct {
+	EventType
+
+	PayloadSchema map[string]any `json:"payload_schema"`
+	Example       map[string]any `json:"example"`
+}
+
+// Catalog lists all events that are sent to webhooks with their payloads.
+// The schemas and examples are generated from the Go types that are sent.
+func Catalog() []CatalogEntry {
+	entries := make([]CatalogEntry, 0, len(recordSchemas)*3)
+
+	for _, event := range EventTypes() {
+		record := recordType(event.Collection)
+		if event.Action == database.DeleteAction {
+			record = reflect.TypeFor[string]()
+		}
+
+		payloadSchema := jsonSchema(reflect.TypeFor[Payload](), nil)
+		payloadSchema["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+		payloadSchema["title"] = event.Collection + "." + event.Action
+		payloadSchema["properties"].(map[string]any)["record"] = jsonSchema(record, nil)
+
+		example, _ := exampleValue(reflect.TypeFor[Payload](), nil).(map[string]any)
+		example["version"] = event.Version
+		example["action"] = event.Action
+		example["collection"] = event.Collection
+		example["record"] = exampleValue(record, nil)
+
+		entries = append(entries, CatalogEntry{
+			EventType:     event,
+			PayloadSchema: payloadSchema,
+			Example:       example,
+		})
+	}
+
+	return entries
+}
+
+func recordType(collection string) reflect.Type {
+	for _, r := range recordSchemas {
+		if r.table.ID == collection {
+			return reflect.TypeOf(r.record)
+		}
+	}
+
+	return reflect.TypeFor[any]()
+}
+
+// jsonField is an exported struct field with its name in JSON.
+type jsonField struct {
+	field     reflect.StructField
+	name      string
+	omitempty bool
+}
+
+// jsonFields returns the fields of a struct as encoding/json marshals them,
+// including the fields of embedded structs.
+func jsonFields(t reflect.Type) []jsonField {
+	var fields []jsonField
+
+	for i := range t.NumField() {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+
+		name, options, _ := strings.Cut(tag, ",")
+
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			fields = append(fields, jsonFields(field.Type)...)
+
+			continue
+		}
+
+		fields = append(fields, jsonField{
+			field:     field,
+			name:      cmp.Or(name, field.Name),
+			omitempty: strings.Contains(options, "omitempty"),
+		})
+	}
+
+	return fields
+}
+
+// jsonSchema returns the JSON Schema of the JSON encoding of a Go type.
+// Types that marshal themselves are described as any value. The seen types
+// stop recursive types.
+func jsonSchema(t reflect.Type, seen map[reflect.Type]bool) map[string]any {
+	if t.Kind() == reflect.Pointer {
+		return jsonSchema(t.Elem(), seen)
+	}
+
+	if t == timeType {
+		return map[string]any{"type": "string", "format": "date-time"}
+	}
+
+	if t.Implements(marshalerType) || seen[t] {
+		return map[string]any{}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return map[string]any{"type": "string", "contentEncoding": "base64"}
+		}
+
+		return map[string]any{"type": "array", "items": jsonSchema(t.Elem(), seen)}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": jsonSchema(t.Elem(), seen)}
+	case reflect.Struct:
+		seen = withType(seen, t)
+
+		properties := map[string]any{}
+		required := []string{}
+
+		for _, field := range jsonFields(t) {
+			schema := jsonSchema(field.field.Type, seen)
+
+			switch {
+			case field.field.Type.Kind() == reflect.Pointer && !field.omitempty:
+				schema = map[string]any{"anyOf": []any{schema, map[string]any{"type": "null"}}}
+			case field.field.Type.Kind() != reflect.Pointer && !field.omitempty:
+				required = append(required, field.name)
+			}
+
+			properties[field.name] = schema
+		}
+
+		return map[string]any{"type": "object", "properties": properties, "required": required}
+	default:
+		return map[string]any{}
+	}
+}
+
+// exampleValue returns an example of the JSON encoding of a Go type. Optional
+// fields are included, so that the example shows every field.
+func exampleValue(t reflect.Type, seen map[reflect.Type]bool) any {
+	if t.Kind() == reflect.Pointer {
+		return exampleValue(t.Elem(), seen)
+	}
+
+	if t == timeType {
+		return exampleTime.Format(time.RFC3339)
+	}
+
+	if t.Implements(marshalerType) || seen[t] {
+		return nil
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return false
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return 0
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return ""
+		}
+
+		return []any{exampleValue(t.Elem(), seen)}
+	case reflect.Map:
+		return map[string]any{}
+	case reflect.Struct:
+		seen = withType(seen, t)
+
+		example := map[string]any{}
+		for _, field := range jsonFields(t) {
+			example[field.name] = exampleValue(field.field.Type, seen)
+		}
+
+		return example
+	default:
+		return nil
+	}
+}
+
+func withType(seen map[reflect.Type]bool, t reflect.Type) map[reflect.Type]bool {
+	next := maps.Clone(seen)
+	if next == nil {
+		next = map[reflect.Type]bool{}
+	}
+
+	next[t] = true
+
+	return next
+}
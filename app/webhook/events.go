@@ -0,0 +1,68 @@
+package webhook
+
+import (
+	"github.com/SecurityBrewery/catalyst/app/database"
+	"github.com/SecurityBrewery/catalyst/app/database/sqlc"
+	"github.com/SecurityBrewery/catalyst/app/openapi"
+)
+
+// PayloadVersion is the version of the webhook payload format. Fields may be
+// added to payloads and records without changing the version, removing or
+// changing existing fields increments it.
+const PayloadVersion = 1
+
+type EventType struct {
+	Collection string `json:"collection"`
+	Action     string `json:"action"`
+	Version    int    `json:"version"`
+	// Schema references the OpenAPI schema of the record in the payload.
+	Schema string `json:"schema"`
+}
+
+// recordSchemas maps the collections that emit events to the OpenAPI
+// schema and the Go type of their records.
+var recordSchemas = []struct {
+	table  database.Table
+	schema string
+	record any
+}{
+	{database.TicketsTable, "Ticket", openapi.Ticket{}},
+	{database.CommentsTable, "Comment", openapi.Comment{}},
+	{database.LinksTable, "Link", openapi.Link{}},
+	{database.TasksTable, "Task", openapi.Task{}},
+	{database.TimelinesTable, "TimelineEntry", openapi.TimelineEntry{}},
+	{database.FilesTable, "File", sqlc.File{}},
+	{database.TypesTable, "Type", openapi.Type{}},
+	{database.UsersTable, "User", openapi.User{}},
+	{database.GroupsTable, "Group", openapi.Group{}},
+	{database.ReactionsTable, "Reaction", openapi.Reaction{}},
+	{database.WebhooksTable, "Webhook", openapi.Webhook{}},
+	{database.UserGroupTable, "GroupRelation", openapi.GroupRelation{}},
+	{database.GroupParentTable, "GroupRelation", openapi.GroupRelation{}},
+	{database.AlertsTable, "Alert", openapi.Alert{}},
+	{database.DetectionRulesTable, "DetectionRule", openapi.DetectionRule{}},
+}
+
+// EventTypes lists all events that are sent to webhooks. Delete events only
+// contain the id of the deleted record.
+func EventTypes() []EventType {
+	events := make([]EventType, 0, len(recordSchemas)*3)
+
+	for _, r := range recordSchemas {
+		for _, action := range []string{database.CreateAction, database.UpdateAction, database.DeleteAction} {
+			schema := "#/components/schemas/" + r.schema
+			if action == database.DeleteAction {
+				schema = "string"
+			}
+
+			events = append(events, EventType{
+				Collection: r.table.ID,
+				Action:     action,
+				Version:    PayloadVersion,
+				Schema:     schema,
+			})
+		}
+	}
+
+	return events
+}
@@ -13,6 +13,7 @@ import (
 	"github.com/SecurityBrewery/catalyst/app/database"
 	"github.com/SecurityBrewery/catalyst/app/database/sqlc"
 	"github.com/SecurityBrewery/catalyst/app/hook"
+	"github.com/SecurityBrewery/catalyst/app/redact"
 )
 
 type Webhook struct {
@@ -35,6 +36,7 @@ func BindHooks(hooks *hook.Hooks, queries *sqlc.Queries) {
 }
 
 type Payload struct {
+	Version    int        `json:"version"`
 	Action     string     `json:"action"`
 	Collection string     `json:"collection"`
 	Record     any        `json:"record"`
@@ -64,6 +66,7 @@ func event(ctx context.Context, queries *sqlc.Queries, event, collection string,
 	}
 
 	payload, err := json.Marshal(&Payload{
+		Version:    PayloadVersion,
 		Action:     event,
 		Collection: collection,
 		Record:     record,
@@ -77,16 +80,66 @@ func event(ctx context.Context, queries *sqlc.Queries, event, collection string,
 	}
 
 	for _, webhook := range webhooks {
-		if err := sendWebhook(ctx, webhook, payload); err != nil {
+		if err := sendWebhook(ctx, webhook.Destination, payload); err != nil {
 			slog.ErrorContext(ctx, "failed to send webhook", "action", event, "name", webhook.Name, "collection", webhook.Collection, "destination", webhook.Destination, "error", err.Error())
+
+			if _, err := queries.CreateDeadLetter(ctx, sqlc.CreateDeadLetterParams{
+				Webhook:     webhook.ID,
+				Destination: webhook.Destination,
+				Payload:     payload,
+				Error:       deliveryError(ctx, queries, err),
+			}); err != nil {
+				slog.ErrorContext(ctx, "failed to store dead letter", "name", webhook.Name, "error", err.Error())
+			}
 		} else {
 			slog.InfoContext(ctx, "webhook sent", "action", event, "name", webhook.Name, "collection", webhook.Collection, "destination", webhook.Destination)
 		}
 	}
 }
 
-func sendWebhook(ctx context.Context, webhook sqlc.ListWebhooksRow, payload []byte) error {
-	req, _ := http.NewRequestWithContext(ctx, http.MethodPost, webhook.Destination, bytes.NewReader(payload))
+// Replay sends a failed delivery again and reports whether it was delivered.
+// On success the dead letter is removed, otherwise its error and number of
+// attempts are updated.
+func Replay(ctx context.Context, queries *sqlc.Queries, id string) (bool, error) {
+	deadLetter, err := queries.GetDeadLetter(ctx, id)
+	if err != nil {
+		return false, err
+	}
+
+	if err := sendWebhook(ctx, deadLetter.Destination, deadLetter.Payload); err != nil {
+		if _, err := queries.UpdateDeadLetterAttempt(ctx, sqlc.UpdateDeadLetterAttemptParams{
+			ID:    deadLetter.ID,
+			Error: deliveryError(ctx, queries, err),
+		}); err != nil {
+			return false, fmt.Errorf("failed to update dead letter: %w", err)
+		}
+
+		return false, nil
+	}
+
+	if err := queries.DeleteDeadLetter(ctx, deadLetter.ID); err != nil {
+		return true, fmt.Errorf("failed to delete dead letter: %w", err)
+	}
+
+	return true, nil
+}
+
+// deliveryError returns the stored message of a failed delivery. Responses
+// of misconfigured receivers can echo credentials, so secrets are masked,
+// and the whole message if the secrets cannot be loaded.
+func deliveryError(ctx context.Context, queries *sqlc.Queries, err error) string {
+	redactor, loadErr := redact.Load(ctx, queries)
+	if loadErr != nil {
+		slog.ErrorContext(ctx, "failed to load secrets", "error", loadErr.Error())
+
+		return redact.Mask
+	}
+
+	return redactor.String(err.Error())
+}
+
+func sendWebhook(ctx context.Context, destination string, payload []byte) error {
+	req, _ := http.NewRequestWithContext(ctx, http.MethodPost, destination, bytes.NewReader(payload))
 	req.Header.Set("Content-Type", "application/json")
 
 	resp, err := http.DefaultClient.Do(req)
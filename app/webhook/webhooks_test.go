@@ -0,0 +1,115 @@
+package webhook_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/SecurityBrewery/catalyst/app/data"
+	"github.com/SecurityBrewery/catalyst/app/database/sqlc"
+	"github.com/SecurityBrewery/catalyst/app/webhook"
+)
+
+func TestReplay(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	queries := data.NewTestDB(t, t.TempDir())
+
+	var up atomic.Bool
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if !up.Load() {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+
+	hook, err := queries.CreateWebhook(ctx, sqlc.CreateWebhookParams{
+		Name:        "test",
+		Collection:  "tickets",
+		Destination: server.URL,
+	})
+	require.NoError(t, err)
+
+	deadLetter, err := queries.CreateDeadLetter(ctx, sqlc.CreateDeadLetterParams{
+		Webhook:     hook.ID,
+		Destination: hook.Destination,
+		Payload:     []byte(`{"action":"create"}`),
+		Error:       "connection refused",
+	})
+	require.NoError(t, err)
+
+	delivered, err := webhook.Replay(ctx, queries, deadLetter.ID)
+	require.NoError(t, err)
+	assert.False(t, delivered)
+
+	failed, err := queries.GetDeadLetter(ctx, deadLetter.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), failed.Attempts)
+	assert.Contains(t, failed.Error, "unavailable")
+
+	up.Store(true)
+
+	delivered, err = webhook.Replay(ctx, queries, deadLetter.ID)
+	require.NoError(t, err)
+	assert.True(t, delivered)
+
+	_, err = queries.GetDeadLetter(ctx, deadLetter.ID)
+	require.Error(t, err)
+}
+
+func TestCatalog(t *testing.T) {
+	t.Parallel()
+
+	catalog := webhook.Catalog()
+	require.Len(t, catalog, len(webhook.EventTypes()))
+
+	var create, remove *webhook.CatalogEntry
+
+	for i, entry := range catalog {
+		if entry.Collection == "tickets" && entry.Action == "create" {
+			create = &catalog[i]
+		}
+
+		if entry.Collection == "tickets" && entry.Action == "delete" {
+			remove = &catalog[i]
+		}
+	}
+
+	require.NotNil(t, create)
+	require.NotNil(t, remove)
+
+	record := create.PayloadSchema["properties"].(map[string]any)["record"].(map[string]any)
+	properties := record["properties"].(map[string]any)
+	assert.Equal(t, map[string]any{"type": "string"}, properties["name"])
+	assert.Equal(t, map[string]any{"type": "string", "format": "date-time"}, properties["created"])
+	assert.Contains(t, record["required"], "name")
+	assert.NotContains(t, record["required"], "owner", "optional fields are not required")
+
+	assert.Equal(t, "tickets", create.Example["collection"])
+	assert.Equal(t, "create", create.Example["action"])
+	assert.Equal(t, "string", create.Example["record"].(map[string]any)["name"])
+	assert.Equal(t, "2025-01-01T12:00:00Z", create.Example["record"].(map[string]any)["created"])
+
+	assert.Equal(t, map[string]any{"type": "string"}, remove.PayloadSchema["properties"].(map[string]any)["record"])
+	assert.Equal(t, "string", remove.Example["record"])
+
+	// the examples are valid payloads
+	for _, entry := range catalog {
+		example, err := json.Marshal(entry.Example)
+		require.NoError(t, err)
+
+		var payload webhook.Payload
+		require.NoError(t, json.Unmarshal(example, &payload), entry.Collection+"."+entry.Action)
+	}
+}
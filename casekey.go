@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/SecurityBrewery/catalyst/app/casekey"
+	"github.com/SecurityBrewery/catalyst/app/database"
+)
+
+func caseKeyExport(_ context.Context, _ *cli.Command) error {
+	encoded, err := casekey.Export(dataDir)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(encoded)
+
+	return nil
+}
+
+func caseKeyImport(ctx context.Context, command *cli.Command) error {
+	if command.Args().Len() != 1 {
+		return errors.New("usage: catalyst case-key import <file>")
+	}
+
+	encoded, err := os.ReadFile(command.Args().Get(0))
+	if err != nil {
+		return fmt.Errorf("failed to read master key: %w", err)
+	}
+
+	wrapped, err := storedCaseKey(ctx)
+	if err != nil {
+		return err
+	}
+
+	return casekey.Import(dataDir, string(encoded), wrapped)
+}
+
+// storedCaseKey returns one of the wrapped case keys of the database, or an
+// empty string before the first start or a restore.
+func storedCaseKey(ctx context.Context) (string, error) {
+	if _, err := os.Stat(filepath.Join(dataDir, "data.db")); errors.Is(err, os.ErrNotExist) {
+		return "", nil
+	}
+
+	queries, cleanup, err := database.DB(ctx, dataDir)
+	if err != nil {
+		return "", fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer cleanup()
+
+	wrapped, err := queries.AnyTicketKey(ctx)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	} else if err != nil {
+		return "", fmt.Errorf("failed to read case keys: %w", err)
+	}
+
+	return wrapped, nil
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/SecurityBrewery/catalyst/app/settings"
+)
+
+func checkConfig(ctx context.Context, command *cli.Command) error {
+	var errs []error
+
+	if err := checkDataDir(dataDir); err != nil {
+		errs = append(errs, err)
+	}
+
+	catalyst, cleanup, err := setup(ctx, command)
+	if err != nil {
+		return errors.Join(append(errs, fmt.Errorf("failed to setup catalyst: %w", err))...)
+	}
+
+	defer cleanup()
+
+	s, err := settings.Load(ctx, catalyst.Queries)
+	if err != nil {
+		return errors.Join(append(errs, fmt.Errorf("failed to load settings: %w", err))...)
+	}
+
+	if err := s.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+
+	if s.SMTP.Enabled && s.SMTP.Host != "" {
+		if err := checkReachable(ctx, net.JoinHostPort(s.SMTP.Host, strconv.Itoa(s.SMTP.Port))); err != nil {
+			errs = append(errs, fmt.Errorf("smtp server not reachable: %w", err))
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("configuration is invalid:\n%w", errors.Join(errs...))
+	}
+
+	slog.InfoContext(ctx, "Configuration is valid")
+
+	return nil
+}
+
+func checkDataDir(dir string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("data directory %s cannot be created: %w", dir, err)
+	}
+
+	f, err := os.CreateTemp(dir, ".check-*")
+	if err != nil {
+		return fmt.Errorf("data directory %s is not writable: %w", dir, err)
+	}
+
+	_ = f.Close()
+
+	return os.Remove(filepath.Clean(f.Name()))
+}
+
+func checkReachable(ctx context.Context, address string) error {
+	dialer := net.Dialer{Timeout: 5 * time.Second}
+
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return err
+	}
+
+	return conn.Close()
+}
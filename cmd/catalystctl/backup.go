@@ -0,0 +1,312 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/SecurityBrewery/catalyst/app/backup"
+	"github.com/SecurityBrewery/catalyst/app/database"
+	"github.com/SecurityBrewery/catalyst/app/database/sqlc"
+	"github.com/SecurityBrewery/catalyst/app/migration"
+	"github.com/SecurityBrewery/catalyst/app/openapi"
+	"github.com/SecurityBrewery/catalyst/app/settings"
+	"github.com/SecurityBrewery/catalyst/app/storage"
+	"github.com/SecurityBrewery/catalyst/app/upload"
+)
+
+func backupConfig(command *cli.Command) backup.Config {
+	return backup.Config{
+		Passphrase: command.String("backup-passphrase"),
+		KeyFile:    command.String("backup-key-file"),
+		Version:    version,
+	}
+}
+
+func storageConfig(command *cli.Command) storage.Config {
+	return storage.Config{
+		URL:             command.String("storage"),
+		Endpoint:        command.String("storage-endpoint"),
+		Region:          command.String("storage-region"),
+		AccessKeyID:     command.String("storage-access-key-id"),
+		SecretAccessKey: command.String("storage-secret-access-key"),
+		AzureAccount:    command.String("storage-azure-account"),
+		AzureAccountKey: command.String("storage-azure-account-key"),
+	}
+}
+
+func backupCreate(ctx context.Context, command *cli.Command) error {
+	if command.String("server") != "" {
+		job, err := newClient(command).createBackup(ctx, command.String("base"), command.String("target"), command.StringSlice("exclude"), command.String("output"))
+		if err != nil {
+			return err
+		}
+
+		slog.InfoContext(ctx, "Backup created", "name", job.Name, "location", job.Location, "size", job.Size)
+
+		return nil
+	}
+
+	dataDir := command.String("data-dir")
+
+	queries, cleanup, err := database.DB(ctx, dataDir)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer cleanup()
+
+	uploader, err := upload.NewWithStorage(dataDir, storageConfig(command))
+	if err != nil {
+		return err
+	}
+
+	backups, err := backup.New(queries, uploader, dataDir, backupConfig(command))
+	if err != nil {
+		return err
+	}
+
+	if target := command.String("target"); target != "" {
+		return streamBackup(ctx, queries, backups, target, command)
+	}
+
+	info, err := backups.Create(ctx, command.String("base"), command.StringSlice("exclude")...)
+	if err != nil {
+		return fmt.Errorf("failed to create backup: %w", err)
+	}
+
+	location := filepath.Join(dataDir, "backups", info.Name)
+
+	if output := command.String("output"); output != "" {
+		if err := copyFile(location, output); err != nil {
+			return err
+		}
+	}
+
+	slog.InfoContext(ctx, "Backup created", "name", info.Name, "location", location, "size", info.Size)
+
+	return nil
+}
+
+// streamBackup streams a full backup to a target of the backup storage
+// settings and waits for it.
+func streamBackup(ctx context.Context, queries *sqlc.Queries, backups *backup.Manager, target string, command *cli.Command) error {
+	if command.String("base") != "" || command.String("output") != "" {
+		return errors.New("--target cannot be combined with --base or --output")
+	}
+
+	se, err := settings.Load(ctx, queries)
+	if err != nil {
+		return err
+	}
+
+	t, err := backup.NewTarget(target, &se.BackupStorage)
+	if err != nil {
+		return err
+	}
+
+	job, err := backups.Stream(ctx, t, command.StringSlice("exclude")...)
+	if err != nil {
+		return fmt.Errorf("failed to create backup: %w", err)
+	}
+
+	for job.Status == backup.JobRunning {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(100 * time.Millisecond):
+		}
+
+		if job, err = backups.Job(job.ID); err != nil {
+			return err
+		}
+	}
+
+	if job.Status == backup.JobFailed {
+		return fmt.Errorf("failed to create backup: %s", job.Error)
+	}
+
+	slog.InfoContext(ctx, "Backup created", "name", job.Name, "location", job.Location, "size", job.Size)
+
+	return nil
+}
+
+func backupVerify(ctx context.Context, command *cli.Command) error {
+	name := command.String("name")
+	if (name == "") == (command.Args().Len() != 1) {
+		return errors.New("usage: catalystctl backup verify <backup.zip|backup.zip.enc> or catalystctl --server <url> backup verify --name <backup>")
+	}
+
+	var (
+		report *openapi.BackupVerification
+		err    error
+	)
+
+	switch {
+	case command.String("server") != "":
+		report, err = newClient(command).verifyBackup(ctx, name, command.Args().Get(0))
+	case name != "":
+		report, err = verifyFile(ctx, filepath.Join(command.String("data-dir"), "backups", name), backupConfig(command))
+	default:
+		report, err = verifyFile(ctx, command.Args().Get(0), backupConfig(command))
+	}
+
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+
+	if err := encoder.Encode(report); err != nil {
+		return err
+	}
+
+	if !report.Valid {
+		return errors.New("the backup is invalid")
+	}
+
+	return nil
+}
+
+// verifyFile verifies a backup file, the bases of an incremental backup are
+// expected next to it.
+func verifyFile(ctx context.Context, filename string, config backup.Config) (*openapi.BackupVerification, error) {
+	key, err := backup.NewKey(config)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open backup: %w", err)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	archive, size, err := backup.Archive(f, info.Size(), key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read backup: %w", err)
+	}
+
+	report := backup.Verify(ctx, archive, size, migration.Latest())
+	backup.VerifyBasesIn(archive, size, filepath.Dir(filename), key, report)
+
+	return mapReport(report), nil
+}
+
+func mapReport(report *backup.Report) *openapi.BackupVerification {
+	files := make([]openapi.BackupFileCheck, 0, len(report.Files))
+	for _, file := range report.Files {
+		check := openapi.BackupFileCheck{Path: file.Path, Valid: file.Valid}
+		if file.Error != "" {
+			check.Error = &file.Error
+		}
+
+		files = append(files, check)
+	}
+
+	var version *string
+	if report.Version != "" {
+		version = &report.Version
+	}
+
+	return &openapi.BackupVerification{
+		Valid:                report.Valid,
+		Created:              report.Created,
+		Version:              version,
+		SchemaVersion:        report.Schema,
+		CurrentSchemaVersion: report.CurrentSchema,
+		Compatible:           report.Compatible,
+		Files:                files,
+		Bases:                append([]string{}, report.Bases...),
+		Errors:               append([]string{}, report.Errors...),
+	}
+}
+
+func backupRestore(ctx context.Context, command *cli.Command) error {
+	if command.String("server") != "" {
+		return errors.New("a restore replaces the data directory and needs Catalyst to be stopped, run it on the server without --server")
+	}
+
+	if command.Args().Len() != 1 {
+		return errors.New("usage: catalystctl backup restore <backup.zip|backup.zip.enc>")
+	}
+
+	key, err := backup.NewKey(backupConfig(command))
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Open(command.Args().Get(0))
+	if err != nil {
+		return fmt.Errorf("failed to open backup: %w", err)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	dataDir := command.String("data-dir")
+
+	if err := os.MkdirAll(dataDir, 0o755); err != nil {
+		return err
+	}
+
+	selection := backup.Selection{
+		Tables:  command.StringSlice("table"),
+		Uploads: command.StringSlice("upload"),
+	}
+
+	// the bases of an incremental backup are expected next to it
+	restored, err := backup.Restore(ctx, f, info.Size(), dataDir, filepath.Dir(f.Name()), key, selection)
+	if err != nil {
+		return fmt.Errorf("failed to restore backup: %w", err)
+	}
+
+	slog.InfoContext(ctx, "Backup restored", "version", restored.Version, "schema", restored.Schema, "migrated_to", migration.Latest(), "previous_data", restored.Previous,
+		"tables", selection.Tables, "uploads", selection.Uploads, "kept", restored.Kept)
+
+	return nil
+}
+
+func copyFile(from, to string) error {
+	src, err := os.Open(from)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	return writeFile(to, src)
+}
+
+// writeFile writes a backup to a file, which is removed if the backup could
+// not be written completely.
+func writeFile(filename string, r io.Reader) error {
+	dst, err := os.OpenFile(filename, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(dst, r); err != nil {
+		dst.Close()
+		os.Remove(filename)
+
+		return fmt.Errorf("failed to write backup: %w", err)
+	}
+
+	return dst.Close()
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/SecurityBrewery/catalyst/app/openapi"
+)
+
+// client calls the API of a running Catalyst server.
+type client struct {
+	server string
+	token  string
+	http   *http.Client
+	// poll is the interval in which the job of a backup is checked.
+	poll time.Duration
+}
+
+func newClient(command *cli.Command) *client {
+	return &client{
+		server: strings.TrimSuffix(command.String("server"), "/"),
+		token:  command.String("token"),
+		http:   http.DefaultClient,
+		poll:   time.Second,
+	}
+}
+
+// createBackup creates a backup in the background, so that big backups are
+// not cut off by the timeouts of reverse proxies, and waits for it. With a
+// target, the backup is streamed to it. With an output file, the backup is
+// downloaded to it.
+func (c *client) createBackup(ctx context.Context, base, target string, exclude []string, output string) (*openapi.BackupJob, error) {
+	query := url.Values{"async": {"true"}}
+	if base != "" {
+		query.Set("base", base)
+	}
+
+	if target != "" {
+		query.Set("target", target)
+	}
+
+	if len(exclude) > 0 {
+		query.Set("exclude", strings.Join(exclude, ","))
+	}
+
+	var job openapi.BackupJob
+	if err := c.do(ctx, http.MethodPost, "/api/backups?"+query.Encode(), "", nil, &job); err != nil {
+		return nil, fmt.Errorf("failed to create backup: %w", err)
+	}
+
+	for job.Status == openapi.BackupJobStatusRunning {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(c.poll):
+		}
+
+		if err := c.do(ctx, http.MethodGet, "/api/backup/jobs/"+url.PathEscape(job.Id), "", nil, &job); err != nil {
+			return nil, fmt.Errorf("failed to get backup job: %w", err)
+		}
+	}
+
+	if job.Status == openapi.BackupJobStatusFailed {
+		msg := "unknown error"
+		if job.Error != nil {
+			msg = *job.Error
+		}
+
+		return nil, fmt.Errorf("failed to create backup: %s", msg)
+	}
+
+	if output != "" {
+		if err := c.download(ctx, "/api/backup/download/"+url.PathEscape(job.Id), output); err != nil {
+			return nil, fmt.Errorf("failed to download backup: %w", err)
+		}
+	}
+
+	return &job, nil
+}
+
+// verifyBackup verifies a stored backup of the server by its name, or
+// uploads a backup file to verify it.
+func (c *client) verifyBackup(ctx context.Context, name, filename string) (*openapi.BackupVerification, error) {
+	var report openapi.BackupVerification
+
+	if name != "" {
+		if err := c.do(ctx, http.MethodPost, "/api/backup/verify?"+url.Values{"name": {name}}.Encode(), "", nil, &report); err != nil {
+			return nil, fmt.Errorf("failed to verify backup: %w", err)
+		}
+
+		return &report, nil
+	}
+
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open backup: %w", err)
+	}
+	defer f.Close()
+
+	if err := c.do(ctx, http.MethodPost, "/api/backup/verify", "application/zip", f, &report); err != nil {
+		return nil, fmt.Errorf("failed to verify backup: %w", err)
+	}
+
+	return &report, nil
+}
+
+func (c *client) do(ctx context.Context, method, path, contentType string, body io.Reader, response any) error {
+	resp, err := c.request(ctx, method, path, contentType, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	return json.NewDecoder(resp.Body).Decode(response)
+}
+
+func (c *client) download(ctx context.Context, path, filename string) error {
+	resp, err := c.request(ctx, http.MethodGet, path, "", nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	return writeFile(filename, resp.Body)
+}
+
+// request sends an authenticated request and turns error responses into
+// errors with the message of the server.
+func (c *client) request(ctx context.Context, method, path, contentType string, body io.Reader) (*http.Response, error) {
+	if c.token == "" {
+		return nil, errors.New("an API token is required with --server")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.server+path, body)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Authorization", "Bearer "+c.token)
+
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode < http.StatusBadRequest {
+		return resp, nil
+	}
+
+	defer resp.Body.Close()
+
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+
+	var apiErr openapi.Error
+	if err := json.Unmarshal(data, &apiErr); err == nil && apiErr.Message != "" {
+		return nil, fmt.Errorf("%s: %s", resp.Status, apiErr.Message)
+	}
+
+	return nil, fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(data)))
+}
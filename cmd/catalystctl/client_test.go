@@ -0,0 +1,98 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/SecurityBrewery/catalyst/app/openapi"
+	"github.com/SecurityBrewery/catalyst/app/pointer"
+)
+
+func newTestClient(t *testing.T, handler http.Handler) *client {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	return &client{server: server.URL, token: "secret", http: server.Client(), poll: time.Millisecond}
+}
+
+func TestClient_createBackup(t *testing.T) {
+	t.Parallel()
+
+	var polls atomic.Int32
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/backups", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+		assert.Equal(t, "true", r.URL.Query().Get("async"))
+		assert.Equal(t, "logs,jobs", r.URL.Query().Get("exclude"))
+
+		w.WriteHeader(http.StatusAccepted)
+		_ = json.NewEncoder(w).Encode(openapi.BackupJob{Id: "j1", Status: openapi.BackupJobStatusRunning})
+	})
+	mux.HandleFunc("GET /api/backup/jobs/j1", func(w http.ResponseWriter, _ *http.Request) {
+		job := openapi.BackupJob{Id: "j1", Name: "catalyst-20250601-120000.zip", Status: openapi.BackupJobStatusRunning}
+		if polls.Add(1) > 2 {
+			job.Status = openapi.BackupJobStatusSucceeded
+		}
+
+		_ = json.NewEncoder(w).Encode(job)
+	})
+	mux.HandleFunc("GET /api/backup/download/j1", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("backup"))
+	})
+
+	output := filepath.Join(t.TempDir(), "backup.zip")
+
+	job, err := newTestClient(t, mux).createBackup(t.Context(), "", "", []string{"logs", "jobs"}, output)
+	require.NoError(t, err)
+	assert.Equal(t, "catalyst-20250601-120000.zip", job.Name)
+	assert.Equal(t, int32(3), polls.Load())
+
+	data, err := os.ReadFile(output)
+	require.NoError(t, err)
+	assert.Equal(t, "backup", string(data))
+}
+
+func TestClient_createBackupFailed(t *testing.T) {
+	t.Parallel()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/backups", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+		_ = json.NewEncoder(w).Encode(openapi.BackupJob{Id: "j1", Status: openapi.BackupJobStatusFailed, Error: pointer.Pointer("disk full")})
+	})
+
+	_, err := newTestClient(t, mux).createBackup(t.Context(), "", "", nil, "")
+	require.ErrorContains(t, err, "disk full")
+}
+
+func TestClient_errors(t *testing.T) {
+	t.Parallel()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/backups", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_ = json.NewEncoder(w).Encode(openapi.Error{Status: http.StatusNotFound, Error: "Not Found", Message: "base backup not found"})
+	})
+
+	c := newTestClient(t, mux)
+
+	_, err := c.createBackup(t.Context(), "missing.zip", "", nil, "")
+	require.ErrorContains(t, err, "base backup not found")
+
+	c.token = ""
+
+	_, err = c.verifyBackup(t.Context(), "catalyst-20250601-120000.zip", "")
+	require.ErrorContains(t, err, "API token is required")
+}
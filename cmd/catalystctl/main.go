@@ -0,0 +1,84 @@
+// Command catalystctl creates, verifies and restores Catalyst backups, so
+// that disaster recovery can be automated. Backups are created and verified
+// through the API of a running server with --server and --token, or directly
+// on the data directory without them. A restore replaces the data directory
+// and always runs directly on it, while Catalyst is stopped.
+package main
+
+import (
+	"context"
+	"log/slog"
+	"os"
+
+	"github.com/urfave/cli/v3"
+)
+
+// version is set by the release build.
+var version = "dev"
+
+func main() {
+	cmd := &cli.Command{
+		Name:    "catalystctl",
+		Usage:   "Manage the backups of a Catalyst server",
+		Version: version,
+		Flags: []cli.Flag{
+			&cli.StringFlag{Name: "server", Usage: "URL of a running Catalyst server, e.g. https://catalyst.example.com, the data directory is used without it", Sources: cli.EnvVars("CATALYST_SERVER")},
+			&cli.StringFlag{Name: "token", Usage: "API token of a user with the settings:write and export:backup permissions", Sources: cli.EnvVars("CATALYST_TOKEN")},
+			&cli.StringFlag{Name: "data-dir", Usage: "Data directory of Catalyst", Value: "./catalyst_data", Sources: cli.EnvVars("CATALYST_DATA_DIR")},
+			&cli.StringFlag{Name: "backup-passphrase", Usage: "Passphrase of encrypted backups", Sources: cli.EnvVars("CATALYST_BACKUP_PASSPHRASE")},
+			&cli.StringFlag{Name: "backup-key-file", Usage: "File with the key of encrypted backups, 32 random bytes in base64", Sources: cli.EnvVars("CATALYST_BACKUP_KEY_FILE")},
+			&cli.StringFlag{Name: "storage", Usage: "Bucket of the uploaded files, e.g. s3://bucket/prefix, azure://container/prefix or gs://bucket/prefix, if the server does not keep them in the data directory", Sources: cli.EnvVars("CATALYST_STORAGE")},
+			&cli.StringFlag{Name: "storage-endpoint", Usage: "Endpoint of the storage", Sources: cli.EnvVars("CATALYST_STORAGE_ENDPOINT")},
+			&cli.StringFlag{Name: "storage-region", Usage: "Region of the storage bucket", Sources: cli.EnvVars("CATALYST_STORAGE_REGION")},
+			&cli.StringFlag{Name: "storage-access-key-id", Usage: "Access key id of the storage, or the access id of a GCS HMAC key", Sources: cli.EnvVars("CATALYST_STORAGE_ACCESS_KEY_ID")},
+			&cli.StringFlag{Name: "storage-secret-access-key", Usage: "Secret access key of the storage, or the secret of a GCS HMAC key", Sources: cli.EnvVars("CATALYST_STORAGE_SECRET_ACCESS_KEY")},
+			&cli.StringFlag{Name: "storage-azure-account", Usage: "Azure storage account of the storage", Sources: cli.EnvVars("CATALYST_STORAGE_AZURE_ACCOUNT")},
+			&cli.StringFlag{Name: "storage-azure-account-key", Usage: "Base64 encoded key of the Azure storage account", Sources: cli.EnvVars("CATALYST_STORAGE_AZURE_ACCOUNT_KEY")},
+		},
+		Commands: []*cli.Command{
+			{
+				Name:  "backup",
+				Usage: "Create, verify and restore backups",
+				Commands: []*cli.Command{
+					{
+						Name:  "create",
+						Usage: "Create a backup, stored in the backups folder of the data directory",
+						Flags: []cli.Flag{
+							&cli.StringFlag{Name: "base", Usage: "A stored backup to create an incremental backup of"},
+							&cli.StringSliceFlag{Name: "exclude", Usage: "Leave out the rows of logs or jobs, repeat to leave out both"},
+							&cli.StringFlag{Name: "target", Usage: "Stream a full backup to a bucket, the container or the directory of the backup storage settings instead, like s3://bucket/prefix, azure://container/prefix, gs://bucket/prefix or file:///mnt/backups"},
+							&cli.StringFlag{Name: "output", Aliases: []string{"o"}, Usage: "Also write the backup to a file"},
+						},
+						Action: backupCreate,
+					},
+					{
+						Name:      "verify",
+						Usage:     "Verify the integrity and compatibility of a backup, fails if the backup is invalid",
+						ArgsUsage: "<backup.zip|backup.zip.enc>",
+						Flags: []cli.Flag{
+							&cli.StringFlag{Name: "name", Usage: "Verify a stored backup of the server instead of a file"},
+						},
+						Action: backupVerify,
+					},
+					{
+						Name:      "restore",
+						Usage:     "Restore a backup into the data directory, Catalyst must not run during the restore",
+						ArgsUsage: "<backup.zip|backup.zip.enc>",
+						Flags: []cli.Flag{
+							&cli.StringSliceFlag{Name: "table", Usage: "Only restore the rows of a table, repeat to restore several, e.g. playbooks"},
+							&cli.StringSliceFlag{Name: "upload", Usage: "Only restore the upload of a file by its id, repeat to restore several"},
+						},
+						Action: backupRestore,
+					},
+				},
+			},
+		},
+	}
+
+	ctx := context.Background()
+	if err := cmd.Run(ctx, os.Args); err != nil {
+		slog.ErrorContext(ctx, "Error running catalystctl", "error", err)
+
+		os.Exit(1)
+	}
+}
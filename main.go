@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"net"
 	"net/http"
 	"os"
 	"slices"
@@ -12,18 +13,39 @@ import (
 	"github.com/urfave/cli/v3"
 
 	"github.com/SecurityBrewery/catalyst/app"
+	"github.com/SecurityBrewery/catalyst/app/backup"
 	"github.com/SecurityBrewery/catalyst/app/data"
 	"github.com/SecurityBrewery/catalyst/app/database/sqlc"
+	"github.com/SecurityBrewery/catalyst/app/fips"
+	"github.com/SecurityBrewery/catalyst/app/listener"
+	"github.com/SecurityBrewery/catalyst/app/router"
 	"github.com/SecurityBrewery/catalyst/app/settings"
+	"github.com/SecurityBrewery/catalyst/app/storage"
 )
 
+// version is set by the release build.
+var version = "dev"
+
 func main() {
 	cmd := &cli.Command{
-		Name:  "catalyst",
-		Usage: "Catalyst CLI",
+		Name:    "catalyst",
+		Usage:   "Catalyst CLI",
+		Version: version,
 		Flags: []cli.Flag{
-			&cli.StringFlag{Name: "app-url"},
-			&cli.StringSliceFlag{Name: "flags"},
+			&cli.StringFlag{Name: "app-url", Sources: cli.EnvVars("CATALYST_APP_URL")},
+			&cli.StringSliceFlag{Name: "flags", Sources: cli.EnvVars("CATALYST_FLAGS")},
+			&cli.BoolFlag{Name: "fips", Usage: "Restrict cryptography to FIPS 140-3 approved algorithms", Sources: cli.EnvVars("CATALYST_FIPS")},
+			&cli.BoolFlag{Name: "read-only", Usage: "Open the database read-only, reject all requests that modify data and run no background jobs, e.g. when serving a replicated database", Sources: cli.EnvVars("CATALYST_READ_ONLY")},
+			&cli.StringSliceFlag{Name: "trusted-proxy", Usage: "Identify clients by X-Forwarded-For behind this proxy address or CIDR range, repeat to trust several", Sources: cli.EnvVars("CATALYST_TRUSTED_PROXIES")},
+			&cli.StringFlag{Name: "backup-passphrase", Usage: "Encrypt backups with a passphrase", Sources: cli.EnvVars("CATALYST_BACKUP_PASSPHRASE")},
+			&cli.StringFlag{Name: "backup-key-file", Usage: "Encrypt backups with the key in the file, 32 random bytes in base64", Sources: cli.EnvVars("CATALYST_BACKUP_KEY_FILE")},
+			&cli.StringFlag{Name: "storage", Usage: "Store the uploaded files in a bucket, e.g. s3://bucket/prefix, azure://container/prefix or gs://bucket/prefix, instead of the data directory", Sources: cli.EnvVars("CATALYST_STORAGE")},
+			&cli.StringFlag{Name: "storage-endpoint", Usage: "Endpoint of the storage, e.g. https://s3.eu-central-1.amazonaws.com", Sources: cli.EnvVars("CATALYST_STORAGE_ENDPOINT")},
+			&cli.StringFlag{Name: "storage-region", Usage: "Region of the storage bucket", Sources: cli.EnvVars("CATALYST_STORAGE_REGION")},
+			&cli.StringFlag{Name: "storage-access-key-id", Usage: "Access key id of the storage, or the access id of a GCS HMAC key", Sources: cli.EnvVars("CATALYST_STORAGE_ACCESS_KEY_ID")},
+			&cli.StringFlag{Name: "storage-secret-access-key", Usage: "Secret access key of the storage, or the secret of a GCS HMAC key", Sources: cli.EnvVars("CATALYST_STORAGE_SECRET_ACCESS_KEY")},
+			&cli.StringFlag{Name: "storage-azure-account", Usage: "Azure storage account of the storage", Sources: cli.EnvVars("CATALYST_STORAGE_AZURE_ACCOUNT")},
+			&cli.StringFlag{Name: "storage-azure-account-key", Usage: "Base64 encoded key of the Azure storage account", Sources: cli.EnvVars("CATALYST_STORAGE_AZURE_ACCOUNT_KEY")},
 		},
 		Commands: []*cli.Command{
 			{
@@ -39,11 +61,21 @@ func main() {
 					return nil
 				},
 			},
+			{
+				Name:   "check-config",
+				Usage:  "Validate the configuration without starting the server",
+				Action: checkConfig,
+			},
 			{
 				Name:  "serve",
 				Usage: "Start the Catalyst server",
 				Flags: []cli.Flag{
-					&cli.StringFlag{Name: "http", Usage: "HTTP listen address", Value: ":8090"},
+					&cli.StringSliceFlag{
+						Name:    "http",
+						Usage:   "HTTP listen address, repeat to listen on several, e.g. tcp6://[::]:8090, unix:///run/catalyst.sock or :8443?tls_cert=cert.pem&tls_key=key.pem&proxy_protocol=true",
+						Value:   []string{":8090"},
+						Sources: cli.EnvVars("CATALYST_HTTP"),
+					},
 				},
 				Action: serve,
 			},
@@ -62,6 +94,24 @@ func main() {
 				Usage:   "Generate default data for Catalyst",
 				Action:  defaultData,
 			},
+			{
+				Name:      "restore",
+				Usage:     "Restore a backup, Catalyst must not run during the restore",
+				ArgsUsage: "<backup.zip|backup.zip.enc>",
+				Flags: []cli.Flag{
+					&cli.StringSliceFlag{Name: "table", Usage: "Only restore the rows of a table, repeat to restore several, e.g. playbooks"},
+					&cli.StringSliceFlag{Name: "upload", Usage: "Only restore the upload of a file by its id, repeat to restore several"},
+				},
+				Action: restore,
+			},
+			{
+				Name:  "case-key",
+				Usage: "Export or import the master key of the encrypted tickets, which is not in backups",
+				Commands: []*cli.Command{
+					{Name: "export", Usage: "Print the master key, store it apart from the backups", Action: caseKeyExport},
+					{Name: "import", Usage: "Store an exported master key, e.g. before restoring a backup on another instance", ArgsUsage: "<file>", Action: caseKeyImport},
+				},
+			},
 			{
 				Name: "admin",
 				Commands: []*cli.Command{
@@ -81,12 +131,50 @@ func main() {
 	}
 }
 
+const dataDir = "./catalyst_data"
+
 func setup(ctx context.Context, command *cli.Command) (*app.App, func(), error) {
-	catalyst, cleanup, err := app.New(ctx, "./catalyst_data")
+	// FIPS mode is enabled before anything hashes passwords
+	if fips.Required(command.Bool("fips")) {
+		if err := fips.Enable(); err != nil {
+			return nil, nil, fmt.Errorf("failed to enable FIPS mode: %w", err)
+		}
+
+		slog.InfoContext(ctx, "FIPS mode enabled")
+	}
+
+	trustedProxies, err := router.ParseTrustedProxies(command.StringSlice("trusted-proxy"))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	catalyst, cleanup, err := app.New(ctx, dataDir, app.Config{
+		Backup:         backupConfig(command),
+		Storage:        storageConfig(command),
+		ReadOnly:       command.Bool("read-only"),
+		TrustedProxies: trustedProxies,
+	})
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to initialize catalyst: %w", err)
 	}
 
+	// from here on the records are kept in the application log as well
+	slog.SetDefault(slog.New(catalyst.Logs.Handler(slog.NewTextHandler(os.Stderr, nil))))
+
+	if fips.Enabled() {
+		if err := fips.Check(ctx, catalyst.Queries); err != nil {
+			cleanup()
+
+			return nil, nil, err
+		}
+	}
+
+	// Environment variables override the stored settings whenever they are
+	// loaded, they are checked once before serving any request.
+	if err := settings.ApplyEnv(&settings.Settings{}); err != nil {
+		return nil, nil, fmt.Errorf("failed to apply environment settings: %w", err)
+	}
+
 	if appURL := command.String("app-url"); appURL != "" {
 		_, err := settings.Update(ctx, catalyst.Queries, func(settings *settings.Settings) {
 			settings.Meta.AppURL = appURL
@@ -103,6 +191,26 @@ func setup(ctx context.Context, command *cli.Command) (*app.App, func(), error)
 	return catalyst, cleanup, nil
 }
 
+func backupConfig(command *cli.Command) backup.Config {
+	return backup.Config{
+		Passphrase: command.String("backup-passphrase"),
+		KeyFile:    command.String("backup-key-file"),
+		Version:    version,
+	}
+}
+
+func storageConfig(command *cli.Command) storage.Config {
+	return storage.Config{
+		URL:             command.String("storage"),
+		Endpoint:        command.String("storage-endpoint"),
+		Region:          command.String("storage-region"),
+		AccessKeyID:     command.String("storage-access-key-id"),
+		SecretAccessKey: command.String("storage-secret-access-key"),
+		AzureAccount:    command.String("storage-azure-account"),
+		AzureAccountKey: command.String("storage-azure-account-key"),
+	}
+}
+
 func serve(ctx context.Context, command *cli.Command) error {
 	catalyst, cleanup, err := setup(ctx, command)
 	if err != nil {
@@ -111,17 +219,53 @@ func serve(ctx context.Context, command *cli.Command) error {
 
 	defer cleanup()
 
-	addr := command.String("http")
+	configs := make([]listener.Config, 0, len(command.StringSlice("http")))
+
+	for _, spec := range command.StringSlice("http") {
+		config, err := listener.Parse(spec)
+		if err != nil {
+			return err
+		}
+
+		configs = append(configs, config)
+	}
 
 	server := &http.Server{
-		Addr:        addr,
 		Handler:     catalyst,
 		ReadTimeout: 10 * time.Minute,
 	}
 
-	slog.InfoContext(ctx, "Starting Catalyst server", "address", server.Addr)
+	listeners := make([]net.Listener, 0, len(configs))
+
+	for _, config := range configs {
+		l, err := config.Listen(ctx)
+		if err != nil {
+			for _, l := range listeners {
+				_ = l.Close()
+			}
+
+			return fmt.Errorf("failed to listen on %s: %w", config.String(), err)
+		}
+
+		listeners = append(listeners, l)
+	}
+
+	// the first listener that fails stops the server
+	errs := make(chan error, len(listeners))
+
+	for i, l := range listeners {
+		slog.InfoContext(ctx, "Starting Catalyst server", "address", configs[i].String(), "tls", configs[i].TLS(), "proxy_protocol", configs[i].ProxyProtocol)
+
+		go func() {
+			errs <- server.Serve(l)
+		}()
+	}
+
+	err = <-errs
+
+	_ = server.Close()
 
-	return server.ListenAndServe()
+	return err
 }
 
 func fakeData(ctx context.Context, command *cli.Command) error {
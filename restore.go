@@ -0,0 +1,57 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/SecurityBrewery/catalyst/app/backup"
+	"github.com/SecurityBrewery/catalyst/app/migration"
+)
+
+func restore(ctx context.Context, command *cli.Command) error {
+	if command.Args().Len() != 1 {
+		return errors.New("usage: catalyst restore <backup.zip|backup.zip.enc>")
+	}
+
+	key, err := backup.NewKey(backupConfig(command))
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Open(command.Args().Get(0))
+	if err != nil {
+		return fmt.Errorf("failed to open backup: %w", err)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(dataDir, 0o755); err != nil {
+		return err
+	}
+
+	selection := backup.Selection{
+		Tables:  command.StringSlice("table"),
+		Uploads: command.StringSlice("upload"),
+	}
+
+	// the bases of an incremental backup are expected next to it
+	restored, err := backup.Restore(ctx, f, info.Size(), dataDir, filepath.Dir(f.Name()), key, selection)
+	if err != nil {
+		return fmt.Errorf("failed to restore backup: %w", err)
+	}
+
+	slog.InfoContext(ctx, "Backup restored", "version", restored.Version, "schema", restored.Schema, "migrated_to", migration.Latest(), "previous_data", restored.Previous,
+		"tables", selection.Tables, "uploads", selection.Uploads, "kept", restored.Kept)
+
+	return nil
+}
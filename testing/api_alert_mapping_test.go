@@ -0,0 +1,253 @@
+package testing
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/SecurityBrewery/catalyst/app/data"
+)
+
+func TestAlertMappingsCollection(t *testing.T) {
+	t.Parallel()
+
+	testSets := []catalystTest{
+		{
+			baseTest: baseTest{
+				Name:   "ListAlertMappingTemplates",
+				Method: http.MethodGet,
+				URL:    "/api/alerts/mapping/templates",
+			},
+			userTests: []userTest{
+				{
+					Name:            "Unauthorized",
+					ExpectedStatus:  http.StatusUnauthorized,
+					ExpectedContent: []string{`"invalid bearer token"`},
+				},
+				{
+					Name:            "Analyst",
+					AuthRecord:      data.AnalystEmail,
+					ExpectedStatus:  http.StatusUnauthorized,
+					ExpectedContent: []string{`"missing required scopes"`},
+				},
+				{
+					Name:            "Admin",
+					Admin:           data.AdminEmail,
+					ExpectedStatus:  http.StatusOK,
+					ExpectedContent: []string{`"id":"sentinel"`, `"id":"wazuh"`, `"name":"rule.description"`},
+					ExpectedEvents:  map[string]int{},
+				},
+			},
+		},
+		{
+			baseTest: baseTest{
+				Name:   "ListAlertMappings",
+				Method: http.MethodGet,
+				URL:    "/api/alerts/mappings",
+			},
+			userTests: []userTest{
+				{
+					Name:            "Admin",
+					Admin:           data.AdminEmail,
+					ExpectedStatus:  http.StatusOK,
+					ExpectedContent: []string{`[]`},
+					ExpectedHeaders: map[string]string{"X-Total-Count": "0"},
+					ExpectedEvents:  map[string]int{},
+				},
+			},
+		},
+		{
+			baseTest: baseTest{
+				Name:           "CloneAlertMappingTemplate",
+				Method:         http.MethodPost,
+				RequestHeaders: map[string]string{"Content-Type": "application/json"},
+				URL:            "/api/alerts/mappings",
+				Body: s(map[string]any{
+					"name":     "Wazuh production",
+					"template": "wazuh",
+				}),
+			},
+			userTests: []userTest{
+				{
+					Name:            "Unauthorized",
+					ExpectedStatus:  http.StatusUnauthorized,
+					ExpectedContent: []string{`"invalid bearer token"`},
+				},
+				{
+					Name:            "Analyst",
+					AuthRecord:      data.AnalystEmail,
+					ExpectedStatus:  http.StatusUnauthorized,
+					ExpectedContent: []string{`"missing required scopes"`},
+				},
+				{
+					Name:            "Admin",
+					Admin:           data.AdminEmail,
+					ExpectedStatus:  http.StatusOK,
+					ExpectedContent: []string{`"name":"Wazuh production"`, `"source":"wazuh"`, `"template":"wazuh"`, `"template_version":1`, `"upgrade_available":false`},
+					ExpectedEvents:  map[string]int{},
+				},
+			},
+		},
+		{
+			baseTest: baseTest{
+				Name:           "CreateAlertMappingUnknownTemplate",
+				Method:         http.MethodPost,
+				RequestHeaders: map[string]string{"Content-Type": "application/json"},
+				URL:            "/api/alerts/mappings",
+				Body: s(map[string]any{
+					"name":     "Splunk",
+					"template": "splunk",
+				}),
+			},
+			userTests: []userTest{
+				{
+					Name:            "Admin",
+					Admin:           data.AdminEmail,
+					ExpectedStatus:  http.StatusBadRequest,
+					ExpectedContent: []string{`unknown mapping template \"splunk\"`},
+					ExpectedEvents:  map[string]int{},
+				},
+			},
+		},
+		{
+			baseTest: baseTest{
+				Name:           "IngestAlertEventWithTemplate",
+				Method:         http.MethodPost,
+				RequestHeaders: map[string]string{"Content-Type": "application/json"},
+				URL:            "/api/alerts/mappings/wazuh/ingest",
+				Body: s(map[string]any{
+					"rule":     map[string]any{"description": "sshd: brute force trying to get access", "level": 10},
+					"full_log": "Failed password for root from 203.0.113.7",
+				}),
+			},
+			userTests: []userTest{
+				{
+					Name:            "Unauthorized",
+					ExpectedStatus:  http.StatusUnauthorized,
+					ExpectedContent: []string{`"invalid bearer token"`},
+				},
+				{
+					Name:            "Analyst",
+					AuthRecord:      data.AnalystEmail,
+					ExpectedStatus:  http.StatusOK,
+					ExpectedContent: []string{`"source":"wazuh"`, `"name":"sshd: brute force trying to get access"`, `"severity":"High"`},
+					ExpectedEvents: map[string]int{
+						"OnRecordAfterCreateRequest":  1,
+						"OnRecordBeforeCreateRequest": 1,
+					},
+				},
+			},
+		},
+		{
+			baseTest: baseTest{
+				Name:           "IngestAlertEventWithoutName",
+				Method:         http.MethodPost,
+				RequestHeaders: map[string]string{"Content-Type": "application/json"},
+				URL:            "/api/alerts/mappings/wazuh/ingest",
+				Body:           s(map[string]any{"full_log": "Failed password for root"}),
+			},
+			userTests: []userTest{
+				{
+					Name:            "Analyst",
+					AuthRecord:      data.AnalystEmail,
+					ExpectedStatus:  http.StatusBadRequest,
+					ExpectedContent: []string{`no value at the name path`},
+					ExpectedEvents:  map[string]int{},
+				},
+			},
+		},
+		{
+			baseTest: baseTest{
+				Name:           "IngestAlertEventUnknownMapping",
+				Method:         http.MethodPost,
+				RequestHeaders: map[string]string{"Content-Type": "application/json"},
+				URL:            "/api/alerts/mappings/splunk/ingest",
+				Body:           s(map[string]any{"name": "Test"}),
+			},
+			userTests: []userTest{
+				{
+					Name:            "Analyst",
+					AuthRecord:      data.AnalystEmail,
+					ExpectedStatus:  http.StatusNotFound,
+					ExpectedContent: []string{`no alert mapping or template with the ID \"splunk\"`},
+					ExpectedEvents:  map[string]int{},
+				},
+			},
+		},
+		{
+			baseTest: baseTest{
+				Name:           "TestAlertMapping",
+				Method:         http.MethodPost,
+				RequestHeaders: map[string]string{"Content-Type": "application/json"},
+				URL:            "/api/alerts/mappings/wazuh/test",
+				Body: s(map[string]any{
+					"rule":     map[string]any{"description": "sshd: brute force trying to get access", "level": 10},
+					"full_log": "Failed password for root from 203.0.113.7",
+				}),
+			},
+			userTests: []userTest{
+				{
+					Name:            "Unauthorized",
+					ExpectedStatus:  http.StatusUnauthorized,
+					ExpectedContent: []string{`"invalid bearer token"`},
+				},
+				{
+					Name:            "Admin",
+					Admin:           data.AdminEmail,
+					ExpectedStatus:  http.StatusOK,
+					ExpectedContent: []string{`"valid":true`, `"source":"wazuh"`, `"name":"sshd: brute force trying to get access"`, `"severity":"High"`},
+					ExpectedEvents:  map[string]int{},
+				},
+			},
+		},
+		{
+			baseTest: baseTest{
+				Name:           "TestAlertMappingWithoutName",
+				Method:         http.MethodPost,
+				RequestHeaders: map[string]string{"Content-Type": "application/json"},
+				URL:            "/api/alerts/mappings/wazuh/test",
+				Body:           s(map[string]any{"full_log": "Failed password for root"}),
+			},
+			userTests: []userTest{
+				{
+					Name:            "Admin",
+					Admin:           data.AdminEmail,
+					ExpectedStatus:  http.StatusOK,
+					ExpectedContent: []string{`"valid":false`, `no value at the name path`},
+					ExpectedEvents:  map[string]int{},
+				},
+			},
+		},
+		{
+			baseTest: baseTest{
+				Name:           "TestAlertMappingUnknownMapping",
+				Method:         http.MethodPost,
+				RequestHeaders: map[string]string{"Content-Type": "application/json"},
+				URL:            "/api/alerts/mappings/splunk/test",
+				Body:           s(map[string]any{"name": "Test"}),
+			},
+			userTests: []userTest{
+				{
+					Name:            "Admin",
+					Admin:           data.AdminEmail,
+					ExpectedStatus:  http.StatusNotFound,
+					ExpectedContent: []string{`no alert mapping or template with the ID \"splunk\"`},
+					ExpectedEvents:  map[string]int{},
+				},
+			},
+		},
+	}
+
+	for _, testSet := range testSets {
+		t.Run(testSet.baseTest.Name, func(t *testing.T) {
+			t.Parallel()
+
+			for _, userTest := range testSet.userTests {
+				t.Run(userTest.Name, func(t *testing.T) {
+					t.Parallel()
+
+					runMatrixTest(t, testSet.baseTest, userTest)
+				})
+			}
+		})
+	}
+}
@@ -0,0 +1,165 @@
+package testing
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/SecurityBrewery/catalyst/app/data"
+)
+
+func TestAlertsCollection(t *testing.T) {
+	t.Parallel()
+
+	testSets := []catalystTest{
+		{
+			baseTest: baseTest{
+				Name:   "ListAlerts",
+				Method: http.MethodGet,
+				URL:    "/api/alerts",
+			},
+			userTests: []userTest{
+				{
+					Name:            "Unauthorized",
+					ExpectedStatus:  http.StatusUnauthorized,
+					ExpectedContent: []string{`"invalid bearer token"`},
+				},
+				{
+					Name:            "Analyst",
+					AuthRecord:      data.AnalystEmail,
+					ExpectedStatus:  http.StatusOK,
+					ExpectedHeaders: map[string]string{"X-Total-Count": "0"},
+					ExpectedEvents:  map[string]int{"OnRecordsListRequest": 1},
+				},
+			},
+		},
+		{
+			baseTest: baseTest{
+				Name:           "CreateAlert",
+				Method:         http.MethodPost,
+				RequestHeaders: map[string]string{"Content-Type": "application/json"},
+				URL:            "/api/alerts",
+				Body: s(map[string]any{
+					"source":   "edr",
+					"name":     "Malware detected",
+					"severity": "High",
+					"data":     map[string]any{"host": "ws-042"},
+				}),
+			},
+			userTests: []userTest{
+				{
+					Name:            "Unauthorized",
+					ExpectedStatus:  http.StatusUnauthorized,
+					ExpectedContent: []string{`"invalid bearer token"`},
+				},
+				{
+					Name:           "Analyst",
+					AuthRecord:     data.AnalystEmail,
+					ExpectedStatus: http.StatusOK,
+					ExpectedContent: []string{
+						`"name":"Malware detected"`,
+						`"status":"new"`,
+						`"host":"ws-042"`,
+					},
+					ExpectedEvents: map[string]int{
+						"OnRecordAfterCreateRequest":  1,
+						"OnRecordBeforeCreateRequest": 1,
+					},
+				},
+			},
+		},
+		{
+			baseTest: baseTest{
+				Name:           "TestAlert",
+				Method:         http.MethodPost,
+				RequestHeaders: map[string]string{"Content-Type": "application/json"},
+				URL:            "/api/alerts/test?type=incident",
+				Body: s(map[string]any{
+					"source":   "edr",
+					"name":     "Malware detected",
+					"severity": "High",
+					"data":     map[string]any{"host": "ws-042", "ip": "203.0.113.7"},
+				}),
+			},
+			userTests: []userTest{
+				{
+					Name:            "Unauthorized",
+					ExpectedStatus:  http.StatusUnauthorized,
+					ExpectedContent: []string{`"invalid bearer token"`},
+				},
+				{
+					Name:           "Analyst",
+					AuthRecord:     data.AnalystEmail,
+					ExpectedStatus: http.StatusOK,
+					ExpectedContent: []string{
+						`"valid":true`,
+						`"errors":[]`,
+						`"name":"Malware detected"`,
+						`"state":{"severity":"High","source":"edr"}`,
+						`{"kind":"ip","value":"203.0.113.7"}`,
+					},
+					ExpectedEvents: map[string]int{},
+				},
+			},
+		},
+		{
+			baseTest: baseTest{
+				Name:           "TestInvalidAlert",
+				Method:         http.MethodPost,
+				RequestHeaders: map[string]string{"Content-Type": "application/json"},
+				URL:            "/api/alerts/test?type=unknown",
+				Body:           s(map[string]any{"source": "edr", "name": " "}),
+			},
+			userTests: []userTest{
+				{
+					Name:           "Analyst",
+					AuthRecord:     data.AnalystEmail,
+					ExpectedStatus: http.StatusOK,
+					ExpectedContent: []string{
+						`"valid":false`,
+						`"The name of the alert is required"`,
+						`"The ticket type unknown does not exist"`,
+						`"indicators":[]`,
+					},
+					ExpectedEvents: map[string]int{},
+				},
+			},
+		},
+		{
+			baseTest: baseTest{
+				Name:           "PromoteUnknownAlert",
+				Method:         http.MethodPost,
+				RequestHeaders: map[string]string{"Content-Type": "application/json"},
+				URL:            "/api/alerts/promote",
+				Body:           s(map[string]any{"alerts": []string{"r_unknown"}, "type": "incident"}),
+			},
+			userTests: []userTest{
+				{
+					Name:            "Unauthorized",
+					ExpectedStatus:  http.StatusUnauthorized,
+					ExpectedContent: []string{`"invalid bearer token"`},
+				},
+				{
+					Name:            "Analyst",
+					AuthRecord:      data.AnalystEmail,
+					ExpectedStatus:  http.StatusBadRequest,
+					ExpectedContent: []string{`"The alert r_unknown does not exist"`},
+					ExpectedEvents:  map[string]int{},
+				},
+			},
+		},
+	}
+
+	for _, testSet := range testSets {
+		t.Run(testSet.baseTest.Name, func(t *testing.T) {
+			t.Parallel()
+
+			for _, userTest := range testSet.userTests {
+				t.Run(userTest.Name, func(t *testing.T) {
+					t.Parallel()
+
+					runMatrixTest(t, testSet.baseTest, userTest)
+				})
+			}
+		})
+	}
+}
@@ -0,0 +1,205 @@
+package testing
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/SecurityBrewery/catalyst/app/data"
+)
+
+func TestArtifactsCollection(t *testing.T) {
+	t.Parallel()
+
+	testSets := []catalystTest{
+		{
+			baseTest: baseTest{
+				Name:   "ListTicketArtifacts",
+				Method: http.MethodGet,
+				URL:    "/api/tickets/test-ticket/artifacts",
+			},
+			userTests: []userTest{
+				{
+					Name:            "Unauthorized",
+					ExpectedStatus:  http.StatusUnauthorized,
+					ExpectedContent: []string{`"invalid bearer token"`},
+				},
+				{
+					Name:            "Analyst",
+					AuthRecord:      data.AnalystEmail,
+					ExpectedStatus:  http.StatusOK,
+					ExpectedContent: []string{`[]`},
+					ExpectedEvents:  map[string]int{},
+				},
+			},
+		},
+		{
+			baseTest: baseTest{
+				Name:           "AddTicketArtifactsCSV",
+				Method:         http.MethodPost,
+				RequestHeaders: map[string]string{"Content-Type": "application/json"},
+				URL:            "/api/tickets/test-ticket/artifacts/bulk",
+				Body: s(map[string]any{
+					"csv": "kind,value\nip,10.0.0.1\nexample[.]com\nEXAMPLE.com\nhash,xyz\n",
+				}),
+			},
+			userTests: []userTest{
+				{
+					Name:            "Unauthorized",
+					ExpectedStatus:  http.StatusUnauthorized,
+					ExpectedContent: []string{`"invalid bearer token"`},
+				},
+				{
+					Name:            "Analyst",
+					AuthRecord:      data.AnalystEmail,
+					ExpectedStatus:  http.StatusOK,
+					ExpectedContent: []string{`"added":2`, `"duplicates":1`, `"invalid":1`, `"value":"example.com"`, `"line":5`},
+					ExpectedEvents:  map[string]int{},
+				},
+			},
+		},
+		{
+			baseTest: baseTest{
+				Name:           "AddTicketArtifactsList",
+				Method:         http.MethodPost,
+				RequestHeaders: map[string]string{"Content-Type": "application/json"},
+				URL:            "/api/tickets/test-ticket/artifacts/bulk",
+				Body: s(map[string]any{
+					"artifacts": []map[string]any{{"value": "CVE-2021-44228"}, {"kind": "email", "value": "alice@Example.com"}},
+				}),
+			},
+			userTests: []userTest{
+				{
+					Name:            "Analyst",
+					AuthRecord:      data.AnalystEmail,
+					ExpectedStatus:  http.StatusOK,
+					ExpectedContent: []string{`"added":2`, `"kind":"cve"`, `"value":"alice@example.com"`},
+					ExpectedEvents:  map[string]int{},
+				},
+			},
+		},
+		{
+			baseTest: baseTest{
+				Name:           "AddTicketArtifactsInvalidCSV",
+				Method:         http.MethodPost,
+				RequestHeaders: map[string]string{"Content-Type": "application/json"},
+				URL:            "/api/tickets/test-ticket/artifacts/bulk",
+				Body:           s(map[string]any{"csv": "ip,10.0.0.1,extra\n"}),
+			},
+			userTests: []userTest{
+				{
+					Name:            "Analyst",
+					AuthRecord:      data.AnalystEmail,
+					ExpectedStatus:  http.StatusBadRequest,
+					ExpectedContent: []string{`line 1 has 3 columns`},
+					ExpectedEvents:  map[string]int{},
+				},
+			},
+		},
+		{
+			baseTest: baseTest{
+				Name:           "AddTicketArtifactsTicketNotFound",
+				Method:         http.MethodPost,
+				RequestHeaders: map[string]string{"Content-Type": "application/json"},
+				URL:            "/api/tickets/unknown/artifacts/bulk",
+				Body:           s(map[string]any{"artifacts": []map[string]any{{"value": "10.0.0.1"}}}),
+			},
+			userTests: []userTest{
+				{
+					Name:            "Analyst",
+					AuthRecord:      data.AnalystEmail,
+					ExpectedStatus:  http.StatusNotFound,
+					ExpectedContent: []string{`"The ticket does not exist"`},
+					ExpectedEvents:  map[string]int{},
+				},
+			},
+		},
+		{
+			baseTest: baseTest{
+				Name:   "SearchArtifacts",
+				Method: http.MethodGet,
+				URL:    "/api/artifacts/search?value=10.0.0.1",
+			},
+			userTests: []userTest{
+				{
+					Name:            "Unauthorized",
+					ExpectedStatus:  http.StatusUnauthorized,
+					ExpectedContent: []string{`"invalid bearer token"`},
+				},
+				{
+					Name:            "Analyst",
+					AuthRecord:      data.AnalystEmail,
+					ExpectedStatus:  http.StatusOK,
+					ExpectedContent: []string{`"kind":"ip"`, `"ticket_count":0`, `"tickets":[]`, `"feeds":[]`, `"enrichments":[]`},
+					ExpectedEvents:  map[string]int{},
+				},
+			},
+		},
+		{
+			baseTest: baseTest{
+				Name:   "SearchArtifactsInvalid",
+				Method: http.MethodGet,
+				URL:    "/api/artifacts/search?value=not+an+indicator",
+			},
+			userTests: []userTest{
+				{
+					Name:            "Analyst",
+					AuthRecord:      data.AnalystEmail,
+					ExpectedStatus:  http.StatusBadRequest,
+					ExpectedContent: []string{`the kind could not be detected`},
+					ExpectedEvents:  map[string]int{},
+				},
+			},
+		},
+		{
+			baseTest: baseTest{
+				Name:   "ListArtifactSightings",
+				Method: http.MethodGet,
+				URL:    "/api/artifacts/hxxps:%2F%2FExample.com%2Fa/sightings",
+			},
+			userTests: []userTest{
+				{
+					Name:            "Unauthorized",
+					ExpectedStatus:  http.StatusUnauthorized,
+					ExpectedContent: []string{`"invalid bearer token"`},
+				},
+				{
+					Name:            "Analyst",
+					AuthRecord:      data.AnalystEmail,
+					ExpectedStatus:  http.StatusOK,
+					ExpectedContent: []string{`"kind":"url"`, `"value":"https://example.com/a"`, `"count":0`, `"sightings":[]`},
+					ExpectedEvents:  map[string]int{},
+				},
+			},
+		},
+		{
+			baseTest: baseTest{
+				Name:   "ListArtifactSightingsInvalid",
+				Method: http.MethodGet,
+				URL:    "/api/artifacts/example.com/sightings?kind=ip",
+			},
+			userTests: []userTest{
+				{
+					Name:            "Analyst",
+					AuthRecord:      data.AnalystEmail,
+					ExpectedStatus:  http.StatusBadRequest,
+					ExpectedContent: []string{`invalid IP address`},
+					ExpectedEvents:  map[string]int{},
+				},
+			},
+		},
+	}
+
+	for _, testSet := range testSets {
+		t.Run(testSet.baseTest.Name, func(t *testing.T) {
+			t.Parallel()
+
+			for _, userTest := range testSet.userTests {
+				t.Run(userTest.Name, func(t *testing.T) {
+					t.Parallel()
+
+					runMatrixTest(t, testSet.baseTest, userTest)
+				})
+			}
+		})
+	}
+}
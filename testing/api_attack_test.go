@@ -0,0 +1,203 @@
+package testing
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/SecurityBrewery/catalyst/app/data"
+)
+
+func TestAttackCollection(t *testing.T) {
+	t.Parallel()
+
+	testSets := []catalystTest{
+		{
+			baseTest: baseTest{
+				Name:   "GetAttackCatalog",
+				Method: http.MethodGet,
+				URL:    "/api/attack/catalog",
+			},
+			userTests: []userTest{
+				{
+					Name:            "Unauthorized",
+					ExpectedStatus:  http.StatusUnauthorized,
+					ExpectedContent: []string{`"invalid bearer token"`},
+				},
+				{
+					Name:            "Analyst",
+					AuthRecord:      data.AnalystEmail,
+					ExpectedStatus:  http.StatusOK,
+					ExpectedContent: []string{`"version":"bundled"`, `{"id":"TA0001","name":"Initial Access","shortname":"initial-access"}`},
+					ExpectedEvents:  map[string]int{},
+				},
+			},
+		},
+		{
+			baseTest: baseTest{
+				Name:           "UpdateAttackCatalog",
+				Method:         http.MethodPut,
+				RequestHeaders: map[string]string{"Content-Type": "application/json"},
+				URL:            "/api/attack/catalog",
+				Body: s(map[string]any{
+					"version":    "custom",
+					"tactics":    []map[string]any{{"id": "TA0001", "shortname": "initial-access", "name": "Initial Access"}},
+					"techniques": []map[string]any{{"id": "T1566", "name": "Phishing", "tactics": []string{"TA0001"}}},
+				}),
+			},
+			userTests: []userTest{
+				{
+					Name:            "Analyst",
+					AuthRecord:      data.AnalystEmail,
+					ExpectedStatus:  http.StatusUnauthorized,
+					ExpectedContent: []string{`"missing required scopes"`},
+				},
+				{
+					Name:            "Admin",
+					Admin:           data.AdminEmail,
+					ExpectedStatus:  http.StatusOK,
+					ExpectedContent: []string{`"version":"custom"`, `"techniques":[{"id":"T1566","name":"Phishing","tactics":["TA0001"]}]`},
+					ExpectedEvents:  map[string]int{},
+				},
+			},
+		},
+		{
+			baseTest: baseTest{
+				Name:           "UpdateAttackCatalogInvalid",
+				Method:         http.MethodPut,
+				RequestHeaders: map[string]string{"Content-Type": "application/json"},
+				URL:            "/api/attack/catalog",
+				Body:           s(map[string]any{"version": "empty"}),
+			},
+			userTests: []userTest{
+				{
+					Name:            "Admin",
+					Admin:           data.AdminEmail,
+					ExpectedStatus:  http.StatusBadRequest,
+					ExpectedContent: []string{`catalog: no tactics"`},
+					ExpectedEvents:  map[string]int{},
+				},
+			},
+		},
+		{
+			baseTest: baseTest{
+				Name:   "GetAttackMatrix",
+				Method: http.MethodGet,
+				URL:    "/api/stats/attack-matrix",
+			},
+			userTests: []userTest{
+				{
+					Name:            "Unauthorized",
+					ExpectedStatus:  http.StatusUnauthorized,
+					ExpectedContent: []string{`"invalid bearer token"`},
+				},
+				{
+					Name:            "Analyst",
+					AuthRecord:      data.AnalystEmail,
+					ExpectedStatus:  http.StatusOK,
+					ExpectedContent: []string{`"tickets":0`, `"id":"TA0040"`, `{"id":"T1486","name":"Data Encrypted for Impact","tickets":0}`},
+					ExpectedEvents:  map[string]int{},
+				},
+			},
+		},
+		{
+			baseTest: baseTest{
+				Name:           "SetTicketTechniques",
+				Method:         http.MethodPut,
+				RequestHeaders: map[string]string{"Content-Type": "application/json"},
+				URL:            "/api/tickets/test-ticket/techniques",
+				Body:           `[{"technique":"T1566.001"}]`,
+			},
+			userTests: []userTest{
+				{
+					Name:            "Unauthorized",
+					ExpectedStatus:  http.StatusUnauthorized,
+					ExpectedContent: []string{`"invalid bearer token"`},
+				},
+				{
+					Name:            "Analyst",
+					AuthRecord:      data.AnalystEmail,
+					ExpectedStatus:  http.StatusOK,
+					ExpectedContent: []string{`"technique":"T1566.001"`, `"technique_name":"Spearphishing Attachment"`, `"tactic":"TA0001"`, `"tactic_name":"Initial Access"`},
+					ExpectedEvents:  map[string]int{},
+				},
+			},
+		},
+		{
+			baseTest: baseTest{
+				Name:           "SetTicketTechniquesUnknown",
+				Method:         http.MethodPut,
+				RequestHeaders: map[string]string{"Content-Type": "application/json"},
+				URL:            "/api/tickets/test-ticket/techniques",
+				Body:           `[{"technique":"T9999"}]`,
+			},
+			userTests: []userTest{
+				{
+					Name:            "Analyst",
+					AuthRecord:      data.AnalystEmail,
+					ExpectedStatus:  http.StatusBadRequest,
+					ExpectedContent: []string{`unknown technique \"T9999\"`},
+					ExpectedEvents:  map[string]int{},
+				},
+			},
+		},
+		{
+			baseTest: baseTest{
+				Name:   "ListTypeTechniques",
+				Method: http.MethodGet,
+				URL:    "/api/types/incident/techniques",
+			},
+			userTests: []userTest{
+				{
+					Name:            "Unauthorized",
+					ExpectedStatus:  http.StatusUnauthorized,
+					ExpectedContent: []string{`"invalid bearer token"`},
+				},
+				{
+					Name:            "Analyst",
+					AuthRecord:      data.AnalystEmail,
+					ExpectedStatus:  http.StatusOK,
+					ExpectedContent: []string{`[]`},
+					ExpectedEvents:  map[string]int{},
+				},
+			},
+		},
+		{
+			baseTest: baseTest{
+				Name:           "SetTypeTechniques",
+				Method:         http.MethodPut,
+				RequestHeaders: map[string]string{"Content-Type": "application/json"},
+				URL:            "/api/types/incident/techniques",
+				Body:           `[{"technique":"T1486","tactic":"TA0040"}]`,
+			},
+			userTests: []userTest{
+				{
+					Name:            "Analyst",
+					AuthRecord:      data.AnalystEmail,
+					ExpectedStatus:  http.StatusUnauthorized,
+					ExpectedContent: []string{`"missing required scopes"`},
+				},
+				{
+					Name:            "Admin",
+					Admin:           data.AdminEmail,
+					ExpectedStatus:  http.StatusOK,
+					ExpectedContent: []string{`"technique":"T1486"`, `"tactic_name":"Impact"`},
+					ExpectedEvents:  map[string]int{},
+				},
+			},
+		},
+	}
+
+	for _, testSet := range testSets {
+		t.Run(testSet.baseTest.Name, func(t *testing.T) {
+			t.Parallel()
+
+			for _, userTest := range testSet.userTests {
+				t.Run(userTest.Name, func(t *testing.T) {
+					t.Parallel()
+
+					runMatrixTest(t, testSet.baseTest, userTest)
+				})
+			}
+		})
+	}
+}
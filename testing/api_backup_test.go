@@ -0,0 +1,621 @@
+package testing
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/SecurityBrewery/catalyst/app/data"
+)
+
+func TestBackupsCollection(t *testing.T) {
+	t.Parallel()
+
+	testSets := []catalystTest{
+		{
+			baseTest: baseTest{
+				Name:   "ListBackups",
+				Method: http.MethodGet,
+				URL:    "/api/backups",
+			},
+			userTests: []userTest{
+				{
+					Name:            "Unauthorized",
+					ExpectedStatus:  http.StatusUnauthorized,
+					ExpectedContent: []string{`"invalid bearer token"`},
+				},
+				{
+					Name:            "Analyst",
+					AuthRecord:      data.AnalystEmail,
+					ExpectedStatus:  http.StatusUnauthorized,
+					ExpectedContent: []string{`"missing required scopes"`},
+				},
+				{
+					Name:            "Admin",
+					Admin:           data.AdminEmail,
+					ExpectedStatus:  http.StatusOK,
+					ExpectedContent: []string{`[]`},
+					ExpectedEvents:  map[string]int{},
+				},
+			},
+		},
+		{
+			baseTest: baseTest{
+				Name:   "DownloadBackup",
+				Method: http.MethodGet,
+				URL:    "/api/backups/catalyst-20250601-120000.zip",
+			},
+			userTests: []userTest{
+				{
+					Name:            "Admin",
+					Admin:           data.AdminEmail,
+					ExpectedStatus:  http.StatusNotFound,
+					ExpectedContent: []string{`"The backup does not exist"`},
+					ExpectedEvents:  map[string]int{},
+				},
+			},
+		},
+		{
+			baseTest: baseTest{
+				Name:   "CreateIncrementalBackupWithoutBase",
+				Method: http.MethodPost,
+				URL:    "/api/backups?base=catalyst-20250601-120000.zip",
+			},
+			userTests: []userTest{
+				{
+					Name:            "Admin",
+					Admin:           data.AdminEmail,
+					ExpectedStatus:  http.StatusNotFound,
+					ExpectedContent: []string{`"The backup does not exist"`},
+					ExpectedEvents:  map[string]int{},
+				},
+			},
+		},
+		{
+			baseTest: baseTest{
+				Name:   "CreateBackupInvalidExclusion",
+				Method: http.MethodPost,
+				URL:    "/api/backups?exclude=logs,tickets",
+			},
+			userTests: []userTest{
+				{
+					Name:            "Admin",
+					Admin:           data.AdminEmail,
+					ExpectedStatus:  http.StatusBadRequest,
+					ExpectedContent: []string{`only logs and jobs can be excluded`},
+					ExpectedEvents:  map[string]int{},
+				},
+			},
+		},
+		{
+			baseTest: baseTest{
+				Name:   "CreateBackupJobUnknownBase",
+				Method: http.MethodPost,
+				URL:    "/api/backups?async=true&base=catalyst-20990101-000000.zip",
+			},
+			userTests: []userTest{
+				{
+					Name:            "Admin",
+					Admin:           data.AdminEmail,
+					ExpectedStatus:  http.StatusNotFound,
+					ExpectedContent: []string{`"The backup does not exist"`},
+					ExpectedEvents:  map[string]int{},
+				},
+			},
+		},
+		{
+			baseTest: baseTest{
+				Name:   "DownloadBackupJobNotFound",
+				Method: http.MethodGet,
+				URL:    "/api/backup/download/bj_unknown",
+			},
+			userTests: []userTest{
+				{
+					Name:            "Unauthorized",
+					ExpectedStatus:  http.StatusUnauthorized,
+					ExpectedContent: []string{`"invalid bearer token"`},
+				},
+				{
+					Name:            "Admin",
+					Admin:           data.AdminEmail,
+					ExpectedStatus:  http.StatusNotFound,
+					ExpectedContent: []string{`"The backup job does not exist or its download expired"`},
+					ExpectedEvents:  map[string]int{},
+				},
+			},
+		},
+		{
+			baseTest: baseTest{
+				Name:   "StreamBackupWithoutStorage",
+				Method: http.MethodPost,
+				URL:    "/api/backups?target=s3%3A%2F%2Fbackups%2Fcatalyst",
+			},
+			userTests: []userTest{
+				{
+					Name:            "Admin",
+					Admin:           data.AdminEmail,
+					ExpectedStatus:  http.StatusBadRequest,
+					ExpectedContent: []string{`"invalid backup target: the backup storage is not configured"`},
+					ExpectedEvents:  map[string]int{},
+				},
+			},
+		},
+		{
+			baseTest: baseTest{
+				Name:   "StreamBackupToDirectoryWithoutStorage",
+				Method: http.MethodPost,
+				URL:    "/api/backups?target=file%3A%2F%2F%2Fmnt%2Fbackups",
+			},
+			userTests: []userTest{
+				{
+					Name:            "Admin",
+					Admin:           data.AdminEmail,
+					ExpectedStatus:  http.StatusBadRequest,
+					ExpectedContent: []string{`"invalid backup target: the backup directory is not configured"`},
+					ExpectedEvents:  map[string]int{},
+				},
+			},
+		},
+		{
+			baseTest: baseTest{
+				Name:   "StreamBackupToAzureWithoutStorage",
+				Method: http.MethodPost,
+				URL:    "/api/backups?target=azure%3A%2F%2Fbackups%2Fcatalyst",
+			},
+			userTests: []userTest{
+				{
+					Name:            "Admin",
+					Admin:           data.AdminEmail,
+					ExpectedStatus:  http.StatusBadRequest,
+					ExpectedContent: []string{`"invalid backup target: the Azure storage account is not configured"`},
+					ExpectedEvents:  map[string]int{},
+				},
+			},
+		},
+		{
+			baseTest: baseTest{
+				Name:   "StreamBackupToGCSWithoutStorage",
+				Method: http.MethodPost,
+				URL:    "/api/backups?target=gs%3A%2F%2Fbackups%2Fcatalyst",
+			},
+			userTests: []userTest{
+				{
+					Name:            "Admin",
+					Admin:           data.AdminEmail,
+					ExpectedStatus:  http.StatusBadRequest,
+					ExpectedContent: []string{`"invalid backup target: the GCS bucket is not configured"`},
+					ExpectedEvents:  map[string]int{},
+				},
+			},
+		},
+		{
+			baseTest: baseTest{
+				Name:   "StreamIncrementalBackup",
+				Method: http.MethodPost,
+				URL:    "/api/backups?target=s3%3A%2F%2Fbackups%2Fcatalyst&base=catalyst-20250601-120000.zip",
+			},
+			userTests: []userTest{
+				{
+					Name:            "Admin",
+					Admin:           data.AdminEmail,
+					ExpectedStatus:  http.StatusBadRequest,
+					ExpectedContent: []string{`"Incremental backups can only be stored in the backups folder"`},
+					ExpectedEvents:  map[string]int{},
+				},
+			},
+		},
+		{
+			baseTest: baseTest{
+				Name:   "GetBackupJob",
+				Method: http.MethodGet,
+				URL:    "/api/backup/jobs/bj_unknown",
+			},
+			userTests: []userTest{
+				{
+					Name:            "Analyst",
+					AuthRecord:      data.AnalystEmail,
+					ExpectedStatus:  http.StatusUnauthorized,
+					ExpectedContent: []string{`"missing required scopes"`},
+				},
+				{
+					Name:            "Admin",
+					Admin:           data.AdminEmail,
+					ExpectedStatus:  http.StatusNotFound,
+					ExpectedContent: []string{`"The backup job does not exist"`},
+					ExpectedEvents:  map[string]int{},
+				},
+			},
+		},
+		{
+			baseTest: baseTest{
+				Name:   "GetBackupStorageSettings",
+				Method: http.MethodGet,
+				URL:    "/api/backup/storage/settings",
+			},
+			userTests: []userTest{
+				{
+					Name:            "Analyst",
+					AuthRecord:      data.AnalystEmail,
+					ExpectedStatus:  http.StatusUnauthorized,
+					ExpectedContent: []string{`"missing required scopes"`},
+				},
+				{
+					Name:            "Admin",
+					Admin:           data.AdminEmail,
+					ExpectedStatus:  http.StatusOK,
+					ExpectedContent: []string{`"endpoint":""`, `"secret_access_key":""`},
+					ExpectedEvents:  map[string]int{},
+				},
+			},
+		},
+		{
+			baseTest: baseTest{
+				Name:           "UpdateBackupStorageSettings",
+				Method:         http.MethodPost,
+				RequestHeaders: map[string]string{"Content-Type": "application/json"},
+				URL:            "/api/backup/storage/settings",
+				Body: s(map[string]any{
+					"endpoint":          "https://s3.eu-central-1.amazonaws.com",
+					"region":            "eu-central-1",
+					"bucket":            "backups",
+					"access_key_id":     "AKIDEXAMPLE",
+					"secret_access_key": "secret",
+				}),
+			},
+			userTests: []userTest{
+				{
+					Name:            "Analyst",
+					AuthRecord:      data.AnalystEmail,
+					ExpectedStatus:  http.StatusUnauthorized,
+					ExpectedContent: []string{`"missing required scopes"`},
+				},
+				{
+					Name:            "Admin",
+					Admin:           data.AdminEmail,
+					ExpectedStatus:  http.StatusOK,
+					ExpectedContent: []string{`"bucket":"backups"`, `"secret_access_key":"********"`},
+					ExpectedEvents:  map[string]int{},
+				},
+			},
+		},
+		{
+			baseTest: baseTest{
+				Name:           "UpdateBackupStorageSettingsAzure",
+				Method:         http.MethodPost,
+				RequestHeaders: map[string]string{"Content-Type": "application/json"},
+				URL:            "/api/backup/storage/settings",
+				Body: s(map[string]any{
+					"endpoint":          "",
+					"region":            "",
+					"bucket":            "",
+					"access_key_id":     "",
+					"secret_access_key": "",
+					"azure_account":     "catalyst",
+					"azure_account_key": "a2V5",
+					"azure_container":   "backups",
+				}),
+			},
+			userTests: []userTest{
+				{
+					Name:            "Admin",
+					Admin:           data.AdminEmail,
+					ExpectedStatus:  http.StatusOK,
+					ExpectedContent: []string{`"azure_container":"backups"`, `"azure_account_key":"********"`},
+					ExpectedEvents:  map[string]int{},
+				},
+			},
+		},
+		{
+			baseTest: baseTest{
+				Name:           "UpdateBackupStorageSettingsGCS",
+				Method:         http.MethodPost,
+				RequestHeaders: map[string]string{"Content-Type": "application/json"},
+				URL:            "/api/backup/storage/settings",
+				Body: s(map[string]any{
+					"endpoint":          "",
+					"region":            "",
+					"bucket":            "",
+					"access_key_id":     "",
+					"secret_access_key": "",
+					"gcs_bucket":        "backups",
+					"gcs_access_key_id": "GOOG1E",
+					"gcs_secret":        "secret",
+				}),
+			},
+			userTests: []userTest{
+				{
+					Name:            "Admin",
+					Admin:           data.AdminEmail,
+					ExpectedStatus:  http.StatusOK,
+					ExpectedContent: []string{`"gcs_bucket":"backups"`, `"gcs_secret":"********"`},
+					ExpectedEvents:  map[string]int{},
+				},
+			},
+		},
+		{
+			baseTest: baseTest{
+				Name:           "UpdateBackupStorageSettingsInvalid",
+				Method:         http.MethodPost,
+				RequestHeaders: map[string]string{"Content-Type": "application/json"},
+				URL:            "/api/backup/storage/settings",
+				Body: s(map[string]any{
+					"endpoint":          "s3.eu-central-1.amazonaws.com",
+					"region":            "",
+					"bucket":            "",
+					"access_key_id":     "",
+					"secret_access_key": "",
+				}),
+			},
+			userTests: []userTest{
+				{
+					Name:            "Admin",
+					Admin:           data.AdminEmail,
+					ExpectedStatus:  http.StatusBadRequest,
+					ExpectedContent: []string{`backupStorage.endpoint`},
+					ExpectedEvents:  map[string]int{},
+				},
+			},
+		},
+		{
+			baseTest: baseTest{
+				Name:   "GetBackupNotificationSettings",
+				Method: http.MethodGet,
+				URL:    "/api/backup/notification/settings",
+			},
+			userTests: []userTest{
+				{
+					Name:            "Analyst",
+					AuthRecord:      data.AnalystEmail,
+					ExpectedStatus:  http.StatusUnauthorized,
+					ExpectedContent: []string{`"missing required scopes"`},
+				},
+				{
+					Name:            "Admin",
+					Admin:           data.AdminEmail,
+					ExpectedStatus:  http.StatusOK,
+					ExpectedContent: []string{`"url":""`, `"slack":false`},
+					ExpectedEvents:  map[string]int{},
+				},
+			},
+		},
+		{
+			baseTest: baseTest{
+				Name:           "UpdateBackupNotificationSettings",
+				Method:         http.MethodPost,
+				RequestHeaders: map[string]string{"Content-Type": "application/json"},
+				URL:            "/api/backup/notification/settings",
+				Body: s(map[string]any{
+					"url":     "https://hooks.example.com/backups",
+					"slack":   false,
+					"headers": map[string]string{"Authorization": "Bearer secret"},
+				}),
+			},
+			userTests: []userTest{
+				{
+					Name:            "Analyst",
+					AuthRecord:      data.AnalystEmail,
+					ExpectedStatus:  http.StatusUnauthorized,
+					ExpectedContent: []string{`"missing required scopes"`},
+				},
+				{
+					Name:            "Admin",
+					Admin:           data.AdminEmail,
+					ExpectedStatus:  http.StatusOK,
+					ExpectedContent: []string{`"url":"https://hooks.example.com/backups"`, `"Authorization":"********"`},
+					ExpectedEvents:  map[string]int{},
+				},
+			},
+		},
+		{
+			baseTest: baseTest{
+				Name:           "UpdateBackupNotificationSettingsInvalid",
+				Method:         http.MethodPost,
+				RequestHeaders: map[string]string{"Content-Type": "application/json"},
+				URL:            "/api/backup/notification/settings",
+				Body:           s(map[string]any{"url": "hooks.example.com", "slack": true}),
+			},
+			userTests: []userTest{
+				{
+					Name:            "Admin",
+					Admin:           data.AdminEmail,
+					ExpectedStatus:  http.StatusBadRequest,
+					ExpectedContent: []string{`backupNotification.url`},
+					ExpectedEvents:  map[string]int{},
+				},
+			},
+		},
+		{
+			baseTest: baseTest{
+				Name:   "GetBackupRetentionSettings",
+				Method: http.MethodGet,
+				URL:    "/api/backup/retention/settings",
+			},
+			userTests: []userTest{
+				{
+					Name:            "Analyst",
+					AuthRecord:      data.AnalystEmail,
+					ExpectedStatus:  http.StatusUnauthorized,
+					ExpectedContent: []string{`"missing required scopes"`},
+				},
+				{
+					Name:            "Admin",
+					Admin:           data.AdminEmail,
+					ExpectedStatus:  http.StatusOK,
+					ExpectedContent: []string{`"daily":0`, `"weekly":0`},
+					ExpectedEvents:  map[string]int{},
+				},
+			},
+		},
+		{
+			baseTest: baseTest{
+				Name:           "UpdateBackupRetentionSettings",
+				Method:         http.MethodPost,
+				RequestHeaders: map[string]string{"Content-Type": "application/json"},
+				URL:            "/api/backup/retention/settings",
+				Body:           s(map[string]any{"daily": 7, "weekly": 4}),
+			},
+			userTests: []userTest{
+				{
+					Name:            "Analyst",
+					AuthRecord:      data.AnalystEmail,
+					ExpectedStatus:  http.StatusUnauthorized,
+					ExpectedContent: []string{`"missing required scopes"`},
+				},
+				{
+					Name:            "Admin",
+					Admin:           data.AdminEmail,
+					ExpectedStatus:  http.StatusOK,
+					ExpectedContent: []string{`"daily":7`, `"weekly":4`},
+					ExpectedEvents:  map[string]int{},
+				},
+			},
+		},
+		{
+			baseTest: baseTest{
+				Name:           "UpdateBackupRetentionSettingsInvalid",
+				Method:         http.MethodPost,
+				RequestHeaders: map[string]string{"Content-Type": "application/json"},
+				URL:            "/api/backup/retention/settings",
+				Body:           s(map[string]any{"daily": -1, "weekly": 0}),
+			},
+			userTests: []userTest{
+				{
+					Name:            "Admin",
+					Admin:           data.AdminEmail,
+					ExpectedStatus:  http.StatusBadRequest,
+					ExpectedContent: []string{`backupRetention.daily`},
+					ExpectedEvents:  map[string]int{},
+				},
+			},
+		},
+		{
+			baseTest: baseTest{
+				Name:   "DeleteBackupNotFound",
+				Method: http.MethodDelete,
+				URL:    "/api/backups/catalyst-20250601-000000.zip",
+			},
+			userTests: []userTest{
+				{
+					Name:            "Analyst",
+					AuthRecord:      data.AnalystEmail,
+					ExpectedStatus:  http.StatusUnauthorized,
+					ExpectedContent: []string{`"missing required scopes"`},
+				},
+				{
+					Name:            "Admin",
+					Admin:           data.AdminEmail,
+					ExpectedStatus:  http.StatusNotFound,
+					ExpectedContent: []string{`"The backup does not exist"`},
+					ExpectedEvents:  map[string]int{},
+				},
+			},
+		},
+		{
+			baseTest: baseTest{
+				Name:           "VerifyBackup",
+				Method:         http.MethodPost,
+				RequestHeaders: map[string]string{"Content-Type": "application/zip"},
+				URL:            "/api/backup/verify",
+				Body:           "not a zip archive",
+			},
+			userTests: []userTest{
+				{
+					Name:            "Analyst",
+					AuthRecord:      data.AnalystEmail,
+					ExpectedStatus:  http.StatusUnauthorized,
+					ExpectedContent: []string{`"missing required scopes"`},
+				},
+				{
+					Name:           "Admin",
+					Admin:          data.AdminEmail,
+					ExpectedStatus: http.StatusOK,
+					ExpectedContent: []string{
+						`"valid":false`,
+						`"invalid zip archive: zip: not a valid zip file"`,
+					},
+					ExpectedEvents: map[string]int{},
+				},
+			},
+		},
+		{
+			baseTest: baseTest{
+				Name:           "PreviewBackup",
+				Method:         http.MethodPost,
+				RequestHeaders: map[string]string{"Content-Type": "application/zip"},
+				URL:            "/api/backup/preview",
+				Body:           "not a zip archive",
+			},
+			userTests: []userTest{
+				{
+					Name:            "Analyst",
+					AuthRecord:      data.AnalystEmail,
+					ExpectedStatus:  http.StatusUnauthorized,
+					ExpectedContent: []string{`"missing required scopes"`},
+				},
+				{
+					Name:            "Admin",
+					Admin:           data.AdminEmail,
+					ExpectedStatus:  http.StatusBadRequest,
+					ExpectedContent: []string{`"backup is invalid: invalid zip archive: zip: not a valid zip file"`},
+					ExpectedEvents:  map[string]int{},
+				},
+			},
+		},
+		{
+			baseTest: baseTest{
+				Name:           "ValidateBackup",
+				Method:         http.MethodPost,
+				RequestHeaders: map[string]string{"Content-Type": "application/zip"},
+				URL:            "/api/backup/validate?table=playbooks&table=templates",
+				Body:           "not a zip archive",
+			},
+			userTests: []userTest{
+				{
+					Name:            "Analyst",
+					AuthRecord:      data.AnalystEmail,
+					ExpectedStatus:  http.StatusUnauthorized,
+					ExpectedContent: []string{`"missing required scopes"`},
+				},
+				{
+					Name:           "Admin",
+					Admin:          data.AdminEmail,
+					ExpectedStatus: http.StatusOK,
+					ExpectedContent: []string{
+						`"valid":false`,
+						`"invalid zip archive: zip: not a valid zip file"`,
+					},
+					ExpectedEvents: map[string]int{},
+				},
+			},
+		},
+		{
+			baseTest: baseTest{
+				Name:   "ValidateBackupNotFound",
+				Method: http.MethodPost,
+				URL:    "/api/backup/validate?name=catalyst-20250601-000000.zip",
+			},
+			userTests: []userTest{
+				{
+					Name:            "Admin",
+					Admin:           data.AdminEmail,
+					ExpectedStatus:  http.StatusNotFound,
+					ExpectedContent: []string{`"The backup does not exist"`},
+					ExpectedEvents:  map[string]int{},
+				},
+			},
+		},
+	}
+
+	for _, testSet := range testSets {
+		t.Run(testSet.baseTest.Name, func(t *testing.T) {
+			t.Parallel()
+
+			for _, userTest := range testSet.userTests {
+				t.Run(userTest.Name, func(t *testing.T) {
+					t.Parallel()
+
+					runMatrixTest(t, testSet.baseTest, userTest)
+				})
+			}
+		})
+	}
+}
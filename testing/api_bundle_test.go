@@ -0,0 +1,161 @@
+package testing
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/SecurityBrewery/catalyst/app/data"
+)
+
+var testBundle = map[string]any{
+	"playbooks": []any{
+		map[string]any{
+			"name":   "Imported playbook",
+			"inputs": []any{},
+			"tasks":  []any{map[string]any{"name": "Check the sender"}},
+		},
+	},
+	"reactions": []any{
+		map[string]any{
+			"name":        "Imported reaction",
+			"action":      "python",
+			"actiondata":  map[string]any{"requirements": "", "script": "import subprocess\nprint('hello')"},
+			"trigger":     "hook",
+			"triggerdata": map[string]any{"collections": []any{"tickets"}, "events": []any{"create"}},
+		},
+	},
+}
+
+func TestBundles(t *testing.T) {
+	t.Parallel()
+
+	testSets := []catalystTest{
+		{
+			baseTest: baseTest{
+				Name:           "ScanBundle",
+				Method:         http.MethodPost,
+				RequestHeaders: map[string]string{"Content-Type": "application/json"},
+				URL:            "/api/admin/bundles/scan",
+				Body:           s(testBundle),
+			},
+			userTests: []userTest{
+				{
+					Name:            "Unauthorized",
+					ExpectedStatus:  http.StatusUnauthorized,
+					ExpectedContent: []string{`"invalid bearer token"`},
+				},
+				{
+					Name:            "Analyst",
+					AuthRecord:      data.AnalystEmail,
+					ExpectedStatus:  http.StatusUnauthorized,
+					ExpectedContent: []string{`"missing required scopes"`},
+				},
+				{
+					Name:            "Admin",
+					Admin:           data.AdminEmail,
+					ExpectedStatus:  http.StatusOK,
+					ExpectedContent: []string{`"rule":"dangerous-command"`, `"blocked":false`, `"digest":"`},
+				},
+			},
+		},
+		{
+			baseTest: baseTest{
+				Name:           "ImportBundleUnconfirmed",
+				Method:         http.MethodPost,
+				RequestHeaders: map[string]string{"Content-Type": "application/json"},
+				URL:            "/api/admin/bundles",
+				Body:           s(map[string]any{"bundle": testBundle}),
+			},
+			userTests: []userTest{
+				{
+					Name:               "Admin",
+					Admin:              data.AdminEmail,
+					ExpectedStatus:     http.StatusConflict,
+					ExpectedContent:    []string{`"findings":[`, `"digest":"`},
+					NotExpectedContent: []string{`"reactions"`},
+				},
+			},
+		},
+		{
+			baseTest: baseTest{
+				Name:           "ImportBlockedBundle",
+				Method:         http.MethodPost,
+				RequestHeaders: map[string]string{"Content-Type": "application/json"},
+				URL:            "/api/admin/bundles",
+				Body: s(map[string]any{"bundle": map[string]any{
+					"playbooks": []any{},
+					"reactions": []any{map[string]any{"name": "Shell", "action": "bash", "actiondata": map[string]any{}, "trigger": "hook", "triggerdata": map[string]any{}}},
+				}}),
+			},
+			userTests: []userTest{
+				{
+					Name:            "Admin",
+					Admin:           data.AdminEmail,
+					ExpectedStatus:  http.StatusBadRequest,
+					ExpectedContent: []string{"The bundle violates a blocking policy"},
+				},
+			},
+		},
+	}
+
+	for _, testSet := range testSets {
+		t.Run(testSet.baseTest.Name, func(t *testing.T) {
+			t.Parallel()
+
+			for _, userTest := range testSet.userTests {
+				t.Run(userTest.Name, func(t *testing.T) {
+					t.Parallel()
+
+					runMatrixTest(t, testSet.baseTest, userTest)
+				})
+			}
+		})
+	}
+}
+
+func TestBundles_confirmedImport(t *testing.T) {
+	t.Parallel()
+
+	baseApp, cleanup, counter := App(t)
+	t.Cleanup(cleanup)
+
+	token := adminToken(t, baseApp)
+
+	post := func(t *testing.T, url string, body map[string]any) *httptest.ResponseRecorder {
+		t.Helper()
+
+		req := httptest.NewRequest(http.MethodPost, url, strings.NewReader(s(body)))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+
+		recorder := httptest.NewRecorder()
+		baseApp.ServeHTTP(recorder, req)
+
+		return recorder
+	}
+
+	res := post(t, "/api/admin/bundles/scan", testBundle)
+	require.Equal(t, http.StatusOK, res.Code, res.Body.String())
+
+	var report struct {
+		Digest string `json:"digest"`
+	}
+
+	require.NoError(t, json.Unmarshal(res.Body.Bytes(), &report))
+
+	res = post(t, "/api/admin/bundles", map[string]any{"bundle": testBundle, "confirm": "stale"})
+	assert.Equal(t, http.StatusConflict, res.Code)
+
+	res = post(t, "/api/admin/bundles", map[string]any{"bundle": testBundle, "confirm": report.Digest})
+	require.Equal(t, http.StatusOK, res.Code, res.Body.String())
+
+	assert.Contains(t, res.Body.String(), `"name":"Imported playbook"`)
+	assert.Contains(t, res.Body.String(), `"name":"Imported reaction"`)
+	assert.Equal(t, 1, counter.Count("OnRecordAfterCreateRequest"))
+}
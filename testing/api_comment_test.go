@@ -92,6 +92,28 @@ func TestCommentsCollection(t *testing.T) {
 				},
 			},
 		},
+		{
+			baseTest: baseTest{
+				Name:           "CreateCommentWithSecret",
+				Method:         http.MethodPost,
+				RequestHeaders: map[string]string{"Content-Type": "application/json"},
+				URL:            "/api/comments",
+				Body: s(map[string]any{
+					"author":  "u_bob_analyst",
+					"message": "webhook token is 1234567890",
+					"ticket":  "test-ticket",
+				}),
+			},
+			userTests: []userTest{
+				{
+					Name:               "Analyst",
+					AuthRecord:         data.AnalystEmail,
+					ExpectedStatus:     http.StatusOK,
+					ExpectedContent:    []string{`"message":"webhook token is ********"`},
+					NotExpectedContent: []string{"1234567890"},
+				},
+			},
+		},
 		{
 			baseTest: baseTest{
 				Name:   "GetComment",
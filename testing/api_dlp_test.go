@@ -0,0 +1,125 @@
+package testing
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/SecurityBrewery/catalyst/app/data"
+)
+
+func TestDLPEndpoints(t *testing.T) {
+	t.Parallel()
+
+	testSets := []catalystTest{
+		{
+			baseTest: baseTest{
+				Name:   "GetDLPSettings",
+				Method: http.MethodGet,
+				URL:    "/api/dlp/settings",
+			},
+			userTests: []userTest{
+				{
+					Name:            "Unauthorized",
+					ExpectedStatus:  http.StatusUnauthorized,
+					ExpectedContent: []string{`"invalid bearer token"`},
+				},
+				{
+					Name:            "Analyst",
+					AuthRecord:      data.AnalystEmail,
+					ExpectedStatus:  http.StatusUnauthorized,
+					ExpectedContent: []string{`"missing required scopes"`},
+				},
+				{
+					Name:            "Admin",
+					Admin:           data.AdminEmail,
+					ExpectedStatus:  http.StatusOK,
+					ExpectedContent: []string{`"rules":[]`},
+				},
+			},
+		},
+		{
+			baseTest: baseTest{
+				Name:           "UpdateDLPSettings",
+				Method:         http.MethodPost,
+				RequestHeaders: map[string]string{"Content-Type": "application/json"},
+				URL:            "/api/dlp/settings",
+				Body: s(map[string]any{
+					"rules": []any{
+						map[string]any{"name": "No red tickets", "tlp": []string{"TLP:RED"}, "exports": []string{"csv"}},
+					},
+				}),
+			},
+			userTests: []userTest{
+				{
+					Name:            "Analyst",
+					AuthRecord:      data.AnalystEmail,
+					ExpectedStatus:  http.StatusUnauthorized,
+					ExpectedContent: []string{`"missing required scopes"`},
+				},
+				{
+					Name:            "Admin",
+					Admin:           data.AdminEmail,
+					ExpectedStatus:  http.StatusOK,
+					ExpectedContent: []string{`"name":"No red tickets"`, `"tlp":["TLP:RED"]`, `"exports":["csv"]`},
+				},
+			},
+		},
+		{
+			baseTest: baseTest{
+				Name:           "UpdateDLPSettingsInvalid",
+				Method:         http.MethodPost,
+				RequestHeaders: map[string]string{"Content-Type": "application/json"},
+				URL:            "/api/dlp/settings",
+				Body: s(map[string]any{
+					"rules": []any{
+						map[string]any{"name": "Nothing", "exports": []string{"csv"}},
+					},
+				}),
+			},
+			userTests: []userTest{
+				{
+					Name:            "Admin",
+					Admin:           data.AdminEmail,
+					ExpectedStatus:  http.StatusBadRequest,
+					ExpectedContent: []string{`"invalid data loss prevention rule: rule Nothing matches no TLP level or tag"`},
+				},
+			},
+		},
+		{
+			baseTest: baseTest{
+				Name:   "ListDLPEvents",
+				Method: http.MethodGet,
+				URL:    "/api/dlp/events",
+			},
+			userTests: []userTest{
+				{
+					Name:            "Analyst",
+					AuthRecord:      data.AnalystEmail,
+					ExpectedStatus:  http.StatusUnauthorized,
+					ExpectedContent: []string{`"missing required scopes"`},
+				},
+				{
+					Name:            "Admin",
+					Admin:           data.AdminEmail,
+					ExpectedStatus:  http.StatusOK,
+					ExpectedHeaders: map[string]string{"X-Total-Count": "0"},
+					ExpectedContent: []string{`[]`},
+				},
+			},
+		},
+	}
+
+	for _, testSet := range testSets {
+		t.Run(testSet.baseTest.Name, func(t *testing.T) {
+			t.Parallel()
+
+			for _, userTest := range testSet.userTests {
+				t.Run(userTest.Name, func(t *testing.T) {
+					t.Parallel()
+
+					runMatrixTest(t, testSet.baseTest, userTest)
+				})
+			}
+		})
+	}
+}
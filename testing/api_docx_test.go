@@ -0,0 +1,155 @@
+package testing
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/SecurityBrewery/catalyst/app/data"
+)
+
+func TestDocxEndpoints(t *testing.T) {
+	t.Parallel()
+
+	testSets := []catalystTest{
+		{
+			baseTest: baseTest{
+				Name:   "GetTicketDocx",
+				Method: http.MethodGet,
+				URL:    "/api/tickets/test-ticket/docx",
+			},
+			userTests: []userTest{
+				{
+					Name:            "Unauthorized",
+					ExpectedStatus:  http.StatusUnauthorized,
+					ExpectedContent: []string{`"invalid bearer token"`},
+				},
+				{
+					Name:            "Analyst",
+					AuthRecord:      data.AnalystEmail,
+					ExpectedStatus:  http.StatusUnauthorized,
+					ExpectedContent: []string{`"missing required scopes"`},
+				},
+				{
+					Name:           "Admin",
+					Admin:          data.AdminEmail,
+					ExpectedStatus: http.StatusOK,
+					ExpectedHeaders: map[string]string{
+						"Content-Type":        "application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+						"Content-Disposition": `attachment; filename="test-ticket.docx"`,
+					},
+					ExpectedContent: []string{`word/document.xml`},
+				},
+			},
+		},
+		{
+			baseTest: baseTest{
+				Name:   "GetTicketDocxUnknownProfile",
+				Method: http.MethodGet,
+				URL:    "/api/tickets/test-ticket/docx?profile=p-unknown",
+			},
+			userTests: []userTest{
+				{
+					Name:            "Admin",
+					Admin:           data.AdminEmail,
+					ExpectedStatus:  http.StatusBadRequest,
+					ExpectedContent: []string{`unknown redaction profile p-unknown`},
+				},
+			},
+		},
+		{
+			baseTest: baseTest{
+				Name:   "GetReportDocx",
+				Method: http.MethodGet,
+				URL:    "/api/reports/docx?since=2000-01-01T00:00:00Z",
+			},
+			userTests: []userTest{
+				{
+					Name:            "Analyst",
+					AuthRecord:      data.AnalystEmail,
+					ExpectedStatus:  http.StatusUnauthorized,
+					ExpectedContent: []string{`"missing required scopes"`},
+				},
+				{
+					Name:            "Admin",
+					Admin:           data.AdminEmail,
+					ExpectedStatus:  http.StatusOK,
+					ExpectedHeaders: map[string]string{"Content-Type": "application/vnd.openxmlformats-officedocument.wordprocessingml.document"},
+					ExpectedContent: []string{`word/document.xml`},
+				},
+			},
+		},
+		{
+			baseTest: baseTest{
+				Name:   "GetDocxTemplate",
+				Method: http.MethodGet,
+				URL:    "/api/docx/template",
+			},
+			userTests: []userTest{
+				{
+					Name:            "Analyst",
+					AuthRecord:      data.AnalystEmail,
+					ExpectedStatus:  http.StatusUnauthorized,
+					ExpectedContent: []string{`"missing required scopes"`},
+				},
+				{
+					Name:            "Admin",
+					Admin:           data.AdminEmail,
+					ExpectedStatus:  http.StatusOK,
+					ExpectedHeaders: map[string]string{"Content-Disposition": `attachment; filename="template.docx"`},
+					ExpectedContent: []string{`word/styles.xml`},
+				},
+			},
+		},
+		{
+			baseTest: baseTest{
+				Name:           "SetDocxTemplateInvalid",
+				Method:         http.MethodPut,
+				RequestHeaders: map[string]string{"Content-Type": "application/vnd.openxmlformats-officedocument.wordprocessingml.document"},
+				URL:            "/api/docx/template",
+				Body:           "not a docx file",
+			},
+			userTests: []userTest{
+				{
+					Name:            "Analyst",
+					AuthRecord:      data.AnalystEmail,
+					ExpectedStatus:  http.StatusUnauthorized,
+					ExpectedContent: []string{`"missing required scopes"`},
+				},
+				{
+					Name:            "Admin",
+					Admin:           data.AdminEmail,
+					ExpectedStatus:  http.StatusBadRequest,
+					ExpectedContent: []string{`invalid DOCX template`},
+				},
+			},
+		},
+		{
+			baseTest: baseTest{
+				Name:   "DeleteDocxTemplate",
+				Method: http.MethodDelete,
+				URL:    "/api/docx/template",
+			},
+			userTests: []userTest{
+				{
+					Name:           "Admin",
+					Admin:          data.AdminEmail,
+					ExpectedStatus: http.StatusNoContent,
+				},
+			},
+		},
+	}
+
+	for _, testSet := range testSets {
+		t.Run(testSet.baseTest.Name, func(t *testing.T) {
+			t.Parallel()
+
+			for _, userTest := range testSet.userTests {
+				t.Run(userTest.Name, func(t *testing.T) {
+					t.Parallel()
+
+					runMatrixTest(t, testSet.baseTest, userTest)
+				})
+			}
+		})
+	}
+}
@@ -68,7 +68,7 @@ func TestFilesCollection(t *testing.T) {
 					Name:            "Admin",
 					Admin:           data.AdminEmail,
 					ExpectedStatus:  http.StatusOK,
-					ExpectedContent: []string{`"ticket":"test-ticket"`},
+					ExpectedContent: []string{`"ticket":"test-ticket"`, `"sha256":"e1c8f0626e85eee5c9215c09d1faf2486d575dd4b96a498e6d192d7a1ed2baa4"`},
 					ExpectedEvents: map[string]int{
 						"OnRecordAfterCreateRequest":  1,
 						"OnRecordBeforeCreateRequest": 1,
@@ -133,6 +133,48 @@ func TestFilesCollection(t *testing.T) {
 				},
 			},
 		},
+		{
+			baseTest: baseTest{
+				Name:   "GetTicketCustody",
+				Method: http.MethodGet,
+				URL:    "/api/tickets/test-ticket/custody",
+			},
+			userTests: []userTest{
+				{
+					Name:           "Unauthorized",
+					ExpectedStatus: http.StatusUnauthorized,
+					ExpectedContent: []string{
+						`"invalid bearer token"`,
+					},
+				},
+				{
+					Name:           "Analyst",
+					AuthRecord:     data.AnalystEmail,
+					ExpectedStatus: http.StatusOK,
+					ExpectedContent: []string{
+						`%PDF-1.4`,
+						`(Ticket: test-ticket - Test Ticket) Tj`,
+					},
+				},
+			},
+		},
+		{
+			baseTest: baseTest{
+				Name:   "GetCustodyKey",
+				Method: http.MethodGet,
+				URL:    "/api/custody/key",
+			},
+			userTests: []userTest{
+				{
+					Name:           "Analyst",
+					AuthRecord:     data.AnalystEmail,
+					ExpectedStatus: http.StatusOK,
+					ExpectedContent: []string{
+						`"algorithm":"Ed25519"`,
+					},
+				},
+			},
+		},
 	}
 
 	for _, testSet := range testSets {
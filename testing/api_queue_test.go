@@ -0,0 +1,201 @@
+package testing
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/SecurityBrewery/catalyst/app/data"
+	"github.com/SecurityBrewery/catalyst/app/database/sqlc"
+)
+
+func TestQueuesCollection(t *testing.T) {
+	t.Parallel()
+
+	testSets := []catalystTest{
+		{
+			baseTest: baseTest{
+				Name:   "ListQueues",
+				Method: http.MethodGet,
+				URL:    "/api/queues",
+			},
+			userTests: []userTest{
+				{
+					Name:            "Unauthorized",
+					ExpectedStatus:  http.StatusUnauthorized,
+					ExpectedContent: []string{`"invalid bearer token"`},
+				},
+				{
+					Name:            "Analyst",
+					AuthRecord:      data.AnalystEmail,
+					ExpectedStatus:  http.StatusOK,
+					ExpectedHeaders: map[string]string{"X-Total-Count": "0"},
+				},
+			},
+		},
+		{
+			baseTest: baseTest{
+				Name:           "CreateQueue",
+				Method:         http.MethodPost,
+				RequestHeaders: map[string]string{"Content-Type": "application/json"},
+				URL:            "/api/queues",
+				Body:           s(map[string]any{"name": "tier-1", "filter": map[string]any{"types": []string{"incident"}}}),
+			},
+			userTests: []userTest{
+				{
+					Name:            "Unauthorized",
+					ExpectedStatus:  http.StatusUnauthorized,
+					ExpectedContent: []string{`"invalid bearer token"`},
+				},
+				{
+					Name:            "Analyst",
+					AuthRecord:      data.AnalystEmail,
+					ExpectedStatus:  http.StatusUnauthorized,
+					ExpectedContent: []string{`"missing required scopes"`},
+				},
+				{
+					Name:            "Admin",
+					Admin:           data.AdminEmail,
+					ExpectedStatus:  http.StatusOK,
+					ExpectedContent: []string{`"name":"tier-1"`, `"types":["incident"]`},
+				},
+			},
+		},
+		{
+			baseTest: baseTest{
+				Name:   "ClaimNextTicketOfUnknownQueue",
+				Method: http.MethodPost,
+				URL:    "/api/queues/tier-1/next",
+			},
+			userTests: []userTest{
+				{
+					Name:            "Unauthorized",
+					ExpectedStatus:  http.StatusUnauthorized,
+					ExpectedContent: []string{`"invalid bearer token"`},
+				},
+				{
+					Name:            "Analyst",
+					AuthRecord:      data.AnalystEmail,
+					ExpectedStatus:  http.StatusNotFound,
+					ExpectedContent: []string{`"The queue does not exist"`},
+				},
+			},
+		},
+	}
+
+	for _, testSet := range testSets {
+		t.Run(testSet.baseTest.Name, func(t *testing.T) {
+			t.Parallel()
+
+			for _, userTest := range testSet.userTests {
+				t.Run(userTest.Name, func(t *testing.T) {
+					t.Parallel()
+
+					runMatrixTest(t, testSet.baseTest, userTest)
+				})
+			}
+		})
+	}
+}
+
+func TestClaimNextQueueTicket(t *testing.T) {
+	t.Parallel()
+
+	baseApp, cleanup, _ := App(t)
+	t.Cleanup(cleanup)
+
+	token := adminToken(t, baseApp)
+
+	_, err := baseApp.Queries.CreateQueue(t.Context(), sqlc.CreateQueueParams{
+		Name:   "claims",
+		Filter: []byte(`{"types":["alert"],"tags":["queue-claim"]}`),
+	})
+	require.NoError(t, err)
+
+	createTicket := func(ticketType, state string) string {
+		t.Helper()
+
+		ticket, err := baseApp.Queries.CreateTicket(t.Context(), sqlc.CreateTicketParams{
+			Name:   "Claim",
+			Open:   true,
+			Schema: []byte(`{}`),
+			State:  []byte(state),
+			Type:   ticketType,
+		})
+		require.NoError(t, err)
+
+		return ticket.ID
+	}
+
+	const queued = 10
+
+	expected := make([]string, 0, queued)
+	for range queued {
+		expected = append(expected, createTicket("alert", `{"severity":"Low","tags":["other","Queue-Claim"]}`))
+	}
+
+	other := []string{
+		createTicket("incident", `{"tags":["queue-claim"]}`),
+		createTicket("alert", `{"tags":["other"]}`),
+		createTicket("alert", `{"tags":"queue-claim"}`),
+	}
+
+	var (
+		mu      sync.Mutex
+		claimed []string
+		empty   int
+		wg      sync.WaitGroup
+	)
+
+	for range queued + 5 {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			req := httptest.NewRequest(http.MethodPost, "/api/queues/claims/next", nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+
+			recorder := httptest.NewRecorder()
+			baseApp.ServeHTTP(recorder, req)
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			switch recorder.Code {
+			case http.StatusOK:
+				var ticket struct {
+					ID    string `json:"id"`
+					Owner string `json:"owner"`
+				}
+
+				if assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &ticket)) {
+					assert.NotEmpty(t, ticket.Owner)
+
+					claimed = append(claimed, ticket.ID)
+				}
+			case http.StatusNoContent:
+				empty++
+			default:
+				t.Errorf("unexpected status %d: %s", recorder.Code, recorder.Body.String())
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	// every queued ticket is claimed exactly once
+	assert.ElementsMatch(t, expected, claimed)
+	assert.Equal(t, 5, empty)
+
+	for _, id := range other {
+		ticket, err := baseApp.Queries.Ticket(t.Context(), id)
+		require.NoError(t, err)
+		assert.Nil(t, ticket.Owner, id)
+	}
+}
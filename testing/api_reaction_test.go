@@ -134,6 +134,73 @@ func TestReactionsCollection(t *testing.T) {
 				},
 			},
 		},
+		{
+			baseTest: baseTest{
+				Name:   "GetReactionStats",
+				Method: http.MethodGet,
+				URL:    "/api/reactions/r-test-webhook/stats",
+			},
+			userTests: []userTest{
+				{
+					Name:            "Analyst",
+					AuthRecord:      data.AnalystEmail,
+					ExpectedStatus:  http.StatusUnauthorized,
+					ExpectedContent: []string{`"missing required scopes"`},
+				},
+				{
+					Name:            "Admin",
+					Admin:           data.AdminEmail,
+					ExpectedStatus:  http.StatusOK,
+					ExpectedContent: []string{`"runs":0`, `"recent_runs":[]`},
+					ExpectedEvents:  map[string]int{},
+				},
+			},
+		},
+		{
+			baseTest: baseTest{
+				Name:   "ListReactionJournal",
+				Method: http.MethodGet,
+				URL:    "/api/reactions/r-test-webhook/journal",
+			},
+			userTests: []userTest{
+				{
+					Name:            "Analyst",
+					AuthRecord:      data.AnalystEmail,
+					ExpectedStatus:  http.StatusUnauthorized,
+					ExpectedContent: []string{`"missing required scopes"`},
+				},
+				{
+					Name:            "Admin",
+					Admin:           data.AdminEmail,
+					ExpectedStatus:  http.StatusOK,
+					ExpectedHeaders: map[string]string{"X-Total-Count": "0"},
+					ExpectedContent: []string{`[]`},
+					ExpectedEvents:  map[string]int{},
+				},
+			},
+		},
+		{
+			baseTest: baseTest{
+				Name:   "ReplayReactionJournal",
+				Method: http.MethodPost,
+				URL:    "/api/reactions/r-test-webhook/journal/j-unknown/replay",
+			},
+			userTests: []userTest{
+				{
+					Name:            "Analyst",
+					AuthRecord:      data.AnalystEmail,
+					ExpectedStatus:  http.StatusUnauthorized,
+					ExpectedContent: []string{`"missing required scopes"`},
+				},
+				{
+					Name:            "Admin",
+					Admin:           data.AdminEmail,
+					ExpectedStatus:  http.StatusNotFound,
+					ExpectedContent: []string{`"The request is not in the journal of the reaction"`},
+					ExpectedEvents:  map[string]int{},
+				},
+			},
+		},
 		{
 			baseTest: baseTest{
 				Name:           "UpdateReaction",
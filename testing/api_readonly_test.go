@@ -0,0 +1,75 @@
+package testing
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/SecurityBrewery/catalyst/app"
+	"github.com/SecurityBrewery/catalyst/app/data"
+	"github.com/SecurityBrewery/catalyst/app/migration"
+	"github.com/SecurityBrewery/catalyst/app/settings"
+)
+
+func TestReadOnly(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	catalyst, cleanup, err := app.New(t.Context(), dir, app.Config{})
+	require.NoError(t, err)
+
+	data.DefaultTestData(t, dir, catalyst.Queries)
+
+	// the settings are stored on first use, a replica cannot store them
+	_, err = settings.Load(t.Context(), catalyst.Queries)
+	require.NoError(t, err)
+
+	cleanup()
+
+	before, err := os.ReadFile(filepath.Join(dir, "data.db"))
+	require.NoError(t, err)
+
+	replica, cleanup, err := app.New(t.Context(), dir, app.Config{ReadOnly: true})
+	require.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	replica.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/branding", nil))
+	assert.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+
+	recorder = httptest.NewRecorder()
+	replica.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/api/branding", strings.NewReader(`{"title":"Replica"}`)))
+	assert.Equal(t, http.StatusMethodNotAllowed, recorder.Code)
+
+	cleanup()
+
+	after, err := os.ReadFile(filepath.Join(dir, "data.db"))
+	require.NoError(t, err)
+	assert.Equal(t, before, after, "the read-only instance changed the database")
+
+	// an outdated database is not migrated
+	db, err := sql.Open("sqlite3", "file:"+filepath.Join(dir, "data.db"))
+	require.NoError(t, err)
+
+	_, err = db.ExecContext(t.Context(), fmt.Sprintf("PRAGMA user_version = %d", migration.Latest()-1))
+	require.NoError(t, err)
+	require.NoError(t, db.Close())
+
+	before, err = os.ReadFile(filepath.Join(dir, "data.db"))
+	require.NoError(t, err)
+
+	_, _, err = app.New(t.Context(), dir, app.Config{ReadOnly: true})
+	require.ErrorContains(t, err, "migrate it to")
+
+	after, err = os.ReadFile(filepath.Join(dir, "data.db"))
+	require.NoError(t, err)
+	assert.Equal(t, before, after, "the read-only instance migrated the database")
+}
@@ -66,6 +66,877 @@ func Test_Routes(t *testing.T) {
 				},
 			},
 		},
+		{
+			baseTest: baseTest{
+				Name:   "Branding",
+				Method: http.MethodGet,
+				URL:    "/api/branding",
+			},
+			userTests: []userTest{
+				{
+					Name:            "NoAuth",
+					ExpectedStatus:  http.StatusOK,
+					ExpectedContent: []string{`"custom_css":`},
+				},
+				{
+					Name:            "Analyst",
+					AuthRecord:      data.AnalystEmail,
+					ExpectedStatus:  http.StatusOK,
+					ExpectedContent: []string{`"custom_css":`},
+				},
+			},
+		},
+		{
+			baseTest: baseTest{
+				Name:   "IntakeForm",
+				Method: http.MethodGet,
+				URL:    "/api/intake",
+			},
+			userTests: []userTest{
+				{
+					Name:            "NoAuth",
+					ExpectedStatus:  http.StatusNotFound,
+					ExpectedContent: []string{`"The intake form is disabled"`},
+				},
+			},
+		},
+		{
+			baseTest: baseTest{
+				Name:           "UpdateIntakeSettings",
+				Method:         http.MethodPost,
+				RequestHeaders: map[string]string{"Content-Type": "application/json"},
+				URL:            "/api/intake/settings",
+				Body:           s(map[string]any{"enabled": true, "type": "incident"}),
+			},
+			userTests: []userTest{
+				{
+					Name:            "NoAuth",
+					ExpectedStatus:  http.StatusUnauthorized,
+					ExpectedContent: []string{`"invalid bearer token"`},
+				},
+				{
+					Name:            "Analyst",
+					AuthRecord:      data.AnalystEmail,
+					ExpectedStatus:  http.StatusUnauthorized,
+					ExpectedContent: []string{`"missing required scopes"`},
+				},
+				{
+					Name:            "Admin",
+					Admin:           data.AdminEmail,
+					ExpectedStatus:  http.StatusOK,
+					ExpectedContent: []string{`"enabled":true`},
+				},
+			},
+		},
+		{
+			baseTest: baseTest{
+				Name:           "UpdateSlackSettings",
+				Method:         http.MethodPost,
+				RequestHeaders: map[string]string{"Content-Type": "application/json"},
+				URL:            "/api/slack/settings",
+				Body: s(map[string]any{
+					"enabled":        true,
+					"signing_secret": "secret",
+					"bot_token":      "xoxb-token",
+					"ticket_type":    "incident",
+				}),
+			},
+			userTests: []userTest{
+				{
+					Name:            "NoAuth",
+					ExpectedStatus:  http.StatusUnauthorized,
+					ExpectedContent: []string{`"invalid bearer token"`},
+				},
+				{
+					Name:            "Analyst",
+					AuthRecord:      data.AnalystEmail,
+					ExpectedStatus:  http.StatusUnauthorized,
+					ExpectedContent: []string{`"missing required scopes"`},
+				},
+				{
+					Name:            "Admin",
+					Admin:           data.AdminEmail,
+					ExpectedStatus:  http.StatusOK,
+					ExpectedContent: []string{`"bot_token":"********"`, `"ticket_type":"incident"`},
+				},
+			},
+		},
+		{
+			baseTest: baseTest{
+				Name:           "UpdateMetricsExportSettings",
+				Method:         http.MethodPost,
+				RequestHeaders: map[string]string{"Content-Type": "application/json"},
+				URL:            "/api/export/settings",
+				Body: s(map[string]any{
+					"enabled":           true,
+					"interval":          12,
+					"endpoint":          "https://s3.eu-central-1.amazonaws.com",
+					"region":            "eu-central-1",
+					"bucket":            "soc-metrics",
+					"prefix":            "catalyst",
+					"access_key_id":     "AKIDEXAMPLE",
+					"secret_access_key": "secret",
+				}),
+			},
+			userTests: []userTest{
+				{
+					Name:            "NoAuth",
+					ExpectedStatus:  http.StatusUnauthorized,
+					ExpectedContent: []string{`"invalid bearer token"`},
+				},
+				{
+					Name:            "Analyst",
+					AuthRecord:      data.AnalystEmail,
+					ExpectedStatus:  http.StatusUnauthorized,
+					ExpectedContent: []string{`"missing required scopes"`},
+				},
+				{
+					Name:            "Admin",
+					Admin:           data.AdminEmail,
+					ExpectedStatus:  http.StatusOK,
+					ExpectedContent: []string{`"secret_access_key":"********"`, `"interval":12`},
+				},
+			},
+		},
+		{
+			baseTest: baseTest{
+				Name:   "RunMetricsExportDisabled",
+				Method: http.MethodPost,
+				URL:    "/api/export/run",
+			},
+			userTests: []userTest{
+				{
+					Name:            "Admin",
+					Admin:           data.AdminEmail,
+					ExpectedStatus:  http.StatusNotFound,
+					ExpectedContent: []string{`"The metrics export is disabled"`},
+				},
+			},
+		},
+		{
+			baseTest: baseTest{
+				Name:   "GetPlatformTicketSettings",
+				Method: http.MethodGet,
+				URL:    "/api/platform/settings",
+			},
+			userTests: []userTest{
+				{
+					Name:            "Analyst",
+					AuthRecord:      data.AnalystEmail,
+					ExpectedStatus:  http.StatusUnauthorized,
+					ExpectedContent: []string{`"missing required scopes"`},
+				},
+				{
+					Name:            "Admin",
+					Admin:           data.AdminEmail,
+					ExpectedStatus:  http.StatusOK,
+					ExpectedContent: []string{`"enabled":false`, `"ticket_type":"platform"`},
+				},
+			},
+		},
+		{
+			baseTest: baseTest{
+				Name:           "UpdatePlatformTicketSettings",
+				Method:         http.MethodPost,
+				RequestHeaders: map[string]string{"Content-Type": "application/json"},
+				URL:            "/api/platform/settings",
+				Body:           s(map[string]any{"enabled": true, "ticket_type": ""}),
+			},
+			userTests: []userTest{
+				{
+					Name:            "Analyst",
+					AuthRecord:      data.AnalystEmail,
+					ExpectedStatus:  http.StatusUnauthorized,
+					ExpectedContent: []string{`"missing required scopes"`},
+				},
+				{
+					Name:            "Admin",
+					Admin:           data.AdminEmail,
+					ExpectedStatus:  http.StatusOK,
+					ExpectedContent: []string{`"enabled":true`, `"ticket_type":"platform"`},
+					ExpectedEvents:  map[string]int{},
+				},
+			},
+		},
+		{
+			baseTest: baseTest{
+				Name:           "UpdateAnomalySettings",
+				Method:         http.MethodPost,
+				RequestHeaders: map[string]string{"Content-Type": "application/json"},
+				URL:            "/api/anomaly/settings",
+				Body: s(map[string]any{
+					"enabled":       true,
+					"ticket_type":   "alert",
+					"sensitivity":   2.5,
+					"min_count":     0,
+					"silence_hours": 12,
+				}),
+			},
+			userTests: []userTest{
+				{
+					Name:            "NoAuth",
+					ExpectedStatus:  http.StatusUnauthorized,
+					ExpectedContent: []string{`"invalid bearer token"`},
+				},
+				{
+					Name:            "Analyst",
+					AuthRecord:      data.AnalystEmail,
+					ExpectedStatus:  http.StatusUnauthorized,
+					ExpectedContent: []string{`"missing required scopes"`},
+				},
+				{
+					Name:            "Admin",
+					Admin:           data.AdminEmail,
+					ExpectedStatus:  http.StatusOK,
+					ExpectedContent: []string{`"ticket_type":"alert"`, `"sensitivity":2.5`, `"min_count":5`, `"silence_hours":12`},
+				},
+			},
+		},
+		{
+			baseTest: baseTest{
+				Name:   "GetPasswordPolicy",
+				Method: http.MethodGet,
+				URL:    "/api/password/policy",
+			},
+			userTests: []userTest{
+				{
+					Name:            "Analyst",
+					AuthRecord:      data.AnalystEmail,
+					ExpectedStatus:  http.StatusUnauthorized,
+					ExpectedContent: []string{`"missing required scopes"`},
+				},
+				{
+					Name:            "Admin",
+					Admin:           data.AdminEmail,
+					ExpectedStatus:  http.StatusOK,
+					ExpectedContent: []string{`"min_length":8`, `"breach_check":false`, `"breach_check_url":"https://api.pwnedpasswords.com"`},
+				},
+			},
+		},
+		{
+			baseTest: baseTest{
+				Name:           "UpdatePasswordPolicy",
+				Method:         http.MethodPost,
+				RequestHeaders: map[string]string{"Content-Type": "application/json"},
+				URL:            "/api/password/policy",
+				Body: s(map[string]any{
+					"min_length":        12,
+					"require_uppercase": true,
+					"require_lowercase": true,
+					"require_digit":     true,
+					"require_symbol":    false,
+					"history":           5,
+					"max_age":           90,
+					"breach_check":      false,
+					"breach_check_url":  "",
+				}),
+			},
+			userTests: []userTest{
+				{
+					Name:            "Analyst",
+					AuthRecord:      data.AnalystEmail,
+					ExpectedStatus:  http.StatusUnauthorized,
+					ExpectedContent: []string{`"missing required scopes"`},
+				},
+				{
+					Name:            "Admin",
+					Admin:           data.AdminEmail,
+					ExpectedStatus:  http.StatusOK,
+					ExpectedContent: []string{`"min_length":12`, `"history":5`, `"max_age":90`},
+				},
+			},
+		},
+		{
+			baseTest: baseTest{
+				Name:           "UpdateUserWeakPassword",
+				Method:         http.MethodPatch,
+				RequestHeaders: map[string]string{"Content-Type": "application/json"},
+				URL:            "/api/users/u_bob_analyst",
+				Body:           s(map[string]any{"password": "short", "passwordConfirm": "short"}),
+			},
+			userTests: []userTest{
+				{
+					Name:            "Admin",
+					Admin:           data.AdminEmail,
+					ExpectedStatus:  http.StatusBadRequest,
+					ExpectedContent: []string{`must be at least 8 characters long`},
+				},
+			},
+		},
+		{
+			baseTest: baseTest{
+				Name:   "PreviewUserDeactivation",
+				Method: http.MethodGet,
+				URL:    "/api/users/u_bob_analyst/deactivation",
+			},
+			userTests: []userTest{
+				{
+					Name:            "Analyst",
+					AuthRecord:      data.AnalystEmail,
+					ExpectedStatus:  http.StatusUnauthorized,
+					ExpectedContent: []string{`"missing required scopes"`},
+				},
+				{
+					Name:            "Admin",
+					Admin:           data.AdminEmail,
+					ExpectedStatus:  http.StatusOK,
+					ExpectedContent: []string{`"tickets":["test-ticket"]`, `"tasks":["k_test_task"]`},
+				},
+			},
+		},
+		{
+			baseTest: baseTest{
+				Name:           "DeactivateUser",
+				Method:         http.MethodPost,
+				RequestHeaders: map[string]string{"Content-Type": "application/json"},
+				URL:            "/api/users/u_bob_analyst/deactivation",
+				Body:           s(map[string]any{"reassign_user": "u_admin"}),
+			},
+			userTests: []userTest{
+				{
+					Name:            "Analyst",
+					AuthRecord:      data.AnalystEmail,
+					ExpectedStatus:  http.StatusUnauthorized,
+					ExpectedContent: []string{`"missing required scopes"`},
+				},
+				{
+					Name:            "Admin",
+					Admin:           data.AdminEmail,
+					ExpectedStatus:  http.StatusOK,
+					ExpectedContent: []string{`"tickets":["test-ticket"]`, `"tasks":["k_test_task"]`, `"digest":false`},
+					ExpectedEvents:  map[string]int{"OnRecordBeforeUpdateRequest": 1, "OnRecordAfterUpdateRequest": 1},
+				},
+			},
+		},
+		{
+			baseTest: baseTest{
+				Name:           "DeactivateUserToUnknownGroup",
+				Method:         http.MethodPost,
+				RequestHeaders: map[string]string{"Content-Type": "application/json"},
+				URL:            "/api/users/u_bob_analyst/deactivation",
+				Body:           s(map[string]any{"reassign_group": "unknown"}),
+			},
+			userTests: []userTest{
+				{
+					Name:            "Admin",
+					Admin:           data.AdminEmail,
+					ExpectedStatus:  http.StatusBadRequest,
+					ExpectedContent: []string{`group \"unknown\" does not exist`},
+				},
+			},
+		},
+		{
+			baseTest: baseTest{
+				Name:   "ListUserAPIUsage",
+				Method: http.MethodGet,
+				URL:    "/api/admin/usage/users",
+			},
+			userTests: []userTest{
+				{
+					Name:            "Analyst",
+					AuthRecord:      data.AnalystEmail,
+					ExpectedStatus:  http.StatusUnauthorized,
+					ExpectedContent: []string{`"missing required scopes"`},
+				},
+				{
+					// the request is counted, but stored in the background
+					Name:            "Admin",
+					Admin:           data.AdminEmail,
+					ExpectedStatus:  http.StatusOK,
+					ExpectedContent: []string{`[]`},
+				},
+			},
+		},
+		{
+			baseTest: baseTest{
+				Name:           "SetAPIQuota",
+				Method:         http.MethodPut,
+				RequestHeaders: map[string]string{"Content-Type": "application/json"},
+				URL:            "/api/admin/usage/quotas",
+				Body:           s(map[string]any{"user": "u_bob_analyst", "daily_limit": 1000}),
+			},
+			userTests: []userTest{
+				{
+					Name:            "Analyst",
+					AuthRecord:      data.AnalystEmail,
+					ExpectedStatus:  http.StatusUnauthorized,
+					ExpectedContent: []string{`"missing required scopes"`},
+				},
+				{
+					Name:            "Admin",
+					Admin:           data.AdminEmail,
+					ExpectedStatus:  http.StatusOK,
+					ExpectedContent: []string{`"user":"u_bob_analyst"`, `"daily_limit":1000`},
+				},
+			},
+		},
+		{
+			baseTest: baseTest{
+				Name:           "SetAPIQuotaUnknownUser",
+				Method:         http.MethodPut,
+				RequestHeaders: map[string]string{"Content-Type": "application/json"},
+				URL:            "/api/admin/usage/quotas",
+				Body:           s(map[string]any{"user": "u_unknown", "daily_limit": 1000}),
+			},
+			userTests: []userTest{
+				{
+					Name:            "Admin",
+					Admin:           data.AdminEmail,
+					ExpectedStatus:  http.StatusBadRequest,
+					ExpectedContent: []string{`does not exist`},
+				},
+			},
+		},
+		{
+			baseTest: baseTest{
+				Name:   "DeleteAPIQuota",
+				Method: http.MethodDelete,
+				URL:    "/api/admin/usage/quotas?user=u_bob_analyst",
+			},
+			userTests: []userTest{
+				{
+					Name:            "Analyst",
+					AuthRecord:      data.AnalystEmail,
+					ExpectedStatus:  http.StatusUnauthorized,
+					ExpectedContent: []string{`"missing required scopes"`},
+				},
+				{
+					Name:           "Admin",
+					Admin:          data.AdminEmail,
+					ExpectedStatus: http.StatusNoContent,
+				},
+			},
+		},
+		{
+			baseTest: baseTest{
+				Name:   "ListLogs",
+				Method: http.MethodGet,
+				URL:    "/api/logs?level=error&component=reaction",
+			},
+			userTests: []userTest{
+				{
+					Name:            "Analyst",
+					AuthRecord:      data.AnalystEmail,
+					ExpectedStatus:  http.StatusUnauthorized,
+					ExpectedContent: []string{`"missing required scopes"`},
+				},
+				{
+					Name:            "Admin",
+					Admin:           data.AdminEmail,
+					ExpectedStatus:  http.StatusOK,
+					ExpectedHeaders: map[string]string{"X-Total-Count": "0"},
+					ExpectedContent: []string{`[]`},
+				},
+			},
+		},
+		{
+			baseTest: baseTest{
+				Name:   "ListLogsInvalidLevel",
+				Method: http.MethodGet,
+				URL:    "/api/logs?level=verbose",
+			},
+			userTests: []userTest{
+				{
+					Name:            "Admin",
+					Admin:           data.AdminEmail,
+					ExpectedStatus:  http.StatusBadRequest,
+					ExpectedContent: []string{`invalid log level`},
+				},
+			},
+		},
+		{
+			baseTest: baseTest{
+				Name:   "GetLogSettings",
+				Method: http.MethodGet,
+				URL:    "/api/logs/settings",
+			},
+			userTests: []userTest{
+				{
+					Name:            "Analyst",
+					AuthRecord:      data.AnalystEmail,
+					ExpectedStatus:  http.StatusUnauthorized,
+					ExpectedContent: []string{`"missing required scopes"`},
+				},
+				{
+					Name:            "Admin",
+					Admin:           data.AdminEmail,
+					ExpectedStatus:  http.StatusOK,
+					ExpectedContent: []string{`"level":"warn"`, `"retention_days":14`},
+				},
+			},
+		},
+		{
+			baseTest: baseTest{
+				Name:           "UpdateLogSettings",
+				Method:         http.MethodPost,
+				RequestHeaders: map[string]string{"Content-Type": "application/json"},
+				URL:            "/api/logs/settings",
+				Body:           s(map[string]any{"level": "debug", "retention_days": 30}),
+			},
+			userTests: []userTest{
+				{
+					Name:            "Analyst",
+					AuthRecord:      data.AnalystEmail,
+					ExpectedStatus:  http.StatusUnauthorized,
+					ExpectedContent: []string{`"missing required scopes"`},
+				},
+				{
+					Name:            "Admin",
+					Admin:           data.AdminEmail,
+					ExpectedStatus:  http.StatusOK,
+					ExpectedContent: []string{`"level":"debug"`, `"retention_days":30`},
+				},
+			},
+		},
+		{
+			baseTest: baseTest{
+				Name:   "GetLogSinks",
+				Method: http.MethodGet,
+				URL:    "/api/logs/sinks",
+			},
+			userTests: []userTest{
+				{
+					Name:            "Analyst",
+					AuthRecord:      data.AnalystEmail,
+					ExpectedStatus:  http.StatusUnauthorized,
+					ExpectedContent: []string{`"missing required scopes"`},
+				},
+				{
+					Name:            "Admin",
+					Admin:           data.AdminEmail,
+					ExpectedStatus:  http.StatusOK,
+					ExpectedContent: []string{`[]`},
+				},
+			},
+		},
+		{
+			baseTest: baseTest{
+				Name:           "UpdateLogSinks",
+				Method:         http.MethodPost,
+				RequestHeaders: map[string]string{"Content-Type": "application/json"},
+				URL:            "/api/logs/sinks",
+				Body:           `[{"name":"siem","type":"http","streams":["audit"],"url":"https://siem.example.com/collector","headers":{"Authorization":"Bearer token"}}]`,
+			},
+			userTests: []userTest{
+				{
+					Name:            "Analyst",
+					AuthRecord:      data.AnalystEmail,
+					ExpectedStatus:  http.StatusUnauthorized,
+					ExpectedContent: []string{`"missing required scopes"`},
+				},
+				{
+					Name:            "Admin",
+					Admin:           data.AdminEmail,
+					ExpectedStatus:  http.StatusOK,
+					ExpectedContent: []string{`"name":"siem"`, `"headers":{"Authorization":"********"}`},
+				},
+			},
+		},
+		{
+			baseTest: baseTest{
+				Name:           "UpdateLogSinksInvalid",
+				Method:         http.MethodPost,
+				RequestHeaders: map[string]string{"Content-Type": "application/json"},
+				URL:            "/api/logs/sinks",
+				Body:           `[{"name":"siem","type":"syslog","streams":[],"address":"siem.example.com"}]`,
+			},
+			userTests: []userTest{
+				{
+					Name:            "Admin",
+					Admin:           data.AdminEmail,
+					ExpectedStatus:  http.StatusBadRequest,
+					ExpectedContent: []string{`logSinks[0].address`},
+				},
+			},
+		},
+		{
+			baseTest: baseTest{
+				Name:   "GetCorsSettings",
+				Method: http.MethodGet,
+				URL:    "/api/cors/settings",
+			},
+			userTests: []userTest{
+				{
+					Name:            "Analyst",
+					AuthRecord:      data.AnalystEmail,
+					ExpectedStatus:  http.StatusUnauthorized,
+					ExpectedContent: []string{`"missing required scopes"`},
+				},
+				{
+					Name:            "Admin",
+					Admin:           data.AdminEmail,
+					ExpectedStatus:  http.StatusOK,
+					ExpectedContent: []string{`"allowed_origins":[]`, `"allow_credentials":false`},
+				},
+			},
+		},
+		{
+			baseTest: baseTest{
+				Name:           "UpdateCorsSettings",
+				Method:         http.MethodPost,
+				RequestHeaders: map[string]string{"Content-Type": "application/json"},
+				URL:            "/api/cors/settings",
+				Body: s(map[string]any{
+					"allowed_origins":   []string{"https://portal.example.com"},
+					"allowed_headers":   []string{"Authorization", "Content-Type"},
+					"allow_credentials": true,
+					"max_age":           600,
+				}),
+			},
+			userTests: []userTest{
+				{
+					Name:            "Analyst",
+					AuthRecord:      data.AnalystEmail,
+					ExpectedStatus:  http.StatusUnauthorized,
+					ExpectedContent: []string{`"missing required scopes"`},
+				},
+				{
+					Name:            "Admin",
+					Admin:           data.AdminEmail,
+					ExpectedStatus:  http.StatusOK,
+					ExpectedContent: []string{`"allowed_origins":["https://portal.example.com"]`, `"allow_credentials":true`, `"max_age":600`},
+				},
+			},
+		},
+		{
+			baseTest: baseTest{
+				Name:           "UpdateCorsSettingsWildcardCredentials",
+				Method:         http.MethodPost,
+				RequestHeaders: map[string]string{"Content-Type": "application/json"},
+				URL:            "/api/cors/settings",
+				Body: s(map[string]any{
+					"allowed_origins":   []string{"*"},
+					"allowed_headers":   []string{},
+					"allow_credentials": true,
+					"max_age":           0,
+				}),
+			},
+			userTests: []userTest{
+				{
+					Name:            "Admin",
+					Admin:           data.AdminEmail,
+					ExpectedStatus:  http.StatusBadRequest,
+					ExpectedContent: []string{`must not be combined with allowCredentials`},
+				},
+			},
+		},
+		{
+			baseTest: baseTest{
+				Name:           "UpdateRateLimits",
+				Method:         http.MethodPost,
+				RequestHeaders: map[string]string{"Content-Type": "application/json"},
+				URL:            "/api/rate_limits",
+				Body:           `[{"host":"www.virustotal.com","requests":4,"period":60},{"reaction":"r-test-webhook","requests":100,"period":3600}]`,
+			},
+			userTests: []userTest{
+				{
+					Name:            "Analyst",
+					AuthRecord:      data.AnalystEmail,
+					ExpectedStatus:  http.StatusUnauthorized,
+					ExpectedContent: []string{`"missing required scopes"`},
+				},
+				{
+					Name:            "Admin",
+					Admin:           data.AdminEmail,
+					ExpectedStatus:  http.StatusOK,
+					ExpectedContent: []string{`{"host":"www.virustotal.com","period":60,"requests":4}`, `"reaction":"r-test-webhook"`},
+				},
+			},
+		},
+		{
+			baseTest: baseTest{
+				Name:           "UpdateRateLimitsInvalid",
+				Method:         http.MethodPost,
+				RequestHeaders: map[string]string{"Content-Type": "application/json"},
+				URL:            "/api/rate_limits",
+				Body:           `[{"requests":4,"period":60}]`,
+			},
+			userTests: []userTest{
+				{
+					Name:            "Admin",
+					Admin:           data.AdminEmail,
+					ExpectedStatus:  http.StatusInternalServerError,
+					ExpectedContent: []string{`rateLimits[0] must either set a host or a reaction`},
+				},
+			},
+		},
+		{
+			baseTest: baseTest{
+				Name:           "SetEnrichment",
+				Method:         http.MethodPut,
+				RequestHeaders: map[string]string{"Content-Type": "application/json"},
+				URL:            "/api/enrichment/cache/virustotal",
+				Body:           s(map[string]any{"value": "Example[.]COM", "result": map[string]any{"malicious": 3}}),
+			},
+			userTests: []userTest{
+				{
+					Name:            "NoAuth",
+					ExpectedStatus:  http.StatusUnauthorized,
+					ExpectedContent: []string{`"invalid bearer token"`},
+				},
+				{
+					Name:            "Analyst",
+					AuthRecord:      data.AnalystEmail,
+					ExpectedStatus:  http.StatusOK,
+					ExpectedContent: []string{`"value":"example.com"`, `"result":{"malicious":3}`},
+				},
+			},
+		},
+		{
+			baseTest: baseTest{
+				Name:   "GetEnrichmentNotCached",
+				Method: http.MethodGet,
+				URL:    "/api/enrichment/cache/virustotal?value=example.com",
+			},
+			userTests: []userTest{
+				{
+					Name:            "Analyst",
+					AuthRecord:      data.AnalystEmail,
+					ExpectedStatus:  http.StatusNotFound,
+					ExpectedContent: []string{`"The enrichment result is not cached"`},
+				},
+			},
+		},
+		{
+			baseTest: baseTest{
+				Name:           "UpdateEnrichmentSettings",
+				Method:         http.MethodPost,
+				RequestHeaders: map[string]string{"Content-Type": "application/json"},
+				URL:            "/api/enrichment/settings",
+				Body:           s(map[string]any{"default_ttl": 0, "ttls": map[string]any{"virustotal": 3600}}),
+			},
+			userTests: []userTest{
+				{
+					Name:            "Analyst",
+					AuthRecord:      data.AnalystEmail,
+					ExpectedStatus:  http.StatusUnauthorized,
+					ExpectedContent: []string{`"missing required scopes"`},
+				},
+				{
+					Name:            "Admin",
+					Admin:           data.AdminEmail,
+					ExpectedStatus:  http.StatusOK,
+					ExpectedContent: []string{`"default_ttl":86400`, `"ttls":{"virustotal":3600}`},
+				},
+			},
+		},
+		{
+			baseTest: baseTest{
+				Name:           "Canonicalize",
+				Method:         http.MethodPost,
+				RequestHeaders: map[string]string{"Content-Type": "application/json"},
+				URL:            "/api/canonicalize",
+				Body:           s(map[string]any{"values": []string{"Example.COM", "example[.]com", "2001:DB8::1"}}),
+			},
+			userTests: []userTest{
+				{
+					Name:            "NoAuth",
+					ExpectedStatus:  http.StatusUnauthorized,
+					ExpectedContent: []string{`"invalid bearer token"`},
+				},
+				{
+					Name:            "Analyst",
+					AuthRecord:      data.AnalystEmail,
+					ExpectedStatus:  http.StatusOK,
+					ExpectedContent: []string{`"value":"example.com"`, `"originals":["Example.COM","example[.]com"]`, `"value":"2001:db8::1"`},
+				},
+			},
+		},
+		{
+			baseTest: baseTest{
+				Name:           "CreatePushSubscription",
+				Method:         http.MethodPost,
+				RequestHeaders: map[string]string{"Content-Type": "application/json"},
+				URL:            "/api/push/subscriptions",
+				Body: s(map[string]any{
+					"endpoint": "https://push.example.com/send/abc",
+					"keys": map[string]any{
+						"p256dh": "BCO0zKiIMT6Z3ZsACMcalpBCVOYDuGZ-vs6ZPB_L5J4Yqaxr6F2q9-V2ywmqkci-amQJ5LtR_RMmuAqxoJZbd6Q",
+						"auth":   "8eDyX_uCN0XRhSbY5hs7Hg",
+					},
+				}),
+			},
+			userTests: []userTest{
+				{
+					Name:            "NoAuth",
+					ExpectedStatus:  http.StatusUnauthorized,
+					ExpectedContent: []string{`"invalid bearer token"`},
+				},
+				{
+					Name:            "Analyst",
+					AuthRecord:      data.AnalystEmail,
+					ExpectedStatus:  http.StatusOK,
+					ExpectedContent: []string{`"endpoint":"https://push.example.com/send/abc"`},
+				},
+				{
+					Name:            "Admin",
+					Admin:           data.AdminEmail,
+					ExpectedStatus:  http.StatusOK,
+					ExpectedContent: []string{`"endpoint":"https://push.example.com/send/abc"`},
+				},
+			},
+		},
+		{
+			baseTest: baseTest{
+				Name:           "UpdateDigestSettings",
+				Method:         http.MethodPost,
+				RequestHeaders: map[string]string{"Content-Type": "application/json"},
+				URL:            "/api/digest/settings",
+				Body:           s(map[string]any{"frequency": "daily"}),
+			},
+			userTests: []userTest{
+				{
+					Name:            "NoAuth",
+					ExpectedStatus:  http.StatusUnauthorized,
+					ExpectedContent: []string{`"invalid bearer token"`},
+				},
+				{
+					Name:            "Analyst",
+					AuthRecord:      data.AnalystEmail,
+					ExpectedStatus:  http.StatusOK,
+					ExpectedContent: []string{`"frequency":"daily"`},
+				},
+				{
+					Name:            "Admin",
+					Admin:           data.AdminEmail,
+					ExpectedStatus:  http.StatusOK,
+					ExpectedContent: []string{`"frequency":"daily"`},
+				},
+			},
+		},
+		{
+			baseTest: baseTest{
+				Name:           "UpdateBranding",
+				Method:         http.MethodPost,
+				RequestHeaders: map[string]string{"Content-Type": "application/json"},
+				URL:            "/api/branding",
+				Body: s(map[string]any{
+					"title":        "ACME SOC",
+					"logo":         "https://example.com/logo.svg",
+					"login_banner": "Authorized use only",
+					"custom_css":   "body { color: red; }",
+				}),
+			},
+			userTests: []userTest{
+				{
+					Name:            "NoAuth",
+					ExpectedStatus:  http.StatusUnauthorized,
+					ExpectedContent: []string{`"invalid bearer token"`},
+				},
+				{
+					Name:            "Analyst",
+					AuthRecord:      data.AnalystEmail,
+					ExpectedStatus:  http.StatusUnauthorized,
+					ExpectedContent: []string{`"missing required scopes"`},
+				},
+				{
+					Name:            "Admin",
+					Admin:           data.AdminEmail,
+					ExpectedStatus:  http.StatusOK,
+					ExpectedContent: []string{`"title":"ACME SOC"`},
+				},
+			},
+		},
 	}
 	for _, testSet := range testSets {
 		t.Run(testSet.baseTest.Name, func(t *testing.T) {
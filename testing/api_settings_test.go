@@ -0,0 +1,71 @@
+package testing
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestEffectiveSettings sets environment variables and cannot run in
+// parallel.
+func TestEffectiveSettings(t *testing.T) { //nolint:paralleltest
+	t.Setenv("CATALYST_CVE_ENRICHMENT_NVD_URL", "https://nvd.example.com")
+	t.Setenv("CATALYST_CVE_ENRICHMENT_NVD_API_KEY", "nvd-api-key")
+	t.Setenv("CATALYST_BACKUP_NOTIFICATION_SLACK", "true")
+	t.Setenv("CATALYST_BACKUP_NOTIFICATION_HEADERS", `{"Authorization":"Bearer notification-token"}`)
+
+	baseApp, cleanup, _ := App(t)
+	t.Cleanup(cleanup)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/settings/effective", nil)
+	req.Header.Set("Authorization", "Bearer "+adminToken(t, baseApp))
+
+	recorder := httptest.NewRecorder()
+	baseApp.ServeHTTP(recorder, req)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+
+	body := recorder.Body.String()
+	assert.NotContains(t, body, "nvd-api-key")
+	assert.NotContains(t, body, "notification-token")
+
+	var effective struct {
+		Settings struct {
+			Meta struct {
+				AppURL string `json:"appUrl"`
+			} `json:"meta"`
+			RecordAuthToken struct {
+				Secret string `json:"secret"`
+			} `json:"recordAuthToken"`
+			CVEEnrichment struct {
+				NVDURL    string `json:"nvdUrl"`
+				NVDAPIKey string `json:"nvdApiKey"`
+			} `json:"cveEnrichment"`
+			BackupNotification struct {
+				URL     string            `json:"url"`
+				Slack   bool              `json:"slack"`
+				Headers map[string]string `json:"headers"`
+			} `json:"backupNotification"`
+		} `json:"settings"`
+		Overrides []string `json:"overrides"`
+	}
+
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &effective))
+
+	assert.NotEmpty(t, effective.Settings.Meta.AppURL)
+	assert.Equal(t, "********", effective.Settings.RecordAuthToken.Secret)
+	assert.Equal(t, "https://nvd.example.com", effective.Settings.CVEEnrichment.NVDURL)
+	assert.Equal(t, "********", effective.Settings.CVEEnrichment.NVDAPIKey)
+	assert.Empty(t, effective.Settings.BackupNotification.URL, "unset secrets stay empty")
+	assert.True(t, effective.Settings.BackupNotification.Slack)
+	assert.Equal(t, map[string]string{"Authorization": "********"}, effective.Settings.BackupNotification.Headers)
+	assert.ElementsMatch(t, []string{
+		"CATALYST_CVE_ENRICHMENT_NVD_URL",
+		"CATALYST_CVE_ENRICHMENT_NVD_API_KEY",
+		"CATALYST_BACKUP_NOTIFICATION_SLACK",
+		"CATALYST_BACKUP_NOTIFICATION_HEADERS",
+	}, effective.Overrides)
+}
@@ -0,0 +1,135 @@
+package testing
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/websocket"
+
+	"github.com/SecurityBrewery/catalyst/app"
+)
+
+func TestSocketToken(t *testing.T) {
+	t.Parallel()
+
+	baseApp, cleanup, _ := App(t)
+	t.Cleanup(cleanup)
+
+	server := httptest.NewServer(baseApp)
+	t.Cleanup(server.Close)
+
+	token := adminToken(t, baseApp)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/backups?async=true", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	recorder := httptest.NewRecorder()
+	baseApp.ServeHTTP(recorder, req)
+	require.Equal(t, http.StatusAccepted, recorder.Code, recorder.Body.String())
+
+	var job struct {
+		ID string `json:"id"`
+	}
+
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &job))
+
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/api/backup/jobs/" + job.ID + "/progress"
+
+	t.Run("UI", func(t *testing.T) {
+		t.Parallel()
+
+		socketToken := newSocketToken(t, baseApp, token)
+
+		// the UI connects from the origin of the server
+		conn, err := dialSocket(url+"?token="+socketToken, server.URL)
+		require.NoError(t, err)
+
+		t.Cleanup(func() { conn.Close() })
+
+		require.NoError(t, conn.SetReadDeadline(time.Now().Add(10*time.Second)))
+
+		var message struct {
+			ID string `json:"id"`
+		}
+
+		require.NoError(t, websocket.JSON.Receive(conn, &message))
+		assert.Equal(t, job.ID, message.ID)
+
+		// the token is used up
+		_, err = dialSocket(url+"?token="+socketToken, server.URL)
+		require.Error(t, err)
+	})
+
+	t.Run("ForeignOrigin", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := dialSocket(url+"?token="+newSocketToken(t, baseApp, token), "https://attacker.example.com")
+		require.Error(t, err)
+	})
+
+	t.Run("AccessToken", func(t *testing.T) {
+		t.Parallel()
+
+		// an access token in the URL could leak with it
+		_, err := dialSocket(url+"?token="+token, server.URL)
+		require.Error(t, err)
+	})
+
+	t.Run("LogTail", func(t *testing.T) {
+		t.Parallel()
+
+		tail := "ws" + strings.TrimPrefix(server.URL, "http") + "/api/logs/tail?level=error"
+
+		conn, err := dialSocket(tail+"&token="+newSocketToken(t, baseApp, token), server.URL)
+		require.NoError(t, err)
+
+		conn.Close()
+
+		_, err = dialSocket(tail+"&token="+newSocketToken(t, baseApp, token), "https://attacker.example.com")
+		require.Error(t, err)
+	})
+
+	t.Run("WithoutToken", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := dialSocket(url, server.URL)
+		require.Error(t, err)
+	})
+}
+
+func newSocketToken(t *testing.T, baseApp *app.App, token string) string {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodPost, "/auth/socket-token", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	recorder := httptest.NewRecorder()
+	baseApp.ServeHTTP(recorder, req)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	assert.Equal(t, "no-store", recorder.Header().Get("Cache-Control"))
+
+	var body struct {
+		Token     string `json:"token"`
+		ExpiresIn int    `json:"expires_in"`
+	}
+
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+	assert.Equal(t, 30, body.ExpiresIn)
+
+	return body.Token
+}
+
+func dialSocket(url, origin string) (*websocket.Conn, error) {
+	config, err := websocket.NewConfig(url, origin)
+	if err != nil {
+		return nil, err
+	}
+
+	return websocket.DialConfig(config)
+}
@@ -205,6 +205,84 @@ func TestTasksCollection(t *testing.T) {
 				},
 			},
 		},
+		{
+			baseTest: baseTest{
+				Name:           "SetTaskTimer",
+				Method:         http.MethodPut,
+				RequestHeaders: map[string]string{"Content-Type": "application/json"},
+				URL:            "/api/tasks/k_test_task/timer",
+				Body:           s(map[string]any{"timeout": 60, "action": "escalate"}),
+			},
+			userTests: []userTest{
+				{
+					Name:            "Unauthorized",
+					ExpectedStatus:  http.StatusUnauthorized,
+					ExpectedContent: []string{`"invalid bearer token"`},
+				},
+				{
+					Name:            "Analyst",
+					AuthRecord:      data.AnalystEmail,
+					ExpectedStatus:  http.StatusOK,
+					ExpectedContent: []string{`"task":"k_test_task"`, `"action":"escalate"`},
+					ExpectedEvents:  map[string]int{},
+				},
+			},
+		},
+		{
+			baseTest: baseTest{
+				Name:           "SetTaskOutput",
+				Method:         http.MethodPut,
+				RequestHeaders: map[string]string{"Content-Type": "application/json"},
+				URL:            "/api/tasks/k_test_task/output",
+				Body:           s(map[string]any{"name": "lookup", "output": map[string]any{"score": 85}}),
+			},
+			userTests: []userTest{
+				{
+					Name:            "Unauthorized",
+					ExpectedStatus:  http.StatusUnauthorized,
+					ExpectedContent: []string{`"invalid bearer token"`},
+				},
+				{
+					Name:            "Analyst",
+					AuthRecord:      data.AnalystEmail,
+					ExpectedStatus:  http.StatusOK,
+					ExpectedContent: []string{`"name":"lookup"`, `"output":{"score":85}`},
+					ExpectedEvents:  map[string]int{},
+				},
+			},
+		},
+		{
+			baseTest: baseTest{
+				Name:   "GetTaskOutputNotSet",
+				Method: http.MethodGet,
+				URL:    "/api/tasks/k_test_task/output",
+			},
+			userTests: []userTest{
+				{
+					Name:            "Analyst",
+					AuthRecord:      data.AnalystEmail,
+					ExpectedStatus:  http.StatusNotFound,
+					ExpectedContent: []string{`"The task has no output"`},
+					ExpectedEvents:  map[string]int{},
+				},
+			},
+		},
+		{
+			baseTest: baseTest{
+				Name:   "GetTaskTimerNotSet",
+				Method: http.MethodGet,
+				URL:    "/api/tasks/k_test_task/timer",
+			},
+			userTests: []userTest{
+				{
+					Name:            "Analyst",
+					AuthRecord:      data.AnalystEmail,
+					ExpectedStatus:  http.StatusNotFound,
+					ExpectedContent: []string{`"The task has no timer"`},
+					ExpectedEvents:  map[string]int{},
+				},
+			},
+		},
 	}
 
 	for _, testSet := range testSets {
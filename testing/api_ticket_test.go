@@ -11,6 +11,27 @@ func TestTicketsCollection(t *testing.T) {
 	t.Parallel()
 
 	testSets := []catalystTest{
+		{
+			baseTest: baseTest{
+				Name:   "ListTicketActivity",
+				Method: http.MethodGet,
+				URL:    "/api/tickets/test-ticket/activity",
+			},
+			userTests: []userTest{
+				{
+					Name:            "Unauthorized",
+					ExpectedStatus:  http.StatusUnauthorized,
+					ExpectedContent: []string{`"invalid bearer token"`},
+				},
+				{
+					Name:            "Analyst",
+					AuthRecord:      data.AnalystEmail,
+					ExpectedStatus:  http.StatusOK,
+					ExpectedContent: []string{`[]`},
+					ExpectedEvents:  map[string]int{},
+				},
+			},
+		},
 		{
 			baseTest: baseTest{
 				Name:   "ListTickets",
@@ -208,6 +229,77 @@ func TestTicketsCollection(t *testing.T) {
 				},
 			},
 		},
+		{
+			baseTest: baseTest{
+				Name:   "EncryptTicket",
+				Method: http.MethodPost,
+				URL:    "/api/tickets/test-ticket/encrypt",
+			},
+			userTests: []userTest{
+				{
+					Name:           "Unauthorized",
+					ExpectedStatus: http.StatusUnauthorized,
+					ExpectedContent: []string{
+						`"invalid bearer token"`,
+					},
+				},
+				{
+					Name:           "Analyst",
+					AuthRecord:     data.AnalystEmail,
+					ExpectedStatus: http.StatusOK,
+					ExpectedContent: []string{
+						`"id":"test-ticket"`,
+						`"encrypted":true`,
+					},
+					ExpectedEvents: map[string]int{
+						"OnRecordAfterUpdateRequest": 1,
+					},
+				},
+			},
+		},
+		{
+			baseTest: baseTest{
+				Name:           "EvaluateExpression",
+				Method:         http.MethodPost,
+				RequestHeaders: map[string]string{"Content-Type": "application/json"},
+				URL:            "/api/tickets/test-ticket/evaluate",
+				Body:           s(map[string]any{"expression": `ticket.id + "/" + ticket.type`}),
+			},
+			userTests: []userTest{
+				{
+					Name:           "Unauthorized",
+					ExpectedStatus: http.StatusUnauthorized,
+					ExpectedContent: []string{
+						`"invalid bearer token"`,
+					},
+				},
+				{
+					Name:            "Analyst",
+					AuthRecord:      data.AnalystEmail,
+					ExpectedStatus:  http.StatusOK,
+					ExpectedContent: []string{`"value":"test-ticket/incident"`},
+					ExpectedEvents:  map[string]int{},
+				},
+			},
+		},
+		{
+			baseTest: baseTest{
+				Name:           "EvaluateInvalidExpression",
+				Method:         http.MethodPost,
+				RequestHeaders: map[string]string{"Content-Type": "application/json"},
+				URL:            "/api/tickets/test-ticket/evaluate",
+				Body:           s(map[string]any{"expression": `ticket.id +`}),
+			},
+			userTests: []userTest{
+				{
+					Name:            "Analyst",
+					AuthRecord:      data.AnalystEmail,
+					ExpectedStatus:  http.StatusBadRequest,
+					ExpectedContent: []string{`invalid expression`},
+					ExpectedEvents:  map[string]int{},
+				},
+			},
+		},
 	}
 
 	for _, testSet := range testSets {
@@ -0,0 +1,132 @@
+package testing
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/SecurityBrewery/catalyst/app"
+	"github.com/SecurityBrewery/catalyst/app/auth"
+	"github.com/SecurityBrewery/catalyst/app/data"
+)
+
+func TestTokenExchange(t *testing.T) {
+	t.Parallel()
+
+	baseApp, cleanup, _ := App(t)
+	t.Cleanup(cleanup)
+
+	subjectToken := adminToken(t, baseApp)
+
+	exchange := func(t *testing.T, form url.Values) *httptest.ResponseRecorder {
+		t.Helper()
+
+		form.Set("grant_type", "urn:ietf:params:oauth:grant-type:token-exchange")
+		form.Set("subject_token_type", "urn:ietf:params:oauth:token-type:access_token")
+
+		req := httptest.NewRequest(http.MethodPost, "/auth/token", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		recorder := httptest.NewRecorder()
+		baseApp.ServeHTTP(recorder, req)
+
+		return recorder
+	}
+
+	get := func(t *testing.T, token, target string) int {
+		t.Helper()
+
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+
+		recorder := httptest.NewRecorder()
+		baseApp.ServeHTTP(recorder, req)
+
+		return recorder.Code
+	}
+
+	t.Run("InvalidSubjectToken", func(t *testing.T) {
+		t.Parallel()
+
+		res := exchange(t, url.Values{"subject_token": {"invalid"}, "scope": {"ticket:read"}, "automation": {"r-test-webhook"}})
+		assert.Equal(t, http.StatusUnauthorized, res.Code)
+	})
+
+	t.Run("UnknownAutomation", func(t *testing.T) {
+		t.Parallel()
+
+		res := exchange(t, url.Values{"subject_token": {subjectToken}, "scope": {"ticket:read"}, "automation": {"r-unknown"}})
+		assert.Equal(t, http.StatusBadRequest, res.Code)
+		assert.Contains(t, res.Body.String(), "Unknown automation")
+	})
+
+	t.Run("ExceedingScope", func(t *testing.T) {
+		t.Parallel()
+
+		email := data.AnalystEmail
+
+		analyst, err := baseApp.Queries.UserByEmail(t.Context(), &email)
+		require.NoError(t, err)
+
+		token, err := auth.CreateAccessToken(t.Context(), &analyst, []string{"ticket:read"}, time.Hour, baseApp.Queries)
+		require.NoError(t, err)
+
+		res := exchange(t, url.Values{"subject_token": {token}, "scope": {"ticket:read user:write"}, "automation": {"r-test-webhook"}})
+		assert.Equal(t, http.StatusBadRequest, res.Code)
+		assert.Contains(t, res.Body.String(), "The scope exceeds the scopes of the subject token")
+	})
+
+	t.Run("TicketBound", func(t *testing.T) {
+		t.Parallel()
+
+		res := exchange(t, url.Values{"subject_token": {subjectToken}, "scope": {"ticket:read"}, "automation": {"r-test-webhook"}, "ticket": {"test-ticket"}})
+		require.Equal(t, http.StatusOK, res.Code, res.Body.String())
+		assert.Equal(t, "no-store", res.Header().Get("Cache-Control"))
+
+		var body struct {
+			AccessToken string `json:"access_token"`
+			TokenType   string `json:"token_type"`
+			ExpiresIn   int    `json:"expires_in"`
+			Scope       string `json:"scope"`
+		}
+
+		require.NoError(t, json.Unmarshal(res.Body.Bytes(), &body))
+
+		assert.Equal(t, "Bearer", body.TokenType)
+		assert.Equal(t, 900, body.ExpiresIn)
+		assert.Equal(t, "ticket:read", body.Scope)
+
+		assert.Equal(t, http.StatusOK, get(t, body.AccessToken, "/api/tickets/test-ticket"))
+		assert.Equal(t, http.StatusOK, get(t, body.AccessToken, "/api/comments?ticket=test-ticket"))
+		assert.Equal(t, http.StatusForbidden, get(t, body.AccessToken, "/api/tickets"))
+		assert.Equal(t, http.StatusForbidden, get(t, body.AccessToken, "/api/users"))
+
+		res = exchange(t, url.Values{"subject_token": {body.AccessToken}, "scope": {"ticket:read"}, "automation": {"r-test-webhook"}, "ticket": {"other-ticket"}})
+		assert.Equal(t, http.StatusBadRequest, res.Code)
+		assert.Contains(t, res.Body.String(), "The subject token is restricted to another ticket")
+	})
+}
+
+func adminToken(t *testing.T, baseApp *app.App) string {
+	t.Helper()
+
+	email := data.AdminEmail
+
+	user, err := baseApp.Queries.UserByEmail(t.Context(), &email)
+	require.NoError(t, err)
+
+	permissions, err := baseApp.Queries.ListUserPermissions(t.Context(), user.ID)
+	require.NoError(t, err)
+
+	token, err := auth.CreateAccessToken(t.Context(), &user, permissions, time.Hour, baseApp.Queries)
+	require.NoError(t, err)
+
+	return token
+}
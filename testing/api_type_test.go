@@ -31,7 +31,7 @@ func TestTypesCollection(t *testing.T) {
 					AuthRecord:     data.AnalystEmail,
 					ExpectedStatus: http.StatusOK,
 					ExpectedHeaders: map[string]string{
-						"X-Total-Count": "4",
+						"X-Total-Count": "5",
 					},
 					ExpectedEvents: map[string]int{"OnRecordsListRequest": 1},
 				},
@@ -40,7 +40,7 @@ func TestTypesCollection(t *testing.T) {
 					Admin:          data.AdminEmail,
 					ExpectedStatus: http.StatusOK,
 					ExpectedHeaders: map[string]string{
-						"X-Total-Count": "4",
+						"X-Total-Count": "5",
 					},
 					ExpectedEvents: map[string]int{"OnRecordsListRequest": 1},
 				},
@@ -89,6 +89,139 @@ func TestTypesCollection(t *testing.T) {
 				},
 			},
 		},
+		{
+			baseTest: baseTest{
+				Name:           "CreateTypeWithKeyPrefix",
+				Method:         http.MethodPost,
+				RequestHeaders: map[string]string{"Content-Type": "application/json"},
+				URL:            "/api/types",
+				Body: s(map[string]any{
+					"singular":   "Incident Response",
+					"plural":     "Incident Responses",
+					"schema":     map[string]any{},
+					"key_prefix": "IR",
+				}),
+			},
+			userTests: []userTest{
+				{
+					Name:           "Admin",
+					Admin:          data.AdminEmail,
+					ExpectedStatus: http.StatusOK,
+					ExpectedContent: []string{
+						`"key_prefix":"IR"`,
+					},
+					ExpectedEvents: map[string]int{
+						"OnRecordAfterCreateRequest":  1,
+						"OnRecordBeforeCreateRequest": 1,
+					},
+				},
+			},
+		},
+		{
+			baseTest: baseTest{
+				Name:           "CreateTypeWithInvalidKeyPrefix",
+				Method:         http.MethodPost,
+				RequestHeaders: map[string]string{"Content-Type": "application/json"},
+				URL:            "/api/types",
+				Body: s(map[string]any{
+					"singular":   "Incident Response",
+					"plural":     "Incident Responses",
+					"schema":     map[string]any{},
+					"key_prefix": "ir-",
+				}),
+			},
+			userTests: []userTest{
+				{
+					Name:           "Admin",
+					Admin:          data.AdminEmail,
+					ExpectedStatus: http.StatusBadRequest,
+					ExpectedContent: []string{
+						`invalid key prefix`,
+					},
+					ExpectedEvents: map[string]int{},
+				},
+			},
+		},
+		{
+			baseTest: baseTest{
+				Name:           "CreateTypeWithMetrics",
+				Method:         http.MethodPost,
+				RequestHeaders: map[string]string{"Content-Type": "application/json"},
+				URL:            "/api/types",
+				Body: s(map[string]any{
+					"singular": "Phishing",
+					"plural":   "Phishing",
+					"schema": map[string]any{"type": "object", "properties": map[string]any{
+						"brand": map[string]any{"type": "string", "enum": []string{"DHL", "PayPal"}},
+					}},
+					"metrics": []string{"brand"},
+				}),
+			},
+			userTests: []userTest{
+				{
+					Name:           "Admin",
+					Admin:          data.AdminEmail,
+					ExpectedStatus: http.StatusOK,
+					ExpectedContent: []string{
+						`"metrics":["brand"]`,
+					},
+					ExpectedEvents: map[string]int{
+						"OnRecordAfterCreateRequest":  1,
+						"OnRecordBeforeCreateRequest": 1,
+					},
+				},
+			},
+		},
+		{
+			baseTest: baseTest{
+				Name:           "CreateTypeWithInvalidMetric",
+				Method:         http.MethodPost,
+				RequestHeaders: map[string]string{"Content-Type": "application/json"},
+				URL:            "/api/types",
+				Body: s(map[string]any{
+					"singular": "Phishing",
+					"plural":   "Phishing",
+					"schema": map[string]any{"type": "object", "properties": map[string]any{
+						"sender": map[string]any{"type": "string"},
+					}},
+					"metrics": []string{"sender"},
+				}),
+			},
+			userTests: []userTest{
+				{
+					Name:           "Admin",
+					Admin:          data.AdminEmail,
+					ExpectedStatus: http.StatusBadRequest,
+					ExpectedContent: []string{
+						`the field \"sender\" is neither a number nor an enum`,
+					},
+					ExpectedEvents: map[string]int{},
+				},
+			},
+		},
+		{
+			baseTest: baseTest{
+				Name:   "GetMetricSeriesWithoutMetric",
+				Method: http.MethodGet,
+				URL:    "/api/stats/metrics/incident/severity",
+			},
+			userTests: []userTest{
+				{
+					Name:            "Unauthorized",
+					ExpectedStatus:  http.StatusUnauthorized,
+					ExpectedContent: []string{`"invalid bearer token"`},
+				},
+				{
+					Name:           "Analyst",
+					AuthRecord:     data.AnalystEmail,
+					ExpectedStatus: http.StatusBadRequest,
+					ExpectedContent: []string{
+						`the field \"severity\" is not a metric of the type incident`,
+					},
+					ExpectedEvents: map[string]int{},
+				},
+			},
+		},
 		{
 			baseTest: baseTest{
 				Name:   "GetType",
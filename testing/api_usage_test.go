@@ -0,0 +1,87 @@
+package testing
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/SecurityBrewery/catalyst/app/data"
+)
+
+func TestUsageReport(t *testing.T) {
+	t.Parallel()
+
+	testSets := []catalystTest{
+		{
+			baseTest: baseTest{
+				Name:   "GetUsageReport",
+				Method: http.MethodGet,
+				URL:    "/api/reports/usage?since=2025-06-01T00:00:00Z&until=2025-07-01T00:00:00Z",
+			},
+			userTests: []userTest{
+				{
+					Name:            "Unauthorized",
+					ExpectedStatus:  http.StatusUnauthorized,
+					ExpectedContent: []string{`"invalid bearer token"`},
+				},
+				{
+					Name:            "Analyst",
+					AuthRecord:      data.AnalystEmail,
+					ExpectedStatus:  http.StatusUnauthorized,
+					ExpectedContent: []string{`"missing required scopes"`},
+				},
+				{
+					Name:            "Admin",
+					Admin:           data.AdminEmail,
+					ExpectedStatus:  http.StatusOK,
+					ExpectedContent: []string{`"month":"2025-06"`, `"tickets":1`, `"storage_bytes":5`},
+				},
+			},
+		},
+		{
+			baseTest: baseTest{
+				Name:   "GetUsageReportCSV",
+				Method: http.MethodGet,
+				URL:    "/api/reports/usage?since=2025-06-01T00:00:00Z&until=2025-07-01T00:00:00Z&format=csv",
+			},
+			userTests: []userTest{
+				{
+					Name:            "Admin",
+					Admin:           data.AdminEmail,
+					ExpectedStatus:  http.StatusOK,
+					ExpectedHeaders: map[string]string{"Content-Type": "text/csv"},
+					ExpectedContent: []string{"2025-06,,1,0.00,0,5"},
+				},
+			},
+		},
+		{
+			baseTest: baseTest{
+				Name:   "GetUsageReportPDF",
+				Method: http.MethodGet,
+				URL:    "/api/reports/usage?format=pdf",
+			},
+			userTests: []userTest{
+				{
+					Name:            "Admin",
+					Admin:           data.AdminEmail,
+					ExpectedStatus:  http.StatusOK,
+					ExpectedHeaders: map[string]string{"Content-Type": "application/pdf"},
+					ExpectedContent: []string{"%PDF-1.4"},
+				},
+			},
+		},
+	}
+
+	for _, testSet := range testSets {
+		t.Run(testSet.baseTest.Name, func(t *testing.T) {
+			t.Parallel()
+
+			for _, userTest := range testSet.userTests {
+				t.Run(userTest.Name, func(t *testing.T) {
+					t.Parallel()
+
+					runMatrixTest(t, testSet.baseTest, userTest)
+				})
+			}
+		})
+	}
+}
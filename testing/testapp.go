@@ -17,7 +17,7 @@ func App(t *testing.T) (*app.App, func(), *counter.Counter) {
 
 	dir := t.TempDir()
 
-	catalyst, cleanup, err := app.New(t.Context(), dir)
+	catalyst, cleanup, err := app.New(t.Context(), dir, app.Config{})
 	require.NoError(t, err)
 
 	data.DefaultTestData(t, dir, catalyst.Queries)
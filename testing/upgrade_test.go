@@ -30,7 +30,7 @@ func TestUpgrades(t *testing.T) {
 				log.Fatal(err)
 			}
 
-			pb, cleanup, err := app.New(t.Context(), db)
+			pb, cleanup, err := app.New(t.Context(), db, app.Config{})
 			if err != nil {
 				log.Fatal(err)
 			}